@@ -48,3 +48,26 @@ func TestArbCompress(t *testing.T) {
 	// test empty data:
 	testCompressDecompress(t, []byte{})
 }
+
+func TestCompressWithRawDictionary(t *testing.T) {
+	dictionary := []byte("position health inventory score position health inventory score")
+	data := []byte{}
+	for i := 0; i < 32; i++ {
+		data = append(data, []byte("player position health inventory score ")...)
+		data = append(data, byte(i))
+	}
+	compressed, err := CompressWithRawDictionary(data, LEVEL_WELL, dictionary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := DecompressWithRawDictionary(compressed, len(data)*2+64, dictionary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(res, data) {
+		t.Fatal("results differ ", res, " vs. ", data)
+	}
+	if res, err := Decompress(compressed, len(data)*2+64); err == nil && bytes.Equal(res, data) {
+		t.Fatal("decompressed without the dictionary")
+	}
+}
@@ -47,6 +47,22 @@ func Compress(input []byte, level uint32, dictionary Dictionary) ([]byte, error)
 	return output, nil
 }
 
+// CompressWithRawDictionary compresses with a raw LZ77 dictionary, which must be given again to decompress.
+func CompressWithRawDictionary(input []byte, level uint32, dictionary []byte) ([]byte, error) {
+	maxSize := compressedBufferSizeFor(len(input))
+	output := make([]byte, maxSize)
+	outbuf := sliceToBuffer(output)
+	inbuf := sliceToBuffer(input)
+	dictbuf := sliceToBuffer(dictionary)
+
+	status := C.brotli_compress_raw_dict(inbuf, outbuf, dictbuf, u32(level))
+	if status != C.BrotliStatus_Success {
+		return nil, fmt.Errorf("failed compression: %d", status)
+	}
+	output = output[:*outbuf.len]
+	return output, nil
+}
+
 var ErrOutputWontFit = errors.New("output won't fit in maxsize")
 
 func Decompress(input []byte, maxSize int) ([]byte, error) {
@@ -72,6 +88,27 @@ func DecompressWithDictionary(input []byte, maxSize int, dictionary Dictionary)
 	return output, nil
 }
 
+// DecompressWithRawDictionary decompresses data compressed with the raw LZ77 dictionary given.
+func DecompressWithRawDictionary(input []byte, maxSize int, dictionary []byte) ([]byte, error) {
+	output := make([]byte, maxSize)
+	outbuf := sliceToBuffer(output)
+	inbuf := sliceToBuffer(input)
+	dictbuf := sliceToBuffer(dictionary)
+
+	status := C.brotli_decompress_raw_dict(inbuf, outbuf, dictbuf)
+	if status == C.BrotliStatus_NeedsMoreOutput {
+		return nil, ErrOutputWontFit
+	}
+	if status != C.BrotliStatus_Success {
+		return nil, fmt.Errorf("failed decompression: %d", status)
+	}
+	if *outbuf.len > usize(maxSize) {
+		return nil, fmt.Errorf("failed decompression: result too large: %d", *outbuf.len)
+	}
+	output = output[:*outbuf.len]
+	return output, nil
+}
+
 func sliceToBuffer(slice []byte) brotliBuffer {
 	count := usize(len(slice))
 	if count == 0 {
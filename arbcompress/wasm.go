@@ -26,6 +26,9 @@ func brotliCompress(inBuf unsafe.Pointer, inLen uint32, outBuf unsafe.Pointer, o
 //go:wasmimport arbcompress brotli_decompress
 func brotliDecompress(inBuf unsafe.Pointer, inLen uint32, outBuf unsafe.Pointer, outLen unsafe.Pointer, dictionary Dictionary) brotliStatus
 
+//go:wasmimport arbcompress brotli_decompress_raw_dict
+func brotliDecompressRawDict(inBuf unsafe.Pointer, inLen uint32, outBuf unsafe.Pointer, outLen unsafe.Pointer, dictBuf unsafe.Pointer, dictLen uint32) brotliStatus
+
 func Compress(input []byte, level uint32, dictionary Dictionary) ([]byte, error) {
 	maxOutSize := compressedBufferSizeFor(len(input))
 	outBuf := make([]byte, maxOutSize)
@@ -62,3 +65,21 @@ func DecompressWithDictionary(input []byte, maxSize int, dictionary Dictionary)
 	}
 	return outBuf[:outLen], nil
 }
+
+// DecompressWithRawDictionary decompresses data compressed with the raw LZ77 dictionary given.
+func DecompressWithRawDictionary(input []byte, maxSize int, dictionary []byte) ([]byte, error) {
+	outBuf := make([]byte, maxSize)
+	outLen := uint32(len(outBuf))
+	status := brotliDecompressRawDict(
+		arbutil.SliceToUnsafePointer(input),
+		uint32(len(input)),
+		arbutil.SliceToUnsafePointer(outBuf),
+		unsafe.Pointer(&outLen),
+		arbutil.SliceToUnsafePointer(dictionary),
+		uint32(len(dictionary)),
+	)
+	if status != brotliSuccess {
+		return nil, fmt.Errorf("failed decompression")
+	}
+	return outBuf[:outLen], nil
+}
@@ -64,3 +64,46 @@ pub extern "C" fn brotli_decompress(
     }
     BrotliStatus::Success
 }
+
+/// Brotli compresses the given Go data into a buffer of limited capacity using a raw LZ77 dictionary.
+#[no_mangle]
+pub extern "C" fn brotli_compress_raw_dict(
+    input: BrotliBuffer,
+    mut output: BrotliBuffer,
+    dictionary: BrotliBuffer,
+    level: u32,
+) -> BrotliStatus {
+    let window = DEFAULT_WINDOW_SIZE;
+    let dictionary = dictionary.as_slice();
+    let buffer = output.as_uninit();
+    match crate::compress_fixed_with_raw_dictionary(
+        input.as_slice(),
+        buffer,
+        level,
+        window,
+        dictionary,
+    ) {
+        Ok(slice) => unsafe { *output.len = slice.len() },
+        Err(status) => return status,
+    }
+    BrotliStatus::Success
+}
+
+/// Brotli decompresses the given Go data into a buffer of limited capacity using a raw LZ77 dictionary.
+#[no_mangle]
+pub extern "C" fn brotli_decompress_raw_dict(
+    input: BrotliBuffer,
+    mut output: BrotliBuffer,
+    dictionary: BrotliBuffer,
+) -> BrotliStatus {
+    let dictionary = dictionary.as_slice();
+    match crate::decompress_fixed_with_raw_dictionary(
+        input.as_slice(),
+        output.as_uninit(),
+        dictionary,
+    ) {
+        Ok(slice) => unsafe { *output.len = slice.len() },
+        Err(status) => return status,
+    }
+    BrotliStatus::Success
+}
@@ -25,6 +25,9 @@ extern "C" {
     fn BrotliEncoderGetPreparedDictionarySize(
         dictionary: *const EncoderPreparedDictionary,
     ) -> usize;
+
+    /// Frees a dictionary prepared by [`BrotliEncoderPrepareDictionary`].
+    fn BrotliEncoderDestroyPreparedDictionary(dictionary: *mut EncoderPreparedDictionary);
 }
 
 /// Forces a type to implement [`Sync`].
@@ -82,6 +85,39 @@ impl Dictionary {
     }
 }
 
+/// Prepares a raw LZ77 dictionary supplied by the caller for compression at the given level.
+/// The result must be freed with [`destroy_prepared`].
+pub(crate) fn prepare_raw(
+    data: &[u8],
+    level: u32,
+) -> Result<*mut EncoderPreparedDictionary, BrotliStatus> {
+    let len = c_int::try_from(data.len()).map_err(|_| BrotliStatus::Failure)?;
+    unsafe {
+        let dict = BrotliEncoderPrepareDictionary(
+            BrotliSharedDictionaryType::Raw,
+            len,
+            data.as_ptr(),
+            level as c_int,
+            None,
+            None,
+            ptr::null_mut(),
+        );
+        if dict.is_null() {
+            return Err(BrotliStatus::Failure);
+        }
+        if BrotliEncoderGetPreparedDictionarySize(dict) == 0 {
+            BrotliEncoderDestroyPreparedDictionary(dict);
+            return Err(BrotliStatus::Failure);
+        }
+        Ok(dict)
+    }
+}
+
+/// Frees a dictionary made by [`prepare_raw`].
+pub(crate) unsafe fn destroy_prepared(dict: *mut EncoderPreparedDictionary) {
+    BrotliEncoderDestroyPreparedDictionary(dict)
+}
+
 impl From<Dictionary> for u8 {
     fn from(value: Dictionary) -> Self {
         value as u32 as u8
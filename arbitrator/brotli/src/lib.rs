@@ -141,6 +141,32 @@ pub fn compress_fixed<'a>(
     level: u32,
     window_size: u32,
     dictionary: Dictionary,
+) -> Result<&'a [u8], BrotliStatus> {
+    let dict = dictionary.ptr(level)?;
+    compress_fixed_prepared(input, output, level, window_size, dict)
+}
+
+/// Brotli compresses a slice into a buffer of limited capacity using a raw LZ77 dictionary supplied by the caller.
+pub fn compress_fixed_with_raw_dictionary<'a>(
+    input: &'a [u8],
+    output: &'a mut [MaybeUninit<u8>],
+    level: u32,
+    window_size: u32,
+    dictionary: &[u8],
+) -> Result<&'a [u8], BrotliStatus> {
+    let dict = dicts::prepare_raw(dictionary, level)?;
+    let result = compress_fixed_prepared(input, output, level, window_size, Some(dict as _));
+    unsafe { dicts::destroy_prepared(dict) };
+    result
+}
+
+/// Brotli compresses a slice into a buffer of limited capacity, attaching the prepared dictionary if there is one.
+fn compress_fixed_prepared<'a>(
+    input: &'a [u8],
+    output: &'a mut [MaybeUninit<u8>],
+    level: u32,
+    window_size: u32,
+    dictionary: Option<*const EncoderPreparedDictionary>,
 ) -> Result<&'a [u8], BrotliStatus> {
     unsafe {
         let state = BrotliEncoderCreateInstance(None, None, ptr::null_mut());
@@ -166,10 +192,8 @@ pub fn compress_fixed<'a>(
         ));
 
         // attach a custom dictionary if requested
-        match dictionary.ptr(level) {
-            Ok(Some(dict)) => check!(BrotliEncoderAttachPreparedDictionary(state, dict)),
-            Err(status) => check!(status),
-            _ => {}
+        if let Some(dict) = dictionary {
+            check!(BrotliEncoderAttachPreparedDictionary(state, dict));
         }
 
         let mut in_len = input.len();
@@ -262,6 +286,23 @@ pub fn decompress_fixed<'a>(
     input: &'a [u8],
     output: &'a mut [MaybeUninit<u8>],
     dictionary: Dictionary,
+) -> Result<&'a [u8], BrotliStatus> {
+    decompress_fixed_raw(input, output, dictionary.slice())
+}
+
+/// Brotli decompresses a slice into a buffer of limited capacity using a raw LZ77 dictionary supplied by the caller.
+pub fn decompress_fixed_with_raw_dictionary<'a>(
+    input: &'a [u8],
+    output: &'a mut [MaybeUninit<u8>],
+    dictionary: &[u8],
+) -> Result<&'a [u8], BrotliStatus> {
+    decompress_fixed_raw(input, output, Some(dictionary))
+}
+
+fn decompress_fixed_raw<'a>(
+    input: &'a [u8],
+    output: &'a mut [MaybeUninit<u8>],
+    dictionary: Option<&[u8]>,
 ) -> Result<&'a [u8], BrotliStatus> {
     unsafe {
         let state = BrotliDecoderCreateInstance(None, None, ptr::null_mut());
@@ -275,7 +316,7 @@ pub fn decompress_fixed<'a>(
             };
         }
 
-        if let Some(dict) = dictionary.slice() {
+        if let Some(dict) = dictionary {
             let attatched = BrotliDecoderAttachDictionary(
                 state,
                 BrotliSharedDictionaryType::Raw,
@@ -2,6 +2,7 @@ package arbnode
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -54,3 +55,110 @@ func (a *BlockValidatorDebugAPI) ValidateMessageNumber(
 	result.Valid = valid
 	return result, err
 }
+
+type SeqCoordinatorAPI struct {
+	coordinator *SeqCoordinator
+}
+
+// HandoffDrill hands the lockout off to the next sequencer in priority order, or only checks that it could with dryRun.
+func (a *SeqCoordinatorAPI) HandoffDrill(ctx context.Context, dryRun bool) (*HandoffDrillResult, error) {
+	return a.coordinator.HandoffDrill(ctx, dryRun)
+}
+
+func (a *SeqCoordinatorAPI) FencingToken(ctx context.Context) hexutil.Uint64 {
+	return hexutil.Uint64(a.coordinator.FencingToken())
+}
+
+type InboxAPI struct {
+	tracker   *InboxTracker
+	reader    *InboxReader
+	sequencer bool
+}
+
+// InboxRewindTarget selects the point to rewind the inbox to, either by batch count, or by parent chain block, which
+// keeps the batches and delayed messages posted up to and including that block.
+type InboxRewindTarget struct {
+	BatchCount       *hexutil.Uint64 `json:"batchCount"`
+	ParentChainBlock *hexutil.Uint64 `json:"parentChainBlock"`
+}
+
+func (a *InboxAPI) plan(ctx context.Context, target InboxRewindTarget) (*RewindPlan, error) {
+	if (target.BatchCount == nil) == (target.ParentChainBlock == nil) {
+		return nil, errors.New("exactly one of batchCount and parentChainBlock must be given")
+	}
+	if target.BatchCount != nil {
+		delayedCount, err := a.tracker.GetDelayedCount()
+		if err != nil {
+			return nil, err
+		}
+		return a.tracker.PlanRewind(ctx, a.reader, uint64(*target.BatchCount), delayedCount)
+	}
+	block := uint64(*target.ParentChainBlock)
+	batchCount, err := a.tracker.BatchCountAtParentChainBlock(block)
+	if err != nil {
+		return nil, err
+	}
+	delayedCount, err := a.tracker.DelayedCountAtParentChainBlock(ctx, block)
+	if err != nil {
+		return nil, err
+	}
+	return a.tracker.PlanRewind(ctx, a.reader, batchCount, delayedCount)
+}
+
+// SimulateRewind returns what rewinding to the target would remove, verifying the kept accumulators, without
+// changing anything.
+func (a *InboxAPI) SimulateRewind(ctx context.Context, target InboxRewindTarget) (*RewindPlan, error) {
+	return a.plan(ctx, target)
+}
+
+// Rewind rewinds the inbox tracker and transaction streamer to the target, after verifying the kept accumulators.
+// It's refused on a sequencer, which would drop messages it already sequenced but hasn't posted yet.
+func (a *InboxAPI) Rewind(ctx context.Context, target InboxRewindTarget) (*RewindPlan, error) {
+	if a.sequencer {
+		return nil, errors.New("refusing to rewind the inbox of a sequencer, stop sequencing first")
+	}
+	plan, err := a.plan(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	return plan, a.tracker.Rewind(plan)
+}
+
+// maximum number of messages a single provenance query may return
+const maxMessageProvenanceQuery = 10_000
+
+type MessageProvenanceAPI struct {
+	streamer *TransactionStreamer
+}
+
+type MessageProvenanceResult struct {
+	MessageIndex hexutil.Uint64 `json:"messageIndex"`
+	MessageProvenance
+}
+
+// MessageProvenance returns the recorded provenance of up to count messages starting at start, stopping at the
+// current message count.
+func (a *MessageProvenanceAPI) MessageProvenance(ctx context.Context, start hexutil.Uint64, count hexutil.Uint64) ([]*MessageProvenanceResult, error) {
+	if count > maxMessageProvenanceQuery {
+		return nil, fmt.Errorf("count %v exceeds the maximum of %v", count, maxMessageProvenanceQuery)
+	}
+	msgCount, err := a.streamer.GetMessageCount()
+	if err != nil {
+		return nil, err
+	}
+	var results []*MessageProvenanceResult
+	for pos := arbutil.MessageIndex(start); pos < arbutil.MessageIndex(start+count) && pos < msgCount; pos++ {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		provenance, err := a.streamer.GetMessageProvenance(pos)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, &MessageProvenanceResult{
+			MessageIndex:      hexutil.Uint64(pos),
+			MessageProvenance: *provenance,
+		})
+	}
+	return results, nil
+}
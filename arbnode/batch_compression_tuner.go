@@ -0,0 +1,139 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbnode
+
+import (
+	"errors"
+	"time"
+
+	"github.com/andybalholm/brotli"
+	"github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+var (
+	compressionLevelGauge     = metrics.NewRegisteredGauge("arb/batchposter/compression/level", nil)
+	compressionTimeHistogram  = metrics.NewRegisteredHistogram("arb/batchposter/compression/time", nil, metrics.NewBoundedHistogramSample())
+	compressionRatioGaugeBips = metrics.NewRegisteredGauge("arb/batchposter/compression/ratio_bips", nil)
+)
+
+// AdaptiveCompressionConfig configures tuning the brotli level batches are recompressed at when closed.
+// The level moves between MinLevel and the batch poster's compression-level, trading size for time.
+type AdaptiveCompressionConfig struct {
+	Enable           bool          `koanf:"enable" reload:"hot"`
+	CPUBudget        time.Duration `koanf:"cpu-budget" reload:"hot"`
+	MinLevel         int           `koanf:"min-level" reload:"hot"`
+	MinSizeReduction float64       `koanf:"min-size-reduction" reload:"hot"`
+}
+
+var DefaultAdaptiveCompressionConfig = AdaptiveCompressionConfig{
+	Enable:           false,
+	CPUBudget:        2 * time.Second,
+	MinLevel:         brotli.DefaultCompression,
+	MinSizeReduction: 0.01,
+}
+
+func AdaptiveCompressionConfigAddOptions(prefix string, f *pflag.FlagSet) {
+	f.Bool(prefix+".enable", DefaultAdaptiveCompressionConfig.Enable, "choose the batch compression level dynamically, up to compression-level, to stay within the cpu budget")
+	f.Duration(prefix+".cpu-budget", DefaultAdaptiveCompressionConfig.CPUBudget, "target maximum time spent compressing a batch when it's closed")
+	f.Int(prefix+".min-level", DefaultAdaptiveCompressionConfig.MinLevel, "lowest compression level adaptive compression may choose")
+	f.Float64(prefix+".min-size-reduction", DefaultAdaptiveCompressionConfig.MinSizeReduction, "minimum fractional size reduction a higher compression level must have achieved to be chosen again")
+}
+
+func (c *AdaptiveCompressionConfig) Validate() error {
+	if !c.Enable {
+		return nil
+	}
+	if c.CPUBudget <= 0 {
+		return errors.New("adaptive compression cpu budget must be positive")
+	}
+	if c.MinLevel < brotli.BestSpeed || c.MinLevel > brotli.BestCompression {
+		return errors.New("adaptive compression min level must be a valid brotli level")
+	}
+	if c.MinSizeReduction < 0 || c.MinSizeReduction >= 1 {
+		return errors.New("adaptive compression min size reduction must be in [0, 1)")
+	}
+	return nil
+}
+
+// compressionRatioDecay weighs the latest batch in each level's moving compression ratio
+const compressionRatioDecay = 0.2
+
+// compressionTuner picks the level batches are recompressed at. After each batch it lowers the level if the
+// recompression went over budget, and raises it if there's ample budget left, unless the higher level has
+// been observed to barely shrink batches compared to the current one.
+// It's only used by the batch poster's main loop, so it isn't thread safe.
+type compressionTuner struct {
+	level  int             // 0 until first used
+	ratios map[int]float64 // moving average of compressed size over uncompressed size, per level
+}
+
+func newCompressionTuner() *compressionTuner {
+	return &compressionTuner{ratios: make(map[int]float64)}
+}
+
+func (t *compressionTuner) bounds(config *BatchPosterConfig) (int, int) {
+	maxLevel := config.CompressionLevel
+	minLevel := config.AdaptiveCompression.MinLevel
+	if minLevel > maxLevel {
+		minLevel = maxLevel
+	}
+	return minLevel, maxLevel
+}
+
+// Level returns the compression level to close the next batch at
+func (t *compressionTuner) Level(config *BatchPosterConfig) int {
+	if !config.AdaptiveCompression.Enable {
+		return config.CompressionLevel
+	}
+	minLevel, maxLevel := t.bounds(config)
+	if t.level == 0 || t.level > maxLevel {
+		t.level = maxLevel
+	}
+	if t.level < minLevel {
+		t.level = minLevel
+	}
+	return t.level
+}
+
+// Observe records how long closing a batch at level took and how well it compressed, adjusting the level
+func (t *compressionTuner) Observe(config *BatchPosterConfig, level int, elapsed time.Duration, uncompressedSize, compressedSize int) {
+	compressionTimeHistogram.Update(elapsed.Microseconds())
+	if uncompressedSize > 0 {
+		ratio := float64(compressedSize) / float64(uncompressedSize)
+		compressionRatioGaugeBips.Update(int64(ratio * 10000))
+		if previous, ok := t.ratios[level]; ok {
+			ratio = previous*(1-compressionRatioDecay) + ratio*compressionRatioDecay
+		}
+		t.ratios[level] = ratio
+	}
+	if !config.AdaptiveCompression.Enable || level != t.level {
+		// the level was lowered for this batch due to the backlog, so it says little about the budget
+		compressionLevelGauge.Update(int64(level))
+		return
+	}
+
+	minLevel, maxLevel := t.bounds(config)
+	budget := config.AdaptiveCompression.CPUBudget
+	if elapsed > budget && t.level > minLevel {
+		t.level--
+		log.Info("lowering batch compression level", "level", t.level, "elapsed", elapsed, "budget", budget)
+	} else if elapsed < budget/2 && t.level < maxLevel && t.worthRaising(config) {
+		t.level++
+		log.Info("raising batch compression level", "level", t.level, "elapsed", elapsed, "budget", budget)
+	}
+	compressionLevelGauge.Update(int64(t.level))
+}
+
+func (t *compressionTuner) worthRaising(config *BatchPosterConfig) bool {
+	current, haveCurrent := t.ratios[t.level]
+	higher, haveHigher := t.ratios[t.level+1]
+	if !haveCurrent || !haveHigher || current <= 0 {
+		// explore levels we have no data for
+		return true
+	}
+	return (current-higher)/current >= config.AdaptiveCompression.MinSizeReduction
+}
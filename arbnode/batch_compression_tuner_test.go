@@ -0,0 +1,55 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbnode
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCompressionTuner(t *testing.T) {
+	config := DefaultBatchPosterConfig
+	config.CompressionLevel = 11
+	config.AdaptiveCompression.Enable = true
+	config.AdaptiveCompression.CPUBudget = time.Second
+	config.AdaptiveCompression.MinLevel = 9
+	config.AdaptiveCompression.MinSizeReduction = 0.05
+	Require(t, config.Validate())
+
+	tuner := newCompressionTuner()
+	if level := tuner.Level(&config); level != 11 {
+		Fail(t, "didn't start at the configured compression level", level)
+	}
+
+	// over budget: step down, but never below the minimum level
+	tuner.Observe(&config, 11, 2*time.Second, 1000, 300)
+	tuner.Observe(&config, 10, 2*time.Second, 1000, 310)
+	tuner.Observe(&config, 9, 2*time.Second, 1000, 320)
+	if level := tuner.Level(&config); level != 9 {
+		Fail(t, "wrong level after exceeding the budget", level)
+	}
+
+	// well under budget: level 10 saved under 5% over level 9, so don't raise
+	tuner.Observe(&config, 9, 100*time.Millisecond, 1000, 320)
+	if level := tuner.Level(&config); level != 9 {
+		Fail(t, "raised the level when it barely helped", level)
+	}
+
+	// a batch compressed at a backlog-lowered level doesn't move the tuner
+	tuner.Observe(&config, 5, 10*time.Second, 1000, 400)
+	if level := tuner.Level(&config); level != 9 {
+		Fail(t, "adjusted the level for a batch not compressed at it", level)
+	}
+
+	config.AdaptiveCompression.MinSizeReduction = 0.01
+	tuner.Observe(&config, 9, 100*time.Millisecond, 1000, 320)
+	if level := tuner.Level(&config); level != 10 {
+		Fail(t, "didn't raise the level with budget to spare", level)
+	}
+
+	config.AdaptiveCompression.Enable = false
+	if level := tuner.Level(&config); level != 11 {
+		Fail(t, "didn't use the configured level while disabled", level)
+	}
+}
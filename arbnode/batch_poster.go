@@ -24,8 +24,8 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
-	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/crypto/kzg4844"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/log"
@@ -34,6 +34,7 @@ import (
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/rpc"
 
+	"github.com/offchainlabs/nitro/arbcompress"
 	"github.com/offchainlabs/nitro/arbnode/dataposter"
 	"github.com/offchainlabs/nitro/arbnode/dataposter/storage"
 	"github.com/offchainlabs/nitro/arbnode/redislock"
@@ -43,12 +44,14 @@ import (
 	"github.com/offchainlabs/nitro/arbutil"
 	"github.com/offchainlabs/nitro/cmd/chaininfo"
 	"github.com/offchainlabs/nitro/cmd/genericconf"
+	"github.com/offchainlabs/nitro/das"
 	"github.com/offchainlabs/nitro/execution"
 	"github.com/offchainlabs/nitro/solgen/go/bridgegen"
 	"github.com/offchainlabs/nitro/util"
 	"github.com/offchainlabs/nitro/util/arbmath"
 	"github.com/offchainlabs/nitro/util/blobs"
 	"github.com/offchainlabs/nitro/util/headerreader"
+	"github.com/offchainlabs/nitro/util/l1price"
 	"github.com/offchainlabs/nitro/util/redisutil"
 	"github.com/offchainlabs/nitro/util/stopwaiter"
 )
@@ -72,9 +75,6 @@ var (
 	batchPosterDAFailureCounter            = metrics.NewRegisteredCounter("arb/batchPoster/action/da_failure", nil)
 
 	batchPosterFailureCounter = metrics.NewRegisteredCounter("arb/batchPoster/action/failure", nil)
-
-	usableBytesInBlob    = big.NewInt(int64(len(kzg4844.Blob{}) * 31 / 32))
-	blobTxBlobGasPerBlob = big.NewInt(params.BlobTxBlobGasPerBlob)
 )
 
 const (
@@ -107,9 +107,14 @@ type BatchPoster struct {
 	building           *buildingBatch
 	dapWriter          daprovider.Writer
 	dapReaders         []daprovider.Reader
+	dictionaries       daprovider.DictionaryReader
 	dataPoster         *dataposter.DataPoster
+	l1PriceOracle      l1price.Oracle
+	postedBatches      ethdb.Database // records of posted batches to repost if a reorg drops them, if non-nil
 	redisLock          *redislock.Simple
 	messagesPerBatch   *arbmath.MovingAverage[uint64]
+	compressionTuner   *compressionTuner
+	postingPolicy      *batchPostingPolicy
 	non4844BatchCount  int // Count of consecutive non-4844 batches posted
 	// This is an atomic variable that should only be accessed atomically.
 	// An estimate of the number of batches we want to post but haven't yet.
@@ -156,6 +161,9 @@ type BatchPosterConfig struct {
 	// Batch posting error delay.
 	ErrorDelay                     time.Duration               `koanf:"error-delay" reload:"hot"`
 	CompressionLevel               int                         `koanf:"compression-level" reload:"hot"`
+	CompressionDictionary          string                      `koanf:"compression-dictionary" reload:"hot"`
+	AdaptiveCompression            AdaptiveCompressionConfig   `koanf:"adaptive-compression" reload:"hot"`
+	PostingPolicy                  PostingPolicyConfig         `koanf:"posting-policy" reload:"hot"`
 	DASRetentionPeriod             time.Duration               `koanf:"das-retention-period" reload:"hot"`
 	GasRefunderAddress             string                      `koanf:"gas-refunder-address" reload:"hot"`
 	DataPoster                     dataposter.DataPosterConfig `koanf:"data-poster" reload:"hot"`
@@ -172,9 +180,12 @@ type BatchPosterConfig struct {
 	Dangerous                      BatchPosterDangerousConfig  `koanf:"dangerous"`
 	ReorgResistanceMargin          time.Duration               `koanf:"reorg-resistance-margin" reload:"hot"`
 	CheckBatchCorrectness          bool                        `koanf:"check-batch-correctness"`
+	Resubmission                   BatchResubmissionConfig     `koanf:"resubmission" reload:"hot"`
+	L1PriceOracle                  l1price.Config              `koanf:"l1-price-oracle"`
 
-	gasRefunder  common.Address
-	l1BlockBound l1BlockBound
+	gasRefunder           common.Address
+	compressionDictionary common.Hash
+	l1BlockBound          l1BlockBound
 }
 
 func (c *BatchPosterConfig) Validate() error {
@@ -182,9 +193,25 @@ func (c *BatchPosterConfig) Validate() error {
 		return fmt.Errorf("invalid gas refunder address \"%v\"", c.GasRefunderAddress)
 	}
 	c.gasRefunder = common.HexToAddress(c.GasRefunderAddress)
+	if c.CompressionDictionary != "" {
+		hash := common.FromHex(c.CompressionDictionary)
+		if len(hash) != common.HashLength {
+			return fmt.Errorf("invalid compression dictionary hash \"%v\"", c.CompressionDictionary)
+		}
+		c.compressionDictionary = common.BytesToHash(hash)
+	}
 	if c.MaxSize <= 40 {
 		return errors.New("MaxBatchSize too small")
 	}
+	if err := c.AdaptiveCompression.Validate(); err != nil {
+		return err
+	}
+	if err := c.PostingPolicy.Validate(); err != nil {
+		return err
+	}
+	if err := c.L1PriceOracle.Validate(); err != nil {
+		return err
+	}
 	if c.L1BlockBound == "" {
 		c.l1BlockBound = l1BlockBoundDefault
 	} else if c.L1BlockBound == "safe" {
@@ -213,6 +240,9 @@ func BatchPosterConfigAddOptions(prefix string, f *pflag.FlagSet) {
 	f.Duration(prefix+".poll-interval", DefaultBatchPosterConfig.PollInterval, "how long to wait after no batches are ready to be posted before checking again")
 	f.Duration(prefix+".error-delay", DefaultBatchPosterConfig.ErrorDelay, "how long to delay after error posting batch")
 	f.Int(prefix+".compression-level", DefaultBatchPosterConfig.CompressionLevel, "batch compression level")
+	f.String(prefix+".compression-dictionary", DefaultBatchPosterConfig.CompressionDictionary, "keccak256 hash of one of the node's batch dictionaries to compress batches with when it makes them smaller (the chain must have committed to it, and calldata batches need a sequencer inbox accepting the dictionary header byte)")
+	AdaptiveCompressionConfigAddOptions(prefix+".adaptive-compression", f)
+	PostingPolicyConfigAddOptions(prefix+".posting-policy", f)
 	f.Duration(prefix+".das-retention-period", DefaultBatchPosterConfig.DASRetentionPeriod, "In AnyTrust mode, the period which DASes are requested to retain the stored batches.")
 	f.String(prefix+".gas-refunder-address", DefaultBatchPosterConfig.GasRefunderAddress, "The gas refunder contract address (optional)")
 	f.Uint64(prefix+".extra-batch-gas", DefaultBatchPosterConfig.ExtraBatchGas, "use this much more gas than estimation says is necessary to post batches")
@@ -225,6 +255,8 @@ func BatchPosterConfigAddOptions(prefix string, f *pflag.FlagSet) {
 	f.Uint64(prefix+".gas-estimate-base-fee-multiple-bips", uint64(DefaultBatchPosterConfig.GasEstimateBaseFeeMultipleBips), "for gas estimation, use this multiple of the basefee (measured in basis points) as the max fee per gas")
 	f.Duration(prefix+".reorg-resistance-margin", DefaultBatchPosterConfig.ReorgResistanceMargin, "do not post batch if its within this duration from layer 1 minimum bounds. Requires l1-block-bound option not be set to \"ignore\"")
 	f.Bool(prefix+".check-batch-correctness", DefaultBatchPosterConfig.CheckBatchCorrectness, "setting this to true will run the batch against an inbox multiplexer and verifies that it produces the correct set of messages")
+	BatchResubmissionConfigAddOptions(prefix+".resubmission", f)
+	l1price.ConfigAddOptions(prefix+".l1-price-oracle", f)
 	redislock.AddConfigOptions(prefix+".redis-lock", f)
 	dataposter.DataPosterConfigAddOptions(prefix+".data-poster", f, dataposter.DefaultDataPosterConfig)
 	genericconf.WalletConfigAddOptions(prefix+".parent-chain-wallet", f, DefaultBatchPosterConfig.ParentChainWallet.Pathname)
@@ -242,6 +274,8 @@ var DefaultBatchPosterConfig = BatchPosterConfig{
 	MaxDelay:                       time.Hour,
 	WaitForMaxDelay:                false,
 	CompressionLevel:               brotli.BestCompression,
+	AdaptiveCompression:            DefaultAdaptiveCompressionConfig,
+	PostingPolicy:                  DefaultPostingPolicyConfig,
 	DASRetentionPeriod:             daprovider.DefaultDASRetentionPeriod,
 	GasRefunderAddress:             "",
 	ExtraBatchGas:                  50_000,
@@ -256,6 +290,8 @@ var DefaultBatchPosterConfig = BatchPosterConfig{
 	GasEstimateBaseFeeMultipleBips: arbmath.OneInBips * 3 / 2,
 	ReorgResistanceMargin:          10 * time.Minute,
 	CheckBatchCorrectness:          true,
+	Resubmission:                   DefaultBatchResubmissionConfig,
+	L1PriceOracle:                  l1price.DefaultConfig,
 }
 
 var DefaultBatchPosterL1WalletConfig = genericconf.WalletConfig{
@@ -275,6 +311,8 @@ var TestBatchPosterConfig = BatchPosterConfig{
 	MaxDelay:                       0,
 	WaitForMaxDelay:                false,
 	CompressionLevel:               2,
+	AdaptiveCompression:            DefaultAdaptiveCompressionConfig,
+	PostingPolicy:                  DefaultPostingPolicyConfig,
 	DASRetentionPeriod:             daprovider.DefaultDASRetentionPeriod,
 	GasRefunderAddress:             "",
 	ExtraBatchGas:                  10_000,
@@ -287,10 +325,13 @@ var TestBatchPosterConfig = BatchPosterConfig{
 	UseAccessLists:                 true,
 	GasEstimateBaseFeeMultipleBips: arbmath.OneInBips * 3 / 2,
 	CheckBatchCorrectness:          true,
+	Resubmission:                   DefaultBatchResubmissionConfig,
+	L1PriceOracle:                  l1price.DefaultConfig,
 }
 
 type BatchPosterOpts struct {
 	DataPosterDB  ethdb.Database
+	PostedBatchDB ethdb.Database
 	L1Reader      *headerreader.HeaderReader
 	Inbox         *InboxTracker
 	Streamer      *TransactionStreamer
@@ -302,6 +343,7 @@ type BatchPosterOpts struct {
 	DAPWriter     daprovider.Writer
 	ParentChainID *big.Int
 	DAPReaders    []daprovider.Reader
+	Dictionaries  daprovider.DictionaryReader
 }
 
 func NewBatchPoster(ctx context.Context, opts *BatchPosterOpts) (*BatchPoster, error) {
@@ -349,11 +391,19 @@ func NewBatchPoster(ctx context.Context, opts *BatchPosterOpts) (*BatchPoster, e
 		dapWriter:          opts.DAPWriter,
 		redisLock:          redisLock,
 		dapReaders:         opts.DAPReaders,
+		dictionaries:       opts.Dictionaries,
+		postedBatches:      opts.PostedBatchDB,
+		compressionTuner:   newCompressionTuner(),
+		postingPolicy:      newBatchPostingPolicy(),
 	}
 	b.messagesPerBatch, err = arbmath.NewMovingAverage[uint64](20)
 	if err != nil {
 		return nil, err
 	}
+	b.l1PriceOracle, err = l1price.NewOracle(ctx, &opts.Config().L1PriceOracle)
+	if err != nil {
+		return nil, err
+	}
 	dataPosterConfigFetcher := func() *dataposter.DataPosterConfig {
 		return &(opts.Config().DataPoster)
 	}
@@ -591,18 +641,16 @@ func (b *BatchPoster) pollForL1PriceData(ctx context.Context) {
 				log.Info("L1 headers channel checking for l1 price data has been closed")
 				return
 			}
-			baseFeeGauge.Update(h.BaseFee.Int64())
-			l1GasPrice := h.BaseFee.Uint64()
+			prices, err := b.l1PriceOracle.Prices(ctx, h)
+			if err != nil {
+				log.Warn("unable to get parent chain prices to update arb/batchposter price metrics", "err", err)
+				continue
+			}
+			baseFeeGauge.Update(prices.BaseFee.Int64())
+			if prices.BlobFeePerByte != nil {
+				blobFeeGauge.Update(prices.BlobFeePerByte.Int64())
+			}
 			if h.BlobGasUsed != nil {
-				if h.ExcessBlobGas != nil {
-					blobFeePerByte := eip4844.CalcBlobFee(eip4844.CalcExcessBlobGas(*h.ExcessBlobGas, *h.BlobGasUsed))
-					blobFeePerByte.Mul(blobFeePerByte, blobTxBlobGasPerBlob)
-					blobFeePerByte.Div(blobFeePerByte, usableBytesInBlob)
-					blobFeeGauge.Update(blobFeePerByte.Int64())
-					if l1GasPrice > blobFeePerByte.Uint64()/16 {
-						l1GasPrice = blobFeePerByte.Uint64() / 16
-					}
-				}
 				// #nosec G115
 				blobGasUsedGauge.Update(int64(*h.BlobGasUsed))
 			}
@@ -616,8 +664,7 @@ func (b *BatchPoster) pollForL1PriceData(ctx context.Context) {
 			} else {
 				suggestedTipCapGauge.Update(suggestedTipCap.Int64())
 			}
-			// #nosec G115
-			l1GasPriceGauge.Update(int64(l1GasPrice))
+			l1GasPriceGauge.Update(prices.L1GasPrice().Int64())
 		case <-ctx.Done():
 			return
 		}
@@ -703,11 +750,13 @@ type batchSegments struct {
 	compressedBuffer      *bytes.Buffer
 	compressedWriter      *brotli.Writer
 	rawSegments           [][]byte
+	dictionary            []byte // compresses the batch if it makes it smaller, nil if there's none
 	timestamp             uint64
 	blockNum              uint64
 	delayedMsg            uint64
 	sizeLimit             int
 	recompressionLevel    int
+	recompressionTime     time.Duration // how long the final recompression took
 	newUncompressedSize   int
 	totalUncompressedSize int
 	lastCompressedSize    int
@@ -724,7 +773,7 @@ type buildingBatch struct {
 	muxBackend        *simulatedMuxBackend
 }
 
-func newBatchSegments(firstDelayed uint64, config *BatchPosterConfig, backlog uint64, use4844 bool) *batchSegments {
+func newBatchSegments(firstDelayed uint64, config *BatchPosterConfig, compressionLevel int, backlog uint64, use4844 bool, dictionary []byte) *batchSegments {
 	maxSize := config.MaxSize
 	if use4844 {
 		maxSize = config.Max4844BatchSize
@@ -735,8 +784,7 @@ func newBatchSegments(firstDelayed uint64, config *BatchPosterConfig, backlog ui
 		maxSize -= 40
 	}
 	compressedBuffer := bytes.NewBuffer(make([]byte, 0, maxSize*2))
-	compressionLevel := config.CompressionLevel
-	recompressionLevel := config.CompressionLevel
+	recompressionLevel := compressionLevel
 	if backlog > 20 {
 		compressionLevel = arbmath.MinInt(compressionLevel, brotli.DefaultCompression)
 	}
@@ -761,6 +809,7 @@ func newBatchSegments(firstDelayed uint64, config *BatchPosterConfig, backlog ui
 		sizeLimit:          maxSize,
 		recompressionLevel: recompressionLevel,
 		rawSegments:        make([][]byte, 0, 128),
+		dictionary:         dictionary,
 		delayedMsg:         firstDelayed,
 	}
 }
@@ -817,10 +866,12 @@ func (s *batchSegments) testForOverflow(isHeader bool) (bool, error) {
 func (s *batchSegments) close() error {
 	s.rawSegments = s.rawSegments[:len(s.rawSegments)-s.trailingHeaders]
 	s.trailingHeaders = 0
+	start := time.Now()
 	err := s.recompressAll()
 	if err != nil {
 		return err
 	}
+	s.recompressionTime = time.Since(start)
 	s.isDone = true
 	return nil
 }
@@ -925,6 +976,11 @@ func (s *batchSegments) AddMessage(msg *arbostypes.MessageWithMetadata) (bool, e
 	return s.addL2Msg(msg.Message.L2msg)
 }
 
+// estimatedSize returns an upper bound on the compressed size of the batch so far
+func (s *batchSegments) estimatedSize() int {
+	return s.lastCompressedSize + s.newUncompressedSize
+}
+
 func (s *batchSegments) IsDone() bool {
 	return s.isDone
 }
@@ -945,12 +1001,39 @@ func (s *batchSegments) CloseAndGetBytes() ([]byte, error) {
 		return nil, err
 	}
 	compressedBytes := s.compressedBuffer.Bytes()
-	fullMsg := make([]byte, 1, len(compressedBytes)+1)
-	fullMsg[0] = daprovider.BrotliMessageHeaderByte
+	header := []byte{daprovider.BrotliMessageHeaderByte}
+	if s.dictionary != nil {
+		compressed, err := s.compressWithDictionary()
+		if err != nil {
+			return nil, err
+		}
+		// the header commits to the dictionary's hash, which must be paid for by the better compression
+		if len(compressed)+common.HashLength < len(compressedBytes) {
+			header = append([]byte{daprovider.BrotliDictionaryMessageHeaderByte}, crypto.Keccak256(s.dictionary)...)
+			compressedBytes = compressed
+		}
+	}
+	fullMsg := make([]byte, 0, len(header)+len(compressedBytes))
+	fullMsg = append(fullMsg, header...)
 	fullMsg = append(fullMsg, compressedBytes...)
 	return fullMsg, nil
 }
 
+// compressWithDictionary compresses the segments again with the dictionary, which the streaming compressor
+// used to fill the batch doesn't support.
+func (s *batchSegments) compressWithDictionary() ([]byte, error) {
+	var encoded []byte
+	for _, segment := range s.rawSegments {
+		item, err := rlp.EncodeToBytes(segment)
+		if err != nil {
+			return nil, err
+		}
+		encoded = append(encoded, item...)
+	}
+	// #nosec G115
+	return arbcompress.CompressWithRawDictionary(encoded, uint32(s.recompressionLevel), s.dictionary)
+}
+
 func (b *BatchPoster) encodeAddBatch(
 	seqNum *big.Int,
 	prevMsgNum arbutil.MessageIndex,
@@ -1095,7 +1178,24 @@ func (b *BatchPoster) estimateGas(ctx context.Context, sequencerMessage []byte,
 	return gas + config.ExtraBatchGas, nil
 }
 
-const ethPosBlockTime = 12 * time.Second
+// parentChainTiming returns the parent chain's cadence as the chain owner set it, which is Ethereum's by default.
+// batchDictionaryCommitted returns whether a batch starting at msgCount may be compressed with the dictionary,
+// which the chain must have committed to before the batch's first message.
+func (b *BatchPoster) batchDictionaryCommitted(msgCount arbutil.MessageIndex, hash common.Hash) (bool, error) {
+	if msgCount == 0 {
+		return false, nil
+	}
+	return b.arbOSVersionGetter.BatchDictionaryCommitted(msgCount-1, hash)
+}
+
+func (b *BatchPoster) parentChainTiming() execution.ParentChainTiming {
+	timing, err := b.arbOSVersionGetter.ParentChainTiming()
+	if err != nil {
+		log.Warn("error getting parent chain timing; assuming a 12 second block time", "err", err)
+		return execution.ParentChainTiming{BlockTime: 12 * time.Second}
+	}
+	return timing
+}
 
 var errAttemptLockFailed = errors.New("failed to acquire lock; either another batch poster posted a batch or this node fell behind")
 
@@ -1111,6 +1211,9 @@ func (b *BatchPoster) maybePostSequencerBatch(ctx context.Context) (bool, error)
 	if err := rlp.DecodeBytes(batchPositionBytes, &batchPosition); err != nil {
 		return false, fmt.Errorf("decoding batch position: %w", err)
 	}
+	if err := b.repostDroppedBatches(ctx, batchPosition.NextSeqNum); err != nil {
+		return false, err
+	}
 
 	dbBatchCount, err := b.inbox.GetBatchCount()
 	if err != nil {
@@ -1132,7 +1235,9 @@ func (b *BatchPoster) maybePostSequencerBatch(ctx context.Context) (bool, error)
 				return false, err
 			}
 			if arbOSVersion >= 20 {
-				if config.IgnoreBlobPrice {
+				if config.PostingPolicy.dataPreference == dataPreferenceCalldata {
+					use4844 = false
+				} else if config.IgnoreBlobPrice || config.PostingPolicy.dataPreference == dataPreferenceBlobs {
 					use4844 = true
 				} else {
 					backlog := b.backlog.Load()
@@ -1144,19 +1249,37 @@ func (b *BatchPoster) maybePostSequencerBatch(ctx context.Context) (bool, error)
 					if backlog == 0 ||
 						b.non4844BatchCount == 0 ||
 						b.non4844BatchCount > 16 {
-						blobFeePerByte := eip4844.CalcBlobFee(eip4844.CalcExcessBlobGas(*latestHeader.ExcessBlobGas, *latestHeader.BlobGasUsed))
-						blobFeePerByte.Mul(blobFeePerByte, blobTxBlobGasPerBlob)
-						blobFeePerByte.Div(blobFeePerByte, usableBytesInBlob)
-
-						calldataFeePerByte := arbmath.BigMulByUint(latestHeader.BaseFee, 16)
-						use4844 = arbmath.BigLessThan(blobFeePerByte, calldataFeePerByte)
+						prices, err := b.l1PriceOracle.Prices(ctx, latestHeader)
+						if err != nil {
+							return false, err
+						}
+						use4844 = prices.BlobsAreCheaper()
 					}
 				}
 			}
 		}
 
+		var dictionary []byte
+		if hash := b.config().compressionDictionary; hash != (common.Hash{}) {
+			if b.dictionaries == nil {
+				return false, errors.New("batch compression dictionary configured without any batch dictionaries")
+			}
+			committed, err := b.batchDictionaryCommitted(batchPosition.MessageCount, hash)
+			if err != nil {
+				return false, err
+			}
+			if committed {
+				dictionary, err = b.dictionaries.GetDictionary(ctx, hash, nil)
+				if err != nil {
+					return false, err
+				}
+			} else {
+				// the batch would be invalid
+				log.Warn("not compressing the batch with a dictionary the chain hasn't committed to", "dictionary", hash)
+			}
+		}
 		b.building = &buildingBatch{
-			segments:      newBatchSegments(batchPosition.DelayedMessageCount, b.config(), b.GetBacklogEstimate(), use4844),
+			segments:      newBatchSegments(batchPosition.DelayedMessageCount, b.config(), b.compressionTuner.Level(b.config()), b.GetBacklogEstimate(), use4844, dictionary),
 			msgCount:      batchPosition.MessageCount,
 			startMsgCount: batchPosition.MessageCount,
 			use4844:       use4844,
@@ -1189,7 +1312,6 @@ func (b *BatchPoster) maybePostSequencerBatch(ctx context.Context) (bool, error)
 	}
 
 	config := b.config()
-	forcePostBatch := config.MaxDelay <= 0 || time.Since(firstMsgTime) >= config.MaxDelay
 
 	var l1BoundMaxBlockNumber uint64 = math.MaxUint64
 	var l1BoundMaxTimestamp uint64 = math.MaxUint64
@@ -1197,6 +1319,7 @@ func (b *BatchPoster) maybePostSequencerBatch(ctx context.Context) (bool, error)
 	var l1BoundMinTimestamp uint64
 	var l1BoundMinBlockNumberWithBypass uint64
 	var l1BoundMinTimestampWithBypass uint64
+	var maxDeferral time.Duration
 	hasL1Bound := config.l1BlockBound != l1BlockBoundIgnore
 	if hasL1Bound {
 		var l1Bound *types.Header
@@ -1249,15 +1372,27 @@ func (b *BatchPoster) maybePostSequencerBatch(ctx context.Context) (bool, error)
 		l1BoundMinBlockNumber = arbmath.SaturatingUSub(latestBlockNumber, arbmath.BigToUintSaturating(maxTimeVariationDelayBlocks))
 		l1BoundMinTimestamp = arbmath.SaturatingUSub(latestHeader.Time, arbmath.BigToUintSaturating(maxTimeVariationDelaySeconds))
 
+		// the posting policy mustn't defer a batch past the delay bounds, less the margin within which it's refused
+		durationOf := func(count *big.Int, unit time.Duration) time.Duration {
+			// #nosec G115
+			return time.Duration(arbmath.MinInt(arbmath.BigToUintSaturating(count), uint64(math.MaxInt64/unit))) * unit
+		}
+		delayBound := durationOf(maxTimeVariationDelaySeconds, time.Second)
+		if blockTime := b.parentChainTiming().BlockTime; blockTime > 0 {
+			delayBound = arbmath.MinInt(delayBound, durationOf(maxTimeVariationDelayBlocks, blockTime))
+		}
+		maxDeferral = arbmath.MaxInt(delayBound-config.ReorgResistanceMargin, time.Nanosecond)
+
 		if config.L1BlockBoundBypass > 0 {
-			blockNumberWithPadding := arbmath.SaturatingUAdd(latestBlockNumber, uint64(config.L1BlockBoundBypass/ethPosBlockTime))
+			blockNumberWithPadding := arbmath.SaturatingUAdd(latestBlockNumber, b.parentChainTiming().Blocks(config.L1BlockBoundBypass))
 			timestampWithPadding := arbmath.SaturatingUAdd(latestHeader.Time, uint64(config.L1BlockBoundBypass/time.Second))
 			l1BoundMinBlockNumberWithBypass = arbmath.SaturatingUSub(blockNumberWithPadding, arbmath.BigToUintSaturating(maxTimeVariationDelayBlocks))
 			l1BoundMinTimestampWithBypass = arbmath.SaturatingUSub(timestampWithPadding, arbmath.BigToUintSaturating(maxTimeVariationDelaySeconds))
 		}
 	}
 
-	for b.building.msgCount < msgCount {
+	// a full batch can be held back by the posting policy, but can't take more messages
+	for b.building.msgCount < msgCount && !b.building.segments.IsDone() {
 		msg, err := b.streamer.GetMessage(b.building.msgCount)
 		if err != nil {
 			log.Error("error getting message from streamer", "error", err)
@@ -1295,9 +1430,6 @@ func (b *BatchPoster) maybePostSequencerBatch(ctx context.Context) (bool, error)
 		}
 		if !success {
 			// this batch is full
-			if !config.WaitForMaxDelay {
-				forcePostBatch = true
-			}
 			b.building.haveUsefulMessage = true
 			break
 		}
@@ -1316,7 +1448,7 @@ func (b *BatchPoster) maybePostSequencerBatch(ctx context.Context) (bool, error)
 	if hasL1Bound && config.ReorgResistanceMargin > 0 {
 		firstMsgBlockNumber := firstMsg.Message.Header.BlockNumber
 		firstMsgTimeStamp := firstMsg.Message.Header.Timestamp
-		batchNearL1BoundMinBlockNumber := firstMsgBlockNumber <= arbmath.SaturatingUAdd(l1BoundMinBlockNumber, uint64(config.ReorgResistanceMargin/ethPosBlockTime))
+		batchNearL1BoundMinBlockNumber := firstMsgBlockNumber <= arbmath.SaturatingUAdd(l1BoundMinBlockNumber, b.parentChainTiming().Blocks(config.ReorgResistanceMargin))
 		batchNearL1BoundMinTimestamp := firstMsgTimeStamp <= arbmath.SaturatingUAdd(l1BoundMinTimestamp, uint64(config.ReorgResistanceMargin/time.Second))
 		if batchNearL1BoundMinTimestamp || batchNearL1BoundMinBlockNumber {
 			log.Error(
@@ -1331,8 +1463,35 @@ func (b *BatchPoster) maybePostSequencerBatch(ctx context.Context) (bool, error)
 		}
 	}
 
-	if !forcePostBatch || !b.building.haveUsefulMessage {
-		// the batch isn't full yet and we've posted a batch recently
+	if !b.building.haveUsefulMessage {
+		return false, nil
+	}
+	state := &postingState{
+		firstMsgTime: firstMsgTime,
+		full:         b.building.segments.IsDone(),
+		size:         b.building.segments.estimatedSize(),
+		use4844:      b.building.use4844,
+		maxDeferral:  maxDeferral,
+	}
+	if hasL1Bound {
+		// post while there's still a reorg resistance margin to spare before the batch would be refused
+		nearMargin := 2 * config.ReorgResistanceMargin
+		state.nearDelayBound = firstMsg.Message.Header.BlockNumber <= arbmath.SaturatingUAdd(l1BoundMinBlockNumber, b.parentChainTiming().Blocks(nearMargin)) ||
+			firstMsg.Message.Header.Timestamp <= arbmath.SaturatingUAdd(l1BoundMinTimestamp, uint64(nearMargin/time.Second))
+	}
+	if b.postingPolicy.needsPrices(config) {
+		latestHeader, err := b.l1Reader.LastHeader(ctx)
+		if err != nil {
+			return false, err
+		}
+		prices, err := b.l1PriceOracle.Prices(ctx, latestHeader)
+		if err != nil {
+			return false, err
+		}
+		state.prices = &prices
+	}
+	if !b.postingPolicy.decide(config, state).post {
+		// the batch isn't ready yet or its posting is deferred
 		// don't post anything for now
 		return false, nil
 	}
@@ -1341,6 +1500,10 @@ func (b *BatchPoster) maybePostSequencerBatch(ctx context.Context) (bool, error)
 	if err != nil {
 		return false, err
 	}
+	if sequencerMsg != nil {
+		segments := b.building.segments
+		b.compressionTuner.Observe(config, segments.recompressionLevel, segments.recompressionTime, segments.totalUncompressedSize, len(sequencerMsg)-1)
+	}
 	if sequencerMsg == nil {
 		log.Debug("BatchPoster: batch nil", "sequence nr.", batchPosition.NextSeqNum, "from", batchPosition.MessageCount, "prev delayed", batchPosition.DelayedMessageCount)
 		b.building = nil // a closed batchSegments can't be reused
@@ -1420,11 +1583,12 @@ func (b *BatchPoster) maybePostSequencerBatch(ctx context.Context) (bool, error)
 	if err != nil {
 		return false, err
 	}
-	newMeta, err := rlp.EncodeToBytes(batchPosterPosition{
+	newPosition := batchPosterPosition{
 		MessageCount:        b.building.msgCount,
 		DelayedMessageCount: b.building.segments.delayedMsg,
 		NextSeqNum:          batchPosition.NextSeqNum + 1,
-	})
+	}
+	newMeta, err := rlp.EncodeToBytes(newPosition)
 	if err != nil {
 		return false, err
 	}
@@ -1443,7 +1607,13 @@ func (b *BatchPoster) maybePostSequencerBatch(ctx context.Context) (bool, error)
 		b.building.muxBackend.seqMsg = seqMsg
 		b.building.muxBackend.delayedInboxStart = batchPosition.DelayedMessageCount
 		b.building.muxBackend.SetPositionWithinMessage(0)
-		simMux := arbstate.NewInboxMultiplexer(b.building.muxBackend, batchPosition.DelayedMessageCount, dapReaders, daprovider.KeysetValidate)
+		dictionaries := &daprovider.CommittedDictionaries{
+			Committed: func(hash common.Hash) (bool, error) {
+				return b.batchDictionaryCommitted(batchPosition.MessageCount, hash)
+			},
+			Reader: b.dictionaries,
+		}
+		simMux := arbstate.NewInboxMultiplexer(b.building.muxBackend, batchPosition.DelayedMessageCount, dapReaders, dictionaries, daprovider.KeysetValidate)
 		log.Debug("Begin checking the correctness of batch against inbox multiplexer", "startMsgSeqNum", batchPosition.MessageCount, "endMsgSeqNum", b.building.msgCount-1)
 		for i := batchPosition.MessageCount; i < b.building.msgCount; i++ {
 			msg, err := simMux.Pop(ctx)
@@ -1477,6 +1647,9 @@ func (b *BatchPoster) maybePostSequencerBatch(ctx context.Context) (bool, error)
 		return false, err
 	}
 	b.postedFirstBatch = true
+	if err := b.recordPostedBatch(tx, batchPosition, newPosition); err != nil {
+		log.Warn("BatchPoster: failed to record posted batch, it can't be reposted if a reorg drops it", "sequenceNumber", batchPosition.NextSeqNum, "err", err)
+	}
 	log.Info(
 		"BatchPoster: batch sent",
 		"sequenceNumber", batchPosition.NextSeqNum,
@@ -1692,3 +1865,40 @@ func (b *BoolRing) All(value bool) bool {
 	}
 	return true
 }
+
+// dasStoreReportLogger is the batch poster's DAS writer when the writer can report how each committee member
+// handled a store, logging that report for every batch it stores.
+type dasStoreReportLogger struct {
+	daprovider.DASWriter
+	reporter das.StoreReporter
+}
+
+func newDASStoreReportLogger(writer daprovider.DASWriter) daprovider.DASWriter {
+	reporter, ok := writer.(das.StoreReporter)
+	if !ok {
+		return writer
+	}
+	return &dasStoreReportLogger{DASWriter: writer, reporter: reporter}
+}
+
+func (w *dasStoreReportLogger) Store(ctx context.Context, message []byte, timeout uint64) (*daprovider.DataAvailabilityCertificate, error) {
+	cert, report, err := w.reporter.StoreWithReport(ctx, message, timeout)
+	if report != nil {
+		logger := log.Info
+		if err != nil || len(report.Failed()) > 0 {
+			logger = log.Warn
+		}
+		logger(
+			"DAS store report for batch",
+			"dataHash", report.DataHash,
+			"stored", report.StoredCount,
+			"required", report.RequiredCount,
+			"storedWeight", report.StoredWeight,
+			"requiredWeight", report.RequiredWeight,
+			"failed", report.Failed(),
+			"noResponse", report.Pending(),
+			"err", err,
+		)
+	}
+	return cert, err
+}
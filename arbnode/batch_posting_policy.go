@@ -0,0 +1,262 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbnode
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/offchainlabs/nitro/util/arbmath"
+	"github.com/offchainlabs/nitro/util/l1price"
+)
+
+// Reasons for the batch poster's posting decisions, which are logged and counted under arb/batchposter/policy.
+const (
+	postingReasonWaiting    = "waiting"
+	postingReasonMaxDelay   = "max_delay"
+	postingReasonFull       = "full"
+	postingReasonMinSize    = "min_size"
+	postingReasonDeadline   = "deadline"
+	postingReasonQuietHours = "quiet_hours"
+	postingReasonFeeCeiling = "fee_ceiling"
+	postingReasonDelayBound = "delay_bound"
+)
+
+var (
+	postingDecisionCounters = map[string]metrics.Counter{}
+	postingDeferredGauge    = metrics.NewRegisteredGauge("arb/batchposter/policy/deferred", nil)
+)
+
+func init() {
+	for _, reason := range []string{
+		postingReasonWaiting, postingReasonMaxDelay, postingReasonFull, postingReasonMinSize,
+		postingReasonDeadline, postingReasonQuietHours, postingReasonFeeCeiling, postingReasonDelayBound,
+	} {
+		postingDecisionCounters[reason] = metrics.NewRegisteredCounter("arb/batchposter/policy/"+reason, nil)
+	}
+}
+
+type dataPreference uint8
+
+const (
+	dataPreferenceCheapest dataPreference = iota
+	dataPreferenceBlobs
+	dataPreferenceCalldata
+)
+
+// PostingPolicyConfig configures when the batch poster posts the batch it's building, and in what form.
+// A batch is ready once its first message is max-delay old, or it's full or min-size large unless
+// wait-for-max-delay is set. A ready batch is then deferred during quiet hours or while parent chain data is
+// priced over the ceiling, until its first message is deferral-deadline old.
+type PostingPolicyConfig struct {
+	MinSize           int           `koanf:"min-size" reload:"hot"`
+	MaxL1GasPriceGwei float64       `koanf:"max-l1-gas-price-gwei" reload:"hot"`
+	QuietHours        []string      `koanf:"quiet-hours" reload:"hot"`
+	DeferralDeadline  time.Duration `koanf:"deferral-deadline" reload:"hot"`
+	DataPreference    string        `koanf:"data-preference" reload:"hot"`
+
+	quietHours     []quietHours
+	dataPreference dataPreference
+}
+
+var DefaultPostingPolicyConfig = PostingPolicyConfig{
+	MinSize:           0,
+	MaxL1GasPriceGwei: 0,
+	QuietHours:        []string{},
+	DeferralDeadline:  2 * time.Hour,
+	DataPreference:    "cheapest",
+}
+
+func PostingPolicyConfigAddOptions(prefix string, f *pflag.FlagSet) {
+	f.Int(prefix+".min-size", DefaultPostingPolicyConfig.MinSize, "post a batch before the max delay once its estimated compressed size reaches this many bytes (0 = only when full)")
+	f.Float64(prefix+".max-l1-gas-price-gwei", DefaultPostingPolicyConfig.MaxL1GasPriceGwei, "defer posting while the parent chain price of the batch's data, in gwei per unit of calldata gas, is above this (0 = no ceiling)")
+	f.StringSlice(prefix+".quiet-hours", DefaultPostingPolicyConfig.QuietHours, "UTC time ranges, as HH:MM-HH:MM, in which posting is deferred")
+	f.Duration(prefix+".deferral-deadline", DefaultPostingPolicyConfig.DeferralDeadline, "age of a batch's first message after which the batch is posted despite quiet hours and the gas price ceiling")
+	f.String(prefix+".data-preference", DefaultPostingPolicyConfig.DataPreference, "what to post batches as when 4844 blobs are enabled (\"cheapest\", \"blobs\", or \"calldata\")")
+}
+
+func (c *PostingPolicyConfig) Validate() error {
+	if c.MinSize < 0 {
+		return errors.New("batch posting min size must not be negative")
+	}
+	if c.MaxL1GasPriceGwei < 0 {
+		return errors.New("batch posting max L1 gas price must not be negative")
+	}
+	c.quietHours = nil
+	for _, hours := range c.QuietHours {
+		parsed, err := parseQuietHours(hours)
+		if err != nil {
+			return err
+		}
+		c.quietHours = append(c.quietHours, parsed)
+	}
+	if (len(c.quietHours) > 0 || c.MaxL1GasPriceGwei > 0) && c.DeferralDeadline <= 0 {
+		return errors.New("batch posting deferral deadline must be positive with quiet hours or a gas price ceiling")
+	}
+	switch strings.ToLower(c.DataPreference) {
+	case "", "cheapest":
+		c.dataPreference = dataPreferenceCheapest
+	case "blobs":
+		c.dataPreference = dataPreferenceBlobs
+	case "calldata":
+		c.dataPreference = dataPreferenceCalldata
+	default:
+		return fmt.Errorf("invalid batch data preference \"%v\" (see --help for options)", c.DataPreference)
+	}
+	return nil
+}
+
+// quietHours is a daily UTC time range, as offsets from midnight, which wraps past midnight if end < start.
+type quietHours struct {
+	start time.Duration
+	end   time.Duration
+}
+
+func parseQuietHours(hours string) (quietHours, error) {
+	start, end, found := strings.Cut(hours, "-")
+	if !found {
+		return quietHours{}, fmt.Errorf("invalid quiet hours \"%v\", expected HH:MM-HH:MM", hours)
+	}
+	var parsed quietHours
+	for _, bound := range []struct {
+		text string
+		dest *time.Duration
+	}{{start, &parsed.start}, {end, &parsed.end}} {
+		clock, err := time.Parse("15:04", strings.TrimSpace(bound.text))
+		if err != nil {
+			return quietHours{}, fmt.Errorf("invalid quiet hours \"%v\": %w", hours, err)
+		}
+		*bound.dest = time.Duration(clock.Hour())*time.Hour + time.Duration(clock.Minute())*time.Minute
+	}
+	if parsed.start == parsed.end {
+		return quietHours{}, fmt.Errorf("invalid quiet hours \"%v\", the range is empty", hours)
+	}
+	return parsed, nil
+}
+
+func (q quietHours) contains(now time.Time) bool {
+	now = now.UTC()
+	sinceMidnight := now.Sub(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC))
+	if q.start < q.end {
+		return sinceMidnight >= q.start && sinceMidnight < q.end
+	}
+	return sinceMidnight >= q.start || sinceMidnight < q.end
+}
+
+// postingState is what the posting policy knows about the batch being built.
+type postingState struct {
+	firstMsgTime time.Time
+	full         bool
+	size         int             // estimated compressed size
+	use4844      bool            // whether the batch is being built for blobs
+	prices       *l1price.Prices // nil if they weren't needed
+	// the longest the sequencer inbox's delay bounds, less the reorg resistance margin, let the batch be deferred,
+	// or 0 if the batch poster doesn't check the bounds
+	maxDeferral time.Duration
+	// whether the first message is close enough to the delay bounds that the batch must be posted now
+	nearDelayBound bool
+}
+
+type postingDecision struct {
+	post   bool
+	reason string
+}
+
+// batchPostingPolicy decides whether to post the batch being built. It's only used by the batch poster's main
+// loop, so it isn't thread safe.
+type batchPostingPolicy struct {
+	lastReason string
+	now        func() time.Time
+}
+
+func newBatchPostingPolicy() *batchPostingPolicy {
+	return &batchPostingPolicy{now: time.Now}
+}
+
+// needsPrices returns whether decide needs the parent chain's prices.
+func (p *batchPostingPolicy) needsPrices(config *BatchPosterConfig) bool {
+	return config.PostingPolicy.MaxL1GasPriceGwei > 0
+}
+
+// decide returns whether to post the batch now, and why. The decision is counted, and logged when its reason
+// differs from the last one's.
+func (p *batchPostingPolicy) decide(config *BatchPosterConfig, state *postingState) postingDecision {
+	decision := p.evaluate(config, state)
+	postingDecisionCounters[decision.reason].Inc(1)
+	if decision.reason == postingReasonQuietHours || decision.reason == postingReasonFeeCeiling {
+		postingDeferredGauge.Update(1)
+	} else {
+		postingDeferredGauge.Update(0)
+	}
+	if decision.reason != p.lastReason {
+		logger := log.Debug
+		if !decision.post && decision.reason != postingReasonWaiting {
+			logger = log.Info
+		}
+		logger("batch posting decision", "post", decision.post, "reason", decision.reason, "firstMsgAge", p.now().Sub(state.firstMsgTime), "size", state.size)
+		p.lastReason = decision.reason
+	}
+	return decision
+}
+
+func (p *batchPostingPolicy) evaluate(config *BatchPosterConfig, state *postingState) postingDecision {
+	policy := &config.PostingPolicy
+	now := p.now()
+	age := now.Sub(state.firstMsgTime)
+
+	var ready postingDecision
+	switch {
+	case config.MaxDelay <= 0 || age >= config.MaxDelay:
+		ready = postingDecision{true, postingReasonMaxDelay}
+	case state.full && !config.WaitForMaxDelay:
+		ready = postingDecision{true, postingReasonFull}
+	case policy.MinSize > 0 && state.size >= policy.MinSize && !config.WaitForMaxDelay:
+		ready = postingDecision{true, postingReasonMinSize}
+	default:
+		return postingDecision{false, postingReasonWaiting}
+	}
+
+	if state.nearDelayBound {
+		return postingDecision{true, postingReasonDelayBound}
+	}
+	if len(policy.quietHours) == 0 && policy.MaxL1GasPriceGwei <= 0 {
+		return ready
+	}
+	deadline := policy.DeferralDeadline
+	if state.maxDeferral > 0 && state.maxDeferral < deadline {
+		deadline = state.maxDeferral
+	}
+	if age >= deadline {
+		return postingDecision{true, postingReasonDeadline}
+	}
+	for _, hours := range policy.quietHours {
+		if hours.contains(now) {
+			return postingDecision{false, postingReasonQuietHours}
+		}
+	}
+	if policy.MaxL1GasPriceGwei > 0 && state.prices != nil {
+		ceiling := arbmath.FloatToBig(policy.MaxL1GasPriceGwei * params.GWei)
+		if arbmath.BigGreaterThan(dataGasPrice(state.prices, state.use4844), ceiling) {
+			return postingDecision{false, postingReasonFeeCeiling}
+		}
+	}
+	return ready
+}
+
+// dataGasPrice returns the price of posting the batch's data per unit of calldata gas, a byte of which costs 16.
+func dataGasPrice(prices *l1price.Prices, use4844 bool) *big.Int {
+	if use4844 && prices.BlobFeePerByte != nil {
+		return new(big.Int).Div(prices.BlobFeePerByte, big.NewInt(16))
+	}
+	return prices.BaseFee
+}
@@ -0,0 +1,94 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbnode
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/offchainlabs/nitro/util/l1price"
+)
+
+func TestBatchPostingPolicy(t *testing.T) {
+	config := DefaultBatchPosterConfig
+	config.MaxDelay = time.Hour
+	config.PostingPolicy.MinSize = 10_000
+	config.PostingPolicy.MaxL1GasPriceGwei = 50
+	config.PostingPolicy.QuietHours = []string{"22:00-02:00"}
+	config.PostingPolicy.DeferralDeadline = 3 * time.Hour
+	Require(t, config.Validate())
+
+	noon := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	policy := newBatchPostingPolicy()
+	policy.now = func() time.Time { return noon }
+	cheap := &l1price.Prices{BaseFee: big.NewInt(10 * params.GWei)}
+	expensive := &l1price.Prices{BaseFee: big.NewInt(100 * params.GWei), BlobFeePerByte: big.NewInt(16 * params.GWei)}
+
+	expect := func(state *postingState, post bool, reason string) {
+		t.Helper()
+		decision := policy.decide(&config, state)
+		if decision.post != post || decision.reason != reason {
+			Fail(t, "wrong posting decision", decision, "expected", post, reason)
+		}
+	}
+
+	// small and recent batches wait, full, large and old ones are ready
+	expect(&postingState{firstMsgTime: noon.Add(-time.Minute), size: 100, prices: cheap}, false, postingReasonWaiting)
+	expect(&postingState{firstMsgTime: noon.Add(-time.Minute), size: 100, full: true, prices: cheap}, true, postingReasonFull)
+	expect(&postingState{firstMsgTime: noon.Add(-time.Minute), size: 20_000, prices: cheap}, true, postingReasonMinSize)
+	expect(&postingState{firstMsgTime: noon.Add(-2 * time.Hour), size: 100, prices: cheap}, true, postingReasonMaxDelay)
+
+	// ready batches are deferred while data is expensive, unless they're in blobs that are cheap enough
+	expect(&postingState{firstMsgTime: noon.Add(-time.Minute), full: true, prices: expensive}, false, postingReasonFeeCeiling)
+	expect(&postingState{firstMsgTime: noon.Add(-time.Minute), full: true, use4844: true, prices: expensive}, true, postingReasonFull)
+	expect(&postingState{firstMsgTime: noon.Add(-4 * time.Hour), full: true, prices: expensive}, true, postingReasonDeadline)
+
+	// the delay bounds cap the deadline, and batches close to them are posted right away
+	expect(&postingState{firstMsgTime: noon.Add(-2 * time.Hour), full: true, prices: expensive, maxDeferral: 3 * time.Hour}, false, postingReasonFeeCeiling)
+	expect(&postingState{firstMsgTime: noon.Add(-2 * time.Hour), full: true, prices: expensive, maxDeferral: time.Hour}, true, postingReasonDeadline)
+	expect(&postingState{firstMsgTime: noon.Add(-time.Minute), size: 100, prices: expensive, nearDelayBound: true}, true, postingReasonDelayBound)
+
+	// quiet hours wrap around midnight
+	for _, now := range []time.Time{noon.Add(11 * time.Hour), noon.Add(13 * time.Hour)} {
+		policy.now = func() time.Time { return now }
+		expect(&postingState{firstMsgTime: now.Add(-2 * time.Hour), prices: cheap}, false, postingReasonQuietHours)
+	}
+	policy.now = func() time.Time { return noon.Add(15 * time.Hour) }
+	expect(&postingState{firstMsgTime: noon.Add(13 * time.Hour), prices: cheap}, true, postingReasonMaxDelay)
+
+	// waiting for the max delay ignores full batches
+	config.WaitForMaxDelay = true
+	policy.now = func() time.Time { return noon }
+	expect(&postingState{firstMsgTime: noon.Add(-time.Minute), full: true, size: 20_000, prices: cheap}, false, postingReasonWaiting)
+}
+
+func TestPostingPolicyConfig(t *testing.T) {
+	config := DefaultPostingPolicyConfig
+	Require(t, config.Validate())
+	for _, invalid := range []string{"22:00", "25:00-01:00", "10:00-10:00", "noon-midnight"} {
+		config.QuietHours = []string{invalid}
+		if config.Validate() == nil {
+			Fail(t, "invalid quiet hours validated", invalid)
+		}
+	}
+	config.QuietHours = []string{"09:30-17:00"}
+	Require(t, config.Validate())
+	config.DeferralDeadline = 0
+	if config.Validate() == nil {
+		Fail(t, "quiet hours without a deferral deadline validated")
+	}
+	config = DefaultPostingPolicyConfig
+	config.DataPreference = "carrier pigeon"
+	if config.Validate() == nil {
+		Fail(t, "an invalid data preference validated")
+	}
+	config.DataPreference = "calldata"
+	Require(t, config.Validate())
+	if config.dataPreference != dataPreferenceCalldata {
+		Fail(t, "wrong data preference", config.dataPreference)
+	}
+}
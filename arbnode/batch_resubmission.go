@@ -0,0 +1,229 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbnode
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/offchainlabs/nitro/util/arbmath"
+	"github.com/offchainlabs/nitro/util/dbutil"
+)
+
+var (
+	batchPosterDroppedBatchesGauge  = metrics.NewRegisteredGauge("arb/batchposter/reorg/dropped", nil)
+	batchPosterRepostedBatchCounter = metrics.NewRegisteredCounter("arb/batchposter/reorg/reposted", nil)
+)
+
+var (
+	errDeepReorg            = errors.New("a parent chain reorg dropped more batches than the batch poster reposts by itself")
+	errDroppedBatchDiverged = errors.New("batch dropped by a parent chain reorg no longer continues the parent chain's sequencer inbox")
+)
+
+type BatchResubmissionConfig struct {
+	Enable        bool   `koanf:"enable"`
+	MaxReorgDepth uint64 `koanf:"max-reorg-depth" reload:"hot"`
+}
+
+var DefaultBatchResubmissionConfig = BatchResubmissionConfig{
+	Enable:        true,
+	MaxReorgDepth: 16,
+}
+
+func BatchResubmissionConfigAddOptions(prefix string, f *pflag.FlagSet) {
+	f.Bool(prefix+".enable", DefaultBatchResubmissionConfig.Enable, "repost batches that a parent chain reorg drops after the data poster stopped tracking them")
+	f.Uint64(prefix+".max-reorg-depth", DefaultBatchResubmissionConfig.MaxReorgDepth, "most dropped batches to repost; batch posting halts after deeper reorgs until an operator intervenes")
+}
+
+// postedBatch is the batch poster's record of a batch it posted, kept until the batch is finalized on the parent
+// chain so that the exact same transaction can be posted again if a reorg drops it.
+type postedBatch struct {
+	Tx    []byte // the signed transaction, with its blobs if any
+	Start batchPosterPosition
+	End   batchPosterPosition
+}
+
+func postedBatchKey(seqNum uint64) []byte {
+	return binary.BigEndian.AppendUint64(nil, seqNum)
+}
+
+func (b *BatchPoster) recordPostedBatch(tx *types.Transaction, start batchPosterPosition, end batchPosterPosition) error {
+	if b.postedBatches == nil {
+		return nil
+	}
+	encodedTx, err := tx.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	value, err := rlp.EncodeToBytes(postedBatch{Tx: encodedTx, Start: start, End: end})
+	if err != nil {
+		return err
+	}
+	return b.postedBatches.Put(postedBatchKey(start.NextSeqNum), value)
+}
+
+// readPostedBatch returns nil if there's no record of the batch.
+func (b *BatchPoster) readPostedBatch(seqNum uint64) (*postedBatch, *types.Transaction, error) {
+	value, err := b.postedBatches.Get(postedBatchKey(seqNum))
+	if dbutil.IsErrNotFound(err) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	var record postedBatch
+	if err := rlp.DecodeBytes(value, &record); err != nil {
+		return nil, nil, fmt.Errorf("decoding record of posted batch %v: %w", seqNum, err)
+	}
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(record.Tx); err != nil {
+		return nil, nil, fmt.Errorf("decoding transaction of posted batch %v: %w", seqNum, err)
+	}
+	return &record, tx, nil
+}
+
+// prunePostedBatches deletes the records of batches before the given sequence number.
+func (b *BatchPoster) prunePostedBatches(before uint64) error {
+	end := postedBatchKey(before)
+	it := b.postedBatches.NewIterator(nil, nil)
+	defer it.Release()
+	batch := b.postedBatches.NewBatch()
+	for it.Next() {
+		if bytes.Compare(it.Key(), end) >= 0 {
+			break
+		}
+		if err := batch.Delete(it.Key()); err != nil {
+			return err
+		}
+	}
+	return batch.Write()
+}
+
+// repostDroppedBatches reposts, exactly as they were first posted, the batches before nextSeqNum that the parent
+// chain's sequencer inbox doesn't have and the data poster no longer tracks, which only happens when a reorg drops
+// them after they were confirmed. Before anything is reposted it checks that the inbox still ends where the first
+// dropped batch starts: the inbox tracker must have caught up with the parent chain's accumulator for the batch
+// before it, and that batch must end at the message and delayed message counts the dropped batch starts from.
+// Reposting can't post a batch twice, as only one transaction can take the nonce and the sequencer inbox rejects
+// a sequence number it already has.
+func (b *BatchPoster) repostDroppedBatches(ctx context.Context, nextSeqNum uint64) error {
+	config := b.config().Resubmission
+	if b.postedBatches == nil || !config.Enable {
+		return nil
+	}
+	header, err := b.l1Reader.LastHeader(ctx)
+	if err != nil {
+		return err
+	}
+	callOpts := &bind.CallOpts{Context: ctx, BlockNumber: header.Number}
+	bigCount, err := b.seqInbox.BatchCount(callOpts)
+	if err != nil {
+		return err
+	}
+	count := bigCount.Uint64()
+	// Records are kept until the parent chain can no longer reorg their batches out.
+	keepFrom := arbmath.SaturatingUSub(count, config.MaxReorgDepth)
+	if b.l1Reader.UseFinalityData() {
+		finalized, err := b.l1Reader.LatestFinalizedBlockHeader(ctx)
+		if err != nil {
+			return err
+		}
+		finalizedCount, err := b.seqInbox.BatchCount(&bind.CallOpts{Context: ctx, BlockNumber: finalized.Number})
+		if err != nil {
+			return err
+		}
+		keepFrom = finalizedCount.Uint64()
+	}
+	if err := b.prunePostedBatches(keepFrom); err != nil {
+		return err
+	}
+
+	senderNonce, err := b.l1Reader.Client().NonceAt(ctx, b.dataPoster.Sender(), header.Number)
+	if err != nil {
+		return err
+	}
+
+	var records []*postedBatch
+	var txs []*types.Transaction
+	for seqNum := count; seqNum < nextSeqNum; seqNum++ {
+		record, tx, err := b.readPostedBatch(seqNum)
+		if err != nil {
+			return err
+		}
+		if record == nil {
+			log.Warn("BatchPoster: parent chain is missing a batch this node has no record of posting", "sequenceNumber", seqNum)
+			break
+		}
+		if tx.Nonce() < senderNonce {
+			return fmt.Errorf("%w: the nonce %v of batch %v was used by another transaction", errDroppedBatchDiverged, tx.Nonce(), seqNum)
+		}
+		queued, err := b.dataPoster.IsQueued(ctx, tx.Nonce())
+		if err != nil {
+			return err
+		}
+		if queued {
+			// This batch and the ones after it are still being sent by the data poster.
+			break
+		}
+		records = append(records, record)
+		txs = append(txs, tx)
+	}
+	// #nosec G115
+	batchPosterDroppedBatchesGauge.Update(int64(len(records)))
+	if len(records) == 0 {
+		return nil
+	}
+	if uint64(len(records)) > config.MaxReorgDepth {
+		return fmt.Errorf("%w: %v batches from %v were dropped but at most %v are reposted", errDeepReorg, len(records), count, config.MaxReorgDepth)
+	}
+
+	if count > 0 {
+		parentAcc, err := b.seqInbox.InboxAccs(callOpts, new(big.Int).SetUint64(count-1))
+		if err != nil {
+			return err
+		}
+		prevMeta, err := b.inbox.GetBatchMetadata(count - 1)
+		if err != nil {
+			return fmt.Errorf("waiting for the inbox tracker to read batch %v before reposting dropped batches: %w", count-1, err)
+		}
+		if prevMeta.Accumulator != common.Hash(parentAcc) {
+			return fmt.Errorf("waiting for the inbox tracker to catch up with the reorg: its accumulator for batch %v is %v but the parent chain's is %v", count-1, prevMeta.Accumulator, common.Hash(parentAcc))
+		}
+		if prevMeta.MessageCount != records[0].Start.MessageCount || prevMeta.DelayedMessageCount != records[0].Start.DelayedMessageCount {
+			return fmt.Errorf("%w: batch %v ends at message %v and delayed message %v but the dropped batch starts at %v and %v", errDroppedBatchDiverged, count-1, prevMeta.MessageCount, prevMeta.DelayedMessageCount, records[0].Start.MessageCount, records[0].Start.DelayedMessageCount)
+		}
+	}
+	for i := 1; i < len(records); i++ {
+		if records[i].Start != records[i-1].End {
+			return fmt.Errorf("%w: records of batches %v and %v don't follow each other", errDroppedBatchDiverged, count+uint64(i)-1, count+uint64(i))
+		}
+	}
+
+	// The data poster takes reposted transactions from the highest nonce down.
+	for i := len(records) - 1; i >= 0; i-- {
+		meta, err := rlp.EncodeToBytes(records[i].End)
+		if err != nil {
+			return err
+		}
+		if err := b.dataPoster.RepostTransaction(ctx, txs[i], meta); err != nil {
+			return fmt.Errorf("reposting batch %v: %w", count+uint64(i), err)
+		}
+		batchPosterRepostedBatchCounter.Inc(1)
+	}
+	log.Warn("BatchPoster: reposted batches dropped by a parent chain reorg", "from", count, "batches", len(records))
+	return nil
+}
@@ -0,0 +1,61 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbnode
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestPostedBatchRecords(t *testing.T) {
+	b := &BatchPoster{postedBatches: rawdb.NewMemoryDatabase()}
+	key, err := crypto.GenerateKey()
+	Require(t, err)
+	signer := types.LatestSignerForChainID(big.NewInt(1))
+
+	position := batchPosterPosition{MessageCount: 1, DelayedMessageCount: 1, NextSeqNum: 1}
+	var hashes []common.Hash
+	for i := 0; i < 5; i++ {
+		tx, err := types.SignNewTx(key, signer, &types.DynamicFeeTx{
+			ChainID:   big.NewInt(1),
+			Nonce:     uint64(i),
+			GasTipCap: big.NewInt(1),
+			GasFeeCap: big.NewInt(2),
+			Gas:       100_000,
+			Data:      []byte{byte(i)},
+		})
+		Require(t, err)
+		next := batchPosterPosition{
+			MessageCount:        position.MessageCount + 10,
+			DelayedMessageCount: position.DelayedMessageCount + 1,
+			NextSeqNum:          position.NextSeqNum + 1,
+		}
+		Require(t, b.recordPostedBatch(tx, position, next))
+		hashes = append(hashes, tx.Hash())
+		position = next
+	}
+
+	record, tx, err := b.readPostedBatch(3)
+	Require(t, err)
+	if record == nil || tx.Hash() != hashes[2] {
+		Fail(t, "wrong transaction recorded for batch 3")
+	}
+	if record.Start.NextSeqNum != 3 || record.End.NextSeqNum != 4 || record.End.MessageCount != 31 {
+		Fail(t, "wrong positions recorded for batch 3", record.Start, record.End)
+	}
+
+	Require(t, b.prunePostedBatches(4))
+	for seqNum := uint64(1); seqNum <= 5; seqNum++ {
+		record, _, err := b.readPostedBatch(seqNum)
+		Require(t, err)
+		if (record != nil) != (seqNum >= 4) {
+			Fail(t, "pruning kept the wrong records", seqNum, record != nil)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbnode
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/offchainlabs/nitro/arbos/arbostypes"
+	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/util/dbutil"
+)
+
+// A block export is a self-contained file holding a range of blocks: an info record, then for each block its header
+// and the inbox message it was produced from. Headers are authenticated when imported by chaining them to the hash
+// of the last exported block, which the importer must trust. Messages are checked when they're re-executed, which
+// must produce blocks with the authenticated hashes. Bodies and receipts aren't exported, as re-execution recreates
+// them, along with the state of every block.
+const blockExportVersion uint64 = 2
+
+type blockExportInfo struct {
+	Version            uint64
+	GenesisBlockNumber uint64
+	First              uint64
+	Count              uint64
+}
+
+type blockExportEntry struct {
+	Header  rlp.RawValue
+	Message rlp.RawValue
+}
+
+// ExportBlocks writes the headers of blocks first through last of the chain, with their inbox messages, to w.
+func ExportBlocks(chainDb ethdb.Database, arbDb ethdb.Database, genesisBlockNumber uint64, first uint64, last uint64, w io.Writer) error {
+	if first < genesisBlockNumber || last < first {
+		return fmt.Errorf("invalid block range %v to %v", first, last)
+	}
+	info := blockExportInfo{
+		Version:            blockExportVersion,
+		GenesisBlockNumber: genesisBlockNumber,
+		First:              first,
+		Count:              last - first + 1,
+	}
+	if err := rlp.Encode(w, info); err != nil {
+		return err
+	}
+	for number := first; number <= last; number++ {
+		hash := rawdb.ReadCanonicalHash(chainDb, number)
+		if hash == (common.Hash{}) {
+			return fmt.Errorf("block %v not found", number)
+		}
+		entry := blockExportEntry{
+			Header: rawdb.ReadHeaderRLP(chainDb, hash, number),
+		}
+		if len(entry.Header) == 0 {
+			return fmt.Errorf("block %v is missing its header", number)
+		}
+		pos := uint64(arbutil.BlockNumberToMessageCount(number, genesisBlockNumber)) - 1
+		var err error
+		entry.Message, err = arbDb.Get(dbKey(messagePrefix, pos))
+		if err != nil {
+			return fmt.Errorf("reading message %v of block %v: %w", pos, number, err)
+		}
+		if err := rlp.Encode(w, entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeExportedBlock decodes the entry's header, checking it's of the given block, and checks that its message
+// decodes.
+func decodeExportedBlock(entry *blockExportEntry, number uint64) (*types.Header, error) {
+	var header types.Header
+	if err := rlp.DecodeBytes(entry.Header, &header); err != nil {
+		return nil, fmt.Errorf("decoding header of block %v: %w", number, err)
+	}
+	if header.Number == nil || !header.Number.IsUint64() || header.Number.Uint64() != number {
+		return nil, fmt.Errorf("header of block %v has number %v", number, header.Number)
+	}
+	var message arbostypes.MessageWithMetadata
+	if err := rlp.DecodeBytes(entry.Message, &message); err != nil {
+		return nil, fmt.Errorf("decoding message of block %v: %w", number, err)
+	}
+	return &header, nil
+}
+
+// storedBlockHash returns the hash the database has for the message's block, either of the block itself or as
+// received with the message, or the zero hash if it has neither.
+func storedBlockHash(chainDb ethdb.Database, arbDb ethdb.Database, genesisBlockNumber uint64, pos uint64) (common.Hash, error) {
+	number := uint64(arbutil.MessageCountToBlockNumber(arbutil.MessageIndex(pos+1), genesisBlockNumber))
+	if hash := rawdb.ReadCanonicalHash(chainDb, number); hash != (common.Hash{}) {
+		return hash, nil
+	}
+	data, err := arbDb.Get(dbKey(blockHashInputFeedPrefix, pos))
+	if dbutil.IsErrNotFound(err) {
+		return common.Hash{}, nil
+	}
+	if err != nil {
+		return common.Hash{}, err
+	}
+	var value blockHashDBValue
+	if err := rlp.DecodeBytes(data, &value); err != nil || value.BlockHash == nil {
+		return common.Hash{}, err
+	}
+	return *value.BlockHash, nil
+}
+
+// BlockExportOpener opens a block export for reading, and may be called more than once.
+type BlockExportOpener func() (io.ReadCloser, error)
+
+// ImportBlocks authenticates a sequence of block exports and appends the messages of their blocks the database
+// doesn't have yet, along with the blocks' hashes. The node then produces the blocks by executing the messages, and
+// checks that they hash the same. The exports must continue the database's messages and each other, and may overlap
+// with them. lastBlockHash is the hash of the last exported block, which the caller must trust, for instance as the
+// block of an assertion confirmed on the parent chain: the exports are read once to check that their headers chain
+// to it, and again to import them, which fails if they've changed in between.
+// It returns the number of messages added.
+func ImportBlocks(chainDb ethdb.Database, arbDb ethdb.Database, genesisBlockNumber uint64, exports []BlockExportOpener, lastBlockHash common.Hash) (uint64, error) {
+	if lastBlockHash == (common.Hash{}) {
+		return 0, errors.New("the hash of the last exported block is required to authenticate the exports")
+	}
+	digests := make([]common.Hash, len(exports))
+	var parentHash common.Hash
+	for i, open := range exports {
+		var err error
+		digests[i], parentHash, err = importBlockExport(chainDb, arbDb, genesisBlockNumber, open, parentHash, common.Hash{})
+		if err != nil {
+			return 0, fmt.Errorf("export %v: %w", i, err)
+		}
+	}
+	if parentHash != lastBlockHash {
+		return 0, fmt.Errorf("exports end with block %v but expected %v", parentHash, lastBlockHash)
+	}
+	before, err := readMessageCount(arbDb)
+	if err != nil {
+		return 0, err
+	}
+	parentHash = common.Hash{}
+	for i, open := range exports {
+		_, parentHash, err = importBlockExport(chainDb, arbDb, genesisBlockNumber, open, parentHash, digests[i])
+		if err != nil {
+			return 0, fmt.Errorf("export %v: %w", i, err)
+		}
+	}
+	after, err := readMessageCount(arbDb)
+	if err != nil {
+		return 0, err
+	}
+	return after - before, nil
+}
+
+func readMessageCount(arbDb ethdb.Database) (uint64, error) {
+	countBytes, err := arbDb.Get(messageCountKey)
+	if err != nil {
+		return 0, fmt.Errorf("reading message count: %w", err)
+	}
+	var messageCount uint64
+	if err := rlp.DecodeBytes(countBytes, &messageCount); err != nil {
+		return 0, err
+	}
+	return messageCount, nil
+}
+
+// importBlockExport verifies a block export, returning a digest of its contents and the hash of its last block.
+// parentHash is the hash of the block before the export's first if an earlier export had it, which it must chain
+// to, as it must to any of its blocks the database has. With a nonzero digest, which the export must match, the
+// messages the database doesn't have are appended to it.
+func importBlockExport(chainDb ethdb.Database, arbDb ethdb.Database, genesisBlockNumber uint64, open BlockExportOpener, parentHash common.Hash, digest common.Hash) (common.Hash, common.Hash, error) {
+	reader, err := open()
+	if err != nil {
+		return common.Hash{}, common.Hash{}, err
+	}
+	defer reader.Close()
+	stream := rlp.NewStream(reader, 0)
+	var info blockExportInfo
+	if err := stream.Decode(&info); err != nil {
+		return common.Hash{}, common.Hash{}, fmt.Errorf("reading export info: %w", err)
+	}
+	if info.Version != blockExportVersion {
+		return common.Hash{}, common.Hash{}, fmt.Errorf("unsupported block export version %v", info.Version)
+	}
+	if info.GenesisBlockNumber != genesisBlockNumber {
+		return common.Hash{}, common.Hash{}, fmt.Errorf("export is of a chain with genesis block %v but the database's is %v", info.GenesisBlockNumber, genesisBlockNumber)
+	}
+	if info.First < genesisBlockNumber || info.Count == 0 {
+		return common.Hash{}, common.Hash{}, fmt.Errorf("invalid block range of %v blocks from %v", info.Count, info.First)
+	}
+	messageCount, err := readMessageCount(arbDb)
+	if err != nil {
+		return common.Hash{}, common.Hash{}, err
+	}
+	firstPos := uint64(arbutil.BlockNumberToMessageCount(info.First, genesisBlockNumber)) - 1
+	if parentHash == (common.Hash{}) && firstPos > messageCount {
+		return common.Hash{}, common.Hash{}, fmt.Errorf("export starts at message %v but the database only has %v", firstPos, messageCount)
+	}
+	if parentHash == (common.Hash{}) && firstPos > 0 {
+		parentHash, err = storedBlockHash(chainDb, arbDb, genesisBlockNumber, firstPos-1)
+		if err != nil {
+			return common.Hash{}, common.Hash{}, err
+		}
+		if parentHash == (common.Hash{}) {
+			return common.Hash{}, common.Hash{}, fmt.Errorf("the database has no hash for block %v, which the export continues", info.First-1)
+		}
+	}
+
+	var contents common.Hash
+	batch := arbDb.NewBatch()
+	added := uint64(0)
+	for i := uint64(0); i < info.Count; i++ {
+		number := info.First + i
+		pos := firstPos + i
+		var entry blockExportEntry
+		if err := stream.Decode(&entry); err != nil {
+			return common.Hash{}, common.Hash{}, fmt.Errorf("reading block %v: %w", number, err)
+		}
+		header, err := decodeExportedBlock(&entry, number)
+		if err != nil {
+			return common.Hash{}, common.Hash{}, err
+		}
+		if parentHash != (common.Hash{}) && header.ParentHash != parentHash {
+			return common.Hash{}, common.Hash{}, fmt.Errorf("block %v has parent %v but the previous block is %v", number, header.ParentHash, parentHash)
+		}
+		hash := header.Hash()
+		parentHash = hash
+		contents = crypto.Keccak256Hash(contents.Bytes(), hash.Bytes(), entry.Message)
+		if pos < messageCount {
+			stored, err := storedBlockHash(chainDb, arbDb, genesisBlockNumber, pos)
+			if err != nil {
+				return common.Hash{}, common.Hash{}, err
+			}
+			if stored != (common.Hash{}) && stored != hash {
+				return common.Hash{}, common.Hash{}, fmt.Errorf("block %v is %v in the export but %v in the database", number, hash, stored)
+			}
+			continue
+		}
+		if digest == (common.Hash{}) {
+			continue
+		}
+		if err := batch.Put(dbKey(messagePrefix, pos), entry.Message); err != nil {
+			return common.Hash{}, common.Hash{}, err
+		}
+		encodedHash, err := rlp.EncodeToBytes(blockHashDBValue{BlockHash: &hash})
+		if err != nil {
+			return common.Hash{}, common.Hash{}, err
+		}
+		if err := batch.Put(dbKey(blockHashInputFeedPrefix, pos), encodedHash); err != nil {
+			return common.Hash{}, common.Hash{}, err
+		}
+		added++
+	}
+	if digest == (common.Hash{}) {
+		return contents, parentHash, nil
+	}
+	if contents != digest {
+		return common.Hash{}, common.Hash{}, errors.New("export changed since it was authenticated")
+	}
+	if added == 0 {
+		return contents, parentHash, nil
+	}
+	if err := setMessageCount(batch, arbutil.MessageIndex(messageCount+added)); err != nil {
+		return common.Hash{}, common.Hash{}, err
+	}
+	return contents, parentHash, batch.Write()
+}
@@ -0,0 +1,147 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbnode
+
+import (
+	"bytes"
+	"io"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/offchainlabs/nitro/arbos/arbostypes"
+	"github.com/offchainlabs/nitro/arbutil"
+)
+
+// writeTestBlocks writes a chain of block headers, and the messages the blocks came from. Chains written with
+// different extra data have different hashes.
+func writeTestBlocks(t *testing.T, chainDb ethdb.Database, arbDb ethdb.Database, genesis uint64, count uint64, extra byte) {
+	t.Helper()
+	parent := common.Hash{}
+	for i := uint64(0); i < count; i++ {
+		number := genesis + i
+		header := &types.Header{
+			ParentHash: parent,
+			Number:     new(big.Int).SetUint64(number),
+			Difficulty: big.NewInt(1),
+			Extra:      []byte{extra},
+		}
+		hash := header.Hash()
+		rawdb.WriteHeader(chainDb, header)
+		rawdb.WriteCanonicalHash(chainDb, hash, number)
+		parent = hash
+
+		message := arbostypes.MessageWithMetadata{
+			Message: &arbostypes.L1IncomingMessage{
+				Header: &arbostypes.L1IncomingMessageHeader{
+					Kind:        arbostypes.L1MessageType_L2Message,
+					BlockNumber: number,
+					L1BaseFee:   big.NewInt(1),
+				},
+				L2msg: []byte{byte(i), extra},
+			},
+			DelayedMessagesRead: 1,
+		}
+		data, err := rlp.EncodeToBytes(message)
+		Require(t, err)
+		Require(t, arbDb.Put(dbKey(messagePrefix, i), data))
+	}
+	Require(t, setMessageCount(arbDb, arbutil.MessageIndex(count)))
+}
+
+func exportOpener(exports ...[]byte) BlockExportOpener {
+	opened := 0
+	return func() (io.ReadCloser, error) {
+		export := exports[min(opened, len(exports)-1)]
+		opened++
+		return io.NopCloser(bytes.NewReader(export)), nil
+	}
+}
+
+func TestBlockExportImport(t *testing.T) {
+	genesis := uint64(10)
+	chainDb := rawdb.NewMemoryDatabase()
+	arbDb := rawdb.NewMemoryDatabase()
+	writeTestBlocks(t, chainDb, arbDb, genesis, 6, 0)
+	middleHash := rawdb.ReadCanonicalHash(chainDb, genesis+2)
+	lastHash := rawdb.ReadCanonicalHash(chainDb, genesis+5)
+
+	var first, second bytes.Buffer
+	Require(t, ExportBlocks(chainDb, arbDb, genesis, genesis, genesis+2, &first))
+	Require(t, ExportBlocks(chainDb, arbDb, genesis, genesis+3, genesis+5, &second))
+
+	// a replica with only the genesis block's message imports the rest
+	replicaChainDb := rawdb.NewMemoryDatabase()
+	replicaArbDb := rawdb.NewMemoryDatabase()
+	genesisMessage, err := arbDb.Get(dbKey(messagePrefix, 0))
+	Require(t, err)
+	Require(t, replicaArbDb.Put(dbKey(messagePrefix, 0), genesisMessage))
+	Require(t, setMessageCount(replicaArbDb, 1))
+	expectFailure := func(reason string, lastBlockHash common.Hash, exports ...BlockExportOpener) {
+		t.Helper()
+		if _, err := ImportBlocks(replicaChainDb, replicaArbDb, genesis, exports, lastBlockHash); err == nil {
+			Fail(t, "imported", reason)
+		}
+		count, err := readMessageCount(replicaArbDb)
+		Require(t, err)
+		if count != 1 {
+			Fail(t, "failed import of", reason, "left", count, "messages")
+		}
+	}
+
+	expectFailure("an export that doesn't continue the database", lastHash, exportOpener(second.Bytes()))
+	expectFailure("exports without a trusted hash", common.Hash{}, exportOpener(first.Bytes()), exportOpener(second.Bytes()))
+	expectFailure("exports that don't end with the trusted hash", middleHash, exportOpener(first.Bytes()), exportOpener(second.Bytes()))
+	expectFailure("exports out of order", lastHash, exportOpener(second.Bytes()), exportOpener(first.Bytes()))
+
+	// an export of another chain doesn't chain to the trusted hash
+	otherChainDb := rawdb.NewMemoryDatabase()
+	otherArbDb := rawdb.NewMemoryDatabase()
+	writeTestBlocks(t, otherChainDb, otherArbDb, genesis, 6, 1)
+	var other bytes.Buffer
+	Require(t, ExportBlocks(otherChainDb, otherArbDb, genesis, genesis, genesis+5, &other))
+	expectFailure("an export of another chain", lastHash, exportOpener(other.Bytes()))
+
+	// an export that changes after it's authenticated isn't imported
+	var tampered bytes.Buffer
+	Require(t, ExportBlocks(otherChainDb, otherArbDb, genesis, genesis, genesis+2, &tampered))
+	expectFailure("an export that changed", middleHash, exportOpener(first.Bytes(), tampered.Bytes()))
+
+	added, err := ImportBlocks(replicaChainDb, replicaArbDb, genesis, []BlockExportOpener{exportOpener(first.Bytes())}, middleHash)
+	Require(t, err)
+	if added != 2 {
+		Fail(t, "wrong number of messages added", added)
+	}
+	added, err = ImportBlocks(replicaChainDb, replicaArbDb, genesis, []BlockExportOpener{exportOpener(first.Bytes()), exportOpener(second.Bytes())}, lastHash)
+	Require(t, err)
+	if added != 3 {
+		Fail(t, "wrong number of messages added", added)
+	}
+	for pos := uint64(0); pos < 6; pos++ {
+		expected, err := arbDb.Get(dbKey(messagePrefix, pos))
+		Require(t, err)
+		imported, err := replicaArbDb.Get(dbKey(messagePrefix, pos))
+		Require(t, err)
+		if !bytes.Equal(expected, imported) {
+			Fail(t, "wrong message imported", pos)
+		}
+		hash, err := storedBlockHash(replicaChainDb, replicaArbDb, genesis, pos)
+		Require(t, err)
+		if pos > 0 && hash != rawdb.ReadCanonicalHash(chainDb, genesis+pos) {
+			Fail(t, "wrong block hash imported", pos, hash)
+		}
+	}
+
+	// reimporting adds nothing
+	added, err = ImportBlocks(replicaChainDb, replicaArbDb, genesis, []BlockExportOpener{exportOpener(second.Bytes())}, lastHash)
+	Require(t, err)
+	if added != 0 {
+		Fail(t, "reimport added messages", added)
+	}
+}
@@ -91,6 +91,9 @@ type DataPoster struct {
 	queue      QueueStorage
 	errorCount map[uint64]int // number of consecutive intermittent errors rbf-ing or sending, per nonce
 
+	// the max fee cap formula is recompiled when it's changed by a config reload
+	maxFeeCapMutex      sync.Mutex
+	maxFeeCapFormula    string
 	maxFeeCapExpression *govaluate.EvaluableExpression
 }
 
@@ -161,6 +164,7 @@ func NewDataPoster(ctx context.Context, opts *DataPosterOpts) (*DataPoster, erro
 		metadataRetriever:   opts.MetadataRetriever,
 		queue:               queue,
 		errorCount:          make(map[uint64]int),
+		maxFeeCapFormula:    cfg.MaxFeeCapFormula,
 		maxFeeCapExpression: expression,
 		extraBacklog:        opts.ExtraBacklog,
 		parentChainID:       opts.ParentChainID,
@@ -468,6 +472,24 @@ func (p *DataPoster) GetNextNonceAndMeta(ctx context.Context) (uint64, []byte, e
 const minNonBlobRbfIncrease = arbmath.OneInBips * 11 / 10
 const minBlobRbfIncrease = arbmath.OneInBips * 2
 
+// getMaxFeeCapExpression returns the compiled max fee cap formula, recompiling it if it was changed. If the new
+// formula doesn't compile, the previous one keeps being used.
+func (p *DataPoster) getMaxFeeCapExpression(formula string) *govaluate.EvaluableExpression {
+	p.maxFeeCapMutex.Lock()
+	defer p.maxFeeCapMutex.Unlock()
+	if formula != p.maxFeeCapFormula || p.maxFeeCapExpression == nil {
+		expression, err := govaluate.NewEvaluableExpression(formula)
+		if err != nil && p.maxFeeCapExpression != nil {
+			log.Error("invalid reloaded max fee cap formula, keeping the previous one", "formula", formula, "err", err)
+		} else if err == nil {
+			log.Info("using new max fee cap formula", "formula", formula)
+			p.maxFeeCapExpression = expression
+		}
+		p.maxFeeCapFormula = formula
+	}
+	return p.maxFeeCapExpression
+}
+
 // evalMaxFeeCapExpr uses MaxFeeCapFormula from config to calculate the expression's result by plugging in appropriate parameter values
 // backlogOfBatches should already include extraBacklog
 func (p *DataPoster) evalMaxFeeCapExpr(backlogOfBatches uint64, elapsed time.Duration) (*big.Int, error) {
@@ -480,7 +502,7 @@ func (p *DataPoster) evalMaxFeeCapExpr(backlogOfBatches uint64, elapsed time.Dur
 		"ElapsedTimeImportance": config.ElapsedTimeImportance,
 		"TargetPriceGWei":       config.TargetPriceGwei,
 	}
-	result, err := p.maxFeeCapExpression.Evaluate(parameters)
+	result, err := p.getMaxFeeCapExpression(config.MaxFeeCapFormula).Evaluate(parameters)
 	if err != nil {
 		return nil, fmt.Errorf("error evaluating maxFeeCapExpression: %w", err)
 	}
@@ -820,6 +842,56 @@ func (p *DataPoster) PostTransaction(ctx context.Context, dataCreatedAt time.Tim
 	return fullTx, p.sendTx(ctx, nil, &queuedTx)
 }
 
+// IsQueued returns whether the data poster's queue has a transaction with the nonce.
+func (p *DataPoster) IsQueued(ctx context.Context, nonce uint64) (bool, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	tx, err := p.queue.Get(ctx, nonce)
+	return tx != nil, err
+}
+
+// RepostTransaction sends a transaction the data poster posted before again, unchanged, for when a parent chain
+// reorg drops it after it was pruned from the queue. It does nothing if the transaction's nonce is still queued,
+// since the data poster keeps sending queued transactions itself. Otherwise the nonce must be just before the first
+// queued one, so dropped transactions are reposted from the highest nonce down, and one the parent chain hasn't used.
+func (p *DataPoster) RepostTransaction(ctx context.Context, tx *types.Transaction, meta []byte) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	queued, err := p.queue.Get(ctx, tx.Nonce())
+	if err != nil {
+		return err
+	}
+	if queued != nil {
+		return nil
+	}
+	nonce, err := p.client.NonceAt(ctx, p.Sender(), nil)
+	if err != nil {
+		return err
+	}
+	if tx.Nonce() < nonce {
+		return fmt.Errorf("can't repost transaction with nonce %v as the parent chain already used nonces up to %v", tx.Nonce(), nonce)
+	}
+	if tx.Nonce() < p.nonce {
+		// The transaction is unconfirmed again, so let updateNonce see the nonce rise past it.
+		p.nonce = tx.Nonce()
+		p.lastBlock = nil
+	}
+	log.Warn("DataPoster reposting transaction dropped by a parent chain reorg", "nonce", tx.Nonce(), "hash", tx.Hash())
+	replacementTimes := p.config().ReplacementTimes
+	if tx.Type() == types.BlobTxType {
+		replacementTimes = p.config().BlobTxReplacementTimes
+	}
+	queuedTx := storage.QueuedTransaction{
+		FullTx:          tx,
+		Meta:            meta,
+		Sent:            false,
+		Created:         time.Now(),
+		NextReplacement: time.Now().Add(replacementTimes[0]),
+	}
+	return p.sendTx(ctx, nil, &queuedTx)
+}
+
 // the mutex must be held by the caller
 func (p *DataPoster) saveTx(ctx context.Context, prevTx, newTx *storage.QueuedTransaction) error {
 	if prevTx != nil {
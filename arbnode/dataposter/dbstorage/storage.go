@@ -175,6 +175,9 @@ func (s *Storage) Put(ctx context.Context, index uint64, prev, new *storage.Queu
 		if err := b.Put(lastItemIdxKey, key); err != nil {
 			return fmt.Errorf("updating last item: %w", err)
 		}
+	}
+	// An item may be inserted before the first one when a pruned transaction is reposted.
+	if prev == nil {
 		if err := b.Put(countKey, []byte(strconv.Itoa(cnt+1))); err != nil {
 			return fmt.Errorf("updating length counter: %w", err)
 		}
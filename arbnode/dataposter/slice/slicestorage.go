@@ -88,6 +88,12 @@ func (s *Storage) Put(_ context.Context, index uint64, prev, new *storage.Queued
 			return errors.New("prevItem isn't nil but item is just after end of queue")
 		}
 		s.queue = append(s.queue, newEnc)
+	} else if index+1 == s.firstNonce {
+		if prev != nil {
+			return errors.New("prevItem isn't nil but item is just before start of queue")
+		}
+		s.queue = append([][]byte{newEnc}, s.queue...)
+		s.firstNonce = index
 	} else if index >= s.firstNonce {
 		queueIdx := index - s.firstNonce
 		if queueIdx > uint64(len(s.queue)) {
@@ -21,6 +21,8 @@ var (
 	BlockValidatorPrefix string = "v" // the prefix for all block validator keys
 	StakerPrefix         string = "S" // the prefix for all staker keys
 	BatchPosterPrefix    string = "b" // the prefix for all batch poster keys
+	PostedBatchPrefix    string = "P" // the prefix for the batch poster's records of the batches it posted
+	OutboxExecutorPrefix string = "O" // the prefix for the outbox executor's messages waiting to be executed
 	// TODO(anodar): move everything else from schema.go file to here once
 	// execution split is complete.
 )
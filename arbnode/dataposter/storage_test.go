@@ -371,3 +371,39 @@ func TestLength(t *testing.T) {
 
 	}
 }
+
+func TestPutBeforeFirst(t *testing.T) {
+	ctx := context.Background()
+	for name, s := range initStorages(ctx, t) {
+		t.Run(name, func(t *testing.T) {
+			if err := s.Prune(ctx, 11); err != nil {
+				t.Fatalf("Prune(11) unexpected error: %v", err)
+			}
+			// A reposted transaction goes back just before the first one.
+			if err := s.Put(ctx, 10, nil, valueOf(t, 10)); err != nil {
+				t.Fatalf("Put(10) unexpected error: %v", err)
+			}
+			got, err := s.FetchContents(ctx, 0, 20)
+			if err != nil {
+				t.Fatalf("FetchContents() unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(values(t, 10, 19), got, ignoreData); diff != "" {
+				t.Errorf("Put(10) unexpected diff:\n%s", diff)
+			}
+			length, err := s.Length(ctx)
+			if err != nil {
+				t.Fatalf("Length() unexpected error: %v", err)
+			}
+			if length != 10 {
+				t.Errorf("Length() = %d want 10", length)
+			}
+			last, err := s.FetchLast(ctx)
+			if err != nil {
+				t.Fatalf("FetchLast() unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(valueOf(t, 19), last, ignoreData); diff != "" {
+				t.Errorf("FetchLast() unexpected diff:\n%s", diff)
+			}
+		})
+	}
+}
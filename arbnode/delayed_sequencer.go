@@ -17,6 +17,7 @@ import (
 
 	"github.com/offchainlabs/nitro/arbos/arbostypes"
 	"github.com/offchainlabs/nitro/execution"
+	"github.com/offchainlabs/nitro/util/arbmath"
 	"github.com/offchainlabs/nitro/util/headerreader"
 	"github.com/offchainlabs/nitro/util/stopwaiter"
 )
@@ -92,36 +93,57 @@ func (d *DelayedSequencer) trySequence(ctx context.Context, lastBlockHeader *typ
 	return d.sequenceWithoutLockout(ctx, lastBlockHeader)
 }
 
-func (d *DelayedSequencer) sequenceWithoutLockout(ctx context.Context, lastBlockHeader *types.Header) error {
-	d.mutex.Lock()
-	defer d.mutex.Unlock()
-
-	config := d.config()
-	if !config.Enable {
-		return nil
-	}
+// delayedFinalizeDistance returns how many blocks deep a parent chain block must be before its delayed messages are
+// final, when the parent chain doesn't report finality: the stricter of the node's finalize distance and the one
+// the chain owner set in ArbOS.
+func delayedFinalizeDistance(configured int64, timing execution.ParentChainTiming) int64 {
+	return arbmath.MaxInt(configured, arbmath.SaturatingCast[int64](timing.DelayedFinalityBlocks))
+}
 
-	var finalized uint64
-	var finalizedHash common.Hash
+// finalizedParentChainBlock returns the latest parent chain block whose delayed messages are final as of
+// lastBlockHeader, or false if there's none yet. Its hash is only known when the parent chain reports finality.
+func finalizedParentChainBlock(ctx context.Context, l1Reader *headerreader.HeaderReader, exec execution.ExecutionSequencer, config *DelayedSequencerConfig, lastBlockHeader *types.Header) (uint64, common.Hash, bool, error) {
 	if config.UseMergeFinality && headerreader.HeaderIndicatesFinalitySupport(lastBlockHeader) {
 		var header *types.Header
 		var err error
 		if config.RequireFullFinality {
-			header, err = d.l1Reader.LatestFinalizedBlockHeader(ctx)
+			header, err = l1Reader.LatestFinalizedBlockHeader(ctx)
 		} else {
-			header, err = d.l1Reader.LatestSafeBlockHeader(ctx)
+			header, err = l1Reader.LatestSafeBlockHeader(ctx)
 		}
 		if err != nil {
-			return err
+			return 0, common.Hash{}, false, err
 		}
-		finalized = header.Number.Uint64()
-		finalizedHash = header.Hash()
+		return header.Number.Uint64(), header.Hash(), true, nil
+	}
+	finalizeDistance := config.FinalizeDistance
+	if timing, err := exec.ParentChainTiming(); err != nil {
+		log.Warn("error getting parent chain timing; using the configured finalize distance", "err", err)
 	} else {
-		currentNum := lastBlockHeader.Number.Int64()
-		if currentNum < config.FinalizeDistance {
-			return nil
-		}
-		finalized = uint64(currentNum - config.FinalizeDistance)
+		finalizeDistance = delayedFinalizeDistance(finalizeDistance, timing)
+	}
+	currentNum := lastBlockHeader.Number.Int64()
+	if currentNum < finalizeDistance {
+		return 0, common.Hash{}, false, nil
+	}
+	return uint64(currentNum - finalizeDistance), common.Hash{}, true, nil
+}
+
+func (d *DelayedSequencer) sequenceWithoutLockout(ctx context.Context, lastBlockHeader *types.Header) error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	config := d.config()
+	if !config.Enable {
+		return nil
+	}
+
+	finalized, finalizedHash, ok, err := finalizedParentChainBlock(ctx, d.l1Reader, d.exec, config, lastBlockHeader)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return nil
 	}
 
 	if d.waitingForFinalizedBlock > finalized {
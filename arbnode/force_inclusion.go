@@ -0,0 +1,267 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbnode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/offchainlabs/nitro/arbos/arbostypes"
+	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/cmd/chaininfo"
+	"github.com/offchainlabs/nitro/cmd/genericconf"
+	"github.com/offchainlabs/nitro/execution"
+	"github.com/offchainlabs/nitro/solgen/go/bridgegen"
+	"github.com/offchainlabs/nitro/util/arbmath"
+	"github.com/offchainlabs/nitro/util/headerreader"
+	"github.com/offchainlabs/nitro/util/stopwaiter"
+)
+
+// ForceInclusionConfig configures a service that force includes delayed messages the sequencer has left
+// unsequenced for longer than the sequencer inbox's max time variation allows.
+type ForceInclusionConfig struct {
+	Enable            bool                     `koanf:"enable"`
+	PollInterval      time.Duration            `koanf:"poll-interval" reload:"hot"`
+	DryRun            bool                     `koanf:"dry-run" reload:"hot"`
+	MaxGasFeeCapGwei  float64                  `koanf:"max-gas-fee-cap-gwei" reload:"hot"`
+	MaxTipCapGwei     float64                  `koanf:"max-tip-cap-gwei" reload:"hot"`
+	GasLimit          uint64                   `koanf:"gas-limit" reload:"hot"`
+	TxTimeout         time.Duration            `koanf:"tx-timeout" reload:"hot"`
+	ParentChainWallet genericconf.WalletConfig `koanf:"parent-chain-wallet"`
+}
+
+type ForceInclusionConfigFetcher func() *ForceInclusionConfig
+
+func (c *ForceInclusionConfig) Validate() error {
+	if !c.Enable {
+		return nil
+	}
+	if c.PollInterval <= 0 {
+		return errors.New("force inclusion poll interval must be positive")
+	}
+	if c.MaxGasFeeCapGwei <= 0 || c.MaxTipCapGwei < 0 || c.MaxTipCapGwei > c.MaxGasFeeCapGwei {
+		return errors.New("force inclusion max gas fee cap must be positive and at least the max tip cap")
+	}
+	return nil
+}
+
+func ForceInclusionConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.Bool(prefix+".enable", DefaultForceInclusionConfig.Enable, "force include delayed messages the sequencer hasn't included within the sequencer inbox's delay window")
+	f.Duration(prefix+".poll-interval", DefaultForceInclusionConfig.PollInterval, "how often to check for delayed messages that can be force included")
+	f.Bool(prefix+".dry-run", DefaultForceInclusionConfig.DryRun, "only log the force inclusion transactions that would be sent")
+	f.Float64(prefix+".max-gas-fee-cap-gwei", DefaultForceInclusionConfig.MaxGasFeeCapGwei, "the maximum gas fee cap for force inclusion transactions, in gwei; no transaction is sent while the parent chain base fee is above it")
+	f.Float64(prefix+".max-tip-cap-gwei", DefaultForceInclusionConfig.MaxTipCapGwei, "the maximum tip cap for force inclusion transactions, in gwei")
+	f.Uint64(prefix+".gas-limit", DefaultForceInclusionConfig.GasLimit, "the gas limit for force inclusion transactions (0 = estimate)")
+	f.Duration(prefix+".tx-timeout", DefaultForceInclusionConfig.TxTimeout, "how long to wait for a force inclusion transaction to be mined before trying again")
+	genericconf.WalletConfigAddOptions(prefix+".parent-chain-wallet", f, DefaultForceInclusionConfig.ParentChainWallet.Pathname)
+}
+
+var DefaultForceInclusionL1WalletConfig = genericconf.WalletConfig{
+	Pathname:      "force-inclusion-wallet",
+	Password:      genericconf.WalletConfigDefault.Password,
+	PrivateKey:    genericconf.WalletConfigDefault.PrivateKey,
+	Account:       genericconf.WalletConfigDefault.Account,
+	OnlyCreateKey: genericconf.WalletConfigDefault.OnlyCreateKey,
+}
+
+var DefaultForceInclusionConfig = ForceInclusionConfig{
+	Enable:            false,
+	PollInterval:      time.Minute,
+	DryRun:            false,
+	MaxGasFeeCapGwei:  100,
+	MaxTipCapGwei:     2,
+	GasLimit:          0,
+	TxTimeout:         10 * time.Minute,
+	ParentChainWallet: DefaultForceInclusionL1WalletConfig,
+}
+
+var TestForceInclusionConfig = ForceInclusionConfig{
+	Enable:            true,
+	PollInterval:      time.Millisecond * 100,
+	DryRun:            false,
+	MaxGasFeeCapGwei:  100,
+	MaxTipCapGwei:     2,
+	GasLimit:          0,
+	TxTimeout:         time.Minute,
+	ParentChainWallet: DefaultForceInclusionL1WalletConfig,
+}
+
+// ForceInclusionHelper watches the delayed inbox and calls forceInclusion on the sequencer inbox
+// once delayed messages have waited past the max time variation's delay. Like the delayed sequencer, it only
+// includes delayed messages once they're final on the parent chain.
+type ForceInclusionHelper struct {
+	stopwaiter.StopWaiter
+	l1Reader       *headerreader.HeaderReader
+	inbox          *InboxTracker
+	exec           execution.ExecutionSequencer
+	seqInbox       *bridgegen.SequencerInbox
+	auth           *bind.TransactOpts
+	config         ForceInclusionConfigFetcher
+	finalityConfig DelayedSequencerConfigFetcher
+}
+
+func NewForceInclusionHelper(l1Reader *headerreader.HeaderReader, inbox *InboxTracker, exec execution.ExecutionSequencer, deployInfo *chaininfo.RollupAddresses, auth *bind.TransactOpts, config ForceInclusionConfigFetcher, finalityConfig DelayedSequencerConfigFetcher) (*ForceInclusionHelper, error) {
+	if auth == nil {
+		return nil, errors.New("force inclusion requires a parent chain wallet")
+	}
+	seqInbox, err := bridgegen.NewSequencerInbox(deployInfo.SequencerInbox, l1Reader.Client())
+	if err != nil {
+		return nil, err
+	}
+	return &ForceInclusionHelper{
+		l1Reader:       l1Reader,
+		inbox:          inbox,
+		exec:           exec,
+		seqInbox:       seqInbox,
+		auth:           auth,
+		config:         config,
+		finalityConfig: finalityConfig,
+	}, nil
+}
+
+// delayedMessageEligible returns whether a delayed message has waited long enough to be force included,
+// mirroring the sequencer inbox's checks against the current parent chain block.
+func delayedMessageEligible(msg *arbostypes.L1IncomingMessage, l1BlockNumber, timestamp, delayBlocks, delaySeconds uint64) bool {
+	return arbmath.SaturatingUAdd(msg.Header.BlockNumber, delayBlocks) < l1BlockNumber &&
+		arbmath.SaturatingUAdd(msg.Header.Timestamp, delaySeconds) < timestamp
+}
+
+// findForceIncludable returns the delayed message count to force include up to, or 0 if no message is eligible.
+// Delayed messages are ordered by block and timestamp, so the eligible ones, which must also have been posted no
+// later than the finalized parent chain block, form a prefix of those unread.
+func (f *ForceInclusionHelper) findForceIncludable(ctx context.Context, totalRead, delayedCount, finalized, l1BlockNumber, timestamp, delayBlocks, delaySeconds uint64) (uint64, *arbostypes.L1IncomingMessage, error) {
+	var lookupErr error
+	// #nosec G115
+	eligible := sort.Search(int(delayedCount-totalRead), func(i int) bool {
+		if lookupErr != nil {
+			return true
+		}
+		msg, _, parentChainBlockNumber, err := f.inbox.GetDelayedMessageAccumulatorAndParentChainBlockNumber(ctx, totalRead+uint64(i))
+		if err != nil {
+			lookupErr = err
+			return true
+		}
+		return parentChainBlockNumber > finalized || !delayedMessageEligible(msg, l1BlockNumber, timestamp, delayBlocks, delaySeconds)
+	})
+	if lookupErr != nil {
+		return 0, nil, lookupErr
+	}
+	if eligible == 0 {
+		return 0, nil, nil
+	}
+	target := totalRead + uint64(eligible)
+	msg, err := f.inbox.GetDelayedMessage(ctx, target-1)
+	if err != nil {
+		return 0, nil, err
+	}
+	return target, msg, nil
+}
+
+func (f *ForceInclusionHelper) maybeForceInclude(ctx context.Context) error {
+	config := f.config()
+	callOpts := &bind.CallOpts{Context: ctx}
+	totalReadBig, err := f.seqInbox.TotalDelayedMessagesRead(callOpts)
+	if err != nil {
+		return fmt.Errorf("error getting total delayed messages read: %w", err)
+	}
+	totalRead := arbmath.BigToUintSaturating(totalReadBig)
+	delayedCount, err := f.inbox.GetDelayedCount()
+	if err != nil {
+		return err
+	}
+	if totalRead >= delayedCount {
+		return nil
+	}
+
+	delayBlocks, _, delaySeconds, _, err := f.seqInbox.MaxTimeVariation(callOpts)
+	if err != nil {
+		return fmt.Errorf("error getting max time variation: %w", err)
+	}
+	header, err := f.l1Reader.LastHeader(ctx)
+	if err != nil {
+		return err
+	}
+	finalized, _, ok, err := finalizedParentChainBlock(ctx, f.l1Reader, f.exec, f.finalityConfig(), header)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+	l1BlockNumber := arbutil.ParentHeaderToL1BlockNumber(header)
+	target, msg, err := f.findForceIncludable(ctx, totalRead, delayedCount, finalized, l1BlockNumber, header.Time, arbmath.BigToUintSaturating(delayBlocks), arbmath.BigToUintSaturating(delaySeconds))
+	if err != nil {
+		return err
+	}
+	if target == 0 {
+		return nil
+	}
+
+	maxFeeCap := arbmath.FloatToBig(config.MaxGasFeeCapGwei * params.GWei)
+	tipCap := arbmath.FloatToBig(config.MaxTipCapGwei * params.GWei)
+	if header.BaseFee != nil {
+		if header.BaseFee.Cmp(maxFeeCap) > 0 {
+			log.Warn("not force including delayed messages while the parent chain base fee is above the max fee cap", "baseFee", header.BaseFee, "maxFeeCap", maxFeeCap)
+			return nil
+		}
+		tipCap = arbmath.BigMin(tipCap, arbmath.BigSub(maxFeeCap, header.BaseFee))
+	}
+	opts := *f.auth
+	opts.Context = ctx
+	opts.GasFeeCap = maxFeeCap
+	opts.GasTipCap = tipCap
+	opts.GasLimit = config.GasLimit
+	opts.NoSend = config.DryRun
+
+	tx, err := f.seqInbox.ForceInclusion(
+		&opts,
+		new(big.Int).SetUint64(target),
+		msg.Header.Kind,
+		[2]uint64{msg.Header.BlockNumber, msg.Header.Timestamp},
+		msg.Header.L1BaseFee,
+		msg.Header.Poster,
+		crypto.Keccak256Hash(msg.L2msg),
+	)
+	if err != nil {
+		return fmt.Errorf("error force including delayed messages up to %v: %w", target, err)
+	}
+	if config.DryRun {
+		log.Info("dry run: would force include delayed messages", "from", totalRead, "to", target, "gas", tx.Gas(), "gasFeeCap", tx.GasFeeCap())
+		return nil
+	}
+	log.Info("force including delayed messages", "from", totalRead, "to", target, "tx", tx.Hash())
+
+	waitCtx, cancel := context.WithTimeout(ctx, config.TxTimeout)
+	defer cancel()
+	receipt, err := bind.WaitMined(waitCtx, f.l1Reader.Client(), tx)
+	if err != nil {
+		return fmt.Errorf("error waiting for force inclusion transaction %v: %w", tx.Hash(), err)
+	}
+	if receipt.Status != 1 {
+		return fmt.Errorf("force inclusion transaction %v failed", tx.Hash())
+	}
+	log.Info("force included delayed messages", "to", target, "tx", tx.Hash(), "parentChainBlock", receipt.BlockNumber)
+	return nil
+}
+
+func (f *ForceInclusionHelper) Start(ctxIn context.Context) {
+	f.StopWaiter.Start(ctxIn, f)
+	f.CallIteratively(func(ctx context.Context) time.Duration {
+		if err := f.maybeForceInclude(ctx); err != nil {
+			log.Error("error checking for delayed messages to force include", "err", err)
+		}
+		return f.config().PollInterval
+	})
+}
@@ -0,0 +1,61 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbnode
+
+import (
+	"math"
+	"testing"
+
+	"github.com/offchainlabs/nitro/arbos/arbostypes"
+	"github.com/offchainlabs/nitro/execution"
+)
+
+func TestDelayedMessageEligibleForForceInclusion(t *testing.T) {
+	msg := &arbostypes.L1IncomingMessage{
+		Header: &arbostypes.L1IncomingMessageHeader{
+			BlockNumber: 100,
+			Timestamp:   1000,
+		},
+	}
+	// the sequencer inbox requires both the block and time delays to have strictly passed
+	cases := []struct {
+		l1BlockNumber uint64
+		timestamp     uint64
+		eligible      bool
+	}{
+		{111, 1101, true},
+		{110, 1101, false},
+		{111, 1100, false},
+		{50, 500, false},
+	}
+	for _, tc := range cases {
+		if delayedMessageEligible(msg, tc.l1BlockNumber, tc.timestamp, 10, 100) != tc.eligible {
+			Fail(t, "wrong eligibility at block", tc.l1BlockNumber, "timestamp", tc.timestamp)
+		}
+	}
+	if delayedMessageEligible(msg, ^uint64(0), ^uint64(0), ^uint64(0), 0) {
+		Fail(t, "overflowing delay made a message eligible")
+	}
+}
+
+func TestDelayedFinalizeDistance(t *testing.T) {
+	// the stricter of the node's finalize distance and the chain owner's setting wins
+	cases := []struct {
+		configured     int64
+		finalityBlocks uint64
+		expected       int64
+	}{
+		{20, 0, 20},
+		{20, 5, 20},
+		{20, 64, 64},
+		{0, 1, 1},
+		{20, ^uint64(0), math.MaxInt64},
+	}
+	for _, tc := range cases {
+		timing := execution.ParentChainTiming{DelayedFinalityBlocks: tc.finalityBlocks}
+		if distance := delayedFinalizeDistance(tc.configured, timing); distance != tc.expected {
+			Fail(t, "wrong finalize distance", distance, "expected", tc.expected, "configured", tc.configured, "on chain", tc.finalityBlocks)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbnode
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/broadcastclients"
+	"github.com/offchainlabs/nitro/staker"
+	"github.com/offchainlabs/nitro/util/headerreader"
+)
+
+const (
+	HealthPath    = "/healthz"
+	ReadinessPath = "/readyz"
+)
+
+type HealthConfig struct {
+	Enable                  bool          `koanf:"enable"`
+	MaxMessageLag           uint64        `koanf:"max-message-lag" reload:"hot"`
+	MaxParentChainHeaderAge time.Duration `koanf:"max-parent-chain-header-age" reload:"hot"`
+	MaxValidationLag        uint64        `koanf:"max-validation-lag" reload:"hot"`
+}
+
+var DefaultHealthConfig = HealthConfig{
+	Enable:                  false,
+	MaxMessageLag:           100,
+	MaxParentChainHeaderAge: 5 * time.Minute,
+	MaxValidationLag:        0,
+}
+
+func HealthConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.Bool(prefix+".enable", DefaultHealthConfig.Enable, "serve "+HealthPath+" and "+ReadinessPath+" on the HTTP RPC endpoint, reporting the state of each subsystem in JSON")
+	f.Uint64(prefix+".max-message-lag", DefaultHealthConfig.MaxMessageLag, "number of messages the transaction streamer may be behind the sync target while the node is ready")
+	f.Duration(prefix+".max-parent-chain-header-age", DefaultHealthConfig.MaxParentChainHeaderAge, "maximum age of the latest parent chain header while the node is ready (0 = not checked)")
+	f.Uint64(prefix+".max-validation-lag", DefaultHealthConfig.MaxValidationLag, "number of messages the block validator may be behind the transaction streamer while the node is ready (0 = not checked)")
+}
+
+// SubsystemHealth is the state of a single subsystem. A subsystem is live unless it was stopped, and ready once it
+// has caught up closely enough to serve requests.
+type SubsystemHealth struct {
+	Live    bool           `json:"live"`
+	Ready   bool           `json:"ready"`
+	Error   string         `json:"error,omitempty"`
+	Details map[string]any `json:"details,omitempty"`
+}
+
+type HealthStatus struct {
+	Live       bool                        `json:"live"`
+	Ready      bool                        `json:"ready"`
+	Subsystems map[string]*SubsystemHealth `json:"subsystems"`
+}
+
+// HealthChecker reports the state of the node's subsystems for load balancers and orchestrators. Only the
+// subsystems the node runs are checked, so the same endpoints fit every node role.
+type HealthChecker struct {
+	config           func() *HealthConfig
+	txStreamer       *TransactionStreamer
+	syncMonitor      *SyncMonitor
+	inboxReader      *InboxReader
+	l1Reader         *headerreader.HeaderReader
+	broadcastClients *broadcastclients.BroadcastClients
+	blockValidator   *staker.BlockValidator
+}
+
+func NewHealthChecker(n *Node) *HealthChecker {
+	return &HealthChecker{
+		config:           func() *HealthConfig { return &n.configFetcher.Get().Health },
+		txStreamer:       n.TxStreamer,
+		syncMonitor:      n.SyncMonitor,
+		inboxReader:      n.InboxReader,
+		l1Reader:         n.L1Reader,
+		broadcastClients: n.BroadcastClients,
+		blockValidator:   n.BlockValidator,
+	}
+}
+
+func (h *HealthChecker) Status() *HealthStatus {
+	config := h.config()
+	status := &HealthStatus{
+		Live:       true,
+		Ready:      true,
+		Subsystems: make(map[string]*SubsystemHealth),
+	}
+	msgCount, err := h.txStreamer.GetMessageCount()
+	status.Subsystems["transactionStreamer"] = h.txStreamerHealth(config, msgCount, err)
+	if h.inboxReader != nil {
+		status.Subsystems["inboxReader"] = h.inboxReaderHealth()
+	}
+	if h.l1Reader != nil {
+		status.Subsystems["parentChain"] = h.parentChainHealth(config)
+	}
+	if h.broadcastClients != nil {
+		status.Subsystems["feed"] = h.feedHealth()
+	}
+	if h.blockValidator != nil && err == nil {
+		status.Subsystems["blockValidator"] = h.blockValidatorHealth(config, msgCount)
+	}
+	for _, subsystem := range status.Subsystems {
+		// a stopped subsystem isn't ready, whatever its last progress was
+		subsystem.Ready = subsystem.Ready && subsystem.Live
+		status.Live = status.Live && subsystem.Live
+		status.Ready = status.Ready && subsystem.Ready
+	}
+	return status
+}
+
+func (h *HealthChecker) txStreamerHealth(config *HealthConfig, msgCount arbutil.MessageIndex, err error) *SubsystemHealth {
+	health := &SubsystemHealth{Live: !h.txStreamer.Stopped()}
+	if err != nil {
+		health.Error = err.Error()
+		return health
+	}
+	syncTarget := h.syncMonitor.SyncTargetMessageCount()
+	var lag arbutil.MessageIndex
+	if syncTarget > msgCount {
+		lag = syncTarget - msgCount
+	}
+	health.Details = map[string]any{
+		"msgCount":                msgCount,
+		"syncTargetMsgCount":      syncTarget,
+		"feedPendingMessageCount": h.txStreamer.FeedPendingMessageCount(),
+		"lag":                     lag,
+	}
+	health.Ready = h.txStreamer.Started() && uint64(lag) <= config.MaxMessageLag
+	return health
+}
+
+func (h *HealthChecker) inboxReaderHealth() *SubsystemHealth {
+	caughtUp := false
+	select {
+	case <-h.inboxReader.CaughtUp():
+		caughtUp = true
+	default:
+	}
+	return &SubsystemHealth{
+		Live:  !h.inboxReader.Stopped(),
+		Ready: caughtUp,
+		Details: map[string]any{
+			"caughtUp":       caughtUp,
+			"batchSeen":      h.inboxReader.GetLastSeenBatchCount(),
+			"batchProcessed": h.inboxReader.GetLastReadBatchCount(),
+		},
+	}
+}
+
+func (h *HealthChecker) parentChainHealth(config *HealthConfig) *SubsystemHealth {
+	health := &SubsystemHealth{Live: !h.l1Reader.Stopped()}
+	header, err := h.l1Reader.LastHeaderWithError()
+	if err != nil {
+		health.Error = err.Error()
+		return health
+	}
+	if header == nil {
+		health.Error = "no parent chain header received yet"
+		return health
+	}
+	// #nosec G115
+	age := time.Since(time.Unix(int64(header.Time), 0))
+	health.Details = map[string]any{
+		"blockNumber": header.Number,
+		"blockHash":   header.Hash(),
+		"age":         age.Round(time.Second).String(),
+	}
+	health.Ready = config.MaxParentChainHeaderAge == 0 || age <= config.MaxParentChainHeaderAge
+	return health
+}
+
+func (h *HealthChecker) feedHealth() *SubsystemHealth {
+	// the feed is only connected once the inbox reader caught up, so it isn't needed to be live
+	connected := h.broadcastClients.Connected()
+	return &SubsystemHealth{
+		Live:    true,
+		Ready:   connected > 0,
+		Details: map[string]any{"connected": connected},
+	}
+}
+
+func (h *HealthChecker) blockValidatorHealth(config *HealthConfig, msgCount arbutil.MessageIndex) *SubsystemHealth {
+	validated := h.blockValidator.GetValidated()
+	var lag arbutil.MessageIndex
+	if msgCount > validated {
+		lag = msgCount - validated
+	}
+	return &SubsystemHealth{
+		Live:  !h.blockValidator.Stopped(),
+		Ready: config.MaxValidationLag == 0 || uint64(lag) <= config.MaxValidationLag,
+		Details: map[string]any{
+			"validatedMsgCount": validated,
+			"lag":               lag,
+		},
+	}
+}
+
+// Handler serves the health status, with a 503 status code unless the node is live, or ready if requireReady is set.
+func (h *HealthChecker) Handler(requireReady bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		status := h.Status()
+		ok := status.Live
+		if requireReady {
+			ok = status.Ready
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if ok {
+			w.WriteHeader(http.StatusOK)
+		} else {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		if r.Method == http.MethodHead {
+			return
+		}
+		if err := json.NewEncoder(w).Encode(status); err != nil {
+			log.Warn("error writing health status", "err", err)
+		}
+	})
+}
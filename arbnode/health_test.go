@@ -0,0 +1,84 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbnode
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/nitro/arbutil"
+)
+
+func TestHealthChecker(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	exec, streamer, _, _ := NewTransactionStreamerForTest(t, common.Address{})
+	syncMonitor := NewSyncMonitor(func() *SyncMonitorConfig { return &TestSyncMonitorConfig })
+	config := DefaultHealthConfig
+	config.Enable = true
+	checker := &HealthChecker{
+		config:      func() *HealthConfig { return &config },
+		txStreamer:  streamer,
+		syncMonitor: syncMonitor,
+	}
+
+	expect := func(requireReady bool, code int, live bool, ready bool) *HealthStatus {
+		t.Helper()
+		recorder := httptest.NewRecorder()
+		checker.Handler(requireReady).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, HealthPath, nil))
+		if recorder.Code != code {
+			Fail(t, "unexpected status code", recorder.Code, "expected", code, "requireReady", requireReady)
+		}
+		var status HealthStatus
+		Require(t, json.Unmarshal(recorder.Body.Bytes(), &status))
+		if status.Live != live || status.Ready != ready {
+			Fail(t, "unexpected health", status.Live, status.Ready, "expected", live, ready)
+		}
+		if status.Subsystems["transactionStreamer"] == nil {
+			Fail(t, "transaction streamer health is missing")
+		}
+		return &status
+	}
+
+	// a node that hasn't started yet is live but not ready
+	expect(false, http.StatusOK, true, false)
+	expect(true, http.StatusServiceUnavailable, true, false)
+
+	Require(t, streamer.Start(ctx))
+	exec.Start(ctx)
+	expect(false, http.StatusOK, true, true)
+	expect(true, http.StatusOK, true, true)
+
+	// falling too far behind the sync target makes the node unready
+	msgCount, err := streamer.GetMessageCount()
+	Require(t, err)
+	syncMonitor.syncTargetLock.Lock()
+	// #nosec G115
+	syncMonitor.syncTarget = msgCount + arbutil.MessageIndex(config.MaxMessageLag) + 1
+	syncMonitor.syncTargetLock.Unlock()
+	status := expect(true, http.StatusServiceUnavailable, true, false)
+	if lag := status.Subsystems["transactionStreamer"].Details["lag"]; lag != float64(config.MaxMessageLag+1) {
+		Fail(t, "unexpected lag", lag)
+	}
+	config.MaxMessageLag++
+	expect(true, http.StatusOK, true, true)
+
+	// a stopped subsystem makes the node not live
+	exec.StopAndWait()
+	streamer.StopAndWait()
+	expect(false, http.StatusServiceUnavailable, false, false)
+
+	// only GET and HEAD are served
+	recorder := httptest.NewRecorder()
+	checker.Handler(false).ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, HealthPath, nil))
+	if recorder.Code != http.StatusMethodNotAllowed {
+		Fail(t, "unexpected status code for POST", recorder.Code)
+	}
+}
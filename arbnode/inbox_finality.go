@@ -0,0 +1,165 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbnode
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/util/headerreader"
+)
+
+// finalityCache holds the message count of the batches posted at or before the parent chain's safe or finalized
+// block. It's kept up to date by the inbox reader, so serving the safe and finalized block labels doesn't need
+// parent chain requests. Entries are keyed by the parent chain block's hash and the accumulator of the last batch
+// counted, so neither a parent chain reorg to a block of the same height nor a batch reorg leaves them stale.
+type finalityCache struct {
+	mutex                sync.RWMutex
+	valid                bool
+	parentChainBlockHash common.Hash
+	batchCount           uint64
+	batchAcc             common.Hash
+	msgCount             arbutil.MessageIndex
+}
+
+func (c *finalityCache) get() (arbutil.MessageIndex, uint64, common.Hash, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.msgCount, c.batchCount, c.batchAcc, c.valid
+}
+
+func (c *finalityCache) set(parentChainBlockHash common.Hash, batchCount uint64, batchAcc common.Hash, msgCount arbutil.MessageIndex) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.valid && c.parentChainBlockHash == parentChainBlockHash && c.batchCount == batchCount && c.batchAcc == batchAcc {
+		return
+	}
+	c.valid = true
+	c.parentChainBlockHash = parentChainBlockHash
+	c.batchCount = batchCount
+	c.batchAcc = batchAcc
+	c.msgCount = msgCount
+}
+
+// batchAccAt returns the accumulator of the batch before batchCount, or the zero hash if there is none.
+func (r *InboxReader) batchAccAt(batchCount uint64) (common.Hash, error) {
+	if batchCount == 0 {
+		return common.Hash{}, nil
+	}
+	return r.tracker.GetBatchAcc(batchCount - 1)
+}
+
+// recentParentChainBlockToBatch returns the number of batches posted at or before a recent parent chain block, and
+// the number of messages in them.
+func (r *InboxReader) recentParentChainBlockToBatch(ctx context.Context, parentChainBlock uint64) (uint64, arbutil.MessageIndex, error) {
+	batch, err := r.tracker.GetBatchCount()
+	if err != nil {
+		return 0, 0, err
+	}
+	// assumes the block is recent so we can do a simple search from the end
+	for batch > 0 {
+		if ctx.Err() != nil {
+			return 0, 0, ctx.Err()
+		}
+		meta, err := r.tracker.GetBatchMetadata(batch - 1)
+		if err != nil {
+			return 0, 0, err
+		}
+		if meta.ParentChainBlock <= parentChainBlock {
+			return batch, meta.MessageCount, nil
+		}
+		batch--
+	}
+	return 0, 0, nil
+}
+
+func (r *InboxReader) updateFinalityCache(ctx context.Context, cache *finalityCache, latestHeader func(context.Context) (*types.Header, error)) {
+	header, err := latestHeader(ctx)
+	if err != nil {
+		if !errors.Is(err, headerreader.ErrBlockNumberNotSupported) && ctx.Err() == nil {
+			log.Debug("error reading parent chain finality", "err", err)
+		}
+		return
+	}
+	parentChainBlock := header.Number.Uint64()
+	batchCount, msgCount, err := r.recentParentChainBlockToBatch(ctx, parentChainBlock)
+	if err == nil {
+		var batchAcc common.Hash
+		batchAcc, err = r.batchAccAt(batchCount)
+		if err == nil {
+			cache.set(header.Hash(), batchCount, batchAcc, msgCount)
+			return
+		}
+	}
+	if ctx.Err() == nil {
+		log.Warn("error mapping parent chain finality to messages", "parentChainBlock", parentChainBlock, "err", err)
+	}
+}
+
+// updateFinality refreshes the safe and finalized message counts. It's called on every new parent chain header and
+// after every inbox read, so batches that were read late are counted too.
+func (r *InboxReader) updateFinality(ctx context.Context) {
+	if r.l1Reader == nil || !r.l1Reader.UseFinalityData() {
+		return
+	}
+	r.updateFinalityCache(ctx, &r.safe, r.l1Reader.LatestSafeBlockHeader)
+	r.updateFinalityCache(ctx, &r.finalized, r.l1Reader.LatestFinalizedBlockHeader)
+}
+
+func (r *InboxReader) followFinality(ctx context.Context) {
+	headerChan, unsubscribe := r.l1Reader.Subscribe(false)
+	defer unsubscribe()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case _, ok := <-headerChan:
+			if !ok {
+				return
+			}
+			r.updateFinality(ctx)
+		}
+	}
+}
+
+// finalityMsgCount returns the cached message count, unless it isn't known yet or batches were reorged since it was
+// cached, in which case it's computed from the parent chain.
+func (r *InboxReader) finalityMsgCount(ctx context.Context, cache *finalityCache, latestBlockNr func(context.Context) (uint64, error)) (arbutil.MessageIndex, error) {
+	msgCount, batchCount, batchAcc, valid := cache.get()
+	if valid {
+		currentBatchCount, err := r.tracker.GetBatchCount()
+		if err != nil {
+			return 0, err
+		}
+		if batchCount <= currentBatchCount {
+			currentBatchAcc, err := r.batchAccAt(batchCount)
+			if err != nil {
+				return 0, err
+			}
+			if currentBatchAcc == batchAcc {
+				return msgCount, nil
+			}
+		}
+	}
+	parentChainBlock, err := latestBlockNr(ctx)
+	if err != nil {
+		return 0, err
+	}
+	_, msgCount, err = r.recentParentChainBlockToBatch(ctx, parentChainBlock)
+	return msgCount, err
+}
+
+func (r *InboxReader) GetSafeMsgCount(ctx context.Context) (arbutil.MessageIndex, error) {
+	return r.finalityMsgCount(ctx, &r.safe, r.l1Reader.LatestSafeBlockNr)
+}
+
+func (r *InboxReader) GetFinalizedMsgCount(ctx context.Context) (arbutil.MessageIndex, error) {
+	return r.finalityMsgCount(ctx, &r.finalized, r.l1Reader.LatestFinalizedBlockNr)
+}
@@ -0,0 +1,76 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbnode
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/util/containers"
+)
+
+func TestFinalityCacheFollowsReorgs(t *testing.T) {
+	ctx := context.Background()
+	tracker := &InboxTracker{
+		db:        rawdb.NewMemoryDatabase(),
+		batchMeta: containers.NewLruCache[uint64, BatchMetadata](100),
+	}
+	// one batch of ten messages per parent chain block, starting at block 10
+	writeBatches := func(count uint64, accSalt byte) {
+		t.Helper()
+		for i := uint64(0); i < count; i++ {
+			data, err := rlp.EncodeToBytes(BatchMetadata{
+				Accumulator:      common.Hash{accSalt, byte(i)},
+				MessageCount:     arbutil.MessageIndex(10 * (i + 1)),
+				ParentChainBlock: 10 + i,
+			})
+			Require(t, err)
+			Require(t, tracker.db.Put(dbKey(sequencerBatchMetaPrefix, i), data))
+		}
+		countData, err := rlp.EncodeToBytes(count)
+		Require(t, err)
+		Require(t, tracker.db.Put(sequencerBatchCountKey, countData))
+		tracker.batchMeta.Clear()
+	}
+	writeBatches(5, 1)
+	reader := &InboxReader{tracker: tracker}
+
+	header := &types.Header{Number: big.NewInt(12), Extra: []byte{1}}
+	latestHeader := func(context.Context) (*types.Header, error) { return header, nil }
+	latestBlockNr := func(context.Context) (uint64, error) { return header.Number.Uint64(), nil }
+	expect := func(expected arbutil.MessageIndex) {
+		t.Helper()
+		msgCount, err := reader.finalityMsgCount(ctx, &reader.safe, latestBlockNr)
+		Require(t, err)
+		if msgCount != expected {
+			Fail(t, "unexpected finality message count", msgCount, "expected", expected)
+		}
+	}
+
+	reader.updateFinalityCache(ctx, &reader.safe, latestHeader)
+	expect(30)
+
+	// a batch reorg invalidates the cached count even though the batch count didn't shrink
+	writeBatches(5, 2)
+	header = &types.Header{Number: big.NewInt(11), Extra: []byte{1}}
+	expect(20)
+
+	// a parent chain reorg to a block of the same height replaces the cached entry
+	reader.updateFinalityCache(ctx, &reader.safe, latestHeader)
+	hash := reader.safe.parentChainBlockHash
+	writeBatches(5, 3)
+	header = &types.Header{Number: big.NewInt(11), Extra: []byte{2}}
+	reader.updateFinalityCache(ctx, &reader.safe, latestHeader)
+	if reader.safe.parentChainBlockHash == hash || reader.safe.parentChainBlockHash != header.Hash() {
+		Fail(t, "finality cache wasn't keyed by the new block hash")
+	}
+	expect(20)
+}
@@ -99,6 +99,9 @@ type InboxReader struct {
 	// Atomic
 	lastSeenBatchCount atomic.Uint64
 	lastReadBatchCount atomic.Uint64
+
+	safe      finalityCache
+	finalized finalityCache
 }
 
 func NewInboxReader(tracker *InboxTracker, client arbutil.L1Interface, l1Reader *headerreader.HeaderReader, firstMessageBlock *big.Int, delayedBridge *DelayedBridge, sequencerInbox *SequencerInbox, config InboxReaderConfigFetcher) (*InboxReader, error) {
@@ -129,8 +132,12 @@ func (r *InboxReader) Start(ctxIn context.Context) error {
 		} else {
 			hadError = false
 		}
+		r.updateFinality(ctx)
 		return time.Second
 	})
+	if r.l1Reader != nil {
+		r.LaunchThread(r.followFinality)
+	}
 
 	// Ensure we read the init message before other things start up
 	for i := 0; ; i++ {
@@ -176,46 +183,6 @@ func (r *InboxReader) Start(ctxIn context.Context) error {
 	return nil
 }
 
-// assumes l1block is recent so we could do a simple-search from the end
-func (r *InboxReader) recentParentChainBlockToMsg(ctx context.Context, parentChainBlock uint64) (arbutil.MessageIndex, error) {
-	batch, err := r.tracker.GetBatchCount()
-	if err != nil {
-		return 0, err
-	}
-	for {
-		if ctx.Err() != nil {
-			return 0, ctx.Err()
-		}
-		if batch == 0 {
-			return 0, nil
-		}
-		batch -= 1
-		meta, err := r.tracker.GetBatchMetadata(batch)
-		if err != nil {
-			return 0, err
-		}
-		if meta.ParentChainBlock <= parentChainBlock {
-			return meta.MessageCount, nil
-		}
-	}
-}
-
-func (r *InboxReader) GetSafeMsgCount(ctx context.Context) (arbutil.MessageIndex, error) {
-	l1block, err := r.l1Reader.LatestSafeBlockNr(ctx)
-	if err != nil {
-		return 0, err
-	}
-	return r.recentParentChainBlockToMsg(ctx, l1block)
-}
-
-func (r *InboxReader) GetFinalizedMsgCount(ctx context.Context) (arbutil.MessageIndex, error) {
-	l1block, err := r.l1Reader.LatestFinalizedBlockNr(ctx)
-	if err != nil {
-		return 0, err
-	}
-	return r.recentParentChainBlockToMsg(ctx, l1block)
-}
-
 func (r *InboxReader) Tracker() *InboxTracker {
 	return r.tracker
 }
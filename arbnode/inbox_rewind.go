@@ -0,0 +1,188 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbnode
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/offchainlabs/nitro/arbutil"
+)
+
+// RewindPlan describes the inbox state left after rewinding, and what the rewind removes.
+type RewindPlan struct {
+	BatchCount          uint64               `json:"batchCount"`
+	MessageCount        arbutil.MessageIndex `json:"messageCount"`
+	DelayedMessageCount uint64               `json:"delayedMessageCount"`
+	// the accumulators of the last kept batch and delayed message, checked against the parent chain if it's read
+	BatchAccumulator    common.Hash `json:"batchAccumulator"`
+	DelayedAccumulator  common.Hash `json:"delayedAccumulator"`
+	ParentChainVerified bool        `json:"parentChainVerified"`
+
+	RemovedBatches         uint64 `json:"removedBatches"`
+	RemovedMessages        uint64 `json:"removedMessages"`
+	RemovedDelayedMessages uint64 `json:"removedDelayedMessages"`
+}
+
+// BatchCountAtParentChainBlock returns the number of batches posted at or before the given parent chain block.
+func (t *InboxTracker) BatchCountAtParentChainBlock(block uint64) (uint64, error) {
+	count, err := t.GetBatchCount()
+	if err != nil {
+		return 0, err
+	}
+	var searchErr error
+	// #nosec G115
+	idx := sort.Search(int(count), func(i int) bool {
+		if searchErr != nil {
+			return true
+		}
+		// #nosec G115
+		batchBlock, err := t.GetBatchParentChainBlock(uint64(i))
+		if err != nil {
+			searchErr = err
+			return true
+		}
+		return batchBlock > block
+	})
+	// #nosec G115
+	return uint64(idx), searchErr
+}
+
+// DelayedCountAtParentChainBlock returns the number of delayed messages delivered at or before the given parent
+// chain block.
+func (t *InboxTracker) DelayedCountAtParentChainBlock(ctx context.Context, block uint64) (uint64, error) {
+	count, err := t.GetDelayedCount()
+	if err != nil {
+		return 0, err
+	}
+	var searchErr error
+	// #nosec G115
+	idx := sort.Search(int(count), func(i int) bool {
+		if searchErr != nil {
+			return true
+		}
+		// #nosec G115
+		_, _, messageBlock, err := t.GetDelayedMessageAccumulatorAndParentChainBlockNumber(ctx, uint64(i))
+		if err != nil {
+			searchErr = err
+			return true
+		}
+		return messageBlock > block
+	})
+	// #nosec G115
+	return uint64(idx), searchErr
+}
+
+// PlanRewind checks that the inbox can be rewound to the given batch and delayed message counts. The kept batches
+// and delayed messages must be consistent, and if a reader is given, their last accumulators must match the parent
+// chain's, so the reader can continue from them.
+func (t *InboxTracker) PlanRewind(ctx context.Context, reader *InboxReader, batchCount uint64, delayedCount uint64) (*RewindPlan, error) {
+	currentBatchCount, err := t.GetBatchCount()
+	if err != nil {
+		return nil, err
+	}
+	currentDelayedCount, err := t.GetDelayedCount()
+	if err != nil {
+		return nil, err
+	}
+	if batchCount > currentBatchCount {
+		return nil, fmt.Errorf("cannot rewind to batch count %v, only %v batches are known", batchCount, currentBatchCount)
+	}
+	if delayedCount > currentDelayedCount {
+		return nil, fmt.Errorf("cannot rewind to delayed message count %v, only %v delayed messages are known", delayedCount, currentDelayedCount)
+	}
+	currentMessageCount, err := t.txStreamer.GetMessageCount()
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &RewindPlan{
+		BatchCount:             batchCount,
+		DelayedMessageCount:    delayedCount,
+		RemovedBatches:         currentBatchCount - batchCount,
+		RemovedDelayedMessages: currentDelayedCount - delayedCount,
+	}
+	if batchCount > 0 {
+		meta, err := t.GetBatchMetadata(batchCount - 1)
+		if err != nil {
+			return nil, err
+		}
+		if meta.DelayedMessageCount > delayedCount {
+			return nil, fmt.Errorf("batch %v read %v delayed messages, more than the %v kept", batchCount-1, meta.DelayedMessageCount, delayedCount)
+		}
+		if meta.MessageCount > currentMessageCount {
+			return nil, fmt.Errorf("batch %v ends at message %v, after the %v messages known", batchCount-1, meta.MessageCount, currentMessageCount)
+		}
+		// the previous batch must exist and not end after this one, or the database is corrupted before this point
+		if batchCount > 1 {
+			prevMeta, err := t.GetBatchMetadata(batchCount - 2)
+			if err != nil {
+				return nil, err
+			}
+			if prevMeta.MessageCount > meta.MessageCount || prevMeta.DelayedMessageCount > meta.DelayedMessageCount {
+				return nil, fmt.Errorf("batch %v metadata is inconsistent with the previous batch", batchCount-1)
+			}
+		}
+		plan.MessageCount = meta.MessageCount
+		plan.BatchAccumulator = meta.Accumulator
+	}
+	plan.RemovedMessages = uint64(currentMessageCount - plan.MessageCount)
+	if delayedCount > 0 {
+		plan.DelayedAccumulator, err = t.GetDelayedAcc(delayedCount - 1)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if reader == nil || reader.l1Reader == nil {
+		return plan, nil
+	}
+	header, err := reader.l1Reader.LastHeader(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error reading parent chain header to verify accumulators: %w", err)
+	}
+	if batchCount > 0 {
+		l1Acc, err := reader.sequencerInbox.GetAccumulator(ctx, batchCount-1, header.Number)
+		if err != nil {
+			return nil, err
+		}
+		if l1Acc != plan.BatchAccumulator {
+			return nil, fmt.Errorf("batch %v accumulator %v doesn't match the parent chain's %v, rewind further", batchCount-1, plan.BatchAccumulator, l1Acc)
+		}
+	}
+	if delayedCount > 0 {
+		l1Acc, err := reader.delayedBridge.GetAccumulator(ctx, delayedCount-1, header.Number, common.Hash{})
+		if err != nil {
+			return nil, err
+		}
+		if l1Acc != plan.DelayedAccumulator {
+			return nil, fmt.Errorf("delayed message %v accumulator %v doesn't match the parent chain's %v, rewind further", delayedCount-1, plan.DelayedAccumulator, l1Acc)
+		}
+	}
+	plan.ParentChainVerified = true
+	return plan, nil
+}
+
+// Rewind removes the batches and delayed messages after the plan's counts, along with the messages they produced.
+// The inbox reader reads them again from the parent chain.
+func (t *InboxTracker) Rewind(plan *RewindPlan) error {
+	log.Warn("rewinding inbox", "batchCount", plan.BatchCount, "messageCount", plan.MessageCount, "delayedMessageCount", plan.DelayedMessageCount)
+	if err := t.ReorgBatchesTo(plan.BatchCount); err != nil {
+		return fmt.Errorf("error rewinding batches: %w", err)
+	}
+	delayedCount, err := t.GetDelayedCount()
+	if err != nil {
+		return err
+	}
+	if delayedCount > plan.DelayedMessageCount {
+		if err := t.ReorgDelayedTo(plan.DelayedMessageCount, false); err != nil {
+			return fmt.Errorf("error rewinding delayed messages: %w", err)
+		}
+	}
+	return nil
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/offchainlabs/nitro/arbutil"
 	"github.com/offchainlabs/nitro/broadcaster"
 	m "github.com/offchainlabs/nitro/broadcaster/message"
+	"github.com/offchainlabs/nitro/execution"
 	"github.com/offchainlabs/nitro/staker"
 	"github.com/offchainlabs/nitro/util/containers"
 )
@@ -39,17 +40,19 @@ type InboxTracker struct {
 	mutex          sync.Mutex
 	validator      *staker.BlockValidator
 	dapReaders     []daprovider.Reader
+	dictionaries   daprovider.DictionaryReader
 	snapSyncConfig SnapSyncConfig
 
 	batchMetaMutex sync.Mutex
 	batchMeta      *containers.LruCache[uint64, BatchMetadata]
 }
 
-func NewInboxTracker(db ethdb.Database, txStreamer *TransactionStreamer, dapReaders []daprovider.Reader, snapSyncConfig SnapSyncConfig) (*InboxTracker, error) {
+func NewInboxTracker(db ethdb.Database, txStreamer *TransactionStreamer, dapReaders []daprovider.Reader, dictionaries daprovider.DictionaryReader, snapSyncConfig SnapSyncConfig) (*InboxTracker, error) {
 	tracker := &InboxTracker{
 		db:             db,
 		txStreamer:     txStreamer,
 		dapReaders:     dapReaders,
+		dictionaries:   dictionaries,
 		batchMeta:      containers.NewLruCache[uint64, BatchMetadata](1000),
 		snapSyncConfig: snapSyncConfig,
 	}
@@ -730,15 +733,39 @@ func (t *InboxTracker) AddSequencerBatches(ctx context.Context, client arbutil.L
 		ctx:    ctx,
 		client: client,
 	}
-	multiplexer := arbstate.NewInboxMultiplexer(backend, prevbatchmeta.DelayedMessageCount, t.dapReaders, daprovider.KeysetValidate)
-	batchMessageCounts := make(map[uint64]arbutil.MessageIndex)
 	currentpos := prevbatchmeta.MessageCount + 1
+	dictionaries := &daprovider.CommittedDictionaries{
+		// A batch may use the dictionaries committed to before its first message, which the multiplexer pops next
+		// when it parses the batch. That's the state the replay binary reads the batch in too.
+		Committed: func(hash common.Hash) (bool, error) {
+			if currentpos <= 1 {
+				return false, nil
+			}
+			prevMessage := currentpos - 2
+			if prevMessage >= prevbatchmeta.MessageCount {
+				// execution may only have the messages this call replaces
+				return false, fmt.Errorf("%w: message %d", execution.ErrMessageNotExecuted, prevMessage)
+			}
+			return t.txStreamer.exec.BatchDictionaryCommitted(prevMessage, hash)
+		},
+		Reader: t.dictionaries,
+	}
+	multiplexer := arbstate.NewInboxMultiplexer(backend, prevbatchmeta.DelayedMessageCount, t.dapReaders, dictionaries, daprovider.KeysetValidate)
+	batchMessageCounts := make(map[uint64]arbutil.MessageIndex)
 	for {
 		if len(backend.batches) == 0 {
 			break
 		}
 		batchSeqNum := backend.batches[0].SequenceNumber
 		msg, err := multiplexer.Pop(ctx)
+		if errors.Is(err, execution.ErrMessageNotExecuted) && batchSeqNum > batches[0].SequenceNumber {
+			// The batch needs the state after the earlier batches' messages, which aren't even added yet.
+			// Add those, and have the inbox reader retry this batch once they're executed.
+			if addErr := t.AddSequencerBatches(ctx, client, batches[:batchSeqNum-batches[0].SequenceNumber]); addErr != nil {
+				return addErr
+			}
+			return err
+		}
 		if err != nil {
 			return err
 		}
@@ -1,9 +1,12 @@
 package arbnode
 
 import (
+	"context"
 	"testing"
 
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/offchainlabs/nitro/util/containers"
 )
 
@@ -56,3 +59,48 @@ func TestDeleteBatchMetadata(t *testing.T) {
 	}
 
 }
+
+func TestBatchCountAtParentChainBlock(t *testing.T) {
+	tracker := &InboxTracker{
+		db:        rawdb.NewMemoryDatabase(),
+		batchMeta: containers.NewLruCache[uint64, BatchMetadata](100),
+	}
+
+	// two batches per parent chain block, starting at block 10
+	batchCount := uint64(20)
+	for i := uint64(0); i < batchCount; i++ {
+		data, err := rlp.EncodeToBytes(BatchMetadata{ParentChainBlock: 10 + i/2})
+		Require(t, err)
+		Require(t, tracker.db.Put(dbKey(sequencerBatchMetaPrefix, i), data))
+	}
+	countData, err := rlp.EncodeToBytes(batchCount)
+	Require(t, err)
+	Require(t, tracker.db.Put(sequencerBatchCountKey, countData))
+
+	for _, test := range []struct{ block, count uint64 }{
+		{0, 0},
+		{9, 0},
+		{10, 2},
+		{14, 10},
+		{19, 20},
+		{100, 20},
+	} {
+		count, err := tracker.BatchCountAtParentChainBlock(test.block)
+		Require(t, err)
+		if count != test.count {
+			Fail(t, "unexpected batch count at block", test.block, "got", count, "expected", test.count)
+		}
+	}
+}
+
+func TestInboxRewindRefusedOnSequencer(t *testing.T) {
+	tracker := &InboxTracker{
+		db:        rawdb.NewMemoryDatabase(),
+		batchMeta: containers.NewLruCache[uint64, BatchMetadata](100),
+	}
+	api := &InboxAPI{tracker: tracker, sequencer: true}
+	batchCount := hexutil.Uint64(0)
+	if _, err := api.Rewind(context.Background(), InboxRewindTarget{BatchCount: &batchCount}); err == nil {
+		Fail(t, "a sequencer's inbox was rewound")
+	}
+}
@@ -0,0 +1,271 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbnode
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/offchainlabs/nitro/arbos/arbostypes"
+)
+
+// InboxDivergence is the first delayed message or batch at which the inbox tracker's database is inconsistent,
+// either with itself or with the L1 contracts.
+type InboxDivergence struct {
+	Delayed bool   `json:"delayed"` // whether Index is a delayed message, rather than a batch
+	Index   uint64 `json:"index"`
+	Reason  string `json:"reason"`
+}
+
+func (d *InboxDivergence) String() string {
+	if d.Delayed {
+		return fmt.Sprintf("delayed message %d: %s", d.Index, d.Reason)
+	}
+	return fmt.Sprintf("batch %d: %s", d.Index, d.Reason)
+}
+
+type InboxVerification struct {
+	DelayedCount uint64           `json:"delayedCount"`
+	BatchCount   uint64           `json:"batchCount"`
+	MessageCount uint64           `json:"messageCount"`
+	CheckedL1    bool             `json:"checkedL1"`
+	Divergence   *InboxDivergence `json:"divergence,omitempty"`
+}
+
+// readDelayedEntry reads a delayed message and the accumulator after it as stored, without filling in the batch
+// gas cost of batch posting reports, which doesn't affect the accumulator.
+func (t *InboxTracker) readDelayedEntry(seqNum uint64) (*arbostypes.L1IncomingMessage, common.Hash, bool, error) {
+	legacy := false
+	key := dbKey(rlpDelayedMessagePrefix, seqNum)
+	has, err := t.db.Has(key)
+	if err != nil {
+		return nil, common.Hash{}, false, err
+	}
+	if !has {
+		legacy = true
+		key = dbKey(legacyDelayedMessagePrefix, seqNum)
+		has, err = t.db.Has(key)
+		if err != nil || !has {
+			return nil, common.Hash{}, false, err
+		}
+	}
+	data, err := t.db.Get(key)
+	if err != nil {
+		return nil, common.Hash{}, false, err
+	}
+	if len(data) < 32 {
+		return nil, common.Hash{}, false, fmt.Errorf("delayed message %d entry missing accumulator", seqNum)
+	}
+	acc := common.BytesToHash(data[:32])
+	var msg *arbostypes.L1IncomingMessage
+	if legacy {
+		msg, err = arbostypes.ParseIncomingL1Message(bytes.NewReader(data[32:]), nil)
+	} else {
+		err = rlp.DecodeBytes(data[32:], &msg)
+	}
+	if err != nil {
+		return nil, common.Hash{}, false, fmt.Errorf("decoding delayed message %d: %w", seqNum, err)
+	}
+	return msg, acc, true, nil
+}
+
+// verifyDelayedAccumulators recomputes each stored delayed accumulator from the one before it and its message.
+// A database initialized by snap sync lacks the first messages, so the chain is checked from the first one present.
+func (t *InboxTracker) verifyDelayedAccumulators(ctx context.Context, count uint64) (*InboxDivergence, error) {
+	var prevAcc common.Hash
+	known := false
+	for seqNum := uint64(0); seqNum < count; seqNum++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		msg, acc, found, err := t.readDelayedEntry(seqNum)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			if known {
+				return &InboxDivergence{Delayed: true, Index: seqNum, Reason: "message missing from the database"}, nil
+			}
+			continue
+		}
+		if msgSeqNum, err := msg.Header.SeqNum(); err != nil || msgSeqNum != seqNum {
+			return &InboxDivergence{Delayed: true, Index: seqNum, Reason: fmt.Sprintf("message has sequence number %d", msgSeqNum)}, nil
+		}
+		if known {
+			expected := (&DelayedInboxMessage{BeforeInboxAcc: prevAcc, Message: msg}).AfterInboxAcc()
+			if acc != expected {
+				reason := fmt.Sprintf("stored accumulator %v doesn't match recomputed %v", acc, expected)
+				return &InboxDivergence{Delayed: true, Index: seqNum, Reason: reason}, nil
+			}
+		}
+		prevAcc = acc
+		known = true
+		if seqNum%1_000_000 == 0 && seqNum > 0 {
+			log.Info("verified delayed accumulators", "count", seqNum)
+		}
+	}
+	return nil, nil
+}
+
+// verifyBatchMetadata checks that the message and delayed message counts of the stored batches never go backwards
+// and stay within what's been read. Batch data isn't stored, so batch accumulators can only be checked against L1.
+func (t *InboxTracker) verifyBatchMetadata(ctx context.Context, count uint64, delayedCount uint64, messageCount uint64) (*InboxDivergence, error) {
+	var prev BatchMetadata
+	known := false
+	for seqNum := uint64(0); seqNum < count; seqNum++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		meta, err := t.GetBatchMetadata(seqNum)
+		if errors.Is(err, AccumulatorNotFoundErr) {
+			if known {
+				return &InboxDivergence{Index: seqNum, Reason: "batch missing from the database"}, nil
+			}
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		var reason string
+		switch {
+		case meta.MessageCount < prev.MessageCount:
+			reason = fmt.Sprintf("message count went backwards from %d to %d", prev.MessageCount, meta.MessageCount)
+		case meta.DelayedMessageCount < prev.DelayedMessageCount:
+			reason = fmt.Sprintf("delayed message count went backwards from %d to %d", prev.DelayedMessageCount, meta.DelayedMessageCount)
+		case meta.DelayedMessageCount > delayedCount:
+			reason = fmt.Sprintf("reads %d delayed messages but only %d are stored", meta.DelayedMessageCount, delayedCount)
+		case uint64(meta.MessageCount) > messageCount:
+			reason = fmt.Sprintf("ends at message %d but only %d messages are stored", meta.MessageCount, messageCount)
+		}
+		if reason != "" {
+			return &InboxDivergence{Index: seqNum, Reason: reason}, nil
+		}
+		prev = meta
+		known = true
+	}
+	return nil, nil
+}
+
+// firstL1Mismatch returns the first index below count whose stored accumulator differs from L1's, or count if none
+// does. Accumulators commit to everything before them, so once one matches all earlier ones do, and the first
+// mismatch can be found in a logarithmic number of calls.
+func firstL1Mismatch(count uint64, matches func(uint64) (bool, error)) (uint64, error) {
+	var searchErr error
+	// #nosec G115
+	first := sort.Search(int(count), func(i int) bool {
+		if searchErr != nil {
+			return true
+		}
+		// #nosec G115
+		match, err := matches(uint64(i))
+		if err != nil {
+			searchErr = err
+			return true
+		}
+		return !match
+	})
+	// #nosec G115
+	return uint64(first), searchErr
+}
+
+// VerifyInbox checks the inbox tracker's database: it recomputes the delayed accumulators, checks the batch metadata,
+// and if the L1 contracts are given, finds the first delayed message and batch whose stored accumulator isn't L1's.
+// It returns the first divergence found, delayed messages first as batches depend on them.
+func (t *InboxTracker) VerifyInbox(ctx context.Context, delayedBridge *DelayedBridge, sequencerInbox *SequencerInbox) (*InboxVerification, error) {
+	delayedCount, err := t.GetDelayedCount()
+	if err != nil {
+		return nil, err
+	}
+	batchCount, err := t.GetBatchCount()
+	if err != nil {
+		return nil, err
+	}
+	messageCountBytes, err := t.db.Get(messageCountKey)
+	if err != nil {
+		return nil, err
+	}
+	var messageCount uint64
+	if err := rlp.DecodeBytes(messageCountBytes, &messageCount); err != nil {
+		return nil, err
+	}
+	result := &InboxVerification{
+		DelayedCount: delayedCount,
+		BatchCount:   batchCount,
+		MessageCount: messageCount,
+		CheckedL1:    delayedBridge != nil && sequencerInbox != nil,
+	}
+
+	result.Divergence, err = t.verifyDelayedAccumulators(ctx, delayedCount)
+	if err != nil || result.Divergence != nil {
+		return result, err
+	}
+	if delayedBridge != nil {
+		l1Count, err := delayedBridge.GetMessageCount(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		first, err := firstL1Mismatch(min(delayedCount, l1Count), func(seqNum uint64) (bool, error) {
+			_, acc, found, err := t.readDelayedEntry(seqNum)
+			if err != nil || !found {
+				// messages before a snap sync's first one aren't stored, and are taken to match
+				return !found, err
+			}
+			l1Acc, err := delayedBridge.GetAccumulator(ctx, seqNum, nil, common.Hash{})
+			return acc == l1Acc, err
+		})
+		if err != nil {
+			return nil, err
+		}
+		if first < min(delayedCount, l1Count) {
+			result.Divergence = &InboxDivergence{Delayed: true, Index: first, Reason: "stored accumulator doesn't match L1's"}
+			return result, nil
+		}
+		if delayedCount > l1Count {
+			reason := fmt.Sprintf("message isn't on L1, which has only %d delayed messages", l1Count)
+			result.Divergence = &InboxDivergence{Delayed: true, Index: l1Count, Reason: reason}
+			return result, nil
+		}
+	}
+
+	result.Divergence, err = t.verifyBatchMetadata(ctx, batchCount, delayedCount, messageCount)
+	if err != nil || result.Divergence != nil {
+		return result, err
+	}
+	if sequencerInbox != nil {
+		l1Count, err := sequencerInbox.GetBatchCount(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		first, err := firstL1Mismatch(min(batchCount, l1Count), func(seqNum uint64) (bool, error) {
+			meta, err := t.GetBatchMetadata(seqNum)
+			if errors.Is(err, AccumulatorNotFoundErr) {
+				return true, nil
+			}
+			if err != nil {
+				return false, err
+			}
+			l1Acc, err := sequencerInbox.GetAccumulator(ctx, seqNum, nil)
+			return meta.Accumulator == l1Acc, err
+		})
+		if err != nil {
+			return nil, err
+		}
+		if first < min(batchCount, l1Count) {
+			result.Divergence = &InboxDivergence{Index: first, Reason: "stored accumulator doesn't match L1's"}
+			return result, nil
+		}
+		if batchCount > l1Count {
+			reason := fmt.Sprintf("batch isn't on L1, which has only %d batches", l1Count)
+			result.Divergence = &InboxDivergence{Index: l1Count, Reason: reason}
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,77 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbnode
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/offchainlabs/nitro/arbos/arbostypes"
+	"github.com/offchainlabs/nitro/util/containers"
+)
+
+func TestVerifyDelayedAccumulators(t *testing.T) {
+	tracker := &InboxTracker{
+		db:        rawdb.NewMemoryDatabase(),
+		batchMeta: containers.NewLruCache[uint64, BatchMetadata](100),
+	}
+	putMessage := func(seqNum uint64, before common.Hash, l2msg []byte) common.Hash {
+		requestId := common.BigToHash(new(big.Int).SetUint64(seqNum))
+		msg := &DelayedInboxMessage{
+			BeforeInboxAcc: before,
+			Message: &arbostypes.L1IncomingMessage{
+				Header: &arbostypes.L1IncomingMessageHeader{
+					Kind:      arbostypes.L1MessageType_L2Message,
+					RequestId: &requestId,
+					L1BaseFee: big.NewInt(1),
+				},
+				L2msg: l2msg,
+			},
+		}
+		acc := msg.AfterInboxAcc()
+		data, err := rlp.EncodeToBytes(msg.Message)
+		Require(t, err)
+		Require(t, tracker.db.Put(dbKey(rlpDelayedMessagePrefix, seqNum), append(acc.Bytes(), data...)))
+		return acc
+	}
+	var acc common.Hash
+	accs := make([]common.Hash, 3)
+	for i := range accs {
+		// #nosec G115
+		acc = putMessage(uint64(i), acc, []byte{byte(i)})
+		accs[i] = acc
+	}
+	divergence, err := tracker.verifyDelayedAccumulators(context.Background(), 3)
+	Require(t, err)
+	if divergence != nil {
+		Fail(t, "consistent accumulators diverged at", divergence)
+	}
+
+	// a message that doesn't match its stored accumulator is reported
+	putMessage(1, accs[0], []byte{0xff})
+	divergence, err = tracker.verifyDelayedAccumulators(context.Background(), 3)
+	Require(t, err)
+	if divergence == nil || !divergence.Delayed || divergence.Index != 2 {
+		Fail(t, "wrong divergence", divergence)
+	}
+}
+
+func TestFirstL1Mismatch(t *testing.T) {
+	for _, diverged := range []uint64{0, 1, 7, 10} {
+		calls := 0
+		first, err := firstL1Mismatch(10, func(i uint64) (bool, error) {
+			calls++
+			return i < diverged, nil
+		})
+		Require(t, err)
+		if first != diverged || calls > 4 {
+			Fail(t, "wrong first mismatch", first, "expected", diverged, "calls", calls)
+		}
+	}
+}
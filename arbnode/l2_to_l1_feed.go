@@ -0,0 +1,344 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbnode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/offchainlabs/nitro/arbos/util"
+	"github.com/offchainlabs/nitro/cmd/chaininfo"
+	"github.com/offchainlabs/nitro/execution/outboxproof"
+	"github.com/offchainlabs/nitro/staker"
+	"github.com/offchainlabs/nitro/util/arbmath"
+	"github.com/offchainlabs/nitro/util/headerreader"
+	"github.com/offchainlabs/nitro/util/stopwaiter"
+)
+
+var (
+	l2ToL1FeedMessagesCounter  = metrics.NewRegisteredCounter("arb/l2tol1feed/messages", nil)
+	l2ToL1FeedSendCountGauge   = metrics.NewRegisteredGauge("arb/l2tol1feed/confirmed/sendcount", nil)
+	errL2ToL1FeedRangeTooLarge = errors.New("block range too large")
+)
+
+// L2ToL1FeedConfig configures a service that notifies RPC subscribers of new L2 to L1 messages, and of the
+// parent chain confirming the send roots that let them be executed in the outbox.
+type L2ToL1FeedConfig struct {
+	Enable                   bool          `koanf:"enable"`
+	PollInterval             time.Duration `koanf:"poll-interval" reload:"hot"`
+	ConfirmationPollInterval time.Duration `koanf:"confirmation-poll-interval" reload:"hot"`
+	MaxBlockRange            uint64        `koanf:"max-block-range" reload:"hot"`
+}
+
+type L2ToL1FeedConfigFetcher func() *L2ToL1FeedConfig
+
+func (c *L2ToL1FeedConfig) Validate() error {
+	if !c.Enable {
+		return nil
+	}
+	if c.PollInterval <= 0 || c.ConfirmationPollInterval <= 0 || c.MaxBlockRange == 0 {
+		return errors.New("L2 to L1 feed poll intervals and max block range must be positive")
+	}
+	return nil
+}
+
+func L2ToL1FeedConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.Bool(prefix+".enable", DefaultL2ToL1FeedConfig.Enable, "serve subscriptions to new L2 to L1 messages and to the confirmation of their send roots on the parent chain")
+	f.Duration(prefix+".poll-interval", DefaultL2ToL1FeedConfig.PollInterval, "how often to look for new L2 to L1 messages")
+	f.Duration(prefix+".confirmation-poll-interval", DefaultL2ToL1FeedConfig.ConfirmationPollInterval, "how often to check the parent chain for newly confirmed send roots")
+	f.Uint64(prefix+".max-block-range", DefaultL2ToL1FeedConfig.MaxBlockRange, "most L2 blocks to look through per poll, and per arb_getL2ToL1Messages request")
+}
+
+var DefaultL2ToL1FeedConfig = L2ToL1FeedConfig{
+	Enable:                   false,
+	PollInterval:             time.Second,
+	ConfirmationPollInterval: time.Minute,
+	MaxBlockRange:            1_000,
+}
+
+var TestL2ToL1FeedConfig = L2ToL1FeedConfig{
+	Enable:                   true,
+	PollInterval:             time.Millisecond * 10,
+	ConfirmationPollInterval: time.Millisecond * 100,
+	MaxBlockRange:            1_000,
+}
+
+// L2ToL1Message is an L2ToL1Tx event ArbSys emitted for a message sent to the parent chain, with where it was
+// emitted and whether a confirmed send root covers it yet.
+type L2ToL1Message struct {
+	Position    hexutil.Uint64 `json:"position"`
+	Hash        common.Hash    `json:"hash"`
+	Caller      common.Address `json:"caller"`
+	Destination common.Address `json:"destination"`
+	ArbBlockNum hexutil.Uint64 `json:"arbBlockNum"`
+	EthBlockNum hexutil.Uint64 `json:"ethBlockNum"`
+	Timestamp   hexutil.Uint64 `json:"timestamp"`
+	Callvalue   *hexutil.Big   `json:"callvalue"`
+	Data        hexutil.Bytes  `json:"data"`
+	BlockHash   common.Hash    `json:"blockHash"`
+	TxHash      common.Hash    `json:"transactionHash"`
+	Confirmed   bool           `json:"confirmed"`
+}
+
+// L2ToL1Confirmation is the rollup confirming an assertion, which lets the messages at positions below its send
+// count be executed in the outbox with proofs against its send root.
+type L2ToL1Confirmation struct {
+	Assertion   hexutil.Uint64 `json:"assertion"`
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	BlockHash   common.Hash    `json:"blockHash"`
+	SendRoot    common.Hash    `json:"sendRoot"`
+	SendCount   hexutil.Uint64 `json:"sendCount"`
+}
+
+// Covers returns whether the confirmed send root includes the message at the position.
+func (c *L2ToL1Confirmation) Covers(position uint64) bool {
+	return c != nil && position < uint64(c.SendCount)
+}
+
+// L2ToL1Feed follows the node's blocks for L2 to L1 messages, and the rollup for confirmed assertions, notifying
+// subscribers of each, so that withdrawal UIs don't have to poll logs and work out confirmation themselves.
+// Messages in blocks replaced by a reorg aren't retracted, and the replacing blocks' messages are notified from
+// the fork point on.
+type L2ToL1Feed struct {
+	stopwaiter.StopWaiter
+	rollup *staker.RollupWatcher
+	bc     *core.BlockChain
+	config L2ToL1FeedConfigFetcher
+
+	messageFeed      event.Feed
+	confirmationFeed event.Feed
+	confirmation     atomic.Pointer[L2ToL1Confirmation]
+
+	// only accessed by the polling thread
+	scannedBlock      uint64
+	scannedHash       common.Hash
+	lastConfirmations time.Time
+}
+
+func NewL2ToL1Feed(l1Reader *headerreader.HeaderReader, deployInfo *chaininfo.RollupAddresses, bc *core.BlockChain, config L2ToL1FeedConfigFetcher) (*L2ToL1Feed, error) {
+	rollup, err := staker.NewRollupWatcher(deployInfo.Rollup, l1Reader.Client(), bind.CallOpts{})
+	if err != nil {
+		return nil, err
+	}
+	return &L2ToL1Feed{
+		rollup: rollup,
+		bc:     bc,
+		config: config,
+	}, nil
+}
+
+// Confirmation returns the latest confirmation seen, or nil if none has been yet.
+func (f *L2ToL1Feed) Confirmation() *L2ToL1Confirmation {
+	return f.confirmation.Load()
+}
+
+// Messages returns the L2 to L1 messages sent in the block, in position order.
+func (f *L2ToL1Feed) Messages(blockNumber uint64) ([]*L2ToL1Message, error) {
+	block := f.bc.GetBlockByNumber(blockNumber)
+	if block == nil {
+		return nil, fmt.Errorf("block %v not found", blockNumber)
+	}
+	return f.blockMessages(block)
+}
+
+func (f *L2ToL1Feed) blockMessages(block *types.Block) ([]*L2ToL1Message, error) {
+	confirmation := f.Confirmation()
+	var messages []*L2ToL1Message
+	for _, receipt := range f.bc.GetReceiptsByHash(block.Hash()) {
+		for _, sendLog := range outboxproof.L2ToL1Txs(receipt) {
+			tx, err := util.ParseL2ToL1TxLog(sendLog)
+			if err != nil {
+				return nil, err
+			}
+			if !tx.Position.IsUint64() || !tx.ArbBlockNum.IsUint64() || !tx.EthBlockNum.IsUint64() || !tx.Timestamp.IsUint64() {
+				return nil, fmt.Errorf("malformed L2ToL1Tx event in transaction %v", sendLog.TxHash)
+			}
+			position := tx.Position.Uint64()
+			messages = append(messages, &L2ToL1Message{
+				Position:    hexutil.Uint64(position),
+				Hash:        common.BigToHash(tx.Hash),
+				Caller:      tx.Caller,
+				Destination: tx.Destination,
+				ArbBlockNum: hexutil.Uint64(tx.ArbBlockNum.Uint64()),
+				EthBlockNum: hexutil.Uint64(tx.EthBlockNum.Uint64()),
+				Timestamp:   hexutil.Uint64(tx.Timestamp.Uint64()),
+				Callvalue:   (*hexutil.Big)(tx.Callvalue),
+				Data:        tx.Data,
+				BlockHash:   block.Hash(),
+				TxHash:      sendLog.TxHash,
+				Confirmed:   confirmation.Covers(position),
+			})
+		}
+	}
+	return messages, nil
+}
+
+// scan notifies subscribers of the messages in the blocks after the last one scanned. If a reorg replaced blocks
+// already scanned, it goes back to where the chains fork and scans the replacing blocks.
+func (f *L2ToL1Feed) scan(config *L2ToL1FeedConfig) error {
+	for f.scannedBlock > 0 && f.bc.GetCanonicalHash(f.scannedBlock) != f.scannedHash {
+		header := f.bc.GetHeader(f.scannedHash, f.scannedBlock)
+		if header == nil {
+			return fmt.Errorf("scanned block %v with hash %v not found", f.scannedBlock, f.scannedHash)
+		}
+		f.scannedBlock--
+		f.scannedHash = header.ParentHash
+	}
+	head := f.bc.CurrentBlock().Number.Uint64()
+	if head <= f.scannedBlock {
+		return nil
+	}
+	to := arbmath.MinInt(head, f.scannedBlock+config.MaxBlockRange)
+	for number := f.scannedBlock + 1; number <= to; number++ {
+		block := f.bc.GetBlockByNumber(number)
+		if block == nil || block.ParentHash() != f.scannedHash {
+			// the chain reorged during the scan, so the next one starts from the fork point
+			return nil
+		}
+		messages, err := f.blockMessages(block)
+		if err != nil {
+			return err
+		}
+		for _, message := range messages {
+			f.messageFeed.Send(message)
+		}
+		l2ToL1FeedMessagesCounter.Inc(int64(len(messages)))
+		f.scannedBlock = number
+		f.scannedHash = block.Hash()
+	}
+	return nil
+}
+
+// checkConfirmed notifies subscribers if the rollup has confirmed a send root with more messages than the last.
+func (f *L2ToL1Feed) checkConfirmed(ctx context.Context) error {
+	assertion, header, err := latestConfirmedHeader(ctx, f.rollup, f.bc)
+	if err != nil || header == nil {
+		return err
+	}
+	extra := types.DeserializeHeaderExtraInformation(header)
+	if last := f.Confirmation(); last != nil && uint64(last.SendCount) >= extra.SendCount {
+		return nil
+	}
+	confirmation := &L2ToL1Confirmation{
+		Assertion:   hexutil.Uint64(assertion),
+		BlockNumber: hexutil.Uint64(header.Number.Uint64()),
+		BlockHash:   header.Hash(),
+		SendRoot:    extra.SendRoot,
+		SendCount:   hexutil.Uint64(extra.SendCount),
+	}
+	f.confirmation.Store(confirmation)
+	// #nosec G115
+	l2ToL1FeedSendCountGauge.Update(int64(extra.SendCount))
+	f.confirmationFeed.Send(confirmation)
+	log.Info("L2 to L1 messages confirmed", "assertion", assertion, "sendCount", extra.SendCount, "block", header.Number)
+	return nil
+}
+
+func (f *L2ToL1Feed) Start(ctxIn context.Context) {
+	f.StopWaiter.Start(ctxIn, f)
+	// subscribers are notified of messages sent from now on, and can ask for earlier ones
+	head := f.bc.CurrentBlock()
+	f.scannedBlock = head.Number.Uint64()
+	f.scannedHash = head.Hash()
+	f.CallIteratively(func(ctx context.Context) time.Duration {
+		config := f.config()
+		if err := f.scan(config); err != nil {
+			log.Error("error looking for new L2 to L1 messages", "err", err)
+		}
+		if time.Since(f.lastConfirmations) >= config.ConfirmationPollInterval {
+			f.lastConfirmations = time.Now()
+			if err := f.checkConfirmed(ctx); err != nil {
+				log.Warn("error checking for confirmed L2 to L1 messages", "err", err)
+			}
+		}
+		return config.PollInterval
+	})
+}
+
+// subscribeFeed serves an RPC subscription with the values sent on the feed.
+func subscribeFeed[T any](ctx context.Context, feed *event.Feed) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	rpcSub := notifier.CreateSubscription()
+	values := make(chan T, 128)
+	sub := feed.Subscribe(values)
+	go func() {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case value := <-values:
+				if err := notifier.Notify(rpcSub.ID, value); err != nil {
+					return
+				}
+			case <-rpcSub.Err():
+				return
+			case <-sub.Err():
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
+
+type L2ToL1FeedAPI struct {
+	feed *L2ToL1Feed
+}
+
+// L2ToL1Messages notifies the subscriber of each L2 to L1 message as the block sending it is produced.
+func (a *L2ToL1FeedAPI) L2ToL1Messages(ctx context.Context) (*rpc.Subscription, error) {
+	return subscribeFeed[*L2ToL1Message](ctx, &a.feed.messageFeed)
+}
+
+// L2ToL1Confirmations notifies the subscriber each time the rollup confirms a send root with more messages.
+func (a *L2ToL1FeedAPI) L2ToL1Confirmations(ctx context.Context) (*rpc.Subscription, error) {
+	return subscribeFeed[*L2ToL1Confirmation](ctx, &a.feed.confirmationFeed)
+}
+
+// GetL2ToL1Messages returns the L2 to L1 messages sent in the blocks from fromBlock to toBlock inclusive.
+func (a *L2ToL1FeedAPI) GetL2ToL1Messages(ctx context.Context, fromBlock hexutil.Uint64, toBlock hexutil.Uint64) ([]*L2ToL1Message, error) {
+	if toBlock < fromBlock {
+		return nil, fmt.Errorf("block range from %v to %v is empty", fromBlock, toBlock)
+	}
+	if maxRange := a.feed.config().MaxBlockRange; uint64(toBlock-fromBlock) >= maxRange {
+		return nil, fmt.Errorf("%w: at most %v blocks can be requested", errL2ToL1FeedRangeTooLarge, maxRange)
+	}
+	head := a.feed.bc.CurrentBlock().Number.Uint64()
+	if uint64(toBlock) > head {
+		return nil, fmt.Errorf("block %v is after the head block %v", toBlock, head)
+	}
+	messages := []*L2ToL1Message{}
+	for number := uint64(fromBlock); number <= uint64(toBlock); number++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		blockMessages, err := a.feed.Messages(number)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, blockMessages...)
+	}
+	return messages, nil
+}
+
+// GetL2ToL1Confirmation returns the latest confirmation seen, or null if none has been yet.
+func (a *L2ToL1FeedAPI) GetL2ToL1Confirmation(ctx context.Context) (*L2ToL1Confirmation, error) {
+	return a.feed.Confirmation(), nil
+}
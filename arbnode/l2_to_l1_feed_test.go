@@ -0,0 +1,39 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbnode
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+func TestL2ToL1FeedConfigValidate(t *testing.T) {
+	config := TestL2ToL1FeedConfig
+	Require(t, config.Validate())
+	config.MaxBlockRange = 0
+	if config.Validate() == nil {
+		Fail(t, "an L2 to L1 feed without a block range validated")
+	}
+	config = TestL2ToL1FeedConfig
+	config.ConfirmationPollInterval = 0
+	if config.Validate() == nil {
+		Fail(t, "an L2 to L1 feed without a confirmation poll interval validated")
+	}
+	config.Enable = false
+	Require(t, config.Validate())
+}
+
+func TestL2ToL1ConfirmationCovers(t *testing.T) {
+	var unconfirmed *L2ToL1Confirmation
+	if unconfirmed.Covers(0) {
+		Fail(t, "a message is confirmed before any confirmation")
+	}
+	confirmation := &L2ToL1Confirmation{SendCount: hexutil.Uint64(3)}
+	for position := uint64(0); position < 5; position++ {
+		if confirmation.Covers(position) != (position < 3) {
+			Fail(t, "wrong confirmation of message", position)
+		}
+	}
+}
@@ -101,7 +101,11 @@ func NewMaintenanceRunner(config MaintenanceConfigFetcher, seqCoordinator *SeqCo
 	if seqCoordinator != nil {
 		c := func() *redislock.SimpleCfg { return &cfg.Lock }
 		r := func() bool { return true } // always ready to lock
-		rl, err := redislock.NewSimple(seqCoordinator.Client, c, r)
+		client := seqCoordinator.RedisClient()
+		if client == nil && cfg.TimeOfDay != "" && cfg.Lock.Enable {
+			log.Warn("maintenance lock needs the seq coordinator's redis backend, sequencers may run maintenance at the same time")
+		}
+		rl, err := redislock.NewSimple(client, c, r)
 		if err != nil {
 			return nil, fmt.Errorf("creating new simple redis lock: %w", err)
 		}
@@ -0,0 +1,111 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbnode
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/util/dbutil"
+)
+
+// MessageSource is how a message first reached the transaction streamer.
+type MessageSource string
+
+const (
+	MessageSourceUnknown     MessageSource = "unknown"
+	MessageSourceSequencer   MessageSource = "sequencer"
+	MessageSourceFeed        MessageSource = "feed"
+	MessageSourceParentChain MessageSource = "parent-chain"
+	// messages synced from the active sequencer through the sequencer coordinator, or added locally for testing
+	MessageSourceCoordinator MessageSource = "coordinator"
+)
+
+// MessageProvenance records where a message came from and when. Times are unix milliseconds, and ConfirmedAt is 0
+// until a parent chain batch including the message was read.
+type MessageProvenance struct {
+	Source      MessageSource `json:"source"`
+	ReceivedAt  uint64        `json:"receivedAt"`
+	ConfirmedAt uint64        `json:"confirmedAt,omitempty"`
+}
+
+func newMessageProvenance(source MessageSource) MessageProvenance {
+	// #nosec G115
+	now := uint64(time.Now().UnixMilli())
+	provenance := MessageProvenance{
+		Source:     source,
+		ReceivedAt: now,
+	}
+	if source == MessageSourceParentChain {
+		provenance.ConfirmedAt = now
+	}
+	return provenance
+}
+
+func (s *TransactionStreamer) trackProvenance() bool {
+	return s.config().TrackProvenance
+}
+
+func (s *TransactionStreamer) writeProvenance(pos arbutil.MessageIndex, provenance MessageProvenance, batch ethdb.Batch) error {
+	data, err := rlp.EncodeToBytes(provenance)
+	if err != nil {
+		return err
+	}
+	return batch.Put(dbKey(messageProvenancePrefix, uint64(pos)), data)
+}
+
+// GetMessageProvenance returns the provenance of a message, or an unknown source if it wasn't recorded, because the
+// message was added before tracking was enabled or was pruned.
+func (s *TransactionStreamer) GetMessageProvenance(pos arbutil.MessageIndex) (*MessageProvenance, error) {
+	data, err := s.db.Get(dbKey(messageProvenancePrefix, uint64(pos)))
+	if dbutil.IsErrNotFound(err) {
+		return &MessageProvenance{Source: MessageSourceUnknown}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var provenance MessageProvenance
+	if err := rlp.DecodeBytes(data, &provenance); err != nil {
+		return nil, err
+	}
+	return &provenance, nil
+}
+
+// confirmProvenance sets the confirmation time of messages already in the database that a parent chain batch
+// included. The batch is created if it's nil.
+func (s *TransactionStreamer) confirmProvenance(pos arbutil.MessageIndex, count int, batch *ethdb.Batch) error {
+	if !s.trackProvenance() {
+		return nil
+	}
+	// #nosec G115
+	now := uint64(time.Now().UnixMilli())
+	for i := 0; i < count; i++ {
+		msgPos := pos + arbutil.MessageIndex(i)
+		data, err := s.db.Get(dbKey(messageProvenancePrefix, uint64(msgPos)))
+		if dbutil.IsErrNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		var provenance MessageProvenance
+		if err := rlp.DecodeBytes(data, &provenance); err != nil {
+			return err
+		}
+		if provenance.ConfirmedAt != 0 {
+			continue
+		}
+		provenance.ConfirmedAt = now
+		if *batch == nil {
+			*batch = s.db.NewBatch()
+		}
+		if err := s.writeProvenance(msgPos, provenance, *batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbnode
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/offchainlabs/nitro/arbos"
+	"github.com/offchainlabs/nitro/arbos/arbostypes"
+	"github.com/offchainlabs/nitro/arbutil"
+)
+
+func testProvenanceMessages(count int, salt byte) []arbostypes.MessageWithMetadata {
+	var messages []arbostypes.MessageWithMetadata
+	for i := 0; i < count; i++ {
+		requestId := common.Hash{salt, byte(i)}
+		messages = append(messages, arbostypes.MessageWithMetadata{
+			Message: &arbostypes.L1IncomingMessage{
+				Header: &arbostypes.L1IncomingMessageHeader{
+					Kind:      arbostypes.L1MessageType_L2Message,
+					RequestId: &requestId,
+					L1BaseFee: common.Big0,
+				},
+				L2msg: []byte{arbos.L2MessageKind_Heartbeat},
+			},
+			DelayedMessagesRead: 1,
+		})
+	}
+	return messages
+}
+
+func TestMessageProvenance(t *testing.T) {
+	_, streamer, _, _ := NewTransactionStreamerForTest(t, common.Address{})
+	config := DefaultTransactionStreamerConfig
+	config.TrackProvenance = true
+	streamer.config = func() *TransactionStreamerConfig { return &config }
+
+	expect := func(pos arbutil.MessageIndex, source MessageSource, confirmed bool) *MessageProvenance {
+		t.Helper()
+		provenance, err := streamer.GetMessageProvenance(pos)
+		Require(t, err)
+		if provenance.Source != source {
+			Fail(t, "unexpected source of message", pos, provenance.Source, "expected", source)
+		}
+		if (provenance.ConfirmedAt != 0) != confirmed {
+			Fail(t, "unexpected confirmation of message", pos, provenance.ConfirmedAt, "expected confirmed", confirmed)
+		}
+		if source != MessageSourceUnknown && provenance.ReceivedAt == 0 {
+			Fail(t, "message", pos, "has no receive time")
+		}
+		return provenance
+	}
+
+	// the init message was added before provenance was tracked
+	expect(0, MessageSourceUnknown, false)
+
+	// unconfirmed messages from the coordinator aren't confirmed until a batch includes them
+	unconfirmed := testProvenanceMessages(3, 1)
+	Require(t, streamer.AddMessages(1, false, unconfirmed))
+	for pos := arbutil.MessageIndex(1); pos <= 3; pos++ {
+		expect(pos, MessageSourceCoordinator, false)
+	}
+	received := expect(1, MessageSourceCoordinator, false).ReceivedAt
+
+	// a batch including the first two confirms them and keeps their source and receive time
+	Require(t, streamer.AddMessages(1, true, unconfirmed[:2]))
+	confirmed := expect(1, MessageSourceCoordinator, true)
+	if confirmed.ReceivedAt != received {
+		Fail(t, "confirmation changed the receive time", confirmed.ReceivedAt, "expected", received)
+	}
+	if confirmed.ConfirmedAt < confirmed.ReceivedAt {
+		Fail(t, "message confirmed before it was received", confirmed.ConfirmedAt, received)
+	}
+	expect(2, MessageSourceCoordinator, true)
+	expect(3, MessageSourceCoordinator, false)
+
+	// messages first read from the parent chain are confirmed when received
+	Require(t, streamer.AddMessages(3, true, append(unconfirmed[2:], testProvenanceMessages(2, 2)...)))
+	expect(3, MessageSourceCoordinator, true)
+	parentChain := expect(4, MessageSourceParentChain, true)
+	if parentChain.ConfirmedAt != parentChain.ReceivedAt {
+		Fail(t, "parent chain message confirmed after it was received", parentChain.ConfirmedAt, parentChain.ReceivedAt)
+	}
+	expect(5, MessageSourceParentChain, true)
+
+	// the API returns the range, stopping at the message count
+	api := &MessageProvenanceAPI{streamer: streamer}
+	results, err := api.MessageProvenance(context.Background(), 3, 10)
+	Require(t, err)
+	if len(results) != 3 {
+		Fail(t, "unexpected number of results", len(results))
+	}
+	for i, result := range results {
+		if result.MessageIndex != hexutil.Uint64(3+i) {
+			Fail(t, "unexpected message index", result.MessageIndex, "expected", 3+i)
+		}
+	}
+	if results[1].Source != MessageSourceParentChain {
+		Fail(t, "unexpected source in API result", results[1].Source)
+	}
+	if _, err := api.MessageProvenance(context.Background(), 0, maxMessageProvenanceQuery+1); err == nil {
+		Fail(t, "an oversized provenance query succeeded")
+	}
+
+	// with tracking disabled, new messages have no provenance
+	config.TrackProvenance = false
+	Require(t, streamer.AddMessages(6, false, testProvenanceMessages(1, 3)))
+	expect(6, MessageSourceUnknown, false)
+}
@@ -7,24 +7,50 @@ import (
 	"bytes"
 	"context"
 	"encoding/binary"
+	"errors"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rlp"
 
+	"github.com/offchainlabs/nitro/arbos/arbostypes"
 	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/staker"
+	"github.com/offchainlabs/nitro/util/dbutil"
 	"github.com/offchainlabs/nitro/util/stopwaiter"
 	"github.com/offchainlabs/nitro/validator"
 
 	flag "github.com/spf13/pflag"
 )
 
+var (
+	messagePrunerBatchGauge   = metrics.NewRegisteredGauge("arb/messagepruner/batch", nil)
+	messagePrunerMessageGauge = metrics.NewRegisteredGauge("arb/messagepruner/message", nil)
+	messagePrunerDelayedGauge = metrics.NewRegisteredGauge("arb/messagepruner/delayed", nil)
+)
+
+// Message pruner policies, by the role of the node.
+const (
+	// MessagePrunerPolicyConfirmed prunes the messages before the latest confirmed assertion, as the staker reports it.
+	MessagePrunerPolicyConfirmed = "confirmed"
+	// MessagePrunerPolicyArchive keeps every message.
+	MessagePrunerPolicyArchive = "archive"
+	// MessagePrunerPolicyRetention keeps the messages of the retention period, for replicas.
+	MessagePrunerPolicyRetention = "retention"
+	// MessagePrunerPolicyValidated keeps the messages since the last one the node's block validator validated.
+	MessagePrunerPolicyValidated = "validated"
+)
+
 type MessagePruner struct {
 	stopwaiter.StopWaiter
 	transactionStreamer              *TransactionStreamer
 	inboxTracker                     *InboxTracker
+	blockValidator                   *staker.BlockValidator
 	config                           MessagePrunerConfigFetcher
 	pruningLock                      sync.Mutex
 	lastPruneDone                    time.Time
@@ -32,42 +58,162 @@ type MessagePruner struct {
 	cachedPrunedBlockHashesInputFeed uint64
 	cachedPrunedMessageResult        uint64
 	cachedPrunedDelayedMessages      uint64
+	cachedPrunedMessageProvenance    uint64
 }
 
 type MessagePrunerConfig struct {
 	Enable bool `koanf:"enable"`
 	// Message pruning interval.
-	PruneInterval  time.Duration `koanf:"prune-interval" reload:"hot"`
-	MinBatchesLeft uint64        `koanf:"min-batches-left" reload:"hot"`
+	PruneInterval   time.Duration `koanf:"prune-interval" reload:"hot"`
+	MinBatchesLeft  uint64        `koanf:"min-batches-left" reload:"hot"`
+	Policy          string        `koanf:"policy" reload:"hot"`
+	RetentionPeriod time.Duration `koanf:"retention-period" reload:"hot"`
+	DryRun          bool          `koanf:"dry-run" reload:"hot"`
 }
 
 type MessagePrunerConfigFetcher func() *MessagePrunerConfig
 
 var DefaultMessagePrunerConfig = MessagePrunerConfig{
-	Enable:         true,
-	PruneInterval:  time.Minute,
-	MinBatchesLeft: 2,
+	Enable:          true,
+	PruneInterval:   time.Minute,
+	MinBatchesLeft:  2,
+	Policy:          MessagePrunerPolicyConfirmed,
+	RetentionPeriod: 7 * 24 * time.Hour,
+	DryRun:          false,
 }
 
 func MessagePrunerConfigAddOptions(prefix string, f *flag.FlagSet) {
 	f.Bool(prefix+".enable", DefaultMessagePrunerConfig.Enable, "enable message pruning")
 	f.Duration(prefix+".prune-interval", DefaultMessagePrunerConfig.PruneInterval, "interval for running message pruner")
 	f.Uint64(prefix+".min-batches-left", DefaultMessagePrunerConfig.MinBatchesLeft, "min number of batches not pruned")
+	f.String(prefix+".policy", DefaultMessagePrunerConfig.Policy, "which messages are kept: \"confirmed\" since the latest confirmed assertion, \"archive\" all of them, \"retention\" those of the retention period, or \"validated\" since the last validated by the block validator")
+	f.Duration(prefix+".retention-period", DefaultMessagePrunerConfig.RetentionPeriod, "how long messages are kept for with the retention policy")
+	f.Bool(prefix+".dry-run", DefaultMessagePrunerConfig.DryRun, "only log what would be pruned")
 }
 
-func NewMessagePruner(transactionStreamer *TransactionStreamer, inboxTracker *InboxTracker, config MessagePrunerConfigFetcher) *MessagePruner {
+func (c *MessagePrunerConfig) Validate() error {
+	switch c.Policy {
+	case MessagePrunerPolicyConfirmed, MessagePrunerPolicyArchive, MessagePrunerPolicyValidated:
+	case MessagePrunerPolicyRetention:
+		if c.RetentionPeriod <= 0 {
+			return errors.New("message pruner retention policy requires a positive retention period")
+		}
+	default:
+		return fmt.Errorf("unknown message pruner policy %v", c.Policy)
+	}
+	return nil
+}
+
+// NewMessagePruner creates a message pruner. The block validator is only needed by the validated policy.
+func NewMessagePruner(transactionStreamer *TransactionStreamer, inboxTracker *InboxTracker, blockValidator *staker.BlockValidator, config MessagePrunerConfigFetcher) *MessagePruner {
 	return &MessagePruner{
 		transactionStreamer: transactionStreamer,
 		inboxTracker:        inboxTracker,
+		blockValidator:      blockValidator,
 		config:              config,
 	}
 }
 
 func (m *MessagePruner) Start(ctxIn context.Context) {
 	m.StopWaiter.Start(ctxIn, m)
+	m.CallIteratively(m.pruneIteration)
+}
+
+// pruneIteration prunes on a timer for the policies that don't wait for the staker's confirmations.
+func (m *MessagePruner) pruneIteration(ctx context.Context) time.Duration {
+	config := m.config()
+	if config.Policy != MessagePrunerPolicyRetention && config.Policy != MessagePrunerPolicyValidated {
+		return config.PruneInterval
+	}
+	if !m.pruningLock.TryLock() {
+		return config.PruneInterval
+	}
+	defer m.pruningLock.Unlock()
+	trimBatchCount, err := m.policyTrimBatchCount(config, time.Now())
+	if err == nil {
+		err = m.pruneToBatch(ctx, trimBatchCount)
+	}
+	if err != nil && ctx.Err() == nil {
+		log.Error("error while pruning", "policy", config.Policy, "err", err)
+	}
+	return config.PruneInterval
+}
+
+// policyTrimBatchCount returns the number of batches whose messages the retention or validated policy would prune.
+func (m *MessagePruner) policyTrimBatchCount(config *MessagePrunerConfig, now time.Time) (uint64, error) {
+	batchCount, err := m.inboxTracker.GetBatchCount()
+	if err != nil {
+		return 0, err
+	}
+	switch config.Policy {
+	case MessagePrunerPolicyRetention:
+		// #nosec G115
+		cutoff := uint64(now.Add(-config.RetentionPeriod).Unix())
+		var searchErr error
+		// batches end with later messages, so the prunable ones come first
+		// #nosec G115
+		trim := sort.Search(int(batchCount), func(i int) bool {
+			if searchErr != nil {
+				return true
+			}
+			// #nosec G115
+			retained, err := m.batchRetained(uint64(i), cutoff)
+			if err != nil {
+				searchErr = err
+				return true
+			}
+			return retained
+		})
+		// #nosec G115
+		return uint64(trim), searchErr
+	case MessagePrunerPolicyValidated:
+		if m.blockValidator == nil {
+			return 0, errors.New("message pruner validated policy requires the block validator")
+		}
+		validated := m.blockValidator.GetValidated()
+		if validated == 0 {
+			return 0, nil
+		}
+		// the batch holding the first message that isn't validated yet is kept, along with every batch after it
+		batch, found, err := m.inboxTracker.FindInboxBatchContainingMessage(validated)
+		if err != nil || !found {
+			return batchCount, err
+		}
+		return batch, nil
+	default:
+		return 0, nil
+	}
+}
+
+// batchRetained returns whether the last message of a batch is newer than the cutoff timestamp.
+// Batches and messages that were already pruned aren't retained.
+func (m *MessagePruner) batchRetained(batch uint64, cutoff uint64) (bool, error) {
+	meta, err := m.inboxTracker.GetBatchMetadata(batch)
+	if errors.Is(err, AccumulatorNotFoundErr) {
+		return false, nil
+	}
+	if err != nil || meta.MessageCount == 0 {
+		return false, err
+	}
+	data, err := m.transactionStreamer.db.Get(dbKey(messagePrefix, uint64(meta.MessageCount-1)))
+	if dbutil.IsErrNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	// the timestamp is read directly, as filling in a batch posting report's gas cost would need the parent chain
+	var message arbostypes.MessageWithMetadata
+	if err := rlp.DecodeBytes(data, &message); err != nil {
+		return false, err
+	}
+	return message.Message.Header.Timestamp >= cutoff, nil
 }
 
 func (m *MessagePruner) UpdateLatestConfirmed(count arbutil.MessageIndex, globalState validator.GoGlobalState) {
+	if m.config().Policy != MessagePrunerPolicyConfirmed {
+		return
+	}
 	locked := m.pruningLock.TryLock()
 	if !locked {
 		return
@@ -91,8 +237,14 @@ func (m *MessagePruner) UpdateLatestConfirmed(count arbutil.MessageIndex, global
 }
 
 func (m *MessagePruner) prune(ctx context.Context, count arbutil.MessageIndex, globalState validator.GoGlobalState) error {
-	trimBatchCount := globalState.Batch
-	minBatchesLeft := m.config().MinBatchesLeft
+	return m.pruneToBatch(ctx, globalState.Batch)
+}
+
+// pruneToBatch prunes the messages and delayed messages read by the batches before trimBatchCount, keeping at least
+// the configured number of batches. In dry run mode, it only logs what would be pruned.
+func (m *MessagePruner) pruneToBatch(ctx context.Context, trimBatchCount uint64) error {
+	config := m.config()
+	minBatchesLeft := config.MinBatchesLeft
 	batchCount, err := m.inboxTracker.GetBatchCount()
 	if err != nil {
 		return err
@@ -113,9 +265,45 @@ func (m *MessagePruner) prune(ctx context.Context, count arbutil.MessageIndex, g
 	msgCount := endBatchMetadata.MessageCount
 	delayedCount := endBatchMetadata.DelayedMessageCount
 
+	// #nosec G115
+	messagePrunerBatchGauge.Update(int64(trimBatchCount))
+	// #nosec G115
+	messagePrunerMessageGauge.Update(int64(msgCount))
+	// #nosec G115
+	messagePrunerDelayedGauge.Update(int64(delayedCount))
+	if config.DryRun {
+		firstMessage, found, err := firstStoredKey(m.transactionStreamer.db, messagePrefix)
+		if err != nil {
+			return err
+		}
+		if !found || firstMessage >= uint64(msgCount) {
+			log.Info("message pruner dry run: nothing to prune", "policy", config.Policy, "batches", trimBatchCount)
+			return nil
+		}
+		log.Info(
+			"message pruner dry run",
+			"policy", config.Policy,
+			"batches", trimBatchCount,
+			"firstStoredMessage", firstMessage,
+			"pruneBelowMessage", msgCount,
+			"messages", uint64(msgCount)-firstMessage,
+			"pruneBelowDelayedMessage", delayedCount,
+		)
+		return nil
+	}
 	return m.deleteOldMessagesFromDB(ctx, msgCount, delayedCount)
 }
 
+// firstStoredKey returns the lowest key stored under the prefix, starting from 1 as the pruner never prunes key 0.
+func firstStoredKey(db ethdb.Database, prefix []byte) (uint64, bool, error) {
+	iter := db.NewIterator(prefix, uint64ToKey(1))
+	defer iter.Release()
+	if !iter.Next() {
+		return 0, false, iter.Error()
+	}
+	return binary.BigEndian.Uint64(bytes.TrimPrefix(iter.Key(), prefix)), true, nil
+}
+
 func (m *MessagePruner) deleteOldMessagesFromDB(ctx context.Context, messageCount arbutil.MessageIndex, delayedMessageCount uint64) error {
 	prunedKeysRange, err := deleteFromLastPrunedUptoEndKey(ctx, m.transactionStreamer.db, messageResultPrefix, &m.cachedPrunedMessageResult, uint64(messageCount))
 	if err != nil {
@@ -141,6 +329,14 @@ func (m *MessagePruner) deleteOldMessagesFromDB(ctx context.Context, messageCoun
 		log.Info("Pruned last batch messages:", "first pruned key", prunedKeysRange[0], "last pruned key", prunedKeysRange[len(prunedKeysRange)-1])
 	}
 
+	prunedKeysRange, err = deleteFromLastPrunedUptoEndKey(ctx, m.transactionStreamer.db, messageProvenancePrefix, &m.cachedPrunedMessageProvenance, uint64(messageCount))
+	if err != nil {
+		return fmt.Errorf("error deleting message provenance: %w", err)
+	}
+	if len(prunedKeysRange) > 0 {
+		log.Info("Pruned message provenance:", "first pruned key", prunedKeysRange[0], "last pruned key", prunedKeysRange[len(prunedKeysRange)-1])
+	}
+
 	prunedKeysRange, err = deleteFromLastPrunedUptoEndKey(ctx, m.inboxTracker.db, rlpDelayedMessagePrefix, &m.cachedPrunedDelayedMessages, delayedMessageCount)
 	if err != nil {
 		return fmt.Errorf("error deleting last batch delayed messages: %w", err)
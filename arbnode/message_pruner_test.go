@@ -6,10 +6,14 @@ package arbnode
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/offchainlabs/nitro/arbos/arbostypes"
 	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/util/containers"
 )
 
 func TestMessagePrunerWithPruningEligibleMessagePresent(t *testing.T) {
@@ -116,3 +120,67 @@ func checkDbKeys(t *testing.T, endCount uint64, db ethdb.Database, prefix []byte
 		}
 	}
 }
+
+func TestMessagePrunerRetentionPolicy(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	inboxTrackerDb, transactionStreamerDb, pruner := setupDatabase(t, 0, 0)
+	pruner.inboxTracker.batchMeta = containers.NewLruCache[uint64, BatchMetadata](100)
+	config := DefaultMessagePrunerConfig
+	config.Policy = MessagePrunerPolicyRetention
+	config.RetentionPeriod = time.Hour
+	config.MinBatchesLeft = 1
+	pruner.config = func() *MessagePrunerConfig { return &config }
+
+	// ten batches of ten messages each, a batch every ten minutes
+	now := time.Unix(1_000_000, 0)
+	batchCount := uint64(10)
+	for batch := uint64(0); batch < batchCount; batch++ {
+		// #nosec G115
+		timestamp := uint64(now.Add(-time.Duration(batchCount-batch) * 10 * time.Minute).Unix())
+		for i := batch * 10; i < (batch+1)*10; i++ {
+			message := arbostypes.MessageWithMetadata{
+				Message: &arbostypes.L1IncomingMessage{Header: &arbostypes.L1IncomingMessageHeader{Timestamp: timestamp}},
+			}
+			data, err := rlp.EncodeToBytes(message)
+			Require(t, err)
+			Require(t, transactionStreamerDb.Put(dbKey(messagePrefix, i), data))
+		}
+		meta, err := rlp.EncodeToBytes(BatchMetadata{MessageCount: arbutil.MessageIndex((batch + 1) * 10)})
+		Require(t, err)
+		Require(t, inboxTrackerDb.Put(dbKey(sequencerBatchMetaPrefix, batch), meta))
+	}
+	countData, err := rlp.EncodeToBytes(batchCount)
+	Require(t, err)
+	Require(t, inboxTrackerDb.Put(sequencerBatchCountKey, countData))
+
+	// the batches ending over an hour ago are pruned
+	trimBatchCount, err := pruner.policyTrimBatchCount(&config, now)
+	Require(t, err)
+	if trimBatchCount != 4 {
+		Fail(t, "wrong number of batches to prune", trimBatchCount)
+	}
+
+	// a dry run doesn't delete anything
+	config.DryRun = true
+	Require(t, pruner.pruneToBatch(ctx, trimBatchCount))
+	for i := uint64(0); i < batchCount*10; i++ {
+		hasKey, err := transactionStreamerDb.Has(dbKey(messagePrefix, i))
+		Require(t, err)
+		if !hasKey {
+			Fail(t, "dry run pruned message", i)
+		}
+	}
+
+	config.DryRun = false
+	Require(t, pruner.pruneToBatch(ctx, trimBatchCount))
+	checkDbKeys(t, 40, transactionStreamerDb, messagePrefix)
+
+	// pruned batches stay prunable
+	trimBatchCount, err = pruner.policyTrimBatchCount(&config, now)
+	Require(t, err)
+	if trimBatchCount != 4 {
+		Fail(t, "wrong number of batches to prune after pruning", trimBatchCount)
+	}
+}
@@ -9,6 +9,8 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"os"
+	"slices"
 	"strings"
 	"time"
 
@@ -18,6 +20,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/node"
@@ -35,6 +38,7 @@ import (
 	"github.com/offchainlabs/nitro/cmd/chaininfo"
 	"github.com/offchainlabs/nitro/das"
 	"github.com/offchainlabs/nitro/execution"
+	"github.com/offchainlabs/nitro/execution/execrpc"
 	"github.com/offchainlabs/nitro/execution/gethexec"
 	"github.com/offchainlabs/nitro/solgen/go/bridgegen"
 	"github.com/offchainlabs/nitro/solgen/go/precompilesgen"
@@ -82,6 +86,9 @@ type Config struct {
 	InboxReader         InboxReaderConfig           `koanf:"inbox-reader" reload:"hot"`
 	DelayedSequencer    DelayedSequencerConfig      `koanf:"delayed-sequencer" reload:"hot"`
 	BatchPoster         BatchPosterConfig           `koanf:"batch-poster" reload:"hot"`
+	ForceInclusion      ForceInclusionConfig        `koanf:"force-inclusion" reload:"hot"`
+	OutboxExecutor      OutboxExecutorConfig        `koanf:"outbox-executor" reload:"hot"`
+	L2ToL1Feed          L2ToL1FeedConfig            `koanf:"l2-to-l1-feed" reload:"hot"`
 	MessagePruner       MessagePrunerConfig         `koanf:"message-pruner" reload:"hot"`
 	BlockValidator      staker.BlockValidatorConfig `koanf:"block-validator" reload:"hot"`
 	Feed                broadcastclient.FeedConfig  `koanf:"feed" reload:"hot"`
@@ -93,6 +100,10 @@ type Config struct {
 	TransactionStreamer TransactionStreamerConfig   `koanf:"transaction-streamer" reload:"hot"`
 	Maintenance         MaintenanceConfig           `koanf:"maintenance" reload:"hot"`
 	ResourceMgmt        resourcemanager.Config      `koanf:"resource-mgmt" reload:"hot"`
+	ConsensusServer     execrpc.ServerConfig        `koanf:"consensus-server"`
+	ExecutionClient     execrpc.ClientConfig        `koanf:"execution-client"`
+	Health              HealthConfig                `koanf:"health" reload:"hot"`
+	BatchDictionaries   []string                    `koanf:"batch-dictionaries"`
 	// SnapSyncConfig is only used for testing purposes, these should not be configured in production.
 	SnapSyncTest SnapSyncConfig
 }
@@ -117,12 +128,39 @@ func (c *Config) Validate() error {
 	if err := c.Maintenance.Validate(); err != nil {
 		return err
 	}
+	if err := c.MessagePruner.Validate(); err != nil {
+		return err
+	}
 	if err := c.InboxReader.Validate(); err != nil {
 		return err
 	}
 	if err := c.BatchPoster.Validate(); err != nil {
 		return err
 	}
+	if err := c.ForceInclusion.Validate(); err != nil {
+		return err
+	}
+	if c.ForceInclusion.Enable && !c.ParentChainReader.Enable {
+		return errors.New("cannot enable force inclusion without the parent chain reader")
+	}
+	if err := c.OutboxExecutor.Validate(); err != nil {
+		return err
+	}
+	if c.OutboxExecutor.Enable && !c.ParentChainReader.Enable {
+		return errors.New("cannot enable the outbox executor without the parent chain reader")
+	}
+	if err := c.L2ToL1Feed.Validate(); err != nil {
+		return err
+	}
+	if c.L2ToL1Feed.Enable && !c.ParentChainReader.Enable {
+		return errors.New("cannot enable the L2 to L1 feed without the parent chain reader")
+	}
+	if c.ExecutionClient.URL != "" && !c.ConsensusServer.Enable {
+		return errors.New("an execution node in another process requires the consensus server")
+	}
+	if err := c.SeqCoordinator.Validate(); err != nil {
+		return err
+	}
 	if err := c.Feed.Validate(); err != nil {
 		return err
 	}
@@ -148,6 +186,9 @@ func ConfigAddOptions(prefix string, f *flag.FlagSet, feedInputEnable bool, feed
 	InboxReaderConfigAddOptions(prefix+".inbox-reader", f)
 	DelayedSequencerConfigAddOptions(prefix+".delayed-sequencer", f)
 	BatchPosterConfigAddOptions(prefix+".batch-poster", f)
+	ForceInclusionConfigAddOptions(prefix+".force-inclusion", f)
+	OutboxExecutorConfigAddOptions(prefix+".outbox-executor", f)
+	L2ToL1FeedConfigAddOptions(prefix+".l2-to-l1-feed", f)
 	MessagePrunerConfigAddOptions(prefix+".message-pruner", f)
 	staker.BlockValidatorConfigAddOptions(prefix+".block-validator", f)
 	broadcastclient.FeedConfigAddOptions(prefix+".feed", f, feedInputEnable, feedOutputEnable)
@@ -158,6 +199,10 @@ func ConfigAddOptions(prefix string, f *flag.FlagSet, feedInputEnable bool, feed
 	DangerousConfigAddOptions(prefix+".dangerous", f)
 	TransactionStreamerConfigAddOptions(prefix+".transaction-streamer", f)
 	MaintenanceConfigAddOptions(prefix+".maintenance", f)
+	execrpc.ServerConfigAddOptions(prefix+".consensus-server", f, &ConfigDefault.ConsensusServer)
+	execrpc.ClientConfigAddOptions(prefix+".execution-client", f, &ConfigDefault.ExecutionClient)
+	HealthConfigAddOptions(prefix+".health", f)
+	f.StringSlice(prefix+".batch-dictionaries", ConfigDefault.BatchDictionaries, "paths of the brotli dictionaries the chain committed to with ArbOwner.commitBatchDictionary, which every node of the chain must be configured with")
 }
 
 var ConfigDefault = Config{
@@ -166,6 +211,9 @@ var ConfigDefault = Config{
 	InboxReader:         DefaultInboxReaderConfig,
 	DelayedSequencer:    DefaultDelayedSequencerConfig,
 	BatchPoster:         DefaultBatchPosterConfig,
+	ForceInclusion:      DefaultForceInclusionConfig,
+	OutboxExecutor:      DefaultOutboxExecutorConfig,
+	L2ToL1Feed:          DefaultL2ToL1FeedConfig,
 	MessagePruner:       DefaultMessagePrunerConfig,
 	BlockValidator:      staker.DefaultBlockValidatorConfig,
 	Feed:                broadcastclient.FeedConfigDefault,
@@ -177,6 +225,9 @@ var ConfigDefault = Config{
 	TransactionStreamer: DefaultTransactionStreamerConfig,
 	ResourceMgmt:        resourcemanager.DefaultConfig,
 	Maintenance:         DefaultMaintenanceConfig,
+	ConsensusServer:     execrpc.DefaultConsensusServerConfig,
+	ExecutionClient:     execrpc.DefaultClientConfig,
+	Health:              DefaultHealthConfig,
 	SnapSyncTest:        DefaultSnapSyncConfig,
 }
 
@@ -204,6 +255,7 @@ func ConfigDefaultL1NonSequencerTest() *Config {
 	config.Staker = staker.TestL1ValidatorConfig
 	config.Staker.Enable = false
 	config.BlockValidator.ValidationServerConfigs = []rpcclient.ClientConfig{{URL: ""}}
+	config.Feed.Input.RequireSignature = false
 
 	return &config
 }
@@ -214,6 +266,7 @@ func ConfigDefaultL2Test() *Config {
 	config.ParentChainReader.Enable = false
 	config.SeqCoordinator = TestSeqCoordinatorConfig
 	config.Feed.Input.Verify.Dangerous.AcceptMissing = true
+	config.Feed.Input.RequireSignature = false
 	config.Feed.Output.Signed = false
 	config.SeqCoordinator.Signer.ECDSA.AcceptSequencer = false
 	config.SeqCoordinator.Signer.ECDSA.Dangerous.AcceptMissing = true
@@ -230,24 +283,28 @@ type DangerousConfig struct {
 	NoL1Listener           bool `koanf:"no-l1-listener"`
 	NoSequencerCoordinator bool `koanf:"no-sequencer-coordinator"`
 	DisableBlobReader      bool `koanf:"disable-blob-reader"`
+	InstantFinality        bool `koanf:"instant-finality"`
 }
 
 var DefaultDangerousConfig = DangerousConfig{
 	NoL1Listener:           false,
 	NoSequencerCoordinator: false,
 	DisableBlobReader:      false,
+	InstantFinality:        false,
 }
 
 var TestDangerousConfig = DangerousConfig{
 	NoL1Listener:           false,
 	NoSequencerCoordinator: false,
 	DisableBlobReader:      true,
+	InstantFinality:        false,
 }
 
 func DangerousConfigAddOptions(prefix string, f *flag.FlagSet) {
 	f.Bool(prefix+".no-l1-listener", DefaultDangerousConfig.NoL1Listener, "DANGEROUS! disables listening to L1. To be used in test nodes only")
 	f.Bool(prefix+".no-sequencer-coordinator", DefaultDangerousConfig.NoSequencerCoordinator, "DANGEROUS! allows sequencing without sequencer-coordinator")
 	f.Bool(prefix+".disable-blob-reader", DefaultDangerousConfig.DisableBlobReader, "DANGEROUS! disables the EIP-4844 blob reader, which is necessary to read batches")
+	f.Bool(prefix+".instant-finality", DefaultDangerousConfig.InstantFinality, "DANGEROUS! reports every message as safe and finalized once a batch posting it was read, or immediately without a parent chain reader. To be used in dev nodes only")
 }
 
 type Node struct {
@@ -262,6 +319,10 @@ type Node struct {
 	InboxTracker            *InboxTracker
 	DelayedSequencer        *DelayedSequencer
 	BatchPoster             *BatchPoster
+	ForceInclusionHelper    *ForceInclusionHelper
+	OutboxExecutor          *OutboxExecutor
+	L2ToL1Feed              *L2ToL1Feed
+	ConsensusServer         *execrpc.Server // nil unless serving an execution node in another process
 	MessagePruner           *MessagePruner
 	BlockValidator          *staker.BlockValidator
 	StatelessBlockValidator *staker.StatelessBlockValidator
@@ -299,18 +360,27 @@ type ConfigFetcher interface {
 	Started() bool
 }
 
-func checkArbDbSchemaVersion(arbDb ethdb.Database) error {
-	var version uint64
+// ReadArbDbSchemaVersion returns the schema version recorded in the arbitrumdata database.
+// A database without a recorded version is at version 0.
+func ReadArbDbSchemaVersion(arbDb ethdb.Database) (uint64, error) {
 	hasVersion, err := arbDb.Has(dbSchemaVersion)
+	if err != nil || !hasVersion {
+		return 0, err
+	}
+	versionBytes, err := arbDb.Get(dbSchemaVersion)
 	if err != nil {
-		return err
+		return 0, err
 	}
-	if hasVersion {
-		versionBytes, err := arbDb.Get(dbSchemaVersion)
-		if err != nil {
-			return err
-		}
-		version = binary.BigEndian.Uint64(versionBytes)
+	if len(versionBytes) != 8 {
+		return 0, fmt.Errorf("invalid database schema version length %v", len(versionBytes))
+	}
+	return binary.BigEndian.Uint64(versionBytes), nil
+}
+
+func checkArbDbSchemaVersion(arbDb ethdb.Database) error {
+	version, err := ReadArbDbSchemaVersion(arbDb)
+	if err != nil {
+		return err
 	}
 	for version != currentDbSchemaVersion {
 		batch := arbDb.NewBatch()
@@ -400,6 +470,18 @@ func StakerDataposter(
 		})
 }
 
+func loadBatchDictionaries(paths []string) (daprovider.StaticDictionaries, error) {
+	var dictionaries [][]byte
+	for _, path := range paths {
+		dictionary, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read batch dictionary: %w", err)
+		}
+		dictionaries = append(dictionaries, dictionary)
+	}
+	return daprovider.NewStaticDictionaries(dictionaries...), nil
+}
+
 func createNodeImpl(
 	ctx context.Context,
 	stack *node.Node,
@@ -443,10 +525,18 @@ func createNodeImpl(
 	if config.Feed.Output.Enable {
 		var maybeDataSigner signature.DataSignerFunc
 		if config.Feed.Output.Signed {
-			if dataSigner == nil {
+			if config.Feed.Output.SigningKey != "" {
+				feedKey, err := crypto.HexToECDSA(strings.TrimPrefix(config.Feed.Output.SigningKey, "0x"))
+				if err != nil {
+					return nil, fmt.Errorf("error parsing feed signing key: %w", err)
+				}
+				log.Info("signing feed messages", "signer", crypto.PubkeyToAddress(feedKey.PublicKey))
+				maybeDataSigner = signature.DataSignerFromPrivateKey(feedKey)
+			} else if dataSigner == nil {
 				return nil, errors.New("cannot sign outgoing feed")
+			} else {
+				maybeDataSigner = dataSigner
 			}
-			maybeDataSigner = dataSigner
 		}
 		broadcastServer = broadcaster.NewBroadcaster(func() *wsbroadcastserver.BroadcasterConfig { return &configFetcher.Get().Feed.Output }, l2ChainId, fatalErrChan, maybeDataSigner)
 	}
@@ -516,6 +606,9 @@ func createNodeImpl(
 			InboxTracker:            nil,
 			DelayedSequencer:        nil,
 			BatchPoster:             nil,
+			ForceInclusionHelper:    nil,
+			OutboxExecutor:          nil,
+			L2ToL1Feed:              nil,
 			MessagePruner:           nil,
 			BlockValidator:          nil,
 			StatelessBlockValidator: nil,
@@ -584,7 +677,11 @@ func createNodeImpl(
 	if blobReader != nil {
 		dapReaders = append(dapReaders, daprovider.NewReaderForBlobReader(blobReader))
 	}
-	inboxTracker, err := NewInboxTracker(arbDb, txStreamer, dapReaders, config.SnapSyncTest)
+	dictionaries, err := loadBatchDictionaries(config.BatchDictionaries)
+	if err != nil {
+		return nil, err
+	}
+	inboxTracker, err := NewInboxTracker(arbDb, txStreamer, dapReaders, dictionaries, config.SnapSyncTest)
 	if err != nil {
 		return nil, err
 	}
@@ -603,6 +700,7 @@ func createNodeImpl(
 			exec,
 			rawdb.NewTable(arbDb, storage.BlockValidatorPrefix),
 			dapReaders,
+			dictionaries,
 			func() *staker.BlockValidatorConfig { return &configFetcher.Get().BlockValidator },
 			stack,
 		)
@@ -635,6 +733,14 @@ func createNodeImpl(
 	var messagePruner *MessagePruner
 	var stakerAddr common.Address
 
+	if config.MessagePruner.Enable && config.MessagePruner.Policy != MessagePrunerPolicyConfirmed {
+		// the other policies don't depend on the staker's confirmations
+		if config.MessagePruner.Policy == MessagePrunerPolicyValidated && blockValidator == nil {
+			return nil, errors.New("message pruner validated policy requires the block validator")
+		}
+		messagePruner = NewMessagePruner(txStreamer, inboxTracker, blockValidator, func() *MessagePrunerConfig { return &configFetcher.Get().MessagePruner })
+	}
+
 	if config.Staker.Enable {
 		dp, err := StakerDataposter(
 			ctx,
@@ -680,8 +786,10 @@ func createNodeImpl(
 		}
 
 		var confirmedNotifiers []staker.LatestConfirmedNotifier
-		if config.MessagePruner.Enable {
-			messagePruner = NewMessagePruner(txStreamer, inboxTracker, func() *MessagePrunerConfig { return &configFetcher.Get().MessagePruner })
+		if config.MessagePruner.Enable && messagePruner == nil {
+			messagePruner = NewMessagePruner(txStreamer, inboxTracker, blockValidator, func() *MessagePrunerConfig { return &configFetcher.Get().MessagePruner })
+		}
+		if messagePruner != nil {
 			confirmedNotifiers = append(confirmedNotifiers, messagePruner)
 		}
 
@@ -710,10 +818,11 @@ func createNodeImpl(
 		}
 		var dapWriter daprovider.Writer
 		if daWriter != nil {
-			dapWriter = daprovider.NewWriterForDAS(daWriter)
+			dapWriter = daprovider.NewWriterForDAS(newDASStoreReportLogger(daWriter))
 		}
 		batchPoster, err = NewBatchPoster(ctx, &BatchPosterOpts{
 			DataPosterDB:  rawdb.NewTable(arbDb, storage.BatchPosterPrefix),
+			PostedBatchDB: rawdb.NewTable(arbDb, storage.PostedBatchPrefix),
 			L1Reader:      l1Reader,
 			Inbox:         inboxTracker,
 			Streamer:      txStreamer,
@@ -725,6 +834,7 @@ func createNodeImpl(
 			DAPWriter:     dapWriter,
 			ParentChainID: parentChainID,
 			DAPReaders:    dapReaders,
+			Dictionaries:  dictionaries,
 		})
 		if err != nil {
 			return nil, err
@@ -754,6 +864,9 @@ func createNodeImpl(
 		InboxTracker:            inboxTracker,
 		DelayedSequencer:        delayedSequencer,
 		BatchPoster:             batchPoster,
+		ForceInclusionHelper:    nil,
+		OutboxExecutor:          nil,
+		L2ToL1Feed:              nil,
 		MessagePruner:           messagePruner,
 		BlockValidator:          blockValidator,
 		StatelessBlockValidator: statelessBlockValidator,
@@ -769,8 +882,55 @@ func createNodeImpl(
 	}, nil
 }
 
-func (n *Node) OnConfigReload(_ *Config, _ *Config) error {
-	// TODO
+// EnableForceInclusion creates the force inclusion helper, which sends transactions with its own wallet.
+// It must be called before the node is started.
+func (n *Node) EnableForceInclusion(auth *bind.TransactOpts) error {
+	if n.L1Reader == nil || n.InboxTracker == nil {
+		return errors.New("force inclusion requires the parent chain reader")
+	}
+	helper, err := NewForceInclusionHelper(
+		n.L1Reader,
+		n.InboxTracker,
+		n.Execution,
+		n.DeployInfo,
+		auth,
+		func() *ForceInclusionConfig { return &n.configFetcher.Get().ForceInclusion },
+		func() *DelayedSequencerConfig { return &n.configFetcher.Get().DelayedSequencer },
+	)
+	if err != nil {
+		return err
+	}
+	n.ForceInclusionHelper = helper
+	return nil
+}
+
+// EnableOutboxExecutor creates the outbox executor, which executes L2 to L1 messages with its own wallet.
+// It must be called before the node is started, and needs this process's execution node for proofs.
+func (n *Node) EnableOutboxExecutor(auth *bind.TransactOpts) error {
+	if n.L1Reader == nil || n.DeployInfo == nil {
+		return errors.New("the outbox executor requires the parent chain reader")
+	}
+	execNode, ok := n.Execution.(*gethexec.ExecutionNode)
+	if !ok {
+		return errors.New("the outbox executor requires a local execution node")
+	}
+	executor, err := NewOutboxExecutor(n.L1Reader, n.DeployInfo, execNode.ArbInterface.BlockChain(), rawdb.NewTable(n.ArbDB, storage.OutboxExecutorPrefix), auth, func() *OutboxExecutorConfig { return &n.configFetcher.Get().OutboxExecutor })
+	if err != nil {
+		return err
+	}
+	n.OutboxExecutor = executor
+	return nil
+}
+
+func (n *Node) OnConfigReload(oldConfig *Config, newConfig *Config) error {
+	oldInput, newInput := &oldConfig.Feed.Input, &newConfig.Feed.Input
+	if !slices.Equal(oldInput.URL, newInput.URL) || !slices.Equal(oldInput.SecondaryURL, newInput.SecondaryURL) {
+		if n.BroadcastClients != nil {
+			n.BroadcastClients.UpdateURLs(newInput.URL, newInput.SecondaryURL)
+		} else {
+			log.Warn("feed input wasn't enabled on startup, restart the node to connect to the reloaded feed urls")
+		}
+	}
 	return nil
 }
 
@@ -795,6 +955,37 @@ func CreateNode(
 		return nil, err
 	}
 	var apis []rpc.API
+	if execNode, ok := exec.(*gethexec.ExecutionNode); ok && currentNode.L1Reader != nil && currentNode.DeployInfo != nil {
+		rollup, err := staker.NewRollupWatcher(currentNode.DeployInfo.Rollup, currentNode.L1Reader.Client(), bind.CallOpts{})
+		if err != nil {
+			return nil, err
+		}
+		bc := execNode.ArbInterface.BlockChain()
+		execNode.OutboxAPI.SetConfirmedHeaderFetcher(func(ctx context.Context) (*types.Header, error) {
+			_, header, err := latestConfirmedHeader(ctx, rollup, bc)
+			return header, err
+		})
+	}
+	if configFetcher.Get().L2ToL1Feed.Enable {
+		execNode, ok := exec.(*gethexec.ExecutionNode)
+		if !ok {
+			return nil, errors.New("the L2 to L1 feed requires a local execution node")
+		}
+		if currentNode.L1Reader == nil || currentNode.DeployInfo == nil {
+			return nil, errors.New("the L2 to L1 feed requires the parent chain reader")
+		}
+		feed, err := NewL2ToL1Feed(currentNode.L1Reader, currentNode.DeployInfo, execNode.ArbInterface.BlockChain(), func() *L2ToL1FeedConfig { return &configFetcher.Get().L2ToL1Feed })
+		if err != nil {
+			return nil, err
+		}
+		currentNode.L2ToL1Feed = feed
+		apis = append(apis, rpc.API{
+			Namespace: "arb",
+			Version:   "1.0",
+			Service:   &L2ToL1FeedAPI{feed: feed},
+			Public:    true,
+		})
+	}
 	if currentNode.BlockValidator != nil {
 		apis = append(apis, rpc.API{
 			Namespace: "arb",
@@ -814,8 +1005,47 @@ func CreateNode(
 		})
 	}
 
+	if currentNode.SeqCoordinator != nil {
+		apis = append(apis, rpc.API{
+			Namespace: "seqcoordinator",
+			Version:   "1.0",
+			Service:   &SeqCoordinatorAPI{coordinator: currentNode.SeqCoordinator},
+			Public:    false,
+		})
+	}
+
+	if currentNode.InboxTracker != nil {
+		apis = append(apis, rpc.API{
+			Namespace: "arbinbox",
+			Version:   "1.0",
+			Service:   &InboxAPI{tracker: currentNode.InboxTracker, reader: currentNode.InboxReader, sequencer: configFetcher.Get().Sequencer},
+			Public:    false,
+		})
+	}
+
+	if configFetcher.Get().TransactionStreamer.TrackProvenance {
+		apis = append(apis, rpc.API{
+			Namespace: "arbstreamer",
+			Version:   "1.0",
+			Service:   &MessageProvenanceAPI{streamer: currentNode.TxStreamer},
+			Public:    false,
+		})
+	}
+
+	if config := &configFetcher.Get().ConsensusServer; config.Enable {
+		currentNode.ConsensusServer, err = execrpc.NewServer(config, execrpc.ConsensusService, execrpc.NewConsensusServerAPI(currentNode))
+		if err != nil {
+			return nil, err
+		}
+	}
 	stack.RegisterAPIs(apis)
 
+	if configFetcher.Get().Health.Enable {
+		healthChecker := NewHealthChecker(currentNode)
+		stack.RegisterHandler("health", HealthPath, healthChecker.Handler(false))
+		stack.RegisterHandler("readiness", ReadinessPath, healthChecker.Handler(true))
+	}
+
 	return currentNode, nil
 }
 
@@ -838,6 +1068,12 @@ func (n *Node) Start(ctx context.Context) error {
 	if execClient != nil {
 		execClient.SetConsensusClient(n)
 	}
+	if n.ConsensusServer != nil {
+		err = n.ConsensusServer.Start(ctx)
+		if err != nil {
+			return fmt.Errorf("error starting consensus server: %w", err)
+		}
+	}
 	err = n.Execution.Start(ctx)
 	if err != nil {
 		return fmt.Errorf("error starting exec client: %w", err)
@@ -899,6 +1135,15 @@ func (n *Node) Start(ctx context.Context) error {
 	if n.BatchPoster != nil {
 		n.BatchPoster.Start(ctx)
 	}
+	if n.ForceInclusionHelper != nil {
+		n.ForceInclusionHelper.Start(ctx)
+	}
+	if n.OutboxExecutor != nil {
+		n.OutboxExecutor.Start(ctx)
+	}
+	if n.L2ToL1Feed != nil {
+		n.L2ToL1Feed.Start(ctx)
+	}
 	if n.MessagePruner != nil {
 		n.MessagePruner.Start(ctx)
 	}
@@ -967,12 +1212,24 @@ func (n *Node) StopAndWait() {
 		n.SeqCoordinator.PrepareForShutdown()
 	}
 	n.Stack.StopRPC() // does nothing if not running
+	if n.ConsensusServer != nil && n.ConsensusServer.Started() {
+		n.ConsensusServer.StopAndWait()
+	}
 	if n.DelayedSequencer != nil && n.DelayedSequencer.Started() {
 		n.DelayedSequencer.StopAndWait()
 	}
 	if n.BatchPoster != nil && n.BatchPoster.Started() {
 		n.BatchPoster.StopAndWait()
 	}
+	if n.ForceInclusionHelper != nil && n.ForceInclusionHelper.Started() {
+		n.ForceInclusionHelper.StopAndWait()
+	}
+	if n.OutboxExecutor != nil && n.OutboxExecutor.Started() {
+		n.OutboxExecutor.StopAndWait()
+	}
+	if n.L2ToL1Feed != nil && n.L2ToL1Feed.Started() {
+		n.L2ToL1Feed.StopAndWait()
+	}
 	if n.MessagePruner != nil && n.MessagePruner.Started() {
 		n.MessagePruner.StopAndWait()
 	}
@@ -1024,6 +1281,14 @@ func (n *Node) GetBatchParentChainBlock(seqNum uint64) (uint64, error) {
 	return n.InboxTracker.GetBatchParentChainBlock(seqNum)
 }
 
+func (n *Node) GetBatchMessageCount(seqNum uint64) (arbutil.MessageIndex, error) {
+	return n.InboxTracker.GetBatchMessageCount(seqNum)
+}
+
+func (n *Node) GetBatchCount() (uint64, error) {
+	return n.InboxTracker.GetBatchCount()
+}
+
 func (n *Node) FullSyncProgressMap() map[string]interface{} {
 	return n.SyncMonitor.FullSyncProgressMap()
 }
@@ -1036,12 +1301,37 @@ func (n *Node) SyncTargetMessageCount() arbutil.MessageIndex {
 	return n.SyncMonitor.SyncTargetMessageCount()
 }
 
-// TODO: switch from pulling to pushing safe/finalized
+// instantFinalityMsgCount is the safe and finalized message count of dev nodes, which consider messages final once
+// a batch posting them was read, or immediately without a parent chain reader.
+func (n *Node) instantFinalityMsgCount() (arbutil.MessageIndex, error) {
+	if n.InboxReader == nil {
+		return n.TxStreamer.GetMessageCount()
+	}
+	batchCount, err := n.InboxTracker.GetBatchCount()
+	if err != nil || batchCount == 0 {
+		return 0, err
+	}
+	return n.InboxTracker.GetBatchMessageCount(batchCount - 1)
+}
+
+// The safe and finalized message counts are kept up to date by the inbox reader as the parent chain progresses.
 func (n *Node) GetSafeMsgCount(ctx context.Context) (arbutil.MessageIndex, error) {
+	if n.configFetcher.Get().Dangerous.InstantFinality {
+		return n.instantFinalityMsgCount()
+	}
+	if n.InboxReader == nil {
+		return 0, errors.New("safe block requires the parent chain reader")
+	}
 	return n.InboxReader.GetSafeMsgCount(ctx)
 }
 
 func (n *Node) GetFinalizedMsgCount(ctx context.Context) (arbutil.MessageIndex, error) {
+	if n.configFetcher.Get().Dangerous.InstantFinality {
+		return n.instantFinalityMsgCount()
+	}
+	if n.InboxReader == nil {
+		return 0, errors.New("finalized block requires the parent chain reader")
+	}
 	return n.InboxReader.GetFinalizedMsgCount(ctx)
 }
 
@@ -0,0 +1,478 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbnode
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/offchainlabs/nitro/arbos/util"
+	"github.com/offchainlabs/nitro/cmd/chaininfo"
+	"github.com/offchainlabs/nitro/cmd/genericconf"
+	"github.com/offchainlabs/nitro/execution/outboxproof"
+	"github.com/offchainlabs/nitro/solgen/go/bridgegen"
+	"github.com/offchainlabs/nitro/staker"
+	"github.com/offchainlabs/nitro/util/arbmath"
+	"github.com/offchainlabs/nitro/util/dbutil"
+	"github.com/offchainlabs/nitro/util/headerreader"
+	"github.com/offchainlabs/nitro/util/stopwaiter"
+)
+
+var (
+	outboxExecutorPendingGauge     = metrics.NewRegisteredGauge("arb/outboxexecutor/pending", nil)
+	outboxExecutorSubmittedCounter = metrics.NewRegisteredCounter("arb/outboxexecutor/submitted", nil)
+	outboxExecutorExecutedCounter  = metrics.NewRegisteredCounter("arb/outboxexecutor/executed", nil)
+	outboxExecutorFailedCounter    = metrics.NewRegisteredCounter("arb/outboxexecutor/failed", nil)
+)
+
+// OutboxExecutorConfig configures a service that executes the L2 to L1 messages of chosen senders in the parent
+// chain's outbox once the assertions including them are confirmed.
+type OutboxExecutorConfig struct {
+	Enable            bool                     `koanf:"enable"`
+	Senders           []string                 `koanf:"senders"`
+	FromBlock         uint64                   `koanf:"from-block"`
+	PollInterval      time.Duration            `koanf:"poll-interval" reload:"hot"`
+	MaxBlocksPerPoll  uint64                   `koanf:"max-blocks-per-poll" reload:"hot"`
+	MaxGasFeeCapGwei  float64                  `koanf:"max-gas-fee-cap-gwei" reload:"hot"`
+	MaxTipCapGwei     float64                  `koanf:"max-tip-cap-gwei" reload:"hot"`
+	GasLimit          uint64                   `koanf:"gas-limit" reload:"hot"`
+	TxTimeout         time.Duration            `koanf:"tx-timeout" reload:"hot"`
+	RetryInterval     time.Duration            `koanf:"retry-interval" reload:"hot"`
+	MaxAttempts       uint64                   `koanf:"max-attempts" reload:"hot"`
+	ParentChainWallet genericconf.WalletConfig `koanf:"parent-chain-wallet"`
+}
+
+type OutboxExecutorConfigFetcher func() *OutboxExecutorConfig
+
+func (c *OutboxExecutorConfig) senderSet() (map[common.Address]struct{}, error) {
+	senders := make(map[common.Address]struct{}, len(c.Senders))
+	for _, sender := range c.Senders {
+		if !common.IsHexAddress(sender) {
+			return nil, fmt.Errorf("invalid outbox executor sender %q", sender)
+		}
+		senders[common.HexToAddress(sender)] = struct{}{}
+	}
+	return senders, nil
+}
+
+func (c *OutboxExecutorConfig) Validate() error {
+	senders, err := c.senderSet()
+	if err != nil {
+		return err
+	}
+	if !c.Enable {
+		return nil
+	}
+	if len(senders) == 0 {
+		return errors.New("outbox executor needs at least one sender whose messages to execute")
+	}
+	if c.PollInterval <= 0 || c.MaxBlocksPerPoll == 0 {
+		return errors.New("outbox executor poll interval and max blocks per poll must be positive")
+	}
+	if c.MaxGasFeeCapGwei <= 0 || c.MaxTipCapGwei < 0 || c.MaxTipCapGwei > c.MaxGasFeeCapGwei {
+		return errors.New("outbox executor max gas fee cap must be positive and at least the max tip cap")
+	}
+	return nil
+}
+
+func OutboxExecutorConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.Bool(prefix+".enable", DefaultOutboxExecutorConfig.Enable, "execute the L2 to L1 messages of the configured senders in the parent chain's outbox once they're confirmed")
+	f.StringSlice(prefix+".senders", DefaultOutboxExecutorConfig.Senders, "L2 addresses whose L2 to L1 messages are executed")
+	f.Uint64(prefix+".from-block", DefaultOutboxExecutorConfig.FromBlock, "L2 block to start looking for messages from, the first time the service runs")
+	f.Duration(prefix+".poll-interval", DefaultOutboxExecutorConfig.PollInterval, "how often to look for new and confirmed messages")
+	f.Uint64(prefix+".max-blocks-per-poll", DefaultOutboxExecutorConfig.MaxBlocksPerPoll, "most L2 blocks to look through for new messages per poll")
+	f.Float64(prefix+".max-gas-fee-cap-gwei", DefaultOutboxExecutorConfig.MaxGasFeeCapGwei, "the maximum gas fee cap for execution transactions, in gwei; no transaction is sent while the parent chain base fee is above it")
+	f.Float64(prefix+".max-tip-cap-gwei", DefaultOutboxExecutorConfig.MaxTipCapGwei, "the maximum tip cap for execution transactions, in gwei")
+	f.Uint64(prefix+".gas-limit", DefaultOutboxExecutorConfig.GasLimit, "the gas limit for execution transactions (0 = estimate)")
+	f.Duration(prefix+".tx-timeout", DefaultOutboxExecutorConfig.TxTimeout, "how long to wait for an execution transaction to be mined")
+	f.Duration(prefix+".retry-interval", DefaultOutboxExecutorConfig.RetryInterval, "how long to wait before trying again to execute a message whose last attempt failed")
+	f.Uint64(prefix+".max-attempts", DefaultOutboxExecutorConfig.MaxAttempts, "attempts to execute a message after which it's left for an operator (0 = unlimited)")
+	genericconf.WalletConfigAddOptions(prefix+".parent-chain-wallet", f, DefaultOutboxExecutorConfig.ParentChainWallet.Pathname)
+}
+
+var DefaultOutboxExecutorL1WalletConfig = genericconf.WalletConfig{
+	Pathname:      "outbox-executor-wallet",
+	Password:      genericconf.WalletConfigDefault.Password,
+	PrivateKey:    genericconf.WalletConfigDefault.PrivateKey,
+	Account:       genericconf.WalletConfigDefault.Account,
+	OnlyCreateKey: genericconf.WalletConfigDefault.OnlyCreateKey,
+}
+
+var DefaultOutboxExecutorConfig = OutboxExecutorConfig{
+	Enable:            false,
+	Senders:           []string{},
+	FromBlock:         0,
+	PollInterval:      time.Minute,
+	MaxBlocksPerPoll:  10_000,
+	MaxGasFeeCapGwei:  100,
+	MaxTipCapGwei:     2,
+	GasLimit:          0,
+	TxTimeout:         10 * time.Minute,
+	RetryInterval:     time.Hour,
+	MaxAttempts:       10,
+	ParentChainWallet: DefaultOutboxExecutorL1WalletConfig,
+}
+
+var TestOutboxExecutorConfig = OutboxExecutorConfig{
+	Enable:            true,
+	Senders:           []string{},
+	FromBlock:         0,
+	PollInterval:      time.Millisecond * 100,
+	MaxBlocksPerPoll:  1_000,
+	MaxGasFeeCapGwei:  100,
+	MaxTipCapGwei:     2,
+	GasLimit:          0,
+	TxTimeout:         time.Minute,
+	RetryInterval:     time.Second,
+	MaxAttempts:       3,
+	ParentChainWallet: DefaultOutboxExecutorL1WalletConfig,
+}
+
+var (
+	outboxMessagePrefix     = []byte("m") // maps a send's position in the send merkle tree to its outboxMessage
+	outboxScannedBlockKey   = []byte("_scannedBlock")
+	errOutboxMessageMissing = errors.New("L2 to L1 message not found in its block")
+)
+
+// outboxMessage is the service's record of a message it's waiting to execute.
+type outboxMessage struct {
+	BlockNumber uint64 // the L2 block that sent it
+	Attempts    uint64
+	LastAttempt uint64 // unix time
+}
+
+func outboxMessageKey(position uint64) []byte {
+	return binary.BigEndian.AppendUint64(append([]byte{}, outboxMessagePrefix...), position)
+}
+
+// OutboxExecutor watches the node's own blocks for L2 to L1 messages from the configured senders, and executes
+// each in the outbox once the rollup confirms an assertion whose send root includes it, with a proof built from
+// the node's logs.
+type OutboxExecutor struct {
+	stopwaiter.StopWaiter
+	l1Reader *headerreader.HeaderReader
+	rollup   *staker.RollupWatcher
+	bc       *core.BlockChain
+	db       ethdb.Database
+	auth     *bind.TransactOpts
+	config   OutboxExecutorConfigFetcher
+
+	outbox        *bridgegen.Outbox
+	outboxAddress common.Address
+}
+
+func NewOutboxExecutor(l1Reader *headerreader.HeaderReader, deployInfo *chaininfo.RollupAddresses, bc *core.BlockChain, db ethdb.Database, auth *bind.TransactOpts, config OutboxExecutorConfigFetcher) (*OutboxExecutor, error) {
+	if auth == nil {
+		return nil, errors.New("outbox executor requires a parent chain wallet")
+	}
+	rollup, err := staker.NewRollupWatcher(deployInfo.Rollup, l1Reader.Client(), bind.CallOpts{})
+	if err != nil {
+		return nil, err
+	}
+	return &OutboxExecutor{
+		l1Reader: l1Reader,
+		rollup:   rollup,
+		bc:       bc,
+		db:       db,
+		auth:     auth,
+		config:   config,
+	}, nil
+}
+
+func (e *OutboxExecutor) readMessage(position uint64) (*outboxMessage, error) {
+	value, err := e.db.Get(outboxMessageKey(position))
+	if err != nil {
+		return nil, err
+	}
+	var message outboxMessage
+	if err := rlp.DecodeBytes(value, &message); err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+func (e *OutboxExecutor) writeMessage(position uint64, message *outboxMessage) error {
+	value, err := rlp.EncodeToBytes(message)
+	if err != nil {
+		return err
+	}
+	return e.db.Put(outboxMessageKey(position), value)
+}
+
+func (e *OutboxExecutor) scannedBlock() (uint64, bool, error) {
+	value, err := e.db.Get(outboxScannedBlockKey)
+	if dbutil.IsErrNotFound(err) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	if len(value) != 8 {
+		return 0, false, fmt.Errorf("invalid outbox executor scanned block %x", value)
+	}
+	return binary.BigEndian.Uint64(value), true, nil
+}
+
+// scan records the messages of the configured senders in the blocks after the last one scanned.
+func (e *OutboxExecutor) scan(config *OutboxExecutorConfig) error {
+	senders, err := config.senderSet()
+	if err != nil {
+		return err
+	}
+	scanned, found, err := e.scannedBlock()
+	if err != nil {
+		return err
+	}
+	from := config.FromBlock
+	if found {
+		from = scanned + 1
+	}
+	head := e.bc.CurrentBlock().Number.Uint64()
+	if from > head {
+		return nil
+	}
+	to := arbmath.MinInt(head, from+config.MaxBlocksPerPoll-1)
+	batch := e.db.NewBatch()
+	for number := from; number <= to; number++ {
+		block := e.bc.GetBlockByNumber(number)
+		if block == nil {
+			return fmt.Errorf("block %v not found", number)
+		}
+		for _, receipt := range e.bc.GetReceiptsByHash(block.Hash()) {
+			for _, sendLog := range outboxproof.L2ToL1Txs(receipt) {
+				event, err := util.ParseL2ToL1TxLog(sendLog)
+				if err != nil {
+					return err
+				}
+				if _, ok := senders[event.Caller]; !ok || !event.Position.IsUint64() {
+					continue
+				}
+				value, err := rlp.EncodeToBytes(&outboxMessage{BlockNumber: number})
+				if err != nil {
+					return err
+				}
+				if err := batch.Put(outboxMessageKey(event.Position.Uint64()), value); err != nil {
+					return err
+				}
+				log.Info("found L2 to L1 message to execute", "sender", event.Caller, "position", event.Position, "block", number)
+			}
+		}
+	}
+	if err := batch.Put(outboxScannedBlockKey, binary.BigEndian.AppendUint64(nil, to)); err != nil {
+		return err
+	}
+	return batch.Write()
+}
+
+// latestConfirmedHeader returns the rollup's latest confirmed assertion and the header of its L2 block, or a nil
+// header if this node doesn't have the block yet.
+func latestConfirmedHeader(ctx context.Context, rollup *staker.RollupWatcher, bc *core.BlockChain) (uint64, *types.Header, error) {
+	callOpts := &bind.CallOpts{Context: ctx}
+	latestConfirmed, err := rollup.LatestConfirmed(callOpts)
+	if err != nil {
+		return 0, nil, err
+	}
+	node, err := rollup.LookupNode(ctx, latestConfirmed)
+	if err != nil {
+		return 0, nil, err
+	}
+	globalState := node.Assertion.AfterState.GlobalState
+	header := bc.GetHeaderByHash(globalState.BlockHash)
+	if header == nil {
+		return latestConfirmed, nil, nil
+	}
+	if types.DeserializeHeaderExtraInformation(header).SendRoot != globalState.SendRoot {
+		return 0, nil, fmt.Errorf("confirmed block %v has a different send root than the confirmed assertion", globalState.BlockHash)
+	}
+	return latestConfirmed, header, nil
+}
+
+// confirmedHeader returns the header of the L2 block of the rollup's latest confirmed assertion, or nil if this
+// node doesn't have it yet.
+func (e *OutboxExecutor) confirmedHeader(ctx context.Context) (*types.Header, error) {
+	_, header, err := latestConfirmedHeader(ctx, e.rollup, e.bc)
+	return header, err
+}
+
+// executeConfirmed executes the recorded messages included in the latest confirmed send root.
+func (e *OutboxExecutor) executeConfirmed(ctx context.Context, config *OutboxExecutorConfig) error {
+	root, err := e.confirmedHeader(ctx)
+	if err != nil || root == nil {
+		return err
+	}
+	if e.outbox == nil {
+		e.outboxAddress, err = e.rollup.Outbox(&bind.CallOpts{Context: ctx})
+		if err != nil {
+			return err
+		}
+		e.outbox, err = bridgegen.NewOutbox(e.outboxAddress, e.l1Reader.Client())
+		if err != nil {
+			return err
+		}
+	}
+	sendCount := types.DeserializeHeaderExtraInformation(root).SendCount
+
+	it := e.db.NewIterator(outboxMessagePrefix, nil)
+	defer it.Release()
+	var pending int64
+	for it.Next() {
+		pending++
+		position := binary.BigEndian.Uint64(it.Key()[len(outboxMessagePrefix):])
+		if position >= sendCount {
+			// positions only increase, so the rest aren't confirmed either
+			for it.Next() {
+				pending++
+			}
+			break
+		}
+		var message outboxMessage
+		if err := rlp.DecodeBytes(it.Value(), &message); err != nil {
+			return err
+		}
+		spent, err := e.outbox.IsSpent(&bind.CallOpts{Context: ctx}, new(big.Int).SetUint64(position))
+		if err != nil {
+			return err
+		}
+		if spent {
+			pending--
+			if err := e.db.Delete(outboxMessageKey(position)); err != nil {
+				return err
+			}
+			continue
+		}
+		if config.MaxAttempts > 0 && message.Attempts >= config.MaxAttempts {
+			continue
+		}
+		// #nosec G115
+		if message.Attempts > 0 && time.Since(time.Unix(int64(message.LastAttempt), 0)) < config.RetryInterval {
+			continue
+		}
+		err = e.execute(ctx, config, root, position, message.BlockNumber)
+		if err == nil {
+			pending--
+			if err := e.db.Delete(outboxMessageKey(position)); err != nil {
+				return err
+			}
+			continue
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		message.Attempts++
+		// #nosec G115
+		message.LastAttempt = uint64(time.Now().Unix())
+		outboxExecutorFailedCounter.Inc(1)
+		log.Warn("failed to execute L2 to L1 message", "position", position, "attempts", message.Attempts, "err", err)
+		if config.MaxAttempts > 0 && message.Attempts >= config.MaxAttempts {
+			log.Error("giving up on executing L2 to L1 message", "position", position, "block", message.BlockNumber)
+		}
+		if err := e.writeMessage(position, &message); err != nil {
+			return err
+		}
+	}
+	outboxExecutorPendingGauge.Update(pending)
+	return it.Error()
+}
+
+// execute proves the message at position against the send root of root and sends the execution transaction.
+func (e *OutboxExecutor) execute(ctx context.Context, config *OutboxExecutorConfig, root *types.Header, position uint64, blockNumber uint64) error {
+	block := e.bc.GetBlockByNumber(blockNumber)
+	if block == nil {
+		return fmt.Errorf("block %v not found", blockNumber)
+	}
+	var sendLog *types.Log
+	for _, receipt := range e.bc.GetReceiptsByHash(block.Hash()) {
+		for _, candidate := range outboxproof.L2ToL1Txs(receipt) {
+			if common.BigToHash(new(big.Int).SetUint64(position)) == candidate.Topics[3] {
+				sendLog = candidate
+			}
+		}
+	}
+	if sendLog == nil {
+		return fmt.Errorf("%w: position %v in block %v", errOutboxMessageMissing, position, blockNumber)
+	}
+	proof, err := outboxproof.ForSend(ctx, outboxproof.NewBlockChainBackend(e.bc), sendLog, root)
+	if err != nil {
+		return err
+	}
+	blockHash, err := e.outbox.Roots(&bind.CallOpts{Context: ctx}, proof.SendRoot)
+	if err != nil {
+		return err
+	}
+	if blockHash == (common.Hash{}) {
+		return fmt.Errorf("send root %v isn't in the outbox yet", proof.SendRoot)
+	}
+
+	header, err := e.l1Reader.LastHeader(ctx)
+	if err != nil {
+		return err
+	}
+	maxFeeCap := arbmath.FloatToBig(config.MaxGasFeeCapGwei * params.GWei)
+	tipCap := arbmath.FloatToBig(config.MaxTipCapGwei * params.GWei)
+	if header.BaseFee != nil {
+		if header.BaseFee.Cmp(maxFeeCap) > 0 {
+			return fmt.Errorf("parent chain base fee %v is above the max fee cap %v", header.BaseFee, maxFeeCap)
+		}
+		tipCap = arbmath.BigMin(tipCap, arbmath.BigSub(maxFeeCap, header.BaseFee))
+	}
+	opts := *e.auth
+	opts.Context = ctx
+	opts.GasFeeCap = maxFeeCap
+	opts.GasTipCap = tipCap
+	opts.GasLimit = config.GasLimit
+
+	proofHashes := make([][32]byte, len(proof.Proof))
+	for i, hash := range proof.Proof {
+		proofHashes[i] = hash
+	}
+	tx, err := e.outbox.ExecuteTransaction(&opts, proofHashes, new(big.Int).SetUint64(proof.Index), proof.L2Sender, proof.To, proof.L2Block, proof.L1Block, proof.L2Timestamp, proof.Value, proof.Data)
+	if err != nil {
+		return err
+	}
+	outboxExecutorSubmittedCounter.Inc(1)
+	log.Info("executing L2 to L1 message", "position", position, "sender", proof.L2Sender, "to", proof.To, "tx", tx.Hash())
+
+	waitCtx, cancel := context.WithTimeout(ctx, config.TxTimeout)
+	defer cancel()
+	receipt, err := bind.WaitMined(waitCtx, e.l1Reader.Client(), tx)
+	if err != nil {
+		return fmt.Errorf("waiting for execution transaction %v: %w", tx.Hash(), err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return fmt.Errorf("execution transaction %v failed", tx.Hash())
+	}
+	outboxExecutorExecutedCounter.Inc(1)
+	log.Info("executed L2 to L1 message", "position", position, "tx", tx.Hash(), "parentChainBlock", receipt.BlockNumber)
+	return nil
+}
+
+func (e *OutboxExecutor) Start(ctxIn context.Context) {
+	e.StopWaiter.Start(ctxIn, e)
+	e.CallIteratively(func(ctx context.Context) time.Duration {
+		config := e.config()
+		if err := e.scan(config); err != nil {
+			log.Error("error looking for L2 to L1 messages to execute", "err", err)
+		}
+		if err := e.executeConfirmed(ctx, config); err != nil {
+			log.Error("error executing confirmed L2 to L1 messages", "err", err)
+		}
+		return config.PollInterval
+	})
+}
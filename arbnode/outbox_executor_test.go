@@ -0,0 +1,73 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbnode
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+)
+
+func TestOutboxExecutorConfigSenders(t *testing.T) {
+	config := TestOutboxExecutorConfig
+	if config.Validate() == nil {
+		Fail(t, "an enabled outbox executor without senders validated")
+	}
+	sender := common.HexToAddress("0x1234")
+	config.Senders = []string{sender.Hex()}
+	Require(t, config.Validate())
+	senders, err := config.senderSet()
+	Require(t, err)
+	if _, ok := senders[sender]; !ok || len(senders) != 1 {
+		Fail(t, "wrong senders", senders)
+	}
+	config.Senders = append(config.Senders, "not an address")
+	if config.Validate() == nil {
+		Fail(t, "an invalid sender validated")
+	}
+}
+
+func TestOutboxExecutorMessages(t *testing.T) {
+	executor := &OutboxExecutor{db: rawdb.NewMemoryDatabase()}
+	_, found, err := executor.scannedBlock()
+	Require(t, err)
+	if found {
+		Fail(t, "found a scanned block in an empty database")
+	}
+	Require(t, executor.db.Put(outboxScannedBlockKey, binary.BigEndian.AppendUint64(nil, 7)))
+	scanned, found, err := executor.scannedBlock()
+	Require(t, err)
+	if !found || scanned != 7 {
+		Fail(t, "wrong scanned block", scanned, found)
+	}
+
+	// messages must be iterated in position order for the confirmed send count to end the iteration
+	positions := []uint64{300, 2, 1 << 40, 256}
+	for i, position := range positions {
+		Require(t, executor.writeMessage(position, &outboxMessage{BlockNumber: uint64(i), Attempts: position}))
+	}
+	it := executor.db.NewIterator(outboxMessagePrefix, nil)
+	defer it.Release()
+	var iterated []uint64
+	for it.Next() {
+		iterated = append(iterated, binary.BigEndian.Uint64(it.Key()[len(outboxMessagePrefix):]))
+	}
+	Require(t, it.Error())
+	expected := []uint64{2, 256, 300, 1 << 40}
+	if len(iterated) != len(expected) {
+		Fail(t, "wrong messages iterated", iterated)
+	}
+	for i := range expected {
+		if iterated[i] != expected[i] {
+			Fail(t, "messages iterated out of order", iterated)
+		}
+	}
+	message, err := executor.readMessage(300)
+	Require(t, err)
+	if message.BlockNumber != 0 || message.Attempts != 300 {
+		Fail(t, "wrong message read", message)
+	}
+}
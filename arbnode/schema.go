@@ -12,6 +12,7 @@ var (
 	parentChainBlockNumberPrefix []byte = []byte("p") // maps a delayed sequence number to a parent chain block number
 	sequencerBatchMetaPrefix     []byte = []byte("s") // maps a batch sequence number to BatchMetadata
 	delayedSequencedPrefix       []byte = []byte("a") // maps a delayed message count to the first sequencer batch sequence number with this delayed count
+	messageProvenancePrefix      []byte = []byte("o") // maps a message sequence number to the MessageProvenance of the message
 
 	messageCountKey        []byte = []byte("_messageCount")        // contains the current message count
 	delayedMessageCountKey []byte = []byte("_delayedMessageCount") // contains the current delayed message count
@@ -20,3 +21,6 @@ var (
 )
 
 const currentDbSchemaVersion uint64 = 1
+
+// CurrentDbSchemaVersion is the arbitrumdata schema version this node writes and understands.
+const CurrentDbSchemaVersion = currentDbSchemaVersion
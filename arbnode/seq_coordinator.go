@@ -10,6 +10,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -37,7 +38,7 @@ var (
 type SeqCoordinator struct {
 	stopwaiter.StopWaiter
 
-	redisutil.RedisCoordinator
+	backend seqCoordinatorBackend
 
 	sync             *SyncMonitor
 	streamer         *TransactionStreamer
@@ -50,7 +51,9 @@ type SeqCoordinator struct {
 	reportedWantsLockout bool
 
 	lockoutUntil atomic.Int64 // atomic
+	fencingToken atomic.Uint64
 
+	handoffDrillMutex sync.Mutex
 	wantsLockoutMutex sync.Mutex // manages access to acquireLockoutAndWriteMessage and generally the wants lockout key
 	avoidLockout      int        // If > 0, prevents acquiring the lockout but not extending the lockout if no alternative sequencer wants the lockout. Protected by chosenUpdateMutex.
 
@@ -60,6 +63,7 @@ type SeqCoordinator struct {
 type SeqCoordinatorConfig struct {
 	Enable                bool          `koanf:"enable"`
 	ChosenHealthcheckAddr string        `koanf:"chosen-healthcheck-addr"`
+	Backend               string        `koanf:"backend"`
 	RedisUrl              string        `koanf:"redis-url"`
 	LockoutDuration       time.Duration `koanf:"lockout-duration"`
 	LockoutSpare          time.Duration `koanf:"lockout-spare"`
@@ -74,6 +78,7 @@ type SeqCoordinatorConfig struct {
 	MyUrl               string                     `koanf:"my-url"`
 	DeleteFinalizedMsgs bool                       `koanf:"delete-finalized-msgs"`
 	Signer              signature.SignVerifyConfig `koanf:"signer"`
+	Etcd                SeqCoordinatorEtcdConfig   `koanf:"etcd"`
 }
 
 func (c *SeqCoordinatorConfig) Url() string {
@@ -83,8 +88,26 @@ func (c *SeqCoordinatorConfig) Url() string {
 	return c.MyUrl
 }
 
+func (c *SeqCoordinatorConfig) Validate() error {
+	if !c.Enable {
+		return nil
+	}
+	switch c.Backend {
+	case "redis":
+		if c.RedisUrl == "" {
+			return errors.New("seq coordinator redis backend requires a redis url")
+		}
+	case "etcd":
+		return c.Etcd.Validate()
+	default:
+		return fmt.Errorf("unknown seq coordinator backend %q, expected redis or etcd", c.Backend)
+	}
+	return nil
+}
+
 func SeqCoordinatorConfigAddOptions(prefix string, f *flag.FlagSet) {
 	f.Bool(prefix+".enable", DefaultSeqCoordinatorConfig.Enable, "enable sequence coordinator")
+	f.String(prefix+".backend", DefaultSeqCoordinatorConfig.Backend, "the store to coordinate via, either redis or etcd")
 	f.String(prefix+".redis-url", DefaultSeqCoordinatorConfig.RedisUrl, "the Redis URL to coordinate via")
 	f.String(prefix+".chosen-healthcheck-addr", DefaultSeqCoordinatorConfig.ChosenHealthcheckAddr, "if non-empty, launch an HTTP service binding to this address that returns status code 200 when chosen and 503 otherwise")
 	f.Duration(prefix+".lockout-duration", DefaultSeqCoordinatorConfig.LockoutDuration, "")
@@ -99,11 +122,13 @@ func SeqCoordinatorConfigAddOptions(prefix string, f *flag.FlagSet) {
 	f.String(prefix+".my-url", DefaultSeqCoordinatorConfig.MyUrl, "url for this sequencer if it is the chosen")
 	f.Bool(prefix+".delete-finalized-msgs", DefaultSeqCoordinatorConfig.DeleteFinalizedMsgs, "enable deleting of finalized messages from redis")
 	signature.SignVerifyConfigAddOptions(prefix+".signer", f)
+	SeqCoordinatorEtcdConfigAddOptions(prefix+".etcd", f)
 }
 
 var DefaultSeqCoordinatorConfig = SeqCoordinatorConfig{
 	Enable:                false,
 	ChosenHealthcheckAddr: "",
+	Backend:               "redis",
 	RedisUrl:              "",
 	LockoutDuration:       time.Minute,
 	LockoutSpare:          30 * time.Second,
@@ -117,10 +142,12 @@ var DefaultSeqCoordinatorConfig = SeqCoordinatorConfig{
 	MyUrl:                 redisutil.INVALID_URL,
 	DeleteFinalizedMsgs:   true,
 	Signer:                signature.DefaultSignVerifyConfig,
+	Etcd:                  DefaultSeqCoordinatorEtcdConfig,
 }
 
 var TestSeqCoordinatorConfig = SeqCoordinatorConfig{
 	Enable:              false,
+	Backend:             "redis",
 	RedisUrl:            "",
 	LockoutDuration:     time.Second * 2,
 	LockoutSpare:        time.Millisecond * 10,
@@ -134,6 +161,7 @@ var TestSeqCoordinatorConfig = SeqCoordinatorConfig{
 	MyUrl:               redisutil.INVALID_URL,
 	DeleteFinalizedMsgs: true,
 	Signer:              signature.DefaultSignVerifyConfig,
+	Etcd:                DefaultSeqCoordinatorEtcdConfig,
 }
 
 func NewSeqCoordinator(
@@ -144,7 +172,14 @@ func NewSeqCoordinator(
 	sync *SyncMonitor,
 	config SeqCoordinatorConfig,
 ) (*SeqCoordinator, error) {
-	redisCoordinator, err := redisutil.NewRedisCoordinator(config.RedisUrl)
+	var backend seqCoordinatorBackend
+	var err error
+	switch config.Backend {
+	case "etcd":
+		backend, err = newEtcdSeqCoordinatorBackend(config.Etcd)
+	default:
+		backend, err = newRedisSeqCoordinatorBackend(config.RedisUrl)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -153,12 +188,12 @@ func NewSeqCoordinator(
 		return nil, err
 	}
 	coordinator := &SeqCoordinator{
-		RedisCoordinator: *redisCoordinator,
-		sync:             sync,
-		streamer:         streamer,
-		sequencer:        sequencer,
-		config:           config,
-		signer:           signer,
+		backend:   backend,
+		sync:      sync,
+		streamer:  streamer,
+		sequencer: sequencer,
+		config:    config,
+		signer:    signer,
 	}
 	streamer.SetSeqCoordinator(coordinator)
 	return coordinator, nil
@@ -174,6 +209,14 @@ func (c *SeqCoordinator) SetDelayedSequencer(delayedSequencer *DelayedSequencer)
 	c.delayedSequencer = delayedSequencer
 }
 
+// RedisClient returns the client of the redis backend, or nil if the coordinator uses another backend.
+func (c *SeqCoordinator) RedisClient() redis.UniversalClient {
+	if backend, ok := c.backend.(*redisSeqCoordinatorBackend); ok {
+		return backend.client
+	}
+	return nil
+}
+
 func StandaloneSeqCoordinatorInvalidateMsgIndex(ctx context.Context, redisClient redis.UniversalClient, keyConfig string, msgIndex arbutil.MessageIndex) error {
 	signerConfig := signature.EmptySimpleHmacConfig
 	if keyConfig == "" {
@@ -197,6 +240,50 @@ func StandaloneSeqCoordinatorInvalidateMsgIndex(ctx context.Context, redisClient
 	return nil
 }
 
+// RecommendSequencerWantingLockout returns the top priority sequencer wanting the lockout
+func (c *SeqCoordinator) RecommendSequencerWantingLockout(ctx context.Context) (string, error) {
+	return c.recommendSequencerWantingLockoutExcept(ctx, "")
+}
+
+// recommendSequencerWantingLockoutExcept returns the top priority sequencer other than except wanting the lockout
+func (c *SeqCoordinator) recommendSequencerWantingLockoutExcept(ctx context.Context, except string) (string, error) {
+	prioritiesBytes, err := c.backend.Get(ctx, redisutil.PRIORITIES_KEY)
+	if err != nil {
+		if errors.Is(err, errSeqCoordinatorKeyNotFound) {
+			err = errors.New("sequencer priorities unset")
+		}
+		return "", err
+	}
+	priorities := strings.Split(string(prioritiesBytes), ",")
+	for _, url := range priorities {
+		if url == except {
+			continue
+		}
+		_, err := c.backend.Get(ctx, redisutil.WantsLockoutKeyFor(url))
+		if errors.Is(err, errSeqCoordinatorKeyNotFound) { // wants lockout not set
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		return url, nil
+	}
+	log.Error("no sequencer appears to want the lockout", "priorities", string(prioritiesBytes))
+	return "", nil
+}
+
+// CurrentChosenSequencer retrieves the current chosen sequencer holding the lock
+func (c *SeqCoordinator) CurrentChosenSequencer(ctx context.Context) (string, error) {
+	current, err := c.backend.Get(ctx, redisutil.CHOSENSEQ_KEY)
+	if errors.Is(err, errSeqCoordinatorKeyNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(current), nil
+}
+
 func atomicTimeWrite(addr *atomic.Int64, t time.Time) {
 	asint64 := t.UnixMilli()
 	addr.Store(asint64)
@@ -247,8 +334,8 @@ func (c *SeqCoordinator) signedBytesToMsgCount(ctx context.Context, data []byte)
 
 // Acquires or refreshes the chosen one lockout and optionally writes a message into redis atomically.
 func (c *SeqCoordinator) acquireLockoutAndWriteMessage(ctx context.Context, msgCountExpected, msgCountToWrite arbutil.MessageIndex, lastmsg *arbostypes.MessageWithMetadata) error {
-	var messageData *string
-	var messageSigData *string
+	var messageData []byte
+	var messageSigData []byte
 	if lastmsg != nil {
 		msgBytes, err := json.Marshal(lastmsg)
 		if err != nil {
@@ -259,13 +346,10 @@ func (c *SeqCoordinator) acquireLockoutAndWriteMessage(ctx context.Context, msgC
 			return err
 		}
 		if c.config.Signer.SymmetricSign {
-			messageString := string(append(msgSig, msgBytes...))
-			messageData = &messageString
+			messageData = append(msgSig, msgBytes...)
 		} else {
-			messageString := string(msgBytes)
-			sigString := string(msgSig)
-			messageData = &messageString
-			messageSigData = &sigString
+			messageData = msgBytes
+			messageSigData = msgSig
 		}
 	}
 	msgCountMsg, err := c.msgCountToSignedBytes(msgCountToWrite)
@@ -276,62 +360,62 @@ func (c *SeqCoordinator) acquireLockoutAndWriteMessage(ctx context.Context, msgC
 	defer c.wantsLockoutMutex.Unlock()
 	setWantsLockout := c.avoidLockout <= 0
 	lockoutUntil := time.Now().Add(c.config.LockoutDuration)
-	err = c.Client.Watch(ctx, func(tx *redis.Tx) error {
-		current, err := tx.Get(ctx, redisutil.CHOSENSEQ_KEY).Result()
-		var wasEmpty bool
-		if errors.Is(err, redis.Nil) {
-			wasEmpty = true
-			err = nil
+	var fencingToken uint64
+	watchedKeys := []string{redisutil.CHOSENSEQ_KEY, redisutil.MSG_COUNT_KEY, redisutil.FENCING_TOKEN_KEY}
+	err = c.backend.Update(ctx, watchedKeys, func(values map[string][]byte) ([]seqCoordinatorWrite, error) {
+		current, wasSet := values[redisutil.CHOSENSEQ_KEY]
+		if wasSet && (string(current) != c.config.Url()) {
+			return nil, fmt.Errorf("%w: failed to catch lock. coordinator shows chosen: %s", execution.ErrRetrySequencer, current)
 		}
+		var err error
+		fencingToken, err = fencingTokenFromBytes(values[redisutil.FENCING_TOKEN_KEY])
 		if err != nil {
-			return err
+			return nil, err
 		}
-		if !wasEmpty && (current != c.config.Url()) {
-			return fmt.Errorf("%w: failed to catch lock. redis shows chosen: %s", execution.ErrRetrySequencer, current)
-		}
-		remoteMsgCount, err := c.getRemoteMsgCountImpl(ctx, tx)
-		if err != nil {
-			return err
+		var remoteMsgCount arbutil.MessageIndex
+		if msgCountValue, isSet := values[redisutil.MSG_COUNT_KEY]; isSet {
+			remoteMsgCount, err = c.signedBytesToMsgCount(ctx, msgCountValue)
+			if err != nil {
+				return nil, err
+			}
 		}
 		if remoteMsgCount > msgCountExpected {
 			if messageData == nil && c.CurrentlyChosen() {
 				// this was called from update(), while msgCount was changed by a call from SequencingMessage
 				// no need to do anything
-				return nil
+				return nil, nil
 			}
 			log.Info("coordinator failed to become main", "expected", msgCountExpected, "found", remoteMsgCount, "message is nil?", messageData == nil)
-			return fmt.Errorf("%w: failed to catch lock. expected msg %d found %d", execution.ErrRetrySequencer, msgCountExpected, remoteMsgCount)
+			return nil, fmt.Errorf("%w: failed to catch lock. expected msg %d found %d", execution.ErrRetrySequencer, msgCountExpected, remoteMsgCount)
 		}
-		pipe := tx.TxPipeline()
 		initialDuration := c.config.LockoutDuration
 		if initialDuration < 2*time.Second {
 			initialDuration = 2 * time.Second
 		}
-		if wasEmpty {
-			pipe.Set(ctx, redisutil.CHOSENSEQ_KEY, c.config.Url(), initialDuration)
+		writes := []seqCoordinatorWrite{
+			{key: redisutil.CHOSENSEQ_KEY, value: []byte(c.config.Url()), ttl: initialDuration, expireAt: lockoutUntil},
+			{key: redisutil.MSG_COUNT_KEY, value: msgCountMsg, ttl: c.config.SeqNumDuration},
+		}
+		if !wasSet {
+			// newly acquiring the lockout fences off whichever sequencer held it before
+			fencingToken++
+			writes = append(writes, seqCoordinatorWrite{key: redisutil.FENCING_TOKEN_KEY, value: arbmath.UintToBytes(fencingToken)})
 		}
-		pipe.Set(ctx, redisutil.MSG_COUNT_KEY, msgCountMsg, c.config.SeqNumDuration)
 		if messageData != nil {
-			pipe.Set(ctx, redisutil.MessageKeyFor(msgCountToWrite-1), *messageData, c.config.SeqNumDuration)
+			writes = append(writes, seqCoordinatorWrite{key: redisutil.MessageKeyFor(msgCountToWrite - 1), value: messageData, ttl: c.config.SeqNumDuration})
 			if messageSigData != nil {
-				pipe.Set(ctx, redisutil.MessageSigKeyFor(msgCountToWrite-1), *messageSigData, c.config.SeqNumDuration)
+				writes = append(writes, seqCoordinatorWrite{key: redisutil.MessageSigKeyFor(msgCountToWrite - 1), value: messageSigData, ttl: c.config.SeqNumDuration})
 			}
 		}
-		pipe.PExpireAt(ctx, redisutil.CHOSENSEQ_KEY, lockoutUntil)
 		if setWantsLockout {
 			myWantsLockoutKey := redisutil.WantsLockoutKeyFor(c.config.Url())
-			pipe.Set(ctx, myWantsLockoutKey, redisutil.WANTS_LOCKOUT_VAL, initialDuration)
-			pipe.PExpireAt(ctx, myWantsLockoutKey, lockoutUntil)
-		}
-		err = execTestPipe(pipe, ctx)
-		if errors.Is(err, redis.TxFailedErr) {
-			return fmt.Errorf("%w: failed to catch sequencer lock", execution.ErrRetrySequencer)
-		}
-		if err != nil {
-			return fmt.Errorf("chosen sequencer failed to update redis: %w", err)
+			writes = append(writes, seqCoordinatorWrite{key: myWantsLockoutKey, value: []byte(redisutil.WANTS_LOCKOUT_VAL), ttl: initialDuration, expireAt: lockoutUntil})
 		}
-		return nil
-	}, redisutil.CHOSENSEQ_KEY, redisutil.MSG_COUNT_KEY)
+		return writes, nil
+	})
+	if errors.Is(err, errSeqCoordinatorTxConflict) {
+		return fmt.Errorf("%w: failed to catch sequencer lock", execution.ErrRetrySequencer)
+	}
 
 	if err != nil {
 		return err
@@ -339,32 +423,68 @@ func (c *SeqCoordinator) acquireLockoutAndWriteMessage(ctx context.Context, msgC
 	if setWantsLockout {
 		c.reportedWantsLockout = true
 	}
+	c.setFencingToken(fencingToken)
 	isActiveSequencer.Update(1)
 	atomicTimeWrite(&c.lockoutUntil, lockoutUntil.Add(-c.config.LockoutSpare))
 	return nil
 }
 
 func (c *SeqCoordinator) getRemoteFinalizedMsgCount(ctx context.Context) (arbutil.MessageIndex, error) {
-	resStr, err := c.Client.Get(ctx, redisutil.FINALIZED_MSG_COUNT_KEY).Result()
+	res, err := c.backend.Get(ctx, redisutil.FINALIZED_MSG_COUNT_KEY)
 	if err != nil {
 		return 0, err
 	}
-	return c.signedBytesToMsgCount(ctx, []byte(resStr))
+	return c.signedBytesToMsgCount(ctx, res)
 }
 
-func (c *SeqCoordinator) getRemoteMsgCountImpl(ctx context.Context, r redis.Cmdable) (arbutil.MessageIndex, error) {
-	resStr, err := r.Get(ctx, redisutil.MSG_COUNT_KEY).Result()
-	if errors.Is(err, redis.Nil) {
+func (c *SeqCoordinator) getRemoteMsgCount(ctx context.Context) (arbutil.MessageIndex, error) {
+	res, err := c.backend.Get(ctx, redisutil.MSG_COUNT_KEY)
+	if errors.Is(err, errSeqCoordinatorKeyNotFound) {
 		return 0, nil
 	}
 	if err != nil {
 		return 0, err
 	}
-	return c.signedBytesToMsgCount(ctx, []byte(resStr))
+	return c.signedBytesToMsgCount(ctx, res)
 }
 
 func (c *SeqCoordinator) GetRemoteMsgCount() (arbutil.MessageIndex, error) {
-	return c.getRemoteMsgCountImpl(c.GetContext(), c.Client)
+	return c.getRemoteMsgCount(c.GetContext())
+}
+
+// fencingTokenFromBytes decodes the fencing token key's value, which is unset until a sequencer first acquires the lockout.
+func fencingTokenFromBytes(data []byte) (uint64, error) {
+	if data == nil {
+		return 0, nil
+	}
+	if len(data) != 8 {
+		return 0, fmt.Errorf("fencing token value has length %v, expected 8", len(data))
+	}
+	return binary.BigEndian.Uint64(data), nil
+}
+
+func (c *SeqCoordinator) getRemoteFencingToken(ctx context.Context) (uint64, error) {
+	res, err := c.backend.Get(ctx, redisutil.FENCING_TOKEN_KEY)
+	if errors.Is(err, errSeqCoordinatorKeyNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return fencingTokenFromBytes(res)
+}
+
+// FencingToken returns the latest fencing token this sequencer has seen. It's incremented every time a sequencer
+// newly acquires the lockout, and is included in the messages this node broadcasts so feed consumers can ignore
+// messages from a sequencer that has since lost the lockout.
+func (c *SeqCoordinator) FencingToken() uint64 {
+	return c.fencingToken.Load()
+}
+
+func (c *SeqCoordinator) setFencingToken(token uint64) {
+	if c.fencingToken.Swap(token) != token && c.streamer != nil {
+		c.streamer.SetFencingToken(token)
+	}
 }
 
 func (c *SeqCoordinator) wantsLockoutUpdate(ctx context.Context) error {
@@ -380,16 +500,17 @@ func (c *SeqCoordinator) wantsLockoutUpdateWithMutex(ctx context.Context) error
 	}
 	myWantsLockoutKey := redisutil.WantsLockoutKeyFor(c.config.Url())
 	wantsLockoutUntil := time.Now().Add(c.config.LockoutDuration)
-	pipe := c.Client.TxPipeline()
 	initialDuration := c.config.LockoutDuration
 	if initialDuration < 2*time.Second {
 		initialDuration = 2 * time.Second
 	}
-	pipe.Set(ctx, myWantsLockoutKey, redisutil.WANTS_LOCKOUT_VAL, initialDuration)
-	pipe.PExpireAt(ctx, myWantsLockoutKey, wantsLockoutUntil)
-	err := execTestPipe(pipe, ctx)
+	err := c.backend.Update(ctx, nil, func(map[string][]byte) ([]seqCoordinatorWrite, error) {
+		return []seqCoordinatorWrite{
+			{key: myWantsLockoutKey, value: []byte(redisutil.WANTS_LOCKOUT_VAL), ttl: initialDuration, expireAt: wantsLockoutUntil},
+		}, nil
+	})
 	if err != nil {
-		return fmt.Errorf("failed to update wants lockout key in redis: %w", err)
+		return fmt.Errorf("failed to update wants lockout key: %w", err)
 	}
 	c.reportedWantsLockout = true
 	return nil
@@ -398,34 +519,22 @@ func (c *SeqCoordinator) wantsLockoutUpdateWithMutex(ctx context.Context) error
 func (c *SeqCoordinator) chosenOneRelease(ctx context.Context) error {
 	atomicTimeWrite(&c.lockoutUntil, time.Time{})
 	isActiveSequencer.Update(0)
-	releaseErr := c.Client.Watch(ctx, func(tx *redis.Tx) error {
-		current, err := tx.Get(ctx, redisutil.CHOSENSEQ_KEY).Result()
-		if errors.Is(err, redis.Nil) {
-			return nil
+	releaseErr := c.backend.Update(ctx, []string{redisutil.CHOSENSEQ_KEY}, func(values map[string][]byte) ([]seqCoordinatorWrite, error) {
+		current, wasSet := values[redisutil.CHOSENSEQ_KEY]
+		if !wasSet || string(current) != c.config.Url() {
+			return nil, nil
 		}
-		if err != nil {
-			return err
-		}
-		if current != c.config.Url() {
-			return nil
-		}
-		pipe := tx.TxPipeline()
-		pipe.Del(ctx, redisutil.CHOSENSEQ_KEY)
-		err = execTestPipe(pipe, ctx)
-		if err != nil {
-			return fmt.Errorf("chosen sequencer failed to update redis: %w", err)
-		}
-		return nil
-	}, redisutil.CHOSENSEQ_KEY)
+		return []seqCoordinatorWrite{{key: redisutil.CHOSENSEQ_KEY, delete: true}}, nil
+	})
 	if releaseErr == nil {
 		return nil
 	}
 	// got error - was it still released?
-	current, readErr := c.Client.Get(ctx, redisutil.CHOSENSEQ_KEY).Result()
-	if errors.Is(readErr, redis.Nil) {
+	current, readErr := c.backend.Get(ctx, redisutil.CHOSENSEQ_KEY)
+	if errors.Is(readErr, errSeqCoordinatorKeyNotFound) {
 		return nil
 	}
-	if current != c.config.Url() {
+	if readErr == nil && string(current) != c.config.Url() {
 		return nil
 	}
 	return releaseErr
@@ -438,11 +547,11 @@ func (c *SeqCoordinator) wantsLockoutRelease(ctx context.Context) error {
 		return nil
 	}
 	myWantsLockoutKey := redisutil.WantsLockoutKeyFor(c.config.Url())
-	releaseErr := c.Client.Del(ctx, myWantsLockoutKey).Err()
+	releaseErr := c.backend.Delete(ctx, myWantsLockoutKey)
 	if releaseErr != nil {
 		// got error - was it still deleted?
-		readErr := c.Client.Get(ctx, myWantsLockoutKey).Err()
-		if !errors.Is(readErr, redis.Nil) {
+		_, readErr := c.backend.Get(ctx, myWantsLockoutKey)
+		if !errors.Is(readErr, errSeqCoordinatorKeyNotFound) {
 			return releaseErr
 		}
 	}
@@ -525,8 +634,8 @@ func (c *SeqCoordinator) deleteFinalizedMsgsFromRedis(ctx context.Context, final
 			// In non-init cases it doesn't matter how we delete as we always try to delete from prevFinalized to finalized
 			batchDeleteCount := 1000
 			for i := len(keys); i > 0; i -= batchDeleteCount {
-				if err := c.Client.Del(ctx, keys[max(0, i-batchDeleteCount):i]...).Err(); err != nil {
-					return fmt.Errorf("error deleting finalized messages and their signatures from redis: %w", err)
+				if err := c.backend.Delete(ctx, keys[max(0, i-batchDeleteCount):i]...); err != nil {
+					return fmt.Errorf("error deleting finalized messages and their signatures: %w", err)
 				}
 			}
 		}
@@ -534,16 +643,16 @@ func (c *SeqCoordinator) deleteFinalizedMsgsFromRedis(ctx context.Context, final
 		if err != nil {
 			return err
 		}
-		if err = c.Client.Set(ctx, redisutil.FINALIZED_MSG_COUNT_KEY, finalizedBytes, c.config.SeqNumDuration).Err(); err != nil {
-			return fmt.Errorf("couldn't set %s key to current finalizedMsgCount in redis: %w", redisutil.FINALIZED_MSG_COUNT_KEY, err)
+		if err = c.backend.Set(ctx, redisutil.FINALIZED_MSG_COUNT_KEY, finalizedBytes, c.config.SeqNumDuration); err != nil {
+			return fmt.Errorf("couldn't set %s key to current finalizedMsgCount: %w", redisutil.FINALIZED_MSG_COUNT_KEY, err)
 		}
 		return nil
 	}
 	prevFinalized, err := c.getRemoteFinalizedMsgCount(ctx)
-	if errors.Is(err, redis.Nil) {
+	if errors.Is(err, errSeqCoordinatorKeyNotFound) {
 		var keys []string
 		for msg := finalized - 1; msg > 0; msg-- {
-			exists, err := c.Client.Exists(ctx, redisutil.MessageKeyFor(msg), redisutil.MessageSigKeyFor(msg)).Result()
+			exists, err := c.backend.CountExisting(ctx, redisutil.MessageKeyFor(msg), redisutil.MessageSigKeyFor(msg))
 			if err != nil {
 				// If there is an error deleting finalized messages during init, we retry later either from this sequencer or from another
 				return err
@@ -553,12 +662,12 @@ func (c *SeqCoordinator) deleteFinalizedMsgsFromRedis(ctx context.Context, final
 			}
 			keys = append(keys, redisutil.MessageKeyFor(msg), redisutil.MessageSigKeyFor(msg))
 		}
-		log.Info("Initializing finalizedMsgCount and deleting finalized messages from redis", "finalizedMsgCount", finalized)
+		log.Info("Initializing finalizedMsgCount and deleting finalized messages", "finalizedMsgCount", finalized)
 		return deleteMsgsAndUpdateFinalizedMsgCount(keys)
 	} else if err != nil {
-		return fmt.Errorf("error getting finalizedMsgCount value from redis: %w", err)
+		return fmt.Errorf("error getting finalizedMsgCount value: %w", err)
 	}
-	remoteMsgCount, err := c.getRemoteMsgCountImpl(ctx, c.Client)
+	remoteMsgCount, err := c.getRemoteMsgCount(ctx)
 	if err != nil {
 		return fmt.Errorf("cannot get remote message count: %w", err)
 	}
@@ -606,7 +715,7 @@ func (c *SeqCoordinator) update(ctx context.Context) time.Duration {
 	remoteFinalizedMsgCount, err := c.getRemoteFinalizedMsgCount(ctx)
 	if err != nil {
 		loglevel := log.Error
-		if errors.Is(err, redis.Nil) {
+		if errors.Is(err, errSeqCoordinatorKeyNotFound) {
 			loglevel = log.Debug
 		}
 		loglevel("Cannot get remote finalized message count, might encounter failed to read message warnings later", "err", err)
@@ -617,22 +726,29 @@ func (c *SeqCoordinator) update(ctx context.Context) time.Duration {
 		return c.retryAfterRedisError()
 	}
 	readUntil := min(localMsgCount+c.config.MsgPerPoll, remoteMsgCount)
+	if readUntil > localMsgCount {
+		// messages read here are rebroadcast, so they need the chosen sequencer's fencing token
+		fencingToken, err := c.getRemoteFencingToken(ctx)
+		if err != nil {
+			log.Warn("cannot get remote fencing token", "err", err)
+			return c.retryAfterRedisError()
+		}
+		c.setFencingToken(fencingToken)
+	}
 	var messages []arbostypes.MessageWithMetadata
 	msgToRead := localMsgCount
 	var msgReadErr error
 	for msgToRead < readUntil && localMsgCount >= remoteFinalizedMsgCount {
-		var resString string
-		resString, msgReadErr = c.Client.Get(ctx, redisutil.MessageKeyFor(msgToRead)).Result()
+		var rsBytes []byte
+		rsBytes, msgReadErr = c.backend.Get(ctx, redisutil.MessageKeyFor(msgToRead))
 		if msgReadErr != nil {
 			log.Warn("coordinator failed reading message", "pos", msgToRead, "err", msgReadErr)
 			break
 		}
-		rsBytes := []byte(resString)
-		var sigString string
 		var sigBytes []byte
 		sigSeparateKey := true
-		sigString, msgReadErr = c.Client.Get(ctx, redisutil.MessageSigKeyFor(msgToRead)).Result()
-		if errors.Is(msgReadErr, redis.Nil) {
+		sigBytes, msgReadErr = c.backend.Get(ctx, redisutil.MessageSigKeyFor(msgToRead))
+		if errors.Is(msgReadErr, errSeqCoordinatorKeyNotFound) {
 			// no separate signature. Try reading old-style sig
 			if len(rsBytes) < 32 {
 				log.Warn("signature not found for msg", "pos", msgToRead)
@@ -645,8 +761,6 @@ func (c *SeqCoordinator) update(ctx context.Context) time.Duration {
 		} else if msgReadErr != nil {
 			log.Warn("coordinator failed reading sig", "pos", msgToRead, "err", msgReadErr)
 			break
-		} else {
-			sigBytes = []byte(sigString)
 		}
 		msgReadErr = c.signer.VerifySignature(ctx, sigBytes, arbmath.UintToBytes(uint64(msgToRead)), rsBytes)
 		if msgReadErr != nil {
@@ -872,7 +986,7 @@ func (c *SeqCoordinator) StopAndWait() {
 			time.Sleep(c.retryAfterRedisError())
 		}
 	}
-	_ = c.Client.Close()
+	_ = c.backend.Close()
 }
 
 func (c *SeqCoordinator) CurrentlyChosen() bool {
@@ -928,6 +1042,105 @@ func (c *SeqCoordinator) TryToHandoffChosenOne(ctx context.Context) bool {
 	return true
 }
 
+type HandoffDrillResult struct {
+	DryRun               bool                 `json:"dryRun"`
+	PreviousChosen       string               `json:"previousChosen"`
+	NewChosen            string               `json:"newChosen"`
+	MessageCount         arbutil.MessageIndex `json:"messageCount"`
+	PreviousFencingToken uint64               `json:"previousFencingToken"`
+	FencingToken         uint64               `json:"fencingToken"`
+	Duration             string               `json:"duration"`
+}
+
+// HandoffDrill hands the lockout off to the next sequencer in priority order in controlled steps, so operators can
+// rehearse failover. It checks another sequencer is ready to take over, drains this sequencer by releasing wanting the
+// lockout and waiting for the coordinator to forward to the successor, checkpoints that the coordinator holds every
+// message this sequencer sequenced, and waits for the successor to acquire the lockout with a new fencing token.
+// Afterwards this sequencer seeks the lockout again, so if it has a higher priority than the successor the lockout
+// is handed back, completing a failover and failback. With dryRun, only the checks are run.
+func (c *SeqCoordinator) HandoffDrill(ctx context.Context, dryRun bool) (*HandoffDrillResult, error) {
+	if !c.handoffDrillMutex.TryLock() {
+		return nil, errors.New("a handoff drill is already in progress")
+	}
+	defer c.handoffDrillMutex.Unlock()
+	start := time.Now()
+	result := &HandoffDrillResult{
+		DryRun:               dryRun,
+		PreviousChosen:       c.config.Url(),
+		PreviousFencingToken: c.FencingToken(),
+	}
+	if !c.CurrentlyChosen() {
+		return nil, errors.New("not the chosen sequencer")
+	}
+	successor, err := c.recommendSequencerWantingLockoutExcept(ctx, c.config.Url())
+	if err != nil {
+		return nil, fmt.Errorf("failed to find a sequencer to hand off to: %w", err)
+	}
+	if successor == "" {
+		return nil, errors.New("no other sequencer wants the lockout")
+	}
+	if err := c.checkpointHandoff(ctx, result); err != nil {
+		return nil, err
+	}
+	if dryRun {
+		result.NewChosen = successor
+		result.Duration = time.Since(start).String()
+		return result, nil
+	}
+
+	log.Info("starting handoff drill", "myUrl", c.config.Url(), "successor", successor, "fencingToken", result.PreviousFencingToken)
+	// drain: once we no longer want the lockout, the update loop forwards to the successor and releases it
+	avoided := c.AvoidLockout(ctx)
+	defer c.SeekLockout(ctx)
+	if !avoided {
+		return nil, errors.New("failed to release wanting the lockout")
+	}
+	if !c.TryToHandoffChosenOne(ctx) {
+		return nil, errors.New("timed out waiting to release the lockout")
+	}
+	if err := c.checkpointHandoff(ctx, result); err != nil {
+		return nil, err
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, c.config.HandoffTimeout)
+	defer cancel()
+	var waitErr error
+	acquired := c.waitFor(waitCtx, func() bool {
+		result.NewChosen, waitErr = c.CurrentChosenSequencer(waitCtx)
+		if waitErr != nil || result.NewChosen == "" || result.NewChosen == c.config.Url() {
+			return false
+		}
+		result.FencingToken, waitErr = c.getRemoteFencingToken(waitCtx)
+		return waitErr == nil && result.FencingToken > result.PreviousFencingToken
+	})
+	if !acquired {
+		if waitErr != nil {
+			return nil, fmt.Errorf("timed out waiting for a new sequencer to acquire the lockout: %w", waitErr)
+		}
+		return nil, errors.New("timed out waiting for a new sequencer to acquire the lockout")
+	}
+	result.Duration = time.Since(start).String()
+	log.Info("handoff drill complete", "newChosen", result.NewChosen, "fencingToken", result.FencingToken, "duration", result.Duration)
+	return result, nil
+}
+
+// checkpointHandoff checks the coordinator holds every message this sequencer has, so its successor won't resequence any.
+func (c *SeqCoordinator) checkpointHandoff(ctx context.Context, result *HandoffDrillResult) error {
+	localMsgCount, err := c.streamer.GetMessageCount()
+	if err != nil {
+		return err
+	}
+	remoteMsgCount, err := c.getRemoteMsgCount(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get remote message count: %w", err)
+	}
+	if remoteMsgCount < localMsgCount {
+		return fmt.Errorf("coordinator has %v messages but this sequencer has %v", remoteMsgCount, localMsgCount)
+	}
+	result.MessageCount = localMsgCount
+	return nil
+}
+
 // Undoes the effects of AvoidLockout. AvoidLockout must've been called before an equal number of times.
 func (c *SeqCoordinator) SeekLockout(ctx context.Context) {
 	c.wantsLockoutMutex.Lock()
@@ -0,0 +1,128 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbnode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/offchainlabs/nitro/util/redisutil"
+)
+
+var (
+	errSeqCoordinatorKeyNotFound = errors.New("coordination key not found")
+	errSeqCoordinatorTxConflict  = errors.New("coordination keys changed during update")
+)
+
+// seqCoordinatorWrite is a single write applied atomically by seqCoordinatorBackend.Update.
+// The key expires after ttl, or at expireAt if that's set.
+type seqCoordinatorWrite struct {
+	key      string
+	value    []byte
+	delete   bool
+	ttl      time.Duration
+	expireAt time.Time
+}
+
+// seqCoordinatorBackend is the shared store sequencers coordinate the lockout and share messages through.
+// Implementations must return errSeqCoordinatorKeyNotFound for keys that are unset or expired.
+type seqCoordinatorBackend interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+	// CountExisting returns how many of the given keys are set.
+	CountExisting(ctx context.Context, keys ...string) (int64, error)
+	// Update calls fn with the current values of the watched keys, leaving unset keys out of the map,
+	// and atomically applies the writes it returns. If any watched key changed since it was read,
+	// nothing is written and errSeqCoordinatorTxConflict is returned.
+	Update(ctx context.Context, watchedKeys []string, fn func(values map[string][]byte) ([]seqCoordinatorWrite, error)) error
+	Close() error
+}
+
+type redisSeqCoordinatorBackend struct {
+	client redis.UniversalClient
+}
+
+func newRedisSeqCoordinatorBackend(redisUrl string) (*redisSeqCoordinatorBackend, error) {
+	redisCoordinator, err := redisutil.NewRedisCoordinator(redisUrl)
+	if err != nil {
+		return nil, err
+	}
+	if redisCoordinator.Client == nil {
+		return nil, errors.New("seq coordinator redis url not set")
+	}
+	return &redisSeqCoordinatorBackend{client: redisCoordinator.Client}, nil
+}
+
+func (b *redisSeqCoordinatorBackend) Get(ctx context.Context, key string) ([]byte, error) {
+	value, err := b.client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, errSeqCoordinatorKeyNotFound
+	}
+	return value, err
+}
+
+func (b *redisSeqCoordinatorBackend) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return b.client.Set(ctx, key, value, ttl).Err()
+}
+
+func (b *redisSeqCoordinatorBackend) Delete(ctx context.Context, keys ...string) error {
+	return b.client.Del(ctx, keys...).Err()
+}
+
+func (b *redisSeqCoordinatorBackend) CountExisting(ctx context.Context, keys ...string) (int64, error) {
+	return b.client.Exists(ctx, keys...).Result()
+}
+
+func (b *redisSeqCoordinatorBackend) Update(ctx context.Context, watchedKeys []string, fn func(values map[string][]byte) ([]seqCoordinatorWrite, error)) error {
+	return b.client.Watch(ctx, func(tx *redis.Tx) error {
+		values := make(map[string][]byte)
+		for _, key := range watchedKeys {
+			value, err := tx.Get(ctx, key).Bytes()
+			if errors.Is(err, redis.Nil) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			values[key] = value
+		}
+		writes, err := fn(values)
+		if err != nil || len(writes) == 0 {
+			return err
+		}
+		pipe := tx.TxPipeline()
+		for _, write := range writes {
+			if write.delete {
+				pipe.Del(ctx, write.key)
+				continue
+			}
+			ttl := write.ttl
+			if !write.expireAt.IsZero() && ttl == 0 {
+				// the key must outlive the transaction for the expiry below to apply to it
+				ttl = 2 * time.Second
+			}
+			pipe.Set(ctx, write.key, write.value, ttl)
+			if !write.expireAt.IsZero() {
+				pipe.PExpireAt(ctx, write.key, write.expireAt)
+			}
+		}
+		err = execTestPipe(pipe, ctx)
+		if errors.Is(err, redis.TxFailedErr) {
+			return fmt.Errorf("%w: %w", errSeqCoordinatorTxConflict, err)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to update redis: %w", err)
+		}
+		return nil
+	}, watchedKeys...)
+}
+
+func (b *redisSeqCoordinatorBackend) Close() error {
+	return b.client.Close()
+}
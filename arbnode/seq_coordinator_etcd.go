@@ -0,0 +1,380 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbnode
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	flag "github.com/spf13/pflag"
+)
+
+type SeqCoordinatorEtcdConfig struct {
+	Endpoints      []string      `koanf:"endpoints"`
+	KeyPrefix      string        `koanf:"key-prefix"`
+	RequestTimeout time.Duration `koanf:"request-timeout"`
+}
+
+func SeqCoordinatorEtcdConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.StringSlice(prefix+".endpoints", DefaultSeqCoordinatorEtcdConfig.Endpoints, "the etcd endpoint URLs to coordinate via, e.g. http://etcd-0:2379")
+	f.String(prefix+".key-prefix", DefaultSeqCoordinatorEtcdConfig.KeyPrefix, "prefix for all coordination keys in etcd, so several chains can share an etcd cluster")
+	f.Duration(prefix+".request-timeout", DefaultSeqCoordinatorEtcdConfig.RequestTimeout, "timeout for each request to etcd")
+}
+
+var DefaultSeqCoordinatorEtcdConfig = SeqCoordinatorEtcdConfig{
+	Endpoints:      []string{},
+	KeyPrefix:      "",
+	RequestTimeout: 5 * time.Second,
+}
+
+func (c *SeqCoordinatorEtcdConfig) Validate() error {
+	if len(c.Endpoints) == 0 {
+		return errors.New("seq coordinator etcd backend requires at least one endpoint")
+	}
+	for _, endpoint := range c.Endpoints {
+		if !strings.HasPrefix(endpoint, "http://") && !strings.HasPrefix(endpoint, "https://") {
+			return fmt.Errorf("seq coordinator etcd endpoint %v must be an http or https URL", endpoint)
+		}
+	}
+	if c.RequestTimeout <= 0 {
+		return errors.New("seq coordinator etcd request timeout must be positive")
+	}
+	return nil
+}
+
+// etcd limits the number of operations in a transaction, 128 by default.
+const etcdMaxTxnOps = 128
+
+// How long after its expiry time a key with one may be kept, so that frequent writes can share leases.
+const etcdLeaseSlack = 2 * time.Second
+
+type etcdLease struct {
+	id       int64
+	expireAt time.Time
+}
+
+// etcdSeqCoordinatorBackend coordinates through etcd's v3 JSON gateway, which etcd serves on its client port,
+// so the node doesn't need the grpc client. Key expiry is implemented with leases, which are shared between
+// writes expiring at about the same time, as etcd isn't built to hold a lease per key.
+type etcdSeqCoordinatorBackend struct {
+	config     SeqCoordinatorEtcdConfig
+	client     *http.Client
+	endpoint   atomic.Uint32
+	leasesLock sync.Mutex
+	leases     []etcdLease
+}
+
+func newEtcdSeqCoordinatorBackend(config SeqCoordinatorEtcdConfig) (*etcdSeqCoordinatorBackend, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return &etcdSeqCoordinatorBackend{
+		config: config,
+		client: &http.Client{},
+	}, nil
+}
+
+type etcdKeyValue struct {
+	Key         []byte `json:"key,omitempty"`
+	Value       []byte `json:"value,omitempty"`
+	ModRevision int64  `json:"mod_revision,string,omitempty"`
+}
+
+type etcdRangeRequest struct {
+	Key       []byte `json:"key"`
+	CountOnly bool   `json:"count_only,omitempty"`
+}
+
+type etcdRangeResponse struct {
+	Kvs   []etcdKeyValue `json:"kvs"`
+	Count int64          `json:"count,string"`
+}
+
+type etcdPutRequest struct {
+	Key   []byte `json:"key"`
+	Value []byte `json:"value"`
+	Lease int64  `json:"lease,string,omitempty"`
+}
+
+type etcdDeleteRangeRequest struct {
+	Key []byte `json:"key"`
+}
+
+type etcdCompare struct {
+	Key         []byte `json:"key"`
+	Target      string `json:"target"`
+	Result      string `json:"result"`
+	ModRevision int64  `json:"mod_revision,string"`
+}
+
+type etcdRequestOp struct {
+	RequestRange       *etcdRangeRequest       `json:"request_range,omitempty"`
+	RequestPut         *etcdPutRequest         `json:"request_put,omitempty"`
+	RequestDeleteRange *etcdDeleteRangeRequest `json:"request_delete_range,omitempty"`
+}
+
+type etcdTxnRequest struct {
+	Compare []etcdCompare   `json:"compare,omitempty"`
+	Success []etcdRequestOp `json:"success,omitempty"`
+}
+
+type etcdTxnResponse struct {
+	Succeeded bool `json:"succeeded"`
+	Responses []struct {
+		ResponseRange *etcdRangeResponse `json:"response_range"`
+	} `json:"responses"`
+}
+
+type etcdLeaseGrantRequest struct {
+	TTL int64 `json:"TTL,string"`
+}
+
+type etcdLeaseGrantResponse struct {
+	ID  int64 `json:"ID,string"`
+	TTL int64 `json:"TTL,string"`
+}
+
+// call posts a request to the gateway. On failure, the next call goes to the next endpoint.
+// Requests aren't retried on another endpoint, as a failed transaction might still have been applied.
+func (b *etcdSeqCoordinatorBackend) call(ctx context.Context, path string, request interface{}, response interface{}) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, b.config.RequestTimeout)
+	defer cancel()
+	index := b.endpoint.Load()
+	endpoint := strings.TrimSuffix(b.config.Endpoints[int(index)%len(b.config.Endpoints)], "/")
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	resp, err := b.client.Do(httpReq)
+	if err != nil {
+		b.endpoint.CompareAndSwap(index, index+1)
+		return fmt.Errorf("etcd request to %v failed: %w", endpoint, err)
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		b.endpoint.CompareAndSwap(index, index+1)
+		return fmt.Errorf("failed reading etcd response from %v: %w", endpoint, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		if resp.StatusCode >= 500 {
+			b.endpoint.CompareAndSwap(index, index+1)
+		}
+		return fmt.Errorf("etcd request to %v%v failed with status %v: %v", endpoint, path, resp.StatusCode, string(respBody))
+	}
+	return json.Unmarshal(respBody, response)
+}
+
+func (b *etcdSeqCoordinatorBackend) key(key string) []byte {
+	return []byte(b.config.KeyPrefix + key)
+}
+
+// lease returns a lease expiring between notBefore and notAfter, granting a new one if none is cached.
+func (b *etcdSeqCoordinatorBackend) lease(ctx context.Context, notBefore, notAfter time.Time) (int64, error) {
+	b.leasesLock.Lock()
+	defer b.leasesLock.Unlock()
+	now := time.Now()
+	live := b.leases[:0]
+	for _, lease := range b.leases {
+		if lease.expireAt.After(now) {
+			live = append(live, lease)
+		}
+	}
+	b.leases = live
+	for _, lease := range b.leases {
+		if !lease.expireAt.Before(notBefore) && !lease.expireAt.After(notAfter) {
+			return lease.id, nil
+		}
+	}
+	// etcd leases have a granularity of a second
+	minTTL := (time.Until(notBefore) + time.Second - 1) / time.Second
+	ttl := max(time.Until(notAfter)/time.Second, minTTL, 1)
+	var response etcdLeaseGrantResponse
+	if err := b.call(ctx, "/v3/lease/grant", &etcdLeaseGrantRequest{TTL: int64(ttl)}, &response); err != nil {
+		return 0, err
+	}
+	// the lease's time to live starts when etcd receives the request, so this errs on the early side
+	lease := etcdLease{
+		id:       response.ID,
+		expireAt: now.Add(time.Duration(response.TTL) * time.Second),
+	}
+	b.leases = append(b.leases, lease)
+	return lease.id, nil
+}
+
+func (b *etcdSeqCoordinatorBackend) putOp(ctx context.Context, key string, value []byte, ttl time.Duration, expireAt time.Time) (etcdRequestOp, error) {
+	put := &etcdPutRequest{Key: b.key(key), Value: value}
+	if !expireAt.IsZero() {
+		// the key mustn't expire before expireAt, but should expire soon after it
+		lease, err := b.lease(ctx, expireAt, expireAt.Add(etcdLeaseSlack))
+		if err != nil {
+			return etcdRequestOp{}, err
+		}
+		put.Lease = lease
+	} else if ttl > 0 {
+		// keys with a time to live are kept for at least half of it
+		now := time.Now()
+		lease, err := b.lease(ctx, now.Add(ttl/2), now.Add(ttl))
+		if err != nil {
+			return etcdRequestOp{}, err
+		}
+		put.Lease = lease
+	}
+	return etcdRequestOp{RequestPut: put}, nil
+}
+
+func (b *etcdSeqCoordinatorBackend) txn(ctx context.Context, request *etcdTxnRequest) (*etcdTxnResponse, error) {
+	var response etcdTxnResponse
+	if err := b.call(ctx, "/v3/kv/txn", request, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// rangeKeys reads the keys in a single transaction, so they're read at the same revision.
+func (b *etcdSeqCoordinatorBackend) rangeKeys(ctx context.Context, keys []string, countOnly bool) ([]*etcdRangeResponse, error) {
+	request := &etcdTxnRequest{}
+	for _, key := range keys {
+		request.Success = append(request.Success, etcdRequestOp{RequestRange: &etcdRangeRequest{Key: b.key(key), CountOnly: countOnly}})
+	}
+	response, err := b.txn(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	if len(response.Responses) != len(keys) {
+		return nil, fmt.Errorf("expected %v responses from etcd but got %v", len(keys), len(response.Responses))
+	}
+	var ranges []*etcdRangeResponse
+	for _, op := range response.Responses {
+		if op.ResponseRange == nil {
+			return nil, errors.New("missing range response from etcd")
+		}
+		ranges = append(ranges, op.ResponseRange)
+	}
+	return ranges, nil
+}
+
+func (b *etcdSeqCoordinatorBackend) Get(ctx context.Context, key string) ([]byte, error) {
+	var response etcdRangeResponse
+	if err := b.call(ctx, "/v3/kv/range", &etcdRangeRequest{Key: b.key(key)}, &response); err != nil {
+		return nil, err
+	}
+	if len(response.Kvs) == 0 {
+		return nil, errSeqCoordinatorKeyNotFound
+	}
+	return response.Kvs[0].Value, nil
+}
+
+func (b *etcdSeqCoordinatorBackend) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	op, err := b.putOp(ctx, key, value, ttl, time.Time{})
+	if err != nil {
+		return err
+	}
+	var response json.RawMessage
+	return b.call(ctx, "/v3/kv/put", op.RequestPut, &response)
+}
+
+func (b *etcdSeqCoordinatorBackend) Delete(ctx context.Context, keys ...string) error {
+	for len(keys) > 0 {
+		batch := keys[:min(len(keys), etcdMaxTxnOps)]
+		keys = keys[len(batch):]
+		request := &etcdTxnRequest{}
+		for _, key := range batch {
+			request.Success = append(request.Success, etcdRequestOp{RequestDeleteRange: &etcdDeleteRangeRequest{Key: b.key(key)}})
+		}
+		if _, err := b.txn(ctx, request); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *etcdSeqCoordinatorBackend) CountExisting(ctx context.Context, keys ...string) (int64, error) {
+	var count int64
+	for len(keys) > 0 {
+		batch := keys[:min(len(keys), etcdMaxTxnOps)]
+		keys = keys[len(batch):]
+		ranges, err := b.rangeKeys(ctx, batch, true)
+		if err != nil {
+			return 0, err
+		}
+		for _, r := range ranges {
+			count += r.Count
+		}
+	}
+	return count, nil
+}
+
+func (b *etcdSeqCoordinatorBackend) Update(ctx context.Context, watchedKeys []string, fn func(values map[string][]byte) ([]seqCoordinatorWrite, error)) error {
+	if len(watchedKeys)+1 > etcdMaxTxnOps {
+		return fmt.Errorf("too many watched keys for an etcd transaction: %v", len(watchedKeys))
+	}
+	values := make(map[string][]byte)
+	request := &etcdTxnRequest{}
+	if len(watchedKeys) > 0 {
+		ranges, err := b.rangeKeys(ctx, watchedKeys, false)
+		if err != nil {
+			return err
+		}
+		for i, key := range watchedKeys {
+			// an unset key has a mod revision of 0, so this also checks it remains unset
+			var modRevision int64
+			if len(ranges[i].Kvs) > 0 {
+				values[key] = ranges[i].Kvs[0].Value
+				modRevision = ranges[i].Kvs[0].ModRevision
+			}
+			request.Compare = append(request.Compare, etcdCompare{
+				Key:         b.key(key),
+				Target:      "MOD",
+				Result:      "EQUAL",
+				ModRevision: modRevision,
+			})
+		}
+	}
+	writes, err := fn(values)
+	if err != nil || len(writes) == 0 {
+		return err
+	}
+	if len(writes) > etcdMaxTxnOps {
+		return fmt.Errorf("too many writes for an etcd transaction: %v", len(writes))
+	}
+	for _, write := range writes {
+		if write.delete {
+			request.Success = append(request.Success, etcdRequestOp{RequestDeleteRange: &etcdDeleteRangeRequest{Key: b.key(write.key)}})
+			continue
+		}
+		op, err := b.putOp(ctx, write.key, write.value, write.ttl, write.expireAt)
+		if err != nil {
+			return err
+		}
+		request.Success = append(request.Success, op)
+	}
+	response, err := b.txn(ctx, request)
+	if err != nil {
+		return fmt.Errorf("failed to update etcd: %w", err)
+	}
+	if !response.Succeeded {
+		return errSeqCoordinatorTxConflict
+	}
+	return nil
+}
+
+func (b *etcdSeqCoordinatorBackend) Close() error {
+	b.client.CloseIdleConnections()
+	return nil
+}
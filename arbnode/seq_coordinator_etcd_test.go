@@ -0,0 +1,95 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbnode
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestEtcdSeqCoordinatorBackend(t *testing.T) {
+	ctx := context.Background()
+	var mutex sync.Mutex
+	grants := 0
+	var lastTxn etcdTxnRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		switch r.URL.Path {
+		case "/v3/lease/grant":
+			var req etcdLeaseGrantRequest
+			Require(t, json.NewDecoder(r.Body).Decode(&req))
+			grants++
+			Require(t, json.NewEncoder(w).Encode(&etcdLeaseGrantResponse{ID: int64(grants), TTL: req.TTL}))
+		case "/v3/kv/txn":
+			Require(t, json.NewDecoder(r.Body).Decode(&lastTxn))
+			response := etcdTxnResponse{Succeeded: len(lastTxn.Compare) == 0}
+			for range lastTxn.Success {
+				response.Responses = append(response.Responses, struct {
+					ResponseRange *etcdRangeResponse `json:"response_range"`
+				}{&etcdRangeResponse{}})
+			}
+			Require(t, json.NewEncoder(w).Encode(&response))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	config := DefaultSeqCoordinatorEtcdConfig
+	config.Endpoints = []string{server.URL}
+	config.KeyPrefix = "chain/"
+	backend, err := newEtcdSeqCoordinatorBackend(config)
+	Require(t, err)
+
+	// writes expiring at about the same time share a lease
+	expireAt := time.Now().Add(time.Minute)
+	first, err := backend.lease(ctx, expireAt, expireAt.Add(etcdLeaseSlack))
+	Require(t, err)
+	second, err := backend.lease(ctx, expireAt.Add(time.Millisecond*250), expireAt.Add(time.Millisecond*250+etcdLeaseSlack))
+	Require(t, err)
+	mutex.Lock()
+	reused := first == second && grants == 1
+	mutex.Unlock()
+	if !reused {
+		Fail(t, "expected the lease to be reused, got leases", first, second)
+	}
+	_, err = backend.lease(ctx, expireAt.Add(time.Minute), expireAt.Add(time.Minute+etcdLeaseSlack))
+	Require(t, err)
+	mutex.Lock()
+	granted := grants
+	mutex.Unlock()
+	if granted != 2 {
+		Fail(t, "expected a new lease for a later expiry, got", granted, "grants")
+	}
+
+	// the watched key is read, then must be unchanged for the write to apply
+	err = backend.Update(ctx, []string{"watched"}, func(values map[string][]byte) ([]seqCoordinatorWrite, error) {
+		if _, isSet := values["watched"]; isSet {
+			Fail(t, "unset key has a value")
+		}
+		return []seqCoordinatorWrite{{key: "written", value: []byte("value"), expireAt: expireAt}}, nil
+	})
+	if !errors.Is(err, errSeqCoordinatorTxConflict) {
+		Fail(t, "expected a conflict but got", err)
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(lastTxn.Compare) != 1 || string(lastTxn.Compare[0].Key) != "chain/watched" || lastTxn.Compare[0].ModRevision != 0 {
+		Fail(t, "unexpected transaction compares", lastTxn.Compare)
+	}
+	if len(lastTxn.Success) != 1 || lastTxn.Success[0].RequestPut == nil || lastTxn.Success[0].RequestPut.Lease != first {
+		Fail(t, "unexpected transaction writes", lastTxn.Success)
+	}
+
+	if err := (&SeqCoordinatorEtcdConfig{Endpoints: []string{"etcd:2379"}, RequestTimeout: time.Second}).Validate(); err == nil {
+		Fail(t, "accepted an endpoint without a scheme")
+	}
+}
@@ -14,6 +14,7 @@ import (
 
 	"github.com/offchainlabs/nitro/arbos/arbostypes"
 	"github.com/offchainlabs/nitro/arbutil"
+	m "github.com/offchainlabs/nitro/broadcaster/message"
 	"github.com/offchainlabs/nitro/util/redisutil"
 	"github.com/offchainlabs/nitro/util/signature"
 )
@@ -122,12 +123,12 @@ func TestRedisSeqCoordinatorAtomic(t *testing.T) {
 	for i := 0; i < NumOfThreads; i++ {
 		config := coordConfig
 		config.MyUrl = fmt.Sprint(i)
-		redisCoordinator, err := redisutil.NewRedisCoordinator(config.RedisUrl)
+		backend, err := newRedisSeqCoordinatorBackend(config.RedisUrl)
 		Require(t, err)
 		coordinator := &SeqCoordinator{
-			RedisCoordinator: *redisCoordinator,
-			config:           config,
-			signer:           nullSigner,
+			backend: backend,
+			config:  config,
+			signer:  nullSigner,
 		}
 		go coordinatorTestThread(ctx, coordinator, &testData)
 	}
@@ -178,12 +179,13 @@ func TestSeqCoordinatorDeletesFinalizedMessages(t *testing.T) {
 
 	config := coordConfig
 	config.MyUrl = "test"
-	redisCoordinator, err := redisutil.NewRedisCoordinator(config.RedisUrl)
+	backend, err := newRedisSeqCoordinatorBackend(config.RedisUrl)
 	Require(t, err)
+	redisClient := backend.client
 	coordinator := &SeqCoordinator{
-		RedisCoordinator: *redisCoordinator,
-		config:           config,
-		signer:           nullSigner,
+		backend: backend,
+		config:  config,
+		signer:  nullSigner,
 	}
 
 	// Add messages to redis
@@ -191,18 +193,18 @@ func TestSeqCoordinatorDeletesFinalizedMessages(t *testing.T) {
 	msgBytes, err := coordinator.msgCountToSignedBytes(0)
 	Require(t, err)
 	for i := arbutil.MessageIndex(1); i <= 10; i++ {
-		err = coordinator.Client.Set(ctx, redisutil.MessageKeyFor(i), msgBytes, time.Hour).Err()
+		err = redisClient.Set(ctx, redisutil.MessageKeyFor(i), msgBytes, time.Hour).Err()
 		Require(t, err)
-		err = coordinator.Client.Set(ctx, redisutil.MessageSigKeyFor(i), msgBytes, time.Hour).Err()
+		err = redisClient.Set(ctx, redisutil.MessageSigKeyFor(i), msgBytes, time.Hour).Err()
 		Require(t, err)
 		keys = append(keys, redisutil.MessageKeyFor(i), redisutil.MessageSigKeyFor(i))
 	}
 	// Set msgCount key
 	msgCountBytes, err := coordinator.msgCountToSignedBytes(11)
 	Require(t, err)
-	err = coordinator.Client.Set(ctx, redisutil.MSG_COUNT_KEY, msgCountBytes, time.Hour).Err()
+	err = redisClient.Set(ctx, redisutil.MSG_COUNT_KEY, msgCountBytes, time.Hour).Err()
 	Require(t, err)
-	exists, err := coordinator.Client.Exists(ctx, keys...).Result()
+	exists, err := redisClient.Exists(ctx, keys...).Result()
 	Require(t, err)
 	if exists != 20 {
 		t.Fatal("couldn't find all messages and signatures in redis")
@@ -213,7 +215,7 @@ func TestSeqCoordinatorDeletesFinalizedMessages(t *testing.T) {
 	Require(t, err)
 
 	// Check if messages and signatures were deleted successfully
-	exists, err = coordinator.Client.Exists(ctx, keys[:8]...).Result()
+	exists, err = redisClient.Exists(ctx, keys[:8]...).Result()
 	Require(t, err)
 	if exists != 0 {
 		t.Fatal("finalized messages and signatures in range 1 to 4 were not deleted")
@@ -229,7 +231,7 @@ func TestSeqCoordinatorDeletesFinalizedMessages(t *testing.T) {
 	// Try deleting finalized messages when theres already a finalizedMsgCount
 	err = coordinator.deleteFinalizedMsgsFromRedis(ctx, 7)
 	Require(t, err)
-	exists, err = coordinator.Client.Exists(ctx, keys[8:12]...).Result()
+	exists, err = redisClient.Exists(ctx, keys[8:12]...).Result()
 	Require(t, err)
 	if exists != 0 {
 		t.Fatal("finalized messages and signatures in range 5 to 6 were not deleted")
@@ -241,9 +243,76 @@ func TestSeqCoordinatorDeletesFinalizedMessages(t *testing.T) {
 	}
 
 	// Check that non-finalized messages are still available in redis
-	exists, err = coordinator.Client.Exists(ctx, keys[12:]...).Result()
+	exists, err = redisClient.Exists(ctx, keys[12:]...).Result()
 	Require(t, err)
 	if exists != 8 {
 		t.Fatal("non-finalized messages and signatures in range 7 to 10 are not fully available")
 	}
 }
+
+func TestSeqCoordinatorFencingToken(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	coordConfig := TestSeqCoordinatorConfig
+	coordConfig.Signer.ECDSA.AcceptSequencer = false
+	coordConfig.Signer.SymmetricFallback = true
+	coordConfig.Signer.SymmetricSign = true
+	coordConfig.Signer.Symmetric.Dangerous.DisableSignatureVerification = true
+	coordConfig.Signer.Symmetric.SigningKey = ""
+	nullSigner, err := signature.NewSignVerify(&coordConfig.Signer, nil, nil)
+	Require(t, err)
+	coordConfig.RedisUrl = redisutil.CreateTestRedis(ctx, t)
+
+	var coordinators []*SeqCoordinator
+	for i := 0; i < 2; i++ {
+		config := coordConfig
+		config.MyUrl = fmt.Sprint(i)
+		backend, err := newRedisSeqCoordinatorBackend(config.RedisUrl)
+		Require(t, err)
+		coordinators = append(coordinators, &SeqCoordinator{
+			backend: backend,
+			config:  config,
+			signer:  nullSigner,
+		})
+	}
+	acquire := func(coordinator *SeqCoordinator, expectedToken uint64) {
+		t.Helper()
+		Require(t, coordinator.acquireLockoutAndWriteMessage(ctx, 0, 0, nil))
+		if coordinator.FencingToken() != expectedToken {
+			Fail(t, "coordinator", coordinator.config.Url(), "has fencing token", coordinator.FencingToken(), "expected", expectedToken)
+		}
+	}
+
+	acquire(coordinators[0], 1)
+	// refreshing the lockout keeps the token
+	acquire(coordinators[0], 1)
+	if err := coordinators[1].acquireLockoutAndWriteMessage(ctx, 0, 0, nil); err == nil {
+		Fail(t, "acquired the lockout while another sequencer held it")
+	}
+	Require(t, coordinators[0].chosenOneRelease(ctx))
+	acquire(coordinators[1], 2)
+	Require(t, coordinators[1].chosenOneRelease(ctx))
+	acquire(coordinators[0], 3)
+}
+
+func TestFeedFencing(t *testing.T) {
+	feedMessage := func(pos arbutil.MessageIndex, token uint64) *m.BroadcastFeedMessage {
+		return &m.BroadcastFeedMessage{SequenceNumber: pos, FencingToken: token}
+	}
+	var fencing feedFencingState
+	if kept := fencing.filter([]*m.BroadcastFeedMessage{feedMessage(10, 1), feedMessage(11, 0), feedMessage(12, 2)}); len(kept) != 3 {
+		Fail(t, "fenced off messages with increasing tokens, kept", len(kept))
+	}
+	// messages from before the new sequencer took over are still accepted from the old one
+	if kept := fencing.filter([]*m.BroadcastFeedMessage{feedMessage(11, 1)}); len(kept) != 1 {
+		Fail(t, "fenced off a message sequenced before the lockout changed hands")
+	}
+	// but not ones it sequences afterwards
+	if kept := fencing.filter([]*m.BroadcastFeedMessage{feedMessage(11, 1), feedMessage(12, 1), feedMessage(13, 1)}); len(kept) != 1 {
+		Fail(t, "didn't fence off messages from the previous sequencer, kept", len(kept))
+	}
+	if kept := fencing.filter([]*m.BroadcastFeedMessage{feedMessage(13, 0)}); len(kept) != 1 {
+		Fail(t, "fenced off a message without a token")
+	}
+}
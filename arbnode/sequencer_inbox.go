@@ -70,7 +70,7 @@ func NewSequencerInbox(client arbutil.L1Interface, addr common.Address, fromBloc
 }
 
 func (i *SequencerInbox) GetBatchCount(ctx context.Context, blockNumber *big.Int) (uint64, error) {
-	if blockNumber.IsInt64() && blockNumber.Int64() < i.fromBlock {
+	if blockNumber != nil && blockNumber.IsInt64() && blockNumber.Int64() < i.fromBlock {
 		return 0, nil
 	}
 	opts := &bind.CallOpts{
@@ -47,7 +47,11 @@ type TransactionStreamer struct {
 	chainConfig      *params.ChainConfig
 	exec             execution.ExecutionSequencer
 	execLastMsgCount arbutil.MessageIndex
-	validator        *staker.BlockValidator
+
+	// messages before this position have been passed to the execution client for sender recovery
+	senderPrefetchedPos arbutil.MessageIndex
+
+	validator *staker.BlockValidator
 
 	db             ethdb.Database
 	fatalErrChan   chan<- error
@@ -64,6 +68,9 @@ type TransactionStreamer struct {
 	broadcasterQueuedMessagesPos         atomic.Uint64
 	broadcasterQueuedMessagesActiveReorg bool
 
+	feedFencingMutex sync.Mutex
+	feedFencing      feedFencingState
+
 	coordinator     *SeqCoordinator
 	broadcastServer *broadcaster.Broadcaster
 	inboxReader     *InboxReader
@@ -74,6 +81,8 @@ type TransactionStreamerConfig struct {
 	MaxBroadcasterQueueSize int           `koanf:"max-broadcaster-queue-size"`
 	MaxReorgResequenceDepth int64         `koanf:"max-reorg-resequence-depth" reload:"hot"`
 	ExecuteMessageLoopDelay time.Duration `koanf:"execute-message-loop-delay" reload:"hot"`
+	SenderRecoveryLookahead uint64        `koanf:"sender-recovery-lookahead" reload:"hot"`
+	TrackProvenance         bool          `koanf:"track-provenance"`
 }
 
 type TransactionStreamerConfigFetcher func() *TransactionStreamerConfig
@@ -82,18 +91,24 @@ var DefaultTransactionStreamerConfig = TransactionStreamerConfig{
 	MaxBroadcasterQueueSize: 50_000,
 	MaxReorgResequenceDepth: 1024,
 	ExecuteMessageLoopDelay: time.Millisecond * 100,
+	SenderRecoveryLookahead: 64,
+	TrackProvenance:         false,
 }
 
 var TestTransactionStreamerConfig = TransactionStreamerConfig{
 	MaxBroadcasterQueueSize: 10_000,
 	MaxReorgResequenceDepth: 128 * 1024,
 	ExecuteMessageLoopDelay: time.Millisecond,
+	SenderRecoveryLookahead: 64,
+	TrackProvenance:         false,
 }
 
 func TransactionStreamerConfigAddOptions(prefix string, f *flag.FlagSet) {
 	f.Int(prefix+".max-broadcaster-queue-size", DefaultTransactionStreamerConfig.MaxBroadcasterQueueSize, "maximum cache of pending broadcaster messages")
 	f.Int64(prefix+".max-reorg-resequence-depth", DefaultTransactionStreamerConfig.MaxReorgResequenceDepth, "maximum number of messages to attempt to resequence on reorg (0 = never resequence, -1 = always resequence)")
 	f.Duration(prefix+".execute-message-loop-delay", DefaultTransactionStreamerConfig.ExecuteMessageLoopDelay, "delay when polling calls to execute messages")
+	f.Uint64(prefix+".sender-recovery-lookahead", DefaultTransactionStreamerConfig.SenderRecoveryLookahead, "number of messages ahead of execution to queue for transaction sender recovery, if supported by the execution client (0 = disabled)")
+	f.Bool(prefix+".track-provenance", DefaultTransactionStreamerConfig.TrackProvenance, "record for each message whether it was sequenced locally, received from the feed, or read from the parent chain, and when it was received and confirmed")
 }
 
 func NewTransactionStreamer(
@@ -386,6 +401,10 @@ func (s *TransactionStreamer) reorg(batch ethdb.Batch, count arbutil.MessageInde
 	if err != nil {
 		return err
 	}
+	err = deleteStartingAt(s.db, batch, messageProvenancePrefix, uint64ToKey(uint64(count)))
+	if err != nil {
+		return err
+	}
 
 	for i := 0; i < len(messagesResults); i++ {
 		pos := count + arbutil.MessageIndex(i)
@@ -525,7 +544,44 @@ func (s *TransactionStreamer) FeedPendingMessageCount() arbutil.MessageIndex {
 	return arbutil.MessageIndex(pos + uint64(len(s.broadcasterQueuedMessages)))
 }
 
+// feedFencingState tracks the highest sequencer coordinator fencing token seen on the feed, and the first
+// sequence number it was seen at.
+type feedFencingState struct {
+	token   uint64
+	fromPos arbutil.MessageIndex
+}
+
+// filter returns the prefix of feedMessages that isn't fenced off. A message is fenced off if it has a lower
+// token than one already seen at or before its sequence number, meaning it comes from a sequencer which lost
+// the lockout. Messages without a token weren't sequenced through a coordinator and are never fenced off.
+func (f *feedFencingState) filter(feedMessages []*m.BroadcastFeedMessage) []*m.BroadcastFeedMessage {
+	for i, feedMessage := range feedMessages {
+		token := feedMessage.FencingToken
+		if token == 0 {
+			continue
+		}
+		if token > f.token {
+			f.token = token
+			f.fromPos = feedMessage.SequenceNumber
+		} else if token < f.token && feedMessage.SequenceNumber >= f.fromPos {
+			log.Warn("ignoring feed messages from a fenced off sequencer", "pos", feedMessage.SequenceNumber, "fencingToken", token, "latestFencingToken", f.token, "latestFromPos", f.fromPos)
+			return feedMessages[:i]
+		}
+	}
+	return feedMessages
+}
+
+// SetFencingToken sets the sequencer coordinator fencing token included in the messages this node broadcasts.
+func (s *TransactionStreamer) SetFencingToken(token uint64) {
+	if s.broadcastServer != nil {
+		s.broadcastServer.SetFencingToken(token)
+	}
+}
+
 func (s *TransactionStreamer) AddBroadcastMessages(feedMessages []*m.BroadcastFeedMessage) error {
+	s.feedFencingMutex.Lock()
+	feedMessages = s.feedFencing.filter(feedMessages)
+	s.feedFencingMutex.Unlock()
 	if len(feedMessages) == 0 {
 		return nil
 	}
@@ -676,6 +732,9 @@ func (s *TransactionStreamer) AddMessagesAndEndBatch(pos arbutil.MessageIndex, m
 		s.exec.MarkFeedStart(pos + arbutil.MessageIndex(len(messages)))
 		s.reorgMutex.RLock()
 		dups, _, _, err := s.countDuplicateMessages(pos, messagesWithBlockHash, nil)
+		if err == nil && dups == len(messages) {
+			err = s.confirmProvenance(pos, dups, &batch)
+		}
 		s.reorgMutex.RUnlock()
 		if err != nil {
 			return err
@@ -825,6 +884,9 @@ func (s *TransactionStreamer) addMessagesAndEndBatchImpl(messageStartPos arbutil
 			return err
 		}
 		if duplicates > 0 {
+			if err := s.confirmProvenance(messageStartPos, duplicates, &batch); err != nil {
+				return err
+			}
 			lastDelayedRead = messages[duplicates-1].MessageWithMeta.DelayedMessagesRead
 			messages = messages[duplicates:]
 			messageStartPos += arbutil.MessageIndex(duplicates)
@@ -833,6 +895,8 @@ func (s *TransactionStreamer) addMessagesAndEndBatchImpl(messageStartPos arbutil
 			hasNewConfirmedMessages = true
 		}
 	}
+	// messages after these come from the broadcaster queue
+	nonFeedMessages := len(messages)
 
 	clearQueueOnSuccess := false
 	if (s.broadcasterQueuedMessagesActiveReorg && messageStartPos <= broadcastStartPos) ||
@@ -867,6 +931,7 @@ func (s *TransactionStreamer) addMessagesAndEndBatchImpl(messageStartPos arbutil
 			lastDelayedRead = messages[duplicates-1].MessageWithMeta.DelayedMessagesRead
 			messages = messages[duplicates:]
 			messageStartPos += arbutil.MessageIndex(duplicates)
+			nonFeedMessages = max(nonFeedMessages-duplicates, 0)
 		}
 	}
 	if oldMsg != nil {
@@ -915,7 +980,22 @@ func (s *TransactionStreamer) addMessagesAndEndBatchImpl(messageStartPos arbutil
 		return endBatch(batch)
 	}
 
-	err := s.writeMessages(messageStartPos, messages, batch)
+	var sources []MessageSource
+	if s.trackProvenance() {
+		source := MessageSourceCoordinator
+		if messagesAreConfirmed {
+			source = MessageSourceParentChain
+		}
+		sources = make([]MessageSource, len(messages))
+		for i := range sources {
+			if i < nonFeedMessages {
+				sources[i] = source
+			} else {
+				sources[i] = MessageSourceFeed
+			}
+		}
+	}
+	err := s.writeMessages(messageStartPos, messages, sources, batch)
 	if err != nil {
 		return err
 	}
@@ -978,7 +1058,11 @@ func (s *TransactionStreamer) WriteMessageFromSequencer(
 		BlockHash:       &msgResult.BlockHash,
 	}
 
-	if err := s.writeMessages(pos, []arbostypes.MessageWithMetadataAndBlockHash{msgWithBlockHash}, nil); err != nil {
+	var sources []MessageSource
+	if s.trackProvenance() {
+		sources = []MessageSource{MessageSourceSequencer}
+	}
+	if err := s.writeMessages(pos, []arbostypes.MessageWithMetadataAndBlockHash{msgWithBlockHash}, sources, nil); err != nil {
 		return err
 	}
 	s.broadcastMessages([]arbostypes.MessageWithMetadataAndBlockHash{msgWithBlockHash}, pos)
@@ -1039,7 +1123,8 @@ func (s *TransactionStreamer) broadcastMessages(
 
 // The mutex must be held, and pos must be the latest message count.
 // `batch` may be nil, which initializes a new batch. The batch is closed out in this function.
-func (s *TransactionStreamer) writeMessages(pos arbutil.MessageIndex, messages []arbostypes.MessageWithMetadataAndBlockHash, batch ethdb.Batch) error {
+// `sources` is nil unless provenance is tracked, in which case it holds the source of each message.
+func (s *TransactionStreamer) writeMessages(pos arbutil.MessageIndex, messages []arbostypes.MessageWithMetadataAndBlockHash, sources []MessageSource, batch ethdb.Batch) error {
 	if batch == nil {
 		batch = s.db.NewBatch()
 	}
@@ -1048,6 +1133,12 @@ func (s *TransactionStreamer) writeMessages(pos arbutil.MessageIndex, messages [
 		if err != nil {
 			return err
 		}
+		if i < len(sources) {
+			err = s.writeProvenance(pos+arbutil.MessageIndex(i), newMessageProvenance(sources[i]), batch)
+			if err != nil {
+				return err
+			}
+		}
 	}
 
 	err := setMessageCount(batch, pos+arbutil.MessageIndex(len(messages)))
@@ -1164,6 +1255,7 @@ func (s *TransactionStreamer) ExecuteNextMsg(ctx context.Context, exec execution
 		log.Error("feedOneMsg failed to readMessage", "err", err, "pos", pos)
 		return false
 	}
+	s.prefetchSenders(pos+1, msgCount)
 	var msgForPrefetch *arbostypes.MessageWithMetadata
 	if pos+1 < msgCount {
 		msg, err := s.GetMessage(pos + 1)
@@ -1206,6 +1298,41 @@ func (s *TransactionStreamer) ExecuteNextMsg(ctx context.Context, exec execution
 	return pos+1 < msgCount
 }
 
+// prefetchSenders hands messages from pos up to the configured lookahead to the execution
+// client for sender recovery, skipping messages that were already handed over.
+func (s *TransactionStreamer) prefetchSenders(pos arbutil.MessageIndex, msgCount arbutil.MessageIndex) {
+	prefetcher, ok := s.exec.(execution.SenderPrefetcher)
+	if !ok {
+		return
+	}
+	lookahead := s.config().SenderRecoveryLookahead
+	if lookahead == 0 {
+		return
+	}
+	end := pos + arbutil.MessageIndex(lookahead)
+	if end > msgCount {
+		end = msgCount
+	}
+	start := pos
+	if s.senderPrefetchedPos > start && s.senderPrefetchedPos <= end {
+		start = s.senderPrefetchedPos
+	}
+	if start >= end {
+		return
+	}
+	msgs := make([]*arbostypes.MessageWithMetadata, 0, end-start)
+	for i := start; i < end; i++ {
+		msg, err := s.GetMessage(i)
+		if err != nil {
+			log.Warn("failed to read message for sender recovery", "pos", i, "err", err)
+			break
+		}
+		msgs = append(msgs, msg)
+	}
+	prefetcher.PrefetchSenders(start, msgs)
+	s.senderPrefetchedPos = start + arbutil.MessageIndex(len(msgs))
+}
+
 func (s *TransactionStreamer) executeMessages(ctx context.Context, ignored struct{}) time.Duration {
 	if s.ExecuteNextMsg(ctx, s.exec) {
 		return 0
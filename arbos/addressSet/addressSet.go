@@ -88,6 +88,19 @@ func (as *AddressSet) AllMembers(maxNumToReturn uint64) ([]common.Address, error
 	return ret, nil
 }
 
+// MemberAt returns the member at the given position, counting from zero, in the order AllMembers lists them.
+func (as *AddressSet) MemberAt(index uint64) (common.Address, error) {
+	size, err := as.size.Get()
+	if err != nil {
+		return common.Address{}, err
+	}
+	if index >= size {
+		return common.Address{}, errors.New("address set index out of range")
+	}
+	sba := as.backingStorage.OpenStorageBackedAddress(index + 1)
+	return sba.Get()
+}
+
 func (as *AddressSet) ClearList() error {
 	size, err := as.size.Get()
 	if err != nil || size == 0 {
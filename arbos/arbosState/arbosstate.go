@@ -12,6 +12,7 @@ import (
 	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/params"
@@ -23,13 +24,22 @@ import (
 	"github.com/offchainlabs/nitro/arbos/addressSet"
 	"github.com/offchainlabs/nitro/arbos/addressTable"
 	"github.com/offchainlabs/nitro/arbos/arbostypes"
+	"github.com/offchainlabs/nitro/arbos/batchdictionaries"
 	"github.com/offchainlabs/nitro/arbos/blockhash"
 	"github.com/offchainlabs/nitro/arbos/burn"
+	"github.com/offchainlabs/nitro/arbos/collectorhistory"
+	"github.com/offchainlabs/nitro/arbos/feetoken"
+	"github.com/offchainlabs/nitro/arbos/l1blockmap"
 	"github.com/offchainlabs/nitro/arbos/l1pricing"
+	"github.com/offchainlabs/nitro/arbos/l1timing"
 	"github.com/offchainlabs/nitro/arbos/l2pricing"
 	"github.com/offchainlabs/nitro/arbos/merkleAccumulator"
+	"github.com/offchainlabs/nitro/arbos/methodacl"
+	"github.com/offchainlabs/nitro/arbos/ownerhistory"
+	"github.com/offchainlabs/nitro/arbos/pricehistory"
 	"github.com/offchainlabs/nitro/arbos/programs"
 	"github.com/offchainlabs/nitro/arbos/retryables"
+	"github.com/offchainlabs/nitro/arbos/sponsorship"
 	"github.com/offchainlabs/nitro/arbos/storage"
 	"github.com/offchainlabs/nitro/arbos/util"
 	"github.com/offchainlabs/nitro/util/testhelpers/env"
@@ -60,12 +70,24 @@ type ArbosState struct {
 	genesisBlockNum               storage.StorageBackedUint64
 	infraFeeAccount               storage.StorageBackedAddress
 	brotliCompressionLevel        storage.StorageBackedUint64 // brotli compression level used for pricing
+	maxTxSize                     storage.StorageBackedUint64 // largest user tx accepted in bytes, or 0 for no limit
+	featureFlags                  *storage.Storage            // chain owner overrides of ArbOS features, keyed by Feature
+	feeTokenState                 *feetoken.FeeTokenState
+	methodACL                     *methodacl.MethodACL
+	chainOwnerHistory             *ownerhistory.OwnerHistory
+	l1Timing                      *l1timing.L1TimingState
+	feeCollectorHistory           *collectorhistory.CollectorHistory
+	sponsorship                   *sponsorship.Sponsorship
+	l1BlockMap                    *l1blockmap.L1BlockMap
+	priceHistory                  *pricehistory.PriceHistory
+	batchDictionaries             *batchdictionaries.BatchDictionaries
 	backingStorage                *storage.Storage
 	Burner                        burn.Burner
 }
 
 var ErrUninitializedArbOS = errors.New("ArbOS uninitialized")
 var ErrAlreadyInitialized = errors.New("ArbOS is already initialized")
+var ErrOversizedTx = errors.New("tx exceeds the chain's maximum size")
 
 func OpenArbosState(stateDB vm.StateDB, burner burn.Burner) (*ArbosState, error) {
 	backingStorage := storage.NewGeth(stateDB, burner)
@@ -78,8 +100,8 @@ func OpenArbosState(stateDB vm.StateDB, burner burn.Burner) (*ArbosState, error)
 	}
 	return &ArbosState{
 		arbosVersion,
-		31,
-		31,
+		ArbosVersion_FirstCustom,
+		ArbosVersion_FirstCustom,
 		backingStorage.OpenStorageBackedUint64(uint64(upgradeVersionOffset)),
 		backingStorage.OpenStorageBackedUint64(uint64(upgradeTimestampOffset)),
 		backingStorage.OpenStorageBackedAddress(uint64(networkFeeAccountOffset)),
@@ -96,16 +118,34 @@ func OpenArbosState(stateDB vm.StateDB, burner burn.Burner) (*ArbosState, error)
 		backingStorage.OpenStorageBackedUint64(uint64(genesisBlockNumOffset)),
 		backingStorage.OpenStorageBackedAddress(uint64(infraFeeAccountOffset)),
 		backingStorage.OpenStorageBackedUint64(uint64(brotliCompressionLevelOffset)),
+		backingStorage.OpenStorageBackedUint64(uint64(maxTxSizeOffset)),
+		backingStorage.OpenCachedSubStorage(featureFlagsSubspace),
+		feetoken.OpenFeeTokenState(backingStorage.OpenCachedSubStorage(feeTokenSubspace)),
+		methodacl.Open(backingStorage.OpenCachedSubStorage(methodACLSubspace)),
+		ownerhistory.Open(backingStorage.OpenCachedSubStorage(chainOwnerHistorySubspace)),
+		l1timing.Open(backingStorage.OpenCachedSubStorage(l1TimingSubspace)),
+		collectorhistory.Open(backingStorage.OpenCachedSubStorage(feeCollectorHistorySubspace)),
+		sponsorship.Open(backingStorage.OpenCachedSubStorage(sponsorshipSubspace)),
+		l1blockmap.Open(backingStorage.OpenSubStorage(l1BlockMapSubspace)),
+		pricehistory.Open(backingStorage.OpenCachedSubStorage(priceHistorySubspace)),
+		batchdictionaries.Open(backingStorage.OpenCachedSubStorage(batchDictionariesSubspace)),
 		backingStorage,
 		burner,
 	}, nil
 }
 
 func OpenSystemArbosState(stateDB vm.StateDB, tracingInfo *util.TracingInfo, readOnly bool) (*ArbosState, error) {
-	burner := burn.NewSystemBurner(tracingInfo, readOnly)
-	newState, err := OpenArbosState(stateDB, burner)
-	burner.Restrict(err)
-	return newState, err
+	open := func(burner burn.Burner) (*ArbosState, error) {
+		newState, err := OpenArbosState(stateDB, burner)
+		burner.Restrict(err)
+		return newState, err
+	}
+	if tracingInfo == nil {
+		if cache := getBlockCache(stateDB); cache != nil {
+			return cache.openCached(readOnly, open)
+		}
+	}
+	return open(burn.NewSystemBurner(tracingInfo, readOnly))
 }
 
 func OpenSystemArbosStateOrPanic(stateDB vm.StateDB, tracingInfo *util.TracingInfo, readOnly bool) *ArbosState {
@@ -158,22 +198,65 @@ const (
 	genesisBlockNumOffset
 	infraFeeAccountOffset
 	brotliCompressionLevelOffset
+	maxTxSizeOffset
 )
 
 type SubspaceID []byte
 
 var (
-	l1PricingSubspace    SubspaceID = []byte{0}
-	l2PricingSubspace    SubspaceID = []byte{1}
-	retryablesSubspace   SubspaceID = []byte{2}
-	addressTableSubspace SubspaceID = []byte{3}
-	chainOwnerSubspace   SubspaceID = []byte{4}
-	sendMerkleSubspace   SubspaceID = []byte{5}
-	blockhashesSubspace  SubspaceID = []byte{6}
-	chainConfigSubspace  SubspaceID = []byte{7}
-	programsSubspace     SubspaceID = []byte{8}
+	l1PricingSubspace           SubspaceID = []byte{0}
+	l2PricingSubspace           SubspaceID = []byte{1}
+	retryablesSubspace          SubspaceID = []byte{2}
+	addressTableSubspace        SubspaceID = []byte{3}
+	chainOwnerSubspace          SubspaceID = []byte{4}
+	sendMerkleSubspace          SubspaceID = []byte{5}
+	blockhashesSubspace         SubspaceID = []byte{6}
+	chainConfigSubspace         SubspaceID = []byte{7}
+	programsSubspace            SubspaceID = []byte{8}
+	featureFlagsSubspace        SubspaceID = []byte{9}
+	scheduledChangesSubspace    SubspaceID = []byte{10}
+	statisticsSubspace          SubspaceID = []byte{11}
+	feeTokenSubspace            SubspaceID = []byte{12}
+	methodACLSubspace           SubspaceID = []byte{13}
+	chainOwnerHistorySubspace   SubspaceID = []byte{14}
+	l1TimingSubspace            SubspaceID = []byte{15}
+	feeCollectorHistorySubspace SubspaceID = []byte{16}
+	sponsorshipSubspace         SubspaceID = []byte{17}
+	l1BlockMapSubspace          SubspaceID = []byte{18}
+	priceHistorySubspace        SubspaceID = []byte{19}
+	stateSweepSubspace          SubspaceID = []byte{20}
+	batchDictionariesSubspace   SubspaceID = []byte{21}
 )
 
+func init() {
+	for name, id := range map[string]SubspaceID{
+		"l1-pricing":            l1PricingSubspace,
+		"l2-pricing":            l2PricingSubspace,
+		"retryables":            retryablesSubspace,
+		"address-table":         addressTableSubspace,
+		"chain-owners":          chainOwnerSubspace,
+		"send-merkle":           sendMerkleSubspace,
+		"blockhashes":           blockhashesSubspace,
+		"chain-config":          chainConfigSubspace,
+		"programs":              programsSubspace,
+		"feature-flags":         featureFlagsSubspace,
+		"scheduled-changes":     scheduledChangesSubspace,
+		"statistics":            statisticsSubspace,
+		"fee-token":             feeTokenSubspace,
+		"method-acl":            methodACLSubspace,
+		"chain-owner-history":   chainOwnerHistorySubspace,
+		"l1-timing":             l1TimingSubspace,
+		"fee-collector-history": feeCollectorHistorySubspace,
+		"sponsorship":           sponsorshipSubspace,
+		"l1-block-map":          l1BlockMapSubspace,
+		"price-history":         priceHistorySubspace,
+		"state-sweep":           stateSweepSubspace,
+		"batch-dictionaries":    batchDictionariesSubspace,
+	} {
+		storage.RegisterSubspaceName(id, name)
+	}
+}
+
 var PrecompileMinArbOSVersions = make(map[common.Address]uint64)
 
 func InitializeArbosState(stateDB vm.StateDB, burner burn.Burner, chainConfig *params.ChainConfig, initMessage *arbostypes.ParsedInitMessage) (*ArbosState, error) {
@@ -190,6 +273,15 @@ func InitializeArbosState(stateDB vm.StateDB, burner burn.Burner, chainConfig *p
 	if desiredArbosVersion == 0 {
 		return nil, errors.New("cannot initialize to ArbOS version 0")
 	}
+	if desiredArbosVersion >= ArbosVersion_CustomPrecompileRanges {
+		ranges, err := CustomPrecompileRangesFromChainConfig(initMessage.SerializedChainConfig)
+		if err != nil {
+			return nil, err
+		}
+		if err := ValidatePrecompileAddressSpace(ranges, desiredArbosVersion); err != nil {
+			return nil, fmt.Errorf("invalid chain config: %w", err)
+		}
+	}
 
 	// Solidity requires call targets have code, but precompiles don't.
 	// To work around this, we give precompiles fake code.
@@ -241,6 +333,12 @@ func InitializeArbosState(stateDB vm.StateDB, burner burn.Burner, chainConfig *p
 			return nil, err
 		}
 	}
+	if desiredArbosVersion >= ArbosVersion_ChainOwnerHistory {
+		// the owners at the end of the genesis block are the initial ones
+		if err := aState.StartChainOwnerHistory(chainConfig.ArbitrumChainParams.GenesisBlockNum + 1); err != nil {
+			return nil, err
+		}
+	}
 	return aState, nil
 }
 
@@ -332,6 +430,13 @@ func (state *ArbosState) UpgradeArbosVersion(
 			ensure(params.UpgradeToVersion(2))
 			ensure(params.Save())
 
+		case 32:
+			// no state changes needed
+
+		case ArbosVersion_FirstCustom:
+			// the state of custom features is set up by their upgrade hooks below, and the code deposit fee
+			// defaults to zero
+
 		default:
 			return fmt.Errorf(
 				"the chain is upgrading to unsupported ArbOS version %v, %w",
@@ -340,6 +445,8 @@ func (state *ArbosState) UpgradeArbosVersion(
 			)
 		}
 
+		ensure(state.runFeatureUpgradeHooks(stateDB, nextArbosVersion, firstTime))
+
 		// install any new precompiles
 		for addr, version := range PrecompileMinArbOSVersions {
 			if version == nextArbosVersion {
@@ -412,6 +519,37 @@ func (state *ArbosState) SetBrotliCompressionLevel(val uint64) error {
 	return errors.New("invalid brotli compression level")
 }
 
+// MaxTxSize returns the size in bytes of the largest user tx the chain accepts, or 0 if only the node's
+// configuration limits it.
+func (state *ArbosState) MaxTxSize() (uint64, error) {
+	return state.maxTxSize.Get()
+}
+
+// MinMaxTxSize is the smallest maximum tx size that can be set, which leaves room for the chain owner's own txs.
+const MinMaxTxSize uint64 = 4096
+
+func (state *ArbosState) SetMaxTxSize(val uint64) error {
+	if val != 0 && val < MinMaxTxSize {
+		return fmt.Errorf("max tx size %v is below the minimum of %v", val, MinMaxTxSize)
+	}
+	return state.maxTxSize.Set(val)
+}
+
+// CheckTxSize returns an error if a user tx is larger than the chain's maximum tx size.
+func (state *ArbosState) CheckTxSize(tx *types.Transaction) error {
+	if state.arbosVersion < ArbosVersion_ChainTxLimits || !util.TxTypeHasPosterCosts(tx.Type()) {
+		return nil
+	}
+	maxTxSize, err := state.MaxTxSize()
+	if err != nil {
+		return err
+	}
+	if size := tx.Size(); maxTxSize != 0 && size > maxTxSize {
+		return fmt.Errorf("%w: tx is %v bytes, the limit is %v", ErrOversizedTx, size, maxTxSize)
+	}
+	return nil
+}
+
 func (state *ArbosState) RetryableState() *retryables.RetryableState {
 	return state.retryableState
 }
@@ -428,6 +566,57 @@ func (state *ArbosState) L1PricingState() *l1pricing.L1PricingState {
 	return state.l1PricingState
 }
 
+func (state *ArbosState) FeeTokenState() *feetoken.FeeTokenState {
+	return state.feeTokenState
+}
+
+// MethodACL holds the grants letting non-owners call specific owner-gated precompile methods
+func (state *ArbosState) MethodACL() *methodacl.MethodACL {
+	return state.methodACL
+}
+
+func (state *ArbosState) ChainOwnerHistory() *ownerhistory.OwnerHistory {
+	return state.chainOwnerHistory
+}
+
+// StartChainOwnerHistory starts the chain owner history at the start of the given block with the current owners
+func (state *ArbosState) StartChainOwnerHistory(blockNumber uint64) error {
+	owners, err := state.chainOwners.AllMembers(65536)
+	if err != nil {
+		return err
+	}
+	return state.chainOwnerHistory.Start(blockNumber, owners)
+}
+
+func (state *ArbosState) L1TimingState() *l1timing.L1TimingState {
+	return state.l1Timing
+}
+
+// FeeCollectorHistory holds the latest changes of the network fee account, infra fee account and L1 reward recipient
+func (state *ArbosState) FeeCollectorHistory() *collectorhistory.CollectorHistory {
+	return state.feeCollectorHistory
+}
+
+// Sponsorship holds the policy under which a paymaster pays the gas of user txs calling allowlisted methods
+func (state *ArbosState) Sponsorship() *sponsorship.Sponsorship {
+	return state.sponsorship
+}
+
+// L1BlockMap holds the first L2 block to start with each L1 block number
+func (state *ArbosState) L1BlockMap() *l1blockmap.L1BlockMap {
+	return state.l1BlockMap
+}
+
+// PriceHistory holds the gas prices each of the most recent blocks started with
+func (state *ArbosState) PriceHistory() *pricehistory.PriceHistory {
+	return state.priceHistory
+}
+
+// BatchDictionaries holds the brotli dictionaries the chain's batches may be compressed with
+func (state *ArbosState) BatchDictionaries() *batchdictionaries.BatchDictionaries {
+	return state.batchDictionaries
+}
+
 func (state *ArbosState) L2PricingState() *l2pricing.L2PricingState {
 	return state.l2PricingState
 }
@@ -471,6 +660,37 @@ func (state *ArbosState) SetInfraFeeAccount(account common.Address) error {
 	return state.infraFeeAccount.Set(account)
 }
 
+// ChangeFeeCollector sets a fee collector to a new account in the given block, recording the change in the fee
+// collector history, and returns the account it replaced.
+func (state *ArbosState) ChangeFeeCollector(blockNumber uint64, collector collectorhistory.Collector, account common.Address) (common.Address, error) {
+	var previous common.Address
+	var err error
+	switch collector {
+	case collectorhistory.NetworkFee:
+		previous, err = state.NetworkFeeAccount()
+		if err == nil {
+			err = state.SetNetworkFeeAccount(account)
+		}
+	case collectorhistory.InfraFee:
+		previous, err = state.InfraFeeAccount()
+		if err == nil {
+			err = state.SetInfraFeeAccount(account)
+		}
+	case collectorhistory.L1Reward:
+		previous, err = state.L1PricingState().PayRewardsTo()
+		if err == nil {
+			err = state.L1PricingState().SetPayRewardsTo(account)
+		}
+	default:
+		return common.Address{}, fmt.Errorf("unknown fee collector %v", collector)
+	}
+	if err != nil {
+		return common.Address{}, err
+	}
+	change := collectorhistory.Change{BlockNumber: blockNumber, Collector: collector, Previous: previous, Account: account}
+	return previous, state.feeCollectorHistory.Record(change)
+}
+
 func (state *ArbosState) Keccak(data ...[]byte) ([]byte, error) {
 	return state.backingStorage.Keccak(data...)
 }
@@ -0,0 +1,108 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbosState
+
+import (
+	"sync"
+
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/metrics"
+
+	"github.com/offchainlabs/nitro/arbos/burn"
+	"github.com/offchainlabs/nitro/arbos/storage"
+)
+
+var (
+	blockCacheHitCounter  = metrics.NewRegisteredCounter("arb/arbos/blockcache/hit", nil)
+	blockCacheMissCounter = metrics.NewRegisteredCounter("arb/arbos/blockcache/miss", nil)
+)
+
+// A block cache memoizes, while a block is produced, the states opened for it by system burners without tracing.
+// Opening a state derives the storage key of every subspace and the slot of every parameter, which the tx processor,
+// precompiles and block processor otherwise redo many times per block. Only that layout is memoized: each open takes
+// a copy and re-reads the ArbOS version, and every other value is still read from storage when used. Values such as
+// pricing parameters and chain owners can't be memoized, even if every write through ArbOS invalidated them, as the
+// EVM reverts writes made by precompiles in calls that fail without ArbOS knowing. Reading a memoized slot is only a
+// lookup in the stateDB's own cache of the account's storage.
+type blockCache struct {
+	mutex  sync.Mutex
+	states [2]*ArbosState // indexed by whether the burner is read only
+}
+
+var blockCaches = struct {
+	mutex  sync.Mutex
+	caches map[vm.StateDB]*blockCache
+}{caches: make(map[vm.StateDB]*blockCache)}
+
+// StartBlockCache memoizes the states opened on the stateDB until the returned function is called. It does nothing
+// if a block cache was already started for the stateDB.
+func StartBlockCache(stateDB vm.StateDB) func() {
+	blockCaches.mutex.Lock()
+	defer blockCaches.mutex.Unlock()
+	if _, ok := blockCaches.caches[stateDB]; ok {
+		return func() {}
+	}
+	blockCaches.caches[stateDB] = &blockCache{}
+	return func() {
+		blockCaches.mutex.Lock()
+		defer blockCaches.mutex.Unlock()
+		delete(blockCaches.caches, stateDB)
+	}
+}
+
+func getBlockCache(stateDB vm.StateDB) *blockCache {
+	blockCaches.mutex.Lock()
+	defer blockCaches.mutex.Unlock()
+	return blockCaches.caches[stateDB]
+}
+
+// cachedStateBurner is the system burner of memoized states. Every state opened from the same memoized one shares
+// its burner, so it keeps no count of gas burned, which only traced states report and those aren't memoized.
+type cachedStateBurner struct {
+	*burn.SystemBurner
+}
+
+func (burner cachedStateBurner) Burn(amount uint64) error {
+	return nil
+}
+
+func (burner cachedStateBurner) Burned() uint64 {
+	return 0
+}
+
+// openCached returns a copy of the memoized state, with the current ArbOS version, opening and memoizing it first if
+// need be. Profiled states aren't memoized, as profiling only applies to states opened after it starts.
+func (c *blockCache) openCached(readOnly bool, open func(burner burn.Burner) (*ArbosState, error)) (*ArbosState, error) {
+	if storage.AccessProfilingEnabled() {
+		return open(burn.NewSystemBurner(nil, readOnly))
+	}
+	index := 0
+	if readOnly {
+		index = 1
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	cached := c.states[index]
+	if cached == nil {
+		blockCacheMissCounter.Inc(1)
+		state, err := open(cachedStateBurner{burn.NewSystemBurner(nil, readOnly)})
+		if err != nil {
+			return nil, err
+		}
+		c.states[index] = state
+		cached = state
+	} else {
+		blockCacheHitCounter.Inc(1)
+	}
+	state := *cached
+	arbosVersion, err := state.backingStorage.GetUint64ByUint64(uint64(versionOffset))
+	if err != nil {
+		return nil, err
+	}
+	if arbosVersion == 0 {
+		return nil, ErrUninitializedArbOS
+	}
+	state.arbosVersion = arbosVersion
+	return &state, nil
+}
@@ -0,0 +1,80 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbosState
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestBlockCache(t *testing.T) {
+	_, statedb := NewArbosMemoryBackedArbOSState()
+	endBlock := StartBlockCache(statedb)
+
+	first, err := OpenSystemArbosState(statedb, nil, false)
+	Require(t, err)
+	second, err := OpenSystemArbosState(statedb, nil, false)
+	Require(t, err)
+	if first == second || first.l2PricingState != second.l2PricingState {
+		Fail(t, "opened states don't share the memoized layout")
+	}
+
+	// values are read from storage, so writes through one state are seen by the others
+	account := common.HexToAddress("0x1234")
+	Require(t, first.SetNetworkFeeAccount(account))
+	read, err := second.NetworkFeeAccount()
+	Require(t, err)
+	if read != account {
+		Fail(t, "didn't read the network fee account written through another state", read)
+	}
+
+	// the states share a burner, which mustn't count gas for any one of them
+	if first.Burner.Burned() != 0 || second.Burner.Burned() != 0 {
+		Fail(t, "memoized states count gas in their shared burner", first.Burner.Burned(), second.Burner.Burned())
+	}
+
+	// the ArbOS version is re-read on every open
+	first.SetFormatVersion(first.ArbOSVersion() + 1)
+	third, err := OpenSystemArbosState(statedb, nil, true)
+	Require(t, err)
+	if third.ArbOSVersion() != first.ArbOSVersion() {
+		Fail(t, "opened a state with a stale ArbOS version", third.ArbOSVersion())
+	}
+	if err := third.SetNetworkFeeAccount(common.Address{}); err == nil {
+		Fail(t, "wrote through a read only state")
+	}
+
+	endBlock()
+	fourth, err := OpenSystemArbosState(statedb, nil, false)
+	Require(t, err)
+	if fourth.l2PricingState == first.l2PricingState {
+		Fail(t, "used the block cache after the block ended")
+	}
+}
+
+func benchmarkOpenSystemArbosState(b *testing.B, cached bool) {
+	_, statedb := NewArbosMemoryBackedArbOSState()
+	if cached {
+		defer StartBlockCache(statedb)()
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		state, err := OpenSystemArbosState(statedb, nil, false)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := state.L2PricingState().BaseFeeWei(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkOpenSystemArbosState(b *testing.B) {
+	benchmarkOpenSystemArbosState(b, false)
+}
+
+func BenchmarkOpenSystemArbosStateBlockCache(b *testing.B) {
+	benchmarkOpenSystemArbosState(b, true)
+}
@@ -0,0 +1,202 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbosState
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+// Feature identifies a behavior introduced at some ArbOS version.
+// Feature ids are consensus-critical: they key chain owner overrides in storage and must never be reused.
+type Feature uint64
+
+const (
+	FeatureCodeDepositFee Feature = iota
+	FeatureCustomPrecompileRanges
+	FeatureSendTxToL1Batch
+	FeatureScheduledParameterChanges
+	FeatureStatistics
+	FeatureStateSweep
+	FeatureReceiptCalldataUnits
+	FeatureFunctionTableDeprecation
+	FeatureReceiptRedeemParent
+	FeatureAggregatorDeprecation
+	FeatureGasSponsorship
+	FeatureRedeemRevertData
+	numFeatures
+)
+
+// FeatureUpgradeHook runs as part of every ArbOS upgrade step to a version at which its feature is active.
+// Hooks run after the version-specific upgrade logic and before new precompiles are installed.
+type FeatureUpgradeHook func(state *ArbosState, stateDB vm.StateDB, arbosVersion uint64, firstTime bool) error
+
+type FeatureSpec struct {
+	Name         string
+	ArbosVersion uint64 // the ArbOS version at which the feature activates
+	// OwnerToggleable features may be disabled by the chain owner after activation.
+	// A feature can never be enabled ahead of its ArbOS version, as its upgrade hook wouldn't have run.
+	OwnerToggleable bool
+	// OptIn features stay disabled after activation until the chain owner enables them.
+	OptIn   bool
+	Upgrade FeatureUpgradeHook
+}
+
+var featureSpecs = [numFeatures]FeatureSpec{
+	FeatureCodeDepositFee: {
+		Name:            "code-deposit-fee",
+		ArbosVersion:    ArbosVersion_CodeDepositFee,
+		OwnerToggleable: true,
+	},
+	// the custom precompile ranges are checked when an upgrade is scheduled or the chain config is set, rather than
+	// in an upgrade hook, which can't fail without halting the chain
+	FeatureCustomPrecompileRanges: {
+		Name:         "custom-precompile-ranges",
+		ArbosVersion: ArbosVersion_CustomPrecompileRanges,
+	},
+	FeatureSendTxToL1Batch: {
+		Name:            "send-tx-to-l1-batch",
+		ArbosVersion:    ArbosVersion_SendTxToL1Batch,
+		OwnerToggleable: true,
+	},
+	FeatureScheduledParameterChanges: {
+		Name:         "scheduled-parameter-changes",
+		ArbosVersion: ArbosVersion_ScheduledChanges,
+		Upgrade: func(state *ArbosState, stateDB vm.StateDB, arbosVersion uint64, firstTime bool) error {
+			return state.ScheduledChanges().initializeDelay()
+		},
+	},
+	FeatureStatistics: {
+		Name:            "statistics",
+		ArbosVersion:    ArbosVersion_Statistics,
+		OwnerToggleable: true,
+		OptIn:           true,
+	},
+	FeatureStateSweep: {
+		Name:            "state-sweep",
+		ArbosVersion:    ArbosVersion_StateSweep,
+		OwnerToggleable: true,
+	},
+	FeatureReceiptCalldataUnits: {
+		Name:            "receipt-calldata-units",
+		ArbosVersion:    ArbosVersion_ReceiptCalldataUnits,
+		OwnerToggleable: true,
+		OptIn:           true,
+	},
+	FeatureFunctionTableDeprecation: {
+		Name:            "function-table-deprecation",
+		ArbosVersion:    ArbosVersion_FunctionTableDeprecation,
+		OwnerToggleable: true,
+		OptIn:           true,
+	},
+	FeatureReceiptRedeemParent: {
+		Name:            "receipt-redeem-parent",
+		ArbosVersion:    ArbosVersion_ReceiptRedeemParent,
+		OwnerToggleable: true,
+		OptIn:           true,
+	},
+	FeatureAggregatorDeprecation: {
+		Name:            "aggregator-deprecation",
+		ArbosVersion:    ArbosVersion_AggregatorDeprecation,
+		OwnerToggleable: true,
+		OptIn:           true,
+	},
+	// gas sponsorship is enabled by the chain config rather than the chain owner
+	FeatureGasSponsorship: {
+		Name:         "gas-sponsorship",
+		ArbosVersion: ArbosVersion_GasSponsorship,
+	},
+	FeatureRedeemRevertData: {
+		Name:            "redeem-revert-data",
+		ArbosVersion:    ArbosVersion_RedeemRevertData,
+		OwnerToggleable: true,
+		OptIn:           true,
+	},
+}
+
+// values of a feature's owner override in storage, where zero leaves the feature at its default
+const (
+	featureOverrideDisabled uint64 = 1
+	featureOverrideEnabled  uint64 = 2
+)
+
+func (f Feature) Spec() (*FeatureSpec, error) {
+	if f >= numFeatures {
+		return nil, fmt.Errorf("unknown ArbOS feature %d", uint64(f))
+	}
+	return &featureSpecs[f], nil
+}
+
+func (f Feature) String() string {
+	spec, err := f.Spec()
+	if err != nil {
+		return fmt.Sprintf("feature(%d)", uint64(f))
+	}
+	return spec.Name
+}
+
+// ArbosVersion returns the ArbOS version at which the feature activates, for gating that can't consult state.
+// It panics on unknown features, so it is meant for static initialization.
+func (f Feature) ArbosVersion() uint64 {
+	spec, err := f.Spec()
+	if err != nil {
+		panic(err)
+	}
+	return spec.ArbosVersion
+}
+
+// FeatureEnabled returns whether the feature is active at the current ArbOS version and, for owner toggleable features,
+// not disabled by the chain owner, or explicitly enabled if the feature is opt in.
+func (state *ArbosState) FeatureEnabled(f Feature) bool {
+	spec, err := f.Spec()
+	if err != nil || state.arbosVersion < spec.ArbosVersion {
+		return false
+	}
+	if !spec.OwnerToggleable {
+		return true
+	}
+	override, err := state.featureFlags.GetUint64ByUint64(uint64(f))
+	state.Restrict(err)
+	switch override {
+	case featureOverrideDisabled:
+		return false
+	case featureOverrideEnabled:
+		return true
+	default:
+		return !spec.OptIn
+	}
+}
+
+// SetFeatureDisabled overrides whether an owner toggleable feature is disabled.
+func (state *ArbosState) SetFeatureDisabled(f Feature, disabled bool) error {
+	spec, err := f.Spec()
+	if err != nil {
+		return err
+	}
+	if !spec.OwnerToggleable {
+		return fmt.Errorf("ArbOS feature %v can't be toggled by the chain owner", spec.Name)
+	}
+	if state.arbosVersion < spec.ArbosVersion {
+		return fmt.Errorf("ArbOS feature %v isn't active until ArbOS version %v", spec.Name, spec.ArbosVersion)
+	}
+	value := featureOverrideEnabled
+	if disabled {
+		value = featureOverrideDisabled
+	}
+	return state.featureFlags.SetUint64ByUint64(uint64(f), value)
+}
+
+func (state *ArbosState) runFeatureUpgradeHooks(stateDB vm.StateDB, arbosVersion uint64, firstTime bool) error {
+	for f := Feature(0); f < numFeatures; f++ {
+		spec := &featureSpecs[f]
+		if spec.Upgrade == nil || arbosVersion < spec.ArbosVersion {
+			continue
+		}
+		if err := spec.Upgrade(state, stateDB, arbosVersion, firstTime); err != nil {
+			return fmt.Errorf("upgrade hook of ArbOS feature %v failed: %w", spec.Name, err)
+		}
+	}
+	return nil
+}
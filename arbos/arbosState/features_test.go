@@ -0,0 +1,77 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbosState
+
+import (
+	"testing"
+)
+
+func TestFeatureEnabled(t *testing.T) {
+	state, _ := NewArbosMemoryBackedArbOSState()
+
+	state.arbosVersion = FeatureSendTxToL1Batch.ArbosVersion() - 1
+	if state.FeatureEnabled(FeatureSendTxToL1Batch) {
+		Fail(t, "feature enabled before its ArbOS version")
+	}
+	if state.SetFeatureDisabled(FeatureSendTxToL1Batch, false) == nil {
+		Fail(t, "toggled a feature before its ArbOS version")
+	}
+
+	state.arbosVersion = FeatureSendTxToL1Batch.ArbosVersion()
+	if !state.FeatureEnabled(FeatureSendTxToL1Batch) {
+		Fail(t, "feature not enabled at its ArbOS version")
+	}
+	Require(t, state.SetFeatureDisabled(FeatureSendTxToL1Batch, true))
+	if state.FeatureEnabled(FeatureSendTxToL1Batch) {
+		Fail(t, "feature enabled after the owner disabled it")
+	}
+	if !state.FeatureEnabled(FeatureCodeDepositFee) {
+		Fail(t, "disabling one feature affected another")
+	}
+	Require(t, state.SetFeatureDisabled(FeatureSendTxToL1Batch, false))
+	if !state.FeatureEnabled(FeatureSendTxToL1Batch) {
+		Fail(t, "feature not re-enabled")
+	}
+
+	if state.SetFeatureDisabled(FeatureCustomPrecompileRanges, true) == nil {
+		Fail(t, "disabled a feature that isn't owner toggleable")
+	}
+	if state.FeatureEnabled(numFeatures) || state.SetFeatureDisabled(numFeatures, true) == nil {
+		Fail(t, "unknown feature accepted")
+	}
+}
+
+func TestFeatureSpecs(t *testing.T) {
+	names := make(map[string]bool)
+	for f := Feature(0); f < numFeatures; f++ {
+		spec, err := f.Spec()
+		Require(t, err)
+		if spec.Name == "" || names[spec.Name] {
+			Fail(t, "feature", uint64(f), "has a missing or duplicate name")
+		}
+		names[spec.Name] = true
+		if spec.ArbosVersion == 0 {
+			Fail(t, "feature", spec.Name, "has no ArbOS version")
+		}
+		if spec.OptIn && !spec.OwnerToggleable {
+			Fail(t, "opt in feature", spec.Name, "can't be enabled by the chain owner")
+		}
+	}
+}
+
+func TestOptInFeature(t *testing.T) {
+	state, _ := NewArbosMemoryBackedArbOSState()
+	state.arbosVersion = FeatureReceiptCalldataUnits.ArbosVersion()
+	if state.FeatureEnabled(FeatureReceiptCalldataUnits) {
+		Fail(t, "opt in feature enabled by default")
+	}
+	Require(t, state.SetFeatureDisabled(FeatureReceiptCalldataUnits, false))
+	if !state.FeatureEnabled(FeatureReceiptCalldataUnits) {
+		Fail(t, "opt in feature not enabled by the chain owner")
+	}
+	Require(t, state.SetFeatureDisabled(FeatureReceiptCalldataUnits, true))
+	if state.FeatureEnabled(FeatureReceiptCalldataUnits) {
+		Fail(t, "opt in feature not disabled by the chain owner")
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbosState
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// GasSponsorshipFromChainConfig reads whether gas sponsorship is enabled under arbitrum.EnableGasSponsorship in a
+// serialized chain config.
+func GasSponsorshipFromChainConfig(serializedChainConfig []byte) (bool, error) {
+	if len(serializedChainConfig) == 0 {
+		return false, nil
+	}
+	var config struct {
+		Arbitrum struct {
+			EnableGasSponsorship bool `json:"EnableGasSponsorship"`
+		} `json:"arbitrum"`
+	}
+	if err := json.Unmarshal(serializedChainConfig, &config); err != nil {
+		return false, fmt.Errorf("failed to deserialize gas sponsorship config: %w", err)
+	}
+	return config.Arbitrum.EnableGasSponsorship, nil
+}
+
+// SponsorshipAdvance returns the paymaster and what it advances the sender to buy a tx's gas, or a nil advance if the
+// tx isn't sponsored. Sponsorship must be active at the ArbOS version and enabled by the chain config. The chain
+// config is only read for txs that otherwise qualify, so other txs don't pay for deserializing it.
+func (state *ArbosState) SponsorshipAdvance(sender common.Address, to *common.Address, data []byte, gasFeeCap *big.Int, gasLimit uint64) (common.Address, *big.Int, error) {
+	if !state.FeatureEnabled(FeatureGasSponsorship) {
+		return common.Address{}, nil, nil
+	}
+	paymaster, advance, err := state.sponsorship.Advance(sender, to, data, gasFeeCap, gasLimit)
+	if err != nil || advance == nil {
+		return common.Address{}, nil, err
+	}
+	serializedChainConfig, err := state.ChainConfig()
+	if err != nil {
+		return common.Address{}, nil, err
+	}
+	enabled, err := GasSponsorshipFromChainConfig(serializedChainConfig)
+	if err != nil || !enabled {
+		return common.Address{}, nil, err
+	}
+	return paymaster, advance, nil
+}
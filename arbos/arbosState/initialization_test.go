@@ -6,6 +6,7 @@ package arbosState
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"math/big"
 	"testing"
 
@@ -64,7 +65,7 @@ func tryMarshalUnmarshal(input *statetransfer.ArbosInitializationInfo, t *testin
 	chainConfig := params.ArbitrumDevTestChainConfig()
 
 	cacheConfig := core.DefaultCacheConfigWithScheme(env.GetTestStateScheme())
-	stateroot, err := InitializeArbosInDatabase(raw, cacheConfig, initReader, chainConfig, arbostypes.TestInitMessage, 0, 0)
+	stateroot, err := InitializeArbosInDatabase(raw, cacheConfig, initReader, chainConfig, arbostypes.TestInitMessage, 0, statetransfer.ImportConfig{})
 	Require(t, err)
 
 	triedbConfig := cacheConfig.TriedbConfig()
@@ -199,3 +200,73 @@ func checkAccounts(db *state.StateDB, arbState *ArbosState, accts []statetransfe
 	}
 	_ = l1p
 }
+
+type interruptedInitDataReader struct {
+	statetransfer.InitDataReader
+	failAfter int
+}
+
+type interruptedAccountReader struct {
+	statetransfer.AccountDataReader
+	left int
+}
+
+func (r *interruptedAccountReader) GetNext() (*statetransfer.AccountInitializationInfo, error) {
+	if r.left == 0 {
+		return nil, errors.New("import interrupted")
+	}
+	r.left--
+	return r.AccountDataReader.GetNext()
+}
+
+func (r *interruptedInitDataReader) GetAccountDataReader() (statetransfer.AccountDataReader, error) {
+	reader, err := r.InitDataReader.GetAccountDataReader()
+	return &interruptedAccountReader{AccountDataReader: reader, left: r.failAfter}, err
+}
+
+func TestResumeInterruptedImport(t *testing.T) {
+	prand := testhelpers.NewPseudoRandomDataSource(t, 2)
+	initData := &statetransfer.ArbosInitializationInfo{
+		AddressTableContents: []common.Address{prand.GetAddress()},
+	}
+	for i := 0; i < 10; i++ {
+		initData.Accounts = append(initData.Accounts, statetransfer.AccountInitializationInfo{
+			Addr:       prand.GetAddress(),
+			Nonce:      prand.GetUint64(),
+			EthBalance: new(big.Int).SetUint64(prand.GetUint64()),
+			ContractInfo: &statetransfer.AccountInitContractInfo{
+				Code:            prand.GetData(64),
+				ContractStorage: pseudorandomHashHashMapForTesting(prand, 8),
+			},
+		})
+	}
+	chainConfig := params.ArbitrumDevTestChainConfig()
+	cacheConfig := core.DefaultCacheConfigWithScheme(env.GetTestStateScheme())
+	importConfig := statetransfer.ImportConfig{AccountsPerSync: 3, Verify: true}
+
+	expectedRoot, err := InitializeArbosInDatabase(rawdb.NewMemoryDatabase(), cacheConfig, statetransfer.NewMemoryInitDataReader(initData), chainConfig, arbostypes.TestInitMessage, 0, importConfig)
+	Require(t, err)
+
+	db := rawdb.NewMemoryDatabase()
+	interrupted := &interruptedInitDataReader{InitDataReader: statetransfer.NewMemoryInitDataReader(initData), failAfter: 7}
+	if _, err := InitializeArbosInDatabase(db, cacheConfig, interrupted, chainConfig, arbostypes.TestInitMessage, 0, importConfig); err == nil {
+		Fail(t, "interrupted import succeeded")
+	}
+	progress, err := statetransfer.ReadImportProgress(db)
+	Require(t, err)
+	if progress == nil || progress.AccountsImported != 6 || len(progress.RangeHashes) != 2 {
+		Fail(t, "unexpected import progress", progress)
+	}
+
+	importConfig.Resume = true
+	root, err := InitializeArbosInDatabase(db, cacheConfig, statetransfer.NewMemoryInitDataReader(initData), chainConfig, arbostypes.TestInitMessage, 0, importConfig)
+	Require(t, err)
+	if root != expectedRoot {
+		Fail(t, "resumed import has root", root, "instead of", expectedRoot)
+	}
+	progress, err = statetransfer.ReadImportProgress(db)
+	Require(t, err)
+	if progress != nil {
+		Fail(t, "import progress wasn't cleared", progress)
+	}
+}
@@ -5,6 +5,7 @@ package arbosState
 
 import (
 	"errors"
+	"fmt"
 	"math/big"
 	"regexp"
 	"sort"
@@ -54,22 +55,47 @@ func MakeGenesisBlock(parentHash common.Hash, blockNumber uint64, timestamp uint
 	return types.NewBlock(head, nil, nil, nil, trie.NewStackTrie(nil))
 }
 
-func InitializeArbosInDatabase(db ethdb.Database, cacheConfig *core.CacheConfig, initData statetransfer.InitDataReader, chainConfig *params.ChainConfig, initMessage *arbostypes.ParsedInitMessage, timestamp uint64, accountsPerSync uint) (root common.Hash, err error) {
+func InitializeArbosInDatabase(db ethdb.Database, cacheConfig *core.CacheConfig, initData statetransfer.InitDataReader, chainConfig *params.ChainConfig, initMessage *arbostypes.ParsedInitMessage, timestamp uint64, importConfig statetransfer.ImportConfig) (root common.Hash, err error) {
 	triedbConfig := cacheConfig.TriedbConfig()
 	triedbConfig.Preimages = false
 	stateDatabase := state.NewDatabaseWithConfig(db, triedbConfig)
 	defer func() {
 		err = errors.Join(err, stateDatabase.TrieDB().Close())
 	}()
-	statedb, err := state.New(common.Hash{}, stateDatabase, nil)
+
+	nextBlockNumber, err := initData.GetNextBlockNumber()
 	if err != nil {
+		return common.Hash{}, err
+	}
+	var progress *statetransfer.ImportProgress
+	if importConfig.Resume {
+		progress, err = statetransfer.ReadImportProgress(db)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		if progress != nil && progress.NextBlockNumber != nextBlockNumber {
+			return common.Hash{}, fmt.Errorf("import checkpoint is for block %d, not %d", progress.NextBlockNumber, nextBlockNumber)
+		}
+	}
+	startRoot := common.Hash{}
+	if progress != nil {
+		startRoot = progress.Root
+		log.Info("resuming import", "accountsImported", progress.AccountsImported, "root", progress.Root)
+	} else {
+		progress = &statetransfer.ImportProgress{NextBlockNumber: nextBlockNumber}
+	}
+	statedb, err := state.New(startRoot, stateDatabase, nil)
+	if err != nil {
+		if startRoot != (common.Hash{}) {
+			return common.Hash{}, fmt.Errorf("failed to open the state of the import checkpoint: %w", err)
+		}
 		log.Crit("failed to init empty statedb", "error", err)
 	}
 
 	noStateTrieChangesToCommitError := regexp.MustCompile("^triedb layer .+ is disk layer$")
 
 	// commit avoids keeping the entire state in memory while importing the state.
-	// At some time it was also used to avoid reprocessing the whole import in case of a crash.
+	// With checkpoints enabled, every commit also records how far the import got so it can be resumed.
 	commit := func() (common.Hash, error) {
 		root, err := statedb.Commit(chainConfig.ArbitrumChainParams.GenesisBlockNum, true)
 		if err != nil {
@@ -87,89 +113,90 @@ func InitializeArbosInDatabase(db ethdb.Database, cacheConfig *core.CacheConfig,
 		if err != nil {
 			return common.Hash{}, err
 		}
+		if importConfig.AccountsPerSync > 0 {
+			progress.Root = root
+			if err := statetransfer.WriteImportProgress(db, progress); err != nil {
+				return common.Hash{}, err
+			}
+		}
 		return root, nil
 	}
 
 	burner := burn.NewSystemBurner(nil, false)
-	arbosState, err := InitializeArbosState(statedb, burner, chainConfig, initMessage)
-	if err != nil {
-		log.Crit("failed to open the ArbOS state", "error", err)
-	}
-
-	addrTable := arbosState.AddressTable()
-	addrTableSize, err := addrTable.Size()
-	if err != nil {
-		return common.Hash{}, err
-	}
-	if addrTableSize != 0 {
-		return common.Hash{}, errors.New("address table must be empty")
-	}
-	addressReader, err := initData.GetAddressTableReader()
-	if err != nil {
-		return common.Hash{}, err
-	}
-	for i := 0; addressReader.More(); i++ {
-		addr, err := addressReader.GetNext()
+	var arbosState *ArbosState
+	if startRoot != (common.Hash{}) {
+		// the address table and retryables were imported before the first checkpoint
+		arbosState, err = OpenArbosState(statedb, burner)
 		if err != nil {
 			return common.Hash{}, err
 		}
-		slot, err := addrTable.Register(*addr)
+	} else {
+		arbosState, err = InitializeArbosState(statedb, burner, chainConfig, initMessage)
 		if err != nil {
-			return common.Hash{}, err
+			log.Crit("failed to open the ArbOS state", "error", err)
 		}
-		if uint64(i) != slot {
-			return common.Hash{}, errors.New("address table slot mismatch")
+		if err := initializeAddressTable(arbosState, initData); err != nil {
+			return common.Hash{}, err
 		}
-	}
-	if err := addressReader.Close(); err != nil {
-		return common.Hash{}, err
-	}
-
-	log.Info("addresss table import complete")
-
-	retryableReader, err := initData.GetRetryableDataReader()
-	if err != nil {
-		return common.Hash{}, err
-	}
-	err = initializeRetryables(statedb, arbosState.RetryableState(), retryableReader, timestamp)
-	if err != nil {
-		return common.Hash{}, err
-	}
+		log.Info("addresss table import complete")
 
-	log.Info("retryables import complete")
-
-	if accountsPerSync > 0 {
-		_, err := commit()
+		retryableReader, err := initData.GetRetryableDataReader()
+		if err != nil {
+			return common.Hash{}, err
+		}
+		err = initializeRetryables(statedb, arbosState.RetryableState(), retryableReader, timestamp)
 		if err != nil {
 			return common.Hash{}, err
 		}
+
+		log.Info("retryables import complete")
+
+		if importConfig.AccountsPerSync > 0 {
+			_, err := commit()
+			if err != nil {
+				return common.Hash{}, err
+			}
+		}
 	}
 
 	accountDataReader, err := initData.GetAccountDataReader()
 	if err != nil {
 		return common.Hash{}, err
 	}
-	accountsRead := uint(0)
+	accountsRead := uint64(0)
+	rangeHash := common.Hash{}
 	for accountDataReader.More() {
 		account, err := accountDataReader.GetNext()
 		if err != nil {
 			return common.Hash{}, err
 		}
+		accountsRead++
+		if accountsRead <= progress.AccountsImported {
+			// imported before the checkpoint being resumed from
+			continue
+		}
 		err = initializeArbosAccount(statedb, arbosState, *account)
 		if err != nil {
 			return common.Hash{}, err
 		}
 		statedb.SetBalance(account.Addr, uint256.MustFromBig(account.EthBalance))
 		statedb.SetNonce(account.Addr, account.Nonce)
+		var code []byte
+		var storage map[common.Hash]common.Hash
 		if account.ContractInfo != nil {
-			statedb.SetCode(account.Addr, account.ContractInfo.Code)
-			for k, v := range account.ContractInfo.ContractStorage {
+			code = account.ContractInfo.Code
+			storage = account.ContractInfo.ContractStorage
+			statedb.SetCode(account.Addr, code)
+			for k, v := range storage {
 				statedb.SetState(account.Addr, k, v)
 			}
 		}
-		accountsRead++
-		if accountsPerSync > 0 && (accountsRead%accountsPerSync == 0) {
+		rangeHash = statetransfer.AccountRangeHash(rangeHash, account.Addr, account.Nonce, account.EthBalance, code, storage)
+		if importConfig.AccountsPerSync > 0 && (accountsRead%uint64(importConfig.AccountsPerSync) == 0) {
 			log.Info("imported accounts", "count", accountsRead)
+			progress.AccountsImported = accountsRead
+			progress.RangeHashes = append(progress.RangeHashes, rangeHash)
+			rangeHash = common.Hash{}
 			_, err := commit()
 			if err != nil {
 				return common.Hash{}, err
@@ -179,7 +206,108 @@ func InitializeArbosInDatabase(db ethdb.Database, cacheConfig *core.CacheConfig,
 	if err := accountDataReader.Close(); err != nil {
 		return common.Hash{}, err
 	}
-	return commit()
+	if accountsRead < progress.AccountsImported {
+		return common.Hash{}, fmt.Errorf("import checkpoint has %d accounts, but only %d were read", progress.AccountsImported, accountsRead)
+	}
+	if accountsRead > progress.AccountsImported {
+		progress.AccountsImported = accountsRead
+		progress.RangeHashes = append(progress.RangeHashes, rangeHash)
+	}
+	root, err = commit()
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if importConfig.Verify {
+		if err := verifyImportedAccounts(statedb, initData, progress, uint64(importConfig.AccountsPerSync)); err != nil {
+			return common.Hash{}, err
+		}
+		log.Info("verified imported accounts", "count", accountsRead, "ranges", len(progress.RangeHashes))
+	}
+	if importConfig.AccountsPerSync > 0 {
+		if err := statetransfer.DeleteImportProgress(db); err != nil {
+			return common.Hash{}, err
+		}
+	}
+	return root, nil
+}
+
+func initializeAddressTable(arbosState *ArbosState, initData statetransfer.InitDataReader) error {
+	addrTable := arbosState.AddressTable()
+	addrTableSize, err := addrTable.Size()
+	if err != nil {
+		return err
+	}
+	if addrTableSize != 0 {
+		return errors.New("address table must be empty")
+	}
+	addressReader, err := initData.GetAddressTableReader()
+	if err != nil {
+		return err
+	}
+	for i := 0; addressReader.More(); i++ {
+		addr, err := addressReader.GetNext()
+		if err != nil {
+			return err
+		}
+		slot, err := addrTable.Register(*addr)
+		if err != nil {
+			return err
+		}
+		if uint64(i) != slot {
+			return errors.New("address table slot mismatch")
+		}
+	}
+	return addressReader.Close()
+}
+
+// verifyImportedAccounts re-reads the imported accounts and checks that the state holds them, range by range.
+func verifyImportedAccounts(statedb *state.StateDB, initData statetransfer.InitDataReader, progress *statetransfer.ImportProgress, accountsPerRange uint64) error {
+	accountDataReader, err := initData.GetAccountDataReader()
+	if err != nil {
+		return err
+	}
+	defer accountDataReader.Close()
+	accountsRead := uint64(0)
+	rangeHash := common.Hash{}
+	rangeIndex := 0
+	checkRange := func() error {
+		if rangeIndex >= len(progress.RangeHashes) || progress.RangeHashes[rangeIndex] != rangeHash {
+			return fmt.Errorf("imported accounts %d to %d don't match the state", arbmath.SaturatingUSub(accountsRead, accountsPerRange)+1, accountsRead)
+		}
+		rangeIndex++
+		rangeHash = common.Hash{}
+		return nil
+	}
+	for accountDataReader.More() {
+		account, err := accountDataReader.GetNext()
+		if err != nil {
+			return err
+		}
+		accountsRead++
+		var storage map[common.Hash]common.Hash
+		if account.ContractInfo != nil {
+			storage = make(map[common.Hash]common.Hash, len(account.ContractInfo.ContractStorage))
+			for key := range account.ContractInfo.ContractStorage {
+				storage[key] = statedb.GetState(account.Addr, key)
+			}
+		}
+		balance := statedb.GetBalance(account.Addr).ToBig()
+		rangeHash = statetransfer.AccountRangeHash(rangeHash, account.Addr, statedb.GetNonce(account.Addr), balance, statedb.GetCode(account.Addr), storage)
+		if accountsPerRange > 0 && accountsRead%accountsPerRange == 0 {
+			if err := checkRange(); err != nil {
+				return err
+			}
+		}
+	}
+	if rangeIndex < len(progress.RangeHashes) {
+		if err := checkRange(); err != nil {
+			return err
+		}
+	}
+	if rangeIndex != len(progress.RangeHashes) || accountsRead != progress.AccountsImported {
+		return fmt.Errorf("imported %d accounts, but %d were read back", progress.AccountsImported, accountsRead)
+	}
+	return nil
 }
 
 func initializeRetryables(statedb *state.StateDB, rs *retryables.RetryableState, initData statetransfer.RetryableDataReader, currentTimestamp uint64) error {
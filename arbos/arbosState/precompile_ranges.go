@@ -0,0 +1,124 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbosState
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// PrecompileAddressRange is an inclusive range of addresses a chain reserves for its own precompiles.
+type PrecompileAddressRange struct {
+	Start common.Address `json:"start"`
+	End   common.Address `json:"end"`
+}
+
+func (r PrecompileAddressRange) Contains(address common.Address) bool {
+	return bytes.Compare(address[:], r.Start[:]) >= 0 && bytes.Compare(address[:], r.End[:]) <= 0
+}
+
+func (r PrecompileAddressRange) String() string {
+	return fmt.Sprintf("[%v, %v]", r.Start, r.End)
+}
+
+// upstreamReservedPrecompileRanges are address spaces used, or likely to be used, by upstream
+// Ethereum and Arbitrum precompiles. Custom ranges may not overlap them.
+var upstreamReservedPrecompileRanges = []PrecompileAddressRange{
+	{Start: common.HexToAddress("0x00"), End: common.HexToAddress("0x01ff")},
+}
+
+// CustomPrecompileAddresses holds the addresses of precompiles added by this chain rather than
+// upstream, which must live inside one of the chain's declared custom precompile ranges.
+var CustomPrecompileAddresses = make(map[common.Address]bool)
+
+// CustomPrecompileRangesFromChainConfig reads the custom precompile ranges declared under
+// arbitrum.CustomPrecompileRanges in a serialized chain config.
+func CustomPrecompileRangesFromChainConfig(serializedChainConfig []byte) ([]PrecompileAddressRange, error) {
+	if len(serializedChainConfig) == 0 {
+		return nil, nil
+	}
+	var config struct {
+		Arbitrum struct {
+			CustomPrecompileRanges []PrecompileAddressRange `json:"CustomPrecompileRanges"`
+		} `json:"arbitrum"`
+	}
+	if err := json.Unmarshal(serializedChainConfig, &config); err != nil {
+		return nil, fmt.Errorf("failed to deserialize custom precompile ranges: %w", err)
+	}
+	return config.Arbitrum.CustomPrecompileRanges, nil
+}
+
+// ValidateCustomPrecompileRanges checks that the ranges are well formed, disjoint, and don't
+// intersect the upstream reserved space or any upstream precompile.
+func ValidateCustomPrecompileRanges(ranges []PrecompileAddressRange) error {
+	sorted := append([]PrecompileAddressRange{}, ranges...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i].Start[:], sorted[j].Start[:]) < 0
+	})
+	for i, r := range sorted {
+		if bytes.Compare(r.Start[:], r.End[:]) > 0 {
+			return fmt.Errorf("custom precompile range %v starts after it ends", r)
+		}
+		if i > 0 && bytes.Compare(r.Start[:], sorted[i-1].End[:]) <= 0 {
+			return fmt.Errorf("custom precompile ranges %v and %v overlap", sorted[i-1], r)
+		}
+		for _, reserved := range upstreamReservedPrecompileRanges {
+			if r.Contains(reserved.Start) || reserved.Contains(r.Start) {
+				return fmt.Errorf("custom precompile range %v overlaps upstream reserved range %v", r, reserved)
+			}
+		}
+		for address := range PrecompileMinArbOSVersions {
+			if !CustomPrecompileAddresses[address] && r.Contains(address) {
+				return fmt.Errorf("custom precompile range %v contains upstream precompile %v", r, address)
+			}
+		}
+	}
+	return nil
+}
+
+// ValidatePrecompileAddressSpace checks that every custom precompile active at arbosVersion lies
+// within one of the declared ranges, and that the ranges themselves are valid.
+// A chain that declares no ranges doesn't confine its custom precompiles, so there's nothing to check.
+func ValidatePrecompileAddressSpace(ranges []PrecompileAddressRange, arbosVersion uint64) error {
+	if len(ranges) == 0 {
+		return nil
+	}
+	if err := ValidateCustomPrecompileRanges(ranges); err != nil {
+		return err
+	}
+	for address := range CustomPrecompileAddresses {
+		if PrecompileMinArbOSVersions[address] > arbosVersion {
+			continue
+		}
+		inRange := false
+		for _, r := range ranges {
+			if r.Contains(address) {
+				inRange = true
+				break
+			}
+		}
+		if !inRange {
+			return fmt.Errorf("custom precompile %v active at ArbOS version %v is outside the chain's custom precompile ranges", address, arbosVersion)
+		}
+	}
+	return nil
+}
+
+// ValidatePrecompileAddressSpace checks the custom precompile ranges declared in the chain config
+// stored in ArbOS state against the precompiles active at arbosVersion.
+func (state *ArbosState) ValidatePrecompileAddressSpace(arbosVersion uint64) error {
+	serializedChainConfig, err := state.ChainConfig()
+	if err != nil {
+		return err
+	}
+	ranges, err := CustomPrecompileRangesFromChainConfig(serializedChainConfig)
+	if err != nil {
+		return err
+	}
+	return ValidatePrecompileAddressSpace(ranges, arbosVersion)
+}
@@ -0,0 +1,65 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbosState
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestCustomPrecompileRangesFromChainConfig(t *testing.T) {
+	ranges, err := CustomPrecompileRangesFromChainConfig(nil)
+	Require(t, err)
+	if len(ranges) != 0 {
+		Fail(t, "expected no ranges for empty chain config", ranges)
+	}
+	serialized := []byte(`{"chainId":412346,"arbitrum":{"EnableArbOS":true,"CustomPrecompileRanges":[{"start":"0x0000000000000000000000000000000000010000","end":"0x00000000000000000000000000000000000100ff"}]}}`)
+	ranges, err = CustomPrecompileRangesFromChainConfig(serialized)
+	Require(t, err)
+	if len(ranges) != 1 || ranges[0].Start != common.HexToAddress("0x10000") || ranges[0].End != common.HexToAddress("0x100ff") {
+		Fail(t, "unexpected ranges", ranges)
+	}
+}
+
+func TestValidatePrecompileAddressSpace(t *testing.T) {
+	upstream := common.HexToAddress("0xa4b05")
+	custom := common.HexToAddress("0x10001")
+	PrecompileMinArbOSVersions[upstream] = 0
+	PrecompileMinArbOSVersions[custom] = 40
+	CustomPrecompileAddresses[custom] = true
+	defer func() {
+		delete(PrecompileMinArbOSVersions, upstream)
+		delete(PrecompileMinArbOSVersions, custom)
+		delete(CustomPrecompileAddresses, custom)
+	}()
+
+	valid := []PrecompileAddressRange{
+		{Start: common.HexToAddress("0x20000"), End: common.HexToAddress("0x200ff")},
+		{Start: common.HexToAddress("0x10000"), End: common.HexToAddress("0x100ff")},
+	}
+	Require(t, ValidatePrecompileAddressSpace(valid, 40))
+
+	invalid := map[string][]PrecompileAddressRange{
+		"reversed": {{Start: common.HexToAddress("0x100ff"), End: common.HexToAddress("0x10000")}},
+		"overlapping": {
+			{Start: common.HexToAddress("0x10000"), End: common.HexToAddress("0x100ff")},
+			{Start: common.HexToAddress("0x10080"), End: common.HexToAddress("0x101ff")},
+		},
+		"upstream reserved":    {{Start: common.HexToAddress("0x100"), End: common.HexToAddress("0x10100")}},
+		"upstream precompile":  {{Start: common.HexToAddress("0xa4b00"), End: common.HexToAddress("0xa4bff")}},
+		"custom out of ranges": {{Start: common.HexToAddress("0x20000"), End: common.HexToAddress("0x200ff")}},
+	}
+	for name, ranges := range invalid {
+		if err := ValidatePrecompileAddressSpace(ranges, 40); err == nil {
+			Fail(t, "expected validation failure for", name)
+		}
+	}
+
+	// the custom precompile isn't active yet, so it needn't be covered by a range
+	Require(t, ValidatePrecompileAddressSpace(invalid["custom out of ranges"], 39))
+
+	// a chain without ranges doesn't confine its custom precompiles
+	Require(t, ValidatePrecompileAddressSpace(nil, 40))
+}
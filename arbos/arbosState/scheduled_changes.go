@@ -0,0 +1,310 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbosState
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/nitro/arbos/collectorhistory"
+	"github.com/offchainlabs/nitro/arbos/feetoken"
+	"github.com/offchainlabs/nitro/arbos/storage"
+	"github.com/offchainlabs/nitro/arbos/util"
+	"github.com/offchainlabs/nitro/util/arbmath"
+)
+
+// ScheduledParameter identifies a chain parameter that the chain owner may change with a time lock.
+// Ids are consensus-critical and must never be reused.
+type ScheduledParameter uint64
+
+const (
+	ScheduledL2MinBaseFee ScheduledParameter = iota
+	ScheduledL2SpeedLimit
+	ScheduledL2MaxTxGasLimit
+	ScheduledL2PricingInertia
+	ScheduledL2BacklogTolerance
+	ScheduledL1PricePerUnit
+	ScheduledL1PricingInertia
+	ScheduledL1RewardRate
+	ScheduledL1PerBatchGasCharge
+	ScheduledL1AmortizedCostCapBips
+	ScheduledNetworkFeeAccount
+	ScheduledInfraFeeAccount
+	ScheduledCodeDepositFeePerByte
+	ScheduledChangeDelay
+	ScheduledFeeToken
+)
+
+// MaxPendingScheduledChanges bounds the work done at the start of every block to find due changes.
+const MaxPendingScheduledChanges = 64
+
+// Changes must be scheduled at least the time lock's delay in the future. The delay starts at
+// DefaultScheduledChangeDelay and can itself only be changed through the time lock, never below
+// MinScheduledChangeDelay.
+const (
+	MinScheduledChangeDelay     uint64 = 60 * 60
+	DefaultScheduledChangeDelay uint64 = 24 * 60 * 60
+)
+
+var (
+	ErrTooManyScheduledChanges = errors.New("too many pending scheduled parameter changes")
+	ErrActivationTooEarly      = errors.New("scheduled parameter change activates before the time lock's delay")
+)
+
+type ScheduledChange struct {
+	Id             uint64
+	Parameter      ScheduledParameter
+	Value          common.Hash
+	ActivationTime uint64
+}
+
+// ScheduledChanges tracks pending time-locked parameter changes.
+// the next change id is stored at position 0, and the time lock's delay at position 1
+// the pending map goes from change id to activation time
+// each change's parameter and value are stored in their own substorages, keyed by change id
+type ScheduledChanges struct {
+	nextId     storage.StorageBackedUint64
+	delay      storage.StorageBackedUint64
+	pending    *storage.StorageBackedMap
+	parameters *storage.Storage
+	values     *storage.Storage
+}
+
+func openScheduledChanges(sto *storage.Storage) *ScheduledChanges {
+	return &ScheduledChanges{
+		nextId:     sto.OpenStorageBackedUint64(0),
+		delay:      sto.OpenStorageBackedUint64(1),
+		pending:    storage.OpenStorageBackedMap(sto.OpenSubStorage([]byte{0})),
+		parameters: sto.OpenSubStorage([]byte{1}),
+		values:     sto.OpenSubStorage([]byte{2}),
+	}
+}
+
+func (state *ArbosState) ScheduledChanges() *ScheduledChanges {
+	return openScheduledChanges(state.backingStorage.OpenCachedSubStorage(scheduledChangesSubspace))
+}
+
+// ValidateScheduledValue checks that value is representable by the parameter's type.
+func ValidateScheduledValue(parameter ScheduledParameter, value common.Hash) error {
+	bigValue := value.Big()
+	switch parameter {
+	case ScheduledL2MinBaseFee:
+		if bigValue.Sign() == 0 {
+			return errors.New("minimum base fee must be nonzero")
+		}
+		return nil
+	case ScheduledL1PricePerUnit, ScheduledCodeDepositFeePerByte:
+		return nil
+	case ScheduledNetworkFeeAccount, ScheduledInfraFeeAccount:
+		if bigValue.BitLen() > 160 {
+			return errors.New("scheduled value isn't an address")
+		}
+		return nil
+	case ScheduledL2SpeedLimit, ScheduledL2MaxTxGasLimit, ScheduledL2PricingInertia, ScheduledL2BacklogTolerance,
+		ScheduledL1PricingInertia, ScheduledL1RewardRate, ScheduledL1PerBatchGasCharge, ScheduledL1AmortizedCostCapBips:
+		if !bigValue.IsUint64() {
+			return errors.New("scheduled value doesn't fit in 64 bits")
+		}
+		return nil
+	case ScheduledFeeToken:
+		decimals, exchangeRate := feetoken.DecodeConfig(value)
+		_, err := feetoken.EncodeConfig(decimals, exchangeRate)
+		return err
+	case ScheduledChangeDelay:
+		if !bigValue.IsUint64() || bigValue.Uint64() < MinScheduledChangeDelay {
+			return fmt.Errorf("time lock delay must be at least %v seconds", MinScheduledChangeDelay)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown scheduled parameter %d", uint64(parameter))
+	}
+}
+
+// Delay returns how far in the future changes must be scheduled, in seconds.
+func (sc *ScheduledChanges) Delay() (uint64, error) {
+	return sc.delay.Get()
+}
+
+// EarliestActivation returns the earliest time a change scheduled at currentTime may activate.
+func (sc *ScheduledChanges) EarliestActivation(currentTime uint64) (uint64, error) {
+	delay, err := sc.delay.Get()
+	if err != nil {
+		return 0, err
+	}
+	return arbmath.SaturatingUAdd(currentTime, arbmath.MaxInt(delay, MinScheduledChangeDelay)), nil
+}
+
+// initializeDelay sets the time lock's delay to its default if it was never set.
+func (sc *ScheduledChanges) initializeDelay() error {
+	delay, err := sc.delay.Get()
+	if err != nil || delay != 0 {
+		return err
+	}
+	return sc.delay.Set(DefaultScheduledChangeDelay)
+}
+
+// Schedule records a change of parameter to value at activationTime, returning the change's id. The change must
+// activate no earlier than the time lock's delay after currentTime.
+func (sc *ScheduledChanges) Schedule(parameter ScheduledParameter, value common.Hash, currentTime uint64, activationTime uint64) (uint64, error) {
+	if err := ValidateScheduledValue(parameter, value); err != nil {
+		return 0, err
+	}
+	earliest, err := sc.EarliestActivation(currentTime)
+	if err != nil {
+		return 0, err
+	}
+	if activationTime < earliest {
+		return 0, fmt.Errorf("%w: the earliest activation time is %v", ErrActivationTooEarly, earliest)
+	}
+	size, err := sc.pending.Size()
+	if err != nil {
+		return 0, err
+	}
+	if size >= MaxPendingScheduledChanges {
+		return 0, ErrTooManyScheduledChanges
+	}
+	id, err := sc.nextId.Increment()
+	if err != nil {
+		return 0, err
+	}
+	key := util.UintToHash(id)
+	if err := sc.parameters.SetUint64(key, uint64(parameter)); err != nil {
+		return 0, err
+	}
+	if err := sc.values.Set(key, value); err != nil {
+		return 0, err
+	}
+	return id, sc.pending.Set(key, util.UintToHash(activationTime))
+}
+
+// Cancel removes a pending change, returning whether it was pending.
+func (sc *ScheduledChanges) Cancel(id uint64) (bool, error) {
+	key := util.UintToHash(id)
+	cancelled, err := sc.pending.Delete(key)
+	if !cancelled || err != nil {
+		return false, err
+	}
+	if err := sc.parameters.Clear(key); err != nil {
+		return false, err
+	}
+	return true, sc.values.Clear(key)
+}
+
+// Pending returns all pending changes, ordered by activation time and then id.
+func (sc *ScheduledChanges) Pending() ([]ScheduledChange, error) {
+	var changes []ScheduledChange
+	err := sc.pending.ForEach(func(_ uint64, key common.Hash, activationTime common.Hash) (bool, error) {
+		parameter, err := sc.parameters.GetUint64(key)
+		if err != nil {
+			return false, err
+		}
+		value, err := sc.values.Get(key)
+		if err != nil {
+			return false, err
+		}
+		changes = append(changes, ScheduledChange{
+			Id:             key.Big().Uint64(),
+			Parameter:      ScheduledParameter(parameter),
+			Value:          value,
+			ActivationTime: activationTime.Big().Uint64(),
+		})
+		return false, nil
+	})
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].ActivationTime != changes[j].ActivationTime {
+			return changes[i].ActivationTime < changes[j].ActivationTime
+		}
+		return changes[i].Id < changes[j].Id
+	})
+	return changes, err
+}
+
+// ApplyDueScheduledChanges applies every pending change whose activation time has passed, in order of
+// activation time, removing each once it's applied and calling onExecuted. Values are validated when changes are
+// scheduled, so a change that can't be applied is an error. It's left pending, where it's retried in later blocks
+// until the chain owner cancels it, and the changes after it are still applied.
+// Changes of fee collectors are recorded in the fee collector history as made in the given block.
+func (state *ArbosState) ApplyDueScheduledChanges(currentTime uint64, blockNumber uint64, onExecuted func(ScheduledChange) error) error {
+	scheduled := state.ScheduledChanges()
+	changes, err := scheduled.Pending()
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, change := range changes {
+		if change.ActivationTime > currentTime {
+			break
+		}
+		if err := state.applyScheduledChange(change, blockNumber); err != nil {
+			errs = append(errs, fmt.Errorf("failed to apply scheduled change %v of parameter %v: %w", change.Id, change.Parameter, err))
+			continue
+		}
+		if _, err := scheduled.Cancel(change.Id); err != nil {
+			return err
+		}
+		if onExecuted != nil {
+			if err := onExecuted(change); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (state *ArbosState) applyScheduledChange(change ScheduledChange, blockNumber uint64) error {
+	if err := ValidateScheduledValue(change.Parameter, change.Value); err != nil {
+		return err
+	}
+	bigValue := change.Value.Big()
+	uintValue := bigValue.Uint64()
+	address := common.BytesToAddress(change.Value.Bytes())
+	l1Pricing := state.L1PricingState()
+	l2Pricing := state.L2PricingState()
+	switch change.Parameter {
+	case ScheduledL2MinBaseFee:
+		return l2Pricing.SetMinBaseFeeWei(bigValue)
+	case ScheduledL2SpeedLimit:
+		return l2Pricing.SetSpeedLimitPerSecond(uintValue)
+	case ScheduledL2MaxTxGasLimit:
+		return l2Pricing.SetMaxPerBlockGasLimit(uintValue)
+	case ScheduledL2PricingInertia:
+		return l2Pricing.SetPricingInertia(uintValue)
+	case ScheduledL2BacklogTolerance:
+		return l2Pricing.SetBacklogTolerance(uintValue)
+	case ScheduledL1PricePerUnit:
+		return l1Pricing.SetPricePerUnit(bigValue)
+	case ScheduledL1PricingInertia:
+		return l1Pricing.SetInertia(uintValue)
+	case ScheduledL1RewardRate:
+		return l1Pricing.SetPerUnitReward(uintValue)
+	case ScheduledL1PerBatchGasCharge:
+		// #nosec G115
+		return l1Pricing.SetPerBatchGasCost(int64(uintValue))
+	case ScheduledL1AmortizedCostCapBips:
+		return l1Pricing.SetAmortizedCostCapBips(uintValue)
+	case ScheduledNetworkFeeAccount:
+		if state.ArbOSVersion() >= ArbosVersion_FeeCollectorHistory {
+			_, err := state.ChangeFeeCollector(blockNumber, collectorhistory.NetworkFee, address)
+			return err
+		}
+		return state.SetNetworkFeeAccount(address)
+	case ScheduledInfraFeeAccount:
+		if state.ArbOSVersion() >= ArbosVersion_FeeCollectorHistory {
+			_, err := state.ChangeFeeCollector(blockNumber, collectorhistory.InfraFee, address)
+			return err
+		}
+		return state.SetInfraFeeAccount(address)
+	case ScheduledCodeDepositFeePerByte:
+		return l2Pricing.SetCodeDepositFeePerByte(bigValue)
+	case ScheduledChangeDelay:
+		return state.ScheduledChanges().delay.Set(uintValue)
+	case ScheduledFeeToken:
+		return state.FeeTokenState().Configure(feetoken.DecodeConfig(change.Value))
+	default:
+		return fmt.Errorf("unknown scheduled parameter %d", uint64(change.Parameter))
+	}
+}
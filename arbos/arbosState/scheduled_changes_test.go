@@ -0,0 +1,188 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbosState
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/nitro/arbos/feetoken"
+	"github.com/offchainlabs/nitro/arbos/util"
+)
+
+func TestScheduledChanges(t *testing.T) {
+	state, _ := NewArbosMemoryBackedArbOSState()
+	scheduled := state.ScheduledChanges()
+
+	// changes are scheduled at time 0, so they may activate from the minimum delay on
+	at := func(offset uint64) uint64 { return MinScheduledChangeDelay + offset }
+
+	feeAccount := common.HexToAddress("0x1234")
+	speedLimit := common.BigToHash(big.NewInt(12345))
+	lateId, err := scheduled.Schedule(ScheduledL2SpeedLimit, speedLimit, 0, at(300))
+	Require(t, err)
+	accountId, err := scheduled.Schedule(ScheduledNetworkFeeAccount, common.BytesToHash(feeAccount.Bytes()), 0, at(100))
+	Require(t, err)
+	cancelledId, err := scheduled.Schedule(ScheduledL2PricingInertia, common.BigToHash(big.NewInt(7)), 0, at(100))
+	Require(t, err)
+	if _, err := scheduled.Schedule(ScheduledL2PricingInertia, common.MaxHash, 0, at(100)); err == nil {
+		Fail(t, "scheduled a value that doesn't fit its parameter")
+	}
+
+	pending, err := scheduled.Pending()
+	Require(t, err)
+	if len(pending) != 3 || pending[0].Id != accountId || pending[1].Id != cancelledId || pending[2].Id != lateId {
+		Fail(t, "pending changes out of order", pending)
+	}
+
+	cancelled, err := scheduled.Cancel(cancelledId)
+	Require(t, err)
+	if !cancelled {
+		Fail(t, "failed to cancel a pending change")
+	}
+	cancelled, err = scheduled.Cancel(cancelledId)
+	Require(t, err)
+	if cancelled {
+		Fail(t, "cancelled a change twice")
+	}
+
+	var executed []uint64
+	onExecuted := func(change ScheduledChange) error {
+		executed = append(executed, change.Id)
+		return nil
+	}
+	Require(t, state.ApplyDueScheduledChanges(at(99), 1, onExecuted))
+	if len(executed) != 0 {
+		Fail(t, "applied a change before its activation time")
+	}
+	Require(t, state.ApplyDueScheduledChanges(at(200), 1, onExecuted))
+	if len(executed) != 1 || executed[0] != accountId {
+		Fail(t, "wrong changes applied", executed)
+	}
+	account, err := state.NetworkFeeAccount()
+	Require(t, err)
+	if account != feeAccount {
+		Fail(t, "network fee account not changed", account)
+	}
+	Require(t, state.ApplyDueScheduledChanges(at(300), 1, onExecuted))
+	limit, err := state.L2PricingState().SpeedLimitPerSecond()
+	Require(t, err)
+	if limit != speedLimit.Big().Uint64() {
+		Fail(t, "speed limit not changed", limit)
+	}
+	pending, err = scheduled.Pending()
+	Require(t, err)
+	if len(pending) != 0 {
+		Fail(t, "applied changes still pending", pending)
+	}
+}
+
+func TestScheduledChangesLimit(t *testing.T) {
+	state, _ := NewArbosMemoryBackedArbOSState()
+	scheduled := state.ScheduledChanges()
+	for i := 0; i < MaxPendingScheduledChanges; i++ {
+		_, err := scheduled.Schedule(ScheduledL1RewardRate, common.Hash{}, 0, MinScheduledChangeDelay)
+		Require(t, err)
+	}
+	if _, err := scheduled.Schedule(ScheduledL1RewardRate, common.Hash{}, 0, MinScheduledChangeDelay); err != ErrTooManyScheduledChanges {
+		Fail(t, "exceeded the pending change limit", err)
+	}
+}
+
+func TestScheduledChangeDelay(t *testing.T) {
+	state, _ := NewArbosMemoryBackedArbOSState()
+	scheduled := state.ScheduledChanges()
+	Require(t, scheduled.initializeDelay())
+	delay, err := scheduled.Delay()
+	Require(t, err)
+	if delay != DefaultScheduledChangeDelay {
+		Fail(t, "wrong initial delay", delay)
+	}
+
+	now := uint64(1000)
+	if _, err := scheduled.Schedule(ScheduledL2SpeedLimit, common.Hash{}, now, now+delay-1); !errors.Is(err, ErrActivationTooEarly) {
+		Fail(t, "scheduled a change inside the time lock", err)
+	}
+	tooShort := common.BigToHash(new(big.Int).SetUint64(MinScheduledChangeDelay - 1))
+	if _, err := scheduled.Schedule(ScheduledChangeDelay, tooShort, now, now+delay); err == nil {
+		Fail(t, "scheduled a delay under the minimum")
+	}
+
+	// the delay is shortened through the time lock, after which changes may be scheduled sooner
+	shorter := 2 * MinScheduledChangeDelay
+	_, err = scheduled.Schedule(ScheduledChangeDelay, common.BigToHash(new(big.Int).SetUint64(shorter)), now, now+delay)
+	Require(t, err)
+	now += delay
+	Require(t, state.ApplyDueScheduledChanges(now, 1, nil))
+	earliest, err := scheduled.EarliestActivation(now)
+	Require(t, err)
+	if earliest != now+shorter {
+		Fail(t, "wrong earliest activation", earliest, now+shorter)
+	}
+	_, err = scheduled.Schedule(ScheduledL2SpeedLimit, common.Hash{}, now, now+shorter)
+	Require(t, err)
+}
+
+func TestScheduledFeeTokenSwitch(t *testing.T) {
+	state, _ := NewArbosMemoryBackedArbOSState()
+	scheduled := state.ScheduledChanges()
+
+	rate := new(big.Int).Mul(big.NewInt(1000), feetoken.ExchangeRateOne)
+	config, err := feetoken.EncodeConfig(6, rate)
+	Require(t, err)
+	_, err = scheduled.Schedule(ScheduledFeeToken, config, 0, MinScheduledChangeDelay)
+	Require(t, err)
+	Require(t, state.ApplyDueScheduledChanges(MinScheduledChangeDelay-1, 1, nil))
+	isFeeTokenChain, err := state.FeeTokenState().IsFeeTokenChain()
+	Require(t, err)
+	if isFeeTokenChain {
+		Fail(t, "switched to the fee token before the change activated")
+	}
+	Require(t, state.ApplyDueScheduledChanges(MinScheduledChangeDelay, 1, nil))
+	decimals, err := state.FeeTokenState().Decimals()
+	Require(t, err)
+	exchangeRate, err := state.FeeTokenState().ExchangeRate()
+	Require(t, err)
+	if decimals != 6 || exchangeRate.Cmp(rate) != 0 {
+		Fail(t, "wrong fee token after the change", decimals, exchangeRate)
+	}
+}
+
+func TestScheduledChangeApplyFailure(t *testing.T) {
+	state, _ := NewArbosMemoryBackedArbOSState()
+	scheduled := state.ScheduledChanges()
+
+	brokenId, err := scheduled.Schedule(ScheduledL2MinBaseFee, common.BigToHash(big.NewInt(1)), 0, MinScheduledChangeDelay)
+	Require(t, err)
+	speedLimit := common.BigToHash(big.NewInt(12345))
+	laterId, err := scheduled.Schedule(ScheduledL2SpeedLimit, speedLimit, 0, MinScheduledChangeDelay)
+	Require(t, err)
+	// a zero minimum base fee can't be scheduled, so it's written directly to make the change fail to apply
+	Require(t, scheduled.values.Set(util.UintToHash(brokenId), common.Hash{}))
+
+	var executed []uint64
+	onExecuted := func(change ScheduledChange) error {
+		executed = append(executed, change.Id)
+		return nil
+	}
+	if err := state.ApplyDueScheduledChanges(MinScheduledChangeDelay, 1, onExecuted); err == nil {
+		Fail(t, "applying a broken change didn't fail")
+	}
+	if len(executed) != 1 || executed[0] != laterId {
+		Fail(t, "the change after the broken one wasn't applied", executed)
+	}
+	limit, err := state.L2PricingState().SpeedLimitPerSecond()
+	Require(t, err)
+	if limit != speedLimit.Big().Uint64() {
+		Fail(t, "speed limit not changed", limit)
+	}
+	pending, err := scheduled.Pending()
+	Require(t, err)
+	if len(pending) != 1 || pending[0].Id != brokenId {
+		Fail(t, "the broken change should stay pending until it's cancelled", pending)
+	}
+}
@@ -0,0 +1,293 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbosState
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/vm"
+
+	"github.com/offchainlabs/nitro/arbos/l1pricing"
+)
+
+// Snapshot is a summary of ArbOS's state at a block: the parameters and balances worth comparing between blocks
+// when debugging what ArbOS did in between.
+type Snapshot struct {
+	ArbOSVersion           uint64             `json:"arbosVersion"`
+	BrotliCompressionLevel uint64             `json:"brotliCompressionLevel"`
+	MaxTxSize              uint64             `json:"maxTxSize"`
+	NetworkFeeAccount      common.Address     `json:"networkFeeAccount"`
+	InfraFeeAccount        common.Address     `json:"infraFeeAccount"`
+	ChainOwners            []common.Address   `json:"chainOwners"`
+	L1Pricing              L1PricingSnapshot  `json:"l1Pricing"`
+	L2Pricing              L2PricingSnapshot  `json:"l2Pricing"`
+	Pools                  PoolsSnapshot      `json:"pools"`
+	Retryables             RetryablesSnapshot `json:"retryables"`
+}
+
+type L1PricingSnapshot struct {
+	PricePerUnit         *big.Int       `json:"pricePerUnit"`
+	UnitsSinceUpdate     uint64         `json:"unitsSinceUpdate"`
+	LastUpdateTime       uint64         `json:"lastUpdateTime"`
+	LastSurplus          *big.Int       `json:"lastSurplus"`
+	EquilibrationUnits   *big.Int       `json:"equilibrationUnits"`
+	Inertia              uint64         `json:"inertia"`
+	PerUnitReward        uint64         `json:"perUnitReward"`
+	PayRewardsTo         common.Address `json:"payRewardsTo"`
+	PerBatchGasCost      int64          `json:"perBatchGasCost"`
+	AmortizedCostCapBips uint64         `json:"amortizedCostCapBips"`
+	CalldataPriceBips    uint64         `json:"calldataPriceBips"`
+	FundsDueForRewards   *big.Int       `json:"fundsDueForRewards"`
+	FundsDueToPosters    *big.Int       `json:"fundsDueToPosters"`
+}
+
+type L2PricingSnapshot struct {
+	BaseFee          *big.Int `json:"baseFee"`
+	MinBaseFee       *big.Int `json:"minBaseFee"`
+	SpeedLimit       uint64   `json:"speedLimit"`
+	PerBlockGasLimit uint64   `json:"perBlockGasLimit"`
+	GasBacklog       uint64   `json:"gasBacklog"`
+	PricingInertia   uint64   `json:"pricingInertia"`
+	BacklogTolerance uint64   `json:"backlogTolerance"`
+}
+
+// PoolsSnapshot holds the balances of the accounts ArbOS collects fees into.
+type PoolsSnapshot struct {
+	L1PricerFunds     *big.Int `json:"l1PricerFunds"`
+	NetworkFeeAccount *big.Int `json:"networkFeeAccount"`
+	InfraFeeAccount   *big.Int `json:"infraFeeAccount"`
+}
+
+type RetryablesSnapshot struct {
+	TimeoutQueueSize uint64 `json:"timeoutQueueSize"`
+	// the statistics counters, which stay zero unless statistics are enabled
+	Created  uint64 `json:"created"`
+	Redeemed uint64 `json:"redeemed"`
+	Expired  uint64 `json:"expired"`
+}
+
+// SnapshotChange is a field of the snapshot that differs between the two blocks, with its values formatted.
+type SnapshotChange struct {
+	Field  string `json:"field"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+type SnapshotDiff struct {
+	Before        *Snapshot        `json:"before"`
+	After         *Snapshot        `json:"after"`
+	Changes       []SnapshotChange `json:"changes"`
+	OwnersAdded   []common.Address `json:"ownersAdded"`
+	OwnersRemoved []common.Address `json:"ownersRemoved"`
+}
+
+// Snapshot reads the summary of the state, whose balances are read from statedb.
+func (state *ArbosState) Snapshot(statedb vm.StateDB) (*Snapshot, error) {
+	var err error
+	snapshot := &Snapshot{ArbOSVersion: state.ArbOSVersion()}
+	if snapshot.BrotliCompressionLevel, err = state.BrotliCompressionLevel(); err != nil {
+		return nil, err
+	}
+	if snapshot.MaxTxSize, err = state.MaxTxSize(); err != nil {
+		return nil, err
+	}
+	if snapshot.NetworkFeeAccount, err = state.NetworkFeeAccount(); err != nil {
+		return nil, err
+	}
+	if snapshot.InfraFeeAccount, err = state.InfraFeeAccount(); err != nil {
+		return nil, err
+	}
+	if snapshot.ChainOwners, err = state.ChainOwners().AllMembers(math.MaxUint64); err != nil {
+		return nil, err
+	}
+
+	l1 := state.L1PricingState()
+	l1Snapshot := &snapshot.L1Pricing
+	if l1Snapshot.PricePerUnit, err = l1.PricePerUnit(); err != nil {
+		return nil, err
+	}
+	if l1Snapshot.UnitsSinceUpdate, err = l1.UnitsSinceUpdate(); err != nil {
+		return nil, err
+	}
+	if l1Snapshot.LastUpdateTime, err = l1.LastUpdateTime(); err != nil {
+		return nil, err
+	}
+	if l1Snapshot.LastSurplus, err = l1.LastSurplus(); err != nil {
+		return nil, err
+	}
+	if l1Snapshot.EquilibrationUnits, err = l1.EquilibrationUnits(); err != nil {
+		return nil, err
+	}
+	if l1Snapshot.Inertia, err = l1.Inertia(); err != nil {
+		return nil, err
+	}
+	if l1Snapshot.PerUnitReward, err = l1.PerUnitReward(); err != nil {
+		return nil, err
+	}
+	if l1Snapshot.PayRewardsTo, err = l1.PayRewardsTo(); err != nil {
+		return nil, err
+	}
+	if l1Snapshot.PerBatchGasCost, err = l1.PerBatchGasCost(); err != nil {
+		return nil, err
+	}
+	if l1Snapshot.AmortizedCostCapBips, err = l1.AmortizedCostCapBips(); err != nil {
+		return nil, err
+	}
+	if l1Snapshot.CalldataPriceBips, err = l1.CalldataPriceBips(); err != nil {
+		return nil, err
+	}
+	if l1Snapshot.FundsDueForRewards, err = l1.FundsDueForRewards(); err != nil {
+		return nil, err
+	}
+	if l1Snapshot.FundsDueToPosters, err = l1.BatchPosterTable().TotalFundsDue(); err != nil {
+		return nil, err
+	}
+
+	l2 := state.L2PricingState()
+	l2Snapshot := &snapshot.L2Pricing
+	if l2Snapshot.BaseFee, err = l2.BaseFeeWei(); err != nil {
+		return nil, err
+	}
+	if l2Snapshot.MinBaseFee, err = l2.MinBaseFeeWei(); err != nil {
+		return nil, err
+	}
+	if l2Snapshot.SpeedLimit, err = l2.SpeedLimitPerSecond(); err != nil {
+		return nil, err
+	}
+	if l2Snapshot.PerBlockGasLimit, err = l2.PerBlockGasLimit(); err != nil {
+		return nil, err
+	}
+	if l2Snapshot.GasBacklog, err = l2.GasBacklog(); err != nil {
+		return nil, err
+	}
+	if l2Snapshot.PricingInertia, err = l2.PricingInertia(); err != nil {
+		return nil, err
+	}
+	if l2Snapshot.BacklogTolerance, err = l2.BacklogTolerance(); err != nil {
+		return nil, err
+	}
+
+	snapshot.Pools = PoolsSnapshot{
+		L1PricerFunds:     statedb.GetBalance(l1pricing.L1PricerFundsPoolAddress).ToBig(),
+		NetworkFeeAccount: statedb.GetBalance(snapshot.NetworkFeeAccount).ToBig(),
+		InfraFeeAccount:   statedb.GetBalance(snapshot.InfraFeeAccount).ToBig(),
+	}
+
+	retryables := &snapshot.Retryables
+	if retryables.TimeoutQueueSize, err = state.RetryableState().TimeoutQueue.Size(); err != nil {
+		return nil, err
+	}
+	if retryables.Created, err = state.Statistic(StatRetryablesCreated); err != nil {
+		return nil, err
+	}
+	if retryables.Redeemed, err = state.Statistic(StatRetryablesRedeemed); err != nil {
+		return nil, err
+	}
+	if retryables.Expired, err = state.Statistic(StatRetryablesExpired); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// SnapshotAt opens ArbOS read-only at a state root and takes its snapshot.
+func SnapshotAt(stateDatabase state.Database, root common.Hash) (*Snapshot, error) {
+	statedb, err := state.New(root, stateDatabase, nil)
+	if err != nil {
+		return nil, fmt.Errorf("opening state %v: %w", root, err)
+	}
+	arbState, err := OpenSystemArbosState(statedb, nil, true)
+	if err != nil {
+		return nil, err
+	}
+	return arbState.Snapshot(statedb)
+}
+
+// DiffStates compares the snapshots of ArbOS at two state roots, typically of two blocks of an archive node.
+func DiffStates(stateDatabase state.Database, beforeRoot common.Hash, afterRoot common.Hash) (*SnapshotDiff, error) {
+	before, err := SnapshotAt(stateDatabase, beforeRoot)
+	if err != nil {
+		return nil, err
+	}
+	after, err := SnapshotAt(stateDatabase, afterRoot)
+	if err != nil {
+		return nil, err
+	}
+	return DiffSnapshots(before, after), nil
+}
+
+func DiffSnapshots(before *Snapshot, after *Snapshot) *SnapshotDiff {
+	diff := &SnapshotDiff{
+		Before:        before,
+		After:         after,
+		Changes:       []SnapshotChange{},
+		OwnersAdded:   []common.Address{},
+		OwnersRemoved: []common.Address{},
+	}
+	compare := func(field string, beforeValue, afterValue interface{}) {
+		formattedBefore, formattedAfter := fmt.Sprint(beforeValue), fmt.Sprint(afterValue)
+		if formattedBefore != formattedAfter {
+			diff.Changes = append(diff.Changes, SnapshotChange{field, formattedBefore, formattedAfter})
+		}
+	}
+	compare("arbosVersion", before.ArbOSVersion, after.ArbOSVersion)
+	compare("brotliCompressionLevel", before.BrotliCompressionLevel, after.BrotliCompressionLevel)
+	compare("maxTxSize", before.MaxTxSize, after.MaxTxSize)
+	compare("networkFeeAccount", before.NetworkFeeAccount, after.NetworkFeeAccount)
+	compare("infraFeeAccount", before.InfraFeeAccount, after.InfraFeeAccount)
+
+	l1Before, l1After := &before.L1Pricing, &after.L1Pricing
+	compare("l1Pricing.pricePerUnit", l1Before.PricePerUnit, l1After.PricePerUnit)
+	compare("l1Pricing.unitsSinceUpdate", l1Before.UnitsSinceUpdate, l1After.UnitsSinceUpdate)
+	compare("l1Pricing.lastUpdateTime", l1Before.LastUpdateTime, l1After.LastUpdateTime)
+	compare("l1Pricing.lastSurplus", l1Before.LastSurplus, l1After.LastSurplus)
+	compare("l1Pricing.equilibrationUnits", l1Before.EquilibrationUnits, l1After.EquilibrationUnits)
+	compare("l1Pricing.inertia", l1Before.Inertia, l1After.Inertia)
+	compare("l1Pricing.perUnitReward", l1Before.PerUnitReward, l1After.PerUnitReward)
+	compare("l1Pricing.payRewardsTo", l1Before.PayRewardsTo, l1After.PayRewardsTo)
+	compare("l1Pricing.perBatchGasCost", l1Before.PerBatchGasCost, l1After.PerBatchGasCost)
+	compare("l1Pricing.amortizedCostCapBips", l1Before.AmortizedCostCapBips, l1After.AmortizedCostCapBips)
+	compare("l1Pricing.calldataPriceBips", l1Before.CalldataPriceBips, l1After.CalldataPriceBips)
+	compare("l1Pricing.fundsDueForRewards", l1Before.FundsDueForRewards, l1After.FundsDueForRewards)
+	compare("l1Pricing.fundsDueToPosters", l1Before.FundsDueToPosters, l1After.FundsDueToPosters)
+
+	l2Before, l2After := &before.L2Pricing, &after.L2Pricing
+	compare("l2Pricing.baseFee", l2Before.BaseFee, l2After.BaseFee)
+	compare("l2Pricing.minBaseFee", l2Before.MinBaseFee, l2After.MinBaseFee)
+	compare("l2Pricing.speedLimit", l2Before.SpeedLimit, l2After.SpeedLimit)
+	compare("l2Pricing.perBlockGasLimit", l2Before.PerBlockGasLimit, l2After.PerBlockGasLimit)
+	compare("l2Pricing.gasBacklog", l2Before.GasBacklog, l2After.GasBacklog)
+	compare("l2Pricing.pricingInertia", l2Before.PricingInertia, l2After.PricingInertia)
+	compare("l2Pricing.backlogTolerance", l2Before.BacklogTolerance, l2After.BacklogTolerance)
+
+	compare("pools.l1PricerFunds", before.Pools.L1PricerFunds, after.Pools.L1PricerFunds)
+	compare("pools.networkFeeAccount", before.Pools.NetworkFeeAccount, after.Pools.NetworkFeeAccount)
+	compare("pools.infraFeeAccount", before.Pools.InfraFeeAccount, after.Pools.InfraFeeAccount)
+
+	compare("retryables.timeoutQueueSize", before.Retryables.TimeoutQueueSize, after.Retryables.TimeoutQueueSize)
+	compare("retryables.created", before.Retryables.Created, after.Retryables.Created)
+	compare("retryables.redeemed", before.Retryables.Redeemed, after.Retryables.Redeemed)
+	compare("retryables.expired", before.Retryables.Expired, after.Retryables.Expired)
+
+	beforeOwners := make(map[common.Address]bool, len(before.ChainOwners))
+	for _, owner := range before.ChainOwners {
+		beforeOwners[owner] = true
+	}
+	afterOwners := make(map[common.Address]bool, len(after.ChainOwners))
+	for _, owner := range after.ChainOwners {
+		afterOwners[owner] = true
+		if !beforeOwners[owner] {
+			diff.OwnersAdded = append(diff.OwnersAdded, owner)
+		}
+	}
+	for _, owner := range before.ChainOwners {
+		if !afterOwners[owner] {
+			diff.OwnersRemoved = append(diff.OwnersRemoved, owner)
+		}
+	}
+	return diff
+}
@@ -0,0 +1,43 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbosState
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/holiman/uint256"
+
+	"github.com/offchainlabs/nitro/arbos/l1pricing"
+)
+
+func TestSnapshotDiff(t *testing.T) {
+	state, statedb := NewArbosMemoryBackedArbOSState()
+	before, err := state.Snapshot(statedb)
+	Require(t, err)
+
+	unchanged := DiffSnapshots(before, before)
+	if len(unchanged.Changes) != 0 || len(unchanged.OwnersAdded) != 0 || len(unchanged.OwnersRemoved) != 0 {
+		Fail(t, "a snapshot differs from itself", unchanged.Changes)
+	}
+
+	newOwner := common.HexToAddress("0x1234")
+	Require(t, state.ChainOwners().Add(newOwner))
+	Require(t, state.L2PricingState().SetSpeedLimitPerSecond(before.L2Pricing.SpeedLimit+1))
+	statedb.AddBalance(l1pricing.L1PricerFundsPoolAddress, uint256.NewInt(100))
+	after, err := state.Snapshot(statedb)
+	Require(t, err)
+
+	diff := DiffSnapshots(before, after)
+	changed := make(map[string]bool)
+	for _, change := range diff.Changes {
+		changed[change.Field] = true
+	}
+	if len(diff.Changes) != 2 || !changed["l2Pricing.speedLimit"] || !changed["pools.l1PricerFunds"] {
+		Fail(t, "wrong changes", diff.Changes)
+	}
+	if len(diff.OwnersAdded) != 1 || diff.OwnersAdded[0] != newOwner || len(diff.OwnersRemoved) != 0 {
+		Fail(t, "wrong owner changes", diff.OwnersAdded, diff.OwnersRemoved)
+	}
+}
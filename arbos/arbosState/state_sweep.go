@@ -0,0 +1,107 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbosState
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+
+	"github.com/offchainlabs/nitro/arbos/retryables"
+	"github.com/offchainlabs/nitro/arbos/storage"
+	"github.com/offchainlabs/nitro/arbos/util"
+	"github.com/offchainlabs/nitro/util/arbmath"
+)
+
+// StateSweepEntriesPerBlock bounds how many retryable timeout queue entries the sweep processes in a block,
+// on top of the two the retryable reaper always processes.
+const StateSweepEntriesPerBlock = 8
+
+const stateSweepCursorOffset uint64 = 0
+
+// SweepDeadState reclaims the storage of retryables that have expired and of timeout queue entries left
+// behind by retryables that were redeemed or cancelled. It first processes the head of the timeout queue
+// like the reaper does, then spends what's left of its budget looking further along the queue, where a
+// kept-alive retryable at the head can hold back retryables that have long expired. Those are deleted
+// once they've timed out with no windows left, which is when they can no longer be redeemed or kept alive.
+// The scan resumes where it left off in the next block, and wraps around at the end of the queue.
+//
+// The outbox keeps no per-message state in ArbOS, whose only outbox storage is the send merkle accumulator's
+// partials, and those are cleared as soon as they're merged, so there's nothing of it to sweep.
+//
+// It's a no-op unless the state sweep is enabled. onExpired is called with the id of each retryable that expires.
+func (state *ArbosState) SweepDeadState(currentTimestamp uint64, evm *vm.EVM, onExpired func(ticketId common.Hash) error) error {
+	if !state.FeatureEnabled(FeatureStateSweep) {
+		return nil
+	}
+	expired := func(ticketId common.Hash) error {
+		if err := state.RecordStatistic(StatRetryablesExpired, 1); err != nil {
+			return err
+		}
+		if err := state.RecordStatistic(StatRetryablesSwept, 1); err != nil {
+			return err
+		}
+		return onExpired(ticketId)
+	}
+
+	retryableState := state.RetryableState()
+	budget := uint64(StateSweepEntriesPerBlock)
+	for idle := false; budget > 0 && !idle; budget-- {
+		outcome, ticketId, err := retryableState.ProcessTimeoutQueueHead(currentTimestamp, evm, util.TracingDuringEVM)
+		if err != nil {
+			return err
+		}
+		switch outcome {
+		case retryables.TimeoutQueueIdle:
+			idle = true
+		case retryables.TimeoutQueueDiscarded:
+			if err := state.RecordStatistic(StatTimeoutQueueEntriesSwept, 1); err != nil {
+				return err
+			}
+		case retryables.TimeoutQueueExpired:
+			if err := expired(ticketId); err != nil {
+				return err
+			}
+		}
+	}
+	if budget == 0 {
+		return nil
+	}
+
+	queue := retryableState.TimeoutQueue
+	head, tail, err := queue.Bounds()
+	if err != nil || tail-head < 2 {
+		// the head, if any, was just processed
+		return err
+	}
+	cursor := state.stateSweepCursor()
+	offset, err := cursor.Get()
+	if err != nil {
+		return err
+	}
+	for scans := arbmath.MinInt(budget, tail-head-1); scans > 0; scans-- {
+		if offset <= head || offset >= tail {
+			offset = head + 1
+		}
+		ticketId, err := queue.GetByOffset(offset)
+		if err != nil {
+			return err
+		}
+		offset++
+		deleted, err := retryableState.ExpireIfDead(ticketId, currentTimestamp, evm, util.TracingDuringEVM)
+		if err != nil {
+			return err
+		}
+		if deleted {
+			if err := expired(ticketId); err != nil {
+				return err
+			}
+		}
+	}
+	return cursor.Set(offset)
+}
+
+// stateSweepCursor is the offset of the next timeout queue entry the sweep looks at past the head.
+func (state *ArbosState) stateSweepCursor() storage.StorageBackedUint64 {
+	return state.backingStorage.OpenCachedSubStorage(stateSweepSubspace).OpenStorageBackedUint64(stateSweepCursorOffset)
+}
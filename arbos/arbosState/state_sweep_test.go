@@ -0,0 +1,139 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbosState
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/offchainlabs/nitro/arbos/util"
+)
+
+func TestSweepDeadState(t *testing.T) {
+	state, statedb := NewArbosMemoryBackedArbOSState()
+	state.arbosVersion = ArbosVersion_StateSweep
+	Require(t, state.SetFeatureDisabled(FeatureStatistics, false))
+	evm := vm.NewEVM(vm.BlockContext{}, vm.TxContext{}, statedb, &params.ChainConfig{}, vm.Config{})
+	retryableState := state.RetryableState()
+
+	const timeout = 1000
+	count := StateSweepEntriesPerBlock + 2
+	for i := 0; i < count; i++ {
+		id := common.BigToHash(big.NewInt(int64(i + 1)))
+		_, err := retryableState.CreateRetryable(id, timeout, common.Address{}, nil, common.Big0, common.Address{}, []byte{1, 2, 3})
+		Require(t, err)
+	}
+	// the first retryable was redeemed, leaving its queue entry behind
+	_, err := retryableState.DeleteRetryable(common.BigToHash(big.NewInt(1)), evm, util.TracingDuringEVM)
+	Require(t, err)
+	// one that hasn't timed out stops the sweep
+	_, err = retryableState.CreateRetryable(common.BigToHash(big.NewInt(int64(count+1))), timeout*2, common.Address{}, nil, common.Big0, common.Address{}, nil)
+	Require(t, err)
+
+	checkStats := func(swept, discarded uint64) {
+		t.Helper()
+		sweptCount, err := state.Statistic(StatRetryablesSwept)
+		Require(t, err)
+		discardedCount, err := state.Statistic(StatTimeoutQueueEntriesSwept)
+		Require(t, err)
+		if sweptCount != swept || discardedCount != discarded {
+			Fail(t, "unexpected sweep statistics", sweptCount, discardedCount, "expected", swept, discarded)
+		}
+	}
+
+	expired := make(map[common.Hash]bool)
+	onExpired := func(ticketId common.Hash) error {
+		expired[ticketId] = true
+		return nil
+	}
+
+	Require(t, state.SweepDeadState(timeout, evm, onExpired))
+	checkStats(0, 0)
+
+	Require(t, state.SweepDeadState(timeout+1, evm, onExpired))
+	checkStats(StateSweepEntriesPerBlock-1, 1)
+	Require(t, state.SweepDeadState(timeout+1, evm, onExpired))
+	checkStats(uint64(count-1), 1)
+	size, err := retryableState.TimeoutQueue.Size()
+	Require(t, err)
+	if size != 1 {
+		Fail(t, "expected only the live retryable to be left in the timeout queue, got", size)
+	}
+	if len(expired) != count-1 || expired[common.BigToHash(big.NewInt(1))] {
+		Fail(t, "expected every retryable but the redeemed one to be reported expired, got", expired)
+	}
+	for i := 1; i <= count; i++ {
+		if retryable, err := retryableState.OpenRetryable(common.BigToHash(big.NewInt(int64(i))), 0); err != nil || retryable != nil {
+			Fail(t, "retryable", i, "wasn't swept", err)
+		}
+	}
+
+	Require(t, state.SetFeatureDisabled(FeatureStateSweep, true))
+	_, err = retryableState.CreateRetryable(common.BigToHash(big.NewInt(int64(count+2))), timeout, common.Address{}, nil, common.Big0, common.Address{}, nil)
+	Require(t, err)
+	Require(t, state.SweepDeadState(timeout*3, evm, onExpired))
+	checkStats(uint64(count-1), 1)
+}
+
+func TestSweepPastLiveHead(t *testing.T) {
+	state, statedb := NewArbosMemoryBackedArbOSState()
+	state.arbosVersion = ArbosVersion_StateSweep
+	Require(t, state.SetFeatureDisabled(FeatureStatistics, false))
+	evm := vm.NewEVM(vm.BlockContext{}, vm.TxContext{}, statedb, &params.ChainConfig{}, vm.Config{})
+	retryableState := state.RetryableState()
+
+	// a retryable with a later timeout, as if it had been kept alive, holds back the ones queued after it
+	const timeout = 1000
+	live := common.BigToHash(big.NewInt(1))
+	_, err := retryableState.CreateRetryable(live, timeout*2, common.Address{}, nil, common.Big0, common.Address{}, nil)
+	Require(t, err)
+	count := StateSweepEntriesPerBlock + 2
+	for i := 0; i < count; i++ {
+		id := common.BigToHash(big.NewInt(int64(i + 2)))
+		_, err := retryableState.CreateRetryable(id, timeout, common.Address{}, nil, common.Big0, common.Address{}, []byte{1, 2, 3})
+		Require(t, err)
+	}
+
+	expired := make(map[common.Hash]bool)
+	onExpired := func(ticketId common.Hash) error {
+		expired[ticketId] = true
+		return nil
+	}
+	for len(expired) < count {
+		before := len(expired)
+		Require(t, state.SweepDeadState(timeout+1, evm, onExpired))
+		if len(expired) == before {
+			Fail(t, "the sweep stopped reclaiming expired retryables after", before)
+		}
+	}
+	if expired[live] {
+		Fail(t, "the live retryable was swept")
+	}
+	if retryable, err := retryableState.OpenRetryable(live, timeout+1); err != nil || retryable == nil {
+		Fail(t, "the live retryable was deleted", err)
+	}
+	for i := 0; i < count; i++ {
+		if retryable, err := retryableState.OpenRetryable(common.BigToHash(big.NewInt(int64(i+2))), 0); err != nil || retryable != nil {
+			Fail(t, "retryable", i+2, "wasn't swept", err)
+		}
+	}
+
+	// their queue entries are discarded once the live retryable's entry is reaped
+	Require(t, state.SweepDeadState(timeout*2+1, evm, onExpired))
+	Require(t, state.SweepDeadState(timeout*2+1, evm, onExpired))
+	size, err := retryableState.TimeoutQueue.Size()
+	Require(t, err)
+	if size != 0 {
+		Fail(t, "expected the timeout queue to be empty, got", size)
+	}
+	discarded, err := state.Statistic(StatTimeoutQueueEntriesSwept)
+	Require(t, err)
+	if discarded != uint64(count) {
+		Fail(t, "unexpected discarded queue entries", discarded, "expected", count)
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbosState
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/nitro/arbos/storage"
+	"github.com/offchainlabs/nitro/arbos/util"
+)
+
+// Statistic identifies a live counter maintained by ArbOS.
+// Ids are consensus-critical as they key the counters in storage, and must never be reused.
+type Statistic uint64
+
+const (
+	StatRetryablesCreated Statistic = iota
+	StatRetryablesRedeemed
+	StatRetryablesExpired
+	StatL2ToL1Sends
+	StatRetryablesSwept
+	StatTimeoutQueueEntriesSwept
+	numStatistics
+)
+
+// StatisticsFormatVersion is bumped whenever counters are added, so readers can tell which are meaningful.
+const StatisticsFormatVersion = 2
+
+var (
+	statisticsCountersKey = []byte{0}
+	precompileCallsKey    = []byte{1}
+)
+
+// RecordStatistic adds count to a counter. It's a no-op unless statistics are enabled.
+func (state *ArbosState) RecordStatistic(stat Statistic, count uint64) error {
+	if stat >= numStatistics {
+		return fmt.Errorf("unknown ArbOS statistic %d", uint64(stat))
+	}
+	if !state.FeatureEnabled(FeatureStatistics) {
+		return nil
+	}
+	counters := state.statisticsCounters()
+	value, err := counters.GetUint64ByUint64(uint64(stat))
+	if err != nil {
+		return err
+	}
+	return counters.SetUint64ByUint64(uint64(stat), value+count)
+}
+
+// RecordPrecompileCall counts a successful state-changing call to a precompile.
+// It's a no-op unless statistics are enabled.
+func (state *ArbosState) RecordPrecompileCall(precompile common.Address) error {
+	if !state.FeatureEnabled(FeatureStatistics) {
+		return nil
+	}
+	calls := state.precompileCalls()
+	key := util.AddressToHash(precompile)
+	value, err := calls.GetUint64(key)
+	if err != nil {
+		return err
+	}
+	return calls.SetUint64(key, value+1)
+}
+
+// Statistic reads a counter, which is zero for events from before statistics were enabled.
+func (state *ArbosState) Statistic(stat Statistic) (uint64, error) {
+	if stat >= numStatistics {
+		return 0, fmt.Errorf("unknown ArbOS statistic %d", uint64(stat))
+	}
+	return state.statisticsCounters().GetUint64ByUint64(uint64(stat))
+}
+
+// PrecompileCalls reads the number of recorded state-changing calls to a precompile.
+func (state *ArbosState) PrecompileCalls(precompile common.Address) (uint64, error) {
+	return state.precompileCalls().GetUint64(util.AddressToHash(precompile))
+}
+
+func (state *ArbosState) statisticsCounters() *storage.Storage {
+	return state.backingStorage.OpenCachedSubStorage(statisticsSubspace).OpenSubStorage(statisticsCountersKey)
+}
+
+func (state *ArbosState) precompileCalls() *storage.Storage {
+	return state.backingStorage.OpenCachedSubStorage(statisticsSubspace).OpenSubStorage(precompileCallsKey)
+}
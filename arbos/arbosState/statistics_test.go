@@ -0,0 +1,56 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbosState
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestStatistics(t *testing.T) {
+	state, _ := NewArbosMemoryBackedArbOSState()
+	precompile := common.HexToAddress("0x64")
+
+	state.arbosVersion = ArbosVersion_Statistics - 1
+	Require(t, state.RecordStatistic(StatRetryablesCreated, 1))
+	Require(t, state.RecordPrecompileCall(precompile))
+
+	// statistics are opt in, so nothing is recorded until the chain owner enables them
+	state.arbosVersion = ArbosVersion_Statistics
+	Require(t, state.RecordStatistic(StatRetryablesCreated, 1))
+	Require(t, state.RecordPrecompileCall(precompile))
+
+	Require(t, state.SetFeatureDisabled(FeatureStatistics, false))
+	Require(t, state.RecordStatistic(StatRetryablesCreated, 1))
+	Require(t, state.RecordStatistic(StatL2ToL1Sends, 3))
+	Require(t, state.RecordStatistic(StatL2ToL1Sends, 2))
+	Require(t, state.RecordPrecompileCall(precompile))
+
+	Require(t, state.SetFeatureDisabled(FeatureStatistics, true))
+	Require(t, state.RecordStatistic(StatRetryablesCreated, 1))
+	Require(t, state.RecordPrecompileCall(precompile))
+
+	expected := map[Statistic]uint64{
+		StatRetryablesCreated:  1,
+		StatRetryablesRedeemed: 0,
+		StatRetryablesExpired:  0,
+		StatL2ToL1Sends:        5,
+	}
+	for stat, count := range expected {
+		value, err := state.Statistic(stat)
+		Require(t, err)
+		if value != count {
+			Fail(t, "wrong count for statistic", stat, value, count)
+		}
+	}
+	calls, err := state.PrecompileCalls(precompile)
+	Require(t, err)
+	if calls != 1 {
+		Fail(t, "wrong precompile call count", calls)
+	}
+	if _, err := state.Statistic(numStatistics); err == nil {
+		Fail(t, "read an unknown statistic")
+	}
+}
@@ -0,0 +1,211 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbosState
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/holiman/uint256"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/offchainlabs/nitro/arbos/burn"
+)
+
+type UpgradeStorageChange struct {
+	Address common.Address `json:"address"`
+	Slot    common.Hash    `json:"slot"`
+	Before  common.Hash    `json:"before"`
+	After   common.Hash    `json:"after"`
+}
+
+type UpgradeCodeChange struct {
+	Address common.Address `json:"address"`
+	Before  hexutil.Bytes  `json:"before"`
+	After   hexutil.Bytes  `json:"after"`
+}
+
+type UpgradeBalanceChange struct {
+	Address common.Address `json:"address"`
+	Before  *hexutil.Big   `json:"before"`
+	After   *hexutil.Big   `json:"after"`
+}
+
+type UpgradeNonceChange struct {
+	Address common.Address `json:"address"`
+	Before  hexutil.Uint64 `json:"before"`
+	After   hexutil.Uint64 `json:"after"`
+}
+
+// UpgradeDryRunReport lists every storage slot, account code, balance and nonce an ArbOS upgrade
+// would change. Values written back to what they were aren't listed.
+type UpgradeDryRunReport struct {
+	FromVersion    uint64                 `json:"fromVersion"`
+	ToVersion      uint64                 `json:"toVersion"`
+	StorageChanges []UpgradeStorageChange `json:"storageChanges"`
+	CodeChanges    []UpgradeCodeChange    `json:"codeChanges"`
+	BalanceChanges []UpgradeBalanceChange `json:"balanceChanges"`
+	NonceChanges   []UpgradeNonceChange   `json:"nonceChanges"`
+}
+
+// upgradeRecordingStateDB remembers the original value of every slot, code, balance and nonce
+// written through it.
+type upgradeRecordingStateDB struct {
+	vm.StateDB
+	storageBefore map[common.Address]map[common.Hash]common.Hash
+	codeBefore    map[common.Address][]byte
+	balanceBefore map[common.Address]*uint256.Int
+	nonceBefore   map[common.Address]uint64
+}
+
+func newUpgradeRecordingStateDB(statedb vm.StateDB) *upgradeRecordingStateDB {
+	return &upgradeRecordingStateDB{
+		StateDB:       statedb,
+		storageBefore: make(map[common.Address]map[common.Hash]common.Hash),
+		codeBefore:    make(map[common.Address][]byte),
+		balanceBefore: make(map[common.Address]*uint256.Int),
+		nonceBefore:   make(map[common.Address]uint64),
+	}
+}
+
+func (db *upgradeRecordingStateDB) SetState(address common.Address, key common.Hash, value common.Hash) {
+	slots, ok := db.storageBefore[address]
+	if !ok {
+		slots = make(map[common.Hash]common.Hash)
+		db.storageBefore[address] = slots
+	}
+	if _, seen := slots[key]; !seen {
+		slots[key] = db.StateDB.GetState(address, key)
+	}
+	db.StateDB.SetState(address, key, value)
+}
+
+func (db *upgradeRecordingStateDB) SetCode(address common.Address, code []byte) {
+	if _, seen := db.codeBefore[address]; !seen {
+		db.codeBefore[address] = common.CopyBytes(db.StateDB.GetCode(address))
+	}
+	db.StateDB.SetCode(address, code)
+}
+
+func (db *upgradeRecordingStateDB) recordBalance(address common.Address) {
+	if _, seen := db.balanceBefore[address]; !seen {
+		db.balanceBefore[address] = new(uint256.Int).Set(db.StateDB.GetBalance(address))
+	}
+}
+
+func (db *upgradeRecordingStateDB) AddBalance(address common.Address, amount *uint256.Int) {
+	db.recordBalance(address)
+	db.StateDB.AddBalance(address, amount)
+}
+
+func (db *upgradeRecordingStateDB) SubBalance(address common.Address, amount *uint256.Int) {
+	db.recordBalance(address)
+	db.StateDB.SubBalance(address, amount)
+}
+
+func (db *upgradeRecordingStateDB) SetNonce(address common.Address, nonce uint64) {
+	if _, seen := db.nonceBefore[address]; !seen {
+		db.nonceBefore[address] = db.StateDB.GetNonce(address)
+	}
+	db.StateDB.SetNonce(address, nonce)
+}
+
+func (db *upgradeRecordingStateDB) report(fromVersion, toVersion uint64) *UpgradeDryRunReport {
+	report := &UpgradeDryRunReport{
+		FromVersion:    fromVersion,
+		ToVersion:      toVersion,
+		StorageChanges: []UpgradeStorageChange{},
+		CodeChanges:    []UpgradeCodeChange{},
+		BalanceChanges: []UpgradeBalanceChange{},
+		NonceChanges:   []UpgradeNonceChange{},
+	}
+	for address, slots := range db.storageBefore {
+		for slot, before := range slots {
+			after := db.StateDB.GetState(address, slot)
+			if after != before {
+				report.StorageChanges = append(report.StorageChanges, UpgradeStorageChange{address, slot, before, after})
+			}
+		}
+	}
+	for address, before := range db.codeBefore {
+		after := db.StateDB.GetCode(address)
+		if !bytes.Equal(after, before) {
+			report.CodeChanges = append(report.CodeChanges, UpgradeCodeChange{address, before, common.CopyBytes(after)})
+		}
+	}
+	for address, before := range db.balanceBefore {
+		after := db.StateDB.GetBalance(address)
+		if !after.Eq(before) {
+			report.BalanceChanges = append(report.BalanceChanges, UpgradeBalanceChange{address, (*hexutil.Big)(before.ToBig()), (*hexutil.Big)(after.ToBig())})
+		}
+	}
+	for address, before := range db.nonceBefore {
+		after := db.StateDB.GetNonce(address)
+		if after != before {
+			report.NonceChanges = append(report.NonceChanges, UpgradeNonceChange{address, hexutil.Uint64(before), hexutil.Uint64(after)})
+		}
+	}
+	sort.Slice(report.StorageChanges, func(i, j int) bool {
+		a, b := report.StorageChanges[i], report.StorageChanges[j]
+		if a.Address != b.Address {
+			return bytes.Compare(a.Address[:], b.Address[:]) < 0
+		}
+		return bytes.Compare(a.Slot[:], b.Slot[:]) < 0
+	})
+	sort.Slice(report.CodeChanges, func(i, j int) bool {
+		return bytes.Compare(report.CodeChanges[i].Address[:], report.CodeChanges[j].Address[:]) < 0
+	})
+	sort.Slice(report.BalanceChanges, func(i, j int) bool {
+		return bytes.Compare(report.BalanceChanges[i].Address[:], report.BalanceChanges[j].Address[:]) < 0
+	})
+	sort.Slice(report.NonceChanges, func(i, j int) bool {
+		return bytes.Compare(report.NonceChanges[i].Address[:], report.NonceChanges[j].Address[:]) < 0
+	})
+	return report
+}
+
+// DryRunArbosUpgrade runs the ArbOS upgrade path from the state's current version to upgradeTo on a
+// copy of statedb, leaving statedb itself untouched, and reports what the upgrade would change.
+// If upgradeTo is zero, the upgrade scheduled through ArbOwner is used.
+func DryRunArbosUpgrade(statedb *state.StateDB, upgradeTo uint64, chainConfig *params.ChainConfig) (report *UpgradeDryRunReport, err error) {
+	db := newUpgradeRecordingStateDB(statedb.Copy())
+	arbos, err := OpenArbosState(db, burn.NewSystemBurner(nil, false))
+	if err != nil {
+		return nil, err
+	}
+	fromVersion := arbos.ArbOSVersion()
+	if upgradeTo == 0 {
+		upgradeTo, _, err = arbos.GetScheduledUpgrade()
+		if err != nil {
+			return nil, err
+		}
+		if upgradeTo == 0 {
+			return nil, fmt.Errorf("no ArbOS upgrade is scheduled on ArbOS version %v", fromVersion)
+		}
+	}
+	if upgradeTo <= fromVersion {
+		return nil, fmt.Errorf("ArbOS version %v is already at or past version %v", fromVersion, upgradeTo)
+	}
+	if upgradeTo > arbos.MaxArbosVersionSupported() {
+		return nil, fmt.Errorf("ArbOS version %v is past the latest version %v this node supports", upgradeTo, arbos.MaxArbosVersionSupported())
+	}
+
+	// upgrade steps panic on storage errors, which shouldn't bring down the caller here
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			report = nil
+			err = fmt.Errorf("ArbOS upgrade from version %v to %v failed: %v", fromVersion, upgradeTo, recovered)
+		}
+	}()
+	if err := arbos.UpgradeArbosVersion(upgradeTo, false, db, chainConfig); err != nil {
+		return nil, err
+	}
+	return db.report(fromVersion, upgradeTo), nil
+}
@@ -0,0 +1,100 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbosState
+
+import (
+	"testing"
+
+	"github.com/holiman/uint256"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/offchainlabs/nitro/arbos/arbostypes"
+	"github.com/offchainlabs/nitro/arbos/burn"
+	"github.com/offchainlabs/nitro/arbos/util"
+)
+
+func TestDryRunArbosUpgrade(t *testing.T) {
+	precompile := common.HexToAddress("0xa4b0f")
+	PrecompileMinArbOSVersions[precompile] = 20
+	defer delete(PrecompileMinArbOSVersions, precompile)
+
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	Require(t, err)
+	chainConfig := *params.ArbitrumDevTestChainConfig()
+	chainConfig.ArbitrumChainParams.InitialArbOSVersion = 11
+	_, err = InitializeArbosState(statedb, burn.NewSystemBurner(nil, false), &chainConfig, arbostypes.TestInitMessage)
+	Require(t, err)
+
+	if _, err := DryRunArbosUpgrade(statedb, 0, &chainConfig); err == nil {
+		Fail(t, "dry run succeeded without a scheduled upgrade")
+	}
+	if _, err := DryRunArbosUpgrade(statedb, 11, &chainConfig); err == nil {
+		Fail(t, "dry run succeeded for the current version")
+	}
+
+	report, err := DryRunArbosUpgrade(statedb, 31, &chainConfig)
+	Require(t, err)
+	if report.FromVersion != 11 || report.ToVersion != 31 {
+		Fail(t, "unexpected versions in report", report.FromVersion, report.ToVersion)
+	}
+	versionChanged := false
+	for _, change := range report.StorageChanges {
+		if change.Before == change.After {
+			Fail(t, "reported an unchanged slot", change)
+		}
+		if change.Address == types.ArbosStateAddress && change.Before == util.UintToHash(11) && change.After == util.UintToHash(31) {
+			versionChanged = true
+		}
+	}
+	if !versionChanged || len(report.StorageChanges) < 2 {
+		Fail(t, "expected the version and upgrade parameters to change, got", report.StorageChanges)
+	}
+	if len(report.CodeChanges) != 1 || report.CodeChanges[0].Address != precompile || len(report.CodeChanges[0].Before) != 0 {
+		Fail(t, "expected the new precompile to be installed, got", report.CodeChanges)
+	}
+	if len(report.BalanceChanges) != 0 || len(report.NonceChanges) != 0 {
+		Fail(t, "unexpected balance or nonce changes", report.BalanceChanges, report.NonceChanges)
+	}
+
+	// the dry run works on a copy
+	if version := ArbOSVersion(statedb); version != 11 {
+		Fail(t, "dry run changed the ArbOS version to", version)
+	}
+	if len(statedb.GetCode(precompile)) != 0 {
+		Fail(t, "dry run installed the precompile")
+	}
+}
+
+func TestUpgradeRecordingStateDBBalancesAndNonces(t *testing.T) {
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	Require(t, err)
+	funded := common.HexToAddress("0x1234")
+	unchanged := common.HexToAddress("0x5678")
+	statedb.AddBalance(funded, uint256.NewInt(100))
+	statedb.AddBalance(unchanged, uint256.NewInt(100))
+	statedb.SetNonce(funded, 3)
+
+	db := newUpgradeRecordingStateDB(statedb)
+	db.AddBalance(funded, uint256.NewInt(50))
+	db.SubBalance(unchanged, uint256.NewInt(10))
+	db.AddBalance(unchanged, uint256.NewInt(10))
+	db.SetNonce(funded, 4)
+	db.SetNonce(funded, 5)
+	db.SetNonce(unchanged, 0)
+
+	report := db.report(1, 2)
+	if len(report.BalanceChanges) != 1 || report.BalanceChanges[0].Address != funded ||
+		report.BalanceChanges[0].Before.ToInt().Uint64() != 100 || report.BalanceChanges[0].After.ToInt().Uint64() != 150 {
+		Fail(t, "unexpected balance changes", report.BalanceChanges)
+	}
+	if len(report.NonceChanges) != 1 || report.NonceChanges[0].Address != funded ||
+		report.NonceChanges[0].Before != 3 || report.NonceChanges[0].After != 5 {
+		Fail(t, "unexpected nonce changes", report.NonceChanges)
+	}
+}
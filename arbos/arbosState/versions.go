@@ -0,0 +1,48 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbosState
+
+import (
+	"github.com/offchainlabs/nitro/arbos/blockhash"
+	"github.com/offchainlabs/nitro/arbos/l1pricing"
+)
+
+// Upstream leaves ArbOS versions 33 through 39 to Orbit chains for custom upgrades. Behavior introduced on top of
+// the upstream ArbOS releases is gated at versions in that range, starting with ArbosVersion_FirstCustom.
+const (
+	ArbosVersion_FirstCustom uint64 = 33
+
+	ArbosVersion_CodeDepositFee           uint64 = ArbosVersion_FirstCustom
+	ArbosVersion_CustomPrecompileRanges   uint64 = ArbosVersion_FirstCustom
+	ArbosVersion_SendTxToL1Batch          uint64 = ArbosVersion_FirstCustom
+	ArbosVersion_FeatureFlags             uint64 = ArbosVersion_FirstCustom
+	ArbosVersion_ScheduledChanges         uint64 = ArbosVersion_FirstCustom
+	ArbosVersion_ChainParameters          uint64 = ArbosVersion_FirstCustom
+	ArbosVersion_RewardSplits             uint64 = ArbosVersion_FirstCustom
+	ArbosVersion_Statistics               uint64 = ArbosVersion_FirstCustom
+	ArbosVersion_DebugIntrospection       uint64 = ArbosVersion_FirstCustom
+	ArbosVersion_StateSweep               uint64 = ArbosVersion_FirstCustom
+	ArbosVersion_PosterSettlement         uint64 = l1pricing.ArbosVersionPosterSettlement
+	ArbosVersion_ReceiptCalldataUnits     uint64 = ArbosVersion_FirstCustom
+	ArbosVersion_FeeToken                 uint64 = ArbosVersion_FirstCustom
+	ArbosVersion_TicketExpiredEvent       uint64 = ArbosVersion_FirstCustom
+	ArbosVersion_MethodACL                uint64 = ArbosVersion_FirstCustom
+	ArbosVersion_BlockGasResources        uint64 = ArbosVersion_FirstCustom
+	ArbosVersion_FunctionTableDeprecation uint64 = ArbosVersion_FirstCustom
+	ArbosVersion_ChainOwnerHistory        uint64 = ArbosVersion_FirstCustom
+	ArbosVersion_ParentChainTiming        uint64 = ArbosVersion_FirstCustom
+	ArbosVersion_BlockhashProvenance      uint64 = blockhash.ArbosVersionProvenance
+	ArbosVersion_FeeCollectorHistory      uint64 = ArbosVersion_FirstCustom
+	ArbosVersion_ReceiptRedeemParent      uint64 = ArbosVersion_FirstCustom
+	ArbosVersion_ChainTxLimits            uint64 = ArbosVersion_FirstCustom
+	ArbosVersion_AggregatorDeprecation    uint64 = ArbosVersion_FirstCustom
+	ArbosVersion_GasSponsorship           uint64 = ArbosVersion_FirstCustom
+	ArbosVersion_TypedPrecompileErrors    uint64 = ArbosVersion_FirstCustom
+	ArbosVersion_L1BlockMap               uint64 = ArbosVersion_FirstCustom
+	ArbosVersion_RedeemRevertData         uint64 = ArbosVersion_FirstCustom
+	ArbosVersion_PriceHistory             uint64 = ArbosVersion_FirstCustom
+	ArbosVersion_WithdrawalHelper         uint64 = ArbosVersion_FirstCustom
+	ArbosVersion_BatchDictionaries        uint64 = ArbosVersion_FirstCustom
+	ArbosVersion_L1BaseFeeSmoothing       uint64 = ArbosVersion_FirstCustom
+)
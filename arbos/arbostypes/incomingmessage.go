@@ -81,6 +81,51 @@ var InvalidL1Message = &L1IncomingMessage{
 	L2msg: []byte{},
 }
 
+// The canonical JSON encoding of an L1IncomingMessage, which is also what the sequencer feed carries, is
+//
+//	{"header":{"kind":<uint8>,"sender":"0x<address>","blockNumber":<uint64>,"timestamp":<uint64>,
+//	"requestId":"0x<hash>"|null,"baseFeeL1":<integer>|null}|null,"l2Msg":"<base64>"|null,"batchGasCost":<uint64>}
+//
+// with the fields in that order, no whitespace, and batchGasCost omitted when unset.
+// Unknown fields are ignored when decoding, so that consumers keep working when fields are added.
+// Decoding a canonical encoding and encoding the result reproduces it byte for byte.
+
+// l1IncomingMessageHeaderJSON and l1IncomingMessageJSON have the canonical field set without the custom
+// (un)marshalers, which would otherwise recurse.
+type l1IncomingMessageHeaderJSON L1IncomingMessageHeader
+
+type l1IncomingMessageJSON struct {
+	Header       *L1IncomingMessageHeader `json:"header"`
+	L2msg        []byte                   `json:"l2Msg"`
+	BatchGasCost *uint64                  `json:"batchGasCost,omitempty"`
+}
+
+func (h L1IncomingMessageHeader) MarshalJSON() ([]byte, error) {
+	return json.Marshal(l1IncomingMessageHeaderJSON(h))
+}
+
+func (h *L1IncomingMessageHeader) UnmarshalJSON(data []byte) error {
+	var decoded l1IncomingMessageHeaderJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*h = L1IncomingMessageHeader(decoded)
+	return nil
+}
+
+func (msg L1IncomingMessage) MarshalJSON() ([]byte, error) {
+	return json.Marshal(l1IncomingMessageJSON(msg))
+}
+
+func (msg *L1IncomingMessage) UnmarshalJSON(data []byte) error {
+	var decoded l1IncomingMessageJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*msg = L1IncomingMessage(decoded)
+	return nil
+}
+
 func (msg *L1IncomingMessage) Serialize() ([]byte, error) {
 	wr := &bytes.Buffer{}
 	if err := wr.WriteByte(msg.Header.Kind); err != nil {
@@ -258,6 +303,23 @@ var TestInitMessage = &ParsedInitMessage{
 	InitialL1BaseFee: DefaultInitialL1BaseFee,
 }
 
+// MakeInitMessage serializes an init message that ParseInitMessage parses back into the given values.
+func MakeInitMessage(chainId *big.Int, initialL1BaseFee *big.Int, serializedChainConfig []byte) *L1IncomingMessage {
+	var l2msg []byte
+	l2msg = append(l2msg, arbmath.U256Bytes(chainId)...)
+	l2msg = append(l2msg, 1)
+	l2msg = append(l2msg, arbmath.U256Bytes(initialL1BaseFee)...)
+	l2msg = append(l2msg, serializedChainConfig...)
+	return &L1IncomingMessage{
+		Header: &L1IncomingMessageHeader{
+			Kind:      L1MessageType_Initialize,
+			RequestId: &common.Hash{},
+			L1BaseFee: common.Big0,
+		},
+		L2msg: l2msg,
+	}
+}
+
 // ParseInitMessage returns the chain id on success
 func (msg *L1IncomingMessage) ParseInitMessage() (*ParsedInitMessage, error) {
 	if msg.Header.Kind != L1MessageType_Initialize {
@@ -0,0 +1,119 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbostypes
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestL1IncomingMessageCanonicalJSON(t *testing.T) {
+	requestId := common.BigToHash(big.NewInt(7))
+	batchGasCost := uint64(1000)
+	msg := &L1IncomingMessage{
+		Header: &L1IncomingMessageHeader{
+			Kind:        L1MessageType_BatchPostingReport,
+			Poster:      common.HexToAddress("0xa4b000000000000000000073657175656e636572"),
+			BlockNumber: 12,
+			Timestamp:   34,
+			RequestId:   &requestId,
+			L1BaseFee:   big.NewInt(56),
+		},
+		L2msg:        []byte{1, 2, 3},
+		BatchGasCost: &batchGasCost,
+	}
+	expected := `{"header":{"kind":13,"sender":"0xa4b000000000000000000073657175656e636572","blockNumber":12,"timestamp":34,` +
+		`"requestId":"0x0000000000000000000000000000000000000000000000000000000000000007","baseFeeL1":56},"l2Msg":"AQID","batchGasCost":1000}`
+	encoded, err := json.Marshal(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(encoded) != expected {
+		t.Fatal("unexpected encoding", string(encoded))
+	}
+
+	var decoded L1IncomingMessage
+	if err := json.Unmarshal([]byte(expected), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.Equals(msg) || decoded.BatchGasCost == nil || *decoded.BatchGasCost != batchGasCost {
+		t.Fatal("decoded message differs", decoded)
+	}
+
+	// a missing header is encoded as null and decoded as nil, as before the encoding was made canonical
+	encoded, err = json.Marshal(&L1IncomingMessage{L2msg: []byte{1, 2, 3}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(encoded) != `{"header":null,"l2Msg":"AQID"}` {
+		t.Fatal("unexpected encoding of a message without a header", string(encoded))
+	}
+	decoded = L1IncomingMessage{}
+	if err := json.Unmarshal([]byte(`{"l2Msg":"AQID"}`), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Header != nil || !bytes.Equal(decoded.L2msg, []byte{1, 2, 3}) {
+		t.Fatal("unexpected decoding of a message without a header", decoded)
+	}
+}
+
+func FuzzL1IncomingMessageJSON(f *testing.F) {
+	f.Fuzz(func(t *testing.T, kind uint8, poster []byte, blockNumber uint64, timestamp uint64, requestId []byte, l1BaseFee []byte, l2Msg []byte, batchGasCost uint64, optionals uint8) {
+		msg := &L1IncomingMessage{
+			Header: &L1IncomingMessageHeader{
+				Kind:        kind,
+				Poster:      common.BytesToAddress(poster),
+				BlockNumber: blockNumber,
+				Timestamp:   timestamp,
+			},
+			L2msg: l2Msg,
+		}
+		if optionals&1 != 0 {
+			id := common.BytesToHash(requestId)
+			msg.Header.RequestId = &id
+		}
+		if optionals&2 != 0 {
+			msg.Header.L1BaseFee = new(big.Int).SetBytes(l1BaseFee)
+			if optionals&4 != 0 {
+				msg.Header.L1BaseFee.Neg(msg.Header.L1BaseFee)
+			}
+		}
+		if optionals&8 != 0 {
+			msg.BatchGasCost = &batchGasCost
+		}
+		encoded, err := json.Marshal(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decoded L1IncomingMessage
+		if err := json.Unmarshal(encoded, &decoded); err != nil {
+			t.Fatal(err)
+		}
+		reencoded, err := json.Marshal(&decoded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(encoded, reencoded) {
+			t.Fatal("encoding isn't canonical", string(encoded), string(reencoded))
+		}
+		if (decoded.Header.L1BaseFee == nil) != (msg.Header.L1BaseFee == nil) || (decoded.L2msg == nil) != (msg.L2msg == nil) {
+			t.Fatal("decoded message differs in unset fields", msg, decoded)
+		}
+		if msg.Header.L1BaseFee == nil {
+			// Equals doesn't handle an unset base fee
+			msg.Header.L1BaseFee = common.Big0
+			decoded.Header.L1BaseFee = common.Big0
+		}
+		if !decoded.Equals(msg) {
+			t.Fatal("decoded message differs", msg, decoded)
+		}
+		if (decoded.BatchGasCost == nil) != (msg.BatchGasCost == nil) || (msg.BatchGasCost != nil && *decoded.BatchGasCost != *msg.BatchGasCost) {
+			t.Fatal("decoded batch gas cost differs", msg.BatchGasCost, decoded.BatchGasCost)
+		}
+	})
+}
@@ -0,0 +1,83 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+// Package batchdictionaries holds the brotli dictionaries the chain owner committed the chain to. Batches may only
+// be compressed with a committed dictionary, so every node and every proof knows which dictionaries it needs.
+package batchdictionaries
+
+import (
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/nitro/arbos/storage"
+)
+
+const countOffset uint64 = 0
+
+var (
+	committedKey = []byte{0}
+	listKey      = []byte{1}
+)
+
+var ErrEmptyHash = errors.New("a batch dictionary can't have the zero hash")
+
+// BatchDictionaries is the set of committed dictionaries, by the keccak256 hash of their contents. Commitments
+// are permanent, since batches already compressed with a dictionary must stay decodable.
+type BatchDictionaries struct {
+	committed *storage.Storage // hash => 1 if committed
+	list      *storage.Storage // commitment index => hash
+	count     storage.StorageBackedUint64
+}
+
+func Open(sto *storage.Storage) *BatchDictionaries {
+	return &BatchDictionaries{
+		committed: sto.OpenCachedSubStorage(committedKey),
+		list:      sto.OpenCachedSubStorage(listKey),
+		count:     sto.OpenStorageBackedUint64(countOffset),
+	}
+}
+
+func (d *BatchDictionaries) IsCommitted(hash common.Hash) (bool, error) {
+	value, err := d.committed.Get(hash)
+	return value != (common.Hash{}), err
+}
+
+// Commit commits the chain to a dictionary, and is a no-op if it already is.
+func (d *BatchDictionaries) Commit(hash common.Hash) error {
+	if hash == (common.Hash{}) {
+		return ErrEmptyHash
+	}
+	committed, err := d.IsCommitted(hash)
+	if err != nil || committed {
+		return err
+	}
+	if err := d.committed.Set(hash, common.BigToHash(common.Big1)); err != nil {
+		return err
+	}
+	count, err := d.count.Get()
+	if err != nil {
+		return err
+	}
+	if err := d.list.SetByUint64(count, hash); err != nil {
+		return err
+	}
+	return d.count.Set(count + 1)
+}
+
+// All returns the committed dictionaries in the order they were committed.
+func (d *BatchDictionaries) All() ([]common.Hash, error) {
+	count, err := d.count.Get()
+	if err != nil {
+		return nil, err
+	}
+	hashes := make([]common.Hash, 0, count)
+	for i := uint64(0); i < count; i++ {
+		hash, err := d.list.GetByUint64(i)
+		if err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, hash)
+	}
+	return hashes, nil
+}
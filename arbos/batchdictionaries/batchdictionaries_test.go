@@ -0,0 +1,45 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package batchdictionaries
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/nitro/arbos/burn"
+	"github.com/offchainlabs/nitro/arbos/storage"
+)
+
+func TestBatchDictionaries(t *testing.T) {
+	dictionaries := Open(storage.NewMemoryBacked(burn.NewSystemBurner(nil, false)))
+	first := common.HexToHash("0x1111")
+	second := common.HexToHash("0x2222")
+
+	if err := dictionaries.Commit(common.Hash{}); !errors.Is(err, ErrEmptyHash) {
+		t.Errorf("committing the zero hash gave %v", err)
+	}
+	for _, hash := range []common.Hash{first, second, first} {
+		if err := dictionaries.Commit(hash); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for hash, expected := range map[common.Hash]bool{first: true, second: true, common.HexToHash("0x3333"): false} {
+		committed, err := dictionaries.IsCommitted(hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if committed != expected {
+			t.Errorf("dictionary %v committed is %v", hash, committed)
+		}
+	}
+	all, err := dictionaries.All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 2 || all[0] != first || all[1] != second {
+		t.Errorf("committed dictionaries are %v", all)
+	}
+}
@@ -0,0 +1,294 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+// Package bench replays a corpus of L1 incoming messages through ArbOS block production in isolation,
+// timing every message, attributing the ArbOS storage gas it burns to subsystems, and checking that
+// every run produces the same blocks.
+package bench
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/offchainlabs/nitro/arbos"
+	"github.com/offchainlabs/nitro/arbos/arbosState"
+	"github.com/offchainlabs/nitro/arbos/arbostypes"
+	"github.com/offchainlabs/nitro/arbos/burn"
+	"github.com/offchainlabs/nitro/arbos/storage"
+	"github.com/offchainlabs/nitro/util/arbmath"
+)
+
+type Config struct {
+	Corpus         string `koanf:"corpus"`
+	Runs           uint64 `koanf:"runs"`
+	Output         string `koanf:"output"`
+	ProfileStorage bool   `koanf:"profile-storage"`
+}
+
+var DefaultConfig = Config{
+	Runs:           3,
+	ProfileStorage: true,
+}
+
+func ConfigAddOptions(f *flag.FlagSet) {
+	f.String("corpus", DefaultConfig.Corpus, "file of messages to replay, one JSON encoded message with metadata per line; an init message first sets up the chain, otherwise the dev test chain is used")
+	f.Uint64("runs", DefaultConfig.Runs, "number of times the corpus is replayed from genesis")
+	f.String("output", DefaultConfig.Output, "file the JSON report is written to (stdout if empty)")
+	f.Bool("profile-storage", DefaultConfig.ProfileStorage, "attribute the ArbOS storage gas burned by each message to subsystems, which slightly slows block production")
+}
+
+func (c *Config) Validate() error {
+	if c.Corpus == "" {
+		return errors.New("corpus must be set")
+	}
+	if c.Runs == 0 {
+		return errors.New("runs must be at least 1")
+	}
+	return nil
+}
+
+// MessageReport summarizes the block production of one message across all runs.
+type MessageReport struct {
+	Index     uint64                                 `json:"index"`
+	Kind      uint8                                  `json:"kind"`
+	Txs       int                                    `json:"txs"`
+	GasUsed   uint64                                 `json:"gasUsed"`
+	BlockHash common.Hash                            `json:"blockHash"`
+	StateRoot common.Hash                            `json:"stateRoot"`
+	MinTime   time.Duration                          `json:"minTimeNs"`
+	MeanTime  time.Duration                          `json:"meanTimeNs"`
+	MaxTime   time.Duration                          `json:"maxTimeNs"`
+	Storage   map[string]storage.SubspaceAccessStats `json:"storage,omitempty"` // from the first run
+}
+
+// Divergence is a block that differs from the one the first run produced for the same message.
+type Divergence struct {
+	Run               uint64      `json:"run"`
+	Index             uint64      `json:"index"`
+	ExpectedBlockHash common.Hash `json:"expectedBlockHash"`
+	BlockHash         common.Hash `json:"blockHash"`
+	ExpectedStateRoot common.Hash `json:"expectedStateRoot"`
+	StateRoot         common.Hash `json:"stateRoot"`
+}
+
+type Report struct {
+	Runs          uint64          `json:"runs"`
+	Messages      []MessageReport `json:"messages"`
+	RunTimes      []time.Duration `json:"runTimesNs"`
+	Deterministic bool            `json:"deterministic"`
+	Divergences   []Divergence    `json:"divergences,omitempty"`
+}
+
+// ReadCorpus reads one arbostypes.MessageWithMetadata per line, skipping blank lines.
+func ReadCorpus(reader io.Reader) ([]arbostypes.MessageWithMetadata, error) {
+	var corpus []arbostypes.MessageWithMetadata
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(nil, 1<<27)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var message arbostypes.MessageWithMetadata
+		if err := json.Unmarshal(scanner.Bytes(), &message); err != nil {
+			return nil, fmt.Errorf("corpus line %d: %w", line, err)
+		}
+		if message.Message == nil {
+			return nil, fmt.Errorf("corpus line %d: missing message", line)
+		}
+		corpus = append(corpus, message)
+	}
+	return corpus, scanner.Err()
+}
+
+type benchChainContext struct {
+	headers map[common.Hash]*types.Header
+}
+
+func (c *benchChainContext) Engine() consensus.Engine {
+	return arbos.Engine{}
+}
+
+func (c *benchChainContext) GetHeader(hash common.Hash, number uint64) *types.Header {
+	header := c.headers[hash]
+	if header == nil || header.Number.Uint64() != number {
+		return nil
+	}
+	return header
+}
+
+type blockResult struct {
+	hash    common.Hash
+	root    common.Hash
+	txs     int
+	gasUsed uint64
+	elapsed time.Duration
+	storage map[string]storage.SubspaceAccessStats
+}
+
+// genesis initializes ArbOS from the corpus's init message, if it starts with one, and returns the messages left to replay.
+func genesis(corpus []arbostypes.MessageWithMetadata) (*params.ChainConfig, *arbostypes.ParsedInitMessage, []arbostypes.MessageWithMetadata, error) {
+	if len(corpus) > 0 && corpus[0].Message.Header.Kind == arbostypes.L1MessageType_Initialize {
+		initMessage, err := corpus[0].Message.ParseInitMessage()
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if initMessage.ChainConfig == nil {
+			return nil, nil, nil, errors.New("corpus init message has no chain config")
+		}
+		return initMessage.ChainConfig, initMessage, corpus[1:], nil
+	}
+	return params.ArbitrumDevTestChainConfig(), arbostypes.TestInitMessage, corpus, nil
+}
+
+func runOnce(corpus []arbostypes.MessageWithMetadata, chainConfig *params.ChainConfig, initMessage *arbostypes.ParsedInitMessage, profileStorage bool) ([]blockResult, error) {
+	db := state.NewDatabase(rawdb.NewMemoryDatabase())
+	statedb, err := state.New(common.Hash{}, db, nil)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := arbosState.InitializeArbosState(statedb, burn.NewSystemBurner(nil, false), chainConfig, initMessage); err != nil {
+		return nil, fmt.Errorf("failed to initialize ArbOS: %w", err)
+	}
+	genesisNum := chainConfig.ArbitrumChainParams.GenesisBlockNum
+	root, err := statedb.Commit(genesisNum, true)
+	if err != nil {
+		return nil, err
+	}
+	lastHeader := arbosState.MakeGenesisBlock(common.Hash{}, genesisNum, 0, root, chainConfig).Header()
+	chainContext := &benchChainContext{headers: map[common.Hash]*types.Header{lastHeader.Hash(): lastHeader}}
+
+	results := make([]blockResult, 0, len(corpus))
+	for i, message := range corpus {
+		statedb, err = state.New(lastHeader.Root, db, nil)
+		if err != nil {
+			return nil, err
+		}
+		if profileStorage {
+			storage.StartAccessProfiling()
+		}
+		start := time.Now()
+		block, _, err := arbos.ProduceBlock(message.Message, message.DelayedMessagesRead, lastHeader, statedb, chainContext, chainConfig, false)
+		elapsed := time.Since(start)
+		var profile map[string]storage.SubspaceAccessStats
+		if profileStorage {
+			profile = storage.StopAccessProfiling()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to produce block for message %d: %w", i, err)
+		}
+		if _, err := statedb.Commit(block.NumberU64(), true); err != nil {
+			return nil, err
+		}
+		lastHeader = block.Header()
+		chainContext.headers[block.Hash()] = lastHeader
+		results = append(results, blockResult{
+			hash:    block.Hash(),
+			root:    block.Root(),
+			txs:     len(block.Transactions()),
+			gasUsed: block.GasUsed(),
+			elapsed: elapsed,
+			storage: profile,
+		})
+	}
+	return results, nil
+}
+
+// Run replays the corpus config.Runs times, each from a fresh genesis state.
+func Run(config *Config, corpus []arbostypes.MessageWithMetadata) (*Report, error) {
+	chainConfig, initMessage, messages, err := genesis(corpus)
+	if err != nil {
+		return nil, err
+	}
+	report := &Report{
+		Runs:          config.Runs,
+		Messages:      make([]MessageReport, len(messages)),
+		Deterministic: true,
+	}
+	totalTimes := make([]time.Duration, len(messages))
+	for run := uint64(0); run < config.Runs; run++ {
+		start := time.Now()
+		results, err := runOnce(messages, chainConfig, initMessage, config.ProfileStorage)
+		if err != nil {
+			return nil, fmt.Errorf("run %d: %w", run, err)
+		}
+		report.RunTimes = append(report.RunTimes, time.Since(start))
+		for i, result := range results {
+			entry := &report.Messages[i]
+			if run == 0 {
+				*entry = MessageReport{
+					Index:     uint64(i),
+					Kind:      messages[i].Message.Header.Kind,
+					Txs:       result.txs,
+					GasUsed:   result.gasUsed,
+					BlockHash: result.hash,
+					StateRoot: result.root,
+					MinTime:   result.elapsed,
+					MaxTime:   result.elapsed,
+					Storage:   result.storage,
+				}
+			} else if result.hash != entry.BlockHash || result.root != entry.StateRoot {
+				report.Deterministic = false
+				report.Divergences = append(report.Divergences, Divergence{
+					Run:               run,
+					Index:             uint64(i),
+					ExpectedBlockHash: entry.BlockHash,
+					BlockHash:         result.hash,
+					ExpectedStateRoot: entry.StateRoot,
+					StateRoot:         result.root,
+				})
+			}
+			entry.MinTime = arbmath.MinInt(entry.MinTime, result.elapsed)
+			entry.MaxTime = arbmath.MaxInt(entry.MaxTime, result.elapsed)
+			totalTimes[i] += result.elapsed
+		}
+	}
+	for i := range report.Messages {
+		report.Messages[i].MeanTime = totalTimes[i] / time.Duration(config.Runs)
+	}
+	return report, nil
+}
+
+// Slowest returns the indices of the count messages with the highest mean time, slowest first.
+func (r *Report) Slowest(count int) []uint64 {
+	indices := make([]uint64, len(r.Messages))
+	for i := range indices {
+		indices[i] = uint64(i)
+	}
+	sort.SliceStable(indices, func(i, j int) bool {
+		return r.Messages[indices[i]].MeanTime > r.Messages[indices[j]].MeanTime
+	})
+	if count < len(indices) {
+		indices = indices[:count]
+	}
+	return indices
+}
+
+// WriteReport writes the report as JSON to path, or to stdout if path is empty.
+func WriteReport(path string, report *Report) error {
+	encoded, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	encoded = append(encoded, '\n')
+	if path == "" {
+		_, err = os.Stdout.Write(encoded)
+		return err
+	}
+	// #nosec G306
+	return os.WriteFile(path, encoded, 0o644)
+}
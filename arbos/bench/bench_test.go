@@ -0,0 +1,67 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package bench
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/offchainlabs/nitro/arbos/arbostypes"
+)
+
+func TestRunIsDeterministic(t *testing.T) {
+	var corpusFile bytes.Buffer
+	for i := uint64(0); i < 3; i++ {
+		message := arbostypes.MessageWithMetadata{
+			Message: &arbostypes.L1IncomingMessage{
+				Header: &arbostypes.L1IncomingMessageHeader{
+					Kind:        arbostypes.L1MessageType_L2Message,
+					BlockNumber: i + 1,
+					Timestamp:   100 + i,
+					L1BaseFee:   big.NewInt(1_000_000_000),
+				},
+				L2msg: []byte{},
+			},
+		}
+		encoded, err := json.Marshal(message)
+		if err != nil {
+			t.Fatal(err)
+		}
+		corpusFile.Write(append(encoded, '\n'))
+	}
+	corpus, err := ReadCorpus(&corpusFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(corpus) != 3 {
+		t.Fatal("expected 3 messages in the corpus, got", len(corpus))
+	}
+
+	config := DefaultConfig
+	config.Runs = 2
+	report, err := Run(&config, corpus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Deterministic || len(report.Divergences) != 0 {
+		t.Fatal("replaying the corpus wasn't deterministic", report.Divergences)
+	}
+	if len(report.Messages) != 3 || len(report.RunTimes) != 2 {
+		t.Fatal("unexpected report size", len(report.Messages), len(report.RunTimes))
+	}
+	for i, message := range report.Messages {
+		// every block starts with the internal start block tx, which reads and writes ArbOS storage
+		if message.Txs != 1 || message.Storage["l2-pricing"].Reads == 0 {
+			t.Fatal("unexpected report for message", i, message)
+		}
+		if message.MinTime > message.MeanTime || message.MeanTime > message.MaxTime {
+			t.Fatal("inconsistent times for message", i, message.MinTime, message.MeanTime, message.MaxTime)
+		}
+	}
+	if len(report.Slowest(2)) != 2 {
+		t.Fatal("expected the two slowest messages")
+	}
+}
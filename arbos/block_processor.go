@@ -12,6 +12,7 @@ import (
 
 	"github.com/offchainlabs/nitro/arbos/arbosState"
 	"github.com/offchainlabs/nitro/arbos/arbostypes"
+	"github.com/offchainlabs/nitro/arbos/l1pricing"
 	"github.com/offchainlabs/nitro/arbos/l2pricing"
 	"github.com/offchainlabs/nitro/arbos/util"
 	"github.com/offchainlabs/nitro/util/arbmath"
@@ -33,10 +34,88 @@ var ArbSysAddress common.Address
 var InternalTxStartBlockMethodID [4]byte
 var InternalTxBatchPostingReportMethodID [4]byte
 var RedeemScheduledEventID common.Hash
+var TicketCreatedEventID common.Hash
+var TicketExpiredEventID common.Hash
+var RedeemFailedEventID common.Hash
 var L2ToL1TransactionEventID common.Hash
 var L2ToL1TxEventID common.Hash
 var EmitReedeemScheduledEvent func(*vm.EVM, uint64, uint64, [32]byte, [32]byte, common.Address, *big.Int, *big.Int) error
 var EmitTicketCreatedEvent func(*vm.EVM, [32]byte) error
+var EmitParameterChangeExecutedEvent func(*vm.EVM, uint64, uint64, [32]byte) error
+var EmitTicketExpiredEvent func(*vm.EVM, [32]byte) error
+var EmitRedeemFailedEvent func(*vm.EVM, [32]byte, [32]byte, []byte) error
+var RedeemFailedEventGasCost func([]byte) uint64
+
+// MaxRedeemRevertDataSize bounds the revert data a RedeemFailed event records. Longer revert data is truncated.
+const MaxRedeemRevertDataSize = 1024
+
+// emitRedeemFailed records in a failed retry tx's receipt the revert data of its call, which the EVM otherwise
+// only surfaces to tracers, so bridges can tell users why their deposit's call failed.
+// It runs before the receipt's logs are collected, after the tx's EVM is gone, so the event gets an EVM of its own.
+// The event is paid for with the retry's unused gas: the revert data is truncated to what that gas covers, the
+// gas is added to the tx's, and its cost is taken back from the refund the retry gave the retryable's from and
+// refund addresses.
+func emitRedeemFailed(
+	header *types.Header,
+	chainContext core.ChainContext,
+	chainConfig *params.ChainConfig,
+	statedb *state.StateDB,
+	arbState *arbosState.ArbosState,
+	tx *types.Transaction,
+	result *core.ExecutionResult,
+) {
+	inner, ok := tx.GetInner().(*types.ArbitrumRetryTx)
+	if !ok || result.UsedGas > tx.Gas() {
+		return
+	}
+	gasLeft := tx.Gas() - result.UsedGas
+	revertData := result.Revert()
+	if len(revertData) > MaxRedeemRevertDataSize {
+		revertData = revertData[:MaxRedeemRevertDataSize]
+	}
+	baseCost := RedeemFailedEventGasCost(nil)
+	if baseCost > gasLeft {
+		return
+	}
+	// the data is ABI encoded, so each 32 bytes of it cost a word of log data
+	wordCost := params.LogDataGas * 32
+	if maxWords := (gasLeft - baseCost) / wordCost; uint64(len(revertData)) > maxWords*32 {
+		revertData = revertData[:maxWords*32]
+	}
+	gasCost := RedeemFailedEventGasCost(revertData)
+	if gasCost > gasLeft {
+		return
+	}
+
+	blockContext := core.NewEVMBlockContext(header, chainContext, &header.Coinbase)
+	evm := vm.NewEVM(blockContext, vm.TxContext{}, statedb, chainConfig, vm.Config{})
+	networkFeeAccount, err := arbState.NetworkFeeAccount()
+	if err != nil {
+		log.Error("failed to read the network fee account to charge for a RedeemFailed event", "err", err)
+		return
+	}
+	// the refund went to the refund address first, so the excess the from address got is taken back first
+	charge := arbmath.BigMulByUint(header.BaseFee, gasCost)
+	for _, payer := range []common.Address{inner.From, inner.RefundTo} {
+		balance := statedb.GetBalance(payer).ToBig()
+		paid := arbmath.BigMin(balance, charge)
+		if err := util.TransferBalance(&payer, &networkFeeAccount, paid, evm, util.TracingAfterEVM, "redeemFailedEvent"); err != nil {
+			log.Error("failed to charge for a RedeemFailed event", "payer", payer, "err", err)
+			return
+		}
+		charge = arbmath.BigSub(charge, paid)
+	}
+	if charge.Sign() > 0 {
+		// the refund covered the gas, so this means the refund itself couldn't be paid
+		log.Error("retry refund doesn't cover its RedeemFailed event", "txHash", tx.Hash(), "unpaid", charge)
+	}
+	if err := EmitRedeemFailedEvent(evm, inner.TicketId, tx.Hash(), revertData); err != nil {
+		log.Error("failed to emit RedeemFailed event", "err", err)
+		return
+	}
+	result.UsedGas += gasCost
+	arbState.Restrict(arbState.L2PricingState().AddToGasPool(-arbmath.SaturatingCast[int64](gasCost)))
+}
 
 // A helper struct that implements String() by marshalling to JSON.
 // This is useful for logging because it's lazy, so if the log level is too high to print the transaction,
@@ -120,6 +199,7 @@ type SequencingHooks struct {
 	PreTxFilter             func(*params.ChainConfig, *types.Header, *state.StateDB, *arbosState.ArbosState, *types.Transaction, *arbitrum_types.ConditionalOptions, common.Address, *L1Info) error
 	PostTxFilter            func(*types.Header, *arbosState.ArbosState, *types.Transaction, common.Address, uint64, *core.ExecutionResult) error
 	ConditionalOptionsForTx []*arbitrum_types.ConditionalOptions
+	Tracer                  vm.EVMLogger // may be nil, traces every tx of the block
 }
 
 func NoopSequencingHooks() *SequencingHooks {
@@ -133,6 +213,7 @@ func NoopSequencingHooks() *SequencingHooks {
 			return nil
 		},
 		nil,
+		nil,
 	}
 }
 
@@ -169,6 +250,7 @@ func ProduceBlockAdvanced(
 	sequencingHooks *SequencingHooks,
 	isMsgForPrefetch bool,
 ) (*types.Block, types.Receipts, error) {
+	defer arbosState.StartBlockCache(statedb)()
 
 	state, err := arbosState.OpenSystemArbosState(statedb, nil, true)
 	if err != nil {
@@ -256,7 +338,8 @@ func ProduceBlockAdvanced(
 				return nil, nil, core.ErrGasLimitReached
 			}
 
-			sender, err = signer.Sender(tx)
+			// types.Sender reuses a sender recovered ahead of time with the same signer
+			sender, err = types.Sender(signer, tx)
 			if err != nil {
 				return nil, nil, err
 			}
@@ -317,8 +400,11 @@ func ProduceBlockAdvanced(
 				header,
 				tx,
 				&header.GasUsed,
-				vm.Config{},
+				vm.Config{Tracer: hooks.Tracer},
 				func(result *core.ExecutionResult) error {
+					if result.Failed() && tx.Type() == types.ArbitrumRetryTxType && state.FeatureEnabled(arbosState.FeatureRedeemRevertData) {
+						emitRedeemFailed(header, chainContext, chainConfig, statedb, state, tx, result)
+					}
 					return hooks.PostTxFilter(header, state, tx, sender, dataGas, result)
 				},
 			)
@@ -501,11 +587,14 @@ func FinalizeBlock(header *types.Header, txs types.Transactions, statedb *state.
 		if header.Number.Uint64() == chainConfig.ArbitrumChainParams.GenesisBlockNum {
 			arbosVersion = chainConfig.ArbitrumChainParams.InitialArbOSVersion
 		} else {
-			state, err := arbosState.OpenSystemArbosState(statedb, nil, true)
+			state, err := arbosState.OpenSystemArbosState(statedb, nil, false)
 			if err != nil {
 				newErr := fmt.Errorf("%w while opening arbos state. Block: %d root: %v", err, header.Number, header.Root)
 				panic(newErr)
 			}
+			if state.ArbOSVersion() >= arbosState.ArbosVersion_PosterSettlement {
+				settleBatchPosters(state, header, statedb, chainConfig)
+			}
 			// Add outbox info to the header for client-side proving
 			acc := state.SendMerkleAccumulator()
 			sendRoot, _ = acc.Root()
@@ -523,3 +612,16 @@ func FinalizeBlock(header *types.Header, txs types.Transactions, statedb *state.
 		header.Root = statedb.IntermediateRoot(true)
 	}
 }
+
+// settleBatchPosters pays batch posters what they're due from the L1 fees collected, including the block's own.
+// It's part of finalizing the block so that it happens the same way however the block is executed.
+func settleBatchPosters(arbState *arbosState.ArbosState, header *types.Header, statedb *state.StateDB, chainConfig *params.ChainConfig) {
+	blockContext := vm.BlockContext{
+		BlockNumber:  header.Number,
+		Time:         header.Time,
+		ArbOSVersion: arbState.ArbOSVersion(),
+	}
+	evm := vm.NewEVM(blockContext, vm.TxContext{}, statedb, chainConfig, vm.Config{})
+	_, err := arbState.L1PricingState().SettleBatchPosters(l1pricing.MaxPostersSettledPerBlock, evm, util.TracingAfterEVM)
+	arbState.Restrict(err)
+}
@@ -11,6 +11,51 @@ import (
 	"github.com/offchainlabs/nitro/arbos/storage"
 )
 
+// ArbosVersionProvenance is the ArbOS version from which the provenance of each stored hash is recorded.
+const ArbosVersionProvenance = 33 // arbosState.ArbosVersion_FirstCustom
+
+// HistoryLength is how many of the most recent L1 block numbers have hashes.
+const HistoryLength = 256
+
+// hashes are stored at offsets 1 through 256, and their provenance at the 256 offsets after
+const provenanceOffset = 1 + HistoryLength
+
+const (
+	provenanceUnknown  byte = iota // the hash was stored before provenance was recorded
+	provenanceRecorded             // the hash is of the L2 block before the one that started with the L1 block number
+	provenanceFilled               // no L2 block started with the L1 block number, so the hash was derived from a later one
+)
+
+// Provenance says where a stored hash came from.
+type Provenance struct {
+	Known      bool
+	Filled     bool
+	RecordedAt uint64 // the L2 block whose start stored the hash
+}
+
+func (p Provenance) encode() common.Hash {
+	var encoded common.Hash
+	encoded[0] = provenanceRecorded
+	if p.Filled {
+		encoded[0] = provenanceFilled
+	}
+	binary.BigEndian.PutUint64(encoded[24:], p.RecordedAt)
+	return encoded
+}
+
+func decodeProvenance(encoded common.Hash) Provenance {
+	if encoded[0] == provenanceUnknown {
+		return Provenance{}
+	}
+	return Provenance{
+		Known:      true,
+		Filled:     encoded[0] == provenanceFilled,
+		RecordedAt: binary.BigEndian.Uint64(encoded[24:]),
+	}
+}
+
+var ErrInvalidBlockNumber = errors.New("invalid block number for BlockHash")
+
 type Blockhashes struct {
 	backingStorage *storage.Storage
 	l1BlockNumber  storage.StorageBackedUint64
@@ -33,13 +78,28 @@ func (bh *Blockhashes) BlockHash(number uint64) (common.Hash, error) {
 	if err != nil {
 		return common.Hash{}, err
 	}
-	if number >= currentNumber || number+256 < currentNumber {
-		return common.Hash{}, errors.New("invalid block number for BlockHash")
+	if number >= currentNumber || number+HistoryLength < currentNumber {
+		return common.Hash{}, ErrInvalidBlockNumber
+	}
+	return bh.backingStorage.GetByUint64(1 + (number % HistoryLength))
+}
+
+// BlockHashWithProvenance returns a stored hash along with where it came from.
+func (bh *Blockhashes) BlockHashWithProvenance(number uint64) (common.Hash, Provenance, error) {
+	hash, err := bh.BlockHash(number)
+	if err != nil {
+		return common.Hash{}, Provenance{}, err
+	}
+	encoded, err := bh.backingStorage.GetByUint64(provenanceOffset + (number % HistoryLength))
+	if err != nil {
+		return common.Hash{}, Provenance{}, err
 	}
-	return bh.backingStorage.GetByUint64(1 + (number % 256))
+	return hash, decodeProvenance(encoded), nil
 }
 
-func (bh *Blockhashes) RecordNewL1Block(number uint64, blockHash common.Hash, arbosVersion uint64) error {
+// RecordNewL1Block stores the hash for an L1 block number, when the L2 block l2BlockNumber is the first to start
+// with it, along with placeholders for any L1 block numbers skipped since the last one recorded.
+func (bh *Blockhashes) RecordNewL1Block(number uint64, blockHash common.Hash, l2BlockNumber uint64, arbosVersion uint64) error {
 	nextNumber, err := bh.l1BlockNumber.Get()
 	if err != nil {
 		return err
@@ -48,8 +108,15 @@ func (bh *Blockhashes) RecordNewL1Block(number uint64, blockHash common.Hash, ar
 		// we already have a stored hash for the block, so just return
 		return nil
 	}
-	if nextNumber+256 < number {
-		nextNumber = number - 256 // no need to record hashes that we're just going to discard
+	if nextNumber+HistoryLength < number {
+		nextNumber = number - HistoryLength // no need to record hashes that we're just going to discard
+	}
+	recordProvenance := func(number uint64, filled bool) error {
+		if arbosVersion < ArbosVersionProvenance {
+			return nil
+		}
+		provenance := Provenance{Known: true, Filled: filled, RecordedAt: l2BlockNumber}
+		return bh.backingStorage.SetByUint64(provenanceOffset+(number%HistoryLength), provenance.encode())
 	}
 	for nextNumber+1 < number {
 		// fill in hashes for any "skipped over" blocks
@@ -63,15 +130,21 @@ func (bh *Blockhashes) RecordNewL1Block(number uint64, blockHash common.Hash, ar
 		if err != nil {
 			return err
 		}
-		err = bh.backingStorage.SetByUint64(1+(nextNumber%256), fill)
+		err = bh.backingStorage.SetByUint64(1+(nextNumber%HistoryLength), fill)
 		if err != nil {
 			return err
 		}
+		if err := recordProvenance(nextNumber, true); err != nil {
+			return err
+		}
 	}
 
-	err = bh.backingStorage.SetByUint64(1+(number%256), blockHash)
+	err = bh.backingStorage.SetByUint64(1+(number%HistoryLength), blockHash)
 	if err != nil {
 		return err
 	}
+	if err := recordProvenance(number, false); err != nil {
+		return err
+	}
 	return bh.l1BlockNumber.Set(number + 1)
 }
@@ -35,7 +35,7 @@ func TestBlockhash(t *testing.T) {
 	}
 
 	hash0 := common.BytesToHash(crypto.Keccak256([]byte{0}))
-	err = bh.RecordNewL1Block(0, hash0, arbosVersion)
+	err = bh.RecordNewL1Block(0, hash0, 1, arbosVersion)
 	Require(t, err)
 	bnum, err = bh.L1BlockNumber()
 	Require(t, err)
@@ -49,7 +49,7 @@ func TestBlockhash(t *testing.T) {
 	}
 
 	hash4242 := common.BytesToHash(crypto.Keccak256([]byte{42, 42}))
-	err = bh.RecordNewL1Block(4242, hash4242, arbosVersion)
+	err = bh.RecordNewL1Block(4242, hash4242, 2, arbosVersion)
 	Require(t, err)
 	bnum, err = bh.L1BlockNumber()
 	Require(t, err)
@@ -84,7 +84,44 @@ func TestBlockhash(t *testing.T) {
 	if err == nil {
 		Fail(t, "old blockhash should give error")
 	}
+	_, provenance, err := bh.BlockHashWithProvenance(4242)
+	Require(t, err)
+	if provenance.Known {
+		Fail(t, "provenance recorded before ArbOS started recording it")
+	}
+}
 
+func TestBlockhashProvenance(t *testing.T) {
+	sto := storage.NewMemoryBacked(burn.NewSystemBurner(nil, false))
+	InitializeBlockhashes(sto)
+	bh := OpenBlockhashes(sto)
+
+	hash10 := common.BytesToHash(crypto.Keccak256([]byte{10}))
+	Require(t, bh.RecordNewL1Block(10, hash10, 100, ArbosVersionProvenance))
+	hash13 := common.BytesToHash(crypto.Keccak256([]byte{13}))
+	Require(t, bh.RecordNewL1Block(13, hash13, 105, ArbosVersionProvenance))
+
+	expected := map[uint64]Provenance{
+		10: {Known: true, Filled: false, RecordedAt: 100},
+		11: {Known: true, Filled: true, RecordedAt: 105},
+		12: {Known: true, Filled: true, RecordedAt: 105},
+		13: {Known: true, Filled: false, RecordedAt: 105},
+	}
+	for number, want := range expected {
+		hash, provenance, err := bh.BlockHashWithProvenance(number)
+		Require(t, err)
+		if provenance != want {
+			Fail(t, "wrong provenance of block", number, provenance, "instead of", want)
+		}
+		stored, err := bh.BlockHash(number)
+		Require(t, err)
+		if hash != stored {
+			Fail(t, "hash with provenance differs from the stored hash of block", number)
+		}
+	}
+	if _, _, err := bh.BlockHashWithProvenance(14); err == nil {
+		Fail(t, "provenance of a future block should give an error")
+	}
 }
 
 func Require(t *testing.T, err error, printables ...interface{}) {
@@ -0,0 +1,149 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+// Package collectorhistory keeps the most recent changes of the accounts ArbOS pays fees to, so that where fees
+// went at any recent block can be audited from state alone. Older changes are overwritten.
+package collectorhistory
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/nitro/arbos/storage"
+)
+
+// Collector identifies a fee collector.
+type Collector uint8
+
+const (
+	NetworkFee Collector = iota // the network fee account
+	InfraFee                    // the infrastructure fee account
+	L1Reward                    // the recipient of L1 pricing rewards
+)
+
+func (c Collector) String() string {
+	switch c {
+	case NetworkFee:
+		return "network fee account"
+	case InfraFee:
+		return "infra fee account"
+	case L1Reward:
+		return "L1 reward recipient"
+	default:
+		return fmt.Sprintf("collector %d", uint8(c))
+	}
+}
+
+// Capacity is how many of the latest changes are kept.
+const Capacity uint64 = 256
+
+const sizeOffset uint64 = 0
+
+var changesKey = []byte{0}
+
+var ErrChangeOverwritten = errors.New("fee collector change has been overwritten by newer ones")
+
+// Change is a fee collector being set to a new account.
+type Change struct {
+	BlockNumber uint64
+	Collector   Collector
+	Previous    common.Address
+	Account     common.Address
+}
+
+// encode packs the change into two slots: the collector, block number and new account, then the previous account.
+func (c Change) encode() (common.Hash, common.Hash) {
+	var slot common.Hash
+	slot[0] = byte(c.Collector)
+	binary.BigEndian.PutUint64(slot[4:12], c.BlockNumber)
+	copy(slot[12:], c.Account.Bytes())
+	return slot, common.BytesToHash(c.Previous.Bytes())
+}
+
+func decodeChange(slot common.Hash, previous common.Hash) Change {
+	return Change{
+		BlockNumber: binary.BigEndian.Uint64(slot[4:12]),
+		Collector:   Collector(slot[0]),
+		Previous:    common.BytesToAddress(previous[12:]),
+		Account:     common.BytesToAddress(slot[12:]),
+	}
+}
+
+// CollectorHistory is a ring of the latest changes. Size counts every change ever recorded, and change i is kept
+// at slots 2*(i%Capacity)+1 and 2*(i%Capacity)+2 until Capacity newer ones replace it.
+type CollectorHistory struct {
+	size    storage.StorageBackedUint64
+	changes *storage.Storage
+}
+
+func Open(sto *storage.Storage) *CollectorHistory {
+	return &CollectorHistory{
+		size:    sto.OpenStorageBackedUint64(sizeOffset),
+		changes: sto.OpenSubStorage(changesKey),
+	}
+}
+
+// Size returns the number of changes ever recorded.
+func (h *CollectorHistory) Size() (uint64, error) {
+	return h.size.Get()
+}
+
+// Oldest returns the index of the oldest change still kept.
+func (h *CollectorHistory) Oldest() (uint64, error) {
+	size, err := h.size.Get()
+	if err != nil || size <= Capacity {
+		return 0, err
+	}
+	return size - Capacity, nil
+}
+
+// Record appends a change, overwriting the oldest kept one if the history is full.
+func (h *CollectorHistory) Record(change Change) error {
+	size, err := h.size.Get()
+	if err != nil {
+		return err
+	}
+	slot, previous := change.encode()
+	index := size % Capacity
+	if err := h.changes.SetByUint64(2*index+1, slot); err != nil {
+		return err
+	}
+	if err := h.changes.SetByUint64(2*index+2, previous); err != nil {
+		return err
+	}
+	return h.size.Set(size + 1)
+}
+
+// Changes returns up to count changes, oldest first, starting from the given index. The index must not be older
+// than Oldest.
+func (h *CollectorHistory) Changes(start uint64, count uint64) ([]Change, error) {
+	oldest, err := h.Oldest()
+	if err != nil {
+		return nil, err
+	}
+	if start < oldest {
+		return nil, fmt.Errorf("%w: the oldest kept is %v", ErrChangeOverwritten, oldest)
+	}
+	size, err := h.size.Get()
+	if err != nil || start >= size {
+		return nil, err
+	}
+	count = min(count, size-start)
+	changes := make([]Change, 0, count)
+	for i := start; i < start+count; i++ {
+		index := i % Capacity
+		slot, err := h.changes.GetByUint64(2*index + 1)
+		if err != nil {
+			return nil, err
+		}
+		previous, err := h.changes.GetByUint64(2*index + 2)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, decodeChange(slot, previous))
+	}
+	return changes, nil
+}
@@ -0,0 +1,67 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package collectorhistory
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/offchainlabs/nitro/arbos/burn"
+	"github.com/offchainlabs/nitro/arbos/storage"
+	"github.com/offchainlabs/nitro/util/testhelpers"
+)
+
+func TestCollectorHistory(t *testing.T) {
+	history := Open(storage.NewMemoryBacked(burn.NewSystemBurner(nil, false)))
+	alice := common.HexToAddress("0xa11ce")
+	bob := common.HexToAddress("0xb0b")
+
+	changes, err := history.Changes(0, 10)
+	Require(t, err)
+	if len(changes) != 0 {
+		Fail(t, "changes in an empty history", changes)
+	}
+
+	first := Change{BlockNumber: 5, Collector: InfraFee, Previous: common.Address{}, Account: alice}
+	second := Change{BlockNumber: 9, Collector: L1Reward, Previous: alice, Account: bob}
+	Require(t, history.Record(first))
+	Require(t, history.Record(second))
+	changes, err = history.Changes(0, 10)
+	Require(t, err)
+	if !reflect.DeepEqual(changes, []Change{first, second}) {
+		Fail(t, "wrong changes", changes)
+	}
+
+	// fill the ring so that it wraps, overwriting the first changes
+	for i := uint64(0); i < Capacity; i++ {
+		Require(t, history.Record(Change{BlockNumber: 10 + i, Collector: NetworkFee, Previous: bob, Account: alice}))
+	}
+	size, err := history.Size()
+	Require(t, err)
+	oldest, err := history.Oldest()
+	Require(t, err)
+	if size != Capacity+2 || oldest != 2 {
+		Fail(t, "wrong size or oldest", size, oldest)
+	}
+	if _, err := history.Changes(1, 1); !errors.Is(err, ErrChangeOverwritten) {
+		Fail(t, "read an overwritten change", err)
+	}
+	changes, err = history.Changes(size-1, 10)
+	Require(t, err)
+	if len(changes) != 1 || changes[0].BlockNumber != 10+Capacity-1 || changes[0].Collector != NetworkFee {
+		Fail(t, "wrong latest change", changes)
+	}
+}
+
+func Require(t *testing.T, err error, printables ...interface{}) {
+	t.Helper()
+	testhelpers.RequireImpl(t, err, printables...)
+}
+
+func Fail(t *testing.T, printables ...interface{}) {
+	t.Helper()
+	testhelpers.FailImpl(t, printables...)
+}
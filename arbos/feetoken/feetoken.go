@@ -0,0 +1,195 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+// Package feetoken keeps the ArbOS accounting of chains whose gas is paid in an ERC-20 token bridged from the
+// parent chain instead of in ETH.
+//
+// The bridge delivers native token amounts scaled to 18 decimals, so balances, the L2 basefee and the L1 price per
+// unit are all kept in 18 decimal native token wei. Fees incurred on the parent chain, such as the cost of posting
+// batches, are converted from parent chain wei at the exchange rate set by the chain owner.
+package feetoken
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/nitro/arbos/storage"
+	"github.com/offchainlabs/nitro/util/arbmath"
+)
+
+// StandardDecimals is the number of decimals native token amounts are scaled to by the bridge.
+const StandardDecimals = 18
+
+// MaxDecimals bounds the decimals of a fee token so scaling factors stay small.
+const MaxDecimals = 36
+
+// ExchangeRateOne is the exchange rate at which one parent chain wei converts to one native token wei.
+var ExchangeRateOne = big.NewInt(1e18)
+
+var ErrInvalidFeeTokenConfig = errors.New("invalid fee token configuration")
+
+const (
+	exchangeRateOffset uint64 = iota
+	decimalsOffset
+	totalDepositedOffset
+	totalWithdrawnOffset
+)
+
+type FeeTokenState struct {
+	exchangeRate   storage.StorageBackedBigUint // native token wei per parent chain wei, scaled by ExchangeRateOne, or zero for ETH chains
+	decimals       storage.StorageBackedUint64
+	totalDeposited storage.StorageBackedBigUint
+	totalWithdrawn storage.StorageBackedBigUint
+}
+
+func OpenFeeTokenState(sto *storage.Storage) *FeeTokenState {
+	return &FeeTokenState{
+		exchangeRate:   sto.OpenStorageBackedBigUint(exchangeRateOffset),
+		decimals:       sto.OpenStorageBackedUint64(decimalsOffset),
+		totalDeposited: sto.OpenStorageBackedBigUint(totalDepositedOffset),
+		totalWithdrawn: sto.OpenStorageBackedBigUint(totalWithdrawnOffset),
+	}
+}
+
+// IsFeeTokenChain returns whether gas is paid in a bridged ERC-20 token.
+func (fts *FeeTokenState) IsFeeTokenChain() (bool, error) {
+	rate, err := fts.exchangeRate.Get()
+	if err != nil {
+		return false, err
+	}
+	return rate.Sign() != 0, nil
+}
+
+func (fts *FeeTokenState) ExchangeRate() (*big.Int, error) {
+	return fts.exchangeRate.Get()
+}
+
+// Decimals returns the decimals of the fee token, or StandardDecimals on ETH chains.
+func (fts *FeeTokenState) Decimals() (uint64, error) {
+	isFeeTokenChain, err := fts.IsFeeTokenChain()
+	if err != nil || !isFeeTokenChain {
+		return StandardDecimals, err
+	}
+	return fts.decimals.Get()
+}
+
+// Configure makes the chain pay gas in a fee token with the given decimals, converting parent chain fees
+// at the given exchange rate. A zero exchange rate makes the chain an ETH chain again.
+func (fts *FeeTokenState) Configure(decimals uint64, exchangeRate *big.Int) error {
+	if decimals > MaxDecimals || exchangeRate.Sign() < 0 || exchangeRate.BitLen() > 256 {
+		return ErrInvalidFeeTokenConfig
+	}
+	if err := fts.decimals.Set(decimals); err != nil {
+		return err
+	}
+	return fts.exchangeRate.SetChecked(exchangeRate)
+}
+
+// maxConfigRateBits is the widest exchange rate EncodeConfig can pack alongside the decimals.
+const maxConfigRateBits = 248
+
+// EncodeConfig packs a fee token configuration into a single word, with the decimals in the top byte and the
+// exchange rate below them, so switching the fee token can go through the chain owner's time lock.
+func EncodeConfig(decimals uint64, exchangeRate *big.Int) (common.Hash, error) {
+	if decimals > MaxDecimals || exchangeRate.Sign() < 0 || exchangeRate.BitLen() > maxConfigRateBits {
+		return common.Hash{}, ErrInvalidFeeTokenConfig
+	}
+	config := common.BigToHash(exchangeRate)
+	config[0] = byte(decimals)
+	return config, nil
+}
+
+// DecodeConfig unpacks a fee token configuration packed by EncodeConfig.
+func DecodeConfig(config common.Hash) (uint64, *big.Int) {
+	decimals := uint64(config[0])
+	config[0] = 0
+	return decimals, config.Big()
+}
+
+// SetExchangeRate updates the exchange rate of a chain that already pays gas in a fee token.
+func (fts *FeeTokenState) SetExchangeRate(exchangeRate *big.Int) error {
+	isFeeTokenChain, err := fts.IsFeeTokenChain()
+	if err != nil {
+		return err
+	}
+	if !isFeeTokenChain || exchangeRate.Sign() <= 0 || exchangeRate.BitLen() > 256 {
+		return ErrInvalidFeeTokenConfig
+	}
+	return fts.exchangeRate.SetChecked(exchangeRate)
+}
+
+// FromParentChainWei converts an amount of parent chain wei to the native token wei it's worth.
+// On ETH chains the amount is returned unchanged.
+func (fts *FeeTokenState) FromParentChainWei(parentChainWei *big.Int) (*big.Int, error) {
+	rate, err := fts.exchangeRate.Get()
+	if err != nil || rate.Sign() == 0 {
+		return parentChainWei, err
+	}
+	return arbmath.BigDiv(arbmath.BigMul(parentChainWei, rate), ExchangeRateOne), nil
+}
+
+// ToTokenUnits converts 18 decimal native token wei to the smallest units of the fee token, rounding down.
+// Amounts worth less than a unit of a token with fewer than 18 decimals round down to zero, so prices, which are
+// usually far smaller, should be converted with ToScaledTokenUnits.
+func (fts *FeeTokenState) ToTokenUnits(amount *big.Int) (*big.Int, error) {
+	decimals, err := fts.Decimals()
+	if err != nil {
+		return nil, err
+	}
+	if decimals >= StandardDecimals {
+		return arbmath.BigMul(amount, powerOfTen(decimals-StandardDecimals)), nil
+	}
+	return arbmath.BigDiv(amount, powerOfTen(StandardDecimals-decimals)), nil
+}
+
+// ToScaledTokenUnits converts 18 decimal native token wei to the smallest units of the fee token scaled by 1e18.
+// The scaling is applied before dividing by the token's decimals, so nothing is lost to rounding.
+func (fts *FeeTokenState) ToScaledTokenUnits(amount *big.Int) (*big.Int, error) {
+	decimals, err := fts.Decimals()
+	if err != nil {
+		return nil, err
+	}
+	return arbmath.BigMul(amount, powerOfTen(decimals)), nil
+}
+
+func powerOfTen(exponent uint64) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), new(big.Int).SetUint64(exponent), nil)
+}
+
+// RecordDeposit accounts for native token minted by a deposit from the parent chain.
+// Nothing is recorded on ETH chains.
+func (fts *FeeTokenState) RecordDeposit(amount *big.Int) error {
+	isFeeTokenChain, err := fts.IsFeeTokenChain()
+	if err != nil || !isFeeTokenChain || amount.Sign() <= 0 {
+		return err
+	}
+	total, err := fts.totalDeposited.Get()
+	if err != nil {
+		return err
+	}
+	return fts.totalDeposited.SetSaturatingWithWarning(arbmath.BigAdd(total, amount), "fee token total deposited")
+}
+
+// RecordWithdrawal accounts for native token burned by a withdrawal to the parent chain.
+// Nothing is recorded on ETH chains.
+func (fts *FeeTokenState) RecordWithdrawal(amount *big.Int) error {
+	isFeeTokenChain, err := fts.IsFeeTokenChain()
+	if err != nil || !isFeeTokenChain || amount.Sign() <= 0 {
+		return err
+	}
+	total, err := fts.totalWithdrawn.Get()
+	if err != nil {
+		return err
+	}
+	return fts.totalWithdrawn.SetSaturatingWithWarning(arbmath.BigAdd(total, amount), "fee token total withdrawn")
+}
+
+func (fts *FeeTokenState) TotalDeposited() (*big.Int, error) {
+	return fts.totalDeposited.Get()
+}
+
+func (fts *FeeTokenState) TotalWithdrawn() (*big.Int, error) {
+	return fts.totalWithdrawn.Get()
+}
@@ -0,0 +1,123 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package feetoken
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/offchainlabs/nitro/arbos/burn"
+	"github.com/offchainlabs/nitro/arbos/storage"
+	"github.com/offchainlabs/nitro/util/testhelpers"
+)
+
+func TestFeeTokenState(t *testing.T) {
+	fts := OpenFeeTokenState(storage.NewMemoryBacked(burn.NewSystemBurner(nil, false)))
+
+	// ETH chains convert nothing and record nothing
+	isFeeTokenChain, err := fts.IsFeeTokenChain()
+	Require(t, err)
+	if isFeeTokenChain {
+		Fail(t, "new state is a fee token chain")
+	}
+	converted, err := fts.FromParentChainWei(big.NewInt(1000))
+	Require(t, err)
+	if converted.Int64() != 1000 {
+		Fail(t, "ETH chain converted parent chain wei to", converted)
+	}
+	Require(t, fts.RecordDeposit(big.NewInt(5)))
+	deposited, err := fts.TotalDeposited()
+	Require(t, err)
+	if deposited.Sign() != 0 {
+		Fail(t, "ETH chain recorded a deposit")
+	}
+	if fts.SetExchangeRate(ExchangeRateOne) == nil {
+		Fail(t, "set the exchange rate of an ETH chain")
+	}
+
+	// a 6 decimal token worth a thousandth of the parent chain's currency
+	Require(t, fts.Configure(6, new(big.Int).Mul(big.NewInt(1000), ExchangeRateOne)))
+	converted, err = fts.FromParentChainWei(big.NewInt(1000))
+	Require(t, err)
+	if converted.Int64() != 1_000_000 {
+		Fail(t, "wrong conversion of parent chain wei", converted)
+	}
+	units, err := fts.ToTokenUnits(big.NewInt(3e12 + 1))
+	Require(t, err)
+	if units.Int64() != 3 {
+		Fail(t, "wrong conversion to token units", units)
+	}
+	Require(t, fts.SetExchangeRate(ExchangeRateOne))
+	converted, err = fts.FromParentChainWei(big.NewInt(1000))
+	Require(t, err)
+	if converted.Int64() != 1000 {
+		Fail(t, "wrong conversion after updating the exchange rate", converted)
+	}
+
+	Require(t, fts.RecordDeposit(big.NewInt(5)))
+	Require(t, fts.RecordDeposit(big.NewInt(7)))
+	Require(t, fts.RecordWithdrawal(big.NewInt(3)))
+	deposited, err = fts.TotalDeposited()
+	Require(t, err)
+	withdrawn, err := fts.TotalWithdrawn()
+	Require(t, err)
+	if deposited.Int64() != 12 || withdrawn.Int64() != 3 {
+		Fail(t, "wrong bridge totals", deposited, withdrawn)
+	}
+
+	if fts.Configure(MaxDecimals+1, ExchangeRateOne) == nil || fts.SetExchangeRate(common.Big0) == nil {
+		Fail(t, "accepted an invalid fee token configuration")
+	}
+
+	// the chain can be made an ETH chain again
+	Require(t, fts.Configure(0, common.Big0))
+	decimals, err := fts.Decimals()
+	Require(t, err)
+	if decimals != StandardDecimals {
+		Fail(t, "ETH chain has", decimals, "decimals")
+	}
+}
+
+func TestFeeTokenPrecision(t *testing.T) {
+	fts := OpenFeeTokenState(storage.NewMemoryBacked(burn.NewSystemBurner(nil, false)))
+	Require(t, fts.Configure(6, ExchangeRateOne))
+
+	// a basefee of 0.01 gwei is a fraction of a unit of a 6 decimal token
+	baseFee := big.NewInt(1e7)
+	units, err := fts.ToTokenUnits(baseFee)
+	Require(t, err)
+	if units.Sign() != 0 {
+		Fail(t, "expected the unscaled conversion to round down to zero, got", units)
+	}
+	scaled, err := fts.ToScaledTokenUnits(baseFee)
+	Require(t, err)
+	if scaled.Cmp(big.NewInt(1e13)) != 0 {
+		Fail(t, "wrong scaled conversion", scaled)
+	}
+
+	rate := new(big.Int).Mul(big.NewInt(1234), ExchangeRateOne)
+	config, err := EncodeConfig(6, rate)
+	Require(t, err)
+	decimals, decodedRate := DecodeConfig(config)
+	if decimals != 6 || decodedRate.Cmp(rate) != 0 {
+		Fail(t, "fee token config didn't round trip", decimals, decodedRate)
+	}
+	if _, err := EncodeConfig(MaxDecimals+1, rate); err == nil {
+		Fail(t, "encoded too many decimals")
+	}
+	if _, err := EncodeConfig(6, new(big.Int).Lsh(common.Big1, 248)); err == nil {
+		Fail(t, "encoded an exchange rate that overlaps the decimals")
+	}
+}
+
+func Require(t *testing.T, err error, printables ...interface{}) {
+	t.Helper()
+	testhelpers.RequireImpl(t, err, printables...)
+}
+
+func Fail(t *testing.T, printables ...interface{}) {
+	t.Helper()
+	testhelpers.FailImpl(t, printables...)
+}
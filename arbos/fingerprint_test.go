@@ -0,0 +1,87 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbos
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/offchainlabs/nitro/arbos/arbostypes"
+	"github.com/offchainlabs/nitro/arbos/util"
+	"github.com/offchainlabs/nitro/util/testhelpers"
+)
+
+func TestRetryableTicketId(t *testing.T) {
+	chainId := big.NewInt(412346)
+	for _, retryTo := range []common.Address{testhelpers.RandomAddress(), {}} {
+		submission := util.RetryableSubmission{
+			ChainId:             chainId,
+			DelayedMessageIndex: 77,
+			L1Sender:            testhelpers.RandomAddress(),
+			L1BaseFee:           big.NewInt(30e9),
+			DepositValue:        big.NewInt(1e18),
+			GasFeeCap:           big.NewInt(1e8),
+			Gas:                 100000,
+			RetryTo:             retryTo,
+			Beneficiary:         testhelpers.RandomAddress(),
+			MaxSubmissionFee:    big.NewInt(1e15),
+			FeeRefundAddr:       testhelpers.RandomAddress(),
+			RetryData:           []byte{1, 2, 3},
+		}
+
+		var l2msg bytes.Buffer
+		for _, word := range []common.Hash{
+			common.BytesToHash(submission.RetryTo.Bytes()),
+			{}, // zero callvalue
+			common.BigToHash(submission.DepositValue),
+			common.BigToHash(submission.MaxSubmissionFee),
+			common.BytesToHash(submission.FeeRefundAddr.Bytes()),
+			common.BytesToHash(submission.Beneficiary.Bytes()),
+			common.BigToHash(new(big.Int).SetUint64(submission.Gas)),
+			common.BigToHash(submission.GasFeeCap),
+			common.BigToHash(big.NewInt(int64(len(submission.RetryData)))),
+		} {
+			l2msg.Write(word[:])
+		}
+		l2msg.Write(submission.RetryData)
+
+		requestId := util.DelayedMessageRequestId(submission.DelayedMessageIndex)
+		message := &arbostypes.L1IncomingMessage{
+			Header: &arbostypes.L1IncomingMessageHeader{
+				Kind:      arbostypes.L1MessageType_SubmitRetryable,
+				Poster:    util.RemapL1Address(submission.L1Sender),
+				RequestId: &requestId,
+				L1BaseFee: submission.L1BaseFee,
+			},
+			L2msg: l2msg.Bytes(),
+		}
+		txs, err := ParseL2Transactions(message, chainId)
+		Require(t, err)
+		if len(txs) != 1 || txs[0].Type() != types.ArbitrumSubmitRetryableTxType {
+			Fail(t, "unexpected txs", txs)
+		}
+		if txs[0].Hash() != submission.TicketId() {
+			Fail(t, "ticket id", submission.TicketId(), "doesn't match the parsed tx", txs[0].Hash())
+		}
+	}
+}
+
+func TestSubRequestIds(t *testing.T) {
+	requestId := util.DelayedMessageRequestId(5)
+	if requestId.Big().Uint64() != 5 {
+		Fail(t, "unexpected request id", requestId)
+	}
+	seqNum, err := (&arbostypes.L1IncomingMessageHeader{RequestId: &requestId}).SeqNum()
+	Require(t, err)
+	if seqNum != 5 {
+		Fail(t, "request id round trips to sequence number", seqNum)
+	}
+	if util.SubRequestId(requestId, common.Big0) == util.SubRequestId(requestId, common.Big1) {
+		Fail(t, "sub request ids collide")
+	}
+}
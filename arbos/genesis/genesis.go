@@ -0,0 +1,156 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+// Package genesis builds the genesis of a new Arbitrum chain in process: the ArbOS state, the genesis block,
+// and the init message the chain's inbox must deliver first.
+package genesis
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/offchainlabs/nitro/arbos/arbosState"
+	"github.com/offchainlabs/nitro/arbos/arbostypes"
+	"github.com/offchainlabs/nitro/statetransfer"
+)
+
+var ErrInvalidGenesisConfig = errors.New("invalid genesis config")
+
+type Config struct {
+	ChainConfig      *params.ChainConfig
+	InitialOwner     common.Address // overrides the chain config's initial chain owner if set
+	InitialL1BaseFee *big.Int       // arbostypes.DefaultInitialL1BaseFee if nil
+	Accounts         []statetransfer.AccountInitializationInfo
+	AddressTable     []common.Address
+}
+
+type Genesis struct {
+	ChainConfig *params.ChainConfig
+	InitMessage *arbostypes.L1IncomingMessage
+	Block       *types.Block
+}
+
+func (g *Genesis) StateRoot() common.Hash {
+	return g.Block.Root()
+}
+
+func invalid(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidGenesisConfig, fmt.Sprintf(format, args...))
+}
+
+func (c *Config) validate() (*params.ChainConfig, error) {
+	if c.ChainConfig == nil {
+		return nil, invalid("missing chain config")
+	}
+	// copy the chain config so setting the owner doesn't modify the caller's
+	chainConfig := *c.ChainConfig
+	if c.InitialOwner != (common.Address{}) {
+		chainConfig.ArbitrumChainParams.InitialChainOwner = c.InitialOwner
+	}
+	if chainConfig.ChainID == nil || chainConfig.ChainID.Sign() <= 0 {
+		return nil, invalid("chain id must be positive")
+	}
+	if !chainConfig.IsArbitrum() {
+		return nil, invalid("chain config doesn't enable ArbOS")
+	}
+	if chainConfig.ArbitrumChainParams.InitialArbOSVersion == 0 {
+		return nil, invalid("initial ArbOS version must be set")
+	}
+	if chainConfig.ArbitrumChainParams.GenesisBlockNum != 0 {
+		return nil, invalid("genesis block number must be zero, got %d", chainConfig.ArbitrumChainParams.GenesisBlockNum)
+	}
+	if chainConfig.ArbitrumChainParams.InitialChainOwner == (common.Address{}) {
+		return nil, invalid("initial chain owner must be set")
+	}
+	if err := chainConfig.CheckConfigForkOrder(); err != nil {
+		return nil, invalid("%v", err)
+	}
+	if c.InitialL1BaseFee != nil && c.InitialL1BaseFee.Sign() < 0 {
+		return nil, invalid("initial L1 base fee is negative")
+	}
+
+	tableEntries := make(map[common.Address]bool)
+	for _, address := range c.AddressTable {
+		if tableEntries[address] {
+			return nil, invalid("address %v is in the address table twice", address)
+		}
+		tableEntries[address] = true
+	}
+	accounts := make(map[common.Address]bool)
+	for _, account := range c.Accounts {
+		if accounts[account.Addr] {
+			return nil, invalid("account %v is preloaded twice", account.Addr)
+		}
+		accounts[account.Addr] = true
+		if account.EthBalance == nil || account.EthBalance.Sign() < 0 {
+			return nil, invalid("account %v has a missing or negative balance", account.Addr)
+		}
+		if account.Addr == types.ArbosStateAddress || account.Addr == types.ArbosAddress {
+			return nil, invalid("account %v is reserved for ArbOS", account.Addr)
+		}
+		if _, ok := arbosState.PrecompileMinArbOSVersions[account.Addr]; ok {
+			return nil, invalid("account %v is a precompile", account.Addr)
+		}
+	}
+	return &chainConfig, nil
+}
+
+// Build validates the config and writes the genesis state to db, returning the genesis block and the init
+// message that must be the chain's first delayed message. The default cache config is used if cacheConfig is nil.
+func Build(db ethdb.Database, cacheConfig *core.CacheConfig, config *Config) (*Genesis, error) {
+	chainConfig, err := config.validate()
+	if err != nil {
+		return nil, err
+	}
+	if cacheConfig == nil {
+		cacheConfig = core.DefaultCacheConfigWithScheme(rawdb.HashScheme)
+	}
+	serializedChainConfig, err := json.Marshal(chainConfig)
+	if err != nil {
+		return nil, err
+	}
+	initialL1BaseFee := config.InitialL1BaseFee
+	if initialL1BaseFee == nil {
+		initialL1BaseFee = arbostypes.DefaultInitialL1BaseFee
+	}
+	initMessage := arbostypes.MakeInitMessage(chainConfig.ChainID, initialL1BaseFee, serializedChainConfig)
+
+	// initialize from the parsed message, exactly as a node reading it from the inbox would
+	parsedInitMessage, err := initMessage.ParseInitMessage()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the init message: %w", err)
+	}
+	initData := statetransfer.NewMemoryInitDataReader(&statetransfer.ArbosInitializationInfo{
+		AddressTableContents: config.AddressTable,
+		Accounts:             config.Accounts,
+	})
+	stateRoot, err := arbosState.InitializeArbosInDatabase(db, cacheConfig, initData, parsedInitMessage.ChainConfig, parsedInitMessage, 0, statetransfer.ImportConfig{})
+	if err != nil {
+		return nil, err
+	}
+	block := arbosState.MakeGenesisBlock(common.Hash{}, 0, 0, stateRoot, parsedInitMessage.ChainConfig)
+	return &Genesis{
+		ChainConfig: parsedInitMessage.ChainConfig,
+		InitMessage: initMessage,
+		Block:       block,
+	}, nil
+}
+
+// Write stores the genesis block as the head of db, along with the chain config. The state must already be in db.
+func (g *Genesis) Write(db ethdb.Database) error {
+	if stored := rawdb.ReadCanonicalHash(db, 0); stored != (common.Hash{}) && stored != g.Block.Hash() {
+		return fmt.Errorf("database already has genesis block %v, not %v", stored, g.Block.Hash())
+	}
+	core.WriteHeadBlock(db, g.Block, big.NewInt(0))
+	rawdb.WriteChainConfig(db, g.Block.Hash(), g.ChainConfig)
+	return nil
+}
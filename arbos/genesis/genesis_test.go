@@ -0,0 +1,115 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package genesis
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/offchainlabs/nitro/arbos/arbosState"
+	"github.com/offchainlabs/nitro/arbos/burn"
+	"github.com/offchainlabs/nitro/statetransfer"
+	"github.com/offchainlabs/nitro/util/testhelpers"
+)
+
+func TestBuild(t *testing.T) {
+	owner := testhelpers.RandomAddress()
+	account := statetransfer.AccountInitializationInfo{
+		Addr:       testhelpers.RandomAddress(),
+		Nonce:      3,
+		EthBalance: big.NewInt(1e18),
+	}
+	tableEntry := testhelpers.RandomAddress()
+	config := &Config{
+		ChainConfig:      params.ArbitrumDevTestChainConfig(),
+		InitialOwner:     owner,
+		InitialL1BaseFee: big.NewInt(7e9),
+		Accounts:         []statetransfer.AccountInitializationInfo{account},
+		AddressTable:     []common.Address{tableEntry},
+	}
+
+	db := rawdb.NewMemoryDatabase()
+	cacheConfig := core.DefaultCacheConfigWithScheme(rawdb.HashScheme)
+	genesis, err := Build(db, cacheConfig, config)
+	Require(t, err)
+	if config.ChainConfig.ArbitrumChainParams.InitialChainOwner == owner {
+		Fail(t, "build modified the caller's chain config")
+	}
+
+	parsed, err := genesis.InitMessage.ParseInitMessage()
+	Require(t, err)
+	if parsed.ChainId.Cmp(config.ChainConfig.ChainID) != 0 || parsed.InitialL1BaseFee.Cmp(config.InitialL1BaseFee) != 0 {
+		Fail(t, "unexpected init message", parsed.ChainId, parsed.InitialL1BaseFee)
+	}
+	if parsed.ChainConfig.ArbitrumChainParams.InitialChainOwner != owner {
+		Fail(t, "init message has the wrong chain owner", parsed.ChainConfig.ArbitrumChainParams.InitialChainOwner)
+	}
+
+	statedb, err := state.New(genesis.StateRoot(), state.NewDatabaseWithConfig(db, cacheConfig.TriedbConfig()), nil)
+	Require(t, err)
+	if statedb.GetBalance(account.Addr).ToBig().Cmp(account.EthBalance) != 0 || statedb.GetNonce(account.Addr) != account.Nonce {
+		Fail(t, "preloaded account wasn't imported")
+	}
+	arbState, err := arbosState.OpenArbosState(statedb, burn.NewSystemBurner(nil, false))
+	Require(t, err)
+	isOwner, err := arbState.ChainOwners().IsMember(owner)
+	Require(t, err)
+	if !isOwner {
+		Fail(t, "initial owner isn't a chain owner")
+	}
+	index, exists, err := arbState.AddressTable().Lookup(tableEntry)
+	Require(t, err)
+	if !exists || index != 0 {
+		Fail(t, "address table wasn't imported", index, exists)
+	}
+
+	Require(t, genesis.Write(db))
+	if rawdb.ReadCanonicalHash(db, 0) != genesis.Block.Hash() {
+		Fail(t, "genesis block wasn't written")
+	}
+}
+
+func TestBuildValidation(t *testing.T) {
+	account := statetransfer.AccountInitializationInfo{Addr: testhelpers.RandomAddress(), EthBalance: common.Big1}
+	entry := testhelpers.RandomAddress()
+	configs := map[string]*Config{
+		"no chain config": {InitialOwner: testhelpers.RandomAddress()},
+		"no owner":        {ChainConfig: params.ArbitrumDevTestChainConfig()},
+		"duplicate account": {
+			ChainConfig:  params.ArbitrumDevTestChainConfig(),
+			InitialOwner: testhelpers.RandomAddress(),
+			Accounts:     []statetransfer.AccountInitializationInfo{account, account},
+		},
+		"duplicate table entry": {
+			ChainConfig:  params.ArbitrumDevTestChainConfig(),
+			InitialOwner: testhelpers.RandomAddress(),
+			AddressTable: []common.Address{entry, entry},
+		},
+	}
+	for name, config := range configs {
+		if config.ChainConfig != nil {
+			config.ChainConfig.ArbitrumChainParams.InitialChainOwner = common.Address{}
+		}
+		if _, err := Build(rawdb.NewMemoryDatabase(), nil, config); !errors.Is(err, ErrInvalidGenesisConfig) {
+			Fail(t, name, "didn't fail validation:", err)
+		}
+	}
+}
+
+func Require(t *testing.T, err error, printables ...interface{}) {
+	t.Helper()
+	testhelpers.RequireImpl(t, err, printables...)
+}
+
+func Fail(t *testing.T, printables ...interface{}) {
+	t.Helper()
+	testhelpers.FailImpl(t, printables...)
+}
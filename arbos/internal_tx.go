@@ -16,6 +16,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/offchainlabs/nitro/arbos/arbosState"
+	"github.com/offchainlabs/nitro/arbos/pricehistory"
 	"github.com/offchainlabs/nitro/arbos/util"
 )
 
@@ -77,18 +78,73 @@ func ApplyInternalTxUpdate(tx *types.ArbitrumInternalTx, state *arbosState.Arbos
 			if evm.Context.BlockNumber.Sign() > 0 {
 				prevHash = evm.Context.GetHash(evm.Context.BlockNumber.Uint64() - 1)
 			}
-			state.Restrict(state.Blockhashes().RecordNewL1Block(l1BlockNumber-1, prevHash, state.ArbOSVersion()))
+			state.Restrict(state.Blockhashes().RecordNewL1Block(l1BlockNumber-1, prevHash, evm.Context.BlockNumber.Uint64(), state.ArbOSVersion()))
+			if state.ArbOSVersion() >= arbosState.ArbosVersion_L1BlockMap {
+				state.Restrict(state.L1BlockMap().Record(l1BlockNumber, evm.Context.BlockNumber.Uint64()))
+			}
 		}
 
 		currentTime := evm.Context.Time
 
+		emitTicketExpired := func(ticketId common.Hash) error {
+			if state.ArbOSVersion() < arbosState.ArbosVersion_TicketExpiredEvent {
+				return nil
+			}
+			return EmitTicketExpiredEvent(evm, ticketId)
+		}
+
 		// Try to reap 2 retryables
-		_ = state.RetryableState().TryToReapOneRetryable(currentTime, evm, util.TracingDuringEVM)
-		_ = state.RetryableState().TryToReapOneRetryable(currentTime, evm, util.TracingDuringEVM)
+		for i := 0; i < 2; i++ {
+			ticketId, expired, _ := state.RetryableState().TryToReapExpiredRetryable(currentTime, evm, util.TracingDuringEVM)
+			if expired {
+				state.Restrict(state.RecordStatistic(arbosState.StatRetryablesExpired, 1))
+				if err := emitTicketExpired(ticketId); err != nil {
+					log.Error("failed to emit TicketExpired event", "err", err)
+				}
+			}
+		}
+		state.Restrict(state.SweepDeadState(currentTime, evm, emitTicketExpired))
+
+		if state.FeatureEnabled(arbosState.FeatureScheduledParameterChanges) {
+			state.Restrict(state.ApplyDueScheduledChanges(currentTime, evm.Context.BlockNumber.Uint64(), func(change arbosState.ScheduledChange) error {
+				return EmitParameterChangeExecutedEvent(evm, change.Id, uint64(change.Parameter), change.Value)
+			}))
+		}
 
 		state.L2PricingState().UpdatePricingModel(l2BaseFee, timePassed, false)
 
-		return state.UpgradeArbosVersionIfNecessary(currentTime, evm.StateDB, evm.ChainConfig())
+		if evm.Config.Tracer != nil {
+			nextL2BaseFee, err := state.L2PricingState().BaseFeeWei()
+			state.Restrict(err)
+			l1BaseFee := util.SafeMapGet[*big.Int](inputs, "l1BaseFee")
+			l2BlockNumber := util.SafeMapGet[uint64](inputs, "l2BlockNumber")
+			traceInternalTxStartBlock(evm, l1BaseFee, l1BlockNumber, l2BlockNumber, timePassed, l2BaseFee, nextL2BaseFee)
+		}
+
+		previousVersion := state.ArbOSVersion()
+		if err := state.UpgradeArbosVersionIfNecessary(currentTime, evm.StateDB, evm.ChainConfig()); err != nil {
+			return err
+		}
+		if previousVersion < arbosState.ArbosVersion_ChainOwnerHistory && state.ArbOSVersion() >= arbosState.ArbosVersion_ChainOwnerHistory {
+			// the history starts with the upgrade, before this block's transactions change any owners
+			state.Restrict(state.StartChainOwnerHistory(evm.Context.BlockNumber.Uint64()))
+		}
+		if state.ArbOSVersion() >= arbosState.ArbosVersion_BlockGasResources {
+			state.Restrict(state.L2PricingState().RecordBlockStartBacklog())
+		}
+		if state.ArbOSVersion() >= arbosState.ArbosVersion_PriceHistory {
+			l1PricePerUnit, err := state.L1PricingState().PricePerUnit()
+			state.Restrict(err)
+			minBaseFee, err := state.L2PricingState().MinBaseFeeWei()
+			state.Restrict(err)
+			state.Restrict(state.PriceHistory().Record(pricehistory.Snapshot{
+				BlockNumber:    evm.Context.BlockNumber.Uint64(),
+				L1PricePerUnit: l1PricePerUnit,
+				L2BaseFee:      l2BaseFee,
+				MinBaseFee:     minBaseFee,
+			}))
+		}
+		return nil
 	case InternalTxBatchPostingReportMethodID:
 		inputs, err := util.UnpackInternalTxDataBatchPostingReport(tx.Data)
 		if err != nil {
@@ -98,14 +154,30 @@ func ApplyInternalTxUpdate(tx *types.ArbitrumInternalTx, state *arbosState.Arbos
 		batchPosterAddress := util.SafeMapGet[common.Address](inputs, "batchPosterAddress")
 		batchDataGas := util.SafeMapGet[uint64](inputs, "batchDataGas")
 		l1BaseFeeWei := util.SafeMapGet[*big.Int](inputs, "l1BaseFeeWei")
+		if state.ArbOSVersion() >= arbosState.ArbosVersion_FeeToken {
+			// batch posters are reimbursed in the native token for what they spent in parent chain wei
+			l1BaseFeeWei, err = state.FeeTokenState().FromParentChainWei(l1BaseFeeWei)
+			state.Restrict(err)
+		}
 
 		l1p := state.L1PricingState()
+		var l1PricePerUnit *big.Int
+		if evm.Config.Tracer != nil {
+			l1PricePerUnit, err = l1p.PricePerUnit()
+			state.Restrict(err)
+		}
 		perBatchGas, err := l1p.PerBatchGasCost()
 		if err != nil {
 			log.Warn("L1Pricing PerBatchGas failed", "err", err)
 		}
 		gasSpent := arbmath.SaturatingAdd(perBatchGas, arbmath.SaturatingCast[int64](batchDataGas))
 		weiSpent := arbmath.BigMulByUint(l1BaseFeeWei, arbmath.SaturatingUCast[uint64](gasSpent))
+		// the poster spent the reported base fee, but its costs are capped against the chain's smoothed one
+		capBaseFeeWei := l1BaseFeeWei
+		if state.ArbOSVersion() >= arbosState.ArbosVersion_L1BaseFeeSmoothing {
+			capBaseFeeWei, err = l1p.SmoothBaseFee(l1BaseFeeWei)
+			state.Restrict(err)
+		}
 		err = l1p.UpdateForBatchPosterSpending(
 			evm.StateDB,
 			evm,
@@ -114,12 +186,20 @@ func ApplyInternalTxUpdate(tx *types.ArbitrumInternalTx, state *arbosState.Arbos
 			evm.Context.Time,
 			batchPosterAddress,
 			weiSpent,
-			l1BaseFeeWei,
+			capBaseFeeWei,
 			util.TracingDuringEVM,
 		)
 		if err != nil {
 			log.Warn("L1Pricing UpdateForSequencerSpending failed", "err", err)
 		}
+		if evm.Config.Tracer != nil {
+			nextL1PricePerUnit, err := l1p.PricePerUnit()
+			state.Restrict(err)
+			batchNumber := util.SafeMapGet[uint64](inputs, "batchNumber")
+			traceInternalTxBatchPostingReport(
+				evm, batchTimestamp, batchPosterAddress, batchNumber, batchDataGas, l1BaseFeeWei, weiSpent, l1PricePerUnit, nextL1PricePerUnit,
+			)
+		}
 		return nil
 	default:
 		return fmt.Errorf("unknown internal tx method selector: %v", hex.EncodeToString(tx.Data[:4]))
@@ -0,0 +1,89 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbos
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/offchainlabs/nitro/arbos/util"
+)
+
+// Traces of internal txs carry a log from the ArbOS address with the decoded inputs of the internal tx and the
+// pricing it changed, so that explorers can show why the basefees changed at a block. These logs are only seen
+// by tracers, and never appear in receipts. Their data is ABI encoded after the signatures below.
+const (
+	InternalTxStartBlockTraceSignature         = "StartBlock(uint256,uint64,uint64,uint64,uint256,uint256)"
+	InternalTxBatchPostingReportTraceSignature = "BatchPostingReport(uint256,address,uint64,uint64,uint256,uint256,uint256,uint256)"
+)
+
+var (
+	InternalTxStartBlockTraceID         = crypto.Keccak256Hash([]byte(InternalTxStartBlockTraceSignature))
+	InternalTxBatchPostingReportTraceID = crypto.Keccak256Hash([]byte(InternalTxBatchPostingReportTraceSignature))
+)
+
+func mustNewABIType(name string) abi.Type {
+	t, err := abi.NewType(name, "", nil)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+var (
+	uint256ABIType = mustNewABIType("uint256")
+	uint64ABIType  = mustNewABIType("uint64")
+	addressABIType = mustNewABIType("address")
+
+	// l1BaseFee, l1BlockNumber, l2BlockNumber, timePassed, l2BaseFee, nextL2BaseFee
+	internalTxStartBlockTraceArgs = abi.Arguments{
+		{Type: uint256ABIType}, {Type: uint64ABIType}, {Type: uint64ABIType}, {Type: uint64ABIType},
+		{Type: uint256ABIType}, {Type: uint256ABIType},
+	}
+	// batchTimestamp, batchPosterAddress, batchNumber, batchDataGas, l1BaseFeeWei, weiSpent, l1PricePerUnit, nextL1PricePerUnit
+	internalTxBatchPostingReportTraceArgs = abi.Arguments{
+		{Type: uint256ABIType}, {Type: addressABIType}, {Type: uint64ABIType}, {Type: uint64ABIType},
+		{Type: uint256ABIType}, {Type: uint256ABIType}, {Type: uint256ABIType}, {Type: uint256ABIType},
+	}
+)
+
+func traceInternalTx(evm *vm.EVM, id common.Hash, arguments abi.Arguments, values ...interface{}) {
+	tracingInfo := util.NewTracingInfo(evm, types.ArbosAddress, types.ArbosAddress, util.TracingDuringEVM)
+	if tracingInfo == nil {
+		return
+	}
+	// The tx processor starts the tracer's top level call before applying an internal tx, so the log always
+	// belongs in a call frame, even where the EVM's depth doesn't count it and RecordLog would skip it.
+	tracingInfo.Depth = max(tracingInfo.Depth, 1)
+	data, err := arguments.Pack(values...)
+	if err != nil {
+		log.Error("failed to encode internal tx trace", "id", id, "err", err)
+		return
+	}
+	tracingInfo.CaptureLog(types.ArbosAddress, []common.Hash{id}, data)
+}
+
+func traceInternalTxStartBlock(evm *vm.EVM, l1BaseFee *big.Int, l1BlockNumber, l2BlockNumber, timePassed uint64, l2BaseFee, nextL2BaseFee *big.Int) {
+	traceInternalTx(evm, InternalTxStartBlockTraceID, internalTxStartBlockTraceArgs,
+		l1BaseFee, l1BlockNumber, l2BlockNumber, timePassed, l2BaseFee, nextL2BaseFee,
+	)
+}
+
+func traceInternalTxBatchPostingReport(
+	evm *vm.EVM,
+	batchTimestamp *big.Int,
+	batchPoster common.Address,
+	batchNumber, batchDataGas uint64,
+	l1BaseFeeWei, weiSpent, l1PricePerUnit, nextL1PricePerUnit *big.Int,
+) {
+	traceInternalTx(evm, InternalTxBatchPostingReportTraceID, internalTxBatchPostingReportTraceArgs,
+		batchTimestamp, batchPoster, batchNumber, batchDataGas, l1BaseFeeWei, weiSpent, l1PricePerUnit, nextL1PricePerUnit,
+	)
+}
@@ -0,0 +1,65 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbos
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+// stateCapturingTracer only implements the hook internal tx traces use
+type stateCapturingTracer struct {
+	vm.EVMLogger
+	ops    []vm.OpCode
+	depths []int
+	scopes []*vm.ScopeContext
+}
+
+func (t *stateCapturingTracer) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+	t.ops = append(t.ops, op)
+	t.depths = append(t.depths, depth)
+	t.scopes = append(t.scopes, scope)
+}
+
+func TestInternalTxTrace(t *testing.T) {
+	tracer := &stateCapturingTracer{}
+	evm := newMockEVMForTesting()
+	evm.Config.Tracer = tracer
+
+	// internal txs are applied at the EVM's top level, which RecordLog alone would skip
+	if evm.Depth() != 0 {
+		Fail(t, "expected the mock EVM at the top level, got depth", evm.Depth())
+	}
+	l1BaseFee, l2BaseFee, nextL2BaseFee := big.NewInt(30), big.NewInt(100), big.NewInt(110)
+	traceInternalTxStartBlock(evm, l1BaseFee, 7, 8, 9, l2BaseFee, nextL2BaseFee)
+
+	if len(tracer.ops) != 1 || tracer.ops[0] != vm.LOG1 || tracer.depths[0] != 1 {
+		Fail(t, "expected a LOG1 in the top level call, got", tracer.ops, tracer.depths)
+	}
+	scope := tracer.scopes[0]
+	if scope.Contract.Address() != types.ArbosAddress {
+		Fail(t, "trace recorded for the wrong address", scope.Contract.Address())
+	}
+	if common.Hash(scope.Stack.Back(2).Bytes32()) != InternalTxStartBlockTraceID {
+		Fail(t, "wrong trace topic", scope.Stack.Back(2))
+	}
+	size := scope.Stack.Back(1).Uint64()
+	values, err := internalTxStartBlockTraceArgs.Unpack(scope.Memory.Data()[:size])
+	Require(t, err)
+	if values[0].(*big.Int).Cmp(l1BaseFee) != 0 || values[1].(uint64) != 7 || values[2].(uint64) != 8 || values[3].(uint64) != 9 ||
+		values[4].(*big.Int).Cmp(l2BaseFee) != 0 || values[5].(*big.Int).Cmp(nextL2BaseFee) != 0 {
+		Fail(t, "wrong decoded trace", values)
+	}
+
+	// nothing is traced without a tracer
+	evm.Config.Tracer = nil
+	traceInternalTxStartBlock(evm, l1BaseFee, 7, 8, 9, l2BaseFee, nextL2BaseFee)
+	if len(tracer.ops) != 1 {
+		Fail(t, "traced without a tracer")
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+// Package l1blockmap records which L2 block was the first to start with each L1 block number, so that the L1 block
+// number of any L2 block, and the L2 blocks of any L1 block number, can be looked up from state alone.
+package l1blockmap
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/nitro/arbos/storage"
+)
+
+const sizeOffset uint64 = 0
+
+var entriesKey = []byte{0}
+
+var ErrNotRecorded = errors.New("block was sequenced before ArbOS recorded L1 block numbers")
+
+// Entry says that L2 block L2BlockNumber was the first to start with L1 block number L1BlockNumber.
+type Entry struct {
+	L1BlockNumber uint64
+	L2BlockNumber uint64
+}
+
+func (e Entry) encode() common.Hash {
+	var slot common.Hash
+	binary.BigEndian.PutUint64(slot[16:24], e.L1BlockNumber)
+	binary.BigEndian.PutUint64(slot[24:32], e.L2BlockNumber)
+	return slot
+}
+
+func decodeEntry(slot common.Hash) Entry {
+	return Entry{
+		L1BlockNumber: binary.BigEndian.Uint64(slot[16:24]),
+		L2BlockNumber: binary.BigEndian.Uint64(slot[24:32]),
+	}
+}
+
+// L1BlockMap is an append-only list of entries, one per L1 block number an L2 block started with. Both block numbers
+// increase along the list, so either can be looked up with a binary search over storage. Entry i is kept at slot i.
+type L1BlockMap struct {
+	size    storage.StorageBackedUint64
+	entries *storage.Storage
+}
+
+func Open(sto *storage.Storage) *L1BlockMap {
+	return &L1BlockMap{
+		size:    sto.OpenStorageBackedUint64(sizeOffset),
+		entries: sto.OpenSubStorage(entriesKey),
+	}
+}
+
+// Size returns the number of entries recorded.
+func (m *L1BlockMap) Size() (uint64, error) {
+	return m.size.Get()
+}
+
+func (m *L1BlockMap) entry(index uint64) (Entry, error) {
+	slot, err := m.entries.GetByUint64(index)
+	return decodeEntry(slot), err
+}
+
+// Record notes that the L2 block is the first to start with the L1 block number. Block numbers that don't increase
+// both L1 and L2 block numbers are ignored.
+func (m *L1BlockMap) Record(l1BlockNumber uint64, l2BlockNumber uint64) error {
+	size, err := m.size.Get()
+	if err != nil {
+		return err
+	}
+	if size > 0 {
+		last, err := m.entry(size - 1)
+		if err != nil {
+			return err
+		}
+		if l1BlockNumber <= last.L1BlockNumber || l2BlockNumber <= last.L2BlockNumber {
+			return nil
+		}
+	}
+	entry := Entry{L1BlockNumber: l1BlockNumber, L2BlockNumber: l2BlockNumber}
+	if err := m.entries.SetByUint64(size, entry.encode()); err != nil {
+		return err
+	}
+	return m.size.Set(size + 1)
+}
+
+// search returns the index of the first entry for which after is true, or the size if there's none.
+// after must be false for some prefix of the entries and true for the rest.
+func (m *L1BlockMap) search(after func(Entry) bool) (uint64, error) {
+	size, err := m.size.Get()
+	if err != nil {
+		return 0, err
+	}
+	low, high := uint64(0), size
+	for low < high {
+		mid := low + (high-low)/2
+		entry, err := m.entry(mid)
+		if err != nil {
+			return 0, err
+		}
+		if after(entry) {
+			high = mid
+		} else {
+			low = mid + 1
+		}
+	}
+	return low, nil
+}
+
+// L1BlockNumber returns the L1 block number the L2 block started with, which the caller must know exists.
+func (m *L1BlockMap) L1BlockNumber(l2BlockNumber uint64) (uint64, error) {
+	index, err := m.search(func(entry Entry) bool {
+		return entry.L2BlockNumber > l2BlockNumber
+	})
+	if err != nil {
+		return 0, err
+	}
+	if index == 0 {
+		return 0, ErrNotRecorded
+	}
+	entry, err := m.entry(index - 1)
+	return entry.L1BlockNumber, err
+}
+
+// FirstL2Block returns the first L2 block that started with the given L1 block number or a later one, along with
+// the L1 block number it started with, which is the nearest match when no L2 block started with the one given.
+// It returns false if no L2 block has yet.
+func (m *L1BlockMap) FirstL2Block(l1BlockNumber uint64) (Entry, bool, error) {
+	index, err := m.search(func(entry Entry) bool {
+		return entry.L1BlockNumber >= l1BlockNumber
+	})
+	if err != nil {
+		return Entry{}, false, err
+	}
+	size, err := m.size.Get()
+	if err != nil || index >= size {
+		return Entry{}, false, err
+	}
+	entry, err := m.entry(index)
+	if err != nil {
+		return Entry{}, false, err
+	}
+	if index == 0 && entry.L1BlockNumber > l1BlockNumber {
+		// L2 blocks before the first entry may have started with the L1 block number
+		return Entry{}, false, ErrNotRecorded
+	}
+	return entry, true, nil
+}
@@ -0,0 +1,70 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package l1blockmap
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/offchainlabs/nitro/arbos/burn"
+	"github.com/offchainlabs/nitro/arbos/storage"
+	"github.com/offchainlabs/nitro/util/testhelpers"
+)
+
+func TestL1BlockMap(t *testing.T) {
+	m := Open(storage.NewMemoryBacked(burn.NewSystemBurner(nil, false)))
+
+	if _, err := m.L1BlockNumber(10); !errors.Is(err, ErrNotRecorded) {
+		Fail(t, "looked up an L1 block number in an empty map", err)
+	}
+	if _, found, err := m.FirstL2Block(100); err != nil || found {
+		Fail(t, "found an L2 block in an empty map", found, err)
+	}
+
+	// L2 blocks 10 through 14 start with L1 block 100, 15 through 19 with 101, and 20 onwards with 105
+	Require(t, m.Record(100, 10))
+	Require(t, m.Record(101, 15))
+	Require(t, m.Record(101, 17)) // not a new L1 block number
+	Require(t, m.Record(105, 20))
+	size, err := m.Size()
+	Require(t, err)
+	if size != 3 {
+		Fail(t, "wrong size", size)
+	}
+
+	for l2, l1 := range map[uint64]uint64{10: 100, 14: 100, 15: 101, 19: 101, 20: 105, 30: 105} {
+		number, err := m.L1BlockNumber(l2)
+		Require(t, err)
+		if number != l1 {
+			Fail(t, "wrong L1 block number for L2 block", l2, number, "instead of", l1)
+		}
+	}
+	if _, err := m.L1BlockNumber(9); !errors.Is(err, ErrNotRecorded) {
+		Fail(t, "looked up an L1 block number from before the map", err)
+	}
+
+	for l1, expected := range map[uint64]Entry{100: {100, 10}, 101: {101, 15}, 102: {105, 20}, 105: {105, 20}} {
+		entry, found, err := m.FirstL2Block(l1)
+		Require(t, err)
+		if !found || entry != expected {
+			Fail(t, "wrong L2 block for L1 block", l1, entry, found)
+		}
+	}
+	if _, found, err := m.FirstL2Block(106); err != nil || found {
+		Fail(t, "found an L2 block for a future L1 block", found, err)
+	}
+	if _, _, err := m.FirstL2Block(99); !errors.Is(err, ErrNotRecorded) {
+		Fail(t, "looked up an L2 block from before the map", err)
+	}
+}
+
+func Require(t *testing.T, err error, printables ...interface{}) {
+	t.Helper()
+	testhelpers.RequireImpl(t, err, printables...)
+}
+
+func Fail(t *testing.T, printables ...interface{}) {
+	t.Helper()
+	testhelpers.FailImpl(t, printables...)
+}
@@ -14,7 +14,16 @@ import (
 	"github.com/offchainlabs/nitro/util/arbmath"
 )
 
-const totalFundsDueOffset = 0
+const (
+	totalFundsDueOffset = iota
+	settlementCursorOffset
+)
+
+// MaxPostersSettledPerBlock bounds how many batch posters are paid what they're due at the start of each block.
+const MaxPostersSettledPerBlock = 8
+
+// ArbosVersionPosterSettlement is the ArbOS version from which batch posters are settled at the start of each block.
+const ArbosVersionPosterSettlement = 33 // arbosState.ArbosVersion_FirstCustom
 
 var (
 	PosterAddrsKey = []byte{0}
@@ -26,15 +35,17 @@ var (
 
 // BatchPostersTable is the layout of storage in the table
 type BatchPostersTable struct {
-	posterAddrs   *addressSet.AddressSet
-	posterInfo    *storage.Storage
-	totalFundsDue storage.StorageBackedBigInt
+	posterAddrs      *addressSet.AddressSet
+	posterInfo       *storage.Storage
+	totalFundsDue    storage.StorageBackedBigInt
+	settlementCursor storage.StorageBackedUint64 // index of the next poster to settle
 }
 
 type BatchPosterState struct {
-	fundsDue     storage.StorageBackedBigInt
-	payTo        storage.StorageBackedAddress
-	postersTable *BatchPostersTable
+	fundsDue             storage.StorageBackedBigInt
+	payTo                storage.StorageBackedAddress
+	reimbursementCapBips storage.StorageBackedUint64 // zero for no cap beyond the amortized cost cap
+	postersTable         *BatchPostersTable
 }
 
 func InitializeBatchPostersTable(storage *storage.Storage) error {
@@ -47,9 +58,10 @@ func InitializeBatchPostersTable(storage *storage.Storage) error {
 
 func OpenBatchPostersTable(storage *storage.Storage) *BatchPostersTable {
 	return &BatchPostersTable{
-		posterAddrs:   addressSet.OpenAddressSet(storage.OpenCachedSubStorage(PosterAddrsKey)),
-		posterInfo:    storage.OpenSubStorage(PosterInfoKey),
-		totalFundsDue: storage.OpenStorageBackedBigInt(totalFundsDueOffset),
+		posterAddrs:      addressSet.OpenAddressSet(storage.OpenCachedSubStorage(PosterAddrsKey)),
+		posterInfo:       storage.OpenSubStorage(PosterInfoKey),
+		totalFundsDue:    storage.OpenStorageBackedBigInt(totalFundsDueOffset),
+		settlementCursor: storage.OpenStorageBackedUint64(settlementCursorOffset),
 	}
 }
 
@@ -70,9 +82,10 @@ func (bpt *BatchPostersTable) OpenPoster(poster common.Address, createIfNotExist
 func (bpt *BatchPostersTable) internalOpen(poster common.Address) *BatchPosterState {
 	bpStorage := bpt.posterInfo.OpenSubStorage(poster.Bytes())
 	return &BatchPosterState{
-		fundsDue:     bpStorage.OpenStorageBackedBigInt(0),
-		payTo:        bpStorage.OpenStorageBackedAddress(1),
-		postersTable: bpt,
+		fundsDue:             bpStorage.OpenStorageBackedBigInt(0),
+		payTo:                bpStorage.OpenStorageBackedAddress(1),
+		reimbursementCapBips: bpStorage.OpenStorageBackedUint64(2),
+		postersTable:         bpt,
 	}
 }
 
@@ -140,6 +153,47 @@ func (bps *BatchPosterState) SetPayTo(addr common.Address) error {
 	return bps.payTo.Set(addr)
 }
 
+// ReimbursementCapBips is the most the poster is reimbursed per batch, in bips of the L1 basefee
+// times the calldata units the batch is allocated. Zero means no per-poster cap.
+func (bps *BatchPosterState) ReimbursementCapBips() (uint64, error) {
+	return bps.reimbursementCapBips.Get()
+}
+
+func (bps *BatchPosterState) SetReimbursementCapBips(bips uint64) error {
+	return bps.reimbursementCapBips.Set(bips)
+}
+
+// BatchPosterInfo summarizes a batch poster's accounting.
+type BatchPosterInfo struct {
+	Poster               common.Address
+	PayTo                common.Address
+	FundsDue             *big.Int
+	ReimbursementCapBips uint64
+}
+
+// AllPosterInfo returns the accounting of up to maxNumToGet batch posters, in the order AllPosters lists them.
+func (bpt *BatchPostersTable) AllPosterInfo(maxNumToGet uint64) ([]BatchPosterInfo, error) {
+	posters, err := bpt.AllPosters(maxNumToGet)
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]BatchPosterInfo, len(posters))
+	for i, poster := range posters {
+		posterState := bpt.internalOpen(poster)
+		infos[i].Poster = poster
+		if infos[i].PayTo, err = posterState.PayTo(); err != nil {
+			return nil, err
+		}
+		if infos[i].FundsDue, err = posterState.FundsDue(); err != nil {
+			return nil, err
+		}
+		if infos[i].ReimbursementCapBips, err = posterState.ReimbursementCapBips(); err != nil {
+			return nil, err
+		}
+	}
+	return infos, nil
+}
+
 type FundsDueItem struct {
 	dueTo   common.Address
 	balance *big.Int
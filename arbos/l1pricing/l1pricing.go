@@ -45,10 +45,14 @@ type L1PricingState struct {
 	perBatchGasCost      storage.StorageBackedInt64   // introduced in ArbOS version 3
 	amortizedCostCapBips storage.StorageBackedUint64  // in basis points; introduced in ArbOS version 3
 	l1FeesAvailable      storage.StorageBackedBigUint
+	calldataPriceBips    storage.StorageBackedUint64  // in basis points of the poster's costs; zero charges them alone
+	baseFeeWindow        storage.StorageBackedUint64  // reports smoothed over; zero or one uses each report's base fee
+	smoothedBaseFee      storage.StorageBackedBigUint // zero before the first smoothed report
 }
 
 var (
 	BatchPosterTableKey      = []byte{0}
+	RewardSplitsKey          = []byte{1}
 	BatchPosterAddress       = common.HexToAddress("0xA4B000000000000000000073657175656e636572")
 	BatchPosterPayToAddress  = BatchPosterAddress
 	L1PricerFundsPoolAddress = common.HexToAddress("0xA4B00000000000000000000000000000000000f6")
@@ -69,6 +73,9 @@ const (
 	perBatchGasCostOffset
 	amortizedCostCapBipsOffset
 	l1FeesAvailableOffset
+	calldataPriceBipsOffset
+	baseFeeWindowOffset
+	smoothedBaseFeeOffset
 )
 
 const (
@@ -131,6 +138,9 @@ func OpenL1PricingState(sto *storage.Storage) *L1PricingState {
 		sto.OpenStorageBackedInt64(perBatchGasCostOffset),
 		sto.OpenStorageBackedUint64(amortizedCostCapBipsOffset),
 		sto.OpenStorageBackedBigUint(l1FeesAvailableOffset),
+		sto.OpenStorageBackedUint64(calldataPriceBipsOffset),
+		sto.OpenStorageBackedUint64(baseFeeWindowOffset),
+		sto.OpenStorageBackedBigUint(smoothedBaseFeeOffset),
 	}
 }
 
@@ -255,6 +265,74 @@ func (ps *L1PricingState) SetAmortizedCostCapBips(cap uint64) error {
 	return ps.amortizedCostCapBips.Set(cap)
 }
 
+// CalldataPriceBips returns what txs are charged for their calldata, in bips of the poster's costs for it.
+func (ps *L1PricingState) CalldataPriceBips() (uint64, error) {
+	bips, err := ps.calldataPriceBips.Get()
+	if bips == 0 {
+		// the multiplier was never set
+		return uint64(am.OneInBips), err
+	}
+	return bips, err
+}
+
+// SetCalldataPriceBips sets what txs are charged for their calldata, in bips of the poster's costs for it.
+// It can't be below the poster's costs, as the L1 pricer would raise the price per unit to make up for it.
+func (ps *L1PricingState) SetCalldataPriceBips(bips uint64) error {
+	if bips < uint64(am.OneInBips) {
+		return errors.New("calldata price below the poster's costs")
+	}
+	return ps.calldataPriceBips.Set(bips)
+}
+
+// BaseFeeWindow returns how many batch posting reports the parent chain base fees reported in them are smoothed
+// over, for chains settling to parent chains whose fees spike. Zero or one uses each report's base fee as is.
+func (ps *L1PricingState) BaseFeeWindow() (uint64, error) {
+	return ps.baseFeeWindow.Get()
+}
+
+// SetBaseFeeWindow sets the window, and restarts the average from the next report.
+func (ps *L1PricingState) SetBaseFeeWindow(window uint64) error {
+	if err := ps.smoothedBaseFee.SetChecked(common.Big0); err != nil {
+		return err
+	}
+	return ps.baseFeeWindow.Set(window)
+}
+
+// SmoothedBaseFee returns the average of the base fees reported so far, or zero if they aren't smoothed.
+func (ps *L1PricingState) SmoothedBaseFee() (*big.Int, error) {
+	return ps.smoothedBaseFee.Get()
+}
+
+// SmoothBaseFee folds the base fee of a batch posting report into the average, and returns the base fee the report
+// should be treated as having.
+func (ps *L1PricingState) SmoothBaseFee(l1BaseFee *big.Int) (*big.Int, error) {
+	window, err := ps.BaseFeeWindow()
+	if err != nil || window <= 1 {
+		return l1BaseFee, err
+	}
+	average, err := ps.SmoothedBaseFee()
+	if err != nil {
+		return nil, err
+	}
+	if average.Sign() == 0 {
+		average = l1BaseFee
+	} else {
+		average = am.BigEMA(average, l1BaseFee, window)
+	}
+	return average, ps.smoothedBaseFee.SetSaturatingWithWarning(average, "smoothed parent chain base fee")
+}
+
+// CalldataSurcharge returns what a tx is charged for its calldata above the poster's costs for it.
+// The surcharge is paid to the network rather than the poster, so it isn't undone by the L1 pricer.
+func (ps *L1PricingState) CalldataSurcharge(posterCost *big.Int) (*big.Int, error) {
+	bips, err := ps.CalldataPriceBips()
+	if err != nil {
+		return nil, err
+	}
+	surcharge := am.BigMulByUint(posterCost, bips-uint64(am.OneInBips))
+	return am.BigDivByUint(surcharge, uint64(am.OneInBips)), nil
+}
+
 func (ps *L1PricingState) L1FeesAvailable() (*big.Int, error) {
 	return ps.l1FeesAvailable.Get()
 }
@@ -299,6 +377,63 @@ func (ps *L1PricingState) TransferFromL1FeesAvailable(
 	return updated, nil
 }
 
+// SettleBatchPosters pays batch posters what they're due from the available L1 fees, visiting up to maxPosters
+// posters round-robin so that every poster is eventually settled however many there are.
+// It returns the number of posters paid.
+func (ps *L1PricingState) SettleBatchPosters(maxPosters uint64, evm *vm.EVM, scenario util.TracingScenario) (uint64, error) {
+	table := ps.BatchPosterTable()
+	totalFundsDue, err := table.TotalFundsDue()
+	if err != nil || totalFundsDue.Sign() <= 0 {
+		return 0, err
+	}
+	available, err := ps.L1FeesAvailable()
+	if err != nil || available.Sign() <= 0 {
+		return 0, err
+	}
+	numPosters, err := table.posterAddrs.Size()
+	if err != nil || numPosters == 0 {
+		return 0, err
+	}
+	cursor, err := table.settlementCursor.Get()
+	if err != nil {
+		return 0, err
+	}
+	visits := am.MinInt(maxPosters, numPosters)
+	paid := uint64(0)
+	visited := uint64(0)
+	for ; visited < visits && available.Sign() > 0; visited++ {
+		poster, err := table.posterAddrs.MemberAt((cursor + visited) % numPosters)
+		if err != nil {
+			return paid, err
+		}
+		posterState := table.internalOpen(poster)
+		due, err := posterState.FundsDue()
+		if err != nil {
+			return paid, err
+		}
+		if due.Sign() <= 0 {
+			continue
+		}
+		payment := due
+		if am.BigLessThan(available, payment) {
+			payment = available
+		}
+		payTo, err := posterState.PayTo()
+		if err != nil {
+			return paid, err
+		}
+		available, err = ps.TransferFromL1FeesAvailable(payTo, payment, evm, scenario, "batchPosterRefund")
+		if err != nil {
+			return paid, err
+		}
+		if err := posterState.SetFundsDue(am.BigSub(due, payment)); err != nil {
+			return paid, err
+		}
+		paid++
+	}
+	return paid, table.settlementCursor.Set((cursor + visited) % numPosters)
+}
+
 // UpdateForBatchPosterSpending updates the pricing model based on a payment by a batch poster
 func (ps *L1PricingState) UpdateForBatchPosterSpending(
 	statedb vm.StateDB,
@@ -378,6 +513,21 @@ func (ps *L1PricingState) UpdateForBatchPosterSpending(
 		}
 	}
 
+	// impose the poster's own cap, which is only ever set from ArbOS version ArbosVersionPosterSettlement
+	posterCapBips, err := posterState.ReimbursementCapBips()
+	if err != nil {
+		return err
+	}
+	if posterCapBips != 0 {
+		weiSpentCap := am.BigMulByBips(
+			am.BigMulByUint(l1Basefee, unitsAllocated),
+			am.SaturatingCastToBips(posterCapBips),
+		)
+		if am.BigLessThan(weiSpentCap, weiSpent) {
+			weiSpent = weiSpentCap
+		}
+	}
+
 	dueToPoster, err := posterState.FundsDue()
 	if err != nil {
 		return err
@@ -404,13 +554,7 @@ func (ps *L1PricingState) UpdateForBatchPosterSpending(
 	if err := ps.SetFundsDueForRewards(fundsDueForRewards); err != nil {
 		return err
 	}
-	payRewardsTo, err := ps.PayRewardsTo()
-	if err != nil {
-		return err
-	}
-	l1FeesAvailable, err = ps.TransferFromL1FeesAvailable(
-		payRewardsTo, paymentForRewards, evm, scenario, "batchPosterReward",
-	)
+	l1FeesAvailable, err = ps.payRewards(paymentForRewards, evm, scenario)
 	if err != nil {
 		return err
 	}
@@ -509,11 +653,44 @@ func (ps *L1PricingState) getPosterUnitsWithoutCache(tx *types.Transaction, post
 		return 0
 	}
 
-	l1Bytes, err := byteCountAfterBrotliLevel(txBytes, brotliCompressionLevel)
+	units, err := CompressedPosterUnits(txBytes, brotliCompressionLevel)
 	if err != nil {
 		panic(fmt.Sprintf("failed to compress tx: %v", err))
 	}
-	return l1Bytes * params.TxDataNonZeroGasEIP2028
+	return units
+}
+
+// CompressedPosterUnits returns the calldata units ArbOS charges for posting the bytes of a transaction:
+// their size after brotli compression at the chain's level, priced as nonzero calldata bytes.
+func CompressedPosterUnits(txBytes []byte, brotliCompressionLevel uint64) (uint64, error) {
+	l1Bytes, err := byteCountAfterBrotliLevel(txBytes, brotliCompressionLevel)
+	if err != nil {
+		return 0, err
+	}
+	return l1Bytes * params.TxDataNonZeroGasEIP2028, nil
+}
+
+// EstimateCalldataPosterUnits estimates the calldata units ArbOS will charge for a transaction calling a contract
+// with the given calldata, before its other fields are known. Like gas estimation, it sizes a transaction with
+// random fields in their place and pads the result, so it shouldn't be below what the transaction is charged.
+func EstimateCalldataPosterUnits(data []byte, brotliCompressionLevel uint64) (uint64, error) {
+	tx := makeFakeTxForMessage(&core.Message{
+		To:        &randomTo,
+		Value:     common.Big0,
+		GasTipCap: common.Big0,
+		GasFeeCap: common.Big0,
+		Data:      data,
+		TxRunMode: core.MessageGasEstimationMode,
+	})
+	txBytes, err := tx.MarshalBinary()
+	if err != nil {
+		return 0, err
+	}
+	units, err := CompressedPosterUnits(txBytes, brotliCompressionLevel)
+	if err != nil {
+		return 0, err
+	}
+	return padEstimatedUnits(units), nil
 }
 
 // GetPosterInfo returns the poster cost and the calldata units for a transaction
@@ -540,6 +717,7 @@ var randomNonce = binary.BigEndian.Uint64(crypto.Keccak256([]byte("Nonce"))[:8])
 var randomGasTipCap = new(big.Int).SetBytes(crypto.Keccak256([]byte("GasTipCap"))[:4])
 var randomGasFeeCap = new(big.Int).SetBytes(crypto.Keccak256([]byte("GasFeeCap"))[:4])
 var RandomGas = uint64(binary.BigEndian.Uint32(crypto.Keccak256([]byte("Gas"))[:4]))
+var randomTo = common.BytesToAddress(crypto.Keccak256([]byte("To")))
 var randV = arbmath.BigMulByUint(params.ArbitrumOneChainConfig().ChainID, 3)
 var randR = crypto.Keccak256Hash([]byte("R")).Big()
 var randS = crypto.Keccak256Hash([]byte("S")).Big()
@@ -588,12 +766,15 @@ func (ps *L1PricingState) PosterDataCost(message *core.Message, poster common.Ad
 	// Otherwise, we don't have an underlying transaction, so we're likely in gas estimation.
 	// We'll instead make a fake tx from the message info we do have, and then pad our cost a bit to be safe.
 	tx = makeFakeTxForMessage(message)
-	units := ps.getPosterUnitsWithoutCache(tx, poster, brotliCompressionLevel)
-	units = arbmath.UintMulByBips(units+estimationPaddingUnits, arbmath.OneInBips+estimationPaddingBasisPoints)
+	units := padEstimatedUnits(ps.getPosterUnitsWithoutCache(tx, poster, brotliCompressionLevel))
 	pricePerUnit, _ := ps.PricePerUnit()
 	return am.BigMulByUint(pricePerUnit, units), units
 }
 
+func padEstimatedUnits(units uint64) uint64 {
+	return arbmath.UintMulByBips(units+estimationPaddingUnits, arbmath.OneInBips+estimationPaddingBasisPoints)
+}
+
 func byteCountAfterBrotliLevel(input []byte, level uint64) (uint64, error) {
 	compressed, err := arbcompress.CompressLevel(input, level)
 	if err != nil {
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/offchainlabs/nitro/arbos/burn"
 	"github.com/offchainlabs/nitro/arbos/storage"
@@ -32,3 +33,85 @@ func TestL1PriceUpdate(t *testing.T) {
 		Fail(t)
 	}
 }
+
+func TestEstimateCalldataPosterUnits(t *testing.T) {
+	compressible := make([]byte, 4096)
+	incompressible := crypto.Keccak256(compressible)
+	for len(incompressible) < len(compressible) {
+		incompressible = append(incompressible, crypto.Keccak256(incompressible)...)
+	}
+	for _, level := range []uint64{0, 1} {
+		compressibleUnits, err := CompressedPosterUnits(compressible, level)
+		Require(t, err)
+		incompressibleUnits, err := CompressedPosterUnits(incompressible, level)
+		Require(t, err)
+		if compressibleUnits >= incompressibleUnits || incompressibleUnits < uint64(len(incompressible))*params.TxDataNonZeroGasEIP2028 {
+			Fail(t, "wrong compressed units", level, compressibleUnits, incompressibleUnits)
+		}
+
+		// the estimate covers the transaction around the calldata and is padded
+		estimate, err := EstimateCalldataPosterUnits(incompressible, level)
+		Require(t, err)
+		if estimate <= incompressibleUnits {
+			Fail(t, "estimate", estimate, "isn't above the calldata's own units", incompressibleUnits)
+		}
+		empty, err := EstimateCalldataPosterUnits(nil, level)
+		Require(t, err)
+		if empty == 0 || empty >= estimate {
+			Fail(t, "wrong estimate for empty calldata", empty)
+		}
+	}
+}
+
+func TestCalldataSurcharge(t *testing.T) {
+	sto := storage.NewMemoryBacked(burn.NewSystemBurner(nil, false))
+	Require(t, InitializeL1PricingState(sto, common.Address{}, big.NewInt(params.GWei)))
+	ps := OpenL1PricingState(sto)
+	posterCost := big.NewInt(1000)
+
+	// without a multiplier, txs only pay the poster's costs
+	surcharge, err := ps.CalldataSurcharge(posterCost)
+	Require(t, err)
+	if surcharge.Sign() != 0 {
+		Fail(t, "surcharge without a multiplier", surcharge)
+	}
+
+	if ps.SetCalldataPriceBips(9999) == nil {
+		Fail(t, "set a calldata price below the poster's costs")
+	}
+	Require(t, ps.SetCalldataPriceBips(15000))
+	surcharge, err = ps.CalldataSurcharge(posterCost)
+	Require(t, err)
+	if surcharge.Cmp(big.NewInt(500)) != 0 {
+		Fail(t, "wrong surcharge", surcharge)
+	}
+}
+
+func TestBaseFeeSmoothing(t *testing.T) {
+	sto := storage.NewMemoryBacked(burn.NewSystemBurner(nil, false))
+	Require(t, InitializeL1PricingState(sto, common.Address{}, big.NewInt(params.GWei)))
+	ps := OpenL1PricingState(sto)
+	expect := func(reported int64, smoothed int64) {
+		t.Helper()
+		baseFee, err := ps.SmoothBaseFee(big.NewInt(reported))
+		Require(t, err)
+		if baseFee.Cmp(big.NewInt(smoothed)) != 0 {
+			Fail(t, "reported base fee", reported, "smoothed to", baseFee, "expected", smoothed)
+		}
+	}
+
+	// reports are used as is until the chain owner sets a window
+	expect(100, 100)
+	expect(1000, 1000)
+
+	// the first smoothed report starts the average, and the next move it by 2/(window+1)
+	Require(t, ps.SetBaseFeeWindow(3))
+	expect(100, 100)
+	expect(1000, 550)
+	expect(1000, 775)
+
+	// changing the window restarts the average
+	Require(t, ps.SetBaseFeeWindow(9))
+	expect(200, 200)
+	expect(1200, 400)
+}
@@ -0,0 +1,146 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package l1pricing
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+
+	"github.com/offchainlabs/nitro/arbos/storage"
+	"github.com/offchainlabs/nitro/arbos/util"
+	am "github.com/offchainlabs/nitro/util/arbmath"
+)
+
+// MaxRewardRecipients bounds the transfers made whenever batch poster rewards are paid out.
+const MaxRewardRecipients = 16
+
+var (
+	ErrInvalidRewardSplit = errors.New("reward recipients must be distinct, nonzero, and have weights summing to 10000 bips")
+)
+
+// RewardSplits divides the batch poster reward among several recipients by basis-point weights.
+// When no recipients are configured, the whole reward goes to the PayRewardsTo address.
+// the number of recipients is stored at position 0
+// recipient i's address is stored at position 2i+1 and its weight in bips at 2i+2
+type RewardSplits struct {
+	storage *storage.Storage
+	count   storage.StorageBackedUint64
+}
+
+func openRewardSplits(sto *storage.Storage) *RewardSplits {
+	return &RewardSplits{
+		storage: sto,
+		count:   sto.OpenStorageBackedUint64(0),
+	}
+}
+
+func (ps *L1PricingState) RewardSplits() *RewardSplits {
+	return openRewardSplits(ps.storage.OpenCachedSubStorage(RewardSplitsKey))
+}
+
+// Recipients returns the configured recipients and their weights in bips.
+func (rs *RewardSplits) Recipients() ([]common.Address, []uint64, error) {
+	count, err := rs.count.Get()
+	if err != nil {
+		return nil, nil, err
+	}
+	recipients := make([]common.Address, 0, count)
+	weights := make([]uint64, 0, count)
+	for i := uint64(0); i < count; i++ {
+		sba := rs.storage.OpenStorageBackedAddress(2*i + 1)
+		recipient, err := sba.Get()
+		if err != nil {
+			return nil, nil, err
+		}
+		weight, err := rs.storage.GetUint64ByUint64(2*i + 2)
+		if err != nil {
+			return nil, nil, err
+		}
+		recipients = append(recipients, recipient)
+		weights = append(weights, weight)
+	}
+	return recipients, weights, nil
+}
+
+// SetRecipients replaces the configured recipients. Passing no recipients restores paying PayRewardsTo.
+func (rs *RewardSplits) SetRecipients(recipients []common.Address, weights []uint64) error {
+	if len(recipients) != len(weights) || len(recipients) > MaxRewardRecipients {
+		return ErrInvalidRewardSplit
+	}
+	if len(recipients) > 0 {
+		seen := make(map[common.Address]bool)
+		total := uint64(0)
+		for i, recipient := range recipients {
+			if recipient == (common.Address{}) || seen[recipient] || weights[i] == 0 || weights[i] > uint64(am.OneInBips) {
+				return ErrInvalidRewardSplit
+			}
+			seen[recipient] = true
+			total += weights[i]
+		}
+		if total != uint64(am.OneInBips) {
+			return ErrInvalidRewardSplit
+		}
+	}
+
+	oldCount, err := rs.count.Get()
+	if err != nil {
+		return err
+	}
+	for i := uint64(len(recipients)); i < oldCount; i++ {
+		if err := rs.storage.ClearByUint64(2*i + 1); err != nil {
+			return err
+		}
+		if err := rs.storage.ClearByUint64(2*i + 2); err != nil {
+			return err
+		}
+	}
+	for i, recipient := range recipients {
+		index := uint64(i)
+		sba := rs.storage.OpenStorageBackedAddress(2*index + 1)
+		if err := sba.Set(recipient); err != nil {
+			return err
+		}
+		if err := rs.storage.SetUint64ByUint64(2*index+2, weights[i]); err != nil {
+			return err
+		}
+	}
+	return rs.count.Set(uint64(len(recipients)))
+}
+
+// payRewards transfers the reward to the configured recipients, or to PayRewardsTo if there are none.
+// The last recipient receives any remainder left by rounding.
+func (ps *L1PricingState) payRewards(
+	payment *big.Int,
+	evm *vm.EVM,
+	scenario util.TracingScenario,
+) (*big.Int, error) {
+	recipients, weights, err := ps.RewardSplits().Recipients()
+	if err != nil {
+		return nil, err
+	}
+	if len(recipients) == 0 {
+		payRewardsTo, err := ps.PayRewardsTo()
+		if err != nil {
+			return nil, err
+		}
+		return ps.TransferFromL1FeesAvailable(payRewardsTo, payment, evm, scenario, "batchPosterReward")
+	}
+	var l1FeesAvailable *big.Int
+	remaining := new(big.Int).Set(payment)
+	for i, recipient := range recipients {
+		share := remaining
+		if i < len(recipients)-1 {
+			share = am.BigMulByBips(payment, am.SaturatingCastToBips(weights[i]))
+		}
+		remaining = am.BigSub(remaining, share)
+		l1FeesAvailable, err = ps.TransferFromL1FeesAvailable(recipient, share, evm, scenario, "batchPosterReward")
+		if err != nil {
+			return nil, err
+		}
+	}
+	return l1FeesAvailable, nil
+}
@@ -241,6 +241,49 @@ func TestUpdateTimeUpgradeBehavior(t *testing.T) {
 	))
 }
 
+func TestL1PricingRewardSplits(t *testing.T) {
+	evm := newMockEVMForTesting()
+	burner := burn.NewSystemBurner(nil, false)
+	arbosSt, err := arbosState.OpenArbosState(evm.StateDB, burner)
+	Require(t, err)
+
+	l1p := arbosSt.L1PricingState()
+	Require(t, l1p.SetPerUnitReward(10))
+	splits := l1p.RewardSplits()
+	treasury := common.Address{1, 3, 7}
+	operator := common.Address{2, 4, 8}
+	if splits.SetRecipients([]common.Address{treasury, operator}, []uint64{3000, 6000}) == nil {
+		Fail(t, "accepted weights that don't sum to 10000 bips")
+	}
+	if splits.SetRecipients([]common.Address{treasury, treasury}, []uint64{5000, 5000}) == nil {
+		Fail(t, "accepted duplicate recipients")
+	}
+	Require(t, splits.SetRecipients([]common.Address{treasury, operator}, []uint64{3333, 6667}))
+
+	funds := big.NewInt(1_000_000)
+	evm.StateDB.AddBalance(l1pricing.L1PricerFundsPoolAddress, uint256.MustFromBig(funds))
+	Require(t, l1p.SetL1FeesAvailable(funds))
+	Require(t, l1p.SetUnitsSinceUpdate(1001))
+	poster := l1pricing.BatchPosterAddress
+	Require(t, l1p.UpdateForBatchPosterSpending(
+		evm.StateDB, evm, arbosSt.ArbOSVersion(), 1, 1, poster, common.Big0, common.Big0, util.TracingDuringEVM,
+	))
+
+	// the full reward of 10010 wei is split, with rounding dust going to the last recipient
+	treasuryBalance := evm.StateDB.GetBalance(treasury).ToBig()
+	operatorBalance := evm.StateDB.GetBalance(operator).ToBig()
+	if treasuryBalance.Int64() != 3336 || operatorBalance.Int64() != 6674 {
+		Fail(t, "wrong reward split", treasuryBalance, operatorBalance)
+	}
+
+	Require(t, splits.SetRecipients(nil, nil))
+	recipients, weights, err := splits.Recipients()
+	Require(t, err)
+	if len(recipients) != 0 || len(weights) != 0 {
+		Fail(t, "reward recipients not cleared", recipients, weights)
+	}
+}
+
 func TestL1PriceEquilibrationUp(t *testing.T) {
 	_testL1PriceEquilibration(t, big.NewInt(1_000_000_000), big.NewInt(5_000_000_000))
 }
@@ -324,3 +367,63 @@ func newMockEVMForTesting() *vm.EVM {
 	evm.ProcessingHook = &TxProcessor{}
 	return evm
 }
+
+func TestSettleBatchPosters(t *testing.T) {
+	evm := newMockEVMForTesting()
+	burner := burn.NewSystemBurner(nil, false)
+	arbosSt, err := arbosState.OpenArbosState(evm.StateDB, burner)
+	Require(t, err)
+
+	l1p := arbosSt.L1PricingState()
+	table := l1p.BatchPosterTable()
+	posters := []common.Address{l1pricing.BatchPosterAddress, {1, 2, 3}, {4, 5, 6}}
+	payTo := []common.Address{l1pricing.BatchPosterPayToAddress, {7, 8, 9}, {10, 11, 12}}
+	for i := 1; i < len(posters); i++ {
+		_, err := table.AddPoster(posters[i], payTo[i])
+		Require(t, err)
+	}
+	for i, poster := range posters {
+		posterState, err := table.OpenPoster(poster, false)
+		Require(t, err)
+		Require(t, posterState.SetFundsDue(big.NewInt(int64(100*(i+1)))))
+	}
+
+	funds := big.NewInt(450)
+	evm.StateDB.AddBalance(l1pricing.L1PricerFundsPoolAddress, uint256.MustFromBig(funds))
+	Require(t, l1p.SetL1FeesAvailable(funds))
+
+	// the first two posters are paid in full, then the third is paid the rest on the next block
+	paid, err := l1p.SettleBatchPosters(2, evm, util.TracingDuringEVM)
+	Require(t, err)
+	if paid != 2 {
+		Fail(t, "expected two posters to be paid, got", paid)
+	}
+	paid, err = l1p.SettleBatchPosters(2, evm, util.TracingDuringEVM)
+	Require(t, err)
+	if paid != 1 {
+		Fail(t, "expected one poster to be paid, got", paid)
+	}
+	expectedBalances := []int64{100, 200, 150}
+	for i, recipient := range payTo {
+		if balance := evm.StateDB.GetBalance(recipient).ToBig(); balance.Int64() != expectedBalances[i] {
+			Fail(t, "wrong balance for poster", i, balance)
+		}
+	}
+	infos, err := table.AllPosterInfo(math.MaxUint64)
+	Require(t, err)
+	if len(infos) != 3 || infos[0].FundsDue.Sign() != 0 || infos[1].FundsDue.Sign() != 0 || infos[2].FundsDue.Int64() != 150 {
+		Fail(t, "wrong funds due after settlement", infos)
+	}
+	totalFundsDue, err := table.TotalFundsDue()
+	Require(t, err)
+	if totalFundsDue.Int64() != 150 {
+		Fail(t, "wrong total funds due", totalFundsDue)
+	}
+
+	// nothing is settled without funds available
+	paid, err = l1p.SettleBatchPosters(2, evm, util.TracingDuringEVM)
+	Require(t, err)
+	if paid != 0 {
+		Fail(t, "settled posters without funds available")
+	}
+}
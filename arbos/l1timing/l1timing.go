@@ -0,0 +1,63 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+// Package l1timing holds the chain parameters describing the parent chain's cadence, for chains that settle to a
+// parent chain whose blocks don't come every 12 seconds like Ethereum's.
+package l1timing
+
+import (
+	"errors"
+	"time"
+
+	"github.com/offchainlabs/nitro/arbos/storage"
+)
+
+const (
+	blockTimeOffset uint64 = iota
+	delayedFinalityBlocksOffset
+)
+
+// DefaultBlockTime is Ethereum's, which the parent chain is assumed to have unless the chain owner sets another.
+const DefaultBlockTime = 12 * time.Second
+
+var ErrInvalidBlockTime = errors.New("parent chain block time must be at least a millisecond")
+
+type L1TimingState struct {
+	blockTimeMillis       storage.StorageBackedUint64 // zero for the default
+	delayedFinalityBlocks storage.StorageBackedUint64
+}
+
+func Open(sto *storage.Storage) *L1TimingState {
+	return &L1TimingState{
+		blockTimeMillis:       sto.OpenStorageBackedUint64(blockTimeOffset),
+		delayedFinalityBlocks: sto.OpenStorageBackedUint64(delayedFinalityBlocksOffset),
+	}
+}
+
+func (s *L1TimingState) BlockTime() (time.Duration, error) {
+	millis, err := s.blockTimeMillis.Get()
+	if err != nil || millis == 0 {
+		return DefaultBlockTime, err
+	}
+	// #nosec G115
+	return time.Duration(millis) * time.Millisecond, nil
+}
+
+func (s *L1TimingState) SetBlockTime(blockTime time.Duration) error {
+	if blockTime < time.Millisecond {
+		return ErrInvalidBlockTime
+	}
+	// #nosec G115
+	return s.blockTimeMillis.Set(uint64(blockTime.Milliseconds()))
+}
+
+// DelayedFinalityBlocks is how many blocks deep a parent chain block must be before the delayed sequencer treats
+// the delayed messages in it as final, when the parent chain doesn't report finality itself. Zero leaves it to
+// the node's configuration.
+func (s *L1TimingState) DelayedFinalityBlocks() (uint64, error) {
+	return s.delayedFinalityBlocks.Get()
+}
+
+func (s *L1TimingState) SetDelayedFinalityBlocks(blocks uint64) error {
+	return s.delayedFinalityBlocks.Set(blocks)
+}
@@ -0,0 +1,49 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package l1timing
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/offchainlabs/nitro/arbos/burn"
+	"github.com/offchainlabs/nitro/arbos/storage"
+)
+
+func TestL1Timing(t *testing.T) {
+	state := Open(storage.NewMemoryBacked(burn.NewSystemBurner(nil, false)))
+
+	blockTime, err := state.BlockTime()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if blockTime != DefaultBlockTime {
+		t.Errorf("unset block time is %v instead of the default", blockTime)
+	}
+	if err := state.SetBlockTime(time.Microsecond); !errors.Is(err, ErrInvalidBlockTime) {
+		t.Errorf("setting a sub-millisecond block time gave %v", err)
+	}
+	if err := state.SetBlockTime(250 * time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	blockTime, err = state.BlockTime()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if blockTime != 250*time.Millisecond {
+		t.Errorf("block time is %v after setting it to 250ms", blockTime)
+	}
+
+	if err := state.SetDelayedFinalityBlocks(64); err != nil {
+		t.Fatal(err)
+	}
+	blocks, err := state.DelayedFinalityBlocks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if blocks != 64 {
+		t.Errorf("delayed finality is %v blocks after setting it to 64", blocks)
+	}
+}
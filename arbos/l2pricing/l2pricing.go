@@ -7,6 +7,7 @@ import (
 	"math/big"
 
 	"github.com/offchainlabs/nitro/arbos/storage"
+	"github.com/offchainlabs/nitro/util/arbmath"
 )
 
 type L2PricingState struct {
@@ -18,6 +19,8 @@ type L2PricingState struct {
 	gasBacklog          storage.StorageBackedUint64
 	pricingInertia      storage.StorageBackedUint64
 	backlogTolerance    storage.StorageBackedUint64
+	codeDepositFee      storage.StorageBackedBigUint
+	blockStartBacklog   storage.StorageBackedUint64
 }
 
 const (
@@ -28,6 +31,8 @@ const (
 	gasBacklogOffset
 	pricingInertiaOffset
 	backlogToleranceOffset
+	codeDepositFeeOffset
+	blockStartBacklogOffset
 )
 
 const GethBlockGasLimit = 1 << 50
@@ -52,6 +57,8 @@ func OpenL2PricingState(sto *storage.Storage) *L2PricingState {
 		sto.OpenStorageBackedUint64(gasBacklogOffset),
 		sto.OpenStorageBackedUint64(pricingInertiaOffset),
 		sto.OpenStorageBackedUint64(backlogToleranceOffset),
+		sto.OpenStorageBackedBigUint(codeDepositFeeOffset),
+		sto.OpenStorageBackedUint64(blockStartBacklogOffset),
 	}
 }
 
@@ -98,6 +105,28 @@ func (ps *L2PricingState) SetGasBacklog(backlog uint64) error {
 	return ps.gasBacklog.Set(backlog)
 }
 
+// RecordBlockStartBacklog remembers the backlog at the start of the block, after the time passed paid some of it off.
+func (ps *L2PricingState) RecordBlockStartBacklog() error {
+	backlog, err := ps.GasBacklog()
+	if err != nil {
+		return err
+	}
+	return ps.blockStartBacklog.Set(backlog)
+}
+
+// BlockGasUsed returns the gas the current block added to the backlog so far.
+func (ps *L2PricingState) BlockGasUsed() (uint64, error) {
+	backlog, err := ps.GasBacklog()
+	if err != nil {
+		return 0, err
+	}
+	startBacklog, err := ps.blockStartBacklog.Get()
+	if err != nil {
+		return 0, err
+	}
+	return arbmath.SaturatingUSub(backlog, startBacklog), nil
+}
+
 func (ps *L2PricingState) PricingInertia() (uint64, error) {
 	return ps.pricingInertia.Get()
 }
@@ -114,6 +143,24 @@ func (ps *L2PricingState) SetBacklogTolerance(val uint64) error {
 	return ps.backlogTolerance.Set(val)
 }
 
+// CodeDepositFeePerByte is the surcharge, in wei, levied per byte of code submitted for deployment.
+func (ps *L2PricingState) CodeDepositFeePerByte() (*big.Int, error) {
+	return ps.codeDepositFee.Get()
+}
+
+func (ps *L2PricingState) SetCodeDepositFeePerByte(val *big.Int) error {
+	return ps.codeDepositFee.SetChecked(val)
+}
+
+// CodeDepositFee computes the surcharge for deploying codeSize bytes of code.
+func (ps *L2PricingState) CodeDepositFee(codeSize uint64) (*big.Int, error) {
+	perByte, err := ps.CodeDepositFeePerByte()
+	if err != nil {
+		return nil, err
+	}
+	return arbmath.BigMulByUint(perByte, codeSize), nil
+}
+
 func (ps *L2PricingState) Restrict(err error) {
 	ps.storage.Burner().Restrict(err)
 }
@@ -5,6 +5,7 @@ package l2pricing
 
 import (
 	"fmt"
+	"math/big"
 	"testing"
 
 	"github.com/offchainlabs/nitro/arbos/burn"
@@ -90,6 +91,41 @@ func TestPricingModelExp(t *testing.T) {
 	}
 }
 
+func TestCodeDepositFee(t *testing.T) {
+	pricing := PricingForTest(t)
+	fee, err := pricing.CodeDepositFee(1000)
+	Require(t, err)
+	if fee.Sign() != 0 {
+		Fail(t, "code deposit fee should default to zero", fee)
+	}
+
+	Require(t, pricing.SetCodeDepositFeePerByte(big.NewInt(200)))
+	fee, err = pricing.CodeDepositFee(1000)
+	Require(t, err)
+	if fee.Cmp(big.NewInt(200_000)) != 0 {
+		Fail(t, "unexpected code deposit fee", fee)
+	}
+}
+
+func TestBlockGasUsed(t *testing.T) {
+	pricing := PricingForTest(t)
+	Require(t, pricing.AddToGasPool(-1_000_000))
+	Require(t, pricing.RecordBlockStartBacklog())
+	used, err := pricing.BlockGasUsed()
+	Require(t, err)
+	if used != 0 {
+		Fail(t, "block used gas before any transaction", used)
+	}
+
+	Require(t, pricing.AddToGasPool(-300_000))
+	Require(t, pricing.AddToGasPool(-50_000))
+	used, err = pricing.BlockGasUsed()
+	Require(t, err)
+	if used != 350_000 {
+		Fail(t, "unexpected block gas used", used)
+	}
+}
+
 func getPrice(t *testing.T, pricing *L2PricingState) uint64 {
 	value, err := pricing.BaseFeeWei()
 	Require(t, err)
@@ -0,0 +1,167 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package merkleAccumulator
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// SnapshotArchive provides the history of an accumulator, as recorded for instance by an indexer
+// following the accumulator's events.
+type SnapshotArchive interface {
+	// PartialsAt returns the partials of the accumulator from when it held exactly size leaves,
+	// in the format of GetPartials. Trailing levels may be omitted.
+	PartialsAt(size uint64) ([]common.Hash, error)
+	// ItemAt returns the item hash that was appended as the given leaf.
+	ItemAt(leaf uint64) (common.Hash, error)
+}
+
+// InclusionProof proves that Item was appended as leaf LeafIndex of the accumulator with Size leaves and root Root.
+// Siblings are ordered from the leaf level upwards.
+type InclusionProof struct {
+	Size      uint64
+	Root      common.Hash
+	LeafIndex uint64
+	Item      common.Hash
+	Siblings  []common.Hash
+}
+
+// ComputedRoot folds the proof's siblings over the leaf and returns the resulting root.
+func (p *InclusionProof) ComputedRoot() common.Hash {
+	hash := crypto.Keccak256Hash(p.Item.Bytes())
+	index := p.LeafIndex
+	for _, sibling := range p.Siblings {
+		if index&1 == 0 {
+			hash = crypto.Keccak256Hash(hash.Bytes(), sibling.Bytes())
+		} else {
+			hash = crypto.Keccak256Hash(sibling.Bytes(), hash.Bytes())
+		}
+		index /= 2
+	}
+	return hash
+}
+
+func (p *InclusionProof) IsCorrect() bool {
+	return p.LeafIndex < p.Size && p.ComputedRoot() == p.Root
+}
+
+// ForEachPartial calls fn on every level of the accumulator's partials, lowest level first.
+// Empty levels are visited with a zero hash. Iteration stops at the first error returned by fn.
+func (acc *MerkleAccumulator) ForEachPartial(fn func(level uint64, partial common.Hash) error) error {
+	size, err := acc.size.Get()
+	if err != nil {
+		return err
+	}
+	for level := uint64(0); level < CalcNumPartials(size); level++ {
+		partial, err := acc.getPartial(level)
+		if err != nil {
+			return err
+		}
+		if err := fn(level, *partial); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ProveInclusion proves that leaf is included in the accumulator's current root, using archive to
+// recover the parts of the tree that the accumulator no longer stores.
+func (acc *MerkleAccumulator) ProveInclusion(archive SnapshotArchive, leaf uint64) (*InclusionProof, error) {
+	size, err := acc.size.Get()
+	if err != nil {
+		return nil, err
+	}
+	proof, err := ProveInclusion(archive, size, leaf)
+	if err != nil {
+		return nil, err
+	}
+	root, err := acc.Root()
+	if err != nil {
+		return nil, err
+	}
+	if proof.Root != root {
+		return nil, fmt.Errorf("archive is inconsistent with accumulator: computed root %v but accumulator root is %v", proof.Root, root)
+	}
+	return proof, nil
+}
+
+// ProveInclusion proves that leaf is included in the root the accumulator had when it held size leaves.
+// It needs O(log size) archive lookups: one snapshot and item per sibling subtree.
+func ProveInclusion(archive SnapshotArchive, size, leaf uint64) (*InclusionProof, error) {
+	if leaf >= size {
+		return nil, fmt.Errorf("leaf %v out of range for accumulator of size %v", leaf, size)
+	}
+	item, err := archive.ItemAt(leaf)
+	if err != nil {
+		return nil, err
+	}
+	height := CalcNumPartials(size - 1)
+	proof := &InclusionProof{
+		Size:      size,
+		LeafIndex: leaf,
+		Item:      item,
+		Siblings:  make([]common.Hash, height),
+	}
+	for level := uint64(0); level < height; level++ {
+		proof.Siblings[level], err = historicalNode(archive, size, level, (leaf>>level)^1)
+		if err != nil {
+			return nil, err
+		}
+	}
+	proof.Root = proof.ComputedRoot()
+	return proof, nil
+}
+
+// historicalNode returns the hash of the subtree at the given level and index in the tree of size leaves.
+// Subtrees past the end of the tree are zero, matching the padding in Root.
+func historicalNode(archive SnapshotArchive, size, level, index uint64) (common.Hash, error) {
+	start := index << level
+	end := (index + 1) << level
+	if start >= size {
+		return common.Hash{}, nil
+	}
+	if end > size {
+		left, err := historicalNode(archive, size, level-1, 2*index)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		right, err := historicalNode(archive, size, level-1, 2*index+1)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		return crypto.Keccak256Hash(left.Bytes(), right.Bytes()), nil
+	}
+	return completedNode(archive, level, end)
+}
+
+// completedNode replays the append of the last leaf of a complete subtree ending at end.
+// Just before that append, the accumulator's partials below level are exactly the rest of the subtree.
+func completedNode(archive SnapshotArchive, level, end uint64) (common.Hash, error) {
+	item, err := archive.ItemAt(end - 1)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	hash := crypto.Keccak256Hash(item.Bytes())
+	if level == 0 {
+		return hash, nil
+	}
+	partials, err := archive.PartialsAt(end - 1)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if uint64(len(partials)) < level {
+		return common.Hash{}, fmt.Errorf("archived snapshot at size %v has %v partials, expected at least %v", end-1, len(partials), level)
+	}
+	for l := uint64(0); l < level; l++ {
+		if partials[l] == (common.Hash{}) {
+			return common.Hash{}, errors.New("archived snapshot is missing a partial")
+		}
+		hash = crypto.Keccak256Hash(partials[l].Bytes(), hash.Bytes())
+	}
+	return hash, nil
+}
@@ -0,0 +1,72 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+// Package methodacl keeps the grants that let addresses other than the chain owners call specific owner-gated
+// precompile methods, optionally until an expiry time.
+package methodacl
+
+import (
+	"errors"
+	"math"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/nitro/arbos/storage"
+)
+
+// NoExpiry is the expiry of grants that last until revoked.
+const NoExpiry = math.MaxUint64
+
+var ErrExpiredGrant = errors.New("grant expiry is not in the future")
+
+// MethodACL maps a precompile address, method selector, and grantee to the time the grant expires, with zero meaning
+// there's no grant.
+type MethodACL struct {
+	backingStorage *storage.Storage
+}
+
+func Open(sto *storage.Storage) *MethodACL {
+	return &MethodACL{sto}
+}
+
+func (acl *MethodACL) key(precompile common.Address, selector [4]byte, grantee common.Address) (common.Hash, error) {
+	return acl.backingStorage.KeccakHash(precompile.Bytes(), selector[:], grantee.Bytes())
+}
+
+// Grant lets the grantee call the method until the expiry timestamp, replacing any previous grant.
+func (acl *MethodACL) Grant(precompile common.Address, selector [4]byte, grantee common.Address, expiry uint64, now uint64) error {
+	if expiry <= now {
+		return ErrExpiredGrant
+	}
+	key, err := acl.key(precompile, selector, grantee)
+	if err != nil {
+		return err
+	}
+	return acl.backingStorage.SetUint64(key, expiry)
+}
+
+func (acl *MethodACL) Revoke(precompile common.Address, selector [4]byte, grantee common.Address) error {
+	key, err := acl.key(precompile, selector, grantee)
+	if err != nil {
+		return err
+	}
+	return acl.backingStorage.Clear(key)
+}
+
+// Expiry returns when the grantee's grant to call the method expires, or zero if there's none.
+func (acl *MethodACL) Expiry(precompile common.Address, selector [4]byte, grantee common.Address) (uint64, error) {
+	key, err := acl.key(precompile, selector, grantee)
+	if err != nil {
+		return 0, err
+	}
+	return acl.backingStorage.GetUint64(key)
+}
+
+// IsAllowed returns whether the grantee may call the method at the given time.
+func (acl *MethodACL) IsAllowed(precompile common.Address, selector [4]byte, grantee common.Address, now uint64) (bool, error) {
+	expiry, err := acl.Expiry(precompile, selector, grantee)
+	if err != nil {
+		return false, err
+	}
+	return expiry > now, nil
+}
@@ -0,0 +1,76 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package methodacl
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/offchainlabs/nitro/arbos/burn"
+	"github.com/offchainlabs/nitro/arbos/storage"
+	"github.com/offchainlabs/nitro/util/testhelpers"
+)
+
+func TestMethodACL(t *testing.T) {
+	acl := Open(storage.NewMemoryBacked(burn.NewSystemBurner(nil, false)))
+	precompile := common.BytesToAddress([]byte{0x70})
+	grantee := common.HexToAddress("0x1234")
+	setPrice := [4]byte{1, 2, 3, 4}
+	setOther := [4]byte{5, 6, 7, 8}
+
+	allowed, err := acl.IsAllowed(precompile, setPrice, grantee, 100)
+	Require(t, err)
+	if allowed {
+		Fail(t, "allowed without a grant")
+	}
+	if err := acl.Grant(precompile, setPrice, grantee, 100, 100); !errors.Is(err, ErrExpiredGrant) {
+		Fail(t, "granted access expiring immediately", err)
+	}
+
+	Require(t, acl.Grant(precompile, setPrice, grantee, 200, 100))
+	allowed, err = acl.IsAllowed(precompile, setPrice, grantee, 199)
+	Require(t, err)
+	if !allowed {
+		Fail(t, "not allowed before the grant expired")
+	}
+	allowed, err = acl.IsAllowed(precompile, setPrice, grantee, 200)
+	Require(t, err)
+	if allowed {
+		Fail(t, "allowed after the grant expired")
+	}
+	allowed, err = acl.IsAllowed(precompile, setOther, grantee, 150)
+	Require(t, err)
+	if allowed {
+		Fail(t, "grant applied to another method")
+	}
+	allowed, err = acl.IsAllowed(common.Address{}, setPrice, grantee, 150)
+	Require(t, err)
+	if allowed {
+		Fail(t, "grant applied to another precompile")
+	}
+
+	Require(t, acl.Grant(precompile, setPrice, grantee, NoExpiry, 300))
+	allowed, err = acl.IsAllowed(precompile, setPrice, grantee, 1<<40)
+	Require(t, err)
+	if !allowed {
+		Fail(t, "grant without expiry expired")
+	}
+	Require(t, acl.Revoke(precompile, setPrice, grantee))
+	expiry, err := acl.Expiry(precompile, setPrice, grantee)
+	Require(t, err)
+	if expiry != 0 {
+		Fail(t, "revoked grant has expiry", expiry)
+	}
+}
+
+func Require(t *testing.T, err error, printables ...interface{}) {
+	t.Helper()
+	testhelpers.RequireImpl(t, err, printables...)
+}
+
+func Fail(t *testing.T, printables ...interface{}) {
+	t.Helper()
+	testhelpers.FailImpl(t, printables...)
+}
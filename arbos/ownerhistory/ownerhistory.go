@@ -0,0 +1,242 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+// Package ownerhistory keeps an append-only log of chain owner additions and removals, from which the chain owners
+// at the end of any block since the log started can be recovered. The full set of owners is checkpointed every
+// checkpointInterval changes, so recovering them reads a bounded number of changes.
+package ownerhistory
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/nitro/arbos/storage"
+)
+
+const (
+	sizeOffset uint64 = iota
+	startBlockOffset
+)
+
+var (
+	changesKey     = []byte{0}
+	checkpointsKey = []byte{1}
+)
+
+// the number of changes between checkpoints of the full set of owners
+const checkpointInterval = 16
+
+var ErrHistoryUnavailable = errors.New("chain owner history doesn't reach back to the block")
+
+// Change is a chain owner being added or removed.
+type Change struct {
+	BlockNumber uint64
+	Owner       common.Address
+	Added       bool
+}
+
+// encode packs the change into a single slot: the added flag, then the block number, then the owner.
+func (c Change) encode() common.Hash {
+	var slot common.Hash
+	if c.Added {
+		slot[0] = 1
+	}
+	binary.BigEndian.PutUint64(slot[4:12], c.BlockNumber)
+	copy(slot[12:], c.Owner.Bytes())
+	return slot
+}
+
+func decodeChange(slot common.Hash) Change {
+	return Change{
+		BlockNumber: binary.BigEndian.Uint64(slot[4:12]),
+		Owner:       common.BytesToAddress(slot[12:]),
+		Added:       slot[0] != 0,
+	}
+}
+
+// OwnerHistory stores its changes from index 1 onward, in the order they were made, and the owners after every
+// checkpointInterval-th change, starting with the owners the history started with.
+type OwnerHistory struct {
+	size        storage.StorageBackedUint64
+	startBlock  storage.StorageBackedUint64 // zero until the history starts
+	changes     *storage.Storage
+	checkpoints *storage.Storage
+}
+
+func Open(sto *storage.Storage) *OwnerHistory {
+	return &OwnerHistory{
+		size:        sto.OpenStorageBackedUint64(sizeOffset),
+		startBlock:  sto.OpenStorageBackedUint64(startBlockOffset),
+		changes:     sto.OpenSubStorage(changesKey),
+		checkpoints: sto.OpenSubStorage(checkpointsKey),
+	}
+}
+
+// Start begins the history at the start of the given block, which must be positive, with the owners at the end of
+// the block before it. It does nothing if the history already started.
+func (h *OwnerHistory) Start(blockNumber uint64, owners []common.Address) error {
+	if blockNumber == 0 {
+		return errors.New("chain owner history must start after the genesis block")
+	}
+	start, err := h.startBlock.Get()
+	if start != 0 || err != nil {
+		return err
+	}
+	if err := h.writeCheckpoint(0, owners); err != nil {
+		return err
+	}
+	return h.startBlock.Set(blockNumber)
+}
+
+func (h *OwnerHistory) checkpoint(changeCount uint64) *storage.Storage {
+	return h.checkpoints.OpenSubStorage(binary.BigEndian.AppendUint64(nil, changeCount))
+}
+
+// writeCheckpoint stores the owners after the given number of changes: their count, then each owner.
+func (h *OwnerHistory) writeCheckpoint(changeCount uint64, owners []common.Address) error {
+	checkpoint := h.checkpoint(changeCount)
+	if err := checkpoint.SetUint64ByUint64(0, uint64(len(owners))); err != nil {
+		return err
+	}
+	for i, owner := range owners {
+		if err := checkpoint.SetByUint64(uint64(i)+1, common.BytesToHash(owner.Bytes())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *OwnerHistory) readCheckpoint(changeCount uint64) ([]common.Address, error) {
+	checkpoint := h.checkpoint(changeCount)
+	count, err := checkpoint.GetUint64ByUint64(0)
+	if err != nil {
+		return nil, err
+	}
+	owners := make([]common.Address, 0, count)
+	for i := uint64(1); i <= count; i++ {
+		slot, err := checkpoint.GetByUint64(i)
+		if err != nil {
+			return nil, err
+		}
+		owners = append(owners, common.BytesToAddress(slot.Bytes()))
+	}
+	return owners, nil
+}
+
+// StartBlock returns the block the history started at, or zero if it hasn't started.
+func (h *OwnerHistory) StartBlock() (uint64, error) {
+	return h.startBlock.Get()
+}
+
+func (h *OwnerHistory) Size() (uint64, error) {
+	return h.size.Get()
+}
+
+// Record appends a change made in the given block. The owners after the change are only read when they're
+// checkpointed.
+func (h *OwnerHistory) Record(blockNumber uint64, owner common.Address, added bool, owners func() ([]common.Address, error)) error {
+	size, err := h.size.Increment()
+	if err != nil {
+		return err
+	}
+	change := Change{BlockNumber: blockNumber, Owner: owner, Added: added}
+	if err := h.changes.SetByUint64(size, change.encode()); err != nil {
+		return err
+	}
+	if size%checkpointInterval != 0 {
+		return nil
+	}
+	current, err := owners()
+	if err != nil {
+		return err
+	}
+	return h.writeCheckpoint(size, current)
+}
+
+func (h *OwnerHistory) change(index uint64) (Change, error) {
+	slot, err := h.changes.GetByUint64(index)
+	if err != nil {
+		return Change{}, err
+	}
+	return decodeChange(slot), nil
+}
+
+// Changes returns up to count changes, starting from the given index counting from zero.
+func (h *OwnerHistory) Changes(start uint64, count uint64) ([]Change, error) {
+	size, err := h.size.Get()
+	if err != nil || start >= size {
+		return nil, err
+	}
+	if count > size-start {
+		count = size - start
+	}
+	changes := make([]Change, 0, count)
+	for i := start; i < start+count; i++ {
+		change, err := h.change(i + 1)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// OwnersAt returns the chain owners at the end of the given block. It binary searches the changes for the last
+// one made by the end of the block, and replays the changes since the checkpoint before it. Since the history
+// starts with the owners before its start block, the owners at the end of the block before it are known too.
+func (h *OwnerHistory) OwnersAt(blockNumber uint64) ([]common.Address, error) {
+	start, err := h.startBlock.Get()
+	if err != nil {
+		return nil, err
+	}
+	if start == 0 || blockNumber+1 < start {
+		return nil, ErrHistoryUnavailable
+	}
+	size, err := h.size.Get()
+	if err != nil {
+		return nil, err
+	}
+	// find the number of changes made by the end of the block, as changes are in block order
+	low, high := uint64(0), size
+	for low < high {
+		mid := low + (high-low)/2
+		change, err := h.change(mid + 1)
+		if err != nil {
+			return nil, err
+		}
+		if change.BlockNumber <= blockNumber {
+			low = mid + 1
+		} else {
+			high = mid
+		}
+	}
+	changeCount := low
+	checkpointed := changeCount - changeCount%checkpointInterval
+	owners, err := h.readCheckpoint(checkpointed)
+	if err != nil {
+		return nil, err
+	}
+	for i := checkpointed + 1; i <= changeCount; i++ {
+		change, err := h.change(i)
+		if err != nil {
+			return nil, err
+		}
+		if change.Added {
+			owners = append(owners, change.Owner)
+		} else {
+			owners = remove(owners, change.Owner)
+		}
+	}
+	return owners, nil
+}
+
+func remove(owners []common.Address, owner common.Address) []common.Address {
+	for i, member := range owners {
+		if member == owner {
+			return append(owners[:i], owners[i+1:]...)
+		}
+	}
+	return owners
+}
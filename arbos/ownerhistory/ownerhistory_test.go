@@ -0,0 +1,142 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package ownerhistory
+
+import (
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/offchainlabs/nitro/arbos/burn"
+	"github.com/offchainlabs/nitro/arbos/storage"
+	"github.com/offchainlabs/nitro/util/testhelpers"
+)
+
+func TestOwnersAt(t *testing.T) {
+	history := Open(storage.NewMemoryBacked(burn.NewSystemBurner(nil, false)))
+	alice := common.HexToAddress("0xa11ce")
+	bob := common.HexToAddress("0xb0b")
+	carol := common.HexToAddress("0xca201")
+
+	if _, err := history.OwnersAt(10); !errors.Is(err, ErrHistoryUnavailable) {
+		Fail(t, "recovered owners before the history started", err)
+	}
+	if history.Start(0, []common.Address{alice}) == nil {
+		Fail(t, "started the history at the genesis block")
+	}
+	Require(t, history.Start(10, []common.Address{alice}))
+	Require(t, history.Start(20, []common.Address{bob}))
+	start, err := history.StartBlock()
+	Require(t, err)
+	if start != 10 {
+		Fail(t, "restarting the history moved its start to", start)
+	}
+
+	// alice owns the chain at the start, then bob is added, alice removed and carol added
+	current := []common.Address{alice}
+	record := func(block uint64, owner common.Address, added bool) {
+		t.Helper()
+		if added {
+			current = append(current, owner)
+		} else {
+			current = remove(current, owner)
+		}
+		Require(t, history.Record(block, owner, added, func() ([]common.Address, error) {
+			return append([]common.Address{}, current...), nil
+		}))
+	}
+	record(12, bob, true)
+	record(15, alice, false)
+	record(15, carol, true)
+
+	expected := map[uint64][]common.Address{
+		9:  {alice},
+		11: {alice},
+		12: {bob, alice},
+		14: {bob, alice},
+		15: {bob, carol},
+		30: {bob, carol},
+	}
+	for block, owners := range expected {
+		got, err := history.OwnersAt(block)
+		Require(t, err)
+		if !sameOwners(got, owners) {
+			Fail(t, "wrong owners at block", block, got, owners)
+		}
+	}
+	if _, err := history.OwnersAt(8); !errors.Is(err, ErrHistoryUnavailable) {
+		Fail(t, "recovered owners before the history started", err)
+	}
+
+	changes, err := history.Changes(1, 5)
+	Require(t, err)
+	want := []Change{{15, alice, false}, {15, carol, true}}
+	if !reflect.DeepEqual(changes, want) {
+		Fail(t, "wrong changes", changes, want)
+	}
+	changes, err = history.Changes(3, 5)
+	Require(t, err)
+	if len(changes) != 0 {
+		Fail(t, "changes past the end", changes)
+	}
+}
+
+func TestOwnersAtAcrossCheckpoints(t *testing.T) {
+	history := Open(storage.NewMemoryBacked(burn.NewSystemBurner(nil, false)))
+	base := common.HexToAddress("0xba5e")
+	Require(t, history.Start(1, []common.Address{base}))
+
+	// each block adds an owner and every third removes the one added two blocks before
+	current := []common.Address{base}
+	expected := map[uint64][]common.Address{0: {base}}
+	owners := func() ([]common.Address, error) {
+		return append([]common.Address{}, current...), nil
+	}
+	for block := uint64(1); block <= 5*checkpointInterval; block++ {
+		added := common.BigToAddress(new(big.Int).SetUint64(block))
+		current = append(current, added)
+		Require(t, history.Record(block, added, true, owners))
+		if block%3 == 0 {
+			removed := common.BigToAddress(new(big.Int).SetUint64(block - 2))
+			current = remove(current, removed)
+			Require(t, history.Record(block, removed, false, owners))
+		}
+		expected[block] = append([]common.Address{}, current...)
+	}
+	for block, owners := range expected {
+		got, err := history.OwnersAt(block)
+		Require(t, err)
+		if !sameOwners(got, owners) {
+			Fail(t, "wrong owners at block", block, got, owners)
+		}
+	}
+}
+
+func sameOwners(a, b []common.Address) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	members := make(map[common.Address]bool)
+	for _, owner := range a {
+		members[owner] = true
+	}
+	for _, owner := range b {
+		if !members[owner] {
+			return false
+		}
+	}
+	return true
+}
+
+func Require(t *testing.T, err error, printables ...interface{}) {
+	t.Helper()
+	testhelpers.RequireImpl(t, err, printables...)
+}
+
+func Fail(t *testing.T, printables ...interface{}) {
+	t.Helper()
+	testhelpers.FailImpl(t, printables...)
+}
@@ -0,0 +1,125 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+// Package pricehistory keeps the gas prices each of the most recent blocks started with, so that the prices at a
+// recent block can be queried from current state, without an archive node. Older snapshots are overwritten.
+package pricehistory
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/nitro/arbos/storage"
+)
+
+// MaxDepth is the most blocks the chain owner can have snapshots kept for.
+const MaxDepth uint64 = 8192
+
+const depthOffset uint64 = 0
+
+var snapshotsKey = []byte{0}
+
+var ErrNotKept = errors.New("no gas price snapshot is kept for the block")
+
+// Snapshot is the prices in effect when a block started: the L1 price per unit of calldata, and the L2 base fee
+// along with the minimum it can fall to.
+type Snapshot struct {
+	BlockNumber    uint64
+	L1PricePerUnit *big.Int
+	L2BaseFee      *big.Int
+	MinBaseFee     *big.Int
+}
+
+// slotsPerSnapshot is the number of slots a snapshot takes: a header marking which block it's for, then its prices.
+const slotsPerSnapshot = 4
+
+// header marks a slot as holding the snapshot of the block, so that an unset slot isn't mistaken for block 0's.
+func header(blockNumber uint64) common.Hash {
+	var slot common.Hash
+	slot[0] = 1
+	binary.BigEndian.PutUint64(slot[24:32], blockNumber)
+	return slot
+}
+
+// PriceHistory is a ring of snapshots, one per block, with the snapshot of block b kept at slots starting from
+// slotsPerSnapshot*(b%depth) until the snapshot of block b+depth replaces it. Each snapshot records its block, so
+// changing the depth never returns a snapshot for the wrong block, though it may forget some kept ones.
+type PriceHistory struct {
+	depth     storage.StorageBackedUint64
+	snapshots *storage.Storage
+}
+
+func Open(sto *storage.Storage) *PriceHistory {
+	return &PriceHistory{
+		depth:     sto.OpenStorageBackedUint64(depthOffset),
+		snapshots: sto.OpenSubStorage(snapshotsKey),
+	}
+}
+
+// Depth returns the number of blocks snapshots are kept for. Zero means none are.
+func (h *PriceHistory) Depth() (uint64, error) {
+	return h.depth.Get()
+}
+
+func (h *PriceHistory) SetDepth(depth uint64) error {
+	if depth > MaxDepth {
+		return fmt.Errorf("price history depth %v exceeds the maximum of %v", depth, MaxDepth)
+	}
+	return h.depth.Set(depth)
+}
+
+// Record keeps the snapshot, overwriting the one of the block depth blocks before it.
+func (h *PriceHistory) Record(snapshot Snapshot) error {
+	depth, err := h.depth.Get()
+	if err != nil || depth == 0 {
+		return err
+	}
+	base := slotsPerSnapshot * (snapshot.BlockNumber % depth)
+	if err := h.snapshots.SetByUint64(base, header(snapshot.BlockNumber)); err != nil {
+		return err
+	}
+	if err := h.snapshots.SetByUint64(base+1, common.BigToHash(snapshot.L1PricePerUnit)); err != nil {
+		return err
+	}
+	if err := h.snapshots.SetByUint64(base+2, common.BigToHash(snapshot.L2BaseFee)); err != nil {
+		return err
+	}
+	return h.snapshots.SetByUint64(base+3, common.BigToHash(snapshot.MinBaseFee))
+}
+
+// At returns the snapshot of the block, which must be one of the last depth blocks recorded.
+func (h *PriceHistory) At(blockNumber uint64) (Snapshot, error) {
+	depth, err := h.depth.Get()
+	if err != nil {
+		return Snapshot{}, err
+	}
+	if depth == 0 {
+		return Snapshot{}, fmt.Errorf("%w: the chain keeps no price history", ErrNotKept)
+	}
+	base := slotsPerSnapshot * (blockNumber % depth)
+	slot, err := h.snapshots.GetByUint64(base)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	if slot != header(blockNumber) {
+		return Snapshot{}, fmt.Errorf("%w: block %v isn't among the last %v", ErrNotKept, blockNumber, depth)
+	}
+	prices := make([]*big.Int, slotsPerSnapshot-1)
+	for i := range prices {
+		value, err := h.snapshots.GetByUint64(base + 1 + uint64(i))
+		if err != nil {
+			return Snapshot{}, err
+		}
+		prices[i] = value.Big()
+	}
+	return Snapshot{
+		BlockNumber:    blockNumber,
+		L1PricePerUnit: prices[0],
+		L2BaseFee:      prices[1],
+		MinBaseFee:     prices[2],
+	}, nil
+}
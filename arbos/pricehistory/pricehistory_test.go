@@ -0,0 +1,82 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package pricehistory
+
+import (
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/offchainlabs/nitro/arbos/burn"
+	"github.com/offchainlabs/nitro/arbos/storage"
+	"github.com/offchainlabs/nitro/util/testhelpers"
+)
+
+func testSnapshot(blockNumber uint64) Snapshot {
+	// #nosec G115
+	price := int64(blockNumber)
+	return Snapshot{
+		BlockNumber:    blockNumber,
+		L1PricePerUnit: big.NewInt(1000 + price),
+		L2BaseFee:      big.NewInt(2000 + price),
+		MinBaseFee:     big.NewInt(100),
+	}
+}
+
+func TestPriceHistory(t *testing.T) {
+	history := Open(storage.NewMemoryBacked(burn.NewSystemBurner(nil, false)))
+
+	// nothing is kept until the depth is set
+	Require(t, history.Record(testSnapshot(0)))
+	if _, err := history.At(0); !errors.Is(err, ErrNotKept) {
+		Fail(t, "looked up a snapshot without a history", err)
+	}
+	if err := history.SetDepth(MaxDepth + 1); err == nil {
+		Fail(t, "set a depth above the maximum")
+	}
+
+	Require(t, history.SetDepth(4))
+	for block := uint64(0); block < 10; block++ {
+		Require(t, history.Record(testSnapshot(block)))
+	}
+	for block := uint64(0); block < 12; block++ {
+		snapshot, err := history.At(block)
+		if block < 6 || block >= 10 {
+			if !errors.Is(err, ErrNotKept) {
+				Fail(t, "looked up a snapshot that isn't kept", block, err)
+			}
+			continue
+		}
+		Require(t, err)
+		if !reflect.DeepEqual(snapshot, testSnapshot(block)) {
+			Fail(t, "wrong snapshot", block, snapshot)
+		}
+	}
+
+	// changing the depth forgets snapshots rather than returning the wrong block's
+	Require(t, history.SetDepth(3))
+	for block := uint64(6); block < 10; block++ {
+		snapshot, err := history.At(block)
+		if err == nil && !reflect.DeepEqual(snapshot, testSnapshot(block)) {
+			Fail(t, "wrong snapshot after changing the depth", block, snapshot)
+		}
+	}
+	Require(t, history.Record(testSnapshot(10)))
+	snapshot, err := history.At(10)
+	Require(t, err)
+	if !reflect.DeepEqual(snapshot, testSnapshot(10)) {
+		Fail(t, "wrong snapshot after changing the depth", snapshot)
+	}
+}
+
+func Require(t *testing.T, err error, printables ...interface{}) {
+	t.Helper()
+	testhelpers.RequireImpl(t, err, printables...)
+}
+
+func Fail(t *testing.T, printables ...interface{}) {
+	t.Helper()
+	testhelpers.FailImpl(t, printables...)
+}
@@ -214,6 +214,44 @@ func TestRetryableCreate(t *testing.T) {
 	}
 }
 
+func TestRetryableRedeemParent(t *testing.T) {
+	state, statedb := arbosState.NewArbosMemoryBackedArbOSState()
+	rstate := state.RetryableState()
+	id := common.BigToHash(big.NewInt(978645611142))
+	parent := common.BigToHash(big.NewInt(31415926))
+	from := testhelpers.RandomAddress()
+	to := testhelpers.RandomAddress()
+	timeout := uint64(10000000)
+
+	stateCheck(t, statedb, false, "deleting the retryable left its redeem parent behind", func() {
+		retryable, err := rstate.CreateRetryable(id, timeout, from, &to, big.NewInt(0), from, []byte{1, 2, 3})
+		Require(t, err)
+		recorded, err := retryable.RedeemParent()
+		Require(t, err)
+		if recorded != (common.Hash{}) {
+			Fail(t, "new retryable has a redeem parent", recorded)
+		}
+		Require(t, retryable.SetRedeemParent(parent))
+
+		reread, err := rstate.OpenRetryable(id, 0)
+		Require(t, err)
+		recorded, err = reread.RedeemParent()
+		Require(t, err)
+		if recorded != parent {
+			Fail(t, "wrong redeem parent", recorded)
+		}
+
+		evm := vm.NewEVM(vm.BlockContext{}, vm.TxContext{}, statedb, &params.ChainConfig{}, vm.Config{})
+		deleted, err := rstate.DeleteRetryable(id, evm, util.TracingDuringEVM)
+		Require(t, err)
+		if !deleted {
+			Fail(t, "failed to delete the retryable")
+		}
+		_, err = rstate.TimeoutQueue.Shift()
+		Require(t, err)
+	})
+}
+
 func stateCheck(t *testing.T, statedb *state.StateDB, change bool, message string, scope func()) {
 	stateBefore := statedb.IntermediateRoot(true)
 	dumpBefore := string(statedb.Dump(&state.DumpConfig{}))
@@ -62,6 +62,7 @@ const (
 	beneficiaryOffset
 	timeoutOffset
 	timeoutWindowsLeftOffset
+	redeemParentOffset // the tx that scheduled the latest redeem, when the chain records it
 )
 
 func (rs *RetryableState) CreateRetryable(
@@ -123,6 +124,12 @@ func (rs *RetryableState) OpenRetryable(id common.Hash, currentTimestamp uint64)
 	}, nil
 }
 
+// Timeout returns the timeout stored for a retryable, even one that has expired but has yet to be reaped,
+// or zero if there's none.
+func (rs *RetryableState) Timeout(id common.Hash) (uint64, error) {
+	return rs.retryables.OpenSubStorage(id.Bytes()).GetUint64ByUint64(timeoutOffset)
+}
+
 func (rs *RetryableState) RetryableSizeBytes(id common.Hash, currentTime uint64) (uint64, error) {
 	retryable, err := rs.OpenRetryable(id, currentTime)
 	if retryable == nil || err != nil {
@@ -158,6 +165,7 @@ func (rs *RetryableState) DeleteRetryable(id common.Hash, evm *vm.EVM, scenario
 	_ = retStorage.ClearByUint64(beneficiaryOffset)
 	_ = retStorage.ClearByUint64(timeoutOffset)
 	_ = retStorage.ClearByUint64(timeoutWindowsLeftOffset)
+	_ = retStorage.ClearByUint64(redeemParentOffset)
 	err = retStorage.OpenSubStorage(calldataKey).ClearBytes()
 	return true, err
 }
@@ -170,6 +178,17 @@ func (retryable *Retryable) IncrementNumTries() (uint64, error) {
 	return retryable.numTries.Increment()
 }
 
+// RedeemParent returns the hash of the tx that scheduled the latest redeem, which is the ticket id for an auto-redeem.
+// It's zero if none was recorded.
+func (retryable *Retryable) RedeemParent() (common.Hash, error) {
+	return retryable.backingStorage.GetByUint64(redeemParentOffset)
+}
+
+// SetRedeemParent records the hash of the tx scheduling a redeem, so the retry tx can be linked back to it.
+func (retryable *Retryable) SetRedeemParent(txHash common.Hash) error {
+	return retryable.backingStorage.SetByUint64(redeemParentOffset, txHash)
+}
+
 func (retryable *Retryable) Beneficiary() (common.Address, error) {
 	return retryable.beneficiary.Get()
 }
@@ -288,45 +307,85 @@ func (retryable *Retryable) Equals(other *Retryable) (bool, error) { // for test
 }
 
 func (rs *RetryableState) TryToReapOneRetryable(currentTimestamp uint64, evm *vm.EVM, scenario util.TracingScenario) error {
+	_, _, err := rs.TryToReapExpiredRetryable(currentTimestamp, evm, scenario)
+	return err
+}
+
+// TryToReapExpiredRetryable processes the next entry of the timeout queue, returning the id of the retryable
+// that expired, if any
+func (rs *RetryableState) TryToReapExpiredRetryable(currentTimestamp uint64, evm *vm.EVM, scenario util.TracingScenario) (common.Hash, bool, error) {
+	outcome, id, err := rs.ProcessTimeoutQueueHead(currentTimestamp, evm, scenario)
+	return id, outcome == TimeoutQueueExpired && err == nil, err
+}
+
+// TimeoutQueueOutcome is what processing the head of the timeout queue did.
+type TimeoutQueueOutcome uint8
+
+const (
+	TimeoutQueueIdle      TimeoutQueueOutcome = iota // the queue is empty or its next entry isn't due
+	TimeoutQueueDiscarded                            // the entry's retryable had already been deleted
+	TimeoutQueueExpired                              // the retryable expired and was deleted
+	TimeoutQueueExtended                             // the retryable used up one of its timeout windows
+)
+
+// ProcessTimeoutQueueHead pops the next entry of the timeout queue if it's due, and expires its retryable
+// or consumes one of its timeout windows. The id of the entry is returned unless the outcome is idle.
+func (rs *RetryableState) ProcessTimeoutQueueHead(currentTimestamp uint64, evm *vm.EVM, scenario util.TracingScenario) (TimeoutQueueOutcome, common.Hash, error) {
 	id, err := rs.TimeoutQueue.Peek()
 	if err != nil || id == nil {
-		return err
+		return TimeoutQueueIdle, common.Hash{}, err
 	}
 	retryableStorage := rs.retryables.OpenSubStorage(id.Bytes())
 	timeoutStorage := retryableStorage.OpenStorageBackedUint64(timeoutOffset)
 	timeout, err := timeoutStorage.Get()
 	if err != nil {
-		return err
+		return TimeoutQueueIdle, common.Hash{}, err
 	}
 	if timeout == 0 {
 		// The retryable has already been deleted, so discard the peeked entry
 		_, err = rs.TimeoutQueue.Get()
-		return err
+		return TimeoutQueueDiscarded, *id, err
 	}
 
 	windowsLeftStorage := retryableStorage.OpenStorageBackedUint64(timeoutWindowsLeftOffset)
 	windowsLeft, err := windowsLeftStorage.Get()
 	if err != nil || timeout >= currentTimestamp {
-		return err
+		return TimeoutQueueIdle, common.Hash{}, err
 	}
 
 	// Either the retryable has expired, or it's lost a lifetime's worth of time
 	_, err = rs.TimeoutQueue.Get()
 	if err != nil {
-		return err
+		return TimeoutQueueIdle, common.Hash{}, err
 	}
 
 	if windowsLeft == 0 {
 		// the retryable has expired, time to reap
 		_, err = rs.DeleteRetryable(*id, evm, scenario)
-		return err
+		return TimeoutQueueExpired, *id, err
 	}
 
 	// Consume a window, delaying the timeout one lifetime period
 	if err := timeoutStorage.Set(timeout + RetryableLifetimeSeconds); err != nil {
-		return err
+		return TimeoutQueueExtended, *id, err
+	}
+	return TimeoutQueueExtended, *id, windowsLeftStorage.Set(windowsLeft - 1)
+}
+
+// ExpireIfDead deletes the retryable if it's provably dead: it has timed out with no timeout windows left, so it can
+// no longer be redeemed or kept alive, and the reaper would delete it on reaching any of its queue entries. Its queue
+// entries are left behind, to be discarded when they reach the head of the queue. Returns whether it was deleted.
+func (rs *RetryableState) ExpireIfDead(id common.Hash, currentTimestamp uint64, evm *vm.EVM, scenario util.TracingScenario) (bool, error) {
+	retryableStorage := rs.retryables.OpenSubStorage(id.Bytes())
+	timeout, err := retryableStorage.GetUint64ByUint64(timeoutOffset)
+	if err != nil || timeout == 0 || timeout >= currentTimestamp {
+		return false, err
+	}
+	windowsLeft, err := retryableStorage.GetUint64ByUint64(timeoutWindowsLeftOffset)
+	if err != nil || windowsLeft != 0 {
+		return false, err
 	}
-	return windowsLeftStorage.Set(windowsLeft - 1)
+	return rs.DeleteRetryable(id, evm, scenario)
 }
 
 func (retryable *Retryable) MakeTx(chainId *big.Int, nonce uint64, gasFeeCap *big.Int, gas uint64, ticketId common.Hash, refundTo common.Address, maxRefund *big.Int, submissionFeeRefund *big.Int) (*types.ArbitrumRetryTx, error) {
@@ -0,0 +1,183 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+// Package sponsorship keeps the policy under which a paymaster chosen by the chain owner pays the gas of user txs
+// calling allowlisted methods, how much gas the paymaster has approved paying, and how much gas each sender has been
+// sponsored.
+package sponsorship
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/nitro/arbos/storage"
+	"github.com/offchainlabs/nitro/util/arbmath"
+)
+
+const (
+	paymasterOffset uint64 = iota
+	accountBudgetOffset
+	totalSponsoredOffset
+)
+
+var (
+	selectorsKey = []byte{0}
+	sponsoredKey = []byte{1}
+	allowanceKey = []byte{2}
+)
+
+type Sponsorship struct {
+	paymaster      storage.StorageBackedAddress // the zero address if no txs are sponsored
+	accountBudget  storage.StorageBackedBigUint // wei of gas each sender may be sponsored in total
+	totalSponsored storage.StorageBackedBigUint
+	selectors      *storage.Storage // keyed by the hash of a target and method selector, nonzero if sponsored
+	sponsored      *storage.Storage // keyed by sender, the wei of gas sponsored so far
+	allowances     *storage.Storage // keyed by paymaster, the wei of gas it has approved paying and not yet paid
+}
+
+func Open(sto *storage.Storage) *Sponsorship {
+	return &Sponsorship{
+		paymaster:      sto.OpenStorageBackedAddress(paymasterOffset),
+		accountBudget:  sto.OpenStorageBackedBigUint(accountBudgetOffset),
+		totalSponsored: sto.OpenStorageBackedBigUint(totalSponsoredOffset),
+		selectors:      sto.OpenCachedSubStorage(selectorsKey),
+		sponsored:      sto.OpenSubStorage(sponsoredKey),
+		allowances:     sto.OpenSubStorage(allowanceKey),
+	}
+}
+
+// Paymaster returns the account whose balance pays for sponsored gas, or the zero address if none is set.
+func (s *Sponsorship) Paymaster() (common.Address, error) {
+	return s.paymaster.Get()
+}
+
+func (s *Sponsorship) SetPaymaster(paymaster common.Address) error {
+	return s.paymaster.Set(paymaster)
+}
+
+func (s *Sponsorship) AccountBudget() (*big.Int, error) {
+	return s.accountBudget.Get()
+}
+
+func (s *Sponsorship) SetAccountBudget(budget *big.Int) error {
+	return s.accountBudget.SetChecked(budget)
+}
+
+// TotalSponsored returns the wei of gas sponsored across all senders.
+func (s *Sponsorship) TotalSponsored() (*big.Int, error) {
+	return s.totalSponsored.Get()
+}
+
+func (s *Sponsorship) selectorKey(target common.Address, selector [4]byte) (common.Hash, error) {
+	return s.selectors.KeccakHash(target.Bytes(), selector[:])
+}
+
+// IsSponsoredMethod returns whether calls to the method of the target contract are sponsored.
+func (s *Sponsorship) IsSponsoredMethod(target common.Address, selector [4]byte) (bool, error) {
+	key, err := s.selectorKey(target, selector)
+	if err != nil {
+		return false, err
+	}
+	value, err := s.selectors.GetUint64(key)
+	return value != 0, err
+}
+
+func (s *Sponsorship) SetSponsoredMethod(target common.Address, selector [4]byte, sponsored bool) error {
+	key, err := s.selectorKey(target, selector)
+	if err != nil {
+		return err
+	}
+	if !sponsored {
+		return s.selectors.Clear(key)
+	}
+	return s.selectors.SetUint64(key, 1)
+}
+
+// Allowance returns the wei of gas the paymaster has approved paying for others and not yet paid.
+func (s *Sponsorship) Allowance(paymaster common.Address) (*big.Int, error) {
+	value, err := s.allowances.Get(common.BytesToHash(paymaster.Bytes()))
+	return value.Big(), err
+}
+
+// SetAllowance sets the wei of gas the paymaster approves paying for others. Only the paymaster itself may approve
+// it, as sponsored gas comes out of its balance.
+func (s *Sponsorship) SetAllowance(paymaster common.Address, allowance *big.Int) error {
+	if allowance.Sign() < 0 || allowance.BitLen() > 256 {
+		return errors.New("allowance out of bounds")
+	}
+	return s.allowances.Set(common.BytesToHash(paymaster.Bytes()), common.BigToHash(allowance))
+}
+
+// Sponsored returns the wei of gas sponsored for the sender so far.
+func (s *Sponsorship) Sponsored(sender common.Address) (*big.Int, error) {
+	value, err := s.sponsored.Get(common.BytesToHash(sender.Bytes()))
+	return value.Big(), err
+}
+
+// RemainingBudget returns the wei of gas that may still be sponsored for the sender.
+func (s *Sponsorship) RemainingBudget(sender common.Address) (*big.Int, error) {
+	budget, err := s.accountBudget.Get()
+	if err != nil {
+		return nil, err
+	}
+	sponsored, err := s.Sponsored(sender)
+	if err != nil {
+		return nil, err
+	}
+	return arbmath.BigMax(arbmath.BigSub(budget, sponsored), common.Big0), nil
+}
+
+// RecordSponsored books gas paid for by the paymaster against its allowance and the sender's budget. The amount
+// must be within the sender's remaining budget, which keeps the sender's total within 256 bits.
+func (s *Sponsorship) RecordSponsored(paymaster common.Address, sender common.Address, amount *big.Int) error {
+	allowance, err := s.Allowance(paymaster)
+	if err != nil {
+		return err
+	}
+	allowance = arbmath.BigMax(arbmath.BigSub(allowance, amount), common.Big0)
+	if err := s.allowances.Set(common.BytesToHash(paymaster.Bytes()), common.BigToHash(allowance)); err != nil {
+		return err
+	}
+	sponsored, err := s.Sponsored(sender)
+	if err != nil {
+		return err
+	}
+	sponsored = arbmath.BigAdd(sponsored, amount)
+	if err := s.sponsored.Set(common.BytesToHash(sender.Bytes()), common.BigToHash(sponsored)); err != nil {
+		return err
+	}
+	total, err := s.totalSponsored.Get()
+	if err != nil {
+		return err
+	}
+	return s.totalSponsored.SetSaturatingWithWarning(arbmath.BigAdd(total, amount), "total gas sponsored")
+}
+
+// Advance returns the paymaster and what it advances a tx's sender to buy gas, or a nil advance if the tx doesn't
+// qualify: it must call a sponsored method, and its gas limit at its fee cap must be within both the sender's
+// remaining budget and the paymaster's allowance. Whether the paymaster can afford the advance is left to the caller.
+func (s *Sponsorship) Advance(sender common.Address, to *common.Address, data []byte, gasFeeCap *big.Int, gasLimit uint64) (common.Address, *big.Int, error) {
+	if to == nil || len(data) < 4 || gasFeeCap == nil || gasFeeCap.Sign() <= 0 {
+		return common.Address{}, nil, nil
+	}
+	paymaster, err := s.Paymaster()
+	if err != nil || paymaster == (common.Address{}) || paymaster == sender {
+		return common.Address{}, nil, err
+	}
+	sponsored, err := s.IsSponsoredMethod(*to, [4]byte(data[:4]))
+	if err != nil || !sponsored {
+		return common.Address{}, nil, err
+	}
+	advance := arbmath.BigMulByUint(gasFeeCap, gasLimit)
+	remaining, err := s.RemainingBudget(sender)
+	if err != nil || arbmath.BigLessThan(remaining, advance) {
+		return common.Address{}, nil, err
+	}
+	allowance, err := s.Allowance(paymaster)
+	if err != nil || arbmath.BigLessThan(allowance, advance) {
+		return common.Address{}, nil, err
+	}
+	return paymaster, advance, nil
+}
@@ -0,0 +1,99 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package sponsorship
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/nitro/arbos/burn"
+	"github.com/offchainlabs/nitro/arbos/storage"
+	"github.com/offchainlabs/nitro/util/testhelpers"
+)
+
+func TestSponsorshipAdvance(t *testing.T) {
+	s := Open(storage.NewMemoryBacked(burn.NewSystemBurner(nil, false)))
+	paymaster := common.HexToAddress("0xfee")
+	sender := common.HexToAddress("0xa11ce")
+	target := common.HexToAddress("0xc0de")
+	selector := [4]byte{1, 2, 3, 4}
+	data := append(selector[:], 0xff)
+	feeCap := big.NewInt(100)
+
+	advance := func() *big.Int {
+		t.Helper()
+		_, advance, err := s.Advance(sender, &target, data, feeCap, 1000)
+		Require(t, err)
+		return advance
+	}
+
+	Require(t, s.SetAccountBudget(big.NewInt(250_000)))
+	Require(t, s.SetSponsoredMethod(target, selector, true))
+	if advance() != nil {
+		Fail(t, "sponsored a tx without a paymaster")
+	}
+	Require(t, s.SetPaymaster(paymaster))
+	if advance() != nil {
+		Fail(t, "sponsored a tx without the paymaster's approval")
+	}
+	Require(t, s.SetAllowance(paymaster, big.NewInt(1_000_000)))
+	if got := advance(); got == nil || got.Cmp(big.NewInt(100_000)) != 0 {
+		Fail(t, "wrong advance", got)
+	}
+	if _, got, err := s.Advance(sender, &target, selector[:3], feeCap, 1000); err != nil || got != nil {
+		Fail(t, "sponsored a tx without a method selector", got, err)
+	}
+	if _, got, err := s.Advance(paymaster, &target, data, feeCap, 1000); err != nil || got != nil {
+		Fail(t, "sponsored the paymaster's own tx", got, err)
+	}
+
+	// the advance must fit in what's left of the budget, which only the gas used is booked against
+	Require(t, s.RecordSponsored(paymaster, sender, big.NewInt(120_000)))
+	if got := advance(); got == nil {
+		Fail(t, "didn't sponsor a tx within the remaining budget")
+	}
+	Require(t, s.RecordSponsored(paymaster, sender, big.NewInt(40_000)))
+	if got := advance(); got != nil {
+		Fail(t, "sponsored a tx beyond the remaining budget", got)
+	}
+	sponsored, err := s.Sponsored(sender)
+	Require(t, err)
+	remaining, err := s.RemainingBudget(sender)
+	Require(t, err)
+	total, err := s.TotalSponsored()
+	Require(t, err)
+	allowance, err := s.Allowance(paymaster)
+	Require(t, err)
+	if sponsored.Int64() != 160_000 || remaining.Int64() != 90_000 || total.Int64() != 160_000 || allowance.Int64() != 840_000 {
+		Fail(t, "wrong sponsorship accounting", sponsored, remaining, total, allowance)
+	}
+
+	// the paymaster's allowance caps sponsorship like the sender's budget does
+	Require(t, s.SetAccountBudget(big.NewInt(1_000_000)))
+	Require(t, s.SetAllowance(paymaster, big.NewInt(99_999)))
+	if got := advance(); got != nil {
+		Fail(t, "sponsored a tx beyond the paymaster's allowance", got)
+	}
+	Require(t, s.SetAllowance(paymaster, big.NewInt(100_000)))
+	if got := advance(); got == nil {
+		Fail(t, "didn't sponsor a tx within the paymaster's allowance")
+	}
+
+	Require(t, s.SetSponsoredMethod(target, selector, false))
+	if got := advance(); got != nil {
+		Fail(t, "sponsored a method no longer sponsored", got)
+	}
+}
+
+func Require(t *testing.T, err error, printables ...interface{}) {
+	t.Helper()
+	testhelpers.RequireImpl(t, err, printables...)
+}
+
+func Fail(t *testing.T, printables ...interface{}) {
+	t.Helper()
+	testhelpers.FailImpl(t, printables...)
+}
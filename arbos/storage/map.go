@@ -0,0 +1,189 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package storage
+
+import (
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// StorageBackedMap is a map from hashes to hashes that supports iteration.
+// size is stored at position 0
+// keys are stored sequentially from 1 onward, in insertion order until a key is deleted,
+// at which point the last key takes the deleted key's place
+// each key's 1-based position in the key list is stored in the index substorage
+// each key's value is stored in the values substorage
+type StorageBackedMap struct {
+	keys    *Storage
+	size    StorageBackedUint64
+	indexes *Storage
+	values  *Storage
+}
+
+var ErrMapCorrupt = errors.New("storage backed map is corrupt")
+
+func InitializeStorageBackedMap(sto *Storage) error {
+	return sto.SetUint64ByUint64(0, 0)
+}
+
+func OpenStorageBackedMap(sto *Storage) *StorageBackedMap {
+	return &StorageBackedMap{
+		keys:    sto.WithoutCache(),
+		size:    sto.OpenStorageBackedUint64(0),
+		indexes: sto.OpenSubStorage([]byte{0}),
+		values:  sto.OpenSubStorage([]byte{1}),
+	}
+}
+
+func (m *StorageBackedMap) Size() (uint64, error) {
+	return m.size.Get()
+}
+
+func (m *StorageBackedMap) Contains(key common.Hash) (bool, error) {
+	index, err := m.indexes.GetUint64(key)
+	return index != 0, err
+}
+
+// Get returns the value of key, and whether the key is present. Zero values may be stored.
+func (m *StorageBackedMap) Get(key common.Hash) (common.Hash, bool, error) {
+	contains, err := m.Contains(key)
+	if !contains || err != nil {
+		return common.Hash{}, false, err
+	}
+	value, err := m.values.Get(key)
+	return value, true, err
+}
+
+// Set inserts key or updates its value. New keys are appended to the end of the iteration order.
+func (m *StorageBackedMap) Set(key common.Hash, value common.Hash) error {
+	contains, err := m.Contains(key)
+	if err != nil {
+		return err
+	}
+	if !contains {
+		size, err := m.size.Increment()
+		if err != nil {
+			return err
+		}
+		if err := m.keys.SetByUint64(size, key); err != nil {
+			return err
+		}
+		if err := m.indexes.SetUint64(key, size); err != nil {
+			return err
+		}
+	}
+	return m.values.Set(key, value)
+}
+
+// Delete removes key, returning whether it was present.
+// The last key in the iteration order is moved into the deleted key's position.
+func (m *StorageBackedMap) Delete(key common.Hash) (bool, error) {
+	index, err := m.indexes.GetUint64(key)
+	if index == 0 || err != nil {
+		return false, err
+	}
+	size, err := m.size.Get()
+	if err != nil {
+		return false, err
+	}
+	if index > size {
+		return false, ErrMapCorrupt
+	}
+	if index != size {
+		lastKey, err := m.keys.GetByUint64(size)
+		if err != nil {
+			return false, err
+		}
+		if err := m.keys.SetByUint64(index, lastKey); err != nil {
+			return false, err
+		}
+		if err := m.indexes.SetUint64(lastKey, index); err != nil {
+			return false, err
+		}
+	}
+	if err := m.keys.ClearByUint64(size); err != nil {
+		return false, err
+	}
+	if err := m.indexes.Clear(key); err != nil {
+		return false, err
+	}
+	if err := m.values.Clear(key); err != nil {
+		return false, err
+	}
+	if _, err := m.size.Decrement(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ForEach applies a closure to the map's entries in iteration order, stopping early if it returns true.
+// The closure must not modify the map.
+func (m *StorageBackedMap) ForEach(closure func(index uint64, key common.Hash, value common.Hash) (bool, error)) error {
+	size, err := m.size.Get()
+	if err != nil {
+		return err
+	}
+	for index := uint64(0); index < size; index++ {
+		key, err := m.keys.GetByUint64(index + 1)
+		if err != nil {
+			return err
+		}
+		value, err := m.values.Get(key)
+		if err != nil {
+			return err
+		}
+		done, err := closure(index, key, value)
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+	}
+	return nil
+}
+
+// Keys returns up to maxNumToReturn keys in iteration order.
+func (m *StorageBackedMap) Keys(maxNumToReturn uint64) ([]common.Hash, error) {
+	size, err := m.size.Get()
+	if err != nil {
+		return nil, err
+	}
+	if size > maxNumToReturn {
+		size = maxNumToReturn
+	}
+	keys := make([]common.Hash, size)
+	for i := range keys {
+		keys[i], err = m.keys.GetByUint64(uint64(i + 1))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
+
+// Clear removes every entry, costing storage writes proportional to the size of the map.
+func (m *StorageBackedMap) Clear() error {
+	size, err := m.size.Get()
+	if err != nil || size == 0 {
+		return err
+	}
+	for i := uint64(1); i <= size; i++ {
+		key, err := m.keys.GetByUint64(i)
+		if err != nil {
+			return err
+		}
+		if err := m.keys.ClearByUint64(i); err != nil {
+			return err
+		}
+		if err := m.indexes.Clear(key); err != nil {
+			return err
+		}
+		if err := m.values.Clear(key); err != nil {
+			return err
+		}
+	}
+	return m.size.Clear()
+}
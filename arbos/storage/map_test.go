@@ -0,0 +1,109 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package storage
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/nitro/arbos/burn"
+	"github.com/offchainlabs/nitro/util/testhelpers"
+)
+
+func checkMapMatches(t *testing.T, m *StorageBackedMap, expected map[common.Hash]common.Hash) {
+	t.Helper()
+	size, err := m.Size()
+	testhelpers.RequireImpl(t, err)
+	if size != uint64(len(expected)) {
+		t.Fatal("wrong size", size, len(expected))
+	}
+	seen := make(map[common.Hash]bool)
+	err = m.ForEach(func(index uint64, key common.Hash, value common.Hash) (bool, error) {
+		if seen[key] {
+			t.Fatal("key visited twice", key)
+		}
+		seen[key] = true
+		if expectedValue, ok := expected[key]; !ok || value != expectedValue {
+			t.Fatal("wrong entry", key, value, expectedValue)
+		}
+		return false, nil
+	})
+	testhelpers.RequireImpl(t, err)
+	if len(seen) != len(expected) {
+		t.Fatal("iteration missed keys", len(seen), len(expected))
+	}
+	for key, expectedValue := range expected {
+		value, ok, err := m.Get(key)
+		testhelpers.RequireImpl(t, err)
+		if !ok || value != expectedValue {
+			t.Fatal("wrong value for", key)
+		}
+	}
+}
+
+func TestStorageBackedMap(t *testing.T) {
+	sto := NewMemoryBacked(burn.NewSystemBurner(nil, false))
+	testhelpers.RequireImpl(t, InitializeStorageBackedMap(sto))
+	m := OpenStorageBackedMap(sto)
+	expected := make(map[common.Hash]common.Hash)
+
+	rng := rand.New(rand.NewSource(1))
+	randomKey := func() common.Hash {
+		return common.BigToHash(big.NewInt(rng.Int63n(40)))
+	}
+	for i := 0; i < 500; i++ {
+		key := randomKey()
+		if rng.Intn(3) == 0 {
+			deleted, err := m.Delete(key)
+			testhelpers.RequireImpl(t, err)
+			if _, ok := expected[key]; ok != deleted {
+				t.Fatal("wrong delete result for", key)
+			}
+			delete(expected, key)
+		} else {
+			// zero values are legitimate entries
+			value := common.BigToHash(big.NewInt(rng.Int63n(3)))
+			testhelpers.RequireImpl(t, m.Set(key, value))
+			expected[key] = value
+		}
+		checkMapMatches(t, m, expected)
+	}
+
+	keys, err := m.Keys(5)
+	testhelpers.RequireImpl(t, err)
+	if len(keys) != 5 {
+		t.Fatal("wrong number of keys returned", len(keys))
+	}
+
+	testhelpers.RequireImpl(t, m.Clear())
+	checkMapMatches(t, m, map[common.Hash]common.Hash{})
+	if _, ok, err := m.Get(keys[0]); ok || err != nil {
+		t.Fatal("cleared key still present", err)
+	}
+}
+
+func TestStorageBackedMapOrder(t *testing.T) {
+	sto := NewMemoryBacked(burn.NewSystemBurner(nil, false))
+	m := OpenStorageBackedMap(sto)
+	for i := int64(1); i <= 4; i++ {
+		testhelpers.RequireImpl(t, m.Set(common.BigToHash(big.NewInt(i)), common.Hash{}))
+	}
+	_, err := m.Delete(common.BigToHash(big.NewInt(2)))
+	testhelpers.RequireImpl(t, err)
+	keys, err := m.Keys(10)
+	testhelpers.RequireImpl(t, err)
+	// the last key takes the place of the deleted one
+	order := []int64{1, 4, 3}
+	if len(keys) != len(order) {
+		t.Fatal("wrong number of keys", len(keys))
+	}
+	for i := range order {
+		if keys[i].Big().Int64() != order[i] {
+			t.Fatal("unexpected order", keys)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package storage
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// Storage access profiling tallies reads, writes and the gas burned for them per top-level subspace of the
+// ArbOS storage tree. It's process wide and meant for debugging, so it counts accesses from every state
+// that's opened while enabled, including those made by RPC calls. Profiling never changes what is burned.
+
+// SubspaceAccessStats are the storage accesses attributed to one top-level subspace.
+type SubspaceAccessStats struct {
+	Reads     uint64 `json:"reads"`
+	Writes    uint64 `json:"writes"`
+	BurnedGas uint64 `json:"burnedGas"`
+}
+
+type subspaceCounters struct {
+	reads     atomic.Uint64
+	writes    atomic.Uint64
+	burnedGas atomic.Uint64
+}
+
+func (c *subspaceCounters) recordRead() {
+	c.reads.Add(1)
+	c.burnedGas.Add(StorageReadCost)
+}
+
+func (c *subspaceCounters) recordWrite(cost uint64) {
+	c.writes.Add(1)
+	c.burnedGas.Add(cost)
+}
+
+type accessProfiler struct {
+	mutex     sync.Mutex
+	subspaces map[string]*subspaceCounters
+}
+
+const rootSubspaceName = "root"
+
+var activeProfiler atomic.Pointer[accessProfiler]
+
+var subspaceNamesMutex sync.RWMutex
+var subspaceNames = make(map[string]string)
+
+// RegisterSubspaceName names a top-level subspace in storage access profiles.
+// Unnamed subspaces are reported by their id in hex.
+func RegisterSubspaceName(id []byte, name string) {
+	subspaceNamesMutex.Lock()
+	defer subspaceNamesMutex.Unlock()
+	subspaceNames[string(id)] = name
+}
+
+func subspaceName(id []byte) string {
+	subspaceNamesMutex.RLock()
+	defer subspaceNamesMutex.RUnlock()
+	if name, ok := subspaceNames[string(id)]; ok {
+		return name
+	}
+	return fmt.Sprintf("0x%x", id)
+}
+
+func (p *accessProfiler) counters(name string) *subspaceCounters {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	counters, ok := p.subspaces[name]
+	if !ok {
+		counters = &subspaceCounters{}
+		p.subspaces[name] = counters
+	}
+	return counters
+}
+
+// StartAccessProfiling begins tallying storage accesses, discarding any previous profile.
+// Only states opened after this call are profiled.
+func StartAccessProfiling() {
+	activeProfiler.Store(&accessProfiler{subspaces: make(map[string]*subspaceCounters)})
+}
+
+// StopAccessProfiling stops tallying storage accesses of newly opened states and returns the final profile.
+func StopAccessProfiling() map[string]SubspaceAccessStats {
+	profile := AccessProfile()
+	activeProfiler.Store(nil)
+	return profile
+}
+
+func AccessProfilingEnabled() bool {
+	return activeProfiler.Load() != nil
+}
+
+// AccessProfile returns a snapshot of the current profile, or nil if profiling isn't enabled.
+func AccessProfile() map[string]SubspaceAccessStats {
+	profiler := activeProfiler.Load()
+	if profiler == nil {
+		return nil
+	}
+	profiler.mutex.Lock()
+	defer profiler.mutex.Unlock()
+	profile := make(map[string]SubspaceAccessStats, len(profiler.subspaces))
+	for name, counters := range profiler.subspaces {
+		profile[name] = SubspaceAccessStats{
+			Reads:     counters.reads.Load(),
+			Writes:    counters.writes.Load(),
+			BurnedGas: counters.burnedGas.Load(),
+		}
+	}
+	return profile
+}
+
+// profiledStorage holds the state needed to attribute accesses of a Storage and its children.
+type profiledStorage struct {
+	profiler *accessProfiler
+	counters *subspaceCounters
+	isRoot   bool
+}
+
+func newRootProfile() *profiledStorage {
+	profiler := activeProfiler.Load()
+	if profiler == nil {
+		return nil
+	}
+	return &profiledStorage{profiler, profiler.counters(rootSubspaceName), true}
+}
+
+// child attributes accesses of a subspace to itself if it's top-level, or to its top-level ancestor otherwise.
+func (p *profiledStorage) child(id []byte) *profiledStorage {
+	if p == nil || !p.isRoot {
+		return p
+	}
+	return &profiledStorage{p.profiler, p.profiler.counters(subspaceName(id)), false}
+}
@@ -0,0 +1,58 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package storage
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/nitro/arbos/burn"
+	"github.com/offchainlabs/nitro/util/testhelpers"
+)
+
+func TestAccessProfiling(t *testing.T) {
+	unprofiled := NewMemoryBacked(burn.NewSystemBurner(nil, false))
+	if unprofiled.profile != nil {
+		t.Fatal("storage profiled without profiling enabled")
+	}
+
+	RegisterSubspaceName([]byte{0xaa}, "test-subspace")
+	StartAccessProfiling()
+	defer StopAccessProfiling()
+
+	sto := NewMemoryBacked(burn.NewSystemBurner(nil, false))
+	_, err := sto.GetByUint64(1)
+	testhelpers.RequireImpl(t, err)
+
+	named := sto.OpenCachedSubStorage([]byte{0xaa})
+	testhelpers.RequireImpl(t, named.SetByUint64(1, common.HexToHash("0x01")))
+	testhelpers.RequireImpl(t, named.ClearByUint64(1))
+	// accesses of nested subspaces and storage backed values count towards the top-level subspace
+	nested := named.OpenSubStorage([]byte{1}).OpenStorageBackedUint64(3)
+	_, err = nested.Get()
+	testhelpers.RequireImpl(t, err)
+
+	unnamed := sto.OpenSubStorage([]byte{0xbb})
+	_, err = unnamed.GetByUint64(0)
+	testhelpers.RequireImpl(t, err)
+
+	profile := StopAccessProfiling()
+	expected := map[string]SubspaceAccessStats{
+		rootSubspaceName: {Reads: 1, BurnedGas: StorageReadCost},
+		"test-subspace":  {Reads: 1, Writes: 2, BurnedGas: StorageReadCost + StorageWriteCost + StorageWriteZeroCost},
+		"0xbb":           {Reads: 1, BurnedGas: StorageReadCost},
+	}
+	if len(profile) != len(expected) {
+		t.Fatal("unexpected subspaces in profile", profile)
+	}
+	for name, stats := range expected {
+		if profile[name] != stats {
+			t.Fatal("unexpected stats for", name, profile[name], stats)
+		}
+	}
+	if AccessProfilingEnabled() || AccessProfile() != nil {
+		t.Fatal("profiling still enabled after stop")
+	}
+}
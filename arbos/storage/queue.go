@@ -83,6 +83,21 @@ func (q *Queue) Put(val common.Hash) error {
 	return q.storage.SetByUint64(newOffset-1, val)
 }
 
+// Bounds returns the offset of the queue's first entry and the offset after its last, which are equal if it's empty.
+func (q *Queue) Bounds() (uint64, uint64, error) {
+	get, err := q.nextGetOffset.Get()
+	if err != nil {
+		return 0, 0, err
+	}
+	put, err := q.nextPutOffset.Get()
+	return get, put, err
+}
+
+// GetByOffset returns the entry at an offset within the queue's bounds without removing it.
+func (q *Queue) GetByOffset(offset uint64) (common.Hash, error) {
+	return q.storage.GetByUint64(offset)
+}
+
 // Shift reset the queue to its starting state
 // If the queue is empty, this method will return true and reset its state
 // This is useful for testing
@@ -52,6 +52,7 @@ type Storage struct {
 	storageKey []byte
 	burner     burn.Burner
 	hashCache  *lru.Cache[string, []byte]
+	profile    *profiledStorage // nil unless access profiling was enabled when the root storage was opened
 }
 
 const StorageReadCost = params.SloadGasEIP2200
@@ -74,6 +75,7 @@ func NewGeth(statedb vm.StateDB, burner burn.Burner) *Storage {
 		storageKey: []byte{},
 		burner:     burner,
 		hashCache:  storageHashCache,
+		profile:    newRootProfile(),
 	}
 }
 
@@ -128,6 +130,9 @@ func (s *Storage) Get(key common.Hash) (common.Hash, error) {
 	if err != nil {
 		return common.Hash{}, err
 	}
+	if s.profile != nil {
+		s.profile.counters.recordRead()
+	}
 	if info := s.burner.TracingInfo(); info != nil {
 		info.RecordStorageGet(key)
 	}
@@ -161,10 +166,14 @@ func (s *Storage) Set(key common.Hash, value common.Hash) error {
 		log.Error("Read-only burner attempted to mutate state", "key", key, "value", value)
 		return vm.ErrWriteProtection
 	}
-	err := s.burner.Burn(writeCost(value))
+	cost := writeCost(value)
+	err := s.burner.Burn(cost)
 	if err != nil {
 		return err
 	}
+	if s.profile != nil {
+		s.profile.counters.recordWrite(cost)
+	}
 	if info := s.burner.TracingInfo(); info != nil {
 		info.RecordStorageSet(key, value)
 	}
@@ -215,6 +224,7 @@ func (s *Storage) OpenCachedSubStorage(id []byte) *Storage {
 		storageKey: s.cachedKeccak(s.storageKey, id),
 		burner:     s.burner,
 		hashCache:  storageHashCache,
+		profile:    s.profile.child(id),
 	}
 }
 func (s *Storage) OpenSubStorage(id []byte) *Storage {
@@ -224,6 +234,7 @@ func (s *Storage) OpenSubStorage(id []byte) *Storage {
 		storageKey: s.cachedKeccak(s.storageKey, id),
 		burner:     s.burner,
 		hashCache:  nil,
+		profile:    s.profile.child(id),
 	}
 }
 
@@ -236,6 +247,7 @@ func (s *Storage) WithoutCache() *Storage {
 		storageKey: s.storageKey,
 		burner:     s.burner,
 		hashCache:  nil,
+		profile:    s.profile,
 	}
 }
 
@@ -364,10 +376,11 @@ type StorageSlot struct {
 	db      vm.StateDB
 	slot    common.Hash
 	burner  burn.Burner
+	profile *profiledStorage
 }
 
 func (s *Storage) NewSlot(offset uint64) StorageSlot {
-	return StorageSlot{s.account, s.db, s.mapAddress(util.UintToHash(offset)), s.burner}
+	return StorageSlot{s.account, s.db, s.mapAddress(util.UintToHash(offset)), s.burner, s.profile}
 }
 
 func (ss *StorageSlot) Get() (common.Hash, error) {
@@ -375,6 +388,9 @@ func (ss *StorageSlot) Get() (common.Hash, error) {
 	if err != nil {
 		return common.Hash{}, err
 	}
+	if ss.profile != nil {
+		ss.profile.counters.recordRead()
+	}
 	if info := ss.burner.TracingInfo(); info != nil {
 		info.RecordStorageGet(ss.slot)
 	}
@@ -386,10 +402,14 @@ func (ss *StorageSlot) Set(value common.Hash) error {
 		log.Error("Read-only burner attempted to mutate state", "value", value)
 		return vm.ErrWriteProtection
 	}
-	err := ss.burner.Burn(writeCost(value))
+	cost := writeCost(value)
+	err := ss.burner.Burn(cost)
 	if err != nil {
 		return err
 	}
+	if ss.profile != nil {
+		ss.profile.counters.recordWrite(cost)
+	}
 	if info := ss.burner.TracingInfo(); info != nil {
 		info.RecordStorageSet(ss.slot, value)
 	}
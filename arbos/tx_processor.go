@@ -39,6 +39,9 @@ type TxProcessor struct {
 	state            *arbosState.ArbosState
 	PosterFee        *big.Int // set once in GasChargingHook to track L1 calldata costs
 	posterGas        uint64
+	posterUnits      uint64 // L1 calldata units charged to the tx, set once in GasChargingHook
+	codeDepositGas   uint64 // gas charged for the code deposit fee on the code the tx deployed, set once in ForceRefundGas
+	surchargeGas     uint64 // gas bought to pay what the chain charges for calldata above the poster's costs
 	computeHoldGas   uint64 // amount of gas temporarily held to prevent compute from exceeding the gas limit
 	delayedInbox     bool   // whether this tx was submitted through the delayed inbox
 	Contracts        []*vm.Contract
@@ -47,6 +50,14 @@ type TxProcessor struct {
 	evm              *vm.EVM
 	CurrentRetryable *common.Hash
 	CurrentRefundTo  *common.Address
+	sponsorAdvance   *big.Int       // wei the paymaster advanced the sender to buy gas, or nil if the tx isn't sponsored
+	paymaster        common.Address // the paymaster that advanced sponsorAdvance
+
+	// Tracking for the code deposit fee, which is charged per byte of code any of the tx's creations deploy
+	codeDepositGasPerByte uint64                    // zero if the tx isn't charged, set once in GasChargingHook
+	deploying             map[*vm.Contract]struct{} // creation frames whose init code is running
+	deployed              []common.Address          // addresses the tx's creations may have deployed code to
+	heldDepositGas        uint64                    // gas held back from creations to pay the fee once the tx ends
 
 	// Caches for the latest L1 block number and hash,
 	// for the NUMBER and BLOCKHASH opcodes.
@@ -80,6 +91,9 @@ func (p *TxProcessor) PushContract(contract *vm.Contract) {
 	if !contract.IsDelegateOrCallcode() {
 		p.Programs[contract.Address()]++
 	}
+	if p.codeDepositGasPerByte > 0 && p.isCreation(contract) {
+		p.deploying[contract] = struct{}{}
+	}
 }
 
 func (p *TxProcessor) PopContract() {
@@ -90,6 +104,57 @@ func (p *TxProcessor) PopContract() {
 	if !popped.IsDelegateOrCallcode() {
 		p.Programs[popped.Address()]--
 	}
+	if _, ok := p.deploying[popped]; ok {
+		delete(p.deploying, popped)
+		p.holdCodeDeposit(popped)
+	}
+}
+
+// isCreation returns whether a frame runs init code, which is the only code the EVM runs at an address without code.
+func (p *TxProcessor) isCreation(contract *vm.Contract) bool {
+	if contract.IsDelegateOrCallcode() || contract.CodeAddr == nil || *contract.CodeAddr != contract.Address() {
+		return false
+	}
+	return len(contract.Code) > 0 && p.evm.StateDB.GetCodeSize(contract.Address()) == 0
+}
+
+// holdCodeDeposit holds back the gas a creation needs to pay the code deposit fee once its init code has returned.
+// The EVM then charges CreateDataGas per byte from what's left to store the code, so a creation that stores its code
+// deployed at most gas / (CreateDataGas + codeDepositGasPerByte) bytes, and the hold covers the fee on that many.
+// A creation that can't pay for both fails to store its code as if out of gas.
+func (p *TxProcessor) holdCodeDeposit(creation *vm.Contract) {
+	perByte := p.codeDepositGasPerByte
+	perStoredByte := arbmath.SaturatingUAdd(perByte, params.CreateDataGas)
+	maxFee := arbmath.BigMulByUint(arbmath.UintToBig(creation.Gas), perByte)
+	held := arbmath.BigToUintSaturating(arbmath.BigDivByUint(arbmath.BigAddByUint(maxFee, perStoredByte-1), perStoredByte))
+	held = arbmath.MinInt(held, creation.Gas)
+
+	creation.Gas -= held
+	p.heldDepositGas += held
+	p.deployed = append(p.deployed, creation.Address())
+}
+
+// settleCodeDeposits charges the code deposit fee on the code the tx's creations deployed from the gas held back
+// for it, now that their code is final, and returns the rest of the hold to be refunded.
+// Creations that reverted, or whose code was destroyed within the tx, deployed no code and so pay nothing.
+func (p *TxProcessor) settleCodeDeposits() uint64 {
+	if len(p.deployed) == 0 {
+		return 0
+	}
+	var codeSize uint64
+	counted := make(map[common.Address]struct{}, len(p.deployed))
+	for _, address := range p.deployed {
+		if _, ok := counted[address]; !ok {
+			counted[address] = struct{}{}
+			codeSize += uint64(p.evm.StateDB.GetCodeSize(address))
+		}
+	}
+	fee := arbmath.SaturatingUMul(codeSize, p.codeDepositGasPerByte)
+	p.codeDepositGas = arbmath.MinInt(fee, p.heldDepositGas)
+	refund := p.heldDepositGas - p.codeDepositGas
+	p.deployed = nil
+	p.heldDepositGas = 0
+	return refund
 }
 
 // Attempts to subtract up to `take` from `pool` without going negative.
@@ -176,6 +241,9 @@ func (p *TxProcessor) StartTxHook() (endTxNow bool, gasUsed uint64, err error, r
 			return true, 0, errors.New("eth deposit has no To address"), nil
 		}
 		util.MintBalance(&from, value, evm, util.TracingBeforeEVM, "deposit")
+		if p.state.ArbOSVersion() >= arbosState.ArbosVersion_FeeToken {
+			p.state.Restrict(p.state.FeeTokenState().RecordDeposit(value))
+		}
 		defer (startTracer())()
 		// We intentionally use the variant here that doesn't do tracing,
 		// because this transfer is represented as the outer eth transaction.
@@ -204,6 +272,9 @@ func (p *TxProcessor) StartTxHook() (endTxNow bool, gasUsed uint64, err error, r
 		availableRefund := new(big.Int).Set(tx.DepositValue)
 		takeFunds(availableRefund, tx.RetryValue)
 		util.MintBalance(&tx.From, tx.DepositValue, evm, scenario, "deposit")
+		if p.state.ArbOSVersion() >= arbosState.ArbosVersion_FeeToken {
+			p.state.Restrict(p.state.FeeTokenState().RecordDeposit(tx.DepositValue))
+		}
 
 		transfer := func(from, to *common.Address, amount *big.Int) error {
 			return util.TransferBalance(from, to, amount, evm, scenario, "during evm execution")
@@ -219,7 +290,13 @@ func (p *TxProcessor) StartTxHook() (endTxNow bool, gasUsed uint64, err error, r
 			return true, 0, err, nil
 		}
 
-		submissionFee := retryables.RetryableSubmissionFee(len(tx.RetryData), tx.L1BaseFee)
+		l1BaseFee := tx.L1BaseFee
+		if p.state.ArbOSVersion() >= arbosState.ArbosVersion_FeeToken {
+			// the submission fee is paid in the native token, while the L1 basefee is in parent chain wei
+			l1BaseFee, err = p.state.FeeTokenState().FromParentChainWei(l1BaseFee)
+			p.state.Restrict(err)
+		}
+		submissionFee := retryables.RetryableSubmissionFee(len(tx.RetryData), l1BaseFee)
 		if arbmath.BigLessThan(tx.MaxSubmissionFee, submissionFee) {
 			// should be impossible as this is checked at L1
 			err := fmt.Errorf(
@@ -277,6 +354,7 @@ func (p *TxProcessor) StartTxHook() (endTxNow bool, gasUsed uint64, err error, r
 			tx.RetryData,
 		)
 		p.state.Restrict(err)
+		p.state.Restrict(p.state.RecordStatistic(arbosState.StatRetryablesCreated, 1))
 
 		err = EmitTicketCreatedEvent(evm, ticketId)
 		if err != nil {
@@ -356,6 +434,10 @@ func (p *TxProcessor) StartTxHook() (endTxNow bool, gasUsed uint64, err error, r
 
 		_, err = retryable.IncrementNumTries()
 		p.state.Restrict(err)
+		if p.state.FeatureEnabled(arbosState.FeatureReceiptRedeemParent) {
+			// the auto-redeem's parent is the submission, whose hash is the ticket id
+			p.state.Restrict(retryable.SetRedeemParent(ticketId))
+		}
 
 		err = EmitReedeemScheduledEvent(
 			evm,
@@ -397,10 +479,40 @@ func (p *TxProcessor) StartTxHook() (endTxNow bool, gasUsed uint64, err error, r
 		refundTo := tx.RefundTo
 		p.CurrentRetryable = &ticketId
 		p.CurrentRefundTo = &refundTo
+		if p.state.FeatureEnabled(arbosState.FeatureReceiptRedeemParent) {
+			p.linkRedeemParent(ticketId)
+		}
+	case *types.LegacyTx, *types.AccessListTx, *types.DynamicFeeTx:
+		p.sponsorGas()
 	}
 	return false, 0, nil, nil
 }
 
+// RedeemParentTracer is implemented by tracers that annotate a retry tx's trace with the tx that scheduled its
+// redeem. ArbOS links the two before the EVM starts, where tracers don't otherwise see it.
+type RedeemParentTracer interface {
+	CaptureRedeemParent(ticketId common.Hash, parentTxHash common.Hash)
+}
+
+// linkRedeemParent annotates the retry tx's trace with the tx that scheduled the redeem.
+func (p *TxProcessor) linkRedeemParent(ticketId common.Hash) {
+	evm := p.evm
+	retryable, err := p.state.RetryableState().OpenRetryable(ticketId, evm.Context.Time)
+	p.state.Restrict(err)
+	if retryable == nil {
+		return
+	}
+	parent, err := retryable.RedeemParent()
+	p.state.Restrict(err)
+	if parent == (common.Hash{}) {
+		// the redeem was scheduled before the chain recorded parents
+		return
+	}
+	if tracer, ok := evm.Config.Tracer.(RedeemParentTracer); ok {
+		tracer.CaptureRedeemParent(ticketId, parent)
+	}
+}
+
 func GetPosterGas(state *arbosState.ArbosState, baseFee *big.Int, runMode core.MessageRunMode, posterCost *big.Int) uint64 {
 	if runMode == core.MessageGasEstimationMode {
 		// Suggest the amount of gas needed for a given amount of ETH is higher in case of congestion.
@@ -446,6 +558,12 @@ func (p *TxProcessor) GasChargingHook(gasRemaining *uint64) (common.Address, err
 	if p.msg.TxRunMode.ExecutedOnChain() {
 		p.msg.SkipL1Charging = false
 	}
+	if p.msg.Tx != nil {
+		if err := p.state.CheckTxSize(p.msg.Tx); err != nil {
+			return tipReceipient, err
+		}
+	}
+
 	if basefee.Sign() > 0 && !p.msg.SkipL1Charging {
 		// Since tips go to the network, and not to the poster, we use the basefee.
 		// Note, this only determines the amount of gas bought, not the price per gas.
@@ -459,8 +577,32 @@ func (p *TxProcessor) GasChargingHook(gasRemaining *uint64) (common.Address, err
 			p.state.Restrict(p.state.L1PricingState().AddToUnitsSinceUpdate(calldataUnits))
 		}
 		p.posterGas = GetPosterGas(p.state, basefee, p.msg.TxRunMode, posterCost)
+		p.posterUnits = calldataUnits
 		p.PosterFee = arbmath.BigMulByUint(basefee, p.posterGas) // round down
 		gasNeededToStartEVM = p.posterGas
+
+		if p.state.ArbOSVersion() >= arbosState.ArbosVersion_ChainTxLimits {
+			// the surcharge is paid to the network like compute, so it isn't part of the poster fee
+			surcharge, err := p.state.L1PricingState().CalldataSurcharge(posterCost)
+			if err != nil {
+				return common.Address{}, fmt.Errorf("failed to get calldata surcharge: %w", err)
+			}
+			p.surchargeGas = GetPosterGas(p.state, basefee, p.msg.TxRunMode, surcharge)
+			gasNeededToStartEVM = arbmath.SaturatingUAdd(gasNeededToStartEVM, p.surchargeGas)
+		}
+	}
+
+	if basefee.Sign() > 0 && p.chargesCodeDeposits() {
+		// The size of the deployed code isn't known until the EVM has run, so the fee is collected from the gas
+		// of each creation as it stores its code. Like the poster fee, it's re-expressed as L2 gas, rounded up
+		// per byte, so the user pays for it via the gas limit.
+		perByte, err := p.state.L2PricingState().CodeDepositFeePerByte()
+		if err != nil {
+			return common.Address{}, fmt.Errorf("failed to get code deposit fee: %w", err)
+		}
+		perByteGas := arbmath.BigDiv(arbmath.BigSubByUint(arbmath.BigAdd(perByte, basefee), 1), basefee)
+		p.codeDepositGasPerByte = arbmath.BigToUintSaturating(perByteGas)
+		p.deploying = make(map[*vm.Contract]struct{})
 	}
 
 	if *gasRemaining < gasNeededToStartEVM {
@@ -481,6 +623,16 @@ func (p *TxProcessor) GasChargingHook(gasRemaining *uint64) (common.Address, err
 	return tipReceipient, nil
 }
 
+// chargesCodeDeposits returns whether the tx pays the code deposit fee on the code its creations deploy,
+// whether it's a top-level creation or a contract's CREATE or CREATE2.
+// Retryable redeems are exempt since their gas was prepaid when the ticket was created.
+func (p *TxProcessor) chargesCodeDeposits() bool {
+	if !p.state.FeatureEnabled(arbosState.FeatureCodeDepositFee) {
+		return false
+	}
+	return p.msg.Tx == nil || p.msg.Tx.Type() != types.ArbitrumRetryTxType
+}
+
 func (p *TxProcessor) RunMode() core.MessageRunMode {
 	return p.msg.TxRunMode
 }
@@ -488,12 +640,18 @@ func (p *TxProcessor) RunMode() core.MessageRunMode {
 func (p *TxProcessor) NonrefundableGas() uint64 {
 	// EVM-incentivized activity like freeing storage should only refund amounts paid to the network address,
 	// which represents the overall burden to node operators. A poster's costs, then, should not be eligible
-	// for this refund.
-	return p.posterGas
+	// for this refund. The same goes for the code deposit fee and the calldata surcharge, which aren't compute.
+	return p.nonComputeGas()
+}
+
+// nonComputeGas returns the gas the tx paid for costs other than its execution.
+func (p *TxProcessor) nonComputeGas() uint64 {
+	return p.posterGas + p.codeDepositGas + p.surchargeGas
 }
 
 func (p *TxProcessor) ForceRefundGas() uint64 {
-	return p.computeHoldGas
+	// geth refunds gas after running the EVM and before calling NonrefundableGas, so the deposits are final here
+	return p.computeHoldGas + p.settleCodeDeposits()
 }
 
 func (p *TxProcessor) EndTxHook(gasLeft uint64, success bool) {
@@ -579,7 +737,9 @@ func (p *TxProcessor) EndTxHook(gasLeft uint64, success bool) {
 			// we don't want to charge for this
 			tracingInfo := util.NewTracingInfo(p.evm, arbosAddress, p.msg.From, scenario)
 			state := arbosState.OpenSystemArbosStateOrPanic(p.evm.StateDB, tracingInfo, false)
-			_, _ = state.RetryableState().DeleteRetryable(inner.TicketId, p.evm, scenario)
+			if deleted, _ := state.RetryableState().DeleteRetryable(inner.TicketId, p.evm, scenario); deleted {
+				state.Restrict(state.RecordStatistic(arbosState.StatRetryablesRedeemed, 1))
+			}
 		} else {
 			// return the Callvalue to escrow
 			escrow := retryables.RetryableEscrowAddress(inner.TicketId)
@@ -601,6 +761,10 @@ func (p *TxProcessor) EndTxHook(gasLeft uint64, success bool) {
 	} else {
 		basefee = p.evm.Context.BaseFee
 	}
+	if p.sponsorAdvance != nil {
+		p.settleSponsorship(gasUsed)
+	}
+
 	totalCost := arbmath.BigMul(basefee, arbmath.UintToBig(gasUsed)) // total cost = price of gas * gas burnt
 	computeCost := arbmath.BigSub(totalCost, p.PosterFee)            // total cost = network's compute + poster's L1 costs
 	if computeCost.Sign() < 0 {
@@ -620,7 +784,7 @@ func (p *TxProcessor) EndTxHook(gasLeft uint64, success bool) {
 			minBaseFee, err := p.state.L2PricingState().MinBaseFeeWei()
 			p.state.Restrict(err)
 			infraFee := arbmath.BigMin(minBaseFee, basefee)
-			computeGas := arbmath.SaturatingUSub(gasUsed, p.posterGas)
+			computeGas := arbmath.SaturatingUSub(gasUsed, p.nonComputeGas())
 			infraComputeCost := arbmath.BigMulByUint(infraFee, computeGas)
 			util.MintBalance(&infraFeeAccount, infraComputeCost, p.evm, scenario, purpose)
 			computeCost = arbmath.BigSub(computeCost, infraComputeCost)
@@ -646,13 +810,16 @@ func (p *TxProcessor) EndTxHook(gasLeft uint64, success bool) {
 		// Hence, we deduct the previously saved poster L2-gas-equivalent to reveal the compute-only gas
 
 		var computeGas uint64
-		if gasUsed > p.posterGas {
-			// Don't include posterGas in computeGas as it doesn't represent processing time.
-			computeGas = gasUsed - p.posterGas
+		if gasUsed > p.nonComputeGas() {
+			// Don't include posterGas, codeDepositGas or surchargeGas in computeGas as they don't represent processing time.
+			computeGas = gasUsed - p.nonComputeGas()
 		} else {
 			// Somehow, the core message transition succeeded, but we didn't burn the posterGas.
 			// An invariant was violated. To be safe, subtract the entire gas used from the gas pool.
-			log.Error("total gas used < poster gas component", "gasUsed", gasUsed, "posterGas", p.posterGas)
+			log.Error(
+				"total gas used < poster gas component", "gasUsed", gasUsed, "posterGas", p.posterGas,
+				"codeDepositGas", p.codeDepositGas, "surchargeGas", p.surchargeGas,
+			)
 			computeGas = gasUsed
 		}
 		p.state.Restrict(p.state.L2PricingState().AddToGasPool(-arbmath.SaturatingCast[int64](computeGas)))
@@ -752,6 +919,79 @@ func (p *TxProcessor) GasPriceOp(evm *vm.EVM) *big.Int {
 	return evm.GasPrice
 }
 
+// TxHash returns the hash of the tx being processed, or zero for calls that aren't txs.
+func (p *TxProcessor) TxHash() common.Hash {
+	if p.msg.Tx == nil {
+		return common.Hash{}
+	}
+	return p.msg.Tx.Hash()
+}
+
+// PosterUnits returns the L1 calldata units charged to the tx.
+func (p *TxProcessor) PosterUnits() uint64 {
+	return p.posterUnits
+}
+
+// ScheduledRedeem links a retryable ticket to a redeem scheduled for it.
+type ScheduledRedeem struct {
+	TicketId    common.Hash
+	RetryTxHash common.Hash
+}
+
+// ScheduledRedeemsFromReceipt returns the redeems scheduled by the receipt's tx, which for a submit retryable
+// tx is its auto-redeem, and for a call to ArbRetryableTx.redeem the manual redeem.
+func ScheduledRedeemsFromReceipt(receipt *types.Receipt) []ScheduledRedeem {
+	var redeems []ScheduledRedeem
+	for _, entry := range receipt.Logs {
+		if entry.Address != ArbRetryableTxAddress || len(entry.Topics) == 0 || entry.Topics[0] != RedeemScheduledEventID {
+			continue
+		}
+		event, err := util.ParseRedeemScheduledLog(entry)
+		if err != nil {
+			glog.Error("Failed to parse RedeemScheduled log", "err", err)
+			continue
+		}
+		redeems = append(redeems, ScheduledRedeem{
+			TicketId:    event.TicketId,
+			RetryTxHash: event.RetryTxHash,
+		})
+	}
+	return redeems
+}
+
+// RedeemParentFromReceipts returns the tx that scheduled a retry tx's redeem, which for an auto-redeem is the
+// submission, given the receipts of the retry tx's block. Retry txs run later in the block that scheduled them.
+func RedeemParentFromReceipts(receipts types.Receipts, retryTxHash common.Hash) (common.Hash, bool) {
+	for _, receipt := range receipts {
+		if receipt.TxHash == retryTxHash {
+			break
+		}
+		for _, redeem := range ScheduledRedeemsFromReceipt(receipt) {
+			if redeem.RetryTxHash == retryTxHash {
+				return receipt.TxHash, true
+			}
+		}
+	}
+	return common.Hash{}, false
+}
+
+// RedeemRevertDataFromReceipt returns the revert data of a failed retry tx's call,
+// if the chain records it through the redeem-revert-data ArbOS feature.
+func RedeemRevertDataFromReceipt(receipt *types.Receipt) ([]byte, bool) {
+	for _, entry := range receipt.Logs {
+		if entry.Address != ArbRetryableTxAddress || len(entry.Topics) == 0 || entry.Topics[0] != RedeemFailedEventID {
+			continue
+		}
+		event, err := util.ParseRedeemFailedLog(entry)
+		if err != nil {
+			glog.Error("Failed to parse RedeemFailed log", "err", err)
+			return nil, false
+		}
+		return event.RevertData, true
+	}
+	return nil, false
+}
+
 func (p *TxProcessor) FillReceiptInfo(receipt *types.Receipt) {
 	receipt.GasUsedForL1 = p.posterGas
 }
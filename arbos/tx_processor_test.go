@@ -0,0 +1,136 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbos
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/holiman/uint256"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/vm"
+
+	"github.com/offchainlabs/nitro/arbos/arbosState"
+	"github.com/offchainlabs/nitro/arbos/burn"
+)
+
+func TestNonrefundableGas(t *testing.T) {
+	// none of the gas bought for costs other than compute may be refunded for freeing storage
+	p := &TxProcessor{posterGas: 100, codeDepositGas: 20, surchargeGas: 3}
+	if gas := p.NonrefundableGas(); gas != 123 {
+		Fail(t, "wrong nonrefundable gas", gas)
+	}
+}
+
+func TestCodeDepositHold(t *testing.T) {
+	evm := newMockEVMForTesting()
+	p := &TxProcessor{
+		evm:                   evm,
+		Programs:              make(map[common.Address]uint),
+		codeDepositGasPerByte: 20,
+		deploying:             make(map[*vm.Contract]struct{}),
+	}
+	factory := common.HexToAddress("0xfac")
+	deployment := common.HexToAddress("0xc0de")
+	evm.StateDB.SetCode(factory, []byte{0xf0})
+
+	call := vm.NewContract(vm.AccountRef(common.Address{}), vm.AccountRef(factory), uint256.NewInt(0), 50_000)
+	call.SetCallCode(&factory, common.Hash{}, []byte{0xf0})
+	creation := vm.NewContract(vm.AccountRef(factory), vm.AccountRef(deployment), uint256.NewInt(0), 22_000)
+	creation.SetCallCode(&deployment, common.Hash{}, []byte{0xf3})
+
+	// only the creation is held back from, enough to pay for the most code it could store
+	p.PushContract(call)
+	p.PushContract(creation)
+	p.PopContract()
+	if creation.Gas != 20_000 || p.heldDepositGas != 2_000 {
+		Fail(t, "wrong hold", creation.Gas, p.heldDepositGas)
+	}
+	p.PopContract()
+	if call.Gas != 50_000 || p.heldDepositGas != 2_000 {
+		Fail(t, "held gas from a call", call.Gas, p.heldDepositGas)
+	}
+
+	// the fee is charged on the code deployed, and the rest of the hold refunded
+	evm.StateDB.SetCode(deployment, make([]byte, 50))
+	if refund := p.ForceRefundGas(); refund != 1_000 || p.codeDepositGas != 1_000 {
+		Fail(t, "wrong settlement", refund, p.codeDepositGas)
+	}
+	if refund := p.ForceRefundGas(); refund != 0 || p.heldDepositGas != 0 {
+		Fail(t, "settled twice", refund, p.heldDepositGas)
+	}
+}
+
+func TestGasSponsorshipSettlement(t *testing.T) {
+	evm := newMockEVMForTesting()
+	state, err := arbosState.OpenArbosState(evm.StateDB, burn.NewSystemBurner(nil, false))
+	Require(t, err)
+	state.SetFormatVersion(arbosState.ArbosVersion_GasSponsorship)
+
+	paymaster := common.HexToAddress("0xfee")
+	sender := common.HexToAddress("0xa11ce")
+	target := common.HexToAddress("0xc0de")
+	selector := [4]byte{1, 2, 3, 4}
+	evm.StateDB.AddBalance(paymaster, uint256.NewInt(1_000_000))
+	sponsorship := state.Sponsorship()
+	Require(t, sponsorship.SetPaymaster(paymaster))
+	Require(t, sponsorship.SetSponsoredMethod(target, selector, true))
+	Require(t, sponsorship.SetAccountBudget(big.NewInt(500_000)))
+	Require(t, sponsorship.SetAllowance(paymaster, big.NewInt(300_000)))
+
+	msg := &core.Message{
+		From:      sender,
+		To:        &target,
+		Data:      append(selector[:], 0xff),
+		GasLimit:  1000,
+		GasFeeCap: big.NewInt(100),
+		GasPrice:  big.NewInt(60),
+	}
+	newProcessor := func() *TxProcessor {
+		return &TxProcessor{msg: msg, state: state, evm: evm}
+	}
+	balance := func(account common.Address) uint64 {
+		return evm.StateDB.GetBalance(account).Uint64()
+	}
+
+	// without the chain config enabling it, the sender pays for its own gas
+	p := newProcessor()
+	p.sponsorGas()
+	if p.sponsorAdvance != nil || balance(sender) != 0 {
+		Fail(t, "sponsored a tx the chain config doesn't enable sponsorship for", p.sponsorAdvance, balance(sender))
+	}
+	Require(t, state.SetChainConfig([]byte(`{"arbitrum":{"EnableGasSponsorship":true}}`)))
+
+	// the paymaster advances the gas limit at the fee cap
+	p = newProcessor()
+	p.sponsorGas()
+	if p.sponsorAdvance == nil || p.paymaster != paymaster || balance(sender) != 100_000 || balance(paymaster) != 900_000 {
+		Fail(t, "wrong advance", p.sponsorAdvance, balance(sender), balance(paymaster))
+	}
+
+	// geth buys the gas at the effective price and refunds what's unused, and the paymaster gets back the rest
+	evm.StateDB.SubBalance(sender, uint256.NewInt(60_000))
+	evm.StateDB.AddBalance(sender, uint256.NewInt(36_000))
+	p.settleSponsorship(400)
+	if balance(sender) != 0 || balance(paymaster) != 976_000 {
+		Fail(t, "paymaster didn't pay exactly the gas used", balance(sender), balance(paymaster))
+	}
+	sponsored, err := sponsorship.Sponsored(sender)
+	Require(t, err)
+	allowance, err := sponsorship.Allowance(paymaster)
+	Require(t, err)
+	if sponsored.Uint64() != 24_000 || allowance.Uint64() != 276_000 {
+		Fail(t, "wrong sponsorship accounting", sponsored, allowance)
+	}
+
+	// once the paymaster's allowance can't cover the advance, the sender pays for its own gas
+	Require(t, sponsorship.SetAllowance(paymaster, big.NewInt(99_999)))
+	p = newProcessor()
+	p.sponsorGas()
+	if p.sponsorAdvance != nil || balance(paymaster) != 976_000 {
+		Fail(t, "sponsored a tx beyond the paymaster's allowance", p.sponsorAdvance, balance(paymaster))
+	}
+}
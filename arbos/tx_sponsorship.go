@@ -0,0 +1,47 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbos
+
+import (
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/offchainlabs/nitro/arbos/util"
+	"github.com/offchainlabs/nitro/util/arbmath"
+)
+
+// sponsorGas has the paymaster advance the sender what the tx's gas could cost, if the chain config enables
+// sponsorship, the tx calls a sponsored method, and both the sender's budget and the paymaster's allowance cover it,
+// so that senders without funds can send it. It runs before geth checks the sender's balance and buys the gas, so
+// the advance is the gas limit at the fee cap, as geth's balance check needs.
+// If the tx doesn't qualify, or the paymaster can't pay, the sender pays for its gas as usual.
+func (p *TxProcessor) sponsorGas() {
+	paymaster, advance, err := p.state.SponsorshipAdvance(p.msg.From, p.msg.To, p.msg.Data, p.msg.GasFeeCap, p.msg.GasLimit)
+	p.state.Restrict(err)
+	if advance == nil {
+		return
+	}
+	err = util.TransferBalance(&paymaster, &p.msg.From, advance, p.evm, util.TracingBeforeEVM, "sponsorship")
+	if err != nil {
+		log.Debug("paymaster can't sponsor tx", "paymaster", paymaster, "sender", p.msg.From, "err", err)
+		return
+	}
+	p.sponsorAdvance = advance
+	p.paymaster = paymaster
+}
+
+// settleSponsorship returns to the paymaster what the sender didn't spend of its advance, so the paymaster pays
+// exactly the gas the tx used, and books that cost against its allowance and the sender's budget. Geth has already
+// refunded the sender its unused gas, and the tx can't have spent the advance on anything else, as geth's balance
+// check made sure the sender had its call value without it.
+func (p *TxProcessor) settleSponsorship(gasUsed uint64) {
+	cost := arbmath.BigMulByUint(p.msg.GasPrice, gasUsed)
+	unspent := arbmath.BigSub(p.sponsorAdvance, cost)
+	if unspent.Sign() > 0 {
+		err := util.TransferBalance(&p.msg.From, &p.paymaster, unspent, p.evm, util.TracingAfterEVM, "sponsorshipReturn")
+		if err != nil {
+			log.Error("sender can't return the unspent sponsorship", "sender", p.msg.From, "paymaster", p.paymaster, "err", err)
+		}
+	}
+	p.state.Restrict(p.state.Sponsorship().RecordSponsored(p.paymaster, p.msg.From, arbmath.BigMin(cost, p.sponsorAdvance)))
+}
@@ -0,0 +1,109 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package util
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/offchainlabs/nitro/util/arbmath"
+)
+
+// The helpers below compute the identifiers of cross-chain messages exactly as ArbOS does, so tooling can
+// link parent chain messages to the transactions they become without re-implementing ArbOS's rules.
+
+// DelayedMessageRequestId returns the request id of the delayed message with the given index, which is the
+// L1RequestId of the deposit or the RequestId of the tx it becomes.
+func DelayedMessageRequestId(delayedMessageIndex uint64) common.Hash {
+	return common.BigToHash(new(big.Int).SetUint64(delayedMessageIndex))
+}
+
+// SubRequestId returns the request id of the index'th part of a message. Batches from the delayed inbox number
+// their txs this way, and an L2 funded by L1 message uses index 0 for its deposit and index 1 for its tx.
+func SubRequestId(requestId common.Hash, index *big.Int) common.Hash {
+	return crypto.Keccak256Hash(requestId[:], arbmath.U256Bytes(index))
+}
+
+// RetryableSubmission holds the fields of a retryable ticket as submitted to the parent chain's inbox.
+type RetryableSubmission struct {
+	ChainId             *big.Int
+	DelayedMessageIndex uint64
+	L1Sender            common.Address // the caller of the inbox, which ArbOS sees aliased
+	L1BaseFee           *big.Int
+	DepositValue        *big.Int
+	GasFeeCap           *big.Int
+	Gas                 uint64
+	RetryTo             common.Address // the zero address for retryables that create contracts
+	RetryValue          *big.Int
+	Beneficiary         common.Address
+	MaxSubmissionFee    *big.Int
+	FeeRefundAddr       common.Address
+	RetryData           []byte
+}
+
+// Tx returns the submit retryable tx ArbOS makes of the submission.
+func (s *RetryableSubmission) Tx() *types.ArbitrumSubmitRetryableTx {
+	orZero := func(value *big.Int) *big.Int {
+		if value == nil {
+			return new(big.Int)
+		}
+		return value
+	}
+	var retryTo *common.Address
+	if s.RetryTo != (common.Address{}) {
+		to := s.RetryTo
+		retryTo = &to
+	}
+	retryData := s.RetryData
+	if retryData == nil {
+		retryData = []byte{}
+	}
+	return &types.ArbitrumSubmitRetryableTx{
+		ChainId:          orZero(s.ChainId),
+		RequestId:        DelayedMessageRequestId(s.DelayedMessageIndex),
+		From:             RemapL1Address(s.L1Sender),
+		L1BaseFee:        orZero(s.L1BaseFee),
+		DepositValue:     orZero(s.DepositValue),
+		GasFeeCap:        orZero(s.GasFeeCap),
+		Gas:              s.Gas,
+		RetryTo:          retryTo,
+		RetryValue:       orZero(s.RetryValue),
+		Beneficiary:      s.Beneficiary,
+		MaxSubmissionFee: orZero(s.MaxSubmissionFee),
+		FeeRefundAddr:    s.FeeRefundAddr,
+		RetryData:        retryData,
+	}
+}
+
+// TicketId returns the id of the retryable ticket created by the submission, which is the hash of its
+// submit retryable tx.
+func (s *RetryableSubmission) TicketId() common.Hash {
+	return types.NewTx(s.Tx()).Hash()
+}
+
+// L2ToL1SendHashPreimage returns the data hashed into the leaf of the send merkle accumulator for an L2 to L1 message.
+func L2ToL1SendHashPreimage(
+	caller, destination common.Address, l2BlockNumber, l1BlockNumber *big.Int, timestamp uint64, value *big.Int, calldata []byte,
+) [][]byte {
+	return [][]byte{
+		caller.Bytes(),
+		destination.Bytes(),
+		arbmath.U256Bytes(l2BlockNumber),
+		arbmath.U256Bytes(l1BlockNumber),
+		arbmath.U256Bytes(new(big.Int).SetUint64(timestamp)),
+		common.BigToHash(value).Bytes(),
+		calldata,
+	}
+}
+
+// L2ToL1SendHash returns the leaf of the send merkle accumulator for an L2 to L1 message, which is also the
+// hash reported by the L2ToL1Tx event.
+func L2ToL1SendHash(
+	caller, destination common.Address, l2BlockNumber, l1BlockNumber *big.Int, timestamp uint64, value *big.Int, calldata []byte,
+) common.Hash {
+	return crypto.Keccak256Hash(L2ToL1SendHashPreimage(caller, destination, l2BlockNumber, l1BlockNumber, timestamp, value, calldata)...)
+}
@@ -122,6 +122,36 @@ func (info *TracingInfo) MockCall(input []byte, gas uint64, from, to common.Addr
 	tracer.CaptureState(0, vm.POP, 0, 0, popScope, []byte{}, depth, nil)
 }
 
+// RecordLog shows tracers a log ArbOS adds to the state directly, such as a precompile's event, as if it
+// were emitted by a LOG opcode of the given address. Logs added outside any call frame aren't shown.
+func (info *TracingInfo) RecordLog(address common.Address, topics []common.Hash, data []byte) {
+	if info.Scenario != TracingDuringEVM || info.Depth == 0 {
+		return
+	}
+	info.CaptureLog(address, topics, data)
+}
+
+// CaptureLog shows tracers a log as if it were emitted by a LOG opcode of the given address at the info's
+// depth, without checking that a call frame is open there. Callers must have started the tracer's call.
+func (info *TracingInfo) CaptureLog(address common.Address, topics []common.Hash, data []byte) {
+	if len(topics) > 4 {
+		return
+	}
+	args := []uint256.Int{
+		*uint256.NewInt(0),                 // memory offset
+		*uint256.NewInt(uint64(len(data))), // memory length
+	}
+	for _, topic := range topics {
+		args = append(args, HashToUint256(topic))
+	}
+	scope := &vm.ScopeContext{
+		Memory:   TracingMemoryFromBytes(data),
+		Stack:    TracingStackFromArgs(args...),
+		Contract: vm.NewContract(addressHolder{address}, addressHolder{address}, uint256.NewInt(0), 0),
+	}
+	info.Tracer.CaptureState(0, vm.LOG0+vm.OpCode(len(topics)), 0, 0, scope, []byte{}, info.Depth, nil)
+}
+
 func (info *TracingInfo) CaptureEVMTraceForHostio(name string, args, outs []byte, startInk, endInk uint64) {
 	checkArgs := func(want int) bool {
 		if len(args) < want {
@@ -0,0 +1,59 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package util
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+// logCapturingTracer only implements the hook RecordLog uses
+type logCapturingTracer struct {
+	vm.EVMLogger
+	ops    []vm.OpCode
+	scopes []*vm.ScopeContext
+}
+
+func (t *logCapturingTracer) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+	t.ops = append(t.ops, op)
+	t.scopes = append(t.scopes, scope)
+}
+
+func TestRecordLog(t *testing.T) {
+	tracer := &logCapturingTracer{}
+	address := common.HexToAddress("0x64")
+	topics := []common.Hash{{1}, {2}}
+	data := []byte{3, 4, 5}
+
+	// logs outside a call frame or the EVM aren't recorded
+	(&TracingInfo{Tracer: tracer, Scenario: TracingDuringEVM, Depth: 0}).RecordLog(address, topics, data)
+	(&TracingInfo{Tracer: tracer, Scenario: TracingAfterEVM, Depth: 1}).RecordLog(address, topics, data)
+	if len(tracer.ops) != 0 {
+		t.Fatal("recorded a log outside a call frame")
+	}
+
+	(&TracingInfo{Tracer: tracer, Scenario: TracingDuringEVM, Depth: 1}).RecordLog(address, topics, data)
+	if len(tracer.ops) != 1 || tracer.ops[0] != vm.LOG2 {
+		t.Fatal("expected a LOG2, got", tracer.ops)
+	}
+	scope := tracer.scopes[0]
+	if scope.Contract.Address() != address {
+		t.Fatal("log recorded for the wrong address", scope.Contract.Address())
+	}
+	stack := scope.Stack
+	if stack.Back(0).Uint64() != 0 || stack.Back(1).Uint64() != uint64(len(data)) {
+		t.Fatal("wrong memory range on the stack", stack.Back(0), stack.Back(1))
+	}
+	for i, topic := range topics {
+		if common.Hash(stack.Back(2+i).Bytes32()) != topic {
+			t.Fatal("wrong topic", i, "on the stack", stack.Back(2+i))
+		}
+	}
+	if !bytes.Equal(scope.Memory.Data()[:len(data)], data) {
+		t.Fatal("wrong log data in memory", scope.Memory.Data())
+	}
+}
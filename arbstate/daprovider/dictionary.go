@@ -0,0 +1,71 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package daprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/offchainlabs/nitro/arbutil"
+)
+
+var ErrUnknownDictionary = errors.New("batch is compressed with an unknown brotli dictionary")
+var ErrUncommittedDictionary = errors.New("batch is compressed with a brotli dictionary the chain didn't commit to")
+
+// DictionaryReader looks up the brotli dictionaries a chain's batches may be compressed with,
+// by the keccak256 hash of their contents that such batches commit to.
+type DictionaryReader interface {
+	// GetDictionary returns the dictionary, recording it as a keccak256 preimage if a recorder is given.
+	GetDictionary(ctx context.Context, hash common.Hash, preimageRecorder PreimageRecorder) ([]byte, error)
+}
+
+// StaticDictionaries is a DictionaryReader over the dictionaries a node was configured with.
+type StaticDictionaries map[common.Hash][]byte
+
+func NewStaticDictionaries(dictionaries ...[]byte) StaticDictionaries {
+	d := make(StaticDictionaries, len(dictionaries))
+	for _, dictionary := range dictionaries {
+		d[crypto.Keccak256Hash(dictionary)] = dictionary
+	}
+	return d
+}
+
+func (d StaticDictionaries) GetDictionary(ctx context.Context, hash common.Hash, preimageRecorder PreimageRecorder) ([]byte, error) {
+	dictionary, ok := d[hash]
+	if !ok {
+		return nil, fmt.Errorf("%w %v", ErrUnknownDictionary, hash)
+	}
+	if preimageRecorder != nil {
+		preimageRecorder(hash, dictionary, arbutil.Keccak256PreimageType)
+	}
+	return dictionary, nil
+}
+
+// CommittedDictionaries is a DictionaryReader over the dictionaries the chain committed to in ArbOS. Batches
+// compressed with any other dictionary are invalid, so neither nodes nor proofs ever need its contents.
+type CommittedDictionaries struct {
+	// Committed reports if the chain committed to a dictionary as of the state the batch is read in, which is
+	// never the case before the ArbOS version that lets the chain owner commit to dictionaries.
+	Committed func(hash common.Hash) (bool, error)
+	// Reader has the contents of the committed dictionaries, or is nil if the node has none.
+	Reader DictionaryReader
+}
+
+func (d *CommittedDictionaries) GetDictionary(ctx context.Context, hash common.Hash, preimageRecorder PreimageRecorder) ([]byte, error) {
+	committed, err := d.Committed(hash)
+	if err != nil {
+		return nil, err
+	}
+	if !committed {
+		return nil, fmt.Errorf("%w %v", ErrUncommittedDictionary, hash)
+	}
+	if d.Reader == nil {
+		return nil, fmt.Errorf("%w %v", ErrUnknownDictionary, hash)
+	}
+	return d.Reader.GetDictionary(ctx, hash, preimageRecorder)
+}
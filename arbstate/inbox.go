@@ -49,7 +49,7 @@ const MaxDecompressedLen int = 1024 * 1024 * 16 // 16 MiB
 const maxZeroheavyDecompressedLen = 101*MaxDecompressedLen/100 + 64
 const MaxSegmentsPerSequencerMessage = 100 * 1024
 
-func parseSequencerMessage(ctx context.Context, batchNum uint64, batchBlockHash common.Hash, data []byte, dapReaders []daprovider.Reader, keysetValidationMode daprovider.KeysetValidationMode) (*sequencerMessage, error) {
+func parseSequencerMessage(ctx context.Context, batchNum uint64, batchBlockHash common.Hash, data []byte, dapReaders []daprovider.Reader, dictionaries daprovider.DictionaryReader, keysetValidationMode daprovider.KeysetValidationMode) (*sequencerMessage, error) {
 	if len(data) < 40 {
 		return nil, errors.New("sequencer message missing L1 header")
 	}
@@ -124,9 +124,14 @@ func parseSequencerMessage(ctx context.Context, batchNum uint64, batchBlockHash
 		payload = pl
 	}
 
-	// Stage 3: Decompress the brotli payload and fill the parsedMsg.segments list.
-	if len(payload) > 0 && daprovider.IsBrotliMessageHeaderByte(payload[0]) {
-		decompressed, err := arbcompress.Decompress(payload[1:], MaxDecompressedLen)
+	// Stage 3: Decompress the brotli payload, with the dictionary it commits to if any, and fill the parsedMsg.segments list.
+	if len(payload) > 0 && isBrotliPayloadHeaderByte(payload[0]) {
+		decompressed, err := decompressBrotliPayload(ctx, payload, dictionaries, nil)
+		if err != nil && !errors.Is(err, errInvalidBrotliPayload) && !errors.Is(err, daprovider.ErrUncommittedDictionary) {
+			// The chain committed to the dictionary but it couldn't be looked up,
+			// and skipping the batch would diverge from nodes that could
+			return nil, err
+		}
 		if err == nil {
 			reader := bytes.NewReader(decompressed)
 			stream := rlp.NewStream(reader, uint64(MaxDecompressedLen))
@@ -161,10 +166,72 @@ func parseSequencerMessage(ctx context.Context, batchNum uint64, batchBlockHash
 	return parsedMsg, nil
 }
 
+func isBrotliPayloadHeaderByte(header byte) bool {
+	return daprovider.IsBrotliMessageHeaderByte(header) || daprovider.IsBrotliDictionaryMessageHeaderByte(header)
+}
+
+var errInvalidBrotliPayload = errors.New("invalid brotli payload")
+
+// decompressBrotliPayload decompresses a payload starting with a brotli header byte. Payloads compressed with a
+// dictionary follow their header byte with the dictionary's keccak256 hash, which is looked up in dictionaries.
+// Payloads that don't decompress fail with errInvalidBrotliPayload, and ones compressed with a dictionary the
+// chain didn't commit to with daprovider.ErrUncommittedDictionary; either makes the batch invalid. Other errors
+// are from looking up a committed dictionary.
+func decompressBrotliPayload(ctx context.Context, payload []byte, dictionaries daprovider.DictionaryReader, preimageRecorder daprovider.PreimageRecorder) ([]byte, error) {
+	var decompressed []byte
+	var err error
+	if !daprovider.IsBrotliDictionaryMessageHeaderByte(payload[0]) {
+		decompressed, err = arbcompress.Decompress(payload[1:], MaxDecompressedLen)
+	} else {
+		if len(payload) < 1+32 {
+			return nil, fmt.Errorf("%w: dictionary message missing dictionary hash", errInvalidBrotliPayload)
+		}
+		hash := common.BytesToHash(payload[1:33])
+		if dictionaries == nil {
+			return nil, fmt.Errorf("%w %v", daprovider.ErrUnknownDictionary, hash)
+		}
+		dictionary, lookupErr := dictionaries.GetDictionary(ctx, hash, preimageRecorder)
+		if lookupErr != nil {
+			return nil, lookupErr
+		}
+		decompressed, err = arbcompress.DecompressWithRawDictionary(payload[33:], MaxDecompressedLen, dictionary)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidBrotliPayload, err)
+	}
+	return decompressed, nil
+}
+
+// RecordBatchDictionary records the dictionary a batch payload is compressed with as a preimage, if it has one.
+// The payload is the batch data after the L1 header, with any data availability certificate already resolved.
+func RecordBatchDictionary(ctx context.Context, payload []byte, dictionaries daprovider.DictionaryReader, preimageRecorder daprovider.PreimageRecorder) error {
+	if len(payload) > 0 && daprovider.IsZeroheavyEncodedHeaderByte(payload[0]) {
+		// only the brotli header and dictionary hash are needed
+		header, err := io.ReadAll(io.LimitReader(zeroheavy.NewZeroheavyDecoder(bytes.NewReader(payload[1:])), 1+32))
+		if err != nil {
+			return nil
+		}
+		payload = header
+	}
+	if len(payload) < 1+32 || !daprovider.IsBrotliDictionaryMessageHeaderByte(payload[0]) {
+		return nil
+	}
+	if dictionaries == nil {
+		return fmt.Errorf("%w %v", daprovider.ErrUnknownDictionary, common.BytesToHash(payload[1:33]))
+	}
+	_, err := dictionaries.GetDictionary(ctx, common.BytesToHash(payload[1:33]), preimageRecorder)
+	if errors.Is(err, daprovider.ErrUncommittedDictionary) {
+		// the batch is invalid, so replaying it doesn't need the dictionary
+		return nil
+	}
+	return err
+}
+
 type inboxMultiplexer struct {
 	backend                   InboxBackend
 	delayedMessagesRead       uint64
 	dapReaders                []daprovider.Reader
+	dictionaries              daprovider.DictionaryReader
 	cachedSequencerMessage    *sequencerMessage
 	cachedSequencerMessageNum uint64
 	cachedSegmentNum          uint64
@@ -174,11 +241,12 @@ type inboxMultiplexer struct {
 	keysetValidationMode      daprovider.KeysetValidationMode
 }
 
-func NewInboxMultiplexer(backend InboxBackend, delayedMessagesRead uint64, dapReaders []daprovider.Reader, keysetValidationMode daprovider.KeysetValidationMode) arbostypes.InboxMultiplexer {
+func NewInboxMultiplexer(backend InboxBackend, delayedMessagesRead uint64, dapReaders []daprovider.Reader, dictionaries daprovider.DictionaryReader, keysetValidationMode daprovider.KeysetValidationMode) arbostypes.InboxMultiplexer {
 	return &inboxMultiplexer{
 		backend:              backend,
 		delayedMessagesRead:  delayedMessagesRead,
 		dapReaders:           dapReaders,
+		dictionaries:         dictionaries,
 		keysetValidationMode: keysetValidationMode,
 	}
 }
@@ -200,7 +268,7 @@ func (r *inboxMultiplexer) Pop(ctx context.Context) (*arbostypes.MessageWithMeta
 		}
 		r.cachedSequencerMessageNum = r.backend.GetSequencerInboxPosition()
 		var err error
-		r.cachedSequencerMessage, err = parseSequencerMessage(ctx, r.cachedSequencerMessageNum, batchBlockHash, bytes, r.dapReaders, r.keysetValidationMode)
+		r.cachedSequencerMessage, err = parseSequencerMessage(ctx, r.cachedSequencerMessageNum, batchBlockHash, bytes, r.dapReaders, r.dictionaries, r.keysetValidationMode)
 		if err != nil {
 			return nil, err
 		}
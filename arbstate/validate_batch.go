@@ -0,0 +1,167 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbstate
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/offchainlabs/nitro/arbcompress"
+	"github.com/offchainlabs/nitro/arbos/arbostypes"
+	"github.com/offchainlabs/nitro/arbstate/daprovider"
+	"github.com/offchainlabs/nitro/zeroheavy"
+)
+
+var (
+	ErrBatchMissingHeader         = errors.New("sequencer message missing L1 header")
+	ErrBatchUnknownFormat         = errors.New("unknown sequencer message format")
+	ErrBatchDataAvailability      = errors.New("failed to recover payload from data availability")
+	ErrBatchBadZeroheavyStream    = errors.New("bad zeroheavy stream")
+	ErrBatchBadBrotliStream       = errors.New("bad brotli stream")
+	ErrBatchBadSegmentEncoding    = errors.New("bad segment encoding")
+	ErrBatchTooManySegments       = errors.New("too many segments")
+	ErrBatchEmptySegment          = errors.New("empty segment")
+	ErrBatchUnknownSegmentKind    = errors.New("unknown segment kind")
+	ErrBatchOversizeSegment       = errors.New("oversize segment")
+	ErrBatchInvalidDelayedMessage = errors.New("invalid delayed message index")
+)
+
+// BatchDecodeError describes where and why strict validation of a sequencer batch failed.
+// Segment is -1 when the failure isn't specific to a segment. Offset is the byte offset of the failure within
+// the failing stage's input: the raw batch for the header and data availability stages, the payload for the
+// zeroheavy and decompression stages, and the decompressed payload for segments.
+type BatchDecodeError struct {
+	Stage   string
+	Segment int
+	Offset  int
+	Err     error
+}
+
+func (e *BatchDecodeError) Error() string {
+	if e.Segment >= 0 {
+		return fmt.Sprintf("batch %v stage: segment %v at offset %v: %v", e.Stage, e.Segment, e.Offset, e.Err)
+	}
+	return fmt.Sprintf("batch %v stage: offset %v: %v", e.Stage, e.Offset, e.Err)
+}
+
+func (e *BatchDecodeError) Unwrap() error {
+	return e.Err
+}
+
+func batchDecodeError(stage string, segment int, offset int, kind error, detail error) *BatchDecodeError {
+	err := kind
+	if detail != nil {
+		err = fmt.Errorf("%w: %w", kind, detail)
+	}
+	return &BatchDecodeError{Stage: stage, Segment: segment, Offset: offset, Err: err}
+}
+
+// ValidateBatch decodes a sequencer batch the way the inbox multiplexer would, but fails on the first problem
+// the multiplexer would otherwise log and skip over, returning a *BatchDecodeError locating it.
+// prevDelayedMessages is the delayed message count after the previous batch, which bounds the delayed message
+// segments this batch may contain. It's intended for triaging malformed batches, and must not be used in
+// consensus code, since the multiplexer's lenient handling of these batches is part of the state transition.
+func ValidateBatch(ctx context.Context, batchNum uint64, batchBlockHash common.Hash, data []byte, dapReaders []daprovider.Reader, dictionaries daprovider.DictionaryReader, prevDelayedMessages uint64) error {
+	if len(data) < 40 {
+		return batchDecodeError("header", -1, len(data), ErrBatchMissingHeader, nil)
+	}
+	afterDelayedMessages := binary.BigEndian.Uint64(data[32:40])
+	if afterDelayedMessages < prevDelayedMessages {
+		return batchDecodeError("header", -1, 32, ErrBatchInvalidDelayedMessage, fmt.Errorf("batch reads up to delayed message %v but %v were already read", afterDelayedMessages, prevDelayedMessages))
+	}
+	payload := data[40:]
+	if len(payload) == 0 {
+		return batchDecodeError("header", -1, 40, ErrBatchUnknownFormat, errors.New("empty payload"))
+	}
+	if daprovider.IsL1AuthenticatedMessageHeaderByte(payload[0]) && !daprovider.IsKnownHeaderByte(payload[0]) {
+		return batchDecodeError("header", -1, 40, ErrBatchUnknownFormat, fmt.Errorf("unsupported authenticated header byte 0x%02x", payload[0]))
+	}
+
+	foundDA := false
+	for _, dapReader := range dapReaders {
+		if dapReader == nil || !dapReader.IsValidHeaderByte(payload[0]) {
+			continue
+		}
+		recovered, err := dapReader.RecoverPayloadFromBatch(ctx, batchNum, batchBlockHash, data, nil, true)
+		if err != nil {
+			return batchDecodeError("data availability", -1, 40, ErrBatchDataAvailability, err)
+		}
+		if len(recovered) == 0 {
+			return batchDecodeError("data availability", -1, 40, ErrBatchDataAvailability, errors.New("no payload recovered"))
+		}
+		payload = recovered
+		foundDA = true
+		break
+	}
+	if !foundDA && (daprovider.IsDASMessageHeaderByte(payload[0]) || daprovider.IsBlobHashesHeaderByte(payload[0])) {
+		return batchDecodeError("data availability", -1, 40, ErrBatchDataAvailability, fmt.Errorf("no reader configured for header byte 0x%02x", payload[0]))
+	}
+
+	if daprovider.IsZeroheavyEncodedHeaderByte(payload[0]) {
+		decoded, err := io.ReadAll(io.LimitReader(zeroheavy.NewZeroheavyDecoder(bytes.NewReader(payload[1:])), int64(maxZeroheavyDecompressedLen)))
+		if err != nil {
+			return batchDecodeError("zeroheavy", -1, 1, ErrBatchBadZeroheavyStream, err)
+		}
+		if len(decoded) == 0 {
+			return batchDecodeError("zeroheavy", -1, 1, ErrBatchUnknownFormat, errors.New("empty payload"))
+		}
+		payload = decoded
+	}
+
+	if !isBrotliPayloadHeaderByte(payload[0]) {
+		return batchDecodeError("decompression", -1, 0, ErrBatchUnknownFormat, fmt.Errorf("header byte 0x%02x", payload[0]))
+	}
+	decompressed, err := decompressBrotliPayload(ctx, payload, dictionaries, nil)
+	if err != nil {
+		return batchDecodeError("decompression", -1, 1, ErrBatchBadBrotliStream, err)
+	}
+
+	reader := bytes.NewReader(decompressed)
+	stream := rlp.NewStream(reader, uint64(MaxDecompressedLen))
+	delayedMessages := prevDelayedMessages
+	for segmentNum := 0; ; segmentNum++ {
+		offset := len(decompressed) - reader.Len()
+		var segment []byte
+		if err := stream.Decode(&segment); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return batchDecodeError("segments", segmentNum, offset, ErrBatchBadSegmentEncoding, err)
+		}
+		if segmentNum >= MaxSegmentsPerSequencerMessage {
+			return batchDecodeError("segments", segmentNum, offset, ErrBatchTooManySegments, fmt.Errorf("limit is %v", MaxSegmentsPerSequencerMessage))
+		}
+		if len(segment) == 0 {
+			return batchDecodeError("segments", segmentNum, offset, ErrBatchEmptySegment, nil)
+		}
+		switch kind := segment[0]; kind {
+		case BatchSegmentKindL2Message:
+			if len(segment)-1 > arbostypes.MaxL2MessageSize {
+				return batchDecodeError("segments", segmentNum, offset, ErrBatchOversizeSegment, fmt.Errorf("%v bytes exceeds the %v byte limit", len(segment)-1, arbostypes.MaxL2MessageSize))
+			}
+		case BatchSegmentKindL2MessageBrotli:
+			if _, err := arbcompress.Decompress(segment[1:], arbostypes.MaxL2MessageSize); err != nil {
+				return batchDecodeError("segments", segmentNum, offset, ErrBatchBadBrotliStream, err)
+			}
+		case BatchSegmentKindDelayedMessages:
+			if delayedMessages >= afterDelayedMessages {
+				return batchDecodeError("segments", segmentNum, offset, ErrBatchInvalidDelayedMessage, fmt.Errorf("delayed message %v is past the batch's delayed message count %v", delayedMessages, afterDelayedMessages))
+			}
+			delayedMessages++
+		case BatchSegmentKindAdvanceTimestamp, BatchSegmentKindAdvanceL1BlockNumber:
+			if _, err := rlp.NewStream(bytes.NewReader(segment[1:]), 16).Uint64(); err != nil {
+				return batchDecodeError("segments", segmentNum, offset, ErrBatchBadSegmentEncoding, err)
+			}
+		default:
+			return batchDecodeError("segments", segmentNum, offset, ErrBatchUnknownSegmentKind, fmt.Errorf("kind %v", kind))
+		}
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package arbstate
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/offchainlabs/nitro/arbcompress"
+	"github.com/offchainlabs/nitro/arbstate/daprovider"
+	"github.com/offchainlabs/nitro/arbutil"
+)
+
+func buildTestBatch(t *testing.T, afterDelayedMessages uint64, segments ...[]byte) []byte {
+	t.Helper()
+	var encoded []byte
+	for _, segment := range segments {
+		item, err := rlp.EncodeToBytes(segment)
+		if err != nil {
+			t.Fatal(err)
+		}
+		encoded = append(encoded, item...)
+	}
+	compressed, err := arbcompress.CompressWell(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	batch := make([]byte, 40)
+	binary.BigEndian.PutUint64(batch[8:16], ^uint64(0))
+	binary.BigEndian.PutUint64(batch[24:32], ^uint64(0))
+	binary.BigEndian.PutUint64(batch[32:40], afterDelayedMessages)
+	batch = append(batch, daprovider.BrotliMessageHeaderByte)
+	return append(batch, compressed...)
+}
+
+func TestValidateBatch(t *testing.T) {
+	ctx := context.Background()
+	l2Message := []byte{BatchSegmentKindL2Message, 1, 2, 3}
+	delayed := []byte{BatchSegmentKindDelayedMessages}
+	advance := append([]byte{BatchSegmentKindAdvanceTimestamp}, 0x05)
+
+	valid := buildTestBatch(t, 2, l2Message, advance, delayed, l2Message)
+	if err := ValidateBatch(ctx, 0, common.Hash{}, valid, nil, nil, 1); err != nil {
+		t.Fatal("valid batch failed validation:", err)
+	}
+
+	badBrotli := append([]byte{}, valid[:41]...)
+	badBrotli = append(badBrotli, 0xff, 0xff, 0xff)
+
+	cases := []struct {
+		name    string
+		batch   []byte
+		prev    uint64
+		kind    error
+		segment int
+	}{
+		{"short header", valid[:20], 0, ErrBatchMissingHeader, -1},
+		{"bad brotli", badBrotli, 0, ErrBatchBadBrotliStream, -1},
+		{"delayed count went backwards", valid, 3, ErrBatchInvalidDelayedMessage, -1},
+		{"too many delayed messages", valid, 2, ErrBatchInvalidDelayedMessage, 2},
+		{"empty segment", buildTestBatch(t, 0, l2Message, []byte{}), 0, ErrBatchEmptySegment, 1},
+		{"unknown kind", buildTestBatch(t, 0, []byte{0x7f}), 0, ErrBatchUnknownSegmentKind, 0},
+		{"bad compressed message", buildTestBatch(t, 0, l2Message, []byte{BatchSegmentKindL2MessageBrotli, 0xff}), 0, ErrBatchBadBrotliStream, 1},
+	}
+	for _, tc := range cases {
+		err := ValidateBatch(ctx, 0, common.Hash{}, tc.batch, nil, nil, tc.prev)
+		var decodeErr *BatchDecodeError
+		if !errors.As(err, &decodeErr) {
+			t.Fatalf("%v: expected a decode error but got %v", tc.name, err)
+		}
+		if !errors.Is(err, tc.kind) || decodeErr.Segment != tc.segment {
+			t.Fatalf("%v: unexpected decode error %v", tc.name, err)
+		}
+	}
+}
+
+func TestDictionaryBatch(t *testing.T) {
+	ctx := context.Background()
+	dictionary := []byte("move attack defend inventory position move attack defend inventory position")
+	l2Message := append([]byte{BatchSegmentKindL2Message}, []byte("move attack position")...)
+	encoded, err := rlp.EncodeToBytes(l2Message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compressed, err := arbcompress.CompressWithRawDictionary(encoded, arbcompress.LEVEL_WELL, dictionary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	batch := make([]byte, 40)
+	binary.BigEndian.PutUint64(batch[8:16], ^uint64(0))
+	binary.BigEndian.PutUint64(batch[24:32], ^uint64(0))
+	batch = append(batch, daprovider.BrotliDictionaryMessageHeaderByte)
+	batch = append(batch, crypto.Keccak256(dictionary)...)
+	batch = append(batch, compressed...)
+
+	dictionaries := daprovider.NewStaticDictionaries(dictionary)
+	parsed, err := parseSequencerMessage(ctx, 0, common.Hash{}, batch, nil, dictionaries, daprovider.KeysetValidate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parsed.segments) != 1 || !bytes.Equal(parsed.segments[0], l2Message) {
+		t.Fatal("unexpected segments", parsed.segments)
+	}
+	if err := ValidateBatch(ctx, 0, common.Hash{}, batch, nil, dictionaries, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parseSequencerMessage(ctx, 0, common.Hash{}, batch, nil, nil, daprovider.KeysetValidate); !errors.Is(err, daprovider.ErrUnknownDictionary) {
+		t.Fatal("parsed a batch without its dictionary", err)
+	}
+
+	// batches compressed with a dictionary the chain didn't commit to are invalid, whether the node has it or not
+	for _, reader := range []daprovider.DictionaryReader{dictionaries, nil} {
+		uncommitted := &daprovider.CommittedDictionaries{
+			Committed: func(common.Hash) (bool, error) { return false, nil },
+			Reader:    reader,
+		}
+		parsed, err = parseSequencerMessage(ctx, 0, common.Hash{}, batch, nil, uncommitted, daprovider.KeysetValidate)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(parsed.segments) != 0 {
+			t.Fatal("parsed a batch with an uncommitted dictionary", parsed.segments)
+		}
+	}
+	lookupErr := errors.New("state unavailable")
+	unavailable := &daprovider.CommittedDictionaries{
+		Committed: func(common.Hash) (bool, error) { return false, lookupErr },
+		Reader:    dictionaries,
+	}
+	if _, err := parseSequencerMessage(ctx, 0, common.Hash{}, batch, nil, unavailable, daprovider.KeysetValidate); !errors.Is(err, lookupErr) {
+		t.Fatal("a failed commitment lookup didn't fail parsing", err)
+	}
+	committed := &daprovider.CommittedDictionaries{
+		Committed: func(hash common.Hash) (bool, error) { return hash == crypto.Keccak256Hash(dictionary), nil },
+		Reader:    dictionaries,
+	}
+	parsed, err = parseSequencerMessage(ctx, 0, common.Hash{}, batch, nil, committed, daprovider.KeysetValidate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parsed.segments) != 1 {
+		t.Fatal("didn't parse a batch with a committed dictionary", parsed.segments)
+	}
+
+	preimages := make(map[arbutil.PreimageType]map[common.Hash][]byte)
+	if err := RecordBatchDictionary(ctx, batch[40:], dictionaries, daprovider.RecordPreimagesTo(preimages)); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(preimages[arbutil.Keccak256PreimageType][crypto.Keccak256Hash(dictionary)], dictionary) {
+		t.Fatal("dictionary preimage wasn't recorded")
+	}
+}
@@ -23,10 +23,12 @@ import (
 	"github.com/gobwas/ws/wsflate"
 	flag "github.com/spf13/pflag"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/offchainlabs/nitro/arbutil"
 	m "github.com/offchainlabs/nitro/broadcaster/message"
+	"github.com/offchainlabs/nitro/broadcaster/redisfeed"
 	"github.com/offchainlabs/nitro/util/contracts"
 	"github.com/offchainlabs/nitro/util/signature"
 	"github.com/offchainlabs/nitro/util/stopwaiter"
@@ -44,6 +46,9 @@ type FeedConfig struct {
 }
 
 func (fc *FeedConfig) Validate() error {
+	if err := fc.Input.Validate(); err != nil {
+		return err
+	}
 	return fc.Output.Validate()
 }
 
@@ -67,14 +72,28 @@ type Config struct {
 	RequireChainId          bool                     `koanf:"require-chain-id" reload:"hot"`
 	RequireFeedVersion      bool                     `koanf:"require-feed-version" reload:"hot"`
 	Timeout                 time.Duration            `koanf:"timeout" reload:"hot"`
-	URL                     []string                 `koanf:"url"`
-	SecondaryURL            []string                 `koanf:"secondary-url"`
+	URL                     []string                 `koanf:"url" reload:"hot"`
+	SecondaryURL            []string                 `koanf:"secondary-url" reload:"hot"`
 	Verify                  signature.VerifierConfig `koanf:"verify"`
+	RequireSignature        bool                     `koanf:"require-signature"`
 	EnableCompression       bool                     `koanf:"enable-compression" reload:"hot"`
+	RedisStream             redisfeed.InputConfig    `koanf:"redis-stream"`
+}
+
+func (c *Config) Validate() error {
+	for _, addr := range c.Verify.AllowedAddresses {
+		if !common.IsHexAddress(addr) {
+			return fmt.Errorf("invalid feed verify allowed address %q", addr)
+		}
+	}
+	if c.RequireSignature && len(c.Verify.AllowedAddresses) == 0 && !c.Verify.AcceptSequencer {
+		return errors.New("feed input require-signature needs verify.allowed-addresses or verify.accept-sequencer")
+	}
+	return c.RedisStream.Validate()
 }
 
 func (c *Config) Enable() bool {
-	return len(c.URL) > 0 && c.URL[0] != ""
+	return (len(c.URL) > 0 && c.URL[0] != "") || c.RedisStream.Enabled()
 }
 
 type ConfigFetcher func() *Config
@@ -85,10 +104,12 @@ func ConfigAddOptions(prefix string, f *flag.FlagSet) {
 	f.Bool(prefix+".require-chain-id", DefaultConfig.RequireChainId, "require chain id to be present on connect")
 	f.Bool(prefix+".require-feed-version", DefaultConfig.RequireFeedVersion, "require feed version to be present on connect")
 	f.Duration(prefix+".timeout", DefaultConfig.Timeout, "duration to wait before timing out connection to sequencer feed")
-	f.StringSlice(prefix+".url", DefaultConfig.URL, "list of primary URLs of sequencer feed source")
+	f.StringSlice(prefix+".url", DefaultConfig.URL, "list of primary URLs of sequencer feed source (unix:///path for a feed on a unix domain socket)")
 	f.StringSlice(prefix+".secondary-url", DefaultConfig.SecondaryURL, "list of secondary URLs of sequencer feed source. Would be started in the order they appear in the list when primary feeds fails")
 	signature.FeedVerifierConfigAddOptions(prefix+".verify", f)
+	f.Bool(prefix+".require-signature", DefaultConfig.RequireSignature, "reject feed messages that aren't signed by an allowed address or the sequencer, regardless of verify.dangerous.accept-missing")
 	f.Bool(prefix+".enable-compression", DefaultConfig.EnableCompression, "enable per message deflate compression support")
+	redisfeed.InputConfigAddOptions(prefix+".redis-stream", f)
 }
 
 var DefaultConfig = Config{
@@ -97,10 +118,12 @@ var DefaultConfig = Config{
 	RequireChainId:          false,
 	RequireFeedVersion:      false,
 	Verify:                  signature.DefultFeedVerifierConfig,
+	RequireSignature:        true,
 	URL:                     []string{},
 	SecondaryURL:            []string{},
 	Timeout:                 20 * time.Second,
 	EnableCompression:       true,
+	RedisStream:             redisfeed.DefaultInputConfig,
 }
 
 var DefaultTestConfig = Config{
@@ -109,10 +132,12 @@ var DefaultTestConfig = Config{
 	RequireChainId:          false,
 	RequireFeedVersion:      false,
 	Verify:                  signature.DefultFeedVerifierConfig,
+	RequireSignature:        false,
 	URL:                     []string{""},
 	SecondaryURL:            []string{},
 	Timeout:                 200 * time.Millisecond,
 	EnableCompression:       true,
+	RedisStream:             redisfeed.TestInputConfig,
 }
 
 type TransactionStreamerInterface interface {
@@ -148,6 +173,9 @@ var ErrIncorrectChainId = errors.New("incorrect chain id")
 var ErrMissingChainId = errors.New("missing chain id")
 var ErrMissingFeedServerVersion = errors.New("missing feed server version")
 
+// UnixSocketURLPrefix starts the URL of a feed served on a unix domain socket, followed by the socket's path.
+const UnixSocketURLPrefix = "unix://"
+
 func NewBroadcastClient(
 	config ConfigFetcher,
 	websocketUrl string,
@@ -159,7 +187,7 @@ func NewBroadcastClient(
 	addrVerifier contracts.AddressVerifierInterface,
 	adjustCount func(int32),
 ) (*BroadcastClient, error) {
-	sigVerifier, err := signature.NewVerifier(&config().Verify, addrVerifier)
+	sigVerifier, err := newFeedVerifier(config(), addrVerifier)
 	if err != nil {
 		return nil, err
 	}
@@ -282,11 +310,21 @@ func (bc *BroadcastClient) connect(ctx context.Context, nextSeqNum arbutil.Messa
 		Extensions: extensions,
 	}
 
+	feedUrl := bc.websocketUrl
+	if socketPath, ok := strings.CutPrefix(feedUrl, UnixSocketURLPrefix); ok {
+		timeoutDialer.NetDial = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "unix", socketPath)
+		}
+		// the host is only sent in the handshake
+		feedUrl = "ws://localhost/"
+	}
+
 	if bc.isShuttingDown() {
 		return nil, nil
 	}
 
-	conn, br, _, err := timeoutDialer.Dial(ctx, bc.websocketUrl)
+	conn, br, _, err := timeoutDialer.Dial(ctx, feedUrl)
 	if errors.Is(err, ErrIncorrectFeedServerVersion) || errors.Is(err, ErrIncorrectChainId) {
 		return nil, err
 	}
@@ -406,6 +444,7 @@ func (bc *BroadcastClient) startBackgroundReader(earlyFrameData io.Reader) {
 				}
 				if res.Version == 1 {
 					if len(res.Messages) > 0 {
+						verified := make([]*m.BroadcastFeedMessage, 0, len(res.Messages))
 						for _, message := range res.Messages {
 							if message == nil {
 								log.Warn("ignoring nil feed message")
@@ -420,9 +459,13 @@ func (bc *BroadcastClient) startBackgroundReader(earlyFrameData io.Reader) {
 							}
 
 							bc.nextSeqNum = message.SequenceNumber + 1
+							verified = append(verified, message)
 						}
-						if err := bc.txStreamer.AddBroadcastMessages(res.Messages); err != nil {
-							log.Error("Error adding message from Sequencer Feed", "err", err)
+						// never pass on messages that failed verification
+						if len(verified) > 0 {
+							if err := bc.txStreamer.AddBroadcastMessages(verified); err != nil {
+								log.Error("Error adding message from Sequencer Feed", "err", err)
+							}
 						}
 					}
 					if res.ConfirmedSequenceNumberMessage != nil && bc.confirmedSequenceNumberListener != nil {
@@ -487,13 +530,34 @@ func (bc *BroadcastClient) StopAndWait() {
 }
 
 func (bc *BroadcastClient) isValidSignature(ctx context.Context, message *m.BroadcastFeedMessage) error {
-	if bc.config().Verify.Dangerous.AcceptMissing && bc.sigVerifier == nil {
+	return verifyFeedMessage(ctx, bc.config(), bc.sigVerifier, bc.chainId, message)
+}
+
+// newFeedVerifier creates the verifier for feed message signatures. If signatures are required, missing signatures
+// are rejected, and without a way to look up the sequencer only the allowed addresses are trusted.
+func newFeedVerifier(config *Config, addrVerifier contracts.AddressVerifierInterface) (*signature.Verifier, error) {
+	if !config.RequireSignature {
+		return signature.NewVerifier(&config.Verify, addrVerifier)
+	}
+	verifyConfig := config.Verify
+	verifyConfig.Dangerous.AcceptMissing = false
+	if addrVerifier == nil {
+		if len(verifyConfig.AllowedAddresses) == 0 {
+			return nil, errors.New("feed signatures are required, but no allowed addresses are configured and the sequencer address isn't available (configure verify.allowed-addresses, or disable require-signature to trust the feed)")
+		}
+		verifyConfig.AcceptSequencer = false
+	}
+	return signature.NewVerifier(&verifyConfig, addrVerifier)
+}
+
+func verifyFeedMessage(ctx context.Context, config *Config, sigVerifier *signature.Verifier, chainId uint64, message *m.BroadcastFeedMessage) error {
+	if config.Verify.Dangerous.AcceptMissing && !config.RequireSignature && sigVerifier == nil {
 		// Verifier disabled
 		return nil
 	}
-	hash, err := message.Hash(bc.chainId)
+	hash, err := message.Hash(chainId)
 	if err != nil {
 		return fmt.Errorf("error getting message hash for sequence number %v: %w", message.SequenceNumber, err)
 	}
-	return bc.sigVerifier.VerifyHash(ctx, message.Signature, hash)
+	return sigVerifier.VerifyHash(ctx, message.Signature, hash)
 }
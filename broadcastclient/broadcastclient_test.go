@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"path/filepath"
 	"strconv"
 	"sync"
 	"testing"
@@ -65,6 +66,37 @@ func TestReceiveMessagesWithRequiredCompressionButClientDisabled(t *testing.T) {
 	testReceiveMessages(t, false, true, true, true)
 }
 
+func TestReceiveMessagesOverUnixSocket(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	broadcasterConfig := wsbroadcastserver.DefaultTestBroadcasterConfig
+	broadcasterConfig.UnixSocket = filepath.Join(t.TempDir(), "feed.sock")
+	messageCount := 100
+	chainId := uint64(9742)
+
+	privateKey, err := crypto.GenerateKey()
+	Require(t, err)
+	sequencerAddr := crypto.PubkeyToAddress(privateKey.PublicKey)
+	dataSigner := signature.DataSignerFromPrivateKey(privateKey)
+
+	feedErrChan := make(chan error, 10)
+	b := broadcaster.NewBroadcaster(func() *wsbroadcastserver.BroadcasterConfig { return &broadcasterConfig }, chainId, feedErrChan, dataSigner)
+	Require(t, b.Initialize())
+	Require(t, b.Start(ctx))
+	defer b.StopAndWait()
+
+	var wg sync.WaitGroup
+	startMakeBroadcastClient(ctx, t, DefaultTestConfig, b.ListenerAddr(), 0, messageCount, chainId, &wg, &sequencerAddr)
+	go func() {
+		for i := 0; i < messageCount; i++ {
+			Require(t, b.BroadcastSingle(arbostypes.TestMessageWithMetadataAndRequestId, arbutil.MessageIndex(i), nil))
+		}
+	}()
+	wg.Wait()
+}
+
 func testReceiveMessages(t *testing.T, clientCompression bool, serverCompression bool, serverRequire bool, expectNoMessagesReceived bool) {
 	t.Helper()
 	ctx, cancel := context.WithCancel(context.Background())
@@ -178,6 +210,57 @@ func TestInvalidSignature(t *testing.T) {
 	}
 }
 
+func TestRequireSignature(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	chainId := uint64(9742)
+	settings := wsbroadcastserver.DefaultTestBroadcasterConfig
+	fatalErrChan := make(chan error, 10)
+	// an unsigned feed, as served by a relay that forged or stripped the signatures
+	b := broadcaster.NewBroadcaster(func() *wsbroadcastserver.BroadcasterConfig { return &settings }, chainId, fatalErrChan, nil)
+	Require(t, b.Initialize())
+	Require(t, b.Start(ctx))
+	defer b.StopAndWait()
+
+	trustedKey, err := crypto.GenerateKey()
+	Require(t, err)
+	config := DefaultTestConfig
+	config.RequireSignature = true
+	if _, err := newFeedVerifier(&config, nil); err == nil {
+		Fail(t, "created a verifier that requires signatures without any trusted signer")
+	}
+	config.Verify.AllowedAddresses = []string{crypto.PubkeyToAddress(trustedKey.PublicKey).Hex()}
+	Require(t, config.Validate())
+
+	ts := NewDummyTransactionStreamer(chainId, nil)
+	broadcastClient, err := newTestBroadcastClient(config, b.ListenerAddr(), chainId, 0, ts, nil, fatalErrChan, nil)
+	Require(t, err)
+	broadcastClient.Start(ctx)
+	defer broadcastClient.StopAndWait()
+
+	Require(t, b.BroadcastSingle(arbostypes.TestMessageWithMetadataAndRequestId, 0, nil))
+
+	timer := time.NewTimer(2 * time.Second)
+	defer timer.Stop()
+	select {
+	case err := <-fatalErrChan:
+		if !errors.Is(err, signature.ErrMissingSignature) {
+			Fail(t, "unexpected error", err)
+		}
+	case msg := <-ts.messageReceiver:
+		Fail(t, "unsigned message", msg.SequenceNumber, "was accepted")
+	case <-timer.C:
+		Fail(t, "unsigned message wasn't rejected")
+	}
+	select {
+	case msg := <-ts.messageReceiver:
+		Fail(t, "unsigned message", msg.SequenceNumber, "was accepted")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
 type dummyTransactionStreamer struct {
 	messageReceiver chan m.BroadcastFeedMessage
 	chainId         uint64
@@ -200,7 +283,12 @@ func (ts *dummyTransactionStreamer) AddBroadcastMessages(feedMessages []*m.Broad
 }
 
 func newTestBroadcastClient(config Config, listenerAddress net.Addr, chainId uint64, currentMessageCount arbutil.MessageIndex, txStreamer TransactionStreamerInterface, confirmedSequenceNumberListener chan arbutil.MessageIndex, feedErrChan chan error, validAddr *common.Address) (*BroadcastClient, error) {
-	port := listenerAddress.(*net.TCPAddr).Port
+	var url string
+	if socket, ok := listenerAddress.(*net.UnixAddr); ok {
+		url = UnixSocketURLPrefix + socket.Name
+	} else {
+		url = fmt.Sprintf("ws://127.0.0.1:%d/", listenerAddress.(*net.TCPAddr).Port)
+	}
 	var av contracts.AddressVerifierInterface
 	if validAddr != nil {
 		config.Verify.AcceptSequencer = true
@@ -208,7 +296,7 @@ func newTestBroadcastClient(config Config, listenerAddress net.Addr, chainId uin
 	} else {
 		config.Verify.AcceptSequencer = false
 	}
-	return NewBroadcastClient(func() *Config { return &config }, fmt.Sprintf("ws://127.0.0.1:%d/", port), chainId, currentMessageCount, txStreamer, confirmedSequenceNumberListener, feedErrChan, av, func(_ int32) {})
+	return NewBroadcastClient(func() *Config { return &config }, url, chainId, currentMessageCount, txStreamer, confirmedSequenceNumberListener, feedErrChan, av, func(_ int32) {})
 }
 
 func startMakeBroadcastClient(ctx context.Context, t *testing.T, clientConfig Config, addr net.Addr, index int, expectedCount int, chainId uint64, wg *sync.WaitGroup, sequencerAddr *common.Address) {
@@ -841,3 +929,8 @@ func Require(t *testing.T, err error, printables ...interface{}) {
 	t.Helper()
 	testhelpers.RequireImpl(t, err, printables...)
 }
+
+func Fail(t *testing.T, printables ...interface{}) {
+	t.Helper()
+	testhelpers.FailImpl(t, printables...)
+}
@@ -0,0 +1,188 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package broadcastclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/offchainlabs/nitro/arbutil"
+	m "github.com/offchainlabs/nitro/broadcaster/message"
+	"github.com/offchainlabs/nitro/broadcaster/redisfeed"
+	"github.com/offchainlabs/nitro/util/contracts"
+	"github.com/offchainlabs/nitro/util/redisutil"
+	"github.com/offchainlabs/nitro/util/signature"
+	"github.com/offchainlabs/nitro/util/stopwaiter"
+)
+
+// StreamClient reads sequencer feed messages from a redis stream written by a broadcaster's redis stream output.
+// A stream entry is only considered consumed once its messages were accepted by the transaction streamer, so
+// entries are delivered at least once; already delivered sequence numbers are skipped.
+type StreamClient struct {
+	stopwaiter.StopWaiter
+
+	config      ConfigFetcher
+	client      redis.UniversalClient
+	sigVerifier *signature.Verifier
+	chainId     uint64
+
+	// only accessed by the reader thread
+	nextSeqNum arbutil.MessageIndex
+	lastID     string
+
+	txStreamer                      TransactionStreamerInterface
+	confirmedSequenceNumberListener chan arbutil.MessageIndex
+	fatalErrChan                    chan error
+	adjustCount                     func(int32)
+}
+
+func NewStreamClient(
+	config ConfigFetcher,
+	chainId uint64,
+	currentMessageCount arbutil.MessageIndex,
+	txStreamer TransactionStreamerInterface,
+	confirmedSequenceNumberListener chan arbutil.MessageIndex,
+	fatalErrChan chan error,
+	addrVerifier contracts.AddressVerifierInterface,
+	adjustCount func(int32),
+) (*StreamClient, error) {
+	streamConfig := &config().RedisStream
+	if err := streamConfig.Validate(); err != nil {
+		return nil, err
+	}
+	client, err := redisutil.RedisClientFromURL(streamConfig.RedisURL)
+	if err != nil {
+		return nil, err
+	}
+	if client == nil {
+		return nil, errors.New("redis feed input requires a redis url")
+	}
+	sigVerifier, err := newFeedVerifier(config(), addrVerifier)
+	if err != nil {
+		return nil, err
+	}
+	return &StreamClient{
+		config:                          config,
+		client:                          client,
+		sigVerifier:                     sigVerifier,
+		chainId:                         chainId,
+		nextSeqNum:                      currentMessageCount,
+		lastID:                          "0",
+		txStreamer:                      txStreamer,
+		confirmedSequenceNumberListener: confirmedSequenceNumberListener,
+		fatalErrChan:                    fatalErrChan,
+		adjustCount:                     adjustCount,
+	}, nil
+}
+
+func (sc *StreamClient) Start(ctxIn context.Context) {
+	sc.StopWaiter.Start(ctxIn, sc)
+	sc.LaunchThread(func(ctx context.Context) {
+		connected := false
+		for {
+			streamConfig := &sc.config().RedisStream
+			err := sc.readEntries(ctx, streamConfig)
+			if ctx.Err() != nil {
+				return
+			}
+			if err == nil {
+				if !connected {
+					connected = true
+					sc.adjustCount(1)
+				}
+				continue
+			}
+			log.Warn("error reading feed messages from redis stream", "stream", streamConfig.Stream, "err", err)
+			if connected {
+				connected = false
+				sc.adjustCount(-1)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(streamConfig.RetryInterval):
+			}
+		}
+	})
+}
+
+// readEntries reads and delivers the stream entries after lastID, advancing lastID past each delivered entry.
+func (sc *StreamClient) readEntries(ctx context.Context, streamConfig *redisfeed.InputConfig) error {
+	res, err := sc.client.XRead(ctx, &redis.XReadArgs{
+		Streams: []string{streamConfig.Stream, sc.lastID},
+		Count:   streamConfig.BatchSize,
+		Block:   streamConfig.BlockTime,
+	}).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, stream := range res {
+		for _, entry := range stream.Messages {
+			if err := sc.deliver(ctx, entry); err != nil {
+				return fmt.Errorf("delivering stream entry %v: %w", entry.ID, err)
+			}
+			sc.lastID = entry.ID
+		}
+	}
+	return nil
+}
+
+func (sc *StreamClient) deliver(ctx context.Context, entry redis.XMessage) error {
+	data, ok := entry.Values[redisfeed.MessageKey].(string)
+	if !ok {
+		log.Error("ignoring redis stream entry without a feed message", "id", entry.ID)
+		return nil
+	}
+	var res m.BroadcastMessage
+	if err := json.Unmarshal([]byte(data), &res); err != nil {
+		log.Error("error unmarshalling feed message from redis stream", "id", entry.ID, "err", err)
+		return nil
+	}
+	if res.Version != 1 {
+		return nil
+	}
+	var messages []*m.BroadcastFeedMessage
+	for _, message := range res.Messages {
+		if message == nil || message.SequenceNumber < sc.nextSeqNum {
+			continue
+		}
+		if err := verifyFeedMessage(ctx, sc.config(), sc.sigVerifier, sc.chainId, message); err != nil {
+			log.Error("error validating feed signature", "error", err, "sequence number", message.SequenceNumber)
+			sc.fatalErrChan <- fmt.Errorf("error validating feed signature %v: %w", message.SequenceNumber, err)
+			continue
+		}
+		messages = append(messages, message)
+	}
+	if len(messages) > 0 {
+		if err := sc.txStreamer.AddBroadcastMessages(messages); err != nil {
+			return err
+		}
+		sc.nextSeqNum = messages[len(messages)-1].SequenceNumber + 1
+	}
+	if res.ConfirmedSequenceNumberMessage != nil && sc.confirmedSequenceNumberListener != nil {
+		select {
+		case sc.confirmedSequenceNumberListener <- res.ConfirmedSequenceNumberMessage.SequenceNumber:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+func (sc *StreamClient) StopAndWait() {
+	sc.StopWaiter.StopAndWait()
+	if err := sc.client.Close(); err != nil {
+		log.Warn("error closing redis feed input client", "err", err)
+	}
+}
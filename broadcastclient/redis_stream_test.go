@@ -0,0 +1,93 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package broadcastclient
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/offchainlabs/nitro/arbos/arbostypes"
+	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/broadcaster"
+	"github.com/offchainlabs/nitro/util/contracts"
+	"github.com/offchainlabs/nitro/util/redisutil"
+	"github.com/offchainlabs/nitro/util/signature"
+	"github.com/offchainlabs/nitro/wsbroadcastserver"
+)
+
+func TestRedisStreamFeed(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	chainId := uint64(9742)
+	redisURL := redisutil.CreateTestRedis(ctx, t)
+	privateKey, err := crypto.GenerateKey()
+	Require(t, err)
+	sequencerAddr := crypto.PubkeyToAddress(privateKey.PublicKey)
+
+	settings := wsbroadcastserver.DefaultTestBroadcasterConfig
+	settings.RedisStream.RedisURL = redisURL
+	feedErrChan := make(chan error, 10)
+	b := broadcaster.NewBroadcaster(func() *wsbroadcastserver.BroadcasterConfig { return &settings }, chainId, feedErrChan, signature.DataSignerFromPrivateKey(privateKey))
+	Require(t, b.Initialize())
+	Require(t, b.Start(ctx))
+	defer b.StopAndWait()
+
+	for i := 0; i < 5; i++ {
+		Require(t, b.BroadcastSingle(arbostypes.TestMessageWithMetadataAndRequestId, arbutil.MessageIndex(i), nil))
+	}
+
+	config := DefaultTestConfig
+	config.URL = []string{}
+	config.RedisStream.RedisURL = redisURL
+	config.Verify.AcceptSequencer = true
+	ts := NewDummyTransactionStreamer(chainId, &sequencerAddr)
+	confirmed := make(chan arbutil.MessageIndex, 10)
+	streamClient, err := NewStreamClient(
+		func() *Config { return &config },
+		chainId,
+		2,
+		ts,
+		confirmed,
+		feedErrChan,
+		contracts.NewMockAddressVerifier(sequencerAddr),
+		func(int32) {},
+	)
+	Require(t, err)
+	streamClient.Start(ctx)
+	defer streamClient.StopAndWait()
+
+	expectMessage := func(expected arbutil.MessageIndex) {
+		t.Helper()
+		select {
+		case msg := <-ts.messageReceiver:
+			if msg.SequenceNumber != expected {
+				Fail(t, "expected sequence number", expected, "got", msg.SequenceNumber)
+			}
+		case err := <-feedErrChan:
+			Fail(t, "feed error", err)
+		case <-time.After(10 * time.Second):
+			Fail(t, "timed out waiting for sequence number", expected)
+		}
+	}
+	// messages before the client's message count were already delivered and are skipped
+	for i := arbutil.MessageIndex(2); i < 5; i++ {
+		expectMessage(i)
+	}
+	Require(t, b.BroadcastSingle(arbostypes.TestMessageWithMetadataAndRequestId, 5, nil))
+	expectMessage(5)
+
+	b.Confirm(4)
+	select {
+	case seq := <-confirmed:
+		if seq != 4 {
+			Fail(t, "expected confirmed sequence number 4, got", seq)
+		}
+	case <-time.After(10 * time.Second):
+		Fail(t, "timed out waiting for confirmed sequence number")
+	}
+}
@@ -5,6 +5,8 @@ package broadcastclients
 
 import (
 	"context"
+	"slices"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -40,10 +42,15 @@ func (r *Router) AddBroadcastMessages(feedMessages []*m.BroadcastFeedMessage) er
 }
 
 type BroadcastClients struct {
+	// protects the clients and urls, which can be changed by a config reload
+	mutex            sync.Mutex
 	primaryClients   []*broadcastclient.BroadcastClient
+	primaryURL       []string
 	secondaryClients []*broadcastclient.BroadcastClient
 	secondaryURL     []string
 	makeClient       func(string, *Router) (*broadcastclient.BroadcastClient, error)
+	// reads the feed from a redis stream, as another primary feed
+	streamClient *broadcastclient.StreamClient
 
 	primaryRouter   *Router
 	secondaryRouter *Router
@@ -62,7 +69,7 @@ func NewBroadcastClients(
 	addrVerifier contracts.AddressVerifierInterface,
 ) (*BroadcastClients, error) {
 	config := configFetcher()
-	if len(config.URL) == 0 && len(config.SecondaryURL) == 0 {
+	if len(config.URL) == 0 && len(config.SecondaryURL) == 0 && !config.RedisStream.Enabled() {
 		return nil, nil
 	}
 	newStandardRouter := func() *Router {
@@ -78,7 +85,7 @@ func NewBroadcastClients(
 		secondaryRouter:  newStandardRouter(),
 		primaryClients:   make([]*broadcastclient.BroadcastClient, 0, len(config.URL)),
 		secondaryClients: make([]*broadcastclient.BroadcastClient, 0, len(config.SecondaryURL)),
-		secondaryURL:     config.SecondaryURL,
+		secondaryURL:     slices.Clone(config.SecondaryURL),
 	}
 	clients.makeClient = func(url string, router *Router) (*broadcastclient.BroadcastClient, error) {
 		return broadcastclient.NewBroadcastClient(
@@ -103,8 +110,25 @@ func NewBroadcastClients(
 			continue
 		}
 		clients.primaryClients = append(clients.primaryClients, client)
+		clients.primaryURL = append(clients.primaryURL, address)
 	}
-	if len(clients.primaryClients) == 0 {
+	if config.RedisStream.Enabled() {
+		streamClient, err := broadcastclient.NewStreamClient(
+			configFetcher,
+			l2ChainId,
+			currentMessageCount,
+			clients.primaryRouter,
+			clients.primaryRouter.confirmedSequenceNumberChan,
+			fatalErrChan,
+			addrVerifier,
+			func(delta int32) { clients.adjustCount(delta) },
+		)
+		if err != nil {
+			return nil, err
+		}
+		clients.streamClient = streamClient
+	}
+	if len(clients.primaryClients) == 0 && clients.streamClient == nil {
 		log.Error("no connected feed on startup, last error: %w", lastClientErr)
 		return nil, nil
 	}
@@ -119,6 +143,11 @@ func (bcs *BroadcastClients) adjustCount(delta int32) {
 	}
 }
 
+// Connected returns the number of feeds currently connected.
+func (bcs *BroadcastClients) Connected() int32 {
+	return bcs.connected.Load()
+}
+
 // Clears out a ticker's channel and resets it to the interval
 func clearAndResetTicker(timer *time.Ticker, interval time.Duration) {
 	timer.Stop()
@@ -135,9 +164,14 @@ func (bcs *BroadcastClients) Start(ctx context.Context) {
 	bcs.primaryRouter.StopWaiter.Start(ctx, bcs.primaryRouter)
 	bcs.secondaryRouter.StopWaiter.Start(ctx, bcs.secondaryRouter)
 
+	bcs.mutex.Lock()
 	for _, client := range bcs.primaryClients {
 		client.Start(ctx)
 	}
+	bcs.mutex.Unlock()
+	if bcs.streamClient != nil {
+		bcs.streamClient.Start(ctx)
+	}
 
 	var lastConfirmed arbutil.MessageIndex
 	recentFeedItemsNew := make(map[arbutil.MessageIndex]time.Time, RECENT_FEED_INITIAL_MAP_SIZE)
@@ -238,7 +272,78 @@ func (bcs *BroadcastClients) Start(ctx context.Context) {
 	})
 }
 
+// UpdateURLs applies reloaded feed urls: primary feeds no longer configured are disconnected and new ones are
+// connected, and secondary feeds are started from the new list the next time the primary feeds fail.
+func (bcs *BroadcastClients) UpdateURLs(primaryURL []string, secondaryURL []string) {
+	var removed []*broadcastclient.BroadcastClient
+	// the removed clients are stopped without holding the mutex, as the router thread might need it to drain them
+	defer func() {
+		for _, client := range removed {
+			client.StopAndWait()
+		}
+	}()
+	bcs.mutex.Lock()
+	defer bcs.mutex.Unlock()
+	// before Start, new clients are only created, and are started along with the others
+	ctx, err := bcs.primaryRouter.GetContextSafe()
+	started := err == nil
+
+	var primaryClients []*broadcastclient.BroadcastClient
+	var primaryURLs []string
+	for i, client := range bcs.primaryClients {
+		url := bcs.primaryURL[i]
+		if slices.Contains(primaryURL, url) {
+			primaryClients = append(primaryClients, client)
+			primaryURLs = append(primaryURLs, url)
+			continue
+		}
+		removed = append(removed, client)
+		log.Info("disconnecting removed primary feed", "url", url)
+	}
+	for _, url := range primaryURL {
+		if url == "" || slices.Contains(primaryURLs, url) {
+			continue
+		}
+		client, err := bcs.makeClient(url, bcs.primaryRouter)
+		if err != nil {
+			log.Warn("init broadcast client failed", "address", url, "err", err)
+			continue
+		}
+		if started {
+			client.Start(ctx)
+		}
+		primaryClients = append(primaryClients, client)
+		primaryURLs = append(primaryURLs, url)
+		log.Info("added primary feed", "url", url)
+	}
+	bcs.primaryClients = primaryClients
+	bcs.primaryURL = primaryURLs
+
+	// running secondary feeds are kept until the primary feeds recover, unless they were removed
+	var secondaryClients []*broadcastclient.BroadcastClient
+	var secondaryURLs []string
+	for i, client := range bcs.secondaryClients {
+		url := bcs.secondaryURL[i]
+		if slices.Contains(secondaryURL, url) {
+			secondaryClients = append(secondaryClients, client)
+			secondaryURLs = append(secondaryURLs, url)
+			continue
+		}
+		removed = append(removed, client)
+		log.Info("disconnecting removed secondary feed", "url", url)
+	}
+	for _, url := range secondaryURL {
+		if !slices.Contains(secondaryURLs, url) {
+			secondaryURLs = append(secondaryURLs, url)
+		}
+	}
+	bcs.secondaryClients = secondaryClients
+	bcs.secondaryURL = secondaryURLs
+}
+
 func (bcs *BroadcastClients) startSecondaryFeed(ctx context.Context) {
+	bcs.mutex.Lock()
+	defer bcs.mutex.Unlock()
 	pos := len(bcs.secondaryClients)
 	if pos < len(bcs.secondaryURL) {
 		url := bcs.secondaryURL[pos]
@@ -257,6 +362,8 @@ func (bcs *BroadcastClients) startSecondaryFeed(ctx context.Context) {
 }
 
 func (bcs *BroadcastClients) stopSecondaryFeed() {
+	bcs.mutex.Lock()
+	defer bcs.mutex.Unlock()
 	pos := len(bcs.secondaryClients)
 	if pos > 0 {
 		pos -= 1
@@ -277,10 +384,15 @@ func (bcs *BroadcastClients) stopSecondaryFeed() {
 }
 
 func (bcs *BroadcastClients) StopAndWait() {
+	bcs.mutex.Lock()
+	defer bcs.mutex.Unlock()
 	for _, client := range bcs.primaryClients {
 		client.StopAndWait()
 	}
 	for _, client := range bcs.secondaryClients {
 		client.StopAndWait()
 	}
+	if bcs.streamClient != nil {
+		bcs.streamClient.StopAndWait()
+	}
 }
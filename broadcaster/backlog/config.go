@@ -7,18 +7,22 @@ import (
 type ConfigFetcher func() *Config
 
 type Config struct {
-	SegmentLimit int `koanf:"segment-limit" reload:"hot"`
+	SegmentLimit int          `koanf:"segment-limit" reload:"hot"`
+	Replay       ReplayConfig `koanf:"replay"`
 }
 
 func AddOptions(prefix string, f *flag.FlagSet) {
 	f.Int(prefix+".segment-limit", DefaultConfig.SegmentLimit, "the maximum number of messages each segment within the backlog can contain")
+	ReplayConfigAddOptions(prefix+".replay", f)
 }
 
 var (
 	DefaultConfig = Config{
 		SegmentLimit: 240,
+		Replay:       DefaultReplayConfig,
 	}
 	DefaultTestConfig = Config{
 		SegmentLimit: 3,
+		Replay:       DefaultTestReplayConfig,
 	}
 )
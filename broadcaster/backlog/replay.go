@@ -0,0 +1,347 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package backlog
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+
+	m "github.com/offchainlabs/nitro/broadcaster/message"
+)
+
+var (
+	replaySizeGauge  = metrics.NewRegisteredGauge("arb/feed/replay/messages", nil)
+	replayReadsMeter = metrics.NewRegisteredMeter("arb/feed/replay/reads", nil)
+)
+
+const (
+	replaySegmentSuffix = ".feed"
+	// each record is the sequence number and the length of the json encoded message, followed by the message
+	replayRecordHeaderSize = 12
+)
+
+type ReplayConfig struct {
+	Enable          bool   `koanf:"enable"`
+	Dir             string `koanf:"dir"`
+	SegmentMessages int    `koanf:"segment-messages"`
+	MaxSegments     int    `koanf:"max-segments"`
+}
+
+func (c *ReplayConfig) Validate() error {
+	if !c.Enable {
+		return nil
+	}
+	if c.Dir == "" {
+		return errors.New("feed replay buffer requires a directory")
+	}
+	if c.SegmentMessages <= 0 || c.MaxSegments <= 0 {
+		return errors.New("feed replay buffer segment-messages and max-segments must be positive")
+	}
+	return nil
+}
+
+func ReplayConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.Bool(prefix+".enable", DefaultReplayConfig.Enable, "also keep feed messages in an on-disk buffer, to serve clients that reconnect with a sequence number no longer in the in-memory backlog")
+	f.String(prefix+".dir", DefaultReplayConfig.Dir, "directory to store the feed replay buffer in")
+	f.Int(prefix+".segment-messages", DefaultReplayConfig.SegmentMessages, "number of messages stored in each replay buffer file")
+	f.Int(prefix+".max-segments", DefaultReplayConfig.MaxSegments, "number of replay buffer files to keep, the oldest file is deleted when a new one is started")
+}
+
+var (
+	DefaultReplayConfig = ReplayConfig{
+		Enable:          false,
+		Dir:             "",
+		SegmentMessages: 10_000,
+		MaxSegments:     100,
+	}
+	DefaultTestReplayConfig = ReplayConfig{
+		Enable:          false,
+		Dir:             "",
+		SegmentMessages: 3,
+		MaxSegments:     3,
+	}
+)
+
+// ReplayBuffer is a bounded on-disk buffer of contiguous feed messages. Messages are appended to segment files of a
+// fixed number of messages, and the oldest segment file is deleted once there are too many of them. The offset of
+// every message is kept in memory, so messages can be looked up by sequence number.
+type ReplayBuffer struct {
+	config *ReplayConfig
+
+	mutex    sync.RWMutex // protects segments and their contents
+	segments []*replaySegment
+}
+
+type replaySegment struct {
+	start   uint64
+	file    *os.File
+	offsets []int64
+	size    int64
+}
+
+func (s *replaySegment) end() uint64 {
+	return s.start + uint64(len(s.offsets)) - 1
+}
+
+func replaySegmentPath(dir string, start uint64) string {
+	return filepath.Join(dir, fmt.Sprintf("%020d%s", start, replaySegmentSuffix))
+}
+
+// OpenReplayBuffer opens the replay buffer in the configured directory, keeping the latest contiguous messages
+// already stored there.
+func OpenReplayBuffer(config *ReplayConfig) (*ReplayBuffer, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(config.Dir, 0o755); err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(config.Dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), replaySegmentSuffix) {
+			names = append(names, entry.Name())
+		}
+	}
+	// the zero padded names sort in sequence number order
+	sort.Strings(names)
+	r := &ReplayBuffer{config: config}
+	for _, name := range names {
+		segment, err := loadReplaySegment(filepath.Join(config.Dir, name))
+		if err != nil {
+			r.close()
+			return nil, fmt.Errorf("error loading feed replay buffer file %v: %w", name, err)
+		}
+		if segment == nil {
+			continue
+		}
+		if len(r.segments) > 0 && segment.start != r.segments[len(r.segments)-1].end()+1 {
+			log.Warn("feed replay buffer isn't contiguous, dropping older messages", "file", name)
+			r.dropSegments(len(r.segments))
+		}
+		r.segments = append(r.segments, segment)
+	}
+	r.dropSegments(len(r.segments) - config.MaxSegments)
+	if len(r.segments) > 0 {
+		log.Info("opened feed replay buffer", "dir", config.Dir, "start", r.segments[0].start, "end", r.segments[len(r.segments)-1].end())
+	}
+	r.updateGauge()
+	return r, nil
+}
+
+// loadReplaySegment reads the record headers of a segment file, truncating a partially written last record. Empty
+// segment files are deleted and nil is returned.
+func loadReplaySegment(path string) (*replaySegment, error) {
+	file, err := os.OpenFile(path, os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	segment := &replaySegment{file: file}
+	reader := bufio.NewReader(file)
+	header := make([]byte, replayRecordHeaderSize)
+	for {
+		if _, err := io.ReadFull(reader, header); err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+				_ = file.Close()
+				return nil, err
+			}
+			break
+		}
+		seqNum := binary.BigEndian.Uint64(header)
+		length := binary.BigEndian.Uint32(header[8:])
+		if len(segment.offsets) == 0 {
+			segment.start = seqNum
+		} else if seqNum != segment.end()+1 {
+			_ = file.Close()
+			return nil, fmt.Errorf("unexpected sequence number %v after %v", seqNum, segment.end())
+		}
+		if _, err := reader.Discard(int(length)); err != nil {
+			break
+		}
+		segment.offsets = append(segment.offsets, segment.size)
+		segment.size += replayRecordHeaderSize + int64(length)
+	}
+	if len(segment.offsets) == 0 {
+		_ = file.Close()
+		return nil, os.Remove(path)
+	}
+	if err := file.Truncate(segment.size); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return segment, nil
+}
+
+// Append stores the messages of a broadcast message. Messages at or before the end of the buffer are skipped, and a
+// gap in the sequence numbers clears the buffer, so it only ever holds contiguous messages.
+func (r *ReplayBuffer) Append(bm *m.BroadcastMessage) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	defer r.updateGauge()
+	for _, msg := range bm.Messages {
+		seqNum := uint64(msg.SequenceNumber)
+		if len(r.segments) > 0 {
+			end := r.segments[len(r.segments)-1].end()
+			if seqNum <= end {
+				continue
+			}
+			if seqNum != end+1 {
+				log.Warn("feed replay buffer received non-contiguous message, clearing it", "sequenceNumber", seqNum, "expected", end+1)
+				r.dropSegments(len(r.segments))
+			}
+		}
+		if err := r.append(seqNum, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *ReplayBuffer) append(seqNum uint64, msg *m.BroadcastFeedMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	var segment *replaySegment
+	if len(r.segments) > 0 {
+		segment = r.segments[len(r.segments)-1]
+	}
+	if segment == nil || len(segment.offsets) >= r.config.SegmentMessages {
+		file, err := os.OpenFile(replaySegmentPath(r.config.Dir, seqNum), os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
+		if err != nil {
+			return err
+		}
+		segment = &replaySegment{start: seqNum, file: file}
+		r.segments = append(r.segments, segment)
+		r.dropSegments(len(r.segments) - r.config.MaxSegments)
+	}
+	record := make([]byte, replayRecordHeaderSize, replayRecordHeaderSize+len(data))
+	binary.BigEndian.PutUint64(record, seqNum)
+	// #nosec G115
+	binary.BigEndian.PutUint32(record[8:], uint32(len(data)))
+	record = append(record, data...)
+	if _, err := segment.file.WriteAt(record, segment.size); err != nil {
+		return err
+	}
+	segment.offsets = append(segment.offsets, segment.size)
+	segment.size += int64(len(record))
+	return nil
+}
+
+// Get reads up to maxCount messages starting at the given sequence number.
+func (r *ReplayBuffer) Get(start uint64, maxCount int) ([]*m.BroadcastFeedMessage, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	if !r.contains(start) {
+		return nil, errOutOfBounds
+	}
+	replayReadsMeter.Mark(1)
+	idx := sort.Search(len(r.segments), func(i int) bool { return r.segments[i].end() >= start })
+	var msgs []*m.BroadcastFeedMessage
+	for ; idx < len(r.segments) && len(msgs) < maxCount; idx++ {
+		segment := r.segments[idx]
+		first := start - segment.start
+		last := min(uint64(len(segment.offsets)), first+uint64(maxCount-len(msgs)))
+		from := segment.offsets[first]
+		to := segment.size
+		if last < uint64(len(segment.offsets)) {
+			to = segment.offsets[last]
+		}
+		data := make([]byte, to-from)
+		if _, err := segment.file.ReadAt(data, from); err != nil {
+			return nil, err
+		}
+		for len(data) > 0 {
+			length := binary.BigEndian.Uint32(data[8:replayRecordHeaderSize])
+			var msg m.BroadcastFeedMessage
+			if err := json.Unmarshal(data[replayRecordHeaderSize:replayRecordHeaderSize+length], &msg); err != nil {
+				return nil, err
+			}
+			msgs = append(msgs, &msg)
+			data = data[replayRecordHeaderSize+length:]
+		}
+		start = segment.end() + 1
+	}
+	return msgs, nil
+}
+
+// Contains returns whether the message with the given sequence number is in the buffer.
+func (r *ReplayBuffer) Contains(seqNum uint64) bool {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.contains(seqNum)
+}
+
+func (r *ReplayBuffer) contains(seqNum uint64) bool {
+	return len(r.segments) > 0 && seqNum >= r.segments[0].start && seqNum <= r.segments[len(r.segments)-1].end()
+}
+
+// Count returns the number of messages in the buffer.
+func (r *ReplayBuffer) Count() uint64 {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.count()
+}
+
+func (r *ReplayBuffer) count() uint64 {
+	if len(r.segments) == 0 {
+		return 0
+	}
+	return r.segments[len(r.segments)-1].end() - r.segments[0].start + 1
+}
+
+// dropSegments closes and deletes the oldest n segments.
+func (r *ReplayBuffer) dropSegments(n int) {
+	if n <= 0 {
+		return
+	}
+	for _, segment := range r.segments[:n] {
+		path := segment.file.Name()
+		if err := segment.file.Close(); err != nil {
+			log.Warn("error closing feed replay buffer file", "file", path, "err", err)
+		}
+		if err := os.Remove(path); err != nil {
+			log.Warn("error deleting feed replay buffer file", "file", path, "err", err)
+		}
+	}
+	r.segments = r.segments[n:]
+}
+
+func (r *ReplayBuffer) updateGauge() {
+	// #nosec G115
+	replaySizeGauge.Update(int64(r.count()))
+}
+
+// Close closes the segment files, keeping them on disk.
+func (r *ReplayBuffer) Close() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.close()
+}
+
+func (r *ReplayBuffer) close() {
+	for _, segment := range r.segments {
+		if err := segment.file.Close(); err != nil {
+			log.Warn("error closing feed replay buffer file", "file", segment.file.Name(), "err", err)
+		}
+	}
+	r.segments = nil
+}
@@ -0,0 +1,104 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package backlog
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/offchainlabs/nitro/arbutil"
+	m "github.com/offchainlabs/nitro/broadcaster/message"
+)
+
+func replayMessages(start, end arbutil.MessageIndex) *m.BroadcastMessage {
+	bm := &m.BroadcastMessage{Version: 1}
+	for i := start; i <= end; i++ {
+		bm.Messages = append(bm.Messages, &m.BroadcastFeedMessage{SequenceNumber: i})
+	}
+	return bm
+}
+
+func validateReplay(t *testing.T, r *ReplayBuffer, start, end uint64) {
+	t.Helper()
+	if r.Count() != end-start+1 {
+		t.Fatalf("replay buffer count (%d) does not equal expected count (%d)", r.Count(), end-start+1)
+	}
+	if r.Contains(start-1) || r.Contains(end+1) {
+		t.Errorf("replay buffer contains messages outside of %d-%d", start, end)
+	}
+	msgs, err := r.Get(start, int(end-start+1))
+	if err != nil {
+		t.Fatalf("failed to read replay buffer: %v", err)
+	}
+	if uint64(len(msgs)) != end-start+1 {
+		t.Fatalf("read %d messages from replay buffer, expected %d", len(msgs), end-start+1)
+	}
+	for i, msg := range msgs {
+		if uint64(msg.SequenceNumber) != start+uint64(i) {
+			t.Errorf("unexpected sequence number (%d) in %d returned message", msg.SequenceNumber, i)
+		}
+	}
+}
+
+func TestReplayBuffer(t *testing.T) {
+	config := DefaultTestReplayConfig
+	config.Enable = true
+	config.Dir = t.TempDir()
+	r, err := OpenReplayBuffer(&config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// three segments of three messages
+	if err := r.Append(replayMessages(1, 9)); err != nil {
+		t.Fatal(err)
+	}
+	validateReplay(t, r, 1, 9)
+	// already stored messages are skipped, and a fourth segment drops the first one
+	if err := r.Append(replayMessages(8, 11)); err != nil {
+		t.Fatal(err)
+	}
+	validateReplay(t, r, 4, 11)
+	msgs, err := r.Get(5, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) != 4 || msgs[0].SequenceNumber != 5 || msgs[3].SequenceNumber != 8 {
+		t.Errorf("unexpected messages read across segments: %v", msgs)
+	}
+	if _, err := r.Get(3, 1); !errors.Is(err, errOutOfBounds) {
+		t.Errorf("expected out of bounds error reading a dropped message, got %v", err)
+	}
+
+	// reopening keeps the stored messages and drops a partially written record
+	r.Close()
+	file, err := os.OpenFile(filepath.Join(config.Dir, "00000000000000000010.feed"), os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := file.Write([]byte{0, 0, 0, 0, 0, 0, 0, 12, 0, 0, 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+	r, err = OpenReplayBuffer(&config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	validateReplay(t, r, 4, 11)
+	if err := r.Append(replayMessages(12, 12)); err != nil {
+		t.Fatal(err)
+	}
+	validateReplay(t, r, 4, 12)
+
+	// a gap clears the buffer
+	if err := r.Append(replayMessages(20, 21)); err != nil {
+		t.Fatal(err)
+	}
+	validateReplay(t, r, 20, 21)
+	r.Close()
+}
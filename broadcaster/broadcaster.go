@@ -8,6 +8,7 @@ import (
 	"errors"
 	"net"
 	"runtime/debug"
+	"sync/atomic"
 
 	"github.com/gobwas/ws"
 
@@ -18,6 +19,7 @@ import (
 	"github.com/offchainlabs/nitro/arbutil"
 	"github.com/offchainlabs/nitro/broadcaster/backlog"
 	m "github.com/offchainlabs/nitro/broadcaster/message"
+	"github.com/offchainlabs/nitro/broadcaster/redisfeed"
 	"github.com/offchainlabs/nitro/util/signature"
 	"github.com/offchainlabs/nitro/wsbroadcastserver"
 )
@@ -27,6 +29,11 @@ type Broadcaster struct {
 	backlog    backlog.Backlog
 	chainId    uint64
 	dataSigner signature.DataSignerFunc
+	config     wsbroadcastserver.BroadcasterConfigFetcher
+	// publishes messages to a redis stream alongside the websocket feed, if configured
+	streamOutput *redisfeed.Output
+	// the sequencer coordinator's fencing token, stamped on every message broadcast
+	fencingToken atomic.Uint64
 }
 
 func NewBroadcaster(config wsbroadcastserver.BroadcasterConfigFetcher, chainId uint64, feedErrChan chan error, dataSigner signature.DataSignerFunc) *Broadcaster {
@@ -36,6 +43,7 @@ func NewBroadcaster(config wsbroadcastserver.BroadcasterConfigFetcher, chainId u
 		backlog:    bklg,
 		chainId:    chainId,
 		dataSigner: dataSigner,
+		config:     config,
 	}
 }
 
@@ -44,24 +52,28 @@ func (b *Broadcaster) NewBroadcastFeedMessage(
 	sequenceNumber arbutil.MessageIndex,
 	blockHash *common.Hash,
 ) (*m.BroadcastFeedMessage, error) {
-	var messageSignature []byte
+	feedMessage := &m.BroadcastFeedMessage{
+		SequenceNumber: sequenceNumber,
+		Message:        message,
+		BlockHash:      blockHash,
+		FencingToken:   b.fencingToken.Load(),
+	}
 	if b.dataSigner != nil {
-		hash, err := message.Hash(sequenceNumber, b.chainId)
+		hash, err := feedMessage.Hash(b.chainId)
 		if err != nil {
 			return nil, err
 		}
-		messageSignature, err = b.dataSigner(hash.Bytes())
+		feedMessage.Signature, err = b.dataSigner(hash.Bytes())
 		if err != nil {
 			return nil, err
 		}
 	}
+	return feedMessage, nil
+}
 
-	return &m.BroadcastFeedMessage{
-		SequenceNumber: sequenceNumber,
-		Message:        message,
-		BlockHash:      blockHash,
-		Signature:      messageSignature,
-	}, nil
+// SetFencingToken sets the fencing token included in messages broadcast from now on.
+func (b *Broadcaster) SetFencingToken(token uint64) {
+	b.fencingToken.Store(token)
 }
 
 func (b *Broadcaster) BroadcastSingle(
@@ -123,12 +135,12 @@ func (b *Broadcaster) BroadcastFeedMessages(messages []*m.BroadcastFeedMessage)
 		Messages: messages,
 	}
 
-	b.server.Broadcast(bm)
+	b.broadcast(bm)
 }
 
 func (b *Broadcaster) Confirm(seq arbutil.MessageIndex) {
 	log.Debug("confirming sequence number", "sequenceNumber", seq)
-	b.server.Broadcast(&m.BroadcastMessage{
+	b.broadcast(&m.BroadcastMessage{
 		Version: 1,
 		ConfirmedSequenceNumberMessage: &m.ConfirmedSequenceNumberMessage{
 			SequenceNumber: seq,
@@ -136,6 +148,13 @@ func (b *Broadcaster) Confirm(seq arbutil.MessageIndex) {
 	})
 }
 
+func (b *Broadcaster) broadcast(bm *m.BroadcastMessage) {
+	b.server.Broadcast(bm)
+	if b.streamOutput != nil {
+		b.streamOutput.Publish(bm)
+	}
+}
+
 func (b *Broadcaster) ClientCount() int32 {
 	return b.server.ClientCount()
 }
@@ -150,19 +169,37 @@ func (b *Broadcaster) GetCachedMessageCount() int {
 }
 
 func (b *Broadcaster) Initialize() error {
+	if streamConfig := &b.config().RedisStream; streamConfig.Enabled() {
+		output, err := redisfeed.NewOutput(streamConfig)
+		if err != nil {
+			return err
+		}
+		b.streamOutput = output
+	}
 	return b.server.Initialize()
 }
 
 func (b *Broadcaster) Start(ctx context.Context) error {
+	b.startStreamOutput(ctx)
 	return b.server.Start(ctx)
 }
 
 func (b *Broadcaster) StartWithHeader(ctx context.Context, header ws.HandshakeHeader) error {
+	b.startStreamOutput(ctx)
 	return b.server.StartWithHeader(ctx, header)
 }
 
+func (b *Broadcaster) startStreamOutput(ctx context.Context) {
+	if b.streamOutput != nil {
+		b.streamOutput.Start(ctx)
+	}
+}
+
 func (b *Broadcaster) StopAndWait() {
 	b.server.StopAndWait()
+	if b.streamOutput != nil {
+		b.streamOutput.StopAndWait()
+	}
 }
 
 func (b *Broadcaster) Started() bool {
@@ -1,7 +1,10 @@
 package message
 
 import (
+	"encoding/binary"
+
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/offchainlabs/nitro/arbos/arbostypes"
 	"github.com/offchainlabs/nitro/arbutil"
 )
@@ -36,6 +39,9 @@ type BroadcastFeedMessage struct {
 	Message        arbostypes.MessageWithMetadata `json:"message"`
 	BlockHash      *common.Hash                   `json:"blockHash,omitempty"`
 	Signature      []byte                         `json:"signature"`
+	// FencingToken is the sequencer coordinator's token for the lockout the message was sequenced or relayed
+	// under. It increases with every change of chosen sequencer, and is covered by the signature.
+	FencingToken uint64 `json:"fencingToken,omitempty"`
 
 	CumulativeSumMsgSize uint64 `json:"-"`
 }
@@ -48,8 +54,17 @@ func (m *BroadcastFeedMessage) UpdateCumulativeSumMsgSize(val uint64) {
 	m.CumulativeSumMsgSize += val + m.Size()
 }
 
+var fencingTokenPrefix = []byte("Arbitrum Nitro feed fencing token:")
+
+// Hash is what the sequencer signs. A message without a fencing token has the hash of its message with
+// metadata, as before fencing tokens were added, so feeds without them still verify. Otherwise the token is
+// hashed in, so it can't be changed without invalidating the signature.
 func (m *BroadcastFeedMessage) Hash(chainId uint64) (common.Hash, error) {
-	return m.Message.Hash(m.SequenceNumber, chainId)
+	hash, err := m.Message.Hash(m.SequenceNumber, chainId)
+	if err != nil || m.FencingToken == 0 {
+		return hash, err
+	}
+	return crypto.Keccak256Hash(fencingTokenPrefix, hash.Bytes(), binary.BigEndian.AppendUint64(nil, m.FencingToken)), nil
 }
 
 type ConfirmedSequenceNumberMessage struct {
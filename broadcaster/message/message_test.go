@@ -0,0 +1,41 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package message
+
+import (
+	"testing"
+
+	"github.com/offchainlabs/nitro/arbutil"
+)
+
+func TestFeedMessageHashCoversFencingToken(t *testing.T) {
+	const chainId = 412346
+	msg := CreateDummyBroadcastMessages([]arbutil.MessageIndex{7})[0]
+	unfenced, err := msg.Hash(chainId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// without a token, the hash is unchanged from before fencing tokens
+	messageHash, err := msg.Message.Hash(msg.SequenceNumber, chainId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unfenced != messageHash {
+		t.Fatal("hash of a message without a fencing token changed")
+	}
+
+	msg.FencingToken = 5
+	fenced, err := msg.Hash(chainId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg.FencingToken = 6
+	rewritten, err := msg.Hash(chainId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fenced == unfenced || fenced == rewritten {
+		t.Fatal("fencing token isn't covered by the hash")
+	}
+}
@@ -0,0 +1,244 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+// Package redisfeed carries sequencer feed messages over a Redis stream, as an alternative to the websocket relay
+// for operators that already fan out data through Redis.
+package redisfeed
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+
+	m "github.com/offchainlabs/nitro/broadcaster/message"
+	"github.com/offchainlabs/nitro/util/redisutil"
+	"github.com/offchainlabs/nitro/util/stopwaiter"
+)
+
+// MessageKey is the stream entry field holding the JSON encoded BroadcastMessage.
+const MessageKey = "msg"
+
+var (
+	publishedCounter = metrics.NewRegisteredCounter("arb/feed/redis/published", nil)
+	droppedCounter   = metrics.NewRegisteredCounter("arb/feed/redis/dropped", nil)
+)
+
+type OutputConfig struct {
+	RedisURL      string        `koanf:"redis-url"`
+	Stream        string        `koanf:"stream"`
+	MaxLen        int64         `koanf:"max-len"`
+	Queue         int           `koanf:"queue"`
+	QueueTimeout  time.Duration `koanf:"queue-timeout"`
+	RetryInterval time.Duration `koanf:"retry-interval"`
+}
+
+func (c *OutputConfig) Enabled() bool {
+	return c.RedisURL != ""
+}
+
+func (c *OutputConfig) Validate() error {
+	if !c.Enabled() {
+		return nil
+	}
+	if c.Stream == "" {
+		return errors.New("redis feed output stream name cannot be empty")
+	}
+	if c.Queue <= 0 {
+		return errors.New("redis feed output queue must be positive")
+	}
+	if c.QueueTimeout < 0 {
+		return errors.New("redis feed output queue timeout must not be negative")
+	}
+	return nil
+}
+
+var DefaultOutputConfig = OutputConfig{
+	RedisURL:      "",
+	Stream:        "arb-feed",
+	MaxLen:        100_000,
+	Queue:         4096,
+	QueueTimeout:  time.Second,
+	RetryInterval: time.Second,
+}
+
+var TestOutputConfig = OutputConfig{
+	RedisURL:      "",
+	Stream:        "arb-feed",
+	MaxLen:        1000,
+	Queue:         100,
+	QueueTimeout:  100 * time.Millisecond,
+	RetryInterval: 10 * time.Millisecond,
+}
+
+func OutputConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.String(prefix+".redis-url", DefaultOutputConfig.RedisURL, "if set, also publish feed messages to a redis stream on this redis server")
+	f.String(prefix+".stream", DefaultOutputConfig.Stream, "name of the redis stream to publish feed messages to")
+	f.Int64(prefix+".max-len", DefaultOutputConfig.MaxLen, "approximate number of messages to keep in the redis stream (0 = unlimited)")
+	f.Int(prefix+".queue", DefaultOutputConfig.Queue, "number of messages to buffer while the redis server is unreachable")
+	f.Duration(prefix+".queue-timeout", DefaultOutputConfig.QueueTimeout, "how long publishing a message waits for room in a full queue before dropping it")
+	f.Duration(prefix+".retry-interval", DefaultOutputConfig.RetryInterval, "how long to wait before retrying a failed publish")
+}
+
+type InputConfig struct {
+	RedisURL      string        `koanf:"redis-url"`
+	Stream        string        `koanf:"stream"`
+	BatchSize     int64         `koanf:"batch-size"`
+	BlockTime     time.Duration `koanf:"block-time"`
+	RetryInterval time.Duration `koanf:"retry-interval"`
+}
+
+func (c *InputConfig) Enabled() bool {
+	return c.RedisURL != ""
+}
+
+func (c *InputConfig) Validate() error {
+	if !c.Enabled() {
+		return nil
+	}
+	if c.Stream == "" {
+		return errors.New("redis feed input stream name cannot be empty")
+	}
+	if c.BatchSize <= 0 {
+		return errors.New("redis feed input batch-size must be positive")
+	}
+	return nil
+}
+
+var DefaultInputConfig = InputConfig{
+	RedisURL:      "",
+	Stream:        "arb-feed",
+	BatchSize:     256,
+	BlockTime:     time.Second,
+	RetryInterval: time.Second,
+}
+
+var TestInputConfig = InputConfig{
+	RedisURL:      "",
+	Stream:        "arb-feed",
+	BatchSize:     16,
+	BlockTime:     50 * time.Millisecond,
+	RetryInterval: 10 * time.Millisecond,
+}
+
+func InputConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.String(prefix+".redis-url", DefaultInputConfig.RedisURL, "if set, also read feed messages from a redis stream on this redis server")
+	f.String(prefix+".stream", DefaultInputConfig.Stream, "name of the redis stream to read feed messages from")
+	f.Int64(prefix+".batch-size", DefaultInputConfig.BatchSize, "maximum number of stream entries to read at once")
+	f.Duration(prefix+".block-time", DefaultInputConfig.BlockTime, "how long to wait for new stream entries in a single read")
+	f.Duration(prefix+".retry-interval", DefaultInputConfig.RetryInterval, "how long to wait before retrying after a failed read")
+}
+
+// Output publishes broadcast messages to a redis stream. Messages are queued and published in order by a
+// background thread, which retries until the redis server accepts them. Once the queue is full, publishing waits
+// up to queue-timeout for room, slowing the sequencer down, and then drops the message, which readers of the
+// stream see as a gap in sequence numbers.
+type Output struct {
+	stopwaiter.StopWaiter
+	config  *OutputConfig
+	client  redis.UniversalClient
+	queue   chan []byte
+	dropped atomic.Uint64
+}
+
+func NewOutput(config *OutputConfig) (*Output, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	client, err := redisutil.RedisClientFromURL(config.RedisURL)
+	if err != nil {
+		return nil, err
+	}
+	if client == nil {
+		return nil, errors.New("redis feed output requires a redis url")
+	}
+	return &Output{
+		config: config,
+		client: client,
+		queue:  make(chan []byte, config.Queue),
+	}, nil
+}
+
+func (o *Output) Publish(msg *m.BroadcastMessage) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		log.Error("failed to encode feed message for redis stream", "err", err)
+		return
+	}
+	select {
+	case o.queue <- data:
+		return
+	default:
+	}
+	timer := time.NewTimer(o.config.QueueTimeout)
+	defer timer.Stop()
+	select {
+	case o.queue <- data:
+	case <-timer.C:
+		dropped := o.dropped.Add(1)
+		droppedCounter.Inc(1)
+		var sequenceNumber any
+		if len(msg.Messages) > 0 {
+			sequenceNumber = msg.Messages[0].SequenceNumber
+		}
+		log.Error("redis feed output queue full, dropped message", "stream", o.config.Stream, "sequenceNumber", sequenceNumber, "messages", len(msg.Messages), "totalDropped", dropped)
+	}
+}
+
+// Dropped returns how many messages were dropped because the queue stayed full.
+func (o *Output) Dropped() uint64 {
+	return o.dropped.Load()
+}
+
+func (o *Output) publish(ctx context.Context, data []byte) error {
+	return o.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: o.config.Stream,
+		MaxLen: o.config.MaxLen,
+		Approx: o.config.MaxLen > 0,
+		Values: map[string]any{MessageKey: data},
+	}).Err()
+}
+
+func (o *Output) Start(ctx context.Context) {
+	o.StopWaiter.Start(ctx, o)
+	o.LaunchThread(func(ctx context.Context) {
+		for {
+			var data []byte
+			select {
+			case <-ctx.Done():
+				return
+			case data = <-o.queue:
+			}
+			for {
+				err := o.publish(ctx, data)
+				if err == nil {
+					publishedCounter.Inc(1)
+					break
+				}
+				if ctx.Err() != nil {
+					return
+				}
+				log.Warn("failed to publish feed message to redis stream, retrying", "stream", o.config.Stream, "err", err)
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(o.config.RetryInterval):
+				}
+			}
+		}
+	})
+}
+
+func (o *Output) StopAndWait() {
+	o.StopWaiter.StopAndWait()
+	if err := o.client.Close(); err != nil {
+		log.Warn("error closing redis feed output client", "err", err)
+	}
+}
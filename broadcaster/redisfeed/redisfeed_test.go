@@ -0,0 +1,52 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package redisfeed
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	m "github.com/offchainlabs/nitro/broadcaster/message"
+	"github.com/offchainlabs/nitro/util/redisutil"
+	"github.com/offchainlabs/nitro/util/testhelpers"
+)
+
+func TestOutputQueueBackpressure(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	config := TestOutputConfig
+	config.RedisURL = redisutil.CreateTestRedis(ctx, t)
+	config.Queue = 1
+	config.QueueTimeout = time.Second
+	output, err := NewOutput(&config)
+	testhelpers.RequireImpl(t, err)
+	msg := &m.BroadcastMessage{Messages: []*m.BroadcastFeedMessage{{SequenceNumber: 1}}}
+
+	// the output isn't started, so the first message fills the queue and the second waits for room
+	output.Publish(msg)
+	published := make(chan struct{})
+	go func() {
+		output.Publish(msg)
+		close(published)
+	}()
+	select {
+	case <-published:
+		testhelpers.FailImpl(t, "publishing to a full queue didn't wait")
+	case <-time.After(50 * time.Millisecond):
+	}
+	<-output.queue
+	<-published
+	if output.Dropped() != 0 {
+		testhelpers.FailImpl(t, "a message was dropped despite room in time", output.Dropped())
+	}
+
+	// with no room before the timeout, the message is dropped and counted
+	output.config.QueueTimeout = 10 * time.Millisecond
+	output.Publish(msg)
+	if output.Dropped() != 1 {
+		testhelpers.FailImpl(t, "expected one dropped message, got", output.Dropped())
+	}
+}
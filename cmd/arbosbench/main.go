@@ -0,0 +1,77 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/offchainlabs/nitro/arbos/bench"
+	"github.com/offchainlabs/nitro/cmd/util/confighelpers"
+)
+
+const slowestToLog = 5
+
+func main() {
+	if err := mainImpl(os.Args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "arbosbench: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func parseConfig(args []string) (*bench.Config, error) {
+	f := flag.NewFlagSet("arbosbench", flag.ContinueOnError)
+	bench.ConfigAddOptions(f)
+
+	k, err := confighelpers.BeginCommonParse(f, args)
+	if err != nil {
+		return nil, err
+	}
+
+	config := bench.DefaultConfig
+	if err := confighelpers.EndCommonParse(k, &config); err != nil {
+		return nil, err
+	}
+	return &config, config.Validate()
+}
+
+func mainImpl(args []string) error {
+	config, err := parseConfig(args)
+	if err != nil {
+		return err
+	}
+	file, err := os.Open(config.Corpus)
+	if err != nil {
+		return err
+	}
+	corpus, err := bench.ReadCorpus(file)
+	file.Close()
+	if err != nil {
+		return err
+	}
+
+	report, err := bench.Run(config, corpus)
+	if err != nil {
+		return err
+	}
+	if err := bench.WriteReport(config.Output, report); err != nil {
+		return err
+	}
+	for _, index := range report.Slowest(slowestToLog) {
+		message := report.Messages[index]
+		log.Info("slow message", "index", index, "kind", message.Kind, "txs", message.Txs, "gasUsed", message.GasUsed, "meanTime", message.MeanTime)
+	}
+	if !report.Deterministic {
+		first := report.Divergences[0]
+		return fmt.Errorf("%w: run %d produced a different block for message %d", errNondeterministic, first.Run, first.Index)
+	}
+	return nil
+}
+
+var errNondeterministic = errors.New("block production isn't deterministic")
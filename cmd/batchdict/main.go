@@ -0,0 +1,222 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+// batchdict trains a brotli dictionary for a chain's batches from the transactions of a range of its past blocks,
+// and reports how much better the chain's batches would have compressed with it. Nodes accept batches compressed
+// with the dictionary once it's added to their node.batch-dictionaries, and the batch poster uses it once its
+// keccak256 hash is set as node.batch-poster.compression-dictionary.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/offchainlabs/nitro/arbcompress"
+	"github.com/offchainlabs/nitro/arbos"
+	"github.com/offchainlabs/nitro/arbstate"
+	"github.com/offchainlabs/nitro/cmd/genericconf"
+	"github.com/offchainlabs/nitro/cmd/util/confighelpers"
+)
+
+type TrainConfig struct {
+	Url           string `koanf:"url"`
+	FromBlock     uint64 `koanf:"from-block"`
+	ToBlock       uint64 `koanf:"to-block"`
+	Size          int    `koanf:"size"`
+	SegmentLength int    `koanf:"segment-length"`
+	DmerLength    int    `koanf:"dmer-length"`
+	BatchSize     int    `koanf:"batch-size"`
+	Output        string `koanf:"output"`
+	LogLevel      string `koanf:"log-level"`
+	LogType       string `koanf:"log-type"`
+}
+
+var DefaultTrainConfig = TrainConfig{
+	Url:           "http://localhost:8547",
+	FromBlock:     0,
+	ToBlock:       0,
+	Size:          32 * 1024,
+	SegmentLength: 256,
+	DmerLength:    8,
+	BatchSize:     100_000,
+	Output:        "batch-dictionary.bin",
+	LogLevel:      "INFO",
+	LogType:       "plaintext",
+}
+
+func TrainConfigAddOptions(f *flag.FlagSet) {
+	f.String("url", DefaultTrainConfig.Url, "RPC URL of a node of the chain")
+	f.Uint64("from-block", DefaultTrainConfig.FromBlock, "first block whose transactions are trained on")
+	f.Uint64("to-block", DefaultTrainConfig.ToBlock, "last block whose transactions are trained on")
+	f.Int("size", DefaultTrainConfig.Size, "maximum size of the dictionary in bytes")
+	f.Int("segment-length", DefaultTrainConfig.SegmentLength, "length of the pieces of transactions the dictionary is made of")
+	f.Int("dmer-length", DefaultTrainConfig.DmerLength, "length of the substrings counted to find content common to many transactions")
+	f.Int("batch-size", DefaultTrainConfig.BatchSize, "uncompressed size of the batches the dictionary is evaluated on")
+	f.String("output", DefaultTrainConfig.Output, "file the dictionary is written to")
+	f.String("log-level", DefaultTrainConfig.LogLevel, "log level, valid values are CRIT, ERROR, WARN, INFO, DEBUG, TRACE")
+	f.String("log-type", DefaultTrainConfig.LogType, "log type (plaintext or json)")
+}
+
+func (c *TrainConfig) Validate() error {
+	if c.ToBlock < c.FromBlock {
+		return fmt.Errorf("to-block %v is before from-block %v", c.ToBlock, c.FromBlock)
+	}
+	if c.Size <= 0 || c.DmerLength <= 0 || c.SegmentLength < c.DmerLength {
+		return errors.New("size and dmer-length must be positive, and segment-length at least dmer-length")
+	}
+	if c.BatchSize <= 0 || c.BatchSize > arbstate.MaxDecompressedLen {
+		return fmt.Errorf("batch-size must be positive and at most %v", arbstate.MaxDecompressedLen)
+	}
+	return nil
+}
+
+func parseTrain(args []string) (*TrainConfig, error) {
+	f := flag.NewFlagSet("batchdict", flag.ContinueOnError)
+	TrainConfigAddOptions(f)
+	k, err := confighelpers.BeginCommonParse(f, args)
+	if err != nil {
+		return nil, err
+	}
+	var config TrainConfig
+	if err := confighelpers.EndCommonParse(k, &config); err != nil {
+		return nil, err
+	}
+	return &config, config.Validate()
+}
+
+func printSampleUsage(name string) {
+	fmt.Printf("Sample usage: %s --url=<chain RPC URL> --from-block=<block> --to-block=<block>\n\n", name)
+}
+
+func main() {
+	config, err := parseTrain(os.Args[1:])
+	if err != nil {
+		confighelpers.PrintErrorAndExit(err, printSampleUsage)
+	}
+	err = genericconf.InitLog(config.LogType, config.LogLevel, &genericconf.FileLoggingConfig{Enable: false}, nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing logging: %v\n", err)
+		os.Exit(1)
+	}
+	if err := train(context.Background(), config); err != nil {
+		log.Error("training failed", "err", err)
+		os.Exit(1)
+	}
+}
+
+func train(ctx context.Context, config *TrainConfig) error {
+	client, err := ethclient.DialContext(ctx, config.Url)
+	if err != nil {
+		return err
+	}
+	samples, err := fetchSamples(ctx, client, config.FromBlock, config.ToBlock)
+	if err != nil {
+		return err
+	}
+	if len(samples) < 2 {
+		return errors.New("not enough transactions to train on")
+	}
+
+	// hold out the most recent tenth of the samples to evaluate the dictionary on
+	held := max(len(samples)/10, 1)
+	training, evaluation := samples[:len(samples)-held], samples[len(samples)-held:]
+	dictionary := trainDictionary(training, config.Size, config.SegmentLength, config.DmerLength)
+	if len(dictionary) == 0 {
+		return errors.New("the transactions have no content in common to build a dictionary from")
+	}
+	if err := os.WriteFile(config.Output, dictionary, 0o600); err != nil {
+		return err
+	}
+	plain, withDictionary, err := evaluate(evaluation, dictionary, config.BatchSize)
+	if err != nil {
+		return err
+	}
+	log.Info(
+		"trained batch dictionary",
+		"output", config.Output,
+		"hash", crypto.Keccak256Hash(dictionary),
+		"size", len(dictionary),
+		"samples", len(training),
+		"evaluatedOn", len(evaluation),
+		"compressedSize", plain,
+		"compressedSizeWithDictionary", withDictionary,
+	)
+	return nil
+}
+
+// fetchSamples returns the transactions of the blocks as the batch segments that carried them.
+func fetchSamples(ctx context.Context, client *ethclient.Client, from uint64, to uint64) ([][]byte, error) {
+	var samples [][]byte
+	for number := from; number <= to; number++ {
+		block, err := client.BlockByNumber(ctx, new(big.Int).SetUint64(number))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get block %v: %w", number, err)
+		}
+		for _, tx := range block.Transactions() {
+			// ArbOS makes the transactions of these types itself rather than reading them from batches
+			if tx.Type() >= types.ArbitrumDepositTxType {
+				continue
+			}
+			encoded, err := tx.MarshalBinary()
+			if err != nil {
+				return nil, err
+			}
+			segment := append([]byte{arbstate.BatchSegmentKindL2Message, arbos.L2MessageKind_SignedTx}, encoded...)
+			sample, err := rlp.EncodeToBytes(segment)
+			if err != nil {
+				return nil, err
+			}
+			samples = append(samples, sample)
+		}
+		if (number-from)%10_000 == 0 {
+			log.Info("fetching transactions", "block", number, "samples", len(samples))
+		}
+	}
+	return samples, nil
+}
+
+// evaluate compresses the samples in batches of about batchSize bytes, without and with the dictionary,
+// and returns the total compressed sizes.
+func evaluate(samples [][]byte, dictionary []byte, batchSize int) (int, int, error) {
+	plain, withDictionary := 0, 0
+	compress := func(batch []byte) error {
+		compressed, err := arbcompress.CompressWell(batch)
+		if err != nil {
+			return err
+		}
+		plain += len(compressed)
+		compressed, err = arbcompress.CompressWithRawDictionary(batch, arbcompress.LEVEL_WELL, dictionary)
+		if err != nil {
+			return err
+		}
+		withDictionary += len(compressed)
+		return nil
+	}
+	var batch []byte
+	for _, sample := range samples {
+		if len(batch) > 0 && len(batch)+len(sample) > batchSize {
+			if err := compress(batch); err != nil {
+				return 0, 0, err
+			}
+			batch = nil
+		}
+		batch = append(batch, sample...)
+	}
+	if len(batch) > 0 {
+		if err := compress(batch); err != nil {
+			return 0, 0, err
+		}
+	}
+	return plain, withDictionary, nil
+}
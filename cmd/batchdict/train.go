@@ -0,0 +1,96 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package main
+
+import (
+	"bytes"
+)
+
+// trainDictionary builds a raw brotli dictionary of at most size bytes from samples, with a simplified form of the
+// COVER algorithm. The samples are split into epochs, and each epoch in turn contributes the segment whose dmers
+// (substrings of dmerLen bytes) occur in the most samples. The dmers of picked segments stop counting, so the next
+// picks cover new content. Brotli reaches the end of a dictionary with the shortest distances, so the segments
+// picked first are placed last.
+func trainDictionary(samples [][]byte, size int, segmentLen int, dmerLen int) []byte {
+	segmentLen = max(segmentLen, dmerLen)
+	frequency := make(map[string]int)
+	for _, sample := range samples {
+		seen := make(map[string]bool)
+		for i := 0; i+dmerLen <= len(sample); i++ {
+			dmer := string(sample[i : i+dmerLen])
+			if !seen[dmer] {
+				seen[dmer] = true
+				frequency[dmer]++
+			}
+		}
+	}
+	// content unique to a single sample doesn't help compress others
+	for dmer, count := range frequency {
+		if count < 2 {
+			delete(frequency, dmer)
+		}
+	}
+
+	corpus := bytes.Join(samples, nil)
+	epochs := max(size/segmentLen, 1)
+	epochLen := max(len(corpus)/epochs, segmentLen)
+	var picked [][]byte
+	total := 0
+	for epoch, idle := 0, 0; total < size && idle < epochs; epoch = (epoch + 1) % epochs {
+		start := epoch * epochLen
+		if start >= len(corpus) {
+			idle++
+			continue
+		}
+		data := corpus[start:min(start+epochLen, len(corpus))]
+		offset, score := bestSegment(data, frequency, segmentLen, dmerLen)
+		if score == 0 {
+			idle++
+			continue
+		}
+		idle = 0
+		segment := data[offset : offset+segmentLen]
+		for i := 0; i+dmerLen <= len(segment); i++ {
+			delete(frequency, string(segment[i:i+dmerLen]))
+		}
+		picked = append(picked, segment)
+		total += len(segment)
+	}
+
+	dictionary := make([]byte, 0, total)
+	for i := len(picked) - 1; i >= 0; i-- {
+		dictionary = append(dictionary, picked[i]...)
+	}
+	if len(dictionary) > size {
+		dictionary = dictionary[len(dictionary)-size:]
+	}
+	return dictionary
+}
+
+// bestSegment returns the offset and score of the segment of data whose distinct dmers have the highest total frequency.
+func bestSegment(data []byte, frequency map[string]int, segmentLen int, dmerLen int) (int, int) {
+	dmers := segmentLen - dmerLen + 1
+	inWindow := make(map[string]int)
+	score, bestScore, bestOffset := 0, 0, 0
+	for i := 0; i+dmerLen <= len(data); i++ {
+		dmer := string(data[i : i+dmerLen])
+		if inWindow[dmer] == 0 {
+			score += frequency[dmer]
+		}
+		inWindow[dmer]++
+		if i >= dmers {
+			old := string(data[i-dmers : i-dmers+dmerLen])
+			inWindow[old]--
+			if inWindow[old] == 0 {
+				score -= frequency[old]
+				delete(inWindow, old)
+			}
+		}
+		if i >= dmers-1 && score > bestScore {
+			bestScore = score
+			bestOffset = i - dmers + 1
+		}
+	}
+	return bestOffset, bestScore
+}
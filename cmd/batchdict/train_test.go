@@ -0,0 +1,39 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestTrainDictionary(t *testing.T) {
+	var samples [][]byte
+	for i := 0; i < 200; i++ {
+		samples = append(samples, []byte(fmt.Sprintf("{\"player\":%d,\"action\":\"move\",\"position\":{\"x\":%d,\"y\":%d}}", i, i*7, i*13)))
+	}
+	dictionary := trainDictionary(samples, 256, 32, 8)
+	if len(dictionary) == 0 || len(dictionary) > 256 {
+		t.Fatal("unexpected dictionary size", len(dictionary))
+	}
+	if !bytes.Contains(dictionary, []byte("\"action\":\"move\"")) {
+		t.Fatal("dictionary is missing content common to every sample", string(dictionary))
+	}
+
+	plain, withDictionary, err := evaluate(samples, dictionary, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if withDictionary >= plain {
+		t.Fatal("dictionary didn't improve compression", plain, withDictionary)
+	}
+}
+
+func TestTrainDictionaryWithoutCommonContent(t *testing.T) {
+	samples := [][]byte{[]byte("abcdefghijklmnop"), []byte("qrstuvwxyz012345")}
+	if dictionary := trainDictionary(samples, 256, 16, 8); len(dictionary) != 0 {
+		t.Fatal("dictionary built from content unique to single samples", string(dictionary))
+	}
+}
@@ -31,6 +31,17 @@ type ChainInfo struct {
 	HasGenesisState           bool                `json:"has-genesis-state"`
 	ChainConfig               *params.ChainConfig `json:"chain-config"`
 	RollupAddresses           *RollupAddresses    `json:"rollup"`
+	// Where the chain's batch posters and sequencers read parent chain data prices, if not the latest header
+	ParentChainPriceOracle *ParentChainPriceOracle `json:"parent-chain-price-oracle"`
+}
+
+// ParentChainPriceOracle is the parent chain price oracle all of a chain's nodes default to, for chains settling to
+// a parent chain with another fee market than Ethereum's.
+type ParentChainPriceOracle struct {
+	Source          string `json:"source"`
+	ExternalURL     string `json:"external-url"`
+	ExternalMethod  string `json:"external-method"`
+	SmoothingWindow uint64 `json:"smoothing-window"`
 }
 
 func GetChainConfig(chainId *big.Int, chainName string, genesisBlockNum uint64, l2ChainInfoFiles []string, l2ChainInfoJson string) (*params.ChainConfig, error) {
@@ -0,0 +1,203 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package chaininfo
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/offchainlabs/nitro/arbstate/daprovider"
+)
+
+// Deployment is a chain as deployed with nitro-contracts: its chain info, in the format of the chain info files the
+// node reads, and the DAC keyset its sequencer inbox was created with if it's an AnyTrust chain. Deployments are
+// validated as a whole before being written, so a misconfigured chain is caught before anyone runs it.
+type Deployment struct {
+	ChainInfo
+	DasKeyset hexutil.Bytes `json:"das-keyset,omitempty"`
+}
+
+// NewDeployment assembles a deployment from the chain config and rollup addresses files written when deploying
+// the chain's parent chain contracts, as in l2_chain_config.json and deploy.json, and validates it.
+func NewDeployment(chainName string, parentChainId uint64, parentChainIsArbitrum bool, chainConfigJson []byte, rollupAddressesJson []byte, dasKeyset []byte) (*Deployment, error) {
+	var chainConfig params.ChainConfig
+	if err := json.Unmarshal(chainConfigJson, &chainConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse chain config: %w", err)
+	}
+	var rollupAddresses RollupAddresses
+	if err := json.Unmarshal(rollupAddressesJson, &rollupAddresses); err != nil {
+		return nil, fmt.Errorf("failed to parse rollup addresses: %w", err)
+	}
+	deployment := &Deployment{
+		ChainInfo: ChainInfo{
+			ChainName:             chainName,
+			ParentChainId:         parentChainId,
+			ParentChainIsArbitrum: &parentChainIsArbitrum,
+			ChainConfig:           &chainConfig,
+			RollupAddresses:       &rollupAddresses,
+		},
+		DasKeyset: dasKeyset,
+	}
+	if err := deployment.Validate(); err != nil {
+		return nil, err
+	}
+	return deployment, nil
+}
+
+// ValidateChainConfig checks that a chain config describes an Arbitrum chain and is consistent on its own.
+func ValidateChainConfig(chainConfig *params.ChainConfig) error {
+	if chainConfig == nil {
+		return errors.New("missing chain config")
+	}
+	if chainConfig.ChainID == nil || chainConfig.ChainID.Sign() <= 0 || !chainConfig.ChainID.IsUint64() {
+		return fmt.Errorf("invalid chain id %v", chainConfig.ChainID)
+	}
+	if !chainConfig.IsArbitrum() {
+		return errors.New("chain config doesn't enable ArbOS")
+	}
+	arbParams := chainConfig.ArbitrumChainParams
+	if arbParams.InitialArbOSVersion == 0 {
+		return errors.New("chain config has no initial ArbOS version")
+	}
+	if arbParams.MaxCodeSize != 0 && arbParams.MaxInitCodeSize != 0 && arbParams.MaxInitCodeSize < arbParams.MaxCodeSize {
+		return fmt.Errorf("max init code size %v is less than the max code size %v", arbParams.MaxInitCodeSize, arbParams.MaxCodeSize)
+	}
+	return chainConfig.CheckConfigForkOrder()
+}
+
+// Validate checks that the parent chain contracts' addresses are set and distinct.
+func (a *RollupAddresses) Validate() error {
+	contracts := []struct {
+		name    string
+		address common.Address
+	}{
+		{"bridge", a.Bridge},
+		{"inbox", a.Inbox},
+		{"sequencer-inbox", a.SequencerInbox},
+		{"rollup", a.Rollup},
+	}
+	seen := make(map[common.Address]string)
+	for _, contract := range contracts {
+		if contract.address == (common.Address{}) {
+			return fmt.Errorf("missing %v address", contract.name)
+		}
+		if other, ok := seen[contract.address]; ok {
+			return fmt.Errorf("%v and %v have the same address %v", other, contract.name, contract.address)
+		}
+		seen[contract.address] = contract.name
+	}
+	return nil
+}
+
+// Validate checks that the chain info is consistent: that its chain config is valid, that it agrees with the rest
+// of the chain info, and that its rollup addresses are valid if it has any.
+func (c *ChainInfo) Validate() error {
+	if err := ValidateChainConfig(c.ChainConfig); err != nil {
+		return err
+	}
+	arbParams := c.ChainConfig.ArbitrumChainParams
+	if c.ParentChainId != 0 && c.ParentChainId == c.ChainConfig.ChainID.Uint64() {
+		return fmt.Errorf("chain %v is its own parent chain", c.ParentChainId)
+	}
+	if !c.HasGenesisState && arbParams.GenesisBlockNum != 0 {
+		return fmt.Errorf("chain starts at genesis block %v but has no genesis state to start from", arbParams.GenesisBlockNum)
+	}
+	if c.DasIndexUrl != "" && !arbParams.DataAvailabilityCommittee {
+		return errors.New("chain has a DAS index URL but doesn't use a data availability committee")
+	}
+	if c.RollupAddresses != nil {
+		if err := c.RollupAddresses.Validate(); err != nil {
+			return fmt.Errorf("invalid rollup addresses: %w", err)
+		}
+	}
+	return nil
+}
+
+// Validate checks that the deployment is complete and consistent: on top of its chain info being valid, an Orbit
+// chain needs a name, a parent chain, an owner and deployed rollup addresses, and a DAC keyset if and only if it's
+// an AnyTrust chain.
+func (d *Deployment) Validate() error {
+	if err := d.ChainInfo.Validate(); err != nil {
+		return err
+	}
+	if d.ChainName == "" {
+		return errors.New("missing chain name")
+	}
+	if d.ParentChainId == 0 {
+		return errors.New("missing parent chain id")
+	}
+	if d.ParentChainIsArbitrum == nil {
+		return errors.New("missing whether the parent chain is an Arbitrum chain")
+	}
+	if d.RollupAddresses == nil {
+		return errors.New("missing rollup addresses")
+	}
+	if d.RollupAddresses.DeployedAt == 0 {
+		return errors.New("missing the parent chain block the rollup was deployed at")
+	}
+	arbParams := d.ChainConfig.ArbitrumChainParams
+	if arbParams.InitialChainOwner == (common.Address{}) {
+		return errors.New("missing initial chain owner")
+	}
+	if !arbParams.DataAvailabilityCommittee {
+		if len(d.DasKeyset) != 0 {
+			return errors.New("chain doesn't use a data availability committee but has a DAC keyset")
+		}
+		return nil
+	}
+	if len(d.DasKeyset) == 0 {
+		return errors.New("chain uses a data availability committee but has no DAC keyset")
+	}
+	keyset, err := daprovider.DeserializeKeyset(bytes.NewReader(d.DasKeyset), false)
+	if err != nil {
+		return fmt.Errorf("invalid DAC keyset: %w", err)
+	}
+	if keyset.AssumedHonest == 0 || keyset.AssumedHonest > uint64(len(keyset.PubKeys)) {
+		return fmt.Errorf("DAC keyset assumes %v of its %v members are honest", keyset.AssumedHonest, len(keyset.PubKeys))
+	}
+	return nil
+}
+
+// ReadDeployments parses and validates a list of deployments, which must not have two chains with the same id or name.
+// Plain chain info files, like the ones nitro-contracts writes, parse as deployments without DAC keysets.
+func ReadDeployments(data []byte) ([]Deployment, error) {
+	var deployments []Deployment
+	if err := json.Unmarshal(data, &deployments); err != nil {
+		return nil, err
+	}
+	ids := make(map[uint64]bool)
+	names := make(map[string]bool)
+	for i := range deployments {
+		deployment := &deployments[i]
+		if err := deployment.Validate(); err != nil {
+			return nil, fmt.Errorf("deployment %v (%v): %w", i, deployment.ChainName, err)
+		}
+		id := deployment.ChainConfig.ChainID.Uint64()
+		if ids[id] || names[deployment.ChainName] {
+			return nil, fmt.Errorf("deployment %v duplicates chain %v (%v)", i, id, deployment.ChainName)
+		}
+		ids[id] = true
+		names[deployment.ChainName] = true
+	}
+	return deployments, nil
+}
+
+// WriteDeployments validates the deployments and serializes them as a chain info file the node can read.
+func WriteDeployments(deployments []Deployment) ([]byte, error) {
+	data, err := json.MarshalIndent(deployments, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	// validate the deployments as they'll be read back
+	if _, err := ReadDeployments(data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
@@ -0,0 +1,125 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package chaininfo
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/offchainlabs/nitro/arbstate/daprovider"
+	"github.com/offchainlabs/nitro/blsSignatures"
+	"github.com/offchainlabs/nitro/util/testhelpers"
+)
+
+func TestDefaultChainInfoIsValid(t *testing.T) {
+	var chainsInfo []ChainInfo
+	testhelpers.RequireImpl(t, json.Unmarshal(DefaultChainInfo, &chainsInfo))
+	for _, chainInfo := range chainsInfo {
+		if err := chainInfo.Validate(); err != nil {
+			testhelpers.FailImpl(t, "invalid default chain info", chainInfo.ChainName, err)
+		}
+	}
+}
+
+func testDasKeyset(t *testing.T) []byte {
+	t.Helper()
+	keyset := daprovider.DataAvailabilityKeyset{AssumedHonest: 1}
+	for i := 0; i < 2; i++ {
+		pubKey, _, err := blsSignatures.GenerateKeys()
+		testhelpers.RequireImpl(t, err)
+		keyset.PubKeys = append(keyset.PubKeys, pubKey)
+	}
+	var buf bytes.Buffer
+	testhelpers.RequireImpl(t, keyset.Serialize(&buf))
+	return buf.Bytes()
+}
+
+func testDeployment(t *testing.T) (*params.ChainConfig, *RollupAddresses, []byte) {
+	t.Helper()
+	chainConfig := params.ArbitrumDevTestDASChainConfig()
+	chainConfig.ArbitrumChainParams.InitialChainOwner = common.HexToAddress("0x1000")
+	rollupAddresses := &RollupAddresses{
+		Bridge:         common.HexToAddress("0x2001"),
+		Inbox:          common.HexToAddress("0x2002"),
+		SequencerInbox: common.HexToAddress("0x2003"),
+		Rollup:         common.HexToAddress("0x2004"),
+		DeployedAt:     100,
+	}
+	return chainConfig, rollupAddresses, testDasKeyset(t)
+}
+
+func newTestDeployment(t *testing.T, chainConfig *params.ChainConfig, rollupAddresses *RollupAddresses, dasKeyset []byte) (*Deployment, error) {
+	t.Helper()
+	chainConfigJson, err := json.Marshal(chainConfig)
+	testhelpers.RequireImpl(t, err)
+	rollupAddressesJson, err := json.Marshal(rollupAddresses)
+	testhelpers.RequireImpl(t, err)
+	return NewDeployment("test-anytrust", 1, false, chainConfigJson, rollupAddressesJson, dasKeyset)
+}
+
+func TestDeploymentRoundTrip(t *testing.T) {
+	chainConfig, rollupAddresses, dasKeyset := testDeployment(t)
+	deployment, err := newTestDeployment(t, chainConfig, rollupAddresses, dasKeyset)
+	testhelpers.RequireImpl(t, err)
+
+	data, err := WriteDeployments([]Deployment{*deployment})
+	testhelpers.RequireImpl(t, err)
+	deployments, err := ReadDeployments(data)
+	testhelpers.RequireImpl(t, err)
+	if len(deployments) != 1 {
+		testhelpers.FailImpl(t, "unexpected number of deployments", len(deployments))
+	}
+	read := deployments[0]
+	if read.ChainName != deployment.ChainName || read.ChainConfig.ChainID.Cmp(chainConfig.ChainID) != 0 {
+		testhelpers.FailImpl(t, "read a different chain", read.ChainName, read.ChainConfig.ChainID)
+	}
+	if *read.RollupAddresses != *rollupAddresses {
+		testhelpers.FailImpl(t, "read different rollup addresses", read.RollupAddresses)
+	}
+	if !bytes.Equal(read.DasKeyset, dasKeyset) {
+		testhelpers.FailImpl(t, "read a different DAC keyset")
+	}
+
+	// the node reads the same file as chain info
+	chainInfo, err := findChainInfo(0, deployment.ChainName, data)
+	testhelpers.RequireImpl(t, err)
+	if chainInfo == nil || chainInfo.Validate() != nil {
+		testhelpers.FailImpl(t, "node didn't read the deployment as valid chain info")
+	}
+
+	// two deployments of the same chain are rejected
+	if _, err := WriteDeployments([]Deployment{*deployment, *deployment}); err == nil {
+		testhelpers.FailImpl(t, "wrote a duplicate deployment")
+	}
+}
+
+func TestMisconfiguredDeployments(t *testing.T) {
+	chainConfig, rollupAddresses, dasKeyset := testDeployment(t)
+
+	duplicateAddresses := *rollupAddresses
+	duplicateAddresses.Inbox = duplicateAddresses.Bridge
+	if _, err := newTestDeployment(t, chainConfig, &duplicateAddresses, dasKeyset); err == nil {
+		testhelpers.FailImpl(t, "accepted rollup addresses with a duplicate")
+	}
+
+	if _, err := newTestDeployment(t, chainConfig, rollupAddresses, nil); err == nil {
+		testhelpers.FailImpl(t, "accepted an AnyTrust chain without a DAC keyset")
+	}
+
+	ownParent := *chainConfig
+	ownParent.ChainID = common.Big1
+	if _, err := newTestDeployment(t, &ownParent, rollupAddresses, dasKeyset); err == nil {
+		testhelpers.FailImpl(t, "accepted a chain that's its own parent chain")
+	}
+
+	noArbOS := *chainConfig
+	noArbOS.ArbitrumChainParams.EnableArbOS = false
+	if _, err := newTestDeployment(t, &noArbOS, rollupAddresses, dasKeyset); err == nil {
+		testhelpers.FailImpl(t, "accepted a chain config without ArbOS")
+	}
+}
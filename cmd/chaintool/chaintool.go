@@ -0,0 +1,137 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+// chaintool checks Orbit chain deployments for consistency before a node is run on them, and assembles them from
+// the files written when deploying a chain's parent chain contracts.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/nitro/cmd/chaininfo"
+	"github.com/offchainlabs/nitro/cmd/util/confighelpers"
+)
+
+func main() {
+	args := os.Args
+	if len(args) < 2 {
+		panic("Usage: chaintool [validate|assemble] ...")
+	}
+
+	var err error
+	switch strings.ToLower(args[1]) {
+	case "validate":
+		err = validate(args[2:])
+	case "assemble":
+		err = assemble(args[2:])
+	default:
+		panic(fmt.Sprintf("Unknown tool '%s' specified, valid tools are 'validate', 'assemble'", args[1]))
+	}
+	if err != nil {
+		panic(err)
+	}
+}
+
+// chaintool validate ...
+
+type ValidateConfig struct {
+	Deployments string `koanf:"deployments"`
+}
+
+func validate(args []string) error {
+	f := flag.NewFlagSet("chaintool validate", flag.ContinueOnError)
+	f.String("deployments", "", "chain info file listing the deployments to validate")
+	k, err := confighelpers.BeginCommonParse(f, args)
+	if err != nil {
+		return err
+	}
+	var config ValidateConfig
+	if err := confighelpers.EndCommonParse(k, &config); err != nil {
+		return err
+	}
+	if config.Deployments == "" {
+		return errors.New("--deployments must be specified")
+	}
+	data, err := os.ReadFile(config.Deployments)
+	if err != nil {
+		return err
+	}
+	deployments, err := chaininfo.ReadDeployments(data)
+	if err != nil {
+		return err
+	}
+	for _, deployment := range deployments {
+		fmt.Printf("%v (chain %v): valid\n", deployment.ChainName, deployment.ChainConfig.ChainID)
+	}
+	return nil
+}
+
+// chaintool assemble ...
+
+type AssembleConfig struct {
+	ChainName             string `koanf:"chain-name"`
+	ParentChainId         uint64 `koanf:"parent-chain-id"`
+	ParentChainIsArbitrum bool   `koanf:"parent-chain-is-arbitrum"`
+	ChainConfig           string `koanf:"chain-config"`
+	RollupAddresses       string `koanf:"rollup-addresses"`
+	DasKeyset             string `koanf:"das-keyset"`
+	Output                string `koanf:"output"`
+}
+
+func assemble(args []string) error {
+	f := flag.NewFlagSet("chaintool assemble", flag.ContinueOnError)
+	f.String("chain-name", "", "name of the chain")
+	f.Uint64("parent-chain-id", 0, "chain id of the parent chain")
+	f.Bool("parent-chain-is-arbitrum", false, "whether the parent chain is an Arbitrum chain")
+	f.String("chain-config", "l2_chain_config.json", "chain config file the chain was deployed with")
+	f.String("rollup-addresses", "deploy.json", "file with the addresses of the deployed parent chain contracts")
+	f.String("das-keyset", "", "hex encoded DAC keyset the sequencer inbox was deployed with, for AnyTrust chains")
+	f.String("output", "l2_chain_info.json", "chain info file to append the deployment to, created if it doesn't exist")
+	k, err := confighelpers.BeginCommonParse(f, args)
+	if err != nil {
+		return err
+	}
+	var config AssembleConfig
+	if err := confighelpers.EndCommonParse(k, &config); err != nil {
+		return err
+	}
+	chainConfigJson, err := os.ReadFile(config.ChainConfig)
+	if err != nil {
+		return err
+	}
+	rollupAddressesJson, err := os.ReadFile(config.RollupAddresses)
+	if err != nil {
+		return err
+	}
+	var dasKeyset []byte
+	if config.DasKeyset != "" {
+		dasKeyset = common.FromHex(config.DasKeyset)
+	}
+	deployment, err := chaininfo.NewDeployment(config.ChainName, config.ParentChainId, config.ParentChainIsArbitrum, chainConfigJson, rollupAddressesJson, dasKeyset)
+	if err != nil {
+		return err
+	}
+	var deployments []chaininfo.Deployment
+	existing, err := os.ReadFile(config.Output)
+	if err == nil {
+		deployments, err = chaininfo.ReadDeployments(existing)
+		if err != nil {
+			return fmt.Errorf("existing %v: %w", config.Output, err)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	deployments = append(deployments, *deployment)
+	data, err := chaininfo.WriteDeployments(deployments)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(config.Output, append(data, '\n'), 0o600)
+}
@@ -15,6 +15,7 @@ import (
 type ParentChainConfig struct {
 	ID         uint64                        `koanf:"id"`
 	Connection rpcclient.ClientConfig        `koanf:"connection" reload:"hot"`
+	Failover   rpcclient.FailoverConfig      `koanf:"failover" reload:"hot"`
 	BlobClient headerreader.BlobClientConfig `koanf:"blob-client"`
 }
 
@@ -30,6 +31,7 @@ var L1ConnectionConfigDefault = rpcclient.ClientConfig{
 var L1ConfigDefault = ParentChainConfig{
 	ID:         0,
 	Connection: L1ConnectionConfigDefault,
+	Failover:   rpcclient.DefaultFailoverConfig,
 	BlobClient: headerreader.DefaultBlobClientConfig,
 }
 
@@ -44,11 +46,15 @@ var DefaultL1WalletConfig = genericconf.WalletConfig{
 func L1ConfigAddOptions(prefix string, f *flag.FlagSet) {
 	f.Uint64(prefix+".id", L1ConfigDefault.ID, "if set other than 0, will be used to validate database and L1 connection")
 	rpcclient.RPCClientAddOptions(prefix+".connection", f, &L1ConfigDefault.Connection)
+	rpcclient.FailoverConfigAddOptions(prefix+".failover", f)
 	headerreader.BlobClientAddOptions(prefix+".blob-client", f)
 }
 
 func (c *ParentChainConfig) Validate() error {
-	return c.Connection.Validate()
+	if err := c.Connection.Validate(); err != nil {
+		return err
+	}
+	return c.Failover.Validate()
 }
 
 type L2Config struct {
@@ -1,6 +1,7 @@
 package conf
 
 import (
+	"errors"
 	"fmt"
 	"runtime"
 	"strings"
@@ -8,6 +9,8 @@ import (
 
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/spf13/pflag"
+
+	"github.com/offchainlabs/nitro/statetransfer"
 )
 
 type InitConfig struct {
@@ -23,8 +26,12 @@ type InitConfig struct {
 	DevInitBlockNum          uint64        `koanf:"dev-init-blocknum"`
 	Empty                    bool          `koanf:"empty"`
 	ImportWasm               bool          `koanf:"import-wasm"`
+	VerifySnapshot           bool          `koanf:"verify-snapshot"`
+	RequireVerification      bool          `koanf:"require-snapshot-verification"`
 	AccountsPerSync          uint          `koanf:"accounts-per-sync"`
 	ImportFile               string        `koanf:"import-file"`
+	Resume                   bool          `koanf:"resume"`
+	VerifyImport             bool          `koanf:"verify-import"`
 	ThenQuit                 bool          `koanf:"then-quit"`
 	Prune                    string        `koanf:"prune"`
 	PruneBloomSize           uint64        `koanf:"prune-bloom-size"`
@@ -50,7 +57,11 @@ var InitConfigDefault = InitConfig{
 	DevInitBlockNum:          0,
 	Empty:                    false,
 	ImportWasm:               false,
+	VerifySnapshot:           false,
+	RequireVerification:      false,
 	ImportFile:               "",
+	Resume:                   false,
+	VerifyImport:             false,
 	AccountsPerSync:          100000,
 	ThenQuit:                 false,
 	Prune:                    "",
@@ -77,10 +88,14 @@ func InitConfigAddOptions(prefix string, f *pflag.FlagSet) {
 	f.Uint64(prefix+".dev-init-blocknum", InitConfigDefault.DevInitBlockNum, "Number of preinit blocks. Must exist in ancient database.")
 	f.Bool(prefix+".empty", InitConfigDefault.Empty, "init with empty state")
 	f.Bool(prefix+".import-wasm", InitConfigDefault.ImportWasm, "if set, import the wasm directory when downloading a database (contains executable code - only use with highly trusted source)")
+	f.Bool(prefix+".verify-snapshot", InitConfigDefault.VerifySnapshot, "before using a downloaded snapshot, recompute its head state root and check its inbox accumulators against L1 (requires an L1 connection)")
+	f.Bool(prefix+".require-snapshot-verification", InitConfigDefault.RequireVerification, "refuse to start from a downloaded snapshot that hasn't been verified")
 	f.Bool(prefix+".then-quit", InitConfigDefault.ThenQuit, "quit after init is done")
 	f.String(prefix+".import-file", InitConfigDefault.ImportFile, "path for json data to import")
+	f.Bool(prefix+".resume", InitConfigDefault.Resume, "resume an interrupted import from its last accounts-per-sync checkpoint instead of starting over")
+	f.Bool(prefix+".verify-import", InitConfigDefault.VerifyImport, "after importing, re-read the imported accounts and check each checkpointed range against the state")
 	f.Uint(prefix+".accounts-per-sync", InitConfigDefault.AccountsPerSync, "during init - sync database every X accounts. Lower value for low-memory systems. 0 disables.")
-	f.String(prefix+".prune", InitConfigDefault.Prune, "pruning for a given use: \"full\" for full nodes serving RPC requests, or \"validator\" for validators")
+	f.String(prefix+".prune", InitConfigDefault.Prune, "pruning for a given use: \"full\" for full nodes serving RPC requests, \"validator\" for validators, \"validator-minimal\" for validators that don't serve RPC requests, or \"archive\" to keep all state")
 	f.Uint64(prefix+".prune-bloom-size", InitConfigDefault.PruneBloomSize, "the amount of memory in megabytes to use for the pruning bloom filter (higher values prune better)")
 	f.Int(prefix+".prune-threads", InitConfigDefault.PruneThreads, "the number of threads to use when pruning")
 	f.Int(prefix+".prune-trie-clean-cache", InitConfigDefault.PruneTrieCleanCache, "amount of memory in megabytes to cache unchanged state trie nodes with when traversing state database during pruning")
@@ -108,6 +123,12 @@ func (c *InitConfig) Validate() error {
 	if c.PruneTrieCleanCache < 0 {
 		return fmt.Errorf("invalid trie clean cache size: %d, has to be greater or equal 0", c.PruneTrieCleanCache)
 	}
+	if c.RequireVerification && !c.VerifySnapshot {
+		return errors.New("requiring snapshot verification requires verify-snapshot to be enabled")
+	}
+	if c.Resume && c.AccountsPerSync == 0 {
+		return errors.New("resuming an import requires accounts-per-sync checkpoints")
+	}
 	numReorgOptionsSpecified := 0
 	for _, reorgOption := range []int64{c.ReorgToBatch, c.ReorgToMessageBatch, c.ReorgToBlockBatch} {
 		if reorgOption >= 0 {
@@ -124,6 +145,14 @@ func (c *InitConfig) Validate() error {
 	return nil
 }
 
+func (c *InitConfig) ImportConfig() statetransfer.ImportConfig {
+	return statetransfer.ImportConfig{
+		AccountsPerSync: c.AccountsPerSync,
+		Resume:          c.Resume,
+		Verify:          c.VerifyImport,
+	}
+}
+
 func (c *InitConfig) IsReorgRequested() bool {
 	return c.ReorgToBatch >= 0 || c.ReorgToBlockBatch >= 0 || c.ReorgToMessageBatch >= 0
 }
@@ -23,6 +23,7 @@ import (
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/offchainlabs/nitro/arbstate/daprovider"
+	"github.com/offchainlabs/nitro/blsSignatures"
 	"github.com/offchainlabs/nitro/cmd/genericconf"
 	"github.com/offchainlabs/nitro/cmd/util"
 
@@ -35,7 +36,7 @@ import (
 func main() {
 	args := os.Args
 	if len(args) < 2 {
-		panic("Usage: datool [client|keygen|generatehash|dumpkeyset] ...")
+		panic("Usage: datool [client|keygen|generatehash|dumpkeyset|rotatekeyset|verifycert] ...")
 	}
 
 	var err error
@@ -48,6 +49,10 @@ func main() {
 		err = generateHash(args[2])
 	case "dumpkeyset":
 		err = dumpKeyset(args[2:])
+	case "rotatekeyset":
+		err = rotateKeyset(args[2:])
+	case "verifycert":
+		err = verifyCert(args[2:])
 	default:
 		panic(fmt.Sprintf("Unknown tool '%s' specified, valid tools are 'client', 'keygen', 'generatehash'", args[1]))
 	}
@@ -373,3 +378,135 @@ func dumpKeyset(args []string) error {
 
 	return err
 }
+
+// datool rotatekeyset
+
+type RotateKeysetConfig struct {
+	AssumedHonest      uint64   `koanf:"assumed-honest"`
+	PubKeys            []string `koanf:"pubkeys"`
+	PreviousKeysetHash string   `koanf:"previous-keyset-hash"`
+}
+
+func parseRotateKeysetConfig(args []string) (*RotateKeysetConfig, error) {
+	f := flag.NewFlagSet("datool rotatekeyset", flag.ContinueOnError)
+	f.Uint64("assumed-honest", 0, "number of committee members assumed to be honest in the new keyset")
+	f.StringSlice("pubkeys", nil, "base64 encoded BLS public keys of the new committee, in signer mask order")
+	f.String("previous-keyset-hash", "", "hash of the keyset being replaced; if set the calldata to invalidate it is also printed")
+
+	k, err := confighelpers.BeginCommonParse(f, args)
+	if err != nil {
+		return nil, err
+	}
+
+	var config RotateKeysetConfig
+	if err := confighelpers.EndCommonParse(k, &config); err != nil {
+		return nil, err
+	}
+	if config.AssumedHonest == 0 {
+		return nil, errors.New("--assumed-honest must be set")
+	}
+	if len(config.PubKeys) == 0 {
+		return nil, errors.New("--pubkeys must be set")
+	}
+	return &config, nil
+}
+
+func rotateKeyset(args []string) error {
+	config, err := parseRotateKeysetConfig(args)
+	if err != nil {
+		return err
+	}
+
+	pubKeys := make([]blsSignatures.PublicKey, 0, len(config.PubKeys))
+	for i, encoded := range config.PubKeys {
+		pubKey, err := das.DecodeBase64BLSPublicKey([]byte(encoded))
+		if err != nil {
+			return fmt.Errorf("invalid public key %d: %w", i, err)
+		}
+		pubKeys = append(pubKeys, *pubKey)
+	}
+	keyset, err := das.NewKeyset(config.AssumedHonest, pubKeys)
+	if err != nil {
+		return err
+	}
+	keysetBytes, keysetHash, err := das.SerializeKeysetWithHash(keyset)
+	if err != nil {
+		return err
+	}
+	calldata, err := das.SetValidKeysetCalldata(keysetBytes)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Keyset: %s\n", hexutil.Encode(keysetBytes))
+	fmt.Printf("KeysetHash: %s\n", hexutil.Encode(keysetHash[:]))
+	fmt.Printf("SetValidKeysetCalldata: %s\n", hexutil.Encode(calldata))
+	if config.PreviousKeysetHash != "" {
+		previousHash, err := hexutil.Decode(config.PreviousKeysetHash)
+		if err != nil || len(previousHash) != common.HashLength {
+			return fmt.Errorf("invalid previous keyset hash %s", config.PreviousKeysetHash)
+		}
+		calldata, err := das.InvalidateKeysetHashCalldata(common.BytesToHash(previousHash))
+		if err != nil {
+			return err
+		}
+		fmt.Printf("InvalidateKeysetHashCalldata: %s\n", hexutil.Encode(calldata))
+	}
+	return nil
+}
+
+// datool verifycert
+
+type VerifyCertConfig struct {
+	Keyset      string `koanf:"keyset"`
+	Certificate string `koanf:"certificate"`
+}
+
+func parseVerifyCertConfig(args []string) (*VerifyCertConfig, error) {
+	f := flag.NewFlagSet("datool verifycert", flag.ContinueOnError)
+	f.String("keyset", "", "hex encoded serialized keyset, as output by dumpkeyset or rotatekeyset")
+	f.String("certificate", "", "hex encoded DAS certificate, including the header byte")
+
+	k, err := confighelpers.BeginCommonParse(f, args)
+	if err != nil {
+		return nil, err
+	}
+
+	var config VerifyCertConfig
+	if err := confighelpers.EndCommonParse(k, &config); err != nil {
+		return nil, err
+	}
+	if config.Keyset == "" || config.Certificate == "" {
+		return nil, errors.New("--keyset and --certificate must be set")
+	}
+	return &config, nil
+}
+
+func verifyCert(args []string) error {
+	config, err := parseVerifyCertConfig(args)
+	if err != nil {
+		return err
+	}
+
+	keysetBytes, err := hexutil.Decode(config.Keyset)
+	if err != nil {
+		return fmt.Errorf("invalid keyset: %w", err)
+	}
+	keyset, err := daprovider.DeserializeKeyset(bytes.NewReader(keysetBytes), false)
+	if err != nil {
+		return fmt.Errorf("invalid keyset: %w", err)
+	}
+	certBytes, err := hexutil.Decode(config.Certificate)
+	if err != nil {
+		return fmt.Errorf("invalid certificate: %w", err)
+	}
+	cert, err := daprovider.DeserializeDASCertFrom(bytes.NewReader(certBytes))
+	if err != nil {
+		return fmt.Errorf("invalid certificate: %w", err)
+	}
+	if err := das.VerifyCertificateWithKeyset(cert, keyset); err != nil {
+		return err
+	}
+	fmt.Printf("Certificate for data hash %s is signed by a quorum of keyset %s\n", hexutil.Encode(cert.DataHash[:]), hexutil.Encode(cert.KeysetHash[:]))
+	return nil
+}
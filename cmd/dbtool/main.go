@@ -0,0 +1,559 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+// dbtool inspects a stopped node's databases.
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/node"
+
+	"github.com/offchainlabs/nitro/arbnode"
+	"github.com/offchainlabs/nitro/arbos/arbosState"
+	"github.com/offchainlabs/nitro/cmd/conf"
+	"github.com/offchainlabs/nitro/cmd/genericconf"
+	"github.com/offchainlabs/nitro/cmd/util/confighelpers"
+	"github.com/offchainlabs/nitro/execution/gethexec"
+)
+
+func main() {
+	args := os.Args
+	if len(args) < 2 {
+		fmt.Fprintln(os.Stderr, "Usage: dbtool [arbos-diff|verify-inbox|export-blocks|import-blocks] ...")
+		os.Exit(1)
+	}
+	var err error
+	switch strings.ToLower(args[1]) {
+	case "arbos-diff":
+		err = arbosDiff(args[2:])
+	case "verify-inbox":
+		err = verifyInbox(args[2:])
+	case "export-blocks":
+		err = exportBlocks(args[2:])
+	case "import-blocks":
+		err = importBlocks(args[2:])
+	default:
+		err = fmt.Errorf("unknown tool '%s' specified, valid tools are 'arbos-diff', 'verify-inbox', 'export-blocks', 'import-blocks'", args[1])
+	}
+	if err != nil {
+		log.Error("dbtool failed", "err", err)
+		os.Exit(1)
+	}
+}
+
+// dbtool arbos-diff ...
+
+type ArbosDiffConfig struct {
+	Persistent conf.PersistentConfig `koanf:"persistent"`
+	From       uint64                `koanf:"from"`
+	To         uint64                `koanf:"to"`
+	Output     string                `koanf:"output"`
+	LogLevel   string                `koanf:"log-level"`
+	LogType    string                `koanf:"log-type"`
+}
+
+var DefaultArbosDiffConfig = ArbosDiffConfig{
+	Persistent: conf.PersistentConfigDefault,
+	From:       0,
+	To:         0,
+	Output:     "",
+	LogLevel:   "INFO",
+	LogType:    "plaintext",
+}
+
+func ArbosDiffConfigAddOptions(f *flag.FlagSet) {
+	conf.PersistentConfigAddOptions("persistent", f)
+	f.Uint64("from", DefaultArbosDiffConfig.From, "number of the block whose state is compared from")
+	f.Uint64("to", DefaultArbosDiffConfig.To, "number of the block whose state is compared to")
+	f.String("output", DefaultArbosDiffConfig.Output, "file the JSON diff is written to (stdout if empty)")
+	f.String("log-level", DefaultArbosDiffConfig.LogLevel, "log level, valid values are CRIT, ERROR, WARN, INFO, DEBUG, TRACE")
+	f.String("log-type", DefaultArbosDiffConfig.LogType, "log type (plaintext or json)")
+}
+
+func (c *ArbosDiffConfig) Validate() error {
+	if c.From == c.To {
+		return errors.New("--from and --to must be different blocks")
+	}
+	return c.Persistent.Validate()
+}
+
+func parseArbosDiff(args []string) (*ArbosDiffConfig, error) {
+	f := flag.NewFlagSet("arbos-diff", flag.ContinueOnError)
+	ArbosDiffConfigAddOptions(f)
+	k, err := confighelpers.BeginCommonParse(f, args)
+	if err != nil {
+		return nil, err
+	}
+	var config ArbosDiffConfig
+	if err := confighelpers.EndCommonParse(k, &config); err != nil {
+		return nil, err
+	}
+	if err := config.Persistent.ResolveDirectoryNames(); err != nil {
+		return nil, err
+	}
+	return &config, config.Validate()
+}
+
+func printArbosDiffUsage(name string) {
+	fmt.Printf("Sample usage: %s arbos-diff --persistent.chain=<archive node chain directory> --from=<block number> --to=<block number>\n\n", name)
+}
+
+// arbosDiff compares ArbOS's state at two blocks of an archive node's database.
+func arbosDiff(args []string) error {
+	config, err := parseArbosDiff(args)
+	if err != nil {
+		confighelpers.PrintErrorAndExit(err, printArbosDiffUsage)
+	}
+	err = genericconf.InitLog(config.LogType, config.LogLevel, &genericconf.FileLoggingConfig{Enable: false}, nil)
+	if err != nil {
+		return fmt.Errorf("initializing logging: %w", err)
+	}
+
+	stackConf := node.DefaultConfig
+	stackConf.Name = "nitro"
+	stackConf.DataDir = config.Persistent.Chain
+	stackConf.DBEngine = config.Persistent.DBEngine
+	stackConf.P2P.ListenAddr = ""
+	stackConf.P2P.NoDial = true
+	stackConf.P2P.NoDiscovery = true
+	stack, err := node.New(&stackConf)
+	if err != nil {
+		return err
+	}
+	defer stack.Close()
+
+	chainDb, err := stack.OpenDatabaseWithFreezerWithExtraOptions("l2chaindata", 0, config.Persistent.Handles, config.Persistent.Ancient, "l2chaindata/", true, config.Persistent.Pebble.ExtraOptions("l2chaindata"))
+	if err != nil {
+		return err
+	}
+	defer chainDb.Close()
+	if gethexec.TryReadStoredChainConfig(chainDb) == nil {
+		return fmt.Errorf("no nitro database found in %v", stack.InstanceDir())
+	}
+	stateScheme, err := rawdb.ParseStateScheme("", chainDb)
+	if err != nil {
+		return err
+	}
+	cachingConfig := gethexec.DefaultCachingConfig
+	cachingConfig.StateScheme = stateScheme
+	cacheConfig := gethexec.DefaultCacheConfigFor(stack, &cachingConfig)
+	stateDatabase := state.NewDatabaseWithConfig(chainDb, cacheConfig.TriedbConfig())
+	defer stateDatabase.TrieDB().Close()
+
+	fromRoot, err := blockRoot(chainDb, config.From)
+	if err != nil {
+		return err
+	}
+	toRoot, err := blockRoot(chainDb, config.To)
+	if err != nil {
+		return err
+	}
+	diff, err := arbosState.DiffStates(stateDatabase, fromRoot, toRoot)
+	if err != nil {
+		return fmt.Errorf("%w; comparing blocks before the latest needs an archive node's database", err)
+	}
+	log.Info("compared ArbOS state", "from", config.From, "to", config.To, "changes", len(diff.Changes), "ownersAdded", len(diff.OwnersAdded), "ownersRemoved", len(diff.OwnersRemoved))
+
+	encoded, err := json.MarshalIndent(diff, "", "  ")
+	if err != nil {
+		return err
+	}
+	encoded = append(encoded, '\n')
+	if config.Output == "" {
+		_, err = os.Stdout.Write(encoded)
+		return err
+	}
+	// #nosec G306
+	return os.WriteFile(config.Output, encoded, 0o644)
+}
+
+func blockRoot(chainDb ethdb.Database, number uint64) (common.Hash, error) {
+	hash := rawdb.ReadCanonicalHash(chainDb, number)
+	header := rawdb.ReadHeader(chainDb, hash, number)
+	if header == nil {
+		return common.Hash{}, fmt.Errorf("block %v not found", number)
+	}
+	return header.Root, nil
+}
+
+// dbtool verify-inbox ...
+
+type VerifyInboxConfig struct {
+	Persistent            conf.PersistentConfig `koanf:"persistent"`
+	ParentChainUrl        string                `koanf:"parent-chain-url"`
+	BridgeAddress         string                `koanf:"bridge-address"`
+	SequencerInboxAddress string                `koanf:"sequencer-inbox-address"`
+	LogLevel              string                `koanf:"log-level"`
+	LogType               string                `koanf:"log-type"`
+}
+
+var DefaultVerifyInboxConfig = VerifyInboxConfig{
+	Persistent:            conf.PersistentConfigDefault,
+	ParentChainUrl:        "",
+	BridgeAddress:         "",
+	SequencerInboxAddress: "",
+	LogLevel:              "INFO",
+	LogType:               "plaintext",
+}
+
+func VerifyInboxConfigAddOptions(f *flag.FlagSet) {
+	conf.PersistentConfigAddOptions("persistent", f)
+	f.String("parent-chain-url", DefaultVerifyInboxConfig.ParentChainUrl, "parent chain RPC url, to check the stored accumulators against the L1 contracts (only the database is checked if empty)")
+	f.String("bridge-address", DefaultVerifyInboxConfig.BridgeAddress, "address of the chain's bridge contract (required with parent-chain-url)")
+	f.String("sequencer-inbox-address", DefaultVerifyInboxConfig.SequencerInboxAddress, "address of the chain's sequencer inbox contract (required with parent-chain-url)")
+	f.String("log-level", DefaultVerifyInboxConfig.LogLevel, "log level, valid values are CRIT, ERROR, WARN, INFO, DEBUG, TRACE")
+	f.String("log-type", DefaultVerifyInboxConfig.LogType, "log type (plaintext or json)")
+}
+
+func (c *VerifyInboxConfig) Validate() error {
+	if c.ParentChainUrl != "" && (!common.IsHexAddress(c.BridgeAddress) || !common.IsHexAddress(c.SequencerInboxAddress)) {
+		return errors.New("checking against the parent chain requires bridge-address and sequencer-inbox-address")
+	}
+	return c.Persistent.Validate()
+}
+
+func parseVerifyInbox(args []string) (*VerifyInboxConfig, error) {
+	f := flag.NewFlagSet("verify-inbox", flag.ContinueOnError)
+	VerifyInboxConfigAddOptions(f)
+	k, err := confighelpers.BeginCommonParse(f, args)
+	if err != nil {
+		return nil, err
+	}
+	var config VerifyInboxConfig
+	if err := confighelpers.EndCommonParse(k, &config); err != nil {
+		return nil, err
+	}
+	if err := config.Persistent.ResolveDirectoryNames(); err != nil {
+		return nil, err
+	}
+	return &config, config.Validate()
+}
+
+func printVerifyInboxUsage(name string) {
+	fmt.Printf("Sample usage: %s verify-inbox --persistent.chain=<chain directory> [--parent-chain-url=<url> --bridge-address=<address> --sequencer-inbox-address=<address>]\n\n", name)
+}
+
+// verifyInbox checks a stopped node's inbox tracker database, and prints the first delayed message or batch at which
+// it diverges from itself or from the L1 contracts.
+func verifyInbox(args []string) error {
+	config, err := parseVerifyInbox(args)
+	if err != nil {
+		confighelpers.PrintErrorAndExit(err, printVerifyInboxUsage)
+	}
+	err = genericconf.InitLog(config.LogType, config.LogLevel, &genericconf.FileLoggingConfig{Enable: false}, nil)
+	if err != nil {
+		return fmt.Errorf("initializing logging: %w", err)
+	}
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	stackConf := node.DefaultConfig
+	stackConf.Name = "nitro"
+	stackConf.DataDir = config.Persistent.Chain
+	stackConf.DBEngine = config.Persistent.DBEngine
+	stackConf.P2P.ListenAddr = ""
+	stackConf.P2P.NoDial = true
+	stackConf.P2P.NoDiscovery = true
+	stack, err := node.New(&stackConf)
+	if err != nil {
+		return err
+	}
+	defer stack.Close()
+
+	arbDb, err := stack.OpenDatabaseWithExtraOptions("arbitrumdata", 0, 0, "arbitrumdata/", true, config.Persistent.Pebble.ExtraOptions("arbitrumdata"))
+	if err != nil {
+		return err
+	}
+	defer arbDb.Close()
+	tracker, err := arbnode.NewInboxTracker(arbDb, nil, nil, nil, arbnode.DefaultSnapSyncConfig)
+	if err != nil {
+		return err
+	}
+
+	var delayedBridge *arbnode.DelayedBridge
+	var sequencerInbox *arbnode.SequencerInbox
+	if config.ParentChainUrl != "" {
+		client, err := ethclient.DialContext(ctx, config.ParentChainUrl)
+		if err != nil {
+			return fmt.Errorf("failed to connect to the parent chain: %w", err)
+		}
+		defer client.Close()
+		delayedBridge, err = arbnode.NewDelayedBridge(client, common.HexToAddress(config.BridgeAddress), 0)
+		if err != nil {
+			return err
+		}
+		sequencerInbox, err = arbnode.NewSequencerInbox(client, common.HexToAddress(config.SequencerInboxAddress), 0)
+		if err != nil {
+			return err
+		}
+	}
+
+	result, err := tracker.VerifyInbox(ctx, delayedBridge, sequencerInbox)
+	if err != nil {
+		return err
+	}
+	if result.Divergence != nil {
+		fmt.Printf("inbox diverges at %v\n", result.Divergence)
+		return errors.New("inbox verification failed")
+	}
+	log.Info("inbox verified", "delayedMessages", result.DelayedCount, "batches", result.BatchCount, "messages", result.MessageCount, "checkedL1", result.CheckedL1)
+	return nil
+}
+
+// dbtool export-blocks ...
+
+type ExportBlocksConfig struct {
+	Persistent    conf.PersistentConfig `koanf:"persistent"`
+	From          uint64                `koanf:"from"`
+	To            uint64                `koanf:"to"`
+	BlocksPerFile uint64                `koanf:"blocks-per-file"`
+	OutputDir     string                `koanf:"output-dir"`
+	LogLevel      string                `koanf:"log-level"`
+	LogType       string                `koanf:"log-type"`
+}
+
+var DefaultExportBlocksConfig = ExportBlocksConfig{
+	Persistent:    conf.PersistentConfigDefault,
+	From:          0,
+	To:            0,
+	BlocksPerFile: 8192,
+	OutputDir:     "",
+	LogLevel:      "INFO",
+	LogType:       "plaintext",
+}
+
+func ExportBlocksConfigAddOptions(f *flag.FlagSet) {
+	conf.PersistentConfigAddOptions("persistent", f)
+	f.Uint64("from", DefaultExportBlocksConfig.From, "number of the first block to export (the genesis block if lower)")
+	f.Uint64("to", DefaultExportBlocksConfig.To, "number of the last block to export")
+	f.Uint64("blocks-per-file", DefaultExportBlocksConfig.BlocksPerFile, "number of blocks in each file")
+	f.String("output-dir", DefaultExportBlocksConfig.OutputDir, "directory the files are written to")
+	f.String("log-level", DefaultExportBlocksConfig.LogLevel, "log level, valid values are CRIT, ERROR, WARN, INFO, DEBUG, TRACE")
+	f.String("log-type", DefaultExportBlocksConfig.LogType, "log type (plaintext or json)")
+}
+
+func (c *ExportBlocksConfig) Validate() error {
+	if c.From > c.To {
+		return errors.New("--from must not be after --to")
+	}
+	if c.BlocksPerFile == 0 {
+		return errors.New("--blocks-per-file must be positive")
+	}
+	if c.OutputDir == "" {
+		return errors.New("--output-dir is required")
+	}
+	return c.Persistent.Validate()
+}
+
+func parseExportBlocks(args []string) (*ExportBlocksConfig, error) {
+	f := flag.NewFlagSet("export-blocks", flag.ContinueOnError)
+	ExportBlocksConfigAddOptions(f)
+	k, err := confighelpers.BeginCommonParse(f, args)
+	if err != nil {
+		return nil, err
+	}
+	var config ExportBlocksConfig
+	if err := confighelpers.EndCommonParse(k, &config); err != nil {
+		return nil, err
+	}
+	if err := config.Persistent.ResolveDirectoryNames(); err != nil {
+		return nil, err
+	}
+	return &config, config.Validate()
+}
+
+func printExportBlocksUsage(name string) {
+	fmt.Printf("Sample usage: %s export-blocks --persistent.chain=<chain directory> --from=<block number> --to=<block number> --output-dir=<directory>\n\n", name)
+}
+
+// openNodeDatabases opens a stopped node's chain and arbitrum databases, and reads its genesis block number.
+func openNodeDatabases(persistent *conf.PersistentConfig, readonly bool) (*node.Node, ethdb.Database, ethdb.Database, uint64, error) {
+	stackConf := node.DefaultConfig
+	stackConf.Name = "nitro"
+	stackConf.DataDir = persistent.Chain
+	stackConf.DBEngine = persistent.DBEngine
+	stackConf.P2P.ListenAddr = ""
+	stackConf.P2P.NoDial = true
+	stackConf.P2P.NoDiscovery = true
+	stack, err := node.New(&stackConf)
+	if err != nil {
+		return nil, nil, nil, 0, err
+	}
+	chainDb, err := stack.OpenDatabaseWithFreezerWithExtraOptions("l2chaindata", 0, persistent.Handles, persistent.Ancient, "l2chaindata/", readonly, persistent.Pebble.ExtraOptions("l2chaindata"))
+	if err != nil {
+		stack.Close()
+		return nil, nil, nil, 0, err
+	}
+	chainConfig := gethexec.TryReadStoredChainConfig(chainDb)
+	if chainConfig == nil {
+		stack.Close()
+		return nil, nil, nil, 0, fmt.Errorf("no nitro database found in %v", stack.InstanceDir())
+	}
+	arbDb, err := stack.OpenDatabaseWithExtraOptions("arbitrumdata", 0, 0, "arbitrumdata/", readonly, persistent.Pebble.ExtraOptions("arbitrumdata"))
+	if err != nil {
+		stack.Close()
+		return nil, nil, nil, 0, err
+	}
+	return stack, chainDb, arbDb, chainConfig.ArbitrumChainParams.GenesisBlockNum, nil
+}
+
+// exportBlocks writes the headers of a range of blocks of a stopped node's database, with their inbox messages, to
+// files of a fixed number of blocks each, from which other nodes can be bootstrapped with import-blocks.
+func exportBlocks(args []string) error {
+	config, err := parseExportBlocks(args)
+	if err != nil {
+		confighelpers.PrintErrorAndExit(err, printExportBlocksUsage)
+	}
+	err = genericconf.InitLog(config.LogType, config.LogLevel, &genericconf.FileLoggingConfig{Enable: false}, nil)
+	if err != nil {
+		return fmt.Errorf("initializing logging: %w", err)
+	}
+	stack, chainDb, arbDb, genesis, err := openNodeDatabases(&config.Persistent, true)
+	if err != nil {
+		return err
+	}
+	defer stack.Close()
+	if err := os.MkdirAll(config.OutputDir, os.ModePerm); err != nil {
+		return err
+	}
+
+	for first := max(config.From, genesis); first <= config.To; first += config.BlocksPerFile {
+		last := min(first+config.BlocksPerFile-1, config.To)
+		path := filepath.Join(config.OutputDir, fmt.Sprintf("blocks-%020d-%020d.arbexport", first, last))
+		err := func() error {
+			file, err := os.Create(path)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			writer := bufio.NewWriter(file)
+			if err := arbnode.ExportBlocks(chainDb, arbDb, genesis, first, last, writer); err != nil {
+				return err
+			}
+			if err := writer.Flush(); err != nil {
+				return err
+			}
+			return file.Sync()
+		}()
+		if err != nil {
+			return fmt.Errorf("exporting blocks %v to %v: %w", first, last, err)
+		}
+		log.Info("exported blocks", "first", first, "last", last, "file", path)
+	}
+	return nil
+}
+
+// dbtool import-blocks ...
+
+type ImportBlocksConfig struct {
+	Persistent    conf.PersistentConfig `koanf:"persistent"`
+	Files         []string              `koanf:"files"`
+	LastBlockHash string                `koanf:"last-block-hash"`
+	LogLevel      string                `koanf:"log-level"`
+	LogType       string                `koanf:"log-type"`
+}
+
+var DefaultImportBlocksConfig = ImportBlocksConfig{
+	Persistent:    conf.PersistentConfigDefault,
+	Files:         []string{},
+	LastBlockHash: "",
+	LogLevel:      "INFO",
+	LogType:       "plaintext",
+}
+
+func ImportBlocksConfigAddOptions(f *flag.FlagSet) {
+	conf.PersistentConfigAddOptions("persistent", f)
+	f.StringSlice("files", DefaultImportBlocksConfig.Files, "files written by export-blocks, imported in the order given")
+	f.String("last-block-hash", DefaultImportBlocksConfig.LastBlockHash, "trusted hash of the last block of the last file, such as that of an assertion confirmed on the parent chain, which authenticates the files")
+	f.String("log-level", DefaultImportBlocksConfig.LogLevel, "log level, valid values are CRIT, ERROR, WARN, INFO, DEBUG, TRACE")
+	f.String("log-type", DefaultImportBlocksConfig.LogType, "log type (plaintext or json)")
+}
+
+func (c *ImportBlocksConfig) Validate() error {
+	if len(c.Files) == 0 {
+		return errors.New("--files is required")
+	}
+	if common.HexToHash(c.LastBlockHash) == (common.Hash{}) {
+		return errors.New("--last-block-hash is required")
+	}
+	return c.Persistent.Validate()
+}
+
+func parseImportBlocks(args []string) (*ImportBlocksConfig, error) {
+	f := flag.NewFlagSet("import-blocks", flag.ContinueOnError)
+	ImportBlocksConfigAddOptions(f)
+	k, err := confighelpers.BeginCommonParse(f, args)
+	if err != nil {
+		return nil, err
+	}
+	var config ImportBlocksConfig
+	if err := confighelpers.EndCommonParse(k, &config); err != nil {
+		return nil, err
+	}
+	if err := config.Persistent.ResolveDirectoryNames(); err != nil {
+		return nil, err
+	}
+	return &config, config.Validate()
+}
+
+func printImportBlocksUsage(name string) {
+	fmt.Printf("Sample usage: %s import-blocks --persistent.chain=<chain directory of an initialized node> --files=<file>,<file>,... --last-block-hash=<hash>\n\n", name)
+}
+
+// importBlocks authenticates files written by export-blocks against the trusted hash of their last block, and adds
+// the messages of their blocks to a stopped node's database. The node produces the blocks when next started, checking that they match the exported ones, so that
+// an archive node started with the same chain re-creates the state of every block.
+func importBlocks(args []string) error {
+	config, err := parseImportBlocks(args)
+	if err != nil {
+		confighelpers.PrintErrorAndExit(err, printImportBlocksUsage)
+	}
+	err = genericconf.InitLog(config.LogType, config.LogLevel, &genericconf.FileLoggingConfig{Enable: false}, nil)
+	if err != nil {
+		return fmt.Errorf("initializing logging: %w", err)
+	}
+	stack, chainDb, arbDb, genesis, err := openNodeDatabases(&config.Persistent, false)
+	if err != nil {
+		return err
+	}
+	defer stack.Close()
+
+	exports := make([]arbnode.BlockExportOpener, len(config.Files))
+	for i, path := range config.Files {
+		exports[i] = func() (io.ReadCloser, error) {
+			file, err := os.Open(path)
+			if err != nil {
+				return nil, err
+			}
+			return struct {
+				io.Reader
+				io.Closer
+			}{bufio.NewReader(file), file}, nil
+		}
+	}
+	added, err := arbnode.ImportBlocks(chainDb, arbDb, genesis, exports, common.HexToHash(config.LastBlockHash))
+	if err != nil {
+		return fmt.Errorf("importing %v: %w", config.Files, err)
+	}
+	log.Info("imported blocks", "files", len(config.Files), "messagesAdded", added)
+	return nil
+}
@@ -170,6 +170,9 @@ func main() {
 	if err != nil {
 		panic(fmt.Errorf("failed to deserialize chain config: %w", err))
 	}
+	if err := chaininfo.ValidateChainConfig(&chainConfig); err != nil {
+		panic(fmt.Errorf("invalid chain config: %w", err))
+	}
 
 	arbSys, _ := precompilesgen.NewArbSys(types.ArbSysAddress, l1client)
 	l1Reader, err := headerreader.New(ctx, l1client, func() *headerreader.Config { return &headerReaderConfig }, arbSys)
@@ -214,6 +217,9 @@ func main() {
 			RollupAddresses:       deployedAddresses,
 		},
 	}
+	if err := chainsInfo[0].Validate(); err != nil {
+		panic(fmt.Errorf("invalid chain info: %w", err))
+	}
 	chainsInfoJson, err := json.Marshal(chainsInfo)
 	if err != nil {
 		panic(err)
@@ -2,6 +2,7 @@ package genericconf
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"os/signal"
 	"sync"
@@ -29,6 +30,7 @@ type LiveConfig[T ConfigConstrain[T]] struct {
 	stopwaiter.StopWaiter
 
 	mutex        sync.RWMutex
+	reloadMutex  sync.Mutex // serializes reloads
 	args         []string
 	config       T
 	onReloadHook OnReloadHook[T]
@@ -56,43 +58,49 @@ func (c *LiveConfig[T]) Set(config T) error {
 	return nil
 }
 
+// Reload re-parses the configuration and applies it, if only hot reloadable settings changed.
+func (c *LiveConfig[T]) Reload(ctx context.Context) error {
+	c.reloadMutex.Lock()
+	defer c.reloadMutex.Unlock()
+	config, err := c.parse(ctx, c.args)
+	if err != nil {
+		return fmt.Errorf("error parsing live config: %w", err)
+	}
+	if err := c.Set(config); err != nil {
+		return fmt.Errorf("error updating live config: %w", err)
+	}
+	return nil
+}
+
 func (c *LiveConfig[T]) Start(ctxIn context.Context) {
 	c.StopWaiter.Start(ctxIn, c)
 
-	sigusr1 := make(chan os.Signal, 1)
-	signal.Notify(sigusr1, syscall.SIGUSR1)
+	reloadSignal := make(chan os.Signal, 1)
+	signal.Notify(reloadSignal, syscall.SIGUSR1, syscall.SIGHUP)
 
 	c.LaunchThread(func(ctx context.Context) {
+		defer signal.Stop(reloadSignal)
 		for {
-			reloadInterval := c.config.GetReloadInterval()
-			if reloadInterval == 0 {
-				select {
-				case <-ctx.Done():
-					return
-				case <-sigusr1:
-					log.Info("Configuration reload triggered by SIGUSR1.")
-				}
-			} else {
-				timer := time.NewTimer(reloadInterval)
-				select {
-				case <-ctx.Done():
-					timer.Stop()
-					return
-				case <-sigusr1:
-					timer.Stop()
-					log.Info("Configuration reload triggered by SIGUSR1.")
-				case <-timer.C:
-				}
+			var timer *time.Timer
+			var timerChan <-chan time.Time
+			if reloadInterval := c.Get().GetReloadInterval(); reloadInterval != 0 {
+				timer = time.NewTimer(reloadInterval)
+				timerChan = timer.C
+			}
+			select {
+			case <-ctx.Done():
+			case sig := <-reloadSignal:
+				log.Info("Configuration reload triggered by signal.", "signal", sig)
+			case <-timerChan:
+			}
+			if timer != nil {
+				timer.Stop()
 			}
-			nodeConfig, err := c.parse(ctx, c.args)
-			if err != nil {
-				log.Error("error parsing live config", "error", err.Error())
-				continue
+			if ctx.Err() != nil {
+				return
 			}
-			err = c.Set(nodeConfig)
-			if err != nil {
-				log.Error("error updating live config", "error", err.Error())
-				continue
+			if err := c.Reload(ctx); err != nil {
+				log.Error("error reloading live config", "err", err)
 			}
 		}
 	})
@@ -145,7 +145,7 @@ func (c *ValidationNodeConfig) GetReloadInterval() time.Duration {
 
 func (c *ValidationNodeConfig) Validate() error {
 	// TODO
-	return nil
+	return c.Validation.Wasm.Registry.Validate()
 }
 
 var DefaultValidationNodeStackConfig = node.Config{
@@ -0,0 +1,21 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package main
+
+import (
+	"context"
+
+	"github.com/offchainlabs/nitro/cmd/genericconf"
+)
+
+// ConfigAPI is served in the admin namespace, next to geth's admin API.
+type ConfigAPI struct {
+	config *genericconf.LiveConfig[*NodeConfig]
+}
+
+// ReloadConfig re-reads the node's configuration, like SIGHUP does. It fails if a setting that can't be changed at
+// runtime was changed.
+func (a *ConfigAPI) ReloadConfig(ctx context.Context) error {
+	return a.config.Reload(ctx)
+}
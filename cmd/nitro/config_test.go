@@ -78,6 +78,28 @@ func TestInvalidArchiveConfig(t *testing.T) {
 	}
 }
 
+const replicaArgs = "--persistent.chain /tmp/data --node.parent-chain-reader.enable=false --parent-chain.id 5 --chain.id 421613 --http.addr 0.0.0.0 --execution.replica.enable"
+
+func TestReplicaConfig(t *testing.T) {
+	feedArgs := replicaArgs + " --node.feed.input.url ws://sequencer:9642"
+	_, _, err := ParseNode(context.Background(), strings.Split(feedArgs, " "))
+	Require(t, err)
+
+	for _, args := range []string{
+		feedArgs + " --node.batch-poster.enable",
+		feedArgs + " --node.staker.strategy MakeNodes",
+		feedArgs + " --node.staker.parent-chain-wallet.private-key 0x01",
+		feedArgs + " --node.batch-poster.parent-chain-wallet.password passphrase",
+		feedArgs + " --node.staker.parent-chain-wallet.pathname /l1keystore",
+		feedArgs + " --node.force-inclusion.parent-chain-wallet.account 0x0000000000000000000000000000000000000001",
+		replicaArgs,
+	} {
+		if _, _, err := ParseNode(context.Background(), strings.Split(args, " ")); err == nil || !strings.Contains(err.Error(), "execution.replica.enable") {
+			Fail(t, "replica config", args, "wasn't rejected, got", err)
+		}
+	}
+}
+
 func TestAggregatorConfig(t *testing.T) {
 	args := strings.Split("--persistent.chain /tmp/data --init.dev-init --node.parent-chain-reader.enable=false --parent-chain.id 5 --chain.id 421613 --node.batch-poster.parent-chain-wallet.pathname /l1keystore --node.batch-poster.parent-chain-wallet.password passphrase --http.addr 0.0.0.0 --ws.addr 0.0.0.0 --node.sequencer --execution.sequencer.enable --node.feed.output.enable --node.feed.output.port 9642 --node.data-availability.enable --node.data-availability.rpc-aggregator.backends [{\"url\":\"http://localhost:8547\",\"pubkey\":\"abc==\"}]", " ")
 	_, _, err := ParseNode(context.Background(), args)
@@ -116,6 +138,16 @@ func TestReloads(t *testing.T) {
 	Require(t, config.CanReload(&config))
 	Require(t, config.CanReload(&update))
 
+	// operational settings can be reloaded
+	update = NodeConfigDefault
+	update.Node.Feed.Input.URL = []string{"ws://127.0.0.1:9642"}
+	update.Node.Feed.Input.SecondaryURL = []string{"ws://127.0.0.1:9643"}
+	update.Execution.RPCPolicy.MethodLimits = []string{"eth_call:10"}
+	update.Execution.RPCPolicy.SenderTxLimit = 1
+	update.Node.BatchPoster.DataPoster.MaxFeeCapFormula = "1"
+	Require(t, config.CanReload(&update))
+	update = NodeConfigDefault
+
 	testUnsafe := func() {
 		t.Helper()
 		if config.CanReload(&update) == nil {
@@ -131,6 +163,8 @@ func TestReloads(t *testing.T) {
 	testUnsafe()
 	update.Node.Staker.Enable = !update.Node.Staker.Enable
 	testUnsafe()
+	update.Execution.RPCPolicy.Enable = !update.Execution.RPCPolicy.Enable
+	testUnsafe()
 }
 
 func TestLiveNodeConfig(t *testing.T) {
@@ -202,6 +236,15 @@ func TestLiveNodeConfig(t *testing.T) {
 		Fail(t, "failed to update config", config.Node.BatchPoster.MaxSize, update.Node.BatchPoster.MaxSize)
 	}
 
+	// SIGHUP also triggers a LiveConfig reload
+	expected.Node.BatchPoster.MaxSize += 100
+	jsonConfig = fmt.Sprintf("{\"node\":{\"batch-poster\":{\"max-size\":\"%d\"}}, \"chain\":{\"id\":421613}}", expected.Node.BatchPoster.MaxSize)
+	Require(t, WriteToConfigFile(configFile, jsonConfig))
+	Require(t, syscall.Kill(syscall.Getpid(), syscall.SIGHUP))
+	if !PollLiveConfigUntilEqual(liveConfig, expected) {
+		Fail(t, "failed to update config on SIGHUP", expected.Node.BatchPoster.MaxSize)
+	}
+
 	// change chain.id in the config file (currently non-reloadable)
 	jsonConfig = fmt.Sprintf("{\"node\":{\"batch-poster\":{\"max-size\":\"%d\"}}, \"chain\":{\"id\":421703}}", expected.Node.BatchPoster.MaxSize)
 	Require(t, WriteToConfigFile(configFile, jsonConfig))
@@ -212,6 +255,9 @@ func TestLiveNodeConfig(t *testing.T) {
 	if PollLiveConfigUntilNotEqual(liveConfig, expected) {
 		Fail(t, "failed to reject invalid update")
 	}
+	if (&ConfigAPI{liveConfig}).ReloadConfig(ctx) == nil {
+		Fail(t, "reload through the api should fail on an invalid update")
+	}
 }
 
 func TestPeriodicReloadOfLiveNodeConfig(t *testing.T) {
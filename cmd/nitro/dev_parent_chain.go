@@ -0,0 +1,246 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth"
+	"github.com/ethereum/go-ethereum/eth/catalyst"
+	"github.com/ethereum/go-ethereum/eth/downloader"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/offchainlabs/nitro/arbnode"
+	"github.com/offchainlabs/nitro/cmd/chaininfo"
+	"github.com/offchainlabs/nitro/deploy"
+	"github.com/offchainlabs/nitro/solgen/go/precompilesgen"
+	"github.com/offchainlabs/nitro/util/headerreader"
+)
+
+// DevParentChainConfig configures the parent chain that dev nodes simulate in their own process.
+type DevParentChainConfig struct {
+	Enable    bool   `koanf:"enable"`
+	BlockTime uint64 `koanf:"block-time"`
+}
+
+var DevParentChainConfigDefault = DevParentChainConfig{
+	Enable:    false,
+	BlockTime: 0,
+}
+
+func DevParentChainConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.Bool(prefix+".enable", DevParentChainConfigDefault.Enable, "DANGEROUS! simulate the parent chain in this process, deploy the rollup to it, and post batches to it. To be used in dev nodes only")
+	f.Uint64(prefix+".block-time", DevParentChainConfigDefault.BlockTime, "seconds between the simulated parent chain's blocks (0 = a block for every transaction)")
+}
+
+const (
+	devParentChainDir            = "dev-parent-chain"
+	devParentChainDeploymentFile = "deployment.json"
+	devParentChainGasLimit       = 30_000_000
+	devMaxDataSize               = 117964
+)
+
+// dev chains aren't validated, so the rollup only needs some module root
+var devWasmModuleRoot = common.HexToHash("0x01")
+
+// devParentChainDeployment is what a dev node keeps of its simulated parent chain between runs, next to the
+// parent chain's database.
+type devParentChainDeployment struct {
+	Key       string                     `json:"key"`
+	Addresses *chaininfo.RollupAddresses `json:"addresses"`
+}
+
+// devParentChain is an auto-mined parent chain, run in this process, with the rollup deployed to it. Every block
+// is final as soon as it's mined.
+type devParentChain struct {
+	stack     *node.Node
+	client    *ethclient.Client
+	key       *ecdsa.PrivateKey
+	addresses *chaininfo.RollupAddresses
+}
+
+func readDevParentChainDeployment(path string) (*devParentChainDeployment, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var deployment devParentChainDeployment
+	if err := json.Unmarshal(data, &deployment); err != nil {
+		return nil, fmt.Errorf("failed to parse %v: %w", path, err)
+	}
+	return &deployment, nil
+}
+
+// startDevParentChain starts the simulated parent chain in dataDir, and deploys the rollup of chainConfig to it
+// unless an earlier run already did.
+func startDevParentChain(ctx context.Context, dataDir string, parentChainId uint64, chainConfig *params.ChainConfig, blockTime uint64) (*devParentChain, error) {
+	dir := filepath.Join(dataDir, devParentChainDir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	deploymentPath := filepath.Join(dir, devParentChainDeploymentFile)
+	deployment, err := readDevParentChainDeployment(deploymentPath)
+	if err != nil {
+		return nil, err
+	}
+	var key *ecdsa.PrivateKey
+	if deployment != nil {
+		key, err = crypto.HexToECDSA(deployment.Key)
+	} else {
+		key, err = crypto.GenerateKey()
+	}
+	if err != nil {
+		return nil, err
+	}
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+
+	stackConfig := node.DefaultConfig
+	stackConfig.DataDir = dir
+	stackConfig.HTTPHost = ""
+	stackConfig.WSHost = ""
+	stackConfig.IPCPath = ""
+	stackConfig.P2P.ListenAddr = ""
+	stackConfig.P2P.NoDiscovery = true
+	stackConfig.P2P.NoDial = true
+	stackConfig.P2P.MaxPeers = 0
+	stack, err := node.New(&stackConfig)
+	if err != nil {
+		return nil, err
+	}
+	genesis := core.DeveloperGenesisBlock(devParentChainGasLimit, &addr)
+	genesisConfig := *genesis.Config
+	genesisConfig.ChainID = new(big.Int).SetUint64(parentChainId)
+	genesis.Config = &genesisConfig
+	ethConfig := ethconfig.Defaults
+	ethConfig.NetworkId = parentChainId
+	ethConfig.Genesis = genesis
+	ethConfig.SyncMode = downloader.FullSync
+	backend, err := eth.New(stack, &ethConfig)
+	if err != nil {
+		stack.Close()
+		return nil, err
+	}
+	beacon, err := catalyst.NewSimulatedBeacon(blockTime, backend)
+	if err != nil {
+		stack.Close()
+		return nil, err
+	}
+	catalyst.RegisterSimulatedBeaconAPIs(stack, beacon)
+	stack.RegisterLifecycle(beacon)
+	if err := stack.Start(); err != nil {
+		stack.Close()
+		return nil, fmt.Errorf("failed to start the simulated parent chain: %w", err)
+	}
+	parentChain := &devParentChain{
+		stack:  stack,
+		client: ethclient.NewClient(stack.Attach()),
+		key:    key,
+	}
+
+	if deployment != nil {
+		parentChain.addresses = deployment.Addresses
+		log.Info("started the simulated parent chain", "chainId", parentChainId, "rollup", deployment.Addresses.Rollup)
+		return parentChain, nil
+	}
+	parentChain.addresses, err = parentChain.deployRollup(ctx, chainConfig)
+	if err != nil {
+		parentChain.stop()
+		return nil, fmt.Errorf("failed to deploy the rollup to the simulated parent chain: %w", err)
+	}
+	deployment = &devParentChainDeployment{
+		Key:       common.Bytes2Hex(crypto.FromECDSA(key)),
+		Addresses: parentChain.addresses,
+	}
+	data, err := json.MarshalIndent(deployment, "", "  ")
+	if err != nil {
+		parentChain.stop()
+		return nil, err
+	}
+	if err := os.WriteFile(deploymentPath, data, 0o600); err != nil {
+		parentChain.stop()
+		return nil, err
+	}
+	log.Info("started the simulated parent chain and deployed the rollup", "chainId", parentChainId, "rollup", parentChain.addresses.Rollup)
+	return parentChain, nil
+}
+
+func (c *devParentChain) deployRollup(ctx context.Context, chainConfig *params.ChainConfig) (*chaininfo.RollupAddresses, error) {
+	chainId, err := c.client.ChainID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	opts, err := bind.NewKeyedTransactorWithChainID(c.key, chainId)
+	if err != nil {
+		return nil, err
+	}
+	opts.Context = ctx
+	serializedChainConfig, err := json.Marshal(chainConfig)
+	if err != nil {
+		return nil, err
+	}
+	readerConfig := headerreader.DefaultConfig
+	readerConfig.PollInterval = 100 * time.Millisecond
+	arbSys, _ := precompilesgen.NewArbSys(types.ArbSysAddress, c.client)
+	reader, err := headerreader.New(ctx, c.client, func() *headerreader.Config { return &readerConfig }, arbSys)
+	if err != nil {
+		return nil, err
+	}
+	reader.Start(ctx)
+	defer reader.StopAndWait()
+
+	owner := opts.From
+	return deploy.DeployOnL1(
+		ctx,
+		reader,
+		opts,
+		[]common.Address{owner},
+		owner,
+		0,
+		arbnode.GenerateRollupConfig(false, devWasmModuleRoot, owner, chainConfig, serializedChainConfig, common.Address{}),
+		common.Address{},
+		big.NewInt(devMaxDataSize),
+		false,
+	)
+}
+
+// configure points the node at the simulated parent chain: the batch poster posts calldata with the parent chain's
+// funded key, and delayed messages are sequenced as soon as they're mined.
+func (c *devParentChain) configure(config *NodeConfig) {
+	config.Node.ParentChainReader.Enable = true
+	config.Node.Dangerous.DisableBlobReader = true
+	config.Node.BatchPoster.Enable = true
+	config.Node.BatchPoster.Post4844Blobs = false
+	config.Node.BatchPoster.ParentChainWallet.PrivateKey = common.Bytes2Hex(crypto.FromECDSA(c.key))
+	config.Node.DelayedSequencer.Enable = true
+	config.Node.DelayedSequencer.UseMergeFinality = false
+	config.Node.DelayedSequencer.FinalizeDistance = 0
+}
+
+func (c *devParentChain) stop() {
+	if err := c.stack.Close(); err != nil {
+		log.Error("failed to stop the simulated parent chain", "err", err)
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestDevParentChain(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	dataDir := t.TempDir()
+	chainConfig := params.ArbitrumDevTestChainConfig()
+
+	parentChain, err := startDevParentChain(ctx, dataDir, 1337, chainConfig, 0)
+	Require(t, err)
+	addresses := *parentChain.addresses
+	if addresses.Rollup == (common.Address{}) || addresses.SequencerInbox == (common.Address{}) {
+		Fail(t, "the rollup wasn't deployed", addresses)
+	}
+	code, err := parentChain.client.CodeAt(ctx, addresses.SequencerInbox, nil)
+	Require(t, err)
+	if len(code) == 0 {
+		Fail(t, "no sequencer inbox code on the simulated parent chain")
+	}
+	var config NodeConfig
+	parentChain.configure(&config)
+	if !config.Node.BatchPoster.Enable || config.Node.BatchPoster.ParentChainWallet.PrivateKey == "" {
+		Fail(t, "the batch poster wasn't configured to post to the simulated parent chain")
+	}
+	parentChain.stop()
+
+	// restarting keeps the parent chain and its deployment
+	parentChain, err = startDevParentChain(ctx, dataDir, 1337, chainConfig, 0)
+	Require(t, err)
+	defer parentChain.stop()
+	if *parentChain.addresses != addresses {
+		Fail(t, "the rollup was redeployed", *parentChain.addresses, "expected", addresses)
+	}
+	code, err = parentChain.client.CodeAt(ctx, addresses.SequencerInbox, nil)
+	Require(t, err)
+	if len(code) == 0 {
+		Fail(t, "the simulated parent chain's state wasn't kept")
+	}
+}
@@ -351,6 +351,13 @@ func validateBlockChain(blockChain *core.BlockChain, chainConfig *params.ChainCo
 		if err := oldConfig.CheckCompatible(chainConfig, currentBlock.Number.Uint64(), currentBlock.Time); err != nil {
 			return fmt.Errorf("invalid chain config, not compatible with previous: %w", err)
 		}
+		ranges, err := arbosState.CustomPrecompileRangesFromChainConfig(oldSerializedConfig)
+		if err != nil {
+			return err
+		}
+		if err := arbosState.ValidatePrecompileAddressSpace(ranges, currentArbosState.ArbOSVersion()); err != nil {
+			return fmt.Errorf("invalid custom precompile ranges in chain config: %w", err)
+		}
 	}
 	// Make sure we don't allow accidentally downgrading ArbOS
 	if chainConfig.DebugMode() {
@@ -590,6 +597,10 @@ func openInitializeChainDb(ctx context.Context, stack *node.Node, config *NodeCo
 				if err != nil {
 					return nil, nil, err
 				}
+				err = checkSnapshotVerification(ctx, stack, chainDb, chainConfig, cacheConfig, &config.Init, persistentConfig, l1Client, rollupAddrs)
+				if err != nil {
+					return chainDb, nil, err
+				}
 				err = pruning.PruneChainDb(ctx, chainDb, stack, &config.Init, cacheConfig, persistentConfig, l1Client, rollupAddrs, config.Node.ValidatorRequired())
 				if err != nil {
 					return chainDb, nil, fmt.Errorf("error pruning: %w", err)
@@ -666,6 +677,12 @@ func openInitializeChainDb(ctx context.Context, stack *node.Node, config *NodeCo
 		return nil, nil, err
 	}
 
+	if initFile != "" {
+		if err := markSnapshotUnverified(chainDb); err != nil {
+			return chainDb, nil, err
+		}
+	}
+
 	// Rebuilding wasm store is not required when just starting out
 	err = gethexec.WriteToKeyValueStore(wasmDb, gethexec.RebuildingPositionKey, gethexec.RebuildingDone)
 	log.Info("Setting codehash position in rebuilding of wasm store to done")
@@ -714,6 +731,10 @@ func openInitializeChainDb(ctx context.Context, stack *node.Node, config *NodeCo
 		if chainConfig == nil {
 			return chainDb, nil, errors.New("no --init.* mode supplied and chain data not in expected directory")
 		}
+		err = checkSnapshotVerification(ctx, stack, chainDb, chainConfig, cacheConfig, &config.Init, persistentConfig, l1Client, rollupAddrs)
+		if err != nil {
+			return chainDb, nil, err
+		}
 		l2BlockChain, err = gethexec.GetBlockChain(chainDb, cacheConfig, chainConfig, config.Execution.TxLookupLimit)
 		if err != nil {
 			return chainDb, nil, err
@@ -809,7 +830,7 @@ func openInitializeChainDb(ctx context.Context, stack *node.Node, config *NodeCo
 		if !emptyBlockChain && (cacheConfig.StateScheme == rawdb.PathScheme) && config.Init.Force {
 			return chainDb, nil, errors.New("It is not possible to force init with non-empty blockchain when using path scheme")
 		}
-		l2BlockChain, err = gethexec.WriteOrTestBlockChain(chainDb, cacheConfig, initDataReader, chainConfig, parsedInitMessage, config.Execution.TxLookupLimit, config.Init.AccountsPerSync)
+		l2BlockChain, err = gethexec.WriteOrTestBlockChain(chainDb, cacheConfig, initDataReader, chainConfig, parsedInitMessage, config.Execution.TxLookupLimit, config.Init.ImportConfig())
 		if err != nil {
 			return chainDb, nil, err
 		}
@@ -42,6 +42,7 @@ import (
 	"github.com/ethereum/go-ethereum/metrics/exp"
 	"github.com/ethereum/go-ethereum/node"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
 
 	"github.com/offchainlabs/nitro/arbnode"
 	"github.com/offchainlabs/nitro/arbnode/resourcemanager"
@@ -54,6 +55,8 @@ import (
 	"github.com/offchainlabs/nitro/cmd/util"
 	"github.com/offchainlabs/nitro/cmd/util/confighelpers"
 	"github.com/offchainlabs/nitro/das"
+	"github.com/offchainlabs/nitro/execution"
+	"github.com/offchainlabs/nitro/execution/execrpc"
 	"github.com/offchainlabs/nitro/execution/gethexec"
 	_ "github.com/offchainlabs/nitro/execution/nodeInterface"
 	"github.com/offchainlabs/nitro/solgen/go/bridgegen"
@@ -75,7 +78,7 @@ func printSampleUsage(name string) {
 	fmt.Printf("Sample usage: %s [OPTIONS] \n\n", name)
 	fmt.Printf("Options:\n")
 	fmt.Printf("  --help\n")
-	fmt.Printf("  --dev: Start a default L2-only dev chain\n")
+	fmt.Printf("  --dev: Start a default dev chain on a parent chain simulated in this process\n")
 }
 
 func addUnlockWallet(accountManager *accounts.Manager, walletConf *genericconf.WalletConfig) (common.Address, error) {
@@ -231,6 +234,25 @@ func mainImpl() int {
 		nodeConfig.Node.ParentChainReader.Enable = true
 	}
 
+	var simParentChain *devParentChain
+	if nodeConfig.DevParentChain.Enable {
+		if nodeConfig.Node.Dangerous.NoL1Listener {
+			flag.Usage()
+			log.Crit("--dev-parent-chain.enable conflicts with --node.dangerous.no-l1-listener")
+		}
+		chainInfoFile := aggregateL2ChainInfoFiles(ctx, nodeConfig.Chain.InfoFiles, nodeConfig.Chain.InfoIpfsUrl, nodeConfig.Chain.InfoIpfsDownloadPath)
+		chainConfig, err := chaininfo.GetChainConfig(new(big.Int).SetUint64(nodeConfig.Chain.ID), nodeConfig.Chain.Name, 0, chainInfoFile, nodeConfig.Chain.InfoJson)
+		if err != nil {
+			log.Crit("error getting the chain config to deploy to the simulated parent chain", "err", err)
+		}
+		simParentChain, err = startDevParentChain(ctx, nodeConfig.Persistent.Chain, nodeConfig.ParentChain.ID, chainConfig, nodeConfig.DevParentChain.BlockTime)
+		if err != nil {
+			log.Crit("failed to start the simulated parent chain", "err", err)
+		}
+		defer simParentChain.stop()
+		simParentChain.configure(nodeConfig)
+	}
+
 	if nodeConfig.Execution.Sequencer.Enable && nodeConfig.Node.ParentChainReader.Enable && nodeConfig.Node.InboxReader.HardReorg {
 		flag.Usage()
 		log.Crit("hard reorgs cannot safely be enabled with sequencer mode enabled")
@@ -246,9 +268,11 @@ func mainImpl() int {
 	var dataSigner signature.DataSignerFunc
 	var l1TransactionOptsValidator *bind.TransactOpts
 	var l1TransactionOptsBatchPoster *bind.TransactOpts
+	var l1TransactionOptsForceInclusion *bind.TransactOpts
+	var l1TransactionOptsOutboxExecutor *bind.TransactOpts
 	// If sequencer and signing is enabled or batchposter is enabled without
 	// external signing sequencer will need a key.
-	sequencerNeedsKey := (nodeConfig.Node.Sequencer && !nodeConfig.Node.Feed.Output.DisableSigning) ||
+	sequencerNeedsKey := (nodeConfig.Node.Sequencer && !nodeConfig.Node.Feed.Output.DisableSigning && nodeConfig.Node.Feed.Output.SigningKey == "") ||
 		(nodeConfig.Node.BatchPoster.Enable && nodeConfig.Node.BatchPoster.DataPoster.ExternalSigner.URL == "")
 	validatorNeedsKey := nodeConfig.Node.Staker.OnlyCreateWalletContract ||
 		(nodeConfig.Node.Staker.Enable && !strings.EqualFold(nodeConfig.Node.Staker.Strategy, "watchtower") && nodeConfig.Node.Staker.DataPoster.ExternalSigner.URL == "")
@@ -285,6 +309,30 @@ func mainImpl() int {
 		}
 	}
 
+	if nodeConfig.Node.ForceInclusion.Enable || nodeConfig.Node.ForceInclusion.ParentChainWallet.OnlyCreateKey {
+		nodeConfig.Node.ForceInclusion.ParentChainWallet.ResolveDirectoryNames(nodeConfig.Persistent.Chain)
+		l1TransactionOptsForceInclusion, _, err = util.OpenWallet("l1-force-inclusion", &nodeConfig.Node.ForceInclusion.ParentChainWallet, new(big.Int).SetUint64(nodeConfig.ParentChain.ID))
+		if err != nil {
+			flag.Usage()
+			log.Crit("error opening force inclusion parent chain wallet", "path", nodeConfig.Node.ForceInclusion.ParentChainWallet.Pathname, "account", nodeConfig.Node.ForceInclusion.ParentChainWallet.Account, "err", err)
+		}
+		if nodeConfig.Node.ForceInclusion.ParentChainWallet.OnlyCreateKey {
+			return 0
+		}
+	}
+
+	if nodeConfig.Node.OutboxExecutor.Enable || nodeConfig.Node.OutboxExecutor.ParentChainWallet.OnlyCreateKey {
+		nodeConfig.Node.OutboxExecutor.ParentChainWallet.ResolveDirectoryNames(nodeConfig.Persistent.Chain)
+		l1TransactionOptsOutboxExecutor, _, err = util.OpenWallet("l1-outbox-executor", &nodeConfig.Node.OutboxExecutor.ParentChainWallet, new(big.Int).SetUint64(nodeConfig.ParentChain.ID))
+		if err != nil {
+			flag.Usage()
+			log.Crit("error opening outbox executor parent chain wallet", "path", nodeConfig.Node.OutboxExecutor.ParentChainWallet.Pathname, "account", nodeConfig.Node.OutboxExecutor.ParentChainWallet.Account, "err", err)
+		}
+		if nodeConfig.Node.OutboxExecutor.ParentChainWallet.OnlyCreateKey {
+			return 0
+		}
+	}
+
 	combinedL2ChainInfoFile := aggregateL2ChainInfoFiles(ctx, nodeConfig.Chain.InfoFiles, nodeConfig.Chain.InfoIpfsUrl, nodeConfig.Chain.InfoIpfsDownloadPath)
 
 	if nodeConfig.Node.Staker.Enable {
@@ -318,26 +366,46 @@ func mainImpl() int {
 	var l1Reader *headerreader.HeaderReader
 	var blobReader daprovider.BlobReader
 	if nodeConfig.Node.ParentChainReader.Enable {
-		confFetcher := func() *rpcclient.ClientConfig { return &liveNodeConfig.Get().ParentChain.Connection }
-		rpcClient := rpcclient.NewRpcClient(confFetcher, nil)
-		err := rpcClient.Start(ctx)
-		if err != nil {
-			log.Crit("couldn't connect to L1", "err", err)
-		}
-		l1Client = ethclient.NewClient(rpcClient)
-		l1ChainId, err := l1Client.ChainID(ctx)
-		if err != nil {
-			log.Crit("couldn't read L1 chainid", "err", err)
-		}
-		if l1ChainId.Uint64() != nodeConfig.ParentChain.ID {
-			log.Crit("L1 chainID doesn't fit config", "found", l1ChainId.Uint64(), "expected", nodeConfig.ParentChain.ID)
-		}
+		if simParentChain != nil {
+			l1Client = simParentChain.client
+			rollupAddrs = *simParentChain.addresses
+		} else {
+			confFetcher := func() *rpcclient.ClientConfig { return &liveNodeConfig.Get().ParentChain.Connection }
+			rpcClients := []*rpcclient.RpcClient{rpcclient.NewRpcClient(confFetcher, nil)}
+			for _, url := range nodeConfig.ParentChain.Failover.URLs {
+				url := url
+				rpcClients = append(rpcClients, rpcclient.NewRpcClient(func() *rpcclient.ClientConfig {
+					config := liveNodeConfig.Get().ParentChain.Connection
+					config.URL = url
+					return &config
+				}, nil))
+			}
+			var rpcClient interface {
+				rpc.ClientInterface
+				Start(context.Context) error
+			} = rpcClients[0]
+			if len(rpcClients) > 1 {
+				rpcClient = rpcclient.NewFailoverClient(func() *rpcclient.FailoverConfig { return &liveNodeConfig.Get().ParentChain.Failover }, rpcClients...)
+			}
+			err := rpcClient.Start(ctx)
+			if err != nil {
+				log.Crit("couldn't connect to L1", "err", err)
+			}
+			l1Client = ethclient.NewClient(rpcClient)
+			l1ChainId, err := l1Client.ChainID(ctx)
+			if err != nil {
+				log.Crit("couldn't read L1 chainid", "err", err)
+			}
+			if l1ChainId.Uint64() != nodeConfig.ParentChain.ID {
+				log.Crit("L1 chainID doesn't fit config", "found", l1ChainId.Uint64(), "expected", nodeConfig.ParentChain.ID)
+			}
 
-		log.Info("connected to l1 chain", "l1url", nodeConfig.ParentChain.Connection.URL, "l1chainid", nodeConfig.ParentChain.ID)
+			log.Info("connected to l1 chain", "l1url", nodeConfig.ParentChain.Connection.URL, "l1chainid", nodeConfig.ParentChain.ID)
 
-		rollupAddrs, err = chaininfo.GetRollupAddressesConfig(nodeConfig.Chain.ID, nodeConfig.Chain.Name, combinedL2ChainInfoFile, nodeConfig.Chain.InfoJson)
-		if err != nil {
-			log.Crit("error getting rollup addresses", "err", err)
+			rollupAddrs, err = chaininfo.GetRollupAddressesConfig(nodeConfig.Chain.ID, nodeConfig.Chain.Name, combinedL2ChainInfoFile, nodeConfig.Chain.InfoJson)
+			if err != nil {
+				log.Crit("error getting rollup addresses", "err", err)
+			}
 		}
 		arbSys, _ := precompilesgen.NewArbSys(types.ArbSysAddress, l1Client)
 		l1Reader, err = headerreader.New(ctx, l1Client, func() *headerreader.Config { return &liveNodeConfig.Get().Node.ParentChainReader }, arbSys)
@@ -397,6 +465,8 @@ func mainImpl() int {
 		flag.Usage()
 		log.Crit("Failed to start resource management module", "err", err)
 	}
+	gethexec.InitReplicaHTTPHandler(&nodeConfig.Execution.Replica)
+	rpcPolicyIPCEndpoint := gethexec.InitRPCPolicy(&nodeConfig.Execution.RPCPolicy, &stackConf)
 
 	var sameProcessValidationNodeEnabled bool
 	if nodeConfig.Node.BlockValidator.Enable && (nodeConfig.Node.BlockValidator.ValidationServerConfigs[0].URL == "self" || nodeConfig.Node.BlockValidator.ValidationServerConfigs[0].URL == "self-auth") {
@@ -408,6 +478,7 @@ func mainImpl() int {
 		flag.Usage()
 		log.Crit("failed to initialize geth stack", "err", err)
 	}
+	gethexec.RegisterRPCPolicyIPC(stack, rpcPolicyIPCEndpoint)
 	{
 		devAddr, err := addUnlockWallet(stack.AccountManager(), l2DevWallet)
 		if err != nil {
@@ -488,8 +559,13 @@ func mainImpl() int {
 			} else {
 				path := locator.GetMachinePath(moduleRoot)
 				if _, err := os.Stat(path); err != nil {
-					log.Error("unable to find validator machine directory for the on-chain WASM module root", "err", err)
-					return 1
+					registry := &nodeConfig.Validation.Wasm.Registry
+					if release, ok := registry.Lookup(moduleRoot); registry.Enable && ok {
+						log.Info("validator machine for the on-chain WASM module root will be fetched from the registry", "moduleRoot", moduleRoot, "release", release)
+					} else {
+						log.Error("unable to find validator machine directory for the on-chain WASM module root", "err", err)
+						return 1
+					}
 				}
 			}
 		}
@@ -557,6 +633,31 @@ func mainImpl() int {
 		return 1
 	}
 
+	if nodeConfig.SelfCheck.Enable {
+		selfCheck := &selfCheckInputs{
+			config:      &nodeConfig.SelfCheck,
+			nodeConfig:  nodeConfig,
+			l1Client:    l1Client,
+			rollupAddrs: rollupAddrs,
+			arbDb:       arbDb,
+		}
+		if l1TransactionOptsBatchPoster != nil && nodeConfig.Node.BatchPoster.Enable {
+			selfCheck.batchPosterAddress = &l1TransactionOptsBatchPoster.From
+		}
+		if l1TransactionOptsValidator != nil && nodeConfig.Node.Staker.Enable {
+			selfCheck.validatorAddress = &l1TransactionOptsValidator.From
+		}
+		report := runSelfCheck(ctx, selfCheck)
+		if err := logSelfCheckReport(&nodeConfig.SelfCheck, report); err != nil {
+			log.Error("failed to write self-check report", "err", err, "file", nodeConfig.SelfCheck.OutputFile)
+			return 1
+		}
+		if !report.Passed {
+			log.Error("startup self-check failed", "checks", selfCheckFailureSummary(report))
+			return 1
+		}
+	}
+
 	var valNode *valnode.ValidationNode
 	if sameProcessValidationNodeEnabled {
 		valNode, err = valnode.CreateValidationNode(
@@ -570,23 +671,34 @@ func mainImpl() int {
 		}
 	}
 
-	execNode, err := gethexec.CreateExecutionNode(
-		ctx,
-		stack,
-		chainDb,
-		l2BlockChain,
-		l1Client,
-		func() *gethexec.Config { return &liveNodeConfig.Get().Execution },
-	)
-	if err != nil {
-		log.Error("failed to create execution node", "err", err)
-		return 1
+	var execNode *gethexec.ExecutionNode
+	var execClient execution.FullExecutionClient
+	if nodeConfig.Node.ExecutionClient.URL != "" {
+		// execution runs in another process, which reads from this node through its consensus server
+		execClient = execrpc.NewExecutionRPCClient(func() *execrpc.ClientConfig { return &liveNodeConfig.Get().Node.ExecutionClient })
+	} else {
+		execNode, err = gethexec.CreateExecutionNode(
+			ctx,
+			stack,
+			chainDb,
+			l2BlockChain,
+			l1Client,
+			func() *gethexec.Config { return &liveNodeConfig.Get().Execution },
+		)
+		if err != nil {
+			log.Error("failed to create execution node", "err", err)
+			return 1
+		}
+		execClient = execNode
+		if nodeConfig.Execution.ConsensusClient.URL != "" {
+			return runExecutionOnly(ctx, stack, execNode, liveNodeConfig, fatalErrChan)
+		}
 	}
 
 	currentNode, err := arbnode.CreateNode(
 		ctx,
 		stack,
-		execNode,
+		execClient,
 		arbDb,
 		&NodeConfigFetcher{liveNodeConfig},
 		l2BlockChain.Config(),
@@ -603,6 +715,18 @@ func mainImpl() int {
 		log.Error("failed to create node", "err", err)
 		return 1
 	}
+	if nodeConfig.Node.ForceInclusion.Enable {
+		if err := currentNode.EnableForceInclusion(l1TransactionOptsForceInclusion); err != nil {
+			log.Error("failed to create force inclusion helper", "err", err)
+			return 1
+		}
+	}
+	if nodeConfig.Node.OutboxExecutor.Enable {
+		if err := currentNode.EnableOutboxExecutor(l1TransactionOptsOutboxExecutor); err != nil {
+			log.Error("failed to create outbox executor", "err", err)
+			return 1
+		}
+	}
 
 	// Validate sequencer's MaxTxDataSize and batchPoster's MaxSize params.
 	// SequencerInbox's maxDataSize is defaulted to 117964 which is 90% of Geth's 128KB tx size limit, leaving ~13KB for proving.
@@ -643,8 +767,19 @@ func mainImpl() int {
 		if err := genericconf.InitLog(newCfg.LogType, newCfg.LogLevel, &newCfg.FileLogging, pathResolver(nodeConfig.Persistent.LogDir)); err != nil {
 			return fmt.Errorf("failed to re-init logging: %w", err)
 		}
+		if execNode != nil {
+			if err := execNode.OnConfigReload(&oldCfg.Execution, &newCfg.Execution); err != nil {
+				return err
+			}
+		}
 		return currentNode.OnConfigReload(&oldCfg.Node, &newCfg.Node)
 	})
+	stack.RegisterAPIs([]rpc.API{{
+		Namespace: "admin",
+		Version:   "1.0",
+		Service:   &ConfigAPI{liveNodeConfig},
+		Public:    false,
+	}})
 
 	if nodeConfig.Node.Dangerous.NoL1Listener && nodeConfig.Init.DevInit {
 		// If we don't have any messages, we're not connected to the L1, and we're using a dev init,
@@ -663,6 +798,10 @@ func mainImpl() int {
 	}
 	gqlConf := nodeConfig.GraphQL
 	if gqlConf.Enable {
+		if execNode == nil {
+			log.Error("the GraphQL service requires execution in this process")
+			return 1
+		}
 		if err := graphql.New(stack, execNode.Backend.APIBackend(), execNode.FilterSystem, gqlConf.CORSDomain, gqlConf.VHosts); err != nil {
 			log.Error("failed to register the GraphQL service", "err", err)
 			return 1
@@ -723,6 +862,47 @@ func mainImpl() int {
 	return 0
 }
 
+// runExecutionOnly runs the execution node alone, driven by a consensus node in another process.
+func runExecutionOnly(ctx context.Context, stack *node.Node, execNode *gethexec.ExecutionNode, liveNodeConfig *genericconf.LiveConfig[*NodeConfig], fatalErrChan chan error) int {
+	consensus := execrpc.NewConsensusRPCClient(func() *execrpc.ClientConfig { return &liveNodeConfig.Get().Execution.ConsensusClient })
+	if err := consensus.Start(ctx); err != nil {
+		log.Error("failed to connect to the consensus node", "err", err)
+		return 1
+	}
+	defer consensus.StopAndWait()
+	if err := execNode.Initialize(ctx); err != nil {
+		log.Error("failed to initialize the execution node", "err", err)
+		return 1
+	}
+	if err := stack.Start(); err != nil {
+		log.Error("failed to start the geth stack", "err", err)
+		return 1
+	}
+	defer func() {
+		if err := stack.Close(); err != nil {
+			log.Error("error on stack close", "err", err)
+		}
+	}()
+	execNode.SetConsensusClient(consensus)
+	if err := execNode.Start(ctx); err != nil {
+		log.Error("failed to start the execution node", "err", err)
+		return 1
+	}
+	defer execNode.StopAndWait()
+	log.Info("running execution only", "consensus", liveNodeConfig.Get().Execution.ConsensusClient.URL)
+
+	sigint := make(chan os.Signal, 1)
+	signal.Notify(sigint, os.Interrupt, syscall.SIGTERM)
+	select {
+	case err := <-fatalErrChan:
+		log.Error("shutting down due to fatal error", "err", err)
+		return 1
+	case <-sigint:
+		log.Info("shutting down because of sigint")
+		return 0
+	}
+}
+
 type NodeConfig struct {
 	Conf             genericconf.ConfConfig          `koanf:"conf" reload:"hot"`
 	Node             arbnode.Config                  `koanf:"node" reload:"hot"`
@@ -746,6 +926,8 @@ type NodeConfig struct {
 	Init             conf.InitConfig                 `koanf:"init"`
 	Rpc              genericconf.RpcConfig           `koanf:"rpc"`
 	BlocksReExecutor blocksreexecutor.Config         `koanf:"blocks-reexecutor"`
+	SelfCheck        SelfCheckConfig                 `koanf:"self-check"`
+	DevParentChain   DevParentChainConfig            `koanf:"dev-parent-chain"`
 }
 
 var NodeConfigDefault = NodeConfig{
@@ -771,6 +953,8 @@ var NodeConfigDefault = NodeConfig{
 	PProf:            false,
 	PprofCfg:         genericconf.PProfDefault,
 	BlocksReExecutor: blocksreexecutor.DefaultConfig,
+	SelfCheck:        SelfCheckConfigDefault,
+	DevParentChain:   DevParentChainConfigDefault,
 }
 
 func NodeConfigAddOptions(f *flag.FlagSet) {
@@ -797,6 +981,8 @@ func NodeConfigAddOptions(f *flag.FlagSet) {
 	conf.InitConfigAddOptions("init", f)
 	genericconf.RpcConfigAddOptions("rpc", f)
 	blocksreexecutor.ConfigAddOptions("blocks-reexecutor", f)
+	SelfCheckConfigAddOptions("self-check", f)
+	DevParentChainConfigAddOptions("dev-parent-chain", f)
 }
 
 func (c *NodeConfig) ResolveDirectoryNames() error {
@@ -848,6 +1034,12 @@ func (c *NodeConfig) CanReload(new *NodeConfig) error {
 }
 
 func (c *NodeConfig) Validate() error {
+	if err := c.validateReplica(); err != nil {
+		return err
+	}
+	if c.Node.ExecutionClient.URL != "" && c.Execution.ConsensusClient.URL != "" {
+		return errors.New("consensus and execution can't both run in other processes")
+	}
 	if c.Init.RecreateMissingStateFrom > 0 && !c.Execution.Caching.Archive {
 		return errors.New("recreate-missing-state-from enabled for a non-archive node")
 	}
@@ -866,12 +1058,79 @@ func (c *NodeConfig) Validate() error {
 	if err := c.BlocksReExecutor.Validate(); err != nil {
 		return err
 	}
+	if err := c.SelfCheck.Validate(); err != nil {
+		return err
+	}
+	if err := c.Validation.Wasm.Registry.Validate(); err != nil {
+		return err
+	}
 	if c.Node.ValidatorRequired() && (c.Execution.Caching.StateScheme == rawdb.PathScheme) {
 		return errors.New("path cannot be used as execution.caching.state-scheme when validator is required")
 	}
 	return c.Persistent.Validate()
 }
 
+// validateReplica checks that a read-only replica has everything that could sequence, post to or transact on the
+// parent chain disabled, and that it follows a feed, so a replica image can't be started as anything else.
+func (c *NodeConfig) validateReplica() error {
+	if !c.Execution.Replica.Enable {
+		return nil
+	}
+	var enabled []string
+	if c.Node.Sequencer {
+		enabled = append(enabled, "node.sequencer")
+	}
+	if c.Execution.Sequencer.Enable {
+		enabled = append(enabled, "execution.sequencer.enable")
+	}
+	if c.Node.DelayedSequencer.Enable {
+		enabled = append(enabled, "node.delayed-sequencer.enable")
+	}
+	if c.Node.SeqCoordinator.Enable {
+		enabled = append(enabled, "node.seq-coordinator.enable")
+	}
+	if c.Node.BatchPoster.Enable {
+		enabled = append(enabled, "node.batch-poster.enable")
+	}
+	if c.Node.ForceInclusion.Enable {
+		enabled = append(enabled, "node.force-inclusion.enable")
+	}
+	if c.Node.OutboxExecutor.Enable {
+		enabled = append(enabled, "node.outbox-executor.enable")
+	}
+	// a watchtower only reads from the parent chain
+	if c.Node.Staker.Enable && !strings.EqualFold(c.Node.Staker.Strategy, "watchtower") {
+		enabled = append(enabled, "node.staker.enable with strategy "+c.Node.Staker.Strategy)
+	}
+	if c.Init.DevInit {
+		enabled = append(enabled, "init.dev-init")
+	}
+	if len(enabled) > 0 {
+		return fmt.Errorf("execution.replica.enable is read-only but these are enabled: %v", strings.Join(enabled, ", "))
+	}
+	wallets := []struct {
+		name          string
+		wallet        *genericconf.WalletConfig
+		defaultWallet *genericconf.WalletConfig
+	}{
+		{"node.batch-poster.parent-chain-wallet", &c.Node.BatchPoster.ParentChainWallet, &arbnode.DefaultBatchPosterL1WalletConfig},
+		{"node.staker.parent-chain-wallet", &c.Node.Staker.ParentChainWallet, &staker.DefaultValidatorL1WalletConfig},
+		{"node.force-inclusion.parent-chain-wallet", &c.Node.ForceInclusion.ParentChainWallet, &arbnode.DefaultForceInclusionL1WalletConfig},
+		{"node.outbox-executor.parent-chain-wallet", &c.Node.OutboxExecutor.ParentChainWallet, &arbnode.DefaultOutboxExecutorL1WalletConfig},
+	}
+	for _, w := range wallets {
+		// a keystore wallet is configured by its password, account or a pathname other than the default
+		keystore := w.wallet.Pwd() != nil || w.wallet.Account != "" || w.wallet.Pathname != w.defaultWallet.Pathname
+		if w.wallet.PrivateKey != "" || w.wallet.OnlyCreateKey || keystore {
+			return fmt.Errorf("execution.replica.enable doesn't use parent chain wallets but %v is configured", w.name)
+		}
+	}
+	if len(c.Node.Feed.Input.URL) == 0 {
+		return errors.New("execution.replica.enable requires a feed to follow in node.feed.input.url")
+	}
+	return nil
+}
+
 func (c *NodeConfig) GetReloadInterval() time.Duration {
 	return c.Conf.ReloadInterval
 }
@@ -914,12 +1173,14 @@ func ParseNode(ctx context.Context, args []string) (*NodeConfig, *genericconf.Wa
 	// Don't print wallet passwords
 	if nodeConfig.Conf.Dump {
 		err = confighelpers.DumpConfig(k, map[string]interface{}{
-			"node.batch-poster.parent-chain-wallet.password":    "",
-			"node.batch-poster.parent-chain-wallet.private-key": "",
-			"node.staker.parent-chain-wallet.password":          "",
-			"node.staker.parent-chain-wallet.private-key":       "",
-			"chain.dev-wallet.password":                         "",
-			"chain.dev-wallet.private-key":                      "",
+			"node.batch-poster.parent-chain-wallet.password":       "",
+			"node.batch-poster.parent-chain-wallet.private-key":    "",
+			"node.staker.parent-chain-wallet.password":             "",
+			"node.staker.parent-chain-wallet.private-key":          "",
+			"node.force-inclusion.parent-chain-wallet.password":    "",
+			"node.force-inclusion.parent-chain-wallet.private-key": "",
+			"chain.dev-wallet.password":                            "",
+			"chain.dev-wallet.private-key":                         "",
 		})
 		if err != nil {
 			return nil, nil, err
@@ -972,6 +1233,9 @@ func applyChainParameters(ctx context.Context, k *koanf.Koanf, chainId uint64, c
 	if err != nil {
 		return err
 	}
+	if err := chainInfo.Validate(); err != nil {
+		return fmt.Errorf("invalid chain info for chain %v: %w", chainInfo.ChainName, err)
+	}
 	var parentChainIsArbitrum bool
 	if chainInfo.ParentChainIsArbitrum != nil {
 		parentChainIsArbitrum = *chainInfo.ParentChainIsArbitrum
@@ -1027,6 +1291,22 @@ func applyChainParameters(ctx context.Context, k *koanf.Koanf, chainId uint64, c
 	if chainInfo.DasIndexUrl != "" {
 		chainDefaults["node.batch-poster.max-size"] = 1_000_000
 	}
+	if oracle := chainInfo.ParentChainPriceOracle; oracle != nil {
+		for _, prefix := range []string{"node.batch-poster.l1-price-oracle", "execution.sequencer.l1-price-oracle"} {
+			if oracle.Source != "" {
+				chainDefaults[prefix+".source"] = oracle.Source
+			}
+			if oracle.ExternalURL != "" {
+				chainDefaults[prefix+".external.url"] = oracle.ExternalURL
+			}
+			if oracle.ExternalMethod != "" {
+				chainDefaults[prefix+".external.method"] = oracle.ExternalMethod
+			}
+			if oracle.SmoothingWindow != 0 {
+				chainDefaults[prefix+".smoothing-window"] = oracle.SmoothingWindow
+			}
+		}
+	}
 	err = k.Load(confmap.Provider(chainDefaults, "."), nil)
 	if err != nil {
 		return err
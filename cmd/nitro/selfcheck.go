@@ -0,0 +1,426 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/offchainlabs/nitro/arbnode"
+	"github.com/offchainlabs/nitro/arbstate/daprovider"
+	"github.com/offchainlabs/nitro/blsSignatures"
+	"github.com/offchainlabs/nitro/cmd/chaininfo"
+	"github.com/offchainlabs/nitro/das"
+	"github.com/offchainlabs/nitro/solgen/go/bridgegen"
+	"github.com/offchainlabs/nitro/solgen/go/rollupgen"
+	"github.com/offchainlabs/nitro/util/arbmath"
+)
+
+type SelfCheckConfig struct {
+	Enable                bool    `koanf:"enable"`
+	OutputFile            string  `koanf:"output-file"`
+	FailOnWarning         bool    `koanf:"fail-on-warning"`
+	MinBatchPosterBalance float64 `koanf:"min-batch-poster-balance"`
+	MinValidatorBalance   float64 `koanf:"min-validator-balance"`
+}
+
+var SelfCheckConfigDefault = SelfCheckConfig{
+	Enable:                false,
+	OutputFile:            "",
+	FailOnWarning:         false,
+	MinBatchPosterBalance: 0,
+	MinValidatorBalance:   0,
+}
+
+func SelfCheckConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.Bool(prefix+".enable", SelfCheckConfigDefault.Enable, "run startup self-check of the node configuration against the parent chain and local databases")
+	f.String(prefix+".output-file", SelfCheckConfigDefault.OutputFile, "if set, write the self-check diagnostics as JSON to this file")
+	f.Bool(prefix+".fail-on-warning", SelfCheckConfigDefault.FailOnWarning, "refuse to start if the self-check produces any warnings")
+	f.Float64(prefix+".min-batch-poster-balance", SelfCheckConfigDefault.MinBatchPosterBalance, "minimum parent chain balance in ether required of the batch poster wallet (0 to disable)")
+	f.Float64(prefix+".min-validator-balance", SelfCheckConfigDefault.MinValidatorBalance, "minimum parent chain balance in ether required of the validator wallet (0 to disable)")
+}
+
+func (c *SelfCheckConfig) Validate() error {
+	if c.MinBatchPosterBalance < 0 || c.MinValidatorBalance < 0 {
+		return errors.New("self-check minimum balances must not be negative")
+	}
+	return nil
+}
+
+type DiagnosticStatus string
+
+const (
+	DiagnosticOk   DiagnosticStatus = "ok"
+	DiagnosticWarn DiagnosticStatus = "warn"
+	DiagnosticFail DiagnosticStatus = "fail"
+)
+
+// Diagnostic is the outcome of a single self-check.
+// Remedy suggests what the operator should change when the check doesn't pass.
+type Diagnostic struct {
+	Check    string           `json:"check"`
+	Status   DiagnosticStatus `json:"status"`
+	Message  string           `json:"message"`
+	Expected string           `json:"expected,omitempty"`
+	Found    string           `json:"found,omitempty"`
+	Remedy   string           `json:"remedy,omitempty"`
+}
+
+type SelfCheckReport struct {
+	Passed      bool         `json:"passed"`
+	Diagnostics []Diagnostic `json:"diagnostics"`
+}
+
+func (r *SelfCheckReport) add(d Diagnostic) {
+	r.Diagnostics = append(r.Diagnostics, d)
+}
+
+func (r *SelfCheckReport) ok(check, message string) {
+	r.add(Diagnostic{Check: check, Status: DiagnosticOk, Message: message})
+}
+
+// unavailable records a check that couldn't query the parent chain. A parent chain RPC that's briefly
+// unreachable shouldn't stop the node from starting, so it's only a warning.
+func (r *SelfCheckReport) unavailable(check, message string) {
+	r.add(Diagnostic{
+		Check:   check,
+		Status:  DiagnosticWarn,
+		Message: message,
+		Remedy:  "the check was skipped; make sure --parent-chain.connection.url is reachable",
+	})
+}
+
+func (r *SelfCheckReport) count(status DiagnosticStatus) int {
+	count := 0
+	for _, d := range r.Diagnostics {
+		if d.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+type selfCheckInputs struct {
+	config             *SelfCheckConfig
+	nodeConfig         *NodeConfig
+	l1Client           *ethclient.Client
+	rollupAddrs        chaininfo.RollupAddresses
+	arbDb              ethdb.Database
+	batchPosterAddress *common.Address
+	validatorAddress   *common.Address
+}
+
+// runSelfCheck runs every applicable check and returns the collected diagnostics.
+// Checks that need the parent chain are skipped when no parent chain client is available.
+func runSelfCheck(ctx context.Context, in *selfCheckInputs) *SelfCheckReport {
+	report := &SelfCheckReport{}
+	checkDbSchemaVersion(report, in.arbDb)
+	if in.l1Client != nil {
+		checkParentChainId(ctx, report, in)
+		rollup, err := rollupgen.NewRollupUserLogic(in.rollupAddrs.Rollup, in.l1Client)
+		if err != nil {
+			report.add(Diagnostic{
+				Check:   "rollup-addresses",
+				Status:  DiagnosticFail,
+				Message: fmt.Sprintf("failed to bind rollup contract: %v", err),
+			})
+		} else {
+			checkRollupAddresses(ctx, report, in, rollup)
+			checkWasmModuleRoot(ctx, report, in, rollup)
+		}
+		checkDasKeyset(ctx, report, in)
+		checkWalletBalance(ctx, report, in.l1Client, "batch-poster-balance", in.batchPosterAddress, in.config.MinBatchPosterBalance, "--node.batch-poster.parent-chain-wallet")
+		checkWalletBalance(ctx, report, in.l1Client, "validator-balance", in.validatorAddress, in.config.MinValidatorBalance, "--node.staker.parent-chain-wallet")
+	}
+	failures := report.count(DiagnosticFail)
+	if in.config.FailOnWarning {
+		failures += report.count(DiagnosticWarn)
+	}
+	report.Passed = failures == 0
+	return report
+}
+
+func checkDbSchemaVersion(report *SelfCheckReport, arbDb ethdb.Database) {
+	const check = "arbitrumdata-schema"
+	version, err := arbnode.ReadArbDbSchemaVersion(arbDb)
+	if err != nil {
+		report.add(Diagnostic{
+			Check:   check,
+			Status:  DiagnosticFail,
+			Message: fmt.Sprintf("failed to read arbitrumdata schema version: %v", err),
+			Remedy:  "the arbitrumdata database may be corrupt; delete it and resync",
+		})
+		return
+	}
+	if version > arbnode.CurrentDbSchemaVersion {
+		report.add(Diagnostic{
+			Check:    check,
+			Status:   DiagnosticFail,
+			Message:  "arbitrumdata database was written by a newer node version",
+			Expected: fmt.Sprintf("<= %d", arbnode.CurrentDbSchemaVersion),
+			Found:    fmt.Sprint(version),
+			Remedy:   "upgrade the node software, or delete the arbitrumdata database and resync",
+		})
+		return
+	}
+	report.ok(check, fmt.Sprintf("arbitrumdata schema version %d is supported", version))
+}
+
+func checkParentChainId(ctx context.Context, report *SelfCheckReport, in *selfCheckInputs) {
+	const check = "parent-chain-id"
+	chainId, err := in.l1Client.ChainID(ctx)
+	if err != nil {
+		report.unavailable(check, fmt.Sprintf("failed to read parent chain id: %v", err))
+		return
+	}
+	if chainId.Uint64() != in.nodeConfig.ParentChain.ID {
+		report.add(Diagnostic{
+			Check:    check,
+			Status:   DiagnosticFail,
+			Message:  "parent chain id doesn't match configuration",
+			Expected: fmt.Sprint(in.nodeConfig.ParentChain.ID),
+			Found:    chainId.String(),
+			Remedy:   "point --parent-chain.connection.url at the correct chain or fix --parent-chain.id",
+		})
+		return
+	}
+	report.ok(check, fmt.Sprintf("parent chain id %v matches configuration", chainId))
+}
+
+func checkRollupAddresses(ctx context.Context, report *SelfCheckReport, in *selfCheckInputs, rollup *rollupgen.RollupUserLogic) {
+	const check = "rollup-addresses"
+	callOpts := &bind.CallOpts{Context: ctx}
+	code, err := in.l1Client.CodeAt(ctx, in.rollupAddrs.Rollup, nil)
+	if err != nil {
+		report.unavailable(check, fmt.Sprintf("failed to read rollup contract code: %v", err))
+		return
+	}
+	if len(code) == 0 {
+		report.add(Diagnostic{
+			Check:   check,
+			Status:  DiagnosticFail,
+			Message: "no contract deployed at the configured rollup address",
+			Found:   in.rollupAddrs.Rollup.Hex(),
+			Remedy:  "check --chain.info-json and --chain.id for the rollup address",
+		})
+		return
+	}
+
+	chainId, err := rollup.ChainId(callOpts)
+	if err != nil {
+		report.unavailable("chain-id", fmt.Sprintf("failed to read chain id from rollup: %v", err))
+	} else if chainId.Uint64() != in.nodeConfig.Chain.ID {
+		report.add(Diagnostic{
+			Check:    "chain-id",
+			Status:   DiagnosticFail,
+			Message:  "rollup contract belongs to a different chain",
+			Expected: fmt.Sprint(in.nodeConfig.Chain.ID),
+			Found:    chainId.String(),
+			Remedy:   "fix --chain.id or the rollup address in --chain.info-json",
+		})
+	} else {
+		report.ok("chain-id", fmt.Sprintf("rollup chain id %v matches configuration", chainId))
+	}
+
+	contracts := []struct {
+		name       string
+		configured common.Address
+		read       func(*bind.CallOpts) (common.Address, error)
+	}{
+		{"bridge", in.rollupAddrs.Bridge, rollup.Bridge},
+		{"inbox", in.rollupAddrs.Inbox, rollup.Inbox},
+		{"sequencer-inbox", in.rollupAddrs.SequencerInbox, rollup.SequencerInbox},
+	}
+	mismatched := false
+	for _, contract := range contracts {
+		onChain, err := contract.read(callOpts)
+		if err != nil {
+			report.unavailable(check, fmt.Sprintf("failed to read %v address from rollup: %v", contract.name, err))
+			mismatched = true
+			continue
+		}
+		if onChain != contract.configured {
+			report.add(Diagnostic{
+				Check:    check,
+				Status:   DiagnosticFail,
+				Message:  fmt.Sprintf("configured %v address doesn't match the rollup contract", contract.name),
+				Expected: onChain.Hex(),
+				Found:    contract.configured.Hex(),
+				Remedy:   "fix the rollup addresses in --chain.info-json",
+			})
+			mismatched = true
+		}
+	}
+	if !mismatched {
+		report.ok(check, "configured rollup addresses match the rollup contract")
+	}
+}
+
+func checkWasmModuleRoot(ctx context.Context, report *SelfCheckReport, in *selfCheckInputs, rollup *rollupgen.RollupUserLogic) {
+	const check = "wasm-module-root"
+	moduleRoot, err := rollup.WasmModuleRoot(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		report.unavailable(check, fmt.Sprintf("failed to read wasm module root from rollup: %v", err))
+		return
+	}
+	if (moduleRoot == common.Hash{}) {
+		report.add(Diagnostic{Check: check, Status: DiagnosticFail, Message: "on-chain wasm module root is zero"})
+		return
+	}
+	if in.nodeConfig.Node.BlockValidator.Enable {
+		configured := in.nodeConfig.Node.BlockValidator.CurrentModuleRoot
+		if configured != "current" && configured != "latest" && common.HexToHash(configured) != moduleRoot {
+			report.add(Diagnostic{
+				Check:    check,
+				Status:   DiagnosticWarn,
+				Message:  "block validator is configured with a module root other than the on-chain one",
+				Expected: moduleRoot.Hex(),
+				Found:    configured,
+				Remedy:   "set --node.block-validator.current-module-root to \"current\" or the on-chain root",
+			})
+			return
+		}
+	}
+	report.ok(check, fmt.Sprintf("on-chain wasm module root is %v", moduleRoot))
+}
+
+func checkDasKeyset(ctx context.Context, report *SelfCheckReport, in *selfCheckInputs) {
+	const check = "das-keyset"
+	aggConfig := in.nodeConfig.Node.DataAvailability.RPCAggregator
+	if !in.nodeConfig.Node.DataAvailability.Enable || !aggConfig.Enable {
+		return
+	}
+	keysetHash, err := keysetHashFromAggregatorConfig(&aggConfig)
+	if err != nil {
+		report.add(Diagnostic{
+			Check:   check,
+			Status:  DiagnosticFail,
+			Message: fmt.Sprintf("invalid DAS keyset configuration: %v", err),
+			Remedy:  "check the backends in --node.data-availability.rpc-aggregator.backends",
+		})
+		return
+	}
+	seqInbox, err := bridgegen.NewSequencerInbox(in.rollupAddrs.SequencerInbox, in.l1Client)
+	if err != nil {
+		report.add(Diagnostic{Check: check, Status: DiagnosticFail, Message: fmt.Sprintf("failed to bind sequencer inbox: %v", err)})
+		return
+	}
+	valid, err := seqInbox.IsValidKeysetHash(&bind.CallOpts{Context: ctx}, keysetHash)
+	if err != nil {
+		report.unavailable(check, fmt.Sprintf("failed to query keyset validity: %v", err))
+		return
+	}
+	if !valid {
+		report.add(Diagnostic{
+			Check:   check,
+			Status:  DiagnosticFail,
+			Message: "configured DAS keyset isn't registered as valid in the sequencer inbox",
+			Found:   keysetHash.Hex(),
+			Remedy:  "register the keyset with setValidKeyset or fix the rpc-aggregator backends and assumed-honest value",
+		})
+		return
+	}
+	report.ok(check, fmt.Sprintf("DAS keyset %v is valid on-chain", keysetHash))
+}
+
+// keysetHashFromAggregatorConfig computes the keyset hash the aggregator would sign with,
+// without connecting to any of its backends.
+func keysetHashFromAggregatorConfig(config *das.AggregatorConfig) (common.Hash, error) {
+	pubKeys := make([]blsSignatures.PublicKey, 0, len(config.Backends))
+	for i, backend := range config.Backends {
+		pubKey, err := das.DecodeBase64BLSPublicKey([]byte(backend.Pubkey))
+		if err != nil {
+			return common.Hash{}, fmt.Errorf("backend %d (%v): %w", i, backend.URL, err)
+		}
+		pubKeys = append(pubKeys, *pubKey)
+	}
+	if config.AssumedHonest <= 0 {
+		return common.Hash{}, errors.New("assumed-honest must be positive")
+	}
+	keyset := &daprovider.DataAvailabilityKeyset{
+		// #nosec G115
+		AssumedHonest: uint64(config.AssumedHonest),
+		PubKeys:       pubKeys,
+	}
+	return keyset.Hash()
+}
+
+func checkWalletBalance(ctx context.Context, report *SelfCheckReport, l1Client *ethclient.Client, check string, address *common.Address, minEther float64, walletFlag string) {
+	if address == nil || minEther <= 0 {
+		return
+	}
+	balance, err := l1Client.BalanceAt(ctx, *address, nil)
+	if err != nil {
+		report.unavailable(check, fmt.Sprintf("failed to read balance of %v: %v", address, err))
+		return
+	}
+	minBalance := arbmath.FloatToBig(minEther * params.Ether)
+	if balance.Cmp(minBalance) < 0 {
+		report.add(Diagnostic{
+			Check:    check,
+			Status:   DiagnosticFail,
+			Message:  fmt.Sprintf("wallet %v is below the configured minimum balance", address),
+			Expected: fmt.Sprintf(">= %v wei", minBalance),
+			Found:    fmt.Sprintf("%v wei", balance),
+			Remedy:   fmt.Sprintf("fund %v or configure a different wallet with %v", address, walletFlag),
+		})
+		return
+	}
+	report.ok(check, fmt.Sprintf("wallet %v has balance %v wei", address, balance))
+}
+
+// logSelfCheckReport logs every diagnostic and writes the machine-readable report if configured.
+func logSelfCheckReport(config *SelfCheckConfig, report *SelfCheckReport) error {
+	for _, d := range report.Diagnostics {
+		ctx := []interface{}{"check", d.Check}
+		if d.Expected != "" {
+			ctx = append(ctx, "expected", d.Expected)
+		}
+		if d.Found != "" {
+			ctx = append(ctx, "found", d.Found)
+		}
+		if d.Remedy != "" {
+			ctx = append(ctx, "remedy", d.Remedy)
+		}
+		switch d.Status {
+		case DiagnosticFail:
+			log.Error("self-check failed: "+d.Message, ctx...)
+		case DiagnosticWarn:
+			log.Warn("self-check warning: "+d.Message, ctx...)
+		default:
+			log.Info("self-check passed: "+d.Message, ctx...)
+		}
+	}
+	if config.OutputFile == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(config.OutputFile, append(data, '\n'), 0o600)
+}
+
+func selfCheckFailureSummary(report *SelfCheckReport) string {
+	var failed []string
+	for _, d := range report.Diagnostics {
+		if d.Status != DiagnosticOk {
+			failed = append(failed, d.Check)
+		}
+	}
+	return strings.Join(failed, ",")
+}
@@ -0,0 +1,65 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/rawdb"
+
+	"github.com/offchainlabs/nitro/arbnode"
+	"github.com/offchainlabs/nitro/das"
+)
+
+func TestSelfCheckDbSchemaVersion(t *testing.T) {
+	ctx := context.Background()
+	arbDb := rawdb.NewMemoryDatabase()
+	config := SelfCheckConfigDefault
+	in := &selfCheckInputs{config: &config, nodeConfig: &NodeConfigDefault, arbDb: arbDb}
+
+	report := runSelfCheck(ctx, in)
+	if !report.Passed || len(report.Diagnostics) != 1 || report.Diagnostics[0].Status != DiagnosticOk {
+		t.Fatal("expected empty database to pass", report)
+	}
+
+	versionBytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(versionBytes, arbnode.CurrentDbSchemaVersion+1)
+	if err := arbDb.Put([]byte("_schemaVersion"), versionBytes); err != nil {
+		t.Fatal(err)
+	}
+	report = runSelfCheck(ctx, in)
+	if report.Passed || report.Diagnostics[0].Status != DiagnosticFail || report.Diagnostics[0].Remedy == "" {
+		t.Fatal("expected newer schema version to fail with a remedy", report)
+	}
+	if summary := selfCheckFailureSummary(report); summary != "arbitrumdata-schema" {
+		t.Fatal("unexpected failure summary", summary)
+	}
+}
+
+func TestKeysetHashFromAggregatorConfig(t *testing.T) {
+	config := das.DefaultAggregatorConfig
+	config.Backends = das.BackendConfigList{{URL: "http://localhost:1234", Pubkey: "not a key"}}
+	config.AssumedHonest = 1
+	if _, err := keysetHashFromAggregatorConfig(&config); err == nil {
+		t.Fatal("expected error for invalid public key")
+	}
+	config.Backends = nil
+	config.AssumedHonest = 0
+	if _, err := keysetHashFromAggregatorConfig(&config); err == nil {
+		t.Fatal("expected error for zero assumed-honest")
+	}
+}
+
+func TestSelfCheckUnavailableParentChain(t *testing.T) {
+	if SelfCheckConfigDefault.Enable {
+		t.Fatal("the self-check is enabled by default")
+	}
+	report := &SelfCheckReport{}
+	report.unavailable("parent-chain-id", "failed to read parent chain id: connection refused")
+	if report.count(DiagnosticFail) != 0 || report.count(DiagnosticWarn) != 1 {
+		t.Fatal("expected an unreachable parent chain to only warn", report)
+	}
+}
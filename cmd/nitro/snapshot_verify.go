@@ -0,0 +1,311 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/ethereum/go-ethereum/triedb"
+
+	"github.com/offchainlabs/nitro/arbnode"
+	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/cmd/chaininfo"
+	"github.com/offchainlabs/nitro/cmd/conf"
+	"github.com/offchainlabs/nitro/staker"
+)
+
+// Set when a database is extracted from a downloaded snapshot, and deleted once the snapshot is verified,
+// so a node interrupted during verification verifies again on the next start.
+var snapshotVerificationPendingKey = []byte("_nitroSnapshotVerificationPending")
+
+func markSnapshotUnverified(chainDb ethdb.KeyValueWriter) error {
+	return chainDb.Put(snapshotVerificationPendingKey, []byte{1})
+}
+
+// checkSnapshotVerification verifies the database if it came from a snapshot that hasn't been verified yet.
+// A snapshot that fails verification is always an error. One that can't be verified, because verification is
+// disabled or there's no L1 connection, is only an error if init.require-snapshot-verification is set.
+func checkSnapshotVerification(ctx context.Context, stack *node.Node, chainDb ethdb.Database, chainConfig *params.ChainConfig, cacheConfig *core.CacheConfig, initConfig *conf.InitConfig, persistentConfig *conf.PersistentConfig, l1Client arbutil.L1Interface, rollupAddrs chaininfo.RollupAddresses) error {
+	pending, err := chainDb.Has(snapshotVerificationPendingKey)
+	if err != nil || !pending {
+		return err
+	}
+	unverified := func(reason string) error {
+		if initConfig.RequireVerification {
+			return fmt.Errorf("database was initialized from a snapshot that hasn't been verified: %s", reason)
+		}
+		log.Warn("database was initialized from a snapshot that hasn't been verified", "reason", reason)
+		return nil
+	}
+	if !initConfig.VerifySnapshot {
+		return unverified("init.verify-snapshot is disabled")
+	}
+	if l1Client == nil || reflect.ValueOf(l1Client).IsNil() {
+		return unverified("an L1 connection is required to verify the inbox")
+	}
+	start := time.Now()
+	head := rawdb.ReadHeadHeader(chainDb)
+	if head == nil {
+		return errors.New("snapshot has no head block")
+	}
+	log.Info("verifying snapshot", "head", head.Number, "hash", head.Hash(), "root", head.Root)
+	if err := verifySnapshotInbox(ctx, stack, chainDb, chainConfig, head, persistentConfig, l1Client, rollupAddrs); err != nil {
+		return fmt.Errorf("snapshot verification failed: %w", err)
+	}
+	if err := verifySnapshotState(ctx, chainDb, cacheConfig, head); err != nil {
+		return fmt.Errorf("snapshot verification failed: %w", err)
+	}
+	log.Info("snapshot verified", "head", head.Number, "elapsed", time.Since(start))
+	return chainDb.Delete(snapshotVerificationPendingKey)
+}
+
+// verifySnapshotInbox checks the snapshot's latest batch accumulator, which commits to every batch before it,
+// and the delayed accumulator it read up to against the L1 contracts, and that the head block is on top of them.
+func verifySnapshotInbox(ctx context.Context, stack *node.Node, chainDb ethdb.Database, chainConfig *params.ChainConfig, head *types.Header, persistentConfig *conf.PersistentConfig, l1Client arbutil.L1Interface, rollupAddrs chaininfo.RollupAddresses) error {
+	arbDb, err := stack.OpenDatabaseWithExtraOptions("arbitrumdata", 0, 0, "arbitrumdata/", true, persistentConfig.Pebble.ExtraOptions("arbitrumdata"))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err := arbDb.Close()
+		if err != nil {
+			log.Warn("failed to close arbitrum database after verifying snapshot", "err", err)
+		}
+	}()
+	tracker, err := arbnode.NewInboxTracker(arbDb, nil, nil, nil, arbnode.DefaultSnapSyncConfig)
+	if err != nil {
+		return err
+	}
+	batchCount, err := tracker.GetBatchCount()
+	if err != nil {
+		return err
+	}
+	if batchCount == 0 {
+		return errors.New("snapshot has no batches")
+	}
+	lastBatch := batchCount - 1
+	meta, err := tracker.GetBatchMetadata(lastBatch)
+	if err != nil {
+		return err
+	}
+
+	// #nosec G115
+	sequencerInbox, err := arbnode.NewSequencerInbox(l1Client, rollupAddrs.SequencerInbox, int64(rollupAddrs.DeployedAt))
+	if err != nil {
+		return err
+	}
+	l1BatchCount, err := sequencerInbox.GetBatchCount(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if l1BatchCount < batchCount {
+		return fmt.Errorf("snapshot has %d batches but the L1 sequencer inbox only has %d", batchCount, l1BatchCount)
+	}
+	l1Acc, err := sequencerInbox.GetAccumulator(ctx, lastBatch, nil)
+	if err != nil {
+		return err
+	}
+	if l1Acc != meta.Accumulator {
+		return fmt.Errorf("snapshot accumulator %v for batch %d doesn't match L1 accumulator %v", meta.Accumulator, lastBatch, l1Acc)
+	}
+
+	if meta.DelayedMessageCount > 0 {
+		delayedBridge, err := arbnode.NewDelayedBridge(l1Client, rollupAddrs.Bridge, rollupAddrs.DeployedAt)
+		if err != nil {
+			return err
+		}
+		lastDelayed := meta.DelayedMessageCount - 1
+		delayedAcc, err := tracker.GetDelayedAcc(lastDelayed)
+		if err != nil {
+			return err
+		}
+		l1DelayedAcc, err := delayedBridge.GetAccumulator(ctx, lastDelayed, nil, common.Hash{})
+		if err != nil {
+			return err
+		}
+		if l1DelayedAcc != delayedAcc {
+			return fmt.Errorf("snapshot delayed accumulator %v for message %d doesn't match L1 accumulator %v", delayedAcc, lastDelayed, l1DelayedAcc)
+		}
+	}
+
+	// the last batched block must be canonical, and the head can't be before it
+	// #nosec G115
+	batchedBlock := uint64(arbutil.MessageCountToBlockNumber(meta.MessageCount, chainConfig.ArbitrumChainParams.GenesisBlockNum))
+	if head.Number.Uint64() < batchedBlock {
+		return fmt.Errorf("snapshot head %d is before block %d of the last batch", head.Number, batchedBlock)
+	}
+	if rawdb.ReadCanonicalHash(chainDb, batchedBlock) == (common.Hash{}) {
+		return fmt.Errorf("snapshot is missing block %d of the last batch", batchedBlock)
+	}
+	log.Info("verified snapshot inbox against L1", "batch", lastBatch, "accumulator", l1Acc, "delayedMessages", meta.DelayedMessageCount)
+	return verifySnapshotConfirmed(ctx, chainDb, chainConfig, head, tracker, batchCount, l1Client, rollupAddrs)
+}
+
+// verifySnapshotConfirmed checks the snapshot against the latest confirmed assertion it contains, which is the
+// rollup's latest confirmed assertion unless the snapshot is older. The assertion's block must be canonical in
+// the snapshot and have the assertion's send root.
+func verifySnapshotConfirmed(ctx context.Context, chainDb ethdb.Database, chainConfig *params.ChainConfig, head *types.Header, tracker *arbnode.InboxTracker, batchCount uint64, l1Client arbutil.L1Interface, rollupAddrs chaininfo.RollupAddresses) error {
+	callOpts := bind.CallOpts{
+		Context:     ctx,
+		BlockNumber: big.NewInt(int64(rpc.FinalizedBlockNumber)),
+	}
+	rollup, err := staker.NewRollupWatcher(rollupAddrs.Rollup, l1Client, callOpts)
+	if err != nil {
+		return err
+	}
+	nodeNum, err := rollup.LatestConfirmed(&callOpts)
+	if err != nil {
+		return err
+	}
+	for {
+		if nodeNum == 0 {
+			// the genesis assertion commits to nothing the inbox check hasn't
+			log.Warn("snapshot contains no confirmed assertion to verify against")
+			return nil
+		}
+		node, err := rollup.LookupNode(ctx, nodeNum)
+		if err != nil {
+			return err
+		}
+		globalState := node.Assertion.AfterState.GlobalState
+		if globalState.Batch < batchCount {
+			msgCount := arbutil.MessageIndex(globalState.PosInBatch)
+			if globalState.Batch > 0 {
+				prevMsgCount, err := tracker.GetBatchMessageCount(globalState.Batch - 1)
+				if err != nil {
+					return err
+				}
+				msgCount += prevMsgCount
+			}
+			// #nosec G115
+			blockNum := uint64(arbutil.MessageCountToBlockNumber(msgCount, chainConfig.ArbitrumChainParams.GenesisBlockNum))
+			if blockNum <= head.Number.Uint64() {
+				canonical := rawdb.ReadCanonicalHash(chainDb, blockNum)
+				if canonical != globalState.BlockHash {
+					return fmt.Errorf("snapshot block %d has hash %v but confirmed assertion %d has %v", blockNum, canonical, nodeNum, globalState.BlockHash)
+				}
+				header := rawdb.ReadHeader(chainDb, canonical, blockNum)
+				if header == nil {
+					return fmt.Errorf("snapshot is missing the header of block %d", blockNum)
+				}
+				sendRoot := types.DeserializeHeaderExtraInformation(header).SendRoot
+				if sendRoot != globalState.SendRoot {
+					return fmt.Errorf("snapshot block %d has send root %v but confirmed assertion %d has %v", blockNum, sendRoot, nodeNum, globalState.SendRoot)
+				}
+				log.Info("verified snapshot against confirmed assertion", "assertion", nodeNum, "block", blockNum, "hash", canonical, "sendRoot", sendRoot)
+				return nil
+			}
+		}
+		// the snapshot is older than this assertion, so try the one it builds on
+		contractNode, err := rollup.GetNode(&callOpts, nodeNum)
+		if err != nil {
+			return err
+		}
+		nodeNum = contractNode.PrevNum
+	}
+}
+
+// verifySnapshotState recomputes the head's state root from the leaves of its account and storage tries, and
+// checks every account's code against its code hash.
+func verifySnapshotState(ctx context.Context, chainDb ethdb.Database, cacheConfig *core.CacheConfig, head *types.Header) error {
+	trieDb := triedb.NewDatabase(chainDb, cacheConfig.TriedbConfig())
+	defer trieDb.Close()
+	accountTrie, err := trie.New(trie.StateTrieID(head.Root), trieDb)
+	if err != nil {
+		return err
+	}
+	nodeIt, err := accountTrie.NodeIterator(nil)
+	if err != nil {
+		return err
+	}
+	accountIt := trie.NewIterator(nodeIt)
+	accountsRoot := trie.NewStackTrie(nil)
+	accounts := 0
+	logged := time.Now()
+	for accountIt.Next() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		var account types.StateAccount
+		if err := rlp.DecodeBytes(accountIt.Value, &account); err != nil {
+			return fmt.Errorf("failed to decode account %x: %w", accountIt.Key, err)
+		}
+		accountHash := common.BytesToHash(accountIt.Key)
+		storageRoot, err := recomputeStorageRoot(trieDb, head.Root, accountHash, account.Root)
+		if err != nil {
+			return err
+		}
+		if storageRoot != account.Root {
+			return fmt.Errorf("account %v has storage root %v but its storage hashes to %v", accountHash, account.Root, storageRoot)
+		}
+		if codeHash := common.BytesToHash(account.CodeHash); codeHash != types.EmptyCodeHash {
+			code := rawdb.ReadCode(chainDb, codeHash)
+			if len(code) == 0 {
+				return fmt.Errorf("account %v is missing code %v", accountHash, codeHash)
+			}
+			if crypto.Keccak256Hash(code) != codeHash {
+				return fmt.Errorf("account %v has code that doesn't match its code hash %v", accountHash, codeHash)
+			}
+		}
+		if err := accountsRoot.Update(accountIt.Key, accountIt.Value); err != nil {
+			return err
+		}
+		accounts++
+		if time.Since(logged) > time.Minute {
+			log.Info("verifying snapshot state", "accounts", accounts, "position", accountHash)
+			logged = time.Now()
+		}
+	}
+	if accountIt.Err != nil {
+		return accountIt.Err
+	}
+	if root := accountsRoot.Hash(); root != head.Root {
+		return fmt.Errorf("snapshot state hashes to %v but head block %d has root %v", root, head.Number, head.Root)
+	}
+	log.Info("verified snapshot state", "accounts", accounts, "root", head.Root)
+	return nil
+}
+
+func recomputeStorageRoot(trieDb *triedb.Database, stateRoot common.Hash, accountHash common.Hash, storageRoot common.Hash) (common.Hash, error) {
+	if storageRoot == types.EmptyRootHash {
+		return types.EmptyRootHash, nil
+	}
+	storageTrie, err := trie.New(trie.StorageTrieID(stateRoot, accountHash, storageRoot), trieDb)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	nodeIt, err := storageTrie.NodeIterator(nil)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	slotIt := trie.NewIterator(nodeIt)
+	recomputed := trie.NewStackTrie(nil)
+	for slotIt.Next() {
+		if err := recomputed.Update(slotIt.Key, slotIt.Value); err != nil {
+			return common.Hash{}, err
+		}
+	}
+	if slotIt.Err != nil {
+		return common.Hash{}, slotIt.Err
+	}
+	return recomputed.Hash(), nil
+}
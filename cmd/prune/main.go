@@ -0,0 +1,184 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+// prune prunes the state of a stopped nitro node's database, the same way the node does on startup with
+// --init.prune, without starting the node.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/node"
+	flag "github.com/spf13/pflag"
+
+	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/cmd/chaininfo"
+	"github.com/offchainlabs/nitro/cmd/conf"
+	"github.com/offchainlabs/nitro/cmd/genericconf"
+	"github.com/offchainlabs/nitro/cmd/pruning"
+	"github.com/offchainlabs/nitro/cmd/util/confighelpers"
+	"github.com/offchainlabs/nitro/execution/gethexec"
+)
+
+type PruneConfig struct {
+	Persistent     conf.PersistentConfig `koanf:"persistent"`
+	Mode           string                `koanf:"mode"`
+	BloomSize      uint64                `koanf:"bloom-size"`
+	Threads        int                   `koanf:"threads"`
+	TrieCleanCache int                   `koanf:"trie-clean-cache"`
+	ParentChainUrl string                `koanf:"parent-chain-url"`
+	RollupAddress  string                `koanf:"rollup-address"`
+	Validator      bool                  `koanf:"validator"`
+	LogLevel       string                `koanf:"log-level"`
+	LogType        string                `koanf:"log-type"`
+}
+
+var DefaultPruneConfig = PruneConfig{
+	Persistent:     conf.PersistentConfigDefault,
+	Mode:           pruning.ModeFull,
+	BloomSize:      conf.InitConfigDefault.PruneBloomSize,
+	Threads:        conf.InitConfigDefault.PruneThreads,
+	TrieCleanCache: conf.InitConfigDefault.PruneTrieCleanCache,
+	ParentChainUrl: "",
+	RollupAddress:  "",
+	Validator:      false,
+	LogLevel:       "INFO",
+	LogType:        "plaintext",
+}
+
+func PruneConfigAddOptions(f *flag.FlagSet) {
+	conf.PersistentConfigAddOptions("persistent", f)
+	f.String("mode", DefaultPruneConfig.Mode, "pruning mode: \"full\", \"validator\", \"validator-minimal\", \"archive\", or a comma separated list of state roots to keep")
+	f.Uint64("bloom-size", DefaultPruneConfig.BloomSize, "the amount of memory in megabytes to use for the pruning bloom filter (higher values prune better)")
+	f.Int("threads", DefaultPruneConfig.Threads, "the number of threads to use when pruning")
+	f.Int("trie-clean-cache", DefaultPruneConfig.TrieCleanCache, "amount of memory in megabytes to cache unchanged state trie nodes with when traversing state database during pruning")
+	f.String("parent-chain-url", DefaultPruneConfig.ParentChainUrl, "parent chain RPC url, used to find the latest finalized block and, for the validator modes, the latest confirmed block")
+	f.String("rollup-address", DefaultPruneConfig.RollupAddress, "address of the chain's rollup contract (required for the validator modes)")
+	f.Bool("validator", DefaultPruneConfig.Validator, "the node runs a validator (refuses to prune to full-node level)")
+	f.String("log-level", DefaultPruneConfig.LogLevel, "log level, valid values are CRIT, ERROR, WARN, INFO, DEBUG, TRACE")
+	f.String("log-type", DefaultPruneConfig.LogType, "log type (plaintext or json)")
+}
+
+func (c *PruneConfig) Validate() error {
+	if err := pruning.ValidateMode(c.Mode); err != nil {
+		return err
+	}
+	if c.Mode == "" {
+		return errors.New("a pruning mode is required")
+	}
+	if c.Threads <= 0 {
+		return fmt.Errorf("invalid number of pruning threads: %d, has to be greater then 0", c.Threads)
+	}
+	if c.TrieCleanCache < 0 {
+		return fmt.Errorf("invalid trie clean cache size: %d, has to be greater or equal 0", c.TrieCleanCache)
+	}
+	if c.Mode == pruning.ModeValidator || c.Mode == pruning.ModeValidatorMinimal {
+		if c.ParentChainUrl == "" || !common.IsHexAddress(c.RollupAddress) {
+			return fmt.Errorf("pruning mode %v requires parent-chain-url and rollup-address", c.Mode)
+		}
+	}
+	return c.Persistent.Validate()
+}
+
+func (c *PruneConfig) initConfig() *conf.InitConfig {
+	initConfig := conf.InitConfigDefault
+	initConfig.Prune = c.Mode
+	initConfig.PruneBloomSize = c.BloomSize
+	initConfig.PruneThreads = c.Threads
+	initConfig.PruneTrieCleanCache = c.TrieCleanCache
+	return &initConfig
+}
+
+func parsePrune(args []string) (*PruneConfig, error) {
+	f := flag.NewFlagSet("prune", flag.ContinueOnError)
+	PruneConfigAddOptions(f)
+	k, err := confighelpers.BeginCommonParse(f, args)
+	if err != nil {
+		return nil, err
+	}
+	var config PruneConfig
+	if err := confighelpers.EndCommonParse(k, &config); err != nil {
+		return nil, err
+	}
+	if err := config.Persistent.ResolveDirectoryNames(); err != nil {
+		return nil, err
+	}
+	return &config, config.Validate()
+}
+
+func printSampleUsage(name string) {
+	fmt.Printf("Sample usage: %s --persistent.chain=<chain directory> --mode=full\n\n", name)
+}
+
+func main() {
+	config, err := parsePrune(os.Args[1:])
+	if err != nil {
+		confighelpers.PrintErrorAndExit(err, printSampleUsage)
+	}
+	err = genericconf.InitLog(config.LogType, config.LogLevel, &genericconf.FileLoggingConfig{Enable: false}, nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing logging: %v\n", err)
+		os.Exit(1)
+	}
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	if err := prune(ctx, config); err != nil {
+		log.Error("pruning failed", "err", err)
+		os.Exit(1)
+	}
+}
+
+func prune(ctx context.Context, config *PruneConfig) error {
+	stackConf := node.DefaultConfig
+	stackConf.Name = "nitro"
+	stackConf.DataDir = config.Persistent.Chain
+	stackConf.DBEngine = config.Persistent.DBEngine
+	stackConf.P2P.ListenAddr = ""
+	stackConf.P2P.NoDial = true
+	stackConf.P2P.NoDiscovery = true
+	stack, err := node.New(&stackConf)
+	if err != nil {
+		return err
+	}
+	defer stack.Close()
+
+	chainDb, err := stack.OpenDatabaseWithFreezerWithExtraOptions("l2chaindata", 0, config.Persistent.Handles, config.Persistent.Ancient, "l2chaindata/", false, config.Persistent.Pebble.ExtraOptions("l2chaindata"))
+	if err != nil {
+		return err
+	}
+	defer chainDb.Close()
+	if gethexec.TryReadStoredChainConfig(chainDb) == nil {
+		return fmt.Errorf("no nitro database found in %v", stack.InstanceDir())
+	}
+	stateScheme, err := rawdb.ParseStateScheme("", chainDb)
+	if err != nil {
+		return err
+	}
+	if stateScheme == rawdb.PathScheme {
+		return errors.New("databases using the path state scheme keep only recent state and can't be pruned")
+	}
+	cachingConfig := gethexec.DefaultCachingConfig
+	cachingConfig.StateScheme = stateScheme
+	cacheConfig := gethexec.DefaultCacheConfigFor(stack, &cachingConfig)
+
+	var l1Client arbutil.L1Interface
+	if config.ParentChainUrl != "" {
+		client, err := ethclient.DialContext(ctx, config.ParentChainUrl)
+		if err != nil {
+			return fmt.Errorf("failed to connect to the parent chain: %w", err)
+		}
+		defer client.Close()
+		l1Client = client
+	}
+	rollupAddrs := chaininfo.RollupAddresses{Rollup: common.HexToAddress(config.RollupAddress)}
+	return pruning.PruneChainDb(ctx, chainDb, stack, config.initConfig(), cacheConfig, &config.Persistent, l1Client, rollupAddrs, config.Validator)
+}
@@ -4,10 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io/fs"
 	"math/big"
+	"path/filepath"
 	"reflect"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
@@ -79,6 +82,30 @@ func (r *importantRoots) addHeader(header *types.Header, overwrite bool) error {
 
 var hashListRegex = regexp.MustCompile("^(0x)?[0-9a-fA-F]{64}(,(0x)?[0-9a-fA-F]{64})*$")
 
+const (
+	// ModeArchive keeps all state; it only finishes an interrupted pruning
+	ModeArchive = "archive"
+	// ModeFull keeps the states a full node serving RPC requests needs: genesis, the latest finalized block, and the head
+	ModeFull = "full"
+	// ModeValidator keeps everything ModeFull does, plus the latest confirmed and latest validated blocks
+	ModeValidator = "validator"
+	// ModeValidatorMinimal keeps only the states a validator needs to resume: genesis, the latest confirmed
+	// and latest validated blocks, and the head
+	ModeValidatorMinimal = "validator-minimal"
+)
+
+// ValidateMode checks the mode is "" (don't prune), one of the pruning modes, or a comma separated list of roots.
+func ValidateMode(mode string) error {
+	switch mode {
+	case "", ModeArchive, ModeFull, ModeValidator, ModeValidatorMinimal:
+		return nil
+	}
+	if hashListRegex.MatchString(mode) {
+		return nil
+	}
+	return fmt.Errorf("unknown pruning mode: \"%v\"", mode)
+}
+
 // Finds important roots to retain while proving
 func findImportantRoots(ctx context.Context, chainDb ethdb.Database, stack *node.Node, initConfig *conf.InitConfig, cacheConfig *core.CacheConfig, persistentConfig *conf.PersistentConfig, l1Client arbutil.L1Interface, rollupAddrs chaininfo.RollupAddresses, validatorRequired bool) ([]common.Hash, error) {
 	chainConfig := gethexec.TryReadStoredChainConfig(chainDb)
@@ -108,7 +135,7 @@ func findImportantRoots(ctx context.Context, chainDb ethdb.Database, stack *node
 	if err != nil {
 		return nil, err
 	}
-	if initConfig.Prune == "validator" {
+	if initConfig.Prune == ModeValidator || initConfig.Prune == ModeValidatorMinimal {
 		if l1Client == nil || reflect.ValueOf(l1Client).IsNil() {
 			return nil, errors.New("an L1 connection is required for validator pruning")
 		}
@@ -163,7 +190,7 @@ func findImportantRoots(ctx context.Context, chainDb ethdb.Database, stack *node
 				log.Warn("missing latest validated block", "hash", lastValidated.GlobalState.BlockHash)
 			}
 		}
-	} else if initConfig.Prune == "full" {
+	} else if initConfig.Prune == ModeFull {
 		if validatorRequired {
 			return nil, errors.New("refusing to prune to full-node level when validator is enabled (you should prune in validator mode)")
 		}
@@ -182,14 +209,14 @@ func findImportantRoots(ctx context.Context, chainDb ethdb.Database, stack *node
 	} else {
 		return nil, fmt.Errorf("unknown pruning mode: \"%v\"", initConfig.Prune)
 	}
-	if l1Client != nil {
+	if l1Client != nil && initConfig.Prune != ModeValidatorMinimal {
 		// Find the latest finalized block and add it as a pruning target
 		l1Block, err := l1Client.BlockByNumber(ctx, big.NewInt(int64(rpc.FinalizedBlockNumber)))
 		if err != nil {
 			return nil, fmt.Errorf("failed to get finalized block: %w", err)
 		}
 		l1BlockNum := l1Block.NumberU64()
-		tracker, err := arbnode.NewInboxTracker(arbDb, nil, nil, arbnode.DefaultSnapSyncConfig)
+		tracker, err := arbnode.NewInboxTracker(arbDb, nil, nil, nil, arbnode.DefaultSnapSyncConfig)
 		if err != nil {
 			return nil, err
 		}
@@ -237,9 +264,13 @@ func PruneChainDb(ctx context.Context, chainDb ethdb.Database, stack *node.Node,
 		return nil
 	}
 
-	if initConfig.Prune == "" {
+	if initConfig.Prune == "" || initConfig.Prune == ModeArchive {
 		return pruner.RecoverPruning(stack.InstanceDir(), chainDb, initConfig.PruneThreads)
 	}
+	start := time.Now()
+	chainDataDir := filepath.Join(stack.InstanceDir(), "l2chaindata")
+	sizeBefore := directorySize(chainDataDir)
+	log.Info("pruning state", "mode", initConfig.Prune, "databaseSize", sizeBefore)
 	root, err := findImportantRoots(ctx, chainDb, stack, initConfig, cacheConfig, persistentConfig, l1Client, rollupAddrs, validatorRequired)
 	if err != nil {
 		return fmt.Errorf("failed to find root to retain for pruning: %w", err)
@@ -249,5 +280,30 @@ func PruneChainDb(ctx context.Context, chainDb ethdb.Database, stack *node.Node,
 	if err != nil {
 		return err
 	}
-	return pruner.Prune(root)
+	if err := pruner.Prune(root); err != nil {
+		return err
+	}
+	sizeAfter := directorySize(chainDataDir)
+	log.Info("pruned state", "mode", initConfig.Prune, "elapsed", time.Since(start), "databaseSizeBefore", sizeBefore, "databaseSizeAfter", sizeAfter, "freed", common.StorageSize(float64(sizeBefore)-float64(sizeAfter)))
+	return nil
+}
+
+// directorySize returns the total size of the files under dir, or 0 if it can't be read. It's only used for reporting.
+func directorySize(dir string) common.StorageSize {
+	var size int64
+	err := filepath.WalkDir(dir, func(_ string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		size += info.Size()
+		return nil
+	})
+	if err != nil {
+		log.Debug("failed to measure database size", "dir", dir, "err", err)
+	}
+	return common.StorageSize(size)
 }
@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"slices"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus"
@@ -125,6 +126,15 @@ func (dasReader *PreimageDASReader) ExpirationPolicy(ctx context.Context) (dapro
 	return daprovider.DiscardImmediately, nil
 }
 
+// PreimageDictionaryReader resolves batch dictionaries as keccak256 preimages, which the validator records
+// from the dictionaries its node was configured with.
+type PreimageDictionaryReader struct {
+}
+
+func (r *PreimageDictionaryReader) GetDictionary(ctx context.Context, hash common.Hash, preimageRecorder daprovider.PreimageRecorder) ([]byte, error) {
+	return wavmio.ResolveTypedPreimage(arbutil.Keccak256PreimageType, hash)
+}
+
 type BlobPreimageReader struct {
 }
 
@@ -210,7 +220,7 @@ func main() {
 		}
 		return wavmio.ReadInboxMessage(batchNum), nil
 	}
-	readMessage := func(dasEnabled bool) *arbostypes.MessageWithMetadata {
+	readMessage := func(dasEnabled bool, dictionaries daprovider.DictionaryReader) *arbostypes.MessageWithMetadata {
 		var delayedMessagesRead uint64
 		if lastBlockHeader != nil {
 			delayedMessagesRead = lastBlockHeader.Nonce.Uint64()
@@ -232,7 +242,7 @@ func main() {
 			dapReaders = append(dapReaders, daprovider.NewReaderForDAS(dasReader, dasKeysetFetcher))
 		}
 		dapReaders = append(dapReaders, daprovider.NewReaderForBlobReader(&BlobPreimageReader{}))
-		inboxMultiplexer := arbstate.NewInboxMultiplexer(backend, delayedMessagesRead, dapReaders, keysetValidationMode)
+		inboxMultiplexer := arbstate.NewInboxMultiplexer(backend, delayedMessagesRead, dapReaders, dictionaries, keysetValidationMode)
 		ctx := context.Background()
 		message, err := inboxMultiplexer.Pop(ctx)
 		if err != nil {
@@ -288,7 +298,18 @@ func main() {
 			}
 		}
 
-		message := readMessage(chainConfig.ArbitrumChainParams.DataAvailabilityCommittee)
+		dictionaries := &daprovider.CommittedDictionaries{
+			// the validator records the committed dictionaries along with the block
+			Committed: func(hash common.Hash) (bool, error) {
+				if initialArbosState.ArbOSVersion() < arbosState.ArbosVersion_BatchDictionaries {
+					return false, nil
+				}
+				committed, err := initialArbosState.BatchDictionaries().All()
+				return slices.Contains(committed, hash), err
+			},
+			Reader: &PreimageDictionaryReader{},
+		}
+		message := readMessage(chainConfig.ArbitrumChainParams.DataAvailabilityCommittee, dictionaries)
 
 		chainContext := WavmChainContext{}
 		newBlock, _, err = arbos.ProduceBlock(message.Message, message.DelayedMessagesRead, lastBlockHeader, statedb, chainContext, chainConfig, false)
@@ -298,7 +319,11 @@ func main() {
 	} else {
 		// Initialize ArbOS with this init message and create the genesis block.
 
-		message := readMessage(false)
+		// there's no state the chain could have committed to a dictionary in yet
+		noDictionaries := &daprovider.CommittedDictionaries{
+			Committed: func(common.Hash) (bool, error) { return false, nil },
+		}
+		message := readMessage(false, noDictionaries)
 
 		initMessage, err := message.Message.ParseInitMessage()
 		if err != nil {
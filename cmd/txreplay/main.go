@@ -0,0 +1,335 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+// txreplay re-executes the block containing a transaction from a stopped archive node's database, with the same
+// ArbOS logic the node ran, and dumps every call, storage access, ArbOS transfer and precompile call of the
+// transaction as JSON, along with whether the replay reproduced the block.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/offchainlabs/nitro/arbos"
+	"github.com/offchainlabs/nitro/arbos/arbosState"
+	"github.com/offchainlabs/nitro/cmd/conf"
+	"github.com/offchainlabs/nitro/cmd/genericconf"
+	"github.com/offchainlabs/nitro/cmd/util/confighelpers"
+	"github.com/offchainlabs/nitro/execution/gethexec"
+	"github.com/offchainlabs/nitro/gethhook"
+	"github.com/offchainlabs/nitro/precompiles"
+)
+
+type ReplayConfig struct {
+	Persistent conf.PersistentConfig `koanf:"persistent"`
+	Tx         string                `koanf:"tx"`
+	Output     string                `koanf:"output"`
+	AllTxs     bool                  `koanf:"all-txs"`
+	Steps      bool                  `koanf:"steps"`
+	LogLevel   string                `koanf:"log-level"`
+	LogType    string                `koanf:"log-type"`
+}
+
+var DefaultReplayConfig = ReplayConfig{
+	Persistent: conf.PersistentConfigDefault,
+	Tx:         "",
+	Output:     "",
+	AllTxs:     false,
+	Steps:      false,
+	LogLevel:   "INFO",
+	LogType:    "plaintext",
+}
+
+func ReplayConfigAddOptions(f *flag.FlagSet) {
+	conf.PersistentConfigAddOptions("persistent", f)
+	f.String("tx", DefaultReplayConfig.Tx, "hash of the transaction to replay")
+	f.String("output", DefaultReplayConfig.Output, "file the JSON report is written to (stdout if empty)")
+	f.Bool("all-txs", DefaultReplayConfig.AllTxs, "trace every transaction of the block, not just the given one")
+	f.Bool("steps", DefaultReplayConfig.Steps, "also record every EVM step and Stylus hostio of the traced transactions, which makes the report much larger")
+	f.String("log-level", DefaultReplayConfig.LogLevel, "log level, valid values are CRIT, ERROR, WARN, INFO, DEBUG, TRACE")
+	f.String("log-type", DefaultReplayConfig.LogType, "log type (plaintext or json)")
+}
+
+func (c *ReplayConfig) Validate() error {
+	if len(common.FromHex(c.Tx)) != common.HashLength {
+		return fmt.Errorf("invalid transaction hash %q", c.Tx)
+	}
+	return c.Persistent.Validate()
+}
+
+// TxResult compares the replay of a transaction of the block with its stored receipt.
+type TxResult struct {
+	Index                     int         `json:"index"`
+	Hash                      common.Hash `json:"hash"`
+	Type                      uint8       `json:"type"`
+	Status                    uint64      `json:"status"`
+	CumulativeGasUsed         uint64      `json:"cumulativeGasUsed"`
+	ExpectedStatus            uint64      `json:"expectedStatus"`
+	ExpectedCumulativeGasUsed uint64      `json:"expectedCumulativeGasUsed"`
+	Matches                   bool        `json:"matches"`
+	Error                     string      `json:"error,omitempty"`
+	Trace                     *TxTrace    `json:"trace,omitempty"`
+}
+
+type Report struct {
+	TxHash      common.Hash `json:"txHash"`
+	TxIndex     uint64      `json:"txIndex"`
+	BlockNumber uint64      `json:"blockNumber"`
+	BlockHash   common.Hash `json:"blockHash"`
+	// the ArbOS version of the parent block's state, and the one the block ended with, which differ if it upgraded ArbOS
+	ParentArbOSVersion uint64      `json:"parentArbosVersion"`
+	BlockArbOSVersion  uint64      `json:"blockArbosVersion"`
+	StateRoot          common.Hash `json:"stateRoot"`
+	ExpectedStateRoot  common.Hash `json:"expectedStateRoot"`
+	Reproduced         bool        `json:"reproduced"`
+	Txs                []TxResult  `json:"txs"`
+}
+
+var errNotReproduced = errors.New("replaying the block didn't reproduce it")
+
+func parseReplay(args []string) (*ReplayConfig, error) {
+	f := flag.NewFlagSet("txreplay", flag.ContinueOnError)
+	ReplayConfigAddOptions(f)
+	k, err := confighelpers.BeginCommonParse(f, args)
+	if err != nil {
+		return nil, err
+	}
+	var config ReplayConfig
+	if err := confighelpers.EndCommonParse(k, &config); err != nil {
+		return nil, err
+	}
+	if err := config.Persistent.ResolveDirectoryNames(); err != nil {
+		return nil, err
+	}
+	return &config, config.Validate()
+}
+
+func printSampleUsage(name string) {
+	fmt.Printf("Sample usage: %s --persistent.chain=<archive node chain directory> --tx=<transaction hash>\n\n", name)
+}
+
+func main() {
+	config, err := parseReplay(os.Args[1:])
+	if err != nil {
+		confighelpers.PrintErrorAndExit(err, printSampleUsage)
+	}
+	err = genericconf.InitLog(config.LogType, config.LogLevel, &genericconf.FileLoggingConfig{Enable: false}, nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing logging: %v\n", err)
+		os.Exit(1)
+	}
+	report, err := replay(config)
+	if err != nil {
+		log.Error("replay failed", "err", err)
+		os.Exit(1)
+	}
+	if err := writeReport(config.Output, report); err != nil {
+		log.Error("failed to write report", "err", err)
+		os.Exit(1)
+	}
+	if !report.Reproduced {
+		log.Error(errNotReproduced.Error(), "block", report.BlockNumber, "stateRoot", report.StateRoot, "expectedStateRoot", report.ExpectedStateRoot)
+		os.Exit(1)
+	}
+}
+
+type replayChainContext struct {
+	db ethdb.Database
+}
+
+func (c *replayChainContext) Engine() consensus.Engine {
+	return arbos.Engine{}
+}
+
+func (c *replayChainContext) GetHeader(hash common.Hash, number uint64) *types.Header {
+	return rawdb.ReadHeader(c.db, hash, number)
+}
+
+func replay(config *ReplayConfig) (*Report, error) {
+	stackConf := node.DefaultConfig
+	stackConf.Name = "nitro"
+	stackConf.DataDir = config.Persistent.Chain
+	stackConf.DBEngine = config.Persistent.DBEngine
+	stackConf.P2P.ListenAddr = ""
+	stackConf.P2P.NoDial = true
+	stackConf.P2P.NoDiscovery = true
+	stack, err := node.New(&stackConf)
+	if err != nil {
+		return nil, err
+	}
+	defer stack.Close()
+
+	chainData, err := stack.OpenDatabaseWithFreezerWithExtraOptions("l2chaindata", 0, config.Persistent.Handles, config.Persistent.Ancient, "l2chaindata/", true, config.Persistent.Pebble.ExtraOptions("l2chaindata"))
+	if err != nil {
+		return nil, err
+	}
+	defer chainData.Close()
+	chainConfig := gethexec.TryReadStoredChainConfig(chainData)
+	if chainConfig == nil {
+		return nil, fmt.Errorf("no nitro database found in %v", stack.InstanceDir())
+	}
+	wasmDb, err := stack.OpenDatabaseWithExtraOptions("wasm", 0, config.Persistent.Handles, "wasm/", true, config.Persistent.Pebble.ExtraOptions("wasm"))
+	if err != nil {
+		return nil, err
+	}
+	defer wasmDb.Close()
+	chainDb := rawdb.WrapDatabaseWithWasm(chainData, wasmDb, 1, []ethdb.WasmTarget{rawdb.LocalTarget()})
+
+	stateScheme, err := rawdb.ParseStateScheme("", chainDb)
+	if err != nil {
+		return nil, err
+	}
+	cachingConfig := gethexec.DefaultCachingConfig
+	cachingConfig.StateScheme = stateScheme
+	cacheConfig := gethexec.DefaultCacheConfigFor(stack, &cachingConfig)
+	stateDatabase := state.NewDatabaseWithConfig(chainDb, cacheConfig.TriedbConfig())
+	defer stateDatabase.TrieDB().Close()
+
+	txHash := common.HexToHash(config.Tx)
+	tx, blockHash, blockNumber, txIndex := rawdb.ReadTransaction(chainDb, txHash)
+	if tx == nil {
+		return nil, fmt.Errorf("transaction %v not found, the node may not index it", txHash)
+	}
+	if blockNumber <= chainConfig.ArbitrumChainParams.GenesisBlockNum {
+		return nil, fmt.Errorf("transaction %v is in the genesis block, which can't be replayed", txHash)
+	}
+	block := rawdb.ReadBlock(chainDb, blockHash, blockNumber)
+	if block == nil {
+		return nil, fmt.Errorf("block %v containing transaction %v not found", blockNumber, txHash)
+	}
+	parent := rawdb.ReadHeader(chainDb, block.ParentHash(), blockNumber-1)
+	if parent == nil {
+		return nil, fmt.Errorf("parent of block %v not found", blockNumber)
+	}
+	statedb, err := state.New(parent.Root, stateDatabase, nil)
+	if err != nil {
+		return nil, fmt.Errorf("state of block %v is missing, replaying needs an archive node's database: %w", blockNumber-1, err)
+	}
+	parentArbosState, err := arbosState.OpenSystemArbosState(statedb, nil, true)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info("replaying block", "block", blockNumber, "txs", len(block.Transactions()), "txIndex", txIndex)
+	report := &Report{
+		TxHash:             txHash,
+		TxIndex:            txIndex,
+		BlockNumber:        blockNumber,
+		BlockHash:          blockHash,
+		ParentArbOSVersion: parentArbosState.ArbOSVersion(),
+		BlockArbOSVersion:  types.DeserializeHeaderExtraInformation(block.Header()).ArbOSFormatVersion,
+		ExpectedStateRoot:  block.Root(),
+	}
+	receipts := rawdb.ReadRawReceipts(chainDb, blockHash, blockNumber)
+	report.Txs = replayBlock(config, chainConfig, &replayChainContext{chainDb}, statedb, block, receipts, txIndex)
+	report.StateRoot = statedb.IntermediateRoot(true)
+	report.Reproduced = report.StateRoot == report.ExpectedStateRoot
+	for _, result := range report.Txs {
+		report.Reproduced = report.Reproduced && result.Matches
+	}
+	return report, nil
+}
+
+// replayBlock applies the block's transactions the way the node's block processor does, tracing the one at txIndex
+// or all of them.
+func replayBlock(config *ReplayConfig, chainConfig *params.ChainConfig, chainContext core.ChainContext, statedb *state.StateDB, block *types.Block, receipts types.Receipts, txIndex uint64) []TxResult {
+	contracts := precompiles.Precompiles()
+	header := block.Header()
+	gasPool := new(core.GasPool).AddGas(block.GasLimit())
+	var usedGas uint64
+	results := make([]TxResult, 0, len(block.Transactions()))
+	for i, tx := range block.Transactions() {
+		result := TxResult{
+			Index: i,
+			Hash:  tx.Hash(),
+			Type:  tx.Type(),
+		}
+		var tracer *forensicTracer
+		var before *state.StateDB
+		vmConfig := vm.Config{}
+		// #nosec G115
+		if config.AllTxs || uint64(i) == txIndex {
+			tracer = newForensicTracer(contracts, config.Steps)
+			vmConfig.Tracer = tracer
+			before = statedb.Copy()
+		}
+		statedb.SetTxContext(tx.Hash(), i)
+		receipt, err := core.ApplyTransaction(chainConfig, chainContext, &header.Coinbase, gasPool, statedb, header, tx, &usedGas, vmConfig)
+		if err != nil {
+			result.Error = err.Error()
+		} else {
+			result.Status = receipt.Status
+			result.CumulativeGasUsed = receipt.CumulativeGasUsed
+		}
+		if i < len(receipts) {
+			result.ExpectedStatus = receipts[i].Status
+			result.ExpectedCumulativeGasUsed = receipts[i].CumulativeGasUsed
+			result.Matches = err == nil && result.Status == result.ExpectedStatus && result.CumulativeGasUsed == result.ExpectedCumulativeGasUsed
+		}
+		if tracer != nil {
+			tracer.trace.Accounts = accountChanges(before, statedb, tracer.touched())
+			result.Trace = &tracer.trace
+		}
+		if !result.Matches {
+			log.Warn("replayed transaction differs from its receipt", "index", i, "hash", result.Hash, "err", err)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+func accountChanges(before *state.StateDB, after *state.StateDB, addresses []common.Address) []AccountChange {
+	var changes []AccountChange
+	for _, address := range addresses {
+		change := AccountChange{
+			Address:        address,
+			BalanceBefore:  (*hexutil.Big)(before.GetBalance(address).ToBig()),
+			BalanceAfter:   (*hexutil.Big)(after.GetBalance(address).ToBig()),
+			NonceBefore:    before.GetNonce(address),
+			NonceAfter:     after.GetNonce(address),
+			CodeHashBefore: before.GetCodeHash(address),
+			CodeHashAfter:  after.GetCodeHash(address),
+		}
+		if change.BalanceBefore.ToInt().Cmp(change.BalanceAfter.ToInt()) != 0 || change.NonceBefore != change.NonceAfter || change.CodeHashBefore != change.CodeHashAfter {
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+// writeReport writes the report as JSON to path, or to stdout if path is empty.
+func writeReport(path string, report *Report) error {
+	encoded, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	encoded = append(encoded, '\n')
+	if path == "" {
+		_, err = os.Stdout.Write(encoded)
+		return err
+	}
+	// #nosec G306
+	return os.WriteFile(path, encoded, 0o644)
+}
+
+func init() {
+	// installs the ArbOS transaction processor and precompiles into the EVM
+	gethhook.RequireHookedGeth()
+}
@@ -0,0 +1,312 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package main
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+
+	"github.com/offchainlabs/nitro/arbos"
+	"github.com/offchainlabs/nitro/precompiles"
+)
+
+const (
+	phaseBeforeEVM = "before-evm"
+	phaseEVM       = "evm"
+	phaseAfterEVM  = "after-evm"
+)
+
+type CallFrame struct {
+	Depth      int            `json:"depth"`
+	Type       string         `json:"type"`
+	From       common.Address `json:"from"`
+	To         common.Address `json:"to"`
+	Input      hexutil.Bytes  `json:"input"`
+	Gas        uint64         `json:"gas"`
+	Value      *hexutil.Big   `json:"value,omitempty"`
+	Precompile string         `json:"precompile,omitempty"`
+	Method     string         `json:"method,omitempty"`
+	Output     hexutil.Bytes  `json:"output"`
+	GasUsed    uint64         `json:"gasUsed"`
+	Error      string         `json:"error,omitempty"`
+}
+
+// StorageAccess is a read or write of a storage slot. ArbOS storage is read and written by precompiles during the
+// EVM, in which case Address is the precompile, and by ArbOS itself before and after it. Its keys are relative to the
+// ArbOS storage subspace accessed, and the values of its reads aren't known.
+type StorageAccess struct {
+	Phase    string         `json:"phase"`
+	Depth    int            `json:"depth"`
+	Address  common.Address `json:"address"`
+	ArbOS    bool           `json:"arbos"`
+	Write    bool           `json:"write"`
+	Key      common.Hash    `json:"key"`
+	Value    *common.Hash   `json:"value,omitempty"`
+	Previous *common.Hash   `json:"previous,omitempty"`
+}
+
+// Transfer is a balance change ArbOS makes outside the EVM, such as fee payments and deposits.
+type Transfer struct {
+	Phase   string          `json:"phase"`
+	From    *common.Address `json:"from,omitempty"`
+	To      *common.Address `json:"to,omitempty"`
+	Value   *hexutil.Big    `json:"value"`
+	Purpose string          `json:"purpose"`
+}
+
+type Step struct {
+	PC    uint64 `json:"pc"`
+	Op    string `json:"op"`
+	Gas   uint64 `json:"gas"`
+	Cost  uint64 `json:"cost"`
+	Depth int    `json:"depth"`
+	Error string `json:"error,omitempty"`
+}
+
+type Hostio struct {
+	Name     string        `json:"name"`
+	Args     hexutil.Bytes `json:"args"`
+	Outs     hexutil.Bytes `json:"outs"`
+	StartInk uint64        `json:"startInk"`
+	EndInk   uint64        `json:"endInk"`
+}
+
+type AccountChange struct {
+	Address        common.Address `json:"address"`
+	BalanceBefore  *hexutil.Big   `json:"balanceBefore"`
+	BalanceAfter   *hexutil.Big   `json:"balanceAfter"`
+	NonceBefore    uint64         `json:"nonceBefore"`
+	NonceAfter     uint64         `json:"nonceAfter"`
+	CodeHashBefore common.Hash    `json:"codeHashBefore"`
+	CodeHashAfter  common.Hash    `json:"codeHashAfter"`
+}
+
+// TxTrace is everything a transaction did, in execution order within each list.
+type TxTrace struct {
+	Calls     []CallFrame     `json:"calls"`
+	Storage   []StorageAccess `json:"storage"`
+	Transfers []Transfer      `json:"transfers"`
+	Accounts  []AccountChange `json:"accounts"`
+	Steps     []Step          `json:"steps,omitempty"`
+	Hostios   []Hostio        `json:"hostios,omitempty"`
+
+	// RedeemParent is the tx that scheduled a retry tx's redeem, when the chain records it
+	RedeemParent *common.Hash `json:"redeemParent,omitempty"`
+}
+
+var _ arbos.RedeemParentTracer = (*forensicTracer)(nil)
+
+// forensicTracer records the calls, storage accesses and transfers of a transaction, including those ArbOS makes
+// outside the EVM and the ArbOS storage accesses of precompiles, which the standard tracers drop.
+type forensicTracer struct {
+	precompiles map[common.Address]precompiles.ArbosPrecompile
+	steps       bool
+
+	env   *vm.EVM
+	trace TxTrace
+	open  []int // indices of the calls that haven't returned yet
+}
+
+func newForensicTracer(contracts map[common.Address]precompiles.ArbosPrecompile, steps bool) *forensicTracer {
+	return &forensicTracer{
+		precompiles: contracts,
+		steps:       steps,
+	}
+}
+
+// touched returns the addresses the transaction may have changed the account of.
+func (t *forensicTracer) touched() []common.Address {
+	seen := make(map[common.Address]bool)
+	var addresses []common.Address
+	add := func(address *common.Address) {
+		if address != nil && !seen[*address] {
+			seen[*address] = true
+			addresses = append(addresses, *address)
+		}
+	}
+	for i := range t.trace.Calls {
+		add(&t.trace.Calls[i].From)
+		add(&t.trace.Calls[i].To)
+	}
+	for i := range t.trace.Transfers {
+		add(t.trace.Transfers[i].From)
+		add(t.trace.Transfers[i].To)
+	}
+	for i := range t.trace.Storage {
+		if !t.trace.Storage[i].ArbOS {
+			add(&t.trace.Storage[i].Address)
+		}
+	}
+	return addresses
+}
+
+func (t *forensicTracer) enter(typ string, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	frame := CallFrame{
+		Depth: len(t.open),
+		Type:  typ,
+		From:  from,
+		To:    to,
+		Input: common.CopyBytes(input),
+		Gas:   gas,
+	}
+	if value != nil {
+		frame.Value = (*hexutil.Big)(new(big.Int).Set(value))
+	}
+	if contract, ok := t.precompiles[to]; ok {
+		precompile := contract.Precompile()
+		frame.Precompile = precompile.Name()
+		if len(input) >= 4 {
+			frame.Method, _ = precompile.MethodName([4]byte(input[:4]))
+		}
+	}
+	t.open = append(t.open, len(t.trace.Calls))
+	t.trace.Calls = append(t.trace.Calls, frame)
+}
+
+func (t *forensicTracer) exit(output []byte, gasUsed uint64, err error) {
+	if len(t.open) == 0 {
+		return
+	}
+	frame := &t.trace.Calls[t.open[len(t.open)-1]]
+	t.open = t.open[:len(t.open)-1]
+	frame.Output = common.CopyBytes(output)
+	frame.GasUsed = gasUsed
+	if err != nil {
+		frame.Error = err.Error()
+	}
+}
+
+func (t *forensicTracer) CaptureTxStart(gasLimit uint64) {}
+
+func (t *forensicTracer) CaptureTxEnd(restGas uint64) {}
+
+func (t *forensicTracer) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	t.env = env
+	typ := vm.CALL
+	if create {
+		typ = vm.CREATE
+	}
+	t.enter(typ.String(), from, to, input, gas, value)
+}
+
+func (t *forensicTracer) CaptureEnd(output []byte, gasUsed uint64, err error) {
+	t.exit(output, gasUsed, err)
+}
+
+func (t *forensicTracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	t.enter(typ.String(), from, to, input, gas, value)
+}
+
+func (t *forensicTracer) CaptureExit(output []byte, gasUsed uint64, err error) {
+	t.exit(output, gasUsed, err)
+}
+
+func (t *forensicTracer) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+	if t.steps {
+		step := Step{PC: pc, Op: op.String(), Gas: gas, Cost: cost, Depth: depth}
+		if err != nil {
+			step.Error = err.Error()
+		}
+		t.trace.Steps = append(t.trace.Steps, step)
+	}
+	if op != vm.SLOAD && op != vm.SSTORE {
+		return
+	}
+	if scope == nil || scope.Contract == nil || scope.Stack == nil || scope.Stack.Len() < 1 {
+		return
+	}
+	address := scope.Contract.Address()
+	access := StorageAccess{
+		Phase:   phaseEVM,
+		Depth:   depth,
+		Address: address,
+		Key:     common.Hash(scope.Stack.Back(0).Bytes32()),
+	}
+	// precompiles have no EVM storage, so their accesses are to ArbOS storage
+	_, access.ArbOS = t.precompiles[address]
+	if !access.ArbOS && t.env != nil {
+		current := t.env.StateDB.GetState(address, access.Key)
+		access.Value = &current
+	}
+	if op == vm.SSTORE {
+		if scope.Stack.Len() < 2 {
+			return
+		}
+		value := common.Hash(scope.Stack.Back(1).Bytes32())
+		access.Write = true
+		access.Previous = access.Value
+		access.Value = &value
+	}
+	t.trace.Storage = append(t.trace.Storage, access)
+}
+
+func (t *forensicTracer) CaptureFault(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
+}
+
+func arbosPhase(before bool) string {
+	if before {
+		return phaseBeforeEVM
+	}
+	return phaseAfterEVM
+}
+
+func (t *forensicTracer) CaptureArbitrumTransfer(env *vm.EVM, from, to *common.Address, value *big.Int, before bool, purpose string) {
+	transfer := Transfer{
+		Phase:   arbosPhase(before),
+		Value:   (*hexutil.Big)(new(big.Int).Set(value)),
+		Purpose: purpose,
+	}
+	if from != nil {
+		sender := *from
+		transfer.From = &sender
+	}
+	if to != nil {
+		recipient := *to
+		transfer.To = &recipient
+	}
+	t.trace.Transfers = append(t.trace.Transfers, transfer)
+}
+
+func (t *forensicTracer) CaptureRedeemParent(ticketId common.Hash, parentTxHash common.Hash) {
+	t.trace.RedeemParent = &parentTxHash
+}
+
+func (t *forensicTracer) CaptureArbitrumStorageGet(key common.Hash, depth int, before bool) {
+	t.trace.Storage = append(t.trace.Storage, StorageAccess{
+		Phase:   arbosPhase(before),
+		Depth:   depth,
+		Address: types.ArbosStateAddress,
+		ArbOS:   true,
+		Key:     key,
+	})
+}
+
+func (t *forensicTracer) CaptureArbitrumStorageSet(key, value common.Hash, depth int, before bool) {
+	t.trace.Storage = append(t.trace.Storage, StorageAccess{
+		Phase:   arbosPhase(before),
+		Depth:   depth,
+		Address: types.ArbosStateAddress,
+		ArbOS:   true,
+		Write:   true,
+		Key:     key,
+		Value:   &value,
+	})
+}
+
+func (t *forensicTracer) CaptureStylusHostio(name string, args, outs []byte, startInk, endInk uint64) {
+	if !t.steps {
+		return
+	}
+	t.trace.Hostios = append(t.trace.Hostios, Hostio{
+		Name:     name,
+		Args:     common.CopyBytes(args),
+		Outs:     common.CopyBytes(outs),
+		StartInk: startInk,
+		EndInk:   endInk,
+	})
+}
@@ -0,0 +1,76 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package main
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/holiman/uint256"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+
+	"github.com/offchainlabs/nitro/arbos/util"
+	"github.com/offchainlabs/nitro/precompiles"
+)
+
+func TestForensicTracerRecordsArbOSActivity(t *testing.T) {
+	contracts := precompiles.Precompiles()
+	tracer := newForensicTracer(contracts, false)
+	sender := common.HexToAddress("0x1234")
+	recipient := common.HexToAddress("0x5678")
+	arbSys := types.ArbSysAddress
+
+	// ArbOS links a retry tx to what scheduled it and charges fees before the EVM runs
+	parent := common.HexToHash("0xabcd")
+	tracer.CaptureRedeemParent(common.HexToHash("0x9876"), parent)
+	tracer.CaptureArbitrumStorageGet(common.HexToHash("0x01"), 0, true)
+	tracer.CaptureArbitrumTransfer(nil, &sender, nil, big.NewInt(7), true, "feeCollection")
+
+	selector := contracts[arbSys].Precompile().GetMethodID("ArbOSVersion")
+	tracer.CaptureStart(nil, sender, arbSys, false, selector[:], 100_000, big.NewInt(0))
+	info := &util.TracingInfo{
+		Tracer:   tracer,
+		Scenario: util.TracingDuringEVM,
+		Contract: vm.NewContract(vm.AccountRef(sender), vm.AccountRef(arbSys), uint256.NewInt(0), 0),
+		Depth:    1,
+	}
+	info.RecordStorageSet(common.HexToHash("0x02"), common.HexToHash("0x03"))
+	info.MockCall([]byte{}, 0, arbSys, recipient, big.NewInt(5))
+	tracer.CaptureEnd([]byte{0x20}, 500, nil)
+
+	trace := tracer.trace
+	if len(trace.Calls) != 2 {
+		t.Fatal("expected the precompile call and ArbOS's mock call, got", len(trace.Calls))
+	}
+	call := trace.Calls[0]
+	if call.Precompile != "ArbSys" || call.Method != "ArbOSVersion" || call.GasUsed != 500 {
+		t.Fatal("unexpected precompile call", call)
+	}
+	if trace.Calls[1].To != recipient || trace.Calls[1].Depth != 1 || trace.Calls[1].Value.ToInt().Cmp(big.NewInt(5)) != 0 {
+		t.Fatal("unexpected mock call", trace.Calls[1])
+	}
+	if len(trace.Storage) != 2 {
+		t.Fatal("expected two storage accesses, got", len(trace.Storage))
+	}
+	read, write := trace.Storage[0], trace.Storage[1]
+	if read.Phase != phaseBeforeEVM || !read.ArbOS || read.Write || read.Address != types.ArbosStateAddress {
+		t.Fatal("unexpected ArbOS storage read", read)
+	}
+	if write.Phase != phaseEVM || !write.ArbOS || !write.Write || write.Address != arbSys || write.Key != common.HexToHash("0x02") || *write.Value != common.HexToHash("0x03") {
+		t.Fatal("unexpected precompile storage write", write)
+	}
+	if len(trace.Transfers) != 1 || trace.Transfers[0].Purpose != "feeCollection" || *trace.Transfers[0].From != sender || trace.Transfers[0].To != nil {
+		t.Fatal("unexpected transfers", trace.Transfers)
+	}
+	if trace.RedeemParent == nil || *trace.RedeemParent != parent {
+		t.Fatal("unexpected redeem parent", trace.RedeemParent)
+	}
+	touched := tracer.touched()
+	if len(touched) != 3 {
+		t.Fatal("expected the sender, ArbSys and the recipient to be touched, got", touched)
+	}
+}
@@ -197,18 +197,20 @@ func devFlagArgs() []string {
 	args := []string{
 		"--init.dev-init",
 		"--init.dev-init-address", "0x3f1Eae7D46d88F08fc2F8ed27FCb2AB183EB2d0E",
-		"--node.dangerous.no-l1-listener",
-		"--node.parent-chain-reader.enable=false",
+		"--dev-parent-chain.enable",
 		"--parent-chain.id=1337",
 		"--chain.id=412346",
 		"--persistent.chain", "/tmp/dev-test",
 		"--node.sequencer",
 		"--execution.sequencer.enable",
 		"--node.dangerous.no-sequencer-coordinator",
+		"--node.dangerous.instant-finality",
+		"--execution.dev.enable",
 		"--node.staker.enable=false",
 		"--init.empty=false",
 		"--http.port", "8547",
 		"--http.addr", "127.0.0.1",
+		"--http.api", "net,web3,eth,arb,evm",
 	}
 	return args
 }
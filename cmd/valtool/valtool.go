@@ -0,0 +1,88 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/offchainlabs/nitro/cmd/util/confighelpers"
+	"github.com/offchainlabs/nitro/validator/divergence"
+)
+
+func main() {
+	args := os.Args
+	if len(args) < 2 {
+		panic("Usage: valtool [bisect] ...")
+	}
+
+	var err error
+	switch strings.ToLower(args[1]) {
+	case "bisect":
+		err = startBisect(args[2:])
+	default:
+		panic(fmt.Sprintf("Unknown tool '%s' specified, valid tools are 'bisect'", args[1]))
+	}
+	if err != nil {
+		panic(err)
+	}
+}
+
+// valtool bisect ...
+
+func parseBisectConfig(args []string) (*divergence.Config, error) {
+	f := flag.NewFlagSet("valtool bisect", flag.ContinueOnError)
+	divergence.ConfigAddOptions(f)
+
+	k, err := confighelpers.BeginCommonParse(f, args)
+	if err != nil {
+		return nil, err
+	}
+
+	config := divergence.DefaultConfig
+	if err := confighelpers.EndCommonParse(k, &config); err != nil {
+		return nil, err
+	}
+	return &config, config.Validate()
+}
+
+func startBisect(args []string) error {
+	config, err := parseBisectConfig(args)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	local, err := rpc.DialContext(ctx, config.LocalURL)
+	if err != nil {
+		return fmt.Errorf("error connecting to local node: %w", err)
+	}
+	defer local.Close()
+	reference, err := rpc.DialContext(ctx, config.ReferenceURL)
+	if err != nil {
+		return fmt.Errorf("error connecting to reference node: %w", err)
+	}
+	defer reference.Close()
+
+	report, err := divergence.NewBisector(config, local, reference).Run(ctx)
+	if err != nil {
+		return err
+	}
+	if err := divergence.WriteReport(config.OutputDir, report); err != nil {
+		return err
+	}
+	log.Info("wrote divergence report", "block", report.Block, "offendingTx", report.OffendingTx, "dir", config.OutputDir)
+	fmt.Printf("First divergent block: %d\n", report.Block)
+	if report.OffendingTx != nil {
+		fmt.Printf("Offending transaction: %v (index %d) %s\n", *report.OffendingTx, *report.OffendingTxIndex, report.ReceiptDifference)
+	}
+	return nil
+}
@@ -15,6 +15,7 @@ import (
 	flag "github.com/spf13/pflag"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
 
@@ -33,6 +34,11 @@ var (
 	// there was a Store that had no backend failures.
 	anyErrorGauge = metrics.GetOrRegisterGauge(metricBase+"/error/gauge", nil)
 
+	// These metrics show the total weight of the backends that stored the latest
+	// message, and how many more backends than needed for a quorum stored it.
+	storedWeightGauge = metrics.GetOrRegisterGauge(metricBase+"/weight/stored", nil)
+	quorumMarginGauge = metrics.GetOrRegisterGauge(metricBase+"/quorum/margin", nil)
+
 // Other aggregator metrics are generated dynamically in the Store function.
 )
 
@@ -41,12 +47,16 @@ type AggregatorConfig struct {
 	AssumedHonest         int               `koanf:"assumed-honest"`
 	Backends              BackendConfigList `koanf:"backends"`
 	MaxStoreChunkBodySize int               `koanf:"max-store-chunk-body-size"`
+	QuorumWeight          uint64            `koanf:"quorum-weight"`
+	StoreTimeout          time.Duration     `koanf:"store-timeout"`
 }
 
 var DefaultAggregatorConfig = AggregatorConfig{
 	AssumedHonest:         0,
 	Backends:              nil,
 	MaxStoreChunkBodySize: 512 * 1024,
+	QuorumWeight:          0,
+	StoreTimeout:          0,
 }
 
 var parsedBackendsConf BackendConfigList
@@ -54,18 +64,24 @@ var parsedBackendsConf BackendConfigList
 func AggregatorConfigAddOptions(prefix string, f *flag.FlagSet) {
 	f.Bool(prefix+".enable", DefaultAggregatorConfig.Enable, "enable storage of sequencer batch data from a list of RPC endpoints; this should only be used by the batch poster and not in combination with other DAS storage types")
 	f.Int(prefix+".assumed-honest", DefaultAggregatorConfig.AssumedHonest, "Number of assumed honest backends (H). If there are N backends, K=N+1-H valid responses are required to consider an Store request to be successful.")
-	f.Var(&parsedBackendsConf, prefix+".backends", "JSON RPC backend configuration. This can be specified on the command line as a JSON array, eg: [{\"url\": \"...\", \"pubkey\": \"...\"},...], or as a JSON array in the config file.")
+	f.Var(&parsedBackendsConf, prefix+".backends", "JSON RPC backend configuration. This can be specified on the command line as a JSON array, eg: [{\"url\": \"...\", \"pubkey\": \"...\", \"weight\": 1},...] where weight is optional, or as a JSON array in the config file.")
 	f.Int(prefix+".max-store-chunk-body-size", DefaultAggregatorConfig.MaxStoreChunkBodySize, "maximum HTTP POST body size to use for individual batch chunks, including JSON RPC overhead and an estimated overhead of 512B of headers")
+	f.Uint64(prefix+".quorum-weight", DefaultAggregatorConfig.QuorumWeight, "minimum total weight of the backends that must store a batch, in addition to the K required by the keyset; backends weigh 1 unless configured otherwise (0 = only require K)")
+	f.Duration(prefix+".store-timeout", DefaultAggregatorConfig.StoreTimeout, "how long a Store may take to reach a quorum before it fails, regardless of its context (0 = no limit)")
 }
 
 type Aggregator struct {
 	config         AggregatorConfig
 	services       []ServiceDetails
 	requestTimeout time.Duration
+	storeTimeout   time.Duration
+	lastReport     atomic.Pointer[StoreReport]
 
 	// calculated fields
 	requiredServicesForStore       int
 	maxAllowedServiceStoreFailures int
+	requiredWeightForStore         uint64
+	totalWeight                    uint64
 	keysetHash                     [32]byte
 	keysetBytes                    []byte
 }
@@ -75,6 +91,20 @@ type ServiceDetails struct {
 	pubKey      blsSignatures.PublicKey
 	signersMask uint64
 	metricName  string
+	weight      uint64 // 0 weighs the same as 1
+}
+
+// Weight returns how much the backend storing a message counts towards the aggregator's quorum weight.
+func (s *ServiceDetails) Weight() uint64 {
+	if s.weight == 0 {
+		return 1
+	}
+	return s.weight
+}
+
+// SetWeight sets how much the backend storing a message counts towards the aggregator's quorum weight.
+func (s *ServiceDetails) SetWeight(weight uint64) {
+	s.weight = weight
 }
 
 func (s *ServiceDetails) String() string {
@@ -135,37 +165,176 @@ func NewAggregatorWithSeqInboxCaller(
 		return nil, err
 	}
 
+	var totalWeight uint64
+	for i := range services {
+		totalWeight += services[i].Weight()
+	}
+	if config.RPCAggregator.QuorumWeight > totalWeight {
+		return nil, fmt.Errorf("aggregator quorum weight %d is more than the total weight %d of its backends", config.RPCAggregator.QuorumWeight, totalWeight)
+	}
+
 	return &Aggregator{
 		config:                         config.RPCAggregator,
 		services:                       services,
 		requestTimeout:                 config.RequestTimeout,
+		storeTimeout:                   config.RPCAggregator.StoreTimeout,
 		requiredServicesForStore:       len(services) + 1 - config.RPCAggregator.AssumedHonest,
 		maxAllowedServiceStoreFailures: config.RPCAggregator.AssumedHonest - 1,
+		requiredWeightForStore:         config.RPCAggregator.QuorumWeight,
+		totalWeight:                    totalWeight,
 		keysetHash:                     keysetHash,
 		keysetBytes:                    keysetBytes,
 	}, nil
 }
 
 type storeResponse struct {
+	index   int
 	details ServiceDetails
 	sig     blsSignatures.Signature
 	err     error
+	latency time.Duration
+}
+
+// BackendStoreResult is how a committee member handled a Store.
+type BackendStoreResult struct {
+	Backend     string         `json:"backend"`
+	SignersMask hexutil.Uint64 `json:"signersMask"`
+	Weight      uint64         `json:"weight"`
+	Responded   bool           `json:"responded"`
+	Stored      bool           `json:"stored"`
+	Error       string         `json:"error,omitempty"`
+	LatencyMs   int64          `json:"latencyMs,omitempty"`
+}
+
+// StoreReport is how each committee member had handled a Store as of when it was reported, and what a quorum
+// required, so that failing members are visible while there's still a quorum without them.
+type StoreReport struct {
+	DataHash       common.Hash          `json:"dataHash"`
+	Backends       []BackendStoreResult `json:"backends"` // in keyset order
+	StoredCount    int                  `json:"storedCount"`
+	RequiredCount  int                  `json:"requiredCount"`
+	StoredWeight   uint64               `json:"storedWeight"`
+	RequiredWeight uint64               `json:"requiredWeight"`
+}
+
+// Failed returns the backends that responded with an error or an invalid certificate.
+func (r *StoreReport) Failed() []string {
+	var failed []string
+	for _, result := range r.Backends {
+		if result.Responded && !result.Stored {
+			failed = append(failed, result.Backend)
+		}
+	}
+	return failed
+}
+
+// Pending returns the backends that hadn't responded.
+func (r *StoreReport) Pending() []string {
+	var pending []string
+	for _, result := range r.Backends {
+		if !result.Responded {
+			pending = append(pending, result.Backend)
+		}
+	}
+	return pending
+}
+
+func (r *StoreReport) clone() *StoreReport {
+	clone := *r
+	clone.Backends = append([]BackendStoreResult{}, r.Backends...)
+	return &clone
+}
+
+// StoreReporter is a DAS writer that can also report how each committee member handled a Store.
+type StoreReporter interface {
+	StoreWithReport(ctx context.Context, message []byte, timeout uint64) (*daprovider.DataAvailabilityCertificate, *StoreReport, error)
+}
+
+// StoreError is returned by Store when not enough of the committee stored the message, with a report of which
+// members failed. It wraps daprovider.ErrBatchToDasFailed.
+type StoreError struct {
+	Reason string
+	Report *StoreReport
+}
+
+func (e *StoreError) Error() string {
+	return fmt.Sprintf("%s (failed: %v, no response: %v). %v", e.Reason, e.Report.Failed(), e.Report.Pending(), daprovider.ErrBatchToDasFailed)
+}
+
+func (e *StoreError) Unwrap() error {
+	return daprovider.ErrBatchToDasFailed
+}
+
+func (a *Aggregator) newStoreReport(dataHash common.Hash) *StoreReport {
+	report := &StoreReport{
+		DataHash:       dataHash,
+		Backends:       make([]BackendStoreResult, len(a.services)),
+		RequiredCount:  a.requiredServicesForStore,
+		RequiredWeight: a.requiredWeightForStore,
+	}
+	for i, d := range a.services {
+		report.Backends[i] = BackendStoreResult{
+			Backend:     d.metricName,
+			SignersMask: hexutil.Uint64(d.signersMask),
+			Weight:      d.Weight(),
+		}
+	}
+	return report
+}
+
+// finishStoreReport records the outcome of a Store once every backend has responded or its context is done.
+func (a *Aggregator) finishStoreReport(report *StoreReport) {
+	for _, result := range report.Backends {
+		if !result.Responded {
+			metrics.GetOrRegisterCounter(metricBase+"/"+result.Backend+"/noresponse/total", nil).Inc(1)
+		}
+	}
+	// #nosec G115
+	storedWeightGauge.Update(int64(report.StoredWeight))
+	quorumMarginGauge.Update(int64(report.StoredCount - report.RequiredCount))
+	a.lastReport.Store(report)
+
+	failed, pending := report.Failed(), report.Pending()
+	if len(failed) > 0 || len(pending) > 0 {
+		log.Warn(
+			"DAS Aggregator store report",
+			"dataHash", report.DataHash,
+			"stored", report.StoredCount,
+			"required", report.RequiredCount,
+			"storedWeight", report.StoredWeight,
+			"requiredWeight", report.RequiredWeight,
+			"failed", failed,
+			"noResponse", pending,
+		)
+	}
+}
+
+// LastStoreReport returns the final report of the latest Store to finish, or nil if none has.
+func (a *Aggregator) LastStoreReport() *StoreReport {
+	return a.lastReport.Load()
 }
 
 // Store calls Store on each backend DAS in parallel and collects responses.
-// If there were at least K responses then it aggregates the signatures and
-// signersMasks from each DAS together into the DataAvailabilityCertificate
-// then Store returns immediately. If there were any backend Store subroutines
-// that were still running when Aggregator.Store returns, they are allowed to
-// continue running until the context is canceled (eg via TimeoutWrapper),
-// with their results discarded.
+// If there were at least K responses, with at least the quorum weight between
+// them, then it aggregates the signatures and signersMasks from each DAS
+// together into the DataAvailabilityCertificate then Store returns immediately.
+// If there were any backend Store subroutines that were still running when
+// Aggregator.Store returns, they are allowed to continue running until the
+// context is canceled (eg via TimeoutWrapper), with their results discarded.
 //
-// If Store gets enough errors that K successes is impossible, then it stops early
+// If Store gets enough errors that a quorum is impossible, then it stops early
 // and returns an error.
 //
 // If Store gets not enough successful responses by the time its context is canceled
-// (eg via TimeoutWrapper) then it also returns an error.
+// (eg via TimeoutWrapper), or the store timeout passes, then it also returns an error.
 func (a *Aggregator) Store(ctx context.Context, message []byte, timeout uint64) (*daprovider.DataAvailabilityCertificate, error) {
+	cert, _, err := a.StoreWithReport(ctx, message, timeout)
+	return cert, err
+}
+
+// StoreWithReport is Store, also returning how each backend had handled the message when it returned.
+// Backends that hadn't responded yet are reported as such; LastStoreReport gets their final results.
+func (a *Aggregator) StoreWithReport(ctx context.Context, message []byte, timeout uint64) (*daprovider.DataAvailabilityCertificate, *StoreReport, error) {
 	// #nosec G115
 	log.Trace("das.Aggregator.Store", "message", pretty.FirstFewBytes(message), "timeout", time.Unix(int64(timeout), 0))
 
@@ -181,8 +350,9 @@ func (a *Aggregator) Store(ctx context.Context, message []byte, timeout uint64)
 	responses := make(chan storeResponse, len(a.services))
 
 	expectedHash := dastree.Hash(message)
-	for _, d := range a.services {
-		go func(ctx context.Context, d ServiceDetails) {
+	start := time.Now()
+	for i, d := range a.services {
+		go func(ctx context.Context, i int, d ServiceDetails) {
 			storeCtx, cancel := context.WithTimeout(ctx, a.requestTimeout)
 			var metricWithServiceName = metricBase + "/" + d.metricName
 			defer cancel()
@@ -190,12 +360,15 @@ func (a *Aggregator) Store(ctx context.Context, message []byte, timeout uint64)
 				metrics.GetOrRegisterCounter(metricWithServiceName+"/error/total", nil).Inc(1)
 				metrics.GetOrRegisterCounter(metricBase+"/error/all/total", nil).Inc(1)
 			}
+			respond := func(sig blsSignatures.Signature, err error) {
+				responses <- storeResponse{index: i, details: d, sig: sig, err: err, latency: time.Since(start)}
+			}
 
 			cert, err := d.service.Store(storeCtx, message, timeout)
 			if err != nil {
 				incFailureMetric()
 				log.Warn("DAS Aggregator failed to store batch to backend", "backend", d.metricName, "err", err)
-				responses <- storeResponse{d, nil, err}
+				respond(nil, err)
 				return
 			}
 
@@ -205,13 +378,13 @@ func (a *Aggregator) Store(ctx context.Context, message []byte, timeout uint64)
 			if err != nil {
 				incFailureMetric()
 				log.Warn("DAS Aggregator couldn't parse backend's store response signature", "backend", d.metricName, "err", err)
-				responses <- storeResponse{d, nil, err}
+				respond(nil, err)
 				return
 			}
 			if !verified {
 				incFailureMetric()
 				log.Warn("DAS Aggregator failed to verify backend's store response signature", "backend", d.metricName, "err", err)
-				responses <- storeResponse{d, nil, errors.New("signature verification failed")}
+				respond(nil, errors.New("signature verification failed"))
 				return
 			}
 
@@ -220,20 +393,20 @@ func (a *Aggregator) Store(ctx context.Context, message []byte, timeout uint64)
 			if cert.DataHash != expectedHash {
 				incFailureMetric()
 				log.Warn("DAS Aggregator got a store response with a data hash not matching the expected hash", "backend", d.metricName, "dataHash", cert.DataHash, "expectedHash", expectedHash, "err", err)
-				responses <- storeResponse{d, nil, errors.New("hash verification failed")}
+				respond(nil, errors.New("hash verification failed"))
 				return
 			}
 			if cert.Timeout != timeout {
 				incFailureMetric()
 				log.Warn("DAS Aggregator got a store response with any expiry time not matching the expected expiry time", "backend", d.metricName, "dataHash", cert.DataHash, "expectedHash", expectedHash, "err", err)
-				responses <- storeResponse{d, nil, fmt.Errorf("timeout was %d, expected %d", cert.Timeout, timeout)}
+				respond(nil, fmt.Errorf("timeout was %d, expected %d", cert.Timeout, timeout))
 				return
 			}
 
 			metrics.GetOrRegisterCounter(metricWithServiceName+"/success/total", nil).Inc(1)
 			metrics.GetOrRegisterCounter(metricBase+"/success/all/total", nil).Inc(1)
-			responses <- storeResponse{d, cert.Sig, nil}
-		}(ctx, d)
+			respond(cert.Sig, nil)
+		}(ctx, i, d)
 	}
 
 	var aggCert daprovider.DataAvailabilityCertificate
@@ -242,6 +415,7 @@ func (a *Aggregator) Store(ctx context.Context, message []byte, timeout uint64)
 		pubKeys        []blsSignatures.PublicKey
 		sigs           []blsSignatures.Signature
 		aggSignersMask uint64
+		report         *StoreReport
 		err            error
 	}
 
@@ -252,22 +426,54 @@ func (a *Aggregator) Store(ctx context.Context, message []byte, timeout uint64)
 		var pubKeys []blsSignatures.PublicKey
 		var sigs []blsSignatures.Signature
 		var aggSignersMask uint64
-		var successfullyStoredCount int
+		var failedWeight uint64
 		var returned bool
-		for i := 0; i < len(a.services); i++ {
+		report := a.newStoreReport(expectedHash)
+		defer a.finishStoreReport(report)
+
+		var deadline <-chan time.Time
+		if a.storeTimeout > 0 {
+			timer := time.NewTimer(a.storeTimeout)
+			defer timer.Stop()
+			deadline = timer.C
+		}
+		fail := func(reason string) {
+			certDetailsChan <- certDetails{report: report.clone(), err: &StoreError{Reason: reason, Report: report.clone()}}
+			returned = true
+		}
+
+	collect:
+		for received := 0; received < len(a.services); {
 			select {
 			case <-ctx.Done():
-				break
+				if !returned {
+					fail(fmt.Sprintf("aggregator store canceled before reaching a quorum: %v", ctx.Err()))
+				}
+				break collect
+			case <-deadline:
+				deadline = nil
+				if !returned {
+					fail(fmt.Sprintf("aggregator store didn't reach a quorum within %v", a.storeTimeout))
+				}
+				continue
 			case r := <-responses:
+				received++
+				result := &report.Backends[r.index]
+				result.Responded = true
+				result.LatencyMs = r.latency.Milliseconds()
 				if r.err != nil {
 					_ = storeFailures.Add(1)
+					failedWeight += r.details.Weight()
+					result.Error = r.err.Error()
 					log.Warn("das.Aggregator: Error from backend", "backend", r.details.service, "signerMask", r.details.signersMask, "err", r.err)
 				} else {
 					pubKeys = append(pubKeys, r.details.pubKey)
 					sigs = append(sigs, r.sig)
 					aggSignersMask |= r.details.signersMask
 
-					successfullyStoredCount++
+					result.Stored = true
+					report.StoredCount++
+					report.StoredWeight += r.details.Weight()
 				}
 			}
 
@@ -276,11 +482,12 @@ func (a *Aggregator) Store(ctx context.Context, message []byte, timeout uint64)
 			// running until all responses are received (or the context is canceled)
 			// in order to produce accurate logs/metrics.
 			if !returned {
-				if successfullyStoredCount >= a.requiredServicesForStore {
+				if report.StoredCount >= a.requiredServicesForStore && report.StoredWeight >= a.requiredWeightForStore {
 					cd := certDetails{}
 					cd.pubKeys = append(cd.pubKeys, pubKeys...)
 					cd.sigs = append(cd.sigs, sigs...)
 					cd.aggSignersMask = aggSignersMask
+					cd.report = report.clone()
 					certDetailsChan <- cd
 					returned = true
 					if a.maxAllowedServiceStoreFailures > 0 && // Ignore the case where AssumedHonest = 1, probably a testnet
@@ -288,20 +495,18 @@ func (a *Aggregator) Store(ctx context.Context, message []byte, timeout uint64)
 						log.Error("das.Aggregator: storing the batch data succeeded to enough DAS commitee members to generate the Data Availability Cert, but if one more had failed then the cert would not have been able to be generated. Look for preceding logs with \"Error from backend\"")
 					}
 				} else if int(storeFailures.Load()) > a.maxAllowedServiceStoreFailures {
-					cd := certDetails{}
-					cd.err = fmt.Errorf("aggregator failed to store message to at least %d out of %d DASes (assuming %d are honest). %w", a.requiredServicesForStore, len(a.services), a.config.AssumedHonest, daprovider.ErrBatchToDasFailed)
-					certDetailsChan <- cd
-					returned = true
+					fail(fmt.Sprintf("aggregator failed to store message to at least %d out of %d DASes (assuming %d are honest)", a.requiredServicesForStore, len(a.services), a.config.AssumedHonest))
+				} else if a.totalWeight-failedWeight < a.requiredWeightForStore {
+					fail(fmt.Sprintf("aggregator failed to store message to DASes with a total weight of at least %d out of %d", a.requiredWeightForStore, a.totalWeight))
 				}
 			}
-
 		}
 	}()
 
 	cd := <-certDetailsChan
 
 	if cd.err != nil {
-		return nil, cd.err
+		return nil, cd.report, cd.err
 	}
 
 	aggCert.Sig = blsSignatures.AggregateSignatures(cd.sigs)
@@ -316,17 +521,17 @@ func (a *Aggregator) Store(ctx context.Context, message []byte, timeout uint64)
 	verified, err := blsSignatures.VerifySignature(aggCert.Sig, aggCert.SerializeSignableFields(), aggPubKey)
 	if err != nil {
 		//nolint:errorlint
-		return nil, fmt.Errorf("%s. %w", err.Error(), daprovider.ErrBatchToDasFailed)
+		return nil, cd.report, fmt.Errorf("%s. %w", err.Error(), daprovider.ErrBatchToDasFailed)
 	}
 	if !verified {
-		return nil, fmt.Errorf("failed aggregate signature check. %w", daprovider.ErrBatchToDasFailed)
+		return nil, cd.report, fmt.Errorf("failed aggregate signature check. %w", daprovider.ErrBatchToDasFailed)
 	}
 
 	if storeFailures.Load() == 0 {
 		allBackendsSucceeded = true
 	}
 
-	return &aggCert, nil
+	return &aggCert, cd.report, nil
 }
 
 func (a *Aggregator) String() string {
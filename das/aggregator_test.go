@@ -290,3 +290,99 @@ func TestDAS_AtLeastHStorageFailures(t *testing.T) {
 		})
 	}
 }
+
+type fixedFailure failureType
+
+func (f fixedFailure) shouldFail() failureType {
+	return failureType(f)
+}
+
+func newWeightedTestAggregator(t *testing.T, ctx context.Context, config AggregatorConfig, weights []uint64, failures []failureType) *Aggregator {
+	var backends []ServiceDetails
+	for i, weight := range weights {
+		privKey, err := blsSignatures.GeneratePrivKeyString()
+		Require(t, err)
+		das, err := NewSignAfterStoreDASWriter(ctx, DataAvailabilityConfig{
+			Enable:             true,
+			Key:                KeyConfig{PrivKey: privKey},
+			ParentChainNodeURL: "none",
+		}, NewMemoryBackedStorageService(ctx))
+		Require(t, err)
+		details, err := NewServiceDetails(&WrapStore{t, fixedFailure(failures[i]), das}, *das.pubKey, uint64(1<<i), "service"+strconv.Itoa(i))
+		Require(t, err)
+		details.SetWeight(weight)
+		backends = append(backends, *details)
+	}
+	aggregator, err := NewAggregator(ctx, DataAvailabilityConfig{
+		RPCAggregator:      config,
+		ParentChainNodeURL: "none",
+		RequestTimeout:     time.Second * 5,
+	}, backends)
+	Require(t, err)
+	return aggregator
+}
+
+func TestDAS_WeightedQuorum(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// one signature satisfies the keyset, but the quorum needs the heavy backend or all the light ones
+	config := AggregatorConfig{AssumedHonest: 4, QuorumWeight: 4}
+	weights := []uint64{3, 1, 1, 1}
+	rawMsg := []byte("It's time for you to see the fnords.")
+
+	aggregator := newWeightedTestAggregator(t, ctx, config, weights, []failureType{immediateError, success, success, success})
+	_, report, err := aggregator.StoreWithReport(ctx, rawMsg, 0)
+	if !errors.Is(err, daprovider.ErrBatchToDasFailed) {
+		Fail(t, "stored without the quorum weight", err)
+	}
+	var storeErr *StoreError
+	if !errors.As(err, &storeErr) || storeErr.Report.StoredWeight > 3 {
+		Fail(t, "wrong store error", err)
+	}
+	if failed := report.Failed(); len(failed) != 1 || failed[0] != "service0" {
+		Fail(t, "wrong failed backends", failed)
+	}
+
+	aggregator = newWeightedTestAggregator(t, ctx, config, weights, []failureType{success, immediateError, success, success})
+	cert, report, err := aggregator.StoreWithReport(ctx, rawMsg, 0)
+	Require(t, err)
+	if report.StoredWeight < 4 || cert.SignersMask&1 == 0 {
+		Fail(t, "stored without the quorum weight", report.StoredWeight, cert.SignersMask)
+	}
+
+	// the final report has every backend's result
+	for aggregator.LastStoreReport() == nil {
+		time.Sleep(time.Millisecond * 10)
+	}
+	final := aggregator.LastStoreReport()
+	if len(final.Pending()) != 0 || len(final.Failed()) != 1 || final.StoredCount != 3 || final.StoredWeight != 5 {
+		Fail(t, "wrong final store report", final)
+	}
+
+	if _, err := NewAggregator(ctx, DataAvailabilityConfig{
+		RPCAggregator:      AggregatorConfig{AssumedHonest: 1, QuorumWeight: 7},
+		ParentChainNodeURL: "none",
+	}, aggregator.services); err == nil {
+		Fail(t, "created an aggregator with an unreachable quorum weight")
+	}
+}
+
+func TestDAS_StoreTimeout(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	config := AggregatorConfig{AssumedHonest: 1, StoreTimeout: time.Millisecond * 100}
+	aggregator := newWeightedTestAggregator(t, ctx, config, []uint64{1, 1}, []failureType{success, tooSlow})
+	start := time.Now()
+	_, report, err := aggregator.StoreWithReport(ctx, []byte("It's time for you to see the fnords."), 0)
+	if !errors.Is(err, daprovider.ErrBatchToDasFailed) {
+		Fail(t, "stored without every backend", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		Fail(t, "store outlasted its timeout", elapsed)
+	}
+	if pending := report.Pending(); len(pending) != 1 || pending[0] != "service1" {
+		Fail(t, "wrong backends without a response", pending)
+	}
+}
@@ -44,6 +44,7 @@ type DataAvailabilityConfig struct {
 	LocalDBStorage   LocalDBStorageConfig   `koanf:"local-db-storage"`
 	LocalFileStorage LocalFileStorageConfig `koanf:"local-file-storage"`
 	S3Storage        S3StorageServiceConfig `koanf:"s3-storage"`
+	Retention        RetentionConfig        `koanf:"retention"`
 
 	MigrateLocalDBToFileStorage bool `koanf:"migrate-local-db-to-file-storage"`
 
@@ -66,6 +67,7 @@ var DefaultDataAvailabilityConfig = DataAvailabilityConfig{
 	Enable:                        false,
 	RestAggregator:                DefaultRestfulClientAggregatorConfig,
 	RPCAggregator:                 DefaultAggregatorConfig,
+	Retention:                     DefaultRetentionConfig,
 	ParentChainConnectionAttempts: 15,
 	PanicOnError:                  false,
 }
@@ -114,6 +116,7 @@ func dataAvailabilityConfigAddOptions(prefix string, f *flag.FlagSet, r role) {
 		LocalDBStorageConfigAddOptions(prefix+".local-db-storage", f)
 		LocalFileStorageConfigAddOptions(prefix+".local-file-storage", f)
 		S3ConfigAddOptions(prefix+".s3-storage", f)
+		RetentionConfigAddOptions(prefix+".retention", f)
 		f.Bool(prefix+".migrate-local-db-to-file-storage", DefaultDataAvailabilityConfig.MigrateLocalDBToFileStorage, "daserver will migrate all data on startup from local-db-storage to local-file-storage, then mark local-db-storage as unusable")
 
 		// Key config for storage
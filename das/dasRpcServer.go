@@ -20,6 +20,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/rpc"
 
+	"github.com/offchainlabs/nitro/arbstate/daprovider"
 	"github.com/offchainlabs/nitro/blsSignatures"
 	"github.com/offchainlabs/nitro/cmd/genericconf"
 	"github.com/offchainlabs/nitro/util/pretty"
@@ -105,6 +106,32 @@ type StoreResult struct {
 	KeysetHash  hexutil.Bytes  `json:"keysetHash,omitempty"`
 	Sig         hexutil.Bytes  `json:"sig,omitempty"`
 	Version     hexutil.Uint64 `json:"version,omitempty"`
+	// how each committee member had handled the store when it returned, if the server is an aggregator
+	Report *StoreReport `json:"report,omitempty"`
+}
+
+// store stores the message with the server's writer, with the writer's report of the store if it has one.
+func (s *DASRPCServer) store(ctx context.Context, message []byte, timeout uint64) (*StoreResult, error) {
+	var cert *daprovider.DataAvailabilityCertificate
+	var report *StoreReport
+	var err error
+	if reporter, ok := s.daWriter.(StoreReporter); ok {
+		cert, report, err = reporter.StoreWithReport(ctx, message, timeout)
+	} else {
+		cert, err = s.daWriter.Store(ctx, message, timeout)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &StoreResult{
+		KeysetHash:  cert.KeysetHash[:],
+		DataHash:    cert.DataHash[:],
+		Timeout:     hexutil.Uint64(cert.Timeout),
+		SignersMask: hexutil.Uint64(cert.SignersMask),
+		Sig:         blsSignatures.SignatureToBytes(cert.Sig),
+		Version:     hexutil.Uint64(cert.Version),
+		Report:      report,
+	}, nil
 }
 
 func (s *DASRPCServer) Store(ctx context.Context, message hexutil.Bytes, timeout hexutil.Uint64, sig hexutil.Bytes) (*StoreResult, error) {
@@ -126,20 +153,13 @@ func (s *DASRPCServer) Store(ctx context.Context, message hexutil.Bytes, timeout
 		return nil, err
 	}
 
-	cert, err := s.daWriter.Store(ctx, message, uint64(timeout))
+	result, err := s.store(ctx, message, uint64(timeout))
 	if err != nil {
 		return nil, err
 	}
 	rpcStoreStoredBytesGauge.Inc(int64(len(message)))
 	success = true
-	return &StoreResult{
-		KeysetHash:  cert.KeysetHash[:],
-		DataHash:    cert.DataHash[:],
-		Timeout:     hexutil.Uint64(cert.Timeout),
-		SignersMask: hexutil.Uint64(cert.SignersMask),
-		Sig:         blsSignatures.SignatureToBytes(cert.Sig),
-		Version:     hexutil.Uint64(cert.Version),
-	}, nil
+	return result, nil
 }
 
 type StartChunkedStoreResult struct {
@@ -327,7 +347,7 @@ func (s *DASRPCServer) CommitChunkedStore(ctx context.Context, batchId hexutil.U
 		return nil, err
 	}
 
-	cert, err := s.daWriter.Store(ctx, message, timeout)
+	result, err := s.store(ctx, message, timeout)
 	success := false
 	defer func() {
 		if success {
@@ -342,14 +362,7 @@ func (s *DASRPCServer) CommitChunkedStore(ctx context.Context, batchId hexutil.U
 	}
 	rpcStoreStoredBytesGauge.Inc(int64(len(message)))
 	success = true
-	return &StoreResult{
-		KeysetHash:  cert.KeysetHash[:],
-		DataHash:    cert.DataHash[:],
-		Timeout:     hexutil.Uint64(cert.Timeout),
-		SignersMask: hexutil.Uint64(cert.SignersMask),
-		Sig:         blsSignatures.SignatureToBytes(cert.Sig),
-		Version:     hexutil.Uint64(cert.Version),
-	}, nil
+	return result, nil
 }
 
 func (serv *DASRPCServer) HealthCheck(ctx context.Context) error {
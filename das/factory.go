@@ -10,7 +10,9 @@ import (
 	"math"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
 
+	"github.com/offchainlabs/nitro/arbstate/daprovider"
 	"github.com/offchainlabs/nitro/arbutil"
 	"github.com/offchainlabs/nitro/solgen/go/bridgegen"
 	"github.com/offchainlabs/nitro/util/headerreader"
@@ -62,6 +64,12 @@ func CreatePersistentStorageService(
 		if err != nil {
 			return nil, nil, err
 		}
+		if config.Retention.Enable {
+			s, err = wrapStorageWithRetention(ctx, &config.Retention, s)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
 		lifecycleManager.Register(s)
 		storageServices = append(storageServices, s)
 	}
@@ -84,6 +92,27 @@ func CreatePersistentStorageService(
 	return nil, &lifecycleManager, nil
 }
 
+func wrapStorageWithRetention(ctx context.Context, config *RetentionConfig, storageService StorageService) (StorageService, error) {
+	deletable, ok := storageService.(DeletableStorageService)
+	if !ok {
+		return nil, fmt.Errorf("retention manager enabled but %v does not support deletion", storageService)
+	}
+	policy, err := storageService.ExpirationPolicy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if policy != daprovider.DiscardAfterDataTimeout {
+		log.Warn("retention manager enabled but storage keeps its data past expiry, so nothing will be pruned", "storage", storageService)
+		return storageService, nil
+	}
+	retentionManager, err := NewRetentionManager(*config, deletable)
+	if err != nil {
+		return nil, err
+	}
+	retentionManager.Start(ctx)
+	return retentionManager, nil
+}
+
 func WrapStorageWithCache(
 	ctx context.Context,
 	config *DataAvailabilityConfig,
@@ -0,0 +1,260 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package das
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/offchainlabs/nitro/arbstate/daprovider"
+	"github.com/offchainlabs/nitro/blsSignatures"
+	"github.com/offchainlabs/nitro/das/dastree"
+	"github.com/offchainlabs/nitro/solgen/go/bridgegen"
+	"github.com/offchainlabs/nitro/util/pretty"
+)
+
+// NewKeyset builds a keyset from the committee's public keys, checking that it can be
+// registered with the SequencerInbox and that a certificate signed under it can be verified.
+func NewKeyset(assumedHonest uint64, pubKeys []blsSignatures.PublicKey) (*daprovider.DataAvailabilityKeyset, error) {
+	if len(pubKeys) == 0 {
+		return nil, errors.New("keyset must contain at least one public key")
+	}
+	if len(pubKeys) > 64 {
+		return nil, fmt.Errorf("keyset has %d public keys but at most 64 are supported", len(pubKeys))
+	}
+	if assumedHonest == 0 || assumedHonest > uint64(len(pubKeys)) {
+		return nil, fmt.Errorf("assumed honest %d must be between 1 and the number of keys %d", assumedHonest, len(pubKeys))
+	}
+	seen := make(map[string]int, len(pubKeys))
+	for i, pk := range pubKeys {
+		encoded := string(blsSignatures.PublicKeyToBytes(pk))
+		if j, ok := seen[encoded]; ok {
+			return nil, fmt.Errorf("public keys %d and %d are identical", j, i)
+		}
+		seen[encoded] = i
+	}
+	keyset := &daprovider.DataAvailabilityKeyset{
+		AssumedHonest: assumedHonest,
+		PubKeys:       pubKeys,
+	}
+	if _, err := keyset.Hash(); err != nil {
+		return nil, err
+	}
+	return keyset, nil
+}
+
+// GenerateKeyset generates numMembers fresh BLS keypairs and the keyset made of their
+// public keys. It's mostly useful for test committees, as real committee members should
+// generate their own keys with datool keygen.
+func GenerateKeyset(numMembers int, assumedHonest uint64) (*daprovider.DataAvailabilityKeyset, []blsSignatures.PrivateKey, error) {
+	pubKeys := make([]blsSignatures.PublicKey, numMembers)
+	privKeys := make([]blsSignatures.PrivateKey, numMembers)
+	for i := 0; i < numMembers; i++ {
+		var err error
+		pubKeys[i], privKeys[i], err = blsSignatures.GenerateKeys()
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	keyset, err := NewKeyset(assumedHonest, pubKeys)
+	if err != nil {
+		return nil, nil, err
+	}
+	return keyset, privKeys, nil
+}
+
+// SerializeKeysetWithHash returns the serialized keyset, as posted in SetValidKeyset, and its hash.
+func SerializeKeysetWithHash(keyset *daprovider.DataAvailabilityKeyset) ([]byte, common.Hash, error) {
+	buf := bytes.NewBuffer([]byte{})
+	if err := keyset.Serialize(buf); err != nil {
+		return nil, common.Hash{}, err
+	}
+	hash, err := keyset.Hash()
+	if err != nil {
+		return nil, common.Hash{}, err
+	}
+	return buf.Bytes(), hash, nil
+}
+
+// SetValidKeysetCalldata returns the calldata for the SequencerInbox setValidKeyset call registering keysetBytes.
+func SetValidKeysetCalldata(keysetBytes []byte) ([]byte, error) {
+	if _, err := daprovider.DeserializeKeyset(bytes.NewReader(keysetBytes), false); err != nil {
+		return nil, fmt.Errorf("invalid keyset: %w", err)
+	}
+	seqInboxABI, err := bridgegen.SequencerInboxMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	return seqInboxABI.Pack("setValidKeyset", keysetBytes)
+}
+
+// InvalidateKeysetHashCalldata returns the calldata for the SequencerInbox invalidateKeysetHash call,
+// which retires a superseded keyset once the new one is in use.
+func InvalidateKeysetHashCalldata(keysetHash common.Hash) ([]byte, error) {
+	seqInboxABI, err := bridgegen.SequencerInboxMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	return seqInboxABI.Pack("invalidateKeysetHash", keysetHash)
+}
+
+// VerifyCertificateWithKeyset checks that cert names keyset and carries a quorum signature from it.
+func VerifyCertificateWithKeyset(cert *daprovider.DataAvailabilityCertificate, keyset *daprovider.DataAvailabilityKeyset) error {
+	keysetHash, err := keyset.Hash()
+	if err != nil {
+		return err
+	}
+	if cert.KeysetHash != keysetHash {
+		return fmt.Errorf("certificate keyset %v does not match keyset %v", pretty.PrettyHash(cert.KeysetHash), pretty.PrettyHash(keysetHash))
+	}
+	return keyset.VerifySignature(cert.SignersMask, cert.SerializeSignableFields(), cert.Sig)
+}
+
+type keysetRecord struct {
+	keyset           *daprovider.DataAvailabilityKeyset
+	keysetBytes      []byte
+	validFromBlock   uint64
+	invalidatedBlock uint64 // zero while still valid
+}
+
+// KeysetHistory tracks every keyset registered with the SequencerInbox and the L1 block range
+// in which it was valid, so certificates can be checked against the keyset that was active when
+// their batch was posted, including keysets that have since been superseded.
+type KeysetHistory struct {
+	mutex   sync.RWMutex
+	records map[common.Hash]*keysetRecord
+}
+
+func NewKeysetHistory() *KeysetHistory {
+	return &KeysetHistory{
+		records: make(map[common.Hash]*keysetRecord),
+	}
+}
+
+// AddKeyset records that keysetBytes became valid at l1Block.
+func (h *KeysetHistory) AddKeyset(keysetBytes []byte, l1Block uint64) (common.Hash, error) {
+	keyset, err := daprovider.DeserializeKeyset(bytes.NewReader(keysetBytes), true)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	hash := dastree.Hash(keysetBytes)
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if existing, ok := h.records[hash]; ok && existing.invalidatedBlock == 0 {
+		return hash, nil
+	}
+	h.records[hash] = &keysetRecord{
+		keyset:         keyset,
+		keysetBytes:    keysetBytes,
+		validFromBlock: l1Block,
+	}
+	return hash, nil
+}
+
+// InvalidateKeyset records that the keyset with the given hash was invalidated at l1Block.
+func (h *KeysetHistory) InvalidateKeyset(keysetHash common.Hash, l1Block uint64) error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	record, ok := h.records[keysetHash]
+	if !ok {
+		return fmt.Errorf("unknown keyset %v", pretty.PrettyHash(keysetHash))
+	}
+	if record.invalidatedBlock == 0 {
+		record.invalidatedBlock = l1Block
+	}
+	return nil
+}
+
+// ActiveKeysets returns the hashes of all keysets that have not been invalidated.
+func (h *KeysetHistory) ActiveKeysets() []common.Hash {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	var active []common.Hash
+	for hash, record := range h.records {
+		if record.invalidatedBlock == 0 {
+			active = append(active, hash)
+		}
+	}
+	return active
+}
+
+// GetKeysetByHash implements daprovider.DASKeysetFetcher, returning superseded keysets as well as active ones.
+func (h *KeysetHistory) GetKeysetByHash(ctx context.Context, hash common.Hash) ([]byte, error) {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	record, ok := h.records[hash]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return record.keysetBytes, nil
+}
+
+// VerifyCertificate checks cert against the keyset it names, requiring that keyset to have been
+// valid at l1Block, the L1 block in which the certificate's batch was posted.
+func (h *KeysetHistory) VerifyCertificate(cert *daprovider.DataAvailabilityCertificate, l1Block uint64) error {
+	h.mutex.RLock()
+	record, ok := h.records[cert.KeysetHash]
+	h.mutex.RUnlock()
+	if !ok {
+		return fmt.Errorf("certificate uses unknown keyset %v", pretty.PrettyHash(cert.KeysetHash))
+	}
+	if l1Block < record.validFromBlock {
+		return fmt.Errorf("keyset %v was not yet valid at L1 block %d", pretty.PrettyHash(cert.KeysetHash), l1Block)
+	}
+	if record.invalidatedBlock != 0 && l1Block >= record.invalidatedBlock {
+		return fmt.Errorf("keyset %v was invalidated at L1 block %d before L1 block %d", pretty.PrettyHash(cert.KeysetHash), record.invalidatedBlock, l1Block)
+	}
+	return VerifyCertificateWithKeyset(cert, record.keyset)
+}
+
+// LoadKeysetHistory builds a KeysetHistory from the SetValidKeyset and InvalidateKeyset events
+// emitted by the SequencerInbox between fromBlock and toBlock inclusive.
+func LoadKeysetHistory(ctx context.Context, seqInbox *bridgegen.SequencerInboxFilterer, fromBlock, toBlock uint64) (*KeysetHistory, error) {
+	history := NewKeysetHistory()
+	filterOpts := &bind.FilterOpts{
+		Start:   fromBlock,
+		End:     &toBlock,
+		Context: ctx,
+	}
+	setIter, err := seqInbox.FilterSetValidKeyset(filterOpts, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer setIter.Close()
+	for setIter.Next() {
+		hash, err := history.AddKeyset(setIter.Event.KeysetBytes, setIter.Event.Raw.BlockNumber)
+		if err != nil {
+			log.Warn("ignoring invalid keyset registered with sequencer inbox", "block", setIter.Event.Raw.BlockNumber, "err", err)
+			continue
+		}
+		if hash != setIter.Event.KeysetHash {
+			log.Warn("keyset hash in event does not match keyset bytes", "event", pretty.PrettyHash(setIter.Event.KeysetHash), "computed", pretty.PrettyHash(hash))
+		}
+	}
+	if err := setIter.Error(); err != nil {
+		return nil, err
+	}
+	invalidateIter, err := seqInbox.FilterInvalidateKeyset(filterOpts, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer invalidateIter.Close()
+	for invalidateIter.Next() {
+		err := history.InvalidateKeyset(invalidateIter.Event.KeysetHash, invalidateIter.Event.Raw.BlockNumber)
+		if err != nil {
+			log.Warn("sequencer inbox invalidated keyset registered before range", "err", err)
+		}
+	}
+	if err := invalidateIter.Error(); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
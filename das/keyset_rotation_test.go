@@ -0,0 +1,131 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package das
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/offchainlabs/nitro/arbstate/daprovider"
+	"github.com/offchainlabs/nitro/blsSignatures"
+	"github.com/offchainlabs/nitro/das/dastree"
+)
+
+func signCert(t *testing.T, keyset *daprovider.DataAvailabilityKeyset, privKeys []blsSignatures.PrivateKey, signers []int, data []byte) *daprovider.DataAvailabilityCertificate {
+	t.Helper()
+	keysetHash, err := keyset.Hash()
+	Require(t, err)
+	cert := &daprovider.DataAvailabilityCertificate{
+		KeysetHash: keysetHash,
+		DataHash:   dastree.Hash(data),
+		Timeout:    1000,
+		Version:    1,
+	}
+	var sigs []blsSignatures.Signature
+	for _, i := range signers {
+		sig, err := blsSignatures.SignMessage(privKeys[i], cert.SerializeSignableFields())
+		Require(t, err)
+		sigs = append(sigs, sig)
+		cert.SignersMask |= 1 << i
+	}
+	cert.Sig = blsSignatures.AggregateSignatures(sigs)
+	return cert
+}
+
+func TestNewKeysetValidation(t *testing.T) {
+	keyset, _, err := GenerateKeyset(3, 2)
+	Require(t, err)
+	if _, err := NewKeyset(0, keyset.PubKeys); err == nil {
+		Fail(t, "expected error for zero assumed honest")
+	}
+	if _, err := NewKeyset(4, keyset.PubKeys); err == nil {
+		Fail(t, "expected error for assumed honest larger than the committee")
+	}
+	duplicated := append(append([]blsSignatures.PublicKey{}, keyset.PubKeys...), keyset.PubKeys[0])
+	if _, err := NewKeyset(1, duplicated); err == nil {
+		Fail(t, "expected error for duplicated public key")
+	}
+
+	keysetBytes, keysetHash, err := SerializeKeysetWithHash(keyset)
+	Require(t, err)
+	if !dastree.ValidHash(keysetHash, keysetBytes) {
+		Fail(t, "keyset hash does not match serialized keyset")
+	}
+	deserialized, err := daprovider.DeserializeKeyset(bytes.NewReader(keysetBytes), false)
+	Require(t, err)
+	if deserialized.AssumedHonest != 2 || len(deserialized.PubKeys) != 3 {
+		Fail(t, "keyset did not round trip", deserialized)
+	}
+	if _, err := SetValidKeysetCalldata(keysetBytes); err != nil {
+		Fail(t, "failed to build setValidKeyset calldata", err)
+	}
+	if _, err := SetValidKeysetCalldata(keysetBytes[:len(keysetBytes)-1]); err == nil {
+		Fail(t, "expected error building calldata for truncated keyset")
+	}
+}
+
+func TestVerifyCertificateQuorum(t *testing.T) {
+	// With 4 members and 2 assumed honest, up to 1 member may fail to sign.
+	keyset, privKeys, err := GenerateKeyset(4, 2)
+	Require(t, err)
+	data := []byte("batch data")
+
+	Require(t, VerifyCertificateWithKeyset(signCert(t, keyset, privKeys, []int{0, 1, 2, 3}, data), keyset))
+	Require(t, VerifyCertificateWithKeyset(signCert(t, keyset, privKeys, []int{0, 2, 3}, data), keyset))
+	if err := VerifyCertificateWithKeyset(signCert(t, keyset, privKeys, []int{0, 3}, data), keyset); err == nil {
+		Fail(t, "expected certificate without quorum to be rejected")
+	}
+
+	other, _, err := GenerateKeyset(4, 2)
+	Require(t, err)
+	if err := VerifyCertificateWithKeyset(signCert(t, keyset, privKeys, []int{0, 1, 2, 3}, data), other); err == nil {
+		Fail(t, "expected certificate for a different keyset to be rejected")
+	}
+}
+
+func TestKeysetHistorySupersededKeysets(t *testing.T) {
+	ctx := context.Background()
+	oldKeyset, oldPrivKeys, err := GenerateKeyset(2, 1)
+	Require(t, err)
+	newKeyset, newPrivKeys, err := GenerateKeyset(3, 2)
+	Require(t, err)
+	oldBytes, oldHash, err := SerializeKeysetWithHash(oldKeyset)
+	Require(t, err)
+	newBytes, newHash, err := SerializeKeysetWithHash(newKeyset)
+	Require(t, err)
+
+	history := NewKeysetHistory()
+	_, err = history.AddKeyset(oldBytes, 10)
+	Require(t, err)
+	_, err = history.AddKeyset(newBytes, 100)
+	Require(t, err)
+	Require(t, history.InvalidateKeyset(oldHash, 150))
+
+	active := history.ActiveKeysets()
+	if len(active) != 1 || active[0] != newHash {
+		Fail(t, "expected only the new keyset to be active", active)
+	}
+	fetched, err := history.GetKeysetByHash(ctx, oldHash)
+	Require(t, err)
+	if !bytes.Equal(fetched, oldBytes) {
+		Fail(t, "superseded keyset not returned")
+	}
+
+	data := []byte("historical batch")
+	oldCert := signCert(t, oldKeyset, oldPrivKeys, []int{0, 1}, data)
+	Require(t, history.VerifyCertificate(oldCert, 120))
+	if err := history.VerifyCertificate(oldCert, 150); err == nil {
+		Fail(t, "expected certificate posted after invalidation to be rejected")
+	}
+	if err := history.VerifyCertificate(oldCert, 5); err == nil {
+		Fail(t, "expected certificate posted before registration to be rejected")
+	}
+
+	newCert := signCert(t, newKeyset, newPrivKeys, []int{0, 1, 2}, data)
+	Require(t, history.VerifyCertificate(newCert, 200))
+	if err := history.VerifyCertificate(newCert, 99); err == nil {
+		Fail(t, "expected certificate posted before the new keyset was registered to be rejected")
+	}
+}
@@ -16,6 +16,7 @@ import (
 
 type MemoryBackedStorageService struct { // intended for testing and debugging
 	contents map[[32]byte][]byte
+	expiries map[[32]byte]uint64
 	rwmutex  sync.RWMutex
 	closed   bool
 }
@@ -25,6 +26,7 @@ var ErrClosed = errors.New("cannot access a StorageService that has been Closed"
 func NewMemoryBackedStorageService(ctx context.Context) StorageService {
 	return &MemoryBackedStorageService{
 		contents: make(map[[32]byte][]byte),
+		expiries: make(map[[32]byte]uint64),
 	}
 }
 
@@ -49,7 +51,40 @@ func (m *MemoryBackedStorageService) Put(ctx context.Context, data []byte, expir
 	if m.closed {
 		return ErrClosed
 	}
-	m.contents[dastree.Hash(data)] = append([]byte{}, data...)
+	key := dastree.Hash(data)
+	m.contents[key] = append([]byte{}, data...)
+	if m.expiries[key] < expirationTime {
+		m.expiries[key] = expirationTime
+	}
+	return nil
+}
+
+func (m *MemoryBackedStorageService) Delete(ctx context.Context, key common.Hash) error {
+	log.Trace("das.MemoryBackedStorageService.Delete", "key", key, "this", m)
+	m.rwmutex.Lock()
+	defer m.rwmutex.Unlock()
+	if m.closed {
+		return ErrClosed
+	}
+	if _, found := m.contents[key]; !found {
+		return ErrNotFound
+	}
+	delete(m.contents, key)
+	delete(m.expiries, key)
+	return nil
+}
+
+func (m *MemoryBackedStorageService) ForEachExpiry(ctx context.Context, fn func(key common.Hash, expiry uint64, size uint64) error) error {
+	m.rwmutex.RLock()
+	defer m.rwmutex.RUnlock()
+	if m.closed {
+		return ErrClosed
+	}
+	for key, data := range m.contents {
+		if err := fn(key, m.expiries[key], uint64(len(data))); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -71,6 +71,57 @@ func (c *RestfulDasClient) GetByHash(ctx context.Context, hash common.Hash) ([]b
 	return decodedBytes, nil
 }
 
+// GetByHashes fetches the data for up to MaxGetByHashesBatchSize hashes in one request.
+// The returned slice is in the same order as hashes, with a nil entry for data the server didn't have.
+func (c *RestfulDasClient) GetByHashes(ctx context.Context, hashes []common.Hash) ([][]byte, error) {
+	if len(hashes) > MaxGetByHashesBatchSize {
+		return nil, fmt.Errorf("too many hashes in batch request: %d > %d", len(hashes), MaxGetByHashesBatchSize)
+	}
+	reqBody, err := json.Marshal(RestfulDasServerBatchRequest{Hashes: hashes})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url+getByHashesRequestPath, bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP error with status %d returned by server: %s", res.StatusCode, http.StatusText(res.StatusCode))
+	}
+
+	var response RestfulDasServerBatchResponse
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+	if len(response.Results) != len(hashes) {
+		return nil, fmt.Errorf("server returned %d results for %d hashes", len(response.Results), len(hashes))
+	}
+	ret := make([][]byte, len(hashes))
+	for i, result := range response.Results {
+		if result.Hash != hashes[i] {
+			return nil, fmt.Errorf("server returned result for %v at position of %v", result.Hash, hashes[i])
+		}
+		if result.Error != "" {
+			continue
+		}
+		data, err := base64.StdEncoding.DecodeString(result.Data)
+		if err != nil {
+			return nil, err
+		}
+		if !dastree.ValidHash(hashes[i], data) {
+			return nil, daprovider.ErrHashMismatch
+		}
+		ret[i] = data
+	}
+	return ret, nil
+}
+
 func (c *RestfulDasClient) HealthCheck(ctx context.Context) error {
 	res, err := http.Get(c.url + healthRequestPath)
 	if err != nil {
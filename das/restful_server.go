@@ -4,6 +4,7 @@
 package das
 
 import (
+	"bytes"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
@@ -28,6 +29,12 @@ var (
 	restGetByHashFailureGauge       = metrics.NewRegisteredGauge("arb/das/rest/getbyhash/failure", nil)
 	restGetByHashReturnedBytesGauge = metrics.NewRegisteredGauge("arb/das/rest/getbyhash/bytes", nil)
 	restGetByHashDurationHistogram  = metrics.NewRegisteredHistogram("arb/das/rest/getbyhash/duration", nil, metrics.NewBoundedHistogramSample())
+
+	restGetByHashesRequestGauge       = metrics.NewRegisteredGauge("arb/das/rest/getbyhashes/requests", nil)
+	restGetByHashesSuccessGauge       = metrics.NewRegisteredGauge("arb/das/rest/getbyhashes/success", nil)
+	restGetByHashesFailureGauge       = metrics.NewRegisteredGauge("arb/das/rest/getbyhashes/failure", nil)
+	restGetByHashesReturnedBytesGauge = metrics.NewRegisteredGauge("arb/das/rest/getbyhashes/bytes", nil)
+	restGetByHashesDurationHistogram  = metrics.NewRegisteredHistogram("arb/das/rest/getbyhashes/duration", nil, metrics.NewBoundedHistogramSample())
 )
 
 type RestfulDasServer struct {
@@ -78,6 +85,23 @@ type RestfulDasServerResponse struct {
 	ExpirationPolicy string `json:"expirationPolicy,omitempty"`
 }
 
+// RestfulDasServerBatchRequest is the body of a POST to the get-by-hashes endpoint.
+type RestfulDasServerBatchRequest struct {
+	Hashes []common.Hash `json:"hashes"`
+}
+
+// RestfulDasServerBatchResult holds either the base64 encoded data for a hash or the
+// reason it couldn't be retrieved.
+type RestfulDasServerBatchResult struct {
+	Hash  common.Hash `json:"hash"`
+	Data  string      `json:"data,omitempty"`
+	Error string      `json:"error,omitempty"`
+}
+
+type RestfulDasServerBatchResponse struct {
+	Results []RestfulDasServerBatchResult `json:"results"`
+}
+
 var cacheControlKey = http.CanonicalHeaderKey("cache-control")
 
 const cacheControlValueDefault = "public, max-age=1"                                 // cache for up to 1 second (Used to reduce DOS possibility)
@@ -85,6 +109,14 @@ const cacheControlValueForSuccessfulGetByHash = "public, max-age=2419200, immuta
 const healthRequestPath = "/health"
 const expirationPolicyRequestPath = "/expiration-policy/"
 const getByHashRequestPath = "/get-by-hash/"
+const getByHashesRequestPath = "/get-by-hashes"
+const dataRequestPath = "/data/"
+
+// MaxGetByHashesBatchSize is the maximum number of hashes accepted in one get-by-hashes request.
+const MaxGetByHashesBatchSize = 64
+
+// the request body only needs to hold MaxGetByHashesBatchSize hex-encoded hashes plus JSON syntax
+const maxGetByHashesRequestBodySize = MaxGetByHashesBatchSize*(2*common.HashLength+8) + 64
 
 func (rds *RestfulDasServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	w.Header()[cacheControlKey] = []string{cacheControlValueDefault}
@@ -95,8 +127,12 @@ func (rds *RestfulDasServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		rds.HealthHandler(w, r, requestPath)
 	case strings.HasPrefix(requestPath, expirationPolicyRequestPath):
 		rds.ExpirationPolicyHandler(w, r, requestPath)
+	case strings.HasPrefix(requestPath, getByHashesRequestPath):
+		rds.GetByHashesHandler(w, r, requestPath)
 	case strings.HasPrefix(requestPath, getByHashRequestPath):
 		rds.GetByHashHandler(w, r, requestPath)
+	case strings.HasPrefix(requestPath, dataRequestPath):
+		rds.DataHandler(w, r, requestPath)
 	default:
 		log.Warn("Unknown requestPath", "requestPath", requestPath)
 		w.WriteHeader(http.StatusBadRequest)
@@ -171,19 +207,113 @@ func (rds *RestfulDasServer) GetByHashHandler(w http.ResponseWriter, r *http.Req
 	}
 	log.Trace("RestfulDasServer.ServeHTTP returning", "message", pretty.FirstFewBytes(responseData), "message length", len(responseData))
 
-	encodedResponseData := make([]byte, base64.StdEncoding.EncodedLen(len(responseData)))
-	base64.StdEncoding.Encode(encodedResponseData, responseData)
 	var response RestfulDasServerResponse
-	response.Data = string(encodedResponseData)
+	response.Data = base64.StdEncoding.EncodeToString(responseData)
 	restGetByHashReturnedBytesGauge.Inc(int64(len(response.Data)))
 
+	// Headers must be set before the body is written for them to take effect.
+	w.Header()[cacheControlKey] = []string{cacheControlValueForSuccessfulGetByHash}
 	err = json.NewEncoder(w).Encode(response)
 	if err != nil {
 		log.Warn("Failed encoding and writing response", "path", requestPath, "err", err)
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
+	success = true
+}
+
+// DataHandler serves the raw bytes for a hash. Since the content is addressed by its hash it is
+// immutable, so the hash doubles as a strong ETag, and byte range requests are supported.
+func (rds *RestfulDasServer) DataHandler(w http.ResponseWriter, r *http.Request, requestPath string) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	hash, err := DecodeStorageServiceKey(strings.TrimPrefix(requestPath, dataRequestPath))
+	if err != nil {
+		log.Warn("Failed to decode hex-encoded hash", "path", requestPath, "err", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	etag := `"` + hash.Hex() + `"`
+	if r.Header.Get("If-None-Match") == etag {
+		// The client already has the data, no need to fetch it from storage. A wildcard only matches data
+		// that exists, so it's left to ServeContent once the data is found.
+		w.Header()[cacheControlKey] = []string{cacheControlValueForSuccessfulGetByHash}
+		w.Header().Set("ETag", etag)
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	data, err := rds.daReader.GetByHash(r.Context(), hash)
+	if err != nil {
+		log.Warn("Unable to find data", "path", requestPath, "err", err, "remoteAddr", r.RemoteAddr)
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
 	w.Header()[cacheControlKey] = []string{cacheControlValueForSuccessfulGetByHash}
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Content-Type", "application/octet-stream")
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(data))
+}
+
+// GetByHashesHandler serves a POST of up to MaxGetByHashesBatchSize hashes, returning the data
+// for each in request order. Hashes that can't be retrieved are reported individually rather
+// than failing the whole request.
+func (rds *RestfulDasServer) GetByHashesHandler(w http.ResponseWriter, r *http.Request, requestPath string) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	restGetByHashesRequestGauge.Inc(1)
+	start := time.Now()
+	success := false
+	defer func() {
+		if success {
+			restGetByHashesSuccessGauge.Inc(1)
+		} else {
+			restGetByHashesFailureGauge.Inc(1)
+		}
+		restGetByHashesDurationHistogram.Update(time.Since(start).Nanoseconds())
+	}()
+
+	var request RestfulDasServerBatchRequest
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxGetByHashesRequestBodySize)).Decode(&request)
+	if err != nil {
+		log.Warn("Failed to decode get-by-hashes request", "path", requestPath, "err", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if len(request.Hashes) == 0 || len(request.Hashes) > MaxGetByHashesBatchSize {
+		log.Warn("Invalid number of hashes in get-by-hashes request", "path", requestPath, "count", len(request.Hashes))
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	response := RestfulDasServerBatchResponse{
+		Results: make([]RestfulDasServerBatchResult, len(request.Hashes)),
+	}
+	returnedBytes := 0
+	for i, hash := range request.Hashes {
+		response.Results[i].Hash = hash
+		data, err := rds.daReader.GetByHash(r.Context(), hash)
+		if err != nil {
+			log.Debug("Unable to find data for get-by-hashes request", "hash", pretty.PrettyHash(hash), "err", err)
+			response.Results[i].Error = ErrNotFound.Error()
+			continue
+		}
+		response.Results[i].Data = base64.StdEncoding.EncodeToString(data)
+		returnedBytes += len(response.Results[i].Data)
+	}
+	restGetByHashesReturnedBytesGauge.Inc(int64(returnedBytes))
+
+	// Keep the default short-lived cache-control since the result set depends on which data has expired.
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		log.Warn("Failed encoding and writing response", "path", requestPath, "err", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
 	success = true
 }
 
@@ -8,11 +8,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net"
+	"net/http"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
+
 	"github.com/offchainlabs/nitro/cmd/genericconf"
 	"github.com/offchainlabs/nitro/das/dastree"
 )
@@ -68,3 +72,80 @@ func TestRestfulClientServer(t *testing.T) {
 	err = server.Shutdown()
 	Require(t, err)
 }
+
+func TestRestfulServerBatchAndRawData(t *testing.T) {
+	initTest(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	storage := NewMemoryBackedStorageService(ctx)
+	data := []byte("Testing batch retrieval from a restful server.")
+	dataHash := dastree.Hash(data)
+	absentHash := dastree.Hash([]byte("absent data"))
+
+	server, port, err := NewRestfulDasServerOnRandomPort(LocalServerAddressForTest, storage)
+	Require(t, err)
+	defer func() {
+		Require(t, server.Shutdown())
+	}()
+
+	err = storage.Put(ctx, data, uint64(time.Now().Add(time.Hour).Unix()))
+	Require(t, err)
+
+	time.Sleep(100 * time.Millisecond)
+
+	client := NewRestfulDasClient("http", LocalServerAddressForTest, port)
+	results, err := client.GetByHashes(ctx, []common.Hash{absentHash, dataHash})
+	Require(t, err)
+	if len(results) != 2 || results[0] != nil || !bytes.Equal(results[1], data) {
+		Fail(t, "unexpected batch results", results)
+	}
+
+	url := fmt.Sprintf("http://%s:%d%s%s", LocalServerAddressForTest, port, dataRequestPath, EncodeStorageServiceKey(dataHash))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	Require(t, err)
+	req.Header.Set("Range", "bytes=8-12")
+	res, err := http.DefaultClient.Do(req)
+	Require(t, err)
+	body, err := io.ReadAll(res.Body)
+	Require(t, err)
+	res.Body.Close()
+	if res.StatusCode != http.StatusPartialContent || !bytes.Equal(body, data[8:13]) {
+		Fail(t, "unexpected range response", res.StatusCode, string(body))
+	}
+	etag := res.Header.Get("ETag")
+	if etag == "" || !strings.Contains(res.Header.Get("Cache-Control"), "immutable") {
+		Fail(t, "missing caching headers", res.Header)
+	}
+
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	Require(t, err)
+	req.Header.Set("If-None-Match", etag)
+	res, err = http.DefaultClient.Do(req)
+	Require(t, err)
+	res.Body.Close()
+	if res.StatusCode != http.StatusNotModified {
+		Fail(t, "expected 304 for matching ETag, got", res.StatusCode)
+	}
+
+	// a wildcard only matches data that exists
+	for _, test := range []struct {
+		hash   common.Hash
+		status int
+	}{
+		{dataHash, http.StatusNotModified},
+		{absentHash, http.StatusNotFound},
+	} {
+		url := fmt.Sprintf("http://%s:%d%s%s", LocalServerAddressForTest, port, dataRequestPath, EncodeStorageServiceKey(test.hash))
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		Require(t, err)
+		req.Header.Set("If-None-Match", "*")
+		res, err = http.DefaultClient.Do(req)
+		Require(t, err)
+		res.Body.Close()
+		if res.StatusCode != test.status {
+			Fail(t, "unexpected status for wildcard If-None-Match", res.StatusCode, "expected", test.status)
+		}
+	}
+}
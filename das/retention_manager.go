@@ -0,0 +1,357 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package das
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	badger "github.com/dgraph-io/badger/v4"
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+
+	"github.com/offchainlabs/nitro/arbstate/daprovider"
+	"github.com/offchainlabs/nitro/das/dastree"
+	"github.com/offchainlabs/nitro/util/pretty"
+	"github.com/offchainlabs/nitro/util/stopwaiter"
+)
+
+var (
+	retentionPrunedCounter         = metrics.NewRegisteredCounter("arb/das/retention/pruned", nil)
+	retentionReclaimedBytesCounter = metrics.NewRegisteredCounter("arb/das/retention/reclaimed_bytes", nil)
+	retentionPruneFailureCounter   = metrics.NewRegisteredCounter("arb/das/retention/prune_failure", nil)
+	retentionPruneDurationGauge    = metrics.NewRegisteredGauge("arb/das/retention/prune_duration", nil)
+)
+
+type RetentionConfig struct {
+	Enable         bool          `koanf:"enable"`
+	IndexDir       string        `koanf:"index-dir"`
+	PruneInterval  time.Duration `koanf:"prune-interval"`
+	MaxPrunePerRun int           `koanf:"max-prune-per-run"`
+}
+
+var DefaultRetentionConfig = RetentionConfig{
+	Enable:         false,
+	IndexDir:       "",
+	PruneInterval:  5 * time.Minute,
+	MaxPrunePerRun: 10_000,
+}
+
+func RetentionConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.Bool(prefix+".enable", DefaultRetentionConfig.Enable, "enable tracking of expiry timestamps and background pruning of expired batch data from storage backends that support deletion and discard data after its expiry (currently s3-storage with discard-after-timeout)")
+	f.String(prefix+".index-dir", DefaultRetentionConfig.IndexDir, "directory in which to store the expiry index; if empty the index is kept in memory and rebuilt from storage on start")
+	f.Duration(prefix+".prune-interval", DefaultRetentionConfig.PruneInterval, "how often to prune expired batch data")
+	f.Int(prefix+".max-prune-per-run", DefaultRetentionConfig.MaxPrunePerRun, "maximum number of expired entries to remove per pruning run")
+}
+
+// DeletableStorageService is implemented by storage backends that can remove
+// individual entries, which is required for them to be managed by a RetentionManager.
+type DeletableStorageService interface {
+	StorageService
+	Delete(ctx context.Context, key common.Hash) error
+}
+
+// ExpiryListingStorageService is implemented by storage backends that can list what they store along with its
+// expiry time, which lets a RetentionManager rebuild an index that was lost on restart.
+type ExpiryListingStorageService interface {
+	ForEachExpiry(ctx context.Context, fn func(key common.Hash, expiry uint64, size uint64) error) error
+}
+
+// Keys in the expiry index:
+//
+//	expiryIndexPrefix | expiry (8 bytes, big endian) | data hash -> size of the data (8 bytes)
+//	hashIndexPrefix | data hash -> expiry (8 bytes)
+//
+// The expiry-ordered entries let pruning stop at the first unexpired key, and the hash
+// entries let a re-Put of the same data extend its expiry instead of duplicating it.
+var (
+	expiryIndexPrefix = []byte("x")
+	hashIndexPrefix   = []byte("h")
+)
+
+func expiryIndexKey(expiry uint64, key common.Hash) []byte {
+	ret := make([]byte, 0, len(expiryIndexPrefix)+8+common.HashLength)
+	ret = append(ret, expiryIndexPrefix...)
+	ret = binary.BigEndian.AppendUint64(ret, expiry)
+	return append(ret, key.Bytes()...)
+}
+
+func hashIndexKey(key common.Hash) []byte {
+	return append(append([]byte{}, hashIndexPrefix...), key.Bytes()...)
+}
+
+// RetentionManager tracks the expiry time of every batch stored through it and
+// periodically deletes expired batches from the wrapped storage service.
+type RetentionManager struct {
+	stopwaiter.StopWaiter
+	config      RetentionConfig
+	baseStorage DeletableStorageService
+	index       *badger.DB
+}
+
+func NewRetentionManager(config RetentionConfig, baseStorage DeletableStorageService) (*RetentionManager, error) {
+	if config.PruneInterval <= 0 {
+		return nil, errors.New("retention prune-interval must be positive")
+	}
+	if config.MaxPrunePerRun <= 0 {
+		return nil, errors.New("retention max-prune-per-run must be positive")
+	}
+	options := badger.DefaultOptions(config.IndexDir).WithLogger(nil)
+	if config.IndexDir == "" {
+		options = options.WithInMemory(true)
+	}
+	index, err := badger.Open(options)
+	if err != nil {
+		return nil, fmt.Errorf("error opening retention index: %w", err)
+	}
+	return &RetentionManager{
+		config:      config,
+		baseStorage: baseStorage,
+		index:       index,
+	}, nil
+}
+
+func (r *RetentionManager) Start(ctx context.Context) {
+	r.StopWaiter.Start(ctx, r)
+	rebuilt := false
+	r.CallIteratively(func(ctx context.Context) time.Duration {
+		if !rebuilt {
+			if err := r.RebuildIndex(ctx); err != nil {
+				log.Error("error rebuilding the DAS retention index, will retry next run", "err", err)
+			} else {
+				rebuilt = true
+			}
+		}
+		// #nosec G115
+		if _, _, err := r.Prune(ctx, uint64(time.Now().Unix())); err != nil {
+			log.Error("error pruning expired DAS data", "err", err)
+		}
+		return r.config.PruneInterval
+	})
+}
+
+// discardsExpired returns whether the base storage's expiration policy is to discard data once it expires, which is
+// the only case in which its data is tracked and pruned. Otherwise, everything it stores is kept forever.
+func (r *RetentionManager) discardsExpired(ctx context.Context) (bool, error) {
+	policy, err := r.baseStorage.ExpirationPolicy(ctx)
+	if err != nil {
+		return false, err
+	}
+	return policy == daprovider.DiscardAfterDataTimeout, nil
+}
+
+// RebuildIndex tracks the expiry of everything in the base storage if the index is empty, as it is on every start
+// without an index-dir, so data stored before a restart is still pruned. Base storage that can't list its
+// contents, or that keeps its data forever, is left untracked.
+func (r *RetentionManager) RebuildIndex(ctx context.Context) error {
+	discards, err := r.discardsExpired(ctx)
+	if err != nil || !discards {
+		return err
+	}
+	lister, ok := r.baseStorage.(ExpiryListingStorageService)
+	if !ok {
+		log.Warn("DAS storage can't list its contents, data stored before this start won't be pruned", "storage", r.baseStorage)
+		return nil
+	}
+	empty := true
+	err = r.index.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		it := txn.NewIterator(opts)
+		defer it.Close()
+		it.Rewind()
+		empty = !it.Valid()
+		return nil
+	})
+	if err != nil || !empty {
+		return err
+	}
+	start := time.Now()
+	tracked := 0
+	err = lister.ForEachExpiry(ctx, func(key common.Hash, expiry uint64, size uint64) error {
+		if expiry == math.MaxUint64 {
+			return nil
+		}
+		tracked++
+		return r.track(key, expiry, size)
+	})
+	if err != nil {
+		return err
+	}
+	log.Info("rebuilt the DAS retention index from storage", "tracked", tracked, "duration", time.Since(start))
+	return nil
+}
+
+func (r *RetentionManager) GetByHash(ctx context.Context, key common.Hash) ([]byte, error) {
+	log.Trace("das.RetentionManager.GetByHash", "key", pretty.PrettyHash(key), "this", r)
+	return r.baseStorage.GetByHash(ctx, key)
+}
+
+func (r *RetentionManager) Put(ctx context.Context, data []byte, expiry uint64) error {
+	logPut("das.RetentionManager.Put", data, expiry, r)
+	if err := r.baseStorage.Put(ctx, data, expiry); err != nil {
+		return err
+	}
+	discards, err := r.discardsExpired(ctx)
+	if err != nil || !discards {
+		return err
+	}
+	return r.track(dastree.Hash(data), expiry, uint64(len(data)))
+}
+
+// track records (or extends) the expiry time of the data with the given hash.
+func (r *RetentionManager) track(key common.Hash, expiry uint64, size uint64) error {
+	return r.index.Update(func(txn *badger.Txn) error {
+		item, err := txn.Get(hashIndexKey(key))
+		if err == nil {
+			var existing uint64
+			err = item.Value(func(val []byte) error {
+				if len(val) != 8 {
+					return fmt.Errorf("corrupt retention index entry for %v", key)
+				}
+				existing = binary.BigEndian.Uint64(val)
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			if existing >= expiry {
+				return nil
+			}
+			if err := txn.Delete(expiryIndexKey(existing, key)); err != nil {
+				return err
+			}
+		} else if !errors.Is(err, badger.ErrKeyNotFound) {
+			return err
+		}
+		if err := txn.Set(hashIndexKey(key), binary.BigEndian.AppendUint64(nil, expiry)); err != nil {
+			return err
+		}
+		return txn.Set(expiryIndexKey(expiry, key), binary.BigEndian.AppendUint64(nil, size))
+	})
+}
+
+type expiredEntry struct {
+	key    common.Hash
+	expiry uint64
+	size   uint64
+}
+
+// collectExpired returns up to limit tracked entries whose expiry is strictly before now.
+func (r *RetentionManager) collectExpired(now uint64, limit int) ([]expiredEntry, error) {
+	var expired []expiredEntry
+	err := r.index.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = expiryIndexPrefix
+		it := txn.NewIterator(opts)
+		defer it.Close()
+		for it.Rewind(); it.Valid() && len(expired) < limit; it.Next() {
+			item := it.Item()
+			k := item.Key()
+			if len(k) != len(expiryIndexPrefix)+8+common.HashLength {
+				return fmt.Errorf("corrupt retention index key %v", pretty.FirstFewBytes(k))
+			}
+			expiry := binary.BigEndian.Uint64(k[len(expiryIndexPrefix):])
+			if expiry >= now {
+				break
+			}
+			entry := expiredEntry{
+				key:    common.BytesToHash(k[len(expiryIndexPrefix)+8:]),
+				expiry: expiry,
+			}
+			err := item.Value(func(val []byte) error {
+				if len(val) == 8 {
+					entry.size = binary.BigEndian.Uint64(val)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			expired = append(expired, entry)
+		}
+		return nil
+	})
+	return expired, err
+}
+
+// Prune deletes up to MaxPrunePerRun entries that expired before now from the base
+// storage, returning the number of entries and bytes reclaimed.
+func (r *RetentionManager) Prune(ctx context.Context, now uint64) (int, uint64, error) {
+	start := time.Now()
+	expired, err := r.collectExpired(now, r.config.MaxPrunePerRun)
+	if err != nil {
+		return 0, 0, err
+	}
+	pruned := 0
+	var reclaimed uint64
+	for _, entry := range expired {
+		if ctx.Err() != nil {
+			break
+		}
+		err := r.baseStorage.Delete(ctx, entry.key)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			retentionPruneFailureCounter.Inc(1)
+			log.Warn("failed to prune expired DAS data, will retry next run", "key", pretty.PrettyHash(entry.key), "err", err)
+			continue
+		}
+		err = r.index.Update(func(txn *badger.Txn) error {
+			if err := txn.Delete(expiryIndexKey(entry.expiry, entry.key)); err != nil {
+				return err
+			}
+			return txn.Delete(hashIndexKey(entry.key))
+		})
+		if err != nil {
+			return pruned, reclaimed, err
+		}
+		pruned++
+		reclaimed += entry.size
+	}
+	retentionPrunedCounter.Inc(int64(pruned))
+	if reclaimed <= math.MaxInt64 {
+		// #nosec G115
+		retentionReclaimedBytesCounter.Inc(int64(reclaimed))
+	}
+	retentionPruneDurationGauge.Update(time.Since(start).Milliseconds())
+	if pruned > 0 {
+		log.Info("pruned expired DAS data", "count", pruned, "bytes", reclaimed, "duration", time.Since(start))
+	}
+	return pruned, reclaimed, nil
+}
+
+func (r *RetentionManager) Sync(ctx context.Context) error {
+	if err := r.index.Sync(); err != nil {
+		return err
+	}
+	return r.baseStorage.Sync(ctx)
+}
+
+func (r *RetentionManager) Close(ctx context.Context) error {
+	if r.Started() {
+		r.StopAndWait()
+	}
+	if err := r.index.Close(); err != nil {
+		return err
+	}
+	return r.baseStorage.Close(ctx)
+}
+
+func (r *RetentionManager) ExpirationPolicy(ctx context.Context) (daprovider.ExpirationPolicy, error) {
+	return r.baseStorage.ExpirationPolicy(ctx)
+}
+
+func (r *RetentionManager) String() string {
+	return fmt.Sprintf("RetentionManager(%v)", r.baseStorage)
+}
+
+func (r *RetentionManager) HealthCheck(ctx context.Context) error {
+	return r.baseStorage.HealthCheck(ctx)
+}
@@ -0,0 +1,165 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package das
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/offchainlabs/nitro/arbstate/daprovider"
+	"github.com/offchainlabs/nitro/das/dastree"
+)
+
+// discardingStorageService is memory-backed storage that discards data once it expires,
+// like S3 storage with discard-after-timeout.
+type discardingStorageService struct {
+	*MemoryBackedStorageService
+}
+
+func newDiscardingStorageService(ctx context.Context) discardingStorageService {
+	return discardingStorageService{NewMemoryBackedStorageService(ctx).(*MemoryBackedStorageService)}
+}
+
+func (s discardingStorageService) ExpirationPolicy(ctx context.Context) (daprovider.ExpirationPolicy, error) {
+	return daprovider.DiscardAfterDataTimeout, nil
+}
+
+func TestRetentionManagerPrunesExpired(t *testing.T) {
+	ctx := context.Background()
+	base := newDiscardingStorageService(ctx)
+	config := DefaultRetentionConfig
+	config.Enable = true
+	retention, err := NewRetentionManager(config, base)
+	Require(t, err)
+	defer func() {
+		Require(t, retention.Close(ctx))
+	}()
+
+	short := []byte("expires early")
+	long := []byte("expires late")
+	extended := []byte("stored twice with a later expiry")
+
+	Require(t, retention.Put(ctx, short, 100))
+	Require(t, retention.Put(ctx, long, 300))
+	Require(t, retention.Put(ctx, extended, 100))
+	Require(t, retention.Put(ctx, extended, 250))
+
+	pruned, reclaimed, err := retention.Prune(ctx, 200)
+	Require(t, err)
+	if pruned != 1 || reclaimed != uint64(len(short)) {
+		t.Fatalf("expected to prune 1 entry of %d bytes, pruned %d entries of %d bytes", len(short), pruned, reclaimed)
+	}
+	if _, err := retention.GetByHash(ctx, dastree.Hash(short)); !errors.Is(err, ErrNotFound) {
+		t.Fatal("expected expired data to be pruned, got", err)
+	}
+	for _, data := range [][]byte{long, extended} {
+		val, err := retention.GetByHash(ctx, dastree.Hash(data))
+		Require(t, err)
+		if !bytes.Equal(val, data) {
+			t.Fatal(val, data)
+		}
+	}
+
+	pruned, _, err = retention.Prune(ctx, 1000)
+	Require(t, err)
+	if pruned != 2 {
+		t.Fatal("expected to prune remaining 2 entries, pruned", pruned)
+	}
+
+	// Entries already missing from the base storage are dropped from the index.
+	Require(t, retention.Put(ctx, short, 100))
+	Require(t, base.Delete(ctx, dastree.Hash(short)))
+	pruned, _, err = retention.Prune(ctx, 1000)
+	Require(t, err)
+	if pruned != 1 {
+		t.Fatal("expected to drop index entry for missing data, pruned", pruned)
+	}
+}
+
+func TestRetentionManagerPruneLimit(t *testing.T) {
+	ctx := context.Background()
+	config := DefaultRetentionConfig
+	config.MaxPrunePerRun = 2
+	retention, err := NewRetentionManager(config, newDiscardingStorageService(ctx))
+	Require(t, err)
+	defer func() {
+		Require(t, retention.Close(ctx))
+	}()
+
+	for i := 0; i < 5; i++ {
+		Require(t, retention.Put(ctx, []byte{byte(i)}, uint64(i)))
+	}
+	for _, expected := range []int{2, 2, 1, 0} {
+		pruned, _, err := retention.Prune(ctx, 10)
+		Require(t, err)
+		if pruned != expected {
+			t.Fatalf("expected to prune %d entries, pruned %d", expected, pruned)
+		}
+	}
+}
+
+func TestRetentionManagerRebuildsIndex(t *testing.T) {
+	ctx := context.Background()
+	base := newDiscardingStorageService(ctx)
+	first, err := NewRetentionManager(DefaultRetentionConfig, base)
+	Require(t, err)
+	short := []byte("stored before the restart")
+	long := []byte("kept after the restart")
+	Require(t, first.Put(ctx, short, 100))
+	Require(t, first.Put(ctx, long, 300))
+	Require(t, first.index.Close())
+
+	// the restarted manager's in-memory index starts out empty
+	restarted, err := NewRetentionManager(DefaultRetentionConfig, base)
+	Require(t, err)
+	defer func() {
+		Require(t, restarted.Close(ctx))
+	}()
+	Require(t, restarted.RebuildIndex(ctx))
+	pruned, reclaimed, err := restarted.Prune(ctx, 200)
+	Require(t, err)
+	if pruned != 1 || reclaimed != uint64(len(short)) {
+		t.Fatalf("expected to prune 1 entry of %d bytes, pruned %d entries of %d bytes", len(short), pruned, reclaimed)
+	}
+	if _, err := base.GetByHash(ctx, dastree.Hash(short)); !errors.Is(err, ErrNotFound) {
+		t.Fatal("expected data stored before the restart to be pruned, got", err)
+	}
+	if _, err := base.GetByHash(ctx, dastree.Hash(long)); err != nil {
+		t.Fatal("expected unexpired data to be kept, got", err)
+	}
+
+	// a non-empty index isn't rebuilt, so pruned entries aren't tracked again
+	Require(t, restarted.RebuildIndex(ctx))
+	pruned, _, err = restarted.Prune(ctx, 200)
+	Require(t, err)
+	if pruned != 0 {
+		t.Fatal("expected nothing more to prune, pruned", pruned)
+	}
+}
+
+func TestRetentionManagerKeepsUndiscardedData(t *testing.T) {
+	ctx := context.Background()
+	base := NewMemoryBackedStorageService(ctx).(*MemoryBackedStorageService)
+	data := []byte("kept forever")
+	Require(t, base.Put(ctx, data, 100))
+	retention, err := NewRetentionManager(DefaultRetentionConfig, base)
+	Require(t, err)
+	defer func() {
+		Require(t, retention.Close(ctx))
+	}()
+
+	// storage that keeps its data past expiry has nothing tracked, whether stored before or after the start
+	Require(t, retention.RebuildIndex(ctx))
+	Require(t, retention.Put(ctx, []byte("also kept forever"), 100))
+	pruned, _, err := retention.Prune(ctx, 1000)
+	Require(t, err)
+	if pruned != 0 {
+		t.Fatal("expected nothing to be pruned, pruned", pruned)
+	}
+	if _, err := base.GetByHash(ctx, dastree.Hash(data)); err != nil {
+		t.Fatal("expected data to be kept, got", err)
+	}
+}
@@ -27,6 +27,7 @@ import (
 type BackendConfig struct {
 	URL    string `koanf:"url" json:"url"`
 	Pubkey string `koanf:"pubkey" json:"pubkey"`
+	Weight uint64 `koanf:"weight" json:"weight,omitempty"`
 }
 
 type BackendConfigList []BackendConfig
@@ -123,6 +124,7 @@ func ParseServices(config AggregatorConfig, signer signature.DataSignerFunc) ([]
 		if err != nil {
 			return nil, err
 		}
+		d.SetWeight(b.Weight)
 
 		services = append(services, *d)
 	}
@@ -13,7 +13,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/offchainlabs/nitro/blsSignatures"
 	"github.com/offchainlabs/nitro/cmd/genericconf"
 	"github.com/offchainlabs/nitro/util/signature"
@@ -144,3 +146,35 @@ func TestRPCStore(t *testing.T) {
 		})
 	}
 }
+
+func TestRPCStoreReport(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// a DAS RPC server in front of an aggregator reports how its committee handled the store
+	aggregator := newWeightedTestAggregator(t, ctx, AggregatorConfig{AssumedHonest: 1}, []uint64{1, 1}, []failureType{success, success})
+	storageService := NewMemoryBackedStorageService(ctx)
+	verifier := &SignatureVerifier{
+		extraBpVerifier: func(message []byte, sig []byte, extraFields ...uint64) bool { return true },
+	}
+	lis, err := net.Listen("tcp", "localhost:0")
+	testhelpers.RequireImpl(t, err)
+	dasServer, err := StartDASRPCServerOnListener(ctx, lis, genericconf.HTTPServerTimeoutConfigDefault, genericconf.HTTPServerBodyLimitDefault, storageService, aggregator, storageService, verifier)
+	testhelpers.RequireImpl(t, err)
+	defer func() {
+		testhelpers.RequireImpl(t, dasServer.Shutdown(ctx))
+	}()
+
+	client, err := rpc.DialContext(ctx, "http://"+lis.Addr().String())
+	testhelpers.RequireImpl(t, err)
+	defer client.Close()
+	var result StoreResult
+	err = client.CallContext(ctx, &result, "das_store", hexutil.Bytes("It's time for you to see the fnords."), hexutil.Uint64(0), hexutil.Bytes{})
+	testhelpers.RequireImpl(t, err)
+	if result.Report == nil {
+		testhelpers.FailImpl(t, "das_store didn't return the aggregator's store report")
+	}
+	if !bytes.Equal(result.Report.DataHash[:], result.DataHash) || len(result.Report.Backends) != 2 || result.Report.StoredCount < result.Report.RequiredCount {
+		testhelpers.FailImpl(t, "wrong store report", result.Report)
+	}
+}
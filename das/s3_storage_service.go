@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"io"
 	"math"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -123,6 +124,59 @@ func (s3s *S3StorageService) Put(ctx context.Context, value []byte, timeout uint
 	return err
 }
 
+func (s3s *S3StorageService) Delete(ctx context.Context, key common.Hash) error {
+	log.Trace("das.S3StorageService.Delete", "key", pretty.PrettyHash(key), "this", s3s)
+	_, err := s3s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s3s.bucket),
+		Key:    aws.String(s3s.objectPrefix + EncodeStorageServiceKey(key)),
+	})
+	return err
+}
+
+// ForEachExpiry lists the stored objects and reads back the expiry time each was uploaded with. Objects stored
+// without an expiry are reported as never expiring.
+func (s3s *S3StorageService) ForEachExpiry(ctx context.Context, fn func(key common.Hash, expiry uint64, size uint64) error) error {
+	paginator := s3.NewListObjectsV2Paginator(s3s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s3s.bucket),
+		Prefix: aws.String(s3s.objectPrefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return err
+		}
+		for _, object := range page.Contents {
+			name := strings.TrimPrefix(aws.ToString(object.Key), s3s.objectPrefix)
+			key, err := DecodeStorageServiceKey(name)
+			if err != nil || len(name) != 2*common.HashLength {
+				log.Debug("skipping object that isn't DAS data", "key", aws.ToString(object.Key))
+				continue
+			}
+			head, err := s3s.client.HeadObject(ctx, &s3.HeadObjectInput{
+				Bucket: aws.String(s3s.bucket),
+				Key:    object.Key,
+			})
+			if err != nil {
+				return err
+			}
+			expiry := uint64(math.MaxUint64)
+			if head.Expires != nil && head.Expires.Unix() >= 0 {
+				// #nosec G115
+				expiry = uint64(head.Expires.Unix())
+			}
+			size := uint64(0)
+			if object.Size > 0 {
+				// #nosec G115
+				size = uint64(object.Size)
+			}
+			if err := fn(key, expiry, size); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 func (s3s *S3StorageService) Sync(ctx context.Context) error {
 	return nil
 }
@@ -0,0 +1,186 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+// Package execrpc carries the interfaces between the consensus node (inbox, transaction streamer, staker) and
+// the execution node (geth core) over gRPC, so the two can run as separate processes. The execution node serves
+// ExecutionServerAPI and drives the consensus node through a ConsensusRPCClient, and the consensus node serves
+// ConsensusServerAPI and drives execution through an ExecutionRPCClient.
+package execrpc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/nitro/arbos/arbostypes"
+	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/execution"
+)
+
+// Errors callers check with errors.Is. They only cross the gRPC boundary as messages, so clients restore them.
+var sentinelErrors = []error{
+	execution.ErrRetrySequencer,
+	execution.ErrSequencerInsertLockTaken,
+	execution.ErrMessageNotExecuted,
+}
+
+func restoreError(err error) error {
+	if err == nil {
+		return nil
+	}
+	for _, sentinel := range sentinelErrors {
+		if strings.Contains(err.Error(), sentinel.Error()) {
+			return fmt.Errorf("%w: %v", sentinel, err)
+		}
+	}
+	return err
+}
+
+type ExecutionServerAPI struct {
+	exec execution.FullExecutionClient
+}
+
+func NewExecutionServerAPI(exec execution.FullExecutionClient) *ExecutionServerAPI {
+	return &ExecutionServerAPI{exec}
+}
+
+func (a *ExecutionServerAPI) DigestMessage(num arbutil.MessageIndex, msg *arbostypes.MessageWithMetadata, msgForPrefetch *arbostypes.MessageWithMetadata) (*execution.MessageResult, error) {
+	return a.exec.DigestMessage(num, msg, msgForPrefetch)
+}
+
+func (a *ExecutionServerAPI) Reorg(count arbutil.MessageIndex, newMessages []arbostypes.MessageWithMetadataAndBlockHash, oldMessages []*arbostypes.MessageWithMetadata) ([]*execution.MessageResult, error) {
+	return a.exec.Reorg(count, newMessages, oldMessages)
+}
+
+func (a *ExecutionServerAPI) HeadMessageNumber() (arbutil.MessageIndex, error) {
+	return a.exec.HeadMessageNumber()
+}
+
+func (a *ExecutionServerAPI) ResultAtPos(pos arbutil.MessageIndex) (*execution.MessageResult, error) {
+	return a.exec.ResultAtPos(pos)
+}
+
+func (a *ExecutionServerAPI) ArbOSVersionForMessageNumber(messageNum arbutil.MessageIndex) (uint64, error) {
+	return a.exec.ArbOSVersionForMessageNumber(messageNum)
+}
+
+func (a *ExecutionServerAPI) RecordBlockCreation(ctx context.Context, pos arbutil.MessageIndex, msg *arbostypes.MessageWithMetadata) (*execution.RecordResult, error) {
+	return a.exec.RecordBlockCreation(ctx, pos, msg)
+}
+
+func (a *ExecutionServerAPI) MarkValid(pos arbutil.MessageIndex, resultHash common.Hash) {
+	a.exec.MarkValid(pos, resultHash)
+}
+
+func (a *ExecutionServerAPI) PrepareForRecord(ctx context.Context, start, end arbutil.MessageIndex) error {
+	return a.exec.PrepareForRecord(ctx, start, end)
+}
+
+func (a *ExecutionServerAPI) Pause() {
+	a.exec.Pause()
+}
+
+func (a *ExecutionServerAPI) Activate() {
+	a.exec.Activate()
+}
+
+func (a *ExecutionServerAPI) ForwardTo(url string) error {
+	return a.exec.ForwardTo(url)
+}
+
+func (a *ExecutionServerAPI) SequenceDelayedMessage(message *arbostypes.L1IncomingMessage, delayedSeqNum uint64) error {
+	return a.exec.SequenceDelayedMessage(message, delayedSeqNum)
+}
+
+func (a *ExecutionServerAPI) NextDelayedMessageNumber() (uint64, error) {
+	return a.exec.NextDelayedMessageNumber()
+}
+
+func (a *ExecutionServerAPI) ParentChainTiming() (execution.ParentChainTiming, error) {
+	return a.exec.ParentChainTiming()
+}
+
+func (a *ExecutionServerAPI) BatchDictionaryCommitted(messageNum arbutil.MessageIndex, hash common.Hash) (bool, error) {
+	return a.exec.BatchDictionaryCommitted(messageNum, hash)
+}
+
+func (a *ExecutionServerAPI) MarkFeedStart(to arbutil.MessageIndex) {
+	a.exec.MarkFeedStart(to)
+}
+
+func (a *ExecutionServerAPI) Synced() bool {
+	return a.exec.Synced()
+}
+
+func (a *ExecutionServerAPI) FullSyncProgressMap() map[string]interface{} {
+	return a.exec.FullSyncProgressMap()
+}
+
+func (a *ExecutionServerAPI) Maintenance() error {
+	return a.exec.Maintenance()
+}
+
+// BatchContainingMessage is the result of FindInboxBatchContainingMessage, which RPC can't return as two values.
+type BatchContainingMessage struct {
+	Batch uint64 `json:"batch"`
+	Found bool   `json:"found"`
+}
+
+type ConsensusServerAPI struct {
+	consensus execution.FullConsensusClient
+}
+
+func NewConsensusServerAPI(consensus execution.FullConsensusClient) *ConsensusServerAPI {
+	return &ConsensusServerAPI{consensus}
+}
+
+func (a *ConsensusServerAPI) FindInboxBatchContainingMessage(message arbutil.MessageIndex) (BatchContainingMessage, error) {
+	batch, found, err := a.consensus.FindInboxBatchContainingMessage(message)
+	return BatchContainingMessage{Batch: batch, Found: found}, err
+}
+
+func (a *ConsensusServerAPI) GetBatchParentChainBlock(seqNum uint64) (uint64, error) {
+	return a.consensus.GetBatchParentChainBlock(seqNum)
+}
+
+func (a *ConsensusServerAPI) GetBatchMessageCount(seqNum uint64) (arbutil.MessageIndex, error) {
+	return a.consensus.GetBatchMessageCount(seqNum)
+}
+
+func (a *ConsensusServerAPI) GetBatchCount() (uint64, error) {
+	return a.consensus.GetBatchCount()
+}
+
+func (a *ConsensusServerAPI) Synced() bool {
+	return a.consensus.Synced()
+}
+
+func (a *ConsensusServerAPI) FullSyncProgressMap() map[string]interface{} {
+	return a.consensus.FullSyncProgressMap()
+}
+
+func (a *ConsensusServerAPI) SyncTargetMessageCount() arbutil.MessageIndex {
+	return a.consensus.SyncTargetMessageCount()
+}
+
+func (a *ConsensusServerAPI) GetSafeMsgCount(ctx context.Context) (arbutil.MessageIndex, error) {
+	return a.consensus.GetSafeMsgCount(ctx)
+}
+
+func (a *ConsensusServerAPI) GetFinalizedMsgCount(ctx context.Context) (arbutil.MessageIndex, error) {
+	return a.consensus.GetFinalizedMsgCount(ctx)
+}
+
+func (a *ConsensusServerAPI) ValidatedMessageCount() (arbutil.MessageIndex, error) {
+	return a.consensus.ValidatedMessageCount()
+}
+
+func (a *ConsensusServerAPI) WriteMessageFromSequencer(pos arbutil.MessageIndex, msgWithMeta arbostypes.MessageWithMetadata, msgResult execution.MessageResult) error {
+	return a.consensus.WriteMessageFromSequencer(pos, msgWithMeta, msgResult)
+}
+
+func (a *ConsensusServerAPI) ExpectChosenSequencer() error {
+	return a.consensus.ExpectChosenSequencer()
+}
@@ -0,0 +1,290 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package execrpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/offchainlabs/nitro/arbos/arbostypes"
+	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/execution"
+	"github.com/offchainlabs/nitro/util/stopwaiter"
+)
+
+// ExecutionRPCClient drives an execution node in another process through its ExecutionServerAPI.
+type ExecutionRPCClient struct {
+	stopwaiter.StopWaiter
+	client grpcClient
+}
+
+func NewExecutionRPCClient(config ClientConfigFetcher) *ExecutionRPCClient {
+	return &ExecutionRPCClient{
+		client: grpcClient{config: config, service: ExecutionService},
+	}
+}
+
+func (c *ExecutionRPCClient) Start(ctx context.Context) error {
+	if err := c.client.start(ctx); err != nil {
+		return err
+	}
+	c.StopWaiter.Start(ctx, c)
+	return nil
+}
+
+func (c *ExecutionRPCClient) StopAndWait() {
+	c.StopWaiter.StopAndWait()
+	c.client.close()
+}
+
+func (c *ExecutionRPCClient) call(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	return c.client.call(ctx, result, method, args...)
+}
+
+// callAsync is for the methods of execution.FullExecutionClient that don't take a context
+func (c *ExecutionRPCClient) callAsync(result interface{}, method string, args ...interface{}) error {
+	ctx, err := c.GetContextSafe()
+	if err != nil {
+		return err
+	}
+	return c.call(ctx, result, method, args...)
+}
+
+func (c *ExecutionRPCClient) DigestMessage(num arbutil.MessageIndex, msg *arbostypes.MessageWithMetadata, msgForPrefetch *arbostypes.MessageWithMetadata) (*execution.MessageResult, error) {
+	var res execution.MessageResult
+	if err := c.callAsync(&res, "DigestMessage", num, msg, msgForPrefetch); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (c *ExecutionRPCClient) Reorg(count arbutil.MessageIndex, newMessages []arbostypes.MessageWithMetadataAndBlockHash, oldMessages []*arbostypes.MessageWithMetadata) ([]*execution.MessageResult, error) {
+	var res []*execution.MessageResult
+	err := c.callAsync(&res, "Reorg", count, newMessages, oldMessages)
+	return res, err
+}
+
+func (c *ExecutionRPCClient) HeadMessageNumber() (arbutil.MessageIndex, error) {
+	var res arbutil.MessageIndex
+	err := c.callAsync(&res, "HeadMessageNumber")
+	return res, err
+}
+
+func (c *ExecutionRPCClient) HeadMessageNumberSync(t *testing.T) (arbutil.MessageIndex, error) {
+	return c.HeadMessageNumber()
+}
+
+func (c *ExecutionRPCClient) ResultAtPos(pos arbutil.MessageIndex) (*execution.MessageResult, error) {
+	var res execution.MessageResult
+	if err := c.callAsync(&res, "ResultAtPos", pos); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (c *ExecutionRPCClient) ArbOSVersionForMessageNumber(messageNum arbutil.MessageIndex) (uint64, error) {
+	var res uint64
+	err := c.callAsync(&res, "ArbOSVersionForMessageNumber", messageNum)
+	return res, err
+}
+
+func (c *ExecutionRPCClient) RecordBlockCreation(ctx context.Context, pos arbutil.MessageIndex, msg *arbostypes.MessageWithMetadata) (*execution.RecordResult, error) {
+	var res execution.RecordResult
+	if err := c.call(ctx, &res, "RecordBlockCreation", pos, msg); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (c *ExecutionRPCClient) MarkValid(pos arbutil.MessageIndex, resultHash common.Hash) {
+	if err := c.callAsync(nil, "MarkValid", pos, resultHash); err != nil {
+		log.Warn("failed to mark block valid in execution", "pos", pos, "err", err)
+	}
+}
+
+func (c *ExecutionRPCClient) PrepareForRecord(ctx context.Context, start, end arbutil.MessageIndex) error {
+	return c.call(ctx, nil, "PrepareForRecord", start, end)
+}
+
+func (c *ExecutionRPCClient) Pause() {
+	if err := c.callAsync(nil, "Pause"); err != nil {
+		log.Error("failed to pause execution sequencer", "err", err)
+	}
+}
+
+func (c *ExecutionRPCClient) Activate() {
+	if err := c.callAsync(nil, "Activate"); err != nil {
+		log.Error("failed to activate execution sequencer", "err", err)
+	}
+}
+
+func (c *ExecutionRPCClient) ForwardTo(url string) error {
+	return c.callAsync(nil, "ForwardTo", url)
+}
+
+func (c *ExecutionRPCClient) SequenceDelayedMessage(message *arbostypes.L1IncomingMessage, delayedSeqNum uint64) error {
+	return c.callAsync(nil, "SequenceDelayedMessage", message, delayedSeqNum)
+}
+
+func (c *ExecutionRPCClient) NextDelayedMessageNumber() (uint64, error) {
+	var res uint64
+	err := c.callAsync(&res, "NextDelayedMessageNumber")
+	return res, err
+}
+
+func (c *ExecutionRPCClient) ParentChainTiming() (execution.ParentChainTiming, error) {
+	var res execution.ParentChainTiming
+	err := c.callAsync(&res, "ParentChainTiming")
+	return res, err
+}
+
+func (c *ExecutionRPCClient) BatchDictionaryCommitted(messageNum arbutil.MessageIndex, hash common.Hash) (bool, error) {
+	var res bool
+	err := c.callAsync(&res, "BatchDictionaryCommitted", messageNum, hash)
+	return res, err
+}
+
+func (c *ExecutionRPCClient) MarkFeedStart(to arbutil.MessageIndex) {
+	if err := c.callAsync(nil, "MarkFeedStart", to); err != nil {
+		log.Warn("failed to mark feed start in execution", "to", to, "err", err)
+	}
+}
+
+func (c *ExecutionRPCClient) Synced() bool {
+	var res bool
+	if err := c.callAsync(&res, "Synced"); err != nil {
+		log.Warn("failed to read execution sync status", "err", err)
+		return false
+	}
+	return res
+}
+
+func (c *ExecutionRPCClient) FullSyncProgressMap() map[string]interface{} {
+	var res map[string]interface{}
+	if err := c.callAsync(&res, "FullSyncProgressMap"); err != nil {
+		return map[string]interface{}{"executionRPCError": err.Error()}
+	}
+	return res
+}
+
+func (c *ExecutionRPCClient) Maintenance() error {
+	return c.callAsync(nil, "Maintenance")
+}
+
+// ConsensusRPCClient lets an execution node in its own process read from and write to the consensus node through
+// its ConsensusServerAPI.
+type ConsensusRPCClient struct {
+	stopwaiter.StopWaiter
+	client grpcClient
+}
+
+func NewConsensusRPCClient(config ClientConfigFetcher) *ConsensusRPCClient {
+	return &ConsensusRPCClient{
+		client: grpcClient{config: config, service: ConsensusService},
+	}
+}
+
+func (c *ConsensusRPCClient) Start(ctx context.Context) error {
+	if err := c.client.start(ctx); err != nil {
+		return err
+	}
+	c.StopWaiter.Start(ctx, c)
+	return nil
+}
+
+func (c *ConsensusRPCClient) StopAndWait() {
+	c.StopWaiter.StopAndWait()
+	c.client.close()
+}
+
+func (c *ConsensusRPCClient) call(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	return c.client.call(ctx, result, method, args...)
+}
+
+func (c *ConsensusRPCClient) callAsync(result interface{}, method string, args ...interface{}) error {
+	ctx, err := c.GetContextSafe()
+	if err != nil {
+		return err
+	}
+	return c.call(ctx, result, method, args...)
+}
+
+func (c *ConsensusRPCClient) FindInboxBatchContainingMessage(message arbutil.MessageIndex) (uint64, bool, error) {
+	var res BatchContainingMessage
+	err := c.callAsync(&res, "FindInboxBatchContainingMessage", message)
+	return res.Batch, res.Found, err
+}
+
+func (c *ConsensusRPCClient) GetBatchParentChainBlock(seqNum uint64) (uint64, error) {
+	var res uint64
+	err := c.callAsync(&res, "GetBatchParentChainBlock", seqNum)
+	return res, err
+}
+
+func (c *ConsensusRPCClient) GetBatchMessageCount(seqNum uint64) (arbutil.MessageIndex, error) {
+	var res arbutil.MessageIndex
+	err := c.callAsync(&res, "GetBatchMessageCount", seqNum)
+	return res, err
+}
+
+func (c *ConsensusRPCClient) GetBatchCount() (uint64, error) {
+	var res uint64
+	err := c.callAsync(&res, "GetBatchCount")
+	return res, err
+}
+
+func (c *ConsensusRPCClient) Synced() bool {
+	var res bool
+	if err := c.callAsync(&res, "Synced"); err != nil {
+		log.Warn("failed to read consensus sync status", "err", err)
+		return false
+	}
+	return res
+}
+
+func (c *ConsensusRPCClient) FullSyncProgressMap() map[string]interface{} {
+	var res map[string]interface{}
+	if err := c.callAsync(&res, "FullSyncProgressMap"); err != nil {
+		return map[string]interface{}{"consensusRPCError": err.Error()}
+	}
+	return res
+}
+
+func (c *ConsensusRPCClient) SyncTargetMessageCount() arbutil.MessageIndex {
+	var res arbutil.MessageIndex
+	if err := c.callAsync(&res, "SyncTargetMessageCount"); err != nil {
+		log.Warn("failed to read consensus sync target", "err", err)
+		return 0
+	}
+	return res
+}
+
+func (c *ConsensusRPCClient) GetSafeMsgCount(ctx context.Context) (arbutil.MessageIndex, error) {
+	var res arbutil.MessageIndex
+	err := c.call(ctx, &res, "GetSafeMsgCount")
+	return res, err
+}
+
+func (c *ConsensusRPCClient) GetFinalizedMsgCount(ctx context.Context) (arbutil.MessageIndex, error) {
+	var res arbutil.MessageIndex
+	err := c.call(ctx, &res, "GetFinalizedMsgCount")
+	return res, err
+}
+
+func (c *ConsensusRPCClient) ValidatedMessageCount() (arbutil.MessageIndex, error) {
+	var res arbutil.MessageIndex
+	err := c.callAsync(&res, "ValidatedMessageCount")
+	return res, err
+}
+
+func (c *ConsensusRPCClient) WriteMessageFromSequencer(pos arbutil.MessageIndex, msgWithMeta arbostypes.MessageWithMetadata, msgResult execution.MessageResult) error {
+	return c.callAsync(nil, "WriteMessageFromSequencer", pos, msgWithMeta, msgResult)
+}
+
+func (c *ConsensusRPCClient) ExpectChosenSequencer() error {
+	return c.callAsync(nil, "ExpectChosenSequencer")
+}
@@ -0,0 +1,108 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package execrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/nitro/arbos/arbostypes"
+	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/execution"
+	"github.com/offchainlabs/nitro/util/testhelpers"
+)
+
+var (
+	_ execution.FullExecutionClient = (*ExecutionRPCClient)(nil)
+	_ execution.FullConsensusClient = (*ConsensusRPCClient)(nil)
+)
+
+type mockExecution struct {
+	execution.FullExecutionClient
+	digested []*arbostypes.MessageWithMetadata
+}
+
+func (m *mockExecution) DigestMessage(num arbutil.MessageIndex, msg *arbostypes.MessageWithMetadata, _ *arbostypes.MessageWithMetadata) (*execution.MessageResult, error) {
+	if num != arbutil.MessageIndex(len(m.digested)) {
+		return nil, fmt.Errorf("expected message %d, got %d", len(m.digested), num)
+	}
+	m.digested = append(m.digested, msg)
+	return &execution.MessageResult{BlockHash: common.BigToHash(msg.Message.Header.L1BaseFee)}, nil
+}
+
+func (m *mockExecution) HeadMessageNumber() (arbutil.MessageIndex, error) {
+	return arbutil.MessageIndex(len(m.digested)) - 1, nil
+}
+
+func (m *mockExecution) SequenceDelayedMessage(*arbostypes.L1IncomingMessage, uint64) error {
+	return fmt.Errorf("%w: not main sequencer", execution.ErrRetrySequencer)
+}
+
+func TestExecutionRPCRoundTrip(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	exec := &mockExecution{}
+	serverConfig := DefaultExecutionServerConfig
+	serverConfig.Port = 0
+	server, err := NewServer(&serverConfig, ExecutionService, NewExecutionServerAPI(exec))
+	Require(t, err)
+	Require(t, server.Start(ctx))
+	defer server.StopAndWait()
+
+	clientConfig := DefaultClientConfig
+	clientConfig.URL = server.Addr().String()
+	client := NewExecutionRPCClient(func() *ClientConfig { return &clientConfig })
+	Require(t, client.Start(ctx))
+	defer client.StopAndWait()
+
+	for i := int64(0); i < 3; i++ {
+		msg := &arbostypes.MessageWithMetadata{
+			Message: &arbostypes.L1IncomingMessage{
+				Header: &arbostypes.L1IncomingMessageHeader{
+					Kind:      arbostypes.L1MessageType_L2Message,
+					Poster:    testhelpers.RandomAddress(),
+					L1BaseFee: common.Big2,
+				},
+				L2msg: []byte{byte(i)},
+			},
+			DelayedMessagesRead: uint64(i),
+		}
+		// #nosec G115
+		result, err := client.DigestMessage(arbutil.MessageIndex(i), msg, nil)
+		Require(t, err)
+		if result.BlockHash != common.BigToHash(common.Big2) {
+			Fail(t, "unexpected result", result)
+		}
+	}
+	if len(exec.digested) != 3 || exec.digested[2].DelayedMessagesRead != 2 || exec.digested[2].Message.L2msg[0] != 2 {
+		Fail(t, "messages weren't passed through", exec.digested)
+	}
+	head, err := client.HeadMessageNumber()
+	Require(t, err)
+	if head != 2 {
+		Fail(t, "unexpected head", head)
+	}
+	if _, err := client.DigestMessage(5, exec.digested[0], nil); err == nil {
+		Fail(t, "out of order message was accepted")
+	}
+	err = client.SequenceDelayedMessage(exec.digested[0].Message, 0)
+	if !errors.Is(err, execution.ErrRetrySequencer) {
+		Fail(t, "sentinel error wasn't restored", err)
+	}
+}
+
+func Require(t *testing.T, err error, printables ...interface{}) {
+	t.Helper()
+	testhelpers.RequireImpl(t, err, printables...)
+}
+
+func Fail(t *testing.T, printables ...interface{}) {
+	t.Helper()
+	testhelpers.FailImpl(t, printables...)
+}
@@ -0,0 +1,313 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package execrpc
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"strings"
+	"time"
+
+	flag "github.com/spf13/pflag"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/offchainlabs/nitro/util/signature"
+	"github.com/offchainlabs/nitro/util/stopwaiter"
+)
+
+const (
+	ExecutionService = "nitro.execution.Execution"
+	ConsensusService = "nitro.execution.Consensus"
+
+	// the largest message either side sends, which bounds the preimages of a recorded block
+	maxMessageSize = 256 * 1024 * 1024
+)
+
+type ServerConfig struct {
+	Enable    bool   `koanf:"enable"`
+	Addr      string `koanf:"addr"`
+	Port      uint64 `koanf:"port"`
+	JWTSecret string `koanf:"jwtsecret"`
+}
+
+var DefaultExecutionServerConfig = ServerConfig{
+	Enable:    false,
+	Addr:      "127.0.0.1",
+	Port:      9642,
+	JWTSecret: "",
+}
+
+var DefaultConsensusServerConfig = ServerConfig{
+	Enable:    false,
+	Addr:      "127.0.0.1",
+	Port:      9643,
+	JWTSecret: "",
+}
+
+func ServerConfigAddOptions(prefix string, f *flag.FlagSet, defaultConfig *ServerConfig) {
+	f.Bool(prefix+".enable", defaultConfig.Enable, "enable the gRPC server")
+	f.String(prefix+".addr", defaultConfig.Addr, "gRPC server listening interface")
+	f.Uint64(prefix+".port", defaultConfig.Port, "gRPC server listening port")
+	f.String(prefix+".jwtsecret", defaultConfig.JWTSecret, "path to file with the secret clients must present (empty to not require one)")
+}
+
+type ClientConfig struct {
+	URL            string        `koanf:"url"`
+	JWTSecret      string        `koanf:"jwtsecret"`
+	Timeout        time.Duration `koanf:"timeout" reload:"hot"`
+	ConnectionWait time.Duration `koanf:"connection-wait"`
+}
+
+// DefaultClientConfig has no url, which keeps the other side in this process.
+var DefaultClientConfig = ClientConfig{
+	URL:            "",
+	JWTSecret:      "",
+	Timeout:        time.Minute,
+	ConnectionWait: time.Minute,
+}
+
+type ClientConfigFetcher func() *ClientConfig
+
+func ClientConfigAddOptions(prefix string, f *flag.FlagSet, defaultConfig *ClientConfig) {
+	f.String(prefix+".url", defaultConfig.URL, "host:port of the gRPC server to connect to (empty to run it in this process)")
+	f.String(prefix+".jwtsecret", defaultConfig.JWTSecret, "path to file with the secret to present to the server")
+	f.Duration(prefix+".timeout", defaultConfig.Timeout, "per-call timeout (0-disabled)")
+	f.Duration(prefix+".connection-wait", defaultConfig.ConnectionWait, "how long to wait for the initial connection")
+}
+
+// jsonCodec encodes the services' messages as JSON, which every type crossing the boundary already supports,
+// so the services need no protobuf definitions.
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+func (jsonCodec) Name() string {
+	return "json"
+}
+
+var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// serviceDesc describes a gRPC service with a unary method for each exported method of api. A method's request
+// is the array of its arguments after an optional leading context, and its response is its result, if any.
+func serviceDesc(service string, api interface{}) *grpc.ServiceDesc {
+	apiValue := reflect.ValueOf(api)
+	desc := &grpc.ServiceDesc{
+		ServiceName: service,
+		HandlerType: (*interface{})(nil),
+	}
+	for i := 0; i < apiValue.NumMethod(); i++ {
+		name := apiValue.Type().Method(i).Name
+		desc.Methods = append(desc.Methods, grpc.MethodDesc{
+			MethodName: name,
+			Handler:    methodHandler("/"+service+"/"+name, apiValue.Method(i)),
+		})
+	}
+	return desc
+}
+
+func methodHandler(fullMethod string, method reflect.Value) func(interface{}, context.Context, func(interface{}) error, grpc.UnaryServerInterceptor) (interface{}, error) {
+	methodType := method.Type()
+	firstArg := 0
+	if methodType.NumIn() > 0 && methodType.In(0) == contextType {
+		firstArg = 1
+	}
+	return func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+		var rawArgs []json.RawMessage
+		if err := dec(&rawArgs); err != nil {
+			return nil, err
+		}
+		if len(rawArgs) != methodType.NumIn()-firstArg {
+			return nil, status.Errorf(codes.InvalidArgument, "expected %d arguments but got %d", methodType.NumIn()-firstArg, len(rawArgs))
+		}
+		handle := func(ctx context.Context, _ interface{}) (interface{}, error) {
+			args := make([]reflect.Value, methodType.NumIn())
+			if firstArg > 0 {
+				args[0] = reflect.ValueOf(ctx)
+			}
+			for i, rawArg := range rawArgs {
+				arg := reflect.New(methodType.In(firstArg + i))
+				if err := json.Unmarshal(rawArg, arg.Interface()); err != nil {
+					return nil, status.Errorf(codes.InvalidArgument, "invalid argument %d: %v", i, err)
+				}
+				args[firstArg+i] = arg.Elem()
+			}
+			var result interface{} = struct{}{}
+			for _, out := range method.Call(args) {
+				if out.Type() == errorType {
+					if !out.IsNil() {
+						return nil, out.Interface().(error)
+					}
+				} else {
+					result = out.Interface()
+				}
+			}
+			return result, nil
+		}
+		if interceptor == nil {
+			return handle(ctx, rawArgs)
+		}
+		return interceptor(ctx, rawArgs, &grpc.UnaryServerInfo{Server: srv, FullMethod: fullMethod}, handle)
+	}
+}
+
+// Server serves an ExecutionServerAPI or ConsensusServerAPI over gRPC.
+type Server struct {
+	stopwaiter.StopWaiter
+	config   *ServerConfig
+	server   *grpc.Server
+	listener net.Listener
+}
+
+func NewServer(config *ServerConfig, service string, api interface{}) (*Server, error) {
+	opts := []grpc.ServerOption{
+		grpc.ForceServerCodec(jsonCodec{}),
+		grpc.MaxRecvMsgSize(maxMessageSize),
+		grpc.MaxSendMsgSize(maxMessageSize),
+	}
+	if config.JWTSecret != "" {
+		secret, err := signature.LoadSigningKey(config.JWTSecret)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load gRPC server secret: %w", err)
+		}
+		opts = append(opts, grpc.UnaryInterceptor(requireSecret(secret.Hex())))
+	}
+	server := grpc.NewServer(opts...)
+	server.RegisterService(serviceDesc(service, api), api)
+	return &Server{
+		config: config,
+		server: server,
+	}, nil
+}
+
+func requireSecret(secret string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		for _, presented := range md.Get("authorization") {
+			token, found := strings.CutPrefix(presented, "Bearer ")
+			if found && subtle.ConstantTimeCompare([]byte(token), []byte(secret)) == 1 {
+				return handler(ctx, req)
+			}
+		}
+		return nil, status.Error(codes.Unauthenticated, "missing or wrong secret")
+	}
+}
+
+func (s *Server) Start(ctx context.Context) error {
+	listener, err := net.Listen("tcp", fmt.Sprintf("%s:%d", s.config.Addr, s.config.Port))
+	if err != nil {
+		return err
+	}
+	s.listener = listener
+	s.StopWaiter.Start(ctx, s)
+	s.LaunchThread(func(ctx context.Context) {
+		if err := s.server.Serve(listener); err != nil {
+			log.Error("gRPC server stopped", "addr", listener.Addr(), "err", err)
+		}
+	})
+	return nil
+}
+
+// Addr is the address the server is listening on, once it's started.
+func (s *Server) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+func (s *Server) StopAndWait() {
+	s.server.Stop()
+	s.StopWaiter.StopAndWait()
+}
+
+type secretCredentials string
+
+func (c secretCredentials) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(c)}, nil
+}
+
+func (c secretCredentials) RequireTransportSecurity() bool {
+	return false
+}
+
+// grpcClient calls the methods of a service served by a Server.
+type grpcClient struct {
+	config  ClientConfigFetcher
+	service string
+	conn    *grpc.ClientConn
+}
+
+func (c *grpcClient) start(ctx context.Context) error {
+	config := c.config()
+	if config.URL == "" {
+		return errors.New("no url provided for this connection")
+	}
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(
+			grpc.ForceCodec(jsonCodec{}),
+			grpc.MaxCallRecvMsgSize(maxMessageSize),
+			grpc.MaxCallSendMsgSize(maxMessageSize),
+		),
+	}
+	if config.JWTSecret != "" {
+		secret, err := signature.LoadSigningKey(config.JWTSecret)
+		if err != nil {
+			return fmt.Errorf("failed to load gRPC client secret: %w", err)
+		}
+		opts = append(opts, grpc.WithPerRPCCredentials(secretCredentials(secret.Hex())))
+	}
+	dialCtx := ctx
+	if config.ConnectionWait > 0 {
+		var cancel context.CancelFunc
+		dialCtx, cancel = context.WithTimeout(ctx, config.ConnectionWait)
+		defer cancel()
+		opts = append(opts, grpc.WithBlock())
+	}
+	conn, err := grpc.DialContext(dialCtx, config.URL, opts...)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %v: %w", config.URL, err)
+	}
+	c.conn = conn
+	return nil
+}
+
+func (c *grpcClient) close() {
+	if c.conn == nil {
+		return
+	}
+	if err := c.conn.Close(); err != nil {
+		log.Warn("failed to close gRPC connection", "service", c.service, "err", err)
+	}
+}
+
+func (c *grpcClient) call(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	if timeout := c.config().Timeout; timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	if args == nil {
+		args = []interface{}{}
+	}
+	if result == nil {
+		result = new(json.RawMessage)
+	}
+	return restoreError(c.conn.Invoke(ctx, "/"+c.service+"/"+method, args, result))
+}
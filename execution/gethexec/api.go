@@ -15,11 +15,18 @@ import (
 
 	"github.com/ethereum/go-ethereum/arbitrum"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/offchainlabs/nitro/arbos"
 	"github.com/offchainlabs/nitro/arbos/arbosState"
 	"github.com/offchainlabs/nitro/arbos/retryables"
+	"github.com/offchainlabs/nitro/arbos/storage"
+	"github.com/offchainlabs/nitro/execution/outboxproof"
+	"github.com/offchainlabs/nitro/precompiles"
 	"github.com/offchainlabs/nitro/util/arbmath"
 )
 
@@ -264,6 +271,65 @@ func (api *ArbDebugAPI) TimeoutQueue(ctx context.Context, blockNum rpc.BlockNumb
 	return queue, err
 }
 
+// StartStorageProfiling begins tallying ArbOS storage accesses per subspace, discarding any previous profile.
+// The profile covers every ArbOS state opened by this node while profiling, including by RPC calls.
+func (api *ArbDebugAPI) StartStorageProfiling(ctx context.Context) {
+	storage.StartAccessProfiling()
+}
+
+// StorageProfile returns the ArbOS storage accesses tallied so far, keyed by subspace.
+func (api *ArbDebugAPI) StorageProfile(ctx context.Context) (map[string]storage.SubspaceAccessStats, error) {
+	if !storage.AccessProfilingEnabled() {
+		return nil, errors.New("storage profiling isn't running")
+	}
+	return storage.AccessProfile(), nil
+}
+
+// StopStorageProfiling stops tallying ArbOS storage accesses and returns the final profile.
+func (api *ArbDebugAPI) StopStorageProfiling(ctx context.Context) (map[string]storage.SubspaceAccessStats, error) {
+	if !storage.AccessProfilingEnabled() {
+		return nil, errors.New("storage profiling isn't running")
+	}
+	return storage.StopAccessProfiling(), nil
+}
+
+// StartPrecompileGasAudit begins auditing the gas charged by precompile methods against the work of their calls,
+// discarding any previous audit.
+func (api *ArbDebugAPI) StartPrecompileGasAudit(ctx context.Context) {
+	precompiles.StartGasAudit()
+}
+
+// PrecompileGasAudit returns the audit so far, keyed by precompile method.
+func (api *ArbDebugAPI) PrecompileGasAudit(ctx context.Context) (map[string]precompiles.MethodGasAudit, error) {
+	if !precompiles.GasAuditEnabled() {
+		return nil, errors.New("precompile gas auditing isn't running")
+	}
+	return precompiles.GasAudit(), nil
+}
+
+// StopPrecompileGasAudit stops auditing precompile calls and returns the final audit.
+func (api *ArbDebugAPI) StopPrecompileGasAudit(ctx context.Context) (map[string]precompiles.MethodGasAudit, error) {
+	if !precompiles.GasAuditEnabled() {
+		return nil, errors.New("precompile gas auditing isn't running")
+	}
+	return precompiles.StopGasAudit(), nil
+}
+
+// ArbosUpgradeDryRun runs the ArbOS upgrade to the given version, or the scheduled upgrade if zero, on a copy
+// of the state at the given block and returns every storage slot, account code, balance and nonce it would change.
+func (api *ArbDebugAPI) ArbosUpgradeDryRun(ctx context.Context, blockNum rpc.BlockNumber, upgradeTo hexutil.Uint64) (*arbosState.UpgradeDryRunReport, error) {
+	blockNum, _ = api.blockchain.ClipToPostNitroGenesis(blockNum)
+	header := api.blockchain.GetHeaderByNumber(uint64(blockNum))
+	if header == nil {
+		return nil, fmt.Errorf("block %v not found", blockNum)
+	}
+	statedb, err := api.blockchain.StateAt(header.Root)
+	if err != nil {
+		return nil, err
+	}
+	return arbosState.DryRunArbosUpgrade(statedb, uint64(upgradeTo), api.blockchain.Config())
+}
+
 func stateAndHeader(blockchain *core.BlockChain, block uint64) (*arbosState.ArbosState, *types.Header, error) {
 	header := blockchain.GetHeaderByNumber(block)
 	if !blockchain.Config().IsArbitrumNitro(header.Number) {
@@ -358,3 +424,217 @@ func (api *ArbTraceForwarderAPI) Block(ctx context.Context, blockNum json.RawMes
 func (api *ArbTraceForwarderAPI) Filter(ctx context.Context, filter json.RawMessage) (*json.RawMessage, error) {
 	return api.forward(ctx, "arbtrace_filter", filter)
 }
+
+// ConfirmedHeaderFetcher returns the header of the L2 block of the rollup's latest confirmed assertion, or nil if
+// this node doesn't have that block yet.
+type ConfirmedHeaderFetcher func(ctx context.Context) (*types.Header, error)
+
+type ArbOutboxAPI struct {
+	blockchain      *core.BlockChain
+	chainDb         ethdb.Database
+	confirmedHeader ConfirmedHeaderFetcher
+}
+
+func NewArbOutboxAPI(blockchain *core.BlockChain, chainDb ethdb.Database) *ArbOutboxAPI {
+	return &ArbOutboxAPI{
+		blockchain: blockchain,
+		chainDb:    chainDb,
+	}
+}
+
+// SetConfirmedHeaderFetcher lets proofs default to the latest confirmed send root. It must be called before the
+// node is started.
+func (api *ArbOutboxAPI) SetConfirmedHeaderFetcher(fetcher ConfirmedHeaderFetcher) {
+	api.confirmedHeader = fetcher
+}
+
+// rootHeader returns the header of the block whose send root a proof is against: the latest confirmed one
+// by default, the latest one for the "latest" tag, or a block by number. Other tags are rejected.
+func (api *ArbOutboxAPI) rootHeader(ctx context.Context, rootBlock *rpc.BlockNumber) (*types.Header, error) {
+	if rootBlock == nil {
+		if api.confirmedHeader == nil {
+			return nil, errors.New("this node doesn't follow the rollup's confirmed assertions, a root block must be given")
+		}
+		header, err := api.confirmedHeader(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the latest confirmed block: %w", err)
+		}
+		if header == nil {
+			return nil, errors.New("this node doesn't have the latest confirmed block yet")
+		}
+		return header, nil
+	}
+	if *rootBlock == rpc.LatestBlockNumber {
+		return api.blockchain.CurrentBlock(), nil
+	}
+	if *rootBlock < 0 {
+		return nil, fmt.Errorf("unsupported root block tag %v", *rootBlock)
+	}
+	// #nosec G115
+	header := api.blockchain.GetHeaderByNumber(uint64(*rootBlock))
+	if header == nil {
+		return nil, fmt.Errorf("root block %d not found", *rootBlock)
+	}
+	return header, nil
+}
+
+type OutboxProofResult struct {
+	Proof       []common.Hash  `json:"proof"`
+	Index       hexutil.Uint64 `json:"index"`
+	L2Sender    common.Address `json:"l2Sender"`
+	To          common.Address `json:"to"`
+	L2Block     *hexutil.Big   `json:"l2Block"`
+	L1Block     *hexutil.Big   `json:"l1Block"`
+	L2Timestamp *hexutil.Big   `json:"l2Timestamp"`
+	Value       *hexutil.Big   `json:"value"`
+	Data        hexutil.Bytes  `json:"data"`
+	SendRoot    common.Hash    `json:"sendRoot"`
+	Size        hexutil.Uint64 `json:"size"`
+	Calldata    hexutil.Bytes  `json:"calldata"`
+}
+
+// OutboxProof returns the arguments and calldata for Outbox.executeTransaction to execute the
+// sendIndex'th L2 to L1 message of the transaction txHash, proven against the send root of
+// rootBlock (the latest confirmed one by default). A send root given by rootBlock must be
+// confirmed in the rollup before execution.
+func (api *ArbOutboxAPI) OutboxProof(ctx context.Context, txHash common.Hash, sendIndex *hexutil.Uint64, rootBlock *rpc.BlockNumber) (*OutboxProofResult, error) {
+	_, receipt, err := readReceipt(api.blockchain, api.chainDb, txHash)
+	if err != nil {
+		return nil, err
+	}
+	blockNumber := receipt.BlockNumber.Uint64()
+
+	root, err := api.rootHeader(ctx, rootBlock)
+	if err != nil {
+		return nil, err
+	}
+	rootNumber := root.Number.Uint64()
+	if rootNumber < blockNumber {
+		if rootBlock == nil {
+			return nil, fmt.Errorf("transaction block %d isn't confirmed yet, the latest confirmed block is %d", blockNumber, rootNumber)
+		}
+		return nil, fmt.Errorf("root block %d is older than transaction block %d", rootNumber, blockNumber)
+	}
+	state, rootHeader, err := stateAndHeader(api.blockchain, rootNumber)
+	if err != nil {
+		return nil, err
+	}
+	accumulatorRoot, err := state.SendMerkleAccumulator().Root()
+	if err != nil {
+		return nil, err
+	}
+	if accumulatorRoot != types.DeserializeHeaderExtraInformation(rootHeader).SendRoot {
+		return nil, fmt.Errorf("send merkle accumulator root %v doesn't match block %d send root", accumulatorRoot, rootNumber)
+	}
+
+	index := 0
+	if sendIndex != nil {
+		index = int(*sendIndex)
+	}
+	proof, err := outboxproof.ForReceipt(ctx, outboxproof.NewBlockChainBackend(api.blockchain), receipt, index, rootHeader)
+	if err != nil {
+		return nil, err
+	}
+	calldata, err := proof.ExecuteTransactionCalldata()
+	if err != nil {
+		return nil, err
+	}
+	return &OutboxProofResult{
+		Proof:       proof.Proof,
+		Index:       hexutil.Uint64(proof.Index),
+		L2Sender:    proof.L2Sender,
+		To:          proof.To,
+		L2Block:     (*hexutil.Big)(proof.L2Block),
+		L1Block:     (*hexutil.Big)(proof.L1Block),
+		L2Timestamp: (*hexutil.Big)(proof.L2Timestamp),
+		Value:       (*hexutil.Big)(proof.Value),
+		Data:        proof.Data,
+		SendRoot:    proof.SendRoot,
+		Size:        hexutil.Uint64(proof.Size),
+		Calldata:    calldata,
+	}, nil
+}
+
+func readReceipt(blockchain *core.BlockChain, chainDb ethdb.Database, txHash common.Hash) (*types.Transaction, *types.Receipt, error) {
+	tx, blockHash, _, _ := rawdb.ReadTransaction(chainDb, txHash)
+	if tx == nil || blockHash == (common.Hash{}) {
+		return nil, nil, fmt.Errorf("transaction %v not found", txHash)
+	}
+	for _, receipt := range blockchain.GetReceiptsByHash(blockHash) {
+		if receipt.TxHash == txHash {
+			return tx, receipt, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("receipt for transaction %v not found", txHash)
+}
+
+type ArbRetryableAPI struct {
+	blockchain *core.BlockChain
+	chainDb    ethdb.Database
+}
+
+func NewArbRetryableAPI(blockchain *core.BlockChain, chainDb ethdb.Database) *ArbRetryableAPI {
+	return &ArbRetryableAPI{blockchain, chainDb}
+}
+
+type RetryableReceiptResult struct {
+	Receipt          *types.Receipt  `json:"receipt"`
+	TicketId         *common.Hash    `json:"ticketId,omitempty"`
+	AutoRedeemTxHash *common.Hash    `json:"autoRedeemTxHash,omitempty"`
+	ParentTxHash     *common.Hash    `json:"parentTxHash,omitempty"`
+	RedeemStatus     *hexutil.Uint64 `json:"redeemStatus,omitempty"`
+	RevertData       hexutil.Bytes   `json:"revertData,omitempty"`
+	TicketOpen       bool            `json:"ticketOpen"`
+}
+
+// RetryableReceipt returns the receipt of txHash along with the retryable it's linked to, so deposits can be followed
+// to their redeems with a single call. For a submit retryable tx that's the ticket it created, the hash of the
+// auto-redeem scheduled for it and the status of that redeem. For a retry tx it's the ticket it redeemed, and the
+// tx that scheduled the redeem, found by its RedeemScheduled log earlier in the block. RevertData is what the redeem's call reverted
+// with, if it failed and the chain records it in receipts.
+// TicketOpen is whether the ticket can still be redeemed at the latest block.
+func (api *ArbRetryableAPI) RetryableReceipt(ctx context.Context, txHash common.Hash) (*RetryableReceiptResult, error) {
+	tx, receipt, err := readReceipt(api.blockchain, api.chainDb, txHash)
+	if err != nil {
+		return nil, err
+	}
+	result := &RetryableReceiptResult{Receipt: receipt}
+	switch inner := tx.GetInner().(type) {
+	case *types.ArbitrumSubmitRetryableTx:
+		ticketId := tx.Hash()
+		result.TicketId = &ticketId
+		for _, redeem := range arbos.ScheduledRedeemsFromReceipt(receipt) {
+			if redeem.TicketId != ticketId {
+				continue
+			}
+			retryTxHash := redeem.RetryTxHash
+			result.AutoRedeemTxHash = &retryTxHash
+			if _, retryReceipt, err := readReceipt(api.blockchain, api.chainDb, retryTxHash); err == nil {
+				status := hexutil.Uint64(retryReceipt.Status)
+				result.RedeemStatus = &status
+				result.RevertData, _ = arbos.RedeemRevertDataFromReceipt(retryReceipt)
+			}
+			break
+		}
+	case *types.ArbitrumRetryTx:
+		ticketId := inner.TicketId
+		result.TicketId = &ticketId
+		if parent, ok := arbos.RedeemParentFromReceipts(api.blockchain.GetReceiptsByHash(receipt.BlockHash), txHash); ok {
+			result.ParentTxHash = &parent
+		}
+		result.RevertData, _ = arbos.RedeemRevertDataFromReceipt(receipt)
+	default:
+		return result, nil
+	}
+
+	state, header, err := stateAndHeader(api.blockchain, api.blockchain.CurrentBlock().Number.Uint64())
+	if err != nil {
+		return nil, err
+	}
+	retryable, err := state.RetryableState().OpenRetryable(*result.TicketId, header.Time)
+	if err != nil {
+		return nil, err
+	}
+	result.TicketOpen = retryable != nil
+	return result, nil
+}
@@ -0,0 +1,38 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package gethexec
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+func TestOutboxProofRootHeader(t *testing.T) {
+	ctx := context.Background()
+	api := NewArbOutboxAPI(nil, nil)
+
+	// without confirmed assertions, the root must be given
+	if _, err := api.rootHeader(ctx, nil); err == nil {
+		t.Fatal("expected an error without a confirmed header fetcher")
+	}
+	confirmed := &types.Header{Number: big.NewInt(42)}
+	api.SetConfirmedHeaderFetcher(func(context.Context) (*types.Header, error) { return confirmed, nil })
+	header, err := api.rootHeader(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header != confirmed {
+		t.Fatal("expected the latest confirmed header by default, got", header)
+	}
+
+	for _, tag := range []rpc.BlockNumber{rpc.PendingBlockNumber, rpc.SafeBlockNumber, rpc.FinalizedBlockNumber, -100} {
+		if _, err := api.rootHeader(ctx, &tag); err == nil {
+			t.Fatal("expected an error for block tag", tag)
+		}
+	}
+}
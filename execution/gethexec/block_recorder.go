@@ -116,17 +116,26 @@ func (r *BlockRecorder) RecordBlockCreation(
 		if genesisNum != expectedNum {
 			return nil, fmt.Errorf("unexpected genesis block number %v in ArbOS state, expected %v", genesisNum, expectedNum)
 		}
+		// the replay binary reads the committed batch dictionaries to parse batches compressed with one
+		if initialArbosState.ArbOSVersion() >= arbosState.ArbosVersion_BatchDictionaries {
+			_, err = initialArbosState.BatchDictionaries().All()
+			if err != nil {
+				return nil, fmt.Errorf("error getting batch dictionaries from initial ArbOS state: %w", err)
+			}
+		}
 	}
 
 	var blockHash common.Hash
 	if msg != nil {
-		block, _, err := arbos.ProduceBlock(
-			msg.Message,
+		block, _, err := arbos.ProduceBlockAdvanced(
+			msg.Message.Header,
+			r.execEngine.parseL2Transactions(msg.Message),
 			msg.DelayedMessagesRead,
 			prevHeader,
 			recordingdb,
 			chaincontext,
 			chainConfig,
+			arbos.NoopSequencingHooks(),
 			false,
 		)
 		if err != nil {
@@ -0,0 +1,424 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package gethexec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/offchainlabs/nitro/arbos"
+	"github.com/offchainlabs/nitro/arbos/arbosState"
+	"github.com/offchainlabs/nitro/arbos/arbostypes"
+	"github.com/offchainlabs/nitro/arbos/l1pricing"
+	"github.com/offchainlabs/nitro/util/arbmath"
+)
+
+type BundleSimulationConfig struct {
+	Enable  bool   `koanf:"enable"`
+	MaxTxs  int    `koanf:"max-txs" reload:"hot"`
+	MaxGas  uint64 `koanf:"max-gas" reload:"hot"`
+	MaxSize int    `koanf:"max-size" reload:"hot"`
+}
+
+var DefaultBundleSimulationConfig = BundleSimulationConfig{
+	Enable:  false,
+	MaxTxs:  100,
+	MaxGas:  32_000_000,
+	MaxSize: 1024 * 1024,
+}
+
+func BundleSimulationConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.Bool(prefix+".enable", DefaultBundleSimulationConfig.Enable, "enable the arb_simulateBundle RPC method")
+	f.Int(prefix+".max-txs", DefaultBundleSimulationConfig.MaxTxs, "maximum number of transactions in a simulated bundle")
+	f.Uint64(prefix+".max-gas", DefaultBundleSimulationConfig.MaxGas, "maximum total gas limit of the transactions in a simulated bundle")
+	f.Int(prefix+".max-size", DefaultBundleSimulationConfig.MaxSize, "maximum total size in bytes of the encoded transactions in a simulated bundle")
+}
+
+func (c *BundleSimulationConfig) Validate() error {
+	if !c.Enable {
+		return nil
+	}
+	if c.MaxTxs <= 0 {
+		return errors.New("bundle simulation max-txs must be positive")
+	}
+	if c.MaxGas == 0 {
+		return errors.New("bundle simulation max-gas must be positive")
+	}
+	if c.MaxSize <= 0 {
+		return errors.New("bundle simulation max-size must be positive")
+	}
+	return nil
+}
+
+type SimulateBundleArgs struct {
+	Txs           []hexutil.Bytes       `json:"txs"`
+	Block         rpc.BlockNumberOrHash `json:"block"`
+	Timestamp     *hexutil.Uint64       `json:"timestamp"`
+	L1BlockNumber *hexutil.Uint64       `json:"l1BlockNumber"`
+}
+
+// AccountDiff is the change of an account's balance, nonce or code across a transaction or bundle.
+// Fields are only set if they changed.
+type AccountDiff struct {
+	BalanceBefore *hexutil.Big    `json:"balanceBefore,omitempty"`
+	BalanceAfter  *hexutil.Big    `json:"balanceAfter,omitempty"`
+	NonceBefore   *hexutil.Uint64 `json:"nonceBefore,omitempty"`
+	NonceAfter    *hexutil.Uint64 `json:"nonceAfter,omitempty"`
+	CodeHashAfter *common.Hash    `json:"codeHashAfter,omitempty"`
+}
+
+type BundleTxResult struct {
+	TxHash       common.Hash                    `json:"txHash"`
+	From         common.Address                 `json:"from"`
+	To           *common.Address                `json:"to"`
+	Error        string                         `json:"error,omitempty"`
+	Status       hexutil.Uint64                 `json:"status"`
+	GasUsed      hexutil.Uint64                 `json:"gasUsed"`
+	GasUsedForL1 hexutil.Uint64                 `json:"gasUsedForL1"`
+	GasPrice     *hexutil.Big                   `json:"effectiveGasPrice"`
+	ReturnData   hexutil.Bytes                  `json:"returnData"`
+	RevertReason string                         `json:"revertReason,omitempty"`
+	Logs         []*types.Log                   `json:"logs"`
+	StateDiff    map[common.Address]AccountDiff `json:"stateDiff"`
+}
+
+type SimulateBundleResult struct {
+	ParentBlockNumber hexutil.Uint64                 `json:"parentBlockNumber"`
+	ParentBlockHash   common.Hash                    `json:"parentBlockHash"`
+	BlockNumber       hexutil.Uint64                 `json:"blockNumber"`
+	BaseFee           *hexutil.Big                   `json:"baseFee"`
+	GasUsed           hexutil.Uint64                 `json:"gasUsed"`
+	GasUsedForL1      hexutil.Uint64                 `json:"gasUsedForL1"`
+	Results           []BundleTxResult               `json:"results"`
+	StateDiff         map[common.Address]AccountDiff `json:"stateDiff"`
+}
+
+// ArbBundleAPI simulates bundles of signed transactions by producing a block on top of a chosen
+// block, exactly as the sequencer would, and discarding it. Gas and L1 cost accounting therefore
+// match what the transactions would pay if sequenced in that block.
+type ArbBundleAPI struct {
+	blockchain *core.BlockChain
+	config     func() *BundleSimulationConfig
+}
+
+func NewArbBundleAPI(blockchain *core.BlockChain, config func() *BundleSimulationConfig) *ArbBundleAPI {
+	return &ArbBundleAPI{blockchain, config}
+}
+
+type accountSnapshot struct {
+	balance  *big.Int
+	nonce    uint64
+	codeHash common.Hash
+}
+
+func snapshotAccount(statedb *state.StateDB, address common.Address) accountSnapshot {
+	return accountSnapshot{
+		balance:  statedb.GetBalance(address).ToBig(),
+		nonce:    statedb.GetNonce(address),
+		codeHash: statedb.GetCodeHash(address),
+	}
+}
+
+func diffAccount(before, after accountSnapshot) (AccountDiff, bool) {
+	var diff AccountDiff
+	changed := false
+	if before.balance.Cmp(after.balance) != 0 {
+		diff.BalanceBefore = (*hexutil.Big)(before.balance)
+		diff.BalanceAfter = (*hexutil.Big)(after.balance)
+		changed = true
+	}
+	if before.nonce != after.nonce {
+		diff.NonceBefore = (*hexutil.Uint64)(&before.nonce)
+		diff.NonceAfter = (*hexutil.Uint64)(&after.nonce)
+		changed = true
+	}
+	if before.codeHash != after.codeHash {
+		codeHash := after.codeHash
+		diff.CodeHashAfter = &codeHash
+		changed = true
+	}
+	return diff, changed
+}
+
+// touchTracer collects every account a transaction may change: the accounts of its calls, creations and
+// self-destructs, and both sides of the transfers ArbOS makes outside the EVM.
+type touchTracer struct {
+	touched map[common.Address]struct{}
+}
+
+var _ vm.EVMLogger = (*touchTracer)(nil)
+
+func newTouchTracer() *touchTracer {
+	return &touchTracer{touched: make(map[common.Address]struct{})}
+}
+
+func (t *touchTracer) touch(addresses ...*common.Address) {
+	for _, address := range addresses {
+		if address != nil {
+			t.touched[*address] = struct{}{}
+		}
+	}
+}
+
+// drain returns the accounts touched since it was last called.
+func (t *touchTracer) drain() []common.Address {
+	addresses := make([]common.Address, 0, len(t.touched))
+	for address := range t.touched {
+		addresses = append(addresses, address)
+	}
+	t.touched = make(map[common.Address]struct{})
+	return addresses
+}
+
+func (t *touchTracer) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	t.touch(&from, &to)
+}
+
+func (t *touchTracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	t.touch(&from, &to)
+}
+
+func (t *touchTracer) CaptureArbitrumTransfer(env *vm.EVM, from, to *common.Address, value *big.Int, before bool, purpose string) {
+	t.touch(from, to)
+}
+
+func (t *touchTracer) CaptureArbitrumStorageGet(key common.Hash, depth int, before bool)        {}
+func (t *touchTracer) CaptureArbitrumStorageSet(key, value common.Hash, depth int, before bool) {}
+func (t *touchTracer) CaptureStylusHostio(name string, args, outs []byte, startInk, endInk uint64) {
+}
+func (t *touchTracer) CaptureEnd(output []byte, gasUsed uint64, err error)  {}
+func (t *touchTracer) CaptureExit(output []byte, gasUsed uint64, err error) {}
+func (t *touchTracer) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+}
+func (t *touchTracer) CaptureFault(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
+}
+func (t *touchTracer) CaptureTxStart(gasLimit uint64) {}
+func (t *touchTracer) CaptureTxEnd(restGas uint64)    {}
+
+// stateDiffTracker attributes account changes to the transactions of a bundle. It watches every
+// account a transaction touched, plus all accounts touched earlier in the bundle.
+type stateDiffTracker struct {
+	parent  *state.StateDB
+	initial map[common.Address]accountSnapshot
+	latest  map[common.Address]accountSnapshot
+}
+
+func (t *stateDiffTracker) track(statedb *state.StateDB, touched []common.Address) map[common.Address]AccountDiff {
+	for _, address := range touched {
+		if _, ok := t.latest[address]; !ok {
+			snapshot := snapshotAccount(t.parent, address)
+			t.initial[address] = snapshot
+			t.latest[address] = snapshot
+		}
+	}
+	diffs := make(map[common.Address]AccountDiff)
+	for address, before := range t.latest {
+		after := snapshotAccount(statedb, address)
+		if diff, changed := diffAccount(before, after); changed {
+			diffs[address] = diff
+		}
+		t.latest[address] = after
+	}
+	return diffs
+}
+
+func (t *stateDiffTracker) total() map[common.Address]AccountDiff {
+	diffs := make(map[common.Address]AccountDiff)
+	for address, before := range t.initial {
+		if diff, changed := diffAccount(before, t.latest[address]); changed {
+			diffs[address] = diff
+		}
+	}
+	return diffs
+}
+
+func (api *ArbBundleAPI) parentHeader(blockNrOrHash rpc.BlockNumberOrHash) (*types.Header, error) {
+	if hash, ok := blockNrOrHash.Hash(); ok {
+		header := api.blockchain.GetHeaderByHash(hash)
+		if header == nil {
+			return nil, fmt.Errorf("block %v not found", hash)
+		}
+		return header, nil
+	}
+	number, ok := blockNrOrHash.Number()
+	if !ok || number == rpc.LatestBlockNumber || number == rpc.PendingBlockNumber {
+		return api.blockchain.CurrentBlock(), nil
+	}
+	if number < 0 {
+		return nil, fmt.Errorf("unsupported block tag %v", number)
+	}
+	header := api.blockchain.GetHeaderByNumber(uint64(number))
+	if header == nil {
+		return nil, fmt.Errorf("block %v not found", number)
+	}
+	return header, nil
+}
+
+// SimulateBundle executes txs in order in a new block on top of args.Block (latest by default),
+// returning the result and account changes of every transaction. Transactions the sequencer
+// would reject are reported with an error and don't affect later transactions.
+func (api *ArbBundleAPI) SimulateBundle(ctx context.Context, args SimulateBundleArgs) (*SimulateBundleResult, error) {
+	config := api.config()
+	if len(args.Txs) == 0 {
+		return nil, errors.New("bundle has no transactions")
+	}
+	if len(args.Txs) > config.MaxTxs {
+		return nil, fmt.Errorf("bundle has %d transactions but at most %d are allowed", len(args.Txs), config.MaxTxs)
+	}
+	size := 0
+	for _, encoded := range args.Txs {
+		size += len(encoded)
+	}
+	if size > config.MaxSize {
+		return nil, fmt.Errorf("bundle is %d bytes but at most %d are allowed", size, config.MaxSize)
+	}
+	var gas uint64
+	txes := make(types.Transactions, len(args.Txs))
+	for i, encoded := range args.Txs {
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(encoded); err != nil {
+			return nil, fmt.Errorf("failed to decode transaction %d: %w", i, err)
+		}
+		if tx.Type() >= types.ArbitrumDepositTxType {
+			return nil, fmt.Errorf("transaction %d has type %d which can't be sequenced", i, tx.Type())
+		}
+		gas = arbmath.SaturatingUAdd(gas, tx.Gas())
+		if gas > config.MaxGas {
+			return nil, fmt.Errorf("bundle has a total gas limit over the %d allowed", config.MaxGas)
+		}
+		txes[i] = tx
+	}
+
+	parent, err := api.parentHeader(args.Block)
+	if err != nil {
+		return nil, err
+	}
+	statedb, err := api.blockchain.StateAt(parent.Root)
+	if err != nil {
+		return nil, err
+	}
+	parentStatedb := statedb.Copy()
+
+	parentInfo := types.DeserializeHeaderExtraInformation(parent)
+	l1Header := &arbostypes.L1IncomingMessageHeader{
+		Kind:        arbostypes.L1MessageType_L2Message,
+		Poster:      l1pricing.BatchPosterAddress,
+		BlockNumber: parentInfo.L1BlockNumber,
+		Timestamp:   parent.Time,
+		RequestId:   nil,
+		L1BaseFee:   nil,
+	}
+	if args.Timestamp != nil {
+		l1Header.Timestamp = uint64(*args.Timestamp)
+	}
+	if args.L1BlockNumber != nil {
+		l1Header.BlockNumber = uint64(*args.L1BlockNumber)
+	}
+
+	tracker := &stateDiffTracker{
+		parent:  parentStatedb,
+		initial: make(map[common.Address]accountSnapshot),
+		latest:  make(map[common.Address]accountSnapshot),
+	}
+	results := make(map[common.Hash]*BundleTxResult, len(txes))
+	tracer := newTouchTracer()
+	hooks := arbos.NoopSequencingHooks()
+	hooks.DiscardInvalidTxsEarly = true
+	hooks.Tracer = tracer
+	hooks.PostTxFilter = func(header *types.Header, arbState *arbosState.ArbosState, tx *types.Transaction, sender common.Address, dataGas uint64, result *core.ExecutionResult) error {
+		txResult := &BundleTxResult{
+			ReturnData: result.ReturnData,
+		}
+		if result.Err != nil {
+			txResult.Error = result.Err.Error()
+			if errors.Is(result.Err, vm.ErrExecutionReverted) {
+				if reason, err := abi.UnpackRevert(result.ReturnData); err == nil {
+					txResult.RevertReason = reason
+				}
+			}
+		}
+		// some fees are paid without a traced transfer
+		touched := append(tracer.drain(), sender, header.Coinbase, l1pricing.L1PricerFundsPoolAddress)
+		if networkFeeAccount, err := arbState.NetworkFeeAccount(); err == nil {
+			touched = append(touched, networkFeeAccount)
+		}
+		if infraFeeAccount, err := arbState.InfraFeeAccount(); err == nil {
+			touched = append(touched, infraFeeAccount)
+		}
+		txResult.StateDiff = tracker.track(statedb, touched)
+		results[tx.Hash()] = txResult
+		return nil
+	}
+
+	block, receipts, err := arbos.ProduceBlockAdvanced(
+		l1Header,
+		txes,
+		parent.Nonce.Uint64(),
+		parent,
+		statedb,
+		api.blockchain,
+		api.blockchain.Config(),
+		hooks,
+		false,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(hooks.TxErrors) != len(txes) {
+		return nil, fmt.Errorf("unexpected number of error results: %v vs number of txes %v", len(hooks.TxErrors), len(txes))
+	}
+
+	receiptsByHash := make(map[common.Hash]*types.Receipt, len(receipts))
+	for _, receipt := range receipts {
+		receiptsByHash[receipt.TxHash] = receipt
+	}
+	signer := types.MakeSigner(api.blockchain.Config(), block.Number(), block.Time())
+	bundleResult := &SimulateBundleResult{
+		ParentBlockNumber: hexutil.Uint64(parent.Number.Uint64()),
+		ParentBlockHash:   parent.Hash(),
+		BlockNumber:       hexutil.Uint64(block.NumberU64()),
+		BaseFee:           (*hexutil.Big)(block.BaseFee()),
+		Results:           make([]BundleTxResult, len(txes)),
+		StateDiff:         tracker.total(),
+	}
+	for i, tx := range txes {
+		txResult := BundleTxResult{}
+		receipt := receiptsByHash[tx.Hash()]
+		if cached, ok := results[tx.Hash()]; ok && hooks.TxErrors[i] == nil && receipt != nil {
+			txResult = *cached
+			txResult.Status = hexutil.Uint64(receipt.Status)
+			txResult.GasUsed = hexutil.Uint64(receipt.GasUsed)
+			txResult.GasUsedForL1 = hexutil.Uint64(receipt.GasUsedForL1)
+			txResult.GasPrice = (*hexutil.Big)(block.BaseFee())
+			txResult.Logs = receipt.Logs
+			bundleResult.GasUsed += txResult.GasUsed
+			bundleResult.GasUsedForL1 += txResult.GasUsedForL1
+		} else if hooks.TxErrors[i] != nil {
+			txResult.Error = hooks.TxErrors[i].Error()
+		}
+		txResult.TxHash = tx.Hash()
+		txResult.To = tx.To()
+		if from, err := types.Sender(signer, tx); err == nil {
+			txResult.From = from
+		}
+		if txResult.Logs == nil {
+			txResult.Logs = []*types.Log{}
+		}
+		bundleResult.Results[i] = txResult
+	}
+	return bundleResult, nil
+}
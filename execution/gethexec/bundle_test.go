@@ -0,0 +1,81 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package gethexec
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+func TestDiffAccount(t *testing.T) {
+	before := accountSnapshot{balance: big.NewInt(100), nonce: 3}
+	if _, changed := diffAccount(before, before); changed {
+		t.Fatal("unchanged account reported as changed")
+	}
+
+	after := accountSnapshot{balance: big.NewInt(40), nonce: 4}
+	diff, changed := diffAccount(before, after)
+	if !changed {
+		t.Fatal("changed account not reported")
+	}
+	if diff.BalanceBefore.ToInt().Int64() != 100 || diff.BalanceAfter.ToInt().Int64() != 40 {
+		t.Fatal("unexpected balance diff", diff.BalanceBefore, diff.BalanceAfter)
+	}
+	if uint64(*diff.NonceBefore) != 3 || uint64(*diff.NonceAfter) != 4 {
+		t.Fatal("unexpected nonce diff", *diff.NonceBefore, *diff.NonceAfter)
+	}
+	if diff.CodeHashAfter != nil {
+		t.Fatal("code hash reported as changed")
+	}
+
+	deployed := accountSnapshot{balance: big.NewInt(100), nonce: 3, codeHash: common.HexToHash("0xc0de")}
+	diff, changed = diffAccount(before, deployed)
+	if !changed || diff.CodeHashAfter == nil || *diff.CodeHashAfter != deployed.codeHash {
+		t.Fatal("code change not reported", diff)
+	}
+	if diff.BalanceBefore != nil || diff.NonceBefore != nil {
+		t.Fatal("unchanged fields reported", diff)
+	}
+}
+
+func TestTouchTracer(t *testing.T) {
+	sender := common.HexToAddress("0x01")
+	contract := common.HexToAddress("0x02")
+	beneficiary := common.HexToAddress("0x03")
+	feeAccount := common.HexToAddress("0x04")
+	tracer := newTouchTracer()
+	tracer.CaptureArbitrumTransfer(nil, &sender, nil, big.NewInt(1), true, "feeCollection")
+	tracer.CaptureStart(nil, sender, contract, false, nil, 0, big.NewInt(0))
+	tracer.CaptureEnter(vm.SELFDESTRUCT, contract, beneficiary, nil, 0, big.NewInt(5))
+	tracer.CaptureArbitrumTransfer(nil, nil, &feeAccount, big.NewInt(1), false, "feePayment")
+
+	touched := make(map[common.Address]bool)
+	for _, address := range tracer.drain() {
+		touched[address] = true
+	}
+	if len(touched) != 4 || !touched[sender] || !touched[contract] || !touched[beneficiary] || !touched[feeAccount] {
+		t.Fatal("unexpected touched accounts", touched)
+	}
+	if len(tracer.drain()) != 0 {
+		t.Fatal("accounts reported again after being drained")
+	}
+}
+
+func TestBundleSimulationConfig(t *testing.T) {
+	config := DefaultBundleSimulationConfig
+	if config.Enable {
+		t.Fatal("bundle simulation is enabled by default")
+	}
+	config.Enable = true
+	if err := config.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	config.MaxGas = 0
+	if err := config.Validate(); err == nil {
+		t.Fatal("expected an error for no gas cap")
+	}
+}
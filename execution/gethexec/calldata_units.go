@@ -0,0 +1,104 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package gethexec
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/offchainlabs/nitro/arbos/arbosState"
+	"github.com/offchainlabs/nitro/util/dbutil"
+)
+
+// followed by the tx hash, and holding the big-endian number of units
+const calldataUnitsDBPrefix = "arb-calldata-units-"
+
+// CalldataUnitsStore keeps the L1 calldata units each tx was charged for while the chain enables the
+// receipt-calldata-units ArbOS feature, so L1 costs can be attributed without replaying blocks. Receipts only carry
+// the gas the units were charged as, which depends on the L1 price at the time.
+type CalldataUnitsStore struct {
+	db ethdb.Database
+}
+
+func NewCalldataUnitsStore(chainDB ethdb.Database) *CalldataUnitsStore {
+	return &CalldataUnitsStore{db: rawdb.NewTable(chainDB, calldataUnitsDBPrefix)}
+}
+
+// enabled returns whether the chain records calldata units in the state a block was produced with.
+func (s *CalldataUnitsStore) enabled(statedb *state.StateDB) bool {
+	arbState, err := arbosState.OpenSystemArbosState(statedb, nil, true)
+	if err != nil {
+		log.Error("failed to open ArbOS state to check if calldata units are recorded", "err", err)
+		return false
+	}
+	return arbState.FeatureEnabled(arbosState.FeatureReceiptCalldataUnits)
+}
+
+// recordBlock stores the units charged to a block's txs, which ArbOS caches on each tx as it charges for it.
+// Entries are keyed by tx hash, so a reorg overwrites those of txs included again.
+func (s *CalldataUnitsStore) recordBlock(txs types.Transactions, baseFee *big.Int) error {
+	// ArbOS only charges for calldata while the base fee is positive
+	charged := baseFee != nil && baseFee.Sign() > 0
+	batch := s.db.NewBatch()
+	for _, tx := range txs {
+		var units uint64
+		if charged {
+			units = atomic.LoadUint64(&tx.CalldataUnits)
+		}
+		if err := batch.Put(tx.Hash().Bytes(), binary.BigEndian.AppendUint64(nil, units)); err != nil {
+			return err
+		}
+	}
+	return batch.Write()
+}
+
+// units returns the units recorded for a tx, if any were.
+func (s *CalldataUnitsStore) units(txHash common.Hash) (uint64, bool, error) {
+	data, err := s.db.Get(txHash.Bytes())
+	if dbutil.IsErrNotFound(err) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	if len(data) != 8 {
+		return 0, false, fmt.Errorf("malformed calldata units %x of tx %v", data, txHash)
+	}
+	return binary.BigEndian.Uint64(data), true, nil
+}
+
+type ArbCalldataUnitsAPI struct {
+	store   *CalldataUnitsStore
+	chainDb ethdb.Database
+}
+
+func NewArbCalldataUnitsAPI(store *CalldataUnitsStore, chainDb ethdb.Database) *ArbCalldataUnitsAPI {
+	return &ArbCalldataUnitsAPI{store, chainDb}
+}
+
+// L1CalldataUnits returns the L1 calldata units txHash was charged for, or nil if they weren't recorded,
+// as the chain didn't enable the receipt-calldata-units ArbOS feature or this node didn't execute the tx's block.
+func (api *ArbCalldataUnitsAPI) L1CalldataUnits(ctx context.Context, txHash common.Hash) (*hexutil.Uint64, error) {
+	// entries of txs reorged out aren't deleted, so the tx must still be in the chain
+	if tx, blockHash, _, _ := rawdb.ReadTransaction(api.chainDb, txHash); tx == nil || blockHash == (common.Hash{}) {
+		return nil, fmt.Errorf("transaction %v not found", txHash)
+	}
+	units, found, err := api.store.units(txHash)
+	if err != nil || !found {
+		return nil, err
+	}
+	result := hexutil.Uint64(units)
+	return &result, nil
+}
@@ -0,0 +1,44 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package gethexec
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestCalldataUnitsStore(t *testing.T) {
+	store := NewCalldataUnitsStore(rawdb.NewMemoryDatabase())
+	charged := types.NewTx(&types.LegacyTx{Nonce: 1})
+	charged.CalldataUnits = 1234
+	uncharged := types.NewTx(&types.LegacyTx{Nonce: 2})
+
+	expect := func(tx *types.Transaction, units uint64, found bool) {
+		t.Helper()
+		recorded, ok, err := store.units(tx.Hash())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if recorded != units || ok != found {
+			t.Fatal("unexpected calldata units", recorded, ok, "expected", units, found)
+		}
+	}
+	expect(charged, 0, false)
+
+	if err := store.recordBlock(types.Transactions{charged, uncharged}, big.NewInt(1)); err != nil {
+		t.Fatal(err)
+	}
+	expect(charged, 1234, true)
+	expect(uncharged, 0, true)
+
+	// units cached on a tx aren't charged in a block without a base fee
+	if err := store.recordBlock(types.Transactions{charged}, common.Big0); err != nil {
+		t.Fatal(err)
+	}
+	expect(charged, 0, true)
+}
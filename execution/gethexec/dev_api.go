@@ -0,0 +1,158 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package gethexec
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/offchainlabs/nitro/arbos/arbostypes"
+	"github.com/offchainlabs/nitro/arbos/l1pricing"
+)
+
+type DevConfig struct {
+	Enable bool `koanf:"enable"`
+}
+
+var DefaultDevConfig = DevConfig{
+	Enable: false,
+}
+
+var errDevRequiresSequencer = errors.New("dev mode requires the sequencer")
+
+func DevConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.Bool(prefix+".enable", DefaultDevConfig.Enable, "DANGEROUS! serve evm_increaseTime, evm_setNextBlockTimestamp and evm_mine to move the sequencer's clock and mine empty blocks. To be used in dev nodes only")
+}
+
+// devClock shifts the timestamps the sequencer gives to blocks, so dev nodes can travel forward in time.
+type devClock struct {
+	mutex         sync.Mutex
+	offset        int64  // seconds added to the local clock
+	nextTimestamp uint64 // timestamp of the next block if non-zero
+}
+
+// timestamp returns the timestamp for the next block, and whether it was set explicitly.
+func (c *devClock) timestamp() (int64, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.nextTimestamp != 0 {
+		// #nosec G115
+		return int64(c.nextTimestamp), true
+	}
+	return time.Now().Unix() + c.offset, false
+}
+
+// blockCreated moves the clock to follow an explicitly set timestamp once a block used it.
+func (c *devClock) blockCreated(timestamp int64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	// #nosec G115
+	if c.nextTimestamp == 0 || int64(c.nextTimestamp) != timestamp {
+		return
+	}
+	c.offset = timestamp - time.Now().Unix()
+	c.nextTimestamp = 0
+}
+
+func (c *devClock) increase(seconds int64) int64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.offset += seconds
+	return c.offset
+}
+
+func (c *devClock) setNext(timestamp uint64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.nextTimestamp = timestamp
+}
+
+// MineEmptyBlock sequences a block without transactions, which still advances the ArbOS clock.
+func (s *Sequencer) MineEmptyBlock(ctx context.Context) error {
+	timestamp, explicit := s.devClock.timestamp()
+	header := &arbostypes.L1IncomingMessageHeader{
+		Kind:        arbostypes.L1MessageType_L2Message,
+		Poster:      l1pricing.BatchPosterAddress,
+		BlockNumber: s.l1BlockNumber.Load(),
+		// #nosec G115
+		Timestamp: uint64(timestamp),
+	}
+	if _, err := s.execEngine.SequenceEmptyBlock(header); err != nil {
+		return err
+	}
+	if explicit {
+		s.devClock.blockCreated(timestamp)
+	}
+	return nil
+}
+
+// devQuantity is a quantity given either as a JSON number or a hex string, as dev tooling sends both.
+type devQuantity uint64
+
+func (q *devQuantity) UnmarshalJSON(input []byte) error {
+	if len(input) > 0 && input[0] == '"' {
+		var value hexutil.Uint64
+		if err := value.UnmarshalJSON(input); err != nil {
+			return err
+		}
+		*q = devQuantity(value)
+		return nil
+	}
+	var value uint64
+	if err := json.Unmarshal(input, &value); err != nil {
+		return err
+	}
+	*q = devQuantity(value)
+	return nil
+}
+
+// DevAPI serves the time travel methods of Hardhat-style dev nodes under the evm namespace.
+type DevAPI struct {
+	sequencer *Sequencer
+}
+
+func NewDevAPI(sequencer *Sequencer) *DevAPI {
+	return &DevAPI{sequencer}
+}
+
+// IncreaseTime moves the clock of future blocks forward, returning the total offset in seconds.
+func (a *DevAPI) IncreaseTime(ctx context.Context, seconds devQuantity) (int64, error) {
+	// #nosec G115
+	if int64(seconds) < 0 {
+		return 0, fmt.Errorf("cannot increase time by %v seconds", uint64(seconds))
+	}
+	// #nosec G115
+	return a.sequencer.devClock.increase(int64(seconds)), nil
+}
+
+// SetNextBlockTimestamp sets the timestamp of the next block, which later blocks continue from.
+func (a *DevAPI) SetNextBlockTimestamp(ctx context.Context, timestamp devQuantity) error {
+	latest := a.sequencer.execEngine.bc.CurrentBlock()
+	if latest != nil && uint64(timestamp) <= latest.Time {
+		return fmt.Errorf("timestamp %v isn't after the latest block's timestamp %v", uint64(timestamp), latest.Time)
+	}
+	a.sequencer.devClock.setNext(uint64(timestamp))
+	return nil
+}
+
+// Mine creates an empty block, at the given timestamp if there's one.
+func (a *DevAPI) Mine(ctx context.Context, timestamp *devQuantity) (string, error) {
+	if timestamp != nil {
+		if err := a.SetNextBlockTimestamp(ctx, *timestamp); err != nil {
+			return "", err
+		}
+	}
+	if err := a.sequencer.MineEmptyBlock(ctx); err != nil {
+		return "", err
+	}
+	return "0x0", nil
+}
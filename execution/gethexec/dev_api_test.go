@@ -0,0 +1,53 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package gethexec
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestDevClock(t *testing.T) {
+	var clock devClock
+	now := time.Now().Unix()
+	if timestamp, explicit := clock.timestamp(); explicit || timestamp < now || timestamp > now+5 {
+		t.Fatal("unexpected timestamp without time travel", timestamp, explicit)
+	}
+
+	clock.increase(3600)
+	if timestamp, _ := clock.timestamp(); timestamp < now+3600 || timestamp > now+3605 {
+		t.Fatal("time didn't increase by an hour", timestamp)
+	}
+
+	// #nosec G115
+	next := uint64(now + 86400)
+	clock.setNext(next)
+	timestamp, explicit := clock.timestamp()
+	// #nosec G115
+	if !explicit || uint64(timestamp) != next {
+		t.Fatal("next block timestamp wasn't used", timestamp, explicit)
+	}
+	clock.blockCreated(timestamp)
+	// later blocks continue from the explicit timestamp
+	if timestamp, explicit := clock.timestamp(); explicit || timestamp < now+86400 || timestamp > now+86405 {
+		t.Fatal("clock didn't continue from the next block timestamp", timestamp, explicit)
+	}
+}
+
+func TestDevQuantity(t *testing.T) {
+	for input, want := range map[string]uint64{`60`: 60, `"0x3c"`: 60} {
+		var q devQuantity
+		if err := json.Unmarshal([]byte(input), &q); err != nil {
+			t.Fatal("failed to parse", input, err)
+		}
+		if uint64(q) != want {
+			t.Fatal("parsed", input, "as", q)
+		}
+	}
+	var q devQuantity
+	if err := json.Unmarshal([]byte(`"60"`), &q); err == nil {
+		t.Fatal("parsed a non-hex string")
+	}
+}
@@ -0,0 +1,332 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package gethexec
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth/filters"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/offchainlabs/nitro/arbos"
+	"github.com/offchainlabs/nitro/util/dbutil"
+)
+
+var (
+	eventIndexHitCounter  = metrics.NewRegisteredCounter("arb/eventindex/hit", nil)
+	eventIndexMissCounter = metrics.NewRegisteredCounter("arb/eventindex/miss", nil)
+)
+
+type EventIndexConfig struct {
+	Enable bool     `koanf:"enable"`
+	Events []string `koanf:"events"`
+}
+
+var DefaultEventIndexConfig = EventIndexConfig{
+	Enable: false,
+	Events: []string{"L2ToL1Tx", "RedeemScheduled"},
+}
+
+func EventIndexConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.Bool(prefix+".enable", DefaultEventIndexConfig.Enable, "index the blocks emitting high-volume precompile events as they're imported, and serve eth_getLogs queries for only those events from the index")
+	f.StringSlice(prefix+".events", DefaultEventIndexConfig.Events, "precompile events to index (L2ToL1Tx, L2ToL1Transaction, RedeemScheduled, TicketCreated)")
+}
+
+func (c *EventIndexConfig) Validate() error {
+	if !c.Enable {
+		return nil
+	}
+	if len(c.Events) == 0 {
+		return errors.New("the event index is enabled without any events to index")
+	}
+	for _, name := range c.Events {
+		if _, _, ok := indexableEvent(name); !ok {
+			return fmt.Errorf("unknown event to index %v", name)
+		}
+	}
+	return nil
+}
+
+// indexableEvent returns the precompile emitting an event that can be indexed, and the event's id.
+// The ids are only known once the precompiles have been created.
+func indexableEvent(name string) (common.Address, common.Hash, bool) {
+	switch name {
+	case "L2ToL1Tx":
+		return types.ArbSysAddress, arbos.L2ToL1TxEventID, true
+	case "L2ToL1Transaction":
+		return types.ArbSysAddress, arbos.L2ToL1TransactionEventID, true
+	case "RedeemScheduled":
+		return types.ArbRetryableTxAddress, arbos.RedeemScheduledEventID, true
+	case "TicketCreated":
+		return types.ArbRetryableTxAddress, arbos.TicketCreatedEventID, true
+	default:
+		return common.Address{}, common.Hash{}, false
+	}
+}
+
+const eventIndexDBPrefix = "arb-event-index-"
+
+var (
+	// the first and last blocks indexed without gaps, as two big-endian numbers
+	eventIndexRangeKey = []byte("range")
+	// followed by the precompile's address, the event id and the big-endian number of a block emitting the event
+	eventIndexEntryPrefix = []byte("e")
+)
+
+type indexedEvent struct {
+	address common.Address
+	id      common.Hash
+}
+
+func (e indexedEvent) prefix() []byte {
+	key := append([]byte{}, eventIndexEntryPrefix...)
+	key = append(key, e.address.Bytes()...)
+	return append(key, e.id.Bytes()...)
+}
+
+// EventIndex records which blocks emit a few high-volume precompile events, so that eth_getLogs queries for them
+// read only those blocks' receipts instead of scanning the blooms of the whole range. Entries are only ever added,
+// so a reorg may leave entries for blocks that no longer emit the event, which are filtered out when read.
+type EventIndex struct {
+	db     ethdb.Database
+	bc     *core.BlockChain
+	events map[indexedEvent]struct{}
+}
+
+func NewEventIndex(chainDB ethdb.Database, bc *core.BlockChain, config *EventIndexConfig) (*EventIndex, error) {
+	events := make(map[indexedEvent]struct{}, len(config.Events))
+	for _, name := range config.Events {
+		address, id, ok := indexableEvent(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown event to index %v", name)
+		}
+		if id == (common.Hash{}) {
+			return nil, fmt.Errorf("the id of event %v isn't known yet", name)
+		}
+		events[indexedEvent{address, id}] = struct{}{}
+	}
+	return &EventIndex{
+		db:     rawdb.NewTable(chainDB, eventIndexDBPrefix),
+		bc:     bc,
+		events: events,
+	}, nil
+}
+
+// indexedRange returns the first and last blocks indexed without gaps, if any were.
+func (idx *EventIndex) indexedRange() (uint64, uint64, bool, error) {
+	data, err := idx.db.Get(eventIndexRangeKey)
+	if dbutil.IsErrNotFound(err) {
+		return 0, 0, false, nil
+	}
+	if err != nil {
+		return 0, 0, false, err
+	}
+	if len(data) != 16 {
+		return 0, 0, false, fmt.Errorf("malformed event index range %x", data)
+	}
+	return binary.BigEndian.Uint64(data[:8]), binary.BigEndian.Uint64(data[8:]), true, nil
+}
+
+// indexBlock adds the block's events to the index. It must be called for each block imported, in order.
+func (idx *EventIndex) indexBlock(number uint64, receipts types.Receipts) error {
+	first, last, found, err := idx.indexedRange()
+	if err != nil {
+		return err
+	}
+	if !found || number > last+1 {
+		// the blocks before weren't indexed, as the index was disabled or failed to write
+		first = number
+	}
+	first = min(first, number)
+	batch := idx.db.NewBatch()
+	for _, receipt := range receipts {
+		for _, txLog := range receipt.Logs {
+			if len(txLog.Topics) == 0 {
+				continue
+			}
+			event := indexedEvent{txLog.Address, txLog.Topics[0]}
+			if _, ok := idx.events[event]; !ok {
+				continue
+			}
+			if err := batch.Put(binary.BigEndian.AppendUint64(event.prefix(), number), []byte{}); err != nil {
+				return err
+			}
+		}
+	}
+	indexedRange := binary.BigEndian.AppendUint64(nil, first)
+	indexedRange = binary.BigEndian.AppendUint64(indexedRange, number)
+	if err := batch.Put(eventIndexRangeKey, indexedRange); err != nil {
+		return err
+	}
+	return batch.Write()
+}
+
+// resolveBlockNumber returns the block a filter bound refers to, if the index can serve it.
+func resolveBlockNumber(number *big.Int, head uint64) (uint64, bool) {
+	if number == nil {
+		return head, true
+	}
+	if number.Sign() >= 0 {
+		return number.Uint64(), number.IsUint64()
+	}
+	switch rpc.BlockNumber(number.Int64()) {
+	case rpc.LatestBlockNumber, rpc.PendingBlockNumber:
+		return head, true
+	default:
+		// the safe and finalized blocks are left to the filter system
+		return 0, false
+	}
+}
+
+// indexedBlocks returns the blocks in the query's range that may have logs matching it,
+// or false if the query isn't only for indexed events over an indexed range.
+func (idx *EventIndex) indexedBlocks(crit filters.FilterCriteria, head uint64) ([]uint64, bool, error) {
+	if crit.BlockHash != nil || len(crit.Addresses) == 0 || len(crit.Topics) == 0 || len(crit.Topics[0]) == 0 {
+		return nil, false, nil
+	}
+	var events []indexedEvent
+	for _, address := range crit.Addresses {
+		for _, id := range crit.Topics[0] {
+			event := indexedEvent{address, id}
+			if _, ok := idx.events[event]; !ok {
+				return nil, false, nil
+			}
+			events = append(events, event)
+		}
+	}
+	from, ok := resolveBlockNumber(crit.FromBlock, head)
+	if !ok {
+		return nil, false, nil
+	}
+	to, ok := resolveBlockNumber(crit.ToBlock, head)
+	if !ok || from > to {
+		return nil, false, nil
+	}
+	first, last, found, err := idx.indexedRange()
+	if err != nil || !found || from < first || to > last {
+		return nil, false, err
+	}
+
+	blocks := make(map[uint64]struct{})
+	for _, event := range events {
+		prefix := event.prefix()
+		err := func() error {
+			it := idx.db.NewIterator(prefix, binary.BigEndian.AppendUint64(nil, from))
+			defer it.Release()
+			for it.Next() {
+				number := binary.BigEndian.Uint64(it.Key()[len(prefix):])
+				if number > to {
+					break
+				}
+				blocks[number] = struct{}{}
+			}
+			return it.Error()
+		}()
+		if err != nil {
+			return nil, false, err
+		}
+	}
+	numbers := make([]uint64, 0, len(blocks))
+	for number := range blocks {
+		numbers = append(numbers, number)
+	}
+	sort.Slice(numbers, func(i, j int) bool { return numbers[i] < numbers[j] })
+	return numbers, true, nil
+}
+
+func logMatches(txLog *types.Log, crit filters.FilterCriteria) bool {
+	addressMatches := len(crit.Addresses) == 0
+	for _, address := range crit.Addresses {
+		if txLog.Address == address {
+			addressMatches = true
+			break
+		}
+	}
+	if !addressMatches || len(crit.Topics) > len(txLog.Topics) {
+		return false
+	}
+	for i, alternatives := range crit.Topics {
+		if len(alternatives) == 0 {
+			continue
+		}
+		matches := false
+		for _, topic := range alternatives {
+			if txLog.Topics[i] == topic {
+				matches = true
+				break
+			}
+		}
+		if !matches {
+			return false
+		}
+	}
+	return true
+}
+
+// getLogs returns the logs matching the query from the blocks the index has, or false if it can't serve the query.
+func (idx *EventIndex) getLogs(ctx context.Context, crit filters.FilterCriteria) ([]*types.Log, bool, error) {
+	numbers, ok, err := idx.indexedBlocks(crit, idx.bc.CurrentBlock().Number.Uint64())
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	logs := []*types.Log{}
+	for _, number := range numbers {
+		if err := ctx.Err(); err != nil {
+			return nil, false, err
+		}
+		header := idx.bc.GetHeaderByNumber(number)
+		if header == nil {
+			continue
+		}
+		for _, receipt := range idx.bc.GetReceiptsByHash(header.Hash()) {
+			for _, txLog := range receipt.Logs {
+				if logMatches(txLog, crit) {
+					logs = append(logs, txLog)
+				}
+			}
+		}
+	}
+	return logs, true, nil
+}
+
+// EventIndexAPI serves eth_getLogs from the event index when a query only asks for indexed events, and from the
+// filter system otherwise. It's registered after geth's eth API, so its GetLogs takes the place of geth's.
+type EventIndexAPI struct {
+	index    *EventIndex
+	fallback *filters.FilterAPI
+}
+
+func NewEventIndexAPI(index *EventIndex, filterSystem *filters.FilterSystem) *EventIndexAPI {
+	return &EventIndexAPI{
+		index:    index,
+		fallback: filters.NewFilterAPI(filterSystem, false),
+	}
+}
+
+// GetLogs returns the logs matching the given filter criteria, as eth_getLogs.
+func (api *EventIndexAPI) GetLogs(ctx context.Context, crit filters.FilterCriteria) ([]*types.Log, error) {
+	logs, ok, err := api.index.getLogs(ctx, crit)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		eventIndexHitCounter.Inc(1)
+		return logs, nil
+	}
+	eventIndexMissCounter.Inc(1)
+	return api.fallback.GetLogs(ctx, crit)
+}
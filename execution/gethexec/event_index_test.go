@@ -0,0 +1,123 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package gethexec
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth/filters"
+)
+
+func TestEventIndexBlocks(t *testing.T) {
+	indexed := indexedEvent{types.ArbSysAddress, common.HexToHash("0x11")}
+	other := indexedEvent{types.ArbSysAddress, common.HexToHash("0x22")}
+	idx := &EventIndex{
+		db:     rawdb.NewTable(rawdb.NewMemoryDatabase(), eventIndexDBPrefix),
+		events: map[indexedEvent]struct{}{indexed: {}},
+	}
+	receiptWith := func(events ...indexedEvent) types.Receipts {
+		receipt := &types.Receipt{}
+		for _, event := range events {
+			receipt.Logs = append(receipt.Logs, &types.Log{Address: event.address, Topics: []common.Hash{event.id}})
+		}
+		return types.Receipts{receipt}
+	}
+	query := func(from, to int64) filters.FilterCriteria {
+		return filters.FilterCriteria{
+			FromBlock: big.NewInt(from),
+			ToBlock:   big.NewInt(to),
+			Addresses: []common.Address{indexed.address},
+			Topics:    [][]common.Hash{{indexed.id}},
+		}
+	}
+
+	for number := uint64(10); number <= 20; number++ {
+		var receipts types.Receipts
+		switch number {
+		case 12:
+			receipts = receiptWith(indexed)
+		case 15:
+			receipts = receiptWith(other)
+		case 17:
+			receipts = receiptWith(other, indexed)
+		}
+		if err := idx.indexBlock(number, receipts); err != nil {
+			t.Fatal(err)
+		}
+	}
+	blocks, ok, err := idx.indexedBlocks(query(10, 20), 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok || !reflect.DeepEqual(blocks, []uint64{12, 17}) {
+		t.Fatal("wrong indexed blocks", blocks, ok)
+	}
+	blocks, ok, err = idx.indexedBlocks(query(13, 16), 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok || len(blocks) != 0 {
+		t.Fatal("wrong indexed blocks in a range without events", blocks, ok)
+	}
+
+	// queries that can't be served fall back to the filter system
+	if _, ok, _ := idx.indexedBlocks(query(5, 20), 20); ok {
+		t.Fatal("served a query starting before the index")
+	}
+	if _, ok, _ := idx.indexedBlocks(query(10, 21), 21); ok {
+		t.Fatal("served a query ending after the index")
+	}
+	crit := query(10, 20)
+	crit.Topics = [][]common.Hash{{indexed.id, other.id}}
+	if _, ok, _ := idx.indexedBlocks(crit, 20); ok {
+		t.Fatal("served a query for an event that isn't indexed")
+	}
+	crit = query(10, 20)
+	crit.Addresses = nil
+	if _, ok, _ := idx.indexedBlocks(crit, 20); ok {
+		t.Fatal("served a query for an event from any address")
+	}
+
+	// a gap restarts the index
+	if err := idx.indexBlock(30, receiptWith(indexed)); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ := idx.indexedBlocks(query(10, 30), 30); ok {
+		t.Fatal("served a query over blocks that weren't indexed")
+	}
+	blocks, ok, err = idx.indexedBlocks(query(30, 30), 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok || !reflect.DeepEqual(blocks, []uint64{30}) {
+		t.Fatal("wrong indexed blocks after a gap", blocks, ok)
+	}
+}
+
+func TestEventIndexLogMatches(t *testing.T) {
+	txLog := &types.Log{
+		Address: types.ArbRetryableTxAddress,
+		Topics:  []common.Hash{common.HexToHash("0x1"), common.HexToHash("0x2")},
+	}
+	crit := filters.FilterCriteria{
+		Addresses: []common.Address{types.ArbSysAddress, types.ArbRetryableTxAddress},
+		Topics:    [][]common.Hash{{common.HexToHash("0x1")}, {}},
+	}
+	if !logMatches(txLog, crit) {
+		t.Fatal("matching log didn't match")
+	}
+	crit.Topics[1] = []common.Hash{common.HexToHash("0x3")}
+	if logMatches(txLog, crit) {
+		t.Fatal("log with a different topic matched")
+	}
+	crit.Topics = append(crit.Topics[:1], []common.Hash{}, []common.Hash{})
+	if logMatches(txLog, crit) {
+		t.Fatal("log with too few topics matched")
+	}
+}
@@ -26,6 +26,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
@@ -38,6 +39,7 @@ import (
 	"github.com/offchainlabs/nitro/arbos/arbosState"
 	"github.com/offchainlabs/nitro/arbos/arbostypes"
 	"github.com/offchainlabs/nitro/arbos/l1pricing"
+	"github.com/offchainlabs/nitro/arbos/l1timing"
 	"github.com/offchainlabs/nitro/arbos/programs"
 	"github.com/offchainlabs/nitro/arbutil"
 	"github.com/offchainlabs/nitro/execution"
@@ -89,7 +91,15 @@ type ExecutionEngine struct {
 
 	prefetchBlock bool
 
+	senderRecoverer *SenderRecoverer
+
+	eventIndex *EventIndex
+
+	calldataUnits *CalldataUnitsStore
+
 	cachedL1PriceData *L1PriceData
+
+	retryableMetrics *retryableMetrics
 }
 
 func NewL1PriceData() *L1PriceData {
@@ -104,6 +114,7 @@ func NewExecutionEngine(bc *core.BlockChain) (*ExecutionEngine, error) {
 		resequenceChan:    make(chan []*arbostypes.MessageWithMetadata),
 		newBlockNotifier:  make(chan struct{}, 1),
 		cachedL1PriceData: NewL1PriceData(),
+		retryableMetrics:  newRetryableMetrics(),
 	}, nil
 }
 
@@ -222,6 +233,61 @@ func (s *ExecutionEngine) EnablePrefetchBlock() {
 	s.prefetchBlock = true
 }
 
+func (s *ExecutionEngine) EnableSenderRecovery(config *SenderRecoveryConfig) {
+	if s.Started() {
+		panic("trying to enable sender recovery after start")
+	}
+	if s.senderRecoverer != nil {
+		panic("trying to enable sender recovery when already set")
+	}
+	s.senderRecoverer = NewSenderRecoverer(config, s.bc.Config())
+}
+
+func (s *ExecutionEngine) EnableEventIndex(index *EventIndex) {
+	if s.Started() {
+		panic("trying to enable the event index after start")
+	}
+	if s.eventIndex != nil {
+		panic("trying to enable the event index when already set")
+	}
+	s.eventIndex = index
+}
+
+func (s *ExecutionEngine) EnableCalldataUnitsStore(store *CalldataUnitsStore) {
+	if s.Started() {
+		panic("trying to enable the calldata units store after start")
+	}
+	if s.calldataUnits != nil {
+		panic("trying to enable the calldata units store when already set")
+	}
+	s.calldataUnits = store
+}
+
+// PrefetchSenders queues the transactions of upcoming messages, starting at message number start,
+// for sender recovery ahead of their execution.
+func (s *ExecutionEngine) PrefetchSenders(start arbutil.MessageIndex, msgs []*arbostypes.MessageWithMetadata) {
+	if s.senderRecoverer == nil {
+		return
+	}
+	for i, msg := range msgs {
+		s.senderRecoverer.Prefetch(s.MessageIndexToBlockNumber(start+arbutil.MessageIndex(i)), msg)
+	}
+}
+
+// parseL2Transactions parses the transactions of a message for block production, reusing senders
+// recovered ahead of time when sender recovery is enabled.
+func (s *ExecutionEngine) parseL2Transactions(message *arbostypes.L1IncomingMessage) types.Transactions {
+	if s.senderRecoverer != nil {
+		return s.senderRecoverer.ParseL2Transactions(message)
+	}
+	txes, err := arbos.ParseL2Transactions(message, s.bc.Config().ChainID)
+	if err != nil {
+		log.Warn("error parsing incoming message", "err", err)
+		return types.Transactions{}
+	}
+	return txes
+}
+
 func (s *ExecutionEngine) SetConsensus(consensus execution.FullConsensusClient) {
 	if s.Started() {
 		panic("trying to set transaction consensus after start")
@@ -399,7 +465,7 @@ func (s *ExecutionEngine) resequenceReorgedMessages(messages []*arbostypes.Messa
 		}
 		hooks := arbos.NoopSequencingHooks()
 		hooks.DiscardInvalidTxsEarly = true
-		_, err = s.sequenceTransactionsWithBlockMutex(msg.Message.Header, txes, hooks)
+		_, err = s.sequenceTransactionsWithBlockMutex(msg.Message.Header, txes, hooks, false)
 		if err != nil {
 			log.Error("failed to re-sequence old user message removed by reorg", "err", err)
 			return
@@ -439,7 +505,14 @@ func (s *ExecutionEngine) sequencerWrapper(sequencerFunc func() (*types.Block, e
 func (s *ExecutionEngine) SequenceTransactions(header *arbostypes.L1IncomingMessageHeader, txes types.Transactions, hooks *arbos.SequencingHooks) (*types.Block, error) {
 	return s.sequencerWrapper(func() (*types.Block, error) {
 		hooks.TxErrors = nil
-		return s.sequenceTransactionsWithBlockMutex(header, txes, hooks)
+		return s.sequenceTransactionsWithBlockMutex(header, txes, hooks, false)
+	})
+}
+
+// SequenceEmptyBlock creates a block without user transactions, for dev nodes to advance the chain on demand
+func (s *ExecutionEngine) SequenceEmptyBlock(header *arbostypes.L1IncomingMessageHeader) (*types.Block, error) {
+	return s.sequencerWrapper(func() (*types.Block, error) {
+		return s.sequenceTransactionsWithBlockMutex(header, nil, arbos.NoopSequencingHooks(), true)
 	})
 }
 
@@ -481,7 +554,7 @@ func writeAndLog(pprof, trace *bytes.Buffer) {
 	log.Info("Transactions sequencing took longer than 2 seconds, created pprof and trace files", "pprof", pprofFile, "traceFile", traceFile)
 }
 
-func (s *ExecutionEngine) sequenceTransactionsWithBlockMutex(header *arbostypes.L1IncomingMessageHeader, txes types.Transactions, hooks *arbos.SequencingHooks) (*types.Block, error) {
+func (s *ExecutionEngine) sequenceTransactionsWithBlockMutex(header *arbostypes.L1IncomingMessageHeader, txes types.Transactions, hooks *arbos.SequencingHooks, allowEmpty bool) (*types.Block, error) {
 	lastBlockHeader, err := s.getCurrentHeader()
 	if err != nil {
 		return nil, err
@@ -525,7 +598,7 @@ func (s *ExecutionEngine) sequenceTransactionsWithBlockMutex(header *arbostypes.
 			break
 		}
 	}
-	if allTxsErrored {
+	if allTxsErrored && !allowEmpty {
 		return nil, nil
 	}
 
@@ -661,13 +734,15 @@ func (s *ExecutionEngine) createBlockFromNextMessage(msg *arbostypes.MessageWith
 	statedb.StartPrefetcher("TransactionStreamer")
 	defer statedb.StopPrefetcher()
 
-	block, receipts, err := arbos.ProduceBlock(
-		msg.Message,
+	block, receipts, err := arbos.ProduceBlockAdvanced(
+		msg.Message.Header,
+		s.parseL2Transactions(msg.Message),
 		msg.DelayedMessagesRead,
 		currentHeader,
 		statedb,
 		s.bc,
 		s.bc.Config(),
+		arbos.NoopSequencingHooks(),
 		isMsgForPrefetch,
 	)
 
@@ -680,6 +755,8 @@ func (s *ExecutionEngine) appendBlock(block *types.Block, statedb *state.StateDB
 	for _, receipt := range receipts {
 		logs = append(logs, receipt.Logs...)
 	}
+	// read before the state is committed with the block
+	recordCalldataUnits := s.calldataUnits != nil && s.calldataUnits.enabled(statedb)
 	status, err := s.bc.WriteBlockAndSetHeadWithTime(block, receipts, logs, statedb, true, duration)
 	if err != nil {
 		return err
@@ -697,7 +774,19 @@ func (s *ExecutionEngine) appendBlock(block *types.Block, statedb *state.StateDB
 	}
 	blockGasUsedHistogram.Update(int64(blockGasused))
 	gasUsedSinceStartupCounter.Inc(int64(blockGasused))
+	s.retryableMetrics.update(block, receipts)
 	s.updateL1GasPriceEstimateMetric()
+	if recordCalldataUnits {
+		if err := s.calldataUnits.recordBlock(block.Transactions(), block.BaseFee()); err != nil {
+			log.Error("failed to record calldata units", "block", block.NumberU64(), "err", err)
+		}
+	}
+	if s.eventIndex != nil {
+		if err := s.eventIndex.indexBlock(block.NumberU64(), receipts); err != nil {
+			// the index restarts from the next block, so queries over this one fall back to the filter system
+			log.Error("failed to index block events", "block", block.NumberU64(), "err", err)
+		}
+	}
 	return nil
 }
 
@@ -755,6 +844,50 @@ func (s *ExecutionEngine) getL1PricingSurplus() (int64, error) {
 	return surplus.Int64(), nil
 }
 
+// ParentChainTiming returns the parent chain's cadence as of the latest block, which is Ethereum's before the
+// ArbOS version that lets the chain owner set it.
+func (s *ExecutionEngine) ParentChainTiming() (execution.ParentChainTiming, error) {
+	timing := execution.ParentChainTiming{BlockTime: l1timing.DefaultBlockTime}
+	latestState, err := s.bc.StateAt(s.bc.CurrentBlock().Root)
+	if err != nil {
+		return timing, err
+	}
+	arbState, err := arbosState.OpenSystemArbosState(latestState, nil, true)
+	if err != nil {
+		return timing, err
+	}
+	if arbState.ArbOSVersion() < arbosState.ArbosVersion_ParentChainTiming {
+		return timing, nil
+	}
+	l1Timing := arbState.L1TimingState()
+	if timing.BlockTime, err = l1Timing.BlockTime(); err != nil {
+		return timing, err
+	}
+	timing.DelayedFinalityBlocks, err = l1Timing.DelayedFinalityBlocks()
+	return timing, err
+}
+
+// BatchDictionaryCommitted returns whether the chain committed to the brotli dictionary of the given hash as of
+// the block of messageNum. No dictionary is before the ArbOS version that lets the chain owner commit to them.
+func (s *ExecutionEngine) BatchDictionaryCommitted(messageNum arbutil.MessageIndex, hash common.Hash) (bool, error) {
+	block := s.bc.GetBlockByNumber(s.MessageIndexToBlockNumber(messageNum))
+	if block == nil {
+		return false, fmt.Errorf("%w: message %d", execution.ErrMessageNotExecuted, messageNum)
+	}
+	statedb, err := s.bc.StateAt(block.Root())
+	if err != nil {
+		return false, err
+	}
+	arbState, err := arbosState.OpenSystemArbosState(statedb, nil, true)
+	if err != nil {
+		return false, err
+	}
+	if arbState.ArbOSVersion() < arbosState.ArbosVersion_BatchDictionaries {
+		return false, nil
+	}
+	return arbState.BatchDictionaries().IsCommitted(hash)
+}
+
 func (s *ExecutionEngine) cacheL1PriceDataOfMsg(seqNum arbutil.MessageIndex, receipts types.Receipts, block *types.Block, blockBuiltUsingDelayedMessage bool) {
 	var gasUsedForL1 uint64
 	var callDataUnits uint64
@@ -843,6 +976,9 @@ func (s *ExecutionEngine) digestMessageWithBlockMutex(num arbutil.MessageIndex,
 	}
 
 	startTime := time.Now()
+	if msgForPrefetch != nil {
+		s.PrefetchSenders(num+1, []*arbostypes.MessageWithMetadata{msgForPrefetch})
+	}
 	if s.prefetchBlock && msgForPrefetch != nil {
 		go func() {
 			_, _, _, err := s.createBlockFromNextMessage(msgForPrefetch, true)
@@ -926,6 +1062,11 @@ func (s *ExecutionEngine) ArbOSVersionForMessageNumber(messageNum arbutil.Messag
 
 func (s *ExecutionEngine) Start(ctx_in context.Context) {
 	s.StopWaiter.Start(ctx_in, s)
+	if s.senderRecoverer != nil {
+		for i := 0; i < s.senderRecoverer.numWorkers(); i++ {
+			s.LaunchThread(s.senderRecoverer.worker)
+		}
+	}
 	s.LaunchThread(func(ctx context.Context) {
 		for {
 			select {
@@ -23,6 +23,8 @@ import (
 	"github.com/offchainlabs/nitro/arbos/programs"
 	"github.com/offchainlabs/nitro/arbutil"
 	"github.com/offchainlabs/nitro/execution"
+	"github.com/offchainlabs/nitro/execution/execrpc"
+	"github.com/offchainlabs/nitro/precompiles"
 	"github.com/offchainlabs/nitro/solgen/go/precompilesgen"
 	"github.com/offchainlabs/nitro/util/dbutil"
 	"github.com/offchainlabs/nitro/util/headerreader"
@@ -89,8 +91,17 @@ type Config struct {
 	RPC                       arbitrum.Config                  `koanf:"rpc"`
 	TxLookupLimit             uint64                           `koanf:"tx-lookup-limit"`
 	EnablePrefetchBlock       bool                             `koanf:"enable-prefetch-block"`
+	SenderRecovery            SenderRecoveryConfig             `koanf:"sender-recovery"`
+	EventIndex                EventIndexConfig                 `koanf:"event-index"`
+	Replica                   ReplicaConfig                    `koanf:"replica"`
+	RPCPolicy                 RPCPolicyConfig                  `koanf:"rpc-policy" reload:"hot"`
+	BundleSimulation          BundleSimulationConfig           `koanf:"bundle-simulation"`
 	SyncMonitor               SyncMonitorConfig                `koanf:"sync-monitor"`
 	StylusTarget              StylusTargetConfig               `koanf:"stylus-target"`
+	ExecutionServer           execrpc.ServerConfig             `koanf:"execution-server"`
+	ConsensusClient           execrpc.ClientConfig             `koanf:"consensus-client"`
+	PrecompileGasAudit        bool                             `koanf:"precompile-gas-audit"`
+	Dev                       DevConfig                        `koanf:"dev"`
 
 	forwardingTarget string
 }
@@ -102,7 +113,22 @@ func (c *Config) Validate() error {
 	if err := c.Sequencer.Validate(); err != nil {
 		return err
 	}
-	if !c.Sequencer.Enable && c.ForwardingTarget == "" {
+	if err := c.Replica.Validate(); err != nil {
+		return err
+	}
+	if err := c.TxPreChecker.Validate(); err != nil {
+		return err
+	}
+	if err := c.RPCPolicy.Validate(); err != nil {
+		return err
+	}
+	if c.Dev.Enable && !c.Sequencer.Enable {
+		return errDevRequiresSequencer
+	}
+	if c.Replica.Enable && c.Sequencer.Enable {
+		return errors.New("replica mode is read-only and can't be enabled together with the sequencer")
+	}
+	if !c.Sequencer.Enable && !c.Replica.Enable && c.ForwardingTarget == "" {
 		return errors.New("ForwardingTarget not set and not sequencer (can use \"null\")")
 	}
 	if c.ForwardingTarget == "null" {
@@ -116,6 +142,15 @@ func (c *Config) Validate() error {
 	if err := c.StylusTarget.Validate(); err != nil {
 		return err
 	}
+	if err := c.SenderRecovery.Validate(); err != nil {
+		return err
+	}
+	if err := c.EventIndex.Validate(); err != nil {
+		return err
+	}
+	if err := c.BundleSimulation.Validate(); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -132,7 +167,16 @@ func ConfigAddOptions(prefix string, f *flag.FlagSet) {
 	SyncMonitorConfigAddOptions(prefix+".sync-monitor", f)
 	f.Uint64(prefix+".tx-lookup-limit", ConfigDefault.TxLookupLimit, "retain the ability to lookup transactions by hash for the past N blocks (0 = all blocks)")
 	f.Bool(prefix+".enable-prefetch-block", ConfigDefault.EnablePrefetchBlock, "enable prefetching of blocks")
+	SenderRecoveryConfigAddOptions(prefix+".sender-recovery", f)
+	EventIndexConfigAddOptions(prefix+".event-index", f)
+	ReplicaConfigAddOptions(prefix+".replica", f)
+	RPCPolicyConfigAddOptions(prefix+".rpc-policy", f)
+	BundleSimulationConfigAddOptions(prefix+".bundle-simulation", f)
 	StylusTargetConfigAddOptions(prefix+".stylus-target", f)
+	DevConfigAddOptions(prefix+".dev", f)
+	execrpc.ServerConfigAddOptions(prefix+".execution-server", f, &ConfigDefault.ExecutionServer)
+	execrpc.ClientConfigAddOptions(prefix+".consensus-client", f, &ConfigDefault.ConsensusClient)
+	f.Bool(prefix+".precompile-gas-audit", ConfigDefault.PrecompileGasAudit, "audit the gas charged by precompile methods against the storage accesses, allocations and time of their calls, readable with arbdebug_precompileGasAudit (slows execution)")
 }
 
 var ConfigDefault = Config{
@@ -147,7 +191,16 @@ var ConfigDefault = Config{
 	Caching:                   DefaultCachingConfig,
 	Forwarder:                 DefaultNodeForwarderConfig,
 	EnablePrefetchBlock:       true,
+	SenderRecovery:            DefaultSenderRecoveryConfig,
+	EventIndex:                DefaultEventIndexConfig,
+	Replica:                   DefaultReplicaConfig,
+	RPCPolicy:                 DefaultRPCPolicyConfig,
+	BundleSimulation:          DefaultBundleSimulationConfig,
 	StylusTarget:              DefaultStylusTargetConfig,
+	ExecutionServer:           execrpc.DefaultExecutionServerConfig,
+	ConsensusClient:           execrpc.DefaultClientConfig,
+	PrecompileGasAudit:        false,
+	Dev:                       DefaultDevConfig,
 }
 
 type ConfigFetcher func() *Config
@@ -165,6 +218,9 @@ type ExecutionNode struct {
 	SyncMonitor       *SyncMonitor
 	ParentChainReader *headerreader.HeaderReader
 	ClassicOutbox     *ClassicOutboxRetriever
+	ReplicaMonitor    *ReplicaMonitor // nil unless running as a read-only replica
+	ExecutionServer   *execrpc.Server // nil unless serving a consensus node in another process
+	OutboxAPI         *ArbOutboxAPI
 	started           atomic.Bool
 }
 
@@ -178,11 +234,17 @@ func CreateExecutionNode(
 ) (*ExecutionNode, error) {
 	config := configFetcher()
 	execEngine, err := NewExecutionEngine(l2BlockChain)
+	if err != nil {
+		return nil, err
+	}
 	if config.EnablePrefetchBlock {
 		execEngine.EnablePrefetchBlock()
 	}
-	if err != nil {
-		return nil, err
+	if config.PrecompileGasAudit {
+		precompiles.StartGasAudit()
+	}
+	if config.SenderRecovery.Enable {
+		execEngine.EnableSenderRecovery(&config.SenderRecovery)
 	}
 	recorder := NewBlockRecorder(&config.RecordingDatabase, execEngine, chainDB)
 	var txPublisher TransactionPublisher
@@ -206,6 +268,9 @@ func CreateExecutionNode(
 			return nil, err
 		}
 		txPublisher = sequencer
+	} else if config.Replica.Enable {
+		// replicas never forward transactions, their state comes only from the feed and parent chain
+		txPublisher = NewTxDropper()
 	} else {
 		if config.Forwarder.RedisUrl != "" {
 			txPublisher = NewRedisTxForwarder(config.forwardingTarget, &config.Forwarder)
@@ -235,6 +300,11 @@ func CreateExecutionNode(
 
 	syncMon := NewSyncMonitor(&config.SyncMonitor, execEngine)
 
+	var replicaMonitor *ReplicaMonitor
+	if config.Replica.Enable {
+		replicaMonitor = NewReplicaMonitor(func() *ReplicaConfig { return &configFetcher().Replica }, execEngine, syncMon)
+	}
+
 	var classicOutbox *ClassicOutboxRetriever
 
 	if l2BlockChain.Config().ArbitrumChainParams.GenesisBlockNum > 0 {
@@ -258,6 +328,27 @@ func CreateExecutionNode(
 		Service:   NewArbAPI(txPublisher),
 		Public:    false,
 	}}
+	outboxAPI := NewArbOutboxAPI(l2BlockChain, chainDB)
+	apis = append(apis, rpc.API{
+		Namespace: "arb",
+		Version:   "1.0",
+		Service:   outboxAPI,
+		Public:    false,
+	})
+	apis = append(apis, rpc.API{
+		Namespace: "arb",
+		Version:   "1.0",
+		Service:   NewArbRetryableAPI(l2BlockChain, chainDB),
+		Public:    false,
+	})
+	calldataUnits := NewCalldataUnitsStore(chainDB)
+	execEngine.EnableCalldataUnitsStore(calldataUnits)
+	apis = append(apis, rpc.API{
+		Namespace: "arb",
+		Version:   "1.0",
+		Service:   NewArbCalldataUnitsAPI(calldataUnits, chainDB),
+		Public:    false,
+	})
 	apis = append(apis, rpc.API{
 		Namespace: "arbdebug",
 		Version:   "1.0",
@@ -283,9 +374,54 @@ func CreateExecutionNode(
 		Public:    false,
 	})
 
-	stack.RegisterAPIs(apis)
-
-	return &ExecutionNode{
+	if config.EventIndex.Enable {
+		eventIndex, err := NewEventIndex(chainDB, l2BlockChain, &config.EventIndex)
+		if err != nil {
+			return nil, err
+		}
+		execEngine.EnableEventIndex(eventIndex)
+		// registered after geth's eth API, so that this eth_getLogs replaces geth's
+		apis = append(apis, rpc.API{
+			Namespace: "eth",
+			Version:   "1.0",
+			Service:   NewEventIndexAPI(eventIndex, filterSystem),
+			Public:    true,
+		})
+	}
+	if config.BundleSimulation.Enable {
+		apis = append(apis, rpc.API{
+			Namespace: "arb",
+			Version:   "1.0",
+			Service:   NewArbBundleAPI(l2BlockChain, func() *BundleSimulationConfig { return &configFetcher().BundleSimulation }),
+			Public:    false,
+		})
+	}
+	if replicaMonitor != nil {
+		apis = append(apis, rpc.API{
+			Namespace: "replica",
+			Version:   "1.0",
+			Service:   NewReplicaAPI(replicaMonitor),
+			Public:    false,
+		})
+	}
+	if config.Dev.Enable && sequencer != nil {
+		apis = append(apis, rpc.API{
+			Namespace: "evm",
+			Version:   "1.0",
+			Service:   NewDevAPI(sequencer),
+			Public:    false,
+		})
+	}
+	if policy := activeRPCPolicy.Load(); policy != nil && config.RPCPolicy.Enable {
+		apis = append(apis, rpc.API{
+			Namespace: "rpcpolicy",
+			Version:   "1.0",
+			Service:   NewRPCPolicyAPI(policy),
+			Public:    false,
+		})
+	}
+
+	execNode := &ExecutionNode{
 		ChainDB:           chainDB,
 		Backend:           backend,
 		FilterSystem:      filterSystem,
@@ -298,8 +434,19 @@ func CreateExecutionNode(
 		SyncMonitor:       syncMon,
 		ParentChainReader: parentChainReader,
 		ClassicOutbox:     classicOutbox,
-	}, nil
+		ReplicaMonitor:    replicaMonitor,
+		OutboxAPI:         outboxAPI,
+	}
+	if config.ExecutionServer.Enable {
+		execNode.ExecutionServer, err = execrpc.NewServer(&config.ExecutionServer, execrpc.ExecutionService, execrpc.NewExecutionServerAPI(execNode))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	stack.RegisterAPIs(apis)
 
+	return execNode, nil
 }
 
 func (n *ExecutionNode) MarkFeedStart(to arbutil.MessageIndex) {
@@ -346,6 +493,24 @@ func (n *ExecutionNode) Start(ctx context.Context) error {
 	if n.ParentChainReader != nil {
 		n.ParentChainReader.Start(ctx)
 	}
+	if n.ReplicaMonitor != nil {
+		n.ReplicaMonitor.Start(ctx)
+		activeReplicaMonitor.Store(n.ReplicaMonitor)
+	}
+	if n.ExecutionServer != nil {
+		if err := n.ExecutionServer.Start(ctx); err != nil {
+			return fmt.Errorf("error starting execution server: %w", err)
+		}
+	}
+	return nil
+}
+
+// OnConfigReload applies reloaded settings that aren't read through the config fetcher.
+func (n *ExecutionNode) OnConfigReload(oldConfig *Config, newConfig *Config) error {
+	if policy := activeRPCPolicy.Load(); policy != nil && !oldConfig.RPCPolicy.limitsEqual(&newConfig.RPCPolicy) {
+		policy.Reconfigure(&newConfig.RPCPolicy)
+		log.Info("applied reloaded rpc policy limits")
+	}
 	return nil
 }
 
@@ -355,6 +520,12 @@ func (n *ExecutionNode) StopAndWait() {
 	}
 	// TODO after separation
 	// n.Stack.StopRPC() // does nothing if not running
+	if n.ExecutionServer != nil && n.ExecutionServer.Started() {
+		n.ExecutionServer.StopAndWait()
+	}
+	if n.ReplicaMonitor != nil && n.ReplicaMonitor.Started() {
+		n.ReplicaMonitor.StopAndWait()
+	}
 	if n.TxPublisher.Started() {
 		n.TxPublisher.StopAndWait()
 	}
@@ -378,6 +549,9 @@ func (n *ExecutionNode) StopAndWait() {
 func (n *ExecutionNode) DigestMessage(num arbutil.MessageIndex, msg *arbostypes.MessageWithMetadata, msgForPrefetch *arbostypes.MessageWithMetadata) (*execution.MessageResult, error) {
 	return n.ExecEngine.DigestMessage(num, msg, msgForPrefetch)
 }
+func (n *ExecutionNode) PrefetchSenders(start arbutil.MessageIndex, msgs []*arbostypes.MessageWithMetadata) {
+	n.ExecEngine.PrefetchSenders(start, msgs)
+}
 func (n *ExecutionNode) Reorg(count arbutil.MessageIndex, newMessages []arbostypes.MessageWithMetadataAndBlockHash, oldMessages []*arbostypes.MessageWithMetadata) ([]*execution.MessageResult, error) {
 	return n.ExecEngine.Reorg(count, newMessages, oldMessages)
 }
@@ -390,6 +564,12 @@ func (n *ExecutionNode) HeadMessageNumberSync(t *testing.T) (arbutil.MessageInde
 func (n *ExecutionNode) NextDelayedMessageNumber() (uint64, error) {
 	return n.ExecEngine.NextDelayedMessageNumber()
 }
+func (n *ExecutionNode) ParentChainTiming() (execution.ParentChainTiming, error) {
+	return n.ExecEngine.ParentChainTiming()
+}
+func (n *ExecutionNode) BatchDictionaryCommitted(messageNum arbutil.MessageIndex, hash common.Hash) (bool, error) {
+	return n.ExecEngine.BatchDictionaryCommitted(messageNum, hash)
+}
 func (n *ExecutionNode) SequenceDelayedMessage(message *arbostypes.L1IncomingMessage, delayedSeqNum uint64) error {
 	return n.ExecEngine.SequenceDelayedMessage(message, delayedSeqNum)
 }
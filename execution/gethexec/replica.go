@@ -0,0 +1,227 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package gethexec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/offchainlabs/nitro/util/stopwaiter"
+)
+
+var (
+	replicaStalenessGauge       = metrics.NewRegisteredGauge("arb/replica/staleness_ms", nil)
+	replicaRejectedReadsCounter = metrics.NewRegisteredCounter("arb/replica/rejected_reads", nil)
+)
+
+const ReplicaStalenessHeader = "X-Arbitrum-Replica-Staleness-Ms"
+
+type ReplicaConfig struct {
+	Enable        bool          `koanf:"enable"`
+	MaxStaleness  time.Duration `koanf:"max-staleness" reload:"hot"`
+	CheckInterval time.Duration `koanf:"check-interval"`
+}
+
+var DefaultReplicaConfig = ReplicaConfig{
+	Enable:        false,
+	MaxStaleness:  0,
+	CheckInterval: 250 * time.Millisecond,
+}
+
+func ReplicaConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.Bool(prefix+".enable", DefaultReplicaConfig.Enable, "run as a read-only replica: state is only derived from the feed and parent chain data, transactions are rejected rather than forwarded, and every HTTP RPC response carries the replica's staleness in the "+ReplicaStalenessHeader+" header (WebSocket and IPC clients can follow it with the replica statusUpdates subscription)")
+	f.Duration(prefix+".max-staleness", DefaultReplicaConfig.MaxStaleness, "reject HTTP RPC requests with HTTP 503 while the replica is staler than this (0 = never reject); WebSocket and IPC requests are served regardless")
+	f.Duration(prefix+".check-interval", DefaultReplicaConfig.CheckInterval, "how often the replica's staleness is recomputed")
+}
+
+func (c *ReplicaConfig) Validate() error {
+	if !c.Enable {
+		return nil
+	}
+	if c.MaxStaleness < 0 {
+		return errors.New("replica max-staleness must not be negative")
+	}
+	if c.CheckInterval <= 0 {
+		return errors.New("replica check-interval must be positive")
+	}
+	return nil
+}
+
+type ReplicaStatus struct {
+	Synced         bool           `json:"synced"`
+	HeadBlock      hexutil.Uint64 `json:"headBlock"`
+	HeadTimestamp  hexutil.Uint64 `json:"headTimestamp"`
+	StalenessMs    int64          `json:"stalenessMs"`
+	MaxStalenessMs int64          `json:"maxStalenessMs"`
+	Stale          bool           `json:"stale"`
+}
+
+// ReplicaMonitor tracks how far a read-only replica's state lags behind the chain. The staleness
+// is the time since the replica was last fully synced with the feed and parent chain, capped by
+// the age of its head block, so a replica that stops receiving data becomes stale even while it
+// believes it has executed every message it knows about.
+type ReplicaMonitor struct {
+	stopwaiter.StopWaiter
+	config      func() *ReplicaConfig
+	exec        *ExecutionEngine
+	syncMonitor *SyncMonitor
+
+	lastSynced atomic.Int64 // unix milliseconds
+	status     atomic.Pointer[ReplicaStatus]
+	statusFeed event.Feed
+}
+
+func NewReplicaMonitor(config func() *ReplicaConfig, exec *ExecutionEngine, syncMonitor *SyncMonitor) *ReplicaMonitor {
+	return &ReplicaMonitor{
+		config:      config,
+		exec:        exec,
+		syncMonitor: syncMonitor,
+	}
+}
+
+func (m *ReplicaMonitor) update(now time.Time) *ReplicaStatus {
+	status := &ReplicaStatus{
+		MaxStalenessMs: m.config().MaxStaleness.Milliseconds(),
+	}
+	freshSince := time.UnixMilli(m.lastSynced.Load())
+	if m.syncMonitor.consensus != nil && m.syncMonitor.Synced() {
+		status.Synced = true
+		freshSince = now
+		m.lastSynced.Store(now.UnixMilli())
+	}
+	header, err := m.exec.getCurrentHeader()
+	if err == nil {
+		status.HeadBlock = hexutil.Uint64(header.Number.Uint64())
+		status.HeadTimestamp = hexutil.Uint64(header.Time)
+		// #nosec G115
+		headTime := time.Unix(int64(header.Time), 0)
+		if headTime.After(freshSince) {
+			freshSince = headTime
+		}
+	}
+	staleness := now.Sub(freshSince)
+	if staleness < 0 {
+		staleness = 0
+	}
+	status.StalenessMs = staleness.Milliseconds()
+	status.Stale = status.MaxStalenessMs > 0 && staleness > m.config().MaxStaleness
+	replicaStalenessGauge.Update(status.StalenessMs)
+	m.status.Store(status)
+	m.statusFeed.Send(status)
+	return status
+}
+
+func (m *ReplicaMonitor) Start(ctx context.Context) {
+	m.StopWaiter.Start(ctx, m)
+	m.CallIteratively(func(ctx context.Context) time.Duration {
+		m.update(time.Now())
+		return m.config().CheckInterval
+	})
+}
+
+// Status returns the most recently computed replica status.
+func (m *ReplicaMonitor) Status() *ReplicaStatus {
+	if status := m.status.Load(); status != nil {
+		return status
+	}
+	return m.update(time.Now())
+}
+
+type replicaHTTPHandler struct {
+	inner   http.Handler
+	monitor *atomic.Pointer[ReplicaMonitor]
+}
+
+func (h *replicaHTTPHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	monitor := h.monitor.Load()
+	if monitor == nil {
+		http.Error(w, "replica not ready", http.StatusServiceUnavailable)
+		return
+	}
+	status := monitor.Status()
+	w.Header().Set(ReplicaStalenessHeader, strconv.FormatInt(status.StalenessMs, 10))
+	if status.Stale {
+		replicaRejectedReadsCounter.Inc(1)
+		http.Error(w, fmt.Sprintf("replica is stale: %dms behind, maximum is %dms", status.StalenessMs, status.MaxStalenessMs), http.StatusServiceUnavailable)
+		return
+	}
+	h.inner.ServeHTTP(w, req)
+}
+
+var activeReplicaMonitor atomic.Pointer[ReplicaMonitor]
+
+// InitReplicaHTTPHandler adds the replica staleness header and read rejection to geth's stack of
+// http.Handlers, on top of any handler wrapper already installed.
+//
+// Must be run before the go-ethereum stack is set up (ethereum/go-ethereum/node.New).
+func InitReplicaHTTPHandler(config *ReplicaConfig) {
+	if !config.Enable {
+		return
+	}
+	previousWrapper := node.WrapHTTPHandler
+	node.WrapHTTPHandler = func(srv http.Handler) (http.Handler, error) {
+		if previousWrapper != nil {
+			var err error
+			srv, err = previousWrapper(srv)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return &replicaHTTPHandler{inner: srv, monitor: &activeReplicaMonitor}, nil
+	}
+}
+
+type ReplicaAPI struct {
+	monitor *ReplicaMonitor
+}
+
+func NewReplicaAPI(monitor *ReplicaMonitor) *ReplicaAPI {
+	return &ReplicaAPI{monitor}
+}
+
+func (a *ReplicaAPI) Status(ctx context.Context) *ReplicaStatus {
+	return a.monitor.Status()
+}
+
+// StatusUpdates notifies the subscriber of the replica's status each time it's recomputed. Responses on WebSocket
+// and IPC connections don't carry the staleness header and aren't rejected while stale, so this is how clients
+// on them follow the staleness.
+func (a *ReplicaAPI) StatusUpdates(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	rpcSub := notifier.CreateSubscription()
+	statuses := make(chan *ReplicaStatus, 16)
+	sub := a.monitor.statusFeed.Subscribe(statuses)
+	go func() {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case status := <-statuses:
+				if err := notifier.Notify(rpcSub.ID, status); err != nil {
+					return
+				}
+			case <-rpcSub.Err():
+				return
+			case <-sub.Err():
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
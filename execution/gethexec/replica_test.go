@@ -0,0 +1,85 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package gethexec
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+func TestReplicaHTTPHandler(t *testing.T) {
+	served := 0
+	inner := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		served++
+	})
+	var monitorPtr atomic.Pointer[ReplicaMonitor]
+	handler := &replicaHTTPHandler{inner: inner, monitor: &monitorPtr}
+
+	serve := func() *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/", nil))
+		return recorder
+	}
+
+	if res := serve(); res.Code != http.StatusServiceUnavailable {
+		t.Fatal("expected requests to be rejected before the replica monitor is set, got", res.Code)
+	}
+
+	monitor := NewReplicaMonitor(func() *ReplicaConfig { return &DefaultReplicaConfig }, nil, nil)
+	monitor.status.Store(&ReplicaStatus{StalenessMs: 1500})
+	monitorPtr.Store(monitor)
+	res := serve()
+	if res.Code != http.StatusOK || served != 1 {
+		t.Fatal("expected fresh replica to serve request, got", res.Code)
+	}
+	if header := res.Header().Get(ReplicaStalenessHeader); header != "1500" {
+		t.Fatal("unexpected staleness header", header)
+	}
+
+	monitor.status.Store(&ReplicaStatus{StalenessMs: 5000, MaxStalenessMs: 2000, Stale: true})
+	res = serve()
+	if res.Code != http.StatusServiceUnavailable || served != 1 {
+		t.Fatal("expected stale replica to reject request, got", res.Code)
+	}
+	if header := res.Header().Get(ReplicaStalenessHeader); header != "5000" {
+		t.Fatal("unexpected staleness header", header)
+	}
+}
+
+func TestReplicaStatusUpdates(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	monitor := NewReplicaMonitor(func() *ReplicaConfig { return &DefaultReplicaConfig }, nil, nil)
+	server := rpc.NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("replica", NewReplicaAPI(monitor)); err != nil {
+		t.Fatal(err)
+	}
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	statuses := make(chan *ReplicaStatus, 1)
+	sub, err := client.Subscribe(ctx, "replica", statuses, "statusUpdates")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+	monitor.statusFeed.Send(&ReplicaStatus{StalenessMs: 5000, MaxStalenessMs: 2000, Stale: true})
+	select {
+	case status := <-statuses:
+		if status.StalenessMs != 5000 || !status.Stale {
+			t.Fatal("unexpected status", status)
+		}
+	case err := <-sub.Err():
+		t.Fatal(err)
+	case <-ctx.Done():
+		t.Fatal("no status update received")
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package gethexec
+
+import (
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/metrics"
+
+	"github.com/offchainlabs/nitro/arbos"
+	"github.com/offchainlabs/nitro/util/containers"
+)
+
+var (
+	retryableCreatedCounter           = metrics.NewRegisteredCounter("arb/retryable/created", nil)
+	retryableExpiredCounter           = metrics.NewRegisteredCounter("arb/retryable/expired", nil)
+	retryableAutoRedeemSuccessCounter = metrics.NewRegisteredCounter("arb/retryable/redeem/auto/success", nil)
+	retryableAutoRedeemFailureCounter = metrics.NewRegisteredCounter("arb/retryable/redeem/auto/failure", nil)
+	retryableRedeemSuccessCounter     = metrics.NewRegisteredCounter("arb/retryable/redeem/manual/success", nil)
+	retryableRedeemFailureCounter     = metrics.NewRegisteredCounter("arb/retryable/redeem/manual/failure", nil)
+	retryableTimeToRedeemHistogram    = metrics.NewRegisteredHistogram("arb/retryable/time_to_redeem", nil, metrics.NewBoundedHistogramSample())
+	l2ToL1SendsCounter                = metrics.NewRegisteredCounter("arb/l2tol1/sends", nil)
+	l2ToL1SendsPerBlockHistogram      = metrics.NewRegisteredHistogram("arb/l2tol1/sends_per_block", nil, metrics.NewBoundedHistogramSample())
+)
+
+// number of open retryables whose creation time is remembered, to measure the time it takes to redeem them
+const retryableCreationTimesSize = 10_000
+
+// retryableMetrics follows the retryable lifecycle and the L2->L1 messages through the receipts of each appended
+// block. Only blocks appended by this node are counted, unlike in the state transition, which is also re-executed for
+// recording and calls.
+type retryableMetrics struct {
+	mutex         sync.Mutex
+	creationTimes *containers.LruCache[common.Hash, uint64]
+}
+
+func newRetryableMetrics() *retryableMetrics {
+	return &retryableMetrics{
+		creationTimes: containers.NewLruCache[common.Hash, uint64](retryableCreationTimesSize),
+	}
+}
+
+func (m *retryableMetrics) update(block *types.Block, receipts types.Receipts) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	txs := block.Transactions()
+	blockTime := block.Time()
+	createdInBlock := make(map[common.Hash]struct{})
+	var l2ToL1Sends int64
+	for i, receipt := range receipts {
+		for _, txLog := range receipt.Logs {
+			if len(txLog.Topics) == 0 {
+				continue
+			}
+			switch {
+			case txLog.Address == arbos.ArbRetryableTxAddress && txLog.Topics[0] == arbos.TicketCreatedEventID && len(txLog.Topics) > 1:
+				ticketId := txLog.Topics[1]
+				retryableCreatedCounter.Inc(1)
+				createdInBlock[ticketId] = struct{}{}
+				m.creationTimes.Add(ticketId, blockTime)
+			case txLog.Address == arbos.ArbRetryableTxAddress && txLog.Topics[0] == arbos.TicketExpiredEventID && len(txLog.Topics) > 1:
+				retryableExpiredCounter.Inc(1)
+				m.creationTimes.Remove(txLog.Topics[1])
+			case txLog.Address == arbos.ArbSysAddress && (txLog.Topics[0] == arbos.L2ToL1TxEventID || txLog.Topics[0] == arbos.L2ToL1TransactionEventID):
+				l2ToL1Sends++
+			}
+		}
+		if i >= len(txs) {
+			continue
+		}
+		retryTx, ok := txs[i].GetInner().(*types.ArbitrumRetryTx)
+		if !ok {
+			continue
+		}
+		success := receipt.Status == types.ReceiptStatusSuccessful
+		// a redeem scheduled in the block that created the retryable is its auto-redeem
+		_, autoRedeem := createdInBlock[retryTx.TicketId]
+		switch {
+		case autoRedeem && success:
+			retryableAutoRedeemSuccessCounter.Inc(1)
+		case autoRedeem:
+			retryableAutoRedeemFailureCounter.Inc(1)
+		case success:
+			retryableRedeemSuccessCounter.Inc(1)
+		default:
+			retryableRedeemFailureCounter.Inc(1)
+		}
+		if !success {
+			continue
+		}
+		if createdAt, ok := m.creationTimes.Get(retryTx.TicketId); ok {
+			if blockTime >= createdAt {
+				// #nosec G115
+				retryableTimeToRedeemHistogram.Update(int64(blockTime - createdAt))
+			}
+			m.creationTimes.Remove(retryTx.TicketId)
+		}
+	}
+	l2ToL1SendsCounter.Inc(l2ToL1Sends)
+	l2ToL1SendsPerBlockHistogram.Update(l2ToL1Sends)
+}
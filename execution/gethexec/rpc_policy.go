@@ -0,0 +1,594 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package gethexec
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/node"
+
+	"github.com/offchainlabs/nitro/util/containers"
+)
+
+var (
+	rpcPolicyRejectedMethodCounter = metrics.NewRegisteredCounter("arb/rpcpolicy/rejected/method", nil)
+	rpcPolicyRejectedSenderCounter = metrics.NewRegisteredCounter("arb/rpcpolicy/rejected/sender", nil)
+	rpcPolicyCappedCallGasCounter  = metrics.NewRegisteredCounter("arb/rpcpolicy/capped_call_gas", nil)
+)
+
+const (
+	// same as geth's default http request body limit, larger requests are passed on for geth to reject
+	rpcPolicyMaxBodySize = 5 * 1024 * 1024
+	// EIP-1474 "limit exceeded"
+	rpcPolicyLimitExceededCode = -32005
+)
+
+type RPCPolicyConfig struct {
+	Enable            bool     `koanf:"enable"`
+	MethodLimits      []string `koanf:"method-limits" reload:"hot"`
+	SenderTxLimit     float64  `koanf:"sender-tx-limit" reload:"hot"`
+	SenderTxBurst     uint64   `koanf:"sender-tx-burst" reload:"hot"`
+	MaxTrackedSenders int      `koanf:"max-tracked-senders"`
+	CallGasCap        uint64   `koanf:"call-gas-cap" reload:"hot"`
+
+	methodLimits map[string]RPCRateLimit
+}
+
+var DefaultRPCPolicyConfig = RPCPolicyConfig{
+	Enable:            false,
+	MethodLimits:      []string{},
+	SenderTxLimit:     0,
+	SenderTxBurst:     0,
+	MaxTrackedSenders: 100_000,
+	CallGasCap:        0,
+}
+
+func RPCPolicyConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.Bool(prefix+".enable", DefaultRPCPolicyConfig.Enable, "enforce the rate limits and gas cap below on JSON-RPC requests over HTTP, WebSocket and IPC, and enable the rpcpolicy API to change them at runtime")
+	f.StringSlice(prefix+".method-limits", DefaultRPCPolicyConfig.MethodLimits, "requests per second allowed for each listed method across all clients, each given as method:rate or method:rate:burst")
+	f.Float64(prefix+".sender-tx-limit", DefaultRPCPolicyConfig.SenderTxLimit, "eth_sendRawTransaction calls per second allowed for each transaction sender (0 = unlimited)")
+	f.Uint64(prefix+".sender-tx-burst", DefaultRPCPolicyConfig.SenderTxBurst, "eth_sendRawTransaction calls a sender may burst above its rate (0 = the rate rounded up)")
+	f.Int(prefix+".max-tracked-senders", DefaultRPCPolicyConfig.MaxTrackedSenders, "number of recently seen senders whose rate limits are tracked")
+	f.Uint64(prefix+".call-gas-cap", DefaultRPCPolicyConfig.CallGasCap, "gas limit eth_call requests are capped to, applied when the request gives no gas or more than this (0 = no cap)")
+}
+
+func (c *RPCPolicyConfig) Validate() error {
+	c.methodLimits = make(map[string]RPCRateLimit)
+	if !c.Enable {
+		return nil
+	}
+	for _, entry := range c.MethodLimits {
+		parts := strings.Split(entry, ":")
+		if len(parts) < 2 || len(parts) > 3 || parts[0] == "" {
+			return fmt.Errorf("invalid rpc-policy method limit %q, expected method:rate or method:rate:burst", entry)
+		}
+		var limit RPCRateLimit
+		var err error
+		limit.Rate, err = strconv.ParseFloat(parts[1], 64)
+		if err != nil {
+			return fmt.Errorf("invalid rate in rpc-policy method limit %q: %w", entry, err)
+		}
+		if len(parts) == 3 {
+			limit.Burst, err = strconv.ParseUint(parts[2], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid burst in rpc-policy method limit %q: %w", entry, err)
+			}
+		}
+		if err := limit.validate(); err != nil {
+			return fmt.Errorf("invalid rpc-policy method limit %q: %w", entry, err)
+		}
+		if _, exists := c.methodLimits[parts[0]]; exists {
+			return fmt.Errorf("duplicate rpc-policy method limit for %s", parts[0])
+		}
+		c.methodLimits[parts[0]] = limit
+	}
+	if err := (&RPCRateLimit{Rate: c.SenderTxLimit, Burst: c.SenderTxBurst}).validate(); err != nil {
+		return fmt.Errorf("invalid rpc-policy sender tx limit: %w", err)
+	}
+	if c.MaxTrackedSenders <= 0 {
+		return errors.New("rpc-policy max-tracked-senders must be positive")
+	}
+	return nil
+}
+
+func (c *RPCPolicyConfig) limitsEqual(other *RPCPolicyConfig) bool {
+	return slices.Equal(c.MethodLimits, other.MethodLimits) &&
+		c.SenderTxLimit == other.SenderTxLimit &&
+		c.SenderTxBurst == other.SenderTxBurst &&
+		c.CallGasCap == other.CallGasCap
+}
+
+// RPCRateLimit is a token bucket refilled at Rate tokens per second holding up to Burst tokens.
+// A zero Rate is no limit.
+type RPCRateLimit struct {
+	Rate  float64 `json:"rate"`
+	Burst uint64  `json:"burst"`
+}
+
+func (l *RPCRateLimit) validate() error {
+	if math.IsNaN(l.Rate) || math.IsInf(l.Rate, 0) || l.Rate < 0 {
+		return fmt.Errorf("rate %v must be a non-negative number", l.Rate)
+	}
+	return nil
+}
+
+func (l RPCRateLimit) withDefaultBurst() RPCRateLimit {
+	if l.Burst == 0 && l.Rate > 0 {
+		l.Burst = uint64(math.Max(1, math.Ceil(l.Rate)))
+	}
+	return l
+}
+
+type rpcTokenBucket struct {
+	limit  RPCRateLimit
+	tokens float64
+	last   time.Time
+}
+
+func newRPCTokenBucket(limit RPCRateLimit, now time.Time) *rpcTokenBucket {
+	limit = limit.withDefaultBurst()
+	return &rpcTokenBucket{limit: limit, tokens: float64(limit.Burst), last: now}
+}
+
+func (b *rpcTokenBucket) allow(now time.Time) bool {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = math.Min(float64(b.limit.Burst), b.tokens+elapsed.Seconds()*b.limit.Rate)
+		b.last = now
+	}
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// RPCPolicy throttles JSON-RPC requests by method and by transaction sender, and caps the gas of
+// eth_call requests. It starts out from the node's config and may be changed at runtime through
+// the rpcpolicy API; such changes aren't persisted.
+type RPCPolicy struct {
+	mutex         sync.Mutex
+	methods       map[string]*rpcTokenBucket
+	senderLimit   RPCRateLimit
+	senders       *containers.LruCache[common.Address, *rpcTokenBucket]
+	callGasCap    atomic.Uint64
+	senderLimited atomic.Bool
+}
+
+// NewRPCPolicy creates a policy from a validated config.
+func NewRPCPolicy(config *RPCPolicyConfig) *RPCPolicy {
+	now := time.Now()
+	p := &RPCPolicy{
+		methods:     make(map[string]*rpcTokenBucket),
+		senderLimit: RPCRateLimit{Rate: config.SenderTxLimit, Burst: config.SenderTxBurst}.withDefaultBurst(),
+		senders:     containers.NewLruCache[common.Address, *rpcTokenBucket](config.MaxTrackedSenders),
+	}
+	for method, limit := range config.methodLimits {
+		if limit.Rate > 0 {
+			p.methods[method] = newRPCTokenBucket(limit, now)
+		}
+	}
+	p.senderLimited.Store(p.senderLimit.Rate > 0)
+	p.callGasCap.Store(config.CallGasCap)
+	return p
+}
+
+// Reconfigure replaces all limits with those of a validated config, such as a reloaded one,
+// dropping any changes made through the rpcpolicy API.
+func (p *RPCPolicy) Reconfigure(config *RPCPolicyConfig) {
+	now := time.Now()
+	methods := make(map[string]*rpcTokenBucket)
+	for method, limit := range config.methodLimits {
+		if limit.Rate > 0 {
+			methods[method] = newRPCTokenBucket(limit, now)
+		}
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.methods = methods
+	p.senderLimit = RPCRateLimit{Rate: config.SenderTxLimit, Burst: config.SenderTxBurst}.withDefaultBurst()
+	p.senders.Clear()
+	p.senderLimited.Store(p.senderLimit.Rate > 0)
+	p.callGasCap.Store(config.CallGasCap)
+}
+
+func (p *RPCPolicy) SetMethodLimit(method string, limit RPCRateLimit) error {
+	if err := limit.validate(); err != nil {
+		return err
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if limit.Rate == 0 {
+		delete(p.methods, method)
+	} else {
+		p.methods[method] = newRPCTokenBucket(limit, time.Now())
+	}
+	return nil
+}
+
+func (p *RPCPolicy) SetSenderTxLimit(limit RPCRateLimit) error {
+	if err := limit.validate(); err != nil {
+		return err
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.senderLimit = limit.withDefaultBurst()
+	p.senders.Clear()
+	p.senderLimited.Store(limit.Rate > 0)
+	return nil
+}
+
+func (p *RPCPolicy) SetCallGasCap(gasCap uint64) {
+	p.callGasCap.Store(gasCap)
+}
+
+type RPCPolicyStatus struct {
+	MethodLimits   map[string]RPCRateLimit `json:"methodLimits"`
+	SenderTxLimit  RPCRateLimit            `json:"senderTxLimit"`
+	TrackedSenders int                     `json:"trackedSenders"`
+	CallGasCap     hexutil.Uint64          `json:"callGasCap"`
+}
+
+func (p *RPCPolicy) Status() *RPCPolicyStatus {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	status := &RPCPolicyStatus{
+		MethodLimits:   make(map[string]RPCRateLimit, len(p.methods)),
+		SenderTxLimit:  p.senderLimit,
+		TrackedSenders: p.senders.Len(),
+		CallGasCap:     hexutil.Uint64(p.callGasCap.Load()),
+	}
+	for method, bucket := range p.methods {
+		status.MethodLimits[method] = bucket.limit
+	}
+	return status
+}
+
+type rpcPolicyMessage struct {
+	Version string          `json:"jsonrpc,omitempty"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type rpcPolicyError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type rpcPolicyResponse struct {
+	Version string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Error   rpcPolicyError  `json:"error"`
+}
+
+// rawTransactionSender recovers the sender of the transaction given as the first parameter of
+// eth_sendRawTransaction or eth_sendRawTransactionConditional.
+func rawTransactionSender(params json.RawMessage) (common.Address, error) {
+	var args []json.RawMessage
+	if err := json.Unmarshal(params, &args); err != nil {
+		return common.Address{}, err
+	}
+	if len(args) == 0 {
+		return common.Address{}, errors.New("missing raw transaction")
+	}
+	var raw hexutil.Bytes
+	if err := json.Unmarshal(args[0], &raw); err != nil {
+		return common.Address{}, err
+	}
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(raw); err != nil {
+		return common.Address{}, err
+	}
+	return types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+}
+
+// capCallGas rewrites the call object of an eth_call to use at most gasCap gas, returning whether
+// the parameters changed. Malformed parameters are left for the RPC server to reject.
+func capCallGas(msg *rpcPolicyMessage, gasCap uint64) bool {
+	var args []json.RawMessage
+	if err := json.Unmarshal(msg.Params, &args); err != nil || len(args) == 0 {
+		return false
+	}
+	var call map[string]json.RawMessage
+	if err := json.Unmarshal(args[0], &call); err != nil || call == nil {
+		return false
+	}
+	if rawGas, ok := call["gas"]; ok && string(rawGas) != "null" {
+		var gas hexutil.Uint64
+		if err := json.Unmarshal(rawGas, &gas); err != nil || uint64(gas) <= gasCap {
+			return false
+		}
+	}
+	call["gas"] = json.RawMessage(strconv.Quote(hexutil.EncodeUint64(gasCap)))
+	capped, err := json.Marshal(call)
+	if err != nil {
+		return false
+	}
+	args[0] = capped
+	params, err := json.Marshal(args)
+	if err != nil {
+		return false
+	}
+	msg.Params = params
+	rpcPolicyCappedCallGasCounter.Inc(1)
+	return true
+}
+
+// check applies the policy to a single request, returning whether the request was rewritten or
+// an error if it must be rejected.
+func (p *RPCPolicy) check(msg *rpcPolicyMessage, now time.Time) (bool, error) {
+	var sender common.Address
+	haveSender := false
+	if (msg.Method == "eth_sendRawTransaction" || msg.Method == "eth_sendRawTransactionConditional") && p.senderLimited.Load() {
+		// recover the sender before taking the lock, transactions that can't be decoded are rejected by the RPC server
+		var err error
+		sender, err = rawTransactionSender(msg.Params)
+		haveSender = err == nil
+	}
+
+	p.mutex.Lock()
+	if bucket := p.methods[msg.Method]; bucket != nil && !bucket.allow(now) {
+		p.mutex.Unlock()
+		rpcPolicyRejectedMethodCounter.Inc(1)
+		return false, fmt.Errorf("rate limit of %v %s requests per second exceeded", bucket.limit.Rate, msg.Method)
+	}
+	if haveSender && p.senderLimit.Rate > 0 {
+		bucket, ok := p.senders.Get(sender)
+		if !ok {
+			bucket = newRPCTokenBucket(p.senderLimit, now)
+			p.senders.Add(sender, bucket)
+		}
+		if !bucket.allow(now) {
+			limit := p.senderLimit
+			p.mutex.Unlock()
+			rpcPolicyRejectedSenderCounter.Inc(1)
+			return false, fmt.Errorf("rate limit of %v transactions per second exceeded for sender %v", limit.Rate, sender)
+		}
+	}
+	p.mutex.Unlock()
+
+	if msg.Method == "eth_call" {
+		if gasCap := p.callGasCap.Load(); gasCap > 0 {
+			return capCallGas(msg, gasCap), nil
+		}
+	}
+	return false, nil
+}
+
+// apply checks each request in a JSON-RPC body. It returns the body to pass on to the RPC server,
+// empty if no request is left, and the responses for rejected requests.
+func (p *RPCPolicy) apply(body []byte, now time.Time) (forward []byte, rejected []*rpcPolicyResponse, isBatch bool) {
+	trimmed := bytes.TrimLeft(body, " \t\r\n")
+	isBatch = len(trimmed) > 0 && trimmed[0] == '['
+	var raws []json.RawMessage
+	if isBatch {
+		if err := json.Unmarshal(trimmed, &raws); err != nil {
+			return body, nil, isBatch
+		}
+	} else {
+		raws = []json.RawMessage{trimmed}
+	}
+	changed := false
+	kept := make([]json.RawMessage, 0, len(raws))
+	for _, raw := range raws {
+		var msg rpcPolicyMessage
+		if err := json.Unmarshal(raw, &msg); err != nil {
+			kept = append(kept, raw)
+			continue
+		}
+		rewritten, err := p.check(&msg, now)
+		if err != nil {
+			changed = true
+			if len(msg.ID) > 0 {
+				rejected = append(rejected, &rpcPolicyResponse{
+					Version: "2.0",
+					ID:      msg.ID,
+					Error:   rpcPolicyError{Code: rpcPolicyLimitExceededCode, Message: err.Error()},
+				})
+			}
+			continue
+		}
+		if rewritten {
+			if encoded, err := json.Marshal(&msg); err == nil {
+				changed = true
+				raw = encoded
+			}
+		}
+		kept = append(kept, raw)
+	}
+	if !changed {
+		return body, nil, isBatch
+	}
+	if len(kept) == 0 {
+		return nil, rejected, isBatch
+	}
+	if !isBatch {
+		return kept[0], rejected, isBatch
+	}
+	forward, err := json.Marshal(kept)
+	if err != nil {
+		return body, nil, isBatch
+	}
+	return forward, rejected, isBatch
+}
+
+// bufferedResponseWriter holds a response so rejections can be merged into a batch response.
+type bufferedResponseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferedResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *bufferedResponseWriter) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+func (w *bufferedResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+type rpcPolicyHTTPHandler struct {
+	inner  http.Handler
+	policy *RPCPolicy
+}
+
+func writeRPCPolicyResponse(w http.ResponseWriter, response any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// rejectionResponse encodes the responses to the rejected requests of a body, as an array if the
+// body was a batch.
+func rejectionResponse(rejected []*rpcPolicyResponse, isBatch bool) ([]byte, error) {
+	if isBatch {
+		return json.Marshal(rejected)
+	}
+	return json.Marshal(rejected[0])
+}
+
+func (h *rpcPolicyHTTPHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if websocket.IsWebSocketUpgrade(req) {
+		h.serveWebsocket(w, req)
+		return
+	}
+	if req.Method != http.MethodPost {
+		h.inner.ServeHTTP(w, req)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(req.Body, rpcPolicyMaxBodySize+1))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(body) > rpcPolicyMaxBodySize {
+		req.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), req.Body), req.Body}
+		h.inner.ServeHTTP(w, req)
+		return
+	}
+	forward, rejected, isBatch := h.policy.apply(body, time.Now())
+	if len(forward) == 0 {
+		if len(rejected) > 0 && isBatch {
+			writeRPCPolicyResponse(w, rejected)
+		} else if len(rejected) > 0 {
+			writeRPCPolicyResponse(w, rejected[0])
+		}
+		return
+	}
+	req.Body = io.NopCloser(bytes.NewReader(forward))
+	req.ContentLength = int64(len(forward))
+	if len(rejected) == 0 {
+		h.inner.ServeHTTP(w, req)
+		return
+	}
+
+	buffered := &bufferedResponseWriter{header: w.Header()}
+	h.inner.ServeHTTP(buffered, req)
+	var responses []json.RawMessage
+	if (buffered.status != 0 && buffered.status != http.StatusOK) || json.Unmarshal(buffered.body.Bytes(), &responses) != nil {
+		// not a batch response, pass on whatever error the RPC server returned
+		if buffered.status != 0 {
+			w.WriteHeader(buffered.status)
+		}
+		_, _ = w.Write(buffered.body.Bytes())
+		return
+	}
+	for _, response := range rejected {
+		encoded, err := json.Marshal(response)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		responses = append(responses, encoded)
+	}
+	w.Header().Del("Content-Length")
+	writeRPCPolicyResponse(w, responses)
+}
+
+var activeRPCPolicy atomic.Pointer[RPCPolicy]
+
+// InitRPCPolicy adds the RPC policy to geth's stack of http.Handlers, on top of any handler
+// wrapper already installed, which subjects HTTP and WebSocket requests to it. geth has no such
+// hook for IPC, so the stack's IPC endpoint is taken over and returned, to be served with
+// RegisterRPCPolicyIPC once the stack is set up.
+//
+// Must be run before the go-ethereum stack is set up (ethereum/go-ethereum/node.New).
+func InitRPCPolicy(config *RPCPolicyConfig, stackConf *node.Config) string {
+	if !config.Enable {
+		return ""
+	}
+	policy := NewRPCPolicy(config)
+	activeRPCPolicy.Store(policy)
+	ipcEndpoint := stackConf.IPCEndpoint()
+	stackConf.IPCPath = ""
+	previousWrapper := node.WrapHTTPHandler
+	node.WrapHTTPHandler = func(srv http.Handler) (http.Handler, error) {
+		if previousWrapper != nil {
+			var err error
+			srv, err = previousWrapper(srv)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return &rpcPolicyHTTPHandler{inner: srv, policy: policy}, nil
+	}
+	return ipcEndpoint
+}
+
+type RPCPolicyAPI struct {
+	policy *RPCPolicy
+}
+
+func NewRPCPolicyAPI(policy *RPCPolicy) *RPCPolicyAPI {
+	return &RPCPolicyAPI{policy}
+}
+
+func (a *RPCPolicyAPI) Status(ctx context.Context) *RPCPolicyStatus {
+	return a.policy.Status()
+}
+
+// SetMethodLimit sets the requests per second allowed for a method, a zero rate removes its limit.
+func (a *RPCPolicyAPI) SetMethodLimit(ctx context.Context, method string, rate float64, burst hexutil.Uint64) error {
+	return a.policy.SetMethodLimit(method, RPCRateLimit{Rate: rate, Burst: uint64(burst)})
+}
+
+// SetSenderTxLimit sets the transactions per second allowed for each sender, a zero rate removes the limit.
+func (a *RPCPolicyAPI) SetSenderTxLimit(ctx context.Context, rate float64, burst hexutil.Uint64) error {
+	return a.policy.SetSenderTxLimit(RPCRateLimit{Rate: rate, Burst: uint64(burst)})
+}
+
+// SetCallGasCap sets the gas eth_call requests are capped to, zero removes the cap.
+func (a *RPCPolicyAPI) SetCallGasCap(ctx context.Context, gasCap hexutil.Uint64) {
+	a.policy.SetCallGasCap(uint64(gasCap))
+}
@@ -0,0 +1,381 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package gethexec
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// same as geth's websocket read limit
+	rpcPolicyMaxWebsocketMessageSize = 32 * 1024 * 1024
+	rpcPolicyUpstreamTimeout         = 10 * time.Second
+)
+
+// rpcPolicyConn is one side of a JSON-RPC connection that carries a message at a time, such as a
+// WebSocket or IPC connection. Writes may be made concurrently with reads and other writes.
+type rpcPolicyConn interface {
+	readMessage() ([]byte, error)
+	writeMessage(data []byte) error
+	Close() error
+}
+
+// rpcPolicyPendingBatches holds the rejections of batches that were partly passed on to the RPC
+// server, keyed by the id of the first request passed on, so they can be merged into its response.
+type rpcPolicyPendingBatches struct {
+	mutex   sync.Mutex
+	batches map[string][]*rpcPolicyResponse
+}
+
+func (b *rpcPolicyPendingBatches) add(id json.RawMessage, rejected []*rpcPolicyResponse) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.batches[string(id)] = rejected
+}
+
+// merge adds the rejections held for a batch to the server's response to it, returning any other
+// response unchanged.
+func (b *rpcPolicyPendingBatches) merge(response []byte) []byte {
+	var responses []json.RawMessage
+	if err := json.Unmarshal(response, &responses); err != nil || len(responses) == 0 {
+		return response
+	}
+	var first struct {
+		ID json.RawMessage `json:"id"`
+	}
+	if err := json.Unmarshal(responses[0], &first); err != nil {
+		return response
+	}
+	b.mutex.Lock()
+	rejected, ok := b.batches[string(first.ID)]
+	delete(b.batches, string(first.ID))
+	b.mutex.Unlock()
+	if !ok {
+		return response
+	}
+	for _, r := range rejected {
+		encoded, err := json.Marshal(r)
+		if err != nil {
+			return response
+		}
+		responses = append(responses, encoded)
+	}
+	merged, err := json.Marshal(responses)
+	if err != nil {
+		return response
+	}
+	return merged
+}
+
+// firstRequestID returns the id of the first request in a batch that isn't a notification.
+func firstRequestID(batch []byte) json.RawMessage {
+	var msgs []rpcPolicyMessage
+	if err := json.Unmarshal(batch, &msgs); err != nil {
+		return nil
+	}
+	for _, msg := range msgs {
+		if len(msg.ID) > 0 && string(msg.ID) != "null" {
+			return msg.ID
+		}
+	}
+	return nil
+}
+
+// relay passes messages between a client and the RPC server until either side closes, applying
+// the policy to the client's requests. Rejections are merged into the responses to their batches
+// like they are for HTTP.
+func (p *RPCPolicy) relay(client, server rpcPolicyConn) {
+	pending := &rpcPolicyPendingBatches{batches: make(map[string][]*rpcPolicyResponse)}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer client.Close()
+		for {
+			response, err := server.readMessage()
+			if err != nil {
+				return
+			}
+			if err := client.writeMessage(pending.merge(response)); err != nil {
+				return
+			}
+		}
+	}()
+	defer func() {
+		server.Close()
+		<-done
+	}()
+	for {
+		body, err := client.readMessage()
+		if err != nil {
+			return
+		}
+		forward, rejected, isBatch := p.apply(body, time.Now())
+		if len(forward) > 0 && len(rejected) > 0 && isBatch {
+			if id := firstRequestID(forward); id != nil {
+				pending.add(id, rejected)
+				rejected = nil
+			}
+		}
+		if len(forward) > 0 {
+			if err := server.writeMessage(forward); err != nil {
+				return
+			}
+		}
+		if len(rejected) > 0 {
+			response, err := rejectionResponse(rejected, isBatch)
+			if err != nil {
+				return
+			}
+			if err := client.writeMessage(response); err != nil {
+				return
+			}
+		}
+	}
+}
+
+type rpcPolicyWebsocketConn struct {
+	conn  *websocket.Conn
+	mutex sync.Mutex
+}
+
+func (c *rpcPolicyWebsocketConn) readMessage() ([]byte, error) {
+	_, data, err := c.conn.ReadMessage()
+	return data, err
+}
+
+func (c *rpcPolicyWebsocketConn) writeMessage(data []byte) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.conn.WriteMessage(websocket.TextMessage, data)
+}
+
+func (c *rpcPolicyWebsocketConn) Close() error {
+	return c.conn.Close()
+}
+
+// rpcPolicyPipeListener hands in-memory connections to an http.Server, so the RPC server's own
+// WebSocket handler can be dialed without a network listener.
+type rpcPolicyPipeListener struct {
+	conns     chan net.Conn
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func newRPCPolicyPipeListener() *rpcPolicyPipeListener {
+	return &rpcPolicyPipeListener{
+		conns:  make(chan net.Conn),
+		closed: make(chan struct{}),
+	}
+}
+
+func (l *rpcPolicyPipeListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.closed:
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *rpcPolicyPipeListener) Close() error {
+	l.closeOnce.Do(func() { close(l.closed) })
+	return nil
+}
+
+func (l *rpcPolicyPipeListener) Addr() net.Addr {
+	return &net.UnixAddr{Name: "rpc-policy", Net: "pipe"}
+}
+
+func (l *rpcPolicyPipeListener) dial(ctx context.Context, _, _ string) (net.Conn, error) {
+	clientConn, serverConn := net.Pipe()
+	select {
+	case l.conns <- serverConn:
+		return clientConn, nil
+	case <-l.closed:
+		return nil, net.ErrClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// headers of the client's WebSocket handshake that the dialer sets itself
+var rpcPolicyHandshakeHeaders = []string{
+	"Upgrade",
+	"Connection",
+	"Sec-Websocket-Key",
+	"Sec-Websocket-Version",
+	"Sec-Websocket-Extensions",
+	"Sec-Websocket-Protocol",
+}
+
+// serveWebsocket relays a WebSocket connection to the RPC server's WebSocket handler, applying the
+// policy to each message. The RPC server checks the handshake, such as its origin and JWT, so the
+// client's connection is only accepted once the server accepted the relayed one.
+func (h *rpcPolicyHTTPHandler) serveWebsocket(w http.ResponseWriter, req *http.Request) {
+	listener := newRPCPolicyPipeListener()
+	server := &http.Server{
+		Handler:           h.inner,
+		ReadHeaderTimeout: rpcPolicyUpstreamTimeout,
+	}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, net.ErrClosed) && !errors.Is(err, http.ErrServerClosed) {
+			log.Warn("rpc policy websocket relay stopped", "err", err)
+		}
+	}()
+	// hijacked connections aren't closed with the server, only the relay closes them
+	defer server.Close()
+
+	header := req.Header.Clone()
+	for _, name := range rpcPolicyHandshakeHeaders {
+		header.Del(name)
+	}
+	dialer := websocket.Dialer{
+		NetDialContext:   listener.dial,
+		HandshakeTimeout: rpcPolicyUpstreamTimeout,
+	}
+	url := "ws://" + req.Host + req.URL.RequestURI()
+	upstream, response, err := dialer.DialContext(req.Context(), url, header)
+	if err != nil {
+		if response != nil {
+			http.Error(w, http.StatusText(response.StatusCode), response.StatusCode)
+		} else {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+		}
+		return
+	}
+	upgrader := websocket.Upgrader{
+		// the RPC server already checked the origin of the relayed handshake
+		CheckOrigin: func(*http.Request) bool { return true },
+	}
+	client, err := upgrader.Upgrade(w, req, nil)
+	if err != nil {
+		// the upgrader already responded with the error
+		upstream.Close()
+		return
+	}
+	client.SetReadLimit(rpcPolicyMaxWebsocketMessageSize)
+	h.policy.relay(&rpcPolicyWebsocketConn{conn: client}, &rpcPolicyWebsocketConn{conn: upstream})
+}
+
+type rpcPolicyStreamConn struct {
+	conn    net.Conn
+	decoder *json.Decoder
+	mutex   sync.Mutex
+}
+
+func newRPCPolicyStreamConn(conn net.Conn) *rpcPolicyStreamConn {
+	return &rpcPolicyStreamConn{conn: conn, decoder: json.NewDecoder(conn)}
+}
+
+func (c *rpcPolicyStreamConn) readMessage() ([]byte, error) {
+	var msg json.RawMessage
+	err := c.decoder.Decode(&msg)
+	return msg, err
+}
+
+func (c *rpcPolicyStreamConn) writeMessage(data []byte) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	_, err := c.conn.Write(append(data, '\n'))
+	return err
+}
+
+func (c *rpcPolicyStreamConn) Close() error {
+	return c.conn.Close()
+}
+
+// serveIPCConn serves a client's IPC connection with the stack's in-process RPC server, which
+// offers the same APIs as geth's IPC endpoint, applying the policy to each message.
+func (p *RPCPolicy) serveIPCConn(conn net.Conn, handler *rpc.Server) {
+	clientConn, serverConn := net.Pipe()
+	go handler.ServeCodec(rpc.NewCodec(serverConn), 0)
+	p.relay(newRPCPolicyStreamConn(conn), newRPCPolicyStreamConn(clientConn))
+}
+
+// rpcPolicyIPCServer serves the IPC endpoint InitRPCPolicy took over from the stack.
+type rpcPolicyIPCServer struct {
+	stack    *node.Node
+	policy   *RPCPolicy
+	endpoint string
+	listener net.Listener
+	wg       sync.WaitGroup
+}
+
+// RegisterRPCPolicyIPC serves the IPC endpoint returned by InitRPCPolicy with the stack, subject to
+// the policy, while the stack is running.
+func RegisterRPCPolicyIPC(stack *node.Node, endpoint string) {
+	policy := activeRPCPolicy.Load()
+	if endpoint == "" || policy == nil {
+		return
+	}
+	stack.RegisterLifecycle(&rpcPolicyIPCServer{
+		stack:    stack,
+		policy:   policy,
+		endpoint: endpoint,
+	})
+}
+
+func (s *rpcPolicyIPCServer) Start() error {
+	handler, err := s.stack.RPCHandler()
+	if err != nil {
+		return err
+	}
+	// like geth's IPC endpoint, replace any stale socket and only allow the node's user to connect
+	if err := os.MkdirAll(filepath.Dir(s.endpoint), 0o751); err != nil {
+		return err
+	}
+	if err := os.Remove(s.endpoint); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	listener, err := net.Listen("unix", s.endpoint)
+	if err != nil {
+		return err
+	}
+	if err := os.Chmod(s.endpoint, 0o600); err != nil {
+		listener.Close()
+		return err
+	}
+	s.listener = listener
+	log.Info("IPC endpoint opened", "url", s.endpoint, "rpcPolicy", true)
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				if !errors.Is(err, net.ErrClosed) {
+					log.Error("IPC accept failed", "err", err)
+				}
+				return
+			}
+			// the stack stops the in-process RPC server when it closes, which ends the relay
+			go s.policy.serveIPCConn(conn, handler)
+		}
+	}()
+	return nil
+}
+
+func (s *rpcPolicyIPCServer) Stop() error {
+	if s.listener == nil {
+		return nil
+	}
+	err := s.listener.Close()
+	s.wg.Wait()
+	log.Info("IPC endpoint closed", "url", s.endpoint)
+	return err
+}
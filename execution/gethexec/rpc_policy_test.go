@@ -0,0 +1,244 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package gethexec
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+func TestRPCPolicyHTTPHandler(t *testing.T) {
+	config := DefaultRPCPolicyConfig
+	config.Enable = true
+	config.MethodLimits = []string{"eth_blockNumber:0.001:2"}
+	config.SenderTxLimit = 0.001
+	config.CallGasCap = 1_000_000
+	if err := config.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	policy := NewRPCPolicy(&config)
+
+	var lastBody string
+	inner := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lastBody = string(body)
+		if strings.HasPrefix(lastBody, "[") {
+			_, _ = w.Write([]byte(`[{"jsonrpc":"2.0","id":1,"result":"0x1"}]`))
+		} else {
+			_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x1"}`))
+		}
+	})
+	handler := &rpcPolicyHTTPHandler{inner: inner, policy: policy}
+	serve := func(body string) string {
+		recorder := httptest.NewRecorder()
+		lastBody = ""
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+		return recorder.Body.String()
+	}
+	const blockNumber = `{"jsonrpc":"2.0","id":1,"method":"eth_blockNumber"}`
+
+	for i := 0; i < 2; i++ {
+		if res := serve(blockNumber); !strings.Contains(res, `"result"`) || lastBody != blockNumber {
+			t.Fatal("expected request within the burst to be served, got", res)
+		}
+	}
+	if res := serve(blockNumber); !strings.Contains(res, fmt.Sprint(rpcPolicyLimitExceededCode)) || lastBody != "" {
+		t.Fatal("expected request over the limit to be rejected, got", res)
+	}
+
+	// rejections are merged into the response to the rest of the batch
+	res := serve(`[` + blockNumber + `,{"jsonrpc":"2.0","id":2,"method":"eth_chainId"}]`)
+	var responses []map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(res), &responses); err != nil {
+		t.Fatal(err, res)
+	}
+	if len(responses) != 2 || strings.Contains(lastBody, "eth_blockNumber") || !strings.Contains(lastBody, "eth_chainId") {
+		t.Fatal("unexpected batch handling, forwarded", lastBody, "responded", res)
+	}
+
+	// a method limit can be lifted at runtime
+	if err := NewRPCPolicyAPI(policy).SetMethodLimit(context.Background(), "eth_blockNumber", 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	if res := serve(blockNumber); !strings.Contains(res, `"result"`) {
+		t.Fatal("expected request to be served after the limit was removed, got", res)
+	}
+
+	// transactions are throttled per sender
+	signer := types.LatestSignerForChainID(big.NewInt(412346))
+	newKey := func() *ecdsa.PrivateKey {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return key
+	}
+	sendTx := func(key *ecdsa.PrivateKey, nonce uint64) string {
+		tx, err := types.SignNewTx(key, signer, &types.DynamicFeeTx{Nonce: nonce, Gas: 21000})
+		if err != nil {
+			t.Fatal(err)
+		}
+		raw, err := tx.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return serve(fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"eth_sendRawTransaction","params":["%v"]}`, hexutil.Encode(raw)))
+	}
+	sender := newKey()
+	if res := sendTx(sender, 0); !strings.Contains(res, `"result"`) {
+		t.Fatal("expected first transaction to be accepted, got", res)
+	}
+	if res := sendTx(sender, 1); !strings.Contains(res, "exceeded for sender") {
+		t.Fatal("expected second transaction from the sender to be rejected, got", res)
+	}
+	if res := sendTx(newKey(), 0); !strings.Contains(res, `"result"`) {
+		t.Fatal("expected a transaction from another sender to be accepted, got", res)
+	}
+
+	// eth_call gas is capped, including when it isn't given
+	serve(`{"jsonrpc":"2.0","id":1,"method":"eth_call","params":[{"to":"0x0000000000000000000000000000000000000064","gas":"0xffffffff"},"latest"]}`)
+	if !strings.Contains(lastBody, `"gas":"0xf4240"`) || !strings.Contains(lastBody, `"latest"`) {
+		t.Fatal("expected eth_call gas to be capped, forwarded", lastBody)
+	}
+	serve(`{"jsonrpc":"2.0","id":1,"method":"eth_call","params":[{"to":"0x0000000000000000000000000000000000000064"}]}`)
+	if !strings.Contains(lastBody, `"gas":"0xf4240"`) {
+		t.Fatal("expected eth_call without gas to be capped, forwarded", lastBody)
+	}
+	const lowGasCall = `{"jsonrpc":"2.0","id":1,"method":"eth_call","params":[{"gas":"0x5208"}]}`
+	serve(lowGasCall)
+	if lastBody != lowGasCall {
+		t.Fatal("expected eth_call below the cap to be forwarded unchanged, forwarded", lastBody)
+	}
+}
+
+func TestRPCPolicyConfigValidate(t *testing.T) {
+	for _, limits := range [][]string{{"eth_call"}, {"eth_call:x"}, {":1"}, {"eth_call:-1"}, {"eth_call:1", "eth_call:2"}} {
+		config := DefaultRPCPolicyConfig
+		config.Enable = true
+		config.MethodLimits = limits
+		if err := config.Validate(); err == nil {
+			t.Fatal("accepted invalid method limits", limits)
+		}
+	}
+}
+
+type rpcPolicyTestService struct{}
+
+func (s *rpcPolicyTestService) BlockNumber() hexutil.Uint64 {
+	return 1
+}
+
+func (s *rpcPolicyTestService) ChainId() hexutil.Uint64 {
+	return 2
+}
+
+// testRPCPolicyStream checks the policy is applied to a connection carrying a message at a time.
+func testRPCPolicyStream(t *testing.T, send func(string), receive func() string) {
+	t.Helper()
+	const blockNumber = `{"jsonrpc":"2.0","id":1,"method":"eth_blockNumber"}`
+	for i := 0; i < 2; i++ {
+		send(blockNumber)
+		if res := receive(); !strings.Contains(res, `"result":"0x1"`) {
+			t.Fatal("expected request within the burst to be served, got", res)
+		}
+	}
+	send(blockNumber)
+	if res := receive(); !strings.Contains(res, fmt.Sprint(rpcPolicyLimitExceededCode)) {
+		t.Fatal("expected request over the limit to be rejected, got", res)
+	}
+
+	// rejections are merged into the response to the rest of the batch
+	send(`[` + blockNumber + `,{"jsonrpc":"2.0","id":2,"method":"eth_chainId"}]`)
+	res := receive()
+	var responses []map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(res), &responses); err != nil {
+		t.Fatal(err, res)
+	}
+	if len(responses) != 2 || !strings.Contains(res, `"result":"0x2"`) || !strings.Contains(res, fmt.Sprint(rpcPolicyLimitExceededCode)) {
+		t.Fatal("unexpected batch response", res)
+	}
+}
+
+func newRPCPolicyTestServer(t *testing.T) (*RPCPolicy, *rpc.Server) {
+	t.Helper()
+	config := DefaultRPCPolicyConfig
+	config.Enable = true
+	config.MethodLimits = []string{"eth_blockNumber:0.001:2"}
+	if err := config.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	server := rpc.NewServer()
+	if err := server.RegisterName("eth", &rpcPolicyTestService{}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(server.Stop)
+	return NewRPCPolicy(&config), server
+}
+
+func TestRPCPolicyWebsocket(t *testing.T) {
+	policy, server := newRPCPolicyTestServer(t)
+	handler := &rpcPolicyHTTPHandler{inner: server.WebsocketHandler([]string{"*"}), policy: policy}
+	httpServer := httptest.NewServer(handler)
+	defer httpServer.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(httpServer.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	send := func(msg string) {
+		if err := conn.WriteMessage(websocket.TextMessage, []byte(msg)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	receive := func() string {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+	testRPCPolicyStream(t, send, receive)
+}
+
+func TestRPCPolicyIPC(t *testing.T) {
+	policy, server := newRPCPolicyTestServer(t)
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+	go policy.serveIPCConn(serverConn, server)
+
+	decoder := json.NewDecoder(clientConn)
+	send := func(msg string) {
+		if _, err := clientConn.Write([]byte(msg + "\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	receive := func() string {
+		var msg json.RawMessage
+		if err := decoder.Decode(&msg); err != nil {
+			t.Fatal(err)
+		}
+		return string(msg)
+	}
+	testRPCPolicyStream(t, send, receive)
+}
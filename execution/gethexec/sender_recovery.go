@@ -0,0 +1,171 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package gethexec
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"runtime"
+	"sync"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/offchainlabs/nitro/arbos"
+	"github.com/offchainlabs/nitro/arbos/arbostypes"
+	"github.com/offchainlabs/nitro/util/containers"
+)
+
+var (
+	senderRecoveryQueuedCounter  = metrics.NewRegisteredCounter("arb/sender_recovery/queued", nil)
+	senderRecoveryDroppedCounter = metrics.NewRegisteredCounter("arb/sender_recovery/dropped", nil)
+	senderRecoveryHitCounter     = metrics.NewRegisteredCounter("arb/sender_recovery/hit", nil)
+	senderRecoveryMissCounter    = metrics.NewRegisteredCounter("arb/sender_recovery/miss", nil)
+)
+
+type SenderRecoveryConfig struct {
+	Enable    bool `koanf:"enable"`
+	Workers   int  `koanf:"workers"`
+	QueueSize int  `koanf:"queue-size"`
+	CacheSize int  `koanf:"cache-size"`
+}
+
+var DefaultSenderRecoveryConfig = SenderRecoveryConfig{
+	Enable:    true,
+	Workers:   0,
+	QueueSize: 4096,
+	CacheSize: 16384,
+}
+
+func SenderRecoveryConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.Bool(prefix+".enable", DefaultSenderRecoveryConfig.Enable, "recover transaction senders of upcoming messages in parallel ahead of their execution")
+	f.Int(prefix+".workers", DefaultSenderRecoveryConfig.Workers, "number of sender recovery workers (0 = number of CPUs)")
+	f.Int(prefix+".queue-size", DefaultSenderRecoveryConfig.QueueSize, "maximum number of transactions waiting for sender recovery; further transactions are recovered during execution")
+	f.Int(prefix+".cache-size", DefaultSenderRecoveryConfig.CacheSize, "number of transactions with recovered senders kept for reuse by block production and validation")
+}
+
+func (c *SenderRecoveryConfig) Validate() error {
+	if c.Workers < 0 {
+		return errors.New("sender recovery workers must not be negative")
+	}
+	if c.QueueSize <= 0 {
+		return errors.New("sender recovery queue size must be positive")
+	}
+	return nil
+}
+
+type senderRecoveryJob struct {
+	tx     *types.Transaction
+	signer types.Signer
+}
+
+// SenderRecoverer recovers the senders of transactions in messages that are about to be executed,
+// spreading the signature recovery across workers. Transactions are cached by hash with their
+// sender already recovered, so block production and recording can swap in the cached copy of a
+// freshly parsed transaction and skip recovery. types.Sender only reuses the cached sender if it
+// was recovered with an equal signer, so a mispredicted signer falls back to regular recovery.
+type SenderRecoverer struct {
+	config      *SenderRecoveryConfig
+	chainConfig *params.ChainConfig
+	jobs        chan senderRecoveryJob
+
+	cacheMutex sync.Mutex
+	cache      *containers.LruCache[common.Hash, *types.Transaction]
+}
+
+func NewSenderRecoverer(config *SenderRecoveryConfig, chainConfig *params.ChainConfig) *SenderRecoverer {
+	return &SenderRecoverer{
+		config:      config,
+		chainConfig: chainConfig,
+		jobs:        make(chan senderRecoveryJob, config.QueueSize),
+		cache:       containers.NewLruCache[common.Hash, *types.Transaction](config.CacheSize),
+	}
+}
+
+func (r *SenderRecoverer) numWorkers() int {
+	if r.config.Workers > 0 {
+		return r.config.Workers
+	}
+	return runtime.NumCPU()
+}
+
+func (r *SenderRecoverer) worker(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case job := <-r.jobs:
+			if _, err := types.Sender(job.signer, job.tx); err != nil {
+				// leave it to block production to reject the transaction
+				continue
+			}
+			r.cacheMutex.Lock()
+			r.cache.Add(job.tx.Hash(), job.tx)
+			r.cacheMutex.Unlock()
+		}
+	}
+}
+
+func (r *SenderRecoverer) cached(hash common.Hash) (*types.Transaction, bool) {
+	r.cacheMutex.Lock()
+	defer r.cacheMutex.Unlock()
+	return r.cache.Get(hash)
+}
+
+// Prefetch queues the user transactions of msg, which will be executed as blockNumber, for sender
+// recovery. It never blocks; transactions that don't fit in the queue are recovered during execution.
+func (r *SenderRecoverer) Prefetch(blockNumber uint64, msg *arbostypes.MessageWithMetadata) {
+	if msg == nil || msg.Message == nil || msg.Message.Header == nil {
+		return
+	}
+	txes, err := arbos.ParseL2Transactions(msg.Message, r.chainConfig.ChainID)
+	if err != nil {
+		return
+	}
+	signer := types.MakeSigner(r.chainConfig, new(big.Int).SetUint64(blockNumber), msg.Message.Header.Timestamp)
+	for _, tx := range txes {
+		if tx.Type() >= types.ArbitrumDepositTxType {
+			// Arbitrum transaction types carry their sender and need no recovery
+			continue
+		}
+		if _, ok := r.cached(tx.Hash()); ok {
+			continue
+		}
+		select {
+		case r.jobs <- senderRecoveryJob{tx: tx, signer: signer}:
+			senderRecoveryQueuedCounter.Inc(1)
+		default:
+			senderRecoveryDroppedCounter.Inc(1)
+		}
+	}
+}
+
+// ParseL2Transactions parses the transactions of message, substituting any transactions whose
+// senders have already been recovered. Like arbos.ProduceBlock, a message that fails to parse is
+// treated as containing no transactions.
+func (r *SenderRecoverer) ParseL2Transactions(message *arbostypes.L1IncomingMessage) types.Transactions {
+	txes, err := arbos.ParseL2Transactions(message, r.chainConfig.ChainID)
+	if err != nil {
+		log.Warn("error parsing incoming message", "err", err)
+		return types.Transactions{}
+	}
+	for i, tx := range txes {
+		if tx.Type() >= types.ArbitrumDepositTxType {
+			continue
+		}
+		if cachedTx, ok := r.cached(tx.Hash()); ok {
+			txes[i] = cachedTx
+			senderRecoveryHitCounter.Inc(1)
+		} else {
+			senderRecoveryMissCounter.Inc(1)
+		}
+	}
+	return txes
+}
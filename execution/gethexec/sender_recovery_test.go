@@ -0,0 +1,94 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package gethexec
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/offchainlabs/nitro/arbos"
+	"github.com/offchainlabs/nitro/arbos/arbostypes"
+)
+
+func TestSenderRecovererReusesRecoveredSenders(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	chainConfig := params.ArbitrumDevTestChainConfig()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	const blockNumber = 1
+	const timestamp = 1000
+	signer := types.MakeSigner(chainConfig, big.NewInt(blockNumber), timestamp)
+	tx, err := types.SignNewTx(key, signer, &types.DynamicFeeTx{
+		ChainID:   chainConfig.ChainID,
+		Nonce:     0,
+		GasTipCap: common.Big0,
+		GasFeeCap: big.NewInt(params.GWei),
+		Gas:       params.TxGas,
+		To:        &common.Address{},
+		Value:     common.Big1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	txBytes, err := tx.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := &arbostypes.MessageWithMetadata{
+		Message: &arbostypes.L1IncomingMessage{
+			Header: &arbostypes.L1IncomingMessageHeader{
+				Kind:      arbostypes.L1MessageType_L2Message,
+				Timestamp: timestamp,
+				L1BaseFee: common.Big0,
+			},
+			L2msg: append([]byte{arbos.L2MessageKind_SignedTx}, txBytes...),
+		},
+	}
+
+	config := DefaultSenderRecoveryConfig
+	config.Workers = 2
+	recoverer := NewSenderRecoverer(&config, chainConfig)
+	for i := 0; i < config.Workers; i++ {
+		go recoverer.worker(ctx)
+	}
+	recoverer.Prefetch(blockNumber, msg)
+
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		if _, ok := recoverer.cached(tx.Hash()); ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("sender was not recovered in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	txes := recoverer.ParseL2Transactions(msg.Message)
+	if len(txes) != 1 || txes[0].Hash() != tx.Hash() {
+		t.Fatal("unexpected parsed transactions", txes)
+	}
+	cachedTx, _ := recoverer.cached(tx.Hash())
+	if txes[0] != cachedTx {
+		t.Fatal("parsed transaction was not substituted with the recovered one")
+	}
+	sender, err := types.Sender(signer, txes[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sender != crypto.PubkeyToAddress(key.PublicKey) {
+		t.Fatal("recovered wrong sender", sender)
+	}
+}
@@ -20,17 +20,16 @@ import (
 	"github.com/offchainlabs/nitro/util/arbmath"
 	"github.com/offchainlabs/nitro/util/containers"
 	"github.com/offchainlabs/nitro/util/headerreader"
+	"github.com/offchainlabs/nitro/util/l1price"
 	flag "github.com/spf13/pflag"
 
 	"github.com/ethereum/go-ethereum/arbitrum"
 	"github.com/ethereum/go-ethereum/arbitrum_types"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto/kzg4844"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/params"
@@ -61,21 +60,23 @@ var (
 )
 
 type SequencerConfig struct {
-	Enable                       bool            `koanf:"enable"`
-	MaxBlockSpeed                time.Duration   `koanf:"max-block-speed" reload:"hot"`
-	MaxRevertGasReject           uint64          `koanf:"max-revert-gas-reject" reload:"hot"`
-	MaxAcceptableTimestampDelta  time.Duration   `koanf:"max-acceptable-timestamp-delta" reload:"hot"`
-	SenderWhitelist              []string        `koanf:"sender-whitelist"`
-	Forwarder                    ForwarderConfig `koanf:"forwarder"`
-	QueueSize                    int             `koanf:"queue-size"`
-	QueueTimeout                 time.Duration   `koanf:"queue-timeout" reload:"hot"`
-	NonceCacheSize               int             `koanf:"nonce-cache-size" reload:"hot"`
-	MaxTxDataSize                int             `koanf:"max-tx-data-size" reload:"hot"`
-	NonceFailureCacheSize        int             `koanf:"nonce-failure-cache-size" reload:"hot"`
-	NonceFailureCacheExpiry      time.Duration   `koanf:"nonce-failure-cache-expiry" reload:"hot"`
-	ExpectedSurplusSoftThreshold string          `koanf:"expected-surplus-soft-threshold" reload:"hot"`
-	ExpectedSurplusHardThreshold string          `koanf:"expected-surplus-hard-threshold" reload:"hot"`
-	EnableProfiling              bool            `koanf:"enable-profiling" reload:"hot"`
+	Enable                       bool                         `koanf:"enable"`
+	MaxBlockSpeed                time.Duration                `koanf:"max-block-speed" reload:"hot"`
+	MaxRevertGasReject           uint64                       `koanf:"max-revert-gas-reject" reload:"hot"`
+	MaxAcceptableTimestampDelta  time.Duration                `koanf:"max-acceptable-timestamp-delta" reload:"hot"`
+	SenderWhitelist              []string                     `koanf:"sender-whitelist"`
+	Forwarder                    ForwarderConfig              `koanf:"forwarder"`
+	QueueSize                    int                          `koanf:"queue-size"`
+	QueueTimeout                 time.Duration                `koanf:"queue-timeout" reload:"hot"`
+	NonceCacheSize               int                          `koanf:"nonce-cache-size" reload:"hot"`
+	MaxTxDataSize                int                          `koanf:"max-tx-data-size" reload:"hot"`
+	NonceFailureCacheSize        int                          `koanf:"nonce-failure-cache-size" reload:"hot"`
+	NonceFailureCacheExpiry      time.Duration                `koanf:"nonce-failure-cache-expiry" reload:"hot"`
+	ExpectedSurplusSoftThreshold string                       `koanf:"expected-surplus-soft-threshold" reload:"hot"`
+	ExpectedSurplusHardThreshold string                       `koanf:"expected-surplus-hard-threshold" reload:"hot"`
+	EnableProfiling              bool                         `koanf:"enable-profiling" reload:"hot"`
+	L1PriceOracle                l1price.Config               `koanf:"l1-price-oracle"`
+	PreValidation                SequencerPreValidationConfig `koanf:"pre-validation"`
 	expectedSurplusSoftThreshold int
 	expectedSurplusHardThreshold int
 }
@@ -106,7 +107,10 @@ func (c *SequencerConfig) Validate() error {
 	if c.MaxTxDataSize > arbostypes.MaxL2MessageSize-50000 {
 		return errors.New("max-tx-data-size too large for MaxL2MessageSize")
 	}
-	return nil
+	if err := c.PreValidation.Validate(); err != nil {
+		return err
+	}
+	return c.L1PriceOracle.Validate()
 }
 
 type SequencerConfigFetcher func() *SequencerConfig
@@ -129,6 +133,8 @@ var DefaultSequencerConfig = SequencerConfig{
 	ExpectedSurplusSoftThreshold: "default",
 	ExpectedSurplusHardThreshold: "default",
 	EnableProfiling:              false,
+	L1PriceOracle:                l1price.DefaultConfig,
+	PreValidation:                DefaultSequencerPreValidationConfig,
 }
 
 func SequencerConfigAddOptions(prefix string, f *flag.FlagSet) {
@@ -147,6 +153,8 @@ func SequencerConfigAddOptions(prefix string, f *flag.FlagSet) {
 	f.String(prefix+".expected-surplus-soft-threshold", DefaultSequencerConfig.ExpectedSurplusSoftThreshold, "if expected surplus is lower than this value, warnings are posted")
 	f.String(prefix+".expected-surplus-hard-threshold", DefaultSequencerConfig.ExpectedSurplusHardThreshold, "if expected surplus is lower than this value, new incoming transactions will be denied")
 	f.Bool(prefix+".enable-profiling", DefaultSequencerConfig.EnableProfiling, "enable CPU profiling and tracing")
+	l1price.ConfigAddOptions(prefix+".l1-price-oracle", f)
+	SequencerPreValidationConfigAddOptions(prefix+".pre-validation", f)
 }
 
 type txQueueItem struct {
@@ -295,16 +303,20 @@ type Sequencer struct {
 	txQueue         chan txQueueItem
 	txRetryQueue    containers.Queue[txQueueItem]
 	l1Reader        *headerreader.HeaderReader
+	l1PriceOracle   l1price.Oracle
 	config          SequencerConfigFetcher
 	senderWhitelist map[common.Address]struct{}
 	nonceCache      *nonceCache
 	nonceFailures   *nonceFailureCache
+	preValidator    *txPreValidator
 	onForwarderSet  chan struct{}
 
 	L1BlockAndTimeMutex sync.Mutex
 	l1BlockNumber       atomic.Uint64
 	l1Timestamp         uint64
 
+	devClock devClock
+
 	// activeMutex manages pauseChan (pauses execution) and forwarder
 	// at most one of these is non-nil at any given time
 	// both are nil for the active sequencer
@@ -329,13 +341,19 @@ func NewSequencer(execEngine *ExecutionEngine, l1Reader *headerreader.HeaderRead
 		}
 		senderWhitelist[common.HexToAddress(address)] = struct{}{}
 	}
+	l1PriceOracle, err := l1price.NewOracle(context.Background(), &config.L1PriceOracle)
+	if err != nil {
+		return nil, err
+	}
 	s := &Sequencer{
 		execEngine:      execEngine,
 		txQueue:         make(chan txQueueItem, config.QueueSize),
 		l1Reader:        l1Reader,
+		l1PriceOracle:   l1PriceOracle,
 		config:          configFetcher,
 		senderWhitelist: senderWhitelist,
 		nonceCache:      newNonceCache(config.NonceCacheSize),
+		preValidator:    newTxPreValidator(config.PreValidation.CacheSize),
 		l1Timestamp:     0,
 		pauseChan:       nil,
 		onForwarderSet:  make(chan struct{}, 1),
@@ -678,6 +696,10 @@ func (s *Sequencer) precheckNonces(queueItems []txQueueItem, totalBlockSize int)
 	}
 	nextHeaderNumber := arbmath.BigAdd(latestHeader.Number, common.Big1)
 	signer := types.MakeSigner(bc.Config(), nextHeaderNumber, latestHeader.Time)
+	if config.PreValidation.Enable {
+		s.preValidator.resize(config.PreValidation.CacheSize)
+		queueItems = s.preValidator.preValidate(signer, queueItems, config.PreValidation.numWorkers())
+	}
 	outputQueueItems := make([]txQueueItem, 0, len(queueItems))
 	var nextQueueItem *txQueueItem
 	var queueItemsIdx int
@@ -877,7 +899,7 @@ func (s *Sequencer) createBlock(ctx context.Context) (returnValue bool) {
 		return false
 	}
 
-	timestamp := time.Now().Unix()
+	timestamp, explicitTimestamp := s.devClock.timestamp()
 	s.L1BlockAndTimeMutex.Lock()
 	l1Block := s.l1BlockNumber.Load()
 	l1Timestamp := s.l1Timestamp
@@ -959,6 +981,9 @@ func (s *Sequencer) createBlock(ctx context.Context) (returnValue bool) {
 	if block != nil {
 		successfulBlocksCounter.Inc(1)
 		s.nonceCache.Finalize(block)
+		if explicitTimestamp {
+			s.devClock.blockCreated(timestamp)
+		}
 	}
 
 	madeBlock := false
@@ -1013,27 +1038,16 @@ func (s *Sequencer) Initialize(ctx context.Context) error {
 	return nil
 }
 
-var (
-	usableBytesInBlob    = big.NewInt(int64(len(kzg4844.Blob{}) * 31 / 32))
-	blobTxBlobGasPerBlob = big.NewInt(params.BlobTxBlobGasPerBlob)
-)
-
 func (s *Sequencer) updateExpectedSurplus(ctx context.Context) (int64, error) {
 	header, err := s.l1Reader.LastHeader(ctx)
 	if err != nil {
 		return 0, fmt.Errorf("error encountered getting latest header from l1reader while updating expectedSurplus: %w", err)
 	}
-	l1GasPrice := header.BaseFee.Uint64()
-	if header.BlobGasUsed != nil {
-		if header.ExcessBlobGas != nil {
-			blobFeePerByte := eip4844.CalcBlobFee(eip4844.CalcExcessBlobGas(*header.ExcessBlobGas, *header.BlobGasUsed))
-			blobFeePerByte.Mul(blobFeePerByte, blobTxBlobGasPerBlob)
-			blobFeePerByte.Div(blobFeePerByte, usableBytesInBlob)
-			if l1GasPrice > blobFeePerByte.Uint64()/16 {
-				l1GasPrice = blobFeePerByte.Uint64() / 16
-			}
-		}
+	prices, err := s.l1PriceOracle.Prices(ctx, header)
+	if err != nil {
+		return 0, fmt.Errorf("error encountered getting parent chain prices while updating expectedSurplus: %w", err)
 	}
+	l1GasPrice := prices.L1GasPrice().Uint64()
 	surplus, err := s.execEngine.getL1PricingSurplus()
 	if err != nil {
 		return 0, fmt.Errorf("error encountered getting l1 pricing surplus while updating expectedSurplus: %w", err)
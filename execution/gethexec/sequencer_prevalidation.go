@@ -0,0 +1,173 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package gethexec
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/offchainlabs/nitro/util/containers"
+)
+
+var (
+	preValidationHitCounter      = metrics.NewRegisteredCounter("arb/sequencer/prevalidation/hit", nil)
+	preValidationMissCounter     = metrics.NewRegisteredCounter("arb/sequencer/prevalidation/miss", nil)
+	preValidationRejectedCounter = metrics.NewRegisteredCounter("arb/sequencer/prevalidation/rejected", nil)
+	preValidationTimer           = metrics.NewRegisteredTimer("arb/sequencer/prevalidation/duration", nil)
+)
+
+type SequencerPreValidationConfig struct {
+	Enable    bool `koanf:"enable" reload:"hot"`
+	Workers   int  `koanf:"workers" reload:"hot"`
+	CacheSize int  `koanf:"cache-size" reload:"hot"`
+}
+
+var DefaultSequencerPreValidationConfig = SequencerPreValidationConfig{
+	Enable:    true,
+	Workers:   0,
+	CacheSize: 16384,
+}
+
+func SequencerPreValidationConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.Bool(prefix+".enable", DefaultSequencerPreValidationConfig.Enable, "recover senders and check signatures of queued transactions in parallel before sequencing them")
+	f.Int(prefix+".workers", DefaultSequencerPreValidationConfig.Workers, "number of pre-validation workers (0 = number of CPUs)")
+	f.Int(prefix+".cache-size", DefaultSequencerPreValidationConfig.CacheSize, "number of pre-validated transactions kept by hash, so resubmitted and retried transactions aren't validated again")
+}
+
+func (c *SequencerPreValidationConfig) Validate() error {
+	if c.Workers < 0 {
+		return errors.New("sequencer pre-validation workers must not be negative")
+	}
+	return nil
+}
+
+func (c *SequencerPreValidationConfig) numWorkers() int {
+	if c.Workers > 0 {
+		return c.Workers
+	}
+	return runtime.NumCPU()
+}
+
+// preValidation is the outcome of pre-validating a transaction with a signer: the transaction with its sender
+// recovered, or the reason it can't be sequenced.
+type preValidation struct {
+	signer types.Signer
+	tx     *types.Transaction
+	err    error
+}
+
+// txPreValidator checks the parts of queued transactions that don't depend on state before they're sequenced:
+// their type, their gas floor, and their signature, recovering their sender. Signature recovery dominates the
+// serial work of sequencing a burst of transactions, so it's spread across workers, and the recovered sender is
+// kept in the transaction for the nonce pre-check and block production to reuse. Outcomes are also cached by
+// transaction hash, as the same transaction is often submitted again, and reused only with an equal signer.
+type txPreValidator struct {
+	cacheMutex sync.Mutex
+	cache      *containers.LruCache[common.Hash, preValidation]
+}
+
+func newTxPreValidator(cacheSize int) *txPreValidator {
+	return &txPreValidator{
+		cache: containers.NewLruCache[common.Hash, preValidation](cacheSize),
+	}
+}
+
+func (v *txPreValidator) cached(hash common.Hash, signer types.Signer) (preValidation, bool) {
+	v.cacheMutex.Lock()
+	defer v.cacheMutex.Unlock()
+	result, ok := v.cache.Get(hash)
+	if !ok || !result.signer.Equal(signer) {
+		return preValidation{}, false
+	}
+	return result, true
+}
+
+func (v *txPreValidator) resize(cacheSize int) {
+	v.cacheMutex.Lock()
+	defer v.cacheMutex.Unlock()
+	v.cache.Resize(cacheSize)
+}
+
+func preValidateTx(signer types.Signer, tx *types.Transaction) error {
+	if tx.Type() >= types.ArbitrumDepositTxType || tx.Type() == types.BlobTxType {
+		return types.ErrTxTypeNotSupported
+	}
+	if tx.Gas() < params.TxGas {
+		return core.ErrIntrinsicGas
+	}
+	_, err := types.Sender(signer, tx)
+	return err
+}
+
+// preValidate pre-validates the queue items with up to workers goroutines, returning the result to the items that
+// fail and the rest in their original order. Each returned item holds a transaction with its sender recovered.
+func (v *txPreValidator) preValidate(signer types.Signer, queueItems []txQueueItem, workers int) []txQueueItem {
+	defer preValidationTimer.UpdateSince(time.Now())
+	results := make([]preValidation, len(queueItems))
+	var misses []int
+	for i, item := range queueItems {
+		if result, ok := v.cached(item.tx.Hash(), signer); ok {
+			results[i] = result
+			preValidationHitCounter.Inc(1)
+		} else {
+			misses = append(misses, i)
+			preValidationMissCounter.Inc(1)
+		}
+	}
+	var next atomic.Int64
+	validate := func() {
+		for {
+			n := int(next.Add(1)) - 1
+			if n >= len(misses) {
+				return
+			}
+			i := misses[n]
+			tx := queueItems[i].tx
+			results[i] = preValidation{signer: signer, tx: tx, err: preValidateTx(signer, tx)}
+		}
+	}
+	workers = min(workers, len(misses))
+	var wg sync.WaitGroup
+	for w := 1; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			validate()
+		}()
+	}
+	// this goroutine is a worker too, and does all the work when there's one miss or fewer
+	validate()
+	wg.Wait()
+
+	v.cacheMutex.Lock()
+	for _, i := range misses {
+		v.cache.Add(queueItems[i].tx.Hash(), results[i])
+	}
+	v.cacheMutex.Unlock()
+
+	valid := queueItems[:0]
+	for i, item := range queueItems {
+		result := results[i]
+		if result.err != nil {
+			preValidationRejectedCounter.Inc(1)
+			item.returnResult(result.err)
+			continue
+		}
+		// a cached transaction has the same hash, so it's the same transaction, but with its sender recovered
+		item.tx = result.tx
+		valid = append(valid, item)
+	}
+	return valid
+}
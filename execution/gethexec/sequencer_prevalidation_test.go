@@ -0,0 +1,121 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package gethexec
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func newTestQueueItem(tx *types.Transaction) (txQueueItem, <-chan error) {
+	resultChan := make(chan error, 1)
+	return txQueueItem{
+		tx:             tx,
+		resultChan:     resultChan,
+		returnedResult: &atomic.Bool{},
+		ctx:            context.Background(),
+	}, resultChan
+}
+
+func TestTxPreValidator(t *testing.T) {
+	chainConfig := params.ArbitrumDevTestChainConfig()
+	signer := types.MakeSigner(chainConfig, big.NewInt(1), 1000)
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherChainSigner := types.LatestSignerForChainID(big.NewInt(1))
+
+	var queueItems []txQueueItem
+	var resultChans []<-chan error
+	var txes []*types.Transaction
+	for i := 0; i < 8; i++ {
+		txSigner := signer
+		gas := params.TxGas
+		switch i {
+		case 3:
+			txSigner = otherChainSigner
+		case 5:
+			gas = params.TxGas - 1
+		}
+		tx, err := types.SignNewTx(key, txSigner, &types.DynamicFeeTx{
+			ChainID:   txSigner.ChainID(),
+			Nonce:     uint64(i),
+			GasFeeCap: big.NewInt(params.GWei),
+			Gas:       gas,
+			To:        &common.Address{},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		item, resultChan := newTestQueueItem(tx)
+		queueItems = append(queueItems, item)
+		resultChans = append(resultChans, resultChan)
+		txes = append(txes, tx)
+	}
+
+	validator := newTxPreValidator(16)
+	valid := validator.preValidate(signer, queueItems, 4)
+	if len(valid) != 6 {
+		t.Fatal("unexpected number of valid transactions", len(valid))
+	}
+	for i, resultChan := range resultChans {
+		select {
+		case err := <-resultChan:
+			if i != 3 && i != 5 {
+				t.Fatal("rejected a valid transaction", i, err)
+			}
+			if err == nil {
+				t.Fatal("rejected a transaction without an error", i)
+			}
+		default:
+			if i == 3 || i == 5 {
+				t.Fatal("didn't reject an invalid transaction", i)
+			}
+		}
+	}
+	for _, item := range valid {
+		sender, err := types.Sender(signer, item.tx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sender != crypto.PubkeyToAddress(key.PublicKey) {
+			t.Fatal("recovered wrong sender", sender)
+		}
+	}
+
+	// a resubmitted transaction is swapped for the one already validated
+	resubmitted := new(types.Transaction)
+	encoded, err := txes[0].MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := resubmitted.UnmarshalBinary(encoded); err != nil {
+		t.Fatal(err)
+	}
+	item, _ := newTestQueueItem(resubmitted)
+	valid = validator.preValidate(signer, []txQueueItem{item}, 4)
+	if len(valid) != 1 || valid[0].tx != txes[0] {
+		t.Fatal("didn't reuse the validated transaction")
+	}
+
+	// and a transaction rejected before is rejected again
+	item, resultChan := newTestQueueItem(txes[5])
+	valid = validator.preValidate(signer, []txQueueItem{item}, 4)
+	if len(valid) != 0 {
+		t.Fatal("accepted a transaction rejected before")
+	}
+	if err := <-resultChan; !errors.Is(err, core.ErrIntrinsicGas) {
+		t.Fatal("unexpected error", err)
+	}
+}
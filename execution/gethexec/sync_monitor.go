@@ -82,6 +82,9 @@ func (s *SyncMonitor) SafeBlockNumber(ctx context.Context) (uint64, error) {
 			msg = latestValidatedCount
 		}
 	}
+	if msg == 0 {
+		return 0, errors.New("no safe block yet")
+	}
 	block := s.exec.MessageIndexToBlockNumber(msg - 1)
 	return block, nil
 }
@@ -103,6 +106,9 @@ func (s *SyncMonitor) FinalizedBlockNumber(ctx context.Context) (uint64, error)
 			msg = latestValidatedCount
 		}
 	}
+	if msg == 0 {
+		return 0, errors.New("no finalized block yet")
+	}
 	block := s.exec.MessageIndexToBlockNumber(msg - 1)
 	return block, nil
 }
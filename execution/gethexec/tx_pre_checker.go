@@ -35,9 +35,11 @@ const TxPreCheckerStrictnessLikelyCompatible uint = 20
 const TxPreCheckerStrictnessFullValidation uint = 30
 
 type TxPreCheckerConfig struct {
-	Strictness             uint  `koanf:"strictness" reload:"hot"`
-	RequiredStateAge       int64 `koanf:"required-state-age" reload:"hot"`
-	RequiredStateMaxBlocks uint  `koanf:"required-state-max-blocks" reload:"hot"`
+	Strictness             uint                    `koanf:"strictness" reload:"hot"`
+	RequiredStateAge       int64                   `koanf:"required-state-age" reload:"hot"`
+	RequiredStateMaxBlocks uint                    `koanf:"required-state-max-blocks" reload:"hot"`
+	Rules                  TxPreCheckerRulesConfig `koanf:"rules" reload:"hot"`
+	Hooks                  []string                `koanf:"hooks" reload:"hot"`
 }
 
 type TxPreCheckerConfigFetcher func() *TxPreCheckerConfig
@@ -46,6 +48,8 @@ var DefaultTxPreCheckerConfig = TxPreCheckerConfig{
 	Strictness:             TxPreCheckerStrictnessLikelyCompatible,
 	RequiredStateAge:       2,
 	RequiredStateMaxBlocks: 4,
+	Rules:                  DefaultTxPreCheckerRulesConfig,
+	Hooks:                  []string{},
 }
 
 func TxPreCheckerConfigAddOptions(prefix string, f *flag.FlagSet) {
@@ -54,12 +58,27 @@ func TxPreCheckerConfigAddOptions(prefix string, f *flag.FlagSet) {
 		"30 = full validation which may reject txs that would succeed")
 	f.Int64(prefix+".required-state-age", DefaultTxPreCheckerConfig.RequiredStateAge, "how long ago should the storage conditions from eth_SendRawTransactionConditional be true, 0 = don't check old state")
 	f.Uint(prefix+".required-state-max-blocks", DefaultTxPreCheckerConfig.RequiredStateMaxBlocks, "maximum number of blocks to look back while looking for the <required-state-age> seconds old state, 0 = don't limit the search")
+	TxPreCheckerRulesConfigAddOptions(prefix+".rules", f)
+	f.StringSlice(prefix+".hooks", DefaultTxPreCheckerConfig.Hooks, "names of the custom pre-check hooks compiled into the node to run on every transaction, regardless of strictness")
+}
+
+func (c *TxPreCheckerConfig) Validate() error {
+	if err := c.Rules.Validate(); err != nil {
+		return err
+	}
+	for _, name := range c.Hooks {
+		if _, ok := getTxPreCheckHook(name); !ok {
+			return fmt.Errorf("unknown tx pre-check hook %s", name)
+		}
+	}
+	return nil
 }
 
 type TxPreChecker struct {
 	TransactionPublisher
 	bc     *core.BlockChain
 	config TxPreCheckerConfigFetcher
+	rules  txPreCheckerRulesCache
 }
 
 func NewTxPreChecker(publisher TransactionPublisher, bc *core.BlockChain, config TxPreCheckerConfigFetcher) *TxPreChecker {
@@ -121,6 +140,9 @@ func PreCheckTx(bc *core.BlockChain, chainConfig *params.ChainConfig, header *ty
 		// and we want to disallow BlobTxType since Arbitrum doesn't support EIP-4844 txs yet.
 		return types.ErrTxTypeNotSupported
 	}
+	if err := arbos.CheckTxSize(tx); err != nil {
+		return err
+	}
 	sender, err := types.Sender(types.MakeSigner(chainConfig, header.Number, header.Time), tx)
 	if err != nil {
 		return err
@@ -186,9 +208,17 @@ func PreCheckTx(bc *core.BlockChain, chainConfig *params.ChainConfig, header *ty
 			conditionalTxAcceptedByTxPreCheckerOldStateCounter.Inc(1)
 		}
 	}
-	balance := statedb.GetBalance(sender)
+	balance := statedb.GetBalance(sender).ToBig()
+	paymaster, advance, err := arbos.SponsorshipAdvance(sender, tx.To(), tx.Data(), tx.GasFeeCap(), tx.Gas())
+	if err != nil {
+		return err
+	}
+	if advance != nil && !arbmath.BigLessThan(statedb.GetBalance(paymaster).ToBig(), advance) {
+		// the paymaster will advance the sender the tx's gas
+		balance = arbmath.BigAdd(balance, advance)
+	}
 	cost := tx.Cost()
-	if arbmath.BigLessThan(balance.ToBig(), cost) {
+	if arbmath.BigLessThan(balance, cost) {
 		return fmt.Errorf("%w: address %v have %v want %v", core.ErrInsufficientFunds, sender, balance, cost)
 	}
 	if config.Strictness >= TxPreCheckerStrictnessFullValidation && tx.Nonce() > stateNonce {
@@ -206,8 +236,39 @@ func PreCheckTx(bc *core.BlockChain, chainConfig *params.ChainConfig, header *ty
 	return nil
 }
 
+// checkPolicy applies the configured rules and hooks, which unlike the checks in PreCheckTx don't
+// depend on the strictness.
+func (c *TxPreChecker) checkPolicy(ctx context.Context, header *types.Header, tx *types.Transaction, options *arbitrum_types.ConditionalOptions, config *TxPreCheckerConfig) error {
+	if err := c.rules.get(&config.Rules).check(tx, header.BaseFee); err != nil {
+		txRejectedByTxPreCheckerRulesCounter.Inc(1)
+		return err
+	}
+	if len(config.Hooks) == 0 {
+		return nil
+	}
+	sender, err := types.Sender(types.MakeSigner(c.bc.Config(), header.Number, header.Time), tx)
+	if err != nil {
+		return err
+	}
+	for _, name := range config.Hooks {
+		hook, ok := getTxPreCheckHook(name)
+		if !ok {
+			return fmt.Errorf("unknown tx pre-check hook %s", name)
+		}
+		if err := hook.PreCheckTx(ctx, tx, sender, header, options); err != nil {
+			txRejectedByTxPreCheckerHooksCounter.Inc(1)
+			return err
+		}
+	}
+	return nil
+}
+
 func (c *TxPreChecker) PublishTransaction(ctx context.Context, tx *types.Transaction, options *arbitrum_types.ConditionalOptions) error {
+	config := c.config()
 	block := c.bc.CurrentBlock()
+	if err := c.checkPolicy(ctx, block, tx, options, config); err != nil {
+		return err
+	}
 	statedb, err := c.bc.StateAt(block.Root)
 	if err != nil {
 		return err
@@ -216,7 +277,7 @@ func (c *TxPreChecker) PublishTransaction(ctx context.Context, tx *types.Transac
 	if err != nil {
 		return err
 	}
-	err = PreCheckTx(c.bc, c.bc.Config(), block, statedb, arbos, tx, options, c.config())
+	err = PreCheckTx(c.bc, c.bc.Config(), block, statedb, arbos, tx, options, config)
 	if err != nil {
 		return err
 	}
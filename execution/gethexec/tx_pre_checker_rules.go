@@ -0,0 +1,205 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package gethexec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/arbitrum_types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+
+	"github.com/offchainlabs/nitro/util/arbmath"
+)
+
+var (
+	txRejectedByTxPreCheckerRulesCounter = metrics.NewRegisteredCounter("arb/txprechecker/rules/rejected", nil)
+	txRejectedByTxPreCheckerHooksCounter = metrics.NewRegisteredCounter("arb/txprechecker/hooks/rejected", nil)
+)
+
+// ErrTxPreCheckRejected is wrapped by the errors of transactions rejected by the tx pre-checker's rules.
+var ErrTxPreCheckRejected = errors.New("transaction rejected by policy")
+
+// TxPreCheckHook is a custom check run on every transaction the tx pre-checker is given, before it
+// is forwarded or sequenced. Returning an error rejects the transaction with that error.
+// Hooks are called concurrently and must be safe for that.
+type TxPreCheckHook interface {
+	PreCheckTx(ctx context.Context, tx *types.Transaction, sender common.Address, header *types.Header, options *arbitrum_types.ConditionalOptions) error
+}
+
+type TxPreCheckHookFunc func(ctx context.Context, tx *types.Transaction, sender common.Address, header *types.Header, options *arbitrum_types.ConditionalOptions) error
+
+func (f TxPreCheckHookFunc) PreCheckTx(ctx context.Context, tx *types.Transaction, sender common.Address, header *types.Header, options *arbitrum_types.ConditionalOptions) error {
+	return f(ctx, tx, sender, header, options)
+}
+
+var (
+	txPreCheckHooksMutex sync.RWMutex
+	txPreCheckHooks      = make(map[string]TxPreCheckHook)
+)
+
+// RegisterTxPreCheckHook makes a hook available to be enabled by name through the tx-pre-checker.hooks
+// option. Custom node builds register their hooks from an init function.
+func RegisterTxPreCheckHook(name string, hook TxPreCheckHook) {
+	txPreCheckHooksMutex.Lock()
+	defer txPreCheckHooksMutex.Unlock()
+	if _, exists := txPreCheckHooks[name]; exists {
+		panic(fmt.Sprintf("tx pre-check hook %s registered twice", name))
+	}
+	txPreCheckHooks[name] = hook
+}
+
+func getTxPreCheckHook(name string) (TxPreCheckHook, bool) {
+	txPreCheckHooksMutex.RLock()
+	defer txPreCheckHooksMutex.RUnlock()
+	hook, ok := txPreCheckHooks[name]
+	return hook, ok
+}
+
+type TxPreCheckerRulesConfig struct {
+	BlockedTargets     []string `koanf:"blocked-targets" reload:"hot"`
+	BlockedSelectors   []string `koanf:"blocked-selectors" reload:"hot"`
+	MinBaseFeeMultiple float64  `koanf:"min-basefee-multiple" reload:"hot"`
+}
+
+var DefaultTxPreCheckerRulesConfig = TxPreCheckerRulesConfig{
+	BlockedTargets:     []string{},
+	BlockedSelectors:   []string{},
+	MinBaseFeeMultiple: 0,
+}
+
+func TxPreCheckerRulesConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.StringSlice(prefix+".blocked-targets", DefaultTxPreCheckerRulesConfig.BlockedTargets, "reject transactions sent to these addresses")
+	f.StringSlice(prefix+".blocked-selectors", DefaultTxPreCheckerRulesConfig.BlockedSelectors, "reject transactions whose calldata starts with these 4 byte function selectors, each given as selector or target:selector to only block calls to that target")
+	f.Float64(prefix+".min-basefee-multiple", DefaultTxPreCheckerRulesConfig.MinBaseFeeMultiple, "reject transactions whose max fee per gas is below this multiple of the current basefee (0 = disabled)")
+}
+
+func (c *TxPreCheckerRulesConfig) Validate() error {
+	_, err := parseTxPreCheckerRules(c)
+	return err
+}
+
+type txSelectorRule struct {
+	target   common.Address // zero for any target
+	selector [4]byte
+}
+
+type txPreCheckerRules struct {
+	blockedTargets     map[common.Address]struct{}
+	blockedSelectors   map[txSelectorRule]struct{}
+	minBaseFeeMultiple *big.Float
+}
+
+func parseTxPreCheckerAddress(s string) (common.Address, error) {
+	if !common.IsHexAddress(s) {
+		return common.Address{}, fmt.Errorf("invalid address %q", s)
+	}
+	return common.HexToAddress(s), nil
+}
+
+func parseTxPreCheckerRules(config *TxPreCheckerRulesConfig) (*txPreCheckerRules, error) {
+	rules := &txPreCheckerRules{
+		blockedTargets:   make(map[common.Address]struct{}),
+		blockedSelectors: make(map[txSelectorRule]struct{}),
+	}
+	for _, target := range config.BlockedTargets {
+		address, err := parseTxPreCheckerAddress(target)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tx pre-checker blocked target: %w", err)
+		}
+		rules.blockedTargets[address] = struct{}{}
+	}
+	for _, entry := range config.BlockedSelectors {
+		var rule txSelectorRule
+		selector := entry
+		if target, rest, scoped := strings.Cut(entry, ":"); scoped {
+			address, err := parseTxPreCheckerAddress(target)
+			if err != nil {
+				return nil, fmt.Errorf("invalid tx pre-checker blocked selector %q: %w", entry, err)
+			}
+			rule.target = address
+			selector = rest
+		}
+		decoded, err := hexutil.Decode(selector)
+		if err != nil || len(decoded) != len(rule.selector) {
+			return nil, fmt.Errorf("invalid tx pre-checker blocked selector %q, expected 0x followed by 8 hex digits", entry)
+		}
+		copy(rule.selector[:], decoded)
+		rules.blockedSelectors[rule] = struct{}{}
+	}
+	if config.MinBaseFeeMultiple < 0 {
+		return nil, errors.New("tx pre-checker min-basefee-multiple must not be negative")
+	}
+	if config.MinBaseFeeMultiple > 0 {
+		rules.minBaseFeeMultiple = big.NewFloat(config.MinBaseFeeMultiple)
+	}
+	return rules, nil
+}
+
+func (r *txPreCheckerRules) check(tx *types.Transaction, baseFee *big.Int) error {
+	if to := tx.To(); to != nil {
+		if _, blocked := r.blockedTargets[*to]; blocked {
+			return fmt.Errorf("%w: target %v is blocked", ErrTxPreCheckRejected, *to)
+		}
+		if data := tx.Data(); len(data) >= 4 {
+			rule := txSelectorRule{}
+			copy(rule.selector[:], data)
+			_, blocked := r.blockedSelectors[rule]
+			if !blocked {
+				rule.target = *to
+				_, blocked = r.blockedSelectors[rule]
+			}
+			if blocked {
+				return fmt.Errorf("%w: selector %v is blocked for target %v", ErrTxPreCheckRejected, hexutil.Bytes(rule.selector[:]), *to)
+			}
+		}
+	}
+	if r.minBaseFeeMultiple != nil && baseFee != nil {
+		minFeeCap, _ := new(big.Float).Mul(new(big.Float).SetInt(baseFee), r.minBaseFeeMultiple).Int(nil)
+		if arbmath.BigLessThan(tx.GasFeeCap(), minFeeCap) {
+			return fmt.Errorf("%w: max fee per gas %v is below the required %v", ErrTxPreCheckRejected, tx.GasFeeCap(), minFeeCap)
+		}
+	}
+	return nil
+}
+
+type parsedTxPreCheckerRules struct {
+	source *TxPreCheckerRulesConfig
+	rules  *txPreCheckerRules
+}
+
+// txPreCheckerRulesCache parses the hot-reloadable rules again only when the config changes.
+// If reloaded rules are invalid, the previous rules stay in effect.
+type txPreCheckerRulesCache struct {
+	parsed atomic.Pointer[parsedTxPreCheckerRules]
+}
+
+func (c *txPreCheckerRulesCache) get(config *TxPreCheckerRulesConfig) *txPreCheckerRules {
+	previous := c.parsed.Load()
+	if previous != nil && previous.source == config {
+		return previous.rules
+	}
+	rules, err := parseTxPreCheckerRules(config)
+	if err != nil {
+		if previous == nil {
+			rules = &txPreCheckerRules{}
+		} else {
+			rules = previous.rules
+		}
+		log.Error("invalid tx pre-checker rules, keeping the previous rules", "err", err)
+	}
+	c.parsed.Store(&parsedTxPreCheckerRules{source: config, rules: rules})
+	return rules
+}
@@ -0,0 +1,70 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package gethexec
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestTxPreCheckerRules(t *testing.T) {
+	blocked := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	scoped := common.HexToAddress("0x00000000000000000000000000000000000000bb")
+	other := common.HexToAddress("0x00000000000000000000000000000000000000cc")
+	config := &TxPreCheckerRulesConfig{
+		BlockedTargets:     []string{blocked.Hex()},
+		BlockedSelectors:   []string{"0xa9059cbb", scoped.Hex() + ":0x095ea7b3"},
+		MinBaseFeeMultiple: 1.5,
+	}
+	var cache txPreCheckerRulesCache
+	rules := cache.get(config)
+	baseFee := big.NewInt(100)
+	check := func(to common.Address, data []byte, feeCap int64) error {
+		return rules.check(types.NewTx(&types.DynamicFeeTx{To: &to, Data: data, GasFeeCap: big.NewInt(feeCap)}), baseFee)
+	}
+
+	if err := check(other, []byte{0x09, 0x5e, 0xa7, 0xb3}, 150); err != nil {
+		t.Fatal("rejected allowed transaction:", err)
+	}
+	for name, err := range map[string]error{
+		"blocked target":         check(blocked, nil, 150),
+		"blocked selector":       check(other, []byte{0xa9, 0x05, 0x9c, 0xbb, 0x01}, 150),
+		"target scoped selector": check(scoped, []byte{0x09, 0x5e, 0xa7, 0xb3}, 150),
+		"low fee cap":            check(other, nil, 149),
+	} {
+		if !errors.Is(err, ErrTxPreCheckRejected) {
+			t.Fatal("expected", name, "to be rejected, got", err)
+		}
+	}
+
+	// invalid reloaded rules keep the previous rules in effect
+	reloaded := &TxPreCheckerRulesConfig{BlockedSelectors: []string{"0x1234"}}
+	if reloaded.Validate() == nil {
+		t.Fatal("accepted a short selector")
+	}
+	if cache.get(reloaded) != rules {
+		t.Fatal("invalid rules replaced the previous rules")
+	}
+}
+
+func TestTxPreCheckHookRegistry(t *testing.T) {
+	config := DefaultTxPreCheckerConfig
+	config.Hooks = []string{"test-reject-all"}
+	if config.Validate() == nil {
+		t.Fatal("accepted an unregistered hook")
+	}
+	RegisterTxPreCheckHook("test-reject-all", TxPreCheckHookFunc(nil))
+	t.Cleanup(func() {
+		txPreCheckHooksMutex.Lock()
+		defer txPreCheckHooksMutex.Unlock()
+		delete(txPreCheckHooks, "test-reject-all")
+	})
+	if err := config.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
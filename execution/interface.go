@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/state"
@@ -23,8 +24,24 @@ type RecordResult struct {
 	UserWasms state.UserWasms
 }
 
+// ParentChainTiming is the parent chain's cadence, as the chain owner set it in ArbOS.
+type ParentChainTiming struct {
+	BlockTime             time.Duration
+	DelayedFinalityBlocks uint64 // a minimum on top of the node's configured finalize distance
+}
+
+// Blocks returns how many whole parent chain blocks the duration spans.
+func (t ParentChainTiming) Blocks(d time.Duration) uint64 {
+	if t.BlockTime <= 0 || d <= 0 {
+		return 0
+	}
+	// #nosec G115
+	return uint64(d / t.BlockTime)
+}
+
 var ErrRetrySequencer = errors.New("please retry transaction")
 var ErrSequencerInsertLockTaken = errors.New("insert lock taken")
+var ErrMessageNotExecuted = errors.New("message hasn't been executed yet")
 
 // always needed
 type ExecutionClient interface {
@@ -35,6 +52,11 @@ type ExecutionClient interface {
 	ResultAtPos(pos arbutil.MessageIndex) (*MessageResult, error)
 }
 
+// optionally implemented by execution clients that can recover transaction senders ahead of execution
+type SenderPrefetcher interface {
+	PrefetchSenders(start arbutil.MessageIndex, msgs []*arbostypes.MessageWithMetadata)
+}
+
 // needed for validators / stakers
 type ExecutionRecorder interface {
 	RecordBlockCreation(
@@ -54,6 +76,8 @@ type ExecutionSequencer interface {
 	ForwardTo(url string) error
 	SequenceDelayedMessage(message *arbostypes.L1IncomingMessage, delayedSeqNum uint64) error
 	NextDelayedMessageNumber() (uint64, error)
+	ParentChainTiming() (ParentChainTiming, error)
+	BatchDictionaryCommitted(messageNum arbutil.MessageIndex, hash common.Hash) (bool, error)
 	MarkFeedStart(to arbutil.MessageIndex)
 	Synced() bool
 	FullSyncProgressMap() map[string]interface{}
@@ -77,6 +101,8 @@ type FullExecutionClient interface {
 type BatchFetcher interface {
 	FindInboxBatchContainingMessage(message arbutil.MessageIndex) (uint64, bool, error)
 	GetBatchParentChainBlock(seqNum uint64) (uint64, error)
+	GetBatchMessageCount(seqNum uint64) (arbutil.MessageIndex, error)
+	GetBatchCount() (uint64, error)
 }
 
 type ConsensusInfo interface {
@@ -84,7 +110,7 @@ type ConsensusInfo interface {
 	FullSyncProgressMap() map[string]interface{}
 	SyncTargetMessageCount() arbutil.MessageIndex
 
-	// TODO: switch from pulling to pushing safe/finalized
+	// safe/finalized are kept up to date by consensus, so these don't need to reach the parent chain
 	GetSafeMsgCount(ctx context.Context) (arbutil.MessageIndex, error)
 	GetFinalizedMsgCount(ctx context.Context) (arbutil.MessageIndex, error)
 	ValidatedMessageCount() (arbutil.MessageIndex, error)
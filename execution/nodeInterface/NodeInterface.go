@@ -11,6 +11,8 @@ import (
 	"math/big"
 	"sort"
 
+	"github.com/holiman/uint256"
+
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/arbitrum"
 	"github.com/ethereum/go-ethereum/common"
@@ -18,17 +20,19 @@ import (
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/offchainlabs/nitro/arbos"
+	"github.com/offchainlabs/nitro/arbos/arbosState"
 	"github.com/offchainlabs/nitro/arbos/l1pricing"
 	"github.com/offchainlabs/nitro/arbos/retryables"
 	"github.com/offchainlabs/nitro/arbos/util"
 	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/execution/gethexec"
+	"github.com/offchainlabs/nitro/execution/outboxproof"
 	"github.com/offchainlabs/nitro/solgen/go/node_interfacegen"
 	"github.com/offchainlabs/nitro/util/arbmath"
-	"github.com/offchainlabs/nitro/util/merkletree"
 )
 
 // To avoid creating new RPC methods for client-side tooling, nitro Geth's InterceptRPCMessage() hook provides
@@ -48,10 +52,6 @@ type NodeInterface struct {
 	}
 }
 
-var merkleTopic common.Hash
-var l2ToL1TxTopic common.Hash
-var l2ToL1TransactionTopic common.Hash
-
 func (n NodeInterface) NitroGenesisBlock(c ctx) (huge, error) {
 	block := n.backend.ChainConfig().ArbitrumChainParams.GenesisBlockNum
 	return arbmath.UintToBig(block), nil
@@ -136,7 +136,12 @@ func (n NodeInterface) GetL1Confirmations(c ctx, evm mech, blockHash bytes32) (u
 	if err != nil {
 		return 0, err
 	}
+	return n.parentChainConfirmations(node, parentChainBlockNum)
+}
 
+// parentChainConfirmations returns how many confirmations a parent chain block has, recursing through
+// the parent's NodeInterface if the parent chain is itself an Arbitrum chain
+func (n NodeInterface) parentChainConfirmations(node *gethexec.ExecutionNode, parentChainBlockNum uint64) (uint64, error) {
 	if node.ParentChainReader.IsParentChainArbitrum() {
 		parentChainClient := node.ParentChainReader.Client()
 		parentNodeInterface, err := node_interfacegen.NewNodeInterface(types.NodeInterfaceAddress, parentChainClient)
@@ -177,6 +182,112 @@ func (n NodeInterface) GetL1Confirmations(c ctx, evm mech, blockHash bytes32) (u
 	return (latestBlockNum - parentChainBlockNum), nil
 }
 
+// MaxBlockRangeLookup bounds the number of blocks the range variants of the batch lookups process per call
+const MaxBlockRangeLookup = 10000
+
+// batchesForBlockRange finds the batch containing each block in [firstBlock, lastBlock] in a single pass over the
+// inbox tracker. The result stops early at the first block that isn't yet part of a batch.
+// Blocks from before the chain's genesis are attributed to the batch of the genesis message, as in GetL1Confirmations.
+func (n NodeInterface) batchesForBlockRange(firstBlock, lastBlock uint64) ([]uint64, error) {
+	if lastBlock < firstBlock {
+		return nil, errors.New("last block precedes first block")
+	}
+	if lastBlock-firstBlock >= MaxBlockRangeLookup {
+		return nil, fmt.Errorf("block range exceeds the limit of %v blocks", MaxBlockRangeLookup)
+	}
+	node, err := gethExecFromNodeInterfaceBackend(n.backend)
+	if err != nil {
+		return nil, err
+	}
+	fetcher := node.ExecEngine.GetBatchFetcher()
+	if fetcher == nil {
+		return nil, errors.New("batch fetcher not set")
+	}
+	batchCount, err := fetcher.GetBatchCount()
+	if err != nil {
+		return nil, err
+	}
+
+	batches := make([]uint64, 0, lastBlock-firstBlock+1)
+	var batch uint64
+	var batchEnd arbutil.MessageIndex // the message count of the current batch
+	haveBatch := false
+	for blockNum := firstBlock; blockNum <= lastBlock; blockNum++ {
+		msgIndex, _, err := n.blockNumToMessageIndex(blockNum)
+		if err != nil {
+			return nil, err
+		}
+		if !haveBatch {
+			var found bool
+			batch, found, err = fetcher.FindInboxBatchContainingMessage(msgIndex)
+			if err != nil || !found {
+				return batches, err
+			}
+			batchEnd, err = fetcher.GetBatchMessageCount(batch)
+			if err != nil {
+				return nil, err
+			}
+			haveBatch = true
+		}
+		for msgIndex >= batchEnd {
+			batch++
+			if batch >= batchCount {
+				return batches, nil
+			}
+			batchEnd, err = fetcher.GetBatchMessageCount(batch)
+			if err != nil {
+				return nil, err
+			}
+		}
+		batches = append(batches, batch)
+	}
+	return batches, nil
+}
+
+// FindBatchesContainingBlocks finds the batch containing each block in [firstBlock, lastBlock].
+// The result is truncated at the first block that hasn't been posted in a batch yet.
+func (n NodeInterface) FindBatchesContainingBlocks(c ctx, evm mech, firstBlock uint64, lastBlock uint64) ([]uint64, error) {
+	blockchain, err := blockchainFromNodeInterfaceBackend(n.backend)
+	if err != nil {
+		return nil, err
+	}
+	if genesis := blockchain.Config().ArbitrumChainParams.GenesisBlockNum; firstBlock < genesis {
+		return nil, fmt.Errorf("block %v is part of genesis", firstBlock)
+	}
+	return n.batchesForBlockRange(firstBlock, lastBlock)
+}
+
+// GetL1ConfirmationsForBlocks gets the L1 confirmations of each block in [firstBlock, lastBlock].
+// Blocks not yet posted in a batch have 0 confirmations, as in GetL1Confirmations.
+func (n NodeInterface) GetL1ConfirmationsForBlocks(c ctx, evm mech, firstBlock uint64, lastBlock uint64) ([]uint64, error) {
+	node, err := gethExecFromNodeInterfaceBackend(n.backend)
+	if err != nil {
+		return nil, err
+	}
+	batches, err := n.batchesForBlockRange(firstBlock, lastBlock)
+	if err != nil {
+		return nil, err
+	}
+	confirmations := make([]uint64, lastBlock-firstBlock+1)
+	confirmationsByBatch := make(map[uint64]uint64)
+	for i, batch := range batches {
+		confs, ok := confirmationsByBatch[batch]
+		if !ok {
+			parentChainBlockNum, err := node.ExecEngine.GetBatchFetcher().GetBatchParentChainBlock(batch)
+			if err != nil {
+				return nil, err
+			}
+			confs, err = n.parentChainConfirmations(node, parentChainBlockNum)
+			if err != nil {
+				return nil, err
+			}
+			confirmationsByBatch[batch] = confs
+		}
+		confirmations[i] = confs
+	}
+	return confirmations, nil
+}
+
 func (n NodeInterface) EstimateRetryableTicket(
 	c ctx,
 	evm mech,
@@ -224,257 +335,110 @@ func (n NodeInterface) EstimateRetryableTicket(
 	return nil
 }
 
-func (n NodeInterface) ConstructOutboxProof(c ctx, evm mech, size, leaf uint64) (bytes32, bytes32, []bytes32, error) {
+// retryableBaseFeeHistoryBlocks is how many recent parent chain blocks' base fees EstimateRetryableTicketDetailed
+// takes its percentile of, the most eth_feeHistory returns at once.
+const retryableBaseFeeHistoryBlocks = 1024
 
-	hash0 := bytes32{}
-
-	currentBlock := n.backend.CurrentBlock()
-	currentBlockInfo := types.DeserializeHeaderExtraInformation(currentBlock)
-	if leaf > currentBlockInfo.SendCount {
-		return hash0, hash0, nil, errors.New("leaf does not exist")
+// EstimateRetryableTicketDetailed estimates what a retryable ticket needs to succeed, so that bridges can fund it
+// without large safety factors: the submission fee the inbox will charge if the parent chain's base fee is at the
+// given percentile of its recent base fees (or is its latest block's for percentile 0), that base fee,
+// the max fee per gas the auto-redeem needs at the current L2 base fee, and the gas used by simulating the redeem
+// with the call's gas limit along with whether and why it reverts.
+func (n NodeInterface) EstimateRetryableTicketDetailed(
+	c ctx,
+	evm mech,
+	sender addr,
+	to addr,
+	l2CallValue huge,
+	data []byte,
+	l1BaseFeePercentile uint64,
+) (huge, huge, huge, uint64, bool, []byte, error) {
+	if l1BaseFeePercentile > 100 {
+		return nil, nil, nil, 0, false, nil, fmt.Errorf("invalid parent chain base fee percentile %v", l1BaseFeePercentile)
 	}
-
-	balanced := size == arbmath.NextPowerOf2(size)/2
-	// #nosec G115
-	treeLevels := int(arbmath.Log2ceil(size)) // the # of levels in the tree
-	proofLevels := treeLevels - 1             // the # of levels where a hash is needed (all but root)
-	walkLevels := treeLevels                  // the # of levels we need to consider when building walks
-	if balanced {
-		walkLevels -= 1 // skip the root
-	}
-
-	// find which nodes we'll want in our proof up to a partial
-	start := merkletree.NewLevelAndLeaf(0, leaf)
-	query := []merkletree.LevelAndLeaf{start} // the nodes we'll query for
-	nodes := []merkletree.LevelAndLeaf{}      // the nodes needed (might not be found from query)
-	which := uint64(1)                        // which bit to flip & set
-	place := leaf                             // where we are in the tree
-	for level := 0; level < walkLevels; level++ {
-		sibling := place ^ which
-		position := merkletree.NewLevelAndLeaf(uint64(level), sibling)
-
-		if sibling < size {
-			// the sibling must not be newer than the root
-			query = append(query, position)
-		}
-		nodes = append(nodes, position)
-		place |= which // set the bit so that we approach from the right
-		which <<= 1    // advance to the next bit
+	l1BaseFee, err := n.parentChainBaseFee(l1BaseFeePercentile)
+	if err != nil {
+		return nil, nil, nil, 0, false, nil, err
 	}
-
-	// find all the partials
-	partials := make(map[merkletree.LevelAndLeaf]hash)
-	if !balanced {
-		power := uint64(1) << proofLevels
-		total := uint64(0)
-		for level := proofLevels; level >= 0; level-- {
-
-			if (power & size) > 0 { // the partials map to the binary representation of the size
-
-				total += power    // The leaf for a given partial is the sum of the powers
-				leaf := total - 1 // of 2 preceding it. It's 1 less since we count from 0
-
-				partial := merkletree.NewLevelAndLeaf(uint64(level), leaf)
-
-				query = append(query, partial)
-				partials[partial] = hash0
-			}
-			power >>= 1
+	submissionFee := retryables.RetryableSubmissionFee(len(data), l1BaseFee)
+	maxFeePerGas, err := c.State.L2PricingState().BaseFeeWei()
+	if err != nil {
+		return nil, nil, nil, 0, false, nil, err
+	}
+
+	// Simulate the redeem as the ticket's aliased sender, who the retryable's escrow pays the call value to.
+	// The state is the RPC call's own copy, but is reverted anyway so later reads see it unchanged.
+	from := util.RemapL1Address(sender)
+	value, overflow := uint256.FromBig(l2CallValue)
+	if overflow {
+		return nil, nil, nil, 0, false, nil, errors.New("l2 call value too large")
+	}
+	gas := n.sourceMessage.GasLimit
+	snapshot := evm.StateDB.Snapshot()
+	evm.StateDB.AddBalance(from, value)
+	output, gasLeft, callErr := evm.Call(vm.AccountRef(from), to, data, gas, value)
+	evm.StateDB.RevertToSnapshot(snapshot)
+
+	gasUsed := gas - gasLeft
+	if callErr != nil {
+		if !errors.Is(callErr, vm.ErrExecutionReverted) {
+			output = []byte(callErr.Error())
 		}
+		return submissionFee, l1BaseFee, maxFeePerGas, gasUsed, true, output, nil
 	}
-	sort.Slice(query, func(i, j int) bool {
-		return query[i].Leaf < query[j].Leaf
-	})
+	return submissionFee, l1BaseFee, maxFeePerGas, gasUsed, false, nil, nil
+}
 
-	// collect the logs
-	var search func(lo, hi uint64, find []merkletree.LevelAndLeaf)
-	var searchLogs []*types.Log
-	var searchErr error
-	var searchPositions = make(map[hash]struct{})
-	for _, item := range query {
-		hash := common.BigToHash(item.ToBigInt())
-		searchPositions[hash] = struct{}{}
+// parentChainBaseFee returns the base fee of the parent chain's latest block for percentile 0, and otherwise the
+// percentile of its recent base fees.
+func (n NodeInterface) parentChainBaseFee(percentile uint64) (*big.Int, error) {
+	node, err := gethExecFromNodeInterfaceBackend(n.backend)
+	if err != nil {
+		return nil, err
 	}
-	search = func(lo, hi uint64, find []merkletree.LevelAndLeaf) {
-
-		mid := (lo + hi) / 2
-
-		// #nosec G115
-		block, err := n.backend.BlockByNumber(n.context, rpc.BlockNumber(mid))
+	if node.ParentChainReader == nil {
+		return nil, errors.New("no parent chain reader to read base fees from")
+	}
+	if percentile == 0 {
+		header, err := node.ParentChainReader.LastHeader(n.context)
 		if err != nil {
-			searchErr = err
-			return
-		}
-
-		if lo == hi {
-			all, err := n.backend.GetLogs(n.context, block.Hash(), block.NumberU64())
-			if err != nil {
-				searchErr = err
-				return
-			}
-			for _, tx := range all {
-				for _, log := range tx {
-					if log.Address != types.ArbSysAddress {
-						// log not produced by ArbOS
-						continue
-					}
-
-					// L2ToL1TransactionEventID is deprecated in upgrade 4, but it should to safe to make this code handle
-					// both events ignoring the version.
-					// TODO: Remove L2ToL1Transaction handling on next chain reset
-					if log.Topics[0] != merkleTopic && log.Topics[0] != l2ToL1TxTopic && log.Topics[0] != l2ToL1TransactionTopic {
-						// log is unrelated
-						continue
-					}
-
-					position := log.Topics[3]
-					if _, ok := searchPositions[position]; ok {
-						// ensure log is one we're looking for
-						searchLogs = append(searchLogs, log)
-					}
-				}
-			}
-			return
-		}
-
-		info := types.DeserializeHeaderExtraInformation(block.Header())
-
-		// Figure out which elements are above and below the midpoint
-		//   lower includes leaves older than the midpoint
-		//   upper includes leaves at least as new as the midpoint
-		//   note: while a binary search is possible here, it doesn't change the complexity
-		//
-		lower := find
-		for len(lower) > 0 && lower[len(lower)-1].Leaf >= info.SendCount {
-			lower = lower[:len(lower)-1]
-		}
-		upper := find[len(lower):]
-
-		if len(lower) > 0 {
-			search(lo, mid, lower)
+			return nil, err
 		}
-		if len(upper) > 0 {
-			search(mid+1, hi, upper)
+		if header.BaseFee == nil {
+			return nil, errors.New("parent chain header has no base fee")
 		}
+		return header.BaseFee, nil
 	}
-
-	search(0, currentBlock.Number.Uint64(), query)
-
-	if searchErr != nil {
-		return hash0, hash0, nil, searchErr
+	var history struct {
+		BaseFees []*hexutil.Big `json:"baseFeePerGas"`
 	}
-
-	known := make(map[merkletree.LevelAndLeaf]hash) // all values in the tree we know
-	partialsByLevel := make(map[uint64]hash)        // maps for each level the partial it may have
-	var minPartialPlace *merkletree.LevelAndLeaf    // the lowest-level partial
-	var send hash
-
-	for _, log := range searchLogs {
-
-		hash := log.Topics[2]
-		position := log.Topics[3]
-
-		level := new(big.Int).SetBytes(position[:8]).Uint64()
-		leafAdded := new(big.Int).SetBytes(position[8:]).Uint64()
-
-		if level == 0 && leafAdded == leaf {
-			send = hash
-		}
-
-		if level == 0 {
-			hash = crypto.Keccak256Hash(hash.Bytes())
-		}
-
-		place := merkletree.NewLevelAndLeaf(level, leafAdded)
-		known[place] = hash
-
-		if zero, ok := partials[place]; ok {
-			if zero != hash0 {
-				return hash0, hash0, nil, errors.New("internal error constructing proof: duplicate partial")
-			}
-			partials[place] = hash
-			partialsByLevel[level] = hash
-			if minPartialPlace == nil || level < minPartialPlace.Level {
-				minPartialPlace = &place
-			}
-		}
-	}
-
-	if !balanced {
-		// This tree isn't balanced, so we'll need to use the partials to recover the missing info.
-		// To do this, we'll walk the boundry of what's known, computing hashes along the way
-
-		step := *minPartialPlace
-		step.Leaf += 1 << step.Level // we start on the min partial's zero-hash sibling
-		known[step] = hash0
-
-		for step.Level < uint64(treeLevels) {
-
-			curr, ok := known[step]
-			if !ok {
-				return hash0, hash0, nil, errors.New("internal error constructing proof: bad step in walk")
-			}
-
-			left := curr
-			right := curr
-
-			if _, ok := partialsByLevel[step.Level]; ok {
-				// a partial on the frontier can only appear on the left
-				// moving leftward for a level l skips 2^l leaves
-				step.Leaf -= 1 << step.Level
-				partial, ok := known[step]
-				if !ok {
-					err := errors.New("internal error constructing proof: incomplete frontier")
-					return hash0, hash0, nil, err
-				}
-				left = partial
-			} else {
-				// getting to the next partial means covering its mirror subtree, so go right
-				// moving rightward for a level l skips 2^l leaves
-				step.Leaf += 1 << step.Level
-				known[step] = hash0
-				right = hash0
-			}
-
-			// move to the parent
-			step.Level += 1
-			step.Leaf |= 1 << (step.Level - 1)
-			known[step] = crypto.Keccak256Hash(left.Bytes(), right.Bytes())
-		}
+	err = node.ParentChainReader.Client().Client().CallContext(
+		n.context, &history, "eth_feeHistory", hexutil.Uint64(retryableBaseFeeHistoryBlocks), rpc.LatestBlockNumber, nil,
+	)
+	if err != nil {
+		return nil, err
 	}
-
-	hashes := make([]hash, len(nodes))
-	for i, place := range nodes {
-		hash, ok := known[place]
-		if !ok {
-			return hash0, hash0, nil, errors.New("internal error constructing proof: incomplete information")
+	baseFees := make([]*big.Int, 0, len(history.BaseFees))
+	for _, baseFee := range history.BaseFees {
+		if baseFee != nil {
+			baseFees = append(baseFees, baseFee.ToInt())
 		}
-		hashes[i] = hash
 	}
-
-	// recover the root and check correctness
-	recovery := crypto.Keccak256Hash(send.Bytes())
-	recoveryStep := leaf
-	for _, hash := range hashes {
-		if recoveryStep&1 == 0 {
-			recovery = crypto.Keccak256Hash(recovery.Bytes(), hash.Bytes())
-		} else {
-			recovery = crypto.Keccak256Hash(hash.Bytes(), recovery.Bytes())
-		}
-		recoveryStep >>= 1
+	if len(baseFees) == 0 {
+		return nil, errors.New("parent chain returned no base fees")
 	}
-	root := recovery
+	sort.Slice(baseFees, func(i, j int) bool { return baseFees[i].Cmp(baseFees[j]) < 0 })
+	// nearest-rank percentile
+	// #nosec G115
+	rank := arbmath.DivCeil(percentile*uint64(len(baseFees)), 100)
+	return baseFees[arbmath.MaxInt(rank, 1)-1], nil
+}
 
-	proof := merkletree.MerkleProof{
-		RootHash:  root, // now resolved
-		LeafHash:  crypto.Keccak256Hash(send.Bytes()),
-		LeafIndex: leaf,
-		Proof:     hashes,
-	}
-	if !proof.IsCorrect() {
-		return hash0, hash0, nil, errors.New("internal error constructing proof: proof is wrong")
+func (n NodeInterface) ConstructOutboxProof(c ctx, evm mech, size, leaf uint64) (bytes32, bytes32, []bytes32, error) {
+	send, root, hashes, err := outboxproof.Construct(n.context, n.backend, n.backend.CurrentBlock(), size, leaf)
+	if err != nil {
+		return bytes32{}, bytes32{}, nil, err
 	}
-
 	hashes32 := make([]bytes32, len(hashes))
 	for i, hash := range hashes {
 		hashes32[i] = hash
@@ -547,16 +511,28 @@ func (n NodeInterface) GasEstimateL1Component(
 	return gasForL1, baseFee, l1BaseFeeEstimate, nil
 }
 
-func (n NodeInterface) GasEstimateComponents(
+// gasEstimate holds the components of a gas estimate, as reported by GasEstimateComponents and
+// GasEstimateComponentsDetailed
+type gasEstimate struct {
+	total             uint64
+	gasForL1          uint64
+	baseFee           *big.Int
+	l1BaseFeeEstimate *big.Int
+	posterUnits       uint64
+	posterFee         *big.Int
+	poster            common.Address
+}
+
+func (n NodeInterface) estimateGasComponents(
 	c ctx, evm mech, value huge, to addr, contractCreation bool, data []byte,
-) (uint64, uint64, huge, huge, error) {
+) (*gasEstimate, error) {
 	if to == types.NodeInterfaceAddress || to == types.NodeInterfaceDebugAddress {
-		return 0, 0, nil, nil, errors.New("cannot estimate virtual contract")
+		return nil, errors.New("cannot estimate virtual contract")
 	}
 
 	backend, ok := n.backend.(*arbitrum.APIBackend)
 	if !ok {
-		return 0, 0, nil, nil, errors.New("failed getting API backend")
+		return nil, errors.New("failed getting API backend")
 	}
 
 	context := n.context
@@ -566,9 +542,12 @@ func (n NodeInterface) GasEstimateComponents(
 
 	totalRaw, err := arbitrum.EstimateGas(context, backend, args, block, nil, gasCap)
 	if err != nil {
-		return 0, 0, nil, nil, err
+		return nil, err
+	}
+	estimate := &gasEstimate{
+		total:  uint64(totalRaw),
+		poster: l1pricing.BatchPosterAddress,
 	}
-	total := uint64(totalRaw)
 
 	pricing := c.State.L1PricingState()
 
@@ -577,27 +556,83 @@ func (n NodeInterface) GasEstimateComponents(
 	args.Gas = &totalRaw
 	msg, err := args.ToMessage(gasCap, n.header, evm.StateDB.(*state.StateDB), core.MessageGasEstimationMode)
 	if err != nil {
-		return 0, 0, nil, nil, err
+		return nil, err
 	}
 	brotliCompressionLevel, err := c.State.BrotliCompressionLevel()
 	if err != nil {
-		return 0, 0, nil, nil, fmt.Errorf("failed to get brotli compression level: %w", err)
+		return nil, fmt.Errorf("failed to get brotli compression level: %w", err)
 	}
-	feeForL1, _ := pricing.PosterDataCost(msg, l1pricing.BatchPosterAddress, brotliCompressionLevel)
+	estimate.posterFee, estimate.posterUnits = pricing.PosterDataCost(msg, estimate.poster, brotliCompressionLevel)
 
-	baseFee, err := c.State.L2PricingState().BaseFeeWei()
+	estimate.baseFee, err = c.State.L2PricingState().BaseFeeWei()
 	if err != nil {
-		return 0, 0, nil, nil, err
+		return nil, err
 	}
-	l1BaseFeeEstimate, err := pricing.PricePerUnit()
+	estimate.l1BaseFeeEstimate, err = pricing.PricePerUnit()
 	if err != nil {
-		return 0, 0, nil, nil, err
+		return nil, err
 	}
 
 	// Compute the fee paid for L1 in L2 terms
-	gasForL1 := arbos.GetPosterGas(c.State, baseFee, core.MessageGasEstimationMode, feeForL1)
+	estimate.gasForL1 = arbos.GetPosterGas(c.State, estimate.baseFee, core.MessageGasEstimationMode, estimate.posterFee)
+	return estimate, nil
+}
+
+func (n NodeInterface) GasEstimateComponents(
+	c ctx, evm mech, value huge, to addr, contractCreation bool, data []byte,
+) (uint64, uint64, huge, huge, error) {
+	estimate, err := n.estimateGasComponents(c, evm, value, to, contractCreation, data)
+	if err != nil {
+		return 0, 0, nil, nil, err
+	}
+	return estimate.total, estimate.gasForL1, estimate.baseFee, estimate.l1BaseFeeEstimate, nil
+}
+
+// GasEstimateComponentsDetailed extends GasEstimateComponents with the inputs of the L1 fee, so that clients can
+// display a breakdown and recompute it: the padded calldata units charged for, the L1 fee in wei before the
+// estimation padding applied to gasEstimateForL1, and the batch poster the fee was computed for.
+func (n NodeInterface) GasEstimateComponentsDetailed(
+	c ctx, evm mech, value huge, to addr, contractCreation bool, data []byte,
+) (uint64, uint64, huge, huge, uint64, huge, addr, error) {
+	estimate, err := n.estimateGasComponents(c, evm, value, to, contractCreation, data)
+	if err != nil {
+		return 0, 0, nil, nil, 0, nil, addr{}, err
+	}
+	return estimate.total, estimate.gasForL1, estimate.baseFee, estimate.l1BaseFeeEstimate,
+		estimate.posterUnits, estimate.posterFee, estimate.poster, nil
+}
 
-	return total, gasForL1, baseFee, l1BaseFeeEstimate, nil
+// EstimateL1Component sizes a transaction with the given calldata the way ArbOS will when charging for posting it,
+// without executing anything: the padded calldata units, their L1 fee at the current price per unit, and the L2 gas
+// the transaction buys at the current base fee to pay that fee along with any calldata surcharge the chain levies.
+func (n NodeInterface) EstimateL1Component(c ctx, evm mech, data []byte) (uint64, huge, uint64, error) {
+	brotliCompressionLevel, err := c.State.BrotliCompressionLevel()
+	if err != nil {
+		return 0, nil, 0, fmt.Errorf("failed to get brotli compression level: %w", err)
+	}
+	units, err := l1pricing.EstimateCalldataPosterUnits(data, brotliCompressionLevel)
+	if err != nil {
+		return 0, nil, 0, err
+	}
+	pricePerUnit, err := c.State.L1PricingState().PricePerUnit()
+	if err != nil {
+		return 0, nil, 0, err
+	}
+	baseFee, err := c.State.L2PricingState().BaseFeeWei()
+	if err != nil {
+		return 0, nil, 0, err
+	}
+	posterFee := arbmath.BigMulByUint(pricePerUnit, units)
+	gasForL1 := arbos.GetPosterGas(c.State, baseFee, core.MessageGasEstimationMode, posterFee)
+	if c.State.ArbOSVersion() >= arbosState.ArbosVersion_ChainTxLimits {
+		surcharge, err := c.State.L1PricingState().CalldataSurcharge(posterFee)
+		if err != nil {
+			return 0, nil, 0, err
+		}
+		surchargeGas := arbos.GetPosterGas(c.State, baseFee, core.MessageGasEstimationMode, surcharge)
+		gasForL1 = arbmath.SaturatingUAdd(gasForL1, surchargeGas)
+	}
+	return units, posterFee, gasForL1, nil
 }
 
 func (n NodeInterface) LegacyLookupMessageBatchProof(c ctx, evm mech, batchNum huge, index uint64) (
@@ -22,7 +22,6 @@ import (
 	"github.com/offchainlabs/nitro/gethhook"
 	"github.com/offchainlabs/nitro/precompiles"
 	"github.com/offchainlabs/nitro/solgen/go/node_interfacegen"
-	"github.com/offchainlabs/nitro/solgen/go/precompilesgen"
 	"github.com/offchainlabs/nitro/util/arbmath"
 )
 
@@ -164,14 +163,6 @@ func init() {
 		}
 		return speedLimit, nil
 	}
-
-	arbSys, err := precompilesgen.ArbSysMetaData.GetAbi()
-	if err != nil {
-		panic(err)
-	}
-	l2ToL1TxTopic = arbSys.Events["L2ToL1Tx"].ID
-	l2ToL1TransactionTopic = arbSys.Events["L2ToL1Transaction"].ID
-	merkleTopic = arbSys.Events["SendMerkleUpdate"].ID
 }
 
 func gethExecFromNodeInterfaceBackend(backend BackendAPI) (*gethexec.ExecutionNode, error) {
@@ -0,0 +1,438 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+// Package outboxproof constructs the Merkle proofs needed to execute L2 to L1 messages in the
+// parent chain's Outbox, using only the send merkle accumulator events in the node's own logs.
+package outboxproof
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/offchainlabs/nitro/arbos/util"
+	"github.com/offchainlabs/nitro/solgen/go/bridgegen"
+	"github.com/offchainlabs/nitro/solgen/go/precompilesgen"
+	"github.com/offchainlabs/nitro/util/arbmath"
+	"github.com/offchainlabs/nitro/util/merkletree"
+)
+
+var merkleTopic common.Hash
+var l2ToL1TxTopic common.Hash
+var l2ToL1TransactionTopic common.Hash
+
+func init() {
+	arbSys, err := precompilesgen.ArbSysMetaData.GetAbi()
+	if err != nil {
+		panic(err)
+	}
+	l2ToL1TxTopic = arbSys.Events["L2ToL1Tx"].ID
+	l2ToL1TransactionTopic = arbSys.Events["L2ToL1Transaction"].ID
+	merkleTopic = arbSys.Events["SendMerkleUpdate"].ID
+}
+
+// Backend provides the blocks and logs the proof is built from.
+type Backend interface {
+	BlockByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Block, error)
+	GetLogs(ctx context.Context, blockHash common.Hash, number uint64) ([][]*types.Log, error)
+}
+
+type blockChainBackend struct {
+	bc *core.BlockChain
+}
+
+// NewBlockChainBackend serves proof construction directly from a local blockchain.
+func NewBlockChainBackend(bc *core.BlockChain) Backend {
+	return &blockChainBackend{bc}
+}
+
+func (b *blockChainBackend) BlockByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Block, error) {
+	if number < 0 {
+		return nil, fmt.Errorf("unsupported block number %v", number)
+	}
+	block := b.bc.GetBlockByNumber(uint64(number))
+	if block == nil {
+		return nil, fmt.Errorf("block %v not found", number)
+	}
+	return block, nil
+}
+
+func (b *blockChainBackend) GetLogs(ctx context.Context, blockHash common.Hash, number uint64) ([][]*types.Log, error) {
+	receipts := b.bc.GetReceiptsByHash(blockHash)
+	logs := make([][]*types.Log, len(receipts))
+	for i, receipt := range receipts {
+		logs[i] = receipt.Logs
+	}
+	return logs, nil
+}
+
+// Construct builds the proof that leaf is part of the send merkle tree once it reached size sends,
+// searching the blocks up to current for the accumulator's events. It returns the leaf's send hash,
+// the root of the tree and the sibling hashes from the leaf upwards.
+func Construct(ctx context.Context, backend Backend, current *types.Header, size, leaf uint64) (common.Hash, common.Hash, []common.Hash, error) {
+
+	hash0 := common.Hash{}
+
+	currentBlockInfo := types.DeserializeHeaderExtraInformation(current)
+	if leaf > currentBlockInfo.SendCount {
+		return hash0, hash0, nil, errors.New("leaf does not exist")
+	}
+
+	balanced := size == arbmath.NextPowerOf2(size)/2
+	// #nosec G115
+	treeLevels := int(arbmath.Log2ceil(size)) // the # of levels in the tree
+	proofLevels := treeLevels - 1             // the # of levels where a hash is needed (all but root)
+	walkLevels := treeLevels                  // the # of levels we need to consider when building walks
+	if balanced {
+		walkLevels -= 1 // skip the root
+	}
+
+	// find which nodes we'll want in our proof up to a partial
+	start := merkletree.NewLevelAndLeaf(0, leaf)
+	query := []merkletree.LevelAndLeaf{start} // the nodes we'll query for
+	nodes := []merkletree.LevelAndLeaf{}      // the nodes needed (might not be found from query)
+	which := uint64(1)                        // which bit to flip & set
+	place := leaf                             // where we are in the tree
+	for level := 0; level < walkLevels; level++ {
+		sibling := place ^ which
+		position := merkletree.NewLevelAndLeaf(uint64(level), sibling)
+
+		if sibling < size {
+			// the sibling must not be newer than the root
+			query = append(query, position)
+		}
+		nodes = append(nodes, position)
+		place |= which // set the bit so that we approach from the right
+		which <<= 1    // advance to the next bit
+	}
+
+	// find all the partials
+	partials := make(map[merkletree.LevelAndLeaf]common.Hash)
+	if !balanced {
+		power := uint64(1) << proofLevels
+		total := uint64(0)
+		for level := proofLevels; level >= 0; level-- {
+
+			if (power & size) > 0 { // the partials map to the binary representation of the size
+
+				total += power    // The leaf for a given partial is the sum of the powers
+				leaf := total - 1 // of 2 preceding it. It's 1 less since we count from 0
+
+				partial := merkletree.NewLevelAndLeaf(uint64(level), leaf)
+
+				query = append(query, partial)
+				partials[partial] = hash0
+			}
+			power >>= 1
+		}
+	}
+	sort.Slice(query, func(i, j int) bool {
+		return query[i].Leaf < query[j].Leaf
+	})
+
+	// collect the logs
+	var search func(lo, hi uint64, find []merkletree.LevelAndLeaf)
+	var searchLogs []*types.Log
+	var searchErr error
+	var searchPositions = make(map[common.Hash]struct{})
+	for _, item := range query {
+		hash := common.BigToHash(item.ToBigInt())
+		searchPositions[hash] = struct{}{}
+	}
+	search = func(lo, hi uint64, find []merkletree.LevelAndLeaf) {
+
+		mid := (lo + hi) / 2
+
+		// #nosec G115
+		block, err := backend.BlockByNumber(ctx, rpc.BlockNumber(mid))
+		if err != nil {
+			searchErr = err
+			return
+		}
+
+		if lo == hi {
+			all, err := backend.GetLogs(ctx, block.Hash(), block.NumberU64())
+			if err != nil {
+				searchErr = err
+				return
+			}
+			for _, tx := range all {
+				for _, log := range tx {
+					if log.Address != types.ArbSysAddress {
+						// log not produced by ArbOS
+						continue
+					}
+
+					// L2ToL1TransactionEventID is deprecated in upgrade 4, but it should to safe to make this code handle
+					// both events ignoring the version.
+					// TODO: Remove L2ToL1Transaction handling on next chain reset
+					if log.Topics[0] != merkleTopic && log.Topics[0] != l2ToL1TxTopic && log.Topics[0] != l2ToL1TransactionTopic {
+						// log is unrelated
+						continue
+					}
+
+					position := log.Topics[3]
+					if _, ok := searchPositions[position]; ok {
+						// ensure log is one we're looking for
+						searchLogs = append(searchLogs, log)
+					}
+				}
+			}
+			return
+		}
+
+		info := types.DeserializeHeaderExtraInformation(block.Header())
+
+		// Figure out which elements are above and below the midpoint
+		//   lower includes leaves older than the midpoint
+		//   upper includes leaves at least as new as the midpoint
+		//   note: while a binary search is possible here, it doesn't change the complexity
+		//
+		lower := find
+		for len(lower) > 0 && lower[len(lower)-1].Leaf >= info.SendCount {
+			lower = lower[:len(lower)-1]
+		}
+		upper := find[len(lower):]
+
+		if len(lower) > 0 {
+			search(lo, mid, lower)
+		}
+		if len(upper) > 0 {
+			search(mid+1, hi, upper)
+		}
+	}
+
+	search(0, current.Number.Uint64(), query)
+
+	if searchErr != nil {
+		return hash0, hash0, nil, searchErr
+	}
+
+	known := make(map[merkletree.LevelAndLeaf]common.Hash) // all values in the tree we know
+	partialsByLevel := make(map[uint64]common.Hash)        // maps for each level the partial it may have
+	var minPartialPlace *merkletree.LevelAndLeaf           // the lowest-level partial
+	var send common.Hash
+
+	for _, log := range searchLogs {
+
+		hash := log.Topics[2]
+		position := log.Topics[3]
+
+		level := new(big.Int).SetBytes(position[:8]).Uint64()
+		leafAdded := new(big.Int).SetBytes(position[8:]).Uint64()
+
+		if level == 0 && leafAdded == leaf {
+			send = hash
+		}
+
+		if level == 0 {
+			hash = crypto.Keccak256Hash(hash.Bytes())
+		}
+
+		place := merkletree.NewLevelAndLeaf(level, leafAdded)
+		known[place] = hash
+
+		if zero, ok := partials[place]; ok {
+			if zero != hash0 {
+				return hash0, hash0, nil, errors.New("internal error constructing proof: duplicate partial")
+			}
+			partials[place] = hash
+			partialsByLevel[level] = hash
+			if minPartialPlace == nil || level < minPartialPlace.Level {
+				minPartialPlace = &place
+			}
+		}
+	}
+
+	if !balanced {
+		// This tree isn't balanced, so we'll need to use the partials to recover the missing info.
+		// To do this, we'll walk the boundry of what's known, computing hashes along the way
+
+		step := *minPartialPlace
+		step.Leaf += 1 << step.Level // we start on the min partial's zero-hash sibling
+		known[step] = hash0
+
+		for step.Level < uint64(treeLevels) {
+
+			curr, ok := known[step]
+			if !ok {
+				return hash0, hash0, nil, errors.New("internal error constructing proof: bad step in walk")
+			}
+
+			left := curr
+			right := curr
+
+			if _, ok := partialsByLevel[step.Level]; ok {
+				// a partial on the frontier can only appear on the left
+				// moving leftward for a level l skips 2^l leaves
+				step.Leaf -= 1 << step.Level
+				partial, ok := known[step]
+				if !ok {
+					err := errors.New("internal error constructing proof: incomplete frontier")
+					return hash0, hash0, nil, err
+				}
+				left = partial
+			} else {
+				// getting to the next partial means covering its mirror subtree, so go right
+				// moving rightward for a level l skips 2^l leaves
+				step.Leaf += 1 << step.Level
+				known[step] = hash0
+				right = hash0
+			}
+
+			// move to the parent
+			step.Level += 1
+			step.Leaf |= 1 << (step.Level - 1)
+			known[step] = crypto.Keccak256Hash(left.Bytes(), right.Bytes())
+		}
+	}
+
+	hashes := make([]common.Hash, len(nodes))
+	for i, place := range nodes {
+		hash, ok := known[place]
+		if !ok {
+			return hash0, hash0, nil, errors.New("internal error constructing proof: incomplete information")
+		}
+		hashes[i] = hash
+	}
+
+	// recover the root and check correctness
+	recovery := crypto.Keccak256Hash(send.Bytes())
+	recoveryStep := leaf
+	for _, hash := range hashes {
+		if recoveryStep&1 == 0 {
+			recovery = crypto.Keccak256Hash(recovery.Bytes(), hash.Bytes())
+		} else {
+			recovery = crypto.Keccak256Hash(hash.Bytes(), recovery.Bytes())
+		}
+		recoveryStep >>= 1
+	}
+	root := recovery
+
+	proof := merkletree.MerkleProof{
+		RootHash:  root, // now resolved
+		LeafHash:  crypto.Keccak256Hash(send.Bytes()),
+		LeafIndex: leaf,
+		Proof:     hashes,
+	}
+	if !proof.IsCorrect() {
+		return hash0, hash0, nil, errors.New("internal error constructing proof: proof is wrong")
+	}
+
+	return send, root, hashes, nil
+}
+
+// OutboxProof holds the arguments of Outbox.executeTransaction for one L2 to L1 message.
+type OutboxProof struct {
+	Proof       []common.Hash
+	Index       uint64
+	L2Sender    common.Address
+	To          common.Address
+	L2Block     *big.Int
+	L1Block     *big.Int
+	L2Timestamp *big.Int
+	Value       *big.Int
+	Data        []byte
+
+	// The send root the proof is against, and the number of sends it covers. The root must be
+	// confirmed in the rollup before the message can be executed.
+	SendRoot common.Hash
+	Size     uint64
+}
+
+// ExecuteTransactionCalldata returns the calldata for Outbox.executeTransaction.
+func (p *OutboxProof) ExecuteTransactionCalldata() ([]byte, error) {
+	outboxABI, err := bridgegen.OutboxMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	proof := make([][32]byte, len(p.Proof))
+	for i, hash := range p.Proof {
+		proof[i] = hash
+	}
+	return outboxABI.Pack(
+		"executeTransaction",
+		proof,
+		new(big.Int).SetUint64(p.Index),
+		p.L2Sender,
+		p.To,
+		p.L2Block,
+		p.L1Block,
+		p.L2Timestamp,
+		p.Value,
+		p.Data,
+	)
+}
+
+// L2ToL1Txs returns the L2ToL1Tx events emitted by the transaction of receipt.
+func L2ToL1Txs(receipt *types.Receipt) []*types.Log {
+	var sends []*types.Log
+	for _, log := range receipt.Logs {
+		if log.Address == types.ArbSysAddress && len(log.Topics) > 0 && log.Topics[0] == l2ToL1TxTopic {
+			sends = append(sends, log)
+		}
+	}
+	return sends
+}
+
+// ForReceipt builds the proof for the sendIndex'th L2 to L1 message sent by the transaction of
+// receipt, against the send root of the block root. The root block must not be older than the
+// transaction's block.
+func ForReceipt(ctx context.Context, backend Backend, receipt *types.Receipt, sendIndex int, root *types.Header) (*OutboxProof, error) {
+	sends := L2ToL1Txs(receipt)
+	if len(sends) == 0 {
+		return nil, fmt.Errorf("transaction %v sent no L2 to L1 messages", receipt.TxHash)
+	}
+	if sendIndex < 0 || sendIndex >= len(sends) {
+		return nil, fmt.Errorf("transaction %v sent %d L2 to L1 messages, message %d requested", receipt.TxHash, len(sends), sendIndex)
+	}
+	return ForSend(ctx, backend, sends[sendIndex], root)
+}
+
+// ForSend builds the proof for the L2 to L1 message of an L2ToL1Tx event, against the send root
+// of the block root. The root block must not be older than the event's block.
+func ForSend(ctx context.Context, backend Backend, sendLog *types.Log, root *types.Header) (*OutboxProof, error) {
+	event, err := util.ParseL2ToL1TxLog(sendLog)
+	if err != nil {
+		return nil, err
+	}
+	if !event.Position.IsUint64() {
+		return nil, fmt.Errorf("invalid L2 to L1 message position %v", event.Position)
+	}
+	leaf := event.Position.Uint64()
+	rootInfo := types.DeserializeHeaderExtraInformation(root)
+	if leaf >= rootInfo.SendCount {
+		return nil, fmt.Errorf("message %d isn't included in the send root of block %v, which has %d sends", leaf, root.Number, rootInfo.SendCount)
+	}
+	send, sendRoot, proof, err := Construct(ctx, backend, root, rootInfo.SendCount, leaf)
+	if err != nil {
+		return nil, err
+	}
+	if send != common.BigToHash(event.Hash) {
+		return nil, fmt.Errorf("proof is for send %v but the event has %v", send, common.BigToHash(event.Hash))
+	}
+	if sendRoot != rootInfo.SendRoot {
+		return nil, fmt.Errorf("constructed send root %v doesn't match block %v send root %v", sendRoot, root.Number, rootInfo.SendRoot)
+	}
+	return &OutboxProof{
+		Proof:       proof,
+		Index:       leaf,
+		L2Sender:    event.Caller,
+		To:          event.Destination,
+		L2Block:     event.ArbBlockNum,
+		L1Block:     event.EthBlockNum,
+		L2Timestamp: event.Timestamp,
+		Value:       event.Callvalue,
+		Data:        event.Data,
+		SendRoot:    sendRoot,
+		Size:        rootInfo.SendCount,
+	}, nil
+}
@@ -0,0 +1,130 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package outboxproof
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/offchainlabs/nitro/arbos/merkleAccumulator"
+	"github.com/offchainlabs/nitro/solgen/go/precompilesgen"
+	"github.com/offchainlabs/nitro/util/merkletree"
+	"github.com/offchainlabs/nitro/util/testhelpers"
+)
+
+// fakeBackend holds one block per send, with block i+1 containing send i.
+type fakeBackend struct {
+	blocks []*types.Block
+	logs   map[common.Hash][][]*types.Log
+}
+
+func (b *fakeBackend) BlockByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Block, error) {
+	if number < 0 || int(number) >= len(b.blocks) {
+		return nil, fmt.Errorf("block %v not found", number)
+	}
+	return b.blocks[number], nil
+}
+
+func (b *fakeBackend) GetLogs(ctx context.Context, blockHash common.Hash, number uint64) ([][]*types.Log, error) {
+	return b.logs[blockHash], nil
+}
+
+func positionTopic(level, leaf uint64) common.Hash {
+	return common.BigToHash(merkletree.NewLevelAndLeaf(level, leaf).ToBigInt())
+}
+
+func newFakeBackend(t *testing.T, sends []common.Hash) (*fakeBackend, []*types.Receipt) {
+	arbSys, err := precompilesgen.ArbSysMetaData.GetAbi()
+	testhelpers.RequireImpl(t, err)
+	acc := merkleAccumulator.NewNonpersistentMerkleAccumulator()
+	backend := &fakeBackend{logs: make(map[common.Hash][][]*types.Log)}
+	var receipts []*types.Receipt
+
+	addBlock := func(logs []*types.Log) {
+		root, err := acc.Root()
+		testhelpers.RequireImpl(t, err)
+		size, err := acc.Size()
+		testhelpers.RequireImpl(t, err)
+		header := &types.Header{
+			Number:     big.NewInt(int64(len(backend.blocks))),
+			BaseFee:    big.NewInt(1),
+			Difficulty: big.NewInt(1),
+		}
+		info := types.HeaderInfo{SendRoot: root, SendCount: size}
+		info.UpdateHeaderWithInfo(header)
+		block := types.NewBlockWithHeader(header)
+		backend.blocks = append(backend.blocks, block)
+		backend.logs[block.Hash()] = [][]*types.Log{logs}
+	}
+	addBlock(nil)
+
+	for i, send := range sends {
+		events, err := acc.Append(send)
+		testhelpers.RequireImpl(t, err)
+		var logs []*types.Log
+		for _, event := range events {
+			logs = append(logs, &types.Log{
+				Address: types.ArbSysAddress,
+				Topics:  []common.Hash{merkleTopic, {}, event.Hash, positionTopic(event.Level, event.NumLeaves)},
+			})
+		}
+		data, err := arbSys.Events["L2ToL1Tx"].Inputs.NonIndexed().Pack(
+			common.HexToAddress("0xca11e4"), big.NewInt(int64(i+1)), big.NewInt(100), big.NewInt(1000), big.NewInt(7), []byte{0xde, 0xad},
+		)
+		testhelpers.RequireImpl(t, err)
+		sendLog := &types.Log{
+			Address: types.ArbSysAddress,
+			Topics:  []common.Hash{l2ToL1TxTopic, common.HexToHash("0xde57"), send, positionTopic(0, uint64(i))},
+			Data:    data,
+		}
+		logs = append(logs, sendLog)
+		receipts = append(receipts, &types.Receipt{TxHash: common.BigToHash(big.NewInt(int64(i))), Logs: []*types.Log{sendLog}})
+		addBlock(logs)
+	}
+	return backend, receipts
+}
+
+func TestConstructOutboxProof(t *testing.T) {
+	ctx := context.Background()
+	for _, numSends := range []int{2, 5, 8, 11} {
+		sends := make([]common.Hash, numSends)
+		for i := range sends {
+			sends[i] = common.BigToHash(big.NewInt(int64(1000 + i)))
+		}
+		backend, receipts := newFakeBackend(t, sends)
+		head := backend.blocks[len(backend.blocks)-1].Header()
+		headInfo := types.DeserializeHeaderExtraInformation(head)
+		for leaf := range sends {
+			send, root, _, err := Construct(ctx, backend, head, uint64(numSends), uint64(leaf))
+			testhelpers.RequireImpl(t, err)
+			if send != sends[leaf] {
+				t.Fatal("wrong send for leaf", leaf, "of", numSends)
+			}
+			if root != headInfo.SendRoot {
+				t.Fatal("wrong root for leaf", leaf, "of", numSends)
+			}
+
+			proof, err := ForReceipt(ctx, backend, receipts[leaf], 0, head)
+			testhelpers.RequireImpl(t, err)
+			if proof.Index != uint64(leaf) || proof.SendRoot != headInfo.SendRoot || proof.Size != uint64(numSends) {
+				t.Fatal("unexpected proof", proof)
+			}
+			if proof.L2Block.Int64() != int64(leaf+1) || proof.Value.Int64() != 7 || proof.To != common.HexToAddress("0xde57") {
+				t.Fatal("unexpected message fields", proof)
+			}
+			if _, err := proof.ExecuteTransactionCalldata(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := ForReceipt(ctx, backend, receipts[0], 1, head); err == nil {
+			t.Fatal("expected error for missing send index")
+		}
+	}
+}
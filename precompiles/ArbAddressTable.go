@@ -11,6 +11,10 @@ import (
 // ArbAddressTable precompile provides the ability to create short-hands for commonly used accounts.
 type ArbAddressTable struct {
 	Address addr // 0x66
+
+	InvalidOffsetError   func(huge) error
+	AddressNotFoundError func(addr) error
+	IndexNotFoundError   func(huge) error
 }
 
 // AddressExists checks if an address exists in the table
@@ -26,11 +30,11 @@ func (con ArbAddressTable) Compress(c ctx, evm mech, addr addr) ([]uint8, error)
 // Decompress the compressed bytes at the given offset with those of the corresponding account
 func (con ArbAddressTable) Decompress(c ctx, evm mech, buf []uint8, offset huge) (addr, huge, error) {
 	if !offset.IsInt64() {
-		return addr{}, nil, errors.New("invalid offset in ArbAddressTable.Decompress")
+		return addr{}, nil, typedRevert(c, con.InvalidOffsetError(offset), errors.New("invalid offset in ArbAddressTable.Decompress"))
 	}
 	ioffset := offset.Int64()
 	if ioffset > int64(len(buf)) {
-		return addr{}, nil, errors.New("invalid offset in ArbAddressTable.Decompress")
+		return addr{}, nil, typedRevert(c, con.InvalidOffsetError(offset), errors.New("invalid offset in ArbAddressTable.Decompress"))
 	}
 	result, nbytes, err := c.State.AddressTable().Decompress(buf[ioffset:])
 	return result, new(big.Int).SetUint64(nbytes), err
@@ -43,7 +47,7 @@ func (con ArbAddressTable) Lookup(c ctx, evm mech, addr addr) (huge, error) {
 		return nil, err
 	}
 	if !exists {
-		return nil, errors.New("address does not exist in AddressTable")
+		return nil, typedRevert(c, con.AddressNotFoundError(addr), errors.New("address does not exist in AddressTable"))
 	}
 	return new(big.Int).SetUint64(result), nil
 }
@@ -51,14 +55,14 @@ func (con ArbAddressTable) Lookup(c ctx, evm mech, addr addr) (huge, error) {
 // LookupIndex for  an address in the table by index
 func (con ArbAddressTable) LookupIndex(c ctx, evm mech, index huge) (addr, error) {
 	if !index.IsUint64() {
-		return addr{}, errors.New("invalid index in ArbAddressTable.LookupIndex")
+		return addr{}, typedRevert(c, con.IndexNotFoundError(index), errors.New("invalid index in ArbAddressTable.LookupIndex"))
 	}
 	result, exists, err := c.State.AddressTable().LookupIndex(index.Uint64())
 	if err != nil {
 		return addr{}, err
 	}
 	if !exists {
-		return addr{}, errors.New("index does not exist in AddressTable")
+		return addr{}, typedRevert(c, con.IndexNotFoundError(index), errors.New("index does not exist in AddressTable"))
 	}
 	return result, nil
 }
@@ -10,6 +10,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/params"
@@ -20,7 +21,7 @@ import (
 
 func TestArbAddressTableInit(t *testing.T) {
 	evm := newMockEVMForTesting()
-	atab := ArbAddressTable{}
+	atab := precompileForTesting[ArbAddressTable](types.ArbAddressTableAddress)
 	context := testContext(common.Address{}, evm)
 
 	size, err := atab.Size(context, evm)
@@ -42,7 +43,7 @@ func TestArbAddressTableInit(t *testing.T) {
 
 func TestAddressTable1(t *testing.T) {
 	evm := newMockEVMForTesting()
-	atab := ArbAddressTable{}
+	atab := precompileForTesting[ArbAddressTable](types.ArbAddressTableAddress)
 	context := testContext(common.Address{}, evm)
 
 	addr := common.BytesToAddress(crypto.Keccak256([]byte{})[:20])
@@ -150,6 +151,12 @@ func TestAddressTableCompressInTable(t *testing.T) {
 	}
 }
 
+// precompileForTesting returns the implementer of the precompile at the address, whose event and error fields are set
+func precompileForTesting[T any](address common.Address) *T {
+	//nolint:errcheck
+	return Precompiles()[address].Precompile().implementer.Interface().(*T)
+}
+
 func newMockEVMForTesting() *vm.EVM {
 	return newMockEVMForTestingWithVersion(nil)
 }
@@ -7,27 +7,54 @@ import (
 	"errors"
 	"math/big"
 
+	"github.com/ethereum/go-ethereum/metrics"
+
+	"github.com/offchainlabs/nitro/arbos/arbosState"
 	"github.com/offchainlabs/nitro/arbos/l1pricing"
 )
 
 // ArbAggregator provides aggregators and their users methods for configuring how they participate in L1 aggregation.
 // Arbitrum One's default aggregator is the Sequencer, which a user will prefer unless SetPreferredAggregator()
 // is invoked to change it.
+// Once the chain owner enables the aggregator deprecation feature, the legacy aggregator methods return zero values
+// and nodes count their calls in metrics, so remaining usage can be measured before they're removed.
 type ArbAggregator struct {
 	Address addr // 0x6d
+
+	NotChainOwnerError                func(addr) error
+	NotBatchPosterOrFeeCollectorError func(addr) error
 }
 
 var ErrNotOwner = errors.New("must be called by chain owner")
 
+var legacyAggregatorCallsCounter = metrics.NewRegisteredCounter("arb/precompiles/aggregator/legacy_calls", nil)
+
+// legacyCall returns whether a legacy method should return zero values, which it does once the aggregator
+// deprecation feature is enabled. Those calls are counted in the node's metrics rather than in ArbOS, as most
+// legacy methods are views, which can't write state.
+func (con ArbAggregator) legacyCall(c ctx) bool {
+	if !c.State.FeatureEnabled(arbosState.FeatureAggregatorDeprecation) {
+		return false
+	}
+	legacyAggregatorCallsCounter.Inc(1)
+	return true
+}
+
 // GetPreferredAggregator returns the preferred aggregator address.
 // Deprecated: Do not use this method.
 func (con ArbAggregator) GetPreferredAggregator(c ctx, evm mech, address addr) (prefAgg addr, isDefault bool, err error) {
-	return l1pricing.BatchPosterAddress, true, err
+	if con.legacyCall(c) {
+		return addr{}, false, nil
+	}
+	return l1pricing.BatchPosterAddress, true, nil
 }
 
 // GetDefaultAggregator returns the default aggregator address.
 // Deprecated: Do not use this method.
 func (con ArbAggregator) GetDefaultAggregator(c ctx, evm mech) (addr, error) {
+	if con.legacyCall(c) {
+		return addr{}, nil
+	}
 	return l1pricing.BatchPosterAddress, nil
 }
 
@@ -36,13 +63,32 @@ func (con ArbAggregator) GetBatchPosters(c ctx, evm mech) ([]addr, error) {
 	return c.State.L1PricingState().BatchPosterTable().AllPosters(65536)
 }
 
+// GetBatchPostersInfo gets every batch poster with its fee collector, the funds it's due, and its reimbursement cap in bips
+func (con ArbAggregator) GetBatchPostersInfo(c ctx, evm mech) ([]addr, []addr, []huge, []uint64, error) {
+	infos, err := c.State.L1PricingState().BatchPosterTable().AllPosterInfo(65536)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	posters := make([]addr, len(infos))
+	feeCollectors := make([]addr, len(infos))
+	fundsDue := make([]huge, len(infos))
+	caps := make([]uint64, len(infos))
+	for i, info := range infos {
+		posters[i] = info.Poster
+		feeCollectors[i] = info.PayTo
+		fundsDue[i] = info.FundsDue
+		caps[i] = info.ReimbursementCapBips
+	}
+	return posters, feeCollectors, fundsDue, caps, nil
+}
+
 func (con ArbAggregator) AddBatchPoster(c ctx, evm mech, newBatchPoster addr) error {
 	isOwner, err := c.State.ChainOwners().IsMember(c.caller)
 	if err != nil {
 		return err
 	}
 	if !isOwner {
-		return ErrNotOwner
+		return typedRevert(c, con.NotChainOwnerError(c.caller), ErrNotOwner)
 	}
 	batchPosterTable := c.State.L1PricingState().BatchPosterTable()
 	isBatchPoster, err := batchPosterTable.ContainsPoster(newBatchPoster)
@@ -83,7 +129,8 @@ func (con ArbAggregator) SetFeeCollector(c ctx, evm mech, batchPoster addr, newF
 			return err
 		}
 		if !isOwner {
-			return errors.New("only a batch poster (or its fee collector / chain owner) may change its fee collector")
+			legacy := errors.New("only a batch poster (or its fee collector / chain owner) may change its fee collector")
+			return typedRevert(c, con.NotBatchPosterOrFeeCollectorError(c.caller), legacy)
 		}
 	}
 	return posterInfo.SetPayTo(newFeeCollector)
@@ -92,11 +139,32 @@ func (con ArbAggregator) SetFeeCollector(c ctx, evm mech, batchPoster addr, newF
 // GetTxBaseFee gets an aggregator's current fixed fee to submit a tx
 func (con ArbAggregator) GetTxBaseFee(c ctx, evm mech, aggregator addr) (huge, error) {
 	// This is deprecated and now always returns zero.
+	con.legacyCall(c)
 	return big.NewInt(0), nil
 }
 
 // SetTxBaseFee sets an aggregator's fixed fee (caller must be the aggregator, its fee collector, or an owner)
 func (con ArbAggregator) SetTxBaseFee(c ctx, evm mech, aggregator addr, feeInL1Gas huge) error {
 	// This is deprecated and is now a no-op.
+	con.legacyCall(c)
 	return nil
 }
+
+// GetRewardRecipients gets the addresses the batch poster reward is split among and their weights in basis points.
+// If there are none, the whole reward goes to the L1 reward recipient.
+func (con ArbAggregator) GetRewardRecipients(c ctx, evm mech) ([]addr, []uint64, error) {
+	return c.State.L1PricingState().RewardSplits().Recipients()
+}
+
+// SetRewardRecipients splits the batch poster reward among recipients by weights in basis points summing to 10000.
+// Passing empty arrays pays the whole reward to the L1 reward recipient again. Caller must be an owner.
+func (con ArbAggregator) SetRewardRecipients(c ctx, evm mech, recipients []addr, weightsBips []uint64) error {
+	isOwner, err := c.State.ChainOwners().IsMember(c.caller)
+	if err != nil {
+		return err
+	}
+	if !isOwner {
+		return typedRevert(c, con.NotChainOwnerError(c.caller), ErrNotOwner)
+	}
+	return c.State.L1PricingState().RewardSplits().SetRecipients(recipients, weightsBips)
+}
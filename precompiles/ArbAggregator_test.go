@@ -8,7 +8,9 @@ import (
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/offchainlabs/nitro/arbos/arbosState"
 	"github.com/offchainlabs/nitro/arbos/l1pricing"
 )
 
@@ -42,7 +44,7 @@ func TestArbAggregatorBatchPosters(t *testing.T) {
 
 func TestFeeCollector(t *testing.T) {
 	evm := newMockEVMForTesting()
-	agg := ArbAggregator{}
+	agg := precompileForTesting[ArbAggregator](types.ArbAggregatorAddress)
 
 	aggAddr := l1pricing.BatchPosterAddress
 	collectorAddr := common.BytesToAddress(crypto.Keccak256([]byte{1})[:20])
@@ -109,3 +111,36 @@ func TestTxBaseFee(t *testing.T) {
 		Fail(t, fee)
 	}
 }
+
+func TestAggregatorDeprecation(t *testing.T) {
+	evm := newMockEVMForTesting()
+	agg := ArbAggregator{}
+	context := testContext(common.Address{}, evm)
+	callsBefore := legacyAggregatorCallsCounter.Snapshot().Count()
+
+	// until the chain owner opts in, the legacy methods behave as before and aren't counted
+	prefAgg, isDefault, err := agg.GetPreferredAggregator(context, evm, common.Address{})
+	Require(t, err)
+	if prefAgg != l1pricing.BatchPosterAddress || !isDefault {
+		Fail(t, "wrong preferred aggregator", prefAgg, isDefault)
+	}
+	if calls := legacyAggregatorCallsCounter.Snapshot().Count() - callsBefore; calls != 0 {
+		Fail(t, "counted a call before the deprecation was enabled", calls)
+	}
+
+	Require(t, context.State.SetFeatureDisabled(arbosState.FeatureAggregatorDeprecation, false))
+	prefAgg, isDefault, err = agg.GetPreferredAggregator(context, evm, common.Address{})
+	Require(t, err)
+	if prefAgg != (common.Address{}) || isDefault {
+		Fail(t, "deprecated preferred aggregator isn't zero", prefAgg, isDefault)
+	}
+	defaultAgg, err := agg.GetDefaultAggregator(context, evm)
+	Require(t, err)
+	if defaultAgg != (common.Address{}) {
+		Fail(t, "deprecated default aggregator isn't zero", defaultAgg)
+	}
+	Require(t, agg.SetTxBaseFee(context, evm, common.Address{}, big.NewInt(1)))
+	if calls := legacyAggregatorCallsCounter.Snapshot().Count() - callsBefore; calls != 3 {
+		Fail(t, "wrong legacy call count", calls)
+	}
+}
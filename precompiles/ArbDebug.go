@@ -5,6 +5,7 @@ package precompiles
 
 import (
 	"errors"
+	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
 )
@@ -20,8 +21,9 @@ type ArbDebug struct {
 	MixedGasCost func(bool, bool, bytes32, addr, addr) (uint64, error)
 	StoreGasCost func(bool, addr, huge, bytes32, []byte) (uint64, error)
 
-	CustomError func(uint64, string, bool) error
-	UnusedError func() error
+	CustomError        func(uint64, string, bool) error
+	UnusedError        func() error
+	NotChainOwnerError func(addr) error
 }
 
 func (con ArbDebug) Events(c ctx, evm mech, paid huge, flag bool, value bytes32) (addr, huge, error) {
@@ -53,7 +55,14 @@ func (con ArbDebug) CustomRevert(c ctx, number uint64) error {
 
 // Caller becomes a chain owner
 func (con ArbDebug) BecomeChainOwner(c ctx, evm mech) error {
-	return c.State.ChainOwners().Add(c.caller)
+	member, err := c.State.ChainOwners().IsMember(c.caller)
+	if err != nil || member {
+		return err
+	}
+	if err := c.State.ChainOwners().Add(c.caller); err != nil {
+		return err
+	}
+	return recordChainOwnerChange(c, evm, c.caller, true)
 }
 
 // Halts the chain by panicking in the STF
@@ -64,3 +73,168 @@ func (con ArbDebug) Panic(c ctx, evm mech) error {
 func (con ArbDebug) LegacyError(c ctx) error {
 	return errors.New("example legacy error")
 }
+
+// MaxDebugStorageReads bounds the number of slots ReadArbosStorage may return in a single call
+const MaxDebugStorageReads = 256
+
+// RetryableQueueState mirrors the ArbDebug.RetryableQueueState solidity struct
+type RetryableQueueState struct {
+	Size        uint64
+	Head        [32]byte
+	HeadTimeout uint64
+}
+
+// L1PricingSnapshot mirrors the ArbDebug.L1PricingSnapshot solidity struct
+type L1PricingSnapshot struct {
+	PricePerUnit       *big.Int
+	UnitsSinceUpdate   uint64
+	LastUpdateTime     uint64
+	FundsDueForRewards *big.Int
+	FundsDueToPosters  *big.Int
+	L1FeesAvailable    *big.Int
+	LastSurplus        *big.Int
+	EquilibrationUnits *big.Int
+}
+
+// L2PricingSnapshot mirrors the ArbDebug.L2PricingSnapshot solidity struct
+type L2PricingSnapshot struct {
+	BaseFee          *big.Int
+	MinBaseFee       *big.Int
+	GasBacklog       uint64
+	SpeedLimit       uint64
+	PerBlockGasLimit uint64
+	PricingInertia   uint64
+	BacklogTolerance uint64
+}
+
+func (con ArbDebug) requireOwner(c ctx) error {
+	isOwner, err := c.State.ChainOwners().IsMember(c.caller)
+	if err != nil {
+		return err
+	}
+	if !isOwner {
+		return typedRevert(c, con.NotChainOwnerError(c.caller), ErrNotOwner)
+	}
+	return nil
+}
+
+// GetRetryableQueueState returns the size of the retryable timeout queue and its head (caller must be an owner)
+func (con ArbDebug) GetRetryableQueueState(c ctx, evm mech) (RetryableQueueState, error) {
+	var queueState RetryableQueueState
+	if err := con.requireOwner(c); err != nil {
+		return queueState, err
+	}
+	retryableState := c.State.RetryableState()
+	size, err := retryableState.TimeoutQueue.Size()
+	if err != nil {
+		return queueState, err
+	}
+	queueState.Size = size
+	head, err := retryableState.TimeoutQueue.Peek()
+	if err != nil || head == nil {
+		return queueState, err
+	}
+	queueState.Head = *head
+	retryable, err := retryableState.OpenRetryable(*head, 0)
+	if err != nil || retryable == nil {
+		// the head may be a stale entry for a retryable that's already been deleted
+		return queueState, err
+	}
+	queueState.HeadTimeout, err = retryable.CalculateTimeout()
+	return queueState, err
+}
+
+// GetL1PricingSnapshot returns the variables of the L1 pricing model (caller must be an owner)
+func (con ArbDebug) GetL1PricingSnapshot(c ctx, evm mech) (L1PricingSnapshot, error) {
+	var snapshot L1PricingSnapshot
+	if err := con.requireOwner(c); err != nil {
+		return snapshot, err
+	}
+	l1Pricing := c.State.L1PricingState()
+	var err error
+	if snapshot.PricePerUnit, err = l1Pricing.PricePerUnit(); err != nil {
+		return snapshot, err
+	}
+	if snapshot.UnitsSinceUpdate, err = l1Pricing.UnitsSinceUpdate(); err != nil {
+		return snapshot, err
+	}
+	if snapshot.LastUpdateTime, err = l1Pricing.LastUpdateTime(); err != nil {
+		return snapshot, err
+	}
+	if snapshot.FundsDueForRewards, err = l1Pricing.FundsDueForRewards(); err != nil {
+		return snapshot, err
+	}
+	if snapshot.FundsDueToPosters, err = l1Pricing.BatchPosterTable().TotalFundsDue(); err != nil {
+		return snapshot, err
+	}
+	if snapshot.L1FeesAvailable, err = l1Pricing.L1FeesAvailable(); err != nil {
+		return snapshot, err
+	}
+	if snapshot.LastSurplus, err = l1Pricing.LastSurplus(); err != nil {
+		return snapshot, err
+	}
+	snapshot.EquilibrationUnits, err = l1Pricing.EquilibrationUnits()
+	return snapshot, err
+}
+
+// GetL2PricingSnapshot returns the variables of the L2 pricing model (caller must be an owner)
+func (con ArbDebug) GetL2PricingSnapshot(c ctx, evm mech) (L2PricingSnapshot, error) {
+	var snapshot L2PricingSnapshot
+	if err := con.requireOwner(c); err != nil {
+		return snapshot, err
+	}
+	l2Pricing := c.State.L2PricingState()
+	var err error
+	if snapshot.BaseFee, err = l2Pricing.BaseFeeWei(); err != nil {
+		return snapshot, err
+	}
+	if snapshot.MinBaseFee, err = l2Pricing.MinBaseFeeWei(); err != nil {
+		return snapshot, err
+	}
+	if snapshot.GasBacklog, err = l2Pricing.GasBacklog(); err != nil {
+		return snapshot, err
+	}
+	if snapshot.SpeedLimit, err = l2Pricing.SpeedLimitPerSecond(); err != nil {
+		return snapshot, err
+	}
+	if snapshot.PerBlockGasLimit, err = l2Pricing.PerBlockGasLimit(); err != nil {
+		return snapshot, err
+	}
+	if snapshot.PricingInertia, err = l2Pricing.PricingInertia(); err != nil {
+		return snapshot, err
+	}
+	snapshot.BacklogTolerance, err = l2Pricing.BacklogTolerance()
+	return snapshot, err
+}
+
+// GetAddressTableSize returns the number of addresses registered in the address table (caller must be an owner)
+func (con ArbDebug) GetAddressTableSize(c ctx, evm mech) (uint64, error) {
+	if err := con.requireOwner(c); err != nil {
+		return 0, err
+	}
+	return c.State.AddressTable().Size()
+}
+
+// ReadArbosStorage reads raw slots of the ArbOS storage subspace reached by following subspaceKeys from the root
+// (caller must be an owner)
+func (con ArbDebug) ReadArbosStorage(c ctx, evm mech, subspaceKeys [][]byte, offsets []uint64) ([]bytes32, error) {
+	if err := con.requireOwner(c); err != nil {
+		return nil, err
+	}
+	if len(offsets) > MaxDebugStorageReads {
+		return nil, errors.New("too many storage slots requested")
+	}
+	sto := c.State.BackingStorage()
+	for _, key := range subspaceKeys {
+		sto = sto.OpenSubStorage(key)
+	}
+	values := make([]bytes32, len(offsets))
+	for i, offset := range offsets {
+		value, err := sto.GetByUint64(offset)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	return values, nil
+}
@@ -6,26 +6,44 @@ package precompiles
 import (
 	"errors"
 	"math/big"
+
+	"github.com/offchainlabs/nitro/arbos/arbosState"
 )
 
 // ArbFunctionTable  precompile provided aggregator's the ability to manage function tables.
 // Aggregation works differently in Nitro, so these methods have been stubbed and their effects disabled.
-// They are kept for backwards compatibility.
+// They are kept for backwards compatibility, unless the chain owner enables the function table deprecation feature,
+// in which case every method reverts with FunctionTableDeprecated so tooling doesn't mistake the stubs for a table.
 type ArbFunctionTable struct {
-	Address addr // 0x68
+	Address                      addr // 0x68
+	FunctionTableDeprecatedError func() error
+	TableEmptyError              func() error
+}
+
+func (con ArbFunctionTable) checkDeprecated(c ctx) error {
+	if c.State.FeatureEnabled(arbosState.FeatureFunctionTableDeprecation) {
+		return con.FunctionTableDeprecatedError()
+	}
+	return nil
 }
 
 // Upload does nothing
 func (con ArbFunctionTable) Upload(c ctx, evm mech, buf []byte) error {
-	return nil
+	return con.checkDeprecated(c)
 }
 
 // Size returns the empty table's size, which is 0
 func (con ArbFunctionTable) Size(c ctx, evm mech, addr addr) (huge, error) {
+	if err := con.checkDeprecated(c); err != nil {
+		return nil, err
+	}
 	return big.NewInt(0), nil
 }
 
 // Get reverts since the table is empty
 func (con ArbFunctionTable) Get(c ctx, evm mech, addr addr, index huge) (huge, bool, huge, error) {
-	return nil, false, nil, errors.New("table is empty")
+	if err := con.checkDeprecated(c); err != nil {
+		return nil, false, nil, err
+	}
+	return nil, false, nil, typedRevert(c, con.TableEmptyError(), errors.New("table is empty"))
 }
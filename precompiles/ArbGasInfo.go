@@ -4,6 +4,7 @@
 package precompiles
 
 import (
+	"fmt"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -42,7 +43,15 @@ func (con ArbGasInfo) GetPricesInWeiWithAggregator(
 	} else {
 		l2GasPrice = evm.Context.BaseFee
 	}
+	minBaseFee, err := c.State.L2PricingState().MinBaseFeeWei()
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, err
+	}
+	perL2Tx, weiForL1Calldata, weiForL2Storage, perArbGasBase, perArbGasCongestion, perArbGasTotal := pricesInWei(l1GasPrice, l2GasPrice, minBaseFee)
+	return perL2Tx, weiForL1Calldata, weiForL2Storage, perArbGasBase, perArbGasCongestion, perArbGasTotal, nil
+}
 
+func pricesInWei(l1GasPrice, l2GasPrice, minBaseFee huge) (huge, huge, huge, huge, huge, huge) {
 	// aggregators compress calldata, so we must estimate accordingly
 	weiForL1Calldata := arbmath.BigMulByUint(l1GasPrice, params.TxDataNonZeroGasEIP2028)
 
@@ -50,10 +59,7 @@ func (con ArbGasInfo) GetPricesInWeiWithAggregator(
 	perL2Tx := arbmath.BigMulByUint(weiForL1Calldata, AssumedSimpleTxSize)
 
 	// nitro's compute-centric l2 gas pricing has no special compute component that rises independently
-	perArbGasBase, err := c.State.L2PricingState().MinBaseFeeWei()
-	if err != nil {
-		return nil, nil, nil, nil, nil, nil, err
-	}
+	perArbGasBase := minBaseFee
 	if arbmath.BigLessThan(l2GasPrice, perArbGasBase) {
 		perArbGasBase = l2GasPrice
 	}
@@ -62,7 +68,7 @@ func (con ArbGasInfo) GetPricesInWeiWithAggregator(
 
 	weiForL2Storage := arbmath.BigMul(l2GasPrice, storageArbGas)
 
-	return perL2Tx, weiForL1Calldata, weiForL2Storage, perArbGasBase, perArbGasCongestion, perArbGasTotal, nil
+	return perL2Tx, weiForL1Calldata, weiForL2Storage, perArbGasBase, perArbGasCongestion, perArbGasTotal
 }
 
 func (con ArbGasInfo) _preVersion4_GetPricesInWeiWithAggregator(
@@ -102,6 +108,68 @@ func (con ArbGasInfo) GetPricesInWei(c ctx, evm mech) (huge, huge, huge, huge, h
 	return con.GetPricesInWeiWithAggregator(c, evm, addr{})
 }
 
+// GetPricesInWeiAtBlock gets the prices GetPricesInWei gave when the block started, which must be one of the last
+// blocks the chain keeps a price history for
+func (con ArbGasInfo) GetPricesInWeiAtBlock(c ctx, evm mech, blockNumber uint64) (huge, huge, huge, huge, huge, huge, error) {
+	if blockNumber > evm.Context.BlockNumber.Uint64() {
+		return nil, nil, nil, nil, nil, nil, fmt.Errorf("block %v is in the future", blockNumber)
+	}
+	snapshot, err := c.State.PriceHistory().At(blockNumber)
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, err
+	}
+	perL2Tx, weiForL1Calldata, weiForL2Storage, perArbGasBase, perArbGasCongestion, perArbGasTotal := pricesInWei(
+		snapshot.L1PricePerUnit, snapshot.L2BaseFee, snapshot.MinBaseFee,
+	)
+	return perL2Tx, weiForL1Calldata, weiForL2Storage, perArbGasBase, perArbGasCongestion, perArbGasTotal, nil
+}
+
+// GetPricingHistoryDepth gets the number of recent blocks GetPricesInWeiAtBlock can be queried for
+func (con ArbGasInfo) GetPricingHistoryDepth(c ctx, evm mech) (uint64, error) {
+	return c.State.PriceHistory().Depth()
+}
+
+// GetPricesInFeeToken gets the same prices as GetPricesInWei, in the smallest units of the chain's fee token scaled
+// by 1e18, so that prices below one unit of a token with fewer than 18 decimals aren't truncated to zero
+func (con ArbGasInfo) GetPricesInFeeToken(c ctx, evm mech) (huge, huge, huge, huge, huge, huge, error) {
+	perL2Tx, perL1CalldataByte, perStorageAllocation, perArbGasBase, perArbGasCongestion, perArbGasTotal, err := con.GetPricesInWei(c, evm)
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, err
+	}
+	prices := []huge{perL2Tx, perL1CalldataByte, perStorageAllocation, perArbGasBase, perArbGasCongestion, perArbGasTotal}
+	feeToken := c.State.FeeTokenState()
+	for i, price := range prices {
+		if prices[i], err = feeToken.ToScaledTokenUnits(price); err != nil {
+			return nil, nil, nil, nil, nil, nil, err
+		}
+	}
+	return prices[0], prices[1], prices[2], prices[3], prices[4], prices[5], nil
+}
+
+// GetFeeTokenInfo gets whether gas is paid in a bridged ERC-20 token, its decimals, the rate at which parent chain
+// fees are converted to it scaled by 1e18, and the total amounts deposited and withdrawn through the bridge
+func (con ArbGasInfo) GetFeeTokenInfo(c ctx, evm mech) (bool, uint64, huge, huge, huge, error) {
+	feeToken := c.State.FeeTokenState()
+	isFeeTokenChain, err := feeToken.IsFeeTokenChain()
+	if err != nil {
+		return false, 0, nil, nil, nil, err
+	}
+	decimals, err := feeToken.Decimals()
+	if err != nil {
+		return false, 0, nil, nil, nil, err
+	}
+	exchangeRate, err := feeToken.ExchangeRate()
+	if err != nil {
+		return false, 0, nil, nil, nil, err
+	}
+	deposited, err := feeToken.TotalDeposited()
+	if err != nil {
+		return false, 0, nil, nil, nil, err
+	}
+	withdrawn, err := feeToken.TotalWithdrawn()
+	return isFeeTokenChain, decimals, exchangeRate, deposited, withdrawn, err
+}
+
 // GetPricesInArbGasWithAggregator gets prices in ArbGas when using the provided aggregator
 func (con ArbGasInfo) GetPricesInArbGasWithAggregator(c ctx, evm mech, aggregator addr) (huge, huge, huge, error) {
 	if c.State.ArbOSVersion() < 4 {
@@ -207,6 +275,38 @@ func (con ArbGasInfo) GetGasBacklog(c ctx, evm mech) (uint64, error) {
 	return c.State.L2PricingState().GasBacklog()
 }
 
+// GetBlockGasResources gets the per-block gas limit, the gas the pending block used so far and what's left of the
+// limit, the backlog, and the backlog relative to the tolerance ArbOS allows before raising the basefee, in bips.
+// Outside of a block, such as in calls, the pending block is the latest one.
+func (con ArbGasInfo) GetBlockGasResources(c ctx, evm mech) (uint64, uint64, uint64, uint64, uint64, error) {
+	l2State := c.State.L2PricingState()
+	limit, err := l2State.PerBlockGasLimit()
+	if err != nil {
+		return 0, 0, 0, 0, 0, err
+	}
+	used, err := l2State.BlockGasUsed()
+	if err != nil {
+		return 0, 0, 0, 0, 0, err
+	}
+	backlog, err := l2State.GasBacklog()
+	if err != nil {
+		return 0, 0, 0, 0, 0, err
+	}
+	speedLimit, err := l2State.SpeedLimitPerSecond()
+	if err != nil {
+		return 0, 0, 0, 0, 0, err
+	}
+	tolerance, err := l2State.BacklogTolerance()
+	if err != nil {
+		return 0, 0, 0, 0, 0, err
+	}
+	var utilization uint64
+	if toleratedBacklog := arbmath.SaturatingUMul(tolerance, speedLimit); toleratedBacklog != 0 {
+		utilization = arbmath.SaturatingUMul(backlog, uint64(arbmath.OneInBips)) / toleratedBacklog
+	}
+	return limit, used, arbmath.SaturatingUSub(limit, used), backlog, utilization, nil
+}
+
 // GetPricingInertia gets how slowly ArbOS updates the L2 basefee in response to backlogged gas
 func (con ArbGasInfo) GetPricingInertia(c ctx, evm mech) (uint64, error) {
 	return c.State.L2PricingState().PricingInertia()
@@ -251,6 +351,21 @@ func (con ArbGasInfo) GetAmortizedCostCapBips(c ctx, evm mech) (uint64, error) {
 	return c.State.L1PricingState().AmortizedCostCapBips()
 }
 
+// GetL1BaseFeeSmoothingWindow gets how many batch posting reports the parent chain base fee is smoothed over
+func (con ArbGasInfo) GetL1BaseFeeSmoothingWindow(c ctx, evm mech) (uint64, error) {
+	return c.State.L1PricingState().BaseFeeWindow()
+}
+
+// GetSmoothedL1BaseFee gets the smoothed parent chain base fee, or zero if it isn't smoothed
+func (con ArbGasInfo) GetSmoothedL1BaseFee(c ctx, evm mech) (huge, error) {
+	return c.State.L1PricingState().SmoothedBaseFee()
+}
+
+// GetCalldataPriceBips gets what txs are charged for their calldata, in bips of the poster's costs for it
+func (con ArbGasInfo) GetCalldataPriceBips(c ctx, evm mech) (uint64, error) {
+	return c.State.L1PricingState().CalldataPriceBips()
+}
+
 // GetL1FeesAvailable gets the available funds from L1 fees
 func (con ArbGasInfo) GetL1FeesAvailable(c ctx, evm mech) (huge, error) {
 	return c.State.L1PricingState().L1FeesAvailable()
@@ -280,3 +395,8 @@ func (con ArbGasInfo) GetL1PricingUnitsSinceUpdate(c ctx, evm mech) (uint64, err
 func (con ArbGasInfo) GetLastL1PricingSurplus(c ctx, evm mech) (*big.Int, error) {
 	return c.State.L1PricingState().LastSurplus()
 }
+
+// GetCodeDepositFeePerByte gets the surcharge in wei levied per byte of code submitted for deployment
+func (con ArbGasInfo) GetCodeDepositFeePerByte(c ctx, evm mech) (huge, error) {
+	return c.State.L2PricingState().CodeDepositFeePerByte()
+}
@@ -8,9 +8,15 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"math/big"
+	"time"
 
+	"github.com/offchainlabs/nitro/arbos/arbosState"
+	"github.com/offchainlabs/nitro/arbos/collectorhistory"
+	"github.com/offchainlabs/nitro/arbos/feetoken"
 	"github.com/offchainlabs/nitro/arbos/l1pricing"
+	"github.com/offchainlabs/nitro/arbos/methodacl"
 	"github.com/offchainlabs/nitro/arbos/programs"
 	"github.com/offchainlabs/nitro/util/arbmath"
 	am "github.com/offchainlabs/nitro/util/arbmath"
@@ -24,27 +30,88 @@ import (
 // which ensures only a chain owner can access these methods. For methods that
 // are safe for non-owners to call, see ArbOwnerOld
 type ArbOwner struct {
-	Address          addr // 0x70
-	OwnerActs        func(ctx, mech, bytes4, addr, []byte) error
-	OwnerActsGasCost func(bytes4, addr, []byte) (uint64, error)
+	Address                         addr // 0x70
+	OwnerActs                       func(ctx, mech, bytes4, addr, []byte) error
+	OwnerActsGasCost                func(bytes4, addr, []byte) (uint64, error)
+	ParameterChangeScheduled        func(ctx, mech, uint64, uint64, bytes32, uint64) error
+	ParameterChangeScheduledGasCost func(uint64, uint64, bytes32, uint64) (uint64, error)
+	ParameterChangeExecuted         func(ctx, mech, uint64, uint64, bytes32) error
+	ParameterChangeExecutedGasCost  func(uint64, uint64, bytes32) (uint64, error)
+	ParameterChangeCancelled        func(ctx, mech, uint64) error
+	ParameterChangeCancelledGasCost func(uint64) (uint64, error)
+	ChainOwnerAdded                 func(ctx, mech, addr) error
+	ChainOwnerAddedGasCost          func(addr) (uint64, error)
+	ChainOwnerRemoved               func(ctx, mech, addr) error
+	ChainOwnerRemovedGasCost        func(addr) (uint64, error)
+	NetworkFeeAccountChanged        func(ctx, mech, addr, addr) error
+	NetworkFeeAccountChangedGasCost func(addr, addr) (uint64, error)
+	InfraFeeAccountChanged          func(ctx, mech, addr, addr) error
+	InfraFeeAccountChangedGasCost   func(addr, addr) (uint64, error)
+	L1RewardRecipientChanged        func(ctx, mech, addr, addr) error
+	L1RewardRecipientChangedGasCost func(addr, addr) (uint64, error)
+
+	NotChainOwnerError         func(addr) error
+	NotDelegableError          func(bytes4) error
+	NotCacheManagerError       func(addr) error
+	OutOfBoundsError           func() error
+	InvalidChainConfigError    func(string) error
+	ActivationNotInFutureError func(uint64) error
+	NoScheduledChangeError     func(uint64) error
+	ActivationTooEarlyError    func(uint64) error
 }
 
 var (
 	ErrOutOfBounds = errors.New("value out of bounds")
 )
 
+// owner-gated methods that only chain owners may call, so granting them would amount to transferring ownership
+var nonDelegableOwnerMethods = make(map[bytes4]struct{})
+
 // AddChainOwner adds account as a chain owner
 func (con ArbOwner) AddChainOwner(c ctx, evm mech, newOwner addr) error {
-	return c.State.ChainOwners().Add(newOwner)
+	if c.State.ArbOSVersion() < arbosState.ArbosVersion_ChainOwnerHistory {
+		return c.State.ChainOwners().Add(newOwner)
+	}
+	member, err := con.IsChainOwner(c, evm, newOwner)
+	if err != nil || member {
+		return err
+	}
+	if err := c.State.ChainOwners().Add(newOwner); err != nil {
+		return err
+	}
+	if err := recordChainOwnerChange(c, evm, newOwner, true); err != nil {
+		return err
+	}
+	return con.ChainOwnerAdded(c, evm, newOwner)
 }
 
 // RemoveChainOwner removes account from the list of chain owners
 func (con ArbOwner) RemoveChainOwner(c ctx, evm mech, addr addr) error {
 	member, _ := con.IsChainOwner(c, evm, addr)
 	if !member {
-		return errors.New("tried to remove non-owner")
+		return typedRevert(c, con.NotChainOwnerError(addr), errors.New("tried to remove non-owner"))
+	}
+	if err := c.State.ChainOwners().Remove(addr, c.State.ArbOSVersion()); err != nil {
+		return err
+	}
+	if c.State.ArbOSVersion() < arbosState.ArbosVersion_ChainOwnerHistory {
+		return nil
 	}
-	return c.State.ChainOwners().Remove(addr, c.State.ArbOSVersion())
+	if err := recordChainOwnerChange(c, evm, addr, false); err != nil {
+		return err
+	}
+	return con.ChainOwnerRemoved(c, evm, addr)
+}
+
+// recordChainOwnerChange appends a change of the chain owners to their history, if the ArbOS version keeps one
+func recordChainOwnerChange(c ctx, evm mech, owner addr, added bool) error {
+	if c.State.ArbOSVersion() < arbosState.ArbosVersion_ChainOwnerHistory {
+		return nil
+	}
+	owners := func() ([]addr, error) {
+		return c.State.ChainOwners().AllMembers(65536)
+	}
+	return c.State.ChainOwnerHistory().Record(evm.Context.BlockNumber.Uint64(), owner, added, owners)
 }
 
 // IsChainOwner checks if the account is a chain owner
@@ -52,6 +119,23 @@ func (con ArbOwner) IsChainOwner(c ctx, evm mech, addr addr) (bool, error) {
 	return c.State.ChainOwners().IsMember(addr)
 }
 
+// GrantMethodAccess lets grantee call a single owner-gated method of a precompile until the expiry timestamp,
+// or until revoked if expiry is zero
+func (con ArbOwner) GrantMethodAccess(c ctx, evm mech, precompile addr, method bytes4, grantee addr, expiry uint64) error {
+	if _, ok := nonDelegableOwnerMethods[method]; ok && precompile == con.Address {
+		return typedRevert(c, con.NotDelegableError(method), errors.New("method may only be called by chain owners"))
+	}
+	if expiry == 0 {
+		expiry = methodacl.NoExpiry
+	}
+	return c.State.MethodACL().Grant(precompile, method, grantee, expiry, evm.Context.Time)
+}
+
+// RevokeMethodAccess removes grantee's access to an owner-gated method of a precompile
+func (con ArbOwner) RevokeMethodAccess(c ctx, evm mech, precompile addr, method bytes4, grantee addr) error {
+	return c.State.MethodACL().Revoke(precompile, method, grantee)
+}
+
 // GetAllChainOwners retrieves the list of chain owners
 func (con ArbOwner) GetAllChainOwners(c ctx, evm mech) ([]common.Address, error) {
 	return c.State.ChainOwners().AllMembers(65536)
@@ -70,7 +154,7 @@ func (con ArbOwner) SetL2BaseFee(c ctx, evm mech, priceInWei huge) error {
 // SetMinimumL2BaseFee sets the minimum base fee needed for a transaction to succeed
 func (con ArbOwner) SetMinimumL2BaseFee(c ctx, evm mech, priceInWei huge) error {
 	if c.txProcessor.MsgIsNonMutating() && priceInWei.Sign() == 0 {
-		return errors.New("minimum base fee must be nonzero")
+		return typedRevert(c, con.OutOfBoundsError(), errors.New("minimum base fee must be nonzero"))
 	}
 	return c.State.L2PricingState().SetMinBaseFeeWei(priceInWei)
 }
@@ -107,16 +191,37 @@ func (con ArbOwner) GetInfraFeeAccount(c ctx, evm mech) (addr, error) {
 
 // SetNetworkFeeAccount sets the network fee collector to the new network fee account
 func (con ArbOwner) SetNetworkFeeAccount(c ctx, evm mech, newNetworkFeeAccount addr) error {
-	return c.State.SetNetworkFeeAccount(newNetworkFeeAccount)
+	if c.State.ArbOSVersion() < arbosState.ArbosVersion_FeeCollectorHistory {
+		return c.State.SetNetworkFeeAccount(newNetworkFeeAccount)
+	}
+	previous, err := c.State.ChangeFeeCollector(evm.Context.BlockNumber.Uint64(), collectorhistory.NetworkFee, newNetworkFeeAccount)
+	if err != nil {
+		return err
+	}
+	return con.NetworkFeeAccountChanged(c, evm, previous, newNetworkFeeAccount)
 }
 
 // SetInfraFeeAccount sets the infra fee collector to the new network fee account
 func (con ArbOwner) SetInfraFeeAccount(c ctx, evm mech, newNetworkFeeAccount addr) error {
-	return c.State.SetInfraFeeAccount(newNetworkFeeAccount)
+	if c.State.ArbOSVersion() < arbosState.ArbosVersion_FeeCollectorHistory {
+		return c.State.SetInfraFeeAccount(newNetworkFeeAccount)
+	}
+	previous, err := c.State.ChangeFeeCollector(evm.Context.BlockNumber.Uint64(), collectorhistory.InfraFee, newNetworkFeeAccount)
+	if err != nil {
+		return err
+	}
+	return con.InfraFeeAccountChanged(c, evm, previous, newNetworkFeeAccount)
 }
 
 // ScheduleArbOSUpgrade to the requested version at the requested timestamp
 func (con ArbOwner) ScheduleArbOSUpgrade(c ctx, evm mech, newVersion uint64, timestamp uint64) error {
+	// the upgrade can't fail once it's due, so the chain config must already place the new version's custom
+	// precompiles in its ranges, if it declares any
+	if newVersion >= arbosState.ArbosVersion_CustomPrecompileRanges {
+		if err := c.State.ValidatePrecompileAddressSpace(newVersion); err != nil {
+			return typedRevert(c, con.InvalidChainConfigError(err.Error()), err)
+		}
+	}
 	return c.State.ScheduleArbOSUpgrade(newVersion, timestamp)
 }
 
@@ -129,7 +234,14 @@ func (con ArbOwner) SetL1PricingInertia(c ctx, evm mech, inertia uint64) error {
 }
 
 func (con ArbOwner) SetL1PricingRewardRecipient(c ctx, evm mech, recipient addr) error {
-	return c.State.L1PricingState().SetPayRewardsTo(recipient)
+	if c.State.ArbOSVersion() < arbosState.ArbosVersion_FeeCollectorHistory {
+		return c.State.L1PricingState().SetPayRewardsTo(recipient)
+	}
+	previous, err := c.State.ChangeFeeCollector(evm.Context.BlockNumber.Uint64(), collectorhistory.L1Reward, recipient)
+	if err != nil {
+		return err
+	}
+	return con.L1RewardRecipientChanged(c, evm, previous, recipient)
 }
 
 func (con ArbOwner) SetL1PricingRewardRate(c ctx, evm mech, weiPerUnit uint64) error {
@@ -148,10 +260,118 @@ func (con ArbOwner) SetAmortizedCostCapBips(c ctx, evm mech, cap uint64) error {
 	return c.State.L1PricingState().SetAmortizedCostCapBips(cap)
 }
 
+// SetBatchPosterReimbursementCap caps how much a batch poster is reimbursed per batch, in bips of the L1 basefee
+// times the batch's calldata units, on top of the amortized cost cap. Zero removes the poster's cap.
+func (con ArbOwner) SetBatchPosterReimbursementCap(c ctx, evm mech, batchPoster addr, capBips uint64) error {
+	posterState, err := c.State.L1PricingState().BatchPosterTable().OpenPoster(batchPoster, false)
+	if err != nil {
+		return err
+	}
+	return posterState.SetReimbursementCapBips(capBips)
+}
+
+// SetCodeDepositFeePerByte sets the surcharge in wei levied per byte of code submitted for deployment
+func (con ArbOwner) SetCodeDepositFeePerByte(c ctx, evm mech, weiPerByte huge) error {
+	return c.State.L2PricingState().SetCodeDepositFeePerByte(weiPerByte)
+}
+
+// SetFeeToken makes the chain pay gas in a bridged ERC-20 token with the given decimals, converting parent chain
+// fees at exchangeRate native token wei per 1e18 parent chain wei. A zero exchange rate makes it an ETH chain again.
+// As switching the currency gas is paid in affects every user, it only takes effect after the time lock's delay.
+func (con ArbOwner) SetFeeToken(c ctx, evm mech, decimals uint64, exchangeRate huge) error {
+	config, err := feetoken.EncodeConfig(decimals, exchangeRate)
+	if err != nil {
+		return typedRevert(c, con.OutOfBoundsError(), err)
+	}
+	if locked, err := con.timeLocked(c, evm, arbosState.ScheduledFeeToken, config); locked {
+		return err
+	}
+	return c.State.FeeTokenState().Configure(decimals, exchangeRate)
+}
+
+// SetFeeTokenExchangeRate updates the rate, scaled by 1e18, at which parent chain fees are converted to the fee token
+func (con ArbOwner) SetFeeTokenExchangeRate(c ctx, evm mech, exchangeRate huge) error {
+	return c.State.FeeTokenState().SetExchangeRate(exchangeRate)
+}
+
+// SetFeatureDisabled disables or re-enables an ArbOS feature that chain owners may toggle
+func (con ArbOwner) SetFeatureDisabled(c ctx, evm mech, feature uint64, disabled bool) error {
+	return c.State.SetFeatureDisabled(arbosState.Feature(feature), disabled)
+}
+
 func (con ArbOwner) SetBrotliCompressionLevel(c ctx, evm mech, level uint64) error {
 	return c.State.SetBrotliCompressionLevel(level)
 }
 
+// SetParentChainBlockTime sets the parent chain's block time in milliseconds, for chains whose parent chain
+// doesn't produce blocks every 12 seconds like Ethereum
+func (con ArbOwner) SetParentChainBlockTime(c ctx, evm mech, milliseconds uint64) error {
+	if milliseconds == 0 || milliseconds > math.MaxInt64/uint64(time.Millisecond) {
+		return typedRevert(c, con.OutOfBoundsError(), ErrOutOfBounds)
+	}
+	// #nosec G115
+	return c.State.L1TimingState().SetBlockTime(time.Duration(milliseconds) * time.Millisecond)
+}
+
+// CommitBatchDictionary commits the chain to a brotli dictionary, by the keccak256 hash of its contents, so batches
+// may be compressed with it. The commitment is permanent, and nodes must be configured with the dictionary.
+func (con ArbOwner) CommitBatchDictionary(c ctx, evm mech, hash bytes32) error {
+	if hash == (bytes32{}) {
+		return typedRevert(c, con.OutOfBoundsError(), ErrOutOfBounds)
+	}
+	return c.State.BatchDictionaries().Commit(hash)
+}
+
+// SetDelayedMessageFinalityBlocks sets how many blocks deep a parent chain block must be before delayed messages in
+// it are sequenced, when the parent chain doesn't report finality. Nodes wait for the stricter of this and their own
+// finalize distance.
+func (con ArbOwner) SetDelayedMessageFinalityBlocks(c ctx, evm mech, blocks uint64) error {
+	return c.State.L1TimingState().SetDelayedFinalityBlocks(blocks)
+}
+
+// SetMaxTxSize sets the size in bytes of the largest user tx the chain accepts. Zero leaves it to the sequencer's
+// configuration.
+func (con ArbOwner) SetMaxTxSize(c ctx, evm mech, size uint64) error {
+	return c.State.SetMaxTxSize(size)
+}
+
+// SetPricingHistoryDepth sets the number of recent blocks whose gas prices are kept for ArbGasInfo's
+// getPricesInWeiAtBlock. Zero stops keeping them.
+func (con ArbOwner) SetPricingHistoryDepth(c ctx, evm mech, depth uint64) error {
+	return c.State.PriceHistory().SetDepth(depth)
+}
+
+// SetL1BaseFeeSmoothingWindow sets how many batch posting reports the parent chain base fee is smoothed over before
+// batch poster costs are capped against it, for chains whose parent chain fees spike. Zero or one doesn't smooth it.
+func (con ArbOwner) SetL1BaseFeeSmoothingWindow(c ctx, evm mech, window uint64) error {
+	return c.State.L1PricingState().SetBaseFeeWindow(window)
+}
+
+// SetCalldataPriceBips sets what txs are charged for their calldata, in bips of the poster's costs for it.
+// The charge above the poster's costs is paid to the network fee account.
+func (con ArbOwner) SetCalldataPriceBips(c ctx, evm mech, bips uint64) error {
+	return c.State.L1PricingState().SetCalldataPriceBips(bips)
+}
+
+// SetGasSponsorPaymaster sets the account whose balance pays the gas of txs calling sponsored methods, within what
+// it approved through ArbOwnerPublic.approveGasSponsorship. The zero address stops sponsoring txs.
+func (con ArbOwner) SetGasSponsorPaymaster(c ctx, evm mech, paymaster addr) error {
+	return c.State.Sponsorship().SetPaymaster(paymaster)
+}
+
+// SetGasSponsoredMethod sets whether the paymaster pays the gas of txs calling a method of the target contract
+func (con ArbOwner) SetGasSponsoredMethod(c ctx, evm mech, target addr, method bytes4, sponsored bool) error {
+	return c.State.Sponsorship().SetSponsoredMethod(target, method, sponsored)
+}
+
+// SetGasSponsorshipBudget sets the wei of gas the paymaster may pay for each sender in total
+func (con ArbOwner) SetGasSponsorshipBudget(c ctx, evm mech, budget huge) error {
+	if budget.Sign() < 0 || budget.BitLen() > 256 {
+		return typedRevert(c, con.OutOfBoundsError(), ErrOutOfBounds)
+	}
+	return c.State.Sponsorship().SetAccountBudget(budget)
+}
+
 func (con ArbOwner) ReleaseL1PricerSurplusFunds(c ctx, evm mech, maxWeiToRelease huge) (huge, error) {
 	balance := evm.StateDB.GetBalance(l1pricing.L1PricerFundsPoolAddress)
 	l1p := c.State.L1PricingState()
@@ -180,7 +400,7 @@ func (con ArbOwner) SetInkPrice(c ctx, evm mech, inkPrice uint32) error {
 	}
 	ink, err := arbmath.IntToUint24(inkPrice)
 	if err != nil || ink == 0 {
-		return errors.New("ink price must be a positive uint24")
+		return typedRevert(c, con.OutOfBoundsError(), errors.New("ink price must be a positive uint24"))
 	}
 	params.InkPrice = ink
 	return params.Save()
@@ -290,7 +510,7 @@ func (con ArbOwner) RemoveWasmCacheManager(c ctx, _ mech, manager addr) error {
 		return err
 	}
 	if !isMember {
-		return errors.New("tried to remove non-manager")
+		return typedRevert(c, con.NotCacheManagerError(manager), errors.New("tried to remove non-manager"))
 	}
 	return managers.Remove(manager, c.State.ArbOSVersion())
 }
@@ -303,42 +523,214 @@ func (con ArbOwner) SetChainConfig(c ctx, evm mech, serializedChainConfig []byte
 		return errors.New("uninitialized tx processor")
 	}
 	if c.txProcessor.MsgIsNonMutating() {
-		var newConfig params.ChainConfig
-		err := json.Unmarshal(serializedChainConfig, &newConfig)
-		if err != nil {
-			return fmt.Errorf("invalid chain config, can't deserialize: %w", err)
+		if err := con.checkChainConfig(c, evm, serializedChainConfig); err != nil {
+			return typedRevert(c, con.InvalidChainConfigError(err.Error()), err)
 		}
-		if newConfig.ChainID == nil {
-			return errors.New("invalid chain config, missing chain id")
-		}
-		chainId, err := c.State.ChainId()
-		if err != nil {
-			return fmt.Errorf("failed to get chain id from ArbOS state: %w", err)
-		}
-		if newConfig.ChainID.Cmp(chainId) != 0 {
-			return fmt.Errorf("invalid chain config, chain id mismatch, want: %v, have: %v", chainId, newConfig.ChainID)
-		}
-		oldSerializedConfig, err := c.State.ChainConfig()
+	}
+	// the custom precompile ranges only depend on ArbOS state, so they're checked in every call, against the
+	// precompiles of the current version and of any upgrade scheduled
+	if err := con.checkPrecompileRanges(c, serializedChainConfig); err != nil {
+		return typedRevert(c, con.InvalidChainConfigError(err.Error()), err)
+	}
+	return c.State.SetChainConfig(serializedChainConfig)
+}
+
+func (con ArbOwner) checkPrecompileRanges(c ctx, serializedChainConfig []byte) error {
+	version := c.State.ArbOSVersion()
+	upgradeVersion, _, err := c.State.GetScheduledUpgrade()
+	if err != nil {
+		return err
+	}
+	version = arbmath.MaxInt(version, upgradeVersion)
+	if version < arbosState.ArbosVersion_CustomPrecompileRanges {
+		return nil
+	}
+	ranges, err := arbosState.CustomPrecompileRangesFromChainConfig(serializedChainConfig)
+	if err != nil {
+		return err
+	}
+	return arbosState.ValidatePrecompileAddressSpace(ranges, version)
+}
+
+// checkChainConfig checks a new chain config when SetChainConfig is called in an eth_call, so it can be validated
+// before it's set
+func (con ArbOwner) checkChainConfig(c ctx, evm mech, serializedChainConfig []byte) error {
+	var newConfig params.ChainConfig
+	err := json.Unmarshal(serializedChainConfig, &newConfig)
+	if err != nil {
+		return fmt.Errorf("invalid chain config, can't deserialize: %w", err)
+	}
+	if newConfig.ChainID == nil {
+		return errors.New("invalid chain config, missing chain id")
+	}
+	chainId, err := c.State.ChainId()
+	if err != nil {
+		return fmt.Errorf("failed to get chain id from ArbOS state: %w", err)
+	}
+	if newConfig.ChainID.Cmp(chainId) != 0 {
+		return fmt.Errorf("invalid chain config, chain id mismatch, want: %v, have: %v", chainId, newConfig.ChainID)
+	}
+	oldSerializedConfig, err := c.State.ChainConfig()
+	if err != nil {
+		return fmt.Errorf("failed to get old chain config from ArbOS state: %w", err)
+	}
+	if bytes.Equal(oldSerializedConfig, serializedChainConfig) {
+		return errors.New("new chain config is the same as old one in ArbOS state")
+	}
+	if len(oldSerializedConfig) != 0 {
+		var oldConfig params.ChainConfig
+		err = json.Unmarshal(oldSerializedConfig, &oldConfig)
 		if err != nil {
-			return fmt.Errorf("failed to get old chain config from ArbOS state: %w", err)
-		}
-		if bytes.Equal(oldSerializedConfig, serializedChainConfig) {
-			return errors.New("new chain config is the same as old one in ArbOS state")
-		}
-		if len(oldSerializedConfig) != 0 {
-			var oldConfig params.ChainConfig
-			err = json.Unmarshal(oldSerializedConfig, &oldConfig)
-			if err != nil {
-				return fmt.Errorf("failed to deserialize old chain config: %w", err)
-			}
-			if err := oldConfig.CheckCompatible(&newConfig, evm.Context.BlockNumber.Uint64(), evm.Context.Time); err != nil {
-				return fmt.Errorf("invalid chain config, not compatible with previous: %w", err)
-			}
+			return fmt.Errorf("failed to deserialize old chain config: %w", err)
 		}
-		currentConfig := evm.ChainConfig()
-		if err := currentConfig.CheckCompatible(&newConfig, evm.Context.BlockNumber.Uint64(), evm.Context.Time); err != nil {
-			return fmt.Errorf("invalid chain config, not compatible with EVM's chain config: %w", err)
+		if err := oldConfig.CheckCompatible(&newConfig, evm.Context.BlockNumber.Uint64(), evm.Context.Time); err != nil {
+			return fmt.Errorf("invalid chain config, not compatible with previous: %w", err)
 		}
 	}
-	return c.State.SetChainConfig(serializedChainConfig)
+	currentConfig := evm.ChainConfig()
+	if err := currentConfig.CheckCompatible(&newConfig, evm.Context.BlockNumber.Uint64(), evm.Context.Time); err != nil {
+		return fmt.Errorf("invalid chain config, not compatible with EVM's chain config: %w", err)
+	}
+	return nil
+}
+
+func (con ArbOwner) scheduleChange(c ctx, evm mech, parameter arbosState.ScheduledParameter, value common.Hash, activationTime uint64) (uint64, error) {
+	if activationTime <= evm.Context.Time {
+		legacy := errors.New("scheduled parameter change must activate in the future")
+		return 0, typedRevert(c, con.ActivationNotInFutureError(activationTime), legacy)
+	}
+	scheduled := c.State.ScheduledChanges()
+	earliest, err := scheduled.EarliestActivation(evm.Context.Time)
+	if err != nil {
+		return 0, err
+	}
+	if activationTime < earliest {
+		return 0, typedRevert(c, con.ActivationTooEarlyError(earliest), arbosState.ErrActivationTooEarly)
+	}
+	id, err := scheduled.Schedule(parameter, value, evm.Context.Time, activationTime)
+	if err != nil {
+		return 0, err
+	}
+	return id, con.ParameterChangeScheduled(c, evm, id, uint64(parameter), value, activationTime)
+}
+
+// timeLocked schedules the change of parameter to value at the earliest time the time lock allows, in place of
+// an immediate change, once scheduled parameter changes are active. It returns whether the change was time locked.
+// It's only for setters too disruptive to ever take effect at once, such as switching the fee token. Other setters
+// stay immediate, so the chain owner can still react to emergencies, with ScheduleSetX variants for time locked use.
+func (con ArbOwner) timeLocked(c ctx, evm mech, parameter arbosState.ScheduledParameter, value common.Hash) (bool, error) {
+	if !c.State.FeatureEnabled(arbosState.FeatureScheduledParameterChanges) {
+		return false, nil
+	}
+	earliest, err := c.State.ScheduledChanges().EarliestActivation(evm.Context.Time)
+	if err != nil {
+		return true, err
+	}
+	_, err = con.scheduleChange(c, evm, parameter, value, earliest)
+	return true, err
+}
+
+func uintHash(value uint64) common.Hash {
+	return common.BigToHash(am.UintToBig(value))
+}
+
+func (con ArbOwner) scheduleBigChange(c ctx, evm mech, parameter arbosState.ScheduledParameter, value huge, activationTime uint64) (uint64, error) {
+	if value.Sign() < 0 || value.BitLen() > 256 {
+		return 0, typedRevert(c, con.OutOfBoundsError(), ErrOutOfBounds)
+	}
+	return con.scheduleChange(c, evm, parameter, common.BigToHash(value), activationTime)
+}
+
+func (con ArbOwner) scheduleUintChange(c ctx, evm mech, parameter arbosState.ScheduledParameter, value uint64, activationTime uint64) (uint64, error) {
+	return con.scheduleChange(c, evm, parameter, uintHash(value), activationTime)
+}
+
+// ScheduleSetMinimumL2BaseFee schedules a change of the minimum L2 base fee at activationTime, returning the change's id
+func (con ArbOwner) ScheduleSetMinimumL2BaseFee(c ctx, evm mech, priceInWei huge, activationTime uint64) (uint64, error) {
+	if priceInWei.Sign() == 0 {
+		return 0, typedRevert(c, con.OutOfBoundsError(), errors.New("minimum base fee must be nonzero"))
+	}
+	return con.scheduleBigChange(c, evm, arbosState.ScheduledL2MinBaseFee, priceInWei, activationTime)
+}
+
+// ScheduleSetSpeedLimit schedules a change of the computational speed limit at activationTime
+func (con ArbOwner) ScheduleSetSpeedLimit(c ctx, evm mech, limit uint64, activationTime uint64) (uint64, error) {
+	return con.scheduleUintChange(c, evm, arbosState.ScheduledL2SpeedLimit, limit, activationTime)
+}
+
+// ScheduleSetMaxTxGasLimit schedules a change of the maximum tx (and block) gas limit at activationTime
+func (con ArbOwner) ScheduleSetMaxTxGasLimit(c ctx, evm mech, limit uint64, activationTime uint64) (uint64, error) {
+	return con.scheduleUintChange(c, evm, arbosState.ScheduledL2MaxTxGasLimit, limit, activationTime)
+}
+
+// ScheduleSetL2GasPricingInertia schedules a change of the L2 gas pricing inertia at activationTime
+func (con ArbOwner) ScheduleSetL2GasPricingInertia(c ctx, evm mech, sec uint64, activationTime uint64) (uint64, error) {
+	return con.scheduleUintChange(c, evm, arbosState.ScheduledL2PricingInertia, sec, activationTime)
+}
+
+// ScheduleSetL2GasBacklogTolerance schedules a change of the L2 gas backlog tolerance at activationTime
+func (con ArbOwner) ScheduleSetL2GasBacklogTolerance(c ctx, evm mech, sec uint64, activationTime uint64) (uint64, error) {
+	return con.scheduleUintChange(c, evm, arbosState.ScheduledL2BacklogTolerance, sec, activationTime)
+}
+
+// ScheduleSetL1PricePerUnit schedules a change of the L1 price per unit at activationTime
+func (con ArbOwner) ScheduleSetL1PricePerUnit(c ctx, evm mech, pricePerUnit huge, activationTime uint64) (uint64, error) {
+	return con.scheduleBigChange(c, evm, arbosState.ScheduledL1PricePerUnit, pricePerUnit, activationTime)
+}
+
+// ScheduleSetL1PricingInertia schedules a change of the L1 pricing inertia at activationTime
+func (con ArbOwner) ScheduleSetL1PricingInertia(c ctx, evm mech, inertia uint64, activationTime uint64) (uint64, error) {
+	return con.scheduleUintChange(c, evm, arbosState.ScheduledL1PricingInertia, inertia, activationTime)
+}
+
+// ScheduleSetL1PricingRewardRate schedules a change of the L1 pricing reward rate at activationTime
+func (con ArbOwner) ScheduleSetL1PricingRewardRate(c ctx, evm mech, weiPerUnit uint64, activationTime uint64) (uint64, error) {
+	return con.scheduleUintChange(c, evm, arbosState.ScheduledL1RewardRate, weiPerUnit, activationTime)
+}
+
+// ScheduleSetPerBatchGasCharge schedules a change of the per batch gas charge at activationTime
+func (con ArbOwner) ScheduleSetPerBatchGasCharge(c ctx, evm mech, cost int64, activationTime uint64) (uint64, error) {
+	// #nosec G115
+	return con.scheduleUintChange(c, evm, arbosState.ScheduledL1PerBatchGasCharge, uint64(cost), activationTime)
+}
+
+// ScheduleSetAmortizedCostCapBips schedules a change of the amortized cost cap at activationTime
+func (con ArbOwner) ScheduleSetAmortizedCostCapBips(c ctx, evm mech, cap uint64, activationTime uint64) (uint64, error) {
+	return con.scheduleUintChange(c, evm, arbosState.ScheduledL1AmortizedCostCapBips, cap, activationTime)
+}
+
+// ScheduleSetNetworkFeeAccount schedules a change of the network fee collector at activationTime
+func (con ArbOwner) ScheduleSetNetworkFeeAccount(c ctx, evm mech, account addr, activationTime uint64) (uint64, error) {
+	return con.scheduleChange(c, evm, arbosState.ScheduledNetworkFeeAccount, common.BytesToHash(account.Bytes()), activationTime)
+}
+
+// ScheduleSetInfraFeeAccount schedules a change of the infrastructure fee collector at activationTime
+func (con ArbOwner) ScheduleSetInfraFeeAccount(c ctx, evm mech, account addr, activationTime uint64) (uint64, error) {
+	return con.scheduleChange(c, evm, arbosState.ScheduledInfraFeeAccount, common.BytesToHash(account.Bytes()), activationTime)
+}
+
+// ScheduleSetCodeDepositFeePerByte schedules a change of the code deposit fee at activationTime
+func (con ArbOwner) ScheduleSetCodeDepositFeePerByte(c ctx, evm mech, weiPerByte huge, activationTime uint64) (uint64, error) {
+	return con.scheduleBigChange(c, evm, arbosState.ScheduledCodeDepositFeePerByte, weiPerByte, activationTime)
+}
+
+// ScheduleSetScheduledChangeDelay schedules a change of the time lock's delay at activationTime. The delay can't be
+// set below the minimum of an hour.
+func (con ArbOwner) ScheduleSetScheduledChangeDelay(c ctx, evm mech, seconds uint64, activationTime uint64) (uint64, error) {
+	if seconds < arbosState.MinScheduledChangeDelay {
+		return 0, typedRevert(c, con.OutOfBoundsError(), ErrOutOfBounds)
+	}
+	return con.scheduleUintChange(c, evm, arbosState.ScheduledChangeDelay, seconds, activationTime)
+}
+
+// CancelScheduledChange cancels a pending scheduled parameter change
+func (con ArbOwner) CancelScheduledChange(c ctx, evm mech, id uint64) error {
+	cancelled, err := c.State.ScheduledChanges().Cancel(id)
+	if err != nil {
+		return err
+	}
+	if !cancelled {
+		return typedRevert(c, con.NoScheduledChangeError(id), errors.New("no pending scheduled change with that id"))
+	}
+	return con.ParameterChangeCancelled(c, evm, id)
 }
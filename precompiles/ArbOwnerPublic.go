@@ -4,7 +4,12 @@
 package precompiles
 
 import (
+	"math/big"
+
 	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/nitro/arbos/arbosState"
+	"github.com/offchainlabs/nitro/arbos/retryables"
 )
 
 // ArbOwnerPublic precompile provides non-owners with info about the current chain owners.
@@ -65,3 +70,268 @@ func (con ArbOwnerPublic) GetScheduledUpgrade(c ctx, evm mech) (uint64, uint64,
 	}
 	return version, timestamp, nil
 }
+
+// IsFeatureEnabled checks whether an ArbOS feature is active and not disabled by the chain owner
+func (con ArbOwnerPublic) IsFeatureEnabled(c ctx, evm mech, feature uint64) (bool, error) {
+	return c.State.FeatureEnabled(arbosState.Feature(feature)), nil
+}
+
+// GetMethodAccessExpiry gets when grantee's access to an owner-gated method of a precompile expires.
+// Returns 0 if there's no grant, and the maximum uint64 if the grant lasts until revoked.
+func (con ArbOwnerPublic) GetMethodAccessExpiry(c ctx, evm mech, precompile addr, method bytes4, grantee addr) (uint64, error) {
+	return c.State.MethodACL().Expiry(precompile, method, grantee)
+}
+
+// GetChainOwnerHistoryStart gets the block the chain owner history starts at, or 0 if it hasn't started.
+// The owners at the end of the block before it are known too.
+func (con ArbOwnerPublic) GetChainOwnerHistoryStart(c ctx, evm mech) (uint64, error) {
+	return c.State.ChainOwnerHistory().StartBlock()
+}
+
+// GetChainOwnerChangeCount gets the number of chain owner additions and removals in the history
+func (con ArbOwnerPublic) GetChainOwnerChangeCount(c ctx, evm mech) (uint64, error) {
+	return c.State.ChainOwnerHistory().Size()
+}
+
+// GetChainOwnerChanges gets up to count chain owner additions and removals, oldest first, starting from the given
+// index. Each change is the block it was made in, the owner, and whether the owner was added or removed.
+func (con ArbOwnerPublic) GetChainOwnerChanges(c ctx, evm mech, start uint64, count uint64) ([]uint64, []addr, []bool, error) {
+	changes, err := c.State.ChainOwnerHistory().Changes(start, count)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	blockNumbers := make([]uint64, len(changes))
+	owners := make([]addr, len(changes))
+	added := make([]bool, len(changes))
+	for i, change := range changes {
+		blockNumbers[i] = change.BlockNumber
+		owners[i] = change.Owner
+		added[i] = change.Added
+	}
+	return blockNumbers, owners, added, nil
+}
+
+// GetChainOwnersAtBlock gets the chain owners at the end of a block since the history started
+func (con ArbOwnerPublic) GetChainOwnersAtBlock(c ctx, evm mech, blockNumber uint64) ([]addr, error) {
+	return c.State.ChainOwnerHistory().OwnersAt(blockNumber)
+}
+
+// IsBatchDictionaryCommitted checks if batches may be compressed with the brotli dictionary of the given hash
+func (con ArbOwnerPublic) IsBatchDictionaryCommitted(c ctx, evm mech, hash bytes32) (bool, error) {
+	return c.State.BatchDictionaries().IsCommitted(hash)
+}
+
+// GetBatchDictionaries gets the hashes of the brotli dictionaries batches may be compressed with
+func (con ArbOwnerPublic) GetBatchDictionaries(c ctx, evm mech) ([]bytes32, error) {
+	hashes, err := c.State.BatchDictionaries().All()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]bytes32, 0, len(hashes))
+	for _, hash := range hashes {
+		result = append(result, hash)
+	}
+	return result, nil
+}
+
+// GetParentChainBlockTime gets the parent chain's block time in milliseconds
+func (con ArbOwnerPublic) GetParentChainBlockTime(c ctx, evm mech) (uint64, error) {
+	blockTime, err := c.State.L1TimingState().BlockTime()
+	// #nosec G115
+	return uint64(blockTime.Milliseconds()), err
+}
+
+// GetDelayedMessageFinalityBlocks gets how many blocks deep a parent chain block must be before delayed messages in
+// it are sequenced when the parent chain doesn't report finality, on top of the node's own finalize distance
+func (con ArbOwnerPublic) GetDelayedMessageFinalityBlocks(c ctx, evm mech) (uint64, error) {
+	return c.State.L1TimingState().DelayedFinalityBlocks()
+}
+
+// GetMaxTxSize gets the size in bytes of the largest user tx the chain accepts, or 0 if the sequencer's
+// configuration decides
+func (con ArbOwnerPublic) GetMaxTxSize(c ctx, evm mech) (uint64, error) {
+	return c.State.MaxTxSize()
+}
+
+// GetGasSponsorship gets the paymaster paying the gas of txs calling sponsored methods, the wei of gas it may pay
+// for each sender, and the wei of gas it has paid in total
+func (con ArbOwnerPublic) GetGasSponsorship(c ctx, evm mech) (addr, huge, huge, error) {
+	sponsorship := c.State.Sponsorship()
+	paymaster, err := sponsorship.Paymaster()
+	if err != nil {
+		return addr{}, nil, nil, err
+	}
+	budget, err := sponsorship.AccountBudget()
+	if err != nil {
+		return addr{}, nil, nil, err
+	}
+	total, err := sponsorship.TotalSponsored()
+	return paymaster, budget, total, err
+}
+
+// IsGasSponsoredMethod checks whether the paymaster pays the gas of txs calling a method of the target contract
+func (con ArbOwnerPublic) IsGasSponsoredMethod(c ctx, evm mech, target addr, method bytes4) (bool, error) {
+	return c.State.Sponsorship().IsSponsoredMethod(target, method)
+}
+
+// ApproveGasSponsorship sets the wei of gas the caller agrees to pay for others while the chain owner has it as the
+// paymaster. Sponsored gas is only ever taken from a paymaster within what it has approved, and 0 withdraws it.
+func (con ArbOwnerPublic) ApproveGasSponsorship(c ctx, evm mech, allowance huge) error {
+	if allowance.Sign() < 0 || allowance.BitLen() > 256 {
+		return ErrOutOfBounds
+	}
+	return c.State.Sponsorship().SetAllowance(c.caller, allowance)
+}
+
+// GetGasSponsorshipAllowance gets the wei of gas the paymaster has approved paying for others and not yet paid
+func (con ArbOwnerPublic) GetGasSponsorshipAllowance(c ctx, evm mech, paymaster addr) (huge, error) {
+	return c.State.Sponsorship().Allowance(paymaster)
+}
+
+// GetGasSponsored gets the wei of gas the paymaster has paid for a sender, and how much more it may pay
+func (con ArbOwnerPublic) GetGasSponsored(c ctx, evm mech, sender addr) (huge, huge, error) {
+	sponsored, err := c.State.Sponsorship().Sponsored(sender)
+	if err != nil {
+		return nil, nil, err
+	}
+	remaining, err := c.State.Sponsorship().RemainingBudget(sender)
+	return sponsored, remaining, err
+}
+
+// GetFeeCollectorChangeCount gets the number of fee collector changes ever recorded, and the index of the oldest
+// one still kept
+func (con ArbOwnerPublic) GetFeeCollectorChangeCount(c ctx, evm mech) (uint64, uint64, error) {
+	history := c.State.FeeCollectorHistory()
+	size, err := history.Size()
+	if err != nil {
+		return 0, 0, err
+	}
+	oldest, err := history.Oldest()
+	return size, oldest, err
+}
+
+// GetFeeCollectorChanges gets up to count changes of the network fee account, infra fee account and L1 reward
+// recipient, oldest first, starting from the given index. Each change is the block it was made in, the collector
+// (0 for network fees, 1 for infra fees, 2 for L1 rewards), and its previous and new accounts.
+func (con ArbOwnerPublic) GetFeeCollectorChanges(c ctx, evm mech, start uint64, count uint64) ([]uint64, []uint8, []addr, []addr, error) {
+	changes, err := c.State.FeeCollectorHistory().Changes(start, count)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	blockNumbers := make([]uint64, len(changes))
+	collectors := make([]uint8, len(changes))
+	previous := make([]addr, len(changes))
+	accounts := make([]addr, len(changes))
+	for i, change := range changes {
+		blockNumbers[i] = change.BlockNumber
+		collectors[i] = uint8(change.Collector)
+		previous[i] = change.Previous
+		accounts[i] = change.Account
+	}
+	return blockNumbers, collectors, previous, accounts, nil
+}
+
+// GetScheduledChangeDelay gets how far in the future, in seconds, time-locked parameter changes must be scheduled
+func (con ArbOwnerPublic) GetScheduledChangeDelay(c ctx, evm mech) (uint64, error) {
+	return c.State.ScheduledChanges().EarliestActivation(0)
+}
+
+// GetScheduledParameterChanges gets the pending time-locked parameter changes, ordered by activation time
+func (con ArbOwnerPublic) GetScheduledParameterChanges(c ctx, evm mech) ([]uint64, []uint64, []bytes32, []uint64, error) {
+	changes, err := c.State.ScheduledChanges().Pending()
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	ids := make([]uint64, len(changes))
+	parameters := make([]uint64, len(changes))
+	values := make([]bytes32, len(changes))
+	activationTimes := make([]uint64, len(changes))
+	for i, change := range changes {
+		ids[i] = change.Id
+		parameters[i] = uint64(change.Parameter)
+		values[i] = change.Value
+		activationTimes[i] = change.ActivationTime
+	}
+	return ids, parameters, values, activationTimes, nil
+}
+
+// ChainParameters mirrors the ArbOwnerPublic.ChainParameters solidity struct.
+// Field names and order must match the ABI for the tuple to be packed.
+type ChainParameters struct {
+	ArbOSVersion                uint64
+	NetworkFeeAccount           common.Address
+	InfraFeeAccount             common.Address
+	MinimumL2BaseFee            *big.Int
+	SpeedLimitPerSecond         uint64
+	MaxTxGasLimit               uint64
+	L2PricingInertia            uint64
+	L2GasBacklogTolerance       uint64
+	L1PricePerUnit              *big.Int
+	L1PricingInertia            uint64
+	L1PricingRewardRate         uint64
+	L1PricingRewardRecipient    common.Address
+	L1PricingEquilibrationUnits *big.Int
+	PerBatchGasCharge           int64
+	AmortizedCostCapBips        uint64
+	BrotliCompressionLevel      uint64
+	CodeDepositFeePerByte       *big.Int
+	RetryableLifetimeSeconds    uint64
+}
+
+// GetAllChainParameters gets every owner-settable chain parameter in a single call
+func (con ArbOwnerPublic) GetAllChainParameters(c ctx, evm mech) (ChainParameters, error) {
+	params := ChainParameters{
+		ArbOSVersion:             c.State.ArbOSVersion(),
+		RetryableLifetimeSeconds: retryables.RetryableLifetimeSeconds,
+	}
+	l1Pricing := c.State.L1PricingState()
+	l2Pricing := c.State.L2PricingState()
+	var err error
+	if params.NetworkFeeAccount, err = con.GetNetworkFeeAccount(c, evm); err != nil {
+		return params, err
+	}
+	if params.InfraFeeAccount, err = con.GetInfraFeeAccount(c, evm); err != nil {
+		return params, err
+	}
+	if params.MinimumL2BaseFee, err = l2Pricing.MinBaseFeeWei(); err != nil {
+		return params, err
+	}
+	if params.SpeedLimitPerSecond, err = l2Pricing.SpeedLimitPerSecond(); err != nil {
+		return params, err
+	}
+	if params.MaxTxGasLimit, err = l2Pricing.PerBlockGasLimit(); err != nil {
+		return params, err
+	}
+	if params.L2PricingInertia, err = l2Pricing.PricingInertia(); err != nil {
+		return params, err
+	}
+	if params.L2GasBacklogTolerance, err = l2Pricing.BacklogTolerance(); err != nil {
+		return params, err
+	}
+	if params.CodeDepositFeePerByte, err = l2Pricing.CodeDepositFeePerByte(); err != nil {
+		return params, err
+	}
+	if params.L1PricePerUnit, err = l1Pricing.PricePerUnit(); err != nil {
+		return params, err
+	}
+	if params.L1PricingInertia, err = l1Pricing.Inertia(); err != nil {
+		return params, err
+	}
+	if params.L1PricingRewardRate, err = l1Pricing.PerUnitReward(); err != nil {
+		return params, err
+	}
+	if params.L1PricingRewardRecipient, err = l1Pricing.PayRewardsTo(); err != nil {
+		return params, err
+	}
+	if params.L1PricingEquilibrationUnits, err = l1Pricing.EquilibrationUnits(); err != nil {
+		return params, err
+	}
+	if params.PerBatchGasCharge, err = l1Pricing.PerBatchGasCost(); err != nil {
+		return params, err
+	}
+	if params.AmortizedCostCapBips, err = l1Pricing.AmortizedCostCapBips(); err != nil {
+		return params, err
+	}
+	params.BrotliCompressionLevel, err = c.State.BrotliCompressionLevel()
+	return params, err
+}
@@ -7,10 +7,12 @@ import (
 	"bytes"
 	"encoding/json"
 	"math/big"
+	"reflect"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/params"
@@ -35,7 +37,7 @@ func TestArbOwner(t *testing.T) {
 	addr2 := common.BytesToAddress(crypto.Keccak256([]byte{2})[:20])
 	addr3 := common.BytesToAddress(crypto.Keccak256([]byte{3})[:20])
 
-	prec := &ArbOwner{}
+	prec := arbOwnerForTesting()
 	gasInfo := &ArbGasInfo{}
 	callCtx := testContext(caller, evm)
 
@@ -228,3 +230,110 @@ func TestArbInfraFeeAccount(t *testing.T) {
 		t.Fatal()
 	}
 }
+
+func TestArbOwnerPublicGetAllChainParameters(t *testing.T) {
+	evm := newMockEVMForTesting()
+	caller := common.BytesToAddress(crypto.Keccak256([]byte{})[:20])
+	callCtx := testContext(caller, evm)
+	prec := arbOwnerForTesting()
+	precPublic := &ArbOwnerPublic{}
+
+	newAddr := common.BytesToAddress(crypto.Keccak256([]byte{0})[:20])
+	Require(t, prec.SetSpeedLimit(callCtx, evm, 12345))
+	Require(t, prec.SetNetworkFeeAccount(callCtx, evm, newAddr))
+	Require(t, prec.SetPerBatchGasCharge(callCtx, evm, 4321))
+
+	chainParams, err := precPublic.GetAllChainParameters(callCtx, evm)
+	Require(t, err)
+	if chainParams.SpeedLimitPerSecond != 12345 || chainParams.NetworkFeeAccount != newAddr || chainParams.PerBatchGasCharge != 4321 {
+		Fail(t, "chain parameters don't reflect owner changes", chainParams)
+	}
+	minBaseFee, err := callCtx.State.L2PricingState().MinBaseFeeWei()
+	Require(t, err)
+	if chainParams.MinimumL2BaseFee.Cmp(minBaseFee) != 0 {
+		Fail(t, "wrong minimum base fee", chainParams.MinimumL2BaseFee, minBaseFee)
+	}
+	if chainParams.ArbOSVersion != callCtx.State.ArbOSVersion() {
+		Fail(t, "wrong ArbOS version", chainParams.ArbOSVersion)
+	}
+}
+
+func TestArbOwnerFeeCollectorHistory(t *testing.T) {
+	evm := newMockEVMForTesting()
+	caller := common.BytesToAddress(crypto.Keccak256([]byte{})[:20])
+	callCtx := testContext(caller, evm)
+	prec := arbOwnerForTesting()
+	precPublic := &ArbOwnerPublic{}
+
+	networkFeeAccount, err := callCtx.State.NetworkFeeAccount()
+	Require(t, err)
+	addr1 := common.BytesToAddress(crypto.Keccak256([]byte{1})[:20])
+	addr2 := common.BytesToAddress(crypto.Keccak256([]byte{2})[:20])
+	evm.Context.BlockNumber = big.NewInt(7)
+	Require(t, prec.SetNetworkFeeAccount(callCtx, evm, addr1))
+	evm.Context.BlockNumber = big.NewInt(9)
+	Require(t, prec.SetInfraFeeAccount(callCtx, evm, addr2))
+	Require(t, prec.SetL1PricingRewardRecipient(callCtx, evm, addr2))
+
+	count, oldest, err := precPublic.GetFeeCollectorChangeCount(callCtx, evm)
+	Require(t, err)
+	if count != 3 || oldest != 0 {
+		Fail(t, "wrong change count", count, oldest)
+	}
+	blocks, collectors, previous, accounts, err := precPublic.GetFeeCollectorChanges(callCtx, evm, 0, 10)
+	Require(t, err)
+	if !reflect.DeepEqual(blocks, []uint64{7, 9, 9}) || !reflect.DeepEqual(collectors, []uint8{0, 1, 2}) {
+		Fail(t, "wrong changes", blocks, collectors)
+	}
+	if previous[0] != networkFeeAccount || accounts[0] != addr1 || accounts[1] != addr2 || accounts[2] != addr2 {
+		Fail(t, "wrong accounts", previous, accounts)
+	}
+	if len(evm.StateDB.(*state.StateDB).Logs()) != 3 {
+		Fail(t, "expected an event per change")
+	}
+}
+
+func TestArbOwnerTimeLock(t *testing.T) {
+	evm := newMockEVMForTesting()
+	caller := common.BytesToAddress(crypto.Keccak256([]byte{})[:20])
+	callCtx := testContext(caller, evm)
+	callCtx.State.SetFormatVersion(arbosState.ArbosVersion_ScheduledChanges)
+	prec := arbOwnerForTesting()
+	evm.Context.Time = 1000
+
+	// the immediate setters aren't time locked, so the chain owner can react to emergencies
+	limit, err := callCtx.State.L2PricingState().SpeedLimitPerSecond()
+	Require(t, err)
+	Require(t, prec.SetSpeedLimit(callCtx, evm, limit+1))
+	changed, err := callCtx.State.L2PricingState().SpeedLimitPerSecond()
+	Require(t, err)
+	if changed != limit+1 {
+		Fail(t, "setter didn't change the speed limit immediately", changed)
+	}
+	pending, err := callCtx.State.ScheduledChanges().Pending()
+	Require(t, err)
+	if len(pending) != 0 {
+		Fail(t, "setter scheduled a change", pending)
+	}
+
+	earliest := evm.Context.Time + arbosState.MinScheduledChangeDelay
+	_, err = prec.ScheduleSetSpeedLimit(callCtx, evm, limit+2, earliest)
+	Require(t, err)
+	pending, err = callCtx.State.ScheduledChanges().Pending()
+	Require(t, err)
+	if len(pending) != 1 || pending[0].Parameter != arbosState.ScheduledL2SpeedLimit || pending[0].ActivationTime != earliest {
+		Fail(t, "change wasn't scheduled", pending)
+	}
+	if _, err := prec.ScheduleSetSpeedLimit(callCtx, evm, limit+2, earliest-1); err == nil {
+		Fail(t, "scheduled a change inside the time lock")
+	}
+	if _, err := prec.ScheduleSetScheduledChangeDelay(callCtx, evm, arbosState.MinScheduledChangeDelay-1, earliest); err == nil {
+		Fail(t, "scheduled a delay under the minimum")
+	}
+}
+
+// arbOwnerForTesting returns the registered ArbOwner, whose events can be emitted
+func arbOwnerForTesting() *ArbOwner {
+	//nolint:errcheck
+	return Precompiles()[types.ArbOwnerAddress].Precompile().implementer.Interface().(*ArbOwner)
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/offchainlabs/nitro/arbos/arbosState"
 	"github.com/offchainlabs/nitro/arbos/retryables"
 	"github.com/offchainlabs/nitro/arbos/storage"
 	"github.com/offchainlabs/nitro/arbos/util"
@@ -23,32 +24,58 @@ type ArbRetryableTx struct {
 	LifetimeExtended        func(ctx, mech, bytes32, huge) error
 	RedeemScheduled         func(ctx, mech, bytes32, bytes32, uint64, uint64, addr, huge, huge) error
 	Canceled                func(ctx, mech, bytes32) error
+	TicketExpired           func(ctx, mech, bytes32) error
+	RedeemFailed            func(ctx, mech, bytes32, bytes32, []byte) error
 	TicketCreatedGasCost    func(bytes32) (uint64, error)
 	LifetimeExtendedGasCost func(bytes32, huge) (uint64, error)
 	RedeemScheduledGasCost  func(bytes32, bytes32, uint64, uint64, addr, huge, huge) (uint64, error)
 	CanceledGasCost         func(bytes32) (uint64, error)
+	TicketExpiredGasCost    func(bytes32) (uint64, error)
+	RedeemFailedGasCost     func(bytes32, bytes32, []byte) (uint64, error)
 
 	// deprecated event
 	Redeemed        func(ctx, mech, bytes32) error
 	RedeemedGasCost func(bytes32) (uint64, error)
 
-	NoTicketWithIDError func() error
-	NotCallableError    func() error
+	NoTicketWithIDError         func() error
+	NotCallableError            func() error
+	TicketHasExpiredError       func(bytes32, uint64) error
+	SelfModifyingRetryableError func() error
+	InsufficientRedeemGasError  func(uint64, uint64) error
+	NotBeneficiaryError         func(addr, addr) error
 }
 
 var ErrSelfModifyingRetryable = errors.New("retryable cannot modify itself")
 
-func (con ArbRetryableTx) oldNotFoundError(c ctx) error {
+func (con ArbRetryableTx) oldNotFoundError(c ctx, ticketId bytes32) error {
 	if c.State.ArbOSVersion() >= 3 {
-		return con.NoTicketWithIDError()
+		return con.notFoundError(c, ticketId)
 	}
 	return errors.New("ticketId not found")
 }
 
+// notFoundError reverts a call about a ticket that doesn't exist, saying when it expired if it has yet to be reaped
+func (con ArbRetryableTx) notFoundError(c ctx, ticketId bytes32) error {
+	if c.State.ArbOSVersion() >= arbosState.ArbosVersion_TypedPrecompileErrors {
+		timeout, err := c.State.RetryableState().Timeout(ticketId)
+		if err != nil {
+			return err
+		}
+		if timeout != 0 {
+			return con.TicketHasExpiredError(ticketId, timeout)
+		}
+	}
+	return con.NoTicketWithIDError()
+}
+
+func (con ArbRetryableTx) selfModifyingError(c ctx) error {
+	return typedRevert(c, con.SelfModifyingRetryableError(), ErrSelfModifyingRetryable)
+}
+
 // Redeem schedules an attempt to redeem the retryable, donating all of the call's gas to the redeem attempt
 func (con ArbRetryableTx) Redeem(c ctx, evm mech, ticketId bytes32) (bytes32, error) {
 	if c.txProcessor.CurrentRetryable != nil && ticketId == *c.txProcessor.CurrentRetryable {
-		return bytes32{}, ErrSelfModifyingRetryable
+		return bytes32{}, con.selfModifyingError(c)
 	}
 	retryableState := c.State.RetryableState()
 	byteCount, err := retryableState.RetryableSizeBytes(ticketId, evm.Context.Time)
@@ -65,13 +92,19 @@ func (con ArbRetryableTx) Redeem(c ctx, evm mech, ticketId bytes32) (bytes32, er
 		return hash{}, err
 	}
 	if retryable == nil {
-		return hash{}, con.oldNotFoundError(c)
+		return hash{}, con.oldNotFoundError(c, ticketId)
 	}
 	nextNonce, err := retryable.IncrementNumTries()
 	if err != nil {
 		return hash{}, err
 	}
 	nonce := nextNonce - 1
+	if c.State.FeatureEnabled(arbosState.FeatureReceiptRedeemParent) {
+		// link the retry tx back to this tx in its receipt
+		if err := retryable.SetRedeemParent(c.txProcessor.TxHash()); err != nil {
+			return hash{}, err
+		}
+	}
 
 	maxRefund := new(big.Int).Exp(common.Big2, common.Big256, nil)
 	maxRefund.Sub(maxRefund, common.Big1)
@@ -104,7 +137,8 @@ func (con ArbRetryableTx) Redeem(c ctx, evm mech, ticketId bytes32) (bytes32, er
 	}
 	gasToDonate := c.gasLeft - futureGasCosts
 	if gasToDonate < params.TxGas {
-		return hash{}, errors.New("not enough gas to run redeem attempt")
+		legacy := errors.New("not enough gas to run redeem attempt")
+		return hash{}, typedRevert(c, con.InsufficientRedeemGasError(gasToDonate, params.TxGas), legacy)
 	}
 
 	// fix up the gas in the retry
@@ -143,7 +177,7 @@ func (con ArbRetryableTx) GetTimeout(c ctx, evm mech, ticketId bytes32) (huge, e
 		return nil, err
 	}
 	if retryable == nil {
-		return nil, con.NoTicketWithIDError()
+		return nil, con.notFoundError(c, ticketId)
 	}
 	timeout, err := retryable.CalculateTimeout()
 	if err != nil {
@@ -152,6 +186,28 @@ func (con ArbRetryableTx) GetTimeout(c ctx, evm mech, ticketId bytes32) (huge, e
 	return new(big.Int).SetUint64(timeout), nil
 }
 
+// GetTimeouts gets the timestamps for when each of the tickets will expire, or zero for tickets that don't exist
+func (con ArbRetryableTx) GetTimeouts(c ctx, evm mech, ticketIds []bytes32) ([]huge, error) {
+	retryableState := c.State.RetryableState()
+	timeouts := make([]huge, len(ticketIds))
+	for i, ticketId := range ticketIds {
+		retryable, err := retryableState.OpenRetryable(ticketId, evm.Context.Time)
+		if err != nil {
+			return nil, err
+		}
+		timeouts[i] = new(big.Int)
+		if retryable == nil {
+			continue
+		}
+		timeout, err := retryable.CalculateTimeout()
+		if err != nil {
+			return nil, err
+		}
+		timeouts[i].SetUint64(timeout)
+	}
+	return timeouts, nil
+}
+
 // Keepalive adds one lifetime period to the ticket's expiry
 func (con ArbRetryableTx) Keepalive(c ctx, evm mech, ticketId bytes32) (huge, error) {
 
@@ -162,7 +218,7 @@ func (con ArbRetryableTx) Keepalive(c ctx, evm mech, ticketId bytes32) (huge, er
 		return nil, err
 	}
 	if nbytes == 0 {
-		return nil, con.oldNotFoundError(c)
+		return nil, con.oldNotFoundError(c, ticketId)
 	}
 	updateCost := arbmath.WordsForBytes(nbytes) * params.SstoreSetGas / 100
 	if err := c.Burn(updateCost); err != nil {
@@ -189,7 +245,7 @@ func (con ArbRetryableTx) GetBeneficiary(c ctx, evm mech, ticketId bytes32) (add
 		return addr{}, err
 	}
 	if retryable == nil {
-		return addr{}, con.oldNotFoundError(c)
+		return addr{}, con.oldNotFoundError(c, ticketId)
 	}
 	return retryable.Beneficiary()
 }
@@ -197,7 +253,7 @@ func (con ArbRetryableTx) GetBeneficiary(c ctx, evm mech, ticketId bytes32) (add
 // Cancel the ticket and refund its callvalue to its beneficiary
 func (con ArbRetryableTx) Cancel(c ctx, evm mech, ticketId bytes32) error {
 	if c.txProcessor.CurrentRetryable != nil && ticketId == *c.txProcessor.CurrentRetryable {
-		return ErrSelfModifyingRetryable
+		return con.selfModifyingError(c)
 	}
 	retryableState := c.State.RetryableState()
 	retryable, err := retryableState.OpenRetryable(ticketId, evm.Context.Time)
@@ -205,14 +261,15 @@ func (con ArbRetryableTx) Cancel(c ctx, evm mech, ticketId bytes32) error {
 		return err
 	}
 	if retryable == nil {
-		return con.oldNotFoundError(c)
+		return con.oldNotFoundError(c, ticketId)
 	}
 	beneficiary, err := retryable.Beneficiary()
 	if err != nil {
 		return err
 	}
 	if c.caller != beneficiary {
-		return errors.New("only the beneficiary may cancel a retryable")
+		legacy := errors.New("only the beneficiary may cancel a retryable")
+		return typedRevert(c, con.NotBeneficiaryError(c.caller, beneficiary), legacy)
 	}
 
 	// no refunds are given for deleting retryables because they use rented space
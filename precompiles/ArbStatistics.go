@@ -5,6 +5,8 @@ package precompiles
 
 import (
 	"math/big"
+
+	"github.com/offchainlabs/nitro/arbos/arbosState"
 )
 
 // ArbStatistics provides statistics about the rollup right before the Nitro upgrade.
@@ -24,3 +26,61 @@ func (con ArbStatistics) GetStats(c ctx, evm mech) (huge, huge, huge, huge, huge
 	classicNumContracts := big.NewInt(0) // TODO: hardcode the final value from Arbitrum Classic
 	return blockNum, classicNumAccounts, classicStorageSum, classicGasSum, classicNumTxes, classicNumContracts, nil
 }
+
+// GetLiveStats returns the statistics format version followed by the number of retryables created, redeemed,
+// and expired, and of L2-to-L1 sends, counted since statistics were enabled
+func (con ArbStatistics) GetLiveStats(c ctx, evm mech) (uint64, uint64, uint64, uint64, uint64, error) {
+	stats := []arbosState.Statistic{
+		arbosState.StatRetryablesCreated,
+		arbosState.StatRetryablesRedeemed,
+		arbosState.StatRetryablesExpired,
+		arbosState.StatL2ToL1Sends,
+	}
+	values := make([]uint64, len(stats))
+	for i, stat := range stats {
+		value, err := c.State.Statistic(stat)
+		if err != nil {
+			return 0, 0, 0, 0, 0, err
+		}
+		values[i] = value
+	}
+	return arbosState.StatisticsFormatVersion, values[0], values[1], values[2], values[3], nil
+}
+
+// GetStateSweepStats returns the number of expired retryables and of dead timeout queue entries whose storage
+// was reclaimed by the state sweep, counted since statistics were enabled
+func (con ArbStatistics) GetStateSweepStats(c ctx, evm mech) (uint64, uint64, error) {
+	retryables, err := c.State.Statistic(arbosState.StatRetryablesSwept)
+	if err != nil {
+		return 0, 0, err
+	}
+	queueEntries, err := c.State.Statistic(arbosState.StatTimeoutQueueEntriesSwept)
+	return retryables, queueEntries, err
+}
+
+// GetPrecompileCallCount returns the number of successful state-changing calls made to a precompile
+func (con ArbStatistics) GetPrecompileCallCount(c ctx, evm mech, precompile addr) (uint64, error) {
+	return c.State.PrecompileCalls(precompile)
+}
+
+// Statistics are maintained by ArbOS itself, so callers aren't charged for updating them. Otherwise the writes
+// would be charged after calls that pass on all but a fixed amount of their gas, such as redeem.
+func openStatisticsState(evm mech) (*arbosState.ArbosState, error) {
+	return arbosState.OpenSystemArbosState(evm.StateDB, nil, false)
+}
+
+func recordL2ToL1Sends(evm mech, count uint64) error {
+	state, err := openStatisticsState(evm)
+	if err != nil {
+		return err
+	}
+	return state.RecordStatistic(arbosState.StatL2ToL1Sends, count)
+}
+
+func recordPrecompileCall(evm mech, precompile addr) error {
+	state, err := openStatisticsState(evm)
+	if err != nil {
+		return err
+	}
+	return state.RecordPrecompileCall(precompile)
+}
@@ -9,6 +9,8 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/offchainlabs/nitro/arbos/arbosState"
+	"github.com/offchainlabs/nitro/arbos/blockhash"
 	"github.com/offchainlabs/nitro/arbos/util"
 	"github.com/offchainlabs/nitro/util/arbmath"
 	"github.com/offchainlabs/nitro/util/merkletree"
@@ -22,6 +24,10 @@ type ArbSys struct {
 	SendMerkleUpdate        func(ctx, mech, huge, bytes32, huge) error
 	SendMerkleUpdateGasCost func(huge, bytes32, huge) (uint64, error)
 	InvalidBlockNumberError func(huge, huge) error
+	MismatchedBatchError    func(uint64, uint64) error
+	EmptyBatchError         func() error
+	BatchSendsDisabledError func() error
+	CallerNotZeroError      func(addr) error
 
 	// deprecated event
 	L2ToL1Transaction        func(ctx, mech, addr, addr, huge, huge, huge, huge, huge, huge, huge, []byte) error
@@ -54,6 +60,76 @@ func (con *ArbSys) ArbBlockHash(c ctx, evm mech, arbBlockNumber *big.Int) (bytes
 	return evm.Context.GetHash(requestedBlockNum), nil
 }
 
+// GetL1BlockHashes gets, in one call, the hashes stored for up to count L1 block numbers from first, along with
+// where each came from: whether it's a placeholder for an L1 block number no L2 block started with, and the L2
+// block that stored it, which is zero if it was stored before ArbOS recorded provenance. The range is cut short
+// at the latest L1 block number, and first must be one of the 256 most recent.
+func (con *ArbSys) GetL1BlockHashes(c ctx, evm mech, first uint64, count uint64) ([]bytes32, []bool, []uint64, error) {
+	blockhashes := c.State.Blockhashes()
+	next, err := blockhashes.L1BlockNumber()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if first >= next || first+blockhash.HistoryLength < next {
+		return nil, nil, nil, con.InvalidBlockNumberError(new(big.Int).SetUint64(first), new(big.Int).SetUint64(next))
+	}
+	end := arbmath.MinInt(arbmath.SaturatingUAdd(first, count), next)
+	hashes := make([]bytes32, 0, end-first)
+	filled := make([]bool, 0, end-first)
+	recordedAt := make([]uint64, 0, end-first)
+	for number := first; number < end; number++ {
+		hash, provenance, err := blockhashes.BlockHashWithProvenance(number)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		hashes = append(hashes, hash)
+		filled = append(filled, provenance.Filled)
+		recordedAt = append(recordedAt, provenance.RecordedAt)
+	}
+	return hashes, filled, recordedAt, nil
+}
+
+// GetArbBlockHashes gets, in one call, the hashes of up to count L2 blocks from first. The range is cut short at the
+// current block, and first must be one of the 256 blocks before it.
+func (con *ArbSys) GetArbBlockHashes(c ctx, evm mech, first uint64, count uint64) ([]bytes32, error) {
+	current := evm.Context.BlockNumber.Uint64()
+	if first >= current || first+256 < current {
+		return nil, con.InvalidBlockNumberError(new(big.Int).SetUint64(first), evm.Context.BlockNumber)
+	}
+	end := arbmath.MinInt(arbmath.SaturatingUAdd(first, count), current)
+	hashes := make([]bytes32, 0, end-first)
+	for number := first; number < end; number++ {
+		hashes = append(hashes, evm.Context.GetHash(number))
+	}
+	return hashes, nil
+}
+
+// GetL1BlockNumberForArbBlock gets the L1 block number an L2 block up to the current one started with, as recorded
+// when it was sequenced. Blocks sequenced before ArbOS recorded L1 block numbers aren't known.
+func (con *ArbSys) GetL1BlockNumberForArbBlock(c ctx, evm mech, arbBlockNumber uint64) (uint64, error) {
+	if arbBlockNumber > evm.Context.BlockNumber.Uint64() {
+		return 0, con.InvalidBlockNumberError(new(big.Int).SetUint64(arbBlockNumber), evm.Context.BlockNumber)
+	}
+	return c.State.L1BlockMap().L1BlockNumber(arbBlockNumber)
+}
+
+// GetArbBlockForL1BlockNumber gets the first L2 block that started with the given L1 block number, or if none did,
+// the first that started with a later one, along with the L1 block number that block started with.
+func (con *ArbSys) GetArbBlockForL1BlockNumber(c ctx, evm mech, l1BlockNumber uint64) (uint64, uint64, error) {
+	entry, found, err := c.State.L1BlockMap().FirstL2Block(l1BlockNumber)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !found {
+		next, err := c.State.Blockhashes().L1BlockNumber()
+		if err != nil {
+			return 0, 0, err
+		}
+		return 0, 0, con.InvalidBlockNumberError(new(big.Int).SetUint64(l1BlockNumber), new(big.Int).SetUint64(next))
+	}
+	return entry.L2BlockNumber, entry.L1BlockNumber, nil
+}
+
 // ArbChainID gets the rollup's unique chain identifier
 func (con *ArbSys) ArbChainID(c ctx, evm mech) (huge, error) {
 	return evm.ChainConfig().ChainID, nil
@@ -65,7 +141,8 @@ func (con *ArbSys) ArbOSVersion(c ctx, evm mech) (huge, error) {
 	return version, nil
 }
 
-// GetStorageGasAvailable returns 0 since Nitro has no concept of storage gas
+// GetStorageGasAvailable returns 0 since Nitro has no concept of storage gas.
+// ArbGasInfo's GetBlockGasResources reports the gas left in the block instead.
 func (con *ArbSys) GetStorageGasAvailable(c ctx, evm mech) (huge, error) {
 	return big.NewInt(0), nil
 }
@@ -113,19 +190,12 @@ func (con *ArbSys) SendTxToL1(c ctx, evm mech, value huge, destination addr, cal
 		return nil, err
 	}
 	bigL1BlockNum := arbmath.UintToBig(l1BlockNum)
+	recordWithdrawal := c.State.ArbOSVersion() >= arbosState.ArbosVersion_FeeToken
 
 	arbosState := c.State
-	var t big.Int
-	t.SetUint64(evm.Context.Time)
-	sendHash, err := arbosState.KeccakHash(
-		c.caller.Bytes(),
-		destination.Bytes(),
-		arbmath.U256Bytes(evm.Context.BlockNumber),
-		arbmath.U256Bytes(bigL1BlockNum),
-		arbmath.U256Bytes(&t),
-		common.BigToHash(value).Bytes(),
-		calldataForL1,
-	)
+	sendHash, err := arbosState.KeccakHash(util.L2ToL1SendHashPreimage(
+		c.caller, destination, evm.Context.BlockNumber, bigL1BlockNum, evm.Context.Time, value, calldataForL1,
+	)...)
 	if err != nil {
 		return nil, err
 	}
@@ -134,6 +204,9 @@ func (con *ArbSys) SendTxToL1(c ctx, evm mech, value huge, destination addr, cal
 	if err != nil {
 		return nil, err
 	}
+	if err := recordL2ToL1Sends(evm, 1); err != nil {
+		return nil, err
+	}
 
 	size, err := merkleAcc.Size()
 	if err != nil {
@@ -144,6 +217,11 @@ func (con *ArbSys) SendTxToL1(c ctx, evm mech, value huge, destination addr, cal
 	if err := util.BurnBalance(&con.Address, value, evm, util.TracingDuringEVM, "withdraw"); err != nil {
 		return nil, err
 	}
+	if recordWithdrawal {
+		if err := arbosState.FeeTokenState().RecordWithdrawal(value); err != nil {
+			return nil, err
+		}
+	}
 
 	for _, merkleUpdateEvent := range merkleUpdateEvents {
 		position := merkletree.LevelAndLeaf{
@@ -186,10 +264,97 @@ func (con *ArbSys) SendTxToL1(c ctx, evm mech, value huge, destination addr, cal
 	return sendHash.Big(), err
 }
 
+// SendTxToL1Batch sends a transaction without callvalue to L1 for each destination and payload,
+// adding them to the outbox with a single update of the send merkle accumulator. Each message
+// emits the same events as an individual SendTxToL1 call.
+func (con *ArbSys) SendTxToL1Batch(c ctx, evm mech, destinations []addr, calldatasForL1 [][]byte) ([]huge, error) {
+	if len(destinations) != len(calldatasForL1) {
+		legacy := errors.New("number of destinations and payloads differ")
+		return nil, typedRevert(c, con.MismatchedBatchError(uint64(len(destinations)), uint64(len(calldatasForL1))), legacy)
+	}
+	if len(destinations) == 0 {
+		return nil, typedRevert(c, con.EmptyBatchError(), errors.New("no transactions to send"))
+	}
+	if !c.State.FeatureEnabled(arbosState.FeatureSendTxToL1Batch) {
+		return nil, typedRevert(c, con.BatchSendsDisabledError(), errors.New("batched sends are disabled on this chain"))
+	}
+	l1BlockNum, err := c.txProcessor.L1BlockNumber(vm.BlockContext{})
+	if err != nil {
+		return nil, err
+	}
+	bigL1BlockNum := arbmath.UintToBig(l1BlockNum)
+
+	arbosState := c.State
+	var blockTime big.Int
+	blockTime.SetUint64(evm.Context.Time)
+	value := big.NewInt(0)
+	sendHashes := make([]common.Hash, len(destinations))
+	for i, destination := range destinations {
+		sendHashes[i], err = arbosState.KeccakHash(util.L2ToL1SendHashPreimage(
+			c.caller, destination, evm.Context.BlockNumber, bigL1BlockNum, evm.Context.Time, value, calldatasForL1[i],
+		)...)
+		if err != nil {
+			return nil, err
+		}
+	}
+	merkleAcc := arbosState.SendMerkleAccumulator()
+	size, err := merkleAcc.Size()
+	if err != nil {
+		return nil, err
+	}
+	merkleUpdateEvents, err := merkleAcc.AppendMany(sendHashes)
+	if err != nil {
+		return nil, err
+	}
+	if err := recordL2ToL1Sends(evm, uint64(len(sendHashes))); err != nil {
+		return nil, err
+	}
+
+	leafNums := make([]huge, len(destinations))
+	for i, destination := range destinations {
+		for _, merkleUpdateEvent := range merkleUpdateEvents[i] {
+			position := merkletree.LevelAndLeaf{
+				Level: merkleUpdateEvent.Level,
+				Leaf:  merkleUpdateEvent.NumLeaves,
+			}
+			err := con.SendMerkleUpdate(
+				c,
+				evm,
+				big.NewInt(0),
+				merkleUpdateEvent.Hash,
+				position.ToBigInt(),
+			)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		leafNums[i] = new(big.Int).SetUint64(size + uint64(i))
+		err = con.L2ToL1Tx(
+			c,
+			evm,
+			c.caller,
+			destination,
+			sendHashes[i].Big(),
+			leafNums[i],
+			evm.Context.BlockNumber,
+			bigL1BlockNum,
+			&blockTime,
+			value,
+			calldatasForL1[i],
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return leafNums, nil
+}
+
 // SendMerkleTreeState gets the root, size, and partials of the outbox Merkle tree state (caller must be the 0 address)
 func (con ArbSys) SendMerkleTreeState(c ctx, evm mech) (huge, bytes32, []bytes32, error) {
 	if c.caller != (addr{}) {
-		return nil, bytes32{}, nil, errors.New("method can only be called by address zero")
+		legacy := errors.New("method can only be called by address zero")
+		return nil, bytes32{}, nil, typedRevert(c, con.CallerNotZeroError(c.caller), legacy)
 	}
 
 	// OK to not charge gas, because method is only callable by address zero
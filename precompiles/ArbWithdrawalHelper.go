@@ -0,0 +1,125 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package precompiles
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/offchainlabs/nitro/arbos/util"
+	"github.com/offchainlabs/nitro/util/arbmath"
+)
+
+// ArbWithdrawalHelperAddress is outside the address space upstream reserves for its precompiles, so chains running
+// it must declare a custom precompile range containing it
+var ArbWithdrawalHelperAddress = common.HexToAddress("0x10000")
+
+// The EIP-712 domain and type of withdrawal intents, which smart wallets sign to authorize an L2 to L1 message
+// before it's sent.
+var (
+	eip712DomainTypeHash     = crypto.Keccak256Hash([]byte("EIP712Domain(string name,string version,uint256 chainId,address verifyingContract)"))
+	withdrawalIntentTypeHash = crypto.Keccak256Hash([]byte("WithdrawalIntent(address sender,address destination,uint256 value,bytes data,uint256 nonce,uint256 deadline)"))
+	withdrawalHelperName     = crypto.Keccak256Hash([]byte("ArbWithdrawalHelper"))
+	withdrawalHelperVersion  = crypto.Keccak256Hash([]byte("1"))
+	eip712DigestPrefix       = []byte{0x19, 0x01}
+	errTimestampOutOfRange   = errors.New("timestamp doesn't fit in 64 bits")
+)
+
+// ArbWithdrawalHelper computes what identifies an L2 to L1 message without sending it: the leaf ArbSys would add
+// to the send merkle accumulator, and the EIP-712 digest of an intent to send it, so that contracts can check
+// signed withdrawal intents, and what sending them will commit to, before committing.
+type ArbWithdrawalHelper struct {
+	Address addr // 0x10000
+}
+
+// keccak hashes the data, charging the same as the KECCAK256 opcode's dynamic and static costs
+func (con ArbWithdrawalHelper) keccak(c ctx, data ...[]byte) (hash, error) {
+	byteCount := 0
+	for _, part := range data {
+		byteCount += len(part)
+	}
+	// #nosec G115
+	if err := c.Burn(30 + 6*arbmath.WordsForBytes(uint64(byteCount))); err != nil {
+		return hash{}, err
+	}
+	return crypto.Keccak256Hash(data...), nil
+}
+
+// DomainSeparator gets the EIP-712 domain separator of withdrawal intents on this chain
+func (con ArbWithdrawalHelper) DomainSeparator(c ctx, evm mech) (bytes32, error) {
+	return con.keccak(c,
+		eip712DomainTypeHash.Bytes(),
+		withdrawalHelperName.Bytes(),
+		withdrawalHelperVersion.Bytes(),
+		common.BigToHash(evm.ChainConfig().ChainID).Bytes(),
+		common.BytesToHash(con.Address.Bytes()).Bytes(),
+	)
+}
+
+// WithdrawalIntentDigest gets the EIP-712 digest a sender signs to authorize sending the L2 to L1 message,
+// with a nonce and deadline for the verifying contract to prevent replays
+func (con ArbWithdrawalHelper) WithdrawalIntentDigest(
+	c ctx, evm mech, sender addr, destination addr, value huge, data []byte, nonce huge, deadline huge,
+) (bytes32, error) {
+	domainSeparator, err := con.DomainSeparator(c, evm)
+	if err != nil {
+		return bytes32{}, err
+	}
+	dataHash, err := con.keccak(c, data)
+	if err != nil {
+		return bytes32{}, err
+	}
+	structHash, err := con.keccak(c,
+		withdrawalIntentTypeHash.Bytes(),
+		common.BytesToHash(sender.Bytes()).Bytes(),
+		common.BytesToHash(destination.Bytes()).Bytes(),
+		arbmath.U256Bytes(value),
+		dataHash.Bytes(),
+		arbmath.U256Bytes(nonce),
+		arbmath.U256Bytes(deadline),
+	)
+	if err != nil {
+		return bytes32{}, err
+	}
+	return con.keccak(c, eip712DigestPrefix, domainSeparator[:], structHash.Bytes())
+}
+
+// SendHash gets the leaf ArbSys adds to the send merkle accumulator for an L2 to L1 message with the given
+// contents, which is the hash its L2ToL1Tx event reports and the outbox proves
+func (con ArbWithdrawalHelper) SendHash(
+	c ctx, evm mech, sender addr, destination addr, arbBlockNum huge, ethBlockNum huge, timestamp huge, value huge, data []byte,
+) (bytes32, error) {
+	if !timestamp.IsUint64() {
+		return bytes32{}, errTimestampOutOfRange
+	}
+	return con.keccak(c, util.L2ToL1SendHashPreimage(
+		sender, destination, arbBlockNum, ethBlockNum, timestamp.Uint64(), value, data,
+	)...)
+}
+
+// PreviewSendTxToL1 gets the leaf and position in the send merkle accumulator the L2 to L1 message would have if
+// the sender sent it with ArbSys's sendTxToL1 next in this block. Other messages sent first move its position.
+func (con ArbWithdrawalHelper) PreviewSendTxToL1(
+	c ctx, evm mech, sender addr, destination addr, value huge, data []byte,
+) (bytes32, huge, error) {
+	l1BlockNum, err := c.txProcessor.L1BlockNumber(vm.BlockContext{})
+	if err != nil {
+		return bytes32{}, nil, err
+	}
+	sendHash, err := con.keccak(c, util.L2ToL1SendHashPreimage(
+		sender, destination, evm.Context.BlockNumber, arbmath.UintToBig(l1BlockNum), evm.Context.Time, value, data,
+	)...)
+	if err != nil {
+		return bytes32{}, nil, err
+	}
+	size, err := c.State.SendMerkleAccumulator().Size()
+	if err != nil {
+		return bytes32{}, nil, err
+	}
+	return sendHash, new(big.Int).SetUint64(size), nil
+}
@@ -0,0 +1,116 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package precompiles
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+
+	"github.com/offchainlabs/nitro/arbos/util"
+)
+
+func TestWithdrawalIntentDigest(t *testing.T) {
+	evm := newMockEVMForTesting()
+	c := testContext(common.Address{}, evm)
+	helper := ArbWithdrawalHelper{Address: ArbWithdrawalHelperAddress}
+
+	sender := common.HexToAddress("0x5e4de5")
+	destination := common.HexToAddress("0xde57")
+	value := big.NewInt(1e18)
+	data := []byte{0xca, 0x11, 0xda, 0x7a}
+	nonce := big.NewInt(7)
+	deadline := big.NewInt(1 << 40)
+
+	digest, err := helper.WithdrawalIntentDigest(c, evm, sender, destination, value, data, nonce, deadline)
+	Require(t, err)
+
+	// the digest must be what wallets compute when signing the typed data
+	typedData := apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": {
+				{Name: "name", Type: "string"},
+				{Name: "version", Type: "string"},
+				{Name: "chainId", Type: "uint256"},
+				{Name: "verifyingContract", Type: "address"},
+			},
+			"WithdrawalIntent": {
+				{Name: "sender", Type: "address"},
+				{Name: "destination", Type: "address"},
+				{Name: "value", Type: "uint256"},
+				{Name: "data", Type: "bytes"},
+				{Name: "nonce", Type: "uint256"},
+				{Name: "deadline", Type: "uint256"},
+			},
+		},
+		PrimaryType: "WithdrawalIntent",
+		Domain: apitypes.TypedDataDomain{
+			Name:              "ArbWithdrawalHelper",
+			Version:           "1",
+			ChainId:           (*math.HexOrDecimal256)(evm.ChainConfig().ChainID),
+			VerifyingContract: ArbWithdrawalHelperAddress.Hex(),
+		},
+		Message: apitypes.TypedDataMessage{
+			"sender":      sender.Hex(),
+			"destination": destination.Hex(),
+			"value":       value.String(),
+			"data":        hexutil.Encode(data),
+			"nonce":       nonce.String(),
+			"deadline":    deadline.String(),
+		},
+	}
+	expected, _, err := apitypes.TypedDataAndHash(typedData)
+	Require(t, err)
+	if !bytes.Equal(digest[:], expected) {
+		Fail(t, "wrong withdrawal intent digest", common.Hash(digest), common.BytesToHash(expected))
+	}
+
+	// any change to the intent changes the digest
+	other, err := helper.WithdrawalIntentDigest(c, evm, sender, destination, value, data, big.NewInt(8), deadline)
+	Require(t, err)
+	if other == digest {
+		Fail(t, "intents with different nonces have the same digest")
+	}
+}
+
+func TestWithdrawalSendHash(t *testing.T) {
+	evm := newMockEVMForTestingWithVersionAndRunMode(nil, core.MessageCommitMode)
+	c := testContext(common.Address{}, evm)
+	helper := ArbWithdrawalHelper{Address: ArbWithdrawalHelperAddress}
+
+	sender := common.HexToAddress("0x5e4de5")
+	destination := common.HexToAddress("0xde57")
+	value := big.NewInt(1e18)
+	data := []byte{0xca, 0x11, 0xda, 0x7a}
+	arbBlockNum, ethBlockNum, timestamp := big.NewInt(100), big.NewInt(20), big.NewInt(1_700_000_000)
+
+	sendHash, err := helper.SendHash(c, evm, sender, destination, arbBlockNum, ethBlockNum, timestamp, value, data)
+	Require(t, err)
+	expected := util.L2ToL1SendHash(sender, destination, arbBlockNum, ethBlockNum, timestamp.Uint64(), value, data)
+	if sendHash != expected {
+		Fail(t, "wrong send hash", common.Hash(sendHash), expected)
+	}
+	if _, err := helper.SendHash(c, evm, sender, destination, arbBlockNum, ethBlockNum, new(big.Int).Lsh(common.Big1, 64), value, data); err == nil {
+		Fail(t, "hashed a message with an out of range timestamp")
+	}
+
+	// a preview is for the current block and the next leaf
+	preview, position, err := helper.PreviewSendTxToL1(c, evm, sender, destination, value, data)
+	Require(t, err)
+	l1BlockNum, err := c.txProcessor.L1BlockNumber(vm.BlockContext{})
+	Require(t, err)
+	expected = util.L2ToL1SendHash(
+		sender, destination, evm.Context.BlockNumber, new(big.Int).SetUint64(l1BlockNum), evm.Context.Time, value, data,
+	)
+	if preview != expected || position.Sign() != 0 {
+		Fail(t, "wrong preview", common.Hash(preview), position)
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package precompiles
+
+import (
+	"runtime/metrics"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+// Gas auditing tallies, per precompile method, the gas charged by its calls against the work they did in Go:
+// ArbOS storage accesses, heap allocations and time. Methods whose work grows faster than their charge are
+// underpriced. Like storage access profiling it's process wide and meant for debugging: allocations are read
+// from the runtime's process-wide counters, so concurrent work such as RPC calls inflates them, and it's best run
+// on a node that only executes blocks. Auditing never changes what is charged.
+
+// MethodGasAudit is the work and gas charged of all the audited calls to one precompile method.
+type MethodGasAudit struct {
+	Calls          uint64 `json:"calls"`
+	Reverts        uint64 `json:"reverts"`
+	GasCharged     uint64 `json:"gasCharged"`
+	StorageReads   uint64 `json:"storageReads"`
+	StorageWrites  uint64 `json:"storageWrites"`
+	AllocatedBytes uint64 `json:"allocatedBytes"`
+	Allocations    uint64 `json:"allocations"`
+	Nanoseconds    uint64 `json:"nanoseconds"`
+}
+
+type gasAuditor struct {
+	mutex   sync.Mutex
+	methods map[string]*MethodGasAudit
+}
+
+var activeGasAuditor atomic.Pointer[gasAuditor]
+
+// StartGasAudit begins auditing precompile calls, discarding any previous audit.
+func StartGasAudit() {
+	activeGasAuditor.Store(&gasAuditor{methods: make(map[string]*MethodGasAudit)})
+}
+
+// StopGasAudit stops auditing precompile calls and returns the final audit.
+func StopGasAudit() map[string]MethodGasAudit {
+	audit := GasAudit()
+	activeGasAuditor.Store(nil)
+	return audit
+}
+
+func GasAuditEnabled() bool {
+	return activeGasAuditor.Load() != nil
+}
+
+// GasAudit returns a snapshot of the current audit keyed by "Precompile.method", or nil if auditing isn't enabled.
+func GasAudit() map[string]MethodGasAudit {
+	auditor := activeGasAuditor.Load()
+	if auditor == nil {
+		return nil
+	}
+	auditor.mutex.Lock()
+	defer auditor.mutex.Unlock()
+	audit := make(map[string]MethodGasAudit, len(auditor.methods))
+	for name, method := range auditor.methods {
+		audit[name] = *method
+	}
+	return audit
+}
+
+var allocationMetrics = []string{"/gc/heap/allocs:bytes", "/gc/heap/allocs:objects"}
+
+func readAllocations() (bytes uint64, objects uint64) {
+	samples := []metrics.Sample{{Name: allocationMetrics[0]}, {Name: allocationMetrics[1]}}
+	metrics.Read(samples)
+	if samples[0].Value.Kind() == metrics.KindUint64 {
+		bytes = samples[0].Value.Uint64()
+	}
+	if samples[1].Value.Kind() == metrics.KindUint64 {
+		objects = samples[1].Value.Uint64()
+	}
+	return bytes, objects
+}
+
+// auditedCall measures one precompile call from the moment it's created.
+type auditedCall struct {
+	auditor      *gasAuditor
+	start        time.Time
+	startBytes   uint64
+	startObjects uint64
+	stateDB      *auditedStateDB
+}
+
+func (a *gasAuditor) beginCall(stateDB vm.StateDB) *auditedCall {
+	call := &auditedCall{auditor: a, stateDB: &auditedStateDB{StateDB: stateDB}}
+	call.startBytes, call.startObjects = readAllocations()
+	call.start = time.Now()
+	return call
+}
+
+func (c *auditedCall) finish(method string, gasCharged uint64, reverted bool) {
+	// #nosec G115
+	elapsed := uint64(time.Since(c.start).Nanoseconds())
+	bytes, objects := readAllocations()
+	c.auditor.mutex.Lock()
+	defer c.auditor.mutex.Unlock()
+	audit, ok := c.auditor.methods[method]
+	if !ok {
+		audit = &MethodGasAudit{}
+		c.auditor.methods[method] = audit
+	}
+	audit.Calls++
+	if reverted {
+		audit.Reverts++
+	}
+	audit.GasCharged += gasCharged
+	audit.StorageReads += c.stateDB.reads
+	audit.StorageWrites += c.stateDB.writes
+	audit.AllocatedBytes += bytes - c.startBytes
+	audit.Allocations += objects - c.startObjects
+	audit.Nanoseconds += elapsed
+}
+
+// auditedStateDB counts the storage accesses of the ArbOS state a precompile call opens.
+// Accounts the call touches directly, like balances, aren't counted as the EVM prices those itself.
+type auditedStateDB struct {
+	vm.StateDB
+	reads  uint64
+	writes uint64
+}
+
+func (db *auditedStateDB) GetState(address common.Address, key common.Hash) common.Hash {
+	db.reads++
+	return db.StateDB.GetState(address, key)
+}
+
+func (db *auditedStateDB) SetState(address common.Address, key common.Hash, value common.Hash) {
+	db.writes++
+	db.StateDB.SetState(address, key, value)
+}
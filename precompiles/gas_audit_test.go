@@ -0,0 +1,49 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package precompiles
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestGasAudit(t *testing.T) {
+	evm := newMockEVMForTesting()
+	debugContractAddr := common.HexToAddress("ff")
+	contract := Precompiles()[debugContractAddr]
+	method := contract.Precompile().methodsByName["Events"]
+	data := append([]byte{}, method.template.ID...)
+	data = append(data, make([]byte, 64)...)
+	call := func() uint64 {
+		t.Helper()
+		_, gasLeft, err := contract.Call(data, debugContractAddr, debugContractAddr, common.Address{}, big.NewInt(1), false, 1_000_000, evm)
+		Require(t, err)
+		return 1_000_000 - gasLeft
+	}
+
+	call()
+	if GasAuditEnabled() || GasAudit() != nil {
+		Fail(t, "calls were audited before auditing started")
+	}
+
+	StartGasAudit()
+	charged := call() + call()
+	audit := StopGasAudit()
+	if GasAuditEnabled() {
+		Fail(t, "auditing didn't stop")
+	}
+	events, ok := audit["ArbDebug.Events"]
+	if !ok || len(audit) != 1 {
+		Fail(t, "wrong methods audited", audit)
+	}
+	if events.Calls != 2 || events.Reverts != 0 || events.GasCharged != charged {
+		Fail(t, "wrong calls audited", events)
+	}
+	// opening the ArbOS state reads its version
+	if events.StorageReads < 2 {
+		Fail(t, "storage reads weren't counted", events)
+	}
+}
@@ -115,6 +115,15 @@ func (e *SolError) Error() string {
 	return rendered
 }
 
+// typedRevert returns the typed error, a custom solidity error callers can decode, from ArbOS version
+// ArbosVersion_TypedPrecompileErrors on, and the legacy error, which reverts without data, before it.
+func typedRevert(c ctx, typed error, legacy error) error {
+	if c.State.ArbOSVersion() >= arbosState.ArbosVersion_TypedPrecompileErrors {
+		return typed
+	}
+	return legacy
+}
+
 // MakePrecompile makes a precompile for the given hardhat-to-geth bindings, ensuring that the implementer
 // supports each method.
 func MakePrecompile(metadata *bind.MetaData, implementer interface{}) (addr, *Precompile) {
@@ -435,6 +444,10 @@ func MakePrecompile(metadata *bind.MetaData, implementer interface{}) (addr, *Pr
 			}
 
 			state.AddLog(event)
+			if tracingInfo := util.NewTracingInfo(evm, address, address, util.TracingDuringEVM); tracingInfo != nil {
+				// logs added to the state directly are otherwise invisible to tracers such as the callTracer
+				tracingInfo.RecordLog(address, topics, data)
+			}
 			return []reflect.Value{nilError}
 		}
 
@@ -524,6 +537,13 @@ func Precompiles() map[addr]ArbosPrecompile {
 		return impl.Precompile()
 	}
 
+	// precompiles specific to this chain must be inserted with insertCustom, which
+	// restricts them to the custom precompile ranges declared in the chain config
+	insertCustom := func(address addr, impl ArbosPrecompile) *Precompile {
+		arbosState.CustomPrecompileAddresses[address] = true
+		return insert(address, impl)
+	}
+
 	insert(MakePrecompile(pgen.ArbInfoMetaData, &ArbInfo{Address: types.ArbInfoAddress}))
 	insert(MakePrecompile(pgen.ArbAddressTableMetaData, &ArbAddressTable{Address: types.ArbAddressTableAddress}))
 	insert(MakePrecompile(pgen.ArbBLSMetaData, &ArbBLS{Address: types.ArbBLSAddress}))
@@ -538,8 +558,23 @@ func Precompiles() map[addr]ArbosPrecompile {
 	ArbGasInfo.methodsByName["GetL1PricingFundsDueForRewards"].arbosVersion = 20
 	ArbGasInfo.methodsByName["GetL1PricingUnitsSinceUpdate"].arbosVersion = 20
 	ArbGasInfo.methodsByName["GetLastL1PricingSurplus"].arbosVersion = 20
-	insert(MakePrecompile(pgen.ArbAggregatorMetaData, &ArbAggregator{Address: types.ArbAggregatorAddress}))
-	insert(MakePrecompile(pgen.ArbStatisticsMetaData, &ArbStatistics{Address: types.ArbStatisticsAddress}))
+	ArbGasInfo.methodsByName["GetCodeDepositFeePerByte"].arbosVersion = arbosState.FeatureCodeDepositFee.ArbosVersion()
+	ArbGasInfo.methodsByName["GetPricesInFeeToken"].arbosVersion = arbosState.ArbosVersion_FeeToken
+	ArbGasInfo.methodsByName["GetFeeTokenInfo"].arbosVersion = arbosState.ArbosVersion_FeeToken
+	ArbGasInfo.methodsByName["GetBlockGasResources"].arbosVersion = arbosState.ArbosVersion_BlockGasResources
+	ArbGasInfo.methodsByName["GetCalldataPriceBips"].arbosVersion = arbosState.ArbosVersion_ChainTxLimits
+	ArbGasInfo.methodsByName["GetL1BaseFeeSmoothingWindow"].arbosVersion = arbosState.ArbosVersion_L1BaseFeeSmoothing
+	ArbGasInfo.methodsByName["GetSmoothedL1BaseFee"].arbosVersion = arbosState.ArbosVersion_L1BaseFeeSmoothing
+	ArbGasInfo.methodsByName["GetPricesInWeiAtBlock"].arbosVersion = arbosState.ArbosVersion_PriceHistory
+	ArbGasInfo.methodsByName["GetPricingHistoryDepth"].arbosVersion = arbosState.ArbosVersion_PriceHistory
+	ArbAggregator := insert(MakePrecompile(pgen.ArbAggregatorMetaData, &ArbAggregator{Address: types.ArbAggregatorAddress}))
+	ArbAggregator.methodsByName["GetRewardRecipients"].arbosVersion = arbosState.ArbosVersion_RewardSplits
+	ArbAggregator.methodsByName["SetRewardRecipients"].arbosVersion = arbosState.ArbosVersion_RewardSplits
+	ArbAggregator.methodsByName["GetBatchPostersInfo"].arbosVersion = arbosState.ArbosVersion_PosterSettlement
+	ArbStatistics := insert(MakePrecompile(pgen.ArbStatisticsMetaData, &ArbStatistics{Address: types.ArbStatisticsAddress}))
+	ArbStatistics.methodsByName["GetLiveStats"].arbosVersion = arbosState.ArbosVersion_Statistics
+	ArbStatistics.methodsByName["GetPrecompileCallCount"].arbosVersion = arbosState.ArbosVersion_Statistics
+	ArbStatistics.methodsByName["GetStateSweepStats"].arbosVersion = arbosState.ArbosVersion_StateSweep
 
 	eventCtx := func(gasLimit uint64, err error) *Context {
 		if err != nil {
@@ -557,6 +592,27 @@ func Precompiles() map[addr]ArbosPrecompile {
 	ArbOwnerPublic.methodsByName["RectifyChainOwner"].arbosVersion = 11
 	ArbOwnerPublic.methodsByName["GetBrotliCompressionLevel"].arbosVersion = 20
 	ArbOwnerPublic.methodsByName["GetScheduledUpgrade"].arbosVersion = 20
+	ArbOwnerPublic.methodsByName["IsFeatureEnabled"].arbosVersion = arbosState.ArbosVersion_FeatureFlags
+	ArbOwnerPublic.methodsByName["GetScheduledParameterChanges"].arbosVersion = arbosState.ArbosVersion_ScheduledChanges
+	ArbOwnerPublic.methodsByName["GetScheduledChangeDelay"].arbosVersion = arbosState.ArbosVersion_ScheduledChanges
+	ArbOwnerPublic.methodsByName["GetAllChainParameters"].arbosVersion = arbosState.ArbosVersion_ChainParameters
+	ArbOwnerPublic.methodsByName["GetMethodAccessExpiry"].arbosVersion = arbosState.ArbosVersion_MethodACL
+	ArbOwnerPublic.methodsByName["GetChainOwnerHistoryStart"].arbosVersion = arbosState.ArbosVersion_ChainOwnerHistory
+	ArbOwnerPublic.methodsByName["IsBatchDictionaryCommitted"].arbosVersion = arbosState.ArbosVersion_BatchDictionaries
+	ArbOwnerPublic.methodsByName["GetBatchDictionaries"].arbosVersion = arbosState.ArbosVersion_BatchDictionaries
+	ArbOwnerPublic.methodsByName["GetChainOwnerChangeCount"].arbosVersion = arbosState.ArbosVersion_ChainOwnerHistory
+	ArbOwnerPublic.methodsByName["GetChainOwnerChanges"].arbosVersion = arbosState.ArbosVersion_ChainOwnerHistory
+	ArbOwnerPublic.methodsByName["GetChainOwnersAtBlock"].arbosVersion = arbosState.ArbosVersion_ChainOwnerHistory
+	ArbOwnerPublic.methodsByName["GetParentChainBlockTime"].arbosVersion = arbosState.ArbosVersion_ParentChainTiming
+	ArbOwnerPublic.methodsByName["GetDelayedMessageFinalityBlocks"].arbosVersion = arbosState.ArbosVersion_ParentChainTiming
+	ArbOwnerPublic.methodsByName["GetFeeCollectorChangeCount"].arbosVersion = arbosState.ArbosVersion_FeeCollectorHistory
+	ArbOwnerPublic.methodsByName["GetFeeCollectorChanges"].arbosVersion = arbosState.ArbosVersion_FeeCollectorHistory
+	ArbOwnerPublic.methodsByName["GetMaxTxSize"].arbosVersion = arbosState.ArbosVersion_ChainTxLimits
+	ArbOwnerPublic.methodsByName["GetGasSponsorship"].arbosVersion = arbosState.ArbosVersion_GasSponsorship
+	ArbOwnerPublic.methodsByName["IsGasSponsoredMethod"].arbosVersion = arbosState.ArbosVersion_GasSponsorship
+	ArbOwnerPublic.methodsByName["GetGasSponsored"].arbosVersion = arbosState.ArbosVersion_GasSponsorship
+	ArbOwnerPublic.methodsByName["ApproveGasSponsorship"].arbosVersion = arbosState.ArbosVersion_GasSponsorship
+	ArbOwnerPublic.methodsByName["GetGasSponsorshipAllowance"].arbosVersion = arbosState.ArbosVersion_GasSponsorship
 
 	ArbWasmImpl := &ArbWasm{Address: types.ArbWasmAddress}
 	ArbWasm := insert(MakePrecompile(pgen.ArbWasmMetaData, ArbWasmImpl))
@@ -580,10 +636,19 @@ func Precompiles() map[addr]ArbosPrecompile {
 	ArbWasmCache.methodsByName["CacheCodehash"].maxArbosVersion = params.ArbosVersion_Stylus
 	ArbWasmCache.methodsByName["CacheProgram"].arbosVersion = params.ArbosVersion_StylusFixes
 
+	ArbWithdrawalHelper := insertCustom(MakePrecompile(pgen.ArbWithdrawalHelperMetaData, &ArbWithdrawalHelper{Address: ArbWithdrawalHelperAddress}))
+	ArbWithdrawalHelper.arbosVersion = arbosState.ArbosVersion_WithdrawalHelper
+	for _, method := range ArbWithdrawalHelper.methods {
+		method.arbosVersion = ArbWithdrawalHelper.arbosVersion
+	}
+
 	ArbRetryableImpl := &ArbRetryableTx{Address: types.ArbRetryableTxAddress}
 	ArbRetryable := insert(MakePrecompile(pgen.ArbRetryableTxMetaData, ArbRetryableImpl))
 	arbos.ArbRetryableTxAddress = ArbRetryable.address
 	arbos.RedeemScheduledEventID = ArbRetryable.events["RedeemScheduled"].template.ID
+	arbos.TicketCreatedEventID = ArbRetryable.events["TicketCreated"].template.ID
+	arbos.TicketExpiredEventID = ArbRetryable.events["TicketExpired"].template.ID
+	arbos.RedeemFailedEventID = ArbRetryable.events["RedeemFailed"].template.ID
 	arbos.EmitReedeemScheduledEvent = func(
 		evm mech, gas, nonce uint64, ticketId, retryTxHash bytes32,
 		donor addr, maxRefund *big.Int, submissionFeeRefund *big.Int,
@@ -598,11 +663,29 @@ func Precompiles() map[addr]ArbosPrecompile {
 		context := eventCtx(ArbRetryableImpl.TicketCreatedGasCost(hash{}))
 		return ArbRetryableImpl.TicketCreated(context, evm, ticketId)
 	}
+	arbos.EmitTicketExpiredEvent = func(evm mech, ticketId bytes32) error {
+		context := eventCtx(ArbRetryableImpl.TicketExpiredGasCost(hash{}))
+		return ArbRetryableImpl.TicketExpired(context, evm, ticketId)
+	}
+	arbos.EmitRedeemFailedEvent = func(evm mech, ticketId bytes32, retryTxHash bytes32, revertData []byte) error {
+		context := eventCtx(ArbRetryableImpl.RedeemFailedGasCost(hash{}, hash{}, revertData))
+		return ArbRetryableImpl.RedeemFailed(context, evm, ticketId, retryTxHash, revertData)
+	}
+	arbos.RedeemFailedEventGasCost = func(revertData []byte) uint64 {
+		cost, _ := ArbRetryableImpl.RedeemFailedGasCost(hash{}, hash{}, revertData)
+		return cost
+	}
+	ArbRetryable.methodsByName["GetTimeouts"].arbosVersion = arbosState.ArbosVersion_TicketExpiredEvent
 
 	ArbSys := insert(MakePrecompile(pgen.ArbSysMetaData, &ArbSys{Address: types.ArbSysAddress}))
 	arbos.ArbSysAddress = ArbSys.address
 	arbos.L2ToL1TransactionEventID = ArbSys.events["L2ToL1Transaction"].template.ID
 	arbos.L2ToL1TxEventID = ArbSys.events["L2ToL1Tx"].template.ID
+	ArbSys.methodsByName["SendTxToL1Batch"].arbosVersion = arbosState.FeatureSendTxToL1Batch.ArbosVersion()
+	ArbSys.methodsByName["GetL1BlockHashes"].arbosVersion = arbosState.ArbosVersion_BlockhashProvenance
+	ArbSys.methodsByName["GetArbBlockHashes"].arbosVersion = arbosState.ArbosVersion_L1BlockMap
+	ArbSys.methodsByName["GetL1BlockNumberForArbBlock"].arbosVersion = arbosState.ArbosVersion_L1BlockMap
+	ArbSys.methodsByName["GetArbBlockForL1BlockNumber"].arbosVersion = arbosState.ArbosVersion_L1BlockMap
 
 	ArbOwnerImpl := &ArbOwner{Address: types.ArbOwnerAddress}
 	emitOwnerActs := func(evm mech, method bytes4, owner addr, data []byte) error {
@@ -615,6 +698,26 @@ func Precompiles() map[addr]ArbosPrecompile {
 	ArbOwner.methodsByName["ReleaseL1PricerSurplusFunds"].arbosVersion = 10
 	ArbOwner.methodsByName["SetChainConfig"].arbosVersion = 11
 	ArbOwner.methodsByName["SetBrotliCompressionLevel"].arbosVersion = 20
+	ArbOwner.methodsByName["SetCodeDepositFeePerByte"].arbosVersion = arbosState.FeatureCodeDepositFee.ArbosVersion()
+	ArbOwner.methodsByName["SetFeatureDisabled"].arbosVersion = arbosState.ArbosVersion_FeatureFlags
+	ArbOwner.methodsByName["SetBatchPosterReimbursementCap"].arbosVersion = arbosState.ArbosVersion_PosterSettlement
+	ArbOwner.methodsByName["SetFeeToken"].arbosVersion = arbosState.ArbosVersion_FeeToken
+	ArbOwner.methodsByName["SetFeeTokenExchangeRate"].arbosVersion = arbosState.ArbosVersion_FeeToken
+	ArbOwner.methodsByName["GrantMethodAccess"].arbosVersion = arbosState.ArbosVersion_MethodACL
+	ArbOwner.methodsByName["RevokeMethodAccess"].arbosVersion = arbosState.ArbosVersion_MethodACL
+	ArbOwner.methodsByName["SetParentChainBlockTime"].arbosVersion = arbosState.ArbosVersion_ParentChainTiming
+	ArbOwner.methodsByName["SetDelayedMessageFinalityBlocks"].arbosVersion = arbosState.ArbosVersion_ParentChainTiming
+	ArbOwner.methodsByName["CommitBatchDictionary"].arbosVersion = arbosState.ArbosVersion_BatchDictionaries
+	ArbOwner.methodsByName["SetMaxTxSize"].arbosVersion = arbosState.ArbosVersion_ChainTxLimits
+	ArbOwner.methodsByName["SetPricingHistoryDepth"].arbosVersion = arbosState.ArbosVersion_PriceHistory
+	ArbOwner.methodsByName["SetCalldataPriceBips"].arbosVersion = arbosState.ArbosVersion_ChainTxLimits
+	ArbOwner.methodsByName["SetL1BaseFeeSmoothingWindow"].arbosVersion = arbosState.ArbosVersion_L1BaseFeeSmoothing
+	ArbOwner.methodsByName["SetGasSponsorPaymaster"].arbosVersion = arbosState.ArbosVersion_GasSponsorship
+	ArbOwner.methodsByName["SetGasSponsoredMethod"].arbosVersion = arbosState.ArbosVersion_GasSponsorship
+	ArbOwner.methodsByName["SetGasSponsorshipBudget"].arbosVersion = arbosState.ArbosVersion_GasSponsorship
+	for _, method := range []string{"AddChainOwner", "RemoveChainOwner", "GrantMethodAccess", "RevokeMethodAccess"} {
+		nonDelegableOwnerMethods[ArbOwner.GetMethodID(method)] = struct{}{}
+	}
 	stylusMethods := []string{
 		"SetInkPrice", "SetWasmMaxStackDepth", "SetWasmFreePages", "SetWasmPageGas",
 		"SetWasmPageLimit", "SetWasmMinInitGas", "SetWasmInitCostScalar",
@@ -624,10 +727,30 @@ func Precompiles() map[addr]ArbosPrecompile {
 	for _, method := range stylusMethods {
 		ArbOwner.methodsByName[method].arbosVersion = params.ArbosVersion_Stylus
 	}
+	scheduledChangeMethods := []string{
+		"ScheduleSetMinimumL2BaseFee", "ScheduleSetSpeedLimit", "ScheduleSetMaxTxGasLimit",
+		"ScheduleSetL2GasPricingInertia", "ScheduleSetL2GasBacklogTolerance", "ScheduleSetL1PricePerUnit",
+		"ScheduleSetL1PricingInertia", "ScheduleSetL1PricingRewardRate", "ScheduleSetPerBatchGasCharge",
+		"ScheduleSetAmortizedCostCapBips", "ScheduleSetNetworkFeeAccount", "ScheduleSetInfraFeeAccount",
+		"ScheduleSetCodeDepositFeePerByte", "ScheduleSetScheduledChangeDelay", "CancelScheduledChange",
+	}
+	for _, method := range scheduledChangeMethods {
+		ArbOwner.methodsByName[method].arbosVersion = arbosState.ArbosVersion_ScheduledChanges
+	}
+	arbos.EmitParameterChangeExecutedEvent = func(evm mech, id, parameter uint64, value bytes32) error {
+		context := eventCtx(ArbOwnerImpl.ParameterChangeExecutedGasCost(id, parameter, value))
+		return ArbOwnerImpl.ParameterChangeExecuted(context, evm, id, parameter, value)
+	}
 
-	insert(ownerOnly(ArbOwnerImpl.Address, ArbOwner, emitOwnerActs))
+	insert(ownerOnly(ArbOwnerImpl.Address, ArbOwner, emitOwnerActs, ArbOwnerImpl.NotChainOwnerError))
 	_, arbDebug := MakePrecompile(pgen.ArbDebugMetaData, &ArbDebug{Address: types.ArbDebugAddress})
 	arbDebug.methodsByName["Panic"].arbosVersion = params.ArbosVersion_Stylus
+	introspectionMethods := []string{
+		"GetRetryableQueueState", "GetL1PricingSnapshot", "GetL2PricingSnapshot", "GetAddressTableSize", "ReadArbosStorage",
+	}
+	for _, method := range introspectionMethods {
+		arbDebug.methodsByName[method].arbosVersion = arbosState.ArbosVersion_DebugIntrospection
+	}
 	insert(debugOnly(arbDebug.address, arbDebug))
 
 	ArbosActs := insert(MakePrecompile(pgen.ArbosActsMetaData, &ArbosActs{Address: types.ArbosAddress}))
@@ -660,6 +783,19 @@ func (p *Precompile) ArbosVersion() uint64 {
 	return p.arbosVersion
 }
 
+func (p *Precompile) Name() string {
+	return p.name
+}
+
+// MethodName returns the name of the method with the given selector, if the precompile has one.
+func (p *Precompile) MethodName(selector [4]byte) (string, bool) {
+	method, ok := p.methods[selector]
+	if !ok {
+		return "", false
+	}
+	return method.name, true
+}
+
 // Call a precompile in typed form, deserializing its inputs and serializing its outputs
 func (p *Precompile) Call(
 	input []byte,
@@ -704,6 +840,15 @@ func (p *Precompile) Call(
 		return nil, 0, vm.ErrExecutionReverted
 	}
 
+	stateDB := evm.StateDB
+	if auditor := activeGasAuditor.Load(); auditor != nil {
+		audited := auditor.beginCall(evm.StateDB)
+		stateDB = audited.stateDB
+		defer func() {
+			audited.finish(p.name+"."+method.name, gasSupplied-gasLeft, err != nil)
+		}()
+	}
+
 	callerCtx := &Context{
 		caller:      caller,
 		gasSupplied: gasSupplied,
@@ -720,7 +865,7 @@ func (p *Precompile) Call(
 
 	if method.purity != pure {
 		// impure methods may need the ArbOS state, so open & update the call context now
-		state, err := arbosState.OpenArbosState(evm.StateDB, callerCtx)
+		state, err := arbosState.OpenArbosState(stateDB, callerCtx)
 		if err != nil {
 			return nil, 0, err
 		}
@@ -818,6 +963,13 @@ func (p *Precompile) Call(
 		return nil, 0, vm.ErrExecutionReverted
 	}
 
+	if method.purity >= write {
+		// view calls can't write state, so only state-changing calls are counted
+		if err := recordPrecompileCall(evm, precompileAddress); err != nil {
+			return nil, 0, err
+		}
+	}
+
 	return encoded, callerCtx.gasLeft, nil
 }
 
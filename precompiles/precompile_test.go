@@ -4,6 +4,7 @@
 package precompiles
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"math/big"
@@ -14,8 +15,10 @@ import (
 	"github.com/ethereum/go-ethereum/log"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/offchainlabs/nitro/arbos/arbosState"
 	"github.com/offchainlabs/nitro/arbos/storage"
 	templates "github.com/offchainlabs/nitro/solgen/go/precompilesgen"
 	"github.com/offchainlabs/nitro/util/arbmath"
@@ -195,6 +198,7 @@ func TestPrecompilesPerArbosVersion(t *testing.T) {
 		20: 8,
 		30: 38,
 		31: 1,
+		33: 83,
 	}
 
 	precompiles := Precompiles()
@@ -216,3 +220,28 @@ func TestPrecompilesPerArbosVersion(t *testing.T) {
 		}
 	}
 }
+
+func TestTypedReverts(t *testing.T) {
+	evm := newMockEVMForTesting()
+	context := testContext(common.Address{}, evm)
+	atab := precompileForTesting[ArbAddressTable](types.ArbAddressTableAddress)
+	missing := common.HexToAddress("0xdead")
+
+	_, err := atab.Lookup(context, evm, missing)
+	var solErr *SolError
+	if !errors.As(err, &solErr) || solErr.solErr.Name != "AddressNotFound" {
+		Fail(t, "looking up a missing address didn't revert with AddressNotFound", err)
+	}
+	args, err := solErr.solErr.Unpack(solErr.data)
+	Require(t, err)
+	if len(args) != 1 || args[0] != missing {
+		Fail(t, "wrong AddressNotFound arguments", args)
+	}
+
+	// before the typed errors, reverts carry no data
+	context.State.SetFormatVersion(arbosState.ArbosVersion_TypedPrecompileErrors - 1)
+	_, err = atab.Lookup(context, evm, missing)
+	if err == nil || errors.As(err, &solErr) {
+		Fail(t, "legacy revert was a solidity error", err)
+	}
+}
@@ -54,12 +54,14 @@ func (wrapper *DebugPrecompile) Precompile() *Precompile {
 type OwnerPrecompile struct {
 	precompile  ArbosPrecompile
 	emitSuccess func(mech, bytes4, addr, []byte) error
+	notOwner    func(addr) error
 }
 
-func ownerOnly(address addr, impl ArbosPrecompile, emit func(mech, bytes4, addr, []byte) error) (addr, ArbosPrecompile) {
+func ownerOnly(address addr, impl ArbosPrecompile, emit func(mech, bytes4, addr, []byte) error, notOwner func(addr) error) (addr, ArbosPrecompile) {
 	return address, &OwnerPrecompile{
 		precompile:  impl,
 		emitSuccess: emit,
+		notOwner:    notOwner,
 	}
 }
 
@@ -91,7 +93,22 @@ func (wrapper *OwnerPrecompile) Call(
 		return nil, burner.gasLeft, err
 	}
 
+	if !isOwner && state.ArbOSVersion() >= arbosState.ArbosVersion_MethodACL && len(input) >= 4 {
+		// the chain owners may have granted the caller this specific method
+		method := *(*[4]byte)(input[:4])
+		if _, ok := nonDelegableOwnerMethods[method]; !ok {
+			isOwner, err = state.MethodACL().IsAllowed(precompileAddress, method, caller, evm.Context.Time)
+			if err != nil {
+				return nil, burner.gasLeft, err
+			}
+		}
+	}
+
 	if !isOwner {
+		var solErr *SolError
+		if state.ArbOSVersion() >= arbosState.ArbosVersion_TypedPrecompileErrors && errors.As(wrapper.notOwner(caller), &solErr) {
+			return solErr.data, burner.gasLeft, vm.ErrExecutionReverted
+		}
 		return nil, burner.gasLeft, errors.New("unauthorized caller to access-controlled method")
 	}
 
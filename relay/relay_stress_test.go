@@ -115,6 +115,7 @@ func largeBacklogRelayTestImpl(t *testing.T, numClients, backlogSize, l2MsgSize
 
 	relayConfig := &ConfigDefault
 	relayConfig.Node.Feed.Input.URL = []string{"ws://127.0.0.1:" + upStreamPort}
+	relayConfig.Node.Feed.Input.RequireSignature = false
 	relayConfig.Node.Feed.Output.Addr = "127.0.0.1"
 	relayConfig.Node.Feed.Output.Port = relayPort
 	relayConfig.Node.Feed.Output.ClientTimeout = 5 * time.Minute
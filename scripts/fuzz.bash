@@ -13,6 +13,7 @@ function printusage {
     echo "   " FuzzPrecompiles
     echo "   " FuzzInboxMultiplexer
     echo "   " FuzzStateTransition
+    echo "   " FuzzL1IncomingMessageJSON
     echo
     echo "   " duration in minutes
 }
@@ -89,6 +90,15 @@ while [[ $# -gt 0 ]]; do
             test_name=$1
             shift
             ;;
+        FuzzL1IncomingMessageJSON)
+            if [[ ! -z "$test_name" ]]; then
+                echo can only run one fuzzer at a time
+                exit 1
+            fi
+            test_group=arbos/arbostypes
+            test_name=$1
+            shift
+            ;;
         *)
             printusage
             exit
@@ -101,13 +111,13 @@ if [[ "$run_build" == "false" && -z "$test_group" ]]; then
 fi
 
 if $run_build; then
-    for build_group in system_tests arbstate; do
-        go test -c ${nitropath}/${build_group} -fuzz Fuzz -o "$binpath"/${build_group}.fuzz
+    for build_group in system_tests arbstate arbos/arbostypes; do
+        go test -c ${nitropath}/${build_group} -fuzz Fuzz -o "$binpath"/$(basename ${build_group}).fuzz
     done
 fi
 
 if [[ ! -z $test_group ]]; then
-    timeout "$((60 * duration))" "$binpath"/${test_group}.fuzz -test.run "^$" -test.fuzzcachedir "$fuzzcachepath" -test.fuzz $test_name || exit_status=$?
+    timeout "$((60 * duration))" "$binpath"/$(basename ${test_group}).fuzz -test.run "^$" -test.fuzzcachedir "$fuzzcachepath" -test.fuzz $test_name || exit_status=$?
 fi
 
 if  [ -n "$exit_status" ] && [ $exit_status -ne 0 ] && [ $exit_status -ne 124 ]; then
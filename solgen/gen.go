@@ -48,6 +48,45 @@ func (m *moduleInfo) addArtifact(artifact HardHatArtifact) {
 	m.bytecodes = append(m.bytecodes, artifact.Bytecode)
 }
 
+// extendABI appends the given entries to the named contract's ABI, adding the contract if the module lacks it.
+// Entries the ABI already declares, by type and name, are skipped so that overlays can be dropped once the
+// contracts declare them.
+func (m *moduleInfo) extendABI(name string, entries []interface{}) {
+	index := -1
+	for i, contract := range m.contractNames {
+		if contract == name {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		m.addArtifact(HardHatArtifact{ContractName: name, Abi: []interface{}{}, Bytecode: "0x"})
+		index = len(m.contractNames) - 1
+	}
+	var current []interface{}
+	if err := json.Unmarshal([]byte(m.abis[index]), &current); err != nil {
+		log.Fatal("failed to parse the abi of ", name, err)
+	}
+	declared := make(map[string]bool)
+	entryKey := func(entry interface{}) string {
+		fields, _ := entry.(map[string]interface{})
+		return fmt.Sprintf("%v %v", fields["type"], fields["name"])
+	}
+	for _, entry := range current {
+		declared[entryKey(entry)] = true
+	}
+	for _, entry := range entries {
+		if !declared[entryKey(entry)] {
+			current = append(current, entry)
+		}
+	}
+	abi, err := json.Marshal(current)
+	if err != nil {
+		log.Fatal(err)
+	}
+	m.abis[index] = string(abi)
+}
+
 func (m *moduleInfo) exportABIs(dest string) {
 	for i, name := range m.contractNames {
 		path := filepath.Join(dest, name+".abi")
@@ -167,6 +206,34 @@ func main() {
 		modInfo.addArtifact(artifact)
 	}
 
+	// add the declarations in solgen/abi, which extend the contracts' interfaces with this repo's precompile methods,
+	// events, and errors. Each file holds the ABI entries of the contract it's named after, in the module it's in.
+	overlayPaths, err := filepath.Glob(filepath.Join(root, "abi", "*", "*.json"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, path := range overlayPaths {
+		dir, file := filepath.Split(path)
+		_, module := filepath.Split(dir[:len(dir)-1])
+		module = strings.ReplaceAll(module, "-", "_") + "gen"
+		name := file[:len(file)-5]
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			log.Fatal("could not read", path, "for contract", name, err)
+		}
+		var entries []interface{}
+		if err := json.Unmarshal(data, &entries); err != nil {
+			log.Fatal("failed to parse abi overlay", name, err)
+		}
+		modInfo := modules[module]
+		if modInfo == nil {
+			modInfo = &moduleInfo{}
+			modules[module] = modInfo
+		}
+		modInfo.extendABI(name, entries)
+	}
+
 	for module, info := range modules {
 
 		code, err := bind.Bind(
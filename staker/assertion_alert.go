@@ -0,0 +1,231 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package staker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+
+	"github.com/offchainlabs/nitro/util/stopwaiter"
+	"github.com/offchainlabs/nitro/validator"
+)
+
+var (
+	invalidAssertionCounter    = metrics.NewRegisteredCounter("arb/staker/invalid_assertion", nil)
+	assertionAlertErrorCounter = metrics.NewRegisteredCounter("arb/staker/invalid_assertion/alert_error", nil)
+)
+
+type AssertionAlertConfig struct {
+	WebhookURL     string        `koanf:"webhook-url"`
+	WebhookTimeout time.Duration `koanf:"webhook-timeout"`
+	File           string        `koanf:"file"`
+}
+
+var DefaultAssertionAlertConfig = AssertionAlertConfig{
+	WebhookURL:     "",
+	WebhookTimeout: 10 * time.Second,
+	File:           "",
+}
+
+func AssertionAlertConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.String(prefix+".webhook-url", DefaultAssertionAlertConfig.WebhookURL, "URL to POST a JSON alert to when an invalid assertion is detected")
+	f.Duration(prefix+".webhook-timeout", DefaultAssertionAlertConfig.WebhookTimeout, "timeout for invalid assertion webhook requests")
+	f.String(prefix+".file", DefaultAssertionAlertConfig.File, "file to append a JSON line to for every invalid assertion detected")
+}
+
+func (c *AssertionAlertConfig) Enabled() bool {
+	return c.WebhookURL != "" || c.File != ""
+}
+
+func (c *AssertionAlertConfig) Validate() error {
+	if c.WebhookURL != "" && c.WebhookTimeout <= 0 {
+		return errors.New("invalid assertion webhook timeout must be positive")
+	}
+	return nil
+}
+
+// InvalidAssertionAlert describes an assertion the staker found to disagree with its own execution.
+type InvalidAssertionAlert struct {
+	Rollup           common.Address          `json:"rollup"`
+	NodeNum          uint64                  `json:"nodeNum"`
+	NodeHash         common.Hash             `json:"nodeHash"`
+	ParentNodeNum    uint64                  `json:"parentNodeNum"`
+	ParentChainBlock uint64                  `json:"parentChainBlockProposed"`
+	WasmModuleRoot   common.Hash             `json:"wasmModuleRoot"`
+	AssertedState    validator.GoGlobalState `json:"assertedState"`
+	Reason           string                  `json:"reason"`
+	DetectedAt       time.Time               `json:"detectedAt"`
+}
+
+// assertionAlertQueueSize bounds the alerts waiting for delivery; more are dropped and retried the next
+// time their assertion is examined.
+const assertionAlertQueueSize = 64
+
+// AssertionAlerter delivers an alert for every invalid assertion found, once per assertion, to a
+// webhook and/or an append-only file of JSON lines. Alerts are delivered from a background thread so
+// a slow webhook can't hold up the staker. Delivery failures are logged and retried the next time
+// the assertion is examined, only to the destinations that failed.
+type AssertionAlerter struct {
+	stopwaiter.StopWaiter
+	config     *AssertionAlertConfig
+	httpClient *http.Client
+	queue      chan *InvalidAssertionAlert
+
+	mutex    sync.Mutex
+	detected map[common.Hash]bool // assertions counted as invalid
+	pending  map[common.Hash]bool
+	written  map[common.Hash]bool // alerts appended to the file
+	posted   map[common.Hash]bool // alerts accepted by the webhook
+}
+
+func NewAssertionAlerter(config *AssertionAlertConfig) *AssertionAlerter {
+	return &AssertionAlerter{
+		config:     config,
+		httpClient: &http.Client{Timeout: config.WebhookTimeout},
+		queue:      make(chan *InvalidAssertionAlert, assertionAlertQueueSize),
+		detected:   make(map[common.Hash]bool),
+		pending:    make(map[common.Hash]bool),
+		written:    make(map[common.Hash]bool),
+		posted:     make(map[common.Hash]bool),
+	}
+}
+
+func (a *AssertionAlerter) Start(ctxIn context.Context) {
+	a.StopWaiter.Start(ctxIn, a)
+	a.LaunchThread(func(ctx context.Context) {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case alert := <-a.queue:
+				a.deliver(ctx, alert)
+			}
+		}
+	})
+}
+
+// Alert queues an alert for delivery unless one for the same assertion is queued or was delivered.
+// It never blocks.
+func (a *AssertionAlerter) Alert(alert *InvalidAssertionAlert) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if !a.detected[alert.NodeHash] {
+		a.detected[alert.NodeHash] = true
+		invalidAssertionCounter.Inc(1)
+	}
+	if a.pending[alert.NodeHash] || (!a.needsWrite(alert.NodeHash) && !a.needsPost(alert.NodeHash)) {
+		return
+	}
+	select {
+	case a.queue <- alert:
+		a.pending[alert.NodeHash] = true
+	default:
+		log.Error("invalid assertion alert queue is full, will retry", "node", alert.NodeNum)
+		assertionAlertErrorCounter.Inc(1)
+	}
+}
+
+// needsWrite returns whether the alert for an assertion is yet to be appended to the file.
+// The caller must hold the mutex.
+func (a *AssertionAlerter) needsWrite(nodeHash common.Hash) bool {
+	return a.config.File != "" && !a.written[nodeHash]
+}
+
+// needsPost returns whether the alert for an assertion is yet to be accepted by the webhook.
+// The caller must hold the mutex.
+func (a *AssertionAlerter) needsPost(nodeHash common.Hash) bool {
+	return a.config.WebhookURL != "" && !a.posted[nodeHash]
+}
+
+func (a *AssertionAlerter) deliver(ctx context.Context, alert *InvalidAssertionAlert) {
+	a.mutex.Lock()
+	write, post := a.needsWrite(alert.NodeHash), a.needsPost(alert.NodeHash)
+	a.mutex.Unlock()
+	written, posted := false, false
+	defer func() {
+		a.mutex.Lock()
+		defer a.mutex.Unlock()
+		delete(a.pending, alert.NodeHash)
+		if written {
+			a.written[alert.NodeHash] = true
+		}
+		if posted {
+			a.posted[alert.NodeHash] = true
+		}
+		if write != written || post != posted {
+			assertionAlertErrorCounter.Inc(1)
+		}
+	}()
+	encoded, err := json.Marshal(alert)
+	if err != nil {
+		log.Error("failed to encode invalid assertion alert", "node", alert.NodeNum, "err", err)
+		return
+	}
+	if write {
+		if err := a.appendToFile(encoded); err != nil {
+			log.Error("failed to write invalid assertion alert", "file", a.config.File, "node", alert.NodeNum, "err", err)
+		} else {
+			written = true
+		}
+	}
+	if post {
+		ctx, cancel := context.WithTimeout(ctx, a.config.WebhookTimeout)
+		defer cancel()
+		if err := a.postWebhook(ctx, encoded); err != nil {
+			log.Error("failed to deliver invalid assertion alert", "url", a.config.WebhookURL, "node", alert.NodeNum, "err", err)
+		} else {
+			posted = true
+		}
+	}
+}
+
+func (a *AssertionAlerter) isPending(nodeHash common.Hash) bool {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.pending[nodeHash]
+}
+
+func (a *AssertionAlerter) appendToFile(encoded []byte) error {
+	file, err := os.OpenFile(a.config.File, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(append(encoded, '\n'))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func (a *AssertionAlerter) postWebhook(ctx context.Context, encoded []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.config.WebhookURL, bytes.NewReader(encoded))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %v", resp.Status)
+	}
+	return nil
+}
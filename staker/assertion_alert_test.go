@@ -0,0 +1,138 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package staker
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/nitro/util/testhelpers"
+)
+
+func TestAssertionAlerter(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var mutex sync.Mutex
+	fail := true
+	var received []InvalidAssertionAlert
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if fail {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		var alert InvalidAssertionAlert
+		testhelpers.RequireImpl(t, json.NewDecoder(req.Body).Decode(&alert))
+		received = append(received, alert)
+	}))
+	defer server.Close()
+
+	alertFile := filepath.Join(t.TempDir(), "alerts.jsonl")
+	config := DefaultAssertionAlertConfig
+	config.WebhookURL = server.URL
+	config.File = alertFile
+	alerter := NewAssertionAlerter(&config)
+	alerter.Start(ctx)
+	defer alerter.StopAndWait()
+
+	alert := &InvalidAssertionAlert{
+		NodeNum:    7,
+		NodeHash:   common.HexToHash("0x1234"),
+		Reason:     "test",
+		DetectedAt: time.Unix(1700000000, 0).UTC(),
+	}
+	alertAndWait := func() {
+		t.Helper()
+		alerter.Alert(alert)
+		for alerter.isPending(alert.NodeHash) {
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+	alertAndWait()
+	mutex.Lock()
+	if len(received) != 0 {
+		t.Fatal("webhook unexpectedly accepted alert")
+	}
+	// a failed delivery is retried
+	fail = false
+	mutex.Unlock()
+	alertAndWait()
+	// a delivered alert isn't sent again
+	alertAndWait()
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(received) != 1 {
+		t.Fatal("expected exactly one delivered alert, got", len(received))
+	}
+	if received[0].NodeNum != alert.NodeNum || received[0].NodeHash != alert.NodeHash || received[0].Reason != alert.Reason {
+		t.Fatal("webhook received wrong alert", received[0])
+	}
+
+	file, err := os.Open(alertFile)
+	testhelpers.RequireImpl(t, err)
+	defer file.Close()
+	lines := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var fromFile InvalidAssertionAlert
+		testhelpers.RequireImpl(t, json.Unmarshal(scanner.Bytes(), &fromFile))
+		if fromFile.NodeHash != alert.NodeHash {
+			t.Fatal("alert file has wrong alert", fromFile)
+		}
+		lines++
+	}
+	testhelpers.RequireImpl(t, scanner.Err())
+	// the file isn't written again when retrying the webhook
+	if lines != 1 {
+		t.Fatal("expected one alert file line, got", lines)
+	}
+}
+
+func TestAssertionAlerterDoesNotBlock(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	config := DefaultAssertionAlertConfig
+	config.WebhookURL = server.URL
+	config.WebhookTimeout = 50 * time.Millisecond
+	alerter := NewAssertionAlerter(&config)
+	alerter.Start(ctx)
+	defer alerter.StopAndWait()
+
+	alert := &InvalidAssertionAlert{NodeNum: 1, NodeHash: common.HexToHash("0x01")}
+	start := time.Now()
+	alerter.Alert(alert)
+	if time.Since(start) >= config.WebhookTimeout {
+		t.Fatal("alerting waited for the webhook")
+	}
+	// the hung webhook times out and the alert is left to be retried
+	for alerter.isPending(alert.NodeHash) {
+		if time.Since(start) > 10*time.Second {
+			t.Fatal("webhook delivery didn't time out")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	alerter.mutex.Lock()
+	defer alerter.mutex.Unlock()
+	if alerter.posted[alert.NodeHash] {
+		t.Fatal("timed out alert was marked as delivered")
+	}
+}
@@ -29,7 +29,10 @@ import (
 	"github.com/offchainlabs/nitro/util/stopwaiter"
 	"github.com/offchainlabs/nitro/validator"
 	"github.com/offchainlabs/nitro/validator/client/redis"
+	"github.com/offchainlabs/nitro/validator/server_common"
 	"github.com/spf13/pflag"
+
+	validatorclient "github.com/offchainlabs/nitro/validator/client"
 )
 
 var (
@@ -100,19 +103,21 @@ type BlockValidator struct {
 }
 
 type BlockValidatorConfig struct {
-	Enable                      bool                          `koanf:"enable"`
-	RedisValidationClientConfig redis.ValidationClientConfig  `koanf:"redis-validation-client-config"`
-	ValidationServer            rpcclient.ClientConfig        `koanf:"validation-server" reload:"hot"`
-	ValidationServerConfigs     []rpcclient.ClientConfig      `koanf:"validation-server-configs"`
-	ValidationPoll              time.Duration                 `koanf:"validation-poll" reload:"hot"`
-	PrerecordedBlocks           uint64                        `koanf:"prerecorded-blocks" reload:"hot"`
-	ForwardBlocks               uint64                        `koanf:"forward-blocks" reload:"hot"`
-	CurrentModuleRoot           string                        `koanf:"current-module-root"`         // TODO(magic) requires reinitialization on hot reload
-	PendingUpgradeModuleRoot    string                        `koanf:"pending-upgrade-module-root"` // TODO(magic) requires StatelessBlockValidator recreation on hot reload
-	FailureIsFatal              bool                          `koanf:"failure-is-fatal" reload:"hot"`
-	Dangerous                   BlockValidatorDangerousConfig `koanf:"dangerous"`
-	MemoryFreeLimit             string                        `koanf:"memory-free-limit" reload:"hot"`
-	ValidationServerConfigsList string                        `koanf:"validation-server-configs-list"`
+	Enable                      bool                                 `koanf:"enable"`
+	RedisValidationClientConfig redis.ValidationClientConfig         `koanf:"redis-validation-client-config"`
+	ValidationServer            rpcclient.ClientConfig               `koanf:"validation-server" reload:"hot"`
+	ValidationServerConfigs     []rpcclient.ClientConfig             `koanf:"validation-server-configs"`
+	ValidationPoll              time.Duration                        `koanf:"validation-poll" reload:"hot"`
+	PrerecordedBlocks           uint64                               `koanf:"prerecorded-blocks" reload:"hot"`
+	ForwardBlocks               uint64                               `koanf:"forward-blocks" reload:"hot"`
+	CurrentModuleRoot           string                               `koanf:"current-module-root"`         // TODO(magic) requires reinitialization on hot reload
+	PendingUpgradeModuleRoot    string                               `koanf:"pending-upgrade-module-root"` // TODO(magic) requires StatelessBlockValidator recreation on hot reload
+	FailureIsFatal              bool                                 `koanf:"failure-is-fatal" reload:"hot"`
+	Dangerous                   BlockValidatorDangerousConfig        `koanf:"dangerous"`
+	MemoryFreeLimit             string                               `koanf:"memory-free-limit" reload:"hot"`
+	ValidationServerConfigsList string                               `koanf:"validation-server-configs-list"`
+	ValidatedCache              ValidatedCacheConfig                 `koanf:"validated-cache"`
+	ValidationPool              validatorclient.ValidationPoolConfig `koanf:"validation-pool"`
 
 	memoryFreeLimit int
 }
@@ -127,9 +132,15 @@ func (c *BlockValidatorConfig) Validate() error {
 		}
 		c.memoryFreeLimit = limit
 	}
+	if err := c.ValidatedCache.Validate(); err != nil {
+		return err
+	}
 	if err := c.RedisValidationClientConfig.Validate(); err != nil {
 		return fmt.Errorf("failed to validate redis validation client config: %w", err)
 	}
+	if err := c.ValidationPool.Validate(); err != nil {
+		return err
+	}
 	streamsEnabled := c.RedisValidationClientConfig.Enabled()
 	if len(c.ValidationServerConfigs) == 0 {
 		c.ValidationServerConfigs = []rpcclient.ClientConfig{c.ValidationServer}
@@ -151,8 +162,8 @@ func (c *BlockValidatorConfig) Validate() error {
 			if err != nil {
 				return fmt.Errorf("failed parsing validation server's url:%s err: %w", serverUrl, err)
 			}
-			if u.Scheme != "ws" && u.Scheme != "wss" {
-				return fmt.Errorf("validation server's url scheme is unsupported, it should either be ws or wss, url:%s", serverUrl)
+			if u.Scheme != "ws" && u.Scheme != "wss" && u.Scheme != "unix" {
+				return fmt.Errorf("validation server's url scheme is unsupported, it should either be ws, wss or unix, url:%s", serverUrl)
 			}
 		}
 	}
@@ -178,6 +189,8 @@ func BlockValidatorConfigAddOptions(prefix string, f *pflag.FlagSet) {
 	f.Bool(prefix+".failure-is-fatal", DefaultBlockValidatorConfig.FailureIsFatal, "failing a validation is treated as a fatal error")
 	BlockValidatorDangerousConfigAddOptions(prefix+".dangerous", f)
 	f.String(prefix+".memory-free-limit", DefaultBlockValidatorConfig.MemoryFreeLimit, "minimum free-memory limit after reaching which the blockvalidator pauses validation. Enabled by default as 1GB, to disable provide empty string")
+	ValidatedCacheConfigAddOptions(prefix+".validated-cache", f)
+	validatorclient.ValidationPoolConfigAddOptions(prefix+".validation-pool", f)
 }
 
 func BlockValidatorDangerousConfigAddOptions(prefix string, f *pflag.FlagSet) {
@@ -197,6 +210,8 @@ var DefaultBlockValidatorConfig = BlockValidatorConfig{
 	FailureIsFatal:              true,
 	Dangerous:                   DefaultBlockValidatorDangerousConfig,
 	MemoryFreeLimit:             "default",
+	ValidatedCache:              DefaultValidatedCacheConfig,
+	ValidationPool:              validatorclient.DefaultValidationPoolConfig,
 }
 
 var TestBlockValidatorConfig = BlockValidatorConfig{
@@ -212,6 +227,8 @@ var TestBlockValidatorConfig = BlockValidatorConfig{
 	FailureIsFatal:              true,
 	Dangerous:                   DefaultBlockValidatorDangerousConfig,
 	MemoryFreeLimit:             "default",
+	ValidatedCache:              DefaultValidatedCacheConfig,
+	ValidationPool:              validatorclient.TestValidationPoolConfig,
 }
 
 var DefaultBlockValidatorDangerousConfig = BlockValidatorDangerousConfig{
@@ -478,6 +495,14 @@ func (v *BlockValidator) sendRecord(s *validationStatus) error {
 	}
 
 	validatorProfileWaitToRecordHist.Update(s.profileStep())
+	if v.validatedCache != nil && v.validatedCache.AllValidated(s.Entry.Pos, v.GetModuleRootsToValidate(), s.Entry.Start, s.Entry.End) {
+		// no need to record, as the validation results will be served from the cache
+		if !s.replaceStatus(RecordSent, Prepared) {
+			return fmt.Errorf("failed status check for cached validation. Status: %v", s.getStatus())
+		}
+		nonBlockingTrigger(v.progressValidationsChan)
+		return nil
+	}
 	v.LaunchThread(func(ctx context.Context) {
 		err := v.ValidationEntryRecord(ctx, s.Entry)
 		if ctx.Err() != nil {
@@ -791,6 +816,9 @@ validationsLoop:
 					return &pos, nil // if not fatal - retry
 				}
 				validatorValidValidationsCounter.Inc(1)
+				if v.validatedCache != nil {
+					v.validatedCache.Add(pos, run.WasmModuleRoot(), validationStatus.Entry.Start, validationStatus.Entry.End)
+				}
 			}
 			err := v.writeLastValidated(validationStatus.Entry.End, wasmRoots)
 			if err != nil {
@@ -823,6 +851,16 @@ validationsLoop:
 			log.Warn("advanceValidations: aborting due to running low on memory")
 			return nil, nil
 		}
+		if currentStatus == Prepared && validationStatus.Entry.Stage != Ready &&
+			(v.validatedCache == nil || !v.validatedCache.AllValidated(pos, wasmRoots, validationStatus.Entry.Start, validationStatus.Entry.End)) {
+			// recording was skipped, but the cached results have since been evicted
+			if validationStatus.replaceStatus(Prepared, Created) {
+				if err := v.sendRecord(validationStatus); err != nil {
+					return nil, err
+				}
+			}
+			return nil, nil
+		}
 		if currentStatus == Prepared {
 			replaced := validationStatus.replaceStatus(Prepared, SendingValidation)
 			if !replaced {
@@ -832,6 +870,11 @@ validationsLoop:
 			validatorPendingValidationsGauge.Inc(1)
 			var runs []validator.ValidationRun
 			for _, moduleRoot := range wasmRoots {
+				entry := validationStatus.Entry
+				if v.validatedCache != nil && v.validatedCache.Validated(pos, moduleRoot, entry.Start, entry.End) {
+					runs = append(runs, server_common.NewValRun(containers.NewReadyPromise(entry.End, nil), moduleRoot))
+					continue
+				}
 				spawner := v.chosenValidator[moduleRoot]
 				input, err := validationStatus.Entry.ToInput(spawner.StylusArchs())
 				if err != nil && ctx.Err() == nil {
@@ -1129,6 +1172,9 @@ func (v *BlockValidator) Initialize(ctx context.Context) error {
 		if v.redisValidator != nil && validator.SpawnerSupportsModule(v.redisValidator, root) {
 			v.chosenValidator[root] = v.redisValidator
 			log.Info("validator chosen", "WasmModuleRoot", root, "chosen", "redis")
+		} else if v.validationPool != nil && validator.SpawnerSupportsModule(v.validationPool, root) {
+			v.chosenValidator[root] = v.validationPool
+			log.Info("validator chosen", "WasmModuleRoot", root, "chosen", v.validationPool.Name())
 		} else {
 			for _, spawner := range v.execSpawners {
 				if validator.SpawnerSupportsModule(spawner, root) {
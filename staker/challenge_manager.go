@@ -9,6 +9,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"time"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
@@ -21,6 +22,7 @@ import (
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/offchainlabs/nitro/arbutil"
 	"github.com/offchainlabs/nitro/solgen/go/challengegen"
+	"github.com/offchainlabs/nitro/util/arbmath"
 	"github.com/offchainlabs/nitro/validator"
 )
 
@@ -59,6 +61,7 @@ type challengeCore struct {
 	actingAs             common.Address
 	startL1Block         *big.Int
 	confirmationBlocks   int64
+	config               *ChallengeConfig
 }
 
 type ChallengeManager struct {
@@ -77,6 +80,9 @@ type ChallengeManager struct {
 	initialMachineMessageCount arbutil.MessageIndex
 	executionChallengeBackend  *ExecutionChallengeBackend
 	machineFinalStepCount      uint64
+
+	progress   challengeProgress
+	progressDb ethdb.Database // nil if progress isn't persisted
 }
 
 // NewChallengeManager constructs a new challenge manager.
@@ -91,6 +97,7 @@ func NewChallengeManager(
 	val *StatelessBlockValidator,
 	startL1Block uint64,
 	confirmationBlocks int64,
+	config *ChallengeConfig,
 ) (*ChallengeManager, error) {
 	con, err := challengegen.NewChallengeManager(challengeManagerAddr, l1client)
 	if err != nil {
@@ -134,7 +141,7 @@ func NewChallengeManager(
 	if err != nil {
 		return nil, fmt.Errorf("error creating block challenge backend for challenge %v: %w", challengeIndex, err)
 	}
-	return &ChallengeManager{
+	manager := &ChallengeManager{
 		challengeCore: &challengeCore{
 			con:                  con,
 			challengeManagerAddr: challengeManagerAddr,
@@ -144,12 +151,28 @@ func NewChallengeManager(
 			actingAs:             fromAddr,
 			startL1Block:         new(big.Int).SetUint64(startL1Block),
 			confirmationBlocks:   confirmationBlocks,
+			config:               config,
 		},
 		blockChallengeBackend: backend,
 		validator:             val,
 		wasmModuleRoot:        challengeInfo.WasmModuleRoot,
 		maxBatchesRead:        challengeInfo.MaxInboxMessages,
-	}, nil
+	}
+	if config.PersistProgress && val.db != nil {
+		manager.progressDb = val.db
+		progress, err := readChallengeProgress(val.db, challengeIndex)
+		if err != nil {
+			return nil, fmt.Errorf("error reading challenge %v progress: %w", challengeIndex, err)
+		}
+		if progress != nil {
+			manager.progress = *progress
+			log.Info(
+				"restored challenge progress", "challenge", challengeIndex, "moves", progress.Moves,
+				"execution", progress.Execution, "startStep", progress.StartStep, "endStep", progress.EndStep,
+			)
+		}
+	}
+	return manager, nil
 }
 
 // NewExecutionChallengeManager is for testing only - skips block challenges
@@ -180,6 +203,7 @@ func NewExecutionChallengeManager(
 			actingAs:             auth.From,
 			startL1Block:         new(big.Int).SetUint64(startL1Block),
 			confirmationBlocks:   confirmationBlocks,
+			config:               &DefaultChallengeConfig,
 		},
 		executionChallengeBackend: backend,
 	}, nil
@@ -349,24 +373,70 @@ func (m *ChallengeManager) IsMyTurn(ctx context.Context) (bool, error) {
 }
 
 func (m *ChallengeManager) GetChallengeState(ctx context.Context) (*ChallengeState, error) {
+	state, _, err := m.getChallengeState(ctx)
+	return state, err
+}
+
+// getChallengeState also returns the on-chain challenge info the state was resolved from.
+func (m *ChallengeManager) getChallengeState(ctx context.Context) (*ChallengeState, *challengegen.ChallengeLibChallenge, error) {
 	callOpts := &bind.CallOpts{Context: ctx}
 	var err error
 	callOpts.BlockNumber, err = m.latestConfirmedBlock(ctx)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	challengeState, err := m.con.ChallengeInfo(callOpts, m.challengeIndex)
 	if err != nil {
-		return nil, fmt.Errorf("error getting challenge %v info: %w", m.challengeIndex, err)
+		return nil, nil, fmt.Errorf("error getting challenge %v info: %w", m.challengeIndex, err)
 	}
 	if challengeState.ChallengeStateHash == (common.Hash{}) {
-		return nil, errors.New("lost challenge (state hash 0)")
+		return nil, nil, errors.New("lost challenge (state hash 0)")
 	}
 	state, err := m.resolveStateHash(ctx, challengeState.ChallengeStateHash)
 	if err != nil {
-		return nil, fmt.Errorf("error resolving challenge %v state hash %v: %w", m.challengeIndex, challengeState.ChallengeStateHash, err)
+		return nil, nil, fmt.Errorf("error resolving challenge %v state hash %v: %w", m.challengeIndex, challengeState.ChallengeStateHash, err)
 	}
-	return &state, nil
+	return &state, &challengeState, nil
+}
+
+// deferMove returns true if our move should wait for the parent chain base fee to drop below the configured limit.
+// Moves are never deferred once our challenge clock is close to running out.
+func (m *ChallengeManager) deferMove(ctx context.Context, info *challengegen.ChallengeLibChallenge) (bool, error) {
+	maxGasPrice := m.config.maxGasPrice()
+	if maxGasPrice == nil {
+		return false, nil
+	}
+	header, err := m.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("error getting latest header from client: %w", err)
+	}
+	if header.BaseFee == nil || header.BaseFee.Cmp(maxGasPrice) <= 0 {
+		return false, nil
+	}
+	elapsed := arbmath.BigSub(new(big.Int).SetUint64(header.Time), info.LastMoveTimestamp)
+	timeLeft := arbmath.BigSub(info.Current.TimeLeft, elapsed)
+	if arbmath.BigLessThan(timeLeft, big.NewInt(int64(m.config.UrgentTimeLeft/time.Second))) {
+		log.Warn("making challenge move above the gas price limit as our challenge clock is running out", "challenge", m.challengeIndex, "baseFee", header.BaseFee, "secondsLeft", timeLeft)
+		return false, nil
+	}
+	log.Info("deferring challenge move until the parent chain base fee drops", "challenge", m.challengeIndex, "baseFee", header.BaseFee, "maxGasPrice", maxGasPrice, "secondsLeft", timeLeft)
+	return true, nil
+}
+
+// recordMove logs a move we made and updates the persisted progress with it.
+func (m *ChallengeManager) recordMove(stateHash common.Hash, kind string, execution bool, startStep uint64, endStep uint64) {
+	m.progress.StateHash = stateHash
+	// #nosec G115
+	m.progress.MovedAt = uint64(time.Now().Unix())
+	m.progress.Execution = execution
+	m.progress.StartStep = startStep
+	m.progress.EndStep = endStep
+	m.progress.Moves++
+	log.Info(
+		"made challenge move", "challenge", m.challengeIndex, "move", m.progress.Moves, "kind", kind,
+		"execution", m.progress.Execution, "startStep", startStep, "endStep", endStep, "stateHash", stateHash,
+	)
+	m.saveProgress()
 }
 
 func (m *ChallengeManager) ScanChallengeState(ctx context.Context, backend ChallengeBackend, state *ChallengeState) (int, error) {
@@ -412,6 +482,9 @@ func (m *ChallengeManager) LoadExecChallengeIfExists(ctx context.Context) error
 	if m.executionChallengeBackend != nil {
 		return nil
 	}
+	if m.progress.HasExecutionChallenge {
+		return m.createExecutionBackend(ctx, m.progress.ExecutionBlockStep)
+	}
 	logs, err := m.client.FilterLogs(ctx, ethereum.FilterQuery{
 		FromBlock: m.startL1Block,
 		Addresses: []common.Address{m.challengeManagerAddr},
@@ -433,7 +506,13 @@ func (m *ChallengeManager) LoadExecChallengeIfExists(ctx context.Context) error
 	if !ev.BlockSteps.IsUint64() {
 		return fmt.Errorf("ExecutionChallengeBegun event has non-uint64 blockSteps of %v", ev.BlockSteps)
 	}
-	return m.createExecutionBackend(ctx, ev.BlockSteps.Uint64())
+	if err := m.createExecutionBackend(ctx, ev.BlockSteps.Uint64()); err != nil {
+		return err
+	}
+	m.progress.HasExecutionChallenge = true
+	m.progress.ExecutionBlockStep = ev.BlockSteps.Uint64()
+	m.saveProgress()
+	return nil
 }
 
 func (m *ChallengeManager) IssueOneStepProof(
@@ -531,10 +610,26 @@ func (m *ChallengeManager) Act(ctx context.Context) (*types.Transaction, error)
 	if !myTurn {
 		return nil, nil
 	}
-	state, err := m.GetChallengeState(ctx)
+	state, info, err := m.getChallengeState(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("error getting challenge state: %w", err)
 	}
+	if m.progress.Moves > 0 && m.progress.StateHash == info.ChallengeStateHash {
+		// #nosec G115
+		sinceMove := time.Since(time.Unix(int64(m.progress.MovedAt), 0))
+		if sinceMove < m.config.MoveRetryInterval {
+			log.Info("waiting for our challenge move to land", "challenge", m.challengeIndex, "move", m.progress.Moves, "sinceMove", sinceMove)
+			return nil, nil
+		}
+		log.Warn("our challenge move hasn't landed, making it again", "challenge", m.challengeIndex, "move", m.progress.Moves, "sinceMove", sinceMove)
+	}
+	deferMove, err := m.deferMove(ctx, info)
+	if err != nil {
+		return nil, err
+	}
+	if deferMove {
+		return nil, nil
+	}
 
 	var backend ChallengeBackend
 	if m.executionChallengeBackend != nil {
@@ -554,28 +649,39 @@ func (m *ChallengeManager) Act(ctx context.Context) (*types.Transaction, error)
 	}
 	startPosition := state.Segments[nextMovePos].Position
 	endPosition := state.Segments[nextMovePos+1].Position
+	var tx *types.Transaction
+	var kind string
+	execution := m.executionChallengeBackend != nil
 	if startPosition+1 != endPosition {
 		log.Info("bisecting execution", "challenge", m.challengeIndex, "startPosition", startPosition, "endPosition", endPosition)
-		return m.bisect(ctx, backend, state, nextMovePos)
-	}
-	if m.executionChallengeBackend != nil {
+		kind = "bisection"
+		tx, err = m.bisect(ctx, backend, state, nextMovePos)
+	} else if m.executionChallengeBackend != nil {
 		log.Info("sending onestepproof", "challenge", m.challengeIndex, "startPosition", startPosition, "endPosition", endPosition)
-		return m.IssueOneStepProof(
+		kind = "one step proof"
+		tx, err = m.IssueOneStepProof(
 			ctx,
 			state,
 			nextMovePos,
 		)
+	} else {
+		err = m.createExecutionBackend(ctx, uint64(nextMovePos))
+		if err != nil {
+			return nil, fmt.Errorf("error creating execution backend: %w", err)
+		}
+		machineStepCount := m.machineFinalStepCount
+		log.Info("issuing one step proof", "challenge", m.challengeIndex, "machineStepCount", machineStepCount, "initialCount", m.initialMachineMessageCount)
+		kind = "execution challenge"
+		tx, err = m.blockChallengeBackend.IssueExecChallenge(
+			m.challengeCore,
+			state,
+			nextMovePos,
+			machineStepCount,
+		)
 	}
-	err = m.createExecutionBackend(ctx, uint64(nextMovePos))
 	if err != nil {
-		return nil, fmt.Errorf("error creating execution backend: %w", err)
+		return nil, err
 	}
-	machineStepCount := m.machineFinalStepCount
-	log.Info("issuing one step proof", "challenge", m.challengeIndex, "machineStepCount", machineStepCount, "initialCount", m.initialMachineMessageCount)
-	return m.blockChallengeBackend.IssueExecChallenge(
-		m.challengeCore,
-		state,
-		nextMovePos,
-		machineStepCount,
-	)
+	m.recordMove(info.ChallengeStateHash, kind, execution, startPosition, endPosition)
+	return tx, nil
 }
@@ -0,0 +1,133 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package staker
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	flag "github.com/spf13/pflag"
+
+	"github.com/offchainlabs/nitro/util/arbmath"
+)
+
+type ChallengeConfig struct {
+	PersistProgress   bool          `koanf:"persist-progress"`
+	MoveRetryInterval time.Duration `koanf:"move-retry-interval"`
+	MaxGasPriceGwei   float64       `koanf:"max-gas-price-gwei"`
+	UrgentTimeLeft    time.Duration `koanf:"urgent-time-left"`
+}
+
+var DefaultChallengeConfig = ChallengeConfig{
+	PersistProgress:   true,
+	MoveRetryInterval: 10 * time.Minute,
+	MaxGasPriceGwei:   0,
+	UrgentTimeLeft:    24 * time.Hour,
+}
+
+var TestChallengeConfig = ChallengeConfig{
+	PersistProgress:   true,
+	MoveRetryInterval: 0,
+	MaxGasPriceGwei:   0,
+	UrgentTimeLeft:    time.Hour,
+}
+
+func ChallengeConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.Bool(prefix+".persist-progress", DefaultChallengeConfig.PersistProgress, "persist challenge bisection progress to the database, so a restarted validator resumes where it left off")
+	f.Duration(prefix+".move-retry-interval", DefaultChallengeConfig.MoveRetryInterval, "how long to wait for a challenge move to land before making it again for the same challenge state")
+	f.Float64(prefix+".max-gas-price-gwei", DefaultChallengeConfig.MaxGasPriceGwei, "defer challenge moves while the parent chain base fee is above this (0 = no limit)")
+	f.Duration(prefix+".urgent-time-left", DefaultChallengeConfig.UrgentTimeLeft, "make challenge moves regardless of the gas price once less than this much time is left on our challenge clock")
+}
+
+func (c *ChallengeConfig) Validate() error {
+	if c.MaxGasPriceGwei < 0 {
+		return errors.New("challenge max-gas-price-gwei can't be negative")
+	}
+	if c.MoveRetryInterval < 0 {
+		return errors.New("challenge move-retry-interval can't be negative")
+	}
+	return nil
+}
+
+func (c *ChallengeConfig) maxGasPrice() *big.Int {
+	if c.MaxGasPriceGwei == 0 {
+		return nil
+	}
+	return arbmath.FloatToBig(c.MaxGasPriceGwei * params.GWei)
+}
+
+var challengeProgressPrefix = []byte("_challengeProgress") // + challenge index, contains an rlp encoded challengeProgress
+
+// challengeProgress is what a challenge manager knows about its challenge beyond the on-chain state, persisted so a
+// restarted validator can pick the challenge back up.
+type challengeProgress struct {
+	// The on-chain challenge state hash our last move responded to, and when we made it.
+	StateHash common.Hash
+	MovedAt   uint64
+	// The step range of our last move.
+	Execution bool
+	StartStep uint64
+	EndStep   uint64
+	Moves     uint64
+	// The block challenge step the execution challenge was started at, if it has been.
+	HasExecutionChallenge bool
+	ExecutionBlockStep    uint64
+}
+
+func challengeProgressKey(challengeIndex uint64) []byte {
+	return append(append([]byte{}, challengeProgressPrefix...), uint64ToIndex(challengeIndex).Bytes()...)
+}
+
+func readChallengeProgress(db ethdb.KeyValueReader, challengeIndex uint64) (*challengeProgress, error) {
+	key := challengeProgressKey(challengeIndex)
+	exists, err := db.Has(key)
+	if err != nil || !exists {
+		return nil, err
+	}
+	data, err := db.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	var progress challengeProgress
+	if err := rlp.DecodeBytes(data, &progress); err != nil {
+		return nil, fmt.Errorf("error decoding challenge %v progress: %w", challengeIndex, err)
+	}
+	return &progress, nil
+}
+
+func writeChallengeProgress(db ethdb.KeyValueWriter, challengeIndex uint64, progress *challengeProgress) error {
+	data, err := rlp.EncodeToBytes(progress)
+	if err != nil {
+		return err
+	}
+	return db.Put(challengeProgressKey(challengeIndex), data)
+}
+
+// saveProgress persists the progress if enabled. Failing to persist it only costs time after a restart, so it
+// isn't fatal.
+func (m *ChallengeManager) saveProgress() {
+	if m.progressDb == nil {
+		return
+	}
+	if err := writeChallengeProgress(m.progressDb, m.challengeIndex, &m.progress); err != nil {
+		log.Warn("failed to persist challenge progress", "challenge", m.challengeIndex, "err", err)
+	}
+}
+
+// ForgetProgress deletes the persisted progress once the challenge is over.
+func (m *ChallengeManager) ForgetProgress() {
+	if m.progressDb == nil {
+		return
+	}
+	if err := m.progressDb.Delete(challengeProgressKey(m.challengeIndex)); err != nil {
+		log.Warn("failed to delete challenge progress", "challenge", m.challengeIndex, "err", err)
+	}
+}
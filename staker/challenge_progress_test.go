@@ -0,0 +1,53 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package staker
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+)
+
+func TestChallengeProgressPersistence(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	progress, err := readChallengeProgress(db, 3)
+	Require(t, err)
+	if progress != nil {
+		Fail(t, "found progress for a challenge that was never saved")
+	}
+
+	manager := &ChallengeManager{
+		challengeCore: &challengeCore{challengeIndex: 3, config: &TestChallengeConfig},
+		progressDb:    db,
+	}
+	manager.recordMove(common.HexToHash("0x01"), "bisection", false, 100, 200)
+	manager.progress.HasExecutionChallenge = true
+	manager.progress.ExecutionBlockStep = 7
+	manager.recordMove(common.HexToHash("0x02"), "bisection", true, 1000, 1040)
+
+	progress, err = readChallengeProgress(db, 3)
+	Require(t, err)
+	if progress == nil {
+		Fail(t, "challenge progress wasn't persisted")
+	}
+	if *progress != manager.progress {
+		Fail(t, "restored progress", progress, "doesn't match saved progress", manager.progress)
+	}
+	if progress.Moves != 2 || !progress.Execution || progress.StartStep != 1000 || progress.EndStep != 1040 || progress.ExecutionBlockStep != 7 {
+		Fail(t, "unexpected restored progress", progress)
+	}
+	other, err := readChallengeProgress(db, 4)
+	Require(t, err)
+	if other != nil {
+		Fail(t, "progress leaked into another challenge")
+	}
+
+	manager.ForgetProgress()
+	progress, err = readChallengeProgress(db, 3)
+	Require(t, err)
+	if progress != nil {
+		Fail(t, "challenge progress wasn't deleted")
+	}
+}
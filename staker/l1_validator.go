@@ -54,6 +54,7 @@ type L1Validator struct {
 	txStreamer         TransactionStreamerInterface
 	blockValidator     *BlockValidator
 	lastWasmModuleRoot common.Hash
+	assertionAlerter   *AssertionAlerter // may be nil
 }
 
 func NewL1Validator(
@@ -400,6 +401,7 @@ func (v *L1Validator) generateNodeAction(
 		}
 		if correctNode != nil {
 			log.Error("found younger sibling to correct assertion (implicitly invalid)", "node", nd.NodeNum)
+			v.alertInvalidAssertion(stakerInfo.LatestStakedNode, nd, "younger sibling of correct assertion")
 			wrongNodesExist = true
 			continue
 		}
@@ -423,12 +425,14 @@ func (v *L1Validator) generateNodeAction(
 		if nd.Assertion.AfterState.MachineStatus != validator.MachineStatusFinished {
 			wrongNodesExist = true
 			log.Error("Found incorrect assertion: Machine status not finished", "node", nd.NodeNum, "machineStatus", nd.Assertion.AfterState.MachineStatus)
+			v.alertInvalidAssertion(stakerInfo.LatestStakedNode, nd, fmt.Sprintf("machine status %v is not finished", nd.Assertion.AfterState.MachineStatus))
 			continue
 		}
 		caughtUp, nodeMsgCount, err := GlobalStateToMsgCount(v.inboxTracker, v.txStreamer, afterGS)
 		if errors.Is(err, ErrGlobalStateNotInChain) {
 			wrongNodesExist = true
 			log.Error("Found incorrect assertion", "node", nd.NodeNum, "afterGS", afterGS, "err", err)
+			v.alertInvalidAssertion(stakerInfo.LatestStakedNode, nd, err.Error())
 			continue
 		}
 		if err != nil {
@@ -563,3 +567,20 @@ func lookupNodeStartState(ctx context.Context, rollup *RollupWatcher, nodeNum ui
 	}
 	return node.AfterState(), node.InboxMaxCount, node.L1BlockProposed, node.ParentChainBlockProposed, nil
 }
+
+func (v *L1Validator) alertInvalidAssertion(parentNode uint64, nd *NodeInfo, reason string) {
+	if v.assertionAlerter == nil {
+		return
+	}
+	v.assertionAlerter.Alert(&InvalidAssertionAlert{
+		Rollup:           v.rollupAddress,
+		NodeNum:          nd.NodeNum,
+		NodeHash:         nd.NodeHash,
+		ParentNodeNum:    parentNode,
+		ParentChainBlock: nd.ParentChainBlockProposed,
+		WasmModuleRoot:   nd.WasmModuleRoot,
+		AssertedState:    nd.AfterState().GlobalState,
+		Reason:           reason,
+		DetectedAt:       time.Now(),
+	})
+}
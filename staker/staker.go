@@ -94,6 +94,8 @@ type L1ValidatorConfig struct {
 	ParentChainWallet         genericconf.WalletConfig    `koanf:"parent-chain-wallet"`
 	LogQueryBatchSize         uint64                      `koanf:"log-query-batch-size" reload:"hot"`
 	EnableFastConfirmation    bool                        `koanf:"enable-fast-confirmation"`
+	InvalidAssertionAlert     AssertionAlertConfig        `koanf:"invalid-assertion-alert"`
+	Challenge                 ChallengeConfig             `koanf:"challenge"`
 
 	strategy    StakerStrategy
 	gasRefunder common.Address
@@ -139,7 +141,10 @@ func (c *L1ValidatorConfig) Validate() error {
 		return errors.New("invalid validator gas refunder address")
 	}
 	c.gasRefunder = common.HexToAddress(c.GasRefunderAddress)
-	return nil
+	if err := c.Challenge.Validate(); err != nil {
+		return err
+	}
+	return c.InvalidAssertionAlert.Validate()
 }
 
 type L1ValidatorConfigFetcher func() *L1ValidatorConfig
@@ -164,6 +169,8 @@ var DefaultL1ValidatorConfig = L1ValidatorConfig{
 	ParentChainWallet:         DefaultValidatorL1WalletConfig,
 	LogQueryBatchSize:         0,
 	EnableFastConfirmation:    false,
+	InvalidAssertionAlert:     DefaultAssertionAlertConfig,
+	Challenge:                 DefaultChallengeConfig,
 }
 
 var TestL1ValidatorConfig = L1ValidatorConfig{
@@ -186,6 +193,8 @@ var TestL1ValidatorConfig = L1ValidatorConfig{
 	ParentChainWallet:         DefaultValidatorL1WalletConfig,
 	LogQueryBatchSize:         0,
 	EnableFastConfirmation:    false,
+	InvalidAssertionAlert:     DefaultAssertionAlertConfig,
+	Challenge:                 TestChallengeConfig,
 }
 
 var DefaultValidatorL1WalletConfig = genericconf.WalletConfig{
@@ -216,6 +225,8 @@ func L1ValidatorConfigAddOptions(prefix string, f *flag.FlagSet) {
 	DangerousConfigAddOptions(prefix+".dangerous", f)
 	genericconf.WalletConfigAddOptions(prefix+".parent-chain-wallet", f, DefaultL1ValidatorConfig.ParentChainWallet.Pathname)
 	f.Bool(prefix+".enable-fast-confirmation", DefaultL1ValidatorConfig.EnableFastConfirmation, "enable fast confirmation")
+	AssertionAlertConfigAddOptions(prefix+".invalid-assertion-alert", f)
+	ChallengeConfigAddOptions(prefix+".challenge", f)
 }
 
 type DangerousConfig struct {
@@ -315,6 +326,9 @@ func NewStaker(
 	if err != nil {
 		return nil, err
 	}
+	if config().InvalidAssertionAlert.Enabled() {
+		val.assertionAlerter = NewAssertionAlerter(&config().InvalidAssertionAlert)
+	}
 	stakerLastSuccessfulActionGauge.Update(time.Now().Unix())
 	if config().StartValidationFromStaked && blockValidator != nil {
 		stakedNotifiers = append(stakedNotifiers, blockValidator)
@@ -498,6 +512,9 @@ func (s *Staker) getLatestStakedState(ctx context.Context, staker common.Address
 
 func (s *Staker) StopAndWait() {
 	s.StopWaiter.StopAndWait()
+	if s.assertionAlerter != nil {
+		s.assertionAlerter.StopAndWait()
+	}
 	if s.Strategy() != WatchtowerStrategy {
 		s.wallet.StopAndWait()
 	}
@@ -507,6 +524,9 @@ func (s *Staker) Start(ctxIn context.Context) {
 	if s.Strategy() != WatchtowerStrategy {
 		s.wallet.Start(ctxIn)
 	}
+	if s.assertionAlerter != nil {
+		s.assertionAlerter.Start(ctxIn)
+	}
 	s.StopWaiter.Start(ctxIn, s)
 	backoff := time.Second
 	ephemeralErrorHandler := util.NewEphemeralErrorHandler(10*time.Minute, "is ahead of on-chain nonce", 0)
@@ -955,12 +975,18 @@ func (s *Staker) Act(ctx context.Context) (*types.Transaction, error) {
 
 func (s *Staker) handleConflict(ctx context.Context, info *StakerInfo) error {
 	if info.CurrentChallenge == nil {
+		if s.activeChallenge != nil {
+			s.activeChallenge.ForgetProgress()
+		}
 		s.activeChallenge = nil
 		return nil
 	}
 
 	if s.activeChallenge == nil || s.activeChallenge.ChallengeIndex() != *info.CurrentChallenge {
 		log.Error("entered challenge", "challenge", *info.CurrentChallenge)
+		if s.activeChallenge != nil {
+			s.activeChallenge.ForgetProgress()
+		}
 
 		latestConfirmedCreated, err := s.rollup.LatestConfirmedCreationBlock(ctx)
 		if err != nil {
@@ -977,6 +1003,7 @@ func (s *Staker) handleConflict(ctx context.Context, info *StakerInfo) error {
 			s.statelessBlockValidator,
 			latestConfirmedCreated,
 			s.config().ConfirmationBlocks,
+			&s.config().Challenge,
 		)
 		if err != nil {
 			return fmt.Errorf("error creating challenge manager: %w", err)
@@ -997,7 +1024,7 @@ func (s *Staker) advanceStake(ctx context.Context, info *OurStakerInfo, effectiv
 		return fmt.Errorf("error generating node action: %w", err)
 	}
 	if wrongNodesExist && effectiveStrategy == WatchtowerStrategy {
-		log.Error("found incorrect assertion in watchtower mode")
+		log.Error("found incorrect assertion in watchtower mode", "alerting", s.assertionAlerter != nil)
 	}
 	if action == nil {
 		info.CanProgress = false
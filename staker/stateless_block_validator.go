@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/offchainlabs/nitro/arbstate"
 	"github.com/offchainlabs/nitro/arbstate/daprovider"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -32,6 +33,7 @@ type StatelessBlockValidator struct {
 
 	execSpawners   []validator.ExecutionSpawner
 	redisValidator *redis.ValidationClient
+	validationPool *validatorclient.ValidationPool // nil if disabled
 
 	recorder execution.ExecutionRecorder
 
@@ -40,6 +42,9 @@ type StatelessBlockValidator struct {
 	streamer     TransactionStreamerInterface
 	db           ethdb.Database
 	dapReaders   []daprovider.Reader
+	dictionaries daprovider.DictionaryReader
+
+	validatedCache *ValidatedCache // nil if disabled
 }
 
 type BlockValidatorRegistrer interface {
@@ -209,6 +214,7 @@ func NewStatelessBlockValidator(
 	recorder execution.ExecutionRecorder,
 	arbdb ethdb.Database,
 	dapReaders []daprovider.Reader,
+	dictionaries daprovider.DictionaryReader,
 	config func() *BlockValidatorConfig,
 	stack *node.Node,
 ) (*StatelessBlockValidator, error) {
@@ -222,26 +228,46 @@ func NewStatelessBlockValidator(
 			return nil, fmt.Errorf("creating new redis validation client: %w", err)
 		}
 	}
+	var poolClients []*validatorclient.ValidationClient
 	configs := config().ValidationServerConfigs
 	for i := range configs {
 		i := i
 		confFetcher := func() *rpcclient.ClientConfig { return &config().ValidationServerConfigs[i] }
-		executionSpawners = append(executionSpawners, validatorclient.NewExecutionClient(confFetcher, stack))
+		execClient := validatorclient.NewExecutionClient(confFetcher, stack)
+		executionSpawners = append(executionSpawners, execClient)
+		poolClients = append(poolClients, &execClient.ValidationClient)
 	}
 
 	if len(executionSpawners) == 0 {
 		return nil, errors.New("no enabled execution servers")
 	}
 
+	var validationPool *validatorclient.ValidationPool
+	if config().ValidationPool.Enable {
+		var err error
+		validationPool, err = validatorclient.NewValidationPool(&config().ValidationPool, poolClients)
+		if err != nil {
+			return nil, fmt.Errorf("creating validation pool: %w", err)
+		}
+	}
+
+	var validatedCache *ValidatedCache
+	if config().ValidatedCache.Enable {
+		validatedCache = NewValidatedCache(&config().ValidatedCache)
+	}
+
 	return &StatelessBlockValidator{
+		validatedCache: validatedCache,
 		config:         config(),
 		recorder:       recorder,
 		redisValidator: redisValClient,
+		validationPool: validationPool,
 		inboxReader:    inboxReader,
 		inboxTracker:   inbox,
 		streamer:       streamer,
 		db:             arbdb,
 		dapReaders:     dapReaders,
+		dictionaries:   dictionaries,
 		execSpawners:   executionSpawners,
 	}, nil
 }
@@ -320,11 +346,13 @@ func (v *StatelessBlockValidator) ValidationEntryRecord(ctx context.Context, e *
 		if len(batch.Data) <= 40 {
 			continue
 		}
+		payload := batch.Data[40:]
 		foundDA := false
 		for _, dapReader := range v.dapReaders {
 			if dapReader != nil && dapReader.IsValidHeaderByte(batch.Data[40]) {
 				preimageRecorder := daprovider.RecordPreimagesTo(e.Preimages)
-				_, err := dapReader.RecoverPayloadFromBatch(ctx, batch.Number, batch.BlockHash, batch.Data, preimageRecorder, true)
+				recovered, err := dapReader.RecoverPayloadFromBatch(ctx, batch.Number, batch.BlockHash, batch.Data, preimageRecorder, true)
+				payload = recovered
 				if err != nil {
 					// Matches the way keyset validation was done inside DAS readers i.e logging the error
 					//  But other daproviders might just want to return the error
@@ -343,6 +371,12 @@ func (v *StatelessBlockValidator) ValidationEntryRecord(ctx context.Context, e *
 				log.Error("No DAS Reader configured, but sequencer message found with DAS header")
 			}
 		}
+		// The inbox tracker stops at batches compressed with a committed dictionary the node doesn't have, so one it
+		// doesn't have wasn't committed to. The batch is invalid, and replaying it doesn't need the dictionary.
+		err := arbstate.RecordBatchDictionary(ctx, payload, v.dictionaries, daprovider.RecordPreimagesTo(e.Preimages))
+		if err != nil && !errors.Is(err, daprovider.ErrUnknownDictionary) {
+			return err
+		}
 	}
 
 	e.msg = nil // no longer needed
@@ -377,12 +411,26 @@ func (v *StatelessBlockValidator) GlobalStatePositionsAtCount(count arbutil.Mess
 	return GlobalStatePositionsAtCount(v.inboxTracker, count, batch)
 }
 
-func (v *StatelessBlockValidator) CreateReadyValidationEntry(ctx context.Context, pos arbutil.MessageIndex) (*validationEntry, error) {
-	msg, err := v.streamer.GetMessage(pos)
+// expectedGlobalStates returns the global states before and after executing message pos,
+// according to the local execution results.
+func (v *StatelessBlockValidator) expectedGlobalStates(pos arbutil.MessageIndex) (validator.GoGlobalState, validator.GoGlobalState, error) {
+	result, err := v.streamer.ResultAtCount(pos + 1)
 	if err != nil {
-		return nil, err
+		return validator.GoGlobalState{}, validator.GoGlobalState{}, err
 	}
-	result, err := v.streamer.ResultAtCount(pos + 1)
+	prevResult, err := v.streamer.ResultAtCount(pos)
+	if err != nil {
+		return validator.GoGlobalState{}, validator.GoGlobalState{}, err
+	}
+	startPos, endPos, err := v.GlobalStatePositionsAtCount(pos + 1)
+	if err != nil {
+		return validator.GoGlobalState{}, validator.GoGlobalState{}, fmt.Errorf("failed calculating position for validation: %w", err)
+	}
+	return buildGlobalState(*prevResult, startPos), buildGlobalState(*result, endPos), nil
+}
+
+func (v *StatelessBlockValidator) CreateReadyValidationEntry(ctx context.Context, pos arbutil.MessageIndex) (*validationEntry, error) {
+	msg, err := v.streamer.GetMessage(pos)
 	if err != nil {
 		return nil, err
 	}
@@ -394,17 +442,11 @@ func (v *StatelessBlockValidator) CreateReadyValidationEntry(ctx context.Context
 		}
 		prevDelayed = prev.DelayedMessagesRead
 	}
-	prevResult, err := v.streamer.ResultAtCount(pos)
+	start, end, err := v.expectedGlobalStates(pos)
 	if err != nil {
 		return nil, err
 	}
-	startPos, endPos, err := v.GlobalStatePositionsAtCount(pos + 1)
-	if err != nil {
-		return nil, fmt.Errorf("failed calculating position for validation: %w", err)
-	}
-	start := buildGlobalState(*prevResult, startPos)
-	end := buildGlobalState(*result, endPos)
-	seqMsg, batchBlockHash, err := v.inboxReader.GetSequencerMessageBytes(ctx, startPos.BatchNumber)
+	seqMsg, batchBlockHash, err := v.inboxReader.GetSequencerMessageBytes(ctx, start.Batch)
 	if err != nil {
 		return nil, err
 	}
@@ -423,6 +465,15 @@ func (v *StatelessBlockValidator) CreateReadyValidationEntry(ctx context.Context
 func (v *StatelessBlockValidator) ValidateResult(
 	ctx context.Context, pos arbutil.MessageIndex, useExec bool, moduleRoot common.Hash,
 ) (bool, *validator.GoGlobalState, error) {
+	if v.validatedCache != nil {
+		start, end, err := v.expectedGlobalStates(pos)
+		if err != nil {
+			return false, nil, err
+		}
+		if v.validatedCache.Validated(pos, moduleRoot, start, end) {
+			return true, &end, nil
+		}
+	}
 	entry, err := v.CreateReadyValidationEntry(ctx, pos)
 	if err != nil {
 		return false, nil, err
@@ -439,6 +490,13 @@ func (v *StatelessBlockValidator) ValidateResult(
 			}
 		}
 	}
+	if run == nil && v.validationPool != nil && validator.SpawnerSupportsModule(v.validationPool, moduleRoot) {
+		input, err := entry.ToInput(v.validationPool.StylusArchs())
+		if err != nil {
+			return false, nil, err
+		}
+		run = v.validationPool.Launch(input, moduleRoot)
+	}
 	if run == nil {
 		for _, spawner := range v.execSpawners {
 			if validator.SpawnerSupportsModule(spawner, moduleRoot) {
@@ -459,6 +517,9 @@ func (v *StatelessBlockValidator) ValidateResult(
 	if err != nil || gsEnd != entry.End {
 		return false, &gsEnd, err
 	}
+	if v.validatedCache != nil {
+		v.validatedCache.Add(pos, moduleRoot, entry.Start, entry.End)
+	}
 	return true, &entry.End, nil
 }
 
@@ -492,10 +553,18 @@ func (v *StatelessBlockValidator) Start(ctx_in context.Context) error {
 			return err
 		}
 	}
+	if v.validationPool != nil {
+		if err := v.validationPool.Start(ctx_in); err != nil {
+			return fmt.Errorf("starting validation pool: %w", err)
+		}
+	}
 	return nil
 }
 
 func (v *StatelessBlockValidator) Stop() {
+	if v.validationPool != nil {
+		v.validationPool.Stop()
+	}
 	for _, spawner := range v.execSpawners {
 		spawner.Stop()
 	}
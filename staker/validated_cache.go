@@ -0,0 +1,102 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package staker
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/metrics"
+
+	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/util/containers"
+	"github.com/offchainlabs/nitro/validator"
+)
+
+var (
+	validatedCacheHitCounter  = metrics.NewRegisteredCounter("arb/validator/validated_cache/hit", nil)
+	validatedCacheMissCounter = metrics.NewRegisteredCounter("arb/validator/validated_cache/miss", nil)
+)
+
+type ValidatedCacheConfig struct {
+	Enable      bool `koanf:"enable"`
+	MaxMemoryMB int  `koanf:"max-memory-mb"`
+}
+
+var DefaultValidatedCacheConfig = ValidatedCacheConfig{
+	Enable:      false,
+	MaxMemoryMB: 64,
+}
+
+func ValidatedCacheConfigAddOptions(prefix string, f *pflag.FlagSet) {
+	f.Bool(prefix+".enable", DefaultValidatedCacheConfig.Enable, "remember successfully validated messages so that repeated validations of the same message and wasm module root are not re-executed (skips re-checking them against the execution server)")
+	f.Int(prefix+".max-memory-mb", DefaultValidatedCacheConfig.MaxMemoryMB, "approximate upper bound on the memory used by the validated cache in megabytes")
+}
+
+func (c *ValidatedCacheConfig) Validate() error {
+	if c.Enable && c.MaxMemoryMB <= 0 {
+		return errors.New("validated-cache max-memory-mb must be positive when enabled")
+	}
+	return nil
+}
+
+// validatedCacheEntrySize is a conservative estimate of the memory used by one cache entry,
+// including its key, both global states and the LRU bookkeeping.
+const validatedCacheEntrySize = 384
+
+type validatedCacheKey struct {
+	pos        arbutil.MessageIndex
+	moduleRoot common.Hash
+}
+
+type validatedCacheValue struct {
+	start validator.GoGlobalState
+	end   validator.GoGlobalState
+}
+
+// ValidatedCache remembers messages that were successfully validated under a wasm module root.
+// A cached result is only used if the start and end global states match, so results recorded
+// before a reorg are never served for the new chain.
+type ValidatedCache struct {
+	mutex sync.Mutex
+	lru   *containers.LruCache[validatedCacheKey, validatedCacheValue]
+}
+
+func NewValidatedCache(config *ValidatedCacheConfig) *ValidatedCache {
+	return &ValidatedCache{
+		lru: containers.NewLruCache[validatedCacheKey, validatedCacheValue](config.MaxMemoryMB * 1024 * 1024 / validatedCacheEntrySize),
+	}
+}
+
+func (c *ValidatedCache) Add(pos arbutil.MessageIndex, moduleRoot common.Hash, start, end validator.GoGlobalState) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.lru.Add(validatedCacheKey{pos, moduleRoot}, validatedCacheValue{start, end})
+}
+
+// Validated returns true if executing message pos from start under moduleRoot is known to reach end.
+func (c *ValidatedCache) Validated(pos arbutil.MessageIndex, moduleRoot common.Hash, start, end validator.GoGlobalState) bool {
+	c.mutex.Lock()
+	value, ok := c.lru.Get(validatedCacheKey{pos, moduleRoot})
+	c.mutex.Unlock()
+	if ok && value.start == start && value.end == end {
+		validatedCacheHitCounter.Inc(1)
+		return true
+	}
+	validatedCacheMissCounter.Inc(1)
+	return false
+}
+
+// AllValidated returns true if every module root is known to validate message pos from start to end.
+func (c *ValidatedCache) AllValidated(pos arbutil.MessageIndex, moduleRoots []common.Hash, start, end validator.GoGlobalState) bool {
+	for _, moduleRoot := range moduleRoots {
+		if !c.Validated(pos, moduleRoot, start, end) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,54 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package staker
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/validator"
+)
+
+func TestValidatedCache(t *testing.T) {
+	config := ValidatedCacheConfig{Enable: true, MaxMemoryMB: 1}
+	cache := NewValidatedCache(&config)
+	rootA := common.HexToHash("0xa")
+	rootB := common.HexToHash("0xb")
+	start := validator.GoGlobalState{BlockHash: common.HexToHash("0x1"), Batch: 1}
+	end := validator.GoGlobalState{BlockHash: common.HexToHash("0x2"), Batch: 1, PosInBatch: 1}
+
+	cache.Add(5, rootA, start, end)
+	if !cache.Validated(5, rootA, start, end) {
+		t.Fatal("expected cached validation")
+	}
+	if cache.Validated(5, rootB, start, end) {
+		t.Fatal("validation served for a different module root")
+	}
+	if cache.Validated(6, rootA, start, end) {
+		t.Fatal("validation served for a different message")
+	}
+	reorgedEnd := end
+	reorgedEnd.BlockHash = common.HexToHash("0x3")
+	if cache.Validated(5, rootA, start, reorgedEnd) {
+		t.Fatal("validation served for a different end state")
+	}
+	if cache.AllValidated(5, []common.Hash{rootA, rootB}, start, end) {
+		t.Fatal("expected missing module root to fail AllValidated")
+	}
+	cache.Add(5, rootB, start, end)
+	if !cache.AllValidated(5, []common.Hash{rootA, rootB}, start, end) {
+		t.Fatal("expected all module roots to be cached")
+	}
+
+	// the cache is bounded by its memory limit
+	capacity := config.MaxMemoryMB * 1024 * 1024 / validatedCacheEntrySize
+	for i := 0; i < capacity; i++ {
+		cache.Add(arbutil.MessageIndex(100+i), rootA, start, end)
+	}
+	if cache.Validated(5, rootA, start, end) {
+		t.Fatal("expected oldest entry to be evicted")
+	}
+}
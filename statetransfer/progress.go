@@ -0,0 +1,93 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package statetransfer
+
+import (
+	"encoding/json"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+
+	"github.com/offchainlabs/nitro/util/arbmath"
+)
+
+type ImportConfig struct {
+	// AccountsPerSync is the number of accounts imported between commits of the state, each of which is a
+	// checkpoint an interrupted import can resume from. 0 disables checkpoints.
+	AccountsPerSync uint
+	// Resume continues an interrupted import from its last checkpoint instead of starting over.
+	Resume bool
+	// Verify re-reads the imported accounts after the import and checks each checkpointed range against the state.
+	Verify bool
+}
+
+var importProgressKey = []byte("_statetransferImportProgress")
+
+// ImportProgress is persisted at every checkpoint of an import. The state with Root holds the address table,
+// the retryables, and the first AccountsImported accounts, and RangeHashes has the hash of each range of accounts
+// imported between checkpoints.
+type ImportProgress struct {
+	NextBlockNumber  uint64        `json:"nextBlockNumber"`
+	Root             common.Hash   `json:"root"`
+	AccountsImported uint64        `json:"accountsImported"`
+	RangeHashes      []common.Hash `json:"rangeHashes"`
+}
+
+// ReadImportProgress returns the progress of an interrupted import, or nil if there's none.
+func ReadImportProgress(db ethdb.KeyValueReader) (*ImportProgress, error) {
+	has, err := db.Has(importProgressKey)
+	if err != nil || !has {
+		return nil, err
+	}
+	data, err := db.Get(importProgressKey)
+	if err != nil {
+		return nil, err
+	}
+	var progress ImportProgress
+	if err := json.Unmarshal(data, &progress); err != nil {
+		return nil, err
+	}
+	return &progress, nil
+}
+
+func WriteImportProgress(db ethdb.KeyValueWriter, progress *ImportProgress) error {
+	data, err := json.Marshal(progress)
+	if err != nil {
+		return err
+	}
+	return db.Put(importProgressKey, data)
+}
+
+func DeleteImportProgress(db ethdb.KeyValueWriter) error {
+	return db.Delete(importProgressKey)
+}
+
+// AccountRangeHash chains the hash of an account's imported fields onto the hash of the accounts before it
+// in the same range, starting from the zero hash.
+func AccountRangeHash(
+	prev common.Hash, address common.Address, nonce uint64, balance *big.Int, code []byte, storage map[common.Hash]common.Hash,
+) common.Hash {
+	keys := make([]common.Hash, 0, len(storage))
+	for key := range storage {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].Big().Cmp(keys[j].Big()) < 0
+	})
+	data := [][]byte{
+		prev.Bytes(),
+		address.Bytes(),
+		arbmath.UintToBytes(nonce),
+		common.BigToHash(balance).Bytes(),
+		crypto.Keccak256(code),
+	}
+	for _, key := range keys {
+		value := storage[key]
+		data = append(data, key.Bytes(), value.Bytes())
+	}
+	return crypto.Keccak256Hash(data...)
+}
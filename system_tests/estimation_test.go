@@ -5,6 +5,7 @@ package arbtest
 
 import (
 	"context"
+	"fmt"
 	"math/big"
 	"testing"
 
@@ -16,6 +17,8 @@ import (
 	"github.com/ethereum/go-ethereum/eth/gasestimator"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/offchainlabs/nitro/arbos/arbostypes"
+	"github.com/offchainlabs/nitro/arbos/l1pricing"
+	"github.com/offchainlabs/nitro/arbos/retryables"
 	"github.com/offchainlabs/nitro/solgen/go/mocksgen"
 	"github.com/offchainlabs/nitro/solgen/go/node_interfacegen"
 	"github.com/offchainlabs/nitro/solgen/go/precompilesgen"
@@ -291,6 +294,145 @@ func TestComponentEstimate(t *testing.T) {
 	}
 }
 
+func TestComponentEstimateDetailed(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	builder := NewNodeBuilder(ctx).DefaultConfig(t, false)
+	cleanup := builder.Build(t)
+	defer cleanup()
+
+	nodeAbi, err := node_interfacegen.NodeInterfaceMetaData.GetAbi()
+	Require(t, err)
+
+	to := testhelpers.RandomAddress()
+	calldata := []byte{0x00, 0x12}
+	call := func(method string) []interface{} {
+		nodeMethod := nodeAbi.Methods[method]
+		packed, err := nodeMethod.Inputs.Pack(to, false, calldata)
+		Require(t, err)
+		msg := ethereum.CallMsg{
+			From: builder.L2Info.GetAddress("Owner"),
+			To:   &types.NodeInterfaceAddress,
+			Gas:  100000000,
+			Data: append(append([]byte{}, nodeMethod.ID...), packed...),
+		}
+		returnData, err := builder.L2.Client.CallContract(ctx, msg, nil)
+		Require(t, err)
+		outputs, err := nodeMethod.Outputs.Unpack(returnData)
+		Require(t, err)
+		return outputs
+	}
+
+	basic := call("gasEstimateComponents")
+	detailed := call("gasEstimateComponentsDetailed")
+	if len(detailed) != 7 {
+		Fatal(t, "expected 7 outputs from gasEstimateComponentsDetailed, got", len(detailed))
+	}
+	for i := range basic {
+		if fmt.Sprint(basic[i]) != fmt.Sprint(detailed[i]) {
+			Fatal(t, "detailed estimate differs from basic estimate", i, basic[i], detailed[i])
+		}
+	}
+
+	l1BaseFeeEstimate, _ := detailed[3].(*big.Int)
+	posterUnits, _ := detailed[4].(uint64)
+	posterFee, _ := detailed[5].(*big.Int)
+	poster, _ := detailed[6].(common.Address)
+	if posterUnits == 0 {
+		Fatal(t, "no calldata units charged")
+	}
+	if !arbmath.BigEquals(posterFee, arbmath.BigMulByUint(l1BaseFeeEstimate, posterUnits)) {
+		Fatal(t, "poster fee isn't units times price", posterFee, posterUnits, l1BaseFeeEstimate)
+	}
+	if poster != l1pricing.BatchPosterAddress {
+		Fatal(t, "unexpected poster", poster)
+	}
+}
+
+func TestEstimateRetryableTicketDetailed(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	builder := NewNodeBuilder(ctx).DefaultConfig(t, true)
+	// keep the parent chain still, so its latest base fee can't change under the estimate
+	builder.nodeConfig.BatchPoster.Enable = false
+	cleanup := builder.Build(t)
+	defer cleanup()
+
+	nodeAbi, err := node_interfacegen.NodeInterfaceMetaData.GetAbi()
+	Require(t, err)
+	nodeMethod := nodeAbi.Methods["estimateRetryableTicketDetailed"]
+	sender := builder.L2Info.GetAddress("Owner")
+	gas := uint64(1_000_000)
+	estimate := func(to common.Address, data []byte, percentile uint64) ([]interface{}, error) {
+		packed, err := nodeMethod.Inputs.Pack(sender, to, big.NewInt(0), data, percentile)
+		Require(t, err)
+		msg := ethereum.CallMsg{
+			From: sender,
+			To:   &types.NodeInterfaceAddress,
+			Gas:  gas,
+			Data: append(append([]byte{}, nodeMethod.ID...), packed...),
+		}
+		returnData, err := builder.L2.Client.CallContract(ctx, msg, nil)
+		if err != nil {
+			return nil, err
+		}
+		outputs, err := nodeMethod.Outputs.Unpack(returnData)
+		Require(t, err)
+		if len(outputs) != 6 {
+			Fatal(t, "expected 6 outputs from estimateRetryableTicketDetailed, got", len(outputs))
+		}
+		return outputs, nil
+	}
+
+	// percentile 0 prices the submission at the parent chain's latest base fee, not ArbOS's estimate of it
+	l1Header, err := builder.L1.Client.HeaderByNumber(ctx, nil)
+	Require(t, err)
+	data := []byte{0x00, 0x12, 0x34}
+	outputs, err := estimate(testhelpers.RandomAddress(), data, 0)
+	Require(t, err)
+	submissionFee, _ := outputs[0].(*big.Int)
+	l1BaseFee, _ := outputs[1].(*big.Int)
+	maxFeePerGas, _ := outputs[2].(*big.Int)
+	gasUsed, _ := outputs[3].(uint64)
+	reverted, _ := outputs[4].(bool)
+	if !arbmath.BigEquals(l1BaseFee, l1Header.BaseFee) {
+		Fatal(t, "estimate didn't use the parent chain's base fee", l1BaseFee, "expected", l1Header.BaseFee)
+	}
+	if !arbmath.BigEquals(submissionFee, retryables.RetryableSubmissionFee(len(data), l1BaseFee)) {
+		Fatal(t, "wrong submission fee", submissionFee, "for base fee", l1BaseFee)
+	}
+	if maxFeePerGas.Sign() <= 0 {
+		Fatal(t, "no max fee per gas", maxFeePerGas)
+	}
+	if reverted || gasUsed != 0 {
+		Fatal(t, "a call to an empty account reverted or used gas", reverted, gasUsed)
+	}
+
+	// a percentile of recent base fees is one of them
+	outputs, err = estimate(testhelpers.RandomAddress(), data, 50)
+	Require(t, err)
+	l1BaseFee, _ = outputs[1].(*big.Int)
+	submissionFee, _ = outputs[0].(*big.Int)
+	if l1BaseFee.Sign() <= 0 || !arbmath.BigEquals(submissionFee, retryables.RetryableSubmissionFee(len(data), l1BaseFee)) {
+		Fatal(t, "wrong percentile estimate", submissionFee, l1BaseFee)
+	}
+
+	// a redeem that reverts reports it, along with the gas it burned
+	outputs, err = estimate(types.ArbSysAddress, []byte{0xde, 0xad, 0xbe, 0xef}, 0)
+	Require(t, err)
+	gasUsed, _ = outputs[3].(uint64)
+	reverted, _ = outputs[4].(bool)
+	if !reverted || gasUsed == 0 {
+		Fatal(t, "a reverting redeem wasn't reported", reverted, gasUsed)
+	}
+
+	if _, err := estimate(testhelpers.RandomAddress(), data, 101); err == nil {
+		Fatal(t, "a percentile above 100 was accepted")
+	}
+}
+
 func TestDisableL1Charging(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
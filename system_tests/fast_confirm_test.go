@@ -148,6 +148,7 @@ func TestFastConfirmation(t *testing.T) {
 		execNode,
 		l2node.ArbDB,
 		nil,
+		nil,
 		StaticFetcherFrom(t, &blockValidatorConfig),
 		valStack,
 	)
@@ -349,6 +350,7 @@ func TestFastConfirmationWithSafe(t *testing.T) {
 		execNodeA,
 		l2nodeA.ArbDB,
 		nil,
+		nil,
 		StaticFetcherFrom(t, &blockValidatorConfig),
 		valStack,
 	)
@@ -401,6 +403,7 @@ func TestFastConfirmationWithSafe(t *testing.T) {
 		execNodeB,
 		l2nodeB.ArbDB,
 		nil,
+		nil,
 		StaticFetcherFrom(t, &blockValidatorConfig),
 		valStack,
 	)
@@ -82,6 +82,34 @@ func TestFindBatch(t *testing.T) {
 			Fatal(t, "wrong number of confirmations. got ", gotConfirmations)
 		}
 	}
+
+	callOpts := bind.CallOpts{Context: ctx}
+	lastBlock := uint64(makeBatch_MsgsPerBatch) * 3
+	gotBatchNums, err := nodeInterface.FindBatchesContainingBlocks(&callOpts, 1, lastBlock)
+	Require(t, err)
+	if len(gotBatchNums) != int(lastBlock) {
+		Fatal(t, "wrong number of results from findBatchesContainingBlocks ", len(gotBatchNums))
+	}
+	for i, gotBatchNum := range gotBatchNums {
+		blockNum := uint64(i) + 1
+		expBatchNum := 1 + (blockNum-1)/uint64(makeBatch_MsgsPerBatch)
+		if expBatchNum != gotBatchNum {
+			Fatal(t, "wrong result from findBatchesContainingBlocks. blocknum ", blockNum, " expected ", expBatchNum, " got ", gotBatchNum)
+		}
+	}
+	minCurrentL1Block, err := builder.L1.Client.BlockNumber(ctx)
+	Require(t, err)
+	gotConfirmations, err := nodeInterface.GetL1ConfirmationsForBlocks(&callOpts, 1, lastBlock)
+	Require(t, err)
+	maxCurrentL1Block, err := builder.L1.Client.BlockNumber(ctx)
+	Require(t, err)
+	for i, confirmations := range gotConfirmations {
+		batchL1Block, err := builder.L2.ConsensusNode.InboxTracker.GetBatchParentChainBlock(gotBatchNums[i])
+		Require(t, err)
+		if confirmations > (maxCurrentL1Block-batchL1Block) || confirmations < (minCurrentL1Block-batchL1Block) {
+			Fatal(t, "wrong number of confirmations from getL1ConfirmationsForBlocks. got ", confirmations)
+		}
+	}
 }
 
 func TestL2BlockRangeForL1(t *testing.T) {
@@ -175,3 +175,63 @@ func TestScheduleArbosUpgrade(t *testing.T) {
 		t.Errorf("expected upgrade to be scheduled for version %v timestamp %v, got version %v timestamp %v", testVersion, testTimestamp, scheduled.ArbosVersion, scheduled.ScheduledForTimestamp)
 	}
 }
+
+func TestPricesInWeiAtBlock(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	builder := NewNodeBuilder(ctx).DefaultConfig(t, false)
+	cleanup := builder.Build(t)
+	defer cleanup()
+
+	auth := builder.L2Info.GetDefaultTransactOpts("Owner", ctx)
+	callOpts := &bind.CallOpts{Context: ctx}
+
+	arbOwner, err := precompilesgen.NewArbOwner(common.HexToAddress("0x70"), builder.L2.Client)
+	Require(t, err)
+	arbGasInfo, err := precompilesgen.NewArbGasInfo(common.HexToAddress("0x6c"), builder.L2.Client)
+	Require(t, err)
+
+	depth := uint64(4)
+	tx, err := arbOwner.SetPricingHistoryDepth(&auth, depth)
+	Require(t, err)
+	receipt, err := builder.L2.EnsureTxSucceeded(tx)
+	Require(t, err)
+	setBlock := receipt.BlockNumber.Uint64()
+	readDepth, err := arbGasInfo.GetPricingHistoryDepth(callOpts)
+	Require(t, err)
+	if readDepth != depth {
+		Fatal(t, "wrong pricing history depth", readDepth)
+	}
+
+	// the block that set the depth started before any history was kept
+	if _, _, _, _, _, _, err := arbGasInfo.GetPricesInWeiAtBlock(callOpts, setBlock); err == nil {
+		Fatal(t, "got prices for a block from before the history was kept")
+	}
+
+	var blocks []uint64
+	for i := uint64(0); i < depth+2; i++ {
+		_, receipt := builder.L2.TransferBalance(t, "Owner", "Owner", common.Big1, builder.L2Info)
+		blocks = append(blocks, receipt.BlockNumber.Uint64())
+	}
+	latest := blocks[len(blocks)-1]
+	// query the state at the last transfer, as other blocks may have been sequenced since
+	callOpts.BlockNumber = new(big.Int).SetUint64(latest)
+	for _, block := range blocks {
+		_, _, _, _, _, perArbGasTotal, err := arbGasInfo.GetPricesInWeiAtBlock(callOpts, block)
+		if latest-block >= depth {
+			if err == nil {
+				Fatal(t, "got prices for a block that's no longer kept", block)
+			}
+			continue
+		}
+		Require(t, err)
+		baseFee := builder.L2.GetBaseFeeAt(t, new(big.Int).SetUint64(block))
+		if perArbGasTotal.Cmp(baseFee) != 0 {
+			Fatal(t, "wrong base fee at block", block, perArbGasTotal, baseFee)
+		}
+	}
+	if _, _, _, _, _, _, err := arbGasInfo.GetPricesInWeiAtBlock(callOpts, latest+100); err == nil {
+		Fatal(t, "got prices for a future block")
+	}
+}
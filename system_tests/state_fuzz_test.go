@@ -43,7 +43,7 @@ func BuildBlock(
 	if lastBlockHeader != nil {
 		delayedMessagesRead = lastBlockHeader.Nonce.Uint64()
 	}
-	inboxMultiplexer := arbstate.NewInboxMultiplexer(inbox, delayedMessagesRead, nil, daprovider.KeysetValidate)
+	inboxMultiplexer := arbstate.NewInboxMultiplexer(inbox, delayedMessagesRead, nil, nil, daprovider.KeysetValidate)
 
 	ctx := context.Background()
 	message, err := inboxMultiplexer.Pop(ctx)
@@ -151,7 +151,7 @@ func FuzzStateTransition(f *testing.F) {
 			chainConfig,
 			initMessage,
 			0,
-			0,
+			statetransfer.ImportConfig{},
 		)
 		if err != nil {
 			panic(err)
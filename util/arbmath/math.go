@@ -251,6 +251,14 @@ func BigDivByUint(dividend *big.Int, divisor uint64) *big.Int {
 	return BigDiv(dividend, UintToBig(divisor))
 }
 
+// BigEMA moves an exponential moving average over about window values towards the next value,
+// by the usual weight of 2/(window+1)
+func BigEMA(average *big.Int, value *big.Int, window uint64) *big.Int {
+	weighted := BigMulByUint(average, SaturatingUSub(window, 1))
+	weighted.Add(weighted, BigMulByUint(value, 2))
+	return weighted.Div(weighted, UintToBig(SaturatingUAdd(window, 1)))
+}
+
 // BigDivByInt divide a huge by an integer
 func BigDivByInt(dividend *big.Int, divisor int64) *big.Int {
 	return BigDiv(dividend, big.NewInt(divisor))
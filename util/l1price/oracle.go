@@ -0,0 +1,213 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+// Package l1price provides the parent chain data prices the batch poster and sequencer base their decisions on.
+// By default they're read from the parent chain's latest header, but chains settling to parent chains with other
+// fee markets can take them from an external endpoint instead, and either source can be smoothed.
+package l1price
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/offchainlabs/nitro/util/arbmath"
+)
+
+var (
+	usableBytesInBlob    = big.NewInt(int64(len(kzg4844.Blob{}) * 31 / 32))
+	blobTxBlobGasPerBlob = big.NewInt(params.BlobTxBlobGasPerBlob)
+)
+
+// Prices are the costs of posting data to the parent chain.
+type Prices struct {
+	BaseFee        *big.Int `json:"baseFee"`        // per unit of calldata gas, of which a byte costs 16
+	BlobFeePerByte *big.Int `json:"blobFeePerByte"` // nil if the parent chain doesn't support blobs
+}
+
+// L1GasPrice returns the price of a unit of calldata gas, or its equivalent in blobs if they're cheaper.
+func (p Prices) L1GasPrice() *big.Int {
+	if p.BlobFeePerByte != nil {
+		blobEquivalent := new(big.Int).Div(p.BlobFeePerByte, big.NewInt(16))
+		if arbmath.BigLessThan(blobEquivalent, p.BaseFee) {
+			return blobEquivalent
+		}
+	}
+	return new(big.Int).Set(p.BaseFee)
+}
+
+// BlobsAreCheaper returns whether posting a byte in blobs costs less than in calldata.
+func (p Prices) BlobsAreCheaper() bool {
+	return p.BlobFeePerByte != nil && arbmath.BigLessThan(p.BlobFeePerByte, arbmath.BigMulByUint(p.BaseFee, 16))
+}
+
+// Oracle estimates parent chain data prices as of the parent chain's latest header.
+type Oracle interface {
+	Prices(ctx context.Context, header *types.Header) (Prices, error)
+}
+
+type Config struct {
+	Source          string         `koanf:"source"`
+	External        ExternalConfig `koanf:"external"`
+	SmoothingWindow uint64         `koanf:"smoothing-window"`
+}
+
+type ExternalConfig struct {
+	URL     string        `koanf:"url"`
+	Method  string        `koanf:"method"`
+	Timeout time.Duration `koanf:"timeout"`
+}
+
+var DefaultConfig = Config{
+	Source:          "header",
+	External:        DefaultExternalConfig,
+	SmoothingWindow: 0,
+}
+
+var DefaultExternalConfig = ExternalConfig{
+	URL:     "",
+	Method:  "arb_parentChainPrices",
+	Timeout: 5 * time.Second,
+}
+
+func ConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.String(prefix+".source", DefaultConfig.Source, "where parent chain data prices are read from (\"header\" for the latest parent chain header, or \"external\" for an external endpoint)")
+	f.String(prefix+".external.url", DefaultConfig.External.URL, "JSON-RPC URL of the external parent chain price endpoint")
+	f.String(prefix+".external.method", DefaultConfig.External.Method, "JSON-RPC method returning {baseFee, blobFeePerByte} as hex quantities")
+	f.Duration(prefix+".external.timeout", DefaultConfig.External.Timeout, "timeout for requests to the external endpoint, after which prices are read from the header")
+	f.Uint64(prefix+".smoothing-window", DefaultConfig.SmoothingWindow, "if non-zero, smooth prices with an exponential moving average over about this many parent chain blocks")
+}
+
+func (c *Config) Validate() error {
+	switch c.Source {
+	case "header":
+	case "external":
+		if c.External.URL == "" {
+			return errors.New("external parent chain price source needs an external url")
+		}
+	default:
+		return fmt.Errorf("invalid parent chain price source \"%v\"", c.Source)
+	}
+	return nil
+}
+
+// NewOracle returns the oracle the config describes.
+func NewOracle(ctx context.Context, config *Config) (Oracle, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	var oracle Oracle = HeaderOracle{}
+	if config.Source == "external" {
+		client, err := rpc.DialContext(ctx, config.External.URL)
+		if err != nil {
+			return nil, err
+		}
+		oracle = &ExternalOracle{client: client, method: config.External.Method, timeout: config.External.Timeout}
+	}
+	if config.SmoothingWindow > 0 {
+		oracle = NewSmoothedOracle(oracle, config.SmoothingWindow)
+	}
+	return oracle, nil
+}
+
+// HeaderOracle reads prices off the header, the parent chain's own base fee and blob base fee.
+type HeaderOracle struct{}
+
+func (HeaderOracle) Prices(_ context.Context, header *types.Header) (Prices, error) {
+	prices := Prices{BaseFee: header.BaseFee}
+	if header.ExcessBlobGas != nil && header.BlobGasUsed != nil {
+		blobFeePerByte := eip4844.CalcBlobFee(eip4844.CalcExcessBlobGas(*header.ExcessBlobGas, *header.BlobGasUsed))
+		blobFeePerByte.Mul(blobFeePerByte, blobTxBlobGasPerBlob)
+		blobFeePerByte.Div(blobFeePerByte, usableBytesInBlob)
+		prices.BlobFeePerByte = blobFeePerByte
+	}
+	return prices, nil
+}
+
+// ExternalOracle asks a JSON-RPC endpoint for prices, and falls back to the header if it can't.
+type ExternalOracle struct {
+	client  *rpc.Client
+	method  string
+	timeout time.Duration
+}
+
+type externalPrices struct {
+	BaseFee        *hexutil.Big `json:"baseFee"`
+	BlobFeePerByte *hexutil.Big `json:"blobFeePerByte"`
+}
+
+func (o *ExternalOracle) Prices(ctx context.Context, header *types.Header) (Prices, error) {
+	ctx, cancel := context.WithTimeout(ctx, o.timeout)
+	defer cancel()
+	var result externalPrices
+	err := o.client.CallContext(ctx, &result, o.method, hexutil.Big(*header.Number))
+	if err == nil && result.BaseFee == nil {
+		err = errors.New("no base fee in response")
+	}
+	if err != nil {
+		log.Warn("failed to get parent chain prices from external endpoint, reading them from the header", "method", o.method, "err", err)
+		return HeaderOracle{}.Prices(ctx, header)
+	}
+	prices := Prices{BaseFee: result.BaseFee.ToInt()}
+	if result.BlobFeePerByte != nil {
+		prices.BlobFeePerByte = result.BlobFeePerByte.ToInt()
+	}
+	return prices, nil
+}
+
+// SmoothedOracle is an exponential moving average of another oracle's prices, updated once per header.
+type SmoothedOracle struct {
+	inner  Oracle
+	window uint64
+
+	mutex      sync.Mutex
+	lastHeader *big.Int
+	average    Prices
+}
+
+func NewSmoothedOracle(inner Oracle, window uint64) *SmoothedOracle {
+	return &SmoothedOracle{inner: inner, window: window}
+}
+
+func (o *SmoothedOracle) Prices(ctx context.Context, header *types.Header) (Prices, error) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	if o.lastHeader != nil && arbmath.BigEquals(o.lastHeader, header.Number) {
+		return o.average, nil
+	}
+	prices, err := o.inner.Prices(ctx, header)
+	if err != nil {
+		return Prices{}, err
+	}
+	if o.lastHeader == nil {
+		o.average = prices
+	} else {
+		o.average = Prices{
+			BaseFee:        o.smooth(o.average.BaseFee, prices.BaseFee),
+			BlobFeePerByte: o.smooth(o.average.BlobFeePerByte, prices.BlobFeePerByte),
+		}
+	}
+	o.lastHeader = header.Number
+	return o.average, nil
+}
+
+// smooth moves the average towards the price, the same way ArbOS smooths the parent chain base fees reported to it.
+func (o *SmoothedOracle) smooth(average *big.Int, price *big.Int) *big.Int {
+	if price == nil || average == nil {
+		return price
+	}
+	return arbmath.BigEMA(average, price, o.window)
+}
@@ -0,0 +1,61 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package l1price
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+type fixedOracle struct {
+	baseFee *big.Int
+}
+
+func (o *fixedOracle) Prices(context.Context, *types.Header) (Prices, error) {
+	return Prices{BaseFee: o.baseFee}, nil
+}
+
+func TestPrices(t *testing.T) {
+	prices := Prices{BaseFee: big.NewInt(100), BlobFeePerByte: big.NewInt(800)}
+	if prices.L1GasPrice().Uint64() != 50 || !prices.BlobsAreCheaper() {
+		t.Error("blobs at half the price of calldata weren't preferred")
+	}
+	prices.BlobFeePerByte = big.NewInt(3200)
+	if prices.L1GasPrice().Uint64() != 100 || prices.BlobsAreCheaper() {
+		t.Error("blobs at twice the price of calldata were preferred")
+	}
+	prices.BlobFeePerByte = nil
+	if prices.L1GasPrice().Uint64() != 100 || prices.BlobsAreCheaper() {
+		t.Error("blobs were preferred on a parent chain without them")
+	}
+}
+
+func TestSmoothedOracle(t *testing.T) {
+	ctx := context.Background()
+	inner := &fixedOracle{baseFee: big.NewInt(1000)}
+	oracle := NewSmoothedOracle(inner, 3)
+	header := &types.Header{Number: big.NewInt(1)}
+
+	check := func(expected uint64) {
+		t.Helper()
+		prices, err := oracle.Prices(ctx, header)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if prices.BaseFee.Uint64() != expected {
+			t.Errorf("smoothed base fee is %v, expected %v", prices.BaseFee, expected)
+		}
+	}
+	check(1000)
+	// the average only moves once per header, by half the difference with a window of 3
+	inner.baseFee = big.NewInt(2000)
+	check(1000)
+	header = &types.Header{Number: big.NewInt(2)}
+	check(1500)
+	header = &types.Header{Number: big.NewInt(3)}
+	check(1750)
+}
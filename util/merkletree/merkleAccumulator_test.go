@@ -133,6 +133,142 @@ func TestAccumulator4(t *testing.T) {
 	testSerDe(mt, t)
 }
 
+func TestAccumulatorAppendMany(t *testing.T) {
+	for _, persistent := range []bool{true, false} {
+		for _, start := range []uint64{0, 1, 3, 4, 7} {
+			for _, batch := range []uint64{1, 2, 5, 9, 16} {
+				newAcc := func() *merkleAccumulator.MerkleAccumulator {
+					if persistent {
+						return initializedMerkleAccumulatorForTesting()
+					}
+					return merkleAccumulator.NewNonpersistentMerkleAccumulator()
+				}
+				sequential := newAcc()
+				batched := newAcc()
+				for i := uint64(0); i < start; i++ {
+					accAppend(t, sequential, pseudorandomForTesting(i))
+					accAppend(t, batched, pseudorandomForTesting(i))
+				}
+				var expectedEvents [][]merkleAccumulator.MerkleTreeNodeEvent
+				items := []common.Hash{}
+				for i := start; i < start+batch; i++ {
+					item := pseudorandomForTesting(i)
+					events, err := sequential.Append(item)
+					Require(t, err)
+					expectedEvents = append(expectedEvents, events)
+					items = append(items, item)
+				}
+				events, err := batched.AppendMany(items)
+				Require(t, err)
+				if len(events) != len(expectedEvents) {
+					Fail(t, "wrong number of event lists", len(events), len(expectedEvents))
+				}
+				for i := range events {
+					if len(events[i]) != len(expectedEvents[i]) {
+						Fail(t, "wrong number of events for item", i)
+					}
+					for j := range events[i] {
+						if events[i][j] != expectedEvents[i][j] {
+							Fail(t, "wrong event", i, j, events[i][j], expectedEvents[i][j])
+						}
+					}
+				}
+				if size(t, batched) != size(t, sequential) || root(t, batched) != root(t, sequential) {
+					Fail(t, "batched accumulator diverged", persistent, start, batch)
+				}
+				sequentialPartials, err := sequential.GetPartials()
+				Require(t, err)
+				batchedPartials, err := batched.GetPartials()
+				Require(t, err)
+				for i := range sequentialPartials {
+					if *sequentialPartials[i] != *batchedPartials[i] {
+						Fail(t, "partial differs", i)
+					}
+				}
+			}
+		}
+	}
+}
+
+type memorySnapshotArchive struct {
+	items    []common.Hash
+	partials [][]common.Hash // partials[size] is the snapshot after size appends
+}
+
+func (a *memorySnapshotArchive) PartialsAt(size uint64) ([]common.Hash, error) {
+	return a.partials[size], nil
+}
+
+func (a *memorySnapshotArchive) ItemAt(leaf uint64) (common.Hash, error) {
+	return a.items[leaf], nil
+}
+
+func (a *memorySnapshotArchive) record(t *testing.T, acc *merkleAccumulator.MerkleAccumulator) {
+	t.Helper()
+	var partials []common.Hash
+	Require(t, acc.ForEachPartial(func(level uint64, partial common.Hash) error {
+		if level != uint64(len(partials)) {
+			Fail(t, "partials visited out of order", level)
+		}
+		partials = append(partials, partial)
+		return nil
+	}))
+	a.partials = append(a.partials, partials)
+}
+
+func TestAccumulatorProveInclusion(t *testing.T) {
+	acc := initializedMerkleAccumulatorForTesting()
+	archive := &memorySnapshotArchive{}
+	archive.record(t, acc)
+	for size := uint64(1); size <= 20; size++ {
+		item := pseudorandomForTesting(size)
+		accAppend(t, acc, item)
+		archive.items = append(archive.items, item)
+		archive.record(t, acc)
+
+		for leaf := uint64(0); leaf < size; leaf++ {
+			proof, err := acc.ProveInclusion(archive, leaf)
+			Require(t, err)
+			if !proof.IsCorrect() || proof.Root != root(t, acc) {
+				Fail(t, "incorrect proof", size, leaf)
+			}
+			merkleProof := MerkleProof{
+				RootHash:  proof.Root,
+				LeafHash:  crypto.Keccak256Hash(proof.Item.Bytes()),
+				LeafIndex: proof.LeafIndex,
+				Proof:     proof.Siblings,
+			}
+			if !merkleProof.IsCorrect() {
+				Fail(t, "proof not accepted as merkle proof", size, leaf)
+			}
+		}
+		if _, err := acc.ProveInclusion(archive, size); err == nil {
+			Fail(t, "expected error proving leaf past the end")
+		}
+	}
+
+	// proofs against historical roots
+	for size := uint64(1); size <= 20; size++ {
+		historical, err := merkleAccumulator.NewNonpersistentMerkleAccumulatorFromPartials(partialPointers(archive.partials[size]))
+		Require(t, err)
+		for leaf := uint64(0); leaf < size; leaf++ {
+			proof, err := merkleAccumulator.ProveInclusion(archive, size, leaf)
+			Require(t, err)
+			if proof.Root != root(t, historical) {
+				Fail(t, "wrong historical root", size, leaf)
+			}
+		}
+	}
+}
+
+func partialPointers(partials []common.Hash) []*common.Hash {
+	pointers := make([]*common.Hash, len(partials))
+	for i := range partials {
+		pointers[i] = &partials[i]
+	}
+	return pointers
+}
+
 func testAllSummarySizes(tree MerkleTree, t *testing.T) {
 	for i := uint64(1); i <= tree.Size(); i++ {
 		sum := tree.SummarizeUpTo(i)
@@ -16,6 +16,7 @@ import (
 const CHOSENSEQ_KEY string = "coordinator.chosen"                      // Never overwritten. Expires or released only
 const MSG_COUNT_KEY string = "coordinator.msgCount"                    // Only written by sequencer holding CHOSEN key
 const FINALIZED_MSG_COUNT_KEY string = "coordinator.finalizedMsgCount" // Only written by sequencer holding CHOSEN key
+const FENCING_TOKEN_KEY string = "coordinator.fencingToken"            // Only written by sequencer acquiring CHOSEN key
 const PRIORITIES_KEY string = "coordinator.priorities"                 // Read only
 const WANTS_LOCKOUT_KEY_PREFIX string = "coordinator.liveliness."      // Per server. Only written by self
 const MESSAGE_KEY_PREFIX string = "coordinator.msg."                   // Per Message. Only written by sequencer holding CHOSEN
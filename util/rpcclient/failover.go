@@ -0,0 +1,234 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package rpcclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+type FailoverConfig struct {
+	URLs      []string `koanf:"urls"`
+	LogQuorum bool     `koanf:"log-quorum" reload:"hot"`
+}
+
+var DefaultFailoverConfig = FailoverConfig{
+	URLs:      []string{},
+	LogQuorum: false,
+}
+
+func FailoverConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.StringSlice(prefix+".urls", DefaultFailoverConfig.URLs, "urls of backup servers to fail over to, in order, when the connection's server fails; each uses the connection's other settings")
+	f.Bool(prefix+".log-quorum", DefaultFailoverConfig.LogQuorum, "require two servers to return the same logs before eth_getLogs succeeds")
+}
+
+func (c *FailoverConfig) Validate() error {
+	if c.LogQuorum && len(c.URLs) == 0 {
+		return errors.New("log quorum requires at least one failover url")
+	}
+	return nil
+}
+
+type FailoverConfigFetcher func() *FailoverConfig
+
+var ErrLogQuorum = errors.New("servers disagree on logs")
+
+// FailoverClient sends requests to one of several servers, moving on to the next when a request to the current one
+// fails, and optionally asks two servers for logs and only returns them if they agree. This protects readers of the
+// parent chain from a single unreachable or faulty provider.
+type FailoverClient struct {
+	config  FailoverConfigFetcher
+	clients []*RpcClient
+	current atomic.Uint64
+}
+
+func NewFailoverClient(config FailoverConfigFetcher, clients ...*RpcClient) *FailoverClient {
+	return &FailoverClient{
+		config:  config,
+		clients: clients,
+	}
+}
+
+// Start connects to every server. It only fails if it can't connect to any of them.
+func (c *FailoverClient) Start(ctx context.Context) error {
+	var errs []error
+	for i, client := range c.clients {
+		if err := client.Start(ctx); err != nil {
+			log.Warn("failed to connect to rpc server", "index", i, "url", client.config().URL, "err", err)
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == len(c.clients) {
+		return fmt.Errorf("failed to connect to any server: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+func (c *FailoverClient) Close() {
+	for _, client := range c.clients {
+		client.Close()
+	}
+}
+
+// shouldFailOver returns whether another server may succeed where one failed with the error. Reverts aren't
+// retried, as every server should give the same result.
+func shouldFailOver(ctx context.Context, err error) bool {
+	var dataErr rpc.DataError
+	return err != nil && ctx.Err() == nil && !errors.As(err, &dataErr)
+}
+
+// try calls the function with each server in turn, from the current one, until it succeeds, and makes the server
+// it succeeded with the current one.
+func (c *FailoverClient) try(ctx context.Context, call func(*RpcClient) error) error {
+	start := c.current.Load()
+	var err error
+	for i := uint64(0); i < uint64(len(c.clients)); i++ {
+		index := (start + i) % uint64(len(c.clients))
+		client := c.clients[index]
+		if client.client == nil {
+			continue
+		}
+		err = call(client)
+		if !shouldFailOver(ctx, err) {
+			if index != start && c.current.CompareAndSwap(start, index) {
+				log.Warn("failed over to rpc server", "index", index, "url", client.config().URL)
+			}
+			return err
+		}
+		log.Warn("rpc server failed", "index", index, "url", client.config().URL, "err", err)
+	}
+	if err == nil {
+		return errors.New("not connected")
+	}
+	return err
+}
+
+func (c *FailoverClient) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	if method == "eth_getLogs" && c.config().LogQuorum {
+		return c.callWithQuorum(ctx, result, method, args...)
+	}
+	return c.try(ctx, func(client *RpcClient) error {
+		return client.CallContext(ctx, result, method, args...)
+	})
+}
+
+// logKey identifies a log, and is all that servers returning the same log need agree on.
+type logKey struct {
+	blockHash common.Hash
+	txHash    common.Hash
+	index     uint
+	address   common.Address
+	topics    string
+	data      string
+}
+
+func logKeys(raw json.RawMessage) ([]logKey, error) {
+	var logs []types.Log
+	if err := json.Unmarshal(raw, &logs); err != nil {
+		return nil, err
+	}
+	keys := make([]logKey, 0, len(logs))
+	for _, txLog := range logs {
+		var topics []byte
+		for _, topic := range txLog.Topics {
+			topics = append(topics, topic.Bytes()...)
+		}
+		keys = append(keys, logKey{
+			blockHash: txLog.BlockHash,
+			txHash:    txLog.TxHash,
+			index:     txLog.Index,
+			address:   txLog.Address,
+			topics:    string(topics),
+			data:      string(txLog.Data),
+		})
+	}
+	return keys, nil
+}
+
+func sameLogs(a, b json.RawMessage) (bool, error) {
+	if bytes.Equal(a, b) {
+		return true, nil
+	}
+	aKeys, err := logKeys(a)
+	if err != nil {
+		return false, err
+	}
+	bKeys, err := logKeys(b)
+	if err != nil {
+		return false, err
+	}
+	if len(aKeys) != len(bKeys) {
+		return false, nil
+	}
+	for i := range aKeys {
+		if aKeys[i] != bKeys[i] {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// callWithQuorum asks the servers, from the current one, until two have answered, and returns their answer if
+// they agree. Servers may format logs differently, so only the logs' contents are compared.
+func (c *FailoverClient) callWithQuorum(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	start := c.current.Load()
+	var answers []json.RawMessage
+	var errs []error
+	for i := uint64(0); i < uint64(len(c.clients)) && len(answers) < 2; i++ {
+		client := c.clients[(start+i)%uint64(len(c.clients))]
+		if client.client == nil {
+			continue
+		}
+		var answer json.RawMessage
+		err := client.CallContext(ctx, &answer, method, args...)
+		if err != nil {
+			if !shouldFailOver(ctx, err) {
+				return err
+			}
+			log.Warn("rpc server failed", "url", client.config().URL, "err", err)
+			errs = append(errs, err)
+			continue
+		}
+		answers = append(answers, answer)
+	}
+	if len(answers) < 2 {
+		return fmt.Errorf("fewer than two servers answered %v: %w", method, errors.Join(errs...))
+	}
+	same, err := sameLogs(answers[0], answers[1])
+	if err != nil {
+		return err
+	}
+	if !same {
+		log.Error("rpc servers disagree on logs", "method", method)
+		return ErrLogQuorum
+	}
+	return json.Unmarshal(answers[0], result)
+}
+
+func (c *FailoverClient) BatchCallContext(ctx context.Context, b []rpc.BatchElem) error {
+	return c.try(ctx, func(client *RpcClient) error {
+		return client.BatchCallContext(ctx, b)
+	})
+}
+
+func (c *FailoverClient) EthSubscribe(ctx context.Context, channel interface{}, args ...interface{}) (*rpc.ClientSubscription, error) {
+	var sub *rpc.ClientSubscription
+	err := c.try(ctx, func(client *RpcClient) error {
+		var err error
+		sub, err = client.EthSubscribe(ctx, channel, args...)
+		return err
+	})
+	return sub, err
+}
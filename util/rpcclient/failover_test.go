@@ -0,0 +1,88 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package rpcclient
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+type testLogsAPI struct {
+	logs []*types.Log
+	fail bool
+}
+
+func (a *testLogsAPI) GetLogs(ctx context.Context, crit map[string]interface{}) ([]*types.Log, error) {
+	if a.fail {
+		return nil, errors.New("server down")
+	}
+	return a.logs, nil
+}
+
+func createTestLogsClient(t *testing.T, api *testLogsAPI) *RpcClient {
+	server := rpc.NewServer()
+	Require(t, server.RegisterName("eth", api))
+	t.Cleanup(server.Stop)
+	client := NewRpcClient(func() *ClientConfig { return &TestClientConfig }, nil)
+	client.client = rpc.DialInProc(server)
+	return client
+}
+
+func TestFailoverClient(t *testing.T) {
+	ctx := context.Background()
+	logs := []*types.Log{{
+		Address: common.HexToAddress("0x1234"),
+		Topics:  []common.Hash{common.HexToHash("0x1")},
+		Data:    []byte{1, 2, 3},
+		TxHash:  common.HexToHash("0x2"),
+	}}
+	forged := []*types.Log{{
+		Address: common.HexToAddress("0x1234"),
+		Topics:  []common.Hash{common.HexToHash("0x1")},
+		Data:    []byte{4, 5, 6},
+		TxHash:  common.HexToHash("0x2"),
+	}}
+	primary := &testLogsAPI{logs: logs}
+	backup := &testLogsAPI{logs: logs}
+	faulty := &testLogsAPI{logs: forged}
+	config := &FailoverConfig{}
+	client := NewFailoverClient(
+		func() *FailoverConfig { return config },
+		createTestLogsClient(t, primary),
+		createTestLogsClient(t, backup),
+		createTestLogsClient(t, faulty),
+	)
+
+	var result []*types.Log
+	Require(t, client.CallContext(ctx, &result, "eth_getLogs", map[string]interface{}{}))
+	if len(result) != 1 || result[0].Data[0] != 1 {
+		Fail(t, "wrong logs", result)
+	}
+
+	primary.fail = true
+	Require(t, client.CallContext(ctx, &result, "eth_getLogs", map[string]interface{}{}))
+	if client.current.Load() != 1 {
+		Fail(t, "didn't fail over to the backup", client.current.Load())
+	}
+
+	// the backup and the faulty server are asked, and disagree
+	config.LogQuorum = true
+	err := client.CallContext(ctx, &result, "eth_getLogs", map[string]interface{}{})
+	if !errors.Is(err, ErrLogQuorum) {
+		Fail(t, "accepted logs servers disagree on", err)
+	}
+	faulty.logs = logs
+	Require(t, client.CallContext(ctx, &result, "eth_getLogs", map[string]interface{}{}))
+
+	backup.fail = true
+	faulty.fail = true
+	if err := client.CallContext(ctx, &result, "eth_getLogs", map[string]interface{}{}); err == nil {
+		Fail(t, "got logs with no servers answering")
+	}
+}
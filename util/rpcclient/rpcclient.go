@@ -53,6 +53,9 @@ func (c *ClientConfig) UnmarshalJSON(data []byte) error {
 
 type ClientConfigFetcher func() *ClientConfig
 
+// UnixSocketURLPrefix starts the URL of a server's IPC endpoint, followed by the path of its unix domain socket.
+const UnixSocketURLPrefix = "unix://"
+
 var TestClientConfig = ClientConfig{
 	URL:                       "self",
 	JWTSecret:                 "",
@@ -69,7 +72,7 @@ var DefaultClientConfig = ClientConfig{
 }
 
 func RPCClientAddOptions(prefix string, f *flag.FlagSet, defaultConfig *ClientConfig) {
-	f.String(prefix+".url", defaultConfig.URL, "url of server, use self for loopback websocket, self-auth for loopback with authentication")
+	f.String(prefix+".url", defaultConfig.URL, "url of server, use self for loopback websocket, self-auth for loopback with authentication, or unix:///path for the IPC endpoint of a server on the same host")
 	f.String(prefix+".jwtsecret", defaultConfig.JWTSecret, "path to file with jwtsecret for validation - ignored if url is self or self-auth")
 	f.Duration(prefix+".connection-wait", defaultConfig.ConnectionWait, "how long to wait for initial connection")
 	f.Duration(prefix+".timeout", defaultConfig.Timeout, "per-response timeout (0-disabled)")
@@ -247,6 +250,9 @@ func (c *RpcClient) Start(ctx_in context.Context) error {
 		jwtPath = c.autoStack.JWTPath()
 	} else if url == "" {
 		return errors.New("no url provided for this connection")
+	} else if socketPath, ok := strings.CutPrefix(url, UnixSocketURLPrefix); ok {
+		// geth's client dials a plain path as an IPC endpoint, which is a unix domain socket
+		url = socketPath
 	}
 	var jwt *common.Hash
 	if jwtPath != "" {
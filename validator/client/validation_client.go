@@ -57,6 +57,17 @@ func (c *ValidationClient) Launch(entry *validator.ValidationInput, moduleRoot c
 	return server_common.NewValRun(promise, moduleRoot)
 }
 
+// ValidateSigned validates input on the server, which signs the result if it has a signing key.
+func (c *ValidationClient) ValidateSigned(ctx context.Context, input *server_api.InputJSON, moduleRoot common.Hash) (*server_api.SignedValidationResult, error) {
+	c.room.Add(-1)
+	defer c.room.Add(1)
+	var res server_api.SignedValidationResult
+	if err := c.client.CallContext(ctx, &res, server_api.Namespace+"_validateSigned", input, moduleRoot); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
 func (c *ValidationClient) Start(ctx context.Context) error {
 	if err := c.client.Start(ctx); err != nil {
 		return err
@@ -0,0 +1,287 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/spf13/pflag"
+
+	"github.com/offchainlabs/nitro/util/stopwaiter"
+	"github.com/offchainlabs/nitro/validator"
+	"github.com/offchainlabs/nitro/validator/server_api"
+	"github.com/offchainlabs/nitro/validator/server_common"
+)
+
+type ValidationPoolConfig struct {
+	Enable         bool          `koanf:"enable"`
+	MaxAttempts    uint64        `koanf:"max-attempts"`
+	RetryDelay     time.Duration `koanf:"retry-delay"`
+	MaxBackoff     time.Duration `koanf:"max-backoff"`
+	WorkerTimeout  time.Duration `koanf:"worker-timeout"`
+	TrustedSigners []string      `koanf:"trusted-signers"`
+}
+
+func (c *ValidationPoolConfig) Validate() error {
+	if !c.Enable {
+		return nil
+	}
+	if c.MaxAttempts == 0 {
+		return errors.New("validation pool max-attempts must be at least 1")
+	}
+	if c.RetryDelay <= 0 {
+		return errors.New("validation pool retry-delay must be positive")
+	}
+	for _, signer := range c.TrustedSigners {
+		if !common.IsHexAddress(signer) {
+			return fmt.Errorf("invalid validation pool trusted signer %q", signer)
+		}
+	}
+	return nil
+}
+
+var DefaultValidationPoolConfig = ValidationPoolConfig{
+	Enable:         false,
+	MaxAttempts:    3,
+	RetryDelay:     time.Second,
+	MaxBackoff:     time.Minute,
+	WorkerTimeout:  0,
+	TrustedSigners: []string{},
+}
+
+var TestValidationPoolConfig = ValidationPoolConfig{
+	Enable:         false,
+	MaxAttempts:    3,
+	RetryDelay:     10 * time.Millisecond,
+	MaxBackoff:     100 * time.Millisecond,
+	WorkerTimeout:  0,
+	TrustedSigners: []string{},
+}
+
+func ValidationPoolConfigAddOptions(prefix string, f *pflag.FlagSet) {
+	f.Bool(prefix+".enable", DefaultValidationPoolConfig.Enable, "dispatch validations to all validation servers that support the module root, instead of the first one")
+	f.Uint64(prefix+".max-attempts", DefaultValidationPoolConfig.MaxAttempts, "number of validation servers to try a validation on before giving up")
+	f.Duration(prefix+".retry-delay", DefaultValidationPoolConfig.RetryDelay, "time a validation server is skipped for after a failed validation, doubled on each consecutive failure")
+	f.Duration(prefix+".max-backoff", DefaultValidationPoolConfig.MaxBackoff, "maximum time a failing validation server is skipped for")
+	f.Duration(prefix+".worker-timeout", DefaultValidationPoolConfig.WorkerTimeout, "timeout for a single validation on a validation server (0 = no timeout)")
+	f.StringSlice(prefix+".trusted-signers", DefaultValidationPoolConfig.TrustedSigners, "if set, only accept validation results signed by one of these addresses")
+}
+
+type poolWorker struct {
+	client       *ValidationClient
+	failures     uint64
+	backoffUntil time.Time
+}
+
+// ValidationPool is a validation spawner that queues validations and dispatches them to a set of remote validation
+// servers, retrying failed validations on other servers. The pool doesn't own its workers: they're started and
+// stopped by the caller.
+type ValidationPool struct {
+	stopwaiter.StopWaiter
+	config  *ValidationPoolConfig
+	signers map[common.Address]bool
+
+	mutex   sync.Mutex // protects worker state
+	workers []*poolWorker
+	freed   chan struct{}
+}
+
+func NewValidationPool(config *ValidationPoolConfig, clients []*ValidationClient) (*ValidationPool, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	if len(clients) == 0 {
+		return nil, errors.New("validation pool has no validation servers")
+	}
+	signers := make(map[common.Address]bool)
+	for _, signer := range config.TrustedSigners {
+		signers[common.HexToAddress(signer)] = true
+	}
+	workers := make([]*poolWorker, 0, len(clients))
+	for _, client := range clients {
+		workers = append(workers, &poolWorker{client: client})
+	}
+	return &ValidationPool{
+		config:  config,
+		signers: signers,
+		workers: workers,
+		freed:   make(chan struct{}, 1),
+	}, nil
+}
+
+func (p *ValidationPool) Launch(entry *validator.ValidationInput, moduleRoot common.Hash) validator.ValidationRun {
+	promise := stopwaiter.LaunchPromiseThread[validator.GoGlobalState](p, func(ctx context.Context) (validator.GoGlobalState, error) {
+		return p.validate(ctx, server_api.ValidationInputToJson(entry), moduleRoot)
+	})
+	return server_common.NewValRun(promise, moduleRoot)
+}
+
+func (p *ValidationPool) validate(ctx context.Context, input *server_api.InputJSON, moduleRoot common.Hash) (validator.GoGlobalState, error) {
+	var errs []error
+	tried := make(map[*poolWorker]bool)
+	for attempt := uint64(0); attempt < p.config.MaxAttempts; attempt++ {
+		worker, err := p.acquire(ctx, moduleRoot, tried)
+		if err != nil {
+			return validator.GoGlobalState{}, err
+		}
+		state, err := p.validateOn(ctx, worker, input, moduleRoot)
+		p.release(worker, err)
+		if err == nil {
+			return state, nil
+		}
+		if ctx.Err() != nil {
+			return validator.GoGlobalState{}, ctx.Err()
+		}
+		log.Warn("validation failed on validation server", "server", worker.client.Name(), "id", input.Id, "moduleRoot", moduleRoot, "attempt", attempt+1, "err", err)
+		errs = append(errs, fmt.Errorf("%v: %w", worker.client.Name(), err))
+		tried[worker] = true
+	}
+	return validator.GoGlobalState{}, fmt.Errorf("validation of %d failed on all %d attempts: %w", input.Id, p.config.MaxAttempts, errors.Join(errs...))
+}
+
+func (p *ValidationPool) validateOn(ctx context.Context, worker *poolWorker, input *server_api.InputJSON, moduleRoot common.Hash) (validator.GoGlobalState, error) {
+	if p.config.WorkerTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.config.WorkerTimeout)
+		defer cancel()
+	}
+	result, err := worker.client.ValidateSigned(ctx, input, moduleRoot)
+	if err != nil {
+		return validator.GoGlobalState{}, err
+	}
+	if len(p.signers) > 0 {
+		signer, err := server_api.RecoverValidationResultSigner(moduleRoot, input, result)
+		if err != nil {
+			return validator.GoGlobalState{}, fmt.Errorf("bad validation result signature: %w", err)
+		}
+		if !p.signers[signer] {
+			return validator.GoGlobalState{}, fmt.Errorf("validation result signed by untrusted signer %v", signer)
+		}
+	}
+	return result.State, nil
+}
+
+// acquire waits for a worker that supports moduleRoot and has room, preferring workers that haven't been tried yet.
+func (p *ValidationPool) acquire(ctx context.Context, moduleRoot common.Hash, tried map[*poolWorker]bool) (*poolWorker, error) {
+	for {
+		worker, supported := p.pick(moduleRoot, tried)
+		if !supported {
+			return nil, fmt.Errorf("no validation server in the pool supports WasmModuleRoot %v", moduleRoot)
+		}
+		if worker != nil {
+			return worker, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-p.freed:
+		case <-time.After(p.config.RetryDelay):
+		}
+	}
+}
+
+func (p *ValidationPool) pick(moduleRoot common.Hash, tried map[*poolWorker]bool) (*poolWorker, bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	now := time.Now()
+	var best *poolWorker
+	supported := false
+	for _, worker := range p.workers {
+		if !validator.SpawnerSupportsModule(worker.client, moduleRoot) {
+			continue
+		}
+		supported = true
+		if worker.client.Room() == 0 || now.Before(worker.backoffUntil) {
+			continue
+		}
+		if best == nil || (tried[best] && !tried[worker]) ||
+			(tried[best] == tried[worker] && worker.client.Room() > best.client.Room()) {
+			best = worker
+		}
+	}
+	return best, supported
+}
+
+func (p *ValidationPool) release(worker *poolWorker, err error) {
+	p.mutex.Lock()
+	if err == nil {
+		worker.failures = 0
+	} else {
+		worker.failures++
+		backoff := p.config.RetryDelay << min(worker.failures-1, 16)
+		if backoff > p.config.MaxBackoff {
+			backoff = p.config.MaxBackoff
+		}
+		worker.backoffUntil = time.Now().Add(backoff)
+	}
+	p.mutex.Unlock()
+	select {
+	case p.freed <- struct{}{}:
+	default:
+	}
+}
+
+func (p *ValidationPool) WasmModuleRoots() ([]common.Hash, error) {
+	seen := make(map[common.Hash]bool)
+	var roots []common.Hash
+	for _, worker := range p.workers {
+		workerRoots, err := worker.client.WasmModuleRoots()
+		if err != nil {
+			continue
+		}
+		for _, root := range workerRoots {
+			if !seen[root] {
+				seen[root] = true
+				roots = append(roots, root)
+			}
+		}
+	}
+	if len(roots) == 0 {
+		return nil, errors.New("no validation server in the pool is started")
+	}
+	return roots, nil
+}
+
+// StylusArchs returns the archs of all workers, so inputs can be validated on any of them.
+func (p *ValidationPool) StylusArchs() []ethdb.WasmTarget {
+	seen := make(map[ethdb.WasmTarget]bool)
+	var archs []ethdb.WasmTarget
+	for _, worker := range p.workers {
+		for _, arch := range worker.client.StylusArchs() {
+			if !seen[arch] {
+				seen[arch] = true
+				archs = append(archs, arch)
+			}
+		}
+	}
+	return archs
+}
+
+func (p *ValidationPool) Start(ctx context.Context) error {
+	p.StopWaiter.Start(ctx, p)
+	return nil
+}
+
+func (p *ValidationPool) Stop() {
+	p.StopOnly()
+}
+
+func (p *ValidationPool) Name() string {
+	return fmt.Sprintf("validation pool (%d servers)", len(p.workers))
+}
+
+func (p *ValidationPool) Room() int {
+	room := 0
+	for _, worker := range p.workers {
+		room += worker.client.Room()
+	}
+	return room
+}
@@ -0,0 +1,214 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package client
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/offchainlabs/nitro/arbutil"
+	"github.com/offchainlabs/nitro/util/rpcclient"
+	"github.com/offchainlabs/nitro/util/testhelpers"
+	"github.com/offchainlabs/nitro/validator"
+	"github.com/offchainlabs/nitro/validator/server_api"
+)
+
+var mockModuleRoot = common.HexToHash("0xabcd")
+
+type mockWorker struct {
+	name  string
+	key   *ecdsa.PrivateKey
+	fail  bool
+	calls atomic.Int32
+}
+
+func (w *mockWorker) Name() string {
+	return w.name
+}
+
+func (w *mockWorker) Room() int {
+	return 4
+}
+
+func (w *mockWorker) WasmModuleRoots() ([]common.Hash, error) {
+	return []common.Hash{mockModuleRoot}, nil
+}
+
+func (w *mockWorker) StylusArchs() ([]ethdb.WasmTarget, error) {
+	return []ethdb.WasmTarget{"mock"}, nil
+}
+
+func (w *mockWorker) ValidateSigned(_ context.Context, entry *server_api.InputJSON, moduleRoot common.Hash) (*server_api.SignedValidationResult, error) {
+	w.calls.Add(1)
+	if w.fail {
+		return nil, errors.New("worker out of order")
+	}
+	result := &server_api.SignedValidationResult{State: expectedEndState(entry.StartState)}
+	var err error
+	result.Signature, err = crypto.Sign(server_api.ValidationResultHash(moduleRoot, entry, result.State).Bytes(), w.key)
+	return result, err
+}
+
+func expectedEndState(start validator.GoGlobalState) validator.GoGlobalState {
+	return validator.GoGlobalState{
+		BlockHash: crypto.Keccak256Hash(start.BlockHash[:]),
+		Batch:     start.Batch + 1,
+	}
+}
+
+func startMockWorker(t *testing.T, ctx context.Context, worker *mockWorker) *ValidationClient {
+	t.Helper()
+	stackConf := node.DefaultConfig
+	stackConf.HTTPPort = 0
+	stackConf.DataDir = ""
+	stackConf.WSHost = "127.0.0.1"
+	stackConf.WSPort = 0
+	stackConf.WSModules = []string{server_api.Namespace}
+	stackConf.P2P.NoDiscovery = true
+	stackConf.P2P.ListenAddr = ""
+	stack, err := node.New(&stackConf)
+	Require(t, err)
+	stack.RegisterAPIs([]rpc.API{{
+		Namespace: server_api.Namespace,
+		Version:   "1.0",
+		Service:   worker,
+		Public:    true,
+	}})
+	Require(t, stack.Start())
+	t.Cleanup(func() { stack.Close() })
+	client := NewValidationClient(func() *rpcclient.ClientConfig { return &rpcclient.TestClientConfig }, stack)
+	Require(t, client.Start(ctx))
+	t.Cleanup(client.Stop)
+	return client
+}
+
+func newTestKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	Require(t, err)
+	return key
+}
+
+func TestValidationPoolRetriesOnOtherWorkers(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	broken := &mockWorker{name: "broken", key: newTestKey(t), fail: true}
+	working := &mockWorker{name: "working", key: newTestKey(t)}
+	config := TestValidationPoolConfig
+	config.Enable = true
+	config.TrustedSigners = []string{crypto.PubkeyToAddress(working.key.PublicKey).Hex()}
+	pool, err := NewValidationPool(&config, []*ValidationClient{
+		startMockWorker(t, ctx, broken),
+		startMockWorker(t, ctx, working),
+	})
+	Require(t, err)
+	Require(t, pool.Start(ctx))
+	defer pool.Stop()
+
+	if !validator.SpawnerSupportsModule(pool, mockModuleRoot) {
+		Fail(t, "pool doesn't support the workers' module root")
+	}
+	var runs []validator.ValidationRun
+	var starts []validator.GoGlobalState
+	for i := uint64(0); i < 8; i++ {
+		start := validator.GoGlobalState{BlockHash: common.BigToHash(common.Big1), Batch: i}
+		starts = append(starts, start)
+		runs = append(runs, pool.Launch(&validator.ValidationInput{Id: i, StartState: start}, mockModuleRoot))
+	}
+	for i, run := range runs {
+		end, err := run.Await(ctx)
+		Require(t, err)
+		if end != expectedEndState(starts[i]) {
+			Fail(t, "unexpected end state", i, end)
+		}
+	}
+	if working.calls.Load() != 8 {
+		Fail(t, "expected every validation to end up on the working server, got", working.calls.Load())
+	}
+}
+
+func TestValidationPoolRejectsUntrustedSigner(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	untrusted := &mockWorker{name: "untrusted", key: newTestKey(t)}
+	config := TestValidationPoolConfig
+	config.Enable = true
+	config.MaxAttempts = 2
+	config.TrustedSigners = []string{crypto.PubkeyToAddress(newTestKey(t).PublicKey).Hex()}
+	pool, err := NewValidationPool(&config, []*ValidationClient{startMockWorker(t, ctx, untrusted)})
+	Require(t, err)
+	Require(t, pool.Start(ctx))
+	defer pool.Stop()
+
+	_, err = pool.Launch(&validator.ValidationInput{Id: 1}, mockModuleRoot).Await(ctx)
+	if err == nil || !strings.Contains(err.Error(), "untrusted signer") {
+		Fail(t, "expected untrusted signer error, got", err)
+	}
+	if untrusted.calls.Load() != 2 {
+		Fail(t, "expected two attempts, got", untrusted.calls.Load())
+	}
+	if _, err := pool.Launch(&validator.ValidationInput{Id: 2}, common.HexToHash("0x1234")).Await(ctx); err == nil {
+		Fail(t, "validation with an unsupported module root succeeded")
+	}
+}
+
+func TestValidationResultHashCoversInput(t *testing.T) {
+	newInput := func() *validator.ValidationInput {
+		return &validator.ValidationInput{
+			Id:            3,
+			HasDelayedMsg: true,
+			DelayedMsgNr:  7,
+			DelayedMsg:    []byte{1, 2, 3},
+			StartState:    validator.GoGlobalState{Batch: 5},
+			BatchInfo:     []validator.BatchInfo{{Number: 5, Data: []byte{4, 5, 6}}},
+			Preimages: map[arbutil.PreimageType]map[common.Hash][]byte{
+				arbutil.Keccak256PreimageType: {crypto.Keccak256Hash([]byte{7}): {7}},
+			},
+		}
+	}
+	end := expectedEndState(validator.GoGlobalState{Batch: 5})
+	hash := func(input *validator.ValidationInput) common.Hash {
+		return server_api.ValidationResultHash(mockModuleRoot, server_api.ValidationInputToJson(input), end)
+	}
+	expected := hash(newInput())
+	if hash(newInput()) != expected {
+		Fail(t, "the result hash of an input isn't deterministic")
+	}
+	for name, modify := range map[string]func(*validator.ValidationInput){
+		"batch data":      func(input *validator.ValidationInput) { input.BatchInfo[0].Data[0]++ },
+		"batch number":    func(input *validator.ValidationInput) { input.BatchInfo[0].Number++ },
+		"delayed message": func(input *validator.ValidationInput) { input.DelayedMsg[0]++ },
+		"preimage": func(input *validator.ValidationInput) {
+			input.Preimages[arbutil.Keccak256PreimageType][crypto.Keccak256Hash([]byte{8})] = []byte{8}
+		},
+	} {
+		input := newInput()
+		modify(input)
+		if hash(input) == expected {
+			Fail(t, "the result hash doesn't cover the", name)
+		}
+	}
+}
+
+func Require(t *testing.T, err error, printables ...interface{}) {
+	t.Helper()
+	testhelpers.RequireImpl(t, err, printables...)
+}
+
+func Fail(t *testing.T, printables ...interface{}) {
+	t.Helper()
+	testhelpers.FailImpl(t, printables...)
+}
@@ -0,0 +1,381 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+// Package divergence locates the first block whose re-execution by a node diverges from a
+// reference node, and collects the traces needed to understand why.
+package divergence
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+type Config struct {
+	LocalURL     string `koanf:"local-url"`
+	ReferenceURL string `koanf:"reference-url"`
+	StartBlock   uint64 `koanf:"start-block"`
+	EndBlock     uint64 `koanf:"end-block"`
+	Reexec       uint64 `koanf:"reexec"`
+	Tracer       string `koanf:"tracer"`
+	OutputDir    string `koanf:"output-dir"`
+}
+
+var DefaultConfig = Config{
+	LocalURL:   "http://localhost:8547",
+	Reexec:     128,
+	Tracer:     "callTracer",
+	OutputDir:  "divergence",
+	StartBlock: 0,
+	EndBlock:   0,
+}
+
+func ConfigAddOptions(f *flag.FlagSet) {
+	f.String("local-url", DefaultConfig.LocalURL, "RPC endpoint of the node that re-executes the blocks with the code being checked (requires the debug API)")
+	f.String("reference-url", DefaultConfig.ReferenceURL, "RPC endpoint of the trusted reference node")
+	f.Uint64("start-block", DefaultConfig.StartBlock, "first block of the range to bisect, which should match the reference")
+	f.Uint64("end-block", DefaultConfig.EndBlock, "last block of the range to bisect (0 for the latest block of the local node)")
+	f.Uint64("reexec", DefaultConfig.Reexec, "how many blocks back the local node may re-execute to regenerate the state a block is re-executed on")
+	f.String("tracer", DefaultConfig.Tracer, "tracer used with debug_traceTransaction on both nodes for the offending transaction")
+	f.String("output-dir", DefaultConfig.OutputDir, "directory the divergence report and traces are written to")
+}
+
+func (c *Config) Validate() error {
+	if c.ReferenceURL == "" {
+		return errors.New("reference-url must be set")
+	}
+	if c.LocalURL == "" {
+		return errors.New("local-url must be set")
+	}
+	if c.EndBlock != 0 && c.EndBlock < c.StartBlock {
+		return fmt.Errorf("end-block %d is before start-block %d", c.EndBlock, c.StartBlock)
+	}
+	return nil
+}
+
+// Node is the subset of rpc.Client used to query a node.
+type Node interface {
+	CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error
+}
+
+type blockSummary struct {
+	Number       hexutil.Uint64 `json:"number"`
+	Hash         common.Hash    `json:"hash"`
+	StateRoot    common.Hash    `json:"stateRoot"`
+	Transactions []common.Hash  `json:"transactions"`
+}
+
+type txTraceResult struct {
+	TxHash common.Hash     `json:"txHash"`
+	Result json.RawMessage `json:"result"`
+	Error  string          `json:"error"`
+}
+
+// Report describes the first divergent block found. The local side is always the result of
+// re-executing the block on the local node, never what the local node stored for it.
+type Report struct {
+	Block              uint64          `json:"block"`
+	LocalStateRoot     common.Hash     `json:"localStateRoot"`
+	ReferenceStateRoot common.Hash     `json:"referenceStateRoot"`
+	StateRootDiffers   bool            `json:"stateRootDiffers"`
+	ReceiptsDiffer     bool            `json:"receiptsDiffer"`
+	OffendingTx        *common.Hash    `json:"offendingTx,omitempty"`
+	OffendingTxIndex   *int            `json:"offendingTxIndex,omitempty"`
+	ReceiptDifference  string          `json:"receiptDifference,omitempty"`
+	LocalTrace         json.RawMessage `json:"-"`
+	ReferenceTrace     json.RawMessage `json:"-"`
+}
+
+// ErrNoDivergence is returned when the end of the range matches the reference,
+// in which case (assuming divergence persists once it occurs) no earlier block diverges either.
+var ErrNoDivergence = errors.New("no divergence found in block range")
+
+type Bisector struct {
+	config    *Config
+	local     Node
+	reference Node
+}
+
+func NewBisector(config *Config, local, reference Node) *Bisector {
+	return &Bisector{
+		config:    config,
+		local:     local,
+		reference: reference,
+	}
+}
+
+func getBlock(ctx context.Context, node Node, number uint64) (*blockSummary, error) {
+	var block *blockSummary
+	err := node.CallContext(ctx, &block, "eth_getBlockByNumber", hexutil.Uint64(number), false)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block %d not found", number)
+	}
+	return block, nil
+}
+
+// intermediateRoots re-executes a block on node, returning the state root after each of its transactions.
+func (b *Bisector) intermediateRoots(ctx context.Context, node Node, block common.Hash) ([]common.Hash, error) {
+	var roots []common.Hash
+	err := node.CallContext(ctx, &roots, "debug_intermediateRoots", block, map[string]interface{}{"reexec": b.config.Reexec})
+	return roots, err
+}
+
+// traceBlock re-executes a block on node, returning each transaction's calls, result and logs, which
+// cover everything its receipt is derived from.
+func (b *Bisector) traceBlock(ctx context.Context, node Node, block common.Hash) ([]txTraceResult, error) {
+	var traces []txTraceResult
+	traceConfig := map[string]interface{}{
+		"tracer":       "callTracer",
+		"tracerConfig": map[string]interface{}{"withLog": true},
+		"reexec":       b.config.Reexec,
+	}
+	err := node.CallContext(ctx, &traces, "debug_traceBlockByHash", block, traceConfig)
+	return traces, err
+}
+
+// reexecute re-executes block number on the local node and compares its state root to the reference's.
+func (b *Bisector) reexecute(ctx context.Context, number uint64) (*blockSummary, *blockSummary, []common.Hash, bool, error) {
+	local, err := getBlock(ctx, b.local, number)
+	if err != nil {
+		return nil, nil, nil, false, fmt.Errorf("local node: %w", err)
+	}
+	reference, err := getBlock(ctx, b.reference, number)
+	if err != nil {
+		return nil, nil, nil, false, fmt.Errorf("reference node: %w", err)
+	}
+	if !equalHashes(local.Transactions, reference.Transactions) {
+		// the local node's chain has other transactions, so re-executing them can't reproduce the reference
+		return local, reference, nil, true, nil
+	}
+	roots, err := b.intermediateRoots(ctx, b.local, local.Hash)
+	if err != nil {
+		return nil, nil, nil, false, fmt.Errorf("failed to re-execute block %d on the local node: %w", number, err)
+	}
+	if len(roots) != len(local.Transactions) {
+		return nil, nil, nil, false, fmt.Errorf("re-executing block %d produced %d roots for %d transactions", number, len(roots), len(local.Transactions))
+	}
+	diverges := len(roots) > 0 && roots[len(roots)-1] != reference.StateRoot
+	return local, reference, roots, diverges, nil
+}
+
+func equalHashes(a, b []common.Hash) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (b *Bisector) endBlock(ctx context.Context) (uint64, error) {
+	if b.config.EndBlock != 0 {
+		return b.config.EndBlock, nil
+	}
+	var latest hexutil.Uint64
+	if err := b.local.CallContext(ctx, &latest, "eth_blockNumber"); err != nil {
+		return 0, err
+	}
+	return uint64(latest), nil
+}
+
+// FindFirstDivergentBlock binary searches the configured range for the first block whose re-execution
+// on the local node produces a state root other than the reference's.
+func (b *Bisector) FindFirstDivergentBlock(ctx context.Context) (uint64, error) {
+	end, err := b.endBlock(ctx)
+	if err != nil {
+		return 0, err
+	}
+	start := b.config.StartBlock
+	if end < start {
+		return 0, fmt.Errorf("end block %d is before start block %d", end, start)
+	}
+	_, _, _, diverges, err := b.reexecute(ctx, end)
+	if err != nil {
+		return 0, err
+	}
+	if !diverges {
+		return 0, ErrNoDivergence
+	}
+	_, _, _, diverges, err = b.reexecute(ctx, start)
+	if err != nil {
+		return 0, err
+	}
+	if diverges {
+		log.Warn("start block already diverges from reference, consider an earlier start block", "start", start)
+		return start, nil
+	}
+	// invariant: lo matches the reference, hi diverges
+	lo, hi := start, end
+	for hi-lo > 1 {
+		mid := lo + (hi-lo)/2
+		_, _, _, diverges, err := b.reexecute(ctx, mid)
+		if err != nil {
+			return 0, err
+		}
+		log.Info("bisecting", "block", mid, "diverges", diverges, "remaining", hi-lo)
+		if diverges {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+	return hi, nil
+}
+
+// compareTraces returns a description of the first difference between two re-executions of a transaction.
+func compareTraces(local, reference txTraceResult) string {
+	if local.Error != reference.Error {
+		return fmt.Sprintf("trace error local %q reference %q", local.Error, reference.Error)
+	}
+	var localResult, referenceResult interface{}
+	if err := json.Unmarshal(local.Result, &localResult); err != nil {
+		return fmt.Sprintf("invalid local trace: %v", err)
+	}
+	if err := json.Unmarshal(reference.Result, &referenceResult); err != nil {
+		return fmt.Sprintf("invalid reference trace: %v", err)
+	}
+	// re-encoding sorts object keys, so only the contents are compared
+	localCanonical, _ := json.Marshal(localResult)
+	referenceCanonical, _ := json.Marshal(referenceResult)
+	if !bytes.Equal(localCanonical, referenceCanonical) {
+		return "gas used, result, calls or logs differ"
+	}
+	return ""
+}
+
+func (b *Bisector) trace(ctx context.Context, node Node, tx common.Hash) (json.RawMessage, error) {
+	var result json.RawMessage
+	traceConfig := map[string]interface{}{"reexec": b.config.Reexec}
+	if b.config.Tracer != "" {
+		traceConfig["tracer"] = b.config.Tracer
+	}
+	err := node.CallContext(ctx, &result, "debug_traceTransaction", tx, traceConfig)
+	return result, err
+}
+
+// Inspect re-executes a divergent block on both nodes and finds the first transaction after which the
+// state roots differ, or whose calls, result or logs differ, then traces it on both nodes. If nothing
+// differs per transaction, the last transaction is traced since the block's state root is taken after it.
+func (b *Bisector) Inspect(ctx context.Context, number uint64) (*Report, error) {
+	local, reference, localRoots, _, err := b.reexecute(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	report := &Report{
+		Block:              number,
+		ReferenceStateRoot: reference.StateRoot,
+	}
+	if localRoots == nil {
+		report.StateRootDiffers = true
+		report.ReceiptDifference = fmt.Sprintf("transactions differ, local %d reference %d", len(local.Transactions), len(reference.Transactions))
+		return report, nil
+	}
+	if len(localRoots) > 0 {
+		report.LocalStateRoot = localRoots[len(localRoots)-1]
+	}
+	report.StateRootDiffers = report.LocalStateRoot != report.ReferenceStateRoot
+
+	referenceRoots, err := b.intermediateRoots(ctx, b.reference, reference.Hash)
+	if err != nil || len(referenceRoots) != len(localRoots) {
+		log.Warn("failed to re-execute the block on the reference node, not comparing intermediate roots", "block", number, "err", err)
+		referenceRoots = nil
+	}
+	localTraces, err := b.traceBlock(ctx, b.local, local.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to trace block %d on the local node: %w", number, err)
+	}
+	referenceTraces, err := b.traceBlock(ctx, b.reference, reference.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to trace block %d on the reference node: %w", number, err)
+	}
+	if len(localTraces) != len(local.Transactions) || len(referenceTraces) != len(local.Transactions) {
+		return nil, fmt.Errorf("block %d has %d transactions but got %d local and %d reference traces", number, len(local.Transactions), len(localTraces), len(referenceTraces))
+	}
+	for i := range local.Transactions {
+		difference := compareTraces(localTraces[i], referenceTraces[i])
+		if difference != "" {
+			report.ReceiptsDiffer = true
+		} else if referenceRoots != nil && localRoots[i] != referenceRoots[i] {
+			difference = fmt.Sprintf("state root after the transaction local %v reference %v", localRoots[i], referenceRoots[i])
+		}
+		if difference != "" {
+			index := i
+			report.OffendingTx = &local.Transactions[i]
+			report.OffendingTxIndex = &index
+			report.ReceiptDifference = difference
+			break
+		}
+	}
+	if report.OffendingTx == nil && len(local.Transactions) > 0 {
+		index := len(local.Transactions) - 1
+		report.OffendingTx = &local.Transactions[index]
+		report.OffendingTxIndex = &index
+	}
+	if report.OffendingTx != nil {
+		report.LocalTrace, err = b.trace(ctx, b.local, *report.OffendingTx)
+		if err != nil {
+			log.Warn("failed to trace offending transaction on local node", "tx", report.OffendingTx, "err", err)
+		}
+		report.ReferenceTrace, err = b.trace(ctx, b.reference, *report.OffendingTx)
+		if err != nil {
+			log.Warn("failed to trace offending transaction on reference node", "tx", report.OffendingTx, "err", err)
+		}
+	}
+	return report, nil
+}
+
+// Run bisects the configured range and inspects the first divergent block.
+func (b *Bisector) Run(ctx context.Context) (*Report, error) {
+	number, err := b.FindFirstDivergentBlock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	log.Info("found first divergent block", "block", number)
+	return b.Inspect(ctx, number)
+}
+
+// WriteReport writes the report summary and both traces to dir.
+func WriteReport(dir string, report *Report) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	summary, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	prefix := filepath.Join(dir, fmt.Sprintf("block-%d", report.Block))
+	// #nosec G306
+	if err := os.WriteFile(prefix+"-report.json", summary, 0o644); err != nil {
+		return err
+	}
+	traces := map[string]json.RawMessage{
+		"-local-trace.json":     report.LocalTrace,
+		"-reference-trace.json": report.ReferenceTrace,
+	}
+	for suffix, trace := range traces {
+		if len(trace) == 0 {
+			continue
+		}
+		// #nosec G306
+		if err := os.WriteFile(prefix+suffix, trace, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
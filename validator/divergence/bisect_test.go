@@ -0,0 +1,145 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package divergence
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// fakeNode serves blocks with one transaction each. It stores the honest chain, but its
+// re-execution of the blocks from divergeAt on produces other roots and gas usage.
+type fakeNode struct {
+	head      uint64
+	divergeAt uint64
+	calls     int
+}
+
+func txHash(block uint64) common.Hash {
+	return common.BigToHash(new(big.Int).SetUint64(block + 1000))
+}
+
+func blockHash(block uint64) common.Hash {
+	return common.BigToHash(new(big.Int).SetUint64(block))
+}
+
+func honestRoot(block uint64) common.Hash {
+	return common.BigToHash(new(big.Int).SetUint64(block + 2000))
+}
+
+func (n *fakeNode) reexecutedRoot(block uint64) common.Hash {
+	root := honestRoot(block)
+	if block >= n.divergeAt {
+		root[0] = 0xff
+	}
+	return root
+}
+
+func (n *fakeNode) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	n.calls++
+	var value interface{}
+	switch method {
+	case "eth_blockNumber":
+		value = hexutil.Uint64(n.head)
+	case "eth_getBlockByNumber":
+		number := uint64(args[0].(hexutil.Uint64))
+		if number > n.head {
+			value = nil
+			break
+		}
+		value = &blockSummary{
+			Number:       hexutil.Uint64(number),
+			Hash:         blockHash(number),
+			StateRoot:    honestRoot(number),
+			Transactions: []common.Hash{txHash(number)},
+		}
+	case "debug_intermediateRoots":
+		number := args[0].(common.Hash).Big().Uint64()
+		value = []common.Hash{n.reexecutedRoot(number)}
+	case "debug_traceBlockByHash":
+		number := args[0].(common.Hash).Big().Uint64()
+		gasUsed := uint64(21000)
+		if number == n.divergeAt {
+			gasUsed++
+		}
+		value = []map[string]interface{}{{
+			"txHash": txHash(number),
+			"result": map[string]interface{}{"gasUsed": hexutil.Uint64(gasUsed)},
+		}}
+	case "debug_traceTransaction":
+		value = map[string]interface{}{"divergeAt": n.divergeAt}
+	default:
+		return fmt.Errorf("unexpected method %v", method)
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(encoded, result)
+}
+
+func TestBisectFindsFirstDivergentBlock(t *testing.T) {
+	ctx := context.Background()
+	reference := &fakeNode{head: 1000, divergeAt: 1 << 62}
+	for _, divergeAt := range []uint64{1, 2, 17, 500, 999, 1000} {
+		local := &fakeNode{head: 1000, divergeAt: divergeAt}
+		config := DefaultConfig
+		bisector := NewBisector(&config, local, reference)
+		report, err := bisector.Run(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if report.Block != divergeAt {
+			t.Fatalf("expected divergence at %d, got %d", divergeAt, report.Block)
+		}
+		if report.OffendingTx == nil || *report.OffendingTx != txHash(divergeAt) {
+			t.Fatalf("expected offending tx %v, got %v", txHash(divergeAt), report.OffendingTx)
+		}
+		if report.ReceiptDifference == "" || !report.StateRootDiffers || !report.ReceiptsDiffer {
+			t.Fatal("expected differences to be reported", report)
+		}
+		if len(report.LocalTrace) == 0 || len(report.ReferenceTrace) == 0 {
+			t.Fatal("expected traces from both nodes")
+		}
+	}
+}
+
+func TestBisectNoDivergence(t *testing.T) {
+	local := &fakeNode{head: 100, divergeAt: 1 << 62}
+	reference := &fakeNode{head: 100, divergeAt: 1 << 62}
+	config := DefaultConfig
+	_, err := NewBisector(&config, local, reference).Run(context.Background())
+	if !errors.Is(err, ErrNoDivergence) {
+		t.Fatal("expected no divergence, got", err)
+	}
+}
+
+func TestWriteReport(t *testing.T) {
+	local := &fakeNode{head: 100, divergeAt: 42}
+	reference := &fakeNode{head: 100, divergeAt: 1 << 62}
+	config := DefaultConfig
+	config.EndBlock = 64
+	report, err := NewBisector(&config, local, reference).Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := WriteReport(dir, report); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"block-42-report.json", "block-42-local-trace.json", "block-42-reference-trace.json"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
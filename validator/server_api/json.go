@@ -9,12 +9,16 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"slices"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/offchainlabs/nitro/arbcompress"
 	"github.com/offchainlabs/nitro/arbutil"
 
+	"github.com/offchainlabs/nitro/util/arbmath"
 	"github.com/offchainlabs/nitro/util/jsonapi"
 	"github.com/offchainlabs/nitro/validator"
 )
@@ -51,6 +55,103 @@ func RedisStreamForRoot(prefix string, moduleRoot common.Hash) string {
 	return fmt.Sprintf("%sstream:%s", prefix, moduleRoot.Hex())
 }
 
+// SignedValidationResult is the end state of a validation, signed by the validation server that computed it.
+type SignedValidationResult struct {
+	State     validator.GoGlobalState
+	Signature hexutil.Bytes
+}
+
+// ValidationResultHash is the digest a validation server signs for the result of validating input with moduleRoot.
+func ValidationResultHash(moduleRoot common.Hash, input *InputJSON, end validator.GoGlobalState) common.Hash {
+	return crypto.Keccak256Hash(
+		[]byte("Arbitrum validation result:"),
+		moduleRoot[:],
+		ValidationInputHash(input).Bytes(),
+		end.Hash().Bytes(),
+	)
+}
+
+// ValidationInputHash commits to every field of input, including its batches, delayed message, preimages and
+// wasms, so a signed result can't be replayed for a different input with the same id and start state.
+func ValidationInputHash(input *InputJSON) common.Hash {
+	hasher := crypto.NewKeccakState()
+	write := func(data ...[]byte) {
+		for _, item := range data {
+			hasher.Write(arbmath.UintToBytes(uint64(len(item))))
+			hasher.Write(item)
+		}
+	}
+	write(
+		arbmath.UintToBytes(input.Id),
+		input.StartState.Hash().Bytes(),
+		arbmath.BoolToBytes(input.HasDelayedMsg),
+		arbmath.UintToBytes(input.DelayedMsgNr),
+		[]byte(input.DelayedMsgB64),
+		arbmath.BoolToBytes(input.DebugChain),
+	)
+	write(arbmath.UintToBytes(uint64(len(input.BatchInfo))))
+	for _, batch := range input.BatchInfo {
+		write(arbmath.UintToBytes(batch.Number), []byte(batch.DataB64))
+	}
+
+	preimageTypes := make([]arbutil.PreimageType, 0, len(input.PreimagesB64))
+	for ty := range input.PreimagesB64 {
+		preimageTypes = append(preimageTypes, ty)
+	}
+	slices.Sort(preimageTypes)
+	write(arbmath.UintToBytes(uint64(len(preimageTypes))))
+	for _, ty := range preimageTypes {
+		var preimages map[common.Hash][]byte
+		if input.PreimagesB64[ty] != nil {
+			preimages = input.PreimagesB64[ty].Map
+		}
+		hashes := make([]common.Hash, 0, len(preimages))
+		for hash := range preimages {
+			hashes = append(hashes, hash)
+		}
+		slices.SortFunc(hashes, func(a, b common.Hash) int { return a.Cmp(b) })
+		write([]byte{byte(ty)}, arbmath.UintToBytes(uint64(len(hashes))))
+		for _, hash := range hashes {
+			write(hash[:], preimages[hash])
+		}
+	}
+
+	targets := make([]ethdb.WasmTarget, 0, len(input.UserWasms))
+	for target := range input.UserWasms {
+		targets = append(targets, target)
+	}
+	slices.Sort(targets)
+	write(arbmath.UintToBytes(uint64(len(targets))))
+	for _, target := range targets {
+		wasms := input.UserWasms[target]
+		moduleHashes := make([]common.Hash, 0, len(wasms))
+		for moduleHash := range wasms {
+			moduleHashes = append(moduleHashes, moduleHash)
+		}
+		slices.SortFunc(moduleHashes, func(a, b common.Hash) int { return a.Cmp(b) })
+		write([]byte(target), arbmath.UintToBytes(uint64(len(moduleHashes))))
+		for _, moduleHash := range moduleHashes {
+			write(moduleHash[:], []byte(wasms[moduleHash]))
+		}
+	}
+
+	var digest common.Hash
+	_, _ = hasher.Read(digest[:])
+	return digest
+}
+
+// RecoverValidationResultSigner returns the address that signed result.
+func RecoverValidationResultSigner(moduleRoot common.Hash, input *InputJSON, result *SignedValidationResult) (common.Address, error) {
+	if len(result.Signature) == 0 {
+		return common.Address{}, errors.New("validation result is not signed")
+	}
+	pubkey, err := crypto.SigToPub(ValidationResultHash(moduleRoot, input, result.State).Bytes(), result.Signature)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*pubkey), nil
+}
+
 type Request struct {
 	Input      *InputJSON
 	ModuleRoot common.Hash
@@ -76,3 +76,20 @@ func createArbMachine(ctx context.Context, locator *server_common.MachineLocator
 	result.hostIo.Freeze()
 	return result, nil
 }
+
+// VerifyMachineModuleRoot loads the machine in dir and checks its module root. It's the verifier for machines
+// fetched by the machine registry.
+func VerifyMachineModuleRoot(dir string, moduleRoot common.Hash) error {
+	cBinPath := C.CString(filepath.Join(dir, DefaultArbitratorMachineConfig.WavmBinaryPath))
+	defer C.free(unsafe.Pointer(cBinPath))
+	baseMachine := C.arbitrator_load_wavm_binary(cBinPath)
+	if baseMachine == nil {
+		return errors.New("failed to load machine")
+	}
+	machine := machineFromPointer(baseMachine)
+	defer machine.Destroy()
+	if machineModuleRoot := machine.GetModuleRoot(); machineModuleRoot != moduleRoot {
+		return fmt.Errorf("expected module root %v but machine has module root %v", moduleRoot, machineModuleRoot)
+	}
+	return nil
+}
@@ -50,6 +50,10 @@ func (l *MachineLoader[M]) GetMachine(ctx context.Context, moduleRoot common.Has
 		status = newMachineStatus[M]()
 		l.machines[moduleRoot] = status
 		go func() {
+			if err := l.locator.EnsureMachine(context.Background(), moduleRoot); err != nil {
+				status.ProduceError(err)
+				return
+			}
 			machine, err := l.createMachine(context.Background(), moduleRoot)
 			if err != nil {
 				status.ProduceError(err)
@@ -1,11 +1,13 @@
 package server_common
 
 import (
+	"context"
 	"errors"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
@@ -14,7 +16,9 @@ import (
 type MachineLocator struct {
 	rootPath    string
 	latest      common.Hash
+	mutex       sync.RWMutex
 	moduleRoots []common.Hash
+	registry    *MachineRegistry
 }
 
 var ErrMachineNotFound = errors.New("machine not found")
@@ -105,7 +109,7 @@ func NewMachineLocator(rootPath string) (*MachineLocator, error) {
 	}, nil
 }
 
-func (l MachineLocator) GetMachinePath(moduleRoot common.Hash) string {
+func (l *MachineLocator) GetMachinePath(moduleRoot common.Hash) string {
 	if moduleRoot == (common.Hash{}) || moduleRoot == l.latest {
 		return filepath.Join(l.rootPath, "latest")
 	} else {
@@ -113,14 +117,77 @@ func (l MachineLocator) GetMachinePath(moduleRoot common.Hash) string {
 	}
 }
 
-func (l MachineLocator) LatestWasmModuleRoot() common.Hash {
+func (l *MachineLocator) LatestWasmModuleRoot() common.Hash {
 	return l.latest
 }
 
-func (l MachineLocator) RootPath() string {
+func (l *MachineLocator) RootPath() string {
 	return l.rootPath
 }
 
-func (l MachineLocator) ModuleRoots() []common.Hash {
-	return l.moduleRoots
+// ModuleRoots returns the module roots of the machines in the root path, and, if a registry is enabled,
+// those that can be fetched.
+func (l *MachineLocator) ModuleRoots() []common.Hash {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	if l.registry == nil {
+		return l.moduleRoots
+	}
+	roots := append([]common.Hash{}, l.moduleRoots...)
+	for _, moduleRoot := range l.registry.ModuleRoots() {
+		if !l.hasLocalMachine(moduleRoot) {
+			roots = append(roots, moduleRoot)
+		}
+	}
+	return roots
+}
+
+// EnableRegistry lets the locator fetch machines that aren't in the root path from the registry. If no machines
+// were found, fetched machines are stored in the registry's configured directory instead.
+func (l *MachineLocator) EnableRegistry(config *MachineRegistryConfig, verify MachineVerifier) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	dir := l.rootPath
+	if dir == "" {
+		dir = config.Dir
+	}
+	registry, err := NewMachineRegistry(config, dir, verify)
+	if err != nil {
+		return err
+	}
+	l.rootPath = dir
+	l.registry = registry
+	return nil
+}
+
+func (l *MachineLocator) hasLocalMachine(moduleRoot common.Hash) bool {
+	for _, root := range l.moduleRoots {
+		if root == moduleRoot {
+			return true
+		}
+	}
+	return false
+}
+
+// EnsureMachine fetches the machine for moduleRoot if it isn't in the root path and a registry is enabled.
+func (l *MachineLocator) EnsureMachine(ctx context.Context, moduleRoot common.Hash) error {
+	if moduleRoot == (common.Hash{}) || moduleRoot == l.latest {
+		return nil
+	}
+	l.mutex.RLock()
+	registry := l.registry
+	found := l.hasLocalMachine(moduleRoot)
+	l.mutex.RUnlock()
+	if found || registry == nil {
+		return nil
+	}
+	if err := registry.Fetch(ctx, moduleRoot); err != nil {
+		return err
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if !l.hasLocalMachine(moduleRoot) {
+		l.moduleRoots = append(l.moduleRoots, moduleRoot)
+	}
+	return nil
 }
@@ -0,0 +1,250 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package server_common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	flag "github.com/spf13/pflag"
+)
+
+type MachineRegistryConfig struct {
+	Enable   bool          `koanf:"enable"`
+	Mirrors  []string      `koanf:"mirrors"`
+	Releases []string      `koanf:"releases"`
+	Dir      string        `koanf:"dir"`
+	Timeout  time.Duration `koanf:"timeout"`
+}
+
+var DefaultMachineRegistryConfig = MachineRegistryConfig{
+	Enable:   false,
+	Mirrors:  []string{"https://github.com/OffchainLabs/nitro/releases/download"},
+	Releases: []string{},
+	Dir:      "",
+	Timeout:  10 * time.Minute,
+}
+
+func MachineRegistryConfigAddOptions(prefix string, f *flag.FlagSet) {
+	f.Bool(prefix+".enable", DefaultMachineRegistryConfig.Enable, "fetch machines for known wasm module roots that aren't in the root path when they're needed")
+	f.StringSlice(prefix+".mirrors", DefaultMachineRegistryConfig.Mirrors, "base urls to fetch machines from, in order of preference; a machine's files are fetched from <mirror>/<release>/<file>")
+	f.StringSlice(prefix+".releases", DefaultMachineRegistryConfig.Releases, "additional machine releases, as <wasm module root>=<release>")
+	f.String(prefix+".dir", DefaultMachineRegistryConfig.Dir, "directory to store fetched machines in if no machines were found in the root path")
+	f.Duration(prefix+".timeout", DefaultMachineRegistryConfig.Timeout, "timeout for fetching a machine from all mirrors")
+}
+
+func (c *MachineRegistryConfig) Validate() error {
+	if !c.Enable {
+		return nil
+	}
+	if len(c.Mirrors) == 0 {
+		return errors.New("the machine registry requires at least one mirror")
+	}
+	_, err := c.releases()
+	return err
+}
+
+// Releases of the Arbitrum machine, by wasm module root
+var knownMachineReleases = map[common.Hash]string{
+	common.HexToHash("0xbb9d58e9527566138b682f3a207c0976d5359837f6e330f4017434cca983ff41"): "consensus-v1-rc1",
+	common.HexToHash("0x9d68e40c47e3b87a8a7e6368cc52915720a6484bb2f47ceabad7e573e3a11232"): "consensus-v2.1",
+	common.HexToHash("0x53c288a0ca7100c0f2db8ab19508763a51c7fd1be125d376d940a65378acaee7"): "consensus-v3",
+	common.HexToHash("0x588762be2f364be15d323df2aa60ffff60f2b14103b34823b6f7319acd1ae7a3"): "consensus-v3.1",
+	common.HexToHash("0xcfba6a883c50a1b4475ab909600fa88fc9cceed9e3ff6f43dccd2d27f6bd57cf"): "consensus-v3.2",
+	common.HexToHash("0xa24ccdb052d92c5847e8ea3ce722442358db4b00985a9ee737c4e601b6ed9876"): "consensus-v4",
+	common.HexToHash("0x1e09e6d9e35b93f33ed22b2bc8dc10bbcf63fdde5e8a1fb8cc1bcd1a52f14bd0"): "consensus-v5",
+	common.HexToHash("0x3848eff5e0356faf1fc9cafecb789584c5e7f4f8f817694d842ada96613d8bab"): "consensus-v6",
+	common.HexToHash("0x53dd4b9a3d807a8cbb4d58fbfc6a0857c3846d46956848cae0a1cc7eca2bb5a8"): "consensus-v7",
+	common.HexToHash("0x2b20e1490d1b06299b222f3239b0ae07e750d8f3b4dedd19f500a815c1548bbc"): "consensus-v7.1",
+	common.HexToHash("0xd1842bfbe047322b3f3b3635b5fe62eb611557784d17ac1d2b1ce9c170af6544"): "consensus-v9",
+	common.HexToHash("0x6b94a7fc388fd8ef3def759297828dc311761e88d8179c7ee8d3887dc554f3c3"): "consensus-v10",
+	common.HexToHash("0xda4e3ad5e7feacb817c21c8d0220da7650fe9051ece68a3f0b1c5d38bbb27b21"): "consensus-v10.1",
+	common.HexToHash("0x0754e09320c381566cc0449904c377a52bd34a6b9404432e80afd573b67f7b17"): "consensus-v10.2",
+	common.HexToHash("0xf559b6d4fa869472dabce70fe1c15221bdda837533dfd891916836975b434dec"): "consensus-v10.3",
+	common.HexToHash("0xf4389b835497a910d7ba3ebfb77aa93da985634f3c052de1290360635be40c4a"): "consensus-v11",
+	common.HexToHash("0x68e4fe5023f792d4ef584796c84d710303a5e12ea02d6e37e2b5e9c4332507c4"): "consensus-v11.1",
+	common.HexToHash("0x8b104a2e80ac6165dc58b9048de12f301d70b02a0ab51396c22b4b4b802a16a4"): "consensus-v20",
+	common.HexToHash("0xb0de9cb89e4d944ae6023a3b62276e54804c242fd8c4c2d8e6cc4450f5fa8b1b"): "consensus-v30",
+	common.HexToHash("0x260f5fa5c3176a856893642e149cf128b5a8de9f828afec8d11184415dd8dc69"): "consensus-v31",
+}
+
+func (c *MachineRegistryConfig) releases() (map[common.Hash]string, error) {
+	releases := make(map[common.Hash]string, len(knownMachineReleases)+len(c.Releases))
+	for moduleRoot, release := range knownMachineReleases {
+		releases[moduleRoot] = release
+	}
+	for _, entry := range c.Releases {
+		root, release, ok := strings.Cut(entry, "=")
+		if !ok || !isHexHash(root) || release == "" || strings.ContainsAny(release, "/\\") {
+			return nil, fmt.Errorf("invalid machine release %q, expected <wasm module root>=<release>", entry)
+		}
+		releases[common.HexToHash(root)] = release
+	}
+	return releases, nil
+}
+
+// Lookup returns the release a module root's machine is fetched from.
+func (c *MachineRegistryConfig) Lookup(moduleRoot common.Hash) (string, bool) {
+	releases, err := c.releases()
+	if err != nil {
+		return "", false
+	}
+	release, ok := releases[moduleRoot]
+	return release, ok
+}
+
+func isHexHash(s string) bool {
+	s = strings.TrimPrefix(s, "0x")
+	if len(s) != 2*common.HashLength {
+		return false
+	}
+	for _, c := range s {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", c) {
+			return false
+		}
+	}
+	return true
+}
+
+// MachineVerifier checks the machine fetched into dir has the given module root.
+type MachineVerifier func(dir string, moduleRoot common.Hash) error
+
+type machineFile struct {
+	name     string
+	optional bool
+}
+
+var machineFiles = []machineFile{
+	{name: "machine.wavm.br"},
+	{name: "replay.wasm", optional: true}, // older releases don't include it
+}
+
+var errFileNotFound = errors.New("file not found")
+
+// MachineRegistry fetches the machines of known module roots into a machine root directory.
+type MachineRegistry struct {
+	config   *MachineRegistryConfig
+	releases map[common.Hash]string
+	dir      string
+	verify   MachineVerifier
+	client   *http.Client
+	mutex    sync.Mutex // held while fetching
+}
+
+func NewMachineRegistry(config *MachineRegistryConfig, dir string, verify MachineVerifier) (*MachineRegistry, error) {
+	releases, err := config.releases()
+	if err != nil {
+		return nil, err
+	}
+	if dir == "" {
+		return nil, errors.New("no directory to store fetched machines in")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &MachineRegistry{
+		config:   config,
+		releases: releases,
+		dir:      dir,
+		verify:   verify,
+		client:   &http.Client{},
+	}, nil
+}
+
+func (r *MachineRegistry) ModuleRoots() []common.Hash {
+	roots := make([]common.Hash, 0, len(r.releases))
+	for moduleRoot := range r.releases {
+		roots = append(roots, moduleRoot)
+	}
+	return roots
+}
+
+// Fetch downloads the machine for moduleRoot from the first mirror that has it, verifies it, and moves it into place.
+func (r *MachineRegistry) Fetch(ctx context.Context, moduleRoot common.Hash) error {
+	release, ok := r.releases[moduleRoot]
+	if !ok {
+		return fmt.Errorf("%w: module root %v isn't in the machine registry", ErrMachineNotFound, moduleRoot)
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	target := filepath.Join(r.dir, moduleRoot.String())
+	if _, err := os.Stat(target); err == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, r.config.Timeout)
+	defer cancel()
+	var errs []error
+	for _, mirror := range r.config.Mirrors {
+		start := time.Now()
+		err := r.fetchFrom(ctx, mirror, release, moduleRoot, target)
+		if err == nil {
+			log.Info("fetched machine", "moduleRoot", moduleRoot, "release", release, "mirror", mirror, "elapsed", time.Since(start))
+			return nil
+		}
+		log.Warn("failed to fetch machine from mirror", "moduleRoot", moduleRoot, "release", release, "mirror", mirror, "err", err)
+		errs = append(errs, fmt.Errorf("%v: %w", mirror, err))
+	}
+	return fmt.Errorf("failed to fetch machine %v (release %v): %w", moduleRoot, release, errors.Join(errs...))
+}
+
+func (r *MachineRegistry) fetchFrom(ctx context.Context, mirror string, release string, moduleRoot common.Hash, target string) error {
+	tmpDir, err := os.MkdirTemp(r.dir, ".fetch-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+	base := strings.TrimSuffix(mirror, "/") + "/" + release + "/"
+	for _, file := range machineFiles {
+		err := r.download(ctx, base+file.name, filepath.Join(tmpDir, file.name))
+		if errors.Is(err, errFileNotFound) && file.optional {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "module-root.txt"), []byte(moduleRoot.Hex()+"\n"), 0644); err != nil {
+		return err
+	}
+	if r.verify != nil {
+		if err := r.verify(tmpDir, moduleRoot); err != nil {
+			return fmt.Errorf("fetched machine failed verification: %w", err)
+		}
+	}
+	return os.Rename(tmpDir, target)
+}
+
+func (r *MachineRegistry) download(ctx context.Context, url string, path string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: %v", errFileNotFound, url)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status fetching %v: %v", url, resp.Status)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(file, resp.Body)
+	return errors.Join(err, file.Close())
+}
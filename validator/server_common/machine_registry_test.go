@@ -0,0 +1,107 @@
+// Copyright 2024, Offchain Labs, Inc.
+// For license information, see https://github.com/nitro/blob/master/LICENSE
+
+package server_common
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestMachineRegistryFetch(t *testing.T) {
+	moduleRoot := common.HexToHash("0x1111111111111111111111111111111111111111111111111111111111111111")
+	badRoot := common.HexToHash("0x2222222222222222222222222222222222222222222222222222222222222222")
+	machine := []byte("machine for " + moduleRoot.Hex())
+	mux := http.NewServeMux()
+	mux.HandleFunc("/good/test-release/machine.wavm.br", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write(machine)
+	})
+	mux.HandleFunc("/good/bad-release/machine.wavm.br", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("corrupt"))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	config := DefaultMachineRegistryConfig
+	config.Enable = true
+	config.Mirrors = []string{server.URL + "/missing", server.URL + "/good/"}
+	config.Releases = []string{moduleRoot.Hex() + "=test-release", badRoot.Hex() + "=bad-release"}
+	config.Dir = t.TempDir()
+	if err := config.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	locator, err := NewMachineLocator(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	verify := func(dir string, root common.Hash) error {
+		contents, err := os.ReadFile(filepath.Join(dir, "machine.wavm.br"))
+		if err != nil {
+			return err
+		}
+		if string(contents) != "machine for "+root.Hex() {
+			return errors.New("wrong machine")
+		}
+		return nil
+	}
+	if err := locator.EnableRegistry(&config, verify); err != nil {
+		t.Fatal(err)
+	}
+	if !containsRoot(locator.ModuleRoots(), moduleRoot) {
+		t.Error("registry module root isn't reported as supported")
+	}
+
+	ctx := context.Background()
+	if err := locator.EnsureMachine(ctx, moduleRoot); err != nil {
+		t.Fatal(err)
+	}
+	contents, err := os.ReadFile(filepath.Join(locator.GetMachinePath(moduleRoot), "machine.wavm.br"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(contents) != string(machine) {
+		t.Error("fetched machine has the wrong contents")
+	}
+	if _, err := os.Stat(filepath.Join(locator.GetMachinePath(moduleRoot), "replay.wasm")); !errors.Is(err, os.ErrNotExist) {
+		t.Error("missing optional file was created:", err)
+	}
+
+	if err := locator.EnsureMachine(ctx, badRoot); err == nil {
+		t.Error("machine that failed verification was accepted")
+	}
+	if _, err := os.Stat(locator.GetMachinePath(badRoot)); !errors.Is(err, os.ErrNotExist) {
+		t.Error("machine that failed verification was moved into place:", err)
+	}
+	unknownRoot := common.HexToHash("0x3333333333333333333333333333333333333333333333333333333333333333")
+	if err := locator.EnsureMachine(ctx, unknownRoot); !errors.Is(err, ErrMachineNotFound) {
+		t.Error("unexpected error for unknown module root:", err)
+	}
+}
+
+func TestMachineRegistryConfigReleases(t *testing.T) {
+	for _, entry := range []string{"consensus-v31", "0x1234=release", "0x1111111111111111111111111111111111111111111111111111111111111111=", "0x1111111111111111111111111111111111111111111111111111111111111111=../escape"} {
+		config := DefaultMachineRegistryConfig
+		config.Enable = true
+		config.Releases = []string{entry}
+		if err := config.Validate(); err == nil {
+			t.Error("invalid release was accepted:", entry)
+		}
+	}
+}
+
+func containsRoot(roots []common.Hash, moduleRoot common.Hash) bool {
+	for _, root := range roots {
+		if root == moduleRoot {
+			return true
+		}
+	}
+	return false
+}
@@ -5,6 +5,7 @@ package valnode
 
 import (
 	"context"
+	"crypto/ecdsa"
 	"encoding/base64"
 	"errors"
 	"math/rand"
@@ -12,6 +13,7 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethdb"
 
 	"github.com/offchainlabs/nitro/util/stopwaiter"
@@ -21,7 +23,8 @@ import (
 )
 
 type ValidationServerAPI struct {
-	spawner validator.ValidationSpawner
+	spawner    validator.ValidationSpawner
+	signingKey *ecdsa.PrivateKey
 }
 
 func (a *ValidationServerAPI) Name() string {
@@ -41,6 +44,23 @@ func (a *ValidationServerAPI) Validate(ctx context.Context, entry *server_api.In
 	return valRun.Await(ctx)
 }
 
+// ValidateSigned validates like Validate, and signs the result so the client can tell which server computed it.
+// Results are left unsigned if the server has no signing key.
+func (a *ValidationServerAPI) ValidateSigned(ctx context.Context, entry *server_api.InputJSON, moduleRoot common.Hash) (*server_api.SignedValidationResult, error) {
+	state, err := a.Validate(ctx, entry, moduleRoot)
+	if err != nil {
+		return nil, err
+	}
+	result := &server_api.SignedValidationResult{State: state}
+	if a.signingKey != nil {
+		result.Signature, err = crypto.Sign(server_api.ValidationResultHash(moduleRoot, entry, state).Bytes(), a.signingKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
 func (a *ValidationServerAPI) WasmModuleRoots() ([]common.Hash, error) {
 	return a.spawner.WasmModuleRoots()
 }
@@ -50,7 +70,7 @@ func (a *ValidationServerAPI) StylusArchs() ([]ethdb.WasmTarget, error) {
 }
 
 func NewValidationServerAPI(spawner validator.ValidationSpawner) *ValidationServerAPI {
-	return &ValidationServerAPI{spawner}
+	return &ValidationServerAPI{spawner: spawner}
 }
 
 type execRunEntry struct {
@@ -2,9 +2,12 @@ package valnode
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	"github.com/offchainlabs/nitro/validator"
 
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/node"
 	"github.com/ethereum/go-ethereum/rpc"
@@ -17,21 +20,24 @@ import (
 )
 
 type WasmConfig struct {
-	RootPath               string   `koanf:"root-path"`
-	EnableWasmrootsCheck   bool     `koanf:"enable-wasmroots-check"`
-	AllowedWasmModuleRoots []string `koanf:"allowed-wasm-module-roots"`
+	RootPath               string                              `koanf:"root-path"`
+	EnableWasmrootsCheck   bool                                `koanf:"enable-wasmroots-check"`
+	AllowedWasmModuleRoots []string                            `koanf:"allowed-wasm-module-roots"`
+	Registry               server_common.MachineRegistryConfig `koanf:"registry"`
 }
 
 func WasmConfigAddOptions(prefix string, f *pflag.FlagSet) {
 	f.String(prefix+".root-path", DefaultWasmConfig.RootPath, "path to machine folders, each containing wasm files (machine.wavm.br, replay.wasm)")
 	f.Bool(prefix+".enable-wasmroots-check", DefaultWasmConfig.EnableWasmrootsCheck, "enable check for compatibility of on-chain WASM module root with node")
 	f.StringSlice(prefix+".allowed-wasm-module-roots", DefaultWasmConfig.AllowedWasmModuleRoots, "list of WASM module roots or mahcine base paths to match against on-chain WasmModuleRoot")
+	server_common.MachineRegistryConfigAddOptions(prefix+".registry", f)
 }
 
 var DefaultWasmConfig = WasmConfig{
 	RootPath:               "",
 	EnableWasmrootsCheck:   true,
 	AllowedWasmModuleRoots: []string{},
+	Registry:               server_common.DefaultMachineRegistryConfig,
 }
 
 type Config struct {
@@ -41,6 +47,7 @@ type Config struct {
 	Arbitrator server_arb.ArbitratorSpawnerConfig `koanf:"arbitrator" reload:"hot"`
 	Jit        server_jit.JitSpawnerConfig        `koanf:"jit" reload:"hot"`
 	Wasm       WasmConfig                         `koanf:"wasm"`
+	SigningKey string                             `koanf:"signing-key"`
 }
 
 type ValidationConfigFetcher func() *Config
@@ -70,6 +77,7 @@ func ValidationConfigAddOptions(prefix string, f *pflag.FlagSet) {
 	server_arb.ArbitratorSpawnerConfigAddOptions(prefix+".arbitrator", f)
 	server_jit.JitSpawnerConfigAddOptions(prefix+".jit", f)
 	WasmConfigAddOptions(prefix+".wasm", f)
+	f.String(prefix+".signing-key", DefaultValidationConfig.SigningKey, "hex private key to sign validation results with, so validation clients can check which server computed them")
 }
 
 type ValidationNode struct {
@@ -99,6 +107,11 @@ func CreateValidationNode(configFetcher ValidationConfigFetcher, stack *node.Nod
 	if err != nil {
 		return nil, err
 	}
+	if config.Wasm.Registry.Enable {
+		if err := locator.EnableRegistry(&config.Wasm.Registry, server_arb.VerifyMachineModuleRoot); err != nil {
+			return nil, fmt.Errorf("failed to enable the machine registry: %w", err)
+		}
+	}
 	arbConfigFetcher := func() *server_arb.ArbitratorSpawnerConfig {
 		return &configFetcher().Arbitrator
 	}
@@ -119,6 +132,13 @@ func CreateValidationNode(configFetcher ValidationConfigFetcher, stack *node.Nod
 	} else {
 		serverAPI = NewExecutionServerAPI(arbSpawner, arbSpawner, arbConfigFetcher)
 	}
+	if config.SigningKey != "" {
+		serverAPI.signingKey, err = crypto.HexToECDSA(strings.TrimPrefix(config.SigningKey, "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid validation signing key: %w", err)
+		}
+		log.Info("signing validation results", "signer", crypto.PubkeyToAddress(serverAPI.signingKey.PublicKey))
+	}
 	var redisConsumer *redis.ValidationServer
 	redisValidationConfig := arbConfigFetcher().RedisValidationServerConfig
 	if redisValidationConfig.Enabled() {
@@ -26,6 +26,9 @@ import (
 
 var errContextDone = errors.New("context done")
 
+// the number of messages read from the replay buffer and sent to a client at once
+const replayBatchSize = 100
+
 type message struct {
 	data           []byte
 	sequenceNumber *arbutil.MessageIndex
@@ -54,6 +57,7 @@ type ClientConnection struct {
 	lastHeardUnix atomic.Int64
 	out           chan message
 	backlog       backlog.Backlog
+	replay        *backlog.ReplayBuffer
 	registered    chan bool
 	backlogSent   bool
 
@@ -73,6 +77,7 @@ func NewClientConnection(
 	maxSendQueue int,
 	delay time.Duration,
 	bklg backlog.Backlog,
+	replay *backlog.ReplayBuffer,
 ) *ClientConnection {
 	clientConnection := &ClientConnection{
 		conn:            conn,
@@ -87,6 +92,7 @@ func NewClientConnection(
 		flateReader:     NewFlateReader(),
 		delay:           delay,
 		backlog:         bklg,
+		replay:          replay,
 		registered:      make(chan bool, 1),
 		backlogSent:     false,
 	}
@@ -165,6 +171,44 @@ func (cc *ClientConnection) writeBacklog(ctx context.Context, segment backlog.Ba
 	return nil
 }
 
+// writeReplay sends messages from the replay buffer starting at the requested
+// sequence number, until reaching a message in the in-memory backlog or the
+// end of the replay buffer. The requested sequence number is advanced past the
+// messages sent.
+func (cc *ClientConnection) writeReplay(ctx context.Context) error {
+	next := uint64(cc.requestedSeqNum)
+	for cc.replay.Contains(next) {
+		if _, err := cc.backlog.Lookup(next); err == nil {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return errContextDone
+		default:
+		}
+		msgs, err := cc.replay.Get(next, replayBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(msgs) == 0 {
+			break
+		}
+		err = cc.writeBroadcastMessage(&m.BroadcastMessage{
+			Version:  m.V1,
+			Messages: msgs,
+		})
+		if err != nil {
+			return err
+		}
+		end := uint64(msgs[len(msgs)-1].SequenceNumber)
+		cc.LastSentSeqNum.Store(end)
+		next = end + 1
+		log.Debug("replayed messages to client", "client", cc.Name, "sentCount", len(msgs), "lastSentSeqNum", end)
+	}
+	cc.requestedSeqNum = arbutil.MessageIndex(next)
+	return nil
+}
+
 func (cc *ClientConnection) writeBroadcastMessage(bm *m.BroadcastMessage) error {
 	notCompressed, compressed, err := serializeMessage(bm, !cc.compression, cc.compression)
 	if err != nil {
@@ -200,6 +244,22 @@ func (cc *ClientConnection) Start(parentCtx context.Context) {
 			}
 		}
 
+		// Serve a request for messages that are no longer in the in-memory
+		// backlog from the replay buffer, until the backlog can take over
+		if cc.replay != nil && cc.requestedSeqNum > 0 {
+			head := cc.backlog.Head()
+			if backlog.IsBacklogSegmentNil(head) || uint64(cc.requestedSeqNum) < head.Start() {
+				err := cc.writeReplay(ctx)
+				if errors.Is(err, errContextDone) {
+					return
+				} else if err != nil {
+					logWarn(err, "error writing messages from replay buffer")
+					cc.Remove()
+					return
+				}
+			}
+		}
+
 		// Send the current backlog before registering the ClientConnection in
 		// case the backlog is very large
 		segment := cc.backlog.Head()
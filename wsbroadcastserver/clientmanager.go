@@ -51,11 +51,12 @@ type ClientManager struct {
 	clientAction  chan ClientConnectionAction
 	config        BroadcasterConfigFetcher
 	backlog       backlog.Backlog
+	replay        *backlog.ReplayBuffer
 
 	connectionLimiter *ConnectionLimiter
 }
 
-func NewClientManager(poller netpoll.Poller, configFetcher BroadcasterConfigFetcher, bklg backlog.Backlog) *ClientManager {
+func NewClientManager(poller netpoll.Poller, configFetcher BroadcasterConfigFetcher, bklg backlog.Backlog, replay *backlog.ReplayBuffer) *ClientManager {
 	config := configFetcher()
 	return &ClientManager{
 		poller:            poller,
@@ -65,6 +66,7 @@ func NewClientManager(poller netpoll.Poller, configFetcher BroadcasterConfigFetc
 		clientAction:      make(chan ClientConnectionAction, 128),
 		config:            configFetcher,
 		backlog:           bklg,
+		replay:            replay,
 		connectionLimiter: NewConnectionLimiter(func() *ConnectionLimiterConfig { return &configFetcher().ConnectionLimits }),
 	}
 }
@@ -78,7 +80,8 @@ func (cm *ClientManager) registerClient(ctx context.Context, clientConnection *C
 
 	// TODO:(clamb) the clientsTotalFailedRegisterCounter was deleted after backlog logic moved to ClientConnection. Should this metric be reintroduced or will it be ok to just delete completely given the behaviour has changed, ask Lee
 
-	if cm.config().ConnectionLimits.Enable && !cm.connectionLimiter.Register(clientConnection.clientIp) {
+	// clients on a unix domain socket have no IP and aren't limited
+	if cm.config().ConnectionLimits.Enable && clientConnection.clientIp != nil && !cm.connectionLimiter.Register(clientConnection.clientIp) {
 		return fmt.Errorf("Connection limited %s", clientConnection.clientIp)
 	}
 
@@ -129,7 +132,7 @@ func (cm *ClientManager) removeClient(clientConnection *ClientConnection) {
 	}
 
 	cm.removeClientImpl(clientConnection)
-	if cm.config().ConnectionLimits.Enable {
+	if cm.config().ConnectionLimits.Enable && clientConnection.clientIp != nil {
 		cm.connectionLimiter.Release(clientConnection.clientIp)
 	}
 
@@ -155,6 +158,12 @@ func (cm *ClientManager) doBroadcast(bm *m.BroadcastMessage) ([]*ClientConnectio
 	if err := cm.backlog.Append(bm); err != nil {
 		return nil, err
 	}
+	if cm.replay != nil {
+		// the replay buffer only serves deep catch-ups, so failing to write to it shouldn't stop the feed
+		if err := cm.replay.Append(bm); err != nil {
+			log.Error("error writing to feed replay buffer", "err", err)
+		}
+	}
 	config := cm.config()
 	//                                        /-> wsutil.Writer -> not compressed msg buffer
 	// bm -> json.Encoder -> io.MultiWriter -|
@@ -10,6 +10,7 @@ import (
 	"net"
 	"net/http"
 	"net/textproto"
+	"os"
 	"strconv"
 	"strings"
 	"sync"
@@ -22,11 +23,13 @@ import (
 	"github.com/mailru/easygo/netpoll"
 	flag "github.com/spf13/pflag"
 
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/offchainlabs/nitro/arbutil"
 	"github.com/offchainlabs/nitro/broadcaster/backlog"
 	m "github.com/offchainlabs/nitro/broadcaster/message"
+	"github.com/offchainlabs/nitro/broadcaster/redisfeed"
 )
 
 var (
@@ -48,11 +51,13 @@ const (
 type BroadcasterConfig struct {
 	Enable             bool                    `koanf:"enable"`
 	Signed             bool                    `koanf:"signed"`
+	SigningKey         string                  `koanf:"signing-key"`
 	Addr               string                  `koanf:"addr"`
 	ReadTimeout        time.Duration           `koanf:"read-timeout" reload:"hot"`      // reloaded value will affect all clients (next time the timeout is checked)
 	WriteTimeout       time.Duration           `koanf:"write-timeout" reload:"hot"`     // reloading will affect only new connections
 	HandshakeTimeout   time.Duration           `koanf:"handshake-timeout" reload:"hot"` // reloading will affect only new connections
 	Port               string                  `koanf:"port"`
+	UnixSocket         string                  `koanf:"unix-socket"`
 	Ping               time.Duration           `koanf:"ping" reload:"hot"`           // reloaded value will change future ping intervals
 	ClientTimeout      time.Duration           `koanf:"client-timeout" reload:"hot"` // reloaded value will affect all clients (next time the timeout is checked)
 	Queue              int                     `koanf:"queue"`
@@ -69,13 +74,22 @@ type BroadcasterConfig struct {
 	ConnectionLimits   ConnectionLimiterConfig `koanf:"connection-limits" reload:"hot"`
 	ClientDelay        time.Duration           `koanf:"client-delay" reload:"hot"`
 	Backlog            backlog.Config          `koanf:"backlog" reload:"hot"`
+	RedisStream        redisfeed.OutputConfig  `koanf:"redis-stream"`
 }
 
 func (bc *BroadcasterConfig) Validate() error {
 	if !bc.EnableCompression && bc.RequireCompression {
 		return errors.New("require-compression cannot be true while enable-compression is false")
 	}
-	return nil
+	if err := bc.Backlog.Replay.Validate(); err != nil {
+		return err
+	}
+	if bc.SigningKey != "" {
+		if _, err := crypto.HexToECDSA(strings.TrimPrefix(bc.SigningKey, "0x")); err != nil {
+			return fmt.Errorf("invalid feed signing-key: %w", err)
+		}
+	}
+	return bc.RedisStream.Validate()
 }
 
 type BroadcasterConfigFetcher func() *BroadcasterConfig
@@ -83,11 +97,13 @@ type BroadcasterConfigFetcher func() *BroadcasterConfig
 func BroadcasterConfigAddOptions(prefix string, f *flag.FlagSet) {
 	f.Bool(prefix+".enable", DefaultBroadcasterConfig.Enable, "enable broadcaster")
 	f.Bool(prefix+".signed", DefaultBroadcasterConfig.Signed, "sign broadcast messages")
+	f.String(prefix+".signing-key", DefaultBroadcasterConfig.SigningKey, "hex private key to sign broadcast messages with, instead of the batch poster key")
 	f.String(prefix+".addr", DefaultBroadcasterConfig.Addr, "address to bind the relay feed output to")
 	f.Duration(prefix+".read-timeout", DefaultBroadcasterConfig.ReadTimeout, "duration to wait before timing out reading data (i.e. pings) from clients")
 	f.Duration(prefix+".write-timeout", DefaultBroadcasterConfig.WriteTimeout, "duration to wait before timing out writing data to clients")
 	f.Duration(prefix+".handshake-timeout", DefaultBroadcasterConfig.HandshakeTimeout, "duration to wait before timing out HTTP to WS upgrade")
 	f.String(prefix+".port", DefaultBroadcasterConfig.Port, "port to bind the relay feed output to")
+	f.String(prefix+".unix-socket", DefaultBroadcasterConfig.UnixSocket, "path of a unix domain socket to bind the relay feed output to instead of addr and port, for clients on the same host")
 	f.Duration(prefix+".ping", DefaultBroadcasterConfig.Ping, "duration for ping interval")
 	f.Duration(prefix+".client-timeout", DefaultBroadcasterConfig.ClientTimeout, "duration to wait before timing out connections to client")
 	f.Int(prefix+".queue", DefaultBroadcasterConfig.Queue, "queue size for HTTP to WS upgrade")
@@ -104,16 +120,19 @@ func BroadcasterConfigAddOptions(prefix string, f *flag.FlagSet) {
 	ConnectionLimiterConfigAddOptions(prefix+".connection-limits", f)
 	f.Duration(prefix+".client-delay", DefaultBroadcasterConfig.ClientDelay, "delay the first messages sent to each client by this amount")
 	backlog.AddOptions(prefix+".backlog", f)
+	redisfeed.OutputConfigAddOptions(prefix+".redis-stream", f)
 }
 
 var DefaultBroadcasterConfig = BroadcasterConfig{
 	Enable:             false,
 	Signed:             false,
+	SigningKey:         "",
 	Addr:               "",
 	ReadTimeout:        time.Second,
 	WriteTimeout:       2 * time.Second,
 	HandshakeTimeout:   time.Second,
 	Port:               "9642",
+	UnixSocket:         "",
 	Ping:               5 * time.Second,
 	ClientTimeout:      15 * time.Second,
 	Queue:              100,
@@ -130,16 +149,19 @@ var DefaultBroadcasterConfig = BroadcasterConfig{
 	ConnectionLimits:   DefaultConnectionLimiterConfig,
 	ClientDelay:        0,
 	Backlog:            backlog.DefaultConfig,
+	RedisStream:        redisfeed.DefaultOutputConfig,
 }
 
 var DefaultTestBroadcasterConfig = BroadcasterConfig{
 	Enable:             false,
 	Signed:             false,
+	SigningKey:         "",
 	Addr:               "0.0.0.0",
 	ReadTimeout:        2 * time.Second,
 	WriteTimeout:       2 * time.Second,
 	HandshakeTimeout:   2 * time.Second,
 	Port:               "0",
+	UnixSocket:         "",
 	Ping:               5 * time.Second,
 	ClientTimeout:      15 * time.Second,
 	Queue:              1,
@@ -156,6 +178,7 @@ var DefaultTestBroadcasterConfig = BroadcasterConfig{
 	ConnectionLimits:   DefaultConnectionLimiterConfig,
 	ClientDelay:        0,
 	Backlog:            backlog.DefaultTestConfig,
+	RedisStream:        redisfeed.TestOutputConfig,
 }
 
 type WSBroadcastServer struct {
@@ -170,6 +193,7 @@ type WSBroadcastServer struct {
 	started       bool
 	clientManager *ClientManager
 	backlog       backlog.Backlog
+	replay        *backlog.ReplayBuffer
 	chainId       uint64
 	fatalErrChan  chan error
 }
@@ -196,9 +220,16 @@ func (s *WSBroadcastServer) Initialize() error {
 		return err
 	}
 
+	if replayConfig := &s.config().Backlog.Replay; replayConfig.Enable {
+		s.replay, err = backlog.OpenReplayBuffer(replayConfig)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Make pool of X size, Y sized work queue and one pre-spawned
 	// goroutine.
-	s.clientManager = NewClientManager(s.poller, s.config, s.backlog)
+	s.clientManager = NewClientManager(s.poller, s.config, s.backlog, s.replay)
 
 	return nil
 }
@@ -307,6 +338,10 @@ func (s *WSBroadcastServer) StartWithHeader(ctx context.Context, header ws.Hands
 						ws.RejectionReason(fmt.Sprintf("Missing HTTP header %s", HTTPHeaderFeedClientVersion)),
 					)
 				}
+				if _, ok := conn.LocalAddr().(*net.UnixAddr); ok {
+					// clients on the same host aren't subject to the per-IP connection limits
+					return header, nil
+				}
 				if connectingIP == nil {
 					if addr, ok := conn.RemoteAddr().(*net.TCPAddr); ok {
 						connectingIP = addr.IP
@@ -378,7 +413,7 @@ func (s *WSBroadcastServer) StartWithHeader(ctx context.Context, header ws.Hands
 		// Register incoming client in clientManager.
 		safeConn := writeDeadliner{conn, config.WriteTimeout}
 
-		client := NewClientConnection(safeConn, desc, s.clientManager.clientAction, requestedSeqNum, connectingIP, compressionAccepted, s.config().MaxSendQueue, s.config().ClientDelay, s.backlog)
+		client := NewClientConnection(safeConn, desc, s.clientManager.clientAction, requestedSeqNum, connectingIP, compressionAccepted, s.config().MaxSendQueue, s.config().ClientDelay, s.backlog, s.replay)
 		client.Start(ctx)
 
 		// Subscribe to events about conn.
@@ -410,9 +445,19 @@ func (s *WSBroadcastServer) StartWithHeader(ctx context.Context, header ws.Hands
 		}
 	}
 
-	// Create tcp server for relay connections
+	// Create tcp server for relay connections, or a unix domain socket server for clients on the same host
 	config := s.config()
-	ln, err := net.Listen("tcp", config.Addr+":"+config.Port)
+	var ln net.Listener
+	var err error
+	if config.UnixSocket != "" {
+		// a socket left behind by a previous run would make the listen fail
+		if err := os.Remove(config.UnixSocket); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		ln, err = net.Listen("unix", config.UnixSocket)
+	} else {
+		ln, err = net.Listen("tcp", config.Addr+":"+config.Port)
+	}
 	if err != nil {
 		log.Error("error calling net.Listen", "err", err)
 		return err
@@ -527,6 +572,9 @@ func (s *WSBroadcastServer) StopAndWait() {
 	}
 
 	s.clientManager.StopAndWait()
+	if s.replay != nil {
+		s.replay.Close()
+	}
 	s.started = false
 }
 